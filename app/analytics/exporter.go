@@ -0,0 +1,274 @@
+package analytics
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/okex/exchain/libs/tendermint/libs/log"
+	"github.com/okex/exchain/x/evm/watcher"
+)
+
+const (
+	FlagAnalyticsExportDir      = "analytics.export-dir"
+	FlagAnalyticsFormat         = "analytics.format"
+	FlagAnalyticsPartition      = "analytics.partition"
+	FlagAnalyticsPartitionSize  = "analytics.partition-size"
+	FlagAnalyticsExportInterval = "analytics.export-interval"
+
+	FormatCSV     = "csv"
+	FormatParquet = "parquet"
+
+	PartitionDay    = "day"
+	PartitionHeight = "height"
+
+	// stateFile records the last exported height in the output directory
+	stateFile = "export_state.json"
+	// partSuffix is the suffix of the files being written, they are renamed once complete
+	partSuffix = ".part"
+)
+
+// Config is the layout of the exported files
+type Config struct {
+	Dir       string
+	Format    string
+	Partition string
+	// PartitionSize is the number of blocks of the height partitions
+	PartitionSize uint64
+}
+
+// ConfigFromFlags returns the config of the export into dir set by the flags
+func ConfigFromFlags(dir string) Config {
+	return Config{
+		Dir:           dir,
+		Format:        viper.GetString(FlagAnalyticsFormat),
+		Partition:     viper.GetString(FlagAnalyticsPartition),
+		PartitionSize: viper.GetUint64(FlagAnalyticsPartitionSize),
+	}
+}
+
+func (c Config) Validate() error {
+	if c.Dir == "" {
+		return errors.New("no output directory")
+	}
+	switch c.Format {
+	case FormatCSV:
+	case FormatParquet:
+		return errors.New("the parquet format isn't supported by this build, use csv")
+	default:
+		return fmt.Errorf("unknown format %s", c.Format)
+	}
+	switch c.Partition {
+	case PartitionDay:
+	case PartitionHeight:
+		if c.PartitionSize == 0 {
+			return errors.New("the partition size must be positive")
+		}
+	default:
+		return fmt.Errorf("unknown partition %s", c.Partition)
+	}
+	return nil
+}
+
+// Exporter writes the blocks, txs, receipts and logs of the watcher db into one directory per table and
+// partition, e.g. logs/date=2022-03-01/logs-100-200.csv. The files of a partition hold consecutive blocks and
+// only get their final name once complete. The last exported height is recorded in the output directory, so
+// that the export resumes where it stopped.
+type Exporter struct {
+	db     dbm.DB
+	config Config
+
+	partition string
+	first     uint64
+	last      uint64
+	files     []*tableFile
+}
+
+type tableFile struct {
+	table table
+	path  string
+	file  *os.File
+	w     *csv.Writer
+}
+
+type exportState struct {
+	Height uint64 `json:"height"`
+}
+
+func NewExporter(db dbm.DB, config Config) (*Exporter, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(config.Dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Exporter{db: db, config: config}, nil
+}
+
+// LastHeight returns the last exported height, 0 if nothing was exported yet
+func (e *Exporter) LastHeight() (uint64, error) {
+	bz, err := ioutil.ReadFile(filepath.Join(e.config.Dir, stateFile))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var state exportState
+	if err := json.Unmarshal(bz, &state); err != nil {
+		return 0, fmt.Errorf("invalid export state: %w", err)
+	}
+	return state.Height, nil
+}
+
+// Export writes the blocks from the height after the last exported one to the given height, the heights
+// missing from the watcher db are skipped. It returns the number of blocks exported.
+func (e *Exporter) Export(to uint64) (n int, err error) {
+	last, err := e.LastHeight()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if closeErr := e.closeFiles(); err == nil {
+			err = closeErr
+		}
+		if err == nil && to > last {
+			err = e.saveState(to)
+		}
+	}()
+
+	for height := last + 1; height <= to; height++ {
+		data, err := watcher.ReadBlockData(e.db, height)
+		if err != nil {
+			return n, err
+		}
+		if data == nil {
+			continue
+		}
+		if err := e.write(data); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// Run exports the new blocks of the watcher db at every interval until quit is closed
+func (e *Exporter) Run(interval time.Duration, logger log.Logger, quit <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-quit:
+			return
+		case <-ticker.C:
+		}
+
+		latest, err := watcher.GetLatestHeight(e.db)
+		if err != nil {
+			continue
+		}
+		n, err := e.Export(latest)
+		if err != nil {
+			logger.Error("failed to export the blocks", "height", latest, "err", err)
+			continue
+		}
+		if n > 0 {
+			logger.Debug("exported the blocks", "blocks", n, "height", latest)
+		}
+	}
+}
+
+func (e *Exporter) write(data *watcher.BlockData) error {
+	height := uint64(data.Block.Number)
+	partition := e.partitionOf(data)
+	if partition != e.partition {
+		if err := e.closeFiles(); err != nil {
+			return err
+		}
+		if err := e.openFiles(partition, height); err != nil {
+			return err
+		}
+	}
+	for _, f := range e.files {
+		if err := f.w.WriteAll(f.table.rows(data)); err != nil {
+			return err
+		}
+	}
+	e.last = height
+	return nil
+}
+
+func (e *Exporter) partitionOf(data *watcher.BlockData) string {
+	if e.config.Partition == PartitionDay {
+		return "date=" + time.Unix(int64(data.Block.Timestamp), 0).UTC().Format("2006-01-02")
+	}
+	start := uint64(data.Block.Number) / e.config.PartitionSize * e.config.PartitionSize
+	return fmt.Sprintf("height=%d-%d", start, start+e.config.PartitionSize-1)
+}
+
+func (e *Exporter) openFiles(partition string, height uint64) error {
+	e.partition, e.first = partition, height
+	for _, t := range tables {
+		dir := filepath.Join(e.config.Dir, t.name, partition)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		path := filepath.Join(dir, fmt.Sprintf("%s-%d.%s%s", t.name, height, e.config.Format, partSuffix))
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		f := &tableFile{table: t, path: path, file: file, w: csv.NewWriter(file)}
+		e.files = append(e.files, f)
+		if err := f.w.Write(t.columns); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// closeFiles completes the files of the current partition and gives them their final name
+func (e *Exporter) closeFiles() error {
+	var err error
+	for _, f := range e.files {
+		f.w.Flush()
+		if flushErr := f.w.Error(); err == nil {
+			err = flushErr
+		}
+		if syncErr := f.file.Sync(); err == nil {
+			err = syncErr
+		}
+		if closeErr := f.file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			continue
+		}
+		path := strings.TrimSuffix(f.path, fmt.Sprintf("-%d.%s%s", e.first, e.config.Format, partSuffix))
+		err = os.Rename(f.path, fmt.Sprintf("%s-%d-%d.%s", path, e.first, e.last, e.config.Format))
+	}
+	e.files, e.partition = nil, ""
+	return err
+}
+
+func (e *Exporter) saveState(height uint64) error {
+	bz, err := json.Marshal(exportState{Height: height})
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(e.config.Dir, stateFile)
+	if err := ioutil.WriteFile(path+partSuffix, bz, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+partSuffix, path)
+}
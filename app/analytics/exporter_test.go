@@ -0,0 +1,107 @@
+package analytics
+
+import (
+	"encoding/csv"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	abci "github.com/okex/exchain/libs/tendermint/abci/types"
+	"github.com/okex/exchain/x/evm/types"
+	"github.com/okex/exchain/x/evm/watcher"
+)
+
+// newWatchDB returns a watcher db of blocks with one tx each, the blocks 1 and 2 are on the first day
+func newWatchDB(t *testing.T, blocks uint64) dbm.DB {
+	db := dbm.NewMemDB()
+	set := func(msgs ...watcher.WatchMessage) {
+		for _, msg := range msgs {
+			require.NoError(t, db.Set(msg.GetKey(), []byte(msg.GetValue())))
+		}
+	}
+
+	to := common.HexToAddress("0x02")
+	tx := types.NewMsgEthereumTx(0, &to, big.NewInt(0), 21000, big.NewInt(1), nil)
+	for height := uint64(1); height <= blocks; height++ {
+		blockHash := common.BigToHash(new(big.Int).SetUint64(height))
+		txHash := common.BigToHash(new(big.Int).SetUint64(height + 100))
+		header := abci.Header{Height: int64(height), Time: time.Unix(int64(height/3)*86400, 0)}
+		data := &types.ResultData{Logs: []*ethtypes.Log{{Address: to, Topics: []common.Hash{txHash}}}}
+		set(
+			watcher.NewMsgBlock(height, ethtypes.Bloom{}, blockHash, header, 30000000, big.NewInt(21000), []common.Hash{txHash}, big.NewInt(7), to),
+			watcher.NewMsgBlockInfo(height, blockHash),
+			watcher.MsgEthTx{Key: txHash.Bytes(), JsonEthTx: `{"hash":"` + txHash.Hex() + `"}`},
+			watcher.NewMsgTransactionReceipt(watcher.TransactionSuccess, &tx, txHash, blockHash, 0, height, data, 21000, 21000),
+			watcher.NewMsgLogIndexed(blockHash),
+		)
+	}
+	set(watcher.NewMsgLatestHeight(blocks))
+	return db
+}
+
+func readCSV(t *testing.T, path string) [][]string {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+	return records
+}
+
+func TestExport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "analytics")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	db := newWatchDB(t, 4)
+	exporter, err := NewExporter(db, Config{Dir: dir, Format: FormatCSV, Partition: PartitionDay})
+	require.NoError(t, err)
+	n, err := exporter.Export(2)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	blocks := readCSV(t, filepath.Join(dir, "blocks", "date=1970-01-01", "blocks-1-2.csv"))
+	require.Len(t, blocks, 3)
+	require.Equal(t, tables[0].columns, blocks[0])
+	require.Equal(t, "2", blocks[2][0])
+	logs := readCSV(t, filepath.Join(dir, "logs", "date=1970-01-01", "logs-1-2.csv"))
+	require.Len(t, logs, 3)
+	require.Equal(t, common.BigToHash(big.NewInt(101)).Hex(), logs[1][6])
+
+	// the export resumes after the last exported height, in a partition per day
+	n, err = exporter.Export(4)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	last, err := exporter.LastHeight()
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), last)
+	receipts := readCSV(t, filepath.Join(dir, "receipts", "date=1970-01-02", "receipts-3-4.csv"))
+	require.Len(t, receipts, 3)
+	require.Equal(t, "1", receipts[1][4])
+}
+
+func TestExportByHeight(t *testing.T) {
+	dir, err := ioutil.TempDir("", "analytics")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	exporter, err := NewExporter(newWatchDB(t, 5), Config{Dir: dir, Format: FormatCSV, Partition: PartitionHeight, PartitionSize: 2})
+	require.NoError(t, err)
+	_, err = exporter.Export(5)
+	require.NoError(t, err)
+	for _, path := range []string{"height=0-1/transactions-1-1.csv", "height=2-3/transactions-2-3.csv", "height=4-5/transactions-4-5.csv"} {
+		_, err := os.Stat(filepath.Join(dir, "transactions", path))
+		require.NoError(t, err, path)
+	}
+
+	_, err = NewExporter(dbm.NewMemDB(), Config{Dir: dir, Format: FormatParquet, Partition: PartitionDay})
+	require.Error(t, err)
+}
@@ -0,0 +1,104 @@
+package analytics
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/okex/exchain/x/evm/watcher"
+)
+
+// table is a kind of rows exported from the blocks
+type table struct {
+	name    string
+	columns []string
+	rows    func(data *watcher.BlockData) [][]string
+}
+
+var tables = []table{
+	{
+		name:    "blocks",
+		columns: []string{"number", "hash", "parent_hash", "timestamp", "miner", "size", "gas_limit", "gas_used", "tx_count"},
+		rows: func(data *watcher.BlockData) [][]string {
+			b := data.Block
+			return [][]string{{
+				uintString(uint64(b.Number)), b.Hash.Hex(), b.ParentHash.Hex(), uintString(uint64(b.Timestamp)),
+				b.Miner.Hex(), uintString(uint64(b.Size)), uintString(uint64(b.GasLimit)), bigString(b.GasUsed),
+				strconv.Itoa(len(data.Txs)),
+			}}
+		},
+	},
+	{
+		name: "transactions",
+		columns: []string{"block_number", "block_hash", "tx_index", "hash", "from", "to", "value", "gas", "gas_price",
+			"nonce", "input"},
+		rows: func(data *watcher.BlockData) [][]string {
+			rows := make([][]string, 0, len(data.Txs))
+			for i, tx := range data.Txs {
+				to := ""
+				if tx.To != nil {
+					to = tx.To.Hex()
+				}
+				rows = append(rows, []string{
+					uintString(uint64(data.Block.Number)), data.Block.Hash.Hex(), strconv.Itoa(i), tx.Hash.Hex(),
+					tx.From.Hex(), to, bigString(tx.Value), uintString(uint64(tx.Gas)), bigString(tx.GasPrice),
+					uintString(uint64(tx.Nonce)), tx.Input.String(),
+				})
+			}
+			return rows
+		},
+	},
+	{
+		name: "receipts",
+		columns: []string{"block_number", "block_hash", "tx_index", "tx_hash", "status", "gas_used",
+			"cumulative_gas_used", "contract_address"},
+		rows: func(data *watcher.BlockData) [][]string {
+			rows := make([][]string, 0, len(data.Receipts))
+			for _, r := range data.Receipts {
+				contract := ""
+				if r.ContractAddress != nil {
+					contract = r.ContractAddress.Hex()
+				}
+				rows = append(rows, []string{
+					uintString(uint64(r.BlockNumber)), r.BlockHash, uintString(uint64(r.TransactionIndex)),
+					r.TransactionHash, uintString(uint64(r.Status)), uintString(uint64(r.GasUsed)),
+					uintString(uint64(r.CumulativeGasUsed)), contract,
+				})
+			}
+			return rows
+		},
+	},
+	{
+		name:    "logs",
+		columns: []string{"block_number", "block_hash", "tx_index", "tx_hash", "log_index", "address", "topics", "data"},
+		rows: func(data *watcher.BlockData) [][]string {
+			var rows [][]string
+			for _, r := range data.Receipts {
+				for _, l := range r.Logs {
+					topics := make([]string, len(l.Topics))
+					for i, topic := range l.Topics {
+						topics[i] = topic.Hex()
+					}
+					rows = append(rows, []string{
+						uintString(uint64(r.BlockNumber)), r.BlockHash, uintString(uint64(r.TransactionIndex)),
+						r.TransactionHash, strconv.FormatUint(uint64(l.Index), 10), l.Address.Hex(),
+						strings.Join(topics, ";"), hexutil.Encode(l.Data),
+					})
+				}
+			}
+			return rows
+		},
+	},
+}
+
+func uintString(x uint64) string {
+	return strconv.FormatUint(x, 10)
+}
+
+func bigString(x *hexutil.Big) string {
+	if x == nil {
+		return ""
+	}
+	return x.ToInt().String()
+}
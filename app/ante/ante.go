@@ -1,6 +1,10 @@
 package ante
 
 import (
+	"encoding/hex"
+
+	"github.com/ethereum/go-ethereum/common"
+
 	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
 	sdkerrors "github.com/okex/exchain/libs/cosmos-sdk/types/errors"
 	"github.com/okex/exchain/libs/cosmos-sdk/x/auth"
@@ -9,9 +13,11 @@ import (
 	"github.com/okex/exchain/libs/cosmos-sdk/x/auth/types"
 
 	"github.com/okex/exchain/app/crypto/ethsecp256k1"
+	ethermint "github.com/okex/exchain/app/types"
 	evmtypes "github.com/okex/exchain/x/evm/types"
 
 	tmcrypto "github.com/okex/exchain/libs/tendermint/crypto"
+	tmtypes "github.com/okex/exchain/libs/tendermint/types"
 )
 
 func init() {
@@ -29,7 +35,8 @@ const (
 // Ethereum or SDK transaction to an internal ante handler for performing
 // transaction-level processing (e.g. fee payment, signature verification) before
 // being passed onto it's respective handler.
-func NewAnteHandler(ak auth.AccountKeeper, evmKeeper EVMKeeper, sk types.SupplyKeeper, validateMsgHandler ValidateMsgHandler) sdk.AnteHandler {
+func NewAnteHandler(ak auth.AccountKeeper, evmKeeper EVMKeeper, sk types.SupplyKeeper, swapKeeper SwapKeeper,
+	validateMsgHandler ValidateMsgHandler) sdk.AnteHandler {
 	return func(
 		ctx sdk.Context, tx sdk.Tx, sim bool,
 	) (newCtx sdk.Context, err error) {
@@ -40,7 +47,9 @@ func NewAnteHandler(ak auth.AccountKeeper, evmKeeper EVMKeeper, sk types.SupplyK
 				authante.NewSetUpContextDecorator(), // outermost AnteDecorator. SetUpContext must be called first
 				NewAccountSetupDecorator(ak),
 				NewAccountBlockedVerificationDecorator(evmKeeper), //account blocked check AnteDecorator
+				NewTxBlacklistDecorator(),
 				authante.NewMempoolFeeDecorator(),
+				NewMinGasPriceDecorator(evmKeeper),
 				authante.NewValidateBasicDecorator(),
 				authante.NewValidateMemoDecorator(ak),
 				authante.NewConsumeGasForTxSizeDecorator(ak),
@@ -58,12 +67,14 @@ func NewAnteHandler(ak auth.AccountKeeper, evmKeeper EVMKeeper, sk types.SupplyK
 				NewEthSetupContextDecorator(), // outermost AnteDecorator. EthSetUpContext must be called first
 				NewGasLimitDecorator(evmKeeper),
 				NewEthMempoolFeeDecorator(evmKeeper),
+				NewMinGasPriceDecorator(evmKeeper),
 				authante.NewValidateBasicDecorator(),
 				NewEthSigVerificationDecorator(),
 				NewAccountBlockedVerificationDecorator(evmKeeper), //account blocked check AnteDecorator
-				NewAccountVerificationDecorator(ak, evmKeeper),
+				NewTxBlacklistDecorator(),
+				NewAccountVerificationDecorator(ak, evmKeeper, swapKeeper),
 				NewNonceVerificationDecorator(ak),
-				NewEthGasConsumeDecorator(ak, sk, evmKeeper),
+				NewEthGasConsumeDecorator(ak, sk, evmKeeper, swapKeeper),
 				NewIncrementSenderSequenceDecorator(ak), // innermost AnteDecorator.
 			)
 		default:
@@ -163,6 +174,89 @@ func (abvd AccountBlockedVerificationDecorator) AnteHandle(ctx sdk.Context, tx s
 	return next(ctx, tx, simulate)
 }
 
+// TxBlacklistDecorator refuses the admission to the mempool of the evm txs matching a rule of the tx blacklist of
+// the node. The blacklist is node local, the txs of the blocks are never checked against it.
+type TxBlacklistDecorator struct{}
+
+// NewTxBlacklistDecorator creates a new TxBlacklistDecorator instance
+func NewTxBlacklistDecorator() TxBlacklistDecorator {
+	return TxBlacklistDecorator{}
+}
+
+// AnteHandle rejects the tx in the check and the recheck modes if one of its evm calls matches a rule of the
+// blacklist, so that the txs already in the mempool are evicted on the next recheck
+func (tbd TxBlacklistDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (sdk.Context, error) {
+	if !ctx.IsCheckTx() || simulate {
+		return next(ctx, tx, simulate)
+	}
+	for _, msg := range tx.GetMsgs() {
+		var to *common.Address
+		var data []byte
+		switch msg := msg.(type) {
+		case evmtypes.MsgEthereumTx:
+			to, data = msg.Data.Recipient, msg.Data.Payload
+		case evmtypes.MsgEthermint:
+			if msg.Recipient != nil {
+				recipient := common.BytesToAddress(msg.Recipient.Bytes())
+				to = &recipient
+			}
+			data = msg.Payload
+		default:
+			continue
+		}
+		if rule, ok := evmtypes.MatchTxBlacklist(to, data); ok {
+			ctx.Logger().Info("tx refused by the tx blacklist", "hash", hex.EncodeToString(tmtypes.Tx(ctx.TxBytes()).Hash()),
+				"to", to.Hex(), "rule-to", rule.To, "rule-selector", rule.Selector, "reason", rule.Reason)
+			return ctx, sdkerrors.Wrapf(evmtypes.ErrTxBlacklisted, "the call of %s is refused: %s", to.Hex(), rule.Reason)
+		}
+	}
+	return next(ctx, tx, simulate)
+}
+
+// MinGasPriceDecorator rejects the txs whose gas price is below the min gas price floor voted by the
+// validators. Unlike the min gas prices of the nodes, the floor is part of the state and is checked when the
+// txs are delivered too.
+type MinGasPriceDecorator struct {
+	evmKeeper EVMKeeper
+}
+
+// NewMinGasPriceDecorator creates a new MinGasPriceDecorator instance
+func NewMinGasPriceDecorator(evmKeeper EVMKeeper) MinGasPriceDecorator {
+	return MinGasPriceDecorator{
+		evmKeeper: evmKeeper,
+	}
+}
+
+// AnteHandle checks that the fee of the tx in the evm denom covers its gas limit at the floor
+func (mgpd MinGasPriceDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool, next sdk.AnteHandler) (sdk.Context, error) {
+	// the lookup isn't charged to the tx, the simulations estimating the gas are free of the floor
+	floor := mgpd.evmKeeper.GetMinGasPrice(ctx.WithGasMeter(sdk.NewInfiniteGasMeter()))
+	if !floor.IsPositive() || simulate {
+		return next(ctx, tx, simulate)
+	}
+
+	evmDenom := sdk.DefaultBondDenom
+	var fee sdk.Dec
+	var gas uint64
+	switch tx := tx.(type) {
+	case evmtypes.MsgEthereumTx:
+		fee, gas = ethermint.WeiToDec(tx.Fee()), tx.GetGas()
+	case authante.FeeTx:
+		fee, gas = tx.GetFee().AmountOf(evmDenom), tx.GetGas()
+	default:
+		return ctx, sdkerrors.Wrapf(sdkerrors.ErrUnknownRequest, "invalid transaction type: %T", tx)
+	}
+
+	if minFee := floor.MulInt64(int64(gas)); fee.LT(minFee) {
+		return ctx, sdkerrors.Wrapf(
+			sdkerrors.ErrInsufficientFee,
+			"insufficient fee for the min gas price of %s%s, got: %s%s required: %s%s",
+			floor, evmDenom, fee, evmDenom, minFee, evmDenom,
+		)
+	}
+	return next(ctx, tx, simulate)
+}
+
 // getSigners get signers of tx(contains cosmos-tx and eth-tx.
 func getSigners(tx sdk.Tx) ([]sdk.AccAddress, error) {
 	signers := make([]sdk.AccAddress, 0)
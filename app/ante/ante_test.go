@@ -17,6 +17,8 @@ import (
 	"github.com/okex/exchain/app"
 	"github.com/okex/exchain/app/ante"
 	"github.com/okex/exchain/app/types"
+	ammswapkeeper "github.com/okex/exchain/x/ammswap/keeper"
+	ammswaptypes "github.com/okex/exchain/x/ammswap/types"
 	evmtypes "github.com/okex/exchain/x/evm/types"
 )
 
@@ -228,6 +230,97 @@ func (suite *AnteTestSuite) TestEthInsufficientBalance() {
 	requireInvalidTx(suite.T(), suite.anteHandler, suite.ctx, tx, false)
 }
 
+func (suite *AnteTestSuite) TestEthFeeDenomConversion() {
+	suite.ctx = suite.ctx.WithBlockHeight(1)
+
+	addr1, priv1 := newTestAddrKey()
+	addr2, _ := newTestAddrKey()
+
+	// the sender only has the tx value in the evm denom
+	acc := suite.app.AccountKeeper.NewAccountWithAddress(suite.ctx, addr1)
+	_ = acc.SetCoins(sdk.NewCoins(sdk.NewCoin(sdk.DefaultBondDenom, sdk.NewDecWithPrec(32, sdk.Precision)),
+		sdk.NewInt64Coin("usdk", 1)))
+	suite.app.AccountKeeper.SetAccount(suite.ctx, acc)
+
+	pair := ammswaptypes.NewSwapPair(sdk.DefaultBondDenom, "usdk")
+	pair.BasePooledCoin.Amount, pair.QuotePooledCoin.Amount = sdk.NewDec(1000), sdk.NewDec(1000)
+	suite.app.SwapKeeper.SetSwapTokenPair(suite.ctx, pair.TokenPairName(), pair)
+	pool := suite.app.SupplyKeeper.GetModuleAccount(suite.ctx, ammswaptypes.ModuleName)
+	suite.Require().NoError(pool.SetCoins(sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 1000), sdk.NewInt64Coin("usdk", 1000))))
+	suite.app.SupplyKeeper.SetModuleAccount(suite.ctx, pool)
+
+	to := ethcmn.BytesToAddress(addr2.Bytes())
+	ethMsg := evmtypes.NewMsgEthereumTx(0, &to, big.NewInt(32), 22000, big.NewInt(20), []byte("test"))
+	tx, err := newTestEthTx(suite.ctx, ethMsg, priv1)
+	suite.Require().NoError(err)
+
+	// without fee denoms the gas fee can't be paid
+	requireInvalidTx(suite.T(), suite.anteHandler, suite.ctx, tx, false)
+
+	params := evmtypes.DefaultParams()
+	params.FeeDenoms = []string{"usdk"}
+	params.FeeConversionSpread = sdk.NewDecWithPrec(1, 2)
+	suite.app.EvmKeeper.SetParams(suite.ctx, params)
+	requireValidTx(suite.T(), suite.anteHandler, suite.ctx, tx, false)
+
+	// the missing gas fee is bought from the pool, the tx value is left in the evm denom
+	fee := sdk.NewDecWithPrec(22000*20, sdk.Precision)
+	sold := ammswapkeeper.GetOutputPrice(fee, sdk.NewDec(1000), sdk.NewDec(1000), params.FeeConversionSpread)
+	suite.Require().True(sold.GT(fee))
+	coins := suite.app.AccountKeeper.GetAccount(suite.ctx, addr1).GetCoins()
+	suite.Require().Equal(sdk.NewDecWithPrec(32, sdk.Precision), coins.AmountOf(sdk.DefaultBondDenom))
+	suite.Require().Equal(sdk.OneDec().Sub(sold), coins.AmountOf("usdk"))
+
+	pair, err = suite.app.SwapKeeper.GetSwapTokenPair(suite.ctx, pair.TokenPairName())
+	suite.Require().NoError(err)
+	suite.Require().Equal(sdk.NewDec(1000).Sub(fee), pair.BasePooledCoin.Amount)
+	suite.Require().Equal(sdk.NewDec(1000).Add(sold), pair.QuotePooledCoin.Amount)
+}
+
+func (suite *AnteTestSuite) TestEthFeeAllowance() {
+	suite.ctx = suite.ctx.WithBlockHeight(1)
+
+	addr1, priv1 := newTestAddrKey()
+	addr2, _ := newTestAddrKey()
+	granter, _ := newTestAddrKey()
+
+	// the sender only has the tx value, the granter pays the gas fee
+	acc := suite.app.AccountKeeper.NewAccountWithAddress(suite.ctx, addr1)
+	_ = acc.SetCoins(sdk.NewCoins(sdk.NewCoin(sdk.DefaultBondDenom, sdk.NewDecWithPrec(32, sdk.Precision))))
+	suite.app.AccountKeeper.SetAccount(suite.ctx, acc)
+	granterAcc := suite.app.AccountKeeper.NewAccountWithAddress(suite.ctx, granter)
+	_ = granterAcc.SetCoins(sdk.NewCoins(sdk.NewCoin(sdk.DefaultBondDenom, sdk.NewDec(1))))
+	suite.app.AccountKeeper.SetAccount(suite.ctx, granterAcc)
+
+	to := ethcmn.BytesToAddress(addr2.Bytes())
+	ethMsg := evmtypes.NewMsgEthereumTx(0, &to, big.NewInt(32), 22000, big.NewInt(20), []byte("test"))
+	tx, err := newTestEthTx(suite.ctx, ethMsg, priv1)
+	suite.Require().NoError(err)
+
+	// without allowance the gas fee can't be paid
+	requireInvalidTx(suite.T(), suite.anteHandler, suite.ctx, tx, false)
+
+	// the expired allowance isn't used
+	fee := sdk.NewDecWithPrec(22000*20, sdk.Precision)
+	suite.app.EvmKeeper.SetFeeAllowance(suite.ctx, evmtypes.NewFeeAllowance(granter, addr1, fee.MulInt64(2), 1))
+	requireInvalidTx(suite.T(), suite.anteHandler, suite.ctx.WithBlockHeight(2), tx, false)
+
+	requireValidTx(suite.T(), suite.anteHandler, suite.ctx, tx, false)
+
+	// the gas fee is charged to the granter and its allowance, the refund goes to the granter
+	coins := suite.app.AccountKeeper.GetAccount(suite.ctx, addr1).GetCoins()
+	suite.Require().Equal(sdk.NewDecWithPrec(32, sdk.Precision), coins.AmountOf(sdk.DefaultBondDenom))
+	coins = suite.app.AccountKeeper.GetAccount(suite.ctx, granter).GetCoins()
+	suite.Require().Equal(sdk.NewDec(1).Sub(fee), coins.AmountOf(sdk.DefaultBondDenom))
+	allowance, found := suite.app.EvmKeeper.GetFeeAllowance(suite.ctx, addr1)
+	suite.Require().True(found)
+	suite.Require().Equal(fee, allowance.SpendLimit)
+	charged, found := suite.app.EvmKeeper.GetTxFeeAllowance(suite.ctx, addr1)
+	suite.Require().True(found)
+	suite.Require().Equal(granter, charged.Granter)
+	suite.Require().Equal(fee.MulInt64(2), charged.SpendLimit)
+}
+
 func (suite *AnteTestSuite) TestEthInvalidIntrinsicGas() {
 	suite.ctx = suite.ctx.WithBlockHeight(1)
 
@@ -256,7 +349,7 @@ func (suite *AnteTestSuite) TestEthInvalidMempoolFees() {
 	suite.ctx = suite.app.BaseApp.NewContext(true, abci.Header{Height: 1, ChainID: "ethermint-3", Time: time.Now().UTC()})
 	suite.app.EvmKeeper.SetParams(suite.ctx, evmtypes.DefaultParams())
 
-	suite.anteHandler = ante.NewAnteHandler(suite.app.AccountKeeper, suite.app.EvmKeeper, suite.app.SupplyKeeper, nil)
+	suite.anteHandler = ante.NewAnteHandler(suite.app.AccountKeeper, suite.app.EvmKeeper, suite.app.SupplyKeeper, &suite.app.SwapKeeper, nil)
 	suite.ctx = suite.ctx.WithMinGasPrices(sdk.NewDecCoins(sdk.NewDecCoinFromDec(types.NativeToken, sdk.NewDecFromBigIntWithPrec(big.NewInt(500000), sdk.Precision))))
 	addr1, priv1 := newTestAddrKey()
 	addr2, _ := newTestAddrKey()
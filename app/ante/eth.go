@@ -22,6 +22,11 @@ import (
 type EVMKeeper interface {
 	GetParams(ctx sdk.Context) evmtypes.Params
 	IsAddressBlocked(ctx sdk.Context, addr sdk.AccAddress) bool
+	GetFeeAllowance(ctx sdk.Context, grantee sdk.AccAddress) (evmtypes.FeeAllowance, bool)
+	SetFeeAllowance(ctx sdk.Context, allowance evmtypes.FeeAllowance)
+	DeleteFeeAllowance(ctx sdk.Context, grantee sdk.AccAddress)
+	SetTxFeeAllowance(ctx sdk.Context, sender sdk.AccAddress, allowance evmtypes.FeeAllowance)
+	GetMinGasPrice(ctx sdk.Context) sdk.Dec
 }
 
 // EthSetupContextDecorator sets the infinite GasMeter in the Context and wraps
@@ -101,7 +106,7 @@ func (emfd EthMempoolFeeDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simula
 	evmDenom := sdk.DefaultBondDenom
 
 	// fee = gas price * gas limit
-	fee := sdk.NewDecCoinFromDec(evmDenom, sdk.NewDecFromBigIntWithPrec(msgEthTx.Fee(), sdk.Precision))
+	fee := sdk.NewDecCoinFromDec(evmDenom, ethermint.WeiToDec(msgEthTx.Fee()))
 
 	minGasPrices := ctx.MinGasPrices()
 	minFees := minGasPrices.AmountOf(evmDenom).MulInt64(int64(msgEthTx.Data.GasLimit))
@@ -163,15 +168,17 @@ func (esvd EthSigVerificationDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, s
 
 // AccountVerificationDecorator validates an account balance checks
 type AccountVerificationDecorator struct {
-	ak        auth.AccountKeeper
-	evmKeeper EVMKeeper
+	ak         auth.AccountKeeper
+	evmKeeper  EVMKeeper
+	swapKeeper SwapKeeper
 }
 
 // NewAccountVerificationDecorator creates a new AccountVerificationDecorator
-func NewAccountVerificationDecorator(ak auth.AccountKeeper, ek EVMKeeper) AccountVerificationDecorator {
+func NewAccountVerificationDecorator(ak auth.AccountKeeper, ek EVMKeeper, swk SwapKeeper) AccountVerificationDecorator {
 	return AccountVerificationDecorator{
-		ak:        ak,
-		evmKeeper: ek,
+		ak:         ak,
+		evmKeeper:  ek,
+		swapKeeper: swk,
 	}
 }
 
@@ -206,15 +213,19 @@ func (avd AccountVerificationDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, s
 		)
 	}
 
-	evmDenom := sdk.DefaultBondDenom
-
-	// validate sender has enough funds to pay for gas cost
-	balance := acc.GetCoins().AmountOf(evmDenom)
-	if balance.BigInt().Cmp(msgEthTx.Cost()) < 0 {
-		return ctx, sdkerrors.Wrapf(
-			sdkerrors.ErrInsufficientFunds,
-			"sender balance < tx gas cost (%s%s < %s%s)", balance.String(), evmDenom, sdk.NewDecFromBigIntWithPrec(msgEthTx.Cost(), sdk.Precision).String(), evmDenom,
-		)
+	// validate sender has enough funds to pay for gas cost, a sponsor or the fee denoms may pay the gas fee
+	if allowance, _ := getFeeAllowance(ctx, avd.evmKeeper, avd.ak, address, msgEthTx); allowance != nil {
+		evmDenom := sdk.DefaultBondDenom
+		balance := acc.GetCoins().AmountOf(evmDenom)
+		value := ethermint.WeiToDec(msgEthTx.Data.Amount)
+		if balance.LT(value) {
+			return ctx, sdkerrors.Wrapf(
+				sdkerrors.ErrInsufficientFunds,
+				"sender balance < tx value (%s%s < %s%s)", balance.String(), evmDenom, value.String(), evmDenom,
+			)
+		}
+	} else if _, err := getFeeConversion(ctx, avd.swapKeeper, avd.evmKeeper.GetParams(ctx), acc, msgEthTx); err != nil {
+		return ctx, err
 	}
 
 	return next(ctx, tx, simulate)
@@ -332,22 +343,26 @@ func (nvd NonceVerificationDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, sim
 // EthGasConsumeDecorator validates enough intrinsic gas for the transaction and
 // gas consumption.
 type EthGasConsumeDecorator struct {
-	ak        auth.AccountKeeper
-	sk        types.SupplyKeeper
-	evmKeeper EVMKeeper
+	ak         auth.AccountKeeper
+	sk         types.SupplyKeeper
+	evmKeeper  EVMKeeper
+	swapKeeper SwapKeeper
 }
 
 // NewEthGasConsumeDecorator creates a new EthGasConsumeDecorator
-func NewEthGasConsumeDecorator(ak auth.AccountKeeper, sk types.SupplyKeeper, ek EVMKeeper) EthGasConsumeDecorator {
+func NewEthGasConsumeDecorator(ak auth.AccountKeeper, sk types.SupplyKeeper, ek EVMKeeper, swk SwapKeeper) EthGasConsumeDecorator {
 	return EthGasConsumeDecorator{
-		ak:        ak,
-		sk:        sk,
-		evmKeeper: ek,
+		ak:         ak,
+		sk:         sk,
+		evmKeeper:  ek,
+		swapKeeper: swk,
 	}
 }
 
 // AnteHandle validates that the Ethereum tx message has enough to cover intrinsic gas
-// (during CheckTx only) and that the sender has enough balance to pay for the gas cost.
+// (during CheckTx only) and that the sender has enough balance to pay for the gas cost. The gas cost is
+// charged to the granter of the fee allowance of the sender if it may pay it. Otherwise the part of the
+// gas cost missing from the evm denom balance is converted from the fee denoms of the evm params.
 //
 // Intrinsic gas for a transaction is the amount of gas
 // that the transaction uses before the transaction is executed. The gas is a
@@ -397,10 +412,31 @@ func (egcd EthGasConsumeDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simula
 		evmDenom := sdk.DefaultBondDenom
 
 		feeAmt := sdk.NewCoins(
-			sdk.NewCoin(evmDenom, sdk.NewDecFromBigIntWithPrec(cost, sdk.Precision)), // int2dec
+			sdk.NewCoin(evmDenom, ethermint.WeiToDec(cost)), // int2dec
 		)
 
-		err = auth.DeductFees(egcd.sk, ctx, senderAcc, feeAmt)
+		payerAcc := senderAcc
+		if allowance, granterAcc := getFeeAllowance(ctx, egcd.evmKeeper, egcd.ak, address, msgEthTx); allowance != nil {
+			spendFeeAllowance(ctx, egcd.evmKeeper, *allowance, feeAmt.AmountOf(evmDenom))
+			// the unused gas of the tx is refunded to the granter and to the allowance
+			if !ctx.IsCheckTx() {
+				egcd.evmKeeper.SetTxFeeAllowance(ctx, address, *allowance)
+			}
+			payerAcc = granterAcc
+		} else {
+			conversion, err := getFeeConversion(ctx, egcd.swapKeeper, egcd.evmKeeper.GetParams(ctx), senderAcc, msgEthTx)
+			if err != nil {
+				return ctx, err
+			}
+			if conversion != nil {
+				if err = conversion.convert(ctx, egcd.swapKeeper, address); err != nil {
+					return ctx, err
+				}
+				payerAcc = egcd.ak.GetAccount(ctx, address)
+			}
+		}
+
+		err = auth.DeductFees(egcd.sk, ctx, payerAcc, feeAmt)
 		if err != nil {
 			return ctx, err
 		}
@@ -486,8 +522,14 @@ func (g GasLimitDecorator) AnteHandle(ctx sdk.Context, tx sdk.Tx, simulate bool,
 	if !ok {
 		return ctx, sdkerrors.Wrapf(sdkerrors.ErrUnknownRequest, "invalid transaction type: %T", tx)
 	}
-	if msgEthTx.GetGas() > g.evm.GetParams(ctx).MaxGasLimitPerTx {
-		return ctx, sdkerrors.Wrapf(sdkerrors.ErrTxTooLarge, "too large gas limit, it must be less than %d", g.evm.GetParams(ctx).MaxGasLimitPerTx)
+	params := g.evm.GetParams(ctx)
+	if msgEthTx.GetGas() > params.MaxGasLimitPerTx {
+		return ctx, sdkerrors.Wrapf(sdkerrors.ErrTxTooLarge, "too large gas limit, it must be less than %d", params.MaxGasLimitPerTx)
+	}
+
+	// the gas left in the block is checked by the block gas meter of the baseapp, in the order of the txs
+	if params.MaxGasLimitPerBlock != 0 && msgEthTx.GetGas() > params.MaxGasLimitPerBlock {
+		return ctx, sdkerrors.Wrapf(sdkerrors.ErrTxTooLarge, "too large gas limit, it must be less than the block gas limit %d", params.MaxGasLimitPerBlock)
 	}
 	return next(ctx, tx, simulate)
 }
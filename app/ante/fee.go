@@ -0,0 +1,133 @@
+package ante
+
+import (
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	sdkerrors "github.com/okex/exchain/libs/cosmos-sdk/types/errors"
+	"github.com/okex/exchain/libs/cosmos-sdk/x/auth"
+	"github.com/okex/exchain/libs/cosmos-sdk/x/auth/exported"
+
+	ethermint "github.com/okex/exchain/app/types"
+	ammswapkeeper "github.com/okex/exchain/x/ammswap/keeper"
+	ammswaptypes "github.com/okex/exchain/x/ammswap/types"
+	evmtypes "github.com/okex/exchain/x/evm/types"
+)
+
+// SwapKeeper defines the expected ammswap keeper, its pools convert the fee denoms into the evm denom
+type SwapKeeper interface {
+	GetSwapTokenPair(ctx sdk.Context, tokenPairName string) (ammswaptypes.SwapTokenPair, error)
+	SetSwapTokenPair(ctx sdk.Context, tokenPairName string, swapTokenPair ammswaptypes.SwapTokenPair)
+	SendCoinsToPool(ctx sdk.Context, coins sdk.SysCoins, addr sdk.AccAddress) error
+	SendCoinsFromPoolToAccount(ctx sdk.Context, coins sdk.SysCoins, addr sdk.AccAddress) error
+	OnSwapToken(ctx sdk.Context, address sdk.AccAddress, swapTokenPair ammswaptypes.SwapTokenPair, sellAmount sdk.SysCoin, buyAmount sdk.SysCoin)
+}
+
+// feeConversion is the swap of a fee denom into the evm denom missing from the balance of the sender to pay
+// the gas fee of an evm tx
+type feeConversion struct {
+	pair ammswaptypes.SwapTokenPair
+	sold sdk.SysCoin
+	buy  sdk.SysCoin
+}
+
+// getFeeConversion returns the conversion paying the part of the gas fee of the tx missing from the evm denom
+// balance of the sender, through the pool of the first fee denom of the params the sender can pay it with.
+// It's nil if the evm denom balance pays the tx cost. The tx value is never converted.
+func getFeeConversion(ctx sdk.Context, sk SwapKeeper, params evmtypes.Params, acc exported.Account,
+	msgEthTx evmtypes.MsgEthereumTx) (*feeConversion, error) {
+	evmDenom := sdk.DefaultBondDenom
+	balance := acc.GetCoins().AmountOf(evmDenom)
+	cost := ethermint.WeiToDec(msgEthTx.Cost())
+	if balance.GTE(cost) {
+		return nil, nil
+	}
+
+	missing := cost.Sub(balance)
+	if len(params.FeeDenoms) == 0 || missing.GT(ethermint.WeiToDec(msgEthTx.Fee())) {
+		return nil, sdkerrors.Wrapf(
+			sdkerrors.ErrInsufficientFunds,
+			"sender balance < tx gas cost (%s%s < %s%s)", balance.String(), evmDenom, cost.String(), evmDenom,
+		)
+	}
+
+	spread := params.FeeConversionSpread
+	if spread.IsNil() {
+		spread = sdk.ZeroDec()
+	}
+	for _, denom := range params.FeeDenoms {
+		pair, err := sk.GetSwapTokenPair(ctx, ammswaptypes.GetSwapTokenPairName(denom, evmDenom))
+		if err != nil {
+			continue
+		}
+		inputReserve, outputReserve := pair.BasePooledCoin.Amount, pair.QuotePooledCoin.Amount
+		if pair.BasePooledCoin.Denom == evmDenom {
+			inputReserve, outputReserve = outputReserve, inputReserve
+		}
+		if inputReserve.IsZero() || outputReserve.LTE(missing) {
+			continue
+		}
+
+		sold := sdk.NewDecCoinFromDec(denom, ammswapkeeper.GetOutputPrice(missing, inputReserve, outputReserve, spread))
+		if acc.GetCoins().AmountOf(denom).GTE(sold.Amount) {
+			return &feeConversion{pair: pair, sold: sold, buy: sdk.NewDecCoinFromDec(evmDenom, missing)}, nil
+		}
+	}
+
+	return nil, sdkerrors.Wrapf(
+		sdkerrors.ErrInsufficientFunds,
+		"sender balance < tx gas cost (%s%s < %s%s) and no fee denom of %v pays the %s%s missing",
+		balance.String(), evmDenom, cost.String(), evmDenom, params.FeeDenoms, missing.String(), evmDenom,
+	)
+}
+
+// convert swaps the fee denom of the conversion for the evm denom through its pool
+func (fc feeConversion) convert(ctx sdk.Context, sk SwapKeeper, address sdk.AccAddress) error {
+	if err := sk.SendCoinsToPool(ctx, sdk.SysCoins{fc.sold}, address); err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInsufficientFunds, "failed to convert fee denom: %s", err.Error())
+	}
+	if err := sk.SendCoinsFromPoolToAccount(ctx, sdk.SysCoins{fc.buy}, address); err != nil {
+		return sdkerrors.Wrapf(sdkerrors.ErrInsufficientFunds, "failed to convert fee denom: %s", err.Error())
+	}
+
+	pair := fc.pair
+	if pair.BasePooledCoin.Denom == fc.sold.Denom {
+		pair.BasePooledCoin = pair.BasePooledCoin.Add(fc.sold)
+		pair.QuotePooledCoin = pair.QuotePooledCoin.Sub(fc.buy)
+	} else {
+		pair.BasePooledCoin = pair.BasePooledCoin.Sub(fc.buy)
+		pair.QuotePooledCoin = pair.QuotePooledCoin.Add(fc.sold)
+	}
+	sk.SetSwapTokenPair(ctx, pair.TokenPairName(), pair)
+	sk.OnSwapToken(ctx, address, pair, fc.sold, fc.buy)
+	return nil
+}
+
+// getFeeAllowance returns the allowance of the sponsor paying the gas fee of the tx for the sender, along with
+// the account of its granter. It's nil if the sender has no allowance, or if it's expired, short of spend
+// limit or its granter can't pay the gas fee. The sender pays the tx value.
+func getFeeAllowance(ctx sdk.Context, ek EVMKeeper, ak auth.AccountKeeper, sender sdk.AccAddress,
+	msgEthTx evmtypes.MsgEthereumTx) (*evmtypes.FeeAllowance, exported.Account) {
+	allowance, found := ek.GetFeeAllowance(ctx, sender)
+	if !found || allowance.IsExpired(ctx.BlockHeight()) {
+		return nil, nil
+	}
+
+	fee := ethermint.WeiToDec(msgEthTx.Fee())
+	if !allowance.Covers(fee) {
+		return nil, nil
+	}
+
+	granterAcc := ak.GetAccount(ctx, allowance.Granter)
+	if granterAcc == nil || granterAcc.GetCoins().AmountOf(sdk.DefaultBondDenom).LT(fee) {
+		return nil, nil
+	}
+	return &allowance, granterAcc
+}
+
+// spendFeeAllowance charges the gas fee to the spend limit of the allowance, it's removed once used up
+func spendFeeAllowance(ctx sdk.Context, ek EVMKeeper, allowance evmtypes.FeeAllowance, fee sdk.Dec) {
+	if left, ok := allowance.Spend(fee); ok {
+		ek.SetFeeAllowance(ctx, left)
+	} else {
+		ek.DeleteFeeAllowance(ctx, allowance.Grantee)
+	}
+}
@@ -5,12 +5,15 @@ import (
 	"io"
 	"math/big"
 	"os"
+	"path/filepath"
 	"sync"
 
 	"github.com/okex/exchain/app/ante"
 	okexchaincodec "github.com/okex/exchain/app/codec"
 	appconfig "github.com/okex/exchain/app/config"
 	"github.com/okex/exchain/app/refund"
+	"github.com/okex/exchain/app/rpc/namespaces/dev"
+	rpctypes "github.com/okex/exchain/app/rpc/types"
 	okexchain "github.com/okex/exchain/app/types"
 	bam "github.com/okex/exchain/libs/cosmos-sdk/baseapp"
 	"github.com/okex/exchain/libs/cosmos-sdk/client/flags"
@@ -32,6 +35,7 @@ import (
 	"github.com/okex/exchain/libs/tendermint/crypto/tmhash"
 	"github.com/okex/exchain/libs/tendermint/libs/log"
 	tmos "github.com/okex/exchain/libs/tendermint/libs/os"
+	"github.com/okex/exchain/libs/tendermint/libs/tracing"
 	tendermintTypes "github.com/okex/exchain/libs/tendermint/types"
 	"github.com/okex/exchain/x/ammswap"
 	"github.com/okex/exchain/x/backend"
@@ -96,6 +100,9 @@ var (
 			evmclient.ManageContractDeploymentWhitelistProposalHandler,
 			evmclient.ManageContractBlockedListProposalHandler,
 			evmclient.ManageContractMethodBlockedListProposalHandler,
+			evmclient.ManageChainConfigForksProposalHandler,
+			evmclient.ManageTokenRegistryProposalHandler,
+			evmclient.ManageContractGasQuotaProposalHandler,
 		),
 		params.AppModuleBasic{},
 		crisis.AppModuleBasic{},
@@ -119,6 +126,8 @@ var (
 		auth.FeeCollectorName:     nil,
 		distr.ModuleName:          nil,
 		mint.ModuleName:           {supply.Minter},
+		mint.EcosystemFundName:    nil,
+		mint.FeeBurnerName:        {supply.Burner},
 		staking.BondedPoolName:    {supply.Burner, supply.Staking},
 		staking.NotBondedPoolName: {supply.Burner, supply.Staking},
 		gov.ModuleName:            nil,
@@ -184,6 +193,9 @@ type OKExChainApp struct {
 	sm *module.SimulationManager
 
 	blockGasPrice []*big.Int
+
+	// controller of the test apis, only set in the dev mode
+	dev *devController
 }
 
 // NewOKExChainApp returns a reference to a new initialized OKExChain application.
@@ -296,6 +308,7 @@ func NewOKExChainApp(
 		cdc, keys[distr.StoreKey], app.subspaces[distr.ModuleName], &stakingKeeper,
 		app.SupplyKeeper, auth.FeeCollectorName, app.ModuleAccountAddrs(),
 	)
+	app.MintKeeper.SetDistributionKeeper(app.DistrKeeper)
 	app.SlashingKeeper = slashing.NewKeeper(
 		cdc, keys[slashing.StoreKey], &stakingKeeper, app.subspaces[slashing.ModuleName],
 	)
@@ -305,6 +318,7 @@ func NewOKExChainApp(
 	app.UpgradeKeeper = upgrade.NewKeeper(skipUpgradeHeights, keys[upgrade.StoreKey], app.cdc)
 	app.EvmKeeper = evm.NewKeeper(
 		app.cdc, keys[evm.StoreKey], app.subspaces[evm.ModuleName], &app.AccountKeeper, app.SupplyKeeper, app.BankKeeper)
+	app.EvmKeeper.SetEvmMetrics(evmMetrics)
 
 	app.TokenKeeper = token.NewKeeper(app.BankKeeper, app.subspaces[token.ModuleName], auth.FeeCollectorName, app.SupplyKeeper,
 		keys[token.StoreKey], keys[token.KeyLock],
@@ -365,6 +379,8 @@ func NewOKExChainApp(
 	app.StakingKeeper = *stakingKeeper.SetHooks(
 		staking.NewMultiStakingHooks(app.DistrKeeper.Hooks(), app.SlashingKeeper.Hooks()),
 	)
+	app.EvmKeeper.SetStakingKeeper(app.StakingKeeper)
+	app.registerUpgrades()
 
 	// NOTE: Any module instantiated in the module manager that is later modified
 	// must be passed by reference here.
@@ -380,6 +396,7 @@ func NewOKExChainApp(
 		distr.NewAppModule(app.DistrKeeper, app.SupplyKeeper),
 		staking.NewAppModule(app.StakingKeeper, app.AccountKeeper, app.SupplyKeeper),
 		evidence.NewAppModule(app.EvidenceKeeper),
+		upgrade.NewAppModule(app.UpgradeKeeper),
 		evm.NewAppModule(app.EvmKeeper, &app.AccountKeeper),
 		token.NewAppModule(commonversion.ProtocolVersionV0, app.TokenKeeper, app.SupplyKeeper),
 		dex.NewAppModule(commonversion.ProtocolVersionV0, app.DexKeeper, app.SupplyKeeper),
@@ -395,6 +412,7 @@ func NewOKExChainApp(
 	// there is nothing left over in the validator fee pool, so as to keep the
 	// CanWithdrawInvariant invariant.
 	app.mm.SetOrderBeginBlockers(
+		upgrade.ModuleName,
 		stream.ModuleName,
 		order.ModuleName,
 		token.ModuleName,
@@ -429,6 +447,7 @@ func NewOKExChainApp(
 
 	app.mm.RegisterInvariants(&app.CrisisKeeper)
 	app.mm.RegisterRoutes(app.Router(), app.QueryRouter())
+	evmtypes.RegisterQueryService(app.GRPCQueryRouter(), evm.NewQueryServer(app.EvmKeeper))
 
 	// create the simulation manager and define the order of the modules for deterministic simulations
 	//
@@ -455,17 +474,35 @@ func NewOKExChainApp(
 	// initialize BaseApp
 	app.SetInitChainer(app.InitChainer)
 	app.SetBeginBlocker(app.BeginBlocker)
-	app.SetAnteHandler(ante.NewAnteHandler(app.AccountKeeper, app.EvmKeeper, app.SupplyKeeper, validateMsgHook(app.OrderKeeper)))
+	app.SetAnteHandler(ante.NewAnteHandler(app.AccountKeeper, app.EvmKeeper, app.SupplyKeeper, &app.SwapKeeper, validateMsgHook(app.OrderKeeper)))
 	app.SetEndBlocker(app.EndBlocker)
-	app.SetGasRefundHandler(refund.NewGasRefundHandler(app.AccountKeeper, app.SupplyKeeper))
+	app.SetGasRefundHandler(refund.NewGasRefundHandler(app.AccountKeeper, app.SupplyKeeper, app.EvmKeeper, app.EvmKeeper))
 	app.SetAccHandler(NewAccHandler(app.AccountKeeper))
+	app.SetBlockGasLimitHandler(evmBlockGasLimitHandler(app.EvmKeeper))
 	app.SetParallelTxHandlers(updateFeeCollectorHandler(app.BankKeeper, app.SupplyKeeper), evmTxFeeHandler(), fixLogForParallelTxHandler(app.EvmKeeper))
+	app.SetPreDeliverTxHandler(evmTxVerifySigHandler())
+	if evmtypes.IsPrefetchEnabled() {
+		app.SetPrefetchTxHandler(evmTxPrefetchHandler(app.EvmKeeper))
+	}
+	app.SetPreCheckTxHandler(evmTxPreCheckHandler())
+
+	if viper.GetBool(FlagDev) {
+		app.dev = newDevController(app)
+		dev.SetController(app.dev)
+		rpctypes.SetBlockTimeOffset(app.dev.blockTimeOffset)
+	}
 
 	if loadLatest {
 		err := app.LoadLatestVersion(app.keys[bam.MainStoreKey])
 		if err != nil {
 			tmos.Exit(err.Error())
 		}
+		// rebuild the watcher db and the bloom index left behind by a crash
+		if viper.GetBool(FlagEnableConsistencyCheck) {
+			if err := app.checkConsistency(filepath.Join(viper.GetString(flags.FlagHome), "data")); err != nil {
+				tmos.Exit(err.Error())
+			}
+		}
 	}
 
 	return app
@@ -480,6 +517,9 @@ func (app *OKExChainApp) Name() string { return app.BaseApp.Name() }
 
 // BeginBlocker updates every begin block
 func (app *OKExChainApp) BeginBlocker(ctx sdk.Context, req abci.RequestBeginBlock) abci.ResponseBeginBlock {
+	if app.dev != nil {
+		app.dev.beginBlock(ctx)
+	}
 	return app.mm.BeginBlock(ctx, req)
 }
 
@@ -613,21 +653,45 @@ func NewAccHandler(ak auth.AccountKeeper) sdk.AccHandler {
 	}
 }
 
+// evmBlockGasLimitHandler limits the gas of the blocks to the max_gas_limit_per_block evm param
+func evmBlockGasLimitHandler(ek *evm.Keeper) sdk.BlockGasLimitHandler {
+	return func(ctx sdk.Context) uint64 {
+		return ek.GetParams(ctx).MaxGasLimitPerBlock
+	}
+}
+
 func PreRun(ctx *server.Context) error {
 	// set the dynamic config
 	appconfig.RegisterDynamicConfig(ctx.Logger.With("module", "config"))
 
 	// set config by node mode
-	setNodeConfig(ctx)
+	SetNodeConfig(ctx)
+
+	// set config and genesis accounts by dev mode
+	if err := setDevConfig(ctx); err != nil {
+		return err
+	}
 
 	//download pprof
 	appconfig.PprofDownload(ctx)
 
 	// pruning options
-	_, err := server.GetPruningOptionsFromFlags()
+	pruningOpts, err := server.GetPruningOptionsFromFlags()
 	if err != nil {
 		return err
 	}
+	evmPruningOpts, evmPruning, err := evmtypes.GetPruningOptions()
+	if err != nil {
+		return err
+	}
+	if evmPruning {
+		if err := evmPruningOpts.ValidateWithin(pruningOpts); err != nil {
+			return fmt.Errorf("invalid evm pruning options: %w", err)
+		}
+	}
+	// tracing of the rpc queries
+	tracing.Init(viper.GetString(tracing.FlagOTLPEndpoint), "exchaind", viper.GetFloat64(tracing.FlagSampleRatio))
+
 	// repair state on start
 	if viper.GetBool(FlagEnableRepairState) {
 		repairStateOnStart(ctx)
@@ -16,6 +16,9 @@ func (app *OKExChainApp) BeginBlock(req abci.RequestBeginBlock) (res abci.Respon
 
 	// dump app.LastBlockHeight()-1 info for reactor sync mode
 	trace.GetElapsedInfo().Dump(app.Logger())
+	if app.dev != nil {
+		req.Header.Time = app.dev.blockTime(req.Header.Height, req.Header.Time)
+	}
 	return app.BaseApp.BeginBlock(req)
 }
 
@@ -27,7 +30,7 @@ func (app *OKExChainApp) DeliverTx(req abci.RequestDeliverTx) (res abci.Response
 	resp := app.BaseApp.DeliverTx(req)
 
 	if appconfig.GetOecConfig().GetEnableDynamicGp() {
-		tx, err := evm.TxDecoder(app.Codec())(req.Tx)
+		tx, err := evm.TxDecoder(app.Codec())(req.Tx, app.GetDeliverStateCtx().BlockHeight())
 		if err == nil {
 			//optimize get tx gas price can not get value from verifySign method
 			app.blockGasPrice = append(app.blockGasPrice, tx.GetGasPrice())
@@ -52,6 +55,9 @@ func (app *OKExChainApp) Commit(req abci.RequestCommit) abci.ResponseCommit {
 	analyzer.OnCommitEnter()
 	defer analyzer.OnCommitExit()
 	res := app.BaseApp.Commit(req)
+	if app.dev != nil {
+		app.dev.commit()
+	}
 
 	return res
 }
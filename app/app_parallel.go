@@ -2,6 +2,7 @@ package app
 
 import (
 	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	sdkerrors "github.com/okex/exchain/libs/cosmos-sdk/types/errors"
 	"github.com/okex/exchain/libs/cosmos-sdk/x/auth"
 	authante "github.com/okex/exchain/libs/cosmos-sdk/x/auth/ante"
 	"github.com/okex/exchain/libs/cosmos-sdk/x/bank"
@@ -33,6 +34,35 @@ func evmTxFeeHandler() sdk.GetTxFeeHandler {
 	}
 }
 
+// evmTxVerifySigHandler recovers the sender of evm tx ahead of delivering, to fill the sender cache
+func evmTxVerifySigHandler() sdk.PreDeliverTxHandler {
+	return func(height int64, tx sdk.Tx) {
+		if evmTx, ok := tx.(evmtypes.MsgEthereumTx); ok {
+			evmTx.VerifySig(evmTx.ChainID(), height, nil)
+		}
+	}
+}
+
+// evmTxPrefetchHandler warms the state read by the evm txs of a block concurrently with their delivery
+func evmTxPrefetchHandler(ek *evm.Keeper) sdk.PrefetchTxHandler {
+	return func(ctx sdk.Context, tx sdk.Tx) {
+		ek.PrefetchTx(ctx, tx)
+	}
+}
+
+// evmTxPreCheckHandler verifies the signature of evm tx out of the serialized CheckTx, the recovered sender
+// is cached for the ante handler
+func evmTxPreCheckHandler() sdk.PreCheckTxHandler {
+	return func(height int64, tx sdk.Tx) error {
+		if evmTx, ok := tx.(evmtypes.MsgEthereumTx); ok {
+			if _, err := evmTx.VerifySig(evmTx.ChainID(), height, nil); err != nil {
+				return sdkerrors.Wrapf(sdkerrors.ErrUnauthorized, "signature verification failed: %s", err.Error())
+			}
+		}
+		return nil
+	}
+}
+
 // fixLogForParallelTxHandler fix log for parallel tx
 func fixLogForParallelTxHandler(ek *evm.Keeper) sdk.LogFix {
 	return func(execResults [][]string) (logs [][]byte) {
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/okex/exchain/app/bootstrap"
+	"github.com/okex/exchain/libs/cosmos-sdk/server"
+	"github.com/okex/exchain/libs/tendermint/store"
+)
+
+// VerifyBootstrap checks the data extracted from a snapshot matches its manifest: the application state is the
+// one of the manifest height, and the block store holds the block of the trusted hash
+func VerifyBootstrap(ctx *server.Context, manifest *bootstrap.Manifest) error {
+	dataDir := ctx.Config.DBDir()
+	rmLockByDir(dataDir)
+
+	appHeight, err := latestAppHeight(dataDir)
+	if err != nil {
+		return err
+	}
+	if appHeight != manifest.Height {
+		return fmt.Errorf("the application state of the snapshot is at height %d, the manifest is at %d", appHeight, manifest.Height)
+	}
+
+	storeDB, err := openDB(blockStoreDB, dataDir)
+	if err != nil {
+		return err
+	}
+	defer storeDB.Close()
+	blockStore := store.NewBlockStore(storeDB)
+	if blockStore.Height() != manifest.Height {
+		return fmt.Errorf("the block store of the snapshot is at height %d, the manifest is at %d", blockStore.Height(), manifest.Height)
+	}
+	meta := blockStore.LoadBlockMeta(manifest.Height)
+	if meta == nil {
+		return fmt.Errorf("block %d not found in the snapshot", manifest.Height)
+	}
+	hash, _ := hex.DecodeString(manifest.BlockHash)
+	if !bytes.Equal(meta.BlockID.Hash, hash) {
+		return fmt.Errorf("the hash of block %d is %X, the manifest trusts %s", manifest.Height, meta.BlockID.Hash, manifest.BlockHash)
+	}
+	return nil
+}
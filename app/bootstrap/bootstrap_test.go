@@ -0,0 +1,117 @@
+package bootstrap
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/okex/exchain/libs/tendermint/crypto/ed25519"
+)
+
+func signManifest(t *testing.T, key ed25519.PrivKeyEd25519, manifest Manifest) []byte {
+	bz, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	sig, err := key.Sign(bz)
+	require.NoError(t, err)
+	signed, err := json.Marshal(SignedManifest{Manifest: bz, Signature: sig})
+	require.NoError(t, err)
+	return signed
+}
+
+func TestVerifyManifest(t *testing.T) {
+	key := ed25519.GenPrivKey()
+	pk := key.PubKey().(ed25519.PubKeyEd25519)
+	pubKey := hex.EncodeToString(pk[:])
+	file := ManifestFile{Name: "data.tar.gz", Type: FileTypeArchive, Size: 1, SHA256: hex.EncodeToString(make([]byte, 32))}
+	manifest := Manifest{ChainID: "exchain-65", Height: 10, BlockHash: "AB", Files: []ManifestFile{file}}
+
+	verified, err := VerifyManifest(signManifest(t, key, manifest), pubKey)
+	require.NoError(t, err)
+	require.Equal(t, manifest, *verified)
+
+	// signed by another key
+	_, err = VerifyManifest(signManifest(t, ed25519.GenPrivKey(), manifest), pubKey)
+	require.Error(t, err)
+
+	// tampered after signing
+	signed := signManifest(t, key, manifest)
+	_, err = VerifyManifest(bytes.Replace(signed, []byte("exchain-65"), []byte("exchain-66"), 1), pubKey)
+	require.Error(t, err)
+
+	// the files must stay in the download directory
+	for _, name := range []string{"../data.tar.gz", "dir/data.tar.gz", ".", ""} {
+		manifest.Files[0].Name = name
+		_, err = VerifyManifest(signManifest(t, key, manifest), pubKey)
+		require.Error(t, err, name)
+	}
+}
+
+func TestDownload(t *testing.T) {
+	content := []byte("snapshot")
+	sum := sha256.Sum256(content)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	}))
+	defer server.Close()
+	dir, err := ioutil.TempDir("", "bootstrap")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "file")
+	file := ManifestFile{Name: "file", Size: int64(len(content)), SHA256: hex.EncodeToString(sum[:])}
+	require.NoError(t, Download(context.Background(), server.URL, path, file))
+	bz, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, content, bz)
+
+	// the file is removed if it doesn't match the manifest
+	file.SHA256 = hex.EncodeToString(make([]byte, 32))
+	require.Error(t, Download(context.Background(), server.URL, path, file))
+	_, err = os.Stat(path)
+	require.True(t, os.IsNotExist(err))
+}
+
+func writeArchive(t *testing.T, path string, entries map[string]string) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	for name, content := range entries {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, zw.Close())
+	require.NoError(t, ioutil.WriteFile(path, buf.Bytes(), 0644))
+}
+
+func TestExtractArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bootstrap")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	dataDir := filepath.Join(dir, "data")
+
+	archive := filepath.Join(dir, "data.tar.gz")
+	writeArchive(t, archive, map[string]string{"application.db/000001.ldb": "app"})
+	require.NoError(t, ExtractArchive(archive, dataDir))
+	bz, err := ioutil.ReadFile(filepath.Join(dataDir, "application.db", "000001.ldb"))
+	require.NoError(t, err)
+	require.Equal(t, "app", string(bz))
+
+	writeArchive(t, archive, map[string]string{"../escaped": "escaped"})
+	require.Error(t, ExtractArchive(archive, dataDir))
+	_, err = os.Stat(filepath.Join(dir, "escaped"))
+	require.True(t, os.IsNotExist(err))
+}
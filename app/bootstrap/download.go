@@ -0,0 +1,103 @@
+package bootstrap
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+// progressInterval is the minimum interval between two progress logs
+const progressInterval = 10 * time.Second
+
+// Fetch returns the content of the url, bounded to limit bytes
+func Fetch(ctx context.Context, url string, limit int64) ([]byte, error) {
+	body, err := get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return ioutil.ReadAll(io.LimitReader(body, limit))
+}
+
+// Download writes the file at the url into path and checks its size and sha256, the file is removed if they
+// don't match
+func Download(ctx context.Context, url, path string, file ManifestFile) (err error) {
+	body, err := get(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(path)
+		}
+	}()
+
+	h := sha256.New()
+	pw := &progressWriter{name: file.Name, total: file.Size, last: time.Now()}
+	// read one more byte than expected to detect the larger files
+	n, err := io.Copy(io.MultiWriter(f, h, pw), io.LimitReader(body, file.Size+1))
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", file.Name, err)
+	}
+	if n != file.Size {
+		return fmt.Errorf("size of %s is %d, expected %d", file.Name, n, file.Size)
+	}
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != file.SHA256 {
+		return fmt.Errorf("sha256 of %s is %s, expected %s", file.Name, sum, file.SHA256)
+	}
+	log.Printf("Downloaded and verified %s, %d bytes\n", file.Name, n)
+	return f.Sync()
+}
+
+func get(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to get %s: %s", url, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// progressWriter logs the progress of a transfer
+type progressWriter struct {
+	name    string
+	total   int64
+	written int64
+	last    time.Time
+}
+
+func (pw *progressWriter) Write(p []byte) (int, error) {
+	pw.written += int64(len(p))
+	if now := time.Now(); now.Sub(pw.last) >= progressInterval {
+		pw.last = now
+		if pw.total > 0 {
+			log.Printf("%s: %d/%d bytes (%.1f%%)\n", pw.name, pw.written, pw.total, float64(pw.written)*100/float64(pw.total))
+		} else {
+			log.Printf("%s: %d bytes\n", pw.name, pw.written)
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,70 @@
+package bootstrap
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ExtractArchive unpacks the tar.gz archive into dir, the entries must not escape it
+func ExtractArchive(archive, dir string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	pw := &progressWriter{name: filepath.Base(archive), last: time.Now()}
+	tr := tar.NewReader(zr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(dir, header.Name)
+		if path != filepath.Clean(dir) && !strings.HasPrefix(path, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("the entry %s of %s escapes the data directory", header.Name, archive)
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := extractFile(tr, path, header, pw); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported entry %s of %s", header.Name, archive)
+		}
+	}
+}
+
+func extractFile(r io.Reader, path string, header *tar.Header, pw *progressWriter) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, os.FileMode(header.Mode)&0644|0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(io.MultiWriter(f, pw), io.LimitReader(r, header.Size)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
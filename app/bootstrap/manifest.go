@@ -0,0 +1,104 @@
+package bootstrap
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/okex/exchain/libs/tendermint/crypto/ed25519"
+)
+
+const (
+	// FileTypeArchive is a tar.gz archive extracted into the data directory
+	FileTypeArchive = "tar.gz"
+	// FileTypeWatcherSnapshot is a snapshot of the watcher db, see watcher.CreateSnapshot
+	FileTypeWatcherSnapshot = "watcher-snapshot"
+
+	ManifestFileName = "manifest.json"
+)
+
+// Manifest describes a published snapshot of the data of a node at a height.
+type Manifest struct {
+	ChainID string `json:"chain_id"`
+	Height  int64  `json:"height"`
+	// BlockHash is the hex hash of the block at the height, the trusted hash of the snapshot
+	BlockHash string         `json:"block_hash"`
+	Files     []ManifestFile `json:"files"`
+}
+
+// ManifestFile is a file of the snapshot, relative to the url of the manifest.
+type ManifestFile struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// SignedManifest is the published manifest, signed by the ed25519 key of the publisher. The signature is the
+// one of the exact bytes of the manifest.
+type SignedManifest struct {
+	Manifest  json.RawMessage `json:"manifest"`
+	Signature []byte          `json:"signature"`
+}
+
+// VerifyManifest checks the signature of the manifest against the hex ed25519 public key of the publisher and
+// returns the manifest
+func VerifyManifest(bz []byte, publisherKey string) (*Manifest, error) {
+	keyBytes, err := hex.DecodeString(publisherKey)
+	if err != nil || len(keyBytes) != ed25519.PubKeyEd25519Size {
+		return nil, fmt.Errorf("invalid ed25519 public key %q", publisherKey)
+	}
+	var pubKey ed25519.PubKeyEd25519
+	copy(pubKey[:], keyBytes)
+
+	var signed SignedManifest
+	if err := json.Unmarshal(bz, &signed); err != nil {
+		return nil, fmt.Errorf("invalid signed manifest: %w", err)
+	}
+	if !pubKey.VerifyBytes(signed.Manifest, signed.Signature) {
+		return nil, errors.New("invalid signature of the manifest")
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(signed.Manifest, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if err := manifest.ValidateBasic(); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// ValidateBasic checks the manifest is well-formed
+func (m Manifest) ValidateBasic() error {
+	if m.Height <= 0 {
+		return fmt.Errorf("invalid height %d", m.Height)
+	}
+	if _, err := hex.DecodeString(m.BlockHash); err != nil || m.BlockHash == "" {
+		return fmt.Errorf("invalid block hash %q", m.BlockHash)
+	}
+	if len(m.Files) == 0 {
+		return errors.New("the manifest has no files")
+	}
+	names := make(map[string]bool)
+	for _, f := range m.Files {
+		// the files are downloaded next to each other, they must not escape the download directory
+		if f.Name == "" || f.Name != filepath.Base(f.Name) || strings.HasPrefix(f.Name, ".") || names[f.Name] {
+			return fmt.Errorf("invalid file name %q", f.Name)
+		}
+		names[f.Name] = true
+		if f.Type != FileTypeArchive && f.Type != FileTypeWatcherSnapshot {
+			return fmt.Errorf("unknown type %q of file %s", f.Type, f.Name)
+		}
+		if sum, err := hex.DecodeString(f.SHA256); err != nil || len(sum) != 32 {
+			return fmt.Errorf("invalid sha256 of file %s", f.Name)
+		}
+		if f.Size < 0 {
+			return fmt.Errorf("invalid size of file %s", f.Name)
+		}
+	}
+	return nil
+}
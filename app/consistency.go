@@ -0,0 +1,227 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/okex/exchain/libs/cosmos-sdk/store/rootmulti"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	abci "github.com/okex/exchain/libs/tendermint/abci/types"
+	sm "github.com/okex/exchain/libs/tendermint/state"
+	"github.com/okex/exchain/libs/tendermint/store"
+	"github.com/okex/exchain/libs/tendermint/types"
+	evmtypes "github.com/okex/exchain/x/evm/types"
+	"github.com/okex/exchain/x/evm/watcher"
+	"github.com/spf13/viper"
+	dbm "github.com/tendermint/tm-db"
+)
+
+const (
+	FlagEnableConsistencyCheck    = "enable-consistency-check"
+	FlagConsistencyCheckMaxBlocks = "consistency-check-max-blocks"
+)
+
+// blockSource provides the blocks committed by the chain and the results of their txs
+type blockSource interface {
+	Height() int64
+	LoadBlock(height int64) *types.Block
+	LoadABCIResponses(height int64) (*sm.ABCIResponses, error)
+}
+
+// storeBlockSource reads the blocks from the block store and their results from the tendermint state
+type storeBlockSource struct {
+	*store.BlockStore
+	stateDB dbm.DB
+}
+
+func (s storeBlockSource) LoadABCIResponses(height int64) (*sm.ABCIResponses, error) {
+	return sm.LoadABCIResponses(s.stateDB, height)
+}
+
+// checkConsistency checks that the watcher db and the bloom index agree with the application state after a crash,
+// see repairIndices
+func (app *OKExChainApp) checkConsistency(dataDir string) error {
+	storeDB, err := openDB(blockStoreDB, dataDir)
+	if err != nil {
+		return err
+	}
+	defer storeDB.Close()
+	stateStoreDB, err := openDB(stateDB, dataDir)
+	if err != nil {
+		return err
+	}
+	defer stateStoreDB.Close()
+
+	var watchDB dbm.DB
+	if watcher.IsWatcherEnabled() {
+		watchDB = watcher.InstanceOfWatchStore().GetDB()
+	}
+	return app.repairIndices(storeBlockSource{store.NewBlockStore(storeDB), stateStoreDB}, watchDB,
+		evmtypes.GetIndexer(), viper.GetInt64(FlagConsistencyCheckMaxBlocks))
+}
+
+// repairIndices brings the watcher db and the bloom index in step with the application state, rather than serving
+// inconsistent rpc results:
+//   - a watcher db behind the application state is rebuilt from the blocks of the block store and their results,
+//     the bloom sections behind it are indexed from the blooms and the hashes of the blocks kept by the evm module;
+//   - a watcher db ahead of the application state is rolled back to it, and the bloom sections ahead of it are
+//     invalidated, the blocks are indexed again once delivered.
+//
+// Neither is rebuilt over more than maxBlocks blocks, the start fails instead. A block store ahead of the
+// application state is replayed by the handshake with tendermint, which indexes its blocks.
+func (app *OKExChainApp) repairIndices(blocks blockSource, watchDB dbm.DB, indexer *evmtypes.Indexer, maxBlocks int64) error {
+	appHeight, storeHeight := app.LastBlockHeight(), blocks.Height()
+	if appHeight > storeHeight {
+		return fmt.Errorf("the application state at height %d is ahead of the block store at height %d",
+			appHeight, storeHeight)
+	}
+	if storeHeight > appHeight {
+		app.Logger().Info("Consistency check: the blocks of the block store ahead of the application state are indexed once replayed",
+			"app", appHeight, "store", storeHeight)
+	}
+
+	if watchDB != nil {
+		if err := app.repairWatcher(blocks, watchDB, appHeight, maxBlocks); err != nil {
+			return fmt.Errorf("failed to repair the watcher db: %w", err)
+		}
+	}
+	if indexer != nil {
+		ctx := app.NewContext(true, abci.Header{Height: appHeight})
+		if err := repairBloomIndex(ctx, indexer, app.EvmKeeper, appHeight, maxBlocks); err != nil {
+			return fmt.Errorf("failed to repair the bloom index: %w", err)
+		}
+	}
+	return nil
+}
+
+func (app *OKExChainApp) repairWatcher(blocks blockSource, db dbm.DB, appHeight, maxBlocks int64) error {
+	watchHeight, err := watcher.GetLatestHeight(db)
+	if err != nil {
+		// a new watcher db is filled from the next block
+		app.Logger().Info("Consistency check: skip the watcher db", "err", err)
+		return nil
+	}
+
+	switch {
+	case watchHeight > uint64(appHeight):
+		removed, err := watcher.Rollback(db, uint64(appHeight))
+		if err != nil {
+			return err
+		}
+		app.Logger().Info("Consistency check: removed the blocks of the watcher db ahead of the application state",
+			"blocks", removed, "height", appHeight)
+	case watchHeight < uint64(appHeight):
+		if behind := appHeight - int64(watchHeight); behind > maxBlocks {
+			return fmt.Errorf("the watcher db at height %d is %d blocks behind the application state, more than the %d blocks of --%s, remove it to start a new one",
+				watchHeight, behind, maxBlocks, FlagConsistencyCheckMaxBlocks)
+		}
+		load := func(height uint64) (*watcher.RebuiltBlock, error) {
+			return app.rebuiltBlock(blocks, int64(height))
+		}
+		if err := watcher.Rebuild(db, uint64(appHeight), load); err != nil {
+			return err
+		}
+		app.Logger().Info("Consistency check: rebuilt the blocks of the watcher db behind the application state",
+			"from", watchHeight+1, "to", appHeight)
+	}
+	return nil
+}
+
+// rebuiltBlock returns the block at the height with the results of its evm txs. The gas limit, the base fee and the
+// miner are the ones of the latest application state, at the header of the block. The evm txs failing in the ante
+// handler, which the evm module doesn't index, are indexed as failed txs.
+func (app *OKExChainApp) rebuiltBlock(blocks blockSource, height int64) (*watcher.RebuiltBlock, error) {
+	block := blocks.LoadBlock(height)
+	if block == nil {
+		return nil, fmt.Errorf("block %d not found", height)
+	}
+	responses, err := blocks.LoadABCIResponses(height)
+	if err != nil {
+		return nil, err
+	}
+	if len(responses.DeliverTxs) != len(block.Txs) {
+		return nil, fmt.Errorf("block %d has %d txs and %d results", height, len(block.Txs), len(responses.DeliverTxs))
+	}
+
+	header := types.TM2PB.Header(&block.Header)
+	ctx := app.NewContext(true, header)
+	rebuilt := &watcher.RebuiltBlock{
+		Header:   header,
+		Hash:     common.BytesToHash(block.Hash()),
+		GasLimit: app.EvmKeeper.GetParams(ctx).BlockGasLimit(),
+		BaseFee:  app.EvmKeeper.GetBaseFee(ctx),
+		Miner:    app.EvmKeeper.GetCoinbase(ctx),
+	}
+	decode := evmtypes.TxDecoder(app.Codec())
+	for i, txBytes := range block.Txs {
+		tx, err := decode(txBytes, block.Height)
+		if err != nil {
+			continue
+		}
+		msg, ok := tx.(evmtypes.MsgEthereumTx)
+		if !ok {
+			continue
+		}
+		// the sender of the tx is recovered once, like on delivery
+		if _, err := msg.VerifySig(msg.ChainID(), height, nil); err != nil {
+			continue
+		}
+
+		res := responses.DeliverTxs[i]
+		rebuiltTx := watcher.RebuiltTx{
+			Msg:     msg,
+			Hash:    common.BytesToHash(txBytes.Hash()),
+			Failed:  res.Code != abci.CodeTypeOK,
+			GasUsed: uint64(res.GasUsed),
+		}
+		if !rebuiltTx.Failed {
+			if rebuiltTx.Data, err = evmtypes.DecodeResultData(res.Data); err != nil {
+				return nil, fmt.Errorf("invalid result of tx %s at height %d: %w", rebuiltTx.Hash, height, err)
+			}
+		}
+		rebuilt.Txs = append(rebuilt.Txs, rebuiltTx)
+	}
+	return rebuilt, nil
+}
+
+// repairBloomIndex indexes the bloom sections behind the application state from the blooms and the hashes of the
+// blocks kept by the evm module, and invalidates the ones ahead of it
+func repairBloomIndex(ctx sdk.Context, indexer *evmtypes.Indexer, k evmtypes.Keeper, appHeight, maxBlocks int64) error {
+	sections := uint64(0)
+	if appHeight > types.GetStartBlockHeight() {
+		sections = uint64(appHeight-types.GetStartBlockHeight()) / evmtypes.BloomBitsBlocks
+	}
+	stored := indexer.GetValidSections()
+
+	switch {
+	case stored > sections:
+		if _, err := indexer.Rollback(appHeight); err != nil {
+			return err
+		}
+		ctx.Logger().Info("Consistency check: invalidated the bloom sections ahead of the application state",
+			"sections", stored-sections, "height", appHeight)
+	case stored < sections:
+		if behind := (sections - stored) * evmtypes.BloomBitsBlocks; behind > uint64(maxBlocks) {
+			return fmt.Errorf("the bloom index of %d sections is %d blocks behind the application state, more than the %d blocks of --%s",
+				stored, behind, maxBlocks, FlagConsistencyCheckMaxBlocks)
+		}
+		var bloomData []*evmtypes.KV
+		indexer.ProcessSection(ctx, k, uint64(appHeight-types.GetStartBlockHeight()), &bloomData)
+		if valid := indexer.GetValidSections(); valid < sections {
+			return fmt.Errorf("indexed %d of the %d sections at height %d", valid, sections, appHeight)
+		}
+		ctx.Logger().Info("Consistency check: indexed the bloom sections behind the application state",
+			"from", stored, "to", sections-1)
+	}
+	return nil
+}
+
+// latestAppHeight returns the version of the latest commit of the application state
+func latestAppHeight(dataDir string) (int64, error) {
+	db, err := openDB(applicationDB, dataDir)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+	return rootmulti.NewStore(db).GetLatestVersion(), nil
+}
@@ -0,0 +1,242 @@
+package app
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	abci "github.com/okex/exchain/libs/tendermint/abci/types"
+	"github.com/okex/exchain/libs/tendermint/libs/log"
+	sm "github.com/okex/exchain/libs/tendermint/state"
+	"github.com/okex/exchain/libs/tendermint/types"
+	evmtypes "github.com/okex/exchain/x/evm/types"
+	"github.com/okex/exchain/x/evm/watcher"
+)
+
+// testChain is a chain of blocks with an evm tx calling a contract and a failed evm tx each, the app commits the
+// first blocks of the chain
+type testChain struct {
+	app       *OKExChainApp
+	blocks    map[int64]*types.Block
+	msgs      map[int64][]evmtypes.MsgEthereumTx
+	responses map[int64]*sm.ABCIResponses
+	height    int64
+	contract  ethcmn.Address
+	sender    ethcmn.Address
+}
+
+func (c *testChain) Height() int64 { return c.height }
+
+func (c *testChain) LoadBlock(height int64) *types.Block { return c.blocks[height] }
+
+func (c *testChain) LoadABCIResponses(height int64) (*sm.ABCIResponses, error) {
+	responses, ok := c.responses[height]
+	if !ok {
+		return nil, sm.ErrNoABCIResponsesForHeight{Height: height}
+	}
+	return responses, nil
+}
+
+// txHash returns the hash of the tx at the index of the block
+func (c *testChain) txHash(height int64, index int) ethcmn.Hash {
+	return ethcmn.BytesToHash(c.blocks[height].Txs[index].Hash())
+}
+
+func newTestChain(t *testing.T, appHeight, height int64) *testChain {
+	key, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	c := &testChain{
+		app:       Setup(false),
+		blocks:    make(map[int64]*types.Block),
+		msgs:      make(map[int64][]evmtypes.MsgEthereumTx),
+		responses: make(map[int64]*sm.ABCIResponses),
+		height:    height,
+		contract:  ethcmn.HexToAddress("0xc0"),
+		sender:    ethcrypto.PubkeyToAddress(key.PublicKey),
+	}
+
+	var lastBlockID types.BlockID
+	for h := int64(1); h <= height; h++ {
+		var txs types.Txs
+		for nonce := uint64(0); nonce < 2; nonce++ {
+			msg := evmtypes.NewMsgEthereumTx(uint64(h)*2+nonce, &c.contract, big.NewInt(0), 100000, big.NewInt(1), nil)
+			require.NoError(t, msg.Sign(big.NewInt(65), key))
+			txs = append(txs, c.app.Codec().MustMarshalBinaryLengthPrefixed(msg))
+			c.msgs[h] = append(c.msgs[h], msg)
+		}
+		block := &types.Block{
+			Header: types.Header{ChainID: "exchain-65", Height: h, Time: time.Unix(1000+h, 0), LastBlockID: lastBlockID},
+			Data:   types.Data{Txs: txs},
+			// a stand-in for the signatures of the previous block
+			LastCommit: &types.Commit{Height: h - 1},
+		}
+		lastBlockID = types.BlockID{Hash: block.Hash()}
+		c.blocks[h] = block
+
+		logs := []*ethtypes.Log{{Address: c.contract, Topics: []ethcmn.Hash{{0x01}}}}
+		data, err := evmtypes.EncodeResultData(evmtypes.ResultData{
+			Bloom:  ethtypes.BytesToBloom(ethtypes.LogsBloom(logs)),
+			Logs:   logs,
+			TxHash: c.txHash(h, 0),
+		})
+		require.NoError(t, err)
+		c.responses[h] = &sm.ABCIResponses{DeliverTxs: []*abci.ResponseDeliverTx{
+			{Data: data, GasUsed: 30000},
+			{Code: 7, Log: "execution reverted", GasUsed: 25000},
+		}}
+
+		if h <= appHeight {
+			c.app.BeginBlock(abci.RequestBeginBlock{Hash: block.Hash(), Header: types.TM2PB.Header(&block.Header)})
+			c.app.EndBlock(abci.RequestEndBlock{Height: h})
+			c.app.Commit(abci.RequestCommit{})
+		}
+	}
+	return c
+}
+
+// newWatchDB returns a watcher db written up to the height
+func newWatchDB(t *testing.T, height uint64) dbm.DB {
+	db := dbm.NewMemDB()
+	latest := watcher.NewMsgLatestHeight(height)
+	require.NoError(t, db.Set(latest.GetKey(), []byte(latest.GetValue())))
+	return db
+}
+
+func watchHeight(t *testing.T, db dbm.DB) uint64 {
+	height, err := watcher.GetLatestHeight(db)
+	require.NoError(t, err)
+	return height
+}
+
+// requireWatchedBlock checks the block, its txs and their receipts in the watcher db
+func requireWatchedBlock(t *testing.T, c *testChain, db dbm.DB, height int64) {
+	hash := ethcmn.BytesToHash(c.blocks[height].Hash())
+	bz, err := db.Get(watcher.NewMsgBlockInfo(uint64(height), hash).GetKey())
+	require.NoError(t, err)
+	require.Equal(t, hash.Hex(), string(bz), height)
+
+	for i, status := range []uint32{watcher.TransactionSuccess, watcher.TransactionFailed} {
+		txHash := c.txHash(height, i)
+		msg := c.msgs[height][i]
+		ok, err := db.Has(watcher.NewMsgEthTx(&msg, txHash, hash, uint64(height), uint64(i)).GetKey())
+		require.NoError(t, err)
+		require.True(t, ok, "tx %d of block %d", i, height)
+
+		bz, err := db.Get(watcher.NewMsgTransactionReceipt(status, &msg, txHash, hash, uint64(i), uint64(height), nil, 0, 0).GetKey())
+		require.NoError(t, err)
+		var receipt watcher.TransactionReceipt
+		require.NoError(t, json.Unmarshal(bz, &receipt))
+		require.Equal(t, uint64(status), uint64(receipt.Status))
+		require.Equal(t, c.sender.Hex(), receipt.From)
+		require.Equal(t, []uint64{30000, 55000}[i], uint64(receipt.CumulativeGasUsed))
+		if status == watcher.TransactionSuccess {
+			require.Len(t, receipt.Logs, 1)
+			require.Equal(t, c.contract, receipt.Logs[0].Address)
+		} else {
+			require.Empty(t, receipt.Logs)
+		}
+	}
+}
+
+func TestRepairIndicesWatcherBehind(t *testing.T) {
+	c := newTestChain(t, 5, 5)
+	db := newWatchDB(t, 2)
+
+	// the blocks behind the application state are rebuilt, up to the limit
+	require.Error(t, c.app.repairIndices(c, db, nil, 2))
+	require.Equal(t, uint64(2), watchHeight(t, db))
+	require.NoError(t, c.app.repairIndices(c, db, nil, 3))
+	require.Equal(t, uint64(5), watchHeight(t, db))
+	for height := int64(3); height <= 5; height++ {
+		requireWatchedBlock(t, c, db, height)
+	}
+	ok, err := db.Has(watcher.NewMsgBlockInfo(2, ethcmn.Hash{}).GetKey())
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// the results of the blocks are needed to rebuild them
+	db = newWatchDB(t, 2)
+	delete(c.responses, 4)
+	require.Error(t, c.app.repairIndices(c, db, nil, 3))
+	require.Equal(t, uint64(3), watchHeight(t, db))
+}
+
+func TestRepairIndicesWatcherAhead(t *testing.T) {
+	c := newTestChain(t, 5, 5)
+	db := newWatchDB(t, 8)
+
+	require.NoError(t, c.app.repairIndices(c, db, nil, 3))
+	require.Equal(t, uint64(5), watchHeight(t, db))
+
+	// a new watcher db is filled from the next block
+	db = dbm.NewMemDB()
+	require.NoError(t, c.app.repairIndices(c, db, nil, 3))
+	_, err := watcher.GetLatestHeight(db)
+	require.Error(t, err)
+}
+
+func TestRepairIndicesBlockStoreAhead(t *testing.T) {
+	c := newTestChain(t, 5, 7)
+	db := newWatchDB(t, 2)
+
+	// the blocks ahead of the application state are indexed once the handshake replays them
+	require.NoError(t, c.app.repairIndices(c, db, nil, 10))
+	require.Equal(t, uint64(5), watchHeight(t, db))
+	requireWatchedBlock(t, c, db, 5)
+	ok, err := db.Has(watcher.NewMsgBlockInfo(6, ethcmn.Hash{}).GetKey())
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// the application state can't be ahead of the block store
+	c.height = 4
+	require.Error(t, c.app.repairIndices(c, db, nil, 10))
+}
+
+// bloomKeeper keeps the blooms and the hashes of the blocks up to its height
+type bloomKeeper struct {
+	height uint64
+}
+
+func (k bloomKeeper) GetBlockBloom(_ sdk.Context, height int64) ethtypes.Bloom {
+	return ethtypes.BytesToBloom(ethtypes.LogsBloom([]*ethtypes.Log{{Address: ethcmn.BigToAddress(big.NewInt(height))}}))
+}
+
+func (k bloomKeeper) GetHeightHash(_ sdk.Context, height uint64) ethcmn.Hash {
+	if height > k.height {
+		return ethcmn.Hash{}
+	}
+	return ethcmn.BigToHash(new(big.Int).SetUint64(height))
+}
+
+func TestRepairBloomIndex(t *testing.T) {
+	ctx := sdk.Context{}.WithLogger(log.NewNopLogger())
+	indexer := evmtypes.NewIndexer(dbm.NewMemDB())
+	sectionBlocks := int64(evmtypes.BloomBitsBlocks)
+	height := 2*sectionBlocks + 10
+	k := bloomKeeper{height: uint64(height)}
+
+	// the sections behind the application state are indexed, up to the limit
+	require.Error(t, repairBloomIndex(ctx, indexer, k, height, sectionBlocks))
+	require.Zero(t, indexer.GetValidSections())
+	require.NoError(t, repairBloomIndex(ctx, indexer, k, height, 2*sectionBlocks))
+	require.Equal(t, uint64(2), indexer.GetValidSections())
+	require.Equal(t, uint64(2), indexer.StoredSection())
+
+	// the sections ahead of it are invalidated
+	require.NoError(t, repairBloomIndex(ctx, indexer, k, sectionBlocks+10, 2*sectionBlocks))
+	require.Equal(t, uint64(1), indexer.GetValidSections())
+	require.Equal(t, uint64(1), indexer.StoredSection())
+
+	// the blocks must be known to the evm module
+	k.height = uint64(sectionBlocks + 10)
+	require.Error(t, repairBloomIndex(ctx, indexer, k, height, 2*sectionBlocks))
+	require.Equal(t, uint64(1), indexer.GetValidSections())
+}
@@ -0,0 +1,319 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+
+	bam "github.com/okex/exchain/libs/cosmos-sdk/baseapp"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	"github.com/okex/exchain/libs/cosmos-sdk/x/auth"
+	"github.com/okex/exchain/libs/cosmos-sdk/x/supply"
+	distr "github.com/okex/exchain/x/distribution"
+	"github.com/okex/exchain/x/evm"
+	evmtypes "github.com/okex/exchain/x/evm/types"
+)
+
+// devController implements the test apis of the dev mode against the app. The state changes are queued until the
+// next block, they are applied at its beginning and are visible once it is committed. The time of the chain is
+// moved by an offset added to the time of the blocks.
+type devController struct {
+	app *OKExChainApp
+
+	mtx           sync.Mutex
+	pending       []*devOp
+	timeOffset    int64 // in seconds, a duration would overflow for far timestamps
+	nextTimestamp *int64
+	lastTimestamp int64
+	offsets       []devTimeOffset
+	snapshots     []devSnapshot
+	snapshotID    uint64
+
+	// the ops applied in the block being executed, only accessed by the abci calls
+	applied []*devOp
+}
+
+type devOp struct {
+	apply func(ctx sdk.Context) error
+	err   error
+	done  chan error
+}
+
+// devTimeOffset is the time offset of the blocks from height on
+type devTimeOffset struct {
+	height int64
+	offset int64
+}
+
+type devSnapshot struct {
+	id            uint64
+	height        int64
+	timeOffset    int64
+	lastTimestamp int64
+}
+
+// devRestoredStores are the stores restored by a revert, by the prefixes of their restored keys. The evm store keeps
+// the hashes and blooms of the blocks sealed since the snapshot.
+var devRestoredStores = []struct {
+	name     string
+	prefixes [][]byte
+}{
+	{auth.StoreKey, nil},
+	{supply.StoreKey, nil},
+	{distr.StoreKey, nil},
+	{evm.StoreKey, [][]byte{evmtypes.KeyPrefixCode, evmtypes.KeyPrefixStorage}},
+}
+
+func newDevController(app *OKExChainApp) *devController {
+	return &devController{app: app}
+}
+
+// Mine seals a new block
+func (c *devController) Mine(ctx context.Context) error {
+	return c.do(ctx, nil)
+}
+
+// IncreaseTime moves the time of the next blocks forward, it returns the total offset of the chain time
+func (c *devController) IncreaseTime(seconds int64) int64 {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.timeOffset += seconds
+	if c.nextTimestamp != nil {
+		next := *c.nextTimestamp + seconds
+		c.nextTimestamp = &next
+	}
+	return c.timeOffset
+}
+
+// SetNextBlockTimestamp sets the timestamp of the next block, it must be after the timestamp of the last block
+func (c *devController) SetNextBlockTimestamp(timestamp int64) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if timestamp <= c.lastTimestamp {
+		return fmt.Errorf("timestamp %d is not after the timestamp %d of the last block", timestamp, c.lastTimestamp)
+	}
+	c.nextTimestamp = &timestamp
+	return nil
+}
+
+// Snapshot records the state of the last block, it returns the id of the snapshot
+func (c *devController) Snapshot() uint64 {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.snapshotID++
+	c.snapshots = append(c.snapshots, devSnapshot{
+		id:            c.snapshotID,
+		height:        c.app.LastBlockHeight(),
+		timeOffset:    c.timeOffset,
+		lastTimestamp: c.lastTimestamp,
+	})
+	return c.snapshotID
+}
+
+// Revert restores the state and the time of a snapshot in a new block, the snapshot and the later ones are dropped
+func (c *devController) Revert(ctx context.Context, id uint64) (bool, error) {
+	c.mtx.Lock()
+	i := sort.Search(len(c.snapshots), func(i int) bool { return c.snapshots[i].id >= id })
+	if i == len(c.snapshots) || c.snapshots[i].id != id {
+		c.mtx.Unlock()
+		return false, nil
+	}
+	snapshot := c.snapshots[i]
+	c.snapshots = c.snapshots[:i]
+	c.timeOffset = snapshot.timeOffset
+	c.nextTimestamp = nil
+	c.lastTimestamp = snapshot.lastTimestamp
+	c.mtx.Unlock()
+
+	if err := c.do(ctx, func(ctx sdk.Context) error { return c.restore(ctx, snapshot.height) }); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SetBalance sets the evm balance of the address, the supply is changed by the same amount
+func (c *devController) SetBalance(ctx context.Context, addr ethcmn.Address, balance *big.Int) error {
+	return c.do(ctx, func(ctx sdk.Context) error {
+		csdb := evmtypes.CreateEmptyCommitStateDB(c.app.EvmKeeper.GenerateCSDBParams(), ctx)
+		diff := new(big.Int).Sub(balance, csdb.GetBalance(addr))
+		csdb.SetBalance(addr, balance)
+		if err := commitDevState(csdb); err != nil {
+			return err
+		}
+
+		if diff.Sign() == 0 {
+			return nil
+		}
+		coins := sdk.NewCoins(sdk.NewDecCoinFromDec(sdk.DefaultBondDenom,
+			sdk.NewDecFromBigIntWithPrec(new(big.Int).Abs(diff), sdk.Precision)))
+		totalSupply := c.app.SupplyKeeper.GetSupply(ctx)
+		if diff.Sign() > 0 {
+			totalSupply = totalSupply.Inflate(coins)
+		} else {
+			totalSupply = totalSupply.Deflate(coins)
+		}
+		c.app.SupplyKeeper.SetSupply(ctx, totalSupply)
+		return nil
+	})
+}
+
+// SetCode sets the code of the address
+func (c *devController) SetCode(ctx context.Context, addr ethcmn.Address, code []byte) error {
+	return c.do(ctx, c.stateOp(func(csdb *evmtypes.CommitStateDB) { csdb.SetCode(addr, code) }))
+}
+
+// SetNonce sets the nonce of the address
+func (c *devController) SetNonce(ctx context.Context, addr ethcmn.Address, nonce uint64) error {
+	return c.do(ctx, c.stateOp(func(csdb *evmtypes.CommitStateDB) { csdb.SetNonce(addr, nonce) }))
+}
+
+// SetStorageAt sets the value of the storage slot of the address
+func (c *devController) SetStorageAt(ctx context.Context, addr ethcmn.Address, key, value ethcmn.Hash) error {
+	return c.do(ctx, c.stateOp(func(csdb *evmtypes.CommitStateDB) { csdb.SetState(addr, key, value) }))
+}
+
+func (c *devController) stateOp(set func(csdb *evmtypes.CommitStateDB)) func(ctx sdk.Context) error {
+	return func(ctx sdk.Context) error {
+		csdb := evmtypes.CreateEmptyCommitStateDB(c.app.EvmKeeper.GenerateCSDBParams(), ctx)
+		set(csdb)
+		return commitDevState(csdb)
+	}
+}
+
+func commitDevState(csdb *evmtypes.CommitStateDB) error {
+	if err := csdb.Finalise(false); err != nil {
+		return err
+	}
+	_, err := csdb.Commit(false)
+	return err
+}
+
+// do queues the op, and waits for the commit of the block applying it. A nil op only waits for a new block.
+func (c *devController) do(ctx context.Context, apply func(ctx sdk.Context) error) error {
+	op := &devOp{apply: apply, done: make(chan error, 1)}
+	c.mtx.Lock()
+	c.pending = append(c.pending, op)
+	c.mtx.Unlock()
+
+	// the consensus only proposes blocks on the arrival of txs when the dev block interval is 0
+	if mempool, ok := bam.GetGlobalMempool().(interface{ RequestBlock() }); ok {
+		mempool.RequestBlock()
+	}
+
+	select {
+	case err := <-op.done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// blockTime returns the time of the block seen by the app from its tendermint time
+func (c *devController) blockTime(height int64, t time.Time) time.Time {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.nextTimestamp != nil {
+		c.timeOffset = *c.nextTimestamp - t.Unix()
+		c.nextTimestamp = nil
+	}
+	if n := len(c.offsets); (n == 0 && c.timeOffset != 0) || (n > 0 && c.offsets[n-1].offset != c.timeOffset) {
+		c.offsets = append(c.offsets, devTimeOffset{height: height, offset: c.timeOffset})
+	}
+	c.lastTimestamp = t.Unix() + c.timeOffset
+	return time.Unix(c.lastTimestamp, int64(t.Nanosecond())).UTC()
+}
+
+// blockTimeOffset returns the offset in seconds of the time of the block at height from its tendermint time. The
+// offsets are kept in memory, the blocks sealed before a restart of the node are seen at their tendermint time.
+func (c *devController) blockTimeOffset(height int64) int64 {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	i := sort.Search(len(c.offsets), func(i int) bool { return c.offsets[i].height > height })
+	if i == 0 {
+		return 0
+	}
+	return c.offsets[i-1].offset
+}
+
+// beginBlock applies the pending ops, an op failing is discarded
+func (c *devController) beginBlock(ctx sdk.Context) {
+	c.mtx.Lock()
+	ops := c.pending
+	c.pending = nil
+	c.mtx.Unlock()
+
+	for _, op := range ops {
+		if op.apply == nil {
+			continue
+		}
+		cacheCtx, write := ctx.CacheContext()
+		if op.err = op.apply(cacheCtx); op.err == nil {
+			write()
+		}
+	}
+	c.applied = append(c.applied, ops...)
+}
+
+// commit returns the results of the ops applied by the committed block
+func (c *devController) commit() {
+	for _, op := range c.applied {
+		op.done <- op.err
+	}
+	c.applied = nil
+}
+
+// restore sets the stores to their state at height, it must be kept by the pruning
+func (c *devController) restore(ctx sdk.Context, height int64) error {
+	cms, err := c.app.GetCommitMultiStore().CacheMultiStoreWithVersion(height)
+	if err != nil {
+		return fmt.Errorf("failed to load the state of the snapshot at height %d: %w", height, err)
+	}
+
+	for _, restored := range devRestoredStores {
+		key := c.app.keys[restored.name]
+		prefixes := restored.prefixes
+		if prefixes == nil {
+			prefixes = [][]byte{nil}
+		}
+		for _, prefix := range prefixes {
+			restoreStore(ctx.KVStore(key), cms.GetKVStore(key), prefix)
+		}
+	}
+	return nil
+}
+
+// restoreStore sets the keys of store with prefix to the ones of snapshot
+func restoreStore(store, snapshot sdk.KVStore, prefix []byte) {
+	var keys [][]byte
+	it := prefixIterator(store, prefix)
+	for ; it.Valid(); it.Next() {
+		keys = append(keys, append([]byte{}, it.Key()...))
+	}
+	it.Close()
+	for _, key := range keys {
+		store.Delete(key)
+	}
+
+	it = prefixIterator(snapshot, prefix)
+	defer it.Close()
+	for ; it.Valid(); it.Next() {
+		store.Set(it.Key(), it.Value())
+	}
+}
+
+func prefixIterator(store sdk.KVStore, prefix []byte) sdk.Iterator {
+	if len(prefix) == 0 {
+		return store.Iterator(nil, nil)
+	}
+	return sdk.KVStorePrefixIterator(store, prefix)
+}
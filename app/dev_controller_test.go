@@ -0,0 +1,113 @@
+package app
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/okex/exchain/libs/cosmos-sdk/x/supply"
+	abci "github.com/okex/exchain/libs/tendermint/abci/types"
+	evmtypes "github.com/okex/exchain/x/evm/types"
+)
+
+// mineDevBlock runs call until its op is pending, and seals the block applying it
+func mineDevBlock(t *testing.T, app *OKExChainApp, blockTime time.Time, call func() error) error {
+	res := make(chan error, 1)
+	go func() { res <- call() }()
+	require.Eventually(t, func() bool {
+		app.dev.mtx.Lock()
+		defer app.dev.mtx.Unlock()
+		return len(app.dev.pending) > 0
+	}, time.Second, time.Millisecond)
+
+	height := app.LastBlockHeight() + 1
+	app.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: height, Time: blockTime}})
+	app.EndBlock(abci.RequestEndBlock{Height: height})
+	app.Commit(abci.RequestCommit{})
+	return <-res
+}
+
+func devStateDB(app *OKExChainApp) *evmtypes.CommitStateDB {
+	ctx := app.NewContext(true, abci.Header{})
+	return evmtypes.CreateEmptyCommitStateDB(app.EvmKeeper.GenerateCSDBParams(), ctx)
+}
+
+func TestDevController(t *testing.T) {
+	app := Setup(false)
+	app.Commit(abci.RequestCommit{})
+	app.dev = newDevController(app)
+	c, ctx := app.dev, context.Background()
+
+	addr := ethcmn.BytesToAddress([]byte("dev"))
+	key, value := ethcmn.BytesToHash([]byte("key")), ethcmn.BytesToHash([]byte("value"))
+	balance := big.NewInt(1e18)
+
+	now := time.Now()
+	require.NoError(t, mineDevBlock(t, app, now, func() error { return c.SetBalance(ctx, addr, balance) }))
+	require.Equal(t, balance, devStateDB(app).GetBalance(addr))
+	// the supply is kept equal to the sum of the balances
+	_, broken := supply.TotalSupply(app.SupplyKeeper)(app.NewContext(true, abci.Header{}))
+	require.False(t, broken)
+
+	snapshot := c.Snapshot()
+	require.NoError(t, mineDevBlock(t, app, now.Add(time.Second), func() error {
+		return c.SetStorageAt(ctx, addr, key, value)
+	}))
+	require.NoError(t, mineDevBlock(t, app, now.Add(2*time.Second), func() error {
+		return c.SetCode(ctx, addr, []byte("code"))
+	}))
+	require.NoError(t, mineDevBlock(t, app, now.Add(3*time.Second), func() error {
+		return c.SetNonce(ctx, addr, 5)
+	}))
+	csdb := devStateDB(app)
+	require.Equal(t, value, csdb.GetState(addr, key))
+	require.Equal(t, []byte("code"), csdb.GetCode(addr))
+	require.Equal(t, uint64(5), csdb.GetNonce(addr))
+
+	// the blocks sealed since the snapshot are kept, their state is reverted
+	var reverted bool
+	require.NoError(t, mineDevBlock(t, app, now.Add(4*time.Second), func() (err error) {
+		reverted, err = c.Revert(ctx, snapshot)
+		return err
+	}))
+	require.True(t, reverted)
+	csdb = devStateDB(app)
+	require.Equal(t, balance, csdb.GetBalance(addr))
+	require.Equal(t, ethcmn.Hash{}, csdb.GetState(addr, key))
+	require.Empty(t, csdb.GetCode(addr))
+	require.Equal(t, uint64(0), csdb.GetNonce(addr))
+	_, broken = supply.TotalSupply(app.SupplyKeeper)(app.NewContext(true, abci.Header{}))
+	require.False(t, broken)
+	// a snapshot is only reverted once
+	reverted, err := c.Revert(ctx, snapshot)
+	require.NoError(t, err)
+	require.False(t, reverted)
+}
+
+func TestDevControllerTime(t *testing.T) {
+	c := newDevController(nil)
+	now := time.Unix(1000, 0)
+
+	require.Equal(t, int64(1000), c.blockTime(1, now).Unix())
+	require.Equal(t, int64(100), c.IncreaseTime(100))
+	require.Equal(t, int64(1101), c.blockTime(2, now.Add(time.Second)).Unix())
+
+	// the timestamp must be after the last block
+	require.Error(t, c.SetNextBlockTimestamp(1101))
+	require.NoError(t, c.SetNextBlockTimestamp(2000))
+	require.Equal(t, int64(2000), c.blockTime(3, now.Add(2*time.Second)).Unix())
+	require.Equal(t, int64(2001), c.blockTime(4, now.Add(3*time.Second)).Unix())
+	// far timestamps do not overflow
+	require.NoError(t, c.SetNextBlockTimestamp(12345678901))
+	require.Equal(t, int64(12345678901), c.blockTime(5, now.Add(4*time.Second)).Unix())
+
+	require.Equal(t, int64(0), c.blockTimeOffset(1))
+	require.Equal(t, int64(100), c.blockTimeOffset(2))
+	require.Equal(t, int64(998), c.blockTimeOffset(3))
+	require.Equal(t, int64(998), c.blockTimeOffset(4))
+	require.Equal(t, int64(12345678901-1004), c.blockTimeOffset(10))
+}
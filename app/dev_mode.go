@@ -0,0 +1,176 @@
+package app
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/viper"
+
+	okexchaincodec "github.com/okex/exchain/app/codec"
+	"github.com/okex/exchain/app/crypto/ethsecp256k1"
+	"github.com/okex/exchain/app/crypto/hd"
+	"github.com/okex/exchain/app/types"
+	"github.com/okex/exchain/libs/cosmos-sdk/server"
+	storetypes "github.com/okex/exchain/libs/cosmos-sdk/store/types"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	"github.com/okex/exchain/libs/cosmos-sdk/x/auth"
+	"github.com/okex/exchain/libs/cosmos-sdk/x/supply"
+	"github.com/okex/exchain/x/evm/watcher"
+	"github.com/okex/exchain/x/genutil"
+)
+
+const (
+	FlagDev              = "dev"
+	FlagDevBlockInterval = "dev.block-interval"
+	FlagDevAccounts      = "dev.accounts"
+	FlagDevBalance       = "dev.balance"
+
+	// DevMnemonic is the well known mnemonic of the dev accounts, they must never hold real funds
+	DevMnemonic = "test test test test test test test test test test test junk"
+	// devHDPath is the ethereum derivation path of the dev accounts, the last level is the index of the account
+	devHDPath = "m/44'/60'/0'/0/%d"
+)
+
+// devAccount is a pre-funded account of the dev mode
+type devAccount struct {
+	address ethcmn.Address
+	privKey ethsecp256k1.PrivKey
+}
+
+// setDevConfig sets the single node dev mode, the blocks are produced as soon as txs arrive, or on a fixed interval,
+// and the dev accounts are funded in the genesis of a new chain
+func setDevConfig(ctx *server.Context) error {
+	if !viper.GetBool(FlagDev) {
+		return nil
+	}
+
+	consensus := ctx.Config.Consensus
+	interval := viper.GetDuration(FlagDevBlockInterval)
+	if interval < 0 {
+		return fmt.Errorf("invalid dev block interval %s", interval)
+	}
+	if interval == 0 {
+		// seal a block on the arrival of txs only, the app hash changes with every block so it is not
+		// signed by a block of its own
+		consensus.CreateEmptyBlocks = false
+		consensus.CreateEmptyBlocksInterval = 0
+		consensus.SkipProofBlocks = true
+		consensus.TimeoutCommit = 0
+		consensus.SkipTimeoutCommit = true
+	} else {
+		consensus.CreateEmptyBlocks = true
+		consensus.CreateEmptyBlocksInterval = 0
+		consensus.SkipProofBlocks = false
+		consensus.TimeoutCommit = interval
+		consensus.SkipTimeoutCommit = false
+	}
+
+	// the test apis revert the state to past heights, and write the state without txs, past the caches
+	viper.SetDefault(server.FlagPruning, storetypes.PruningOptionNothing)
+	viper.Set(sdk.FlagMultiCache, false)
+	viper.Set(watcher.FlagFastQuery, false)
+
+	balance, err := sdk.ParseCoins(viper.GetString(FlagDevBalance))
+	if err != nil {
+		return fmt.Errorf("invalid dev balance: %w", err)
+	}
+	accounts, err := devAccounts(viper.GetInt(FlagDevAccounts))
+	if err != nil {
+		return err
+	}
+	if err := fundDevAccounts(ctx.Config.GenesisFile(), accounts, balance); err != nil {
+		return err
+	}
+
+	fmt.Printf("Dev mode, blocks are produced %s\n\n", devBlockProduction(interval))
+	fmt.Printf("Dev accounts (mnemonic \"%s\")\n", DevMnemonic)
+	for i, acc := range accounts {
+		fmt.Printf("(%d) %s %s\n    private key: %s\n", i, acc.address.Hex(),
+			sdk.AccAddress(acc.address.Bytes()).String(), hex.EncodeToString(acc.privKey))
+	}
+	fmt.Println()
+	return nil
+}
+
+func devBlockProduction(interval time.Duration) string {
+	if interval == 0 {
+		return "on the arrival of txs"
+	}
+	return fmt.Sprintf("every %s", interval)
+}
+
+// devAccounts derives the dev accounts from DevMnemonic
+func devAccounts(n int) ([]devAccount, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("invalid number of dev accounts %d", n)
+	}
+
+	accounts := make([]devAccount, n)
+	for i := range accounts {
+		bz, err := hd.DeriveSecp256k1(DevMnemonic, "", fmt.Sprintf(devHDPath, i))
+		if err != nil {
+			return nil, err
+		}
+		privKey := ethsecp256k1.PrivKey(bz)
+		accounts[i] = devAccount{
+			address: ethcmn.BytesToAddress(privKey.PubKey().Address().Bytes()),
+			privKey: privKey,
+		}
+	}
+	return accounts, nil
+}
+
+// fundDevAccounts adds the dev accounts missing from the genesis file with balance. The genesis of a chain already
+// started is stored by the node, so the accounts are only funded on the first start of a chain.
+func fundDevAccounts(genFile string, accounts []devAccount, balance sdk.Coins) error {
+	cdc := okexchaincodec.MakeCodec(ModuleBasics)
+	appState, genDoc, err := genutil.GenesisStateFromGenFile(cdc, genFile)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal genesis state: %w", err)
+	}
+
+	authGenState := auth.GetGenesisStateFromAppState(cdc, appState)
+	var added int
+	var funded sdk.Coins
+	for _, acc := range accounts {
+		addr := sdk.AccAddress(acc.address.Bytes())
+		if authGenState.Accounts.Contains(addr) {
+			continue
+		}
+		authGenState.Accounts = append(authGenState.Accounts, types.EthAccount{
+			BaseAccount: auth.NewBaseAccount(addr, balance.Sort(), nil, 0, 0),
+			CodeHash:    ethcrypto.Keccak256(nil),
+		})
+		added++
+		funded = funded.Add(balance...)
+	}
+	if added == 0 {
+		return nil
+	}
+	authGenState.Accounts = auth.SanitizeGenesisAccounts(authGenState.Accounts)
+	if appState[auth.ModuleName], err = cdc.MarshalJSON(authGenState); err != nil {
+		return err
+	}
+
+	// the supply is computed from the accounts when the genesis leaves it empty
+	var supplyGenState supply.GenesisState
+	if bz, ok := appState[supply.ModuleName]; ok {
+		if err := cdc.UnmarshalJSON(bz, &supplyGenState); err != nil {
+			return err
+		}
+	}
+	if !supplyGenState.Supply.Empty() {
+		supplyGenState.Supply = supplyGenState.Supply.Add(funded...)
+		if appState[supply.ModuleName], err = cdc.MarshalJSON(supplyGenState); err != nil {
+			return err
+		}
+	}
+
+	if genDoc.AppState, err = cdc.MarshalJSON(appState); err != nil {
+		return err
+	}
+	return genutil.ExportGenesisFile(genDoc, genFile)
+}
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+
+	okexchaincodec "github.com/okex/exchain/app/codec"
+	"github.com/okex/exchain/libs/cosmos-sdk/codec"
+	"github.com/okex/exchain/libs/cosmos-sdk/server"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	"github.com/okex/exchain/libs/cosmos-sdk/x/auth"
+	tmtypes "github.com/okex/exchain/libs/tendermint/types"
+	"github.com/okex/exchain/x/genutil"
+)
+
+func TestDevAccounts(t *testing.T) {
+	accounts, err := devAccounts(2)
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	// the well known accounts of the dev mnemonic
+	require.Equal(t, "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266", accounts[0].address.Hex())
+	require.Equal(t, "0x70997970C51812dc3A010C7d01b50e0d17dc79C8", accounts[1].address.Hex())
+
+	_, err = devAccounts(-1)
+	require.Error(t, err)
+}
+
+// exportDevGenesis writes the default genesis of the app to the file
+func exportDevGenesis(t *testing.T, genFile string) {
+	cdc := okexchaincodec.MakeCodec(ModuleBasics)
+	appState, err := codec.MarshalJSONIndent(cdc, ModuleBasics.DefaultGenesis())
+	require.NoError(t, err)
+	require.NoError(t, genutil.ExportGenesisFile(&tmtypes.GenesisDoc{ChainID: "exchain-65", AppState: appState}, genFile))
+}
+
+func TestFundDevAccounts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dev_mode")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cdc := okexchaincodec.MakeCodec(ModuleBasics)
+	genFile := filepath.Join(dir, "genesis.json")
+	exportDevGenesis(t, genFile)
+
+	accounts, err := devAccounts(3)
+	require.NoError(t, err)
+	balance := sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 100))
+	require.NoError(t, fundDevAccounts(genFile, accounts[:2], balance))
+	// the accounts already funded are kept
+	require.NoError(t, fundDevAccounts(genFile, accounts, sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 10))))
+
+	genState, _, err := genutil.GenesisStateFromGenFile(cdc, genFile)
+	require.NoError(t, err)
+	authGenState := auth.GetGenesisStateFromAppState(cdc, genState)
+	require.Len(t, authGenState.Accounts, 3)
+	for i, acc := range accounts {
+		addr := sdk.AccAddress(acc.address.Bytes())
+		require.True(t, authGenState.Accounts.Contains(addr))
+		for _, genAcc := range authGenState.Accounts {
+			if !genAcc.GetAddress().Equals(addr) {
+				continue
+			}
+			if i < 2 {
+				require.Equal(t, balance, genAcc.GetCoins())
+			} else {
+				require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 10)), genAcc.GetCoins())
+			}
+		}
+	}
+}
+
+func TestSetDevConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dev_mode")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer viper.Reset()
+
+	ctx := server.NewDefaultContext()
+	ctx.Config.SetRoot(dir)
+	require.NoError(t, os.MkdirAll(filepath.Dir(ctx.Config.GenesisFile()), 0755))
+	exportDevGenesis(t, ctx.Config.GenesisFile())
+
+	// the flags are bound as on the command line, the dev flags nest in viper
+	flags := pflag.NewFlagSet("dev", pflag.ContinueOnError)
+	flags.Bool(FlagDev, true, "")
+	flags.Duration(FlagDevBlockInterval, 0, "")
+	flags.Int(FlagDevAccounts, 1, "")
+	flags.String(FlagDevBalance, "100"+sdk.DefaultBondDenom, "")
+	require.NoError(t, viper.BindPFlags(flags))
+
+	// the blocks are sealed for the txs only, without the blocks signing their app hash
+	require.NoError(t, setDevConfig(ctx))
+	require.False(t, ctx.Config.Consensus.CreateEmptyBlocks)
+	require.True(t, ctx.Config.Consensus.SkipProofBlocks)
+	require.True(t, ctx.Config.Consensus.SkipTimeoutCommit)
+
+	require.NoError(t, flags.Set(FlagDevBlockInterval, "2s"))
+	require.NoError(t, setDevConfig(ctx))
+	require.True(t, ctx.Config.Consensus.CreateEmptyBlocks)
+	require.False(t, ctx.Config.Consensus.SkipProofBlocks)
+	require.Equal(t, 2*time.Second, ctx.Config.Consensus.TimeoutCommit)
+
+	require.NoError(t, flags.Set(FlagDevBlockInterval, "-1s"))
+	require.Error(t, setDevConfig(ctx))
+}
@@ -0,0 +1,141 @@
+package eventsink
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	appCfg "github.com/okex/exchain/libs/cosmos-sdk/server/config"
+	abci "github.com/okex/exchain/libs/tendermint/abci/types"
+	ctypes "github.com/okex/exchain/libs/tendermint/rpc/core/types"
+	tmtypes "github.com/okex/exchain/libs/tendermint/types"
+	evmtypes "github.com/okex/exchain/x/evm/types"
+)
+
+// Message is a message published to a topic of the broker
+type Message struct {
+	Topic string
+	Key   []byte
+	Value []byte
+}
+
+// BlockMessage is published for every committed block
+type BlockMessage struct {
+	ChainID  string    `json:"chain_id"`
+	Height   int64     `json:"height"`
+	Hash     string    `json:"hash"`
+	Time     time.Time `json:"time"`
+	Proposer string    `json:"proposer"`
+	NumTxs   int       `json:"num_txs"`
+}
+
+// TxMessage is published for the result of every tx of a block
+type TxMessage struct {
+	Height    int64  `json:"height"`
+	Index     int    `json:"index"`
+	Hash      string `json:"hash"`
+	Code      uint32 `json:"code"`
+	Codespace string `json:"codespace,omitempty"`
+	Log       string `json:"log"`
+	GasWanted int64  `json:"gas_wanted"`
+	GasUsed   int64  `json:"gas_used"`
+}
+
+// LogMessage is published for every log of the successful evm txs of a block
+type LogMessage struct {
+	Height    int64          `json:"height"`
+	BlockHash string         `json:"block_hash"`
+	TxHash    string         `json:"tx_hash"`
+	TxIndex   int            `json:"tx_index"`
+	LogIndex  uint           `json:"log_index"`
+	Address   common.Address `json:"address"`
+	Topics    []common.Hash  `json:"topics"`
+	Data      hexutil.Bytes  `json:"data"`
+}
+
+// BuildMessages returns the messages of a block in the order they are published: the block, its tx results,
+// then the evm logs
+func BuildMessages(config *appCfg.EventSinkConfig, block *ctypes.ResultBlock, results *ctypes.ResultBlockResults) ([]Message, error) {
+	header := block.Block.Header
+	blockHash := common.BytesToHash(block.BlockID.Hash).Hex()
+	var messages []Message
+	add := func(topic string, key []byte, v interface{}) error {
+		bz, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, Message{Topic: topic, Key: key, Value: bz})
+		return nil
+	}
+
+	err := add(config.BlockTopic, []byte(blockHash), BlockMessage{
+		ChainID:  header.ChainID,
+		Height:   header.Height,
+		Hash:     blockHash,
+		Time:     header.Time,
+		Proposer: header.ProposerAddress.String(),
+		NumTxs:   len(block.Block.Txs),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var logs []LogMessage
+	for i, res := range results.TxsResults {
+		if i >= len(block.Block.Txs) {
+			break
+		}
+		txHash := common.BytesToHash(tmtypes.Tx(block.Block.Txs[i]).Hash()).Hex()
+		err := add(config.TxTopic, []byte(txHash), TxMessage{
+			Height:    header.Height,
+			Index:     i,
+			Hash:      txHash,
+			Code:      res.Code,
+			Codespace: res.Codespace,
+			Log:       res.Log,
+			GasWanted: res.GasWanted,
+			GasUsed:   res.GasUsed,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if res.Code != abci.CodeTypeOK || !isEvmTx(res.Events) {
+			continue
+		}
+		data, err := evmtypes.DecodeResultData(res.Data)
+		if err != nil {
+			return nil, err
+		}
+		for _, log := range data.Logs {
+			logs = append(logs, LogMessage{
+				Height:    header.Height,
+				BlockHash: blockHash,
+				TxHash:    txHash,
+				TxIndex:   i,
+				LogIndex:  log.Index,
+				Address:   log.Address,
+				Topics:    log.Topics,
+				Data:      log.Data,
+			})
+		}
+	}
+	for _, log := range logs {
+		if err := add(config.LogTopic, []byte(fmt.Sprintf("%s-%d", log.TxHash, log.LogIndex)), log); err != nil {
+			return nil, err
+		}
+	}
+	return messages, nil
+}
+
+func isEvmTx(events []abci.Event) bool {
+	for _, event := range events {
+		if event.Type == evmtypes.EventTypeEthereumTx || event.Type == evmtypes.EventTypeEthermint {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package eventsink
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+const natsTimeout = 10 * time.Second
+
+// natsPublisher publishes the messages with the text protocol of nats. The messages are flushed with a PING
+// after them, the server processed them once it answers the PONG.
+type natsPublisher struct {
+	addrs []string
+	next  int
+
+	conn net.Conn
+	r    *bufio.Reader
+	w    *bufio.Writer
+}
+
+func newNatsPublisher(addrs []string) *natsPublisher {
+	return &natsPublisher{addrs: addrs}
+}
+
+func (p *natsPublisher) Publish(messages []Message) error {
+	if p.conn == nil {
+		if err := p.connect(); err != nil {
+			return err
+		}
+	}
+	err := p.publish(messages)
+	if err != nil {
+		// the next publication reconnects, possibly to another server
+		p.Close()
+	}
+	return err
+}
+
+func (p *natsPublisher) publish(messages []Message) error {
+	if err := p.conn.SetDeadline(time.Now().Add(natsTimeout)); err != nil {
+		return err
+	}
+	for _, msg := range messages {
+		fmt.Fprintf(p.w, "PUB %s %d\r\n", msg.Topic, len(msg.Value))
+		p.w.Write(msg.Value)
+		p.w.WriteString("\r\n")
+	}
+	p.w.WriteString("PING\r\n")
+	if err := p.w.Flush(); err != nil {
+		return err
+	}
+	return p.waitPong()
+}
+
+// connect dials the next server, reads its INFO and sends the CONNECT of the client
+func (p *natsPublisher) connect() error {
+	addr := strings.TrimPrefix(p.addrs[p.next%len(p.addrs)], "nats://")
+	p.next++
+	conn, err := net.DialTimeout("tcp", addr, natsTimeout)
+	if err != nil {
+		return err
+	}
+	p.conn, p.r, p.w = conn, bufio.NewReader(conn), bufio.NewWriter(conn)
+	if err := conn.SetDeadline(time.Now().Add(natsTimeout)); err != nil {
+		p.Close()
+		return err
+	}
+
+	line, err := p.r.ReadString('\n')
+	if err == nil && !strings.HasPrefix(line, "INFO") {
+		err = fmt.Errorf("unexpected greeting of nats server %s: %s", addr, strings.TrimSpace(line))
+	}
+	if err == nil {
+		p.w.WriteString(`CONNECT {"verbose":false,"pedantic":false,"name":"exchaind-event-sink"}` + "\r\nPING\r\n")
+		if err = p.w.Flush(); err == nil {
+			err = p.waitPong()
+		}
+	}
+	if err != nil {
+		p.Close()
+		return fmt.Errorf("failed to connect to nats server %s: %w", addr, err)
+	}
+	return nil
+}
+
+func (p *natsPublisher) waitPong() error {
+	for {
+		line, err := p.r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		switch line = strings.TrimSpace(line); {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			p.w.WriteString("PONG\r\n")
+			if err := p.w.Flush(); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.New(line)
+		}
+	}
+}
+
+func (p *natsPublisher) Close() error {
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn = nil
+	return err
+}
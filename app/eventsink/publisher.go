@@ -0,0 +1,87 @@
+package eventsink
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/segmentio/kafka-go"
+
+	appCfg "github.com/okex/exchain/libs/cosmos-sdk/server/config"
+)
+
+const (
+	SinkKafka = "kafka"
+	SinkNats  = "nats"
+)
+
+// Publisher publishes the messages to a broker
+type Publisher interface {
+	// Publish returns once all the messages are acknowledged by the broker, in order per topic
+	Publish(messages []Message) error
+	Close() error
+}
+
+// NewPublisher returns the publisher of the broker of the config
+func NewPublisher(config *appCfg.EventSinkConfig) (Publisher, error) {
+	addrs := strings.Split(config.Addrs, ",")
+	if config.Addrs == "" {
+		return nil, fmt.Errorf("no address of the %s brokers", config.Type)
+	}
+	switch config.Type {
+	case SinkKafka:
+		return newKafkaPublisher(addrs), nil
+	case SinkNats:
+		return newNatsPublisher(addrs), nil
+	default:
+		return nil, fmt.Errorf("unknown event sink %s", config.Type)
+	}
+}
+
+// kafkaPublisher writes the messages with a writer per topic, waiting for the acks of all the replicas
+type kafkaPublisher struct {
+	addrs   []string
+	writers map[string]*kafka.Writer
+}
+
+func newKafkaPublisher(addrs []string) *kafkaPublisher {
+	return &kafkaPublisher{addrs: addrs, writers: make(map[string]*kafka.Writer)}
+}
+
+func (p *kafkaPublisher) Publish(messages []Message) error {
+	var topics []string
+	byTopic := make(map[string][]kafka.Message)
+	for _, msg := range messages {
+		if _, ok := byTopic[msg.Topic]; !ok {
+			topics = append(topics, msg.Topic)
+		}
+		byTopic[msg.Topic] = append(byTopic[msg.Topic], kafka.Message{Key: msg.Key, Value: msg.Value})
+	}
+
+	for _, topic := range topics {
+		w, ok := p.writers[topic]
+		if !ok {
+			w = kafka.NewWriter(kafka.WriterConfig{
+				Brokers: p.addrs,
+				Topic:   topic,
+				// the messages of a key stay in order on its partition
+				Balancer: &kafka.Hash{},
+			})
+			p.writers[topic] = w
+		}
+		if err := w.WriteMessages(context.Background(), byTopic[topic]...); err != nil {
+			return fmt.Errorf("failed to write to kafka topic %s: %w", topic, err)
+		}
+	}
+	return nil
+}
+
+func (p *kafkaPublisher) Close() error {
+	var err error
+	for _, w := range p.writers {
+		if closeErr := w.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
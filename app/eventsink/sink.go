@@ -0,0 +1,195 @@
+package eventsink
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	appCfg "github.com/okex/exchain/libs/cosmos-sdk/server/config"
+	"github.com/okex/exchain/libs/tendermint/libs/log"
+	ctypes "github.com/okex/exchain/libs/tendermint/rpc/core/types"
+)
+
+const (
+	// stateFile records the last published height in the data directory
+	stateFile = "event_sink_state.json"
+
+	pollInterval  = time.Second
+	retryInterval = 5 * time.Second
+)
+
+// Source is the part of the tendermint rpc client the blocks and their results are read from
+type Source interface {
+	Status() (*ctypes.ResultStatus, error)
+	Block(height *int64) (*ctypes.ResultBlock, error)
+	BlockResults(height *int64) (*ctypes.ResultBlockResults, error)
+}
+
+// Sink publishes the messages of the committed blocks in order. A height is only recorded as published once
+// the broker acknowledged all its messages, the messages are published at least once across failures and
+// restarts.
+type Sink struct {
+	config    *appCfg.EventSinkConfig
+	source    Source
+	publisher Publisher
+	stateFile string
+	logger    log.Logger
+}
+
+type sinkState struct {
+	Height int64 `json:"height"`
+}
+
+// ConfigFromViper returns the event sink config of the app config file
+func ConfigFromViper() (*appCfg.EventSinkConfig, error) {
+	conf, err := appCfg.ParseConfig()
+	if err != nil {
+		return nil, err
+	}
+	return conf.EventSinkConfig, nil
+}
+
+// NewSink returns the sink of the blocks of source, the last published height is recorded in dataDir
+func NewSink(config *appCfg.EventSinkConfig, source Source, dataDir string, logger log.Logger) (*Sink, error) {
+	publisher, err := NewPublisher(config)
+	if err != nil {
+		return nil, err
+	}
+	return &Sink{
+		config:    config,
+		source:    source,
+		publisher: publisher,
+		stateFile: filepath.Join(dataDir, stateFile),
+		logger:    logger.With("module", "event-sink"),
+	}, nil
+}
+
+// PublishHeight publishes the messages of the block at the height
+func (s *Sink) PublishHeight(height int64) error {
+	block, err := s.source.Block(&height)
+	if err != nil {
+		return err
+	}
+	results, err := s.source.BlockResults(&height)
+	if err != nil {
+		return err
+	}
+	messages, err := BuildMessages(s.config, block, results)
+	if err != nil {
+		return fmt.Errorf("failed to build the messages of block %d: %w", height, err)
+	}
+	return s.publisher.Publish(messages)
+}
+
+// Replay publishes the blocks from one height to another again, without changing the last published height
+func (s *Sink) Replay(from, to int64) error {
+	for height := from; height <= to; height++ {
+		if err := s.PublishHeight(height); err != nil {
+			return fmt.Errorf("failed to publish block %d: %w", height, err)
+		}
+		s.logger.Info("published block", "height", height)
+	}
+	return nil
+}
+
+// Close closes the connection to the broker
+func (s *Sink) Close() error {
+	return s.publisher.Close()
+}
+
+// LastHeight returns the last published height, 0 if nothing was published yet
+func (s *Sink) LastHeight() (int64, error) {
+	bz, err := ioutil.ReadFile(s.stateFile)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var state sinkState
+	if err := json.Unmarshal(bz, &state); err != nil {
+		return 0, fmt.Errorf("invalid event sink state: %w", err)
+	}
+	return state.Height, nil
+}
+
+// Run publishes the blocks after the last published one as they are committed, until quit is closed
+func (s *Sink) Run(quit <-chan struct{}) {
+	defer s.publisher.Close()
+
+	var next int64
+	wait := time.Duration(0)
+	for {
+		select {
+		case <-quit:
+			return
+		case <-time.After(wait):
+		}
+		wait = retryInterval
+
+		status, err := s.source.Status()
+		if err != nil {
+			s.logger.Error("failed to get the latest height", "err", err)
+			continue
+		}
+		latest := status.SyncInfo.LatestBlockHeight
+		if next == 0 {
+			if next, err = s.firstHeight(latest); err != nil {
+				s.logger.Error("failed to load the last published height", "err", err)
+				continue
+			}
+		}
+		if err := s.publishUntil(&next, latest, quit); err != nil {
+			s.logger.Error("failed to publish block", "height", next, "err", err)
+			continue
+		}
+		wait = pollInterval
+	}
+}
+
+func (s *Sink) firstHeight(latest int64) (int64, error) {
+	last, err := s.LastHeight()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case last > 0:
+		return last + 1, nil
+	case s.config.StartHeight > 0:
+		return s.config.StartHeight, nil
+	default:
+		return latest, nil
+	}
+}
+
+func (s *Sink) publishUntil(next *int64, latest int64, quit <-chan struct{}) error {
+	for ; *next <= latest; *next++ {
+		select {
+		case <-quit:
+			return nil
+		default:
+		}
+		if err := s.PublishHeight(*next); err != nil {
+			return err
+		}
+		if err := s.saveState(*next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Sink) saveState(height int64) error {
+	bz, err := json.Marshal(sinkState{Height: height})
+	if err != nil {
+		return err
+	}
+	tmp := s.stateFile + ".tmp"
+	if err := ioutil.WriteFile(tmp, bz, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.stateFile)
+}
@@ -0,0 +1,167 @@
+package eventsink
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	appCfg "github.com/okex/exchain/libs/cosmos-sdk/server/config"
+	abci "github.com/okex/exchain/libs/tendermint/abci/types"
+	"github.com/okex/exchain/libs/tendermint/libs/log"
+	ctypes "github.com/okex/exchain/libs/tendermint/rpc/core/types"
+	tmtypes "github.com/okex/exchain/libs/tendermint/types"
+	evmtypes "github.com/okex/exchain/x/evm/types"
+)
+
+// mockSource serves blocks of an evm tx with a log and a failed tx
+type mockSource struct {
+	t      *testing.T
+	latest int64
+}
+
+func (s *mockSource) Status() (*ctypes.ResultStatus, error) {
+	return &ctypes.ResultStatus{SyncInfo: ctypes.SyncInfo{LatestBlockHeight: s.latest}}, nil
+}
+
+func (s *mockSource) Block(height *int64) (*ctypes.ResultBlock, error) {
+	block := &tmtypes.Block{Header: tmtypes.Header{ChainID: "exchain-65", Height: *height}}
+	block.Txs = tmtypes.Txs{tmtypes.Tx("evm"), tmtypes.Tx("failed")}
+	return &ctypes.ResultBlock{Block: block, BlockID: tmtypes.BlockID{Hash: []byte{byte(*height)}}}, nil
+}
+
+func (s *mockSource) BlockResults(height *int64) (*ctypes.ResultBlockResults, error) {
+	data, err := evmtypes.EncodeResultData(evmtypes.ResultData{
+		Logs: []*ethtypes.Log{{Address: common.HexToAddress("0x01"), Topics: []common.Hash{{0x02}}, Index: 3}},
+	})
+	require.NoError(s.t, err)
+	return &ctypes.ResultBlockResults{Height: *height, TxsResults: []*abci.ResponseDeliverTx{
+		{Data: data, Events: []abci.Event{{Type: evmtypes.EventTypeEthereumTx}}},
+		{Code: 5, Log: "out of gas"},
+	}}, nil
+}
+
+// mockPublisher fails the publications while fail is set
+type mockPublisher struct {
+	fail     bool
+	messages []Message
+}
+
+func (p *mockPublisher) Publish(messages []Message) error {
+	if p.fail {
+		return errors.New("broker unavailable")
+	}
+	p.messages = append(p.messages, messages...)
+	return nil
+}
+
+func (p *mockPublisher) Close() error { return nil }
+
+func TestBuildMessages(t *testing.T) {
+	source := &mockSource{t: t}
+	height := int64(7)
+	block, _ := source.Block(&height)
+	results, _ := source.BlockResults(&height)
+	messages, err := BuildMessages(appCfg.DefaultEventSinkConfig(), block, results)
+	require.NoError(t, err)
+
+	// the block, the 2 tx results and the log of the successful evm tx
+	require.Len(t, messages, 4)
+	require.Equal(t, "exchain.blocks", messages[0].Topic)
+	var blockMsg BlockMessage
+	require.NoError(t, json.Unmarshal(messages[0].Value, &blockMsg))
+	require.Equal(t, int64(7), blockMsg.Height)
+	require.Equal(t, 2, blockMsg.NumTxs)
+
+	var txMsg TxMessage
+	require.NoError(t, json.Unmarshal(messages[2].Value, &txMsg))
+	require.Equal(t, uint32(5), txMsg.Code)
+	require.Equal(t, common.BytesToHash(tmtypes.Tx("failed").Hash()).Hex(), txMsg.Hash)
+
+	require.Equal(t, "exchain.logs", messages[3].Topic)
+	var logMsg LogMessage
+	require.NoError(t, json.Unmarshal(messages[3].Value, &logMsg))
+	require.Equal(t, uint(3), logMsg.LogIndex)
+	require.Equal(t, common.HexToAddress("0x01"), logMsg.Address)
+}
+
+func TestSinkRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eventsink")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	config := appCfg.DefaultEventSinkConfig()
+	config.StartHeight = 2
+	publisher := &mockPublisher{}
+	sink := &Sink{config: config, source: &mockSource{t: t, latest: 3}, publisher: publisher,
+		stateFile: dir + "/" + stateFile, logger: log.NewNopLogger()}
+
+	quit := make(chan struct{})
+	go sink.Run(quit)
+	require.Eventually(t, func() bool {
+		last, err := sink.LastHeight()
+		return err == nil && last == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	close(quit)
+	require.Len(t, publisher.messages, 8)
+
+	// the failed publications are retried from the last published height after a restart
+	sink = &Sink{config: config, source: &mockSource{t: t, latest: 4}, publisher: &mockPublisher{fail: true},
+		stateFile: dir + "/" + stateFile, logger: log.NewNopLogger()}
+	next := int64(4)
+	require.Error(t, sink.publishUntil(&next, 4, nil))
+	next, err = sink.firstHeight(4)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), next)
+}
+
+func TestNatsPublisher(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	received := make(chan string, 10)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		conn.Write([]byte("INFO {}\r\n"))
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			fields := strings.Fields(line)
+			switch fields[0] {
+			case "PING":
+				conn.Write([]byte("PONG\r\n"))
+			case "PUB":
+				n, _ := strconv.Atoi(fields[2])
+				payload := make([]byte, n+2)
+				io.ReadFull(r, payload)
+				received <- fields[1] + " " + string(payload[:n])
+			}
+		}
+	}()
+
+	p := newNatsPublisher([]string{"nats://" + ln.Addr().String()})
+	defer p.Close()
+	require.NoError(t, p.Publish([]Message{{Topic: "exchain.blocks", Value: []byte(`{"height":1}`)}}))
+	// the publication returns once the server answered the ping after the messages
+	require.Len(t, received, 1)
+	require.Equal(t, `exchain.blocks {"height":1}`, <-received)
+}
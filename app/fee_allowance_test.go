@@ -0,0 +1,69 @@
+package app
+
+import (
+	"math/big"
+	"testing"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/okex/exchain/libs/cosmos-sdk/codec"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	abci "github.com/okex/exchain/libs/tendermint/abci/types"
+	"github.com/okex/exchain/libs/tendermint/libs/log"
+	evmtypes "github.com/okex/exchain/x/evm/types"
+	dbm "github.com/tendermint/tm-db"
+)
+
+func TestFeeAllowanceRefund(t *testing.T) {
+	const gasLimit, gasPrice = 100000, 1000000000
+	fee := sdk.NewDecWithPrec(gasLimit*gasPrice, sdk.Precision)
+
+	for _, tc := range []struct {
+		name       string
+		spendLimit sdk.Dec
+	}{
+		{"spend limit left", fee.MulInt64(3)},
+		{"spend limit used up by the charge", fee},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			app := NewOKExChainApp(log.NewNopLogger(), dbm.NewMemDB(), nil, true, map[int64]bool{}, 0)
+			stateBytes, err := codec.MarshalJSONIndent(app.Codec(), NewDefaultGenesisState())
+			require.NoError(t, err)
+			app.InitChain(abci.RequestInitChain{ChainId: "exchain-65", AppStateBytes: stateBytes})
+			app.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{ChainID: "exchain-65", Height: 1}})
+			ctx := app.GetDeliverStateCtx()
+			params := app.EvmKeeper.GetParams(ctx)
+			params.EnableCall = true
+			app.EvmKeeper.SetParams(ctx, params)
+
+			key, err := ethcrypto.GenerateKey()
+			require.NoError(t, err)
+			sender := sdk.AccAddress(ethcrypto.PubkeyToAddress(key.PublicKey).Bytes())
+			granter := sdk.AccAddress(ethcmn.HexToAddress("0x9a").Bytes())
+			app.AccountKeeper.SetAccount(ctx, app.AccountKeeper.NewAccountWithAddress(ctx, sender))
+			granterAcc := app.AccountKeeper.NewAccountWithAddress(ctx, granter)
+			require.NoError(t, granterAcc.SetCoins(sdk.NewCoins(sdk.NewCoin(sdk.DefaultBondDenom, sdk.NewDec(1)))))
+			app.AccountKeeper.SetAccount(ctx, granterAcc)
+			app.EvmKeeper.SetFeeAllowance(ctx, evmtypes.NewFeeAllowance(granter, sender, tc.spendLimit, 0))
+
+			to := ethcmn.HexToAddress("0xc0")
+			msg := evmtypes.NewMsgEthereumTx(0, &to, big.NewInt(0), gasLimit, big.NewInt(gasPrice), nil)
+			require.NoError(t, msg.Sign(big.NewInt(65), key))
+			res := app.DeliverTx(abci.RequestDeliverTx{Tx: app.Codec().MustMarshalBinaryLengthPrefixed(msg)})
+			require.True(t, res.IsOK(), res.Log)
+			require.True(t, res.GasUsed < gasLimit)
+
+			// the granter and its allowance are only charged the gas used
+			cost := sdk.NewDecWithPrec(res.GasUsed*gasPrice, sdk.Precision)
+			coins := app.AccountKeeper.GetAccount(ctx, granter).GetCoins()
+			require.Equal(t, sdk.NewDec(1).Sub(cost), coins.AmountOf(sdk.DefaultBondDenom))
+			allowance, found := app.EvmKeeper.GetFeeAllowance(ctx, sender)
+			require.True(t, found)
+			require.Equal(t, evmtypes.NewFeeAllowance(granter, sender, tc.spendLimit.Sub(cost), 0), allowance)
+			_, found = app.EvmKeeper.GetTxFeeAllowance(ctx, sender)
+			require.False(t, found)
+		})
+	}
+}
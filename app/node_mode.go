@@ -18,7 +18,8 @@ import (
 	"github.com/spf13/viper"
 )
 
-func setNodeConfig(ctx *server.Context) {
+// SetNodeConfig sets the defaults of the node mode
+func SetNodeConfig(ctx *server.Context) {
 	nodeMode := viper.GetString(types.FlagNodeMode)
 
 	ctx.Logger.Info("starting node","Genesis Height",
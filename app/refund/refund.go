@@ -15,14 +15,28 @@ import (
 	evmtypes "github.com/okex/exchain/x/evm/types"
 )
 
-func NewGasRefundHandler(ak auth.AccountKeeper, sk types.SupplyKeeper) sdk.GasRefundHandler {
+// FeePayerKeeper defines the expected keeper recording the fee allowances of the sponsors that paid the gas fee
+// of the evm txs
+type FeePayerKeeper interface {
+	GetTxFeeAllowance(ctx sdk.Context, sender sdk.AccAddress) (evmtypes.FeeAllowance, bool)
+	DeleteTxFeeAllowance(ctx sdk.Context, sender sdk.AccAddress)
+	RefundFeeAllowance(ctx sdk.Context, charged evmtypes.FeeAllowance, refund sdk.Dec)
+}
+
+// ContractGasKeeper defines the expected keeper recording the gas consumed by the evm txs calling the contracts
+// with a gas quota
+type ContractGasKeeper interface {
+	AddContractGasUsed(ctx sdk.Context, contract sdk.AccAddress, gasUsed uint64)
+}
+
+func NewGasRefundHandler(ak auth.AccountKeeper, sk types.SupplyKeeper, fpk FeePayerKeeper, cgk ContractGasKeeper) sdk.GasRefundHandler {
 	return func(
 		ctx sdk.Context, tx sdk.Tx,
 	) (refundFee sdk.Coins, err error) {
 		var gasRefundHandler sdk.GasRefundHandler
 		switch tx.(type) {
 		case evmtypes.MsgEthereumTx:
-			gasRefundHandler = NewGasRefundDecorator(ak, sk)
+			gasRefundHandler = NewGasRefundDecorator(ak, sk, fpk, cgk)
 		default:
 			return nil, nil
 		}
@@ -31,8 +45,10 @@ func NewGasRefundHandler(ak auth.AccountKeeper, sk types.SupplyKeeper) sdk.GasRe
 }
 
 type Handler struct {
-	ak           keeper.AccountKeeper
-	supplyKeeper types.SupplyKeeper
+	ak                keeper.AccountKeeper
+	supplyKeeper      types.SupplyKeeper
+	feePayerKeeper    FeePayerKeeper
+	contractGasKeeper ContractGasKeeper
 }
 
 func (handler Handler) GasRefund(ctx sdk.Context, tx sdk.Tx) (refundGasFee sdk.Coins, err error) {
@@ -45,6 +61,28 @@ func (handler Handler) GasRefund(ctx sdk.Context, tx sdk.Tx) (refundGasFee sdk.C
 		ctx = ctx.WithGasMeter(currentGasMeter)
 	}()
 
+	feeTx, ok := tx.(ante.FeeTx)
+	if !ok {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrTxDecode, "Tx must be a FeeTx")
+	}
+
+	// the refund runs whether the tx fails or not, so the gas of the reverted calls counts against the gas
+	// quota of their contract too
+	if msg, ok := tx.(evmtypes.MsgEthereumTx); ok && msg.Data.Recipient != nil && handler.contractGasKeeper != nil {
+		handler.contractGasKeeper.AddContractGasUsed(ctx, msg.Data.Recipient.Bytes(), currentGasMeter.GasConsumedToLimit())
+	}
+
+	// the gas fee of the sender may have been paid by a sponsor, the record is removed even without refund
+	feePayer := feeTx.FeePayer(ctx)
+	var allowance *evmtypes.FeeAllowance
+	if handler.feePayerKeeper != nil {
+		if charged, found := handler.feePayerKeeper.GetTxFeeAllowance(ctx, feePayer); found {
+			handler.feePayerKeeper.DeleteTxFeeAllowance(ctx, feePayer)
+			feePayer = charged.Granter
+			allowance = &charged
+		}
+	}
+
 	gasLimit := currentGasMeter.Limit()
 	gasUsed := currentGasMeter.GasConsumed()
 
@@ -52,12 +90,6 @@ func (handler Handler) GasRefund(ctx sdk.Context, tx sdk.Tx) (refundGasFee sdk.C
 		return nil, nil
 	}
 
-	feeTx, ok := tx.(ante.FeeTx)
-	if !ok {
-		return nil, sdkerrors.Wrap(sdkerrors.ErrTxDecode, "Tx must be a FeeTx")
-	}
-
-	feePayer := feeTx.FeePayer(ctx)
 	feePayerAcc := handler.ak.GetAccount(ctx, feePayer)
 	if feePayerAcc == nil {
 		return nil, sdkerrors.Wrapf(sdkerrors.ErrUnknownAddress, "fee payer address: %s does not exist", feePayer)
@@ -70,14 +102,19 @@ func (handler Handler) GasRefund(ctx sdk.Context, tx sdk.Tx) (refundGasFee sdk.C
 	if err != nil {
 		return nil, err
 	}
+	if allowance != nil {
+		handler.feePayerKeeper.RefundFeeAllowance(ctx, *allowance, gasFees.AmountOf(sdk.DefaultBondDenom))
+	}
 
 	return gasFees, nil
 }
 
-func NewGasRefundDecorator(ak auth.AccountKeeper, sk types.SupplyKeeper) sdk.GasRefundHandler {
+func NewGasRefundDecorator(ak auth.AccountKeeper, sk types.SupplyKeeper, fpk FeePayerKeeper, cgk ContractGasKeeper) sdk.GasRefundHandler {
 	chandler := Handler{
-		ak:           ak,
-		supplyKeeper: sk,
+		ak:                ak,
+		supplyKeeper:      sk,
+		feePayerKeeper:    fpk,
+		contractGasKeeper: cgk,
 	}
 
 	return func(ctx sdk.Context, tx sdk.Tx) (refund sdk.Coins, err error) {
@@ -0,0 +1,123 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	bam "github.com/okex/exchain/libs/cosmos-sdk/baseapp"
+	"github.com/okex/exchain/libs/cosmos-sdk/server"
+	"github.com/okex/exchain/libs/iavl"
+	sm "github.com/okex/exchain/libs/tendermint/state"
+	"github.com/okex/exchain/libs/tendermint/store"
+	"github.com/okex/exchain/libs/tendermint/types"
+	evmtypes "github.com/okex/exchain/x/evm/types"
+	"github.com/okex/exchain/x/evm/watcher"
+	dbm "github.com/tendermint/tm-db"
+)
+
+// RollbackHeight returns the height to roll back to, either the given one or the latest block height minus the
+// given number of blocks
+func RollbackHeight(ctx *server.Context, height, blocks int64) (int64, error) {
+	if height > 0 {
+		return height, nil
+	}
+	latest := latestBlockHeight(filepath.Join(ctx.Config.RootDir, "data"))
+	if blocks <= 0 || blocks >= latest-types.GetStartBlockHeight() {
+		return 0, fmt.Errorf("cannot roll back %d blocks, the latest block height is %d", blocks, latest)
+	}
+	return latest - blocks, nil
+}
+
+// Rollback removes the blocks above the given height from the node: the application state, the watcher db, the
+// bloom index, the tendermint state and the block store are rolled back, in that order, so that an interrupted
+// rollback can be run again. The block store is truncated last, the blocks at the height and the next one are
+// needed to rebuild the tendermint state and to check the application hash. The node must be stopped.
+func Rollback(ctx *server.Context, height int64, watchDB dbm.DB) error {
+	iavl.EnableAsyncCommit = false
+	dataDir := filepath.Join(ctx.Config.RootDir, "data")
+
+	blockStoreDB, err := openDB(blockStoreDB, dataDir)
+	if err != nil {
+		return err
+	}
+	defer blockStoreDB.Close()
+	blockStore := store.NewBlockStore(blockStoreDB)
+	if height < blockStore.Base() || height >= blockStore.Height() {
+		return fmt.Errorf("cannot roll back to height %d, the block store holds the blocks [%d, %d]",
+			height, blockStore.Base(), blockStore.Height())
+	}
+	// the next block commits the application hash of the height
+	nextBlock := blockStore.LoadBlockMeta(height + 1)
+	if nextBlock == nil {
+		return fmt.Errorf("block %d not found", height+1)
+	}
+	appHash := nextBlock.Header.AppHash
+
+	log.Printf("Rolling back the node from height %d to height %d\n", blockStore.Height(), height)
+	if err := rollbackApplication(ctx, dataDir, height, appHash); err != nil {
+		return fmt.Errorf("failed to roll back the application state: %w", err)
+	}
+
+	if watchDB != nil {
+		removed, err := watcher.Rollback(watchDB, uint64(height))
+		if err != nil {
+			return fmt.Errorf("failed to roll back the watcher db: %w", err)
+		}
+		log.Printf("Removed %d blocks from the watcher db\n", removed)
+	}
+
+	if _, err := os.Stat(filepath.Join(dataDir, evmtypes.BloomDir+".db")); err == nil {
+		bloomDB, err := openDB(evmtypes.BloomDir, dataDir)
+		if err != nil {
+			return err
+		}
+		sections, err := evmtypes.RollbackBloomBits(bloomDB, height)
+		bloomDB.Close()
+		if err != nil {
+			return fmt.Errorf("failed to roll back the bloom index: %w", err)
+		}
+		log.Printf("Rolled back the bloom index to %d sections\n", sections)
+	}
+
+	stateStoreDB, err := openDB(stateDB, dataDir)
+	if err != nil {
+		return err
+	}
+	defer stateStoreDB.Close()
+	state, err := sm.Rollback(stateStoreDB, blockStore, height)
+	if err != nil {
+		return fmt.Errorf("failed to roll back the tendermint state: %w", err)
+	}
+	log.Printf("Rolled back the tendermint state to height %d\n", state.LastBlockHeight)
+
+	removed, err := blockStore.DeleteBlocksFromTop(height)
+	if err != nil {
+		return fmt.Errorf("failed to truncate the block store: %w", err)
+	}
+	log.Printf("Removed %d blocks from the block store\n", removed)
+	return nil
+}
+
+// rollbackApplication rolls the application state back to the height and checks its hash
+func rollbackApplication(ctx *server.Context, dataDir string, height int64, appHash []byte) error {
+	db, err := openDB(applicationDB, dataDir)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	app := newRepairApp(ctx.Logger, db, nil)
+	if err := app.RollbackToVersion(height, app.keys[bam.MainStoreKey]); err != nil {
+		return err
+	}
+	commitID := app.LastCommitID()
+	if commitID.Version != height || !bytes.Equal(commitID.Hash, appHash) {
+		return fmt.Errorf("the application state is at version %d with hash %X, expected version %d with hash %X",
+			commitID.Version, commitID.Hash, height, appHash)
+	}
+	log.Printf("Rolled back the application state to version %d, app hash %X\n", commitID.Version, commitID.Hash)
+	return nil
+}
@@ -0,0 +1,390 @@
+package admission
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+
+	"github.com/okex/exchain/x/common/monitor"
+)
+
+// Class is the priority class of a rpc request, the lower classes are shed first under overload.
+type Class int
+
+const (
+	// ClassTx is the class of the transaction submissions
+	ClassTx Class = iota
+	// ClassCall is the class of the contract calls and gas estimations
+	ClassCall
+	// ClassDefault is the class of all the other requests
+	ClassDefault
+	// ClassLow is the class of the traces and of the logs queries over a wide block range
+	ClassLow
+
+	numClasses
+)
+
+// ErrCodeOverloaded is the json-rpc error code of the rejected requests, the same as the "limit exceeded"
+// code of the ethereum clients
+const ErrCodeOverloaded = -32005
+
+var classNames = [numClasses]string{"tx", "call", "default", "low"}
+
+func (c Class) String() string {
+	if c < 0 || c >= numClasses {
+		return "unknown"
+	}
+	return classNames[c]
+}
+
+var (
+	txMethods = map[string]bool{
+		"eth_sendRawTransaction": true,
+		"eth_sendTransaction":    true,
+	}
+	callMethods = map[string]bool{
+		"eth_call":        true,
+		"eth_estimateGas": true,
+		"eth_multiCall":   true,
+	}
+	lowMethods = map[string]bool{
+		"eth_getTxTrace": true,
+	}
+	lowPrefixes = []string{"debug_", "trace_"}
+)
+
+// Config defines the limits of the admission controller.
+type Config struct {
+	// Limits are the max numbers of concurrent requests per class, 0 doesn't limit the class
+	Limits [numClasses]int
+	// QueueTimeout is the time the requests wait for a slot before being rejected, the low class
+	// requests are never queued
+	QueueTimeout time.Duration
+	// WideLogsSpan is the block range above which an eth_getLogs request is in the low class
+	WideLogsSpan int64
+}
+
+// Enabled returns true if any class is limited.
+func (cfg Config) Enabled() bool {
+	for _, limit := range cfg.Limits {
+		if limit > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Controller admits the rpc requests under the concurrency limits of their class. The low class requests
+// are shed as soon as the transaction or call requests are queued, so the traces and the wide logs
+// queries never delay the submissions and the calls.
+type Controller struct {
+	cfg          Config
+	latestHeight func() (int64, error)
+
+	slots   [numClasses]chan struct{}
+	queued  int64 // number of tx and call requests waiting for a slot
+	latency [numClasses]*ewma
+}
+
+var (
+	rejectedOnce sync.Once
+	rejected     metrics.Counter
+)
+
+func rejectedCounter() metrics.Counter {
+	rejectedOnce.Do(func() {
+		rejected = prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: monitor.XNameSpace,
+			Subsystem: "rpc",
+			Name:      "admission_rejected",
+			Help:      "the number of rpc requests rejected by the admission controller",
+		}, []string{"class"})
+	})
+	return rejected
+}
+
+// NewController creates an admission controller, the latest height is used to tell the span of the logs
+// queries up to the latest block.
+func NewController(cfg Config, latestHeight func() (int64, error)) *Controller {
+	c := &Controller{
+		cfg:          cfg,
+		latestHeight: latestHeight,
+	}
+	for class, limit := range cfg.Limits {
+		if limit > 0 {
+			c.slots[class] = make(chan struct{}, limit)
+		}
+		c.latency[class] = &ewma{}
+	}
+	return c
+}
+
+// Handler wraps the json-rpc handler, the rejected requests are answered with 429 and a retry-after hint.
+func (c *Controller) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		reqs, batch := parseRequests(body)
+		class := c.classify(reqs)
+		release, ok := c.acquire(r, class)
+		if !ok {
+			c.reject(w, class, reqs, batch)
+			return
+		}
+		defer release()
+
+		start := time.Now()
+		next.ServeHTTP(w, r)
+		c.latency[class].add(time.Since(start))
+	})
+}
+
+// acquire waits for a slot of the class, the returned function releases it
+func (c *Controller) acquire(r *http.Request, class Class) (func(), bool) {
+	if class == ClassLow && atomic.LoadInt64(&c.queued) > 0 {
+		return nil, false
+	}
+	slots := c.slots[class]
+	if slots == nil {
+		return func() {}, true
+	}
+	release := func() { <-slots }
+
+	select {
+	case slots <- struct{}{}:
+		return release, true
+	default:
+	}
+	if class == ClassLow || c.cfg.QueueTimeout <= 0 {
+		return nil, false
+	}
+
+	if class == ClassTx || class == ClassCall {
+		atomic.AddInt64(&c.queued, 1)
+		defer atomic.AddInt64(&c.queued, -1)
+	}
+	timer := time.NewTimer(c.cfg.QueueTimeout)
+	defer timer.Stop()
+	select {
+	case slots <- struct{}{}:
+		return release, true
+	case <-timer.C:
+		return nil, false
+	case <-r.Context().Done():
+		return nil, false
+	}
+}
+
+func (c *Controller) reject(w http.ResponseWriter, class Class, reqs []request, batch bool) {
+	rejectedCounter().With("class", class.String()).Add(1)
+
+	retryAfter := c.retryAfter(class)
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusTooManyRequests)
+
+	if len(reqs) == 0 {
+		reqs = []request{{}}
+	}
+	resps := make([]errorResponse, len(reqs))
+	for i, req := range reqs {
+		id := req.ID
+		if len(id) == 0 {
+			id = json.RawMessage("null")
+		}
+		resps[i] = errorResponse{
+			Version: "2.0",
+			ID:      id,
+			Error: errorMessage{
+				Code:    ErrCodeOverloaded,
+				Message: "server overloaded, retry later",
+				Data:    retryData{RetryAfter: retryAfter, Class: class.String()},
+			},
+		}
+	}
+	if batch {
+		_ = json.NewEncoder(w).Encode(resps)
+	} else {
+		_ = json.NewEncoder(w).Encode(resps[0])
+	}
+}
+
+// retryAfter returns the seconds to wait before retrying, based on the recent latency of the class
+func (c *Controller) retryAfter(class Class) int {
+	seconds := int(math.Ceil(c.latency[class].value().Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
+// classify returns the lowest priority class of the requests, so that the heavy requests can't be
+// admitted in a batch of higher priority ones
+func (c *Controller) classify(reqs []request) Class {
+	if len(reqs) == 0 {
+		return ClassDefault
+	}
+	class := ClassTx
+	for _, req := range reqs {
+		if reqClass := c.classifyRequest(req); reqClass > class {
+			class = reqClass
+		}
+	}
+	return class
+}
+
+func (c *Controller) classifyRequest(req request) Class {
+	switch {
+	case txMethods[req.Method]:
+		return ClassTx
+	case callMethods[req.Method]:
+		return ClassCall
+	case lowMethods[req.Method]:
+		return ClassLow
+	case req.Method == "eth_getLogs":
+		if c.isWideLogsQuery(req.Params) {
+			return ClassLow
+		}
+		return ClassDefault
+	}
+	for _, prefix := range lowPrefixes {
+		if strings.HasPrefix(req.Method, prefix) {
+			return ClassLow
+		}
+	}
+	return ClassDefault
+}
+
+// isWideLogsQuery returns true if the logs are queried over more blocks than the wide logs span
+func (c *Controller) isWideLogsQuery(params json.RawMessage) bool {
+	var criteria []struct {
+		BlockHash *string `json:"blockHash"`
+		FromBlock *string `json:"fromBlock"`
+		ToBlock   *string `json:"toBlock"`
+	}
+	if err := json.Unmarshal(params, &criteria); err != nil || len(criteria) == 0 {
+		return false
+	}
+	if criteria[0].BlockHash != nil {
+		return false
+	}
+
+	var latest *int64
+	resolve := func(block *string) (int64, bool) {
+		if block != nil {
+			switch *block {
+			case "earliest":
+				return 0, true
+			case "latest", "pending":
+			default:
+				height, err := hexutil.DecodeUint64(*block)
+				return int64(height), err == nil
+			}
+		}
+		if latest == nil {
+			if c.latestHeight == nil {
+				return 0, false
+			}
+			height, err := c.latestHeight()
+			if err != nil {
+				return 0, false
+			}
+			latest = &height
+		}
+		return *latest, true
+	}
+	from, ok := resolve(criteria[0].FromBlock)
+	if !ok {
+		return false
+	}
+	to, ok := resolve(criteria[0].ToBlock)
+	if !ok {
+		return false
+	}
+	return to-from > c.cfg.WideLogsSpan
+}
+
+type request struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// parseRequests parses a single or a batch json-rpc request, the invalid ones are left to the rpc server
+func parseRequests(body []byte) ([]request, bool) {
+	body = bytes.TrimLeft(body, " \t\r\n")
+	if len(body) > 0 && body[0] == '[' {
+		var reqs []request
+		if err := json.Unmarshal(body, &reqs); err != nil {
+			return nil, true
+		}
+		return reqs, true
+	}
+	var req request
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, false
+	}
+	return []request{req}, false
+}
+
+type errorResponse struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Error   errorMessage    `json:"error"`
+}
+
+type errorMessage struct {
+	Code    int       `json:"code"`
+	Message string    `json:"message"`
+	Data    retryData `json:"data"`
+}
+
+type retryData struct {
+	RetryAfter int    `json:"retryAfter"`
+	Class      string `json:"class"`
+}
+
+// ewma is the exponentially weighted moving average of the request latency
+type ewma struct {
+	mtx sync.Mutex
+	avg time.Duration
+}
+
+const ewmaWeight = 0.1
+
+func (e *ewma) add(d time.Duration) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	if e.avg == 0 {
+		e.avg = d
+		return
+	}
+	e.avg = time.Duration(ewmaWeight*float64(d) + (1-ewmaWeight)*float64(e.avg))
+}
+
+func (e *ewma) value() time.Duration {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	return e.avg
+}
@@ -0,0 +1,168 @@
+package admission
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func latestHeight(height int64) func() (int64, error) {
+	return func() (int64, error) { return height, nil }
+}
+
+func TestClassify(t *testing.T) {
+	c := NewController(Config{WideLogsSpan: 100}, latestHeight(1000))
+
+	testCases := []struct {
+		body  string
+		class Class
+	}{
+		{`{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction","params":["0x00"]}`, ClassTx},
+		{`{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[{},"latest"]}`, ClassCall},
+		{`{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber","params":[]}`, ClassDefault},
+		{`{"jsonrpc":"2.0","id":1,"method":"eth_getTxTrace","params":["0x00"]}`, ClassLow},
+		{`{"jsonrpc":"2.0","id":1,"method":"debug_traceTransaction","params":["0x00"]}`, ClassLow},
+		// the logs queries are classified by their span
+		{`{"jsonrpc":"2.0","id":1,"method":"eth_getLogs","params":[{"fromBlock":"0x1","toBlock":"0x65"}]}`, ClassDefault},
+		{`{"jsonrpc":"2.0","id":1,"method":"eth_getLogs","params":[{"fromBlock":"0x1","toBlock":"0x66"}]}`, ClassLow},
+		{`{"jsonrpc":"2.0","id":1,"method":"eth_getLogs","params":[{"fromBlock":"0x384"}]}`, ClassDefault},
+		{`{"jsonrpc":"2.0","id":1,"method":"eth_getLogs","params":[{"fromBlock":"0x1","toBlock":"latest"}]}`, ClassLow},
+		{`{"jsonrpc":"2.0","id":1,"method":"eth_getLogs","params":[{"fromBlock":"earliest"}]}`, ClassLow},
+		{`{"jsonrpc":"2.0","id":1,"method":"eth_getLogs","params":[{}]}`, ClassDefault},
+		{`{"jsonrpc":"2.0","id":1,"method":"eth_getLogs","params":[{"blockHash":"0x00","fromBlock":"earliest"}]}`, ClassDefault},
+		// a batch is in the lowest class of its requests
+		{`[{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction","params":["0x00"]},
+			{"jsonrpc":"2.0","id":2,"method":"eth_call","params":[{},"latest"]}]`, ClassCall},
+		{`[{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction","params":["0x00"]},
+			{"jsonrpc":"2.0","id":2,"method":"eth_getTxTrace","params":["0x00"]}]`, ClassLow},
+		{`invalid`, ClassDefault},
+	}
+	for _, tc := range testCases {
+		reqs, _ := parseRequests([]byte(tc.body))
+		require.Equal(t, tc.class, c.classify(reqs), tc.body)
+	}
+
+	// the span up to the latest block is unknown without the latest height
+	c = NewController(Config{WideLogsSpan: 100}, func() (int64, error) { return 0, errors.New("unavailable") })
+	reqs, _ := parseRequests([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_getLogs","params":[{"fromBlock":"0x1"}]}`))
+	require.Equal(t, ClassDefault, c.classify(reqs))
+}
+
+const (
+	txBody   = `{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction","params":["0x00"]}`
+	callBody = `{"jsonrpc":"2.0","id":2,"method":"eth_call","params":[{},"latest"]}`
+	lowBody  = `{"jsonrpc":"2.0","id":3,"method":"eth_getTxTrace","params":["0x00"]}`
+)
+
+// blockingServer serves the requests until they are released, the body is echoed to check it's forwarded
+type blockingServer struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlockingServer() *blockingServer {
+	return &blockingServer{started: make(chan struct{}, 10), release: make(chan struct{})}
+}
+
+func (s *blockingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	s.started <- struct{}{}
+	<-s.release
+	_, _ = w.Write(body)
+}
+
+func post(h http.Handler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestControllerShedding(t *testing.T) {
+	var cfg Config
+	cfg.Limits[ClassCall] = 1
+	cfg.Limits[ClassLow] = 2
+	cfg.QueueTimeout = 200 * time.Millisecond
+	next := newBlockingServer()
+	h := NewController(cfg, latestHeight(0)).Handler(next)
+
+	// the request is forwarded with its body
+	calls := make(chan *httptest.ResponseRecorder, 2)
+	go func() { calls <- post(h, callBody) }()
+	<-next.started
+
+	// the call class is full, the next call is rejected after waiting in the queue
+	start := time.Now()
+	rec := post(h, callBody)
+	require.True(t, time.Since(start) >= cfg.QueueTimeout)
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "1", rec.Header().Get("Retry-After"))
+	var resp errorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Equal(t, "2", string(resp.ID))
+	require.Equal(t, ErrCodeOverloaded, resp.Error.Code)
+	require.Equal(t, retryData{RetryAfter: 1, Class: "call"}, resp.Error.Data)
+
+	// the low class is shed while a call is queued, though it has free slots
+	go func() { calls <- post(h, callBody) }()
+	time.Sleep(20 * time.Millisecond)
+	rec = post(h, lowBody)
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	rec = <-calls
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+	// the tx class isn't limited
+	go func() { calls <- post(h, txBody) }()
+	<-next.started
+	close(next.release)
+	require.Equal(t, callBody, (<-calls).Body.String())
+	require.Equal(t, txBody, (<-calls).Body.String())
+
+	// the slots are released
+	rec = post(h, lowBody)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, lowBody, rec.Body.String())
+}
+
+func TestControllerBatchRejection(t *testing.T) {
+	var cfg Config
+	cfg.Limits[ClassLow] = 1
+	next := newBlockingServer()
+	h := NewController(cfg, latestHeight(0)).Handler(next)
+
+	done := make(chan struct{})
+	go func() {
+		post(h, lowBody)
+		close(done)
+	}()
+	<-next.started
+
+	// the low class requests aren't queued
+	rec := post(h, "["+txBody+","+lowBody+"]")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	var resps []errorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resps))
+	require.Len(t, resps, 2)
+	require.Equal(t, "1", string(resps[0].ID))
+	require.Equal(t, "3", string(resps[1].ID))
+	require.Equal(t, "low", resps[1].Error.Data.Class)
+
+	close(next.release)
+	<-done
+}
+
+func TestRetryAfter(t *testing.T) {
+	c := NewController(Config{}, nil)
+	require.Equal(t, 1, c.retryAfter(ClassDefault))
+	c.latency[ClassDefault].add(2500 * time.Millisecond)
+	require.Equal(t, 3, c.retryAfter(ClassDefault))
+	c.latency[ClassDefault].add(0)
+	require.Equal(t, 3, c.retryAfter(ClassDefault))
+}
@@ -1,15 +1,22 @@
 package rpc
 
 import (
+	gocontext "context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"unicode"
 
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	ethfilters "github.com/ethereum/go-ethereum/eth/filters"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/go-kit/kit/metrics/prometheus"
 	"github.com/okex/exchain/app/rpc/namespaces/eth/txpool"
 	"github.com/okex/exchain/libs/cosmos-sdk/client/context"
+	"github.com/okex/exchain/libs/cosmos-sdk/client/flags"
 	"github.com/okex/exchain/libs/tendermint/libs/log"
 	evmtypes "github.com/okex/exchain/x/evm/types"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
@@ -17,14 +24,27 @@ import (
 	"golang.org/x/time/rate"
 
 	"github.com/okex/exchain/app/crypto/ethsecp256k1"
+	"github.com/okex/exchain/app/rpc/admission"
 	"github.com/okex/exchain/app/rpc/backend"
+	"github.com/okex/exchain/app/rpc/lightclient"
 	"github.com/okex/exchain/app/rpc/monitor"
+	"github.com/okex/exchain/app/rpc/namespaces/admin"
+	"github.com/okex/exchain/app/rpc/namespaces/dev"
 	"github.com/okex/exchain/app/rpc/namespaces/eth"
 	"github.com/okex/exchain/app/rpc/namespaces/eth/filters"
+	"github.com/okex/exchain/app/rpc/namespaces/exchain"
 	"github.com/okex/exchain/app/rpc/namespaces/net"
 	"github.com/okex/exchain/app/rpc/namespaces/personal"
 	"github.com/okex/exchain/app/rpc/namespaces/web3"
+	"github.com/okex/exchain/app/rpc/namespaces/webhook"
+	"github.com/okex/exchain/app/rpc/readonly"
+	"github.com/okex/exchain/app/rpc/respencode"
+	"github.com/okex/exchain/app/rpc/sanitize"
+	"github.com/okex/exchain/app/rpc/stream"
 	rpctypes "github.com/okex/exchain/app/rpc/types"
+	"github.com/okex/exchain/app/rpc/verifier"
+	rpcwebhook "github.com/okex/exchain/app/rpc/webhook"
+	"github.com/okex/exchain/x/evm/watcher"
 )
 
 // RPC namespaces and API version
@@ -34,25 +54,58 @@ const (
 	PersonalNamespace = "personal"
 	NetNamespace      = "net"
 	TxpoolNamespace   = "txpool"
+	EvmNamespace      = "evm"
+	HardhatNamespace  = "hardhat"
+	ExchainNamespace  = "exchain"
+	AdminNamespace    = "admin"
+	WebhookNamespace  = "webhook"
 
 	apiVersion = "1.0"
 )
 
-var ethBackend *backend.EthermintBackend
+var (
+	ethBackend       *backend.EthermintBackend
+	contractVerifier *verifier.Verifier
+	logStreamer      *filters.LogStreamer
+)
 
 func CloseEthBackend() {
 	if ethBackend != nil {
 		ethBackend.Close()
 	}
+	if contractVerifier != nil {
+		contractVerifier.Close()
+	}
 }
 
 // GetAPIs returns the list of all APIs from the Ethereum namespaces
 func GetAPIs(clientCtx context.CLIContext, log log.Logger, keys ...ethsecp256k1.PrivKey) []rpc.API {
+	// the blocks and the txs of an untrusted node are verified against the headers certified by the light client
+	if !clientCtx.TrustNode {
+		if clientCtx.Verifier == nil {
+			panic(errors.New("the rpc server of an untrusted node requires a light client verifier, set --chain-id and --home or --trust-node"))
+		}
+		clientCtx.Client = lightclient.New(clientCtx.Client, clientCtx.Verifier)
+		log.Info("verifying the blocks and the txs of the untrusted node", "node", clientCtx.NodeURI)
+	}
+	rpctypes.SetStrictInput(viper.GetBool(FlagStrictInput))
 	nonceLock := new(rpctypes.AddrLocker)
 	rateLimiters := getRateLimiter()
 	disableAPI := getDisableAPI()
 	ethBackend = backend.New(clientCtx, log, rateLimiters, disableAPI)
 	ethAPI := eth.NewAPI(clientCtx, log, ethBackend, nonceLock, keys...)
+	filterAPI := filters.NewAPI(clientCtx, log, ethBackend)
+	exchainAPI := exchain.NewAPI(clientCtx, log, rateLimiters)
+	exchainAPI.SetLogsFilter(filterAPI)
+	logStreamer = filters.NewLogStreamer(filterAPI)
+	if solcDir := viper.GetString(FlagVerifierSolcDir); solcDir != "" {
+		var err error
+		contractVerifier, err = verifier.NewVerifier(filepath.Join(viper.GetString(flags.FlagHome), "data"), solcDir)
+		if err != nil {
+			panic(err)
+		}
+		exchainAPI.SetVerifier(contractVerifier)
+	}
 	if evmtypes.GetEnableBloomFilter() {
 		ethBackend.StartBloomHandlers(evmtypes.BloomBitsBlocks, evmtypes.GetIndexer().GetDB())
 	}
@@ -73,7 +126,7 @@ func GetAPIs(clientCtx context.CLIContext, log log.Logger, keys ...ethsecp256k1.
 		{
 			Namespace: EthNamespace,
 			Version:   apiVersion,
-			Service:   filters.NewAPI(clientCtx, log, ethBackend),
+			Service:   filterAPI,
 			Public:    true,
 		},
 		{
@@ -88,9 +141,15 @@ func GetAPIs(clientCtx context.CLIContext, log log.Logger, keys ...ethsecp256k1.
 			Service:   txpool.NewAPI(clientCtx, log, ethBackend),
 			Public:    true,
 		},
+		{
+			Namespace: ExchainNamespace,
+			Version:   apiVersion,
+			Service:   exchainAPI,
+			Public:    true,
+		},
 	}
 
-	if viper.GetBool(FlagPersonalAPI) {
+	if viper.GetBool(FlagPersonalAPI) && !viper.GetBool(readonly.FlagReadOnly) {
 		apis = append(apis, rpc.API{
 			Namespace: PersonalNamespace,
 			Version:   apiVersion,
@@ -99,6 +158,56 @@ func GetAPIs(clientCtx context.CLIContext, log log.Logger, keys ...ethsecp256k1.
 		})
 	}
 
+	if viper.GetBool(FlagAdminAPI) {
+		apis = append(apis, rpc.API{
+			Namespace: AdminNamespace,
+			Version:   apiVersion,
+			Service:   admin.NewAPI(log),
+			Public:    false,
+		})
+	}
+
+	// the webhooks are notified of the blocks committed into the watcher db
+	if viper.GetBool(FlagWebhookAPI) && watcher.IsWatcherEnabled() {
+		registry, err := rpcwebhook.NewRegistry(filepath.Join(viper.GetString(flags.FlagHome), "data", "webhooks.json"))
+		if err != nil {
+			panic(err)
+		}
+		rpcwebhook.NewNotifier(registry, log).Start()
+		apis = append(apis, rpc.API{
+			Namespace: WebhookNamespace,
+			Version:   apiVersion,
+			Service:   webhook.NewAPI(registry, log),
+			Public:    false,
+		})
+	}
+
+	if controller := dev.GetController(); controller != nil {
+		ethBackend.SetLatestExecuted()
+		apis = append(apis,
+			rpc.API{
+				Namespace: EvmNamespace,
+				Version:   apiVersion,
+				Service:   dev.NewEvmAPI(controller, log),
+				Public:    true,
+			},
+			rpc.API{
+				Namespace: HardhatNamespace,
+				Version:   apiVersion,
+				Service:   dev.NewHardhatAPI(controller, log),
+				Public:    true,
+			},
+		)
+	}
+
+	var namespaces []string
+	for _, api := range apis {
+		if len(namespaces) == 0 || namespaces[len(namespaces)-1] != api.Namespace {
+			namespaces = append(namespaces, api.Namespace)
+		}
+	}
+	exchainAPI.SetNamespaces(namespaces)
+
 	if viper.GetBool(FlagEnableMonitor) {
 		for _, api := range apis {
 			makeMonitorMetrics(api.Namespace, api.Service)
@@ -122,6 +231,49 @@ func getRateLimiter() map[string]*rate.Limiter {
 	return rateLimiters
 }
 
+func getAdmissionConfig() admission.Config {
+	var cfg admission.Config
+	cfg.Limits[admission.ClassTx] = viper.GetInt(FlagAdmissionTxLimit)
+	cfg.Limits[admission.ClassCall] = viper.GetInt(FlagAdmissionCallLimit)
+	cfg.Limits[admission.ClassDefault] = viper.GetInt(FlagAdmissionDefaultLimit)
+	cfg.Limits[admission.ClassLow] = viper.GetInt(FlagAdmissionLowLimit)
+	cfg.QueueTimeout = viper.GetDuration(FlagAdmissionQueueTimeout)
+	cfg.WideLogsSpan = viper.GetInt64(FlagAdmissionWideLogsSpan)
+	return cfg
+}
+
+func getEncodingConfig() respencode.Config {
+	return respencode.Config{
+		Compression: viper.GetBool(FlagCompression),
+		MinSize:     viper.GetInt(FlagCompressionMinSize),
+		Msgpack:     viper.GetBool(FlagMsgpack),
+	}
+}
+
+// getStreamedMethods returns the methods whose responses are streamed on demand, once the apis are created
+func getStreamedMethods() map[string]stream.Func {
+	return map[string]stream.Func{
+		"eth_getLogs": func(ctx gocontext.Context, params json.RawMessage, emit func(interface{}) error) error {
+			var args []ethfilters.FilterCriteria
+			if err := json.Unmarshal(params, &args); err != nil {
+				return stream.ErrInvalidParams{Err: err}
+			}
+			if len(args) != 1 {
+				return stream.ErrInvalidParams{Err: fmt.Errorf("expected 1 argument, got %d", len(args))}
+			}
+			return logStreamer.StreamLogs(ctx, args[0], func(log *ethtypes.Log) error { return emit(log) })
+		},
+	}
+}
+
+func getSanitizeConfig() sanitize.Config {
+	return sanitize.Config{
+		MaxBodySize:     viper.GetInt64(FlagMaxBodySize),
+		MaxParamsDepth:  viper.GetInt(FlagMaxParamsDepth),
+		MaxParamsLength: viper.GetInt(FlagMaxParamsLength),
+	}
+}
+
 func getDisableAPI() map[string]bool {
 	disableAPI := viper.GetString(FlagDisableAPI)
 	apiMap := make(map[string]bool)
@@ -3,9 +3,11 @@ package backend
 import (
 	"context"
 	"fmt"
+	"math/big"
 
 	"github.com/okex/exchain/x/evm/watcher"
 	"github.com/okex/exchain/libs/tendermint/libs/log"
+	"github.com/okex/exchain/libs/tendermint/libs/tracing"
 	"golang.org/x/time/rate"
 
 	rpctypes "github.com/okex/exchain/app/rpc/types"
@@ -66,6 +68,7 @@ type EthermintBackend struct {
 	wrappedBackend    *watcher.Querier
 	rateLimiters      map[string]*rate.Limiter
 	disableAPI        map[string]bool
+	latestExecuted    bool
 }
 
 // New creates a new EthermintBackend instance
@@ -83,8 +86,22 @@ func New(clientCtx clientcontext.CLIContext, log log.Logger, rateLimiters map[st
 	}
 }
 
+// SetLatestExecuted makes the latest block the last one executed by the app, instead of the one before the last
+// block. The blocks of the dev mode are sealed on demand, the last one would otherwise stay hidden.
+func (b *EthermintBackend) SetLatestExecuted() {
+	b.latestExecuted = true
+}
+
 // BlockNumber returns the current block number.
 func (b *EthermintBackend) BlockNumber() (hexutil.Uint64, error) {
+	if b.latestExecuted {
+		info, err := b.clientCtx.Client.ABCIInfo()
+		if err != nil {
+			return hexutil.Uint64(0), err
+		}
+		return hexutil.Uint64(info.Response.LastBlockHeight), nil
+	}
+
 	ublockNumber, err := b.wrappedBackend.GetLatestBlockNumber()
 	if err == nil {
 		if ublockNumber > 0 {
@@ -107,8 +124,10 @@ func (b *EthermintBackend) BlockNumber() (hexutil.Uint64, error) {
 
 // GetBlockByNumber returns the block identified by number.
 func (b *EthermintBackend) GetBlockByNumber(blockNum rpctypes.BlockNumber, fullTx bool) (interface{}, error) {
-	ethBlock, err := b.wrappedBackend.GetBlockByNumber(uint64(blockNum), fullTx)
-	if err == nil {
+	defer tracing.StartSpan("backend GetBlockByNumber").End()
+
+	ethBlock, wrappedErr := b.wrappedBackend.GetBlockByNumber(uint64(blockNum), fullTx)
+	if wrappedErr == nil {
 		return ethBlock, nil
 	}
 	height := blockNum.Int64()
@@ -126,14 +145,17 @@ func (b *EthermintBackend) GetBlockByNumber(blockNum rpctypes.BlockNumber, fullT
 	if err != nil {
 		return nil, nil
 	}
+	b.wrappedBackend.ReportMiss(watcher.DataBlock, wrappedErr)
 
 	return rpctypes.EthBlockFromTendermint(b.clientCtx, resBlock.Block, fullTx)
 }
 
 // GetBlockByHash returns the block identified by hash.
 func (b *EthermintBackend) GetBlockByHash(hash common.Hash, fullTx bool) (interface{}, error) {
-	ethBlock, err := b.wrappedBackend.GetBlockByHash(hash, fullTx)
-	if err == nil {
+	defer tracing.StartSpan("backend GetBlockByHash").End()
+
+	ethBlock, wrappedErr := b.wrappedBackend.GetBlockByHash(hash, fullTx)
+	if wrappedErr == nil {
 		return ethBlock, nil
 	}
 	res, _, err := b.clientCtx.Query(fmt.Sprintf("custom/%s/%s/%s", evmtypes.ModuleName, evmtypes.QueryHashToHeight, hash.Hex()))
@@ -150,12 +172,15 @@ func (b *EthermintBackend) GetBlockByHash(hash common.Hash, fullTx bool) (interf
 	if err != nil {
 		return nil, nil
 	}
+	b.wrappedBackend.ReportMiss(watcher.DataBlock, wrappedErr)
 
 	return rpctypes.EthBlockFromTendermint(b.clientCtx, resBlock.Block, fullTx)
 }
 
 // HeaderByNumber returns the block header identified by height.
 func (b *EthermintBackend) HeaderByNumber(blockNum rpctypes.BlockNumber) (*ethtypes.Header, error) {
+	defer tracing.StartSpan("backend HeaderByNumber").End()
+
 	height := blockNum.Int64()
 	if height <= 0 {
 		// get latest block height
@@ -187,6 +212,11 @@ func (b *EthermintBackend) HeaderByNumber(blockNum rpctypes.BlockNumber) (*ethty
 
 // HeaderByHash returns the block header identified by hash.
 func (b *EthermintBackend) HeaderByHash(blockHash common.Hash) (*ethtypes.Header, error) {
+	defer tracing.StartSpan("backend HeaderByHash").End()
+
+	if block, err := b.wrappedBackend.GetBlockByHash(blockHash, false); err == nil {
+		return ethHeaderFromWatcher(block), nil
+	}
 	res, _, err := b.clientCtx.Query(fmt.Sprintf("custom/%s/%s/%s", evmtypes.ModuleName, evmtypes.QueryHashToHeight, blockHash.Hex()))
 	if err != nil {
 		return nil, err
@@ -215,10 +245,33 @@ func (b *EthermintBackend) HeaderByHash(blockHash common.Hash) (*ethtypes.Header
 	return ethHeader, nil
 }
 
+// ethHeaderFromWatcher returns the header of a block of the watcher
+func ethHeaderFromWatcher(block *watcher.EthBlock) *ethtypes.Header {
+	header := &ethtypes.Header{
+		ParentHash:  block.ParentHash,
+		UncleHash:   ethtypes.EmptyUncleHash,
+		Coinbase:    block.Miner,
+		Root:        block.StateRoot,
+		TxHash:      block.TransactionsRoot,
+		ReceiptHash: ethtypes.EmptyRootHash,
+		Bloom:       block.LogsBloom,
+		Number:      new(big.Int).SetUint64(uint64(block.Number)),
+		GasLimit:    uint64(block.GasLimit),
+		Time:        uint64(block.Timestamp),
+		Extra:       block.ExtraData,
+	}
+	if block.GasUsed != nil {
+		header.GasUsed = block.GasUsed.ToInt().Uint64()
+	}
+	return header
+}
+
 // GetTransactionLogs returns the logs given a transaction hash.
 // It returns an error if there's an encoding error.
 // If no logs are found for the tx hash, the error is nil.
 func (b *EthermintBackend) GetTransactionLogs(txHash common.Hash) ([]*ethtypes.Log, error) {
+	defer tracing.StartSpan("backend GetTransactionLogs").End()
+
 	txRes, err := b.clientCtx.Client.Tx(txHash.Bytes(), !b.clientCtx.TrustNode)
 	if err != nil {
 		return nil, err
@@ -330,6 +383,11 @@ func (b *EthermintBackend) PendingTransactionsByHash(target common.Hash) (*rpcty
 
 // GetLogs returns all the logs from all the ethereum transactions in a block.
 func (b *EthermintBackend) GetLogs(blockHash common.Hash) ([][]*ethtypes.Log, error) {
+	defer tracing.StartSpan("backend GetLogs").End()
+
+	if blockLogs, err := b.wrappedBackend.GetBlockLogs(blockHash); err == nil {
+		return blockLogs, nil
+	}
 	res, _, err := b.clientCtx.Query(fmt.Sprintf("custom/%s/%s/%s", evmtypes.ModuleName, evmtypes.QueryHashToHeight, blockHash.Hex()))
 	if err != nil {
 		return nil, err
@@ -0,0 +1,76 @@
+package backend
+
+import (
+	"fmt"
+	"time"
+
+	rpctypes "github.com/okex/exchain/app/rpc/types"
+	"github.com/okex/exchain/x/evm/watcher"
+)
+
+// warmUpPollInterval is the interval the height of the watcher is compared to the one of the chain on
+const warmUpPollInterval = 100 * time.Millisecond
+
+// WarmUp waits for the watcher to catch up with the chain, then reads the latest blocks, their headers and the
+// receipts of their txs through the backend, so that the first requests after a restart hit the warm caches of
+// the watch db and the block store instead of all falling back to the chain at once. It gives up waiting on the
+// timeout and returns an error once the blocks are read, the stale watcher is then served with its fallbacks.
+func (b *EthermintBackend) WarmUp(blocks int, timeout time.Duration) error {
+	start := time.Now()
+	latest, freshErr := b.waitWatcher(timeout)
+	if latest == 0 {
+		return freshErr
+	}
+
+	var read, failed int
+	for height := latest; height > 0 && height > latest-int64(blocks); height-- {
+		if err := b.warmUpBlock(rpctypes.BlockNumber(height)); err != nil {
+			b.logger.Debug("failed to warm up block", "height", height, "err", err)
+			failed++
+			continue
+		}
+		read++
+	}
+	b.logger.Info("rpc caches warmed up", "latest", latest, "blocks", read, "failed", failed, "elapsed", time.Since(start))
+	return freshErr
+}
+
+// waitWatcher returns the latest height of the chain once the watcher has caught up with it, or on the timeout
+// along with an error
+func (b *EthermintBackend) waitWatcher(timeout time.Duration) (int64, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		latest, err := b.LatestBlockNumber()
+		if err != nil {
+			return 0, err
+		}
+		if !watcher.IsWatcherEnabled() {
+			return latest, nil
+		}
+		watched, err := b.wrappedBackend.GetLatestBlockNumber()
+		// the data of the last block is written by the watcher once it's committed
+		if err == nil && int64(watched)+1 >= latest {
+			return latest, nil
+		}
+		if time.Now().After(deadline) {
+			return latest, fmt.Errorf("the watcher is at height %d behind the chain at %d after %s", watched, latest, timeout)
+		}
+		time.Sleep(warmUpPollInterval)
+	}
+}
+
+// warmUpBlock reads the header, the block with its txs and the receipts of the block of the height
+func (b *EthermintBackend) warmUpBlock(height rpctypes.BlockNumber) error {
+	if _, err := b.HeaderByNumber(height); err != nil {
+		return err
+	}
+	hash, err := b.GetBlockHashByHeight(height)
+	if err != nil {
+		return err
+	}
+	if _, err := b.GetBlockByHash(hash, true); err != nil {
+		return err
+	}
+	_, err = b.GetLogs(hash)
+	return err
+}
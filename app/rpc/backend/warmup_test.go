@@ -0,0 +1,118 @@
+package backend
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	clientcontext "github.com/okex/exchain/libs/cosmos-sdk/client/context"
+	"github.com/okex/exchain/libs/cosmos-sdk/client/flags"
+	"github.com/okex/exchain/libs/tendermint/libs/log"
+	rpcclient "github.com/okex/exchain/libs/tendermint/rpc/client"
+	ctypes "github.com/okex/exchain/libs/tendermint/rpc/core/types"
+	"github.com/okex/exchain/x/evm/watcher"
+)
+
+// chainNode is the client of a node at the latest height, the blocks it's asked for are recorded and not found
+type chainNode struct {
+	rpcclient.Client
+	latest   int64
+	infoErr  error
+	mtx      sync.Mutex
+	requests []int64
+}
+
+func (n *chainNode) BlockchainInfo(minHeight, maxHeight int64) (*ctypes.ResultBlockchainInfo, error) {
+	if n.infoErr != nil {
+		return nil, n.infoErr
+	}
+	return &ctypes.ResultBlockchainInfo{LastHeight: n.latest}, nil
+}
+
+func (n *chainNode) Block(height *int64) (*ctypes.ResultBlock, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	n.requests = append(n.requests, *height)
+	return nil, errors.New("block not found")
+}
+
+func (n *chainNode) requested() []int64 {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	return n.requests
+}
+
+// newWarmUpBackend returns the backend of the node, with the watcher enabled on an in-memory watch db
+func newWarmUpBackend(t *testing.T, n *chainNode) *EthermintBackend {
+	viper.Set(watcher.FlagFastQuery, true)
+	viper.Set(watcher.FlagFastQueryLru, 100)
+	viper.Set(watcher.FlagDBBackend, string(dbm.MemDBBackend))
+	viper.Set(flags.FlagHome, t.TempDir())
+	return New(clientcontext.CLIContext{Client: n}, log.NewNopLogger(), nil, nil)
+}
+
+// setWatchedHeight sets the latest height written by the watcher
+func setWatchedHeight(height uint64) {
+	msg := watcher.NewMsgLatestHeight(height)
+	watcher.InstanceOfWatchStore().Set(msg.GetKey(), []byte(msg.GetValue()))
+}
+
+func TestWarmUp(t *testing.T) {
+	n := &chainNode{latest: 10}
+	b := newWarmUpBackend(t, n)
+	setWatchedHeight(9)
+
+	// the blocks that fail to be read don't fail the warm-up
+	require.NoError(t, b.WarmUp(3, time.Second))
+	require.Equal(t, []int64{10, 9, 8}, n.requested())
+
+	// it stops at the first block
+	n.requests = nil
+	require.NoError(t, b.WarmUp(20, time.Second))
+	require.Equal(t, []int64{10, 9, 8, 7, 6, 5, 4, 3, 2, 1}, n.requested())
+}
+
+func TestWarmUpWaitsForWatcher(t *testing.T) {
+	n := &chainNode{latest: 10}
+	b := newWarmUpBackend(t, n)
+	setWatchedHeight(5)
+
+	caughtUp := make(chan struct{})
+	go func() {
+		time.Sleep(3 * warmUpPollInterval)
+		setWatchedHeight(9)
+		close(caughtUp)
+	}()
+	require.NoError(t, b.WarmUp(1, 10*time.Second))
+	select {
+	case <-caughtUp:
+	default:
+		t.Fatal("the warm-up didn't wait for the watcher")
+	}
+	require.Equal(t, []int64{10}, n.requested())
+}
+
+func TestWarmUpTimeout(t *testing.T) {
+	n := &chainNode{latest: 10}
+	b := newWarmUpBackend(t, n)
+	setWatchedHeight(5)
+
+	// the blocks are read anyway once the watcher is given up on
+	err := b.WarmUp(2, 2*warmUpPollInterval)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "the watcher is at height 5 behind the chain at 10")
+	require.Equal(t, []int64{10, 9}, n.requested())
+}
+
+func TestWarmUpChainError(t *testing.T) {
+	n := &chainNode{latest: 10, infoErr: errors.New("node unavailable")}
+	b := newWarmUpBackend(t, n)
+
+	require.EqualError(t, b.WarmUp(3, time.Second), "node unavailable")
+	require.Empty(t, n.requested())
+}
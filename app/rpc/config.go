@@ -3,7 +3,9 @@ package rpc
 import (
 	"bufio"
 	"fmt"
+	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/okex/exchain/libs/cosmos-sdk/client/flags"
@@ -13,10 +15,22 @@ import (
 	cmserver "github.com/okex/exchain/libs/cosmos-sdk/server"
 	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/okex/exchain/app/analytics"
 	"github.com/okex/exchain/app/crypto/ethsecp256k1"
 	"github.com/okex/exchain/app/crypto/hd"
+	"github.com/okex/exchain/app/eventsink"
+	"github.com/okex/exchain/app/rpc/admission"
+	"github.com/okex/exchain/app/rpc/dedup"
 	"github.com/okex/exchain/app/rpc/pendingtx"
+	"github.com/okex/exchain/app/rpc/readonly"
+	"github.com/okex/exchain/app/rpc/respcache"
+	"github.com/okex/exchain/app/rpc/respencode"
+	"github.com/okex/exchain/app/rpc/sanitize"
+	"github.com/okex/exchain/app/rpc/stream"
+	"github.com/okex/exchain/app/rpc/traffic"
 	"github.com/okex/exchain/app/rpc/websockets"
+	evmgrpc "github.com/okex/exchain/x/evm/client/grpc"
+	"github.com/okex/exchain/x/evm/watcher"
 	"github.com/spf13/viper"
 )
 
@@ -25,6 +39,8 @@ const (
 	flagWebsocket = "wsport"
 
 	FlagPersonalAPI    = "personal-api"
+	FlagAdminAPI       = "admin-api"
+	FlagWebhookAPI     = "webhook-api"
 	FlagRateLimitAPI   = "rpc.rate-limit-api"
 	FlagRateLimitCount = "rpc.rate-limit-count"
 	FlagRateLimitBurst = "rpc.rate-limit-burst"
@@ -32,6 +48,42 @@ const (
 	FlagDisableAPI     = "rpc.disable-api"
 	FlagKafkaAddr      = "pendingtx.kafka-addr"
 	FlagKafkaTopic     = "pendingtx.kafka-topic"
+	FlagGRPCAddress    = "grpc.address"
+
+	// flags of the admission controller, which limits the concurrent requests per priority class
+	FlagAdmissionTxLimit      = "rpc.admission-tx-limit"
+	FlagAdmissionCallLimit    = "rpc.admission-call-limit"
+	FlagAdmissionDefaultLimit = "rpc.admission-default-limit"
+	FlagAdmissionLowLimit     = "rpc.admission-low-limit"
+	FlagAdmissionQueueTimeout = "rpc.admission-queue-timeout"
+	FlagAdmissionWideLogsSpan = "rpc.admission-wide-logs-span"
+
+	FlagResponseCache     = "rpc.response-cache"
+	FlagResponseCacheSize = "rpc.response-cache-size"
+
+	FlagDedup = "rpc.dedup"
+
+	FlagCompression        = "rpc.compression"
+	FlagCompressionMinSize = "rpc.compression-min-size"
+	FlagMsgpack            = "rpc.msgpack"
+
+	FlagStrictInput = "rpc.strict-input"
+
+	// flags of the sanitization of the requests, which bounds their body and their params
+	FlagMaxBodySize     = "rpc.max-body-size"
+	FlagMaxParamsDepth  = "rpc.max-params-depth"
+	FlagMaxParamsLength = "rpc.max-params-length"
+
+	FlagRecordTraffic = "rpc.record-traffic"
+
+	// FlagStream enables the streamed responses of eth_getLogs negotiated by the X-Rpc-Stream header
+	FlagStream = "rpc.stream"
+
+	// flags of the warm-up of the caches before the rpc server starts serving
+	FlagWarmUpBlocks  = "rpc.warmup-blocks"
+	FlagWarmUpTimeout = "rpc.warmup-timeout"
+
+	FlagVerifierSolcDir = "rpc.verifier-solc-dir"
 
 	MetricsNamespace = "x"
 	// MetricsSubsystem is a subsystem shared by all metrics exposed by this package.
@@ -48,7 +100,10 @@ func RegisterRoutes(rs *lcd.RestServer) {
 	accountNames := strings.Split(accountName, ",")
 
 	var privkeys []ethsecp256k1.PrivKey
-	if len(accountName) > 0 {
+	readOnly := viper.GetBool(readonly.FlagReadOnly)
+	if len(accountName) > 0 && readOnly {
+		rs.Logger().Error("the keys aren't unlocked, the rpc server is read-only", "keys", accountName)
+	} else if len(accountName) > 0 {
 		var err error
 		inBuf := bufio.NewReader(os.Stdin)
 
@@ -74,6 +129,14 @@ func RegisterRoutes(rs *lcd.RestServer) {
 
 	apis := GetAPIs(rs.CliCtx, rs.Logger(), privkeys...)
 
+	// the routes are registered before the rest server and the websocket server listen, the caches are warm
+	// once they do
+	if blocks := viper.GetInt(FlagWarmUpBlocks); blocks > 0 {
+		if err := ethBackend.WarmUp(blocks, viper.GetDuration(FlagWarmUpTimeout)); err != nil {
+			rs.Logger().Error("rpc warm-up incomplete, serving anyway", "err", err)
+		}
+	}
+
 	// Register all the APIs exposed by the namespace services
 	// TODO: handle allowlist and private APIs
 	for _, api := range apis {
@@ -83,12 +146,62 @@ func RegisterRoutes(rs *lcd.RestServer) {
 	}
 
 	// Web3 RPC API route
-	rs.Mux.HandleFunc("/", server.ServeHTTP).Methods("POST", "OPTIONS")
+	var handler http.Handler = server
+	var admit func(http.Handler) http.Handler
+	if cfg := getAdmissionConfig(); cfg.Enabled() {
+		admit = admission.NewController(cfg, ethBackend.LatestBlockNumber).Handler
+		handler = admit(server)
+	}
+	// the identical requests in flight take a single slot of the admission controller
+	if viper.GetBool(FlagDedup) {
+		handler = dedup.New().Handler(handler)
+	}
+	// the cached responses don't take the slots of the admission controller
+	if cachedMethods := viper.GetString(FlagResponseCache); cachedMethods != "" {
+		ttls, err := respcache.ParseTTLs(cachedMethods)
+		if err != nil {
+			panic(err)
+		}
+		cache, err := respcache.New(ttls, viper.GetInt(FlagResponseCacheSize), ethBackend.LatestBlockNumber)
+		if err != nil {
+			panic(err)
+		}
+		handler = cache.Handler(handler)
+	}
+	// the recorded responses are the ones of the node, cached or not, before their encoding
+	if path := viper.GetString(FlagRecordTraffic); path != "" {
+		recorder, err := traffic.NewRecorder(path, ethBackend.LatestBlockNumber)
+		if err != nil {
+			panic(err)
+		}
+		handler = recorder.Handler(handler)
+		rs.RegisterOnShutdown(func() { _ = recorder.Close() })
+	}
+	// the cached responses are encoded as well
+	if cfg := getEncodingConfig(); cfg.Enabled() {
+		handler = respencode.Handler(cfg, handler)
+	}
+	// the streamed responses aren't buffered by the handlers above, they're only admitted
+	if viper.GetBool(FlagStream) {
+		handler = stream.Handler(getStreamedMethods(), admit, handler)
+	}
+	// the disabled methods are rejected before anything else but the sanitization
+	if readOnly {
+		handler = readonly.Handler(handler)
+	}
+	// the oversized and malformed requests are rejected before they're decoded by the other handlers
+	if cfg := getSanitizeConfig(); cfg.Enabled() {
+		handler = sanitize.Handler(cfg, handler)
+	}
+	rs.Mux.Handle("/", handler).Methods("POST", "OPTIONS")
 
 	// start websockets server
 	websocketAddr := viper.GetString(flagWebsocket)
-	ws := websockets.NewServer(rs.CliCtx, rs.Logger(), websocketAddr)
+	ws := websockets.NewServer(rs.CliCtx, rs.Logger(), websocketAddr, rs.Security(), rs.TLSConfig())
 	ws.Start()
+	// the websocket clients are sent their close message while the in-flight requests are drained, their
+	// calls are proxied to the rest server until it closes
+	rs.RegisterOnDrain(ws.Stop)
 
 	// pending tx watcher
 	kafkaAddrs := viper.GetString(FlagKafkaAddr)
@@ -98,6 +211,39 @@ func RegisterRoutes(rs *lcd.RestServer) {
 		ptw := pendingtx.NewWatcher(rs.CliCtx, rs.Logger(), kafkaClient)
 		ptw.Start()
 	}
+
+	// continuous export of the chain data for analytics
+	if dir := viper.GetString(analytics.FlagAnalyticsExportDir); dir != "" && watcher.IsWatcherEnabled() {
+		exporter, err := analytics.NewExporter(watcher.InstanceOfWatchStore().GetDB(), analytics.ConfigFromFlags(dir))
+		if err != nil {
+			panic(err)
+		}
+		quit := make(chan struct{})
+		go exporter.Run(viper.GetDuration(analytics.FlagAnalyticsExportInterval), rs.Logger().With("module", "analytics"), quit)
+		rs.RegisterOnShutdown(func() { close(quit) })
+	}
+
+	// publication of the committed blocks to kafka or nats
+	sinkConfig, err := eventsink.ConfigFromViper()
+	if err != nil {
+		panic(err)
+	}
+	if sinkConfig.Type != "" {
+		sink, err := eventsink.NewSink(sinkConfig, rs.CliCtx.Client, filepath.Join(viper.GetString(flags.FlagHome), "data"), rs.Logger())
+		if err != nil {
+			panic(err)
+		}
+		quit := make(chan struct{})
+		go sink.Run(quit)
+		rs.RegisterOnShutdown(func() { close(quit) })
+	}
+
+	// grpc query services
+	if grpcAddr := viper.GetString(FlagGRPCAddress); grpcAddr != "" {
+		if _, err := evmgrpc.StartGRPCServer(rs.CliCtx, rs.Logger(), grpcAddr); err != nil {
+			panic(err)
+		}
+	}
 }
 
 func unlockKeyFromNameAndPassphrase(accountNames []string, passphrase string) ([]ethsecp256k1.PrivKey, error) {
@@ -0,0 +1,185 @@
+package dedup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// HeaderShared is set to "true" on the responses shared with an identical request in flight
+const HeaderShared = "X-Rpc-Shared"
+
+var (
+	// unsharedMethods are the methods which change the state of the node or depend on the client, the
+	// identical requests of them must all be executed
+	unsharedMethods = map[string]bool{
+		"eth_sendRawTransaction":          true,
+		"eth_sendTransaction":             true,
+		"eth_sign":                        true,
+		"eth_signTransaction":             true,
+		"eth_signTypedData":               true,
+		"eth_newFilter":                   true,
+		"eth_newBlockFilter":              true,
+		"eth_newPendingTransactionFilter": true,
+		"eth_getFilterChanges":            true,
+		"eth_getFilterLogs":               true,
+		"eth_uninstallFilter":             true,
+	}
+	unsharedPrefixes = []string{"personal_", "admin_", "webhook_", "miner_"}
+)
+
+func shareable(method string) bool {
+	if unsharedMethods[method] {
+		return false
+	}
+	for _, prefix := range unsharedPrefixes {
+		if strings.HasPrefix(method, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// Deduplicator executes the concurrent identical json-rpc requests once, the requests of the same method
+// with the same params get the response of the first one with their own id.
+type Deduplicator struct {
+	group singleflight.Group
+}
+
+// New creates a deduplicator of the in-flight requests.
+func New() *Deduplicator {
+	return &Deduplicator{}
+}
+
+// result is the response of the executed request
+type result struct {
+	status int
+	header http.Header
+	resp   response
+	// raw is the response body if it isn't a single json-rpc response
+	raw []byte
+}
+
+// Handler wraps the json-rpc handler, sharing the execution of the concurrent identical single requests.
+// The batches are executed as they are.
+func (d *Deduplicator) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil || !shareable(req.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		key, ok := requestKey(req)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		v, _, shared := d.group.Do(key, func() (interface{}, error) {
+			// the execution isn't canceled by the client of the first request, the others wait for it
+			rec := &recorder{header: make(http.Header), status: http.StatusOK}
+			next.ServeHTTP(rec, r.WithContext(detachedContext{r.Context()}))
+			res := &result{status: rec.status, header: rec.header}
+			if err := json.Unmarshal(rec.body.Bytes(), &res.resp); err != nil {
+				res.raw = rec.body.Bytes()
+			}
+			return res, nil
+		})
+		writeResult(w, req.ID, v.(*result), shared)
+	})
+}
+
+// requestKey returns the key of the method and its params, the params are compacted so that their formatting
+// doesn't matter
+func requestKey(req request) (string, bool) {
+	var buf bytes.Buffer
+	if len(req.Params) > 0 {
+		if err := json.Compact(&buf, req.Params); err != nil {
+			return "", false
+		}
+	}
+	return req.Method + buf.String(), true
+}
+
+func writeResult(w http.ResponseWriter, id json.RawMessage, res *result, shared bool) {
+	for key, values := range res.header {
+		if key != "Content-Length" {
+			w.Header()[key] = values
+		}
+	}
+	if shared {
+		w.Header().Set(HeaderShared, "true")
+	}
+	w.WriteHeader(res.status)
+	if res.raw != nil {
+		_, _ = w.Write(res.raw)
+		return
+	}
+	resp := res.resp
+	resp.ID = id
+	if len(resp.ID) == 0 {
+		resp.ID = json.RawMessage("null")
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+type request struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+type response struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   json.RawMessage `json:"error,omitempty"`
+}
+
+// recorder holds the response of the executed request
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+// detachedContext keeps the values of its parent without its cancellation
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
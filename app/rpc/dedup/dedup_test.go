@@ -0,0 +1,88 @@
+package dedup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		body, _ := ioutil.ReadAll(r.Body)
+		var req request
+		_ = json.Unmarshal(body, &req)
+		if req.Method == "eth_getBlockByNumber" {
+			// the identical requests arrive while the first one is executed
+			<-release
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"%s-%d"}`, req.ID, req.Method, n)
+	})
+	var arrived int32
+	handler := New().Handler(next)
+	serve := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		atomic.AddInt32(&arrived, 1)
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		require.Equal(t, http.StatusOK, rec.Code)
+		return rec
+	}
+
+	const n = 5
+	var wg sync.WaitGroup
+	responses := make([]response, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// the formatting of the params doesn't matter
+			params := `["0x1", false]`
+			if i%2 == 0 {
+				params = `["0x1",false]`
+			}
+			rec := serve(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"eth_getBlockByNumber","params":%s}`, i, params))
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &responses[i]))
+		}(i)
+	}
+	// wait for the requests to join the one in flight
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&arrived) == n }, time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for i, resp := range responses {
+		require.Equal(t, fmt.Sprint(i), string(resp.ID))
+		require.Equal(t, `"eth_getBlockByNumber-1"`, string(resp.Result))
+	}
+
+	// the requests which aren't in flight are executed again
+	rec := serve(`{"jsonrpc":"2.0","id":"a","method":"eth_chainId"}`)
+	require.Contains(t, rec.Body.String(), `"id":"a"`)
+	require.Contains(t, rec.Body.String(), "eth_chainId-2")
+	require.Empty(t, rec.Header().Get(HeaderShared))
+
+	// the batches and the unshared methods are executed as they are
+	rec = serve(`[{"jsonrpc":"2.0","id":1,"method":"eth_chainId"}]`)
+	require.Contains(t, rec.Body.String(), "-3")
+	rec = serve(`{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction","params":["0x01"]}`)
+	require.Contains(t, rec.Body.String(), "eth_sendRawTransaction-4")
+}
+
+func TestShareable(t *testing.T) {
+	require.True(t, shareable("eth_getLogs"))
+	require.False(t, shareable("eth_getFilterChanges"))
+	require.False(t, shareable("personal_unlockAccount"))
+}
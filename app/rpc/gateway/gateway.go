@@ -0,0 +1,277 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+
+	"github.com/okex/exchain/app/rpc/admission"
+	"github.com/okex/exchain/app/rpc/respcache"
+	"github.com/okex/exchain/x/common/monitor"
+)
+
+// HeaderChain selects the chain of the requests which aren't prefixed by its path, by its name or its chain id
+const HeaderChain = "X-Chain"
+
+// heightTTL is how long the latest height of an upstream is used by its response cache before it is polled again
+const heightTTL = time.Second
+
+// ChainConfig is a chain backend served by the gateway
+type ChainConfig struct {
+	// Name is the path prefix of the chain, such as "mainnet" for /mainnet
+	Name    string `json:"name"`
+	ChainID string `json:"chain_id"`
+	// Upstream is the url of the json-rpc endpoint of the chain nodes
+	Upstream string `json:"upstream"`
+	// RateLimit is the requests per second of the chain, 0 for no limit
+	RateLimit float64 `json:"rate_limit"`
+	RateBurst int     `json:"rate_burst"`
+	// ResponseCache is the cached methods with their max age, such as "eth_chainId=0", see respcache.ParseTTLs
+	ResponseCache     string `json:"response_cache"`
+	ResponseCacheSize int    `json:"response_cache_size"`
+}
+
+// Config is the chain backends of the gateway
+type Config struct {
+	Chains []ChainConfig `json:"chains"`
+}
+
+// LoadConfig reads the json config file of the gateway
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+	bz, err := ioutil.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(bz, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid gateway config %s: %w", path, err)
+	}
+	return cfg, cfg.Validate()
+}
+
+// Validate checks the chains, their names and chain ids must all be unique as both select a chain
+func (c Config) Validate() error {
+	if len(c.Chains) == 0 {
+		return fmt.Errorf("no chain in the gateway config")
+	}
+	keys := make(map[string]bool)
+	for _, chain := range c.Chains {
+		if chain.Name == "" || strings.Contains(chain.Name, "/") {
+			return fmt.Errorf("invalid chain name %q", chain.Name)
+		}
+		for _, key := range []string{chain.Name, chain.ChainID} {
+			if key == "" {
+				continue
+			}
+			if keys[key] {
+				return fmt.Errorf("duplicate chain %s", key)
+			}
+			keys[key] = true
+		}
+		if u, err := url.Parse(chain.Upstream); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid upstream %q of chain %s", chain.Upstream, chain.Name)
+		}
+		if chain.RateLimit < 0 || (chain.RateLimit > 0 && chain.RateBurst <= 0) {
+			return fmt.Errorf("invalid rate limit of chain %s, the burst must be positive with the limit set", chain.Name)
+		}
+		if chain.ResponseCache != "" {
+			if _, err := respcache.ParseTTLs(chain.ResponseCache); err != nil {
+				return fmt.Errorf("invalid response cache of chain %s: %w", chain.Name, err)
+			}
+			if chain.ResponseCacheSize <= 0 {
+				return fmt.Errorf("the response cache size of chain %s must be positive", chain.Name)
+			}
+		}
+	}
+	return nil
+}
+
+var (
+	requestsOnce sync.Once
+	requests     metrics.Counter
+)
+
+func requestsCounter() metrics.Counter {
+	requestsOnce.Do(func() {
+		requests = prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: monitor.XNameSpace,
+			Subsystem: "rpc",
+			Name:      "gateway_requests",
+			Help:      "the number of requests of the gateway per chain, served or rate limited",
+		}, []string{"chain", "result"})
+	})
+	return requests
+}
+
+// Gateway serves the json-rpc apis of several chains from a single process. The requests select their chain
+// by the path prefix, /mainnet, or by the X-Chain header, and are proxied to its upstream. Each chain has its
+// own rate limiter and response cache, so that the traffic of a chain never throttles nor evicts another one.
+type Gateway struct {
+	chains map[string]*chain // by name and by chain id
+}
+
+type chain struct {
+	name    string
+	limiter *rate.Limiter
+	handler http.Handler
+}
+
+// New creates the gateway of the chains
+func New(cfg Config) (*Gateway, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	g := &Gateway{chains: make(map[string]*chain)}
+	for _, chainCfg := range cfg.Chains {
+		c, err := newChain(chainCfg)
+		if err != nil {
+			return nil, err
+		}
+		g.chains[chainCfg.Name] = c
+		if chainCfg.ChainID != "" {
+			g.chains[chainCfg.ChainID] = c
+		}
+	}
+	return g, nil
+}
+
+func newChain(cfg ChainConfig) (*chain, error) {
+	upstream, err := url.Parse(cfg.Upstream)
+	if err != nil {
+		return nil, err
+	}
+	c := &chain{name: cfg.Name}
+	if cfg.RateLimit > 0 {
+		c.limiter = rate.NewLimiter(rate.Limit(cfg.RateLimit), cfg.RateBurst)
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(upstream)
+	director := proxy.Director
+	proxy.Director = func(r *http.Request) {
+		director(r)
+		r.Host = upstream.Host
+	}
+	c.handler = proxy
+	if cfg.ResponseCache != "" {
+		ttls, err := respcache.ParseTTLs(cfg.ResponseCache)
+		if err != nil {
+			return nil, err
+		}
+		heights := &heightSource{upstream: cfg.Upstream, client: &http.Client{Timeout: 5 * time.Second}}
+		cache, err := respcache.New(ttls, cfg.ResponseCacheSize, heights.latest)
+		if err != nil {
+			return nil, err
+		}
+		c.handler = cache.Handler(proxy)
+	}
+	return c, nil
+}
+
+// ServeHTTP proxies the request to the upstream of its chain
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c, ok := g.route(r)
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(errorResponse{
+			Version: "2.0",
+			ID:      json.RawMessage("null"),
+			Error:   jsonError{Code: -32601, Message: "unknown chain, set the chain in the path or the X-Chain header"},
+		})
+		return
+	}
+	// the cached responses are limited as well, the limit is the share of the gateway the chain gets
+	if c.limiter != nil && !c.limiter.Allow() {
+		requestsCounter().With("chain", c.name, "result", "limited").Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_ = json.NewEncoder(w).Encode(errorResponse{
+			Version: "2.0",
+			ID:      json.RawMessage("null"),
+			Error:   jsonError{Code: admission.ErrCodeOverloaded, Message: fmt.Sprintf("rate limit of chain %s exceeded", c.name)},
+		})
+		return
+	}
+	requestsCounter().With("chain", c.name, "result", "served").Add(1)
+	c.handler.ServeHTTP(w, r)
+}
+
+// route returns the chain of the request, the path prefix is stripped from the request
+func (g *Gateway) route(r *http.Request) (*chain, bool) {
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	name := path
+	rest := ""
+	if i := strings.Index(path, "/"); i >= 0 {
+		name, rest = path[:i], path[i:]
+	}
+	if c, ok := g.chains[name]; ok && name != "" {
+		if rest == "" {
+			rest = "/"
+		}
+		r.URL.Path = rest
+		r.URL.RawPath = ""
+		return c, true
+	}
+	c, ok := g.chains[r.Header.Get(HeaderChain)]
+	return c, ok
+}
+
+// heightSource polls the latest height of an upstream for its response cache
+type heightSource struct {
+	upstream string
+	client   *http.Client
+
+	mtx     sync.Mutex
+	height  int64
+	fetched time.Time
+}
+
+func (s *heightSource) latest() (int64, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if time.Since(s.fetched) < heightTTL {
+		return s.height, nil
+	}
+	resp, err := s.client.Post(s.upstream, "application/json",
+		bytes.NewReader([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber","params":[]}`)))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	var res struct {
+		Result hexutil.Uint64   `json:"result"`
+		Error  *json.RawMessage `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return 0, err
+	}
+	if res.Error != nil {
+		return 0, fmt.Errorf("eth_blockNumber of %s failed: %s", s.upstream, *res.Error)
+	}
+	s.height, s.fetched = int64(res.Result), time.Now()
+	return s.height, nil
+}
+
+type errorResponse struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Error   jsonError       `json:"error"`
+}
+
+type jsonError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
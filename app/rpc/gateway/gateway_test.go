@@ -0,0 +1,87 @@
+package gateway
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newUpstream is a chain node answering every request with its name, counting the requests of its chain id
+func newUpstream(name string, calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if strings.Contains(string(body), "eth_blockNumber") {
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"0x10"}`)
+			return
+		}
+		atomic.AddInt32(calls, 1)
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":"%s"}`, name)
+	}))
+}
+
+func post(g *Gateway, path, chain, method string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"%s","params":[]}`, method)))
+	if chain != "" {
+		r.Header.Set(HeaderChain, chain)
+	}
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, r)
+	return w
+}
+
+func TestGateway(t *testing.T) {
+	var mainnetCalls, testnetCalls int32
+	mainnet := newUpstream("mainnet", &mainnetCalls)
+	defer mainnet.Close()
+	testnet := newUpstream("testnet", &testnetCalls)
+	defer testnet.Close()
+
+	g, err := New(Config{Chains: []ChainConfig{
+		{Name: "mainnet", ChainID: "exchain-66", Upstream: mainnet.URL, ResponseCache: "eth_chainId=0", ResponseCacheSize: 10},
+		{Name: "testnet", ChainID: "exchain-65", Upstream: testnet.URL, RateLimit: 1, RateBurst: 2, ResponseCache: "eth_chainId=0", ResponseCacheSize: 10},
+	}})
+	require.NoError(t, err)
+
+	// routed by path, chain id header or name header
+	require.Contains(t, post(g, "/mainnet", "", "eth_chainId").Body.String(), `"mainnet"`)
+	require.Contains(t, post(g, "/", "exchain-65", "eth_chainId").Body.String(), `"testnet"`)
+	require.Contains(t, post(g, "/", "mainnet", "eth_syncing").Body.String(), `"mainnet"`)
+	require.Equal(t, http.StatusNotFound, post(g, "/", "", "eth_chainId").Code)
+	require.Equal(t, http.StatusNotFound, post(g, "/devnet", "", "eth_chainId").Code)
+
+	// the caches are per chain
+	require.Contains(t, post(g, "/mainnet", "", "eth_chainId").Body.String(), `"mainnet"`)
+	require.Contains(t, post(g, "/testnet", "", "eth_chainId").Body.String(), `"testnet"`)
+	require.Equal(t, int32(2), atomic.LoadInt32(&mainnetCalls))
+	require.Equal(t, int32(1), atomic.LoadInt32(&testnetCalls))
+
+	// the testnet burst is spent, its requests are rejected while the mainnet ones aren't
+	require.Equal(t, http.StatusTooManyRequests, post(g, "/testnet", "", "eth_syncing").Code)
+	for i := 0; i < 5; i++ {
+		require.Equal(t, http.StatusOK, post(g, "/mainnet", "", "eth_syncing").Code)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := ChainConfig{Name: "mainnet", ChainID: "exchain-66", Upstream: "http://127.0.0.1:8545"}
+	require.NoError(t, Config{Chains: []ChainConfig{valid}}.Validate())
+
+	for _, chains := range [][]ChainConfig{
+		nil,
+		{valid, valid},
+		{valid, {Name: "exchain-66", Upstream: valid.Upstream}},
+		{{Name: "main/net", Upstream: valid.Upstream}},
+		{{Name: "mainnet", Upstream: "127.0.0.1:8545"}},
+		{{Name: "mainnet", Upstream: valid.Upstream, RateLimit: 10}},
+		{{Name: "mainnet", Upstream: valid.Upstream, ResponseCache: "eth_call=1s", ResponseCacheSize: 10}},
+		{{Name: "mainnet", Upstream: valid.Upstream, ResponseCache: "eth_chainId=0"}},
+	} {
+		require.Error(t, Config{Chains: chains}.Validate(), "%+v", chains)
+	}
+}
@@ -0,0 +1,184 @@
+package lightclient
+
+import (
+	"bytes"
+	"fmt"
+
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/okex/exchain/libs/tendermint/lite"
+	liteproxy "github.com/okex/exchain/libs/tendermint/lite/proxy"
+	rpcclient "github.com/okex/exchain/libs/tendermint/rpc/client"
+	ctypes "github.com/okex/exchain/libs/tendermint/rpc/core/types"
+	"github.com/okex/exchain/libs/tendermint/types"
+)
+
+// headerCacheSize is the number of certified signed headers kept by the client, so that the blocks and the
+// txs of the latest heights are verified without downloading their commit again
+const headerCacheSize = 1000
+
+var _ rpcclient.Client = (*Client)(nil)
+
+// Client wraps the client of an untrusted tendermint node, and verifies its blocks, block results, commits and
+// txs against the signed headers certified by the light client verifier. The other responses, such as the
+// status of the node or its mempool, can't be verified and are passed as they are. The abci queries are
+// verified by the CLIContext, for the store queries.
+type Client struct {
+	rpcclient.Client
+	verifier lite.Verifier
+	headers  *lru.Cache
+}
+
+// New wraps the client of an untrusted node with the verifier
+func New(client rpcclient.Client, verifier lite.Verifier) *Client {
+	headers, err := lru.New(headerCacheSize)
+	if err != nil {
+		panic(err)
+	}
+	return &Client{Client: client, verifier: verifier, headers: headers}
+}
+
+// signedHeader returns the certified signed header of the height
+func (c *Client) signedHeader(height int64) (types.SignedHeader, error) {
+	if sh, ok := c.headers.Get(height); ok {
+		return sh.(types.SignedHeader), nil
+	}
+	sh, err := liteproxy.GetCertifiedCommit(height, c.Client, c.verifier)
+	if err != nil {
+		return types.SignedHeader{}, fmt.Errorf("failed to certify the header at height %d: %w", height, err)
+	}
+	c.headers.Add(height, sh)
+	return sh, nil
+}
+
+// Block returns the block once verified against the certified header of its height
+func (c *Client) Block(height *int64) (*ctypes.ResultBlock, error) {
+	res, err := c.Client.Block(height)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := c.signedHeader(res.Block.Height)
+	if err != nil {
+		return nil, err
+	}
+	if err := liteproxy.ValidateBlock(res.Block, sh); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(res.BlockID.Hash, sh.Hash()) {
+		return nil, fmt.Errorf("block id %X doesn't match the certified header %X", res.BlockID.Hash, sh.Hash())
+	}
+	return res, nil
+}
+
+// BlockchainInfo returns the block metas once verified against the certified headers of their heights
+func (c *Client) BlockchainInfo(minHeight, maxHeight int64) (*ctypes.ResultBlockchainInfo, error) {
+	res, err := c.Client.BlockchainInfo(minHeight, maxHeight)
+	if err != nil {
+		return nil, err
+	}
+	for _, meta := range res.BlockMetas {
+		sh, err := c.signedHeader(meta.Header.Height)
+		if err != nil {
+			return nil, err
+		}
+		if err := liteproxy.ValidateBlockMeta(meta, sh); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// BlockResults returns the results of the txs of the block once verified against the results hash of the
+// certified header of the next height
+func (c *Client) BlockResults(height *int64) (*ctypes.ResultBlockResults, error) {
+	res, err := c.Client.BlockResults(height)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := c.signedHeader(res.Height + 1)
+	if err != nil {
+		return nil, err
+	}
+	if hash := types.NewResults(res.TxsResults).Hash(); !bytes.Equal(hash, sh.LastResultsHash) {
+		return nil, fmt.Errorf("results hash %X at height %d doesn't match the certified header %X", hash, res.Height, sh.LastResultsHash)
+	}
+	return res, nil
+}
+
+// Commit returns the commit of the height once certified
+func (c *Client) Commit(height *int64) (*ctypes.ResultCommit, error) {
+	res, err := c.Client.Commit(height)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := c.signedHeader(res.Height)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(res.SignedHeader.Hash(), sh.Hash()) {
+		return nil, fmt.Errorf("commit at height %d doesn't match the certified header", res.Height)
+	}
+	return res, nil
+}
+
+// Tx returns the tx once its inclusion proof is verified against the data hash of the certified header of its
+// block, and its result against the certified results of the block. The proof is always requested.
+func (c *Client) Tx(hash []byte, prove bool) (*ctypes.ResultTx, error) {
+	res, err := c.Client.Tx(hash, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.verifyTx(res, make(map[int64]*ctypes.ResultBlockResults)); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// TxSearch returns the txs once verified as Tx does. The proofs are always requested, but the completeness of
+// the search can't be verified.
+func (c *Client) TxSearch(query string, prove bool, page, perPage int, orderBy string) (*ctypes.ResultTxSearch, error) {
+	res, err := c.Client.TxSearch(query, true, page, perPage, orderBy)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[int64]*ctypes.ResultBlockResults)
+	for _, tx := range res.Txs {
+		if err := c.verifyTx(tx, results); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// verifyTx verifies the inclusion of the tx and its result, the verified block results are shared by the txs
+// of the same block. Only the code and the data of the result are part of the results hash, its log and its
+// events can't be verified.
+func (c *Client) verifyTx(res *ctypes.ResultTx, results map[int64]*ctypes.ResultBlockResults) error {
+	if !bytes.Equal(res.Proof.Data, res.Tx) || !bytes.Equal(res.Tx.Hash(), res.Hash) {
+		return fmt.Errorf("the proof of tx %X doesn't match it", res.Hash)
+	}
+	sh, err := c.signedHeader(res.Height)
+	if err != nil {
+		return err
+	}
+	if err := res.Proof.Validate(sh.DataHash); err != nil {
+		return fmt.Errorf("invalid proof of tx %X: %w", res.Hash, err)
+	}
+
+	blockResults, ok := results[res.Height]
+	if !ok {
+		height := res.Height
+		if blockResults, err = c.BlockResults(&height); err != nil {
+			return err
+		}
+		results[res.Height] = blockResults
+	}
+	if int(res.Index) >= len(blockResults.TxsResults) {
+		return fmt.Errorf("tx %X at index %d of a block of %d txs", res.Hash, res.Index, len(blockResults.TxsResults))
+	}
+	expected := blockResults.TxsResults[res.Index]
+	if expected.Code != res.TxResult.Code || !bytes.Equal(expected.Data, res.TxResult.Data) {
+		return fmt.Errorf("the result of tx %X doesn't match the certified results of its block", res.Hash)
+	}
+	return nil
+}
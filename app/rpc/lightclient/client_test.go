@@ -0,0 +1,111 @@
+package lightclient
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/okex/exchain/libs/tendermint/abci/types"
+	rpcclient "github.com/okex/exchain/libs/tendermint/rpc/client"
+	ctypes "github.com/okex/exchain/libs/tendermint/rpc/core/types"
+	"github.com/okex/exchain/libs/tendermint/types"
+)
+
+// node is an untrusted node of two blocks, the second one committing the results of the first one
+type node struct {
+	rpcclient.Client
+	blocks  map[int64]*types.Block
+	results []*abci.ResponseDeliverTx
+}
+
+func newNode() *node {
+	txs := types.Txs{types.Tx("tx0"), types.Tx("tx1")}
+	results := []*abci.ResponseDeliverTx{{Code: 0, Data: []byte("data0")}, {Code: 1, Data: []byte("data1")}}
+	n := &node{blocks: make(map[int64]*types.Block), results: results}
+	for height, blockTxs := range map[int64]types.Txs{1: txs, 2: nil} {
+		block := types.MakeBlock(height, blockTxs, &types.Commit{}, nil)
+		block.ValidatorsHash = []byte("validators")
+		n.blocks[height] = block
+	}
+	n.blocks[2].LastResultsHash = types.NewResults(results).Hash()
+	return n
+}
+
+func (n *node) Status() (*ctypes.ResultStatus, error) {
+	return &ctypes.ResultStatus{SyncInfo: ctypes.SyncInfo{LatestBlockHeight: 2}}, nil
+}
+
+func (n *node) Commit(height *int64) (*ctypes.ResultCommit, error) {
+	header := n.blocks[*height].Header
+	return &ctypes.ResultCommit{SignedHeader: types.SignedHeader{Header: &header, Commit: &types.Commit{Height: *height}}}, nil
+}
+
+func (n *node) Block(height *int64) (*ctypes.ResultBlock, error) {
+	block := n.blocks[*height]
+	return &ctypes.ResultBlock{BlockID: types.BlockID{Hash: block.Hash()}, Block: block}, nil
+}
+
+func (n *node) BlockResults(height *int64) (*ctypes.ResultBlockResults, error) {
+	return &ctypes.ResultBlockResults{Height: *height, TxsResults: n.results}, nil
+}
+
+func (n *node) Tx(hash []byte, prove bool) (*ctypes.ResultTx, error) {
+	txs := n.blocks[1].Txs
+	for i, tx := range txs {
+		if string(tx.Hash()) == string(hash) {
+			res := &ctypes.ResultTx{Hash: tx.Hash(), Height: 1, Index: uint32(i), Tx: tx, TxResult: *n.results[i]}
+			if prove {
+				res.Proof = txs.Proof(i)
+			}
+			return res, nil
+		}
+	}
+	return nil, nil
+}
+
+// verifier certifies the headers of the node as they were when the node was created
+type verifier map[int64][]byte
+
+func (v verifier) Verify(sh types.SignedHeader) error {
+	if string(v[sh.Height]) != string(sh.Hash()) {
+		return errors.New("uncertified header")
+	}
+	return nil
+}
+
+func (v verifier) ChainID() string { return "" }
+
+func TestClient(t *testing.T) {
+	n := newNode()
+	certified := verifier{}
+	for height, block := range n.blocks {
+		certified[height] = block.Hash()
+	}
+	c := New(n, certified)
+
+	height := int64(1)
+	res, err := c.Block(&height)
+	require.NoError(t, err)
+	require.Equal(t, n.blocks[1], res.Block)
+	tx, err := c.Tx(types.Tx("tx1").Hash(), false)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), tx.TxResult.Code)
+
+	// the forged block, tx and result are rejected
+	block := n.blocks[1]
+	forged := types.MakeBlock(1, types.Txs{types.Tx("forged"), types.Tx("tx1")}, &types.Commit{}, nil)
+	forged.Header = block.Header
+	n.blocks[1] = forged
+	_, err = c.Block(&height)
+	require.Error(t, err)
+	_, err = c.Tx(types.Tx("forged").Hash(), false)
+	require.Error(t, err)
+	n.blocks[1] = block
+
+	n.results[1].Data = []byte("forged")
+	_, err = c.Tx(types.Tx("tx1").Hash(), false)
+	require.Error(t, err)
+	_, err = c.BlockResults(&height)
+	require.Error(t, err)
+}
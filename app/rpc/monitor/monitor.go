@@ -6,6 +6,7 @@ import (
 
 	"github.com/go-kit/kit/metrics"
 	"github.com/okex/exchain/libs/tendermint/libs/log"
+	"github.com/okex/exchain/libs/tendermint/libs/tracing"
 )
 
 // RpcMetrics ...
@@ -19,6 +20,7 @@ type Monitor struct {
 	logger   log.Logger
 	lastTime time.Time
 	metrics  map[string]*RpcMetrics
+	span     *tracing.Span
 }
 
 func GetMonitor(method string, logger log.Logger, metrics map[string]*RpcMetrics) *Monitor {
@@ -31,6 +33,7 @@ func GetMonitor(method string, logger log.Logger, metrics map[string]*RpcMetrics
 
 func (m *Monitor) OnBegin() *Monitor {
 	m.lastTime = time.Now()
+	m.span = tracing.StartRootSpan(m.method)
 
 	if m.metrics == nil {
 		return m
@@ -46,6 +49,7 @@ func (m *Monitor) OnBegin() *Monitor {
 func (m *Monitor) OnEnd(args ...interface{}) {
 	elapsed := time.Since(m.lastTime).Seconds()
 	m.logger.Debug(fmt.Sprintf("RPC: Method<%s>, Elapsed<%fms>, Params<%v>", m.method, elapsed*1e3, args))
+	m.span.End()
 
 	if m.metrics == nil {
 		return
@@ -0,0 +1,254 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/viper"
+
+	"github.com/okex/exchain/app/rpc/monitor"
+	"github.com/okex/exchain/libs/cosmos-sdk/baseapp"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	"github.com/okex/exchain/libs/tendermint/libs/log"
+	"github.com/okex/exchain/libs/tendermint/mempool"
+	"github.com/okex/exchain/libs/tendermint/privval"
+	evmtypes "github.com/okex/exchain/x/evm/types"
+)
+
+// senderReputation is implemented by the mempools scoring the senders of txs
+type senderReputation interface {
+	SenderScores() []mempool.SenderScore
+	ClearSenderScore(sender string) int
+}
+
+// PrivateAdminAPI is the admin_ prefixed set of APIs, reporting the health of the node to its operators,
+// managing the sender reputation and the tx blacklist of its mempool and the signatures decoding its evm traces.
+type PrivateAdminAPI struct {
+	logger  log.Logger
+	Metrics map[string]*monitor.RpcMetrics
+}
+
+// NewAPI creates an instance of the Admin API.
+func NewAPI(log log.Logger) *PrivateAdminAPI {
+	return &PrivateAdminAPI{
+		logger: log.With("module", "json-rpc", "namespace", "admin"),
+	}
+}
+
+// SignerStatus returns the connection state, the signing latency and the failures of the endpoints of the remote
+// signer, nil if the node signs with a local key.
+func (api *PrivateAdminAPI) SignerStatus() *privval.SignerStatus {
+	monitor := monitor.GetMonitor("admin_signerStatus", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd()
+	return privval.GetRemoteSignerStatus()
+}
+
+// SenderScores returns the reputation the mempool keeps of the senders of txs, the most abusive first. It's
+// empty unless the sender reputation of the mempool is enabled.
+func (api *PrivateAdminAPI) SenderScores() ([]mempool.SenderScore, error) {
+	monitor := monitor.GetMonitor("admin_senderScores", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd()
+
+	reputation, ok := baseapp.GetGlobalMempool().(senderReputation)
+	if !ok {
+		return nil, errors.New("the mempool doesn't score the senders")
+	}
+	return reputation.SenderScores(), nil
+}
+
+// ClearSenderScore forgets the reputation of the sender, given by its hex or bech32 address, lifting its
+// graylisting. All the senders are cleared without an address. It returns the number of senders cleared.
+func (api *PrivateAdminAPI) ClearSenderScore(sender *string) (int, error) {
+	monitor := monitor.GetMonitor("admin_clearSenderScore", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("sender", sender)
+
+	reputation, ok := baseapp.GetGlobalMempool().(senderReputation)
+	if !ok {
+		return 0, errors.New("the mempool doesn't score the senders")
+	}
+	if sender == nil || *sender == "" {
+		return reputation.ClearSenderScore(""), nil
+	}
+
+	// the mempool scores the senders by their address whatever its form
+	address := *sender
+	if strings.HasPrefix(address, "0x") {
+		if !common.IsHexAddress(address) {
+			return 0, errors.New("invalid sender address " + address)
+		}
+	} else if _, err := sdk.AccAddressFromBech32(address); err != nil {
+		return 0, err
+	}
+	return reputation.ClearSenderScore(address), nil
+}
+
+// AddTxBlacklistRule adds the rule to the tx blacklist of the node, refusing the admission to its mempool of the
+// evm txs calling the target address or the selector, until the expiry of the rule. The txs already in the
+// mempool are evicted on its next recheck. It returns the rule added.
+func (api *PrivateAdminAPI) AddTxBlacklistRule(rule evmtypes.TxBlacklistRule) (evmtypes.TxBlacklistRule, error) {
+	monitor := monitor.GetMonitor("admin_addTxBlacklistRule", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("to", rule.To, "selector", rule.Selector)
+
+	added, err := evmtypes.AddTxBlacklistRule(rule)
+	if err != nil {
+		return added, err
+	}
+	api.logger.Info("tx blacklist rule added", "to", added.To, "selector", added.Selector,
+		"expiry", added.Expiry, "reason", added.Reason)
+	return added, nil
+}
+
+// RemoveTxBlacklistRule removes the rule of the target address and the selector from the tx blacklist of the
+// node. It returns false if there is no such rule.
+func (api *PrivateAdminAPI) RemoveTxBlacklistRule(to *common.Address, selector hexutil.Bytes) (bool, error) {
+	monitor := monitor.GetMonitor("admin_removeTxBlacklistRule", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("to", to, "selector", selector)
+
+	removed, err := evmtypes.RemoveTxBlacklistRule(to, selector)
+	if err != nil {
+		return false, err
+	}
+	if removed {
+		api.logger.Info("tx blacklist rule removed", "to", to, "selector", selector)
+	}
+	return removed, nil
+}
+
+// TxBlacklist returns the rules of the tx blacklist of the node which haven't expired
+func (api *PrivateAdminAPI) TxBlacklist() []evmtypes.TxBlacklistRule {
+	monitor := monitor.GetMonitor("admin_txBlacklist", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd()
+	return evmtypes.GetTxBlacklist()
+}
+
+// maxResolvedSignatures is the max number of selectors of a admin_resolveSignatures batch
+const maxResolvedSignatures = 100
+
+// AddSignatureABI adds the signatures of the functions and of the events of the json abi to the signatures db
+// rendering the evm traces and the pending txs, replacing the ones with the same selector. It returns the
+// signatures added.
+func (api *PrivateAdminAPI) AddSignatureABI(abiJSON json.RawMessage) ([]evmtypes.Signature, error) {
+	monitor := monitor.GetMonitor("admin_addSignatureABI", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd()
+	return evmtypes.AddSignaturesABI(abiJSON)
+}
+
+// ImportSignatures imports the text signatures of a 4byte dataset on the disk of the node into the signatures db,
+// either a directory of files named after the selector or a file of a signature per line, see
+// evmtypes.ImportSignatures. The type of the signatures without a selector is kind, function by default. It
+// returns the number of signatures imported.
+func (api *PrivateAdminAPI) ImportSignatures(path string, kind *string) (int, error) {
+	monitor := monitor.GetMonitor("admin_importSignatures", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("path", path)
+
+	sigType := evmtypes.SignatureTypeFunction
+	if kind != nil && *kind != "" {
+		sigType = *kind
+	}
+	if sigType != evmtypes.SignatureTypeFunction && sigType != evmtypes.SignatureTypeEvent {
+		return 0, fmt.Errorf("invalid signature type %s", sigType)
+	}
+	return evmtypes.ImportSignatures(path, sigType)
+}
+
+// Signatures returns the signatures of the signatures db, in the order of their selector
+func (api *PrivateAdminAPI) Signatures() ([]evmtypes.Signature, error) {
+	monitor := monitor.GetMonitor("admin_signatures", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd()
+	return evmtypes.GetSignatures()
+}
+
+// RemoveSignature removes the signature of the selector from the signatures db
+func (api *PrivateAdminAPI) RemoveSignature(selector hexutil.Bytes) error {
+	monitor := monitor.GetMonitor("admin_removeSignature", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("selector", selector)
+	return evmtypes.DeleteSignature(selector)
+}
+
+// ResolveSignatures looks the unknown selectors of functions and topics of events up in the 4byte directory of
+// --evm-signatures-4byte-url and adds their text signature to the signatures db. The oldest signature of the
+// directory is taken when several share a selector. It returns the signatures resolved.
+func (api *PrivateAdminAPI) ResolveSignatures(selectors []hexutil.Bytes) ([]evmtypes.Signature, error) {
+	monitor := monitor.GetMonitor("admin_resolveSignatures", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("selectors", len(selectors))
+
+	if len(selectors) > maxResolvedSignatures {
+		return nil, fmt.Errorf("batch of %d selectors exceeds the max of %d", len(selectors), maxResolvedSignatures)
+	}
+	directory := strings.TrimSuffix(viper.GetString(evmtypes.FlagSignatures4ByteURL), "/")
+	if directory == "" {
+		return nil, errors.New("no 4byte directory is configured")
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resolved := make([]evmtypes.Signature, 0, len(selectors))
+	for _, selector := range selectors {
+		var sigType string
+		switch len(selector) {
+		case 4:
+			sigType = evmtypes.SignatureTypeFunction
+		case common.HashLength:
+			sigType = evmtypes.SignatureTypeEvent
+		default:
+			return nil, fmt.Errorf("invalid selector %s", selector)
+		}
+		if _, found := evmtypes.GetSignature(selector); found {
+			continue
+		}
+		text, err := lookup4Byte(client, directory, selector)
+		if err != nil {
+			return nil, err
+		}
+		if text == "" {
+			continue
+		}
+		sig, err := evmtypes.AddTextSignature(text, sigType)
+		if err != nil {
+			api.logger.Debug("skipping the signature of the 4byte directory", "selector", selector, "signature", text, "err", err)
+			continue
+		}
+		resolved = append(resolved, sig)
+	}
+	return resolved, nil
+}
+
+// lookup4Byte returns the oldest text signature of the selector in the 4byte directory, empty if it is unknown.
+// The topics of the events are looked up in the event signatures of the directory.
+func lookup4Byte(client *http.Client, directory string, selector hexutil.Bytes) (string, error) {
+	endpoint := "signatures"
+	if len(selector) == common.HashLength {
+		endpoint = "event-signatures"
+	}
+	resp, err := client.Get(fmt.Sprintf("%s/api/v1/%s/?hex_signature=%s", directory, endpoint, selector))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("4byte directory returned %s", resp.Status)
+	}
+
+	var res struct {
+		Results []struct {
+			ID            int64  `json:"id"`
+			TextSignature string `json:"text_signature"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return "", err
+	}
+	var text string
+	oldest := int64(-1)
+	for _, result := range res.Results {
+		if oldest < 0 || result.ID < oldest {
+			oldest, text = result.ID, result.TextSignature
+		}
+	}
+	return text, nil
+}
@@ -0,0 +1,64 @@
+package admin
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/okex/exchain/libs/cosmos-sdk/baseapp"
+	abci "github.com/okex/exchain/libs/tendermint/abci/types"
+	cfg "github.com/okex/exchain/libs/tendermint/config"
+	"github.com/okex/exchain/libs/tendermint/libs/log"
+	"github.com/okex/exchain/libs/tendermint/mempool"
+	"github.com/okex/exchain/libs/tendermint/proxy"
+	"github.com/okex/exchain/libs/tendermint/types"
+)
+
+// evmSender is the sender of the failed evm txs, in the EIP-55 form the evm txs report it
+const evmSender = "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"
+
+// failingCheckApp fails the checks of the evm txs of evmSender
+type failingCheckApp struct {
+	abci.BaseApplication
+}
+
+func (app *failingCheckApp) CheckTx(req abci.RequestCheckTx) abci.ResponseCheckTx {
+	data, _ := json.Marshal(mempool.ExTxInfo{Sender: evmSender})
+	return abci.ResponseCheckTx{Code: 1, Log: "invalid nonce", Data: data}
+}
+
+func TestClearSenderScore(t *testing.T) {
+	appConn, err := proxy.NewLocalClientCreator(&failingCheckApp{}).NewABCIClient()
+	require.NoError(t, err)
+	require.NoError(t, appConn.Start())
+	defer appConn.Stop()
+
+	config := cfg.TestMempoolConfig()
+	config.EnableSenderReputation = true
+	mp := mempool.NewCListMempool(config, appConn, 0)
+	baseapp.SetGlobalMempool(mp, false, false)
+	defer baseapp.SetGlobalMempool(nil, false, false)
+
+	api := NewAPI(log.NewNopLogger())
+	for _, tx := range []string{"tx1", "tx2"} {
+		require.NoError(t, mp.CheckTx(types.Tx(tx), nil, mempool.TxInfo{}))
+	}
+	scores, err := api.SenderScores()
+	require.NoError(t, err)
+	require.Len(t, scores, 1)
+	require.Equal(t, uint64(2), scores[0].Failures)
+
+	invalid := "0x1234"
+	_, err = api.ClearSenderScore(&invalid)
+	require.Error(t, err)
+
+	// the evm sender is cleared by its hex address
+	sender := evmSender
+	cleared, err := api.ClearSenderScore(&sender)
+	require.NoError(t, err)
+	require.Equal(t, 1, cleared)
+	scores, err = api.SenderScores()
+	require.NoError(t, err)
+	require.Empty(t, scores)
+}
@@ -0,0 +1,164 @@
+package dev
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/okex/exchain/app/rpc/monitor"
+	"github.com/okex/exchain/libs/tendermint/libs/log"
+)
+
+// Controller changes the state and the time of the dev chain. The state changes are applied in a new block, they
+// return once that block is committed.
+type Controller interface {
+	// Mine seals a new block
+	Mine(ctx context.Context) error
+	// IncreaseTime moves the time of the next blocks forward, it returns the total offset of the chain time
+	IncreaseTime(seconds int64) int64
+	// SetNextBlockTimestamp sets the timestamp of the next block, the time of the following blocks goes on from it
+	SetNextBlockTimestamp(timestamp int64) error
+	// Snapshot records the state of the last block, it returns the id of the snapshot
+	Snapshot() uint64
+	// Revert restores the state of a snapshot, the snapshot and the later ones are dropped. It returns false if
+	// there is no such snapshot.
+	Revert(ctx context.Context, id uint64) (bool, error)
+
+	SetBalance(ctx context.Context, addr common.Address, balance *big.Int) error
+	SetCode(ctx context.Context, addr common.Address, code []byte) error
+	SetNonce(ctx context.Context, addr common.Address, nonce uint64) error
+	SetStorageAt(ctx context.Context, addr common.Address, key, value common.Hash) error
+}
+
+var controller Controller
+
+// SetController sets the controller of the dev chain, the dev apis are only served once it is set
+func SetController(c Controller) {
+	controller = c
+}
+
+// GetController returns the controller of the dev chain, it is nil out of the dev mode
+func GetController() Controller {
+	return controller
+}
+
+// PublicEvmAPI is the evm_ prefixed set of test APIs of ganache and hardhat.
+type PublicEvmAPI struct {
+	controller Controller
+	logger     log.Logger
+	Metrics    map[string]*monitor.RpcMetrics
+}
+
+// NewEvmAPI creates an instance of the evm test API.
+func NewEvmAPI(controller Controller, log log.Logger) *PublicEvmAPI {
+	return &PublicEvmAPI{
+		controller: controller,
+		logger:     log.With("module", "json-rpc", "namespace", "evm"),
+	}
+}
+
+// Mine seals a block, at the timestamp if it is given.
+func (api *PublicEvmAPI) Mine(ctx context.Context, timestamp *Quantity) (string, error) {
+	monitor := monitor.GetMonitor("evm_mine", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd()
+	if timestamp != nil {
+		if err := api.controller.SetNextBlockTimestamp(timestamp.Int64()); err != nil {
+			return "", err
+		}
+	}
+	if err := api.controller.Mine(ctx); err != nil {
+		return "", err
+	}
+	return "0x0", nil
+}
+
+// IncreaseTime moves the time of the next blocks forward by seconds, it returns the total time offset.
+func (api *PublicEvmAPI) IncreaseTime(seconds Quantity) int64 {
+	monitor := monitor.GetMonitor("evm_increaseTime", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd()
+	return api.controller.IncreaseTime(seconds.Int64())
+}
+
+// SetNextBlockTimestamp sets the timestamp of the next block.
+func (api *PublicEvmAPI) SetNextBlockTimestamp(timestamp Quantity) error {
+	monitor := monitor.GetMonitor("evm_setNextBlockTimestamp", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd()
+	return api.controller.SetNextBlockTimestamp(timestamp.Int64())
+}
+
+// Snapshot records the state of the chain, it returns the id to revert to.
+func (api *PublicEvmAPI) Snapshot() hexutil.Uint64 {
+	monitor := monitor.GetMonitor("evm_snapshot", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd()
+	return hexutil.Uint64(api.controller.Snapshot())
+}
+
+// Revert restores the state of the snapshot. Unlike ganache and hardhat the blocks sealed since the snapshot are kept,
+// the reverted state is committed in a new block.
+func (api *PublicEvmAPI) Revert(ctx context.Context, id Quantity) (bool, error) {
+	monitor := monitor.GetMonitor("evm_revert", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd()
+	return api.controller.Revert(ctx, id.Uint64())
+}
+
+// PublicHardhatAPI is the hardhat_ prefixed set of test APIs of hardhat.
+type PublicHardhatAPI struct {
+	controller Controller
+	logger     log.Logger
+	Metrics    map[string]*monitor.RpcMetrics
+}
+
+// NewHardhatAPI creates an instance of the hardhat test API.
+func NewHardhatAPI(controller Controller, log log.Logger) *PublicHardhatAPI {
+	return &PublicHardhatAPI{
+		controller: controller,
+		logger:     log.With("module", "json-rpc", "namespace", "hardhat"),
+	}
+}
+
+// SetBalance sets the balance of the address in wei.
+func (api *PublicHardhatAPI) SetBalance(ctx context.Context, address common.Address, balance Quantity) (bool, error) {
+	monitor := monitor.GetMonitor("hardhat_setBalance", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd()
+	if err := api.controller.SetBalance(ctx, address, balance.BigInt()); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SetCode sets the code of the address.
+func (api *PublicHardhatAPI) SetCode(ctx context.Context, address common.Address, code hexutil.Bytes) (bool, error) {
+	monitor := monitor.GetMonitor("hardhat_setCode", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd()
+	if err := api.controller.SetCode(ctx, address, code); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SetNonce sets the nonce of the address.
+func (api *PublicHardhatAPI) SetNonce(ctx context.Context, address common.Address, nonce Quantity) (bool, error) {
+	monitor := monitor.GetMonitor("hardhat_setNonce", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd()
+	if err := api.controller.SetNonce(ctx, address, nonce.Uint64()); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SetStorageAt sets the 32 bytes value of the storage slot of the address.
+func (api *PublicHardhatAPI) SetStorageAt(ctx context.Context, address common.Address, position Quantity, value hexutil.Bytes) (bool, error) {
+	monitor := monitor.GetMonitor("hardhat_setStorageAt", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd()
+	if len(value) != common.HashLength {
+		return false, fmt.Errorf("the storage value must be %d bytes, got %d", common.HashLength, len(value))
+	}
+	key := common.BigToHash(position.BigInt())
+	if err := api.controller.SetStorageAt(ctx, address, key, common.BytesToHash(value)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
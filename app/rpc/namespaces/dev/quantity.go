@@ -0,0 +1,48 @@
+package dev
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Quantity is a non negative integer argument of the test APIs. The test tools send it as a json number, as a hex
+// string, with or without leading zeros, or as a decimal string.
+type Quantity big.Int
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (q *Quantity) UnmarshalJSON(input []byte) error {
+	s := string(input)
+	base := 10
+	if len(input) > 0 && input[0] == '"' {
+		if err := json.Unmarshal(input, &s); err != nil {
+			return err
+		}
+		if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+			s, base = s[2:], 16
+		}
+	}
+
+	i, ok := new(big.Int).SetString(s, base)
+	if !ok || i.Sign() < 0 {
+		return fmt.Errorf("invalid quantity %s", input)
+	}
+	*q = Quantity(*i)
+	return nil
+}
+
+// BigInt returns the quantity as a big.Int.
+func (q *Quantity) BigInt() *big.Int {
+	return (*big.Int)(q)
+}
+
+// Int64 returns the quantity as an int64.
+func (q *Quantity) Int64() int64 {
+	return q.BigInt().Int64()
+}
+
+// Uint64 returns the quantity as an uint64.
+func (q *Quantity) Uint64() uint64 {
+	return q.BigInt().Uint64()
+}
@@ -0,0 +1,37 @@
+package dev
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuantity(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected int64
+		valid    bool
+	}{
+		{`10`, 10, true},
+		{`"10"`, 10, true},
+		{`"0xa"`, 10, true},
+		{`"0x000a"`, 10, true},
+		{`"0x"`, 0, false},
+		{`-1`, 0, false},
+		{`"-0x1"`, 0, false},
+		{`"a"`, 0, false},
+		{`1.5`, 0, false},
+	}
+
+	for _, tc := range testCases {
+		var q Quantity
+		err := json.Unmarshal([]byte(tc.input), &q)
+		if !tc.valid {
+			require.Error(t, err, tc.input)
+			continue
+		}
+		require.NoError(t, err, tc.input)
+		require.Equal(t, tc.expected, q.Int64(), tc.input)
+	}
+}
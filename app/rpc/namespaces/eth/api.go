@@ -21,12 +21,12 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 	lru "github.com/hashicorp/golang-lru"
 	"github.com/okex/exchain/app"
-	"github.com/okex/exchain/app/config"
 	"github.com/okex/exchain/app/crypto/ethsecp256k1"
 	"github.com/okex/exchain/app/crypto/hd"
 	"github.com/okex/exchain/app/rpc/backend"
 	"github.com/okex/exchain/app/rpc/monitor"
 	"github.com/okex/exchain/app/rpc/namespaces/eth/simulation"
+	"github.com/okex/exchain/app/rpc/readonly"
 	rpctypes "github.com/okex/exchain/app/rpc/types"
 	ethermint "github.com/okex/exchain/app/types"
 	"github.com/okex/exchain/app/utils"
@@ -38,6 +38,7 @@ import (
 	sdkerrors "github.com/okex/exchain/libs/cosmos-sdk/types/errors"
 	"github.com/okex/exchain/libs/cosmos-sdk/x/auth"
 	authclient "github.com/okex/exchain/libs/cosmos-sdk/x/auth/client/utils"
+	authexported "github.com/okex/exchain/libs/cosmos-sdk/x/auth/exported"
 	authtypes "github.com/okex/exchain/libs/cosmos-sdk/x/auth/types"
 	abci "github.com/okex/exchain/libs/tendermint/abci/types"
 	"github.com/okex/exchain/libs/tendermint/crypto/merkle"
@@ -46,6 +47,7 @@ import (
 	tmtypes "github.com/okex/exchain/libs/tendermint/types"
 	evmtypes "github.com/okex/exchain/x/evm/types"
 	"github.com/okex/exchain/x/evm/watcher"
+	stakingtypes "github.com/okex/exchain/x/staking/types"
 	"github.com/spf13/viper"
 )
 
@@ -70,6 +72,7 @@ type PublicEthereumAPI struct {
 	watcherBackend *watcher.Watcher
 	evmFactory     simulation.EvmFactory
 	txPool         *TxPool
+	txRelayer      *TxRelayer
 	Metrics        map[string]*monitor.RpcMetrics
 	callCache      *lru.Cache
 }
@@ -96,6 +99,7 @@ func NewAPI(
 		gasPrice:       ParseGasPrice(),
 		wrappedBackend: watcher.NewQuerier(),
 		watcherBackend: watcher.NewWatcher(),
+		txRelayer:      NewTxRelayer(log),
 	}
 	api.evmFactory = simulation.NewEvmFactory(clientCtx.ChainID, api.wrappedBackend)
 
@@ -209,7 +213,17 @@ func (api *PublicEthereumAPI) Coinbase() (common.Address, error) {
 		return common.Address{}, err
 	}
 
-	return common.BytesToAddress(status.ValidatorInfo.Address.Bytes()), nil
+	// on a validator node it's the fee recipient set by the validator
+	consAddr := status.ValidatorInfo.Address
+	res, _, err := api.clientCtx.QueryWithData(fmt.Sprintf("custom/%s/%s", stakingtypes.QuerierRoute, stakingtypes.QueryFeeRecipient), []byte(consAddr.String()))
+	if err == nil {
+		var feeRecipient sdk.AccAddress
+		if err := api.clientCtx.Codec.UnmarshalJSON(res, &feeRecipient); err == nil && !feeRecipient.Empty() {
+			return common.BytesToAddress(feeRecipient), nil
+		}
+	}
+
+	return common.BytesToAddress(consAddr.Bytes()), nil
 }
 
 // Mining returns whether or not this node is currently mining. Always false.
@@ -229,11 +243,29 @@ func (api *PublicEthereumAPI) GasPrice() *hexutil.Big {
 	monitor := monitor.GetMonitor("eth_gasPrice", api.logger, api.Metrics).OnBegin()
 	defer monitor.OnEnd()
 
+	gasPrice := api.gasPrice.ToInt()
 	if app.GlobalGpIndex.RecommendGp != nil {
-		return (*hexutil.Big)(app.GlobalGpIndex.RecommendGp)
+		gasPrice = app.GlobalGpIndex.RecommendGp
 	}
 
-	return api.gasPrice
+	// the txs priced below the floor voted by the validators are rejected
+	if floor := api.minGasPrice(); floor != nil && floor.Cmp(gasPrice) > 0 {
+		gasPrice = floor
+	}
+	return (*hexutil.Big)(gasPrice)
+}
+
+// minGasPrice returns the min gas price floor of the evm txs in wei, nil if it can't be queried
+func (api *PublicEthereumAPI) minGasPrice() *big.Int {
+	res, _, err := api.clientCtx.QueryWithData(fmt.Sprintf("custom/%s/%s", evmtypes.ModuleName, evmtypes.QueryMinGasPrice), nil)
+	if err != nil {
+		return nil
+	}
+	var out evmtypes.QueryResMinGasPrice
+	if err := api.clientCtx.Codec.UnmarshalJSON(res, &out); err != nil || out.MinGasPrice.IsNil() {
+		return nil
+	}
+	return ethermint.DecToWei(out.MinGasPrice)
 }
 
 // Accounts returns the list of accounts available to this node.
@@ -248,6 +280,10 @@ func (api *PublicEthereumAPI) accounts() ([]common.Address, error) {
 	defer api.keyringLock.Unlock()
 
 	addresses := make([]common.Address, 0) // return [] instead of nil if empty
+	// the keys of a read-only node aren't disclosed
+	if viper.GetBool(readonly.FlagReadOnly) {
+		return addresses, nil
+	}
 
 	infos, err := api.clientCtx.Keybase.List()
 	if err != nil {
@@ -270,16 +306,13 @@ func (api *PublicEthereumAPI) BlockNumber() (hexutil.Uint64, error) {
 }
 
 // GetBalance returns the provided account's balance up to the provided block number.
-func (api *PublicEthereumAPI) GetBalance(address common.Address, blockNrOrHash rpctypes.BlockNumberOrHash) (*hexutil.Big, error) {
+func (api *PublicEthereumAPI) GetBalance(addr rpctypes.Address, blockNrOrHash rpctypes.BlockNumberOrHash) (*hexutil.Big, error) {
+	address := addr.Address
 	monitor := monitor.GetMonitor("eth_getBalance", api.logger, api.Metrics).OnBegin()
 	defer monitor.OnEnd("address", address, "block number", blockNrOrHash)
 	acc, err := api.wrappedBackend.MustGetAccount(address.Bytes())
 	if err == nil {
-		balance := acc.GetCoins().AmountOf(sdk.DefaultBondDenom).BigInt()
-		if balance == nil {
-			return (*hexutil.Big)(sdk.ZeroInt().BigInt()), nil
-		}
-		return (*hexutil.Big)(balance), nil
+		return (*hexutil.Big)(ethermint.DecToWei(acc.GetCoins().AmountOf(sdk.DefaultBondDenom))), nil
 	}
 
 	blockNum, err := api.backend.ConvertToBlockNumber(blockNrOrHash)
@@ -302,13 +335,10 @@ func (api *PublicEthereumAPI) GetBalance(address common.Address, blockNrOrHash r
 		return (*hexutil.Big)(sdk.ZeroInt().BigInt()), nil
 	}
 
-	var account ethermint.EthAccount
-	if err := api.clientCtx.Codec.UnmarshalJSON(res, &account); err != nil {
+	val, err := api.accountBalance(clientCtx, res)
+	if err != nil {
 		return nil, err
 	}
-
-	val := account.Balance(sdk.DefaultBondDenom).BigInt()
-	api.watcherBackend.CommitAccountToRpcDb(account)
 	if blockNum != rpctypes.PendingBlockNumber {
 		return (*hexutil.Big)(val), nil
 	}
@@ -336,7 +366,7 @@ func (api *PublicEthereumAPI) GetBalance(address common.Address, blockNrOrHash r
 }
 
 // GetBalanceBatch returns the provided account's balance up to the provided block number.
-func (api *PublicEthereumAPI) GetBalanceBatch(addresses []common.Address, blockNrOrHash rpctypes.BlockNumberOrHash) (interface{}, error) {
+func (api *PublicEthereumAPI) GetBalanceBatch(addresses []rpctypes.Address, blockNrOrHash rpctypes.BlockNumberOrHash) (interface{}, error) {
 	if !viper.GetBool(FlagEnableMultiCall) {
 		return nil, errors.New("the method is not allowed")
 	}
@@ -354,14 +384,10 @@ func (api *PublicEthereumAPI) GetBalanceBatch(addresses []common.Address, blockN
 	}
 
 	balances := make(map[string]*hexutil.Big)
-	for _, address := range addresses {
+	for _, addr := range addresses {
+		address := addr.Address
 		if acc, err := api.wrappedBackend.MustGetAccount(address.Bytes()); err == nil {
-			balance := acc.GetCoins().AmountOf(sdk.DefaultBondDenom).BigInt()
-			if balance == nil {
-				balances[address.String()] = (*hexutil.Big)(sdk.ZeroInt().BigInt())
-			} else {
-				balances[address.String()] = (*hexutil.Big)(balance)
-			}
+			balances[address.String()] = (*hexutil.Big)(ethermint.DecToWei(acc.GetCoins().AmountOf(sdk.DefaultBondDenom)))
 			continue
 		}
 
@@ -377,13 +403,10 @@ func (api *PublicEthereumAPI) GetBalanceBatch(addresses []common.Address, blockN
 			continue
 		}
 
-		var account ethermint.EthAccount
-		if err := api.clientCtx.Codec.UnmarshalJSON(res, &account); err != nil {
+		val, err := api.accountBalance(clientCtx, res)
+		if err != nil {
 			return nil, err
 		}
-
-		val := account.Balance(sdk.DefaultBondDenom).BigInt()
-		api.watcherBackend.CommitAccountToRpcDb(account)
 		if blockNum != rpctypes.PendingBlockNumber {
 			balances[address.String()] = (*hexutil.Big)(val)
 			continue
@@ -412,6 +435,33 @@ func (api *PublicEthereumAPI) GetBalanceBatch(addresses []common.Address, blockN
 	return balances, nil
 }
 
+// accountBalance returns the balance of the account queried from the auth module. The eth accounts are
+// cached into the watcher, the module and vesting accounts are mapped by rpctypes.AccountBalance.
+func (api *PublicEthereumAPI) accountBalance(clientCtx clientcontext.CLIContext, res []byte) (*big.Int, error) {
+	var account authexported.Account
+	if err := api.clientCtx.Codec.UnmarshalJSON(res, &account); err != nil {
+		return nil, err
+	}
+	if ethAccount, ok := account.(*ethermint.EthAccount); ok {
+		api.watcherBackend.CommitAccountToRpcDb(*ethAccount)
+		return ethermint.DecToWei(ethAccount.Balance(sdk.DefaultBondDenom)), nil
+	}
+
+	blockTime := time.Now()
+	if rpctypes.IsVestingAccount(account) {
+		var height *int64
+		if clientCtx.Height > 0 {
+			height = &clientCtx.Height
+		}
+		block, err := clientCtx.Client.Block(height)
+		if err != nil {
+			return nil, err
+		}
+		blockTime = block.Block.Time
+	}
+	return rpctypes.AccountBalance(account, blockTime), nil
+}
+
 // GetAccount returns the provided account's balance up to the provided block number.
 func (api *PublicEthereumAPI) GetAccount(address common.Address) (*ethermint.EthAccount, error) {
 	acc, err := api.wrappedBackend.MustGetAccount(address.Bytes())
@@ -466,7 +516,8 @@ func (api *PublicEthereumAPI) getStorageAt(address common.Address, key []byte, b
 }
 
 // GetStorageAt returns the contract storage at the given address, block number, and key.
-func (api *PublicEthereumAPI) GetStorageAt(address common.Address, key string, blockNrOrHash rpctypes.BlockNumberOrHash) (hexutil.Bytes, error) {
+func (api *PublicEthereumAPI) GetStorageAt(addr rpctypes.Address, key string, blockNrOrHash rpctypes.BlockNumberOrHash) (hexutil.Bytes, error) {
+	address := addr.Address
 	monitor := monitor.GetMonitor("eth_getStorageAt", api.logger, api.Metrics).OnBegin()
 	defer monitor.OnEnd("address", address, "key", key, "block number", blockNrOrHash)
 	blockNum, err := api.backend.ConvertToBlockNumber(blockNrOrHash)
@@ -482,7 +533,8 @@ func (api *PublicEthereumAPI) GetStorageAtInternal(address common.Address, key [
 }
 
 // GetTransactionCount returns the number of transactions at the given address up to the given block number.
-func (api *PublicEthereumAPI) GetTransactionCount(address common.Address, blockNrOrHash rpctypes.BlockNumberOrHash) (*hexutil.Uint64, error) {
+func (api *PublicEthereumAPI) GetTransactionCount(addr rpctypes.Address, blockNrOrHash rpctypes.BlockNumberOrHash) (*hexutil.Uint64, error) {
+	address := addr.Address
 	monitor := monitor.GetMonitor("eth_getTransactionCount", api.logger, api.Metrics).OnBegin()
 	defer monitor.OnEnd("address", address, "block number", blockNrOrHash)
 
@@ -590,7 +642,8 @@ func (api *PublicEthereumAPI) GetUncleCountByBlockNumber(_ rpctypes.BlockNumber)
 }
 
 // GetCode returns the contract code at the given address and block number.
-func (api *PublicEthereumAPI) GetCode(address common.Address, blockNrOrHash rpctypes.BlockNumberOrHash) (hexutil.Bytes, error) {
+func (api *PublicEthereumAPI) GetCode(addr rpctypes.Address, blockNrOrHash rpctypes.BlockNumberOrHash) (hexutil.Bytes, error) {
+	address := addr.Address
 	monitor := monitor.GetMonitor("eth_getCode", api.logger, api.Metrics).OnBegin()
 	defer monitor.OnEnd("address", address, "block number", blockNrOrHash)
 	blockNumber, err := api.backend.ConvertToBlockNumber(blockNrOrHash)
@@ -644,7 +697,8 @@ func (api *PublicEthereumAPI) GetTransactionLogs(txHash common.Hash) ([]*ethtype
 }
 
 // Sign signs the provided data using the private key of address via Geth's signature standard.
-func (api *PublicEthereumAPI) Sign(address common.Address, data hexutil.Bytes) (hexutil.Bytes, error) {
+func (api *PublicEthereumAPI) Sign(addr rpctypes.Address, data hexutil.Bytes) (hexutil.Bytes, error) {
+	address := addr.Address
 	monitor := monitor.GetMonitor("eth_sign", api.logger, api.Metrics).OnBegin()
 	defer monitor.OnEnd("address", address, "data", data)
 	// TODO: Change this functionality to find an unlocked account by address
@@ -723,6 +777,7 @@ func (api *PublicEthereumAPI) SendTransaction(args rpctypes.SendTxArgs) (common.
 	if res.Code != abci.CodeTypeOK {
 		return CheckError(res)
 	}
+	api.txRelayer.Relay(txBytes)
 
 	// Return transaction hash
 	return common.HexToHash(res.TxHash), nil
@@ -762,6 +817,7 @@ func (api *PublicEthereumAPI) SendRawTransaction(data hexutil.Bytes) (common.Has
 	if res.Code != abci.CodeTypeOK {
 		return CheckError(res)
 	}
+	api.txRelayer.Relay(txBytes)
 	// Return transaction hash
 	return common.HexToHash(res.TxHash), nil
 }
@@ -846,6 +902,50 @@ func (api *PublicEthereumAPI) MultiCall(args []rpctypes.CallArgs, blockNr rpctyp
 	return rets, nil
 }
 
+// CallMany executes the calls in order on top of the state of the given block, each call seeing the changes of
+// the previous ones, once the state and the block overrides are applied. It returns the result, the gas used,
+// the logs and the state changes of each call.
+func (api *PublicEthereumAPI) CallMany(
+	args []rpctypes.CallArgs, blockNrOrHash rpctypes.BlockNumberOrHash,
+	stateOverrides *map[common.Address]evmtypes.AccountOverride, blockOverrides *evmtypes.BlockOverrides,
+) ([]evmtypes.BundleTxResult, error) {
+	monitor := monitor.GetMonitor("eth_callMany", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("args", args, "block number", blockNrOrHash)
+
+	blockNum, err := api.backend.ConvertToBlockNumber(blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	var height int64
+	if !(blockNum == rpctypes.PendingBlockNumber || blockNum == rpctypes.LatestBlockNumber) {
+		height = blockNum.Int64()
+	}
+
+	req := evmtypes.SimulateBundleRequest{
+		Txs:            make([]evmtypes.BundleTx, len(args)),
+		BlockOverrides: blockOverrides,
+	}
+	for i, arg := range args {
+		req.Txs[i] = evmtypes.BundleTx{
+			From:     arg.From,
+			To:       arg.To,
+			Gas:      arg.Gas,
+			GasPrice: arg.GasPrice,
+			Value:    arg.Value,
+			Data:     arg.Data,
+		}
+	}
+	if stateOverrides != nil {
+		req.StateOverrides = *stateOverrides
+	}
+
+	results, err := rpctypes.SimulateBundle(api.clientCtx, req, height)
+	if err != nil {
+		return nil, TransformDataError(err, "eth_callMany")
+	}
+	return results, nil
+}
+
 // DoCall performs a simulated call operation through the evmtypes. It returns the
 // estimated gas used on the operation or an error if fails.
 func (api *PublicEthereumAPI) doCall(
@@ -870,46 +970,9 @@ func (api *PublicEthereumAPI) doCall(
 		nonce, _ = api.accountNonce(api.clientCtx, addr, true)
 	}
 
-	// Set default gas & gas price if none were set
-	// Change this to uint64(math.MaxUint64 / 2) if gas cap can be configured
-	gas := uint64(ethermint.DefaultRPCGasLimit)
-	if args.Gas != nil {
-		gas = uint64(*args.Gas)
-	}
-	if globalGasCap != nil && globalGasCap.Uint64() < gas {
-		api.logger.Debug("Caller gas above allowance, capping", "requested", gas, "cap", globalGasCap)
-		gas = globalGasCap.Uint64()
-	}
-
-	// Set gas price using default or parameter if passed in
-	gasPrice := new(big.Int).SetUint64(ethermint.DefaultGasPrice)
-	if args.GasPrice != nil {
-		gasPrice = args.GasPrice.ToInt()
-	}
-
-	// Set value for transaction
-	value := new(big.Int)
-	if args.Value != nil {
-		value = args.Value.ToInt()
-	}
-
-	// Set Data if provided
-	var data []byte
-	if args.Data != nil {
-		data = []byte(*args.Data)
-	}
-
-	// Set destination address for call
-	var toAddr *sdk.AccAddress
-	if args.To != nil {
-		pTemp := sdk.AccAddress(args.To.Bytes())
-		toAddr = &pTemp
-	}
-
 	var msgs []sdk.Msg
 	// Create new call message
-	msg := evmtypes.NewMsgEthermint(nonce, toAddr, sdk.NewIntFromBigInt(value), gas,
-		sdk.NewIntFromBigInt(gasPrice), data, sdk.AccAddress(addr.Bytes()))
+	msg := rpctypes.NewCallMsg(args, nonce, globalGasCap)
 	msgs = append(msgs, msg)
 
 	sim := api.evmFactory.BuildSimulator(api)
@@ -927,33 +990,7 @@ func (api *PublicEthereumAPI) doCall(
 		msgs = append(msgs, pendingMsgs...)
 	}
 
-	//Generate tx to be used to simulate (signature isn't needed)
-	var stdSig authtypes.StdSignature
-	stdSigs := []authtypes.StdSignature{stdSig}
-
-	tx := authtypes.NewStdTx(msgs, authtypes.StdFee{}, stdSigs, "")
-	if err := tx.ValidateBasic(); err != nil {
-		return nil, err
-	}
-
-	txEncoder := authclient.GetTxEncoder(clientCtx.Codec)
-	txBytes, err := txEncoder(tx)
-	if err != nil {
-		return nil, err
-	}
-
-	// Transaction simulation through query
-	res, _, err := clientCtx.QueryWithData("app/simulate", txBytes)
-	if err != nil {
-		return nil, err
-	}
-
-	var simResponse sdk.SimulationResponse
-	if err := clientCtx.Codec.UnmarshalBinaryBare(res, &simResponse); err != nil {
-		return nil, err
-	}
-
-	return &simResponse, nil
+	return rpctypes.SimulateMsgs(clientCtx, msgs)
 }
 
 // EstimateGas returns an estimate of gas usage for the given smart contract call.
@@ -968,12 +1005,7 @@ func (api *PublicEthereumAPI) EstimateGas(args rpctypes.CallArgs) (hexutil.Uint6
 		return 0, TransformDataError(err, "eth_estimateGas")
 	}
 
-	// TODO: change 1000 buffer for more accurate buffer (eg: SDK's gasAdjusted)
-	estimatedGas := simResponse.GasInfo.GasUsed
-	gasBuffer := estimatedGas / 100 * config.GetOecConfig().GetGasLimitBuffer()
-	gas := estimatedGas + gasBuffer
-
-	return hexutil.Uint64(gas), nil
+	return hexutil.Uint64(rpctypes.EstimatedGas(simResponse.GasInfo.GasUsed)), nil
 }
 
 // GetBlockByHash returns the block identified by hash.
@@ -1024,6 +1056,13 @@ func (api *PublicEthereumAPI) GetBlockByNumber(blockNum rpctypes.BlockNumber, fu
 		blockTxs = pendingTxs
 	}
 
+	baseFee := rpctypes.BaseFeeAtHeight(api.clientCtx, 0)
+
+	gasLimit, err := rpctypes.BlockGasLimitAtHeight(api.clientCtx, 0)
+	if err != nil {
+		return nil, err
+	}
+
 	return rpctypes.FormatBlock(
 		tmtypes.Header{
 			Version:         latestBlock.Block.Version,
@@ -1036,10 +1075,11 @@ func (api *PublicEthereumAPI) GetBlockByNumber(blockNum rpctypes.BlockNumber, fu
 		},
 		0,
 		latestBlock.Block.Hash(),
-		0,
+		gasLimit,
 		gasUsed,
 		blockTxs,
 		ethtypes.Bloom{},
+		baseFee,
 	), nil
 
 }
@@ -1048,20 +1088,16 @@ func (api *PublicEthereumAPI) GetBlockByNumber(blockNum rpctypes.BlockNumber, fu
 func (api *PublicEthereumAPI) GetTransactionByHash(hash common.Hash) (*rpctypes.Transaction, error) {
 	monitor := monitor.GetMonitor("eth_getTransactionByHash", api.logger, api.Metrics).OnBegin()
 	defer monitor.OnEnd("hash", hash)
-	rawTx, err := api.wrappedBackend.GetTransactionByHash(hash)
-	if err == nil {
+	rawTx, wrappedErr := api.wrappedBackend.GetTransactionByHash(hash)
+	if wrappedErr == nil {
 		return rawTx, nil
 	}
 	tx, err := api.clientCtx.Client.Tx(hash.Bytes(), false)
 	if err != nil {
-		// check if the tx is on the mempool
-		pendingTx, pendingErr := api.PendingTransactionsByHash(hash)
-		if pendingErr != nil {
-			//to keep consistent with rpc of ethereum, should be return nil
-			return nil, nil
-		}
-		return pendingTx, nil
+		// check if the tx is pending, to keep consistent with rpc of ethereum it's nil if not found
+		return api.getPendingTransaction(hash), nil
 	}
+	api.wrappedBackend.ReportMiss(watcher.DataTx, wrappedErr)
 
 	// Can either cache or just leave this out if not necessary
 	block, err := api.clientCtx.Client.Block(&tx.Height)
@@ -1080,6 +1116,26 @@ func (api *PublicEthereumAPI) GetTransactionByHash(hash common.Hash) (*rpctypes.
 	return rpctypes.NewTransaction(ethTx, common.BytesToHash(tx.Tx.Hash()), blockHash, height, uint64(tx.Index))
 }
 
+// getPendingTransaction returns the tx with the hash in the mempool, or in the txPool if it waits for its nonce
+// there, without block hash, number and index. It returns nil if the tx isn't pending.
+func (api *PublicEthereumAPI) getPendingTransaction(hash common.Hash) *rpctypes.Transaction {
+	if pendingTx, err := api.backend.PendingTransactionsByHash(hash); err == nil {
+		return pendingTx
+	}
+	if api.txPool == nil {
+		return nil
+	}
+	tx, err := api.txPool.getTxByHash(hash)
+	if err != nil {
+		return nil
+	}
+	pendingTx, err := rpctypes.NewTransaction(tx, hash, common.Hash{}, 0, 0)
+	if err != nil {
+		return nil
+	}
+	return pendingTx
+}
+
 // GetTransactionByBlockHashAndIndex returns the transaction identified by hash and index.
 func (api *PublicEthereumAPI) GetTransactionByBlockHashAndIndex(hash common.Hash, idx hexutil.Uint) (*rpctypes.Transaction, error) {
 	monitor := monitor.GetMonitor("eth_getTransactionByBlockHashAndIndex", api.logger, api.Metrics).OnBegin()
@@ -1234,6 +1290,7 @@ func (api *PublicEthereumAPI) GetTransactionReceipt(hash common.Hash) (*watcher.
 		// Return nil for transaction when not found
 		return nil, nil
 	}
+	api.wrappedBackend.ReportMiss(watcher.DataReceipt, e)
 
 	// Query block for consensus hash
 	block, err := api.clientCtx.Client.Block(&tx.Height)
@@ -1241,6 +1298,11 @@ func (api *PublicEthereumAPI) GetTransactionReceipt(hash common.Hash) (*watcher.
 		return nil, err
 	}
 
+	return api.newTransactionReceipt(tx, block)
+}
+
+// newTransactionReceipt builds the receipt of a committed tx missed by the watcher, and backfills the watcher with it
+func (api *PublicEthereumAPI) newTransactionReceipt(tx *ctypes.ResultTx, block *ctypes.ResultBlock) (*watcher.TransactionReceipt, error) {
 	blockHash := common.BytesToHash(block.Block.Hash())
 
 	// Convert tx bytes to eth transaction
@@ -1261,26 +1323,15 @@ func (api *PublicEthereumAPI) GetTransactionReceipt(hash common.Hash) (*watcher.
 	}
 
 	// Set status codes based on tx result
-	var status hexutil.Uint64
+	status := watcher.TransactionFailed
 	if tx.TxResult.IsOK() {
-		status = hexutil.Uint64(1)
-	} else {
-		status = hexutil.Uint64(0)
+		status = watcher.TransactionSuccess
 	}
 
-	txData := tx.TxResult.GetData()
-
-	data, err := evmtypes.DecodeResultData(txData)
+	data, err := evmtypes.DecodeResultData(tx.TxResult.GetData())
 	if err != nil {
-		status = 0 // transaction failed
-	}
-
-	if len(data.Logs) == 0 {
-		data.Logs = []*ethtypes.Log{}
-	}
-	contractAddr := &data.ContractAddress
-	if data.ContractAddress == common.HexToAddress("0x00000000000000000000") {
-		contractAddr = nil
+		status = watcher.TransactionFailed
+		data = evmtypes.ResultData{}
 	}
 
 	// fix gasUsed when deliverTx ante handler check sequence invalid
@@ -1289,22 +1340,10 @@ func (api *PublicEthereumAPI) GetTransactionReceipt(hash common.Hash) (*watcher.
 		gasUsed = 0
 	}
 
-	receipt := &watcher.TransactionReceipt{
-		Status:            status,
-		CumulativeGasUsed: hexutil.Uint64(cumulativeGasUsed),
-		LogsBloom:         data.Bloom,
-		Logs:              data.Logs,
-		TransactionHash:   hash.String(),
-		ContractAddress:   contractAddr,
-		GasUsed:           hexutil.Uint64(gasUsed),
-		BlockHash:         blockHash.String(),
-		BlockNumber:       hexutil.Uint64(tx.Height),
-		TransactionIndex:  hexutil.Uint64(tx.Index),
-		From:              from.String(),
-		To:                ethTx.To(),
-	}
-
-	return receipt, nil
+	receipt := watcher.NewTransactionReceipt(status, ethTx, from, common.BytesToHash(tx.Hash.Bytes()), blockHash,
+		uint64(tx.Index), uint64(tx.Height), &data, cumulativeGasUsed, uint64(gasUsed))
+	api.wrappedBackend.SaveTransactionReceipt(&receipt)
+	return &receipt, nil
 }
 
 // GetTransactionReceiptsByBlock returns the transaction receipt identified by block hash or number.
@@ -1323,7 +1362,6 @@ func (api *PublicEthereumAPI) GetTransactionReceiptsByBlock(blockNrOrHash rpctyp
 
 	var receipts []*watcher.TransactionReceipt
 	var block *ctypes.ResultBlock
-	var blockHash common.Hash
 	for _, tx := range txs {
 		res, _ := api.wrappedBackend.GetTransactionReceipt(tx.Hash)
 		if res != nil {
@@ -1343,66 +1381,12 @@ func (api *PublicEthereumAPI) GetTransactionReceiptsByBlock(blockNrOrHash rpctyp
 			if err != nil {
 				return nil, err
 			}
-			blockHash = common.BytesToHash(block.Block.Hash())
-		}
-
-		// Convert tx bytes to eth transaction
-		ethTx, err := rpctypes.RawTxToEthTx(api.clientCtx, tx.Tx)
-		if err != nil {
-			return nil, err
 		}
 
-		fromSigCache, err := ethTx.VerifySig(ethTx.ChainID(), tx.Height, sdk.EmptyContext().SigCache())
+		receipt, err := api.newTransactionReceipt(tx, block)
 		if err != nil {
 			return nil, err
 		}
-
-		from := fromSigCache.GetFrom()
-		//cumulativeGasUsed := uint64(tx.TxResult.GasUsed)
-		//if tx.Index != 0 {
-		//	cumulativeGasUsed += rpctypes.GetBlockCumulativeGas(api.clientCtx.Codec, block.Block, int(tx.Index))
-		//}
-
-		// Set status codes based on tx result
-		var status = hexutil.Uint64(0)
-		if tx.TxResult.IsOK() {
-			status = hexutil.Uint64(1)
-		}
-
-		txData := tx.TxResult.GetData()
-		data, err := evmtypes.DecodeResultData(txData)
-		if err != nil {
-			status = 0 // transaction failed
-		}
-
-		if len(data.Logs) == 0 {
-			data.Logs = []*ethtypes.Log{}
-		}
-		contractAddr := &data.ContractAddress
-		if data.ContractAddress == common.HexToAddress("0x00000000000000000000") {
-			contractAddr = nil
-		}
-
-		// fix gasUsed when deliverTx ante handler check sequence invalid
-		gasUsed := tx.TxResult.GasUsed
-		if tx.TxResult.Code == sdkerrors.ErrInvalidSequence.ABCICode() {
-			gasUsed = 0
-		}
-
-		receipt := &watcher.TransactionReceipt{
-			Status: status,
-			//CumulativeGasUsed: hexutil.Uint64(cumulativeGasUsed),
-			LogsBloom:        data.Bloom,
-			Logs:             data.Logs,
-			TransactionHash:  common.BytesToHash(tx.Hash.Bytes()).String(),
-			ContractAddress:  contractAddr,
-			GasUsed:          hexutil.Uint64(gasUsed),
-			BlockHash:        blockHash.String(),
-			BlockNumber:      hexutil.Uint64(tx.Height),
-			TransactionIndex: hexutil.Uint64(tx.Index),
-			From:             from.String(),
-			To:               ethTx.To(),
-		}
 		receipts = append(receipts, receipt)
 	}
 
@@ -1432,7 +1416,8 @@ func (api *PublicEthereumAPI) GetUncleByBlockNumberAndIndex(number hexutil.Uint,
 }
 
 // GetProof returns an account object with proof and any storage proofs
-func (api *PublicEthereumAPI) GetProof(address common.Address, storageKeys []string, blockNrOrHash rpctypes.BlockNumberOrHash) (*rpctypes.AccountResult, error) {
+func (api *PublicEthereumAPI) GetProof(addr rpctypes.Address, storageKeys []string, blockNrOrHash rpctypes.BlockNumberOrHash) (*rpctypes.AccountResult, error) {
+	address := addr.Address
 	monitor := monitor.GetMonitor("eth_getProof", api.logger, api.Metrics).OnBegin()
 	defer monitor.OnEnd("address", address, "keys", storageKeys, "number", blockNrOrHash)
 	blockNum, err := api.backend.ConvertToBlockNumber(blockNrOrHash)
@@ -1656,12 +1641,17 @@ func (api *PublicEthereumAPI) accountNonce(
 	return nonce, nil
 }
 
-// GetTxTrace returns the trace of tx execution by txhash.
-func (api *PublicEthereumAPI) GetTxTrace(txHash common.Hash) json.RawMessage {
+// GetTxTrace returns the trace of tx execution by txhash. With decode, the frames of a trace of the callTracer are
+// decorated with their call decoded by the signatures db of the node, see admin_addSignatureABI.
+func (api *PublicEthereumAPI) GetTxTrace(txHash common.Hash, decode *bool) json.RawMessage {
 	monitor := monitor.GetMonitor("eth_getTxTrace", api.logger, api.Metrics).OnBegin()
-	defer monitor.OnEnd("hash", txHash)
+	defer monitor.OnEnd("hash", txHash, "decode", decode)
 
-	return json.RawMessage(evmtypes.GetTracesFromDB(txHash.Bytes()))
+	trace := json.RawMessage(evmtypes.GetTracesFromDB(txHash.Bytes()))
+	if decode != nil && *decode && len(trace) > 0 {
+		return evmtypes.DecorateCallTrace(trace)
+	}
+	return trace
 }
 
 // DeleteTxTrace delete the trace of tx execution by txhash.
@@ -1675,6 +1665,16 @@ func (api *PublicEthereumAPI) DeleteTxTrace(txHash common.Hash) string {
 	return "delete trace succeed"
 }
 
+// GetContractLifecycle returns the self-destruct and redeploy history of a contract, which is only recorded by
+// the nodes running the contract redeploy audit.
+func (api *PublicEthereumAPI) GetContractLifecycle(addr rpctypes.Address) (*watcher.ContractLifecycle, error) {
+	address := addr.Address
+	monitor := monitor.GetMonitor("eth_getContractLifecycle", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("address", address)
+
+	return api.wrappedBackend.GetContractLifecycle(address)
+}
+
 func (api *PublicEthereumAPI) saveZeroAccount(address common.Address) {
 	zeroAccount := ethermint.EthAccount{BaseAccount: &auth.BaseAccount{}}
 	zeroAccount.SetAddress(address.Bytes())
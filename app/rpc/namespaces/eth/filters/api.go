@@ -15,7 +15,6 @@ import (
 
 	"github.com/okex/exchain/app/rpc/monitor"
 	rpctypes "github.com/okex/exchain/app/rpc/types"
-	evmtypes "github.com/okex/exchain/x/evm/types"
 	clientcontext "github.com/okex/exchain/libs/cosmos-sdk/client/context"
 	coretypes "github.com/okex/exchain/libs/tendermint/rpc/core/types"
 	tmtypes "github.com/okex/exchain/libs/tendermint/types"
@@ -27,6 +26,9 @@ import (
 var ErrServerBusy = errors.New("server is too busy")
 var ErrMethodNotAllowed = errors.New("the method is not allowed")
 
+// ErrBlockHashWithRange is returned when the criteria have both the block hash and a block range
+var ErrBlockHashWithRange = errors.New("cannot specify both BlockHash and FromBlock/ToBlock, choose one or the other")
+
 // Backend defines the methods requided by the PublicFilterAPI backend
 type Backend interface {
 	GetBlockByNumber(blockNum rpctypes.BlockNumber, fullTx bool) (interface{}, error)
@@ -235,16 +237,15 @@ func (api *PublicFilterAPI) NewBlockFilter() rpc.ID {
 	api.filters[headerSub.ID()] = &filter{typ: filters.BlocksSubscription, deadline: time.NewTimer(deadline), hashes: []common.Hash{}, s: headerSub}
 	api.filtersMu.Unlock()
 
-	go func(headersCh <-chan coretypes.ResultEvent, errCh <-chan error) {
+	go func(headersCh <-chan *rpctypes.EthHeaderWithBlockHash, errCh <-chan error) {
 		defer cancelSubs()
 
 		for {
 			select {
-			case ev := <-headersCh:
-				data, _ := ev.Data.(tmtypes.EventDataNewBlockHeader)
+			case header := <-headersCh:
 				api.filtersMu.Lock()
 				if f, found := api.filters[headerSub.ID()]; found {
-					f.hashes = append(f.hashes, common.BytesToHash(data.Header.Hash()))
+					f.hashes = append(f.hashes, header.Hash)
 				}
 				api.filtersMu.Unlock()
 			case <-errCh:
@@ -254,7 +255,7 @@ func (api *PublicFilterAPI) NewBlockFilter() rpc.ID {
 				return
 			}
 		}
-	}(headerSub.eventCh, headerSub.Err())
+	}(headerSub.Headers(), headerSub.Err())
 
 	return headerSub.ID()
 }
@@ -274,25 +275,20 @@ func (api *PublicFilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, er
 		return &rpc.Subscription{}, err
 	}
 
-	go func(headersCh <-chan coretypes.ResultEvent) {
+	go func(headersCh <-chan *rpctypes.EthHeaderWithBlockHash, errCh <-chan error) {
 		defer cancelSubs()
 
 		for {
 			select {
-			case ev := <-headersCh:
-				data, ok := ev.Data.(tmtypes.EventDataNewBlockHeader)
-				if !ok {
-					err = fmt.Errorf("invalid event data %T, expected %s", ev.Data, tmtypes.EventNewBlockHeader)
-					headersSub.err <- err
-					return
-				}
-
-				header := rpctypes.EthHeaderFromTendermint(data.Header)
-				err = notifier.Notify(rpcSub.ID, header)
-				if err != nil {
-					headersSub.err <- err
+			case header := <-headersCh:
+				if err := notifier.Notify(rpcSub.ID, header); err != nil {
+					headersSub.Unsubscribe(api.events)
 					return
 				}
+			case <-errCh:
+				// the subscription is dropped as the client doesn't keep up
+				headersSub.Unsubscribe(api.events)
+				return
 			case <-rpcSub.Err():
 				headersSub.Unsubscribe(api.events)
 				return
@@ -301,7 +297,7 @@ func (api *PublicFilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, er
 				return
 			}
 		}
-	}(headersSub.eventCh)
+	}(headersSub.Headers(), headersSub.Err())
 
 	return rpcSub, err
 }
@@ -321,42 +317,23 @@ func (api *PublicFilterAPI) Logs(ctx context.Context, crit filters.FilterCriteri
 		return &rpc.Subscription{}, err
 	}
 
-	go func(logsCh <-chan coretypes.ResultEvent) {
+	go func(logsCh <-chan []*ethtypes.Log, errCh <-chan error) {
 		defer cancelSubs()
 
 		for {
 			select {
-			case event := <-logsCh:
-				// filter only events from EVM module txs
-				_, isMsgEthermint := event.Events[evmtypes.TypeMsgEthermint]
-				_, isMsgEthereumTx := event.Events[evmtypes.TypeMsgEthereumTx]
-
-				if !(isMsgEthermint || isMsgEthereumTx) {
-					// ignore transaction as it's not from the evm module
-					return
-				}
-
-				// get transaction result data
-				dataTx, ok := event.Data.(tmtypes.EventDataTx)
-				if !ok {
-					err = fmt.Errorf("invalid event data %T, expected %s", event.Data, tmtypes.EventTx)
-					logsSub.err <- err
-					return
-				}
-
-				resultData, err := evmtypes.DecodeResultData(dataTx.TxResult.Result.Data)
-				if err != nil {
-					return
-				}
-
-				logs := FilterLogs(resultData.Logs, crit.FromBlock, crit.ToBlock, crit.Addresses, crit.Topics)
-
+			case logs := <-logsCh:
+				logs = FilterLogs(logs, crit.FromBlock, crit.ToBlock, crit.Addresses, crit.Topics)
 				for _, log := range logs {
-					err = notifier.Notify(rpcSub.ID, log)
-					if err != nil {
+					if err := notifier.Notify(rpcSub.ID, log); err != nil {
+						logsSub.Unsubscribe(api.events)
 						return
 					}
 				}
+			case <-errCh:
+				// the subscription is dropped as the client doesn't keep up
+				logsSub.Unsubscribe(api.events)
+				return
 			case <-rpcSub.Err(): // client send an unsubscribe request
 				logsSub.Unsubscribe(api.events)
 				return
@@ -365,7 +342,7 @@ func (api *PublicFilterAPI) Logs(ctx context.Context, crit filters.FilterCriteri
 				return
 			}
 		}
-	}(logsSub.eventCh)
+	}(logsSub.Logs(), logsSub.Err())
 
 	return rpcSub, err
 }
@@ -409,25 +386,13 @@ func (api *PublicFilterAPI) NewFilter(criteria filters.FilterCriteria) (rpc.ID,
 	api.filters[filterID] = &filter{typ: filters.LogsSubscription, crit: criteria, deadline: time.NewTimer(deadline), logs: make([]*ethtypes.Log, 0), s: logsSub}
 	api.filtersMu.Unlock()
 
-	go func(eventCh <-chan coretypes.ResultEvent) {
+	go func(logsCh <-chan []*ethtypes.Log) {
 		defer cancelSubs()
 
 		for {
 			select {
-			case event := <-eventCh:
-				dataTx, ok := event.Data.(tmtypes.EventDataTx)
-				if !ok {
-					err = fmt.Errorf("invalid event data %T, expected EventDataTx", event.Data)
-					return
-				}
-
-				var resultData evmtypes.ResultData
-				resultData, err = evmtypes.DecodeResultData(dataTx.TxResult.Result.Data)
-				if err != nil {
-					return
-				}
-
-				logs := FilterLogs(resultData.Logs, criteria.FromBlock, criteria.ToBlock, criteria.Addresses, criteria.Topics)
+			case logs := <-logsCh:
+				logs = FilterLogs(logs, criteria.FromBlock, criteria.ToBlock, criteria.Addresses, criteria.Topics)
 
 				api.filtersMu.Lock()
 				if f, found := api.filters[filterID]; found {
@@ -441,7 +406,7 @@ func (api *PublicFilterAPI) NewFilter(criteria filters.FilterCriteria) (rpc.ID,
 				return
 			}
 		}
-	}(logsSub.eventCh)
+	}(logsSub.Logs())
 
 	return filterID, err
 }
@@ -452,6 +417,22 @@ func (api *PublicFilterAPI) NewFilter(criteria filters.FilterCriteria) (rpc.ID,
 func (api *PublicFilterAPI) GetLogs(ctx context.Context, criteria filters.FilterCriteria) ([]*ethtypes.Log, error) {
 	monitor := monitor.GetMonitor("eth_getLogs", api.logger, api.Metrics).OnBegin()
 	defer monitor.OnEnd("args", criteria)
+	filter, err := api.logsFilter(criteria)
+	if err != nil {
+		return nil, err
+	}
+
+	// Run the filter and return all the logs
+	logs, err := filter.Logs(ctx)
+	if err != nil {
+		return logs, err
+	}
+
+	return returnLogs(logs), nil
+}
+
+// logsFilter returns the filter of the eth_getLogs criteria, once the method is allowed
+func (api *PublicFilterAPI) logsFilter(criteria filters.FilterCriteria) (*Filter, error) {
 	if api.backend.IsDisabled("eth_getLogs") {
 		return nil, ErrMethodNotAllowed
 	}
@@ -459,31 +440,56 @@ func (api *PublicFilterAPI) GetLogs(ctx context.Context, criteria filters.Filter
 	if rateLimiter != nil && !rateLimiter.Allow() {
 		return nil, ErrServerBusy
 	}
-	var filter *Filter
 	if criteria.BlockHash != nil {
-		// Block filter requested, construct a single-shot filter
-		filter = NewBlockFilter(api.backend, criteria)
-	} else {
-		// Convert the RPC block numbers into internal representations
-		begin := rpc.LatestBlockNumber.Int64()
-		if criteria.FromBlock != nil {
-			begin = criteria.FromBlock.Int64()
-		}
-		end := rpc.LatestBlockNumber.Int64()
-		if criteria.ToBlock != nil {
-			end = criteria.ToBlock.Int64()
+		// BlockHash is mutually exclusive with FromBlock/ToBlock criteria
+		if criteria.FromBlock != nil || criteria.ToBlock != nil {
+			return nil, ErrBlockHashWithRange
 		}
-		// Construct the range filter
-		filter = NewRangeFilter(api.backend, begin, end, criteria.Addresses, criteria.Topics)
+		// Block filter requested, construct a single-shot filter
+		return NewBlockFilter(api.backend, criteria), nil
+	}
+	// Convert the RPC block numbers into internal representations
+	begin := rpc.LatestBlockNumber.Int64()
+	if criteria.FromBlock != nil {
+		begin = criteria.FromBlock.Int64()
 	}
+	end := rpc.LatestBlockNumber.Int64()
+	if criteria.ToBlock != nil {
+		end = criteria.ToBlock.Int64()
+	}
+	// Construct the range filter
+	return NewRangeFilter(api.backend, begin, end, criteria.Addresses, criteria.Topics), nil
+}
 
-	// Run the filter and return all the logs
-	logs, err := filter.Logs(ctx)
+// LogStreamer streams the logs of eth_getLogs as they're found instead of returning them at once. It isn't a
+// method of the api, which would be served by the rpc server.
+type LogStreamer struct {
+	api *PublicFilterAPI
+}
+
+// NewLogStreamer creates the streamer of the logs of the filter api
+func NewLogStreamer(api *PublicFilterAPI) *LogStreamer {
+	return &LogStreamer{api: api}
+}
+
+// StreamLogs passes the logs matching the criteria of eth_getLogs to emit in their order, with the same limits.
+// It stops at the first error of emit.
+func (s *LogStreamer) StreamLogs(ctx context.Context, criteria filters.FilterCriteria, emit func(*ethtypes.Log) error) error {
+	monitor := monitor.GetMonitor("eth_getLogs", s.api.logger, s.api.Metrics).OnBegin()
+	defer monitor.OnEnd("args", criteria, "stream", true)
+
+	filter, err := s.api.logsFilter(criteria)
 	if err != nil {
-		return logs, err
+		return err
 	}
-
-	return returnLogs(logs), nil
+	return filter.StreamLogs(ctx, func(logs []*ethtypes.Log) error {
+		for _, ethLog := range logs {
+			if err := emit(ethLog); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 // UninstallFilter removes the filter with the given filter id.
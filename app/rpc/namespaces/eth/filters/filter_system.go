@@ -2,6 +2,7 @@ package filters
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -22,6 +23,7 @@ import (
 
 	rpctypes "github.com/okex/exchain/app/rpc/types"
 	evmtypes "github.com/okex/exchain/x/evm/types"
+	"github.com/okex/exchain/x/evm/watcher"
 )
 
 var (
@@ -31,6 +33,9 @@ var (
 	headerEvents    = tmtypes.QueryForEvent(tmtypes.EventNewBlockHeader).String()
 )
 
+// ErrSubscriptionOverflow is the error of the subscriptions dropped as they don't keep up with the blocks
+var ErrSubscriptionOverflow = errors.New("subscription dropped, the client is not pulling the events fast enough")
+
 // EventSystem creates subscriptions, processes events and broadcasts them to the
 // subscription which match the subscription criteria using the Tendermint's RPC client.
 type EventSystem struct {
@@ -43,6 +48,10 @@ type EventSystem struct {
 	// light client mode
 	lightMode bool
 
+	// chainEvents feeds the blocks and logs subscriptions with the blocks committed into the watcher,
+	// instead of the tendermint events
+	chainEvents bool
+
 	index      filterIndex
 	indexMux   *sync.RWMutex
 
@@ -72,9 +81,13 @@ func NewEventSystem(client rpcclient.Client) *EventSystem {
 		indexMux:      new(sync.RWMutex),
 		install:       make(chan *Subscription),
 		uninstall:     make(chan *Subscription),
+		chainEvents:   watcher.IsWatcherEnabled(),
 	}
 
 	go es.eventLoop()
+	if es.chainEvents {
+		go es.chainEventLoop()
+	}
 	return es
 }
 
@@ -98,15 +111,22 @@ func (es *EventSystem) subscribe(sub *Subscription) (*Subscription, context.Canc
 
 	es.ctx, cancelFn = context.WithTimeout(context.Background(), deadline)
 
+	sub.quit = make(chan struct{})
 	switch sub.typ {
 	case filters.PendingTransactionsSubscription:
 		eventCh, err = es.client.Subscribe(es.ctx, string(sub.id), sub.event, es.channelLength)
-	case filters.PendingLogsSubscription, filters.MinedAndPendingLogsSubscription:
-		eventCh, err = es.client.Subscribe(es.ctx, string(sub.id), sub.event, es.channelLength)
-	case filters.LogsSubscription:
-		eventCh, err = es.client.Subscribe(es.ctx, string(sub.id), sub.event, es.channelLength)
+	case filters.PendingLogsSubscription, filters.MinedAndPendingLogsSubscription, filters.LogsSubscription:
+		sub.logs = make(chan []*ethtypes.Log, es.bufferLength())
+		sub.chainEvents = es.chainEvents
+		if !sub.chainEvents {
+			eventCh, err = es.client.Subscribe(es.ctx, string(sub.id), sub.event, es.channelLength)
+		}
 	case filters.BlocksSubscription:
-		eventCh, err = es.client.Subscribe(es.ctx, string(sub.id), sub.event)
+		sub.headers = make(chan *rpctypes.EthHeaderWithBlockHash, es.bufferLength())
+		sub.chainEvents = es.chainEvents
+		if !sub.chainEvents {
+			eventCh, err = es.client.Subscribe(es.ctx, string(sub.id), sub.event)
+		}
 	default:
 		err = fmt.Errorf("invalid filter subscription type %d", sub.typ)
 	}
@@ -123,9 +143,20 @@ func (es *EventSystem) subscribe(sub *Subscription) (*Subscription, context.Canc
 	}()
 
 	sub.eventCh = eventCh
+	if eventCh != nil && sub.typ != filters.PendingTransactionsSubscription {
+		go forwardEvents(sub)
+	}
 	return sub, cancelFn, nil
 }
 
+// bufferLength returns the length of the headers and logs channels of the subscriptions
+func (es *EventSystem) bufferLength() int {
+	if es.channelLength < 1 {
+		return 1
+	}
+	return es.channelLength
+}
+
 // SubscribeLogs creates a subscription that will write all logs matching the
 // given criteria to the given logs channel. Default value for the from and to
 // block is "latest". If the fromBlock > toBlock an error is returned.
@@ -171,7 +202,6 @@ func (es *EventSystem) subscribeMinedPendingLogs(crit filters.FilterCriteria) (*
 		event:     evmEvents,
 		logsCrit:  crit,
 		created:   time.Now().UTC(),
-		logs:      make(chan []*ethtypes.Log),
 		installed: make(chan struct{}, 1),
 		err:       make(chan error, 1),
 	}
@@ -187,7 +217,6 @@ func (es *EventSystem) subscribeLogs(crit filters.FilterCriteria) (*Subscription
 		event:     evmEvents,
 		logsCrit:  crit,
 		created:   time.Now().UTC(),
-		logs:      make(chan []*ethtypes.Log),
 		installed: make(chan struct{}, 1),
 		err:       make(chan error, 1),
 	}
@@ -203,7 +232,6 @@ func (es *EventSystem) subscribePendingLogs(crit filters.FilterCriteria) (*Subsc
 		event:     evmEvents,
 		logsCrit:  crit,
 		created:   time.Now().UTC(),
-		logs:      make(chan []*ethtypes.Log),
 		installed: make(chan struct{}, 1),
 		err:       make(chan error, 1),
 	}
@@ -217,7 +245,6 @@ func (es EventSystem) SubscribeNewHeads() (*Subscription, context.CancelFunc, er
 		typ:       filters.BlocksSubscription,
 		event:     headerEvents,
 		created:   time.Now().UTC(),
-		headers:   make(chan *ethtypes.Header),
 		installed: make(chan struct{}, 1),
 		err:       make(chan error, 1),
 	}
@@ -240,37 +267,107 @@ func (es EventSystem) SubscribePendingTxs() (*Subscription, context.CancelFunc,
 
 type filterIndex map[filters.Type]map[rpc.ID]*Subscription
 
-func (es *EventSystem) handleLogs(ev coretypes.ResultEvent) {
+func (es *EventSystem) handleTxsEvent(ev coretypes.ResultEvent) {
 	data, _ := ev.Data.(tmtypes.EventDataTx)
-	resultData, err := evmtypes.DecodeResultData(data.TxResult.Result.Data)
-	if err != nil {
-		return
+	for _, f := range es.index[filters.PendingTransactionsSubscription] {
+		f.hashes <- []common.Hash{common.BytesToHash(data.Tx.Hash())}
 	}
+}
+
+// forwardEvents converts the tendermint events of a blocks or logs subscription into its headers or logs
+func forwardEvents(sub *Subscription) {
+	for {
+		select {
+		case ev, ok := <-sub.eventCh:
+			if !ok {
+				return
+			}
+			if sub.typ == filters.BlocksSubscription {
+				data, ok := ev.Data.(tmtypes.EventDataNewBlockHeader)
+				if !ok {
+					continue
+				}
+				header, err := rpctypes.EthHeaderWithBlockHashFromTendermint(&data.Header)
+				if err != nil {
+					continue
+				}
+				select {
+				case sub.headers <- header:
+				case <-sub.quit:
+					return
+				}
+				continue
+			}
 
-	if len(resultData.Logs) == 0 {
-		return
+			data, ok := ev.Data.(tmtypes.EventDataTx)
+			if !ok {
+				continue
+			}
+			resultData, err := evmtypes.DecodeResultData(data.TxResult.Result.Data)
+			if err != nil || len(resultData.Logs) == 0 {
+				continue
+			}
+			select {
+			case sub.logs <- resultData.Logs:
+			case <-sub.quit:
+				return
+			}
+		case <-sub.quit:
+			return
+		}
 	}
-	for _, f := range es.index[filters.LogsSubscription] {
-		matchedLogs := FilterLogs(resultData.Logs, f.logsCrit.FromBlock, f.logsCrit.ToBlock, f.logsCrit.Addresses, f.logsCrit.Topics)
-		if len(matchedLogs) > 0 {
-			f.logs <- matchedLogs
+}
+
+// chainEventLoop broadcasts the blocks committed into the watcher to the blocks and logs subscriptions
+func (es *EventSystem) chainEventLoop() {
+	eventsCh, _ := watcher.SubscribeChainEvents()
+	for ev := range eventsCh {
+		es.indexMux.Lock()
+		for _, f := range es.index[filters.BlocksSubscription] {
+			select {
+			case f.headers <- ev.Header:
+			default:
+				es.overflow(f)
+			}
+		}
+		if len(ev.Logs) > 0 {
+			// the mined and pending logs subscriptions are in both indices
+			sent := make(map[rpc.ID]bool)
+			for _, typ := range []filters.Type{filters.LogsSubscription, filters.PendingLogsSubscription} {
+				for id, f := range es.index[typ] {
+					if sent[id] {
+						continue
+					}
+					sent[id] = true
+					select {
+					case f.logs <- ev.Logs:
+					default:
+						es.overflow(f)
+					}
+				}
+			}
 		}
+		es.indexMux.Unlock()
 	}
 }
 
-func (es *EventSystem) handleTxsEvent(ev coretypes.ResultEvent) {
-	data, _ := ev.Data.(tmtypes.EventDataTx)
-	for _, f := range es.index[filters.PendingTransactionsSubscription] {
-		f.hashes <- []common.Hash{common.BytesToHash(data.Tx.Hash())}
+// overflow drops the subscription which doesn't keep up with the blocks, its error channel is notified
+func (es *EventSystem) overflow(f *Subscription) {
+	select {
+	case f.err <- ErrSubscriptionOverflow:
+	default:
 	}
+	es.removeFromIndex(f)
 }
 
-func (es *EventSystem) handleChainEvent(ev coretypes.ResultEvent) {
-	data, _ := ev.Data.(tmtypes.EventDataNewBlockHeader)
-	for _, f := range es.index[filters.BlocksSubscription] {
-		f.headers <- rpctypes.EthHeaderFromTendermint(data.Header)
+func (es *EventSystem) removeFromIndex(f *Subscription) {
+	if f.typ == filters.MinedAndPendingLogsSubscription {
+		// the type are logs and pending logs subscriptions
+		delete(es.index[filters.LogsSubscription], f.id)
+		delete(es.index[filters.PendingLogsSubscription], f.id)
+	} else {
+		delete(es.index[f.typ], f.id)
 	}
-	// TODO: light client
 }
 
 // eventLoop (un)installs filters and processes mux events.
@@ -291,14 +388,9 @@ func (es *EventSystem) eventLoop() {
 
 		case f := <-es.uninstall:
 			es.indexMux.Lock()
-			if f.typ == filters.MinedAndPendingLogsSubscription {
-				// the type are logs and pending logs subscriptions
-				delete(es.index[filters.LogsSubscription], f.id)
-				delete(es.index[filters.PendingLogsSubscription], f.id)
-			} else {
-				delete(es.index[f.typ], f.id)
-			}
+			es.removeFromIndex(f)
 			es.indexMux.Unlock()
+			close(f.quit)
 			close(f.err)
 		}
 	}
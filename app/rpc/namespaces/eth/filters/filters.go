@@ -79,28 +79,39 @@ func newFilter(backend Backend, criteria filters.FilterCriteria, matcher *bloomb
 // first block that contains matches, updating the start of the filter accordingly.
 func (f *Filter) Logs(ctx context.Context) ([]*ethtypes.Log, error) {
 	logs := []*ethtypes.Log{}
-	var err error
+	err := f.StreamLogs(ctx, func(found []*ethtypes.Log) error {
+		logs = append(logs, found...)
+		return nil
+	})
+	return logs, err
+}
 
+// StreamLogs searches the blockchain for matching log entries like Logs, but passes the logs of each block to
+// emit as soon as they're found instead of collecting them, in the order of the blocks. It stops at the first
+// error of emit.
+func (f *Filter) StreamLogs(ctx context.Context, emit func([]*ethtypes.Log) error) error {
 	// If we're doing singleton block filtering, execute and return
-	if f.criteria.BlockHash != nil && f.criteria.BlockHash != (&common.Hash{}) {
+	if f.criteria.BlockHash != nil {
 		header, err := f.backend.HeaderByHash(*f.criteria.BlockHash)
-		if err != nil {
-			return nil, err
+		if err != nil || header == nil {
+			// the hash is looked up in the watcher first, then in the hash index of the evm module
+			return fmt.Errorf("unknown block header %s", f.criteria.BlockHash.String())
 		}
-		if header == nil {
-			return nil, fmt.Errorf("unknown block header %s", f.criteria.BlockHash.String())
+		logs, err := f.blockLogs(header, *f.criteria.BlockHash)
+		if err != nil {
+			return err
 		}
-		return f.blockLogs(header, *f.criteria.BlockHash)
+		return emit(logs)
 	}
 
 	// Figure out the limits of the filter range
 	header, err := f.backend.HeaderByNumber(rpctypes.LatestBlockNumber)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if header == nil || header.Number == nil {
-		return nil, nil
+		return nil
 	}
 
 	head := header.Number.Int64()
@@ -113,14 +124,14 @@ func (f *Filter) Logs(ctx context.Context) ([]*ethtypes.Log, error) {
 
 	if f.criteria.FromBlock.Int64() <= tmtypes.GetStartBlockHeight() ||
 		f.criteria.ToBlock.Int64() <= tmtypes.GetStartBlockHeight() {
-		return nil, fmt.Errorf("from and to block height must greater than %d", tmtypes.GetStartBlockHeight())
+		return fmt.Errorf("from and to block height must greater than %d", tmtypes.GetStartBlockHeight())
 	}
 
 	heightSpan := viper.GetInt64(FlagGetLogsHeightSpan)
 	if heightSpan == 0 {
-		return nil, fmt.Errorf("the node connected does not support logs filter")
+		return fmt.Errorf("the node connected does not support logs filter")
 	} else if heightSpan > 0 && f.criteria.ToBlock.Int64()-f.criteria.FromBlock.Int64() > heightSpan {
-		return nil, fmt.Errorf("the span between fromBlock and toBlock must be less than or equal to %d", heightSpan)
+		return fmt.Errorf("the span between fromBlock and toBlock must be less than or equal to %d", heightSpan)
 	}
 
 	begin := f.criteria.FromBlock.Uint64()
@@ -130,19 +141,17 @@ func (f *Filter) Logs(ctx context.Context) ([]*ethtypes.Log, error) {
 		// update from block height
 		f.criteria.FromBlock.Sub(f.criteria.FromBlock, big.NewInt(tmtypes.GetStartBlockHeight()))
 		if indexed > end {
-			logs, err = f.indexedLogs(ctx, end-uint64(tmtypes.GetStartBlockHeight()))
+			err = f.indexedLogs(ctx, end-uint64(tmtypes.GetStartBlockHeight()), emit)
 		} else {
-			logs, err = f.indexedLogs(ctx, indexed-1-uint64(tmtypes.GetStartBlockHeight()))
+			err = f.indexedLogs(ctx, indexed-1-uint64(tmtypes.GetStartBlockHeight()), emit)
 		}
 		if err != nil {
-			return logs, err
+			return err
 		}
 		// recover from block height
 		f.criteria.FromBlock.Add(f.criteria.FromBlock, big.NewInt(tmtypes.GetStartBlockHeight()))
 	}
-	rest, err := f.unindexedLogs(ctx, end)
-	logs = append(logs, rest...)
-	return logs, err
+	return f.unindexedLogs(ctx, end, emit)
 }
 
 // blockLogs returns the logs matching the filter criteria within a single block.
@@ -179,27 +188,25 @@ func (f *Filter) checkMatches(hash common.Hash) (logs []*ethtypes.Log, err error
 	for _, logs := range logsList {
 		unfiltered = append(unfiltered, logs...)
 	}
-	logs = filterLogs(unfiltered, nil, nil, f.criteria.Addresses, f.criteria.Topics)
+	logs = FilterLogs(unfiltered, nil, nil, f.criteria.Addresses, f.criteria.Topics)
 	return logs, nil
 }
 
-// indexedLogs returns the logs matching the filter criteria based on the bloom
+// indexedLogs emits the logs matching the filter criteria based on the bloom
 // bits indexed available locally or via the network.
-func (f *Filter) indexedLogs(ctx context.Context, end uint64) ([]*ethtypes.Log, error) {
+func (f *Filter) indexedLogs(ctx context.Context, end uint64, emit func([]*ethtypes.Log) error) error {
 	// Create a matcher session and request servicing from the backend
 	matches := make(chan uint64, 64)
 
 	session, err := f.matcher.Start(ctx, f.criteria.FromBlock.Uint64(), end, matches)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer session.Close()
 
 	f.backend.ServiceFilter(ctx, session)
 
 	// Iterate over the matches until exhausted or context closed
-	var logs []*ethtypes.Log
-
 	bigEnd := big.NewInt(int64(end))
 	for {
 		select {
@@ -211,7 +218,7 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64) ([]*ethtypes.Log,
 				if err == nil {
 					f.criteria.FromBlock = bigEnd.Add(bigEnd, big.NewInt(1))
 				}
-				return logs, err
+				return err
 			}
 			f.criteria.FromBlock = big.NewInt(int64(number)).Add(big.NewInt(int64(number)), big.NewInt(1))
 
@@ -219,20 +226,23 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64) ([]*ethtypes.Log,
 			hash, err := f.backend.GetBlockHashByHeight(rpctypes.BlockNumber(number))
 			found, err := f.checkMatches(hash)
 			if err != nil {
-				return logs, err
+				return err
+			}
+			if len(found) > 0 {
+				if err := emit(found); err != nil {
+					return err
+				}
 			}
-			logs = append(logs, found...)
 
 		case <-ctx.Done():
-			return logs, ctx.Err()
+			return ctx.Err()
 		}
 	}
 }
 
-// unindexedLogs returns the logs matching the filter criteria based on raw block
+// unindexedLogs emits the logs matching the filter criteria based on raw block
 // iteration and bloom matching.
-func (f *Filter) unindexedLogs(ctx context.Context, end uint64) ([]*ethtypes.Log, error) {
-	var logs []*ethtypes.Log
+func (f *Filter) unindexedLogs(ctx context.Context, end uint64, emit func([]*ethtypes.Log) error) error {
 	begin := f.criteria.FromBlock.Int64()
 	beginPtr := &begin
 	defer f.criteria.FromBlock.SetInt64(*beginPtr)
@@ -240,53 +250,24 @@ func (f *Filter) unindexedLogs(ctx context.Context, end uint64) ([]*ethtypes.Log
 	for ; begin <= int64(end); begin++ {
 		header, err := f.backend.HeaderByNumber(rpctypes.BlockNumber(begin))
 		if header == nil || err != nil {
-			return logs, err
+			return err
 		}
 		hash, err := f.backend.GetBlockHashByHeight(rpctypes.BlockNumber(begin))
 		if err != nil {
-			return logs, err
+			return err
 		}
 		found, err := f.blockLogs(header, hash)
 		if err != nil {
-			return logs, err
-		}
-		logs = append(logs, found...)
-	}
-	return logs, nil
-}
-
-// filterLogs creates a slice of logs matching the given criteria.
-func filterLogs(logs []*ethtypes.Log, fromBlock, toBlock *big.Int, addresses []common.Address, topics [][]common.Hash) []*ethtypes.Log {
-	var ret []*ethtypes.Log
-Logs:
-	for _, log := range logs {
-		if fromBlock != nil && fromBlock.Int64() >= 0 && fromBlock.Uint64() > log.BlockNumber {
-			continue
-		}
-		if toBlock != nil && toBlock.Int64() >= 0 && toBlock.Uint64() < log.BlockNumber {
-			continue
+			return err
 		}
-
-		if len(addresses) > 0 && !includes(addresses, log.Address) {
-			continue
-		}
-		// If the to filtered topics is greater than the amount of topics in logs, skip.
-		if len(topics) > len(log.Topics) {
-			continue Logs
-		}
-		for i, sub := range topics {
-			match := len(sub) == 0 // empty rule set == wildcard
-			for _, topic := range sub {
-				if log.Topics[i] == topic {
-					match = true
-					break
-				}
-			}
-			if !match {
-				continue Logs
+		if len(found) > 0 {
+			if err := emit(found); err != nil {
+				return err
 			}
 		}
-		ret = append(ret, log)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 	}
-	return ret
+	return nil
 }
@@ -8,6 +8,8 @@ import (
 	"github.com/ethereum/go-ethereum/eth/filters"
 	"github.com/ethereum/go-ethereum/rpc"
 	coretypes "github.com/okex/exchain/libs/tendermint/rpc/core/types"
+
+	rpctypes "github.com/okex/exchain/app/rpc/types"
 )
 
 // Subscription defines a wrapper for the private subscription
@@ -19,10 +21,13 @@ type Subscription struct {
 	logsCrit  filters.FilterCriteria
 	logs      chan []*ethtypes.Log
 	hashes    chan []common.Hash
-	headers   chan *ethtypes.Header
+	headers   chan *rpctypes.EthHeaderWithBlockHash
 	installed chan struct{} // closed when the filter is installed
+	quit      chan struct{} // closed when the filter is uninstalled
 	eventCh   <-chan coretypes.ResultEvent
 	err       chan error
+	// chainEvents is true if the subscription is fed by the watcher instead of the tendermint events
+	chainEvents bool
 }
 
 // ID returns the underlying subscription RPC identifier.
@@ -33,8 +38,10 @@ func (s Subscription) ID() rpc.ID {
 // Unsubscribe to the current subscription from Tendermint Websocket. It sends an error to the
 // subscription error channel if unsubscription fails.
 func (s *Subscription) Unsubscribe(es *EventSystem) {
-	if err := es.client.Unsubscribe(es.ctx, string(s.ID()), s.event); err != nil {
-		s.err <- err
+	if !s.chainEvents {
+		if err := es.client.Unsubscribe(es.ctx, string(s.ID()), s.event); err != nil {
+			s.err <- err
+		}
 	}
 
 	go func() {
@@ -60,7 +67,17 @@ func (s *Subscription) Err() <-chan error {
 	return s.err
 }
 
-// Event returns the tendermint result event channel
+// Event returns the tendermint result event channel of the pending txs subscriptions
 func (s *Subscription) Event() <-chan coretypes.ResultEvent {
 	return s.eventCh
 }
+
+// Headers returns the headers of the new blocks of the blocks subscriptions
+func (s *Subscription) Headers() <-chan *rpctypes.EthHeaderWithBlockHash {
+	return s.headers
+}
+
+// Logs returns the logs of the new blocks of the logs subscriptions, they're not filtered by the criteria
+func (s *Subscription) Logs() <-chan []*ethtypes.Log {
+	return s.logs
+}
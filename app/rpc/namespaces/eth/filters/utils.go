@@ -1,21 +1,36 @@
 package filters
 
 import (
+	"encoding/json"
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/filters"
 )
 
-// filterLogs creates a slice of logs matching the given criteria.
-// [] -> anything
-// [A] -> A in first position of log topics, anything after
-// [null, B] -> anything in first position, B in second position
-// [A, B] -> A in first position and B in second position
-// [[A, B], [A, B]] -> A or B in first position, A or B in second position
+// ParseFilterCriteria parses the json params of a log filter as the ones of eth_getLogs, so that the logs
+// subscriptions of the websocket server accept the same criteria. The params are nil if none are given.
+func ParseFilterCriteria(params interface{}) (filters.FilterCriteria, error) {
+	var crit filters.FilterCriteria
+	if params == nil {
+		return crit, nil
+	}
+	bz, err := json.Marshal(params)
+	if err != nil {
+		return crit, err
+	}
+	if err := json.Unmarshal(bz, &crit); err != nil {
+		return crit, fmt.Errorf("invalid criteria: %w", err)
+	}
+	return crit, nil
+}
+
+// FilterLogs creates a slice of logs matching the given criteria, it's shared by eth_getLogs, the filters and the
+// logs subscriptions, see MatchLog.
 func FilterLogs(logs []*ethtypes.Log, fromBlock, toBlock *big.Int, addresses []common.Address, topics [][]common.Hash) []*ethtypes.Log {
 	var ret []*ethtypes.Log
-Logs:
 	for _, log := range logs {
 		if fromBlock != nil && fromBlock.Int64() >= 0 && fromBlock.Uint64() > log.BlockNumber {
 			continue
@@ -23,28 +38,41 @@ Logs:
 		if toBlock != nil && toBlock.Int64() >= 0 && toBlock.Uint64() < log.BlockNumber {
 			continue
 		}
-		if len(addresses) > 0 && !includes(addresses, log.Address) {
-			continue
-		}
-		// If the to filtered topics is greater than the amount of topics in logs, skip.
-		if len(topics) > len(log.Topics) {
-			continue
+		if MatchLog(log, addresses, topics) {
+			ret = append(ret, log)
 		}
-		for i, sub := range topics {
-			match := len(sub) == 0 // empty rule set == wildcard
-			for _, topic := range sub {
-				if log.Topics[i] == topic {
-					match = true
-					break
-				}
-			}
-			if !match {
-				continue Logs
+	}
+	return ret
+}
+
+// MatchLog returns true if the log is emitted by one of the addresses, any address if none is given, and matches
+// the topics by position:
+// [] -> anything
+// [A] -> A in first position of log topics, anything after
+// [null, B] -> anything in first position, B in second position
+// [A, B] -> A in first position and B in second position
+// [[A, B], [A, B]] -> A or B in first position, A or B in second position
+// A log with less topics than the positions of the criteria never matches, even if the last ones are null.
+func MatchLog(log *ethtypes.Log, addresses []common.Address, topics [][]common.Hash) bool {
+	if len(addresses) > 0 && !includes(addresses, log.Address) {
+		return false
+	}
+	if len(topics) > len(log.Topics) {
+		return false
+	}
+	for i, sub := range topics {
+		match := len(sub) == 0 // empty rule set == wildcard
+		for _, topic := range sub {
+			if log.Topics[i] == topic {
+				match = true
+				break
 			}
 		}
-		ret = append(ret, log)
+		if !match {
+			return false
+		}
 	}
-	return ret
+	return true
 }
 
 func includes(addresses []common.Address, a common.Address) bool {
@@ -72,6 +100,7 @@ func bloomFilter(bloom ethtypes.Bloom, addresses []common.Address, topics [][]co
 		}
 	}
 
+	// the bloom isn't positional, but each position must have one of its topics in the bloom
 	for _, sub := range topics {
 		included = len(sub) == 0 // empty rule set == wildcard
 		for _, topic := range sub {
@@ -80,8 +109,11 @@ func bloomFilter(bloom ethtypes.Bloom, addresses []common.Address, topics [][]co
 				break
 			}
 		}
+		if !included {
+			return false
+		}
 	}
-	return included
+	return true
 }
 
 // returnHashes is a helper that will return an empty hash array case the given hash array is nil,
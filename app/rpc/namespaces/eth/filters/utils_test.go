@@ -0,0 +1,138 @@
+package filters
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testAddrA  = common.HexToAddress("0x000000000000000000000000000000000000000a")
+	testAddrB  = common.HexToAddress("0x000000000000000000000000000000000000000b")
+	testTopicA = common.HexToHash("0x0a")
+	testTopicB = common.HexToHash("0x0b")
+	testTopicC = common.HexToHash("0x0c")
+)
+
+// testLogs are matched by index in the cases of TestFilterCriteriaParity
+var testLogs = []*ethtypes.Log{
+	{Address: testAddrA, BlockNumber: 1},
+	{Address: testAddrA, BlockNumber: 2, Topics: []common.Hash{testTopicA}},
+	{Address: testAddrB, BlockNumber: 3, Topics: []common.Hash{testTopicA, testTopicB}},
+	{Address: testAddrB, BlockNumber: 4, Topics: []common.Hash{testTopicB, testTopicA}},
+	{Address: testAddrA, BlockNumber: 5, Topics: []common.Hash{testTopicC, testTopicB, testTopicA}},
+}
+
+// TestFilterCriteriaParity checks that the criteria of a logs subscription are parsed and matched as the ones of
+// eth_getLogs, which are decoded by the rpc server
+func TestFilterCriteriaParity(t *testing.T) {
+	a, b, c := `"`+testTopicA.Hex()+`"`, `"`+testTopicB.Hex()+`"`, `"`+testTopicC.Hex()+`"`
+	addrA, addrB := `"`+testAddrA.Hex()+`"`, `"`+testAddrB.Hex()+`"`
+
+	for _, tc := range []struct {
+		name     string
+		criteria string
+		matched  []int
+	}{
+		{"no criteria", `{}`, []int{0, 1, 2, 3, 4}},
+		{"single address", `{"address":` + addrA + `}`, []int{0, 1, 4}},
+		{"address array", `{"address":[` + addrB + `]}`, []int{2, 3}},
+		{"address set is OR", `{"address":[` + addrA + `,` + addrB + `]}`, []int{0, 1, 2, 3, 4}},
+		{"empty address array", `{"address":[]}`, []int{0, 1, 2, 3, 4}},
+		{"empty topics", `{"topics":[]}`, []int{0, 1, 2, 3, 4}},
+		{"first position", `{"topics":[` + a + `]}`, []int{1, 2}},
+		{"null is a wildcard", `{"topics":[null]}`, []int{1, 2, 3, 4}},
+		{"null then position", `{"topics":[null,` + a + `]}`, []int{3}},
+		{"both positions are AND", `{"topics":[` + a + `,` + b + `]}`, []int{2}},
+		{"nested array is OR", `{"topics":[[` + a + `,` + b + `]]}`, []int{1, 2, 3}},
+		{"nested arrays in all positions", `{"topics":[[` + a + `,` + b + `],[` + a + `,` + b + `]]}`, []int{2, 3}},
+		{"null in nested array is a wildcard", `{"topics":[[` + c + `,null]]}`, []int{1, 2, 3, 4}},
+		{"empty nested array is a wildcard", `{"topics":[[],` + b + `]}`, []int{2, 4}},
+		{"trailing null requires the position", `{"topics":[` + c + `,null,null]}`, []int{4}},
+		{"more positions than topics", `{"topics":[null,null,null,null]}`, nil},
+		{"addresses and topics are AND", `{"address":[` + addrA + `],"topics":[null,` + b + `]}`, []int{4}},
+		{"block range", `{"fromBlock":"0x2","toBlock":"0x3"}`, []int{1, 2}},
+		{"latest", `{"fromBlock":"latest","toBlock":"latest","topics":[` + a + `]}`, []int{1, 2}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// eth_getLogs decodes its argument from the raw json
+			var getLogsCrit filters.FilterCriteria
+			require.NoError(t, json.Unmarshal([]byte(tc.criteria), &getLogsCrit))
+
+			// the websocket server receives the decoded json params
+			var params interface{}
+			require.NoError(t, json.Unmarshal([]byte(tc.criteria), &params))
+			subCrit, err := ParseFilterCriteria(params)
+			require.NoError(t, err)
+			require.Equal(t, getLogsCrit, subCrit)
+
+			var matched []int
+			for i, log := range FilterLogs(testLogs, subCrit.FromBlock, subCrit.ToBlock, subCrit.Addresses, subCrit.Topics) {
+				for j := range testLogs {
+					if testLogs[j] == log {
+						matched = append(matched, j)
+					}
+				}
+				require.Len(t, matched, i+1)
+			}
+			require.Equal(t, tc.matched, matched)
+
+			// the bloom of the matched logs is never skipped
+			for _, i := range tc.matched {
+				bloom := ethtypes.BytesToBloom(ethtypes.LogsBloom([]*ethtypes.Log{testLogs[i]}))
+				require.True(t, bloomFilter(bloom, subCrit.Addresses, subCrit.Topics))
+			}
+		})
+	}
+}
+
+func TestParseFilterCriteriaErrors(t *testing.T) {
+	crit, err := ParseFilterCriteria(nil)
+	require.NoError(t, err)
+	require.Empty(t, crit.Addresses)
+	require.Empty(t, crit.Topics)
+
+	for _, invalid := range []string{
+		`"0x01"`,
+		`{"address":"0x01"}`,
+		`{"address":[1]}`,
+		`{"address":{"a":"b"}}`,
+		`{"topics":"0x0a"}`,
+		`{"topics":["0x0a"]}`,
+		`{"topics":[[1]]}`,
+		`{"topics":[1]}`,
+		`{"blockHash":"` + testTopicA.Hex() + `","fromBlock":"0x1"}`,
+	} {
+		// eth_getLogs rejects the same criteria
+		var getLogsCrit filters.FilterCriteria
+		require.Error(t, json.Unmarshal([]byte(invalid), &getLogsCrit), invalid)
+
+		var params interface{}
+		require.NoError(t, json.Unmarshal([]byte(invalid), &params))
+		_, err := ParseFilterCriteria(params)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestBloomFilter(t *testing.T) {
+	bloom := ethtypes.BytesToBloom(ethtypes.LogsBloom([]*ethtypes.Log{testLogs[2]}))
+	require.True(t, bloomFilter(bloom, nil, nil))
+	require.True(t, bloomFilter(bloom, []common.Address{testAddrA, testAddrB}, nil))
+	require.False(t, bloomFilter(bloom, []common.Address{testAddrA}, nil))
+	require.True(t, bloomFilter(bloom, nil, [][]common.Hash{{testTopicB}, nil, {testTopicA}}))
+	// each position is looked up, not only the last one
+	require.False(t, bloomFilter(bloom, nil, [][]common.Hash{{testTopicC}, {testTopicA}}))
+	require.True(t, bloomFilter(bloom, nil, [][]common.Hash{{testTopicC, testTopicA}, {testTopicB}}))
+}
+
+func TestFilterLogsBlockRange(t *testing.T) {
+	require.Len(t, FilterLogs(testLogs, big.NewInt(4), nil, nil, nil), 2)
+	require.Len(t, FilterLogs(testLogs, nil, big.NewInt(1), nil, nil), 1)
+	// the pending and latest blocks aren't a bound
+	require.Len(t, FilterLogs(testLogs, big.NewInt(-1), big.NewInt(-2), nil, nil), len(testLogs))
+}
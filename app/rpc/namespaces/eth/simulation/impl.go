@@ -120,6 +120,8 @@ func (p SubspaceProxy) GetParamSet(ctx sdk.Context, ps params.ParamSet) {
 	if err == nil {
 		evmParam := ps.(*evmtypes.Params)
 		evmParam.MaxGasLimitPerTx = pr.MaxGasLimitPerTx
+		evmParam.BaseFee = pr.BaseFee
+		evmParam.MaxGasLimitPerBlock = pr.MaxGasLimitPerBlock
 		evmParam.EnableCall = pr.EnableCall
 		evmParam.EnableContractBlockedList = pr.EnableContractBlockedList
 		evmParam.EnableCreate = pr.EnableCreate
@@ -128,10 +130,22 @@ func (p SubspaceProxy) GetParamSet(ctx sdk.Context, ps params.ParamSet) {
 	}
 
 }
+func (p SubspaceProxy) GetParamSetIfExists(ctx sdk.Context, ps params.ParamSet) {
+	p.GetParamSet(ctx, ps)
+}
+
 func (p SubspaceProxy) SetParamSet(ctx sdk.Context, ps params.ParamSet) {
 
 }
 
+func (p SubspaceProxy) GetRaw(ctx sdk.Context, key []byte) []byte {
+	return nil
+}
+
+func (p SubspaceProxy) Update(ctx sdk.Context, key, value []byte) error {
+	return nil
+}
+
 type BankKeeperProxy struct {
 	blacklistedAddrs map[string]bool
 }
@@ -225,6 +239,9 @@ func (i InternalDba) NewStore(parent store.KVStore, Prefix []byte) evmtypes.Stor
 		return HeightHashStore{watcher.NewQuerier()}
 	case evmtypes.KeyPrefixBlockHash[0]:
 		return BlockHashStore{}
+	case evmtypes.KeyPrefixRecentBlockHash[0]:
+		// BLOCKHASH falls back to the height -> hash store
+		return BlockHashStore{}
 	}
 	return nil
 }
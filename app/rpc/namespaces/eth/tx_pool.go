@@ -45,6 +45,7 @@ type TxPool struct {
 	mu                sync.Mutex
 	cap               uint64
 	broadcastInterval time.Duration
+	relayer           *TxRelayer
 	logger            log.Logger
 }
 
@@ -60,6 +61,7 @@ func NewTxPool(clientCtx clientcontext.CLIContext, api *PublicEthereumAPI) *TxPo
 		db:                db,
 		cap:               viper.GetUint64(TxPoolCap),
 		broadcastInterval: interval,
+		relayer:           api.txRelayer,
 		logger:            api.logger.With("module", "tx_pool", "namespace", "eth"),
 	}
 
@@ -104,7 +106,7 @@ func (pool *TxPool) initDB(api *PublicEthereumAPI) error {
 			return fmt.Errorf("nonce[%d] in key is not equal to nonce[%d] in value", tx.Data.AccountNonce, txNonce)
 		}
 		blockNrOrHash := rpctypes.BlockNumberOrHashWithNumber(rpctypes.PendingBlockNumber)
-		pCurrentNonce, err := api.GetTransactionCount(address, blockNrOrHash)
+		pCurrentNonce, err := api.GetTransactionCount(rpctypes.Address{Address: address}, blockNrOrHash)
 		if err != nil {
 			return err
 		}
@@ -146,7 +148,7 @@ func broadcastTxByTxPool(api *PublicEthereumAPI, tx *evmtypes.MsgEthereumTx, txB
 func (pool *TxPool) CacheAndBroadcastTx(api *PublicEthereumAPI, address common.Address, tx *evmtypes.MsgEthereumTx) error {
 	// get currentNonce
 	blockNrOrHash := rpctypes.BlockNumberOrHashWithNumber(rpctypes.PendingBlockNumber)
-	pCurrentNonce, err := api.GetTransactionCount(address, blockNrOrHash)
+	pCurrentNonce, err := api.GetTransactionCount(rpctypes.Address{Address: address}, blockNrOrHash)
 	if err != nil {
 		return err
 	}
@@ -276,9 +278,29 @@ func (pool *TxPool) broadcast(tx *evmtypes.MsgEthereumTx) error {
 			return fmt.Errorf("broadcast tx failed, err: %s", broadcastErrors[res.Code].Error())
 		}
 	}
+	pool.relayer.Relay(txBytes)
 	return nil
 }
 
+// getTxByHash returns the tx of the pool with the hash, it's waiting for its nonce to be broadcast to the mempool
+func (pool *TxPool) getTxByHash(hash common.Hash) (*evmtypes.MsgEthereumTx, error) {
+	txEncoder := authclient.GetTxEncoder(pool.clientCtx.Codec)
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	for _, txs := range pool.addressTxsPool {
+		for _, tx := range txs {
+			txBytes, err := txEncoder(tx)
+			if err != nil {
+				return nil, err
+			}
+			if common.BytesToHash(tmhash.Sum(txBytes)) == hash {
+				return tx, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("tx %s not found in the txPool", hash.Hex())
+}
+
 func (pool *TxPool) writeTxInDB(address common.Address, tx *evmtypes.MsgEthereumTx) error {
 	key := []byte(address.Hex() + "|" + strconv.Itoa(int(tx.Data.AccountNonce)))
 
@@ -322,7 +344,7 @@ func (pool *TxPool) broadcastPeriodCore(api *PublicEthereumAPI) {
 	defer pool.mu.Unlock()
 	blockNrOrHash := rpctypes.BlockNumberOrHashWithNumber(rpctypes.PendingBlockNumber)
 	for address, _ := range pool.addressTxsPool {
-		pCurrentNonce, err := api.GetTransactionCount(address, blockNrOrHash)
+		pCurrentNonce, err := api.GetTransactionCount(rpctypes.Address{Address: address}, blockNrOrHash)
 		if err != nil {
 			pool.logger.Error(err.Error())
 			continue
@@ -338,7 +360,7 @@ func (pool *TxPool) broadcastOnce(api *PublicEthereumAPI) {
 	defer pool.mu.Unlock()
 	blockNrOrHash := rpctypes.BlockNumberOrHashWithNumber(rpctypes.PendingBlockNumber)
 	for address, _ := range pool.addressTxsPool {
-		pCurrentNonce, err := api.GetTransactionCount(address, blockNrOrHash)
+		pCurrentNonce, err := api.GetTransactionCount(rpctypes.Address{Address: address}, blockNrOrHash)
 		if err != nil {
 			continue
 		}
@@ -0,0 +1,38 @@
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	clientcontext "github.com/okex/exchain/libs/cosmos-sdk/client/context"
+	authclient "github.com/okex/exchain/libs/cosmos-sdk/x/auth/client/utils"
+	"github.com/okex/exchain/libs/tendermint/crypto/tmhash"
+	evmtypes "github.com/okex/exchain/x/evm/types"
+)
+
+func TestTxPoolGetTxByHash(t *testing.T) {
+	clientCtx := clientcontext.NewCLIContext().WithCodec(evmtypes.ModuleCdc)
+	pool := &TxPool{addressTxsPool: make(map[common.Address][]*evmtypes.MsgEthereumTx), clientCtx: clientCtx, cap: 10}
+
+	from := common.HexToAddress("0x01")
+	to := common.HexToAddress("0x02")
+	var hashes []common.Hash
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		tx := evmtypes.NewMsgEthereumTx(nonce, &to, big.NewInt(1), 21000, big.NewInt(1), nil)
+		require.NoError(t, pool.insertTx(from, &tx))
+		txBytes, err := authclient.GetTxEncoder(clientCtx.Codec)(&tx)
+		require.NoError(t, err)
+		hashes = append(hashes, common.BytesToHash(tmhash.Sum(txBytes)))
+	}
+
+	// the txs waiting in the pool are found by the hash returned by eth_sendRawTransaction
+	tx, err := pool.getTxByHash(hashes[1])
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), tx.Data.AccountNonce)
+
+	_, err = pool.getTxByHash(common.HexToHash("0x03"))
+	require.Error(t, err)
+}
@@ -0,0 +1,109 @@
+package eth
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	abci "github.com/okex/exchain/libs/tendermint/abci/types"
+	"github.com/okex/exchain/libs/tendermint/libs/log"
+	rpchttp "github.com/okex/exchain/libs/tendermint/rpc/client/http"
+	ctypes "github.com/okex/exchain/libs/tendermint/rpc/core/types"
+	tmtypes "github.com/okex/exchain/libs/tendermint/types"
+)
+
+const (
+	FlagTxRelayEndpoints        = "rpc.tx-relay-endpoints"
+	FlagTxRelayQueueSize        = "rpc.tx-relay-queue-size"
+	FlagPrivateTxRelayEndpoints = "rpc.private-tx-relay-endpoints"
+
+	// txRelayTimeout is the timeout in seconds of a relayed broadcast
+	txRelayTimeout = 3
+)
+
+// TxRelayer forwards the txs accepted by the node to the tendermint rpc of sentries or validators, in addition to
+// the p2p gossip, so that they reach the proposer sooner. Each endpoint is relayed to by its own routine, a slow
+// endpoint doesn't delay the others, and the txs are dropped once its queue is full. The relay never fails the
+// request of the user, the txs are gossiped anyway.
+type TxRelayer struct {
+	endpoints []*txRelayEndpoint
+	logger    log.Logger
+	// private is true for the relayer of the private txs, which are submitted to the endpoints without being
+	// gossiped by them
+	private bool
+}
+
+type txRelayEndpoint struct {
+	addr   string
+	client *rpchttp.HTTP
+	queue  chan tmtypes.Tx
+}
+
+// NewTxRelayer creates the relayer to the endpoints given by the flags, it returns nil if there is none
+func NewTxRelayer(logger log.Logger) *TxRelayer {
+	return newTxRelayer(logger, viper.GetString(FlagTxRelayEndpoints), false)
+}
+
+// NewPrivateTxRelayer creates the relayer of the private txs to the endpoints of --rpc.private-tx-relay-endpoints,
+// the validators proposing them or the relays they trust. It returns nil if there is none.
+func NewPrivateTxRelayer(logger log.Logger) *TxRelayer {
+	return newTxRelayer(logger, viper.GetString(FlagPrivateTxRelayEndpoints), true)
+}
+
+func newTxRelayer(logger log.Logger, endpoints string, private bool) *TxRelayer {
+	var addrs []string
+	for _, addr := range strings.Split(endpoints, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+	if len(addrs) == 0 {
+		return nil
+	}
+
+	r := &TxRelayer{logger: logger.With("module", "tx_relay"), private: private}
+	queueSize := viper.GetInt(FlagTxRelayQueueSize)
+	for _, addr := range addrs {
+		client, err := rpchttp.NewWithTimeout(addr, "/websocket", txRelayTimeout)
+		if err != nil {
+			r.logger.Error("invalid tx relay endpoint", "endpoint", addr, "err", err)
+			continue
+		}
+		ep := &txRelayEndpoint{addr: addr, client: client, queue: make(chan tmtypes.Tx, queueSize)}
+		r.endpoints = append(r.endpoints, ep)
+		go r.relayRoutine(ep)
+	}
+	return r
+}
+
+// Relay queues the tx to every endpoint
+func (r *TxRelayer) Relay(txBytes []byte) {
+	if r == nil {
+		return
+	}
+	for _, ep := range r.endpoints {
+		select {
+		case ep.queue <- txBytes:
+		default:
+			r.logger.Debug("tx relay queue is full, drop the tx", "endpoint", ep.addr, "hash", tmtypes.Tx(txBytes).Hash())
+		}
+	}
+}
+
+func (r *TxRelayer) relayRoutine(ep *txRelayEndpoint) {
+	for tx := range ep.queue {
+		var err error
+		if r.private {
+			var res *ctypes.ResultBroadcastTx
+			if res, err = ep.client.BroadcastTxPrivate(tx); err == nil && res.Code != abci.CodeTypeOK {
+				err = errors.New(res.Log)
+			}
+		} else {
+			_, err = ep.client.BroadcastTxAsync(tx)
+		}
+		if err != nil {
+			r.logger.Debug("failed to relay tx", "endpoint", ep.addr, "private", r.private, "hash", tx.Hash(), "err", err)
+		}
+	}
+}
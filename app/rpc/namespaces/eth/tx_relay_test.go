@@ -0,0 +1,78 @@
+package eth
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+
+	"github.com/okex/exchain/libs/tendermint/libs/log"
+)
+
+func TestTxRelayer(t *testing.T) {
+	relayed := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		require.NoError(t, json.Unmarshal(body, &req))
+		relayed <- req.Method
+		w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(req.ID) + `,"result":{"code":0,"data":"","log":"","hash":"00"}}`))
+	}))
+	defer server.Close()
+
+	require.Nil(t, NewTxRelayer(log.NewNopLogger()))
+	viper.Set(FlagTxRelayEndpoints, server.URL+", ")
+	viper.Set(FlagTxRelayQueueSize, 1)
+	defer viper.Set(FlagTxRelayEndpoints, "")
+	relayer := NewTxRelayer(log.NewNopLogger())
+	require.Len(t, relayer.endpoints, 1)
+
+	relayer.Relay([]byte{0x01})
+	select {
+	case method := <-relayed:
+		require.Equal(t, "broadcast_tx_async", method)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the tx is not relayed")
+	}
+}
+
+func TestPrivateTxRelayer(t *testing.T) {
+	relayed := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		require.NoError(t, json.Unmarshal(body, &req))
+		relayed <- req.Method
+		w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(req.ID) + `,"result":{"code":0,"data":"","log":"","hash":"00"}}`))
+	}))
+	defer server.Close()
+
+	require.Nil(t, NewPrivateTxRelayer(log.NewNopLogger()))
+	viper.Set(FlagPrivateTxRelayEndpoints, server.URL)
+	viper.Set(FlagTxRelayQueueSize, 1)
+	defer viper.Set(FlagPrivateTxRelayEndpoints, "")
+	relayer := NewPrivateTxRelayer(log.NewNopLogger())
+	require.Len(t, relayer.endpoints, 1)
+
+	// the private txs are submitted without being gossiped by the endpoint
+	relayer.Relay([]byte{0x01})
+	select {
+	case method := <-relayed:
+		require.Equal(t, "broadcast_tx_private", method)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the tx is not relayed")
+	}
+}
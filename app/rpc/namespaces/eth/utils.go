@@ -39,11 +39,12 @@ const (
 func ParseGasPrice() *hexutil.Big {
 	gasPrices, err := sdk.ParseDecCoins(viper.GetString(server.FlagMinGasPrices))
 	if err == nil && gasPrices != nil && len(gasPrices) > 0 {
-		return (*hexutil.Big)(gasPrices[0].Amount.BigInt())
+		return (*hexutil.Big)(ethermint.DecToWei(gasPrices[0].Amount))
 	}
 
-	//return the default gas price : DefaultGasPrice
-	return (*hexutil.Big)(sdk.NewDecFromBigIntWithPrec(big.NewInt(ethermint.DefaultGasPrice), sdk.Precision/2+1).BigInt())
+	//return the default gas price : DefaultGasPrice, defined with DefaultGasPriceDecimals
+	return (*hexutil.Big)(ethermint.ScaleDecimals(big.NewInt(ethermint.DefaultGasPrice),
+		ethermint.DefaultGasPriceDecimals, sdk.Precision, ethermint.RoundDown))
 }
 
 type cosmosError struct {
@@ -0,0 +1,1081 @@
+package exchain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/okex/exchain/app/rpc/monitor"
+	"github.com/okex/exchain/app/rpc/namespaces/eth"
+	"github.com/okex/exchain/app/rpc/storeproof"
+	rpctypes "github.com/okex/exchain/app/rpc/types"
+	"github.com/okex/exchain/app/rpc/verifier"
+	ethermint "github.com/okex/exchain/app/types"
+	"github.com/okex/exchain/libs/cosmos-sdk/baseapp"
+	clientcontext "github.com/okex/exchain/libs/cosmos-sdk/client/context"
+	"github.com/okex/exchain/libs/cosmos-sdk/store/rootmulti"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	"github.com/okex/exchain/libs/cosmos-sdk/x/auth"
+	authclient "github.com/okex/exchain/libs/cosmos-sdk/x/auth/client/utils"
+	abci "github.com/okex/exchain/libs/tendermint/abci/types"
+	tmconfig "github.com/okex/exchain/libs/tendermint/config"
+	"github.com/okex/exchain/libs/tendermint/libs/log"
+	"github.com/okex/exchain/libs/tendermint/mempool"
+	ctypes "github.com/okex/exchain/libs/tendermint/rpc/core/types"
+	tmtypes "github.com/okex/exchain/libs/tendermint/types"
+	evmtypes "github.com/okex/exchain/x/evm/types"
+	"github.com/okex/exchain/x/evm/watcher"
+	"golang.org/x/time/rate"
+)
+
+// PublicExchainAPI is the exchain_ prefixed set of APIs, the helpers of the chain specific features.
+type PublicExchainAPI struct {
+	clientCtx clientcontext.CLIContext
+	logger    log.Logger
+	Metrics   map[string]*monitor.RpcMetrics
+
+	wrappedBackend *watcher.Querier
+	rateLimiters   map[string]*rate.Limiter
+	namespaces     []string
+	verifier       *verifier.Verifier
+	logsFilter     logsFilter
+	privateRelayer *eth.TxRelayer
+}
+
+// logsFilter is the eth_getLogs of the eth filter api
+type logsFilter interface {
+	GetLogs(ctx context.Context, criteria filters.FilterCriteria) ([]*ethtypes.Log, error)
+}
+
+// NewAPI creates an instance of the exchain API, the rate limiters are the ones of the --rpc.rate-limit-api.
+func NewAPI(clientCtx clientcontext.CLIContext, log log.Logger, rateLimiters map[string]*rate.Limiter) *PublicExchainAPI {
+	return &PublicExchainAPI{
+		clientCtx: clientCtx,
+		logger:    log.With("module", "json-rpc", "namespace", "exchain"),
+
+		wrappedBackend: watcher.NewQuerier(),
+		rateLimiters:   rateLimiters,
+		privateRelayer: eth.NewPrivateTxRelayer(log),
+	}
+}
+
+// SetVerifier sets the verifier of the contracts of exchain_verifyContract, the contract verification is disabled
+// without it
+func (api *PublicExchainAPI) SetVerifier(v *verifier.Verifier) {
+	api.verifier = v
+}
+
+// SetLogsFilter sets the filter of the logs of exchain_getDecodedLogs
+func (api *PublicExchainAPI) SetLogsFilter(filter logsFilter) {
+	api.logsFilter = filter
+}
+
+// SetNamespaces sets the namespaces of the apis served by the node, reported by GetChainStatus
+func (api *PublicExchainAPI) SetNamespaces(namespaces []string) {
+	api.namespaces = namespaces
+}
+
+// ConvertAddressResult is the address in both of its formats
+type ConvertAddressResult struct {
+	Hex    common.Address `json:"hex"`
+	Bech32 string         `json:"bech32"`
+}
+
+// ConvertAddress returns the hex and the bech32 formats of the hex or bech32 address.
+func (api *PublicExchainAPI) ConvertAddress(address rpctypes.Address) ConvertAddressResult {
+	monitor := monitor.GetMonitor("exchain_convertAddress", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("address", address)
+	return ConvertAddressResult{
+		Hex:    address.Address,
+		Bech32: sdk.AccAddress(address.Bytes()).String(),
+	}
+}
+
+// GetAccount returns the cosmos account of the hex or bech32 address with its type, e.g. a module or a
+// vesting account, at the given block number or at the latest block.
+func (api *PublicExchainAPI) GetAccount(address rpctypes.Address, blockNumber *rpctypes.BlockNumber) (json.RawMessage, error) {
+	monitor := monitor.GetMonitor("exchain_getAccount", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("address", address, "block number", blockNumber)
+	clientCtx := api.clientCtx
+	if blockNumber != nil && blockNumber.Int64() > 0 {
+		clientCtx = api.clientCtx.WithHeight(blockNumber.Int64())
+	}
+
+	bs, err := api.clientCtx.Codec.MarshalJSON(auth.NewQueryAccountParams(address.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+	res, _, err := clientCtx.QueryWithData(fmt.Sprintf("custom/%s/%s", auth.QuerierRoute, auth.QueryAccount), bs)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetTokenRegistry returns the erc20 metadata of the ibc denoms and bridged assets registered by the governance,
+// only the one of the denom if it is given.
+func (api *PublicExchainAPI) GetTokenRegistry(denom *string) ([]evmtypes.TokenMetadata, error) {
+	monitor := monitor.GetMonitor("exchain_getTokenRegistry", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("denom", denom)
+
+	route := fmt.Sprintf("custom/%s/%s", evmtypes.ModuleName, evmtypes.QueryTokenRegistry)
+	if denom != nil && *denom != "" {
+		res, _, err := api.clientCtx.QueryWithData(route+"/"+*denom, nil)
+		if err != nil {
+			return nil, err
+		}
+		var metadata evmtypes.TokenMetadata
+		if err := api.clientCtx.Codec.UnmarshalJSON(res, &metadata); err != nil {
+			return nil, err
+		}
+		return []evmtypes.TokenMetadata{metadata}, nil
+	}
+
+	res, _, err := api.clientCtx.QueryWithData(route, nil)
+	if err != nil {
+		return nil, err
+	}
+	registry := []evmtypes.TokenMetadata{}
+	if err := api.clientCtx.Codec.UnmarshalJSON(res, &registry); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// SimulateBundle executes the calls and the signed raw txs of the bundle in order on top of the state of the
+// given block or of the latest block, once the state and the block overrides are applied. It returns the
+// result, the gas used, the logs and the state changes of each tx. The nonce of the raw txs is checked but no
+// gas fee is charged.
+func (api *PublicExchainAPI) SimulateBundle(req evmtypes.SimulateBundleRequest, blockNumber *rpctypes.BlockNumber) ([]evmtypes.BundleTxResult, error) {
+	monitor := monitor.GetMonitor("exchain_simulateBundle", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("txs", len(req.Txs), "block number", blockNumber)
+	var height int64
+	if blockNumber != nil && blockNumber.Int64() > 0 {
+		height = blockNumber.Int64()
+	}
+	return rpctypes.SimulateBundle(api.clientCtx, req, height)
+}
+
+// PendingReceipt is the provisional receipt of a pending tx, executed on top of the latest state. It may differ
+// from the receipt of the tx once included, as the txs before it in the block may change the state.
+type PendingReceipt struct {
+	TransactionHash common.Hash     `json:"transactionHash"`
+	Pending         bool            `json:"pending"`
+	From            common.Address  `json:"from"`
+	To              *common.Address `json:"to"`
+	Status          hexutil.Uint64  `json:"status"`
+	GasUsed         hexutil.Uint64  `json:"gasUsed"`
+	ContractAddress *common.Address `json:"contractAddress"`
+	Logs            []*ethtypes.Log `json:"logs"`
+	Error           string          `json:"error,omitempty"`
+	// Call and DecodedLogs are the call of the tx and its logs rendered with the abis of the verified contracts
+	// and the signatures db of the node, the decoded logs are aligned with the logs and nil for the unknown events
+	Call        *evmtypes.DecodedCall   `json:"call,omitempty"`
+	DecodedLogs []*evmtypes.DecodedCall `json:"decodedLogs,omitempty"`
+}
+
+// GetPendingReceipt executes the tx of the mempool with the given hash on top of the latest state, after the
+// pending txs of its sender with a lower nonce, and returns its provisional receipt. It returns nil if the tx
+// isn't pending.
+func (api *PublicExchainAPI) GetPendingReceipt(hash common.Hash) (*PendingReceipt, error) {
+	monitor := monitor.GetMonitor("exchain_getPendingReceipt", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("hash", hash)
+	pendingTx, err := api.clientCtx.Client.GetUnconfirmedTxByHash(hash)
+	if errors.Is(err, mempool.ErrNoSuchTx) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	ethTx, err := rpctypes.RawTxToEthTx(api.clientCtx, pendingTx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := rpctypes.NewTransaction(ethTx, hash, common.Hash{}, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// the pending txs of the sender before the tx are executed first, so that its nonce is valid
+	senderTxs, err := api.clientCtx.Client.UserUnconfirmedTxs(tx.From.String(), -1)
+	if err != nil {
+		return nil, err
+	}
+	var previous []*evmtypes.MsgEthereumTx
+	for _, bz := range senderTxs.Txs {
+		senderTx, err := rpctypes.RawTxToEthTx(api.clientCtx, bz)
+		if err != nil || senderTx.Data.AccountNonce >= ethTx.Data.AccountNonce {
+			continue
+		}
+		previous = append(previous, senderTx)
+	}
+	sort.Slice(previous, func(i, j int) bool { return previous[i].Data.AccountNonce < previous[j].Data.AccountNonce })
+
+	var req evmtypes.SimulateBundleRequest
+	for _, msg := range append(previous, ethTx) {
+		raw, err := rlp.EncodeToBytes(msg)
+		if err != nil {
+			return nil, err
+		}
+		req.Txs = append(req.Txs, evmtypes.BundleTx{Raw: raw})
+	}
+	results, err := rpctypes.SimulateBundle(api.clientCtx, req, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) != len(req.Txs) {
+		return nil, fmt.Errorf("%d results for a bundle of %d txs", len(results), len(req.Txs))
+	}
+
+	result := results[len(results)-1]
+	receipt := &PendingReceipt{
+		TransactionHash: hash,
+		Pending:         true,
+		From:            tx.From,
+		To:              tx.To,
+		Status:          hexutil.Uint64(1),
+		GasUsed:         result.GasUsed,
+		ContractAddress: result.ContractAddress,
+		Logs:            result.Logs,
+		Error:           result.Error,
+	}
+	if result.Error != "" {
+		receipt.Status = 0
+	}
+	if receipt.Logs == nil {
+		receipt.Logs = []*ethtypes.Log{}
+	}
+
+	if tx.To != nil {
+		receipt.Call = api.decodeCallData(*tx.To, ethTx.Data.Payload)
+	}
+	var decoded bool
+	decodedLogs := make([]*evmtypes.DecodedCall, len(receipt.Logs))
+	for i, receiptLog := range receipt.Logs {
+		decodedLogs[i] = api.decodeLog(receiptLog)
+		decoded = decoded || decodedLogs[i] != nil
+	}
+	if decoded {
+		receipt.DecodedLogs = decodedLogs
+	}
+	return receipt, nil
+}
+
+// LookupSignature returns the signature of the signatures db of the node with the given selector, the 4 bytes
+// selector of a function or the topic of an event. It returns nil if the selector is unknown. The signatures db
+// is filled by admin_addSignatureABI, admin_importSignatures and admin_resolveSignatures.
+func (api *PublicExchainAPI) LookupSignature(selector hexutil.Bytes) (*evmtypes.Signature, error) {
+	monitor := monitor.GetMonitor("exchain_lookupSignature", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("selector", selector)
+	if len(selector) != 4 && len(selector) != common.HashLength {
+		return nil, fmt.Errorf("invalid selector %s, expected 4 bytes or 32 bytes", selector)
+	}
+	sig, found := evmtypes.GetSignature(selector)
+	if !found {
+		return nil, nil
+	}
+	return &sig, nil
+}
+
+// errVerifierDisabled is returned by the contract verification apis of a node without --rpc.verifier-solc-dir
+var errVerifierDisabled = errors.New("the contract verification is disabled, see --rpc.verifier-solc-dir")
+
+// VerifyContract compiles the sources of the request with the solc of its compiler version and matches the
+// contract with the bytecode deployed at its address, ignoring the metadata hash for a partial match. The record
+// of a verified contract is kept by the node and returned by exchain_getVerifiedContract. With
+// exchain_verifyContract in --rpc.rate-limit-api, each verification takes a token of the rate limiter.
+func (api *PublicExchainAPI) VerifyContract(req verifier.VerifyRequest) (*verifier.Record, error) {
+	monitor := monitor.GetMonitor("exchain_verifyContract", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("address", req.Address, "contract", req.ContractName, "compiler", req.CompilerVersion)
+
+	if api.verifier == nil {
+		return nil, errVerifierDisabled
+	}
+	if limiter := api.rateLimiters["exchain_verifyContract"]; limiter != nil && !limiter.Allow() {
+		return nil, fmt.Errorf("server is too busy, retry later")
+	}
+
+	res, _, err := api.clientCtx.QueryWithData(fmt.Sprintf("custom/%s/%s/%s", evmtypes.ModuleName, evmtypes.QueryCode, req.Address.Hex()), nil)
+	if err != nil {
+		return nil, err
+	}
+	var out evmtypes.QueryResCode
+	if err := api.clientCtx.Codec.UnmarshalJSON(res, &out); err != nil {
+		return nil, err
+	}
+	return api.verifier.Verify(req, out.Code)
+}
+
+// GetVerifiedContract returns the record of the verified contract at the address, with its sources, its compiler
+// settings and its abi. It returns nil if the contract isn't verified.
+func (api *PublicExchainAPI) GetVerifiedContract(address rpctypes.Address) (*verifier.Record, error) {
+	monitor := monitor.GetMonitor("exchain_getVerifiedContract", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("address", address)
+
+	if api.verifier == nil {
+		return nil, errVerifierDisabled
+	}
+	record, found := api.verifier.Get(address.Address)
+	if !found {
+		return nil, nil
+	}
+	return record, nil
+}
+
+// DecodedLog is a log along with its event decoded with the abi of the verified contract emitting it, or else
+// with the signatures db of the node. The event is nil if it's unknown.
+type DecodedLog struct {
+	Log   *ethtypes.Log         `json:"log"`
+	Event *evmtypes.DecodedCall `json:"event"`
+}
+
+// GetDecodedLogs returns the logs matching the criteria of eth_getLogs, along with their decoded event. The
+// events of the verified contracts are decoded with their abi, the other ones with the signatures db of the node.
+func (api *PublicExchainAPI) GetDecodedLogs(ctx context.Context, criteria filters.FilterCriteria) ([]DecodedLog, error) {
+	monitor := monitor.GetMonitor("exchain_getDecodedLogs", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("args", criteria)
+
+	if api.logsFilter == nil {
+		return nil, errors.New("the logs filter isn't available")
+	}
+	logs, err := api.logsFilter.GetLogs(ctx, criteria)
+	if err != nil {
+		return nil, err
+	}
+	decoded := make([]DecodedLog, len(logs))
+	for i, ethLog := range logs {
+		decoded[i] = DecodedLog{Log: ethLog, Event: api.decodeLog(ethLog)}
+	}
+	return decoded, nil
+}
+
+// DecodeCalldata decodes the call data of a call to the contract, e.g. the input of a tx, into its function and
+// its args. The call data is decoded with the abi of the contract if it's verified, or else with the signatures
+// db of the node. It returns nil if the function is unknown.
+func (api *PublicExchainAPI) DecodeCalldata(to *common.Address, data hexutil.Bytes) (*evmtypes.DecodedCall, error) {
+	monitor := monitor.GetMonitor("exchain_decodeCalldata", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("to", to)
+
+	if len(data) < 4 {
+		return nil, fmt.Errorf("call data of %d bytes has no selector", len(data))
+	}
+	if to == nil {
+		return evmtypes.DecodeCallData(data), nil
+	}
+	return api.decodeCallData(*to, data), nil
+}
+
+// decodeCallData decodes the call data with the abi of the verified contract, or else with the signatures db
+func (api *PublicExchainAPI) decodeCallData(to common.Address, data []byte) *evmtypes.DecodedCall {
+	if api.verifier != nil {
+		if contractABI, found := api.verifier.ABI(to); found {
+			if call := evmtypes.DecodeCallDataWithABI(contractABI, data); call != nil {
+				return call
+			}
+		}
+	}
+	return evmtypes.DecodeCallData(data)
+}
+
+// decodeLog decodes the event of the log with the abi of the verified contract, or else with the signatures db
+func (api *PublicExchainAPI) decodeLog(ethLog *ethtypes.Log) *evmtypes.DecodedCall {
+	if api.verifier != nil {
+		if contractABI, found := api.verifier.ABI(ethLog.Address); found {
+			if event := evmtypes.DecodeLogWithABI(contractABI, ethLog.Topics, ethLog.Data); event != nil {
+				return event
+			}
+		}
+	}
+	return evmtypes.DecodeLog(ethLog.Topics, ethLog.Data)
+}
+
+// GetTransactionsByAddress returns the txs sent or received by the address, from the latest one, which are
+// indexed by the watcher. The cursor is the nextCursor of the previous page, or nil for the first page.
+func (api *PublicExchainAPI) GetTransactionsByAddress(address rpctypes.Address, cursor *hexutil.Bytes, limit *hexutil.Uint) (*watcher.AddressTxs, error) {
+	monitor := monitor.GetMonitor("exchain_getTransactionsByAddress", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("address", address, "cursor", cursor, "limit", limit)
+
+	pageLimit := watcher.DefaultAddressTxsLimit
+	if limit != nil {
+		pageLimit = int(*limit)
+	}
+	var pageCursor []byte
+	if cursor != nil {
+		pageCursor = *cursor
+	}
+	return api.wrappedBackend.GetTransactionsByAddress(address.Address, pageCursor, pageLimit)
+}
+
+// GetBalanceHistory returns the changes of the native balance of the address, from the latest one, which are
+// indexed by the watcher with --fast-query-balance-history. Each change is the balance at the end of a block
+// changing it, with its delta. The cursor is the nextCursor of the previous page, or nil for the first page.
+func (api *PublicExchainAPI) GetBalanceHistory(address rpctypes.Address, cursor *hexutil.Bytes, limit *hexutil.Uint) (*watcher.BalanceHistory, error) {
+	monitor := monitor.GetMonitor("exchain_getBalanceHistory", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("address", address, "cursor", cursor, "limit", limit)
+
+	pageLimit := watcher.DefaultBalanceHistoryLimit
+	if limit != nil {
+		pageLimit = int(*limit)
+	}
+	var pageCursor []byte
+	if cursor != nil {
+		pageCursor = *cursor
+	}
+	return api.wrappedBackend.GetBalanceHistory(address.Address, pageCursor, pageLimit)
+}
+
+// GetLogStats returns the number of logs of the contract with the topic0, or with any topic if it's nil, in the
+// ranges of bucketSize blocks, so that the dapps can split their eth_getLogs queries before issuing them.
+func (api *PublicExchainAPI) GetLogStats(address rpctypes.Address, topic0 *common.Hash, fromBlock, toBlock rpctypes.BlockNumber, bucketSize *hexutil.Uint64) (*watcher.LogStats, error) {
+	monitor := monitor.GetMonitor("exchain_getLogStats", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("address", address, "topic0", topic0, "from", fromBlock, "to", toBlock, "bucket size", bucketSize)
+
+	from, err := api.resolveBlockNumber(fromBlock)
+	if err != nil {
+		return nil, err
+	}
+	to, err := api.resolveBlockNumber(toBlock)
+	if err != nil {
+		return nil, err
+	}
+	size := uint64(watcher.DefaultLogStatsBucketSize)
+	if bucketSize != nil {
+		size = uint64(*bucketSize)
+	}
+	return api.wrappedBackend.GetLogStats(address.Address, topic0, from, to, size)
+}
+
+// resolveBlockNumber returns the latest height of the watcher for the latest and the pending blocks
+func (api *PublicExchainAPI) resolveBlockNumber(blockNumber rpctypes.BlockNumber) (uint64, error) {
+	if blockNumber == rpctypes.LatestBlockNumber || blockNumber == rpctypes.PendingBlockNumber {
+		return api.wrappedBackend.GetLatestBlockNumber()
+	}
+	return uint64(blockNumber), nil
+}
+
+// chainStatusBlocks is the number of latest blocks the block time and the gas prices of the chain status are
+// computed over
+const chainStatusBlocks = 20
+
+// GasPricePercentiles are the percentiles of the gas prices of the evm txs of the latest blocks, nil if there
+// is no tx in them
+type GasPricePercentiles struct {
+	P10 *hexutil.Big `json:"p10"`
+	P50 *hexutil.Big `json:"p50"`
+	P90 *hexutil.Big `json:"p90"`
+}
+
+// ChainStatus is the overview of the chain and of the node for the monitoring dashboards
+type ChainStatus struct {
+	LatestBlockNumber hexutil.Uint64 `json:"latestBlockNumber"`
+	LatestBlockTime   hexutil.Uint64 `json:"latestBlockTime"`
+	// AverageBlockTime is in milliseconds
+	AverageBlockTime hexutil.Uint64 `json:"averageBlockTime"`
+	CatchingUp       bool           `json:"catchingUp"`
+	PendingTxs       hexutil.Uint64 `json:"pendingTxs"`
+	PeerCount        hexutil.Uint64 `json:"peerCount"`
+	// WatcherLag is the number of blocks the fast-query mode is behind the chain, nil if it is disabled
+	WatcherLag *hexutil.Uint64 `json:"watcherLag"`
+	// BloomLag is the number of blocks the bloom index is behind the chain, nil if it is disabled. The index
+	// is built per section of evmtypes.BloomBitsBlocks blocks, so it is always behind the latest section.
+	BloomLag   *hexutil.Uint64     `json:"bloomLag"`
+	GasPrices  GasPricePercentiles `json:"gasPrices"`
+	Namespaces []string            `json:"namespaces"`
+}
+
+// GetChainStatus returns the latest height, the average time and the gas prices of the latest blocks, the size
+// of the mempool, the number of peers, the lag of the watcher and of the bloom index, and the namespaces of the
+// apis served by the node, so that the dashboards need a single call.
+func (api *PublicExchainAPI) GetChainStatus() (*ChainStatus, error) {
+	monitor := monitor.GetMonitor("exchain_getChainStatus", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd()
+
+	nodeStatus, err := api.clientCtx.Client.Status()
+	if err != nil {
+		return nil, err
+	}
+	latest := nodeStatus.SyncInfo.LatestBlockHeight
+	status := &ChainStatus{
+		LatestBlockNumber: hexutil.Uint64(latest),
+		LatestBlockTime:   hexutil.Uint64(nodeStatus.SyncInfo.LatestBlockTime.Unix()),
+		CatchingUp:        nodeStatus.SyncInfo.CatchingUp,
+		Namespaces:        api.namespaces,
+	}
+
+	unconfirmed, err := api.clientCtx.Client.NumUnconfirmedTxs()
+	if err != nil {
+		return nil, err
+	}
+	status.PendingTxs = hexutil.Uint64(unconfirmed.Total)
+	netInfo, err := api.clientCtx.Client.NetInfo()
+	if err != nil {
+		return nil, err
+	}
+	status.PeerCount = hexutil.Uint64(netInfo.NPeers)
+
+	if watcher.IsWatcherEnabled() {
+		if height, err := api.wrappedBackend.GetLatestBlockNumber(); err == nil {
+			status.WatcherLag = blockLag(latest, int64(height))
+		}
+	}
+	if evmtypes.GetEnableBloomFilter() {
+		indexed := int64(evmtypes.GetIndexer().StoredSection()*evmtypes.BloomBitsBlocks) + tmtypes.GetStartBlockHeight()
+		status.BloomLag = blockLag(latest, indexed)
+	}
+
+	if latest > 1 {
+		from := latest - chainStatusBlocks + 1
+		if from < 1 {
+			from = 1
+		}
+		info, err := api.clientCtx.Client.BlockchainInfo(from, latest)
+		if err != nil {
+			return nil, err
+		}
+		metas := info.BlockMetas
+		if len(metas) > 1 {
+			// the metas are from the latest block
+			elapsed := metas[0].Header.Time.Sub(metas[len(metas)-1].Header.Time)
+			status.AverageBlockTime = hexutil.Uint64(elapsed / time.Duration(len(metas)-1) / time.Millisecond)
+		}
+		if status.GasPrices, err = api.gasPricePercentiles(metas); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// gasPricePercentiles returns the percentiles of the gas prices of the evm txs of the blocks
+func (api *PublicExchainAPI) gasPricePercentiles(metas []*tmtypes.BlockMeta) (GasPricePercentiles, error) {
+	var prices []*big.Int
+	for _, meta := range metas {
+		if meta.NumTxs == 0 {
+			continue
+		}
+		height := meta.Header.Height
+		block, err := api.clientCtx.Client.Block(&height)
+		if err != nil {
+			return GasPricePercentiles{}, err
+		}
+		for _, tx := range block.Block.Txs {
+			ethTx, err := rpctypes.RawTxToEthTx(api.clientCtx, tx)
+			if err != nil {
+				continue
+			}
+			prices = append(prices, ethTx.Data.Price)
+		}
+	}
+	if len(prices) == 0 {
+		return GasPricePercentiles{}, nil
+	}
+
+	sort.Slice(prices, func(i, j int) bool { return prices[i].Cmp(prices[j]) < 0 })
+	percentile := func(p int) *hexutil.Big {
+		return (*hexutil.Big)(prices[(len(prices)-1)*p/100])
+	}
+	return GasPricePercentiles{P10: percentile(10), P50: percentile(50), P90: percentile(90)}, nil
+}
+
+func blockLag(latest, height int64) *hexutil.Uint64 {
+	lag := hexutil.Uint64(0)
+	if latest > height {
+		lag = hexutil.Uint64(latest - height)
+	}
+	return &lag
+}
+
+// blockPreviewer is implemented by the mempools previewing the next block they'd propose
+type blockPreviewer interface {
+	PreviewBlock(maxBytes, maxGas int64) mempool.BlockPreview
+}
+
+// NextBlockPreview is the candidate next block assembled from the mempool of the node
+type NextBlockPreview struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	// SortedByGasPrice is true if the txs are ordered by gas price, else by arrival
+	SortedByGasPrice bool           `json:"sortedByGasPrice"`
+	MaxBytes         hexutil.Uint64 `json:"maxBytes"`
+	// MaxGas is nil without a gas limit per block
+	MaxGas     *hexutil.Uint64 `json:"maxGas"`
+	Txs        []PreviewTx     `json:"transactions"`
+	TotalBytes hexutil.Uint64  `json:"totalBytes"`
+	TotalGas   hexutil.Uint64  `json:"totalGas"`
+	// Excluded is the number of the txs of the mempool left out of the block
+	Excluded hexutil.Uint64 `json:"excluded"`
+	// Limit is the limit the block is full at, empty if all the txs of the mempool are included
+	Limit string `json:"limit,omitempty"`
+}
+
+// PreviewTx is a tx of the candidate next block. Its gas is the simulated gas used when the node limits the gas
+// used per block, else its gas limit.
+type PreviewTx struct {
+	Hash     common.Hash    `json:"hash"`
+	From     string         `json:"from"`
+	Nonce    hexutil.Uint64 `json:"nonce"`
+	GasPrice *hexutil.Big   `json:"gasPrice"`
+	Gas      hexutil.Uint64 `json:"gas"`
+	Size     hexutil.Uint64 `json:"size"`
+}
+
+// PreviewNextBlock returns the txs the mempool of the node would propose for the next block, in their order,
+// with the limits of the block, so that the operators see why a tx isn't included. The size reserved for the
+// evidence is ignored, the block is larger than the proposed one when some evidence is pending. Nothing is
+// removed from the mempool.
+func (api *PublicExchainAPI) PreviewNextBlock() (*NextBlockPreview, error) {
+	monitor := monitor.GetMonitor("exchain_previewNextBlock", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd()
+
+	previewer, ok := baseapp.GetGlobalMempool().(blockPreviewer)
+	if !ok {
+		return nil, errors.New("the mempool of the node isn't available")
+	}
+	params, err := api.clientCtx.Client.ConsensusParams(nil)
+	if err != nil {
+		return nil, err
+	}
+	validators, err := api.clientCtx.Client.Validators(nil, 1, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	// the same limits as the proposals of the node
+	maxBytes := tmtypes.MaxDataBytes(params.ConsensusParams.Block.MaxBytes, validators.Total, 0)
+	maxGas := params.ConsensusParams.Block.MaxGas
+	if maxGasUsed := tmconfig.DynamicConfig.GetMaxGasUsedPerBlock(); maxGasUsed > -1 {
+		maxGas = maxGasUsed
+	}
+	block := previewer.PreviewBlock(maxBytes, maxGas)
+
+	preview := &NextBlockPreview{
+		BlockNumber:      hexutil.Uint64(params.BlockHeight + 1),
+		SortedByGasPrice: block.SortedByGasPrice,
+		MaxBytes:         hexutil.Uint64(maxBytes),
+		Txs:              make([]PreviewTx, len(block.Txs)),
+		TotalBytes:       hexutil.Uint64(block.TotalBytes),
+		TotalGas:         hexutil.Uint64(block.TotalGas),
+		Excluded:         hexutil.Uint64(block.Excluded),
+		Limit:            block.Limit,
+	}
+	if maxGas > -1 {
+		gas := hexutil.Uint64(maxGas)
+		preview.MaxGas = &gas
+	}
+	for i, tx := range block.Txs {
+		preview.Txs[i] = PreviewTx{
+			Hash:     common.BytesToHash(tx.Tx.Hash()),
+			From:     tx.Sender,
+			Nonce:    hexutil.Uint64(tx.Nonce),
+			GasPrice: (*hexutil.Big)(tx.GasPrice),
+			Gas:      hexutil.Uint64(tx.Gas),
+			Size:     hexutil.Uint64(tx.Bytes),
+		}
+		// the mempool tracks the senders by their bech32 address
+		if addr, err := sdk.AccAddressFromBech32(tx.Sender); err == nil {
+			preview.Txs[i].From = common.BytesToAddress(addr).Hex()
+		}
+	}
+	return preview, nil
+}
+
+// privateBroadcaster is implemented by the tendermint clients submitting the txs privately to the node
+type privateBroadcaster interface {
+	BroadcastTxPrivate(tx tmtypes.Tx) (*ctypes.ResultBroadcastTx, error)
+}
+
+// SendPrivateTransaction checks the signed raw tx against the mempool of the node and holds it there without
+// gossiping it to the peers nor publishing it to the subscribers of the pending txs, then relays it privately to
+// the endpoints of --rpc.private-tx-relay-endpoints. The tx is only included once the node or a relay proposes a
+// block, so the node must be a validator or relay it to the validators. It returns the hash of the tx.
+func (api *PublicExchainAPI) SendPrivateTransaction(data hexutil.Bytes) (common.Hash, error) {
+	monitor := monitor.GetMonitor("exchain_sendPrivateTransaction", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("data", data)
+
+	tx := new(evmtypes.MsgEthereumTx)
+	if err := rlp.DecodeBytes(data, tx); err != nil {
+		return common.Hash{}, err
+	}
+	txBytes, err := authclient.GetTxEncoder(api.clientCtx.Codec)(tx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	broadcaster, ok := api.clientCtx.Client.(privateBroadcaster)
+	if !ok {
+		return common.Hash{}, errors.New("the node doesn't accept private txs")
+	}
+	res, err := broadcaster.BroadcastTxPrivate(txBytes)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if res.Code != abci.CodeTypeOK {
+		return eth.CheckError(sdk.TxResponse{Code: res.Code, Codespace: res.Codespace, RawLog: res.Log})
+	}
+	api.privateRelayer.Relay(txBytes)
+	return common.BytesToHash(res.Hash), nil
+}
+
+// maxRecoverSenders is the max number of txs of a exchain_recoverSenders batch
+const maxRecoverSenders = 1000
+
+// RecoveredSender is the sender of a tx, or the error recovering it
+type RecoveredSender struct {
+	Hash  common.Hash     `json:"hash"`
+	From  *common.Address `json:"from"`
+	Error string          `json:"error,omitempty"`
+}
+
+// RecoverSenders returns the senders of the txs, each given as a raw signed tx or as the hash of a committed tx,
+// in the same order. The senders are recovered through the signature cache of the node, and the txs indexed by
+// the watcher aren't recovered again. A tx which fails doesn't fail the batch, its error is returned with it.
+// With exchain_recoverSenders in --rpc.rate-limit-api, each tx of the batch takes a token of the rate limiter,
+// so a batch larger than the burst of the limiter is always rejected.
+func (api *PublicExchainAPI) RecoverSenders(txs []hexutil.Bytes) ([]RecoveredSender, error) {
+	monitor := monitor.GetMonitor("exchain_recoverSenders", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("txs", len(txs))
+
+	if len(txs) > maxRecoverSenders {
+		return nil, fmt.Errorf("batch of %d txs exceeds the max of %d", len(txs), maxRecoverSenders)
+	}
+	if limiter := api.rateLimiters["exchain_recoverSenders"]; limiter != nil && !limiter.AllowN(time.Now(), len(txs)) {
+		if len(txs) > limiter.Burst() {
+			return nil, fmt.Errorf("batch of %d txs exceeds the rate limit, split it into batches of at most %d txs", len(txs), limiter.Burst())
+		}
+		return nil, fmt.Errorf("server is too busy, retry later")
+	}
+
+	senders := make([]RecoveredSender, len(txs))
+	for i, tx := range txs {
+		var err error
+		if len(tx) == common.HashLength {
+			senders[i], err = api.recoverSenderByHash(common.BytesToHash(tx))
+		} else {
+			senders[i], err = api.recoverSender(tx, 0)
+		}
+		if err != nil {
+			senders[i].Error = err.Error()
+		}
+	}
+	return senders, nil
+}
+
+// recoverSenderByHash returns the sender of the committed tx with the hash
+func (api *PublicExchainAPI) recoverSenderByHash(hash common.Hash) (RecoveredSender, error) {
+	if tx, err := api.wrappedBackend.GetTransactionByHash(hash); err == nil {
+		return RecoveredSender{Hash: hash, From: &tx.From}, nil
+	}
+	res, err := api.clientCtx.Client.Tx(hash.Bytes(), false)
+	if err != nil {
+		return RecoveredSender{Hash: hash}, fmt.Errorf("tx not found: %w", err)
+	}
+	return api.recoverSender(res.Tx, res.Height)
+}
+
+// recoverSender returns the sender of the raw tx, the height tells the signers of the tx, 0 if it is unknown
+func (api *PublicExchainAPI) recoverSender(bz []byte, height int64) (RecoveredSender, error) {
+	sender := RecoveredSender{Hash: common.BytesToHash(tmtypes.Tx(bz).Hash())}
+	ethTx, err := rpctypes.RawTxToEthTx(api.clientCtx, bz)
+	if err != nil {
+		return sender, err
+	}
+	sigCache, err := ethTx.VerifySig(ethTx.ChainID(), height, nil)
+	if err != nil {
+		return sender, err
+	}
+	from := sigCache.GetFrom()
+	sender.From = &from
+	return sender, nil
+}
+
+// maxEstimateGasBatch is the max number of calls of a exchain_estimateGasBatch batch
+const maxEstimateGasBatch = 100
+
+// GasEstimate is the estimated gas of a call, or the error executing it
+type GasEstimate struct {
+	Gas   hexutil.Uint64 `json:"gas"`
+	Error string         `json:"error,omitempty"`
+}
+
+// EstimateGasBatch returns the estimated gas of each call in the same order, with the buffer of eth_estimateGas.
+// The calls are executed concurrently and independently of each other, on top of the state of the given block or
+// of the latest block, so that the estimations of a multicall bundle are done in a single request on the same
+// state. A call which fails doesn't fail the batch, its error is returned with it. With exchain_estimateGasBatch
+// in --rpc.rate-limit-api, each call of the batch takes a token of the rate limiter.
+func (api *PublicExchainAPI) EstimateGasBatch(args []rpctypes.CallArgs, blockNumber *rpctypes.BlockNumber) ([]GasEstimate, error) {
+	monitor := monitor.GetMonitor("exchain_estimateGasBatch", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("calls", len(args), "block number", blockNumber)
+
+	if len(args) > maxEstimateGasBatch {
+		return nil, fmt.Errorf("batch of %d calls exceeds the max of %d", len(args), maxEstimateGasBatch)
+	}
+	if limiter := api.rateLimiters["exchain_estimateGasBatch"]; limiter != nil && !limiter.AllowN(time.Now(), len(args)) {
+		if len(args) > limiter.Burst() {
+			return nil, fmt.Errorf("batch of %d calls exceeds the rate limit, split it into batches of at most %d calls", len(args), limiter.Burst())
+		}
+		return nil, fmt.Errorf("server is too busy, retry later")
+	}
+
+	// all the calls are pinned to the same height, a new block doesn't change the state of the later ones
+	height := int64(0)
+	if blockNumber != nil && blockNumber.Int64() > 0 {
+		height = blockNumber.Int64()
+	} else {
+		status, err := api.clientCtx.Client.Status()
+		if err != nil {
+			return nil, err
+		}
+		height = status.SyncInfo.LatestBlockHeight
+	}
+	clientCtx := api.clientCtx.WithHeight(height)
+
+	estimates := make([]GasEstimate, len(args))
+	workers := make(chan struct{}, runtime.NumCPU())
+	var wg sync.WaitGroup
+	for i := range args {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+			gas, err := estimateGas(clientCtx, args[i])
+			if err != nil {
+				estimates[i].Error = err.Error()
+				return
+			}
+			estimates[i].Gas = hexutil.Uint64(gas)
+		}(i)
+	}
+	wg.Wait()
+	return estimates, nil
+}
+
+// estimateGas simulates the call as eth_estimateGas does and returns the gas it uses with the gas limit buffer.
+// The nonce of the sender is only used by the deployments, for the address of the contract.
+func estimateGas(clientCtx clientcontext.CLIContext, args rpctypes.CallArgs) (uint64, error) {
+	var from common.Address
+	if args.From != nil {
+		from = *args.From
+	}
+	nonce := uint64(0)
+	if args.To == nil && args.Data != nil {
+		if account, err := auth.NewAccountRetriever(clientCtx).GetAccount(from.Bytes()); err == nil {
+			nonce = account.GetSequence()
+		}
+	}
+
+	msg := rpctypes.NewCallMsg(args, nonce, big.NewInt(ethermint.DefaultRPCGasLimit))
+	simResponse, err := rpctypes.SimulateMsgs(clientCtx, []sdk.Msg{msg})
+	if err != nil {
+		return 0, err
+	}
+	return rpctypes.EstimatedGas(simResponse.GasInfo.GasUsed), nil
+}
+
+// StateRoot is the state committed by a block with the roots of the module stores in it, for the proof systems
+// and the bridges anchoring to the chain
+type StateRoot struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	// StateRoot is the app hash once the block is executed, the root of the stores of all the modules. The
+	// stateRoot of the eth blocks is the app hash of their header, the state root of the previous block.
+	StateRoot hexutil.Bytes `json:"stateRoot"`
+	// Stores are the roots of the module stores, the leaves of the state root, sorted by name
+	Stores []StoreRoot `json:"stores"`
+	// AnchorBlockNumber is the block whose signed header commits the state root, the next block
+	AnchorBlockNumber hexutil.Uint64 `json:"anchorBlockNumber"`
+	AnchorBlockHash   common.Hash    `json:"anchorBlockHash"`
+}
+
+// StoreRoot is the root of a module store with its proof in the state root
+type StoreRoot struct {
+	Name string        `json:"name"`
+	Root hexutil.Bytes `json:"root"`
+	// Proof is the ics23 proof of the store in the state root, its value is the hash of the root
+	Proof storeproof.CommitmentProof `json:"proof"`
+}
+
+// GetStateRoot returns the state root once the block is executed with the roots of the module stores and their
+// proofs in it, and the block whose header commits it. The state root of the latest block isn't committed until
+// the next block is.
+func (api *PublicExchainAPI) GetStateRoot(blockNumber rpctypes.BlockNumber) (*StateRoot, error) {
+	monitor := monitor.GetMonitor("exchain_getStateRoot", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("block number", blockNumber)
+
+	height, block, anchor, err := api.committedBlock(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	stores, err := api.storeRoots(height, anchor.AppHash)
+	if err != nil {
+		return nil, err
+	}
+	return &StateRoot{
+		BlockNumber:       hexutil.Uint64(height),
+		BlockHash:         common.BytesToHash(block.Hash()),
+		StateRoot:         hexutil.Bytes(anchor.AppHash),
+		Stores:            stores,
+		AnchorBlockNumber: hexutil.Uint64(anchor.Height),
+		AnchorBlockHash:   common.BytesToHash(anchor.Hash()),
+	}, nil
+}
+
+// committedBlock returns the height and the header of the block, with the header of the next block which commits
+// its state. The latest and the pending blocks are the latest block whose state is committed.
+func (api *PublicExchainAPI) committedBlock(blockNumber rpctypes.BlockNumber) (int64, *tmtypes.SignedHeader, *tmtypes.SignedHeader, error) {
+	height := blockNumber.Int64()
+	if blockNumber == rpctypes.LatestBlockNumber || blockNumber == rpctypes.PendingBlockNumber {
+		status, err := api.clientCtx.Client.Status()
+		if err != nil {
+			return 0, nil, nil, err
+		}
+		height = status.SyncInfo.LatestBlockHeight - 1
+	}
+	if height <= 0 {
+		return 0, nil, nil, fmt.Errorf("invalid block number %d", height)
+	}
+	block, err := api.clientCtx.Client.Commit(&height)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	anchorHeight := height + 1
+	anchor, err := api.clientCtx.Client.Commit(&anchorHeight)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("the state of block %d is committed by the next block: %w", height, err)
+	}
+	return height, &block.SignedHeader, &anchor.SignedHeader, nil
+}
+
+// storeRoots returns the roots of the stores once the block at the height is executed with their proofs, from the
+// multistore proof of a query of the evm store, checked against the state root
+func (api *PublicExchainAPI) storeRoots(height int64, stateRoot []byte) ([]StoreRoot, error) {
+	res, err := api.clientCtx.WithHeight(height).QueryABCI(abci.RequestQuery{
+		Path:   fmt.Sprintf("store/%s/key", evmtypes.StoreKey),
+		Data:   []byte{0x00},
+		Height: height,
+		Prove:  true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.Proof == nil {
+		return nil, fmt.Errorf("no proof of the stores at height %d", height)
+	}
+	for _, op := range res.Proof.Ops {
+		if op.Type != rootmulti.ProofOpMultiStore {
+			continue
+		}
+		decoded, err := rootmulti.MultiStoreProofOpDecoder(op)
+		if err != nil {
+			return nil, err
+		}
+		proof := decoded.(rootmulti.MultiStoreProofOp).Proof
+		if !bytes.Equal(proof.ComputeRootHash(), stateRoot) {
+			return nil, fmt.Errorf("the stores at height %d don't match the state root %X", height, stateRoot)
+		}
+
+		stores := make([]StoreRoot, 0, len(proof.StoreInfos))
+		for _, info := range proof.StoreInfos {
+			storeProof, err := storeproof.FromMultiStore(proof, info.Name)
+			if err != nil {
+				return nil, err
+			}
+			stores = append(stores, StoreRoot{
+				Name:  info.Name,
+				Root:  info.Core.CommitID.Hash,
+				Proof: storeproof.CommitmentProof{Exist: storeProof},
+			})
+		}
+		sort.Slice(stores, func(i, j int) bool { return stores[i].Name < stores[j].Name })
+		return stores, nil
+	}
+	return nil, fmt.Errorf("no multistore proof at height %d", height)
+}
+
+// StoreProof is the ics23 proof of a key of a module store against the state root, for the bridge relayers
+// verifying the state of the chain on their counterpart chains
+type StoreProof struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	Store       string         `json:"store"`
+	Key         hexutil.Bytes  `json:"key"`
+	Value       hexutil.Bytes  `json:"value"`
+	// Proofs are the proof of the key in the store then the proof of the store in the state root
+	Proofs []storeproof.CommitmentProof `json:"proofs"`
+	// MerkleProof is the protobuf encoding of the ibc merkle proof of the proofs
+	MerkleProof hexutil.Bytes `json:"merkleProof"`
+	StateRoot   hexutil.Bytes `json:"stateRoot"`
+	// AnchorBlockNumber is the block whose signed header commits the state root, the next block
+	AnchorBlockNumber hexutil.Uint64 `json:"anchorBlockNumber"`
+	AnchorBlockHash   common.Hash    `json:"anchorBlockHash"`
+}
+
+// GetStoreProof returns the ics23 proof of the key of the module store once the block is executed, against the
+// state root committed by the next block. Only the keys in the store can be proven.
+func (api *PublicExchainAPI) GetStoreProof(storeName string, key hexutil.Bytes, blockNumber rpctypes.BlockNumber) (*StoreProof, error) {
+	monitor := monitor.GetMonitor("exchain_getStoreProof", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("store", storeName, "key", key, "block number", blockNumber)
+	return api.storeProof(storeName, key, blockNumber)
+}
+
+// GetStorageProof returns the ics23 proof of the storage slot of the contract in the evm store once the block is
+// executed, against the state root committed by the next block.
+func (api *PublicExchainAPI) GetStorageProof(address rpctypes.Address, slot common.Hash, blockNumber rpctypes.BlockNumber) (*StoreProof, error) {
+	monitor := monitor.GetMonitor("exchain_getStorageProof", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("address", address, "slot", slot, "block number", blockNumber)
+
+	// the slots are keyed as in the state objects of the evm
+	hash := ethcrypto.Keccak256Hash(address.Bytes(), slot.Bytes())
+	key := append(evmtypes.AddressStoragePrefix(address.Address), hash.Bytes()...)
+	return api.storeProof(evmtypes.StoreKey, key, blockNumber)
+}
+
+func (api *PublicExchainAPI) storeProof(storeName string, key []byte, blockNumber rpctypes.BlockNumber) (*StoreProof, error) {
+	if len(key) == 0 {
+		return nil, fmt.Errorf("empty key")
+	}
+	height, _, anchor, err := api.committedBlock(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	// the query is sent at the height of the context
+	res, err := api.clientCtx.WithHeight(height).QueryABCI(abci.RequestQuery{
+		Path:   fmt.Sprintf("store/%s/key", storeName),
+		Data:   key,
+		Height: height,
+		Prove:  true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	proofs, root, err := storeproof.Prove(storeName, key, res.Value, res.Proof)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(root, anchor.AppHash) {
+		return nil, fmt.Errorf("the proof at height %d doesn't match the state root %X", height, anchor.AppHash)
+	}
+	return &StoreProof{
+		BlockNumber:       hexutil.Uint64(height),
+		Store:             storeName,
+		Key:               key,
+		Value:             res.Value,
+		Proofs:            proofs,
+		MerkleProof:       storeproof.MarshalMerkleProof(proofs),
+		StateRoot:         hexutil.Bytes(anchor.AppHash),
+		AnchorBlockNumber: hexutil.Uint64(anchor.Height),
+		AnchorBlockHash:   common.BytesToHash(anchor.Hash()),
+	}, nil
+}
@@ -0,0 +1,440 @@
+package exchain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+	"golang.org/x/time/rate"
+
+	"github.com/okex/exchain/app/crypto/ethsecp256k1"
+	"github.com/okex/exchain/app/rpc/namespaces/eth"
+	rpctypes "github.com/okex/exchain/app/rpc/types"
+	clientcontext "github.com/okex/exchain/libs/cosmos-sdk/client/context"
+	"github.com/okex/exchain/libs/cosmos-sdk/codec"
+	"github.com/okex/exchain/libs/cosmos-sdk/crypto/keys"
+	"github.com/okex/exchain/libs/cosmos-sdk/store/rootmulti"
+	storetypes "github.com/okex/exchain/libs/cosmos-sdk/store/types"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	"github.com/okex/exchain/libs/cosmos-sdk/x/auth"
+	"github.com/okex/exchain/libs/iavl"
+	abci "github.com/okex/exchain/libs/tendermint/abci/types"
+	tmbytes "github.com/okex/exchain/libs/tendermint/libs/bytes"
+	"github.com/okex/exchain/libs/tendermint/libs/log"
+	"github.com/okex/exchain/libs/tendermint/mempool"
+	rpcclient "github.com/okex/exchain/libs/tendermint/rpc/client"
+	ctypes "github.com/okex/exchain/libs/tendermint/rpc/core/types"
+	"github.com/okex/exchain/libs/tendermint/types"
+	evmtypes "github.com/okex/exchain/x/evm/types"
+	"github.com/okex/exchain/x/evm/watcher"
+)
+
+// revertingCall is the calldata of the calls failing in the simulations of the node
+var revertingCall = hexutil.Bytes{0xde, 0xad}
+
+// node is the client of a node, serving the pending txs of its mempool and the simulations of the calls
+type node struct {
+	rpcclient.Client
+	cdc        *codec.Codec
+	pending    map[[sha256.Size]byte]types.Tx
+	mempoolErr error
+	// simulatedHeights are the heights of the simulations
+	simulatedHeights []int64
+	mtx              sync.Mutex
+	// multi is the state of the node, the app hash of each block is the state once the previous block is executed
+	multi   *rootmulti.Store
+	headers map[int64]types.Header
+}
+
+func newTestCodec() *codec.Codec {
+	cdc := codec.New()
+	sdk.RegisterCodec(cdc)
+	codec.RegisterCrypto(cdc)
+	auth.RegisterCodec(cdc)
+	evmtypes.RegisterCodec(cdc)
+	return cdc
+}
+
+func (n *node) Status() (*ctypes.ResultStatus, error) {
+	return &ctypes.ResultStatus{SyncInfo: ctypes.SyncInfo{LatestBlockHeight: 10}}, nil
+}
+
+// ABCIQueryWithOptions simulates the calls with a gas of 21000 plus 100 per byte of calldata, the calls of
+// revertingCall fail
+func (n *node) ABCIQueryWithOptions(path string, data tmbytes.HexBytes, opts rpcclient.ABCIQueryOptions) (*ctypes.ResultABCIQuery, error) {
+	if strings.HasPrefix(path, "store/") {
+		res := n.multi.Query(abci.RequestQuery{Path: strings.TrimPrefix(path, "store"), Data: data, Height: opts.Height, Prove: opts.Prove})
+		return &ctypes.ResultABCIQuery{Response: res}, nil
+	}
+	if path != "app/simulate" {
+		return &ctypes.ResultABCIQuery{Response: abci.ResponseQuery{Code: 1, Log: "unknown query"}}, nil
+	}
+	n.mtx.Lock()
+	n.simulatedHeights = append(n.simulatedHeights, opts.Height)
+	n.mtx.Unlock()
+	var tx auth.StdTx
+	if err := n.cdc.UnmarshalBinaryLengthPrefixed(data, &tx); err != nil {
+		return nil, err
+	}
+	msg := tx.GetMsgs()[0].(evmtypes.MsgEthermint)
+	if bytes.Equal(msg.Payload, revertingCall) {
+		return &ctypes.ResultABCIQuery{Response: abci.ResponseQuery{Code: 7, Log: "execution reverted"}}, nil
+	}
+	res := sdk.SimulationResponse{GasInfo: sdk.GasInfo{GasUsed: 21000 + 100*uint64(len(msg.Payload))}, Result: &sdk.Result{}}
+	return &ctypes.ResultABCIQuery{Response: abci.ResponseQuery{Value: n.cdc.MustMarshalBinaryBare(res)}}, nil
+}
+
+func (n *node) Commit(height *int64) (*ctypes.ResultCommit, error) {
+	header, ok := n.headers[*height]
+	if !ok {
+		return nil, fmt.Errorf("no block at height %d", *height)
+	}
+	return &ctypes.ResultCommit{SignedHeader: types.SignedHeader{Header: &header, Commit: &types.Commit{Height: *height}}}, nil
+}
+
+// newStateNode returns a node of the given number of blocks, each block setting a key of the evm store
+func newStateNode(t *testing.T, blocks int64) *node {
+	n := &node{multi: rootmulti.NewStore(dbm.NewMemDB()), headers: make(map[int64]types.Header)}
+	var evmKey storetypes.StoreKey
+	for _, name := range []string{"acc", evmtypes.StoreKey, "staking"} {
+		key := storetypes.NewKVStoreKey(name)
+		if name == evmtypes.StoreKey {
+			evmKey = key
+		}
+		n.multi.MountStoreWithDB(key, storetypes.StoreTypeIAVL, nil)
+	}
+	require.NoError(t, n.multi.LoadLatestVersion())
+
+	n.headers[1] = types.Header{Height: 1}
+	for height := int64(1); height <= blocks; height++ {
+		n.multi.GetCommitKVStore(evmKey).Set([]byte{0x01}, []byte(fmt.Sprintf("block %d", height)))
+		id, _, _ := n.multi.Commit(&iavl.TreeDelta{}, nil)
+		n.headers[height+1] = types.Header{Height: height + 1, AppHash: id.Hash}
+	}
+	delete(n.headers, blocks+1)
+	return n
+}
+
+func (n *node) GetUnconfirmedTxByHash(hash [sha256.Size]byte) (types.Tx, error) {
+	if n.mempoolErr != nil {
+		return nil, n.mempoolErr
+	}
+	tx, ok := n.pending[hash]
+	if !ok {
+		return nil, mempool.ErrNoSuchTx
+	}
+	return tx, nil
+}
+
+func newTestAPI(client rpcclient.Client) *PublicExchainAPI {
+	return &PublicExchainAPI{
+		clientCtx: clientcontext.CLIContext{Client: client, Codec: newTestCodec()},
+		logger:    log.NewNopLogger(),
+	}
+}
+
+func TestGetPendingReceiptNotPending(t *testing.T) {
+	api := newTestAPI(&node{})
+	receipt, err := api.GetPendingReceipt(common.HexToHash("0x1"))
+	require.NoError(t, err)
+	require.Nil(t, receipt)
+}
+
+func TestGetPendingReceiptMempoolError(t *testing.T) {
+	// only a tx missing from the mempool means it isn't pending, the other failures are reported
+	mempoolErr := errors.New("mempool unavailable")
+	api := newTestAPI(&node{mempoolErr: mempoolErr})
+	receipt, err := api.GetPendingReceipt(common.HexToHash("0x1"))
+	require.Equal(t, mempoolErr, err)
+	require.Nil(t, receipt)
+}
+
+func TestGetPendingReceiptInvalidTx(t *testing.T) {
+	hash := common.HexToHash("0x1")
+	api := newTestAPI(&node{pending: map[[sha256.Size]byte]types.Tx{hash: types.Tx("not an evm tx")}})
+	receipt, err := api.GetPendingReceipt(hash)
+	require.Error(t, err)
+	require.Nil(t, receipt)
+}
+
+func TestEstimateGasBatch(t *testing.T) {
+	n := &node{cdc: newTestCodec()}
+	api := newTestAPI(n)
+	to := common.HexToAddress("0x1")
+	args := []rpctypes.CallArgs{
+		{To: &to},
+		{To: &to, Data: &revertingCall},
+		{To: &to, Data: &hexutil.Bytes{1, 2, 3}},
+	}
+
+	// a failing call doesn't fail the batch
+	estimates, err := api.EstimateGasBatch(args, nil)
+	require.NoError(t, err)
+	require.Len(t, estimates, 3)
+	require.Empty(t, estimates[0].Error)
+	require.Contains(t, estimates[1].Error, "execution reverted")
+	require.Zero(t, estimates[1].Gas)
+	require.Empty(t, estimates[2].Error)
+	require.Equal(t, hexutil.Uint64(rpctypes.EstimatedGas(21300)), estimates[2].Gas)
+	// all the calls are pinned to the latest height
+	require.Equal(t, []int64{10, 10, 10}, n.simulatedHeights)
+
+	// the estimations are the ones of eth_estimateGas
+	viper.Set(watcher.FlagFastQueryLru, 100)
+	clientCtx := api.clientCtx.WithChainID("exchain-65")
+	clientCtx.Keybase = keys.NewInMemory()
+	ethAPI := eth.NewAPI(clientCtx, log.NewNopLogger(), nil, nil)
+	for i, arg := range args {
+		gas, err := ethAPI.EstimateGas(arg)
+		if estimates[i].Error != "" {
+			require.Error(t, err)
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, gas, estimates[i].Gas, i)
+	}
+
+	_, err = api.EstimateGasBatch(make([]rpctypes.CallArgs, maxEstimateGasBatch+1), nil)
+	require.Error(t, err)
+}
+
+func TestGetStateRoot(t *testing.T) {
+	n := newStateNode(t, 3)
+	api := newTestAPI(n)
+
+	for _, height := range []int64{1, 2} {
+		root, err := api.GetStateRoot(rpctypes.BlockNumber(height))
+		require.NoError(t, err)
+		require.Equal(t, hexutil.Uint64(height), root.BlockNumber)
+		require.Equal(t, hexutil.Uint64(height+1), root.AnchorBlockNumber)
+		require.Equal(t, hexutil.Bytes(n.headers[height+1].AppHash), root.StateRoot)
+
+		// each store root is proven in the state root
+		require.Len(t, root.Stores, 3)
+		require.Equal(t, "acc", root.Stores[0].Name)
+		require.Equal(t, evmtypes.StoreKey, root.Stores[1].Name)
+		for _, store := range root.Stores {
+			require.Equal(t, []byte(store.Name), []byte(store.Proof.Exist.Key))
+			require.Equal(t, root.StateRoot, hexutil.Bytes(store.Proof.Exist.Calculate()))
+			hash := sha256.Sum256(store.Root)
+			require.Equal(t, hash[:], []byte(store.Proof.Exist.Value))
+		}
+	}
+
+	// the evm root changes with each block
+	root1, err := api.GetStateRoot(1)
+	require.NoError(t, err)
+	root2, err := api.GetStateRoot(2)
+	require.NoError(t, err)
+	require.NotEqual(t, root1.Stores[1].Root, root2.Stores[1].Root)
+	require.Equal(t, root1.Stores[0].Root, root2.Stores[0].Root)
+
+	// the state of the last block isn't committed yet
+	_, err = api.GetStateRoot(3)
+	require.Error(t, err)
+
+	// the proofs must match the app hash of the anchor block
+	anchor := n.headers[2]
+	anchor.AppHash = bytes.Repeat([]byte{1}, sha256.Size)
+	n.headers[2] = anchor
+	_, err = api.GetStateRoot(1)
+	require.Error(t, err)
+}
+
+func TestGetStoreProof(t *testing.T) {
+	n := newStateNode(t, 3)
+	api := newTestAPI(n)
+
+	for _, height := range []int64{1, 2} {
+		proof, err := api.GetStoreProof(evmtypes.StoreKey, hexutil.Bytes{0x01}, rpctypes.BlockNumber(height))
+		require.NoError(t, err)
+		// the value is the one of the requested block, not of the latest one
+		require.Equal(t, hexutil.Bytes(fmt.Sprintf("block %d", height)), proof.Value)
+		require.Equal(t, hexutil.Bytes(n.headers[height+1].AppHash), proof.StateRoot)
+		require.Len(t, proof.Proofs, 2)
+		require.Equal(t, []byte(proof.StateRoot), proof.Proofs[1].Exist.Calculate())
+	}
+
+	_, err := api.GetStoreProof(evmtypes.StoreKey, nil, 1)
+	require.Error(t, err)
+}
+
+// chainStatusNode is a node of the given blocks, the txs of a block are evm txs at the given gas prices
+type chainStatusNode struct {
+	rpcclient.Client
+	cdc       *codec.Codec
+	blockTime time.Duration
+	gasPrices [][]int64
+}
+
+func (n *chainStatusNode) Status() (*ctypes.ResultStatus, error) {
+	latest := int64(len(n.gasPrices))
+	return &ctypes.ResultStatus{SyncInfo: ctypes.SyncInfo{
+		LatestBlockHeight: latest,
+		LatestBlockTime:   n.blockHeader(latest).Time,
+		CatchingUp:        true,
+	}}, nil
+}
+
+func (n *chainStatusNode) NumUnconfirmedTxs() (*ctypes.ResultUnconfirmedTxs, error) {
+	return &ctypes.ResultUnconfirmedTxs{Total: 7}, nil
+}
+
+func (n *chainStatusNode) NetInfo() (*ctypes.ResultNetInfo, error) {
+	return &ctypes.ResultNetInfo{NPeers: 3}, nil
+}
+
+func (n *chainStatusNode) blockHeader(height int64) types.Header {
+	return types.Header{Height: height, Time: time.Unix(1600000000, 0).Add(time.Duration(height) * n.blockTime)}
+}
+
+// BlockchainInfo returns the metas of the blocks from the latest one, like tendermint
+func (n *chainStatusNode) BlockchainInfo(minHeight, maxHeight int64) (*ctypes.ResultBlockchainInfo, error) {
+	res := &ctypes.ResultBlockchainInfo{LastHeight: int64(len(n.gasPrices))}
+	for height := maxHeight; height >= minHeight; height-- {
+		res.BlockMetas = append(res.BlockMetas, &types.BlockMeta{
+			Header: n.blockHeader(height),
+			NumTxs: len(n.gasPrices[height-1]),
+		})
+	}
+	return res, nil
+}
+
+func (n *chainStatusNode) Block(height *int64) (*ctypes.ResultBlock, error) {
+	block := &types.Block{Header: n.blockHeader(*height)}
+	for i, price := range n.gasPrices[*height-1] {
+		msg := evmtypes.NewMsgEthereumTx(uint64(i), nil, big.NewInt(0), 21000, big.NewInt(price), nil)
+		block.Txs = append(block.Txs, n.cdc.MustMarshalBinaryLengthPrefixed(msg))
+	}
+	// the txs which aren't evm txs are ignored
+	block.Txs = append(block.Txs, types.Tx("not an evm tx"))
+	return &ctypes.ResultBlock{Block: block}, nil
+}
+
+func TestGetChainStatus(t *testing.T) {
+	n := &chainStatusNode{cdc: newTestCodec(), blockTime: 3 * time.Second}
+	// the blocks before the latest chainStatusBlocks are ignored
+	n.gasPrices = make([][]int64, chainStatusBlocks+1)
+	n.gasPrices[0] = []int64{1000}
+	n.gasPrices[1] = []int64{7, 2, 9}
+	n.gasPrices[chainStatusBlocks-1] = []int64{10, 1, 4, 3}
+	n.gasPrices[chainStatusBlocks] = []int64{6, 8, 5}
+	api := newTestAPI(n)
+	api.SetNamespaces([]string{"eth", "exchain"})
+
+	status, err := api.GetChainStatus()
+	require.NoError(t, err)
+	require.Equal(t, hexutil.Uint64(chainStatusBlocks+1), status.LatestBlockNumber)
+	require.Equal(t, hexutil.Uint64(n.blockHeader(chainStatusBlocks+1).Time.Unix()), status.LatestBlockTime)
+	require.Equal(t, hexutil.Uint64(3000), status.AverageBlockTime)
+	require.True(t, status.CatchingUp)
+	require.Equal(t, hexutil.Uint64(7), status.PendingTxs)
+	require.Equal(t, hexutil.Uint64(3), status.PeerCount)
+	require.Nil(t, status.WatcherLag)
+	require.Nil(t, status.BloomLag)
+	require.Equal(t, []string{"eth", "exchain"}, status.Namespaces)
+
+	// the prices from 1 to 10
+	require.Equal(t, int64(1), status.GasPrices.P10.ToInt().Int64())
+	require.Equal(t, int64(5), status.GasPrices.P50.ToInt().Int64())
+	require.Equal(t, int64(9), status.GasPrices.P90.ToInt().Int64())
+}
+
+func TestGetChainStatusNoTxs(t *testing.T) {
+	// the gas prices are unknown without txs, and the block time without a previous block
+	api := newTestAPI(&chainStatusNode{cdc: newTestCodec(), gasPrices: [][]int64{nil}})
+	status, err := api.GetChainStatus()
+	require.NoError(t, err)
+	require.Equal(t, hexutil.Uint64(1), status.LatestBlockNumber)
+	require.Zero(t, status.AverageBlockTime)
+	require.Equal(t, GasPricePercentiles{}, status.GasPrices)
+}
+
+func TestBlockLag(t *testing.T) {
+	require.Equal(t, hexutil.Uint64(5), *blockLag(15, 10))
+	require.Equal(t, hexutil.Uint64(0), *blockLag(10, 10))
+	// an index ahead of the status read first isn't behind
+	require.Equal(t, hexutil.Uint64(0), *blockLag(10, 12))
+}
+
+// committedTxsNode is a node of the committed txs
+type committedTxsNode struct {
+	rpcclient.Client
+	txs map[common.Hash]types.Tx
+}
+
+func (n *committedTxsNode) Tx(hash []byte, prove bool) (*ctypes.ResultTx, error) {
+	tx, ok := n.txs[common.BytesToHash(hash)]
+	if !ok {
+		return nil, fmt.Errorf("tx %X not found", hash)
+	}
+	return &ctypes.ResultTx{Hash: hash, Height: 5, Tx: tx}, nil
+}
+
+// signedEthTx returns an evm tx signed by a new key, along with its sender
+func signedEthTx(t *testing.T, nonce uint64) (types.Tx, common.Address) {
+	priv, err := ethsecp256k1.GenerateKey()
+	require.NoError(t, err)
+	msg := evmtypes.NewMsgEthereumTx(nonce, nil, big.NewInt(0), 21000, big.NewInt(1), nil)
+	require.NoError(t, msg.Sign(big.NewInt(65), priv.ToECDSA()))
+	return newTestCodec().MustMarshalBinaryLengthPrefixed(msg), ethcrypto.PubkeyToAddress(priv.ToECDSA().PublicKey)
+}
+
+func TestRecoverSenders(t *testing.T) {
+	raw, rawSender := signedEthTx(t, 0)
+	committed, committedSender := signedEthTx(t, 1)
+	committedHash := common.BytesToHash(committed.Hash())
+
+	n := &committedTxsNode{txs: map[common.Hash]types.Tx{committedHash: committed}}
+	api := newTestAPI(n)
+	api.wrappedBackend = &watcher.Querier{}
+
+	// the txs are given as raw txs or as hashes of committed txs, a failing tx doesn't fail the batch
+	missingHash := common.HexToHash("0x1")
+	senders, err := api.RecoverSenders([]hexutil.Bytes{hexutil.Bytes(raw), committedHash.Bytes(), []byte("not an evm tx"), missingHash.Bytes()})
+	require.NoError(t, err)
+	require.Len(t, senders, 4)
+	require.Equal(t, RecoveredSender{Hash: common.BytesToHash(raw.Hash()), From: &rawSender}, senders[0])
+	require.Equal(t, RecoveredSender{Hash: committedHash, From: &committedSender}, senders[1])
+	require.Nil(t, senders[2].From)
+	require.NotEmpty(t, senders[2].Error)
+	require.Equal(t, missingHash, senders[3].Hash)
+	require.Nil(t, senders[3].From)
+	require.Contains(t, senders[3].Error, "tx not found")
+
+	_, err = api.RecoverSenders(make([]hexutil.Bytes, maxRecoverSenders+1))
+	require.Error(t, err)
+}
+
+func TestRecoverSendersRateLimit(t *testing.T) {
+	tx, _ := signedEthTx(t, 0)
+	raw := hexutil.Bytes(tx)
+	api := newTestAPI(&committedTxsNode{})
+	api.wrappedBackend = &watcher.Querier{}
+	api.rateLimiters = map[string]*rate.Limiter{"exchain_recoverSenders": rate.NewLimiter(rate.Every(time.Hour), 2)}
+
+	// each tx of the batch takes a token
+	_, err := api.RecoverSenders([]hexutil.Bytes{raw, raw, raw})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "split it into batches of at most 2 txs")
+
+	senders, err := api.RecoverSenders([]hexutil.Bytes{raw, raw})
+	require.NoError(t, err)
+	require.Len(t, senders, 2)
+
+	_, err = api.RecoverSenders([]hexutil.Bytes{raw})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "too busy")
+}
@@ -11,6 +11,7 @@ import (
 
 // PublicNetAPI is the eth_ prefixed set of APIs in the Web3 JSON-RPC spec.
 type PublicNetAPI struct {
+	clientCtx      context.CLIContext
 	networkVersion uint64
 	logger         log.Logger
 	Metrics        map[string]*monitor.RpcMetrics
@@ -25,6 +26,7 @@ func NewAPI(clientCtx context.CLIContext, log log.Logger) *PublicNetAPI {
 	}
 
 	return &PublicNetAPI{
+		clientCtx:      clientCtx,
 		networkVersion: chainIDEpoch.Uint64(),
 		logger:         log.With("module", "json-rpc", "namespace", "net"),
 	}
@@ -0,0 +1,123 @@
+package net
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/okex/exchain/app/rpc/monitor"
+	ctypes "github.com/okex/exchain/libs/tendermint/rpc/core/types"
+)
+
+// PeerInfo is a tendermint peer of the node.
+type PeerInfo struct {
+	ID           string `json:"id"`
+	Moniker      string `json:"moniker"`
+	Version      string `json:"version"`
+	ListenAddr   string `json:"listenAddr"`
+	RemoteIP     string `json:"remoteIP"`
+	Direction    string `json:"direction"`
+	ConnectedFor string `json:"connectedFor"`
+	// SendRate and RecvRate are the current transfer rates in bytes per second
+	SendRate  hexutil.Uint64 `json:"sendRate"`
+	RecvRate  hexutil.Uint64 `json:"recvRate"`
+	BytesSent hexutil.Uint64 `json:"bytesSent"`
+	BytesRecv hexutil.Uint64 `json:"bytesRecv"`
+	// Height is the consensus height of the peer, 0 until the peer reports it
+	Height hexutil.Uint64 `json:"height"`
+}
+
+// PeersInfo is the sync status of the node and its tendermint peers.
+type PeersInfo struct {
+	Listening  bool           `json:"listening"`
+	Height     hexutil.Uint64 `json:"height"`
+	CatchingUp bool           `json:"catchingUp"`
+	Peers      []PeerInfo     `json:"peers"`
+}
+
+// PeerCount returns the number of tendermint peers of the node.
+func (api *PublicNetAPI) PeerCount() (hexutil.Uint, error) {
+	monitor := monitor.GetMonitor("net_peerCount", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd()
+	netInfo, err := api.clientCtx.Client.NetInfo()
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint(netInfo.NPeers), nil
+}
+
+// Peers returns the tendermint peers of the node with their addresses, the direction of the connections, the
+// transfer rates and the consensus heights, to debug the connectivity of the node.
+func (api *PublicNetAPI) Peers() (*PeersInfo, error) {
+	monitor := monitor.GetMonitor("net_peers", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd()
+
+	status, err := api.clientCtx.Client.Status()
+	if err != nil {
+		return nil, err
+	}
+	netInfo, err := api.clientCtx.Client.NetInfo()
+	if err != nil {
+		return nil, err
+	}
+	heights := make(map[string]int64)
+	if consensusState, err := api.clientCtx.Client.DumpConsensusState(); err == nil {
+		heights = peerHeights(consensusState)
+	} else {
+		api.logger.Debug("failed to get the consensus state of the peers", "err", err)
+	}
+
+	info := &PeersInfo{
+		Listening:  netInfo.Listening,
+		Height:     hexutil.Uint64(status.SyncInfo.LatestBlockHeight),
+		CatchingUp: status.SyncInfo.CatchingUp,
+		Peers:      make([]PeerInfo, 0, len(netInfo.Peers)),
+	}
+	for _, peer := range netInfo.Peers {
+		direction := "inbound"
+		if peer.IsOutbound {
+			direction = "outbound"
+		}
+		conn := peer.ConnectionStatus
+		id := string(peer.NodeInfo.ID())
+		info.Peers = append(info.Peers, PeerInfo{
+			ID:           id,
+			Moniker:      peer.NodeInfo.Moniker,
+			Version:      peer.NodeInfo.Version,
+			ListenAddr:   peer.NodeInfo.ListenAddr,
+			RemoteIP:     peer.RemoteIP,
+			Direction:    direction,
+			ConnectedFor: conn.Duration.String(),
+			SendRate:     hexutil.Uint64(conn.SendMonitor.CurRate),
+			RecvRate:     hexutil.Uint64(conn.RecvMonitor.CurRate),
+			BytesSent:    hexutil.Uint64(conn.SendMonitor.Bytes),
+			BytesRecv:    hexutil.Uint64(conn.RecvMonitor.Bytes),
+			Height:       hexutil.Uint64(heights[id]),
+		})
+	}
+	return info, nil
+}
+
+// peerHeights returns the consensus heights of the peers by id
+func peerHeights(consensusState *ctypes.ResultDumpConsensusState) map[string]int64 {
+	heights := make(map[string]int64, len(consensusState.Peers))
+	for _, peer := range consensusState.Peers {
+		// the node address is id@host:port
+		id := strings.SplitN(peer.NodeAddress, "@", 2)[0]
+		var peerState struct {
+			RoundState struct {
+				// int64 is encoded as a string by amino
+				Height json.RawMessage `json:"height"`
+			} `json:"round_state"`
+		}
+		if id == "" || json.Unmarshal(peer.PeerState, &peerState) != nil {
+			continue
+		}
+		height, err := strconv.ParseInt(strings.Trim(string(peerState.RoundState.Height), `"`), 10, 64)
+		if err == nil {
+			heights[id] = height
+		}
+	}
+	return heights
+}
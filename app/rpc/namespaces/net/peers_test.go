@@ -0,0 +1,21 @@
+package net
+
+import (
+	"encoding/json"
+	"testing"
+
+	ctypes "github.com/okex/exchain/libs/tendermint/rpc/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerHeights(t *testing.T) {
+	consensusState := &ctypes.ResultDumpConsensusState{
+		Peers: []ctypes.PeerStateInfo{
+			{NodeAddress: "a1b2@127.0.0.1:26656", PeerState: json.RawMessage(`{"round_state":{"height":"12","round":"0"}}`)},
+			// the peers without a consensus state yet
+			{},
+			{NodeAddress: "c3d4@127.0.0.1:26657", PeerState: json.RawMessage(`{"round_state":{}}`)},
+		},
+	}
+	require.Equal(t, map[string]int64{"a1b2": 12}, peerHeights(consensusState))
+}
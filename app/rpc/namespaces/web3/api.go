@@ -2,13 +2,20 @@ package web3
 
 import (
 	"fmt"
-	"github.com/okex/exchain/libs/cosmos-sdk/version"
+	"runtime"
+	"strings"
+
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/okex/exchain/app/rpc/monitor"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	"github.com/okex/exchain/libs/cosmos-sdk/version"
 	"github.com/okex/exchain/libs/tendermint/libs/log"
 )
 
+// maxSha3Batch is the max number of inputs of web3_sha3Batch
+const maxSha3Batch = 1000
+
 // PublicWeb3API is the web3_ prefixed set of APIs in the Web3 JSON-RPC spec.
 type PublicWeb3API struct {
 	logger  log.Logger
@@ -22,12 +29,44 @@ func NewAPI(log log.Logger) *PublicWeb3API {
 	}
 }
 
-// ClientVersion returns the client version in the Web3 user agent format.
+// ClientVersion returns the client version in the Web3 user agent format, e.g.
+// exchain/v1.1.0-5a3f1c2e/linux-amd64/go1.17/mercury@5150000, with the enabled milestones of the chain last.
 func (api *PublicWeb3API) ClientVersion() string {
 	monitor := monitor.GetMonitor("web3_clientVersion", api.logger, api.Metrics).OnBegin()
 	defer monitor.OnEnd()
-	info := version.NewInfo()
-	return fmt.Sprintf("%s-%s", info.Name, info.Version)
+	return clientVersion(version.NewInfo())
+}
+
+func clientVersion(info version.Info) string {
+	name := info.Name
+	if name == "" {
+		name = "exchain"
+	}
+	ver := strings.TrimPrefix(info.Version, "v")
+	if ver == "" {
+		ver = "unknown"
+	}
+	ver = "v" + ver
+	if commit := info.GitCommit; commit != "" {
+		if len(commit) > 8 {
+			commit = commit[:8]
+		}
+		ver += "-" + commit
+	}
+	parts := []string{name, ver, runtime.GOOS + "-" + runtime.GOARCH, runtime.Version()}
+	if forks := enabledForks(); len(forks) > 0 {
+		parts = append(parts, strings.Join(forks, ","))
+	}
+	return strings.Join(parts, "/")
+}
+
+// enabledForks returns the milestones enabled on the chain with their heights
+func enabledForks() []string {
+	var forks []string
+	if height := sdk.GetMilestoneMercuryHeight(); height > 0 {
+		forks = append(forks, fmt.Sprintf("mercury@%d", height))
+	}
+	return forks
 }
 
 // Sha3 returns the keccak-256 hash of the passed-in input.
@@ -36,3 +75,17 @@ func (api *PublicWeb3API) Sha3(input hexutil.Bytes) hexutil.Bytes {
 	defer monitor.OnEnd()
 	return crypto.Keccak256(input)
 }
+
+// Sha3Batch returns the keccak-256 hashes of the inputs, in the same order.
+func (api *PublicWeb3API) Sha3Batch(inputs []hexutil.Bytes) ([]hexutil.Bytes, error) {
+	monitor := monitor.GetMonitor("web3_sha3Batch", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("inputs", len(inputs))
+	if len(inputs) > maxSha3Batch {
+		return nil, fmt.Errorf("batch of %d inputs exceeds the max of %d", len(inputs), maxSha3Batch)
+	}
+	hashes := make([]hexutil.Bytes, len(inputs))
+	for i, input := range inputs {
+		hashes[i] = crypto.Keccak256(input)
+	}
+	return hashes, nil
+}
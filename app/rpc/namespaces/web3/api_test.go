@@ -0,0 +1,35 @@
+package web3
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/okex/exchain/libs/cosmos-sdk/version"
+	"github.com/okex/exchain/libs/tendermint/libs/log"
+)
+
+func TestClientVersion(t *testing.T) {
+	platform := "/" + runtime.GOOS + "-" + runtime.GOARCH + "/" + runtime.Version()
+	require.Equal(t, "exchain/v1.1.0-5a3f1c2e"+platform,
+		clientVersion(version.Info{Name: "exchain", Version: "v1.1.0", GitCommit: "5a3f1c2e9b7d"}))
+	require.Equal(t, "exchain/vunknown"+platform, clientVersion(version.Info{}))
+}
+
+func TestSha3Batch(t *testing.T) {
+	api := NewAPI(log.NewNopLogger())
+	inputs := []hexutil.Bytes{{}, {0x01}, []byte("exchain")}
+	hashes, err := api.Sha3Batch(inputs)
+	require.NoError(t, err)
+	require.Len(t, hashes, len(inputs))
+	for i, input := range inputs {
+		require.Equal(t, hexutil.Bytes(crypto.Keccak256(input)), hashes[i])
+		require.Equal(t, api.Sha3(input), hashes[i])
+	}
+
+	_, err = api.Sha3Batch(make([]hexutil.Bytes, maxSha3Batch+1))
+	require.Error(t, err)
+}
@@ -0,0 +1,61 @@
+package webhook
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/okex/exchain/app/rpc/monitor"
+	"github.com/okex/exchain/app/rpc/webhook"
+	"github.com/okex/exchain/libs/tendermint/libs/log"
+)
+
+// SubscribeArgs are the arguments of webhook_subscribe
+type SubscribeArgs struct {
+	URL       string           `json:"url"`
+	Addresses []common.Address `json:"addresses"`
+	Topics    []common.Hash    `json:"topics"`
+	// Secret is generated if empty, and returned with the subscription
+	Secret string `json:"secret"`
+}
+
+// PrivateWebhookAPI is the webhook_ prefixed set of APIs, registering the http callbacks notified of the activity of
+// addresses or log topics.
+type PrivateWebhookAPI struct {
+	registry *webhook.Registry
+	logger   log.Logger
+	Metrics  map[string]*monitor.RpcMetrics
+}
+
+// NewAPI creates an instance of the Webhook API.
+func NewAPI(registry *webhook.Registry, log log.Logger) *PrivateWebhookAPI {
+	return &PrivateWebhookAPI{
+		registry: registry,
+		logger:   log.With("module", "json-rpc", "namespace", "webhook"),
+	}
+}
+
+// Subscribe registers a webhook and returns it with its id and secret.
+func (api *PrivateWebhookAPI) Subscribe(args SubscribeArgs) (*webhook.Subscription, error) {
+	monitor := monitor.GetMonitor("webhook_subscribe", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("url", args.URL)
+	return api.registry.Add(args.URL, args.Addresses, args.Topics, args.Secret)
+}
+
+// Unsubscribe removes the webhook of the id, it returns false if it isn't found.
+func (api *PrivateWebhookAPI) Unsubscribe(id string) (bool, error) {
+	monitor := monitor.GetMonitor("webhook_unsubscribe", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd("id", id)
+	return api.registry.Remove(id)
+}
+
+// Subscriptions returns the registered webhooks, without their secrets.
+func (api *PrivateWebhookAPI) Subscriptions() []webhook.Subscription {
+	monitor := monitor.GetMonitor("webhook_subscriptions", api.logger, api.Metrics).OnBegin()
+	defer monitor.OnEnd()
+	subs := api.registry.List()
+	list := make([]webhook.Subscription, len(subs))
+	for i, sub := range subs {
+		list[i] = *sub
+		list[i].Secret = ""
+	}
+	return list
+}
@@ -0,0 +1,137 @@
+package readonly
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+const (
+	// FlagReadOnly disables the signing and the broadcast methods of the rpc server
+	FlagReadOnly = "rpc.read-only"
+
+	// ErrCodeReadOnly is the json-rpc error code of the methods disabled by the read-only mode
+	ErrCodeReadOnly = -32006
+)
+
+var (
+	// disabledMethods sign with the keys of the node or broadcast txs
+	disabledMethods = map[string]bool{
+		"eth_sendTransaction":    true,
+		"eth_sendRawTransaction": true,
+		"eth_sign":               true,
+		"eth_signTransaction":    true,
+		"eth_signTypedData":      true,
+	}
+	disabledPrefixes = []string{"personal_"}
+)
+
+// Disabled tells whether the method is disabled by the read-only mode
+func Disabled(method string) bool {
+	if disabledMethods[method] {
+		return true
+	}
+	for _, prefix := range disabledPrefixes {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handler wraps the json-rpc handler, rejecting the requests of the signing and the broadcast methods before
+// they are routed to their api, whatever the keys of the node. A batch is rejected as a whole if it has a
+// disabled method.
+func Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		reqs, batch := parse(body)
+		if reqs == nil {
+			// the server answers the malformed requests
+			next.ServeHTTP(w, r)
+			return
+		}
+		var disabled bool
+		for _, req := range reqs {
+			disabled = disabled || Disabled(req.Method)
+		}
+		if !disabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		resps := make([]response, len(reqs))
+		for i, req := range reqs {
+			id := req.ID
+			if len(id) == 0 {
+				id = json.RawMessage("null")
+			}
+			message := "the batch has a method disabled by the read-only mode"
+			if Disabled(req.Method) {
+				message = "method " + req.Method + " is disabled, the node is read-only"
+			}
+			resps[i] = response{
+				Version: "2.0",
+				ID:      id,
+				Error:   jsonError{Code: ErrCodeReadOnly, Message: message, Data: errorData{Method: req.Method, Reason: "read-only"}},
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if batch {
+			_ = json.NewEncoder(w).Encode(resps)
+		} else {
+			_ = json.NewEncoder(w).Encode(resps[0])
+		}
+	})
+}
+
+// parse returns the requests of the body and whether it is a batch, nil if it isn't valid json-rpc
+func parse(body []byte) ([]request, bool) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var reqs []request
+		if err := json.Unmarshal(trimmed, &reqs); err != nil || len(reqs) == 0 {
+			return nil, true
+		}
+		return reqs, true
+	}
+	var req request
+	if err := json.Unmarshal(trimmed, &req); err != nil {
+		return nil, false
+	}
+	return []request{req}, false
+}
+
+type request struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+}
+
+type response struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Error   jsonError       `json:"error"`
+}
+
+type jsonError struct {
+	Code    int       `json:"code"`
+	Message string    `json:"message"`
+	Data    errorData `json:"data"`
+}
+
+type errorData struct {
+	Method string `json:"method"`
+	Reason string `json:"reason"`
+}
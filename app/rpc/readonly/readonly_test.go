@@ -0,0 +1,50 @@
+package readonly
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	var calls int
+	handler := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+	}))
+	serve := func(body string) []byte {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		require.Equal(t, http.StatusOK, rec.Code)
+		return rec.Body.Bytes()
+	}
+
+	// the read methods are served
+	require.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":"0x1"}`, string(serve(`{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber"}`)))
+	require.Equal(t, 1, calls)
+
+	// the signing and the broadcast methods are rejected
+	for _, method := range []string{"eth_sendRawTransaction", "eth_sendTransaction", "eth_sign", "personal_unlockAccount"} {
+		var resp response
+		require.NoError(t, json.Unmarshal(serve(`{"jsonrpc":"2.0","id":7,"method":"`+method+`"}`), &resp))
+		require.Equal(t, "7", string(resp.ID))
+		require.Equal(t, ErrCodeReadOnly, resp.Error.Code)
+		require.Equal(t, errorData{Method: method, Reason: "read-only"}, resp.Error.Data)
+	}
+
+	// a batch with a disabled method is rejected as a whole
+	var resps []response
+	require.NoError(t, json.Unmarshal(serve(`[{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber"},{"jsonrpc":"2.0","id":2,"method":"eth_sign"}]`), &resps))
+	require.Len(t, resps, 2)
+	require.Equal(t, "eth_blockNumber", resps[0].Error.Data.Method)
+	require.Equal(t, "eth_sign", resps[1].Error.Data.Method)
+	require.Equal(t, 1, calls)
+
+	// the malformed requests are answered by the server
+	serve(`{"jsonrpc"`)
+	require.Equal(t, 2, calls)
+}
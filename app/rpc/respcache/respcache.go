@@ -0,0 +1,271 @@
+package respcache
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// HeaderCache is set to "hit" on the responses served from the cache
+const HeaderCache = "X-Rpc-Cache"
+
+// blockParamIndex is the index of the block number param of the cacheable methods, -1 for the methods
+// which don't depend on the state
+var blockParamIndex = map[string]int{
+	"eth_chainId":          -1,
+	"eth_getBlockByNumber": 0,
+	"eth_getCode":          1,
+}
+
+// ParseTTLs parses the cached methods and their max age, such as "eth_chainId=0,eth_getCode=1m",
+// 0 never expires the responses
+func ParseTTLs(s string) (map[string]time.Duration, error) {
+	ttls := make(map[string]time.Duration)
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid cached method %s, use method=max-age", item)
+		}
+		method := strings.TrimSpace(parts[0])
+		if _, ok := blockParamIndex[method]; !ok {
+			return nil, fmt.Errorf("method %s can't be cached, cacheable methods: %s", method, cacheableMethods())
+		}
+		ttl, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil || ttl < 0 {
+			return nil, fmt.Errorf("invalid max age of method %s: %s", method, parts[1])
+		}
+		ttls[method] = ttl
+	}
+	return ttls, nil
+}
+
+func cacheableMethods() string {
+	methods := make([]string, 0, len(blockParamIndex))
+	for method := range blockParamIndex {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return strings.Join(methods, ",")
+}
+
+// Cache serves the responses of the idempotent methods from memory. The responses at a block number
+// never change once the block is committed and are cached until their max age, while the responses
+// at the latest block are keyed to its height and dropped as soon as a new block is committed.
+type Cache struct {
+	ttls         map[string]time.Duration
+	latestHeight func() (int64, error)
+	entries      *lru.Cache
+
+	mtx        sync.Mutex
+	height     int64    // the height the latest entries are keyed to
+	latestKeys []string // the keys of the latest entries
+}
+
+type entry struct {
+	result  json.RawMessage
+	expires time.Time // zero never expires
+}
+
+// New creates a response cache of the methods with their max age, holding up to size responses.
+func New(ttls map[string]time.Duration, size int, latestHeight func() (int64, error)) (*Cache, error) {
+	entries, err := lru.New(size)
+	if err != nil {
+		return nil, err
+	}
+	return &Cache{
+		ttls:         ttls,
+		latestHeight: latestHeight,
+		entries:      entries,
+	}, nil
+}
+
+// Handler wraps the json-rpc handler, serving the single requests of the cached methods from the cache.
+func (c *Cache) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			// batches aren't cached
+			next.ServeHTTP(w, r)
+			return
+		}
+		key, height, ok := c.key(req)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if result, ok := c.get(key); ok {
+			writeResult(w, req.ID, result)
+			return
+		}
+
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		if rec.status != http.StatusOK {
+			return
+		}
+		var resp response
+		if err := json.Unmarshal(rec.body.Bytes(), &resp); err != nil || resp.Error != nil {
+			return
+		}
+		// the blocks which aren't committed yet are null
+		if len(resp.Result) == 0 || string(resp.Result) == "null" {
+			return
+		}
+		c.add(key, height, resp.Result, c.ttls[req.Method])
+	})
+}
+
+// key returns the cache key of the request, the requests at the latest block are keyed to its height,
+// which is returned as well, and 0 is returned for the other requests
+func (c *Cache) key(req request) (string, int64, bool) {
+	if _, ok := c.ttls[req.Method]; !ok {
+		return "", 0, false
+	}
+	var params []json.RawMessage
+	var buf bytes.Buffer
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return "", 0, false
+		}
+		if err := json.Compact(&buf, req.Params); err != nil {
+			return "", 0, false
+		}
+	}
+	key := req.Method + buf.String()
+
+	index := blockParamIndex[req.Method]
+	if index < 0 {
+		return key, 0, true
+	}
+	block := "latest"
+	if index < len(params) {
+		var tag string
+		if err := json.Unmarshal(params[index], &tag); err != nil {
+			// a block hash
+			return key, 0, true
+		}
+		block = tag
+	}
+	switch block {
+	case "pending":
+		// the pending state changes between the blocks
+		return "", 0, false
+	case "latest":
+		height, err := c.latestHeight()
+		if err != nil {
+			return "", 0, false
+		}
+		c.setHeight(height)
+		return fmt.Sprintf("%s@%d", key, height), height, true
+	default:
+		return key, 0, true
+	}
+}
+
+// setHeight drops the entries of the previous latest block once a new block is committed
+func (c *Cache) setHeight(height int64) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if height <= c.height {
+		return
+	}
+	for _, key := range c.latestKeys {
+		c.entries.Remove(key)
+	}
+	c.latestKeys = nil
+	c.height = height
+}
+
+func (c *Cache) get(key string) (json.RawMessage, bool) {
+	value, ok := c.entries.Get(key)
+	if !ok {
+		return nil, false
+	}
+	e := value.(entry)
+	if !e.expires.IsZero() && time.Now().After(e.expires) {
+		c.entries.Remove(key)
+		return nil, false
+	}
+	return e.result, true
+}
+
+func (c *Cache) add(key string, height int64, result json.RawMessage, ttl time.Duration) {
+	e := entry{result: result}
+	if ttl > 0 {
+		e.expires = time.Now().Add(ttl)
+	}
+	if height == 0 {
+		c.entries.Add(key, e)
+		return
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	// the response is stale if a new block was committed meanwhile
+	if height != c.height {
+		return
+	}
+	c.latestKeys = append(c.latestKeys, key)
+	c.entries.Add(key, e)
+}
+
+func writeResult(w http.ResponseWriter, id json.RawMessage, result json.RawMessage) {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(HeaderCache, "hit")
+	_ = json.NewEncoder(w).Encode(response{Version: "2.0", ID: id, Result: result})
+}
+
+type request struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+type response struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   json.RawMessage `json:"error,omitempty"`
+}
+
+// recorder copies the response written to the client
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,141 @@
+package respcache
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTTLs(t *testing.T) {
+	ttls, err := ParseTTLs(" eth_chainId=0, eth_getCode=1m,")
+	require.NoError(t, err)
+	require.Equal(t, map[string]time.Duration{"eth_chainId": 0, "eth_getCode": time.Minute}, ttls)
+
+	for _, s := range []string{"eth_chainId", "eth_call=1m", "eth_getCode=forever", "eth_getCode=-1s"} {
+		_, err = ParseTTLs(s)
+		require.Error(t, err, s)
+	}
+}
+
+// countingServer answers the requests with the method, the params and the number of calls
+type countingServer struct {
+	calls  int
+	height int64
+}
+
+func (s *countingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.calls++
+	body, _ := ioutil.ReadAll(r.Body)
+	var req request
+	_ = json.Unmarshal(body, &req)
+	if strings.Contains(string(req.Params), "0xffff") {
+		// a block which isn't committed yet
+		_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":null}`, req.ID)
+		return
+	}
+	if strings.Contains(string(req.Params), "0xbad") {
+		_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":-32000,"message":"bad"}}`, req.ID)
+		return
+	}
+	_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"%s%s-%d"}`, req.ID, req.Method,
+		strings.Replace(string(req.Params), `"`, "", -1), s.calls)
+}
+
+func call(h http.Handler, id int, method, params string) (string, bool) {
+	body := fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"%s","params":%s}`, id, method, params)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	var resp response
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || string(resp.ID) != fmt.Sprint(id) {
+		panic(rec.Body.String())
+	}
+	var result string
+	_ = json.Unmarshal(resp.Result, &result)
+	return result, rec.Header().Get(HeaderCache) == "hit"
+}
+
+func TestCacheHandler(t *testing.T) {
+	next := &countingServer{height: 10}
+	ttls := map[string]time.Duration{"eth_chainId": 0, "eth_getBlockByNumber": 0, "eth_getCode": 50 * time.Millisecond}
+	c, err := New(ttls, 100, func() (int64, error) { return next.height, nil })
+	require.NoError(t, err)
+	h := c.Handler(next)
+
+	// the responses are served from the cache with the id of the request
+	result, hit := call(h, 1, "eth_chainId", `[]`)
+	require.Equal(t, "eth_chainId[]-1", result)
+	require.False(t, hit)
+	result, hit = call(h, 2, "eth_chainId", `[]`)
+	require.Equal(t, "eth_chainId[]-1", result)
+	require.True(t, hit)
+
+	// the blocks at a height are cached, unlike the uncommitted blocks and the errors
+	result, _ = call(h, 1, "eth_getBlockByNumber", `["0x5", false]`)
+	result2, hit := call(h, 1, "eth_getBlockByNumber", `["0x5",false]`)
+	require.True(t, hit)
+	require.Equal(t, result, result2)
+	_, hit = call(h, 1, "eth_getBlockByNumber", `["0x5",true]`)
+	require.False(t, hit)
+	call(h, 1, "eth_getBlockByNumber", `["0xffff",false]`)
+	_, hit = call(h, 1, "eth_getBlockByNumber", `["0xffff",false]`)
+	require.False(t, hit)
+	call(h, 1, "eth_getBlockByNumber", `["0xbad",false]`)
+	_, hit = call(h, 1, "eth_getBlockByNumber", `["0xbad",false]`)
+	require.False(t, hit)
+
+	// the latest block is cached until the next block
+	result, _ = call(h, 1, "eth_getBlockByNumber", `["latest",false]`)
+	result2, hit = call(h, 1, "eth_getBlockByNumber", `["latest",false]`)
+	require.True(t, hit)
+	require.Equal(t, result, result2)
+	next.height++
+	result2, hit = call(h, 1, "eth_getBlockByNumber", `["latest",false]`)
+	require.False(t, hit)
+	require.NotEqual(t, result, result2)
+	require.Len(t, c.latestKeys, 1)
+
+	// the pending state isn't cached
+	call(h, 1, "eth_getBlockByNumber", `["pending",false]`)
+	_, hit = call(h, 1, "eth_getBlockByNumber", `["pending",false]`)
+	require.False(t, hit)
+
+	// the responses expire on their max age
+	call(h, 1, "eth_getCode", `["0x01"]`)
+	_, hit = call(h, 1, "eth_getCode", `["0x01","latest"]`)
+	require.False(t, hit, "the key is the params of the request")
+	_, hit = call(h, 1, "eth_getCode", `["0x01"]`)
+	require.True(t, hit)
+	time.Sleep(60 * time.Millisecond)
+	_, hit = call(h, 1, "eth_getCode", `["0x01"]`)
+	require.False(t, hit)
+
+	// the methods which aren't configured aren't cached
+	calls := next.calls
+	call(h, 1, "eth_blockNumber", `[]`)
+	call(h, 1, "eth_blockNumber", `[]`)
+	require.Equal(t, calls+2, next.calls)
+}
+
+func TestCacheStaleLatest(t *testing.T) {
+	height := int64(10)
+	c, err := New(map[string]time.Duration{"eth_getCode": 0}, 100, func() (int64, error) { return height, nil })
+	require.NoError(t, err)
+
+	key, keyHeight, ok := c.key(request{Method: "eth_getCode", Params: json.RawMessage(`["0x01"]`)})
+	require.True(t, ok)
+	require.Equal(t, int64(10), keyHeight)
+
+	// a new block is committed while the response is computed
+	height = 11
+	_, _, _ = c.key(request{Method: "eth_getCode", Params: json.RawMessage(`["0x02"]`)})
+	c.add(key, keyHeight, json.RawMessage(`"0x"`), 0)
+	_, ok = c.get(key)
+	require.False(t, ok)
+}
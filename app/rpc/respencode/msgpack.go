@@ -0,0 +1,161 @@
+package respencode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// JSONToMsgpack encodes the json document in msgpack. The integers are encoded as integers and the other
+// numbers as float64, the keys of the objects are sorted.
+func JSONToMsgpack(bz []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(bz))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := encodeMsgpack(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeMsgpack(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if v {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case json.Number:
+		return encodeNumber(buf, v)
+	case string:
+		encodeString(buf, v)
+	case []interface{}:
+		encodeLength(buf, len(v), 0x90, 15, 0xdc, 0xdd)
+		for _, item := range v {
+			if err := encodeMsgpack(buf, item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		encodeLength(buf, len(v), 0x80, 15, 0xde, 0xdf)
+		for _, key := range keys {
+			encodeString(buf, key)
+			if err := encodeMsgpack(buf, v[key]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported json value %T", v)
+	}
+	return nil
+}
+
+func encodeNumber(buf *bytes.Buffer, n json.Number) error {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		encodeInt(buf, i)
+		return nil
+	}
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		buf.WriteByte(0xcf)
+		writeUint(buf, u, 8)
+		return nil
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return err
+	}
+	buf.WriteByte(0xcb)
+	writeUint(buf, math.Float64bits(f), 8)
+	return nil
+}
+
+func encodeInt(buf *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0 && i <= math.MaxInt8:
+		// positive fixint
+		buf.WriteByte(byte(i))
+	case i >= -32 && i < 0:
+		// negative fixint
+		buf.WriteByte(byte(int8(i)))
+	case i >= 0 && i <= math.MaxUint8:
+		buf.WriteByte(0xcc)
+		writeUint(buf, uint64(i), 1)
+	case i >= 0 && i <= math.MaxUint16:
+		buf.WriteByte(0xcd)
+		writeUint(buf, uint64(i), 2)
+	case i >= 0 && i <= math.MaxUint32:
+		buf.WriteByte(0xce)
+		writeUint(buf, uint64(i), 4)
+	case i >= 0:
+		buf.WriteByte(0xcf)
+		writeUint(buf, uint64(i), 8)
+	case i >= math.MinInt8:
+		buf.WriteByte(0xd0)
+		writeUint(buf, uint64(i), 1)
+	case i >= math.MinInt16:
+		buf.WriteByte(0xd1)
+		writeUint(buf, uint64(i), 2)
+	case i >= math.MinInt32:
+		buf.WriteByte(0xd2)
+		writeUint(buf, uint64(i), 4)
+	default:
+		buf.WriteByte(0xd3)
+		writeUint(buf, uint64(i), 8)
+	}
+}
+
+func encodeString(buf *bytes.Buffer, s string) {
+	n := len(s)
+	switch {
+	case n <= 31:
+		// fixstr
+		buf.WriteByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		buf.WriteByte(0xd9)
+		writeUint(buf, uint64(n), 1)
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xda)
+		writeUint(buf, uint64(n), 2)
+	default:
+		buf.WriteByte(0xdb)
+		writeUint(buf, uint64(n), 4)
+	}
+	buf.WriteString(s)
+}
+
+// encodeLength writes the header of an array or a map, in its fix format up to fixMax items
+func encodeLength(buf *bytes.Buffer, n int, fix byte, fixMax int, code16, code32 byte) {
+	switch {
+	case n <= fixMax:
+		buf.WriteByte(fix | byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(code16)
+		writeUint(buf, uint64(n), 2)
+	default:
+		buf.WriteByte(code32)
+		writeUint(buf, uint64(n), 4)
+	}
+}
+
+// writeUint writes the size low bytes of u in big endian
+func writeUint(buf *bytes.Buffer, u uint64, size int) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], u)
+	buf.Write(b[8-size:])
+}
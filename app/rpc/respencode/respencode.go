@@ -0,0 +1,170 @@
+package respencode
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ContentTypeMsgpack is the media type of the msgpack encoded responses
+	ContentTypeMsgpack = "application/msgpack"
+	// contentTypeMsgpackAlt is the legacy media type of msgpack, accepted as well
+	contentTypeMsgpackAlt = "application/x-msgpack"
+
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
+)
+
+// Config defines the encodings of the responses negotiated with the clients.
+type Config struct {
+	// Compression enables the gzip and deflate compressions requested by Accept-Encoding
+	Compression bool
+	// MinSize is the size below which the responses aren't compressed, as the compression wouldn't pay off
+	MinSize int
+	// Msgpack enables the msgpack encoding requested by Accept
+	Msgpack bool
+}
+
+// Enabled returns true if any encoding is enabled.
+func (cfg Config) Enabled() bool {
+	return cfg.Compression || cfg.Msgpack
+}
+
+// Handler wraps the json-rpc handler, encoding its responses in msgpack and compressing them as requested by the
+// client. The responses of the clients which request neither are left untouched.
+func Handler(cfg Config, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msgpack := cfg.Msgpack && acceptsMsgpack(r.Header.Get("Accept"))
+		encoding := ""
+		if cfg.Compression {
+			encoding = negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		}
+		if !msgpack && encoding == "" {
+			cfg.setVary(w.Header())
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &recorder{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		for key, values := range rec.header {
+			w.Header()[key] = values
+		}
+		cfg.setVary(w.Header())
+		body := rec.body.Bytes()
+
+		if msgpack && strings.HasPrefix(rec.header.Get("Content-Type"), "application/json") {
+			if encoded, err := JSONToMsgpack(body); err == nil {
+				body = encoded
+				w.Header().Set("Content-Type", ContentTypeMsgpack)
+			}
+		}
+		if encoding != "" && len(body) >= cfg.MinSize {
+			if compressed, err := compress(encoding, body); err == nil {
+				body = compressed
+				w.Header().Set("Content-Encoding", encoding)
+			}
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(rec.status)
+		_, _ = w.Write(body)
+	})
+}
+
+// setVary tells the caches which request headers the response depends on
+func (cfg Config) setVary(header http.Header) {
+	if cfg.Compression {
+		header.Add("Vary", "Accept-Encoding")
+	}
+	if cfg.Msgpack {
+		header.Add("Vary", "Accept")
+	}
+}
+
+func acceptsMsgpack(accept string) bool {
+	for _, mediaType := range strings.Split(accept, ",") {
+		mediaType, q := parseQuality(mediaType)
+		if q > 0 && (mediaType == ContentTypeMsgpack || mediaType == contentTypeMsgpackAlt) {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiateEncoding returns the supported encoding of the highest quality in Accept-Encoding, gzip on a tie,
+// or "" if none is accepted
+func negotiateEncoding(acceptEncoding string) string {
+	encoding, best := "", 0.0
+	for _, item := range strings.Split(acceptEncoding, ",") {
+		name, q := parseQuality(item)
+		if name != encodingGzip && name != encodingDeflate || q <= 0 {
+			continue
+		}
+		if q > best || q == best && name == encodingGzip {
+			encoding, best = name, q
+		}
+	}
+	return encoding
+}
+
+// parseQuality splits an item of an Accept header into its lowercase value and its quality, 1 by default
+func parseQuality(item string) (string, float64) {
+	parts := strings.Split(item, ";")
+	value := strings.ToLower(strings.TrimSpace(parts[0]))
+	q := 1.0
+	for _, param := range parts[1:] {
+		param = strings.TrimSpace(param)
+		if !strings.HasPrefix(param, "q=") {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(param[2:], 64)
+		if err != nil {
+			return value, 0
+		}
+		q = parsed
+	}
+	return value, q
+}
+
+func compress(encoding string, body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case encodingGzip:
+		w = gzip.NewWriter(&buf)
+	default:
+		// the deflate content coding is the zlib format
+		w = zlib.NewWriter(&buf)
+	}
+	if _, err := w.Write(body); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// recorder holds the response, which is encoded once complete
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
@@ -0,0 +1,108 @@
+package respencode
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	for acceptEncoding, expected := range map[string]string{
+		"":                          "",
+		"br":                        "",
+		"gzip, deflate, br":         encodingGzip,
+		"deflate":                   encodingDeflate,
+		"gzip;q=0.5, deflate":       encodingDeflate,
+		"deflate;q=0.8, gzip;q=0.8": encodingGzip,
+		"gzip;q=0":                  "",
+		"GZIP":                      encodingGzip,
+	} {
+		require.Equal(t, expected, negotiateEncoding(acceptEncoding), acceptEncoding)
+	}
+
+	require.True(t, acceptsMsgpack("application/json;q=0.5, application/msgpack"))
+	require.True(t, acceptsMsgpack("application/x-msgpack"))
+	require.False(t, acceptsMsgpack("application/msgpack;q=0"))
+	require.False(t, acceptsMsgpack("*/*"))
+}
+
+func TestJSONToMsgpack(t *testing.T) {
+	for doc, expected := range map[string]string{
+		`null`:                              "c0",
+		`[true,false]`:                      "92c3c2",
+		`{"b":1,"a":"x"}`:                   "82a161a178a16201",
+		`[-1,-33,200,70000,1.5]`:            "95ffd0dfccc8ce00011170cb3ff8000000000000",
+		`18446744073709551615`:              "cfffffffffffffffff",
+		`"` + strings.Repeat("a", 32) + `"`: "d920" + strings.Repeat("61", 32),
+	} {
+		bz, err := JSONToMsgpack([]byte(doc))
+		require.NoError(t, err, doc)
+		require.Equal(t, expected, hex.EncodeToString(bz), doc)
+	}
+
+	_, err := JSONToMsgpack([]byte(`{"a":`))
+	require.Error(t, err)
+}
+
+func TestHandler(t *testing.T) {
+	result := strings.Repeat("0", 2048)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		body := `{"jsonrpc":"2.0","id":1,"result":"` + result + `"}`
+		if strings.Contains(r.URL.Path, "small") {
+			body = `{"jsonrpc":"2.0","id":1,"result":"0x1"}`
+		}
+		_, _ = w.Write([]byte(body))
+	})
+	handler := Handler(Config{Compression: true, MinSize: 1024, Msgpack: true}, next)
+	serve := func(path string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{}`))
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		return rec
+	}
+
+	// the responses of the clients requesting no encoding are left untouched
+	rec := serve("/", nil)
+	require.Empty(t, rec.Header().Get("Content-Encoding"))
+	require.Contains(t, rec.Body.String(), result)
+	require.Equal(t, []string{"Accept-Encoding", "Accept"}, rec.Header()["Vary"])
+
+	rec = serve("/", map[string]string{"Accept-Encoding": "gzip"})
+	require.Equal(t, encodingGzip, rec.Header().Get("Content-Encoding"))
+	gr, err := gzip.NewReader(rec.Body)
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(gr)
+	require.NoError(t, err)
+	require.Contains(t, string(body), result)
+
+	rec = serve("/", map[string]string{"Accept-Encoding": "deflate"})
+	require.Equal(t, encodingDeflate, rec.Header().Get("Content-Encoding"))
+	zr, err := zlib.NewReader(rec.Body)
+	require.NoError(t, err)
+	body, err = ioutil.ReadAll(zr)
+	require.NoError(t, err)
+	require.Contains(t, string(body), result)
+
+	// the small responses aren't compressed
+	rec = serve("/small", map[string]string{"Accept-Encoding": "gzip"})
+	require.Empty(t, rec.Header().Get("Content-Encoding"))
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	rec = serve("/small", map[string]string{"Accept": ContentTypeMsgpack})
+	require.Equal(t, ContentTypeMsgpack, rec.Header().Get("Content-Type"))
+	expected, err := JSONToMsgpack([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+	require.NoError(t, err)
+	require.Equal(t, expected, rec.Body.Bytes())
+}
@@ -0,0 +1,221 @@
+package sanitize
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// the json-rpc error codes of the rejected requests
+	ErrCodeParse          = -32700
+	ErrCodeInvalidRequest = -32600
+	ErrCodeInvalidParams  = -32602
+)
+
+// Config defines the bounds of the json-rpc requests, a zero bound is disabled
+type Config struct {
+	// MaxBodySize is the max size in bytes of the body of a request, the batches included
+	MaxBodySize int64
+	// MaxParamsDepth is the max nesting of the arrays and the objects of the params of a request
+	MaxParamsDepth int
+	// MaxParamsLength is the max number of elements of each array and object of the params of a request
+	MaxParamsLength int
+}
+
+// Enabled returns true if any bound is enabled.
+func (cfg Config) Enabled() bool {
+	return cfg.MaxBodySize > 0 || cfg.MaxParamsDepth > 0 || cfg.MaxParamsLength > 0
+}
+
+// Handler wraps the json-rpc handler, rejecting the requests exceeding the bounds before they are decoded by the
+// other handlers and the server. An oversized body is rejected with 413, a body which isn't json with a parse
+// error, and the params which are too deep, too long or have a number out of the range of the 64 bits integers
+// and floats with an invalid params error. A batch is rejected as a whole if one of its requests is.
+func Handler(cfg Config, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+		var reader io.Reader = r.Body
+		if cfg.MaxBodySize > 0 {
+			reader = io.LimitReader(r.Body, cfg.MaxBodySize+1)
+		}
+		body, err := ioutil.ReadAll(reader)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if cfg.MaxBodySize > 0 && int64(len(body)) > cfg.MaxBodySize {
+			writeError(w, http.StatusRequestEntityTooLarge, nil, ErrCodeInvalidRequest,
+				fmt.Sprintf("request body exceeds the max of %d bytes", cfg.MaxBodySize))
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		if !json.Valid(body) {
+			writeError(w, http.StatusOK, nil, ErrCodeParse, "parse error")
+			return
+		}
+		reqs, batch := parse(body)
+		if reqs == nil {
+			// the server answers the invalid requests
+			next.ServeHTTP(w, r)
+			return
+		}
+		errs := make([]error, len(reqs))
+		var rejected bool
+		for i, req := range reqs {
+			errs[i] = cfg.checkParams(req.Params)
+			rejected = rejected || errs[i] != nil
+		}
+		if !rejected {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		resps := make([]response, len(reqs))
+		for i, req := range reqs {
+			resps[i] = newResponse(req.ID, ErrCodeInvalidParams, "the batch has a request with invalid params")
+			if errs[i] != nil {
+				resps[i].Error.Message = "invalid params: " + errs[i].Error()
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if batch {
+			_ = json.NewEncoder(w).Encode(resps)
+		} else {
+			_ = json.NewEncoder(w).Encode(resps[0])
+		}
+	})
+}
+
+// checkParams checks the depth, the length and the numbers of the params, which are valid json
+func (cfg Config) checkParams(params json.RawMessage) error {
+	if len(params) == 0 {
+		return nil
+	}
+	type container struct {
+		object bool
+		// tokens is the number of the tokens of the container, the keys of an object included
+		tokens int
+	}
+	var stack []container
+
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.UseNumber()
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if delim, ok := token.(json.Delim); ok && (delim == ']' || delim == '}') {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		if len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			top.tokens++
+			length := top.tokens
+			if top.object {
+				length = (top.tokens + 1) / 2
+			}
+			if cfg.MaxParamsLength > 0 && length > cfg.MaxParamsLength {
+				return fmt.Errorf("an array or an object exceeds the max length of %d", cfg.MaxParamsLength)
+			}
+		}
+
+		switch t := token.(type) {
+		case json.Delim:
+			stack = append(stack, container{object: t == '{'})
+			if cfg.MaxParamsDepth > 0 && len(stack) > cfg.MaxParamsDepth {
+				return fmt.Errorf("the params exceed the max depth of %d", cfg.MaxParamsDepth)
+			}
+		case json.Number:
+			if err := checkNumber(t.String()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// checkNumber checks that the integers fit in 64 bits and the other numbers in a float64
+func checkNumber(number string) error {
+	if strings.ContainsAny(number, ".eE") {
+		if _, err := strconv.ParseFloat(number, 64); err != nil {
+			return fmt.Errorf("number %s is out of range", number)
+		}
+		return nil
+	}
+	if _, err := strconv.ParseInt(number, 10, 64); err == nil {
+		return nil
+	}
+	if _, err := strconv.ParseUint(number, 10, 64); err == nil {
+		return nil
+	}
+	return fmt.Errorf("number %s is out of range", number)
+}
+
+// parse returns the requests of the body and whether it is a batch, nil if it isn't valid json-rpc. The elements
+// of a batch which aren't requests are returned without params, the server answers them.
+func parse(body []byte) ([]request, bool) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var elems []json.RawMessage
+		if err := json.Unmarshal(trimmed, &elems); err != nil || len(elems) == 0 {
+			return nil, true
+		}
+		reqs := make([]request, len(elems))
+		for i, elem := range elems {
+			if err := json.Unmarshal(elem, &reqs[i]); err != nil {
+				reqs[i] = request{}
+			}
+		}
+		return reqs, true
+	}
+	var req request
+	if err := json.Unmarshal(trimmed, &req); err != nil {
+		return nil, false
+	}
+	return []request{req}, false
+}
+
+func writeError(w http.ResponseWriter, status int, id json.RawMessage, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(newResponse(id, code, message))
+}
+
+func newResponse(id json.RawMessage, code int, message string) response {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return response{Version: "2.0", ID: id, Error: jsonError{Code: code, Message: message}}
+}
+
+type request struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+type response struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Error   jsonError       `json:"error"`
+}
+
+type jsonError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
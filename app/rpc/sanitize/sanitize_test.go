@@ -0,0 +1,82 @@
+package sanitize
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	var calls int
+	handler := Handler(Config{MaxBodySize: 256, MaxParamsDepth: 4, MaxParamsLength: 4}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+	}))
+	serve := func(body string, status int) []byte {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		require.Equal(t, status, rec.Code)
+		return rec.Body.Bytes()
+	}
+	requireError := func(body string, status, code int, id string) {
+		var resp response
+		require.NoError(t, json.Unmarshal(serve(body, status), &resp), body)
+		require.Equal(t, code, resp.Error.Code, body)
+		require.Equal(t, id, string(resp.ID), body)
+	}
+
+	// the requests within the bounds are served
+	for _, body := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber"}`,
+		`{"jsonrpc":"2.0","id":1,"method":"eth_getBalance","params":["0x01","latest"]}`,
+		`{"jsonrpc":"2.0","id":1,"method":"eth_feeHistory","params":[4,"latest",[25.5,75]]}`,
+		`{"jsonrpc":"2.0","id":1,"method":"eth_getLogs","params":[{"topics":[["0x0a","0x0b"]]}]}`,
+		`{"jsonrpc":"2.0","id":1,"method":"x","params":[18446744073709551615,-9223372036854775808,1e308]}`,
+		`{"jsonrpc":"2.0","id":1,"method":"x","params":{"a":1,"b":2,"c":3,"d":4}}`,
+	} {
+		serve(body, http.StatusOK)
+	}
+	require.Equal(t, 6, calls)
+
+	requireError(`{"jsonrpc":"2.0","id":1,"method":"x","params":["`+strings.Repeat("a", 256)+`"]}`, http.StatusRequestEntityTooLarge, ErrCodeInvalidRequest, "null")
+	requireError(`{"jsonrpc":"2.0","id":1,"method"`, http.StatusOK, ErrCodeParse, "null")
+	for _, params := range []string{
+		`[[[[["too deep"]]]]]`,
+		`[1,2,3,4,5]`,
+		`[{"a":1,"b":2,"c":3,"d":4,"e":5}]`,
+		`[18446744073709551616]`,
+		`[-9223372036854775809]`,
+		`[1e309]`,
+	} {
+		requireError(`{"jsonrpc":"2.0","id":"7","method":"x","params":`+params+`}`, http.StatusOK, ErrCodeInvalidParams, `"7"`)
+	}
+	require.Equal(t, 6, calls)
+
+	// a batch with invalid params is rejected as a whole, whatever its other elements
+	var resps []response
+	require.NoError(t, json.Unmarshal(serve(`[{"jsonrpc":"2.0","id":1,"method":"x"},1,{"jsonrpc":"2.0","id":2,"method":"x","params":[[[[[1]]]]]}]`, http.StatusOK), &resps))
+	require.Len(t, resps, 3)
+	for _, resp := range resps {
+		require.Equal(t, ErrCodeInvalidParams, resp.Error.Code)
+	}
+	require.Equal(t, "1", string(resps[0].ID))
+	require.Equal(t, "2", string(resps[2].ID))
+	require.Contains(t, resps[2].Error.Message, "depth")
+	require.Equal(t, 6, calls)
+
+	// the invalid requests are answered by the server
+	serve(`[]`, http.StatusOK)
+	serve(`"eth_blockNumber"`, http.StatusOK)
+	serve(`[{"jsonrpc":"2.0","id":1,"method":"x"},1]`, http.StatusOK)
+	require.Equal(t, 9, calls)
+}
+
+func TestCheckParamsDisabled(t *testing.T) {
+	require.NoError(t, Config{}.checkParams(json.RawMessage(`[[[[[[1,2,3,4,5]]]]]]`)))
+	require.Error(t, Config{}.checkParams(json.RawMessage(`[1e999]`)), "the numbers are always checked")
+	require.NoError(t, Config{}.checkParams(nil))
+}
@@ -0,0 +1,327 @@
+package storeproof
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/okex/exchain/libs/cosmos-sdk/store/rootmulti"
+	"github.com/okex/exchain/libs/iavl"
+	"github.com/okex/exchain/libs/tendermint/crypto/merkle"
+)
+
+// HashOp is the ics23 hash operation of a proof step
+type HashOp int32
+
+const (
+	HashOpNoHash HashOp = 0
+	HashOpSHA256 HashOp = 1
+)
+
+func (op HashOp) MarshalText() ([]byte, error) {
+	switch op {
+	case HashOpNoHash:
+		return []byte("NO_HASH"), nil
+	case HashOpSHA256:
+		return []byte("SHA256"), nil
+	}
+	return nil, fmt.Errorf("unsupported hash op %d", op)
+}
+
+// LengthOp is the ics23 length prefix of the key and the value of a leaf
+type LengthOp int32
+
+const LengthOpVarProto LengthOp = 1
+
+func (op LengthOp) MarshalText() ([]byte, error) {
+	if op != LengthOpVarProto {
+		return nil, fmt.Errorf("unsupported length op %d", op)
+	}
+	return []byte("VAR_PROTO"), nil
+}
+
+// ErrAbsentKey is returned for the keys which aren't in the store, the non-existence proofs aren't supported
+var ErrAbsentKey = errors.New("the key isn't in the store, only the existence proofs are supported")
+
+// LeafOp is the ics23 hashing of a leaf of the tree
+type LeafOp struct {
+	Hash         HashOp        `json:"hash"`
+	PrehashKey   HashOp        `json:"prehash_key"`
+	PrehashValue HashOp        `json:"prehash_value"`
+	Length       LengthOp      `json:"length"`
+	Prefix       hexutil.Bytes `json:"prefix"`
+}
+
+// InnerOp is the ics23 hashing of an inner node of the tree, with the hash of its child between the prefix and
+// the suffix
+type InnerOp struct {
+	Hash   HashOp        `json:"hash"`
+	Prefix hexutil.Bytes `json:"prefix"`
+	Suffix hexutil.Bytes `json:"suffix"`
+}
+
+// ExistenceProof is the ics23 proof of a key and its value, the path goes from the leaf up to the root
+type ExistenceProof struct {
+	Key   hexutil.Bytes `json:"key"`
+	Value hexutil.Bytes `json:"value"`
+	Leaf  LeafOp        `json:"leaf"`
+	Path  []InnerOp     `json:"path"`
+}
+
+// CommitmentProof is the ics23 commitment proof, only the existence proofs are produced
+type CommitmentProof struct {
+	Exist *ExistenceProof `json:"exist"`
+}
+
+// Calculate returns the root hash the proof commits to
+func (p *ExistenceProof) Calculate() []byte {
+	key, value := p.Key, p.Value
+	if p.Leaf.PrehashKey == HashOpSHA256 {
+		key = sha256Sum(key)
+	}
+	if p.Leaf.PrehashValue == HashOpSHA256 {
+		value = sha256Sum(value)
+	}
+	var buf bytes.Buffer
+	buf.Write(p.Leaf.Prefix)
+	buf.Write(lengthPrefixed(key))
+	buf.Write(lengthPrefixed(value))
+	hash := sha256Sum(buf.Bytes())
+	for _, op := range p.Path {
+		buf.Reset()
+		buf.Write(op.Prefix)
+		buf.Write(hash)
+		buf.Write(op.Suffix)
+		hash = sha256Sum(buf.Bytes())
+	}
+	return hash
+}
+
+// FromIAVL converts the iavl proof of the key and its value into an ics23 existence proof, whose root is the root
+// of the store
+func FromIAVL(proof *iavl.RangeProof, key, value []byte) (*ExistenceProof, error) {
+	if proof == nil || len(proof.Leaves) != 1 || !bytes.Equal(proof.Leaves[0].Key, key) {
+		return nil, ErrAbsentKey
+	}
+	leaf := proof.Leaves[0]
+	if !bytes.Equal(leaf.ValueHash, sha256Sum(value)) {
+		return nil, fmt.Errorf("the value doesn't match the proof of key %X", key)
+	}
+
+	// adapted from iavl.ProofLeafNode.Hash, the key and the value hash are length prefixed by the leaf op
+	prefix := varint(0)
+	prefix = append(prefix, varint(1)...)
+	prefix = append(prefix, varint(leaf.Version)...)
+	p := &ExistenceProof{
+		Key:   key,
+		Value: value,
+		Leaf: LeafOp{
+			Hash:         HashOpSHA256,
+			PrehashKey:   HashOpNoHash,
+			PrehashValue: HashOpSHA256,
+			Length:       LengthOpVarProto,
+			Prefix:       prefix,
+		},
+	}
+	// adapted from iavl.ProofInnerNode.Hash, the path of iavl goes from the root down to the leaf
+	for i := len(proof.LeftPath) - 1; i >= 0; i-- {
+		node := proof.LeftPath[i]
+		prefix := varint(int64(node.Height))
+		prefix = append(prefix, varint(node.Size)...)
+		prefix = append(prefix, varint(node.Version)...)
+		op := InnerOp{Hash: HashOpSHA256}
+		if len(node.Left) > 0 {
+			op.Prefix = append(append(prefix, lengthPrefixed(node.Left)...), sha256.Size)
+		} else {
+			op.Prefix = append(prefix, sha256.Size)
+			op.Suffix = lengthPrefixed(node.Right)
+		}
+		p.Path = append(p.Path, op)
+	}
+	return p, nil
+}
+
+// FromMultiStore converts the multistore proof into the ics23 existence proof of the store in the app hash. The
+// value of the proof is the hash of the root of the store, as the stores are hashed twice in the app hash.
+func FromMultiStore(proof *rootmulti.MultiStoreProof, storeName string) (*ExistenceProof, error) {
+	hashes := make(map[string][]byte, len(proof.StoreInfos))
+	for _, info := range proof.StoreInfos {
+		hashes[info.Name] = info.Hash()
+	}
+	value, ok := hashes[storeName]
+	if !ok {
+		return nil, fmt.Errorf("no %s store in the multistore proof", storeName)
+	}
+	_, proofs, _ := merkle.SimpleProofsFromMap(hashes)
+	simple := proofs[storeName]
+
+	path, err := simpleInnerOps(simple.Index, simple.Total, simple.Aunts)
+	if err != nil {
+		return nil, err
+	}
+	return &ExistenceProof{
+		Key:   []byte(storeName),
+		Value: value,
+		Leaf: LeafOp{
+			Hash:         HashOpSHA256,
+			PrehashKey:   HashOpNoHash,
+			PrehashValue: HashOpSHA256,
+			Length:       LengthOpVarProto,
+			Prefix:       []byte{0},
+		},
+		Path: path,
+	}, nil
+}
+
+// Prove converts the proof of a query of the key of the store with the proof into the ics23 proofs of the key in
+// the store then of the store in the app hash, and returns the app hash they commit to
+func Prove(storeName string, key, value []byte, proof *merkle.Proof) ([]CommitmentProof, []byte, error) {
+	if proof == nil {
+		return nil, nil, errors.New("no proof in the query response")
+	}
+	var storeProof, multiStoreProof *ExistenceProof
+	for _, op := range proof.Ops {
+		switch op.Type {
+		case iavl.ProofOpIAVLAbsence:
+			return nil, nil, ErrAbsentKey
+		case iavl.ProofOpIAVLValue:
+			decoded, err := iavl.ValueOpDecoder(op)
+			if err != nil {
+				return nil, nil, err
+			}
+			if storeProof, err = FromIAVL(decoded.(iavl.ValueOp).Proof, key, value); err != nil {
+				return nil, nil, err
+			}
+		case rootmulti.ProofOpMultiStore:
+			decoded, err := rootmulti.MultiStoreProofOpDecoder(op)
+			if err != nil {
+				return nil, nil, err
+			}
+			if multiStoreProof, err = FromMultiStore(decoded.(rootmulti.MultiStoreProofOp).Proof, storeName); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	if storeProof == nil || multiStoreProof == nil {
+		return nil, nil, errors.New("the query response lacks the proof of the store or of the multistore")
+	}
+	// the value of the multistore proof is the hash of the store root
+	if !bytes.Equal(sha256Sum(storeProof.Calculate()), multiStoreProof.Value) {
+		return nil, nil, fmt.Errorf("the proof of key %X doesn't match the root of the %s store", key, storeName)
+	}
+	return []CommitmentProof{{Exist: storeProof}, {Exist: multiStoreProof}}, multiStoreProof.Calculate(), nil
+}
+
+// simpleInnerOps returns the inner ops of the simple merkle proof from the leaf up to the root, adapted from
+// merkle.computeHashFromAunts
+func simpleInnerOps(index, total int, aunts [][]byte) ([]InnerOp, error) {
+	if total == 1 {
+		if len(aunts) != 0 {
+			return nil, errors.New("invalid simple proof")
+		}
+		return nil, nil
+	}
+	if len(aunts) == 0 {
+		return nil, errors.New("invalid simple proof")
+	}
+	numLeft := splitPoint(total)
+	aunt := aunts[len(aunts)-1]
+	if index < numLeft {
+		path, err := simpleInnerOps(index, numLeft, aunts[:len(aunts)-1])
+		if err != nil {
+			return nil, err
+		}
+		return append(path, InnerOp{Hash: HashOpSHA256, Prefix: []byte{1}, Suffix: aunt}), nil
+	}
+	path, err := simpleInnerOps(index-numLeft, total-numLeft, aunts[:len(aunts)-1])
+	if err != nil {
+		return nil, err
+	}
+	return append(path, InnerOp{Hash: HashOpSHA256, Prefix: append([]byte{1}, aunt...)}), nil
+}
+
+// splitPoint returns the largest power of 2 less than length, as merkle.getSplitPoint
+func splitPoint(length int) int {
+	k := 1 << uint(bits.Len(uint(length))-1)
+	if k == length {
+		k >>= 1
+	}
+	return k
+}
+
+// Marshal returns the protobuf encoding of the ics23 commitment proof
+func (p CommitmentProof) Marshal() []byte {
+	var exist []byte
+	exist = appendBytes(exist, 1, p.Exist.Key)
+	exist = appendBytes(exist, 2, p.Exist.Value)
+
+	var leaf []byte
+	leaf = appendVarint(leaf, 1, uint64(p.Exist.Leaf.Hash))
+	leaf = appendVarint(leaf, 2, uint64(p.Exist.Leaf.PrehashKey))
+	leaf = appendVarint(leaf, 3, uint64(p.Exist.Leaf.PrehashValue))
+	leaf = appendVarint(leaf, 4, uint64(p.Exist.Leaf.Length))
+	leaf = appendBytes(leaf, 5, p.Exist.Leaf.Prefix)
+	exist = appendBytes(exist, 3, leaf)
+
+	for _, op := range p.Exist.Path {
+		var inner []byte
+		inner = appendVarint(inner, 1, uint64(op.Hash))
+		inner = appendBytes(inner, 2, op.Prefix)
+		inner = appendBytes(inner, 3, op.Suffix)
+		exist = appendBytes(exist, 4, inner)
+	}
+	return appendBytes(nil, 1, exist)
+}
+
+// MarshalMerkleProof returns the protobuf encoding of the ibc merkle proof of the commitment proofs, from the
+// store up to the app hash
+func MarshalMerkleProof(proofs []CommitmentProof) []byte {
+	var bz []byte
+	for _, proof := range proofs {
+		bz = appendBytes(bz, 1, proof.Marshal())
+	}
+	return bz
+}
+
+// appendVarint appends the varint field, the zero values are omitted as in proto3
+func appendVarint(bz []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return bz
+	}
+	bz = appendUvarint(bz, uint64(field)<<3)
+	return appendUvarint(bz, v)
+}
+
+// appendBytes appends the length delimited field, the empty values are omitted as in proto3
+func appendBytes(bz []byte, field int, v []byte) []byte {
+	if len(v) == 0 {
+		return bz
+	}
+	bz = appendUvarint(bz, uint64(field)<<3|2)
+	bz = appendUvarint(bz, uint64(len(v)))
+	return append(bz, v...)
+}
+
+func appendUvarint(bz []byte, v uint64) []byte {
+	buf := make([]byte, binary.MaxVarintLen64)
+	return append(bz, buf[:binary.PutUvarint(buf, v)]...)
+}
+
+func lengthPrefixed(bz []byte) []byte {
+	return append(appendUvarint(nil, uint64(len(bz))), bz...)
+}
+
+func varint(v int64) []byte {
+	buf := make([]byte, binary.MaxVarintLen64)
+	return buf[:binary.PutVarint(buf, v)]
+}
+
+func sha256Sum(bz []byte) []byte {
+	hash := sha256.Sum256(bz)
+	return hash[:]
+}
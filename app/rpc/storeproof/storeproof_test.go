@@ -0,0 +1,85 @@
+package storeproof
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/okex/exchain/libs/cosmos-sdk/store/rootmulti"
+	"github.com/okex/exchain/libs/cosmos-sdk/store/types"
+	"github.com/okex/exchain/libs/iavl"
+	abci "github.com/okex/exchain/libs/tendermint/abci/types"
+)
+
+func TestFromIAVL(t *testing.T) {
+	tree, err := iavl.NewMutableTree(dbm.NewMemDB(), 0)
+	require.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		tree.Set([]byte(fmt.Sprintf("key%03d", i)), []byte(fmt.Sprintf("value%d", i)))
+	}
+	_, _, _, err = tree.SaveVersion(false)
+	require.NoError(t, err)
+
+	for _, i := range []int{0, 37, 99} {
+		key := []byte(fmt.Sprintf("key%03d", i))
+		value, proof, err := tree.GetWithProof(key)
+		require.NoError(t, err)
+		p, err := FromIAVL(proof, key, value)
+		require.NoError(t, err)
+		require.Equal(t, tree.Hash(), p.Calculate())
+
+		_, err = FromIAVL(proof, key, []byte("forged"))
+		require.Error(t, err)
+	}
+
+	_, proof, err := tree.GetWithProof([]byte("absent"))
+	require.NoError(t, err)
+	_, err = FromIAVL(proof, []byte("absent"), nil)
+	require.Equal(t, ErrAbsentKey, err)
+}
+
+func TestProve(t *testing.T) {
+	multi := rootmulti.NewStore(dbm.NewMemDB())
+	var keys []types.StoreKey
+	for _, name := range []string{"acc", "bank", "evm", "gov", "staking"} {
+		key := types.NewKVStoreKey(name)
+		keys = append(keys, key)
+		multi.MountStoreWithDB(key, types.StoreTypeIAVL, nil)
+	}
+	require.NoError(t, multi.LoadLatestVersion())
+	for i, key := range keys {
+		store := multi.GetCommitKVStore(key)
+		for j := 0; j <= i; j++ {
+			store.Set([]byte(fmt.Sprintf("key%d", j)), []byte(fmt.Sprintf("value%d", j)))
+		}
+	}
+	id, _, _ := multi.Commit(&iavl.TreeDelta{}, nil)
+
+	for _, name := range []string{"acc", "evm", "staking"} {
+		res := multi.Query(abci.RequestQuery{Path: "/" + name + "/key", Data: []byte("key0"), Height: id.Version, Prove: true})
+		require.EqualValues(t, 0, res.Code, res.Log)
+
+		proofs, root, err := Prove(name, res.Key, res.Value, res.Proof)
+		require.NoError(t, err)
+		require.Equal(t, id.Hash, root)
+		require.Len(t, proofs, 2)
+		require.NotEmpty(t, MarshalMerkleProof(proofs))
+
+		_, _, err = Prove(name, res.Key, []byte("forged"), res.Proof)
+		require.Error(t, err)
+		_, _, err = Prove("bank", res.Key, res.Value, res.Proof)
+		require.Error(t, err)
+	}
+
+	res := multi.Query(abci.RequestQuery{Path: "/evm/key", Data: []byte("absent"), Height: id.Version, Prove: true})
+	_, _, err := Prove("evm", res.Key, res.Value, res.Proof)
+	require.Equal(t, ErrAbsentKey, err)
+}
+
+func TestSplitPoint(t *testing.T) {
+	for length, expected := range map[int]int{2: 1, 3: 2, 4: 2, 5: 4, 8: 4, 9: 8} {
+		require.Equal(t, expected, splitPoint(length), length)
+	}
+}
@@ -0,0 +1,219 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// Header negotiates the streamed response of a request, with one of the modes
+	Header = "X-Rpc-Stream"
+
+	// ModeNDJSON writes each element of the result on its own line, followed by a line holding the json-rpc
+	// response without the elements
+	ModeNDJSON = "ndjson"
+	// ModeArray writes the json-rpc response with its result array as the elements are found
+	ModeArray = "array"
+
+	ContentTypeNDJSON = "application/x-ndjson"
+
+	// the json-rpc error codes of the streamed requests
+	ErrCodeInvalidRequest = -32600
+	ErrCodeInvalidParams  = -32602
+	ErrCodeServer         = -32000
+
+	// flushSize is the size of the elements buffered before they're flushed to the client
+	flushSize = 32 * 1024
+)
+
+// ErrInvalidParams is returned by the stream functions failing to decode their params
+type ErrInvalidParams struct {
+	Err error
+}
+
+func (e ErrInvalidParams) Error() string {
+	return "invalid params: " + e.Err.Error()
+}
+
+// Func streams the result of a request with the params, passing its elements to emit in order. It stops at the
+// first error of emit, the client is then gone.
+type Func func(ctx context.Context, params json.RawMessage, emit func(interface{}) error) error
+
+// Handler wraps the json-rpc handler, serving the requests of the methods asking for a streamed response by the
+// header with their stream function instead of next, so that the result is neither buffered by the server nor by
+// the other handlers. The streamed requests are served through admit, if any, to be admitted like the others.
+// The batches and the requests without the header are served by next.
+func Handler(methods map[string]Func, admit func(http.Handler) http.Handler, next http.Handler) http.Handler {
+	var streamed http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := r.Context().Value(requestKey{}).(request)
+		serve(w, r, methods[req.Method], req)
+	})
+	if admit != nil {
+		streamed = admit(streamed)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mode := r.Header.Get(Header)
+		if r.Method != http.MethodPost || mode == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil || methods[req.Method] == nil {
+			// the batches and the other methods aren't streamed
+			next.ServeHTTP(w, r)
+			return
+		}
+		if mode != ModeNDJSON && mode != ModeArray {
+			writeError(w, req.ID, ErrCodeInvalidRequest, "unknown stream mode "+mode)
+			return
+		}
+		req.mode = mode
+		streamed.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestKey{}, req)))
+	})
+}
+
+func serve(w http.ResponseWriter, r *http.Request, stream Func, req request) {
+	enc := &encoder{w: w, req: req}
+	enc.flusher, _ = w.(http.Flusher)
+	enc.close(stream(r.Context(), req.Params, enc.emit))
+}
+
+// encoder writes the elements of a streamed result as they're emitted, the response is only started with the
+// first element so that a request failing at once is answered with a plain json-rpc error
+type encoder struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	req     request
+
+	buf   bytes.Buffer
+	count int
+}
+
+func (e *encoder) emit(elem interface{}) error {
+	bz, err := json.Marshal(elem)
+	if err != nil {
+		return err
+	}
+	if e.count == 0 {
+		e.start()
+	}
+	switch e.req.mode {
+	case ModeNDJSON:
+		e.buf.Write(bz)
+		e.buf.WriteByte('\n')
+	case ModeArray:
+		if e.count > 0 {
+			e.buf.WriteByte(',')
+		}
+		e.buf.Write(bz)
+	}
+	e.count++
+
+	if e.buf.Len() < flushSize {
+		return nil
+	}
+	return e.flush()
+}
+
+func (e *encoder) start() {
+	id := e.req.id()
+	switch e.req.mode {
+	case ModeNDJSON:
+		e.w.Header().Set("Content-Type", ContentTypeNDJSON)
+	case ModeArray:
+		e.w.Header().Set("Content-Type", "application/json")
+		e.buf.WriteString(`{"jsonrpc":"2.0","id":` + string(id) + `,"result":[`)
+	}
+}
+
+func (e *encoder) flush() error {
+	_, err := e.w.Write(e.buf.Bytes())
+	e.buf.Reset()
+	if err == nil && e.flusher != nil {
+		e.flusher.Flush()
+	}
+	return err
+}
+
+// close ends the response. A failing stream is ended with the error of the json-rpc response, after the
+// elements already written.
+func (e *encoder) close(err error) {
+	if e.count == 0 {
+		if err != nil {
+			code := ErrCodeServer
+			if errors.As(err, &ErrInvalidParams{}) {
+				code = ErrCodeInvalidParams
+			}
+			writeError(e.w, e.req.ID, code, err.Error())
+			return
+		}
+		e.start()
+	}
+
+	var jsonErr []byte
+	if err != nil {
+		jsonErr, _ = json.Marshal(jsonError{Code: ErrCodeServer, Message: err.Error()})
+	}
+	id := string(e.req.id())
+	switch e.req.mode {
+	case ModeNDJSON:
+		if err != nil {
+			e.buf.WriteString(`{"jsonrpc":"2.0","id":` + id + `,"error":` + string(jsonErr) + "}\n")
+		} else {
+			e.buf.WriteString(`{"jsonrpc":"2.0","id":` + id + `,"result":{"count":` + strconv.Itoa(e.count) + "}}\n")
+		}
+	case ModeArray:
+		e.buf.WriteByte(']')
+		if err != nil {
+			e.buf.WriteString(`,"error":` + string(jsonErr))
+		}
+		e.buf.WriteString("}\n")
+	}
+	_ = e.flush()
+}
+
+func writeError(w http.ResponseWriter, id json.RawMessage, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response{Version: "2.0", ID: request{ID: id}.id(), Error: jsonError{Code: code, Message: message}})
+}
+
+type requestKey struct{}
+
+type request struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+
+	mode string
+}
+
+func (req request) id() json.RawMessage {
+	if len(req.ID) == 0 {
+		return json.RawMessage("null")
+	}
+	return req.ID
+}
+
+type response struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Error   jsonError       `json:"error"`
+}
+
+type jsonError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,132 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testMethods() map[string]Func {
+	return map[string]Func{
+		"test_numbers": func(ctx context.Context, params json.RawMessage, emit func(interface{}) error) error {
+			var args []int
+			if err := json.Unmarshal(params, &args); err != nil || len(args) != 1 {
+				return ErrInvalidParams{Err: errors.New("expected a count")}
+			}
+			for i := 0; i < args[0]; i++ {
+				if err := emit(i); err != nil {
+					return err
+				}
+			}
+			if args[0] < 0 {
+				return errors.New("negative count")
+			}
+			return nil
+		},
+		"test_fails": func(ctx context.Context, params json.RawMessage, emit func(interface{}) error) error {
+			if err := emit(map[string]int{"a": 1}); err != nil {
+				return err
+			}
+			return errors.New("failed")
+		},
+	}
+}
+
+func serveStream(handler http.Handler, mode, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	if mode != "" {
+		req.Header.Set(Header, mode)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler(t *testing.T) {
+	var admitted, served int
+	admit := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			admitted++
+			next.ServeHTTP(w, r)
+		})
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served++
+		_, _ = w.Write([]byte("next"))
+	})
+	handler := Handler(testMethods(), admit, next)
+
+	testCases := []struct {
+		name, mode, body string
+		contentType      string
+		expected         string
+	}{
+		{"no header", "", `{"jsonrpc":"2.0","id":1,"method":"test_numbers","params":[3]}`, "", "next"},
+		{"other method", ModeNDJSON, `{"jsonrpc":"2.0","id":1,"method":"eth_chainId"}`, "", "next"},
+		{"batch", ModeNDJSON, `[{"jsonrpc":"2.0","id":1,"method":"test_numbers","params":[3]}]`, "", "next"},
+		{
+			"ndjson", ModeNDJSON, `{"jsonrpc":"2.0","id":1,"method":"test_numbers","params":[3]}`, ContentTypeNDJSON,
+			"0\n1\n2\n" + `{"jsonrpc":"2.0","id":1,"result":{"count":3}}` + "\n",
+		},
+		{
+			"array", ModeArray, `{"jsonrpc":"2.0","id":"a","method":"test_numbers","params":[3]}`, "application/json",
+			`{"jsonrpc":"2.0","id":"a","result":[0,1,2]}` + "\n",
+		},
+		{
+			"empty array", ModeArray, `{"jsonrpc":"2.0","id":1,"method":"test_numbers","params":[0]}`, "application/json",
+			`{"jsonrpc":"2.0","id":1,"result":[]}` + "\n",
+		},
+		{
+			"failure midway ndjson", ModeNDJSON, `{"jsonrpc":"2.0","id":1,"method":"test_fails"}`, ContentTypeNDJSON,
+			`{"a":1}` + "\n" + `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"failed"}}` + "\n",
+		},
+		{
+			"failure midway array", ModeArray, `{"jsonrpc":"2.0","id":1,"method":"test_fails"}`, "application/json",
+			`{"jsonrpc":"2.0","id":1,"result":[{"a":1}],"error":{"code":-32000,"message":"failed"}}` + "\n",
+		},
+		{
+			"failure at once", ModeArray, `{"jsonrpc":"2.0","id":1,"method":"test_numbers","params":[-1]}`, "application/json",
+			`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"negative count"}}` + "\n",
+		},
+		{
+			"invalid params", ModeNDJSON, `{"jsonrpc":"2.0","id":1,"method":"test_numbers","params":["x"]}`, "application/json",
+			`{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"invalid params: expected a count"}}` + "\n",
+		},
+		{
+			"unknown mode", "xml", `{"jsonrpc":"2.0","id":1,"method":"test_numbers","params":[3]}`, "application/json",
+			`{"jsonrpc":"2.0","id":1,"error":{"code":-32600,"message":"unknown stream mode xml"}}` + "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		admitted, served = 0, 0
+		rec := serveStream(handler, tc.mode, tc.body)
+		require.Equal(t, tc.expected, rec.Body.String(), tc.name)
+		if tc.expected == "next" {
+			require.Equal(t, 1, served, tc.name)
+			continue
+		}
+		require.Equal(t, 0, served, tc.name)
+		require.Equal(t, tc.contentType, rec.Header().Get("Content-Type"), tc.name)
+		if tc.mode == ModeNDJSON || tc.mode == ModeArray {
+			require.Equal(t, 1, admitted, tc.name)
+		}
+	}
+}
+
+func TestHandlerFlush(t *testing.T) {
+	// the elements are flushed to the client once they exceed the flush size, the rest when the stream ends
+	handler := Handler(testMethods(), nil, http.NotFoundHandler())
+	rec := serveStream(handler, ModeNDJSON, `{"jsonrpc":"2.0","id":1,"method":"test_numbers","params":[100000]}`)
+	require.True(t, rec.Flushed)
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	require.Len(t, lines, 100001)
+	require.Equal(t, "99999", lines[99999])
+	require.Equal(t, `{"jsonrpc":"2.0","id":1,"result":{"count":100000}}`, lines[100000])
+}
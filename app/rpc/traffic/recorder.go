@@ -0,0 +1,168 @@
+package traffic
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/okex/exchain/app/rpc/readonly"
+)
+
+// Entry is a recorded json-rpc request with the response of the node
+type Entry struct {
+	Time   time.Time       `json:"time"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+	// Height is the latest height of the node when the request was served, the latest block param of the
+	// request is pinned to it on replay
+	Height   int64           `json:"height"`
+	Result   json.RawMessage `json:"result,omitempty"`
+	Error    json.RawMessage `json:"error,omitempty"`
+	Duration time.Duration   `json:"duration"`
+}
+
+var (
+	// unreplayedMethods change the state of the node or depend on the session or the mempool, they are neither
+	// recorded nor replayed
+	unreplayedMethods = map[string]bool{
+		"eth_newFilter":                   true,
+		"eth_newBlockFilter":              true,
+		"eth_newPendingTransactionFilter": true,
+		"eth_getFilterChanges":            true,
+		"eth_getFilterLogs":               true,
+		"eth_uninstallFilter":             true,
+		"eth_subscribe":                   true,
+		"eth_unsubscribe":                 true,
+	}
+	unreplayedPrefixes = []string{"admin_", "webhook_", "txpool_", "evm_", "hardhat_", "miner_"}
+)
+
+// Replayable tells whether the requests of the method can be replayed against another node
+func Replayable(method string) bool {
+	if readonly.Disabled(method) || unreplayedMethods[method] {
+		return false
+	}
+	for _, prefix := range unreplayedPrefixes {
+		if strings.HasPrefix(method, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// Recorder appends the replayable json-rpc requests served by the node and their responses to a jsonl file. The
+// batches aren't recorded.
+type Recorder struct {
+	latestHeight func() (int64, error)
+
+	mtx sync.Mutex
+	f   *os.File
+	w   *bufio.Writer
+}
+
+// NewRecorder creates a recorder appending to the file, the latest height is recorded with the requests
+func NewRecorder(path string, latestHeight func() (int64, error)) (*Recorder, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{latestHeight: latestHeight, f: f, w: bufio.NewWriter(f)}, nil
+}
+
+// Close flushes the recorded requests and closes the file
+func (r *Recorder) Close() error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if err := r.w.Flush(); err != nil {
+		return err
+	}
+	return r.f.Close()
+}
+
+// Handler wraps the json-rpc handler, recording its single requests and their responses
+func (r *Recorder) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			next.ServeHTTP(w, req)
+			return
+		}
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		var rpcReq request
+		if err := json.Unmarshal(body, &rpcReq); err != nil || !Replayable(rpcReq.Method) {
+			next.ServeHTTP(w, req)
+			return
+		}
+		height, err := r.latestHeight()
+		if err != nil {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		start := time.Now()
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, req)
+		var resp response
+		if rec.status != http.StatusOK || json.Unmarshal(rec.body.Bytes(), &resp) != nil {
+			return
+		}
+		r.record(Entry{
+			Time:     start,
+			Method:   rpcReq.Method,
+			Params:   rpcReq.Params,
+			Height:   height,
+			Result:   resp.Result,
+			Error:    resp.Error,
+			Duration: time.Since(start),
+		})
+	})
+}
+
+func (r *Recorder) record(entry Entry) {
+	bz, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	_, _ = r.w.Write(append(bz, '\n'))
+}
+
+type request struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+type response struct {
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  json.RawMessage `json:"error,omitempty"`
+}
+
+// recorder copies the response written to the client
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,232 @@
+package traffic
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// maxDiffs is the max number of differences reported per response
+const maxDiffs = 10
+
+// DefaultIgnoredMethods are the methods whose responses change over time whatever the block, they are replayed
+// but not diffed
+var DefaultIgnoredMethods = []string{
+	"eth_blockNumber", "eth_gasPrice", "eth_syncing", "net_peerCount", "net_peers", "web3_clientVersion",
+	"eth_protocolVersion", "exchain_getChainStatus",
+}
+
+// ReplayConfig is the node the recorded requests are replayed against
+type ReplayConfig struct {
+	// Target is the url of the json-rpc endpoint of the candidate node
+	Target string
+	// Reference is the url of a node the requests are replayed against as well, its responses are diffed instead
+	// of the recorded ones if it is set
+	Reference string
+	// IgnoredMethods are replayed, but their responses aren't diffed
+	IgnoredMethods []string
+	Timeout        time.Duration
+}
+
+// Mismatch is a replayed request whose response differs from the expected one
+type Mismatch struct {
+	Line   int      `json:"line"`
+	Method string   `json:"method"`
+	Height int64    `json:"height"`
+	Diffs  []string `json:"diffs"`
+}
+
+// Report is the outcome of a replay
+type Report struct {
+	Replayed   int            `json:"replayed"`
+	Matched    int            `json:"matched"`
+	Ignored    int            `json:"ignored"`
+	Skipped    int            `json:"skipped"`
+	Failed     int            `json:"failed"`
+	Mismatches []Mismatch     `json:"mismatches"`
+	ByMethod   map[string]int `json:"mismatches_by_method"`
+}
+
+// Replay replays the recorded entries of the reader against the target node and diffs its responses with the
+// recorded ones, or with the ones of the reference node. The latest block param of the requests is pinned to
+// the recorded height, so that the responses at the latest block are comparable, and the requests at the
+// pending block are skipped. Each mismatch is passed to onMismatch as it is found.
+func Replay(r io.Reader, cfg ReplayConfig, onMismatch func(Mismatch)) (*Report, error) {
+	client := &http.Client{Timeout: cfg.Timeout}
+	ignored := make(map[string]bool)
+	for _, method := range cfg.IgnoredMethods {
+		ignored[method] = true
+	}
+	report := &Report{ByMethod: make(map[string]int)}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return report, fmt.Errorf("invalid entry at line %d: %w", line, err)
+		}
+		params, ok := pinLatest(entry.Params, entry.Height)
+		if !ok || !Replayable(entry.Method) {
+			report.Skipped++
+			continue
+		}
+
+		got, err := call(client, cfg.Target, entry.Method, params)
+		if err != nil {
+			report.Failed++
+			continue
+		}
+		report.Replayed++
+		if ignored[entry.Method] {
+			report.Ignored++
+			continue
+		}
+		expected := response{Result: entry.Result, Error: entry.Error}
+		if cfg.Reference != "" {
+			if expected, err = call(client, cfg.Reference, entry.Method, params); err != nil {
+				report.Failed++
+				continue
+			}
+		}
+
+		diffs := diffResponses(expected, got)
+		if len(diffs) == 0 {
+			report.Matched++
+			continue
+		}
+		mismatch := Mismatch{Line: line, Method: entry.Method, Height: entry.Height, Diffs: diffs}
+		report.Mismatches = append(report.Mismatches, mismatch)
+		report.ByMethod[entry.Method]++
+		if onMismatch != nil {
+			onMismatch(mismatch)
+		}
+	}
+	return report, scanner.Err()
+}
+
+// pinLatest replaces the latest block param with the height, it returns false for the requests at the pending block
+func pinLatest(params json.RawMessage, height int64) (json.RawMessage, bool) {
+	if len(params) == 0 {
+		return params, true
+	}
+	var values []json.RawMessage
+	if err := json.Unmarshal(params, &values); err != nil {
+		return params, true
+	}
+	pinned := hexutil.EncodeUint64(uint64(height))
+	for i, value := range values {
+		switch strings.TrimSpace(string(value)) {
+		case `"pending"`:
+			return nil, false
+		case `"latest"`:
+			values[i] = json.RawMessage(`"` + pinned + `"`)
+		}
+	}
+	bz, err := json.Marshal(values)
+	if err != nil {
+		return params, true
+	}
+	return bz, true
+}
+
+func call(client *http.Client, url, method string, params json.RawMessage) (response, error) {
+	if len(params) == 0 {
+		params = json.RawMessage("[]")
+	}
+	body, err := json.Marshal(struct {
+		Version string          `json:"jsonrpc"`
+		ID      int             `json:"id"`
+		Method  string          `json:"method"`
+		Params  json.RawMessage `json:"params"`
+	}{"2.0", 1, method, params})
+	if err != nil {
+		return response{}, err
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return response{}, err
+	}
+	defer resp.Body.Close()
+	var res response
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return response{}, fmt.Errorf("invalid response of %s with status %d: %w", method, resp.StatusCode, err)
+	}
+	return res, nil
+}
+
+// diffResponses returns the paths where the responses differ, the errors are compared by their code only as
+// the messages of the releases may differ
+func diffResponses(expected, got response) []string {
+	if len(expected.Error) > 0 || len(got.Error) > 0 {
+		var expectedErr, gotErr struct {
+			Code int `json:"code"`
+		}
+		_ = json.Unmarshal(expected.Error, &expectedErr)
+		_ = json.Unmarshal(got.Error, &gotErr)
+		if len(expected.Error) == 0 || len(got.Error) == 0 || expectedErr.Code != gotErr.Code {
+			return []string{fmt.Sprintf("error: expected %s, got %s", orNull(expected.Error), orNull(got.Error))}
+		}
+		return nil
+	}
+	var a, b interface{}
+	_ = json.Unmarshal(orNull(expected.Result), &a)
+	_ = json.Unmarshal(orNull(got.Result), &b)
+	var diffs []string
+	diff("result", a, b, &diffs)
+	return diffs
+}
+
+func diff(path string, a, b interface{}, diffs *[]string) {
+	if len(*diffs) >= maxDiffs || reflect.DeepEqual(a, b) {
+		return
+	}
+	switch av := a.(type) {
+	case map[string]interface{}:
+		if bv, ok := b.(map[string]interface{}); ok {
+			keys := make([]string, 0, len(av)+len(bv))
+			for key := range av {
+				keys = append(keys, key)
+			}
+			for key := range bv {
+				if _, ok := av[key]; !ok {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				diff(path+"."+key, av[key], bv[key], diffs)
+			}
+			return
+		}
+	case []interface{}:
+		if bv, ok := b.([]interface{}); ok && len(av) == len(bv) {
+			for i := range av {
+				diff(fmt.Sprintf("%s[%d]", path, i), av[i], bv[i], diffs)
+			}
+			return
+		}
+	}
+	*diffs = append(*diffs, fmt.Sprintf("%s: expected %s, got %s", path, marshal(a), marshal(b)))
+}
+
+func marshal(v interface{}) string {
+	bz, _ := json.Marshal(v)
+	return string(bz)
+}
+
+func orNull(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return json.RawMessage("null")
+	}
+	return raw
+}
@@ -0,0 +1,89 @@
+package traffic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// node answers eth_getBalance and eth_blockNumber with its release
+func node(release string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req request
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch req.Method {
+		case "eth_getBalance":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":{"balance":"%s"}}`, req.ID, release)
+		case "eth_blockNumber":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"%s"}`, req.ID, release)
+		default:
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":-32601,"message":"%s"}}`, req.ID, release)
+		}
+	})
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traffic.jsonl")
+	recorder, err := NewRecorder(path, func() (int64, error) { return 16, nil })
+	require.NoError(t, err)
+	handler := recorder.Handler(node("v1"))
+	for _, body := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"eth_getBalance","params":["0x01","latest"]}`,
+		`{"jsonrpc":"2.0","id":2,"method":"eth_blockNumber","params":[]}`,
+		`{"jsonrpc":"2.0","id":3,"method":"eth_unknown","params":[]}`,
+		`{"jsonrpc":"2.0","id":4,"method":"eth_sendRawTransaction","params":["0x01"]}`,
+		`[{"jsonrpc":"2.0","id":5,"method":"eth_blockNumber","params":[]}]`,
+	} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	}
+	require.NoError(t, recorder.Close())
+
+	bz, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, 3, bytes.Count(bz, []byte("\n")))
+
+	// the same release matches, the latest block being pinned to the recorded height
+	same := httptest.NewServer(node("v1"))
+	defer same.Close()
+	report, err := Replay(bytes.NewReader(bz), ReplayConfig{Target: same.URL, IgnoredMethods: DefaultIgnoredMethods}, nil)
+	require.NoError(t, err)
+	require.Equal(t, 3, report.Replayed)
+	require.Equal(t, 2, report.Matched)
+	require.Equal(t, 1, report.Ignored)
+	require.Empty(t, report.Mismatches)
+
+	// the balances of another release differ, its error messages don't matter
+	other := httptest.NewServer(node("v2"))
+	defer other.Close()
+	var found []Mismatch
+	report, err = Replay(bytes.NewReader(bz), ReplayConfig{Target: other.URL, IgnoredMethods: DefaultIgnoredMethods}, func(m Mismatch) {
+		found = append(found, m)
+	})
+	require.NoError(t, err)
+	require.Len(t, report.Mismatches, 1)
+	require.Equal(t, found, report.Mismatches)
+	require.Equal(t, "eth_getBalance", found[0].Method)
+	require.Equal(t, []string{`result.balance: expected "v1", got "v2"`}, found[0].Diffs)
+
+	// diffed against a reference node
+	report, err = Replay(bytes.NewReader(bz), ReplayConfig{Target: other.URL, Reference: same.URL}, nil)
+	require.NoError(t, err)
+	require.Len(t, report.Mismatches, 2)
+}
+
+func TestPinLatest(t *testing.T) {
+	params, ok := pinLatest(json.RawMessage(`["0x01", "latest"]`), 16)
+	require.True(t, ok)
+	require.JSONEq(t, `["0x01","0x10"]`, string(params))
+
+	_, ok = pinLatest(json.RawMessage(`["0x01","pending"]`), 16)
+	require.False(t, ok)
+}
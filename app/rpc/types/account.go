@@ -0,0 +1,27 @@
+package types
+
+import (
+	"math/big"
+	"time"
+
+	apptypes "github.com/okex/exchain/app/types"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	authexported "github.com/okex/exchain/libs/cosmos-sdk/x/auth/exported"
+	vestingexported "github.com/okex/exchain/libs/cosmos-sdk/x/auth/vesting/exported"
+)
+
+// AccountBalance returns the balance in wei of an account of any type, as served by the eth endpoints. The
+// vesting accounts report the coins they can spend at the block time, the other accounts all their coins.
+func AccountBalance(acc authexported.Account, blockTime time.Time) *big.Int {
+	coins := acc.GetCoins()
+	if _, ok := acc.(vestingexported.VestingAccount); ok {
+		coins = acc.SpendableCoins(blockTime)
+	}
+	return apptypes.DecToWei(coins.AmountOf(sdk.DefaultBondDenom))
+}
+
+// IsVestingAccount returns true if the balance of the account depends on the block time
+func IsVestingAccount(acc authexported.Account) bool {
+	_, ok := acc.(vestingexported.VestingAccount)
+	return ok
+}
@@ -0,0 +1,38 @@
+package types
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	authtypes "github.com/okex/exchain/libs/cosmos-sdk/x/auth/types"
+	vestingtypes "github.com/okex/exchain/libs/cosmos-sdk/x/auth/vesting/types"
+	"github.com/okex/exchain/libs/cosmos-sdk/x/supply"
+)
+
+func TestAccountBalance(t *testing.T) {
+	oneOKT := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	coins := func(amount int64) sdk.Coins {
+		return sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, amount))
+	}
+
+	// the module accounts have all their coins in wei
+	moduleAcc := supply.NewEmptyModuleAccount("fee_collector")
+	require.Equal(t, big.NewInt(0), AccountBalance(moduleAcc, time.Now()))
+	require.NoError(t, moduleAcc.SetCoins(coins(2)))
+	require.Equal(t, new(big.Int).Mul(oneOKT, big.NewInt(2)), AccountBalance(moduleAcc, time.Now()))
+	require.False(t, IsVestingAccount(moduleAcc))
+
+	// the vesting accounts have their spendable coins at the block time
+	baseAcc := authtypes.NewBaseAccountWithAddress(sdk.AccAddress([]byte("vesting_account_addr")))
+	require.NoError(t, baseAcc.SetCoins(coins(3)))
+	endTime := time.Unix(2000, 0)
+	vestingAcc := vestingtypes.NewDelayedVestingAccount(&baseAcc, endTime.Unix())
+	require.NoError(t, vestingAcc.SetCoins(coins(5)))
+	require.True(t, IsVestingAccount(vestingAcc))
+	require.Equal(t, new(big.Int).Mul(oneOKT, big.NewInt(2)), AccountBalance(vestingAcc, endTime.Add(-time.Second)))
+	require.Equal(t, new(big.Int).Mul(oneOKT, big.NewInt(5)), AccountBalance(vestingAcc, endTime))
+}
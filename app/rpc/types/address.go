@@ -0,0 +1,94 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+)
+
+// Address is an address param of the rpc methods. It's decoded from the hex address, or from the bech32
+// address of the chain, as the users often paste the ex addresses into the ethereum rpc calls.
+type Address struct {
+	common.Address
+}
+
+// UnmarshalJSON decodes the hex or the bech32 address
+func (a *Address) UnmarshalJSON(input []byte) error {
+	var s string
+	if err := json.Unmarshal(input, &s); err != nil {
+		return fmt.Errorf("invalid address %s: must be a string", string(input))
+	}
+	addr, err := ParseAddress(s)
+	if err != nil {
+		return err
+	}
+	a.Address = addr
+	return nil
+}
+
+// ParseAddress returns the address of the 0x prefixed hex address or of the bech32 address
+func ParseAddress(s string) (common.Address, error) {
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		if !common.IsHexAddress(s) {
+			return common.Address{}, fmt.Errorf("invalid address %s: the hex address must be 20 bytes", s)
+		}
+		if err := checkChecksum(s); err != nil {
+			return common.Address{}, err
+		}
+		return common.HexToAddress(s), nil
+	}
+	accAddr, err := sdk.AccAddressFromBech32(s)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid address %s: must be a 0x hex or an %s bech32 address",
+			s, sdk.GetConfig().GetBech32AccountAddrPrefix())
+	}
+	if len(accAddr) != common.AddressLength {
+		return common.Address{}, fmt.Errorf("invalid address %s: must be 20 bytes", s)
+	}
+	return common.BytesToAddress(accAddr), nil
+}
+
+// addressPtr returns the address of the optional param
+func addressPtr(a *Address) *common.Address {
+	if a == nil {
+		return nil
+	}
+	addr := a.Address
+	return &addr
+}
+
+// UnmarshalJSON decodes the args, the from and to addresses can be hex or bech32 addresses
+func (ca *SendTxArgs) UnmarshalJSON(input []byte) error {
+	type sendTxArgs SendTxArgs
+	var args struct {
+		sendTxArgs
+		From *Address `json:"from"`
+		To   *Address `json:"to"`
+	}
+	if err := json.Unmarshal(input, &args); err != nil {
+		return err
+	}
+	*ca = SendTxArgs(args.sendTxArgs)
+	ca.From, ca.To = addressPtr(args.From), addressPtr(args.To)
+	return nil
+}
+
+// UnmarshalJSON decodes the args, the from and to addresses can be hex or bech32 addresses
+func (ca *CallArgs) UnmarshalJSON(input []byte) error {
+	type callArgs CallArgs
+	var args struct {
+		callArgs
+		From *Address `json:"from"`
+		To   *Address `json:"to"`
+	}
+	if err := json.Unmarshal(input, &args); err != nil {
+		return err
+	}
+	*ca = CallArgs(args.callArgs)
+	ca.From, ca.To = addressPtr(args.From), addressPtr(args.To)
+	return nil
+}
@@ -0,0 +1,109 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	apptypes "github.com/okex/exchain/app/types"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+)
+
+func TestAddressUnmarshalJSON(t *testing.T) {
+	apptypes.SetBech32Prefixes(sdk.GetConfig())
+	hexAddr := common.HexToAddress("0x0073F2E28ef8F117e53d858094086Defaf1837D5")
+	bech32Addr := sdk.AccAddress(hexAddr.Bytes()).String()
+
+	tests := []struct {
+		input      string
+		expectPass bool
+	}{
+		{`"0x0073F2E28ef8F117e53d858094086Defaf1837D5"`, true},
+		{`"0x0073f2e28ef8f117e53d858094086defaf1837d5"`, true},
+		{`"` + bech32Addr + `"`, true},
+		{`"0x0073F2E28ef8F117e53d858094086Defaf1837D"`, false},
+		{`"` + bech32Addr + `_"`, false},
+		{`"cosmos1qqsl9c5wlrc30efaskqfgzrdm7h3sd74s9vk6a"`, false},
+		{`1`, false},
+	}
+	for _, tc := range tests {
+		var addr Address
+		err := json.Unmarshal([]byte(tc.input), &addr)
+		if tc.expectPass {
+			require.NoError(t, err, tc.input)
+			require.Equal(t, hexAddr, addr.Address, tc.input)
+		} else {
+			require.Error(t, err, tc.input)
+		}
+	}
+}
+
+func TestArgsUnmarshalJSON(t *testing.T) {
+	apptypes.SetBech32Prefixes(sdk.GetConfig())
+	from := common.HexToAddress("0x01")
+	to := common.HexToAddress("0x02")
+
+	var callArgs CallArgs
+	input := `{"from":"` + sdk.AccAddress(from.Bytes()).String() + `","to":"` + to.Hex() + `","gas":"0x5208","data":"0x01"}`
+	require.NoError(t, json.Unmarshal([]byte(input), &callArgs))
+	require.Equal(t, from, *callArgs.From)
+	require.Equal(t, to, *callArgs.To)
+	require.Equal(t, uint64(21000), uint64(*callArgs.Gas))
+	require.Equal(t, []byte{0x01}, []byte(*callArgs.Data))
+
+	// the contract creations have no to address
+	var sendTxArgs SendTxArgs
+	input = `{"from":"` + sdk.AccAddress(from.Bytes()).String() + `","to":null,"input":"0x01"}`
+	require.NoError(t, json.Unmarshal([]byte(input), &sendTxArgs))
+	require.Equal(t, from, *sendTxArgs.From)
+	require.Nil(t, sendTxArgs.To)
+	require.Equal(t, []byte{0x01}, []byte(*sendTxArgs.Input))
+
+	require.Error(t, json.Unmarshal([]byte(`{"to":"ex1invalid"}`), &sendTxArgs))
+}
+
+func TestAddressChecksum(t *testing.T) {
+	defer SetStrictInput(false)
+	for _, strict := range []bool{false, true} {
+		SetStrictInput(strict)
+		for input, valid := range map[string]bool{
+			"0x0073F2E28ef8F117e53d858094086Defaf1837D5": true,
+			"0x0073f2e28ef8f117e53d858094086defaf1837d5": true,
+			"0x0073F2E28EF8F117E53D858094086DEFAF1837D5": true,
+			"0x0073f2E28ef8F117e53d858094086Defaf1837D5": !strict,
+			"0X0073F2E28ef8F117e53d858094086Defaf1837D5": !strict,
+		} {
+			_, err := ParseAddress(input)
+			require.Equal(t, valid, err == nil, "%s strict %v: %v", input, strict, err)
+		}
+	}
+}
+
+func TestNormalizeQuantity(t *testing.T) {
+	defer SetStrictInput(false)
+	tests := []struct {
+		input      string
+		normalized string
+		strict     bool // valid in strict mode
+	}{
+		{"0x1f", "0x1f", true},
+		{"0x0", "0x0", true},
+		{"0x01F", "0x1f", false},
+		{"0X00", "0x0", false},
+		{"0x", "", false},
+		{"1f", "", false},
+		{"0xzz", "", false},
+	}
+	for _, tc := range tests {
+		SetStrictInput(false)
+		normalized, err := NormalizeQuantity(tc.input)
+		require.Equal(t, tc.normalized, normalized, tc.input)
+		require.Equal(t, tc.normalized != "", err == nil, tc.input)
+
+		SetStrictInput(true)
+		_, err = NormalizeQuantity(tc.input)
+		require.Equal(t, tc.strict, err == nil, tc.input)
+	}
+}
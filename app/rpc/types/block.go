@@ -58,6 +58,10 @@ func (bn *BlockNumber) UnmarshalJSON(data []byte) error {
 		return nil
 	}
 
+	input, err := NormalizeQuantity(input)
+	if err != nil {
+		return err
+	}
 	blckNum, err := hexutil.DecodeUint64(input)
 	if err != nil {
 		return err
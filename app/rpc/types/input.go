@@ -0,0 +1,63 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// strictInput rejects the hex addresses with an invalid EIP-55 checksum and the quantities which aren't in the
+// canonical form of the json-rpc spec, instead of accepting them
+var strictInput bool
+
+// SetStrictInput sets the strict mode of the address and the quantity params, before the rpc server starts
+func SetStrictInput(strict bool) {
+	strictInput = strict
+}
+
+// checkChecksum checks the EIP-55 checksum of the mixed-case hex addresses in strict mode, the addresses in a
+// single case have no checksum
+func checkChecksum(s string) error {
+	if !strictInput {
+		return nil
+	}
+	if !strings.HasPrefix(s, "0x") {
+		return fmt.Errorf("invalid address %s: the hex address must be 0x prefixed", s)
+	}
+	hex := s[2:]
+	if hex == strings.ToLower(hex) || hex == strings.ToUpper(hex) {
+		return nil
+	}
+	if checksummed := common.HexToAddress(s).Hex(); checksummed != s {
+		return fmt.Errorf("invalid address %s: bad EIP-55 checksum, expected %s", s, checksummed)
+	}
+	return nil
+}
+
+// NormalizeQuantity returns the canonical form of the hex quantity, 0x prefixed without leading zeros, such as
+// 0x1 for 0X01. In strict mode the quantities which aren't canonical are rejected instead.
+func NormalizeQuantity(s string) (string, error) {
+	if len(s) < 2 || s[0] != '0' || (s[1] != 'x' && s[1] != 'X') {
+		return "", fmt.Errorf("invalid quantity %s: must be 0x prefixed", s)
+	}
+	digits := strings.ToLower(s[2:])
+	if digits == "" {
+		return "", fmt.Errorf("invalid quantity %s: no digits", s)
+	}
+	for _, c := range digits {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return "", fmt.Errorf("invalid quantity %s: not a hex number", s)
+		}
+	}
+	if trimmed := strings.TrimLeft(digits, "0"); trimmed != "" {
+		digits = trimmed
+	} else {
+		digits = "0"
+	}
+	normalized := "0x" + digits
+	if strictInput && normalized != s {
+		return "", fmt.Errorf("invalid quantity %s: must be in the canonical form %s", s, normalized)
+	}
+	return normalized, nil
+}
@@ -158,4 +158,5 @@ type EthHeaderWithBlockHash struct {
 	MixDigest   common.Hash         `json:"mixHash"`
 	Nonce       ethtypes.BlockNonce `json:"nonce"`
 	Hash        common.Hash         `json:"hash"`
+	BaseFee     *hexutil.Big        `json:"baseFeePerGas,omitempty"`
 }
@@ -2,16 +2,19 @@ package types
 
 import (
 	"bytes"
-	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
 	"reflect"
 
+	"github.com/okex/exchain/app/config"
+	ethermint "github.com/okex/exchain/app/types"
 	clientcontext "github.com/okex/exchain/libs/cosmos-sdk/client/context"
 	"github.com/okex/exchain/libs/cosmos-sdk/codec"
 	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
 	sdkerrors "github.com/okex/exchain/libs/cosmos-sdk/types/errors"
+	authclient "github.com/okex/exchain/libs/cosmos-sdk/x/auth/client/utils"
 	authtypes "github.com/okex/exchain/libs/cosmos-sdk/x/auth/types"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -27,8 +30,26 @@ var (
 	defaultGasLimit   = hexutil.Uint64(int64(^uint32(0)))
 	defaultGasUsed    = hexutil.Uint64(0)
 	defaultDifficulty = (*hexutil.Big)(big.NewInt(0))
+
+	// offset in seconds of the evm time of a block from its tendermint time, only set by the dev mode moving the
+	// chain time
+	blockTimeOffset func(height int64) int64
 )
 
+// SetBlockTimeOffset sets the offset in seconds of the evm time of the blocks from their tendermint time
+func SetBlockTimeOffset(offset func(height int64) int64) {
+	blockTimeOffset = offset
+}
+
+// blockTimestamp returns the timestamp of the block seen by the evm
+func blockTimestamp(header tmtypes.Header) uint64 {
+	timestamp := header.Time.Unix()
+	if blockTimeOffset != nil {
+		timestamp += blockTimeOffset(header.Height)
+	}
+	return uint64(timestamp)
+}
+
 // RawTxToEthTx returns a evm MsgEthereum transaction from raw tx bytes.
 func RawTxToEthTx(clientCtx clientcontext.CLIContext, bz []byte) (*evmtypes.MsgEthereumTx, error) {
 	tx, err := evmtypes.TxDecoder(clientCtx.Codec)(bz)
@@ -79,7 +100,7 @@ func NewTransaction(tx *evmtypes.MsgEthereumTx, txHash, blockHash common.Hash, b
 // EthBlockFromTendermint returns a JSON-RPC compatible Ethereum blockfrom a given Tendermint block.
 func EthBlockFromTendermint(clientCtx clientcontext.CLIContext, block *tmtypes.Block, fullTx bool) (map[string]interface{}, error) {
 	var blockTxs interface{}
-	gasLimit, err := BlockMaxGasFromConsensusParams(context.Background(), clientCtx)
+	gasLimit, err := BlockGasLimitAtHeight(clientCtx, block.Height)
 	if err != nil {
 		return nil, err
 	}
@@ -98,13 +119,144 @@ func EthBlockFromTendermint(clientCtx clientcontext.CLIContext, block *tmtypes.B
 	clientCtx.Codec.MustUnmarshalJSON(res, &bloomRes)
 
 	bloom := bloomRes.Bloom
+
+	baseFee := BaseFeeAtHeight(clientCtx, block.Height)
+
 	if fullTx {
 		blockTxs = ethTxs
 	} else {
 		blockTxs = transactions
 	}
 
-	return FormatBlock(block.Header, block.Size(), block.Hash(), gasLimit, gasUsed, blockTxs, bloom), nil
+	return FormatBlock(block.Header, block.Size(), block.Hash(), gasLimit, gasUsed, blockTxs, bloom, baseFee), nil
+}
+
+// BaseFeeAtHeight returns the base fee per gas at the given height, or nil if London is not activated there.
+// A non-positive height queries the latest state. As the base fee is optional in a block, nil is also
+// returned when the state at that height can't be queried anymore (e.g. it's pruned).
+func BaseFeeAtHeight(clientCtx clientcontext.CLIContext, height int64) *big.Int {
+	if height > 0 {
+		clientCtx = clientCtx.WithHeight(height)
+	}
+	res, _, err := clientCtx.Query(fmt.Sprintf("custom/%s/%s", evmtypes.ModuleName, evmtypes.QueryBaseFee))
+	if err != nil {
+		return nil
+	}
+
+	var baseFeeRes evmtypes.QueryResBaseFee
+	if err := clientCtx.Codec.UnmarshalJSON(res, &baseFeeRes); err != nil {
+		return nil
+	}
+	return (*big.Int)(baseFeeRes.BaseFee)
+}
+
+// SimulateBundle simulates the bundle on top of the state of the block at the given height, or of the latest
+// block for a non-positive height. Unless they are overridden, the number and the time of the block seen by
+// the txs are the ones of that block.
+func SimulateBundle(clientCtx clientcontext.CLIContext, req evmtypes.SimulateBundleRequest, height int64) ([]evmtypes.BundleTxResult, error) {
+	var heightPtr *int64
+	if height > 0 {
+		heightPtr = &height
+	}
+	resBlock, err := clientCtx.Client.Block(heightPtr)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := evmtypes.BlockOverrides{}
+	if req.BlockOverrides != nil {
+		overrides = *req.BlockOverrides
+	}
+	if overrides.Number == nil {
+		overrides.Number = (*hexutil.Big)(big.NewInt(resBlock.Block.Height))
+	}
+	if overrides.Time == nil {
+		timestamp := hexutil.Uint64(blockTimestamp(resBlock.Block.Header))
+		overrides.Time = &timestamp
+	}
+	req.BlockOverrides = &overrides
+
+	bz, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	res, _, err := clientCtx.WithHeight(resBlock.Block.Height).QueryWithData(
+		fmt.Sprintf("custom/%s/%s", evmtypes.ModuleName, evmtypes.QuerySimulateBundle), bz)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []evmtypes.BundleTxResult
+	if err := json.Unmarshal(res, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// NewCallMsg converts the call args into the msg simulating the call, with the given nonce of the sender. The gas
+// is DefaultRPCGasLimit when unset and is capped by gasCap if not nil, the gas price is DefaultGasPrice when unset.
+func NewCallMsg(args CallArgs, nonce uint64, gasCap *big.Int) evmtypes.MsgEthermint {
+	var from common.Address
+	if args.From != nil {
+		from = *args.From
+	}
+
+	gas := uint64(ethermint.DefaultRPCGasLimit)
+	if args.Gas != nil {
+		gas = uint64(*args.Gas)
+	}
+	if gasCap != nil && gasCap.Uint64() < gas {
+		gas = gasCap.Uint64()
+	}
+	gasPrice := new(big.Int).SetUint64(ethermint.DefaultGasPrice)
+	if args.GasPrice != nil {
+		gasPrice = args.GasPrice.ToInt()
+	}
+	value := new(big.Int)
+	if args.Value != nil {
+		value = args.Value.ToInt()
+	}
+	var data []byte
+	if args.Data != nil {
+		data = *args.Data
+	}
+	var to *sdk.AccAddress
+	if args.To != nil {
+		addr := sdk.AccAddress(args.To.Bytes())
+		to = &addr
+	}
+
+	return evmtypes.NewMsgEthermint(nonce, to, sdk.NewIntFromBigInt(value), gas, sdk.NewIntFromBigInt(gasPrice), data,
+		sdk.AccAddress(from.Bytes()))
+}
+
+// SimulateMsgs simulates a tx of the msgs, which needs no signature, on top of the state of the height of the
+// client context
+func SimulateMsgs(clientCtx clientcontext.CLIContext, msgs []sdk.Msg) (*sdk.SimulationResponse, error) {
+	tx := authtypes.NewStdTx(msgs, authtypes.StdFee{}, []authtypes.StdSignature{{}}, "")
+	if err := tx.ValidateBasic(); err != nil {
+		return nil, err
+	}
+	txBytes, err := authclient.GetTxEncoder(clientCtx.Codec)(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, _, err := clientCtx.QueryWithData("app/simulate", txBytes)
+	if err != nil {
+		return nil, err
+	}
+	var simResponse sdk.SimulationResponse
+	if err := clientCtx.Codec.UnmarshalBinaryBare(res, &simResponse); err != nil {
+		return nil, err
+	}
+	return &simResponse, nil
+}
+
+// EstimatedGas returns the gas estimated by eth_estimateGas for the gas used by a simulated call, with the gas
+// limit buffer on top of it
+func EstimatedGas(gasUsed uint64) uint64 {
+	return gasUsed + gasUsed/100*config.GetOecConfig().GetGasLimitBuffer()
 }
 
 // EthHeaderFromTendermint is an util function that returns an Ethereum Header
@@ -119,7 +271,7 @@ func EthHeaderFromTendermint(header tmtypes.Header) *ethtypes.Header {
 		ReceiptHash: ethtypes.EmptyRootHash,
 		Difficulty:  nil,
 		Number:      big.NewInt(header.Height),
-		Time:        uint64(header.Time.Unix()),
+		Time:        blockTimestamp(header),
 		Extra:       nil,
 		MixDigest:   common.Hash{},
 		Nonce:       ethtypes.BlockNonce{},
@@ -153,31 +305,30 @@ func EthTransactionsFromTendermint(clientCtx clientcontext.CLIContext, txs []tmt
 	return transactionHashes, gasUsed, transactions, nil
 }
 
-// BlockMaxGasFromConsensusParams returns the gas limit for the latest block from the chain consensus params.
-func BlockMaxGasFromConsensusParams(_ context.Context, clientCtx clientcontext.CLIContext) (int64, error) {
-	//resConsParams, err := clientCtx.Client.ConsensusParams(nil)
-	//if err != nil {
-	//	return 0, err
-	//}
-	//
-	//gasLimit := resConsParams.ConsensusParams.Block.MaxGas
-	//if gasLimit == -1 {
-	//	// Sets gas limit to max uint32 to not error with javascript dev tooling
-	//	// This -1 value indicating no block gas limit is set to max uint64 with geth hexutils
-	//	// which errors certain javascript dev tooling which only supports up to 53 bits
-	//	gasLimit = int64(^uint32(0))
-	//}
-	//
-	//return gasLimit, nil
-
-	return int64(^uint32(0)), nil
+// BlockGasLimitAtHeight returns the gas limit of the block at the given height, or of the latest block for a
+// non-positive height. Like the blocks of the watcher, it's the block gas limit of the evm params, which the
+// baseapp enforces.
+func BlockGasLimitAtHeight(clientCtx clientcontext.CLIContext, height int64) (int64, error) {
+	if height > 0 {
+		clientCtx = clientCtx.WithHeight(height)
+	}
+	res, _, err := clientCtx.Query(fmt.Sprintf("custom/%s/%s", evmtypes.ModuleName, evmtypes.QueryParameters))
+	if err != nil {
+		return 0, err
+	}
+
+	var params evmtypes.Params
+	if err := clientCtx.Codec.UnmarshalJSON(res, &params); err != nil {
+		return 0, err
+	}
+	return int64(params.BlockGasLimit()), nil
 }
 
 // FormatBlock creates an ethereum block from a tendermint header and ethereum-formatted
 // transactions.
 func FormatBlock(
 	header tmtypes.Header, size int, curBlockHash tmbytes.HexBytes, gasLimit int64,
-	gasUsed *big.Int, transactions interface{}, bloom ethtypes.Bloom,
+	gasUsed *big.Int, transactions interface{}, bloom ethtypes.Bloom, baseFee *big.Int,
 ) map[string]interface{} {
 	if len(header.DataHash) == 0 {
 		header.DataHash = tmbytes.HexBytes(common.Hash{}.Bytes())
@@ -195,7 +346,7 @@ func FormatBlock(
 		"logsBloom":        bloom,
 		"transactionsRoot": hexutil.Bytes(header.DataHash),
 		"stateRoot":        hexutil.Bytes(header.AppHash),
-		"miner":            common.BytesToAddress(header.ProposerAddress),
+		"miner":            common.BytesToAddress(header.ProposerAddress), // the fee recipient is only reported by the watcher
 		"mixHash":          common.Hash{},
 		"difficulty":       hexutil.Uint64(0),
 		"totalDifficulty":  hexutil.Uint64(0),
@@ -203,10 +354,13 @@ func FormatBlock(
 		"size":             hexutil.Uint64(size),
 		"gasLimit":         hexutil.Uint64(gasLimit), // Static gas limit
 		"gasUsed":          (*hexutil.Big)(gasUsed),
-		"timestamp":        hexutil.Uint64(header.Time.Unix()),
+		"timestamp":        hexutil.Uint64(blockTimestamp(header)),
 		"uncles":           []common.Hash{},
 		"receiptsRoot":     ethtypes.EmptyRootHash,
 	}
+	if baseFee != nil {
+		ret["baseFeePerGas"] = (*hexutil.Big)(baseFee)
+	}
 	if !reflect.ValueOf(transactions).IsNil() {
 		switch transactions.(type) {
 		case []common.Hash:
@@ -238,7 +392,7 @@ func GetBlockCumulativeGas(cdc *codec.Codec, block *tmtypes.Block, idx int) uint
 	txDecoder := evmtypes.TxDecoder(cdc)
 
 	for i := 0; i < idx && i < len(block.Txs); i++ {
-		txi, err := txDecoder(block.Txs[i])
+		txi, err := txDecoder(block.Txs[i], block.Height)
 		if err != nil {
 			continue
 		}
@@ -271,7 +425,7 @@ func EthHeaderWithBlockHashFromTendermint(tmHeader *tmtypes.Header) (header *Eth
 		Difficulty: defaultDifficulty,
 		GasLimit:   defaultGasLimit,
 		GasUsed:    defaultGasUsed,
-		Time:       hexutil.Uint64(tmHeader.Time.Unix()),
+		Time:       hexutil.Uint64(blockTimestamp(*tmHeader)),
 		Hash:       common.BytesToHash(tmHeader.Hash()),
 	}
 
@@ -0,0 +1,157 @@
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// compileTimeout bounds the run of the compiler on a verification
+	compileTimeout = time.Minute
+	// maxCompilerOutput bounds the standard json output read from the compiler
+	maxCompilerOutput = 64 << 20
+)
+
+// compilerVersionRegexp matches the versions of solc, e.g. 0.8.17 or v0.8.17+commit.8df45f5f, which are part of
+// the path of their binary
+var compilerVersionRegexp = regexp.MustCompile(`^v?\d+\.\d+\.\d+(\+commit\.[0-9a-f]+)?$`)
+
+// outputSelection is the output of the compiler the bytecode of the contracts is matched with
+var outputSelection = json.RawMessage(`{"*":{"*":["abi","evm.deployedBytecode.object","evm.deployedBytecode.linkReferences","evm.deployedBytecode.immutableReferences"]}}`)
+
+// offset is the position of a linked library address or of an immutable in a bytecode
+type offset struct {
+	Start  int `json:"start"`
+	Length int `json:"length"`
+}
+
+// compiledContract is a contract of the standard json output of solc
+type compiledContract struct {
+	ABI json.RawMessage `json:"abi"`
+	EVM struct {
+		DeployedBytecode struct {
+			Object              string                         `json:"object"`
+			LinkReferences      map[string]map[string][]offset `json:"linkReferences"`
+			ImmutableReferences map[string][]offset            `json:"immutableReferences"`
+		} `json:"deployedBytecode"`
+	} `json:"evm"`
+}
+
+type compilerOutput struct {
+	Errors []struct {
+		Severity         string `json:"severity"`
+		FormattedMessage string `json:"formattedMessage"`
+	} `json:"errors"`
+	Contracts map[string]map[string]compiledContract `json:"contracts"`
+}
+
+// compile compiles the sources with the solc binary of the version in the solc dir, named solc-<version>. The
+// compiler runs in an empty temporary dir without environment, so that the sources can only import each other,
+// and is killed after the compile timeout. It isn't isolated otherwise: it runs as the user of the node, without
+// limits on its memory or on the files it can read. The output selection of the settings is replaced.
+func compile(solcDir, version string, sources map[string]string, settings json.RawMessage) (*compilerOutput, error) {
+	if !compilerVersionRegexp.MatchString(version) {
+		return nil, fmt.Errorf("invalid compiler version %s", version)
+	}
+	solc := filepath.Join(solcDir, "solc-"+strings.TrimPrefix(version, "v"))
+	if _, err := os.Stat(solc); err != nil {
+		return nil, fmt.Errorf("compiler version %s isn't available", version)
+	}
+
+	input, err := standardJSONInput(sources, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	workDir, err := ioutil.TempDir("", "verifier")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(workDir)
+
+	ctx, cancel := context.WithTimeout(context.Background(), compileTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, solc, "--standard-json")
+	cmd.Dir = workDir
+	cmd.Env = []string{}
+	cmd.Stdin = bytes.NewReader(input)
+	stdout := &limitedBuffer{max: maxCompilerOutput}
+	cmd.Stdout = stdout
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("compilation exceeded %s", compileTimeout)
+		}
+		if stdout.exceeded {
+			return nil, errors.New("compiler output exceeds the max size")
+		}
+		return nil, fmt.Errorf("compiler failed: %w", err)
+	}
+
+	var output compilerOutput
+	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		return nil, fmt.Errorf("invalid compiler output: %w", err)
+	}
+	var errs []string
+	for _, e := range output.Errors {
+		if e.Severity == "error" {
+			errs = append(errs, strings.TrimSpace(e.FormattedMessage))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("compilation failed: %s", strings.Join(errs, "; "))
+	}
+	return &output, nil
+}
+
+// standardJSONInput returns the standard json input of solc compiling the sources with the settings
+func standardJSONInput(sources map[string]string, settings json.RawMessage) ([]byte, error) {
+	fields := make(map[string]json.RawMessage)
+	if len(settings) > 0 && string(settings) != "null" {
+		if err := json.Unmarshal(settings, &fields); err != nil {
+			return nil, fmt.Errorf("invalid compiler settings: %w", err)
+		}
+	}
+	fields["outputSelection"] = outputSelection
+
+	type source struct {
+		Content string `json:"content"`
+	}
+	input := struct {
+		Language string                     `json:"language"`
+		Sources  map[string]source          `json:"sources"`
+		Settings map[string]json.RawMessage `json:"settings"`
+	}{
+		Language: "Solidity",
+		Sources:  make(map[string]source, len(sources)),
+		Settings: fields,
+	}
+	for path, content := range sources {
+		input.Sources[path] = source{Content: content}
+	}
+	return json.Marshal(input)
+}
+
+// limitedBuffer is a buffer failing the writes beyond its max size
+type limitedBuffer struct {
+	bytes.Buffer
+	max      int
+	exceeded bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.max {
+		b.exceeded = true
+		return 0, errors.New("output exceeds the max size")
+	}
+	return b.Buffer.Write(p)
+}
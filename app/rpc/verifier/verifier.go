@@ -0,0 +1,291 @@
+package verifier
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	lru "github.com/hashicorp/golang-lru"
+	dbm "github.com/tendermint/tm-db"
+
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+)
+
+const (
+	// MatchFull is the match of a deployed bytecode including its metadata hash, so the sources are exactly the
+	// ones of the deployment, comments included
+	MatchFull = "full"
+	// MatchPartial is the match of a deployed bytecode excluding its metadata hash, so the sources compile to the
+	// same bytecode but may differ from the ones of the deployment, e.g. in their comments
+	MatchPartial = "partial"
+
+	dbName = "verified-contracts"
+
+	// maxSourcesSize bounds the total size of the sources of a verification
+	maxSourcesSize = 4 << 20
+	// abiCacheSize is the number of parsed abis of the verified contracts kept in memory for the decoding
+	abiCacheSize = 1024
+)
+
+var (
+	// the records and the abis of the verified contracts are kept apart, so that the abis are loaded for the
+	// decoding without the sources
+	prefixRecord = []byte{0x01}
+	prefixABI    = []byte{0x02}
+)
+
+// VerifyRequest is the source and the compiler settings of a deployed contract. The contract name is either the
+// fully qualified name, e.g. contracts/Token.sol:Token, the bare name, or empty to match any contract of the
+// sources. The settings are the ones of the solc standard json input, e.g. optimizer, evmVersion and libraries.
+type VerifyRequest struct {
+	Address         common.Address    `json:"address"`
+	ContractName    string            `json:"contractName"`
+	CompilerVersion string            `json:"compilerVersion"`
+	Sources         map[string]string `json:"sources"`
+	Settings        json.RawMessage   `json:"settings"`
+}
+
+// Record is the verification of a deployed contract, with its sources, its abi and the match of its bytecode
+type Record struct {
+	Address         common.Address    `json:"address"`
+	ContractName    string            `json:"contractName"`
+	CompilerVersion string            `json:"compilerVersion"`
+	Sources         map[string]string `json:"sources"`
+	Settings        json.RawMessage   `json:"settings"`
+	ABI             json.RawMessage   `json:"abi"`
+	Match           string            `json:"match"`
+	CodeHash        common.Hash       `json:"codeHash"`
+	VerifiedAt      time.Time         `json:"verifiedAt"`
+}
+
+// Verifier compiles the sources of the deployed contracts with the solc binaries of its solc dir and keeps the
+// records of the verified ones, so that the explorers share a single source of verified contracts
+type Verifier struct {
+	db      dbm.DB
+	solcDir string
+	// slots bounds the compilers running at once
+	slots chan struct{}
+	abis  *lru.Cache
+}
+
+// NewVerifier opens the db of the records in the data dir, the solc binaries are named solc-<version> in the
+// solc dir, e.g. solc-0.8.17
+func NewVerifier(dataDir, solcDir string) (*Verifier, error) {
+	db, err := sdk.NewLevelDB(dbName, dataDir)
+	if err != nil {
+		return nil, err
+	}
+	return newVerifierWithDB(db, solcDir), nil
+}
+
+func newVerifierWithDB(db dbm.DB, solcDir string) *Verifier {
+	abis, _ := lru.New(abiCacheSize)
+	return &Verifier{db: db, solcDir: solcDir, slots: make(chan struct{}, runtime.NumCPU()), abis: abis}
+}
+
+// Verify compiles the sources of the request and matches the contract with the deployed bytecode. The record of
+// a match is kept, unless the contract already has a full match of the same bytecode.
+func (v *Verifier) Verify(req VerifyRequest, deployed []byte) (*Record, error) {
+	if len(deployed) == 0 {
+		return nil, fmt.Errorf("no contract is deployed at %s", req.Address.Hex())
+	}
+	if len(req.Sources) == 0 {
+		return nil, errors.New("no sources")
+	}
+	var size int
+	for path, content := range req.Sources {
+		size += len(path) + len(content)
+	}
+	if size > maxSourcesSize {
+		return nil, fmt.Errorf("sources of %d bytes exceed the max of %d", size, maxSourcesSize)
+	}
+
+	v.slots <- struct{}{}
+	output, err := compile(v.solcDir, req.CompilerVersion, req.Sources, req.Settings)
+	<-v.slots
+	if err != nil {
+		return nil, err
+	}
+
+	// the candidates are sorted, so that the match is the same whatever the order of the compiler output
+	var candidates []string
+	for path, contracts := range output.Contracts {
+		for name := range contracts {
+			qualified := path + ":" + name
+			if req.ContractName == "" || req.ContractName == qualified || req.ContractName == name {
+				candidates = append(candidates, qualified)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("contract %s isn't in the sources", req.ContractName)
+	}
+	sort.Strings(candidates)
+
+	for _, qualified := range candidates {
+		sep := strings.LastIndex(qualified, ":")
+		contract := output.Contracts[qualified[:sep]][qualified[sep+1:]]
+		match, err := matchBytecode(contract, deployed)
+		if err != nil {
+			return nil, fmt.Errorf("contract %s: %w", qualified, err)
+		}
+		if match == "" {
+			continue
+		}
+
+		record := &Record{
+			Address:         req.Address,
+			ContractName:    qualified,
+			CompilerVersion: req.CompilerVersion,
+			Sources:         req.Sources,
+			Settings:        req.Settings,
+			ABI:             contract.ABI,
+			Match:           match,
+			CodeHash:        crypto.Keccak256Hash(deployed),
+			VerifiedAt:      time.Now().UTC(),
+		}
+		if existing, found := v.Get(req.Address); found && existing.CodeHash == record.CodeHash &&
+			existing.Match == MatchFull && match == MatchPartial {
+			return existing, nil
+		}
+		return record, v.set(record)
+	}
+	return nil, errors.New("the bytecode of the sources doesn't match the deployed bytecode")
+}
+
+// Get returns the record of the verified contract at the address
+func (v *Verifier) Get(address common.Address) (*Record, bool) {
+	bz, err := v.db.Get(append(prefixRecord, address.Bytes()...))
+	if err != nil || len(bz) == 0 {
+		return nil, false
+	}
+	var record Record
+	if err := json.Unmarshal(bz, &record); err != nil {
+		return nil, false
+	}
+	return &record, true
+}
+
+// ABI returns the abi of the verified contract at the address
+func (v *Verifier) ABI(address common.Address) (abi.ABI, bool) {
+	if cached, ok := v.abis.Get(address); ok {
+		return cached.(abi.ABI), true
+	}
+	bz, err := v.db.Get(append(prefixABI, address.Bytes()...))
+	if err != nil || len(bz) == 0 {
+		return abi.ABI{}, false
+	}
+	parsed, err := abi.JSON(bytes.NewReader(bz))
+	if err != nil {
+		return abi.ABI{}, false
+	}
+	v.abis.Add(address, parsed)
+	return parsed, true
+}
+
+func (v *Verifier) set(record *Record) error {
+	bz, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if _, err := abi.JSON(bytes.NewReader(record.ABI)); err != nil {
+		return fmt.Errorf("invalid abi of contract %s: %w", record.ContractName, err)
+	}
+
+	batch := v.db.NewBatch()
+	defer batch.Close()
+	batch.Set(append(prefixRecord, record.Address.Bytes()...), bz)
+	batch.Set(append(prefixABI, record.Address.Bytes()...), record.ABI)
+	if err := batch.WriteSync(); err != nil {
+		return err
+	}
+	v.abis.Remove(record.Address)
+	return nil
+}
+
+func (v *Verifier) Close() {
+	v.db.Close()
+}
+
+// matchBytecode matches the deployed bytecode of the compiled contract with the deployed one, once the addresses
+// of the linked libraries and the immutables, which are only known on the deployment, are taken from the
+// deployed one. It returns the match, empty if the bytecodes differ.
+func matchBytecode(contract compiledContract, deployed []byte) (string, error) {
+	object := strings.TrimPrefix(contract.EVM.DeployedBytecode.Object, "0x")
+	if len(object) == 0 {
+		// the interfaces and the abstract contracts have no bytecode
+		return "", nil
+	}
+	if len(object) != 2*len(deployed) {
+		return "", nil
+	}
+
+	// the placeholders of the linked libraries aren't hex
+	linked := []byte(object)
+	deployedHex := hex.EncodeToString(deployed)
+	for _, libs := range contract.EVM.DeployedBytecode.LinkReferences {
+		for _, offsets := range libs {
+			for _, o := range offsets {
+				if err := copyOffset(linked, []byte(deployedHex), 2*o.Start, 2*o.Length); err != nil {
+					return "", err
+				}
+			}
+		}
+	}
+	compiled, err := hex.DecodeString(string(linked))
+	if err != nil {
+		return "", fmt.Errorf("invalid compiled bytecode: %w", err)
+	}
+	for _, offsets := range contract.EVM.DeployedBytecode.ImmutableReferences {
+		for _, o := range offsets {
+			if err := copyOffset(compiled, deployed, o.Start, o.Length); err != nil {
+				return "", err
+			}
+		}
+	}
+	// the libraries start with a push of their own address, zero until their deployment, guarding their calls
+	if len(compiled) > 22 && compiled[0] == 0x73 && bytes.Equal(compiled[1:21], common.Address{}.Bytes()) &&
+		compiled[21] == 0x30 && compiled[22] == 0x14 {
+		copy(compiled[1:21], deployed[1:21])
+	}
+
+	if bytes.Equal(compiled, deployed) {
+		return MatchFull, nil
+	}
+	if bytes.Equal(stripMetadata(compiled), stripMetadata(deployed)) {
+		return MatchPartial, nil
+	}
+	return "", nil
+}
+
+func copyOffset(dst, src []byte, start, length int) error {
+	if start < 0 || length < 0 || start+length > len(dst) || start+length > len(src) {
+		return fmt.Errorf("offset %d+%d out of the bytecode", start, length)
+	}
+	copy(dst[start:start+length], src[start:start+length])
+	return nil
+}
+
+// stripMetadata returns the bytecode without the cbor encoded metadata appended by solc, whose length is given by
+// the last two bytes of the bytecode. The bytecode is returned as it is without a metadata.
+func stripMetadata(code []byte) []byte {
+	if len(code) < 2 {
+		return code
+	}
+	length := int(code[len(code)-2])<<8 | int(code[len(code)-1])
+	start := len(code) - 2 - length
+	// the metadata is a cbor map
+	if length == 0 || start < 0 || code[start]&0xe0 != 0xa0 {
+		return code
+	}
+	return code[:start]
+}
@@ -0,0 +1,151 @@
+package verifier
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+)
+
+const (
+	// testCode pushes an immutable of 32 bytes at offset 1, and the address of a linked library at offset 34
+	testCode     = "7f" + "0000000000000000000000000000000000000000000000000000000000000000" + "73" + "__$1f7a7e5ff5a34e64d3b51e6e1a1b95f3fb$__" + "6000f3"
+	testMetadata = "a2646970667358221220" + "1111111111111111111111111111111111111111111111111111111111111111" + "64736f6c6343000811" + "0033"
+	testLib      = "c0ffee000000000000000000000000000000c0ff"
+	testValue    = "00000000000000000000000000000000000000000000000000000000000004d2"
+)
+
+func testDeployed(t *testing.T, metadataHash string) []byte {
+	code := strings.Replace(testCode, strings.Repeat("0", 64), testValue, 1)
+	code = strings.Replace(code, "__$1f7a7e5ff5a34e64d3b51e6e1a1b95f3fb$__", testLib, 1)
+	code += strings.Replace(testMetadata, strings.Repeat("1", 64), metadataHash, 1)
+	bz, err := hex.DecodeString(code)
+	require.NoError(t, err)
+	return bz
+}
+
+// writeSolc writes a fake solc of the version in the dir, which prints the output whatever its input
+func writeSolc(t *testing.T, dir, version, output string) {
+	script := fmt.Sprintf("#!/bin/sh\n/bin/cat > /dev/null\n/bin/cat <<'EOF'\n%s\nEOF\n", output)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "solc-"+version), []byte(script), 0755))
+}
+
+func TestStripMetadata(t *testing.T) {
+	code := testDeployed(t, strings.Repeat("1", 64))
+	require.Len(t, stripMetadata(code), len(code)-53)
+	require.Equal(t, []byte{0x60, 0x00}, stripMetadata([]byte{0x60, 0x00}))
+	require.Equal(t, []byte{0x60, 0x00, 0x00, 0x01}, stripMetadata([]byte{0x60, 0x00, 0x00, 0x01}))
+	require.Equal(t, []byte{0x1}, stripMetadata([]byte{0x1}))
+}
+
+func TestVerify(t *testing.T) {
+	solcDir, err := ioutil.TempDir("", "solc")
+	require.NoError(t, err)
+	defer os.RemoveAll(solcDir)
+
+	contract := map[string]interface{}{
+		"abi": json.RawMessage(`[{"type":"function","name":"mint","inputs":[{"name":"amount","type":"uint256"}]}]`),
+		"evm": map[string]interface{}{
+			"deployedBytecode": map[string]interface{}{
+				"object":              testCode + testMetadata,
+				"linkReferences":      map[string]interface{}{"Lib.sol": map[string]interface{}{"Lib": []offset{{Start: 34, Length: 20}}}},
+				"immutableReferences": map[string]interface{}{"3": []offset{{Start: 1, Length: 32}}},
+			},
+		},
+	}
+	output, err := json.Marshal(map[string]interface{}{
+		"contracts": map[string]interface{}{
+			"Token.sol": map[string]interface{}{"Token": contract, "IToken": map[string]interface{}{"abi": []interface{}{}}},
+		},
+	})
+	require.NoError(t, err)
+	writeSolc(t, solcDir, "0.8.17", string(output))
+	writeSolc(t, solcDir, "0.8.16", `{"errors":[{"severity":"warning","formattedMessage":"unused"},{"severity":"error","formattedMessage":"ParserError: Expected ';'"}]}`)
+
+	v := newVerifierWithDB(dbm.NewMemDB(), solcDir)
+	address := common.HexToAddress("0xc0ffee")
+	req := VerifyRequest{
+		Address:         address,
+		CompilerVersion: "0.8.17",
+		Sources:         map[string]string{"Token.sol": "contract Token {}"},
+		Settings:        json.RawMessage(`{"optimizer":{"enabled":true,"runs":200}}`),
+	}
+
+	// the metadata hash of the sources differs from the one of the deployment
+	record, err := v.Verify(req, testDeployed(t, strings.Repeat("2", 64)))
+	require.NoError(t, err)
+	require.Equal(t, MatchPartial, record.Match)
+	require.Equal(t, "Token.sol:Token", record.ContractName)
+
+	record, err = v.Verify(req, testDeployed(t, strings.Repeat("1", 64)))
+	require.NoError(t, err)
+	require.Equal(t, MatchFull, record.Match)
+	stored, found := v.Get(address)
+	require.True(t, found)
+	require.Equal(t, MatchFull, stored.Match)
+	require.Equal(t, req.Sources, stored.Sources)
+	contractABI, found := v.ABI(address)
+	require.True(t, found)
+	require.Contains(t, contractABI.Methods, "mint")
+
+	// a partial match doesn't replace a full one of the same bytecode
+	req.ContractName = "Token"
+	record, err = v.Verify(req, testDeployed(t, strings.Repeat("1", 64)))
+	require.NoError(t, err)
+	require.Equal(t, MatchFull, record.Match)
+
+	_, err = v.Verify(req, []byte{0x60, 0x00})
+	require.Error(t, err)
+	_, err = v.Verify(req, nil)
+	require.Error(t, err)
+
+	req.ContractName = "Missing"
+	_, err = v.Verify(req, testDeployed(t, strings.Repeat("1", 64)))
+	require.Error(t, err)
+
+	req.ContractName = ""
+	req.CompilerVersion = "0.8.16"
+	_, err = v.Verify(req, testDeployed(t, strings.Repeat("1", 64)))
+	require.EqualError(t, err, "compilation failed: ParserError: Expected ';'")
+
+	for _, version := range []string{"0.8.15", "../../bin/sh", ""} {
+		req.CompilerVersion = version
+		_, err = v.Verify(req, testDeployed(t, strings.Repeat("1", 64)))
+		require.Error(t, err, version)
+	}
+
+	_, found = v.Get(common.HexToAddress("0xbeef"))
+	require.False(t, found)
+	_, found = v.ABI(common.HexToAddress("0xbeef"))
+	require.False(t, found)
+}
+
+func TestStandardJSONInput(t *testing.T) {
+	input, err := standardJSONInput(map[string]string{"A.sol": "contract A {}"},
+		json.RawMessage(`{"evmVersion":"london","outputSelection":{"*":{"*":["*"]}}}`))
+	require.NoError(t, err)
+
+	var parsed struct {
+		Language string `json:"language"`
+		Sources  map[string]struct {
+			Content string `json:"content"`
+		} `json:"sources"`
+		Settings map[string]json.RawMessage `json:"settings"`
+	}
+	require.NoError(t, json.Unmarshal(input, &parsed))
+	require.Equal(t, "Solidity", parsed.Language)
+	require.Equal(t, "contract A {}", parsed.Sources["A.sol"].Content)
+	require.JSONEq(t, `"london"`, string(parsed.Settings["evmVersion"]))
+	require.JSONEq(t, string(outputSelection), string(parsed.Settings["outputSelection"]))
+
+	_, err = standardJSONInput(nil, json.RawMessage(`[]`))
+	require.Error(t, err)
+}
@@ -0,0 +1,243 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/okex/exchain/libs/tendermint/libs/log"
+	"github.com/okex/exchain/x/evm/watcher"
+)
+
+const (
+	// SignatureHeader holds the hex HMAC-SHA256 of the body of a notification, keyed by the secret of its webhook
+	SignatureHeader = "X-Exchain-Signature"
+	// DeliveryHeader holds the id of a notification, the same for all its attempts
+	DeliveryHeader = "X-Exchain-Delivery"
+
+	// queueSize is the number of notifications a webhook can fall behind before the next ones are dropped
+	queueSize      = 1000
+	maxAttempts    = 5
+	initialBackoff = time.Second
+	requestTimeout = 10 * time.Second
+)
+
+// Notification is the body posted to a webhook for a block with activity it watches
+type Notification struct {
+	SubscriptionID string                        `json:"subscription_id"`
+	BlockNumber    *hexutil.Big                  `json:"block_number"`
+	BlockHash      common.Hash                   `json:"block_hash"`
+	Transactions   []*watcher.TransactionReceipt `json:"transactions"`
+	Logs           []*ethtypes.Log               `json:"logs"`
+}
+
+type delivery struct {
+	id   string
+	body []byte
+}
+
+// Notifier posts the notifications of the blocks committed into the watcher db to the matching webhooks. The
+// notifications of a webhook are posted in order, each retried with a backoff until it's accepted with a 2xx.
+type Notifier struct {
+	registry *Registry
+	logger   log.Logger
+	client   *http.Client
+	backoff  time.Duration
+
+	mtx    sync.Mutex
+	queues map[string]chan delivery
+	quit   chan struct{}
+}
+
+func NewNotifier(registry *Registry, logger log.Logger) *Notifier {
+	return &Notifier{
+		registry: registry,
+		logger:   logger.With("module", "webhook"),
+		client:   &http.Client{Timeout: requestTimeout},
+		backoff:  initialBackoff,
+		queues:   make(map[string]chan delivery),
+		quit:     make(chan struct{}),
+	}
+}
+
+// Start notifies the webhooks of the blocks committed from now on
+func (n *Notifier) Start() {
+	eventsCh, unsubscribe := watcher.SubscribeChainEvents()
+	go func() {
+		defer unsubscribe()
+		for {
+			select {
+			case ev := <-eventsCh:
+				n.notify(ev)
+			case <-n.quit:
+				return
+			}
+		}
+	}()
+}
+
+func (n *Notifier) Stop() {
+	close(n.quit)
+}
+
+func (n *Notifier) notify(ev *watcher.ChainEvent) {
+	for _, sub := range n.registry.List() {
+		notification := Match(sub, ev)
+		if notification == nil {
+			continue
+		}
+		body, err := json.Marshal(notification)
+		if err != nil {
+			n.logger.Error("failed to encode the notification", "webhook", sub.ID, "err", err)
+			continue
+		}
+		d := delivery{id: fmt.Sprintf("%s-%s", sub.ID, ev.Header.Hash.Hex()), body: body}
+		select {
+		case n.queue(sub.ID) <- d:
+		default:
+			n.logger.Error("dropped the notification of a slow webhook", "webhook", sub.ID, "block", ev.Header.Hash)
+		}
+	}
+}
+
+// queue returns the queue of the webhook, started on its first notification
+func (n *Notifier) queue(id string) chan delivery {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	q, ok := n.queues[id]
+	if !ok {
+		q = make(chan delivery, queueSize)
+		n.queues[id] = q
+		go n.deliverLoop(id, q)
+	}
+	return q
+}
+
+func (n *Notifier) deliverLoop(id string, q chan delivery) {
+	for {
+		select {
+		case d := <-q:
+			if !n.deliverWithRetries(id, d) {
+				// the webhook was removed
+				n.mtx.Lock()
+				delete(n.queues, id)
+				n.mtx.Unlock()
+				return
+			}
+		case <-n.quit:
+			return
+		}
+	}
+}
+
+// deliverWithRetries posts the notification until it's accepted, it returns false if the webhook was removed
+func (n *Notifier) deliverWithRetries(id string, d delivery) bool {
+	backoff := n.backoff
+	for attempt := 1; ; attempt++ {
+		sub := n.registry.Get(id)
+		if sub == nil {
+			return false
+		}
+		err := n.post(sub, d)
+		if err == nil {
+			return true
+		}
+		if attempt == maxAttempts {
+			n.logger.Error("failed to notify the webhook", "webhook", id, "delivery", d.id, "err", err)
+			return true
+		}
+		select {
+		case <-time.After(backoff):
+		case <-n.quit:
+			return true
+		}
+		backoff *= 2
+	}
+}
+
+func (n *Notifier) post(sub *Subscription, d delivery) error {
+	req, err := http.NewRequest(http.MethodPost, sub.URL, bytes.NewReader(d.body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(DeliveryHeader, d.id)
+	req.Header.Set(SignatureHeader, Sign(sub.Secret, d.body))
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the signature of the body of a notification
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Match returns the notification of the activity of the block watched by the subscription, nil if there's none
+func Match(sub *Subscription, ev *watcher.ChainEvent) *Notification {
+	addresses := make(map[common.Address]bool, len(sub.Addresses))
+	for _, addr := range sub.Addresses {
+		addresses[addr] = true
+	}
+	topics := make(map[common.Hash]bool, len(sub.Topics))
+	for _, topic := range sub.Topics {
+		topics[topic] = true
+	}
+
+	notification := &Notification{
+		SubscriptionID: sub.ID,
+		BlockNumber:    ev.Header.Number,
+		BlockHash:      ev.Header.Hash,
+		Transactions:   []*watcher.TransactionReceipt{},
+		Logs:           []*ethtypes.Log{},
+	}
+	if len(addresses) > 0 {
+		for _, receipt := range ev.Receipts {
+			if addresses[common.HexToAddress(receipt.From)] || (receipt.To != nil && addresses[*receipt.To]) ||
+				(receipt.ContractAddress != nil && addresses[*receipt.ContractAddress]) {
+				notification.Transactions = append(notification.Transactions, receipt)
+			}
+		}
+	}
+	for _, log := range ev.Logs {
+		if len(addresses) > 0 && !addresses[log.Address] {
+			continue
+		}
+		if len(topics) > 0 && !matchTopics(log.Topics, topics) {
+			continue
+		}
+		notification.Logs = append(notification.Logs, log)
+	}
+
+	if len(notification.Transactions) == 0 && len(notification.Logs) == 0 {
+		return nil
+	}
+	return notification
+}
+
+func matchTopics(logTopics []common.Hash, topics map[common.Hash]bool) bool {
+	for _, topic := range logTopics {
+		if topics[topic] {
+			return true
+		}
+	}
+	return false
+}
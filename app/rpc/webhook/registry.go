@@ -0,0 +1,159 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// maxSubscriptions bounds the number of registered webhooks
+const maxSubscriptions = 1000
+
+// Subscription is a webhook registered for the activity of addresses or log topics
+type Subscription struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+	// Addresses match the txs from or to them, the contracts they create, and the logs they emit
+	Addresses []common.Address `json:"addresses"`
+	// Topics match the logs with any of them as a topic, of the addresses if some are given
+	Topics []common.Hash `json:"topics"`
+	// Secret is the key of the HMAC-SHA256 signature of the notifications
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Registry keeps the subscriptions in a json file, so that they survive the restarts of the node
+type Registry struct {
+	mtx  sync.RWMutex
+	path string
+	subs map[string]*Subscription
+}
+
+// NewRegistry loads the subscriptions of the file at path, if any
+func NewRegistry(path string) (*Registry, error) {
+	r := &Registry{path: path, subs: make(map[string]*Subscription)}
+	bz, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var subs []*Subscription
+	if err := json.Unmarshal(bz, &subs); err != nil {
+		return nil, fmt.Errorf("invalid webhook registry %s: %w", path, err)
+	}
+	for _, sub := range subs {
+		r.subs[sub.ID] = sub
+	}
+	return r, nil
+}
+
+// Add registers a webhook, a secret is generated if none is given. It returns the subscription with its id.
+func (r *Registry) Add(callback string, addresses []common.Address, topics []common.Hash, secret string) (*Subscription, error) {
+	u, err := url.Parse(callback)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid callback url %q", callback)
+	}
+	if len(addresses) == 0 && len(topics) == 0 {
+		return nil, errors.New("no address nor topic to watch")
+	}
+	if secret == "" {
+		if secret, err = randomHex(32); err != nil {
+			return nil, err
+		}
+	}
+	id, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if len(r.subs) >= maxSubscriptions {
+		return nil, fmt.Errorf("too many webhooks, the limit is %d", maxSubscriptions)
+	}
+	sub := &Subscription{
+		ID:        id,
+		URL:       callback,
+		Addresses: addresses,
+		Topics:    topics,
+		Secret:    secret,
+		CreatedAt: time.Now().UTC(),
+	}
+	r.subs[id] = sub
+	if err := r.save(); err != nil {
+		delete(r.subs, id)
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Remove unregisters a webhook, it returns false if it isn't found
+func (r *Registry) Remove(id string) (bool, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	sub, ok := r.subs[id]
+	if !ok {
+		return false, nil
+	}
+	delete(r.subs, id)
+	if err := r.save(); err != nil {
+		r.subs[id] = sub
+		return false, err
+	}
+	return true, nil
+}
+
+// Get returns the subscription of the id, nil if it isn't found
+func (r *Registry) Get(id string) *Subscription {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return r.subs[id]
+}
+
+// List returns the subscriptions in the order of their registration
+func (r *Registry) List() []*Subscription {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	subs := make([]*Subscription, 0, len(r.subs))
+	for _, sub := range r.subs {
+		subs = append(subs, sub)
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].CreatedAt.Before(subs[j].CreatedAt) })
+	return subs
+}
+
+func (r *Registry) save() error {
+	subs := make([]*Subscription, 0, len(r.subs))
+	for _, sub := range r.subs {
+		subs = append(subs, sub)
+	}
+	bz, err := json.Marshal(subs)
+	if err != nil {
+		return err
+	}
+	tmp := r.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, bz, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.path)
+}
+
+func randomHex(n int) (string, error) {
+	bz := make([]byte, n)
+	if _, err := rand.Read(bz); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bz), nil
+}
@@ -0,0 +1,144 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	rpctypes "github.com/okex/exchain/app/rpc/types"
+	"github.com/okex/exchain/libs/tendermint/libs/log"
+	"github.com/okex/exchain/x/evm/watcher"
+)
+
+var (
+	alice    = common.HexToAddress("0x01")
+	bob      = common.HexToAddress("0x02")
+	token    = common.HexToAddress("0x03")
+	transfer = common.HexToHash("0xddf252ad")
+)
+
+func newRegistry(t *testing.T) (*Registry, func()) {
+	dir, err := ioutil.TempDir("", "webhook")
+	require.NoError(t, err)
+	r, err := NewRegistry(filepath.Join(dir, "webhooks.json"))
+	require.NoError(t, err)
+	return r, func() { os.RemoveAll(dir) }
+}
+
+// newEvent returns a block with a transfer of alice to bob, and a call of alice to the token emitting a log
+func newEvent() *watcher.ChainEvent {
+	log := &ethtypes.Log{Address: token, Topics: []common.Hash{transfer, alice.Hash()}, Index: 0}
+	return &watcher.ChainEvent{
+		Header: &rpctypes.EthHeaderWithBlockHash{Number: (*hexutil.Big)(big.NewInt(10)), Hash: common.HexToHash("0xb1")},
+		Logs:   []*ethtypes.Log{log},
+		Receipts: []*watcher.TransactionReceipt{
+			{TransactionHash: "0x01", From: alice.Hex(), To: &bob},
+			{TransactionHash: "0x02", From: alice.Hex(), To: &token, Logs: []*ethtypes.Log{log}},
+		},
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r, cleanup := newRegistry(t)
+	defer cleanup()
+
+	_, err := r.Add("ftp://example.com", []common.Address{alice}, nil, "")
+	require.Error(t, err)
+	_, err = r.Add("http://example.com", nil, nil, "")
+	require.Error(t, err)
+
+	sub, err := r.Add("http://example.com/hook", []common.Address{alice}, nil, "")
+	require.NoError(t, err)
+	require.Len(t, sub.Secret, 64)
+
+	// the subscriptions are reloaded after a restart
+	reloaded, err := NewRegistry(r.path)
+	require.NoError(t, err)
+	require.Equal(t, sub.URL, reloaded.Get(sub.ID).URL)
+
+	ok, err := r.Remove(sub.ID)
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = r.Remove(sub.ID)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Empty(t, r.List())
+}
+
+func TestMatch(t *testing.T) {
+	ev := newEvent()
+
+	// the txs of the address
+	n := Match(&Subscription{Addresses: []common.Address{bob}}, ev)
+	require.Len(t, n.Transactions, 1)
+	require.Empty(t, n.Logs)
+
+	// the logs of the contract
+	n = Match(&Subscription{Addresses: []common.Address{token}}, ev)
+	require.Len(t, n.Transactions, 1)
+	require.Len(t, n.Logs, 1)
+
+	// the logs of a topic in any position, from any contract
+	n = Match(&Subscription{Topics: []common.Hash{alice.Hash()}}, ev)
+	require.Empty(t, n.Transactions)
+	require.Len(t, n.Logs, 1)
+
+	// the addresses and the topics of a log must both match
+	n = Match(&Subscription{Addresses: []common.Address{bob}, Topics: []common.Hash{transfer}}, ev)
+	require.Len(t, n.Transactions, 1)
+	require.Empty(t, n.Logs)
+	require.Nil(t, Match(&Subscription{Topics: []common.Hash{common.HexToHash("0x04")}}, ev))
+}
+
+func TestNotifier(t *testing.T) {
+	r, cleanup := newRegistry(t)
+	defer cleanup()
+
+	var attempts int32
+	received := make(chan Notification, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// the first attempt fails and is retried
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := ioutil.ReadAll(req.Body)
+		if req.Header.Get(SignatureHeader) != Sign("secret", body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var n Notification
+		json.Unmarshal(body, &n)
+		received <- n
+	}))
+	defer server.Close()
+
+	sub, err := r.Add(server.URL, nil, []common.Hash{transfer}, "secret")
+	require.NoError(t, err)
+	notifier := NewNotifier(r, log.NewNopLogger())
+	notifier.backoff = 10 * time.Millisecond
+	defer notifier.Stop()
+
+	notifier.notify(newEvent())
+	select {
+	case n := <-received:
+		require.Equal(t, sub.ID, n.SubscriptionID)
+		require.Equal(t, common.HexToHash("0xb1"), n.BlockHash)
+		require.Len(t, n.Logs, 1)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the webhook wasn't notified")
+	}
+	require.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+}
@@ -0,0 +1,162 @@
+package websockets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	evmtypes "github.com/okex/exchain/x/evm/types"
+)
+
+// maxWatchedAccounts bounds the addresses and the storage slots watched by an account changes subscription
+const maxWatchedAccounts = 1000
+
+// AccountChangesCriteria is the accounts watched by an account changes subscription. The balance, the nonce and the
+// code of the addresses are watched, along with the given storage slots of the addresses of the storage, which are
+// watched too.
+type AccountChangesCriteria struct {
+	Addresses []common.Address                 `json:"addresses"`
+	Storage   map[common.Address][]common.Hash `json:"storage"`
+}
+
+// accountChangesFilter is the parsed criteria of an account changes subscription
+type accountChangesFilter struct {
+	addresses map[common.Address]struct{}
+	slots     map[common.Address]map[common.Hash]struct{}
+}
+
+func newAccountChangesFilter(extra interface{}) (*accountChangesFilter, error) {
+	if extra == nil {
+		return nil, errors.New("no accounts to watch")
+	}
+	bz, err := json.Marshal(extra)
+	if err != nil {
+		return nil, err
+	}
+	var crit AccountChangesCriteria
+	if err := json.Unmarshal(bz, &crit); err != nil {
+		return nil, fmt.Errorf("invalid criteria: %w", err)
+	}
+
+	f := &accountChangesFilter{
+		addresses: make(map[common.Address]struct{}),
+		slots:     make(map[common.Address]map[common.Hash]struct{}),
+	}
+	watched := 0
+	for _, addr := range crit.Addresses {
+		f.addresses[addr] = struct{}{}
+		watched++
+	}
+	for addr, slots := range crit.Storage {
+		f.addresses[addr] = struct{}{}
+		f.slots[addr] = make(map[common.Hash]struct{}, len(slots))
+		for _, slot := range slots {
+			f.slots[addr][slot] = struct{}{}
+		}
+		watched += len(slots)
+	}
+	if len(f.addresses) == 0 {
+		return nil, errors.New("no accounts to watch")
+	}
+	if watched > maxWatchedAccounts {
+		return nil, fmt.Errorf("%d accounts and storage slots exceed the max of %d", watched, maxWatchedAccounts)
+	}
+	return f, nil
+}
+
+// filter returns the changes of the watched accounts, nil if none of them changed
+func (f *accountChangesFilter) filter(diff *evmtypes.BlockStateDiff) *evmtypes.BlockStateDiff {
+	accounts := make(evmtypes.StateDiff)
+	for addr, acc := range diff.Accounts {
+		if _, ok := f.addresses[addr]; !ok {
+			continue
+		}
+		watched := &evmtypes.AccountDiff{Balance: acc.Balance, Nonce: acc.Nonce, Code: acc.Code}
+		for slot, change := range acc.Storage {
+			if _, ok := f.slots[addr][slot]; !ok {
+				continue
+			}
+			if watched.Storage == nil {
+				watched.Storage = make(map[common.Hash]*evmtypes.StorageDiff)
+			}
+			watched.Storage[slot] = change
+		}
+		if watched.Balance != nil || watched.Nonce != nil || watched.Code != nil || len(watched.Storage) != 0 {
+			accounts[addr] = watched
+		}
+	}
+	if len(accounts) == 0 {
+		return nil
+	}
+	return &evmtypes.BlockStateDiff{BlockNumber: diff.BlockNumber, BlockHash: diff.BlockHash, Accounts: accounts}
+}
+
+// subscribeAccountChanges notifies the client of the changes made to the watched accounts by the evm txs of each
+// block, the blocks leaving them unchanged aren't notified
+func (api *PubSubAPI) subscribeAccountChanges(conn *wsConn, extra interface{}) (rpc.ID, error) {
+	if !evmtypes.IsStateDiffFeedEnabled() {
+		return "", fmt.Errorf("the account changes are disabled, see --%s", evmtypes.FlagEnableStateDiffFeed)
+	}
+	crit, err := newAccountChangesFilter(extra)
+	if err != nil {
+		return "", err
+	}
+
+	id := rpc.NewID()
+	unsubscribed := make(chan struct{})
+	api.filtersMu.Lock()
+	api.filters[id] = &wsSubscription{
+		conn:         conn,
+		unsubscribed: unsubscribed,
+	}
+	api.filtersMu.Unlock()
+
+	diffs, cancel := evmtypes.SubscribeStateDiffs()
+	go func() {
+		defer cancel()
+		for {
+			select {
+			case diff := <-diffs:
+				changes := crit.filter(diff)
+				if changes == nil {
+					continue
+				}
+
+				var err error
+				api.filtersMu.RLock()
+				if f, found := api.filters[id]; found {
+					// write to ws conn
+					res := &SubscriptionNotification{
+						Jsonrpc: "2.0",
+						Method:  "eth_subscription",
+						Params: &SubscriptionResult{
+							Subscription: id,
+							Result:       changes,
+						},
+					}
+
+					err = f.conn.WriteJSON(res)
+					if err != nil {
+						api.logger.Error("failed to write account changes", "ID", id, "blocknumber", changes.BlockNumber, "error", err)
+					} else {
+						api.logger.Debug("successfully write account changes", "ID", id, "blocknumber", changes.BlockNumber)
+					}
+				}
+				api.filtersMu.RUnlock()
+
+				if err != nil {
+					api.unsubscribe(id)
+					return
+				}
+			case <-unsubscribed:
+				api.logger.Debug("AccountChanges channel is closed", "ID", id)
+				return
+			}
+		}
+	}()
+
+	return id, nil
+}
@@ -0,0 +1,64 @@
+package websockets
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+
+	evmtypes "github.com/okex/exchain/x/evm/types"
+)
+
+func TestAccountChangesFilter(t *testing.T) {
+	addr, token, other := common.HexToAddress("0x01"), common.HexToAddress("0x02"), common.HexToAddress("0x03")
+	slot, otherSlot := common.HexToHash("0x01"), common.HexToHash("0x02")
+
+	// the criteria are the json params of the subscription
+	var extra interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"addresses":["`+addr.Hex()+`"],"storage":{"`+token.Hex()+`":["`+slot.Hex()+`"]}}`), &extra))
+	f, err := newAccountChangesFilter(extra)
+	require.NoError(t, err)
+
+	balance := &evmtypes.BalanceDiff{From: (*hexutil.Big)(big.NewInt(1)), To: (*hexutil.Big)(big.NewInt(2))}
+	storage := map[common.Hash]*evmtypes.StorageDiff{
+		slot:      {To: common.HexToHash("0x0a")},
+		otherSlot: {To: common.HexToHash("0x0b")},
+	}
+	changes := f.filter(&evmtypes.BlockStateDiff{
+		BlockNumber: 7,
+		Accounts: evmtypes.StateDiff{
+			addr:  {Balance: balance, Storage: storage},
+			token: {Nonce: &evmtypes.NonceDiff{From: 1, To: 2}, Storage: storage},
+			other: {Balance: balance},
+		},
+	})
+	require.NotNil(t, changes)
+	require.Equal(t, hexutil.Uint64(7), changes.BlockNumber)
+	require.Len(t, changes.Accounts, 2)
+	// only the given storage slots are watched
+	require.Equal(t, balance, changes.Accounts[addr].Balance)
+	require.Empty(t, changes.Accounts[addr].Storage)
+	require.NotNil(t, changes.Accounts[token].Nonce)
+	require.Len(t, changes.Accounts[token].Storage, 1)
+	require.Contains(t, changes.Accounts[token].Storage, slot)
+
+	// the blocks leaving the watched accounts unchanged aren't notified
+	require.Nil(t, f.filter(&evmtypes.BlockStateDiff{Accounts: evmtypes.StateDiff{
+		other: {Balance: balance},
+		token: {Storage: map[common.Hash]*evmtypes.StorageDiff{otherSlot: {To: common.HexToHash("0x0b")}}},
+	}}))
+
+	for _, invalid := range []interface{}{nil, map[string]interface{}{}, "0x01", map[string]interface{}{"addresses": []interface{}{"0xzz"}}} {
+		_, err = newAccountChangesFilter(invalid)
+		require.Error(t, err, invalid)
+	}
+	addresses := make([]common.Address, maxWatchedAccounts+1)
+	for i := range addresses {
+		addresses[i] = common.BigToAddress(big.NewInt(int64(i)))
+	}
+	_, err = newAccountChangesFilter(map[string]interface{}{"addresses": addresses})
+	require.Error(t, err)
+}
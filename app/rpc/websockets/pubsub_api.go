@@ -10,13 +10,12 @@ import (
 
 	"github.com/okex/exchain/libs/cosmos-sdk/client/context"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/eth/filters"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rpc"
 
 	rpcfilters "github.com/okex/exchain/app/rpc/namespaces/eth/filters"
 	rpctypes "github.com/okex/exchain/app/rpc/types"
-	evmtypes "github.com/okex/exchain/x/evm/types"
+	"github.com/okex/exchain/x/evm/watcher"
 )
 
 // PubSubAPI is the eth_ prefixed set of APIs in the Web3 JSON-RPC spec
@@ -25,17 +24,25 @@ type PubSubAPI struct {
 	events    *rpcfilters.EventSystem
 	filtersMu *sync.RWMutex
 	filters   map[rpc.ID]*wsSubscription
+	syncing   *syncMonitor
 	logger    log.Logger
 }
 
 // NewAPI creates an instance of the ethereum PubSub API.
 func NewAPI(clientCtx context.CLIContext, log log.Logger) *PubSubAPI {
+	logger := log.With("module", "websocket-client")
+	status := func() (*coretypes.ResultStatus, error) { return clientCtx.Client.Status() }
+	var watcherHeight func() (uint64, error)
+	if watcher.IsWatcherEnabled() {
+		watcherHeight = watcher.NewQuerier().GetLatestBlockNumber
+	}
 	return &PubSubAPI{
 		clientCtx: clientCtx,
 		events:    rpcfilters.NewEventSystem(clientCtx.Client),
 		filtersMu: new(sync.RWMutex),
 		filters:   make(map[rpc.ID]*wsSubscription),
-		logger:    log.With("module", "websocket-client"),
+		syncing:   newSyncMonitor(status, watcherHeight, logger),
+		logger:    logger,
 	}
 }
 
@@ -59,6 +66,12 @@ func (api *PubSubAPI) subscribe(conn *wsConn, params []interface{}) (rpc.ID, err
 		return api.subscribePendingTransactions(conn)
 	case "syncing":
 		return api.subscribeSyncing(conn)
+	case "accountChanges":
+		if len(params) > 1 {
+			return api.subscribeAccountChanges(conn, params[1])
+		}
+
+		return api.subscribeAccountChanges(conn, nil)
 	default:
 		return "0", fmt.Errorf("unsupported method %s", method)
 	}
@@ -96,21 +109,11 @@ func (api *PubSubAPI) subscribeNewHeads(conn *wsConn) (rpc.ID, error) {
 	}
 	api.filtersMu.Unlock()
 
-	go func(headersCh <-chan coretypes.ResultEvent, errCh <-chan error) {
+	go func(headersCh <-chan *rpctypes.EthHeaderWithBlockHash, errCh <-chan error) {
 		for {
 			select {
-			case event := <-headersCh:
-				data, ok := event.Data.(tmtypes.EventDataNewBlockHeader)
-				if !ok {
-					api.logger.Error(fmt.Sprintf("invalid data type %T, expected EventDataTx", event.Data), "ID", sub.ID())
-					continue
-				}
-				headerWithBlockHash, err := rpctypes.EthHeaderWithBlockHashFromTendermint(&data.Header)
-				if err != nil {
-					api.logger.Error("failed to get header with block hash", "error", err)
-					continue
-				}
-
+			case headerWithBlockHash := <-headersCh:
+				var err error
 				api.filtersMu.RLock()
 				if f, found := api.filters[sub.ID()]; found {
 					// write to ws conn
@@ -146,57 +149,17 @@ func (api *PubSubAPI) subscribeNewHeads(conn *wsConn) (rpc.ID, error) {
 				return
 			}
 		}
-	}(sub.Event(), sub.Err())
+	}(sub.Headers(), sub.Err())
 
 	return sub.ID(), nil
 }
 
+// subscribeLogs notifies the client of the new logs matching the criteria, which are parsed and matched as the
+// ones of eth_getLogs
 func (api *PubSubAPI) subscribeLogs(conn *wsConn, extra interface{}) (rpc.ID, error) {
-	crit := filters.FilterCriteria{}
-
-	if extra != nil {
-		params, ok := extra.(map[string]interface{})
-		if !ok {
-			return "", fmt.Errorf("invalid criteria")
-		}
-
-		if params["address"] != nil {
-			address, ok := params["address"].(string)
-			addresses, sok := params["address"].([]interface{})
-			if !ok && !sok {
-				return "", fmt.Errorf("invalid address; must be address or array of addresses")
-			}
-
-			if ok {
-				if !common.IsHexAddress(address) {
-					return "", fmt.Errorf("invalid address")
-				}
-				crit.Addresses = []common.Address{common.HexToAddress(address)}
-			} else if sok {
-				crit.Addresses = []common.Address{}
-				for _, addr := range addresses {
-					address, ok := addr.(string)
-					if !ok || !common.IsHexAddress(address) {
-						return "", fmt.Errorf("invalid address")
-					}
-
-					crit.Addresses = append(crit.Addresses, common.HexToAddress(address))
-				}
-			}
-		}
-
-		if params["topics"] != nil {
-			topics, ok := params["topics"].([]interface{})
-			if !ok {
-				return "", fmt.Errorf("invalid topics")
-			}
-
-			topicFilterLists, err := resolveTopicList(topics)
-			if err != nil {
-				return "", fmt.Errorf("invalid topics")
-			}
-			crit.Topics = topicFilterLists
-		}
+	crit, err := rpcfilters.ParseFilterCriteria(extra)
+	if err != nil {
+		return "", err
 	}
 
 	sub, _, err := api.events.SubscribeLogs(crit)
@@ -213,27 +176,15 @@ func (api *PubSubAPI) subscribeLogs(conn *wsConn, extra interface{}) (rpc.ID, er
 	}
 	api.filtersMu.Unlock()
 
-	go func(ch <-chan coretypes.ResultEvent, errCh <-chan error) {
+	go func(ch <-chan []*ethtypes.Log, errCh <-chan error) {
 		for {
 			select {
-			case event := <-ch:
-				go func(event coretypes.ResultEvent) {
-					dataTx, ok := event.Data.(tmtypes.EventDataTx)
-					if !ok {
-						api.logger.Error(fmt.Sprintf("invalid event data %T, expected EventDataTx", event.Data))
-						return
-					}
-
-					var resultData evmtypes.ResultData
-					resultData, err = evmtypes.DecodeResultData(dataTx.TxResult.Result.Data)
-					if err != nil {
-						api.logger.Error("failed to decode result data", "error", err)
-						return
-					}
-
-					logs := rpcfilters.FilterLogs(resultData.Logs, crit.FromBlock, crit.ToBlock, crit.Addresses, crit.Topics)
+			case logs := <-ch:
+				go func(logs []*ethtypes.Log) {
+					var err error
+					logs = rpcfilters.FilterLogs(logs, crit.FromBlock, crit.ToBlock, crit.Addresses, crit.Topics)
 					if len(logs) == 0 {
-						api.logger.Debug("no matched logs", "ID", sub.ID(), "txhash", resultData.TxHash)
+						api.logger.Debug("no matched logs", "ID", sub.ID())
 						return
 					}
 
@@ -262,7 +213,7 @@ func (api *PubSubAPI) subscribeLogs(conn *wsConn, extra interface{}) (rpc.ID, er
 					if err != nil {
 						api.unsubscribe(sub.ID())
 					}
-				}(event)
+				}(logs)
 			case err := <-errCh:
 				if err != nil {
 					api.unsubscribe(sub.ID())
@@ -274,85 +225,11 @@ func (api *PubSubAPI) subscribeLogs(conn *wsConn, extra interface{}) (rpc.ID, er
 				return
 			}
 		}
-	}(sub.Event(), sub.Err())
+	}(sub.Logs(), sub.Err())
 
 	return sub.ID(), nil
 }
 
-func resolveTopicList(params []interface{}) ([][]common.Hash, error) {
-	topicFilterLists := make([][]common.Hash, len(params))
-	for i, param := range params { // eg: ["0xddf252......f523b3ef", null, ["0x000000......32fea9e4", "0x000000......ab14dc5d"]]
-		if param == nil {
-			// 1.1 if the topic is null
-			topicFilterLists[i] = nil
-		} else {
-			// 2.1 judge if the param is the type of string or not
-			topicStr, ok := param.(string)
-			// 2.1 judge if the param is the type of string slice or not
-			topicSlices, sok := param.([]interface{})
-			if !ok && !sok {
-				// if both judgement are false, return invalid topics
-				return topicFilterLists, fmt.Errorf("invalid topics")
-			}
-
-			if ok {
-				// 2.2 This is string
-				// 2.3 judge the topic is a valid hex hash or not
-				if !IsHexHash(topicStr) {
-					return topicFilterLists, fmt.Errorf("invalid topics")
-				}
-				// 2.4 add this topic to topic-hash-lists
-				topicHash := common.HexToHash(topicStr)
-				topicFilterLists[i] = []common.Hash{topicHash}
-			} else if sok {
-				// 2.2 This is slice of string
-				topicHashes := make([]common.Hash, len(topicSlices))
-				for n, topicStr := range topicSlices {
-					//2.3 judge every topic
-					topicHash, ok := topicStr.(string)
-					if !ok || !IsHexHash(topicHash) {
-						return topicFilterLists, fmt.Errorf("invalid topics")
-					}
-					topicHashes[n] = common.HexToHash(topicHash)
-				}
-				// 2.4 add this topic slice to topic-hash-lists
-				topicFilterLists[i] = topicHashes
-			}
-		}
-	}
-	return topicFilterLists, nil
-}
-
-func IsHexHash(s string) bool {
-	if has0xPrefix(s) {
-		s = s[2:]
-	}
-	return len(s) == 2*common.HashLength && isHex(s)
-}
-
-// has0xPrefix validates str begins with '0x' or '0X'.
-func has0xPrefix(str string) bool {
-	return len(str) >= 2 && str[0] == '0' && (str[1] == 'x' || str[1] == 'X')
-}
-
-// isHexCharacter returns bool of c being a valid hexadecimal.
-func isHexCharacter(c byte) bool {
-	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
-}
-
-// isHex validates whether each byte is valid hexadecimal string.
-func isHex(str string) bool {
-	if len(str)%2 != 0 {
-		return false
-	}
-	for _, c := range []byte(str) {
-		if !isHexCharacter(c) {
-			return false
-		}
-	}
-	return true
-}
-
 func (api *PubSubAPI) subscribePendingTransactions(conn *wsConn) (rpc.ID, error) {
 	sub, _, err := api.events.SubscribePendingTxs()
 	if err != nil {
@@ -419,88 +296,61 @@ func (api *PubSubAPI) subscribePendingTransactions(conn *wsConn) (rpc.ID, error)
 	return sub.ID(), nil
 }
 
+// subscribeSyncing notifies the client when the node starts and stops catching up, the client subscribing
+// while the node is catching up is notified on the next poll
 func (api *PubSubAPI) subscribeSyncing(conn *wsConn) (rpc.ID, error) {
-	sub, _, err := api.events.SubscribeNewHeads()
-	if err != nil {
-		return "", fmt.Errorf("error creating block filter: %s", err.Error())
-	}
-
+	id := rpc.NewID()
 	unsubscribed := make(chan struct{})
 	api.filtersMu.Lock()
-	api.filters[sub.ID()] = &wsSubscription{
-		sub:          sub,
+	api.filters[id] = &wsSubscription{
 		conn:         conn,
 		unsubscribed: unsubscribed,
 	}
 	api.filtersMu.Unlock()
 
-	status, err := api.clientCtx.Client.Status()
-	if err != nil {
-		return "", fmt.Errorf("error get sync status: %s", err.Error())
-	}
-	startingBlock := hexutil.Uint64(status.SyncInfo.EarliestBlockHeight)
-	highestBlock := hexutil.Uint64(0)
-
-	var result interface{}
-
-	go func(headersCh <-chan coretypes.ResultEvent, errCh <-chan error) {
+	go func() {
+		// the client assumes the node is synced until notified otherwise
+		notified := false
 		for {
-			select {
-			case <-headersCh:
-
-				newStatus, err := api.clientCtx.Client.Status()
-				if err != nil {
-					api.logger.Error(fmt.Sprintf("error get sync status: %s", err.Error()))
-					continue
-				}
-
-				if !newStatus.SyncInfo.CatchingUp {
-					result = false
-				} else {
-					result = map[string]interface{}{
-						"startingBlock": startingBlock,
-						"currentBlock":  hexutil.Uint64(newStatus.SyncInfo.LatestBlockHeight),
-						"highestBlock":  highestBlock,
-					}
-				}
-
+			known, syncing, result, changed := api.syncing.current()
+			if known && syncing != notified {
+				var err error
 				api.filtersMu.RLock()
-				if f, found := api.filters[sub.ID()]; found {
+				if f, found := api.filters[id]; found {
 					// write to ws conn
 					res := &SubscriptionNotification{
 						Jsonrpc: "2.0",
 						Method:  "eth_subscription",
 						Params: &SubscriptionResult{
-							Subscription: sub.ID(),
+							Subscription: id,
 							Result:       result,
 						},
 					}
 
 					err = f.conn.WriteJSON(res)
 					if err != nil {
-						api.logger.Error("failed to write syncing status", "ID", sub.ID(), "error", err)
+						api.logger.Error("failed to write syncing status", "ID", id, "error", err)
 					} else {
-						api.logger.Debug("successfully write syncing status", "ID", sub.ID())
+						api.logger.Debug("successfully write syncing status", "ID", id, "syncing", syncing)
 					}
 				}
 				api.filtersMu.RUnlock()
 
 				if err != nil {
-					api.unsubscribe(sub.ID())
+					api.unsubscribe(id)
+					return
 				}
+				notified = syncing
+			}
 
-			case err := <-errCh:
-				if err != nil {
-					api.unsubscribe(sub.ID())
-					api.logger.Error("websocket recv error, close the conn", "ID", sub.ID(), "error", err)
-				}
-				return
+			select {
+			case <-changed:
 			case <-unsubscribed:
-				api.logger.Debug("Syncing channel is closed", "ID", sub.ID())
+				api.logger.Debug("Syncing channel is closed", "ID", id)
 				return
 			}
 		}
-	}(sub.Event(), sub.Err())
+	}()
 
-	return sub.ID(), nil
+	return id, nil
 }
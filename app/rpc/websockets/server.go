@@ -2,15 +2,19 @@ package websockets
 
 import (
 	"bytes"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"math/big"
+	"net"
 	"net/http"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/okex/exchain/libs/cosmos-sdk/client/context"
+	"github.com/okex/exchain/libs/cosmos-sdk/client/lcd"
 	"github.com/okex/exchain/libs/cosmos-sdk/server"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/go-kit/kit/metrics"
@@ -23,6 +27,11 @@ import (
 	"github.com/okex/exchain/libs/tendermint/libs/log"
 )
 
+const (
+	// writeWait is the time allowed to write a message to the client
+	writeWait = 10 * time.Second
+)
+
 // Server defines a server that handles Ethereum websockets.
 type Server struct {
 	rpcAddr string // listen address of rest-server
@@ -30,14 +39,28 @@ type Server struct {
 	api     *PubSubAPI
 	logger  log.Logger
 
+	vhosts     []string
+	origins    map[string]struct{} // nil accepts all the origins
+	tlsConfig  *tls.Config
+	restClient *http.Client
+
+	pingInterval   time.Duration // 0 disables the pings
+	idleTimeout    time.Duration // 0 disables the idle timeout
+	shutdownGrace  time.Duration // the time allowed to the clients to answer the close message on shutdown
+	maxConnsPerIP  int           // 0 disables the per ip limit
+	httpServer     *http.Server
+	closing        bool
+	connWg         sync.WaitGroup
+	conns          map[*wsConn]struct{}
+	connsPerIP     map[string]int
 	connPool       chan struct{}
-	connPoolLock   *sync.Mutex
+	connPoolLock   *sync.Mutex // protects the connPool, closing, conns and connsPerIP
 	currentConnNum metrics.Gauge
 	maxConnNum     metrics.Gauge
 }
 
-// NewServer creates a new websocket server instance.
-func NewServer(clientCtx context.CLIContext, log log.Logger, wsAddr string) *Server {
+// NewServer creates a new websocket server instance, sharing the virtual hosts and the TLS config of the rest-server.
+func NewServer(clientCtx context.CLIContext, log log.Logger, wsAddr string, security lcd.SecurityConfig, tlsConfig *tls.Config) *Server {
 	restServerAddr := viper.GetString(server.FlagListenAddr)
 	parts := strings.SplitN(restServerAddr, "://", 2)
 	if len(parts) != 2 {
@@ -50,13 +73,54 @@ func NewServer(clientCtx context.CLIContext, log log.Logger, wsAddr string) *Ser
 	}
 	port := urlParts[1]
 
+	var origins map[string]struct{}
+	for _, origin := range lcd.SplitList(viper.GetString(server.FlagWsOrigins)) {
+		if origin == "*" {
+			origins = nil
+			break
+		}
+		if origins == nil {
+			origins = make(map[string]struct{})
+		}
+		origins[strings.ToLower(origin)] = struct{}{}
+	}
+
+	pingInterval := viper.GetDuration(server.FlagWsPingInterval)
+	idleTimeout := viper.GetDuration(server.FlagWsIdleTimeout)
+	if pingInterval > 0 && idleTimeout > 0 && pingInterval >= idleTimeout {
+		panic(fmt.Errorf("the websocket ping interval %s must be shorter than the idle timeout %s", pingInterval, idleTimeout))
+	}
+
+	rpcAddr := "http://localhost:" + port
+	restClient := http.DefaultClient
+	if tlsConfig != nil {
+		rpcAddr = "https://localhost:" + port
+		// the rest-server is called on localhost, which its certificate isn't issued for
+		clientTLSConfig := &tls.Config{InsecureSkipVerify: true}
+		if len(security.ACMEHosts) > 0 {
+			// the ACME certificates are only served for their hosts
+			clientTLSConfig.ServerName = security.ACMEHosts[0]
+		}
+		restClient = &http.Client{Transport: &http.Transport{TLSClientConfig: clientTLSConfig}}
+	}
+
 	return &Server{
-		rpcAddr:      "http://localhost:" + port,
-		wsAddr:       wsAddr,
-		api:          NewAPI(clientCtx, log),
-		logger:       log.With("module", "websocket-server"),
-		connPool:     make(chan struct{}, viper.GetInt(server.FlagWsMaxConnections)),
-		connPoolLock: new(sync.Mutex),
+		rpcAddr:       rpcAddr,
+		wsAddr:        wsAddr,
+		api:           NewAPI(clientCtx, log),
+		logger:        log.With("module", "websocket-server"),
+		vhosts:        security.VHosts,
+		origins:       origins,
+		tlsConfig:     tlsConfig,
+		restClient:    restClient,
+		pingInterval:  pingInterval,
+		idleTimeout:   idleTimeout,
+		shutdownGrace: viper.GetDuration(server.FlagShutdownGrace),
+		maxConnsPerIP: viper.GetInt(server.FlagWsMaxConnectionsPerIP),
+		conns:         make(map[*wsConn]struct{}),
+		connsPerIP:    make(map[string]int),
+		connPool:      make(chan struct{}, viper.GetInt(server.FlagWsMaxConnections)),
+		connPoolLock:  new(sync.Mutex),
 		currentConnNum: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
 			Namespace: monitor.XNameSpace,
 			Subsystem: "websocket",
@@ -79,26 +143,81 @@ func (s *Server) Start() {
 	s.maxConnNum.Set(float64(viper.GetInt(server.FlagWsMaxConnections)))
 	s.currentConnNum.Set(0)
 
+	srv := &http.Server{
+		Addr:      fmt.Sprintf(":%s", s.wsAddr),
+		Handler:   lcd.NewVHostHandler(s.vhosts, ws),
+		TLSConfig: s.tlsConfig,
+	}
+	s.connPoolLock.Lock()
+	s.httpServer = srv
+	s.connPoolLock.Unlock()
 	go func() {
-		err := http.ListenAndServe(fmt.Sprintf(":%s", s.wsAddr), ws)
-		if err != nil {
+		var err error
+		if s.tlsConfig != nil {
+			// the certificates are served by the TLS config
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			s.logger.Error("http error:", err)
 		}
 	}()
 }
 
+// Stop stops accepting connections and drains the open ones: they are sent a going away close message,
+// so that the clients reconnect to another node, and their subscriptions are removed once they answer.
+// The clients which don't answer in time are disconnected.
+func (s *Server) Stop() {
+	s.connPoolLock.Lock()
+	s.closing = true
+	srv := s.httpServer
+	conns := make([]*wsConn, 0, len(s.conns))
+	for conn := range s.conns {
+		conns = append(conns, conn)
+	}
+	s.connPoolLock.Unlock()
+
+	// the hijacked websocket connections aren't closed by the http server
+	if srv != nil {
+		_ = srv.Close()
+	}
+	msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+	for _, conn := range conns {
+		_ = conn.WriteControl(websocket.CloseMessage, msg)
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		s.connWg.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		s.logger.Info("websocket connections drained", "count", len(conns))
+	case <-time.After(s.shutdownGrace):
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+		s.logger.Info("websocket connections closed on shutdown timeout", "count", len(conns))
+	}
+}
+
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.connPoolLock.Lock()
 	defer s.connPoolLock.Unlock()
-	if len(s.connPool) >= cap(s.connPool) {
+	if s.closing || len(s.connPool) >= cap(s.connPool) {
 		w.WriteHeader(http.StatusServiceUnavailable)
 		return
 	}
+	ip := remoteIP(r)
+	if s.maxConnsPerIP > 0 && s.connsPerIP[ip] >= s.maxConnsPerIP {
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
 
 	var upgrader = websocket.Upgrader{
-		CheckOrigin: func(r *http.Request) bool {
-			return true
-		},
+		CheckOrigin: s.checkOrigin,
 	}
 
 	conn, err := upgrader.Upgrade(w, r, nil)
@@ -107,12 +226,69 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.connPool <- struct{}{}
-	s.currentConnNum.Set(float64(len(s.connPool)))
-	go s.readLoop(&wsConn{
+	wsConn := &wsConn{
 		mux:  new(sync.Mutex),
 		conn: conn,
-	})
+		ip:   ip,
+		done: make(chan struct{}),
+	}
+	s.connPool <- struct{}{}
+	s.conns[wsConn] = struct{}{}
+	s.connsPerIP[ip]++
+	s.connWg.Add(1)
+	s.currentConnNum.Set(float64(len(s.connPool)))
+
+	if s.idleTimeout > 0 {
+		// the deadline is extended by every message and pong from the client
+		_ = conn.SetReadDeadline(time.Now().Add(s.idleTimeout))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(s.idleTimeout))
+		})
+	}
+	go s.readLoop(wsConn)
+	if s.pingInterval > 0 {
+		go s.pingLoop(wsConn)
+	}
+}
+
+// pingLoop pings the client until the connection is closed, the pongs keep the idle connection alive
+func (s *Server) pingLoop(wsConn *wsConn) {
+	ticker := time.NewTicker(s.pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := wsConn.WriteControl(websocket.PingMessage, nil); err != nil {
+				s.logger.Debug("websocket failed to ping", "error", err)
+				return
+			}
+		case <-wsConn.done:
+			return
+		}
+	}
+}
+
+// remoteIP returns the ip of the client, the connections are limited per ip
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// checkOrigin accepts the connections from the allowed origins, and the ones without an origin, which
+// aren't made by browsers
+func (s *Server) checkOrigin(r *http.Request) bool {
+	if s.origins == nil {
+		return true
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	_, ok := s.origins[strings.ToLower(origin)]
+	return ok
 }
 
 func (s *Server) sendErrResponse(conn *wsConn, msg string) {
@@ -133,15 +309,26 @@ func (s *Server) sendErrResponse(conn *wsConn, msg string) {
 type wsConn struct {
 	conn *websocket.Conn
 	mux  *sync.Mutex
+	ip   string
+	done chan struct{} // closed when the connection is closed
 }
 
 func (w *wsConn) WriteJSON(v interface{}) error {
 	w.mux.Lock()
 	defer w.mux.Unlock()
 
+	// the clients which don't read their messages must not block the server
+	if err := w.conn.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
+		return err
+	}
 	return w.conn.WriteJSON(v)
 }
 
+func (w *wsConn) WriteControl(messageType int, data []byte) error {
+	// safe to be called concurrently with the other methods
+	return w.conn.WriteControl(messageType, data, time.Now().Add(writeWait))
+}
+
 func (w *wsConn) Close() error {
 	w.mux.Lock()
 	defer w.mux.Unlock()
@@ -162,9 +349,12 @@ func (s *Server) readLoop(wsConn *wsConn) {
 		if err != nil {
 			_ = wsConn.Close()
 			s.logger.Error("failed to read message, close the websocket connection.", "error", err)
-			s.closeWsConnection(subIds)
+			s.closeWsConnection(wsConn, subIds)
 			return
 		}
+		if s.idleTimeout > 0 {
+			_ = wsConn.conn.SetReadDeadline(time.Now().Add(s.idleTimeout))
+		}
 
 		var msg map[string]interface{}
 		if err = json.Unmarshal(mb, &msg); err != nil {
@@ -260,7 +450,7 @@ func (s *Server) tcpGetAndSendResponse(conn *wsConn, mb []byte) error {
 		return fmt.Errorf("failed to request; %s", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := s.restClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to write to rest-server; %s", err)
 	}
@@ -280,7 +470,7 @@ func (s *Server) tcpGetAndSendResponse(conn *wsConn, mb []byte) error {
 	return conn.WriteJSON(wsSend)
 }
 
-func (s *Server) closeWsConnection(subIds map[rpc.ID]struct{}) {
+func (s *Server) closeWsConnection(wsConn *wsConn, subIds map[rpc.ID]struct{}) {
 	for id := range subIds {
 		s.api.unsubscribe(id)
 		delete(subIds, id)
@@ -288,6 +478,12 @@ func (s *Server) closeWsConnection(subIds map[rpc.ID]struct{}) {
 	s.connPoolLock.Lock()
 	defer s.connPoolLock.Unlock()
 	<-s.connPool
+	delete(s.conns, wsConn)
+	if s.connsPerIP[wsConn.ip]--; s.connsPerIP[wsConn.ip] <= 0 {
+		delete(s.connsPerIP, wsConn.ip)
+	}
+	close(wsConn.done)
+	s.connWg.Done()
 	s.currentConnNum.Set(float64(len(s.connPool)))
 }
 
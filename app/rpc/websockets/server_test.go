@@ -0,0 +1,116 @@
+package websockets
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/metrics/discard"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+
+	"github.com/okex/exchain/libs/tendermint/libs/log"
+)
+
+func newTestServer(maxConns, maxConnsPerIP int, pingInterval, idleTimeout time.Duration) (*Server, string, func()) {
+	s := &Server{
+		logger:         log.NewNopLogger(),
+		pingInterval:   pingInterval,
+		idleTimeout:    idleTimeout,
+		shutdownGrace:  5 * time.Second,
+		maxConnsPerIP:  maxConnsPerIP,
+		conns:          make(map[*wsConn]struct{}),
+		connsPerIP:     make(map[string]int),
+		connPool:       make(chan struct{}, maxConns),
+		connPoolLock:   new(sync.Mutex),
+		currentConnNum: discard.NewGauge(),
+		maxConnNum:     discard.NewGauge(),
+	}
+	ts := httptest.NewServer(s)
+	return s, "ws" + strings.TrimPrefix(ts.URL, "http"), ts.Close
+}
+
+func dial(t *testing.T, url string) (*websocket.Conn, int) {
+	conn, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		require.NotNil(t, resp, err)
+		return nil, resp.StatusCode
+	}
+	return conn, resp.StatusCode
+}
+
+func connCount(s *Server) int {
+	s.connPoolLock.Lock()
+	defer s.connPoolLock.Unlock()
+	return len(s.conns)
+}
+
+func TestServerConnectionLimits(t *testing.T) {
+	s, url, closeFn := newTestServer(3, 2, 0, 0)
+	defer closeFn()
+
+	conn1, code := dial(t, url)
+	require.Equal(t, http.StatusSwitchingProtocols, code)
+	conn2, _ := dial(t, url)
+	// the connections from the same ip are limited
+	_, code = dial(t, url)
+	require.Equal(t, http.StatusTooManyRequests, code)
+	require.Equal(t, 2, connCount(s))
+
+	// a closed connection frees its place
+	require.NoError(t, conn1.Close())
+	require.Eventually(t, func() bool { return connCount(s) == 1 }, time.Second, 10*time.Millisecond)
+	conn3, code := dial(t, url)
+	require.Equal(t, http.StatusSwitchingProtocols, code)
+
+	// the global limit applies whatever the ip
+	s.maxConnsPerIP = 0
+	conn4, _ := dial(t, url)
+	_, code = dial(t, url)
+	require.Equal(t, http.StatusServiceUnavailable, code)
+
+	for _, conn := range []*websocket.Conn{conn2, conn3, conn4} {
+		require.NoError(t, conn.Close())
+	}
+	require.Eventually(t, func() bool { return connCount(s) == 0 }, time.Second, 10*time.Millisecond)
+	require.Empty(t, s.connsPerIP)
+}
+
+func TestServerKeepalive(t *testing.T) {
+	s, url, closeFn := newTestServer(10, 0, 50*time.Millisecond, 200*time.Millisecond)
+	defer closeFn()
+
+	// the client answers the pings as long as it reads the connection
+	pings := make(chan struct{}, 100)
+	alive, _ := dial(t, url)
+	alive.SetPingHandler(func(data string) error {
+		pings <- struct{}{}
+		return alive.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+	})
+	aliveErr := make(chan error, 1)
+	go func() {
+		_, _, err := alive.ReadMessage()
+		aliveErr <- err
+	}()
+
+	// the client which doesn't read never answers the pings and times out
+	_, _ = dial(t, url)
+	require.Eventually(t, func() bool { return connCount(s) == 1 }, 2*time.Second, 10*time.Millisecond)
+
+	time.Sleep(400 * time.Millisecond)
+	require.Equal(t, 1, connCount(s))
+	require.NotEmpty(t, pings)
+
+	// the connections are drained on stop
+	s.Stop()
+	require.Equal(t, 0, connCount(s))
+	err := <-aliveErr
+	require.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), err)
+
+	// no connection is accepted after the stop
+	_, code := dial(t, url)
+	require.Equal(t, http.StatusServiceUnavailable, code)
+}
@@ -0,0 +1,116 @@
+package websockets
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/okex/exchain/libs/tendermint/libs/log"
+	coretypes "github.com/okex/exchain/libs/tendermint/rpc/core/types"
+)
+
+const (
+	// syncingPollInterval is the interval the sync status of the node is polled at
+	syncingPollInterval = time.Second
+	// maxWatcherLag is the number of blocks the watcher can lag behind the chain before the node is reported
+	// as syncing, as the rpc serves stale data meanwhile
+	maxWatcherLag = 3
+)
+
+// SyncingResult is notified to the syncing subscriptions when the node starts catching up, false is
+// notified when it's done, as in the ethereum clients
+type SyncingResult struct {
+	Syncing bool         `json:"syncing"`
+	Status  SyncProgress `json:"status"`
+}
+
+// SyncProgress is the progress of the node catching up
+type SyncProgress struct {
+	StartingBlock hexutil.Uint64 `json:"startingBlock"`
+	CurrentBlock  hexutil.Uint64 `json:"currentBlock"`
+	HighestBlock  hexutil.Uint64 `json:"highestBlock"`
+	// WatcherBlock is the latest block of the watcher the rpc queries are served from, if it's enabled
+	WatcherBlock *hexutil.Uint64 `json:"watcherBlock,omitempty"`
+}
+
+// syncMonitor polls the sync status of the node for all the syncing subscriptions. The node is syncing
+// while tendermint fast-syncs the chain or while the watcher lags behind it.
+type syncMonitor struct {
+	status        func() (*coretypes.ResultStatus, error)
+	watcherHeight func() (uint64, error) // nil if the watcher is disabled
+	logger        log.Logger
+
+	once    sync.Once
+	mtx     sync.Mutex
+	known   bool // whether the status has been polled yet
+	syncing bool
+	result  interface{}
+	changed chan struct{} // closed when the status changes
+}
+
+func newSyncMonitor(status func() (*coretypes.ResultStatus, error), watcherHeight func() (uint64, error), logger log.Logger) *syncMonitor {
+	return &syncMonitor{
+		status:        status,
+		watcherHeight: watcherHeight,
+		logger:        logger,
+		changed:       make(chan struct{}),
+	}
+}
+
+// current returns the sync status, known is false until it's polled, and a channel closed on the next change
+func (m *syncMonitor) current() (known, syncing bool, result interface{}, changed <-chan struct{}) {
+	m.once.Do(func() { go m.run() })
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.known, m.syncing, m.result, m.changed
+}
+
+func (m *syncMonitor) run() {
+	ticker := time.NewTicker(syncingPollInterval)
+	defer ticker.Stop()
+	for {
+		m.poll()
+		<-ticker.C
+	}
+}
+
+func (m *syncMonitor) poll() {
+	status, err := m.status()
+	if err != nil {
+		m.logger.Error("failed to get the sync status", "error", err)
+		return
+	}
+	syncing := status.SyncInfo.CatchingUp
+	progress := SyncProgress{
+		StartingBlock: hexutil.Uint64(status.SyncInfo.EarliestBlockHeight),
+		CurrentBlock:  hexutil.Uint64(status.SyncInfo.LatestBlockHeight),
+	}
+	if m.watcherHeight != nil {
+		height, err := m.watcherHeight()
+		if err != nil {
+			// the watcher has no block yet
+			height = 0
+		}
+		watcherBlock := hexutil.Uint64(height)
+		progress.WatcherBlock = &watcherBlock
+		if status.SyncInfo.LatestBlockHeight-int64(height) > maxWatcherLag {
+			syncing = true
+		}
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if m.known && syncing == m.syncing {
+		return
+	}
+	m.known = true
+	m.syncing = syncing
+	if syncing {
+		m.result = &SyncingResult{Syncing: true, Status: progress}
+	} else {
+		m.result = false
+	}
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
@@ -0,0 +1,91 @@
+package websockets
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/okex/exchain/libs/tendermint/libs/log"
+	coretypes "github.com/okex/exchain/libs/tendermint/rpc/core/types"
+)
+
+func TestSyncMonitor(t *testing.T) {
+	var (
+		catchingUp    bool
+		height        int64 = 100
+		watcherHeight uint64
+		statusErr     error
+	)
+	m := newSyncMonitor(func() (*coretypes.ResultStatus, error) {
+		return &coretypes.ResultStatus{SyncInfo: coretypes.SyncInfo{
+			EarliestBlockHeight: 1,
+			LatestBlockHeight:   height,
+			CatchingUp:          catchingUp,
+		}}, statusErr
+	}, func() (uint64, error) { return watcherHeight, nil }, log.NewNopLogger())
+	// the status is polled by the test
+	m.once.Do(func() {})
+
+	// the status is unknown until polled
+	statusErr = errors.New("unavailable")
+	m.poll()
+	known, _, _, changed := m.current()
+	require.False(t, known)
+
+	// the watcher lagging behind the chain is syncing
+	statusErr = nil
+	m.poll()
+	known, syncing, result, _ := m.current()
+	require.True(t, known)
+	require.True(t, syncing)
+	watcherBlock := hexutil.Uint64(0)
+	require.Equal(t, &SyncingResult{Syncing: true, Status: SyncProgress{
+		StartingBlock: 1,
+		CurrentBlock:  100,
+		WatcherBlock:  &watcherBlock,
+	}}, result)
+	require.True(t, isClosed(changed))
+
+	// the progress isn't notified
+	_, _, _, changed = m.current()
+	height = 101
+	watcherHeight = 90
+	m.poll()
+	require.False(t, isClosed(changed))
+
+	// a lag below the max is synced
+	watcherHeight = 100
+	m.poll()
+	_, syncing, result, _ = m.current()
+	require.False(t, syncing)
+	require.Equal(t, false, result)
+	require.True(t, isClosed(changed))
+
+	// fast sync
+	_, _, _, changed = m.current()
+	catchingUp = true
+	watcherHeight = 101
+	m.poll()
+	_, syncing, _, _ = m.current()
+	require.True(t, syncing)
+	require.True(t, isClosed(changed))
+
+	// without the watcher only the fast sync is syncing
+	m.watcherHeight = nil
+	catchingUp = false
+	m.poll()
+	_, syncing, result, _ = m.current()
+	require.False(t, syncing)
+	require.Equal(t, false, result)
+}
+
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
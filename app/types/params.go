@@ -3,6 +3,8 @@ package types
 const (
 	// DefaultGasPrice is default gas price for evm transactions
 	DefaultGasPrice = 1
+	// DefaultGasPriceDecimals is the decimals of DefaultGasPrice served by eth_gasPrice, i.e. 0.1 gwei
+	DefaultGasPriceDecimals = 10
 	// DefaultRPCGasLimit is default gas limit for RPC call operations
 	DefaultRPCGasLimit = 30000000
 )
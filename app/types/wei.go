@@ -0,0 +1,60 @@
+package types
+
+import (
+	"math/big"
+
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+)
+
+// The amounts of the native token are sdk.Dec with 18 decimals, as many as the wei of the evm, so they convert
+// exactly with DecToWei and WeiToDec: the balances, the fees and the tx values must all cross between the two
+// representations through them to agree. The amounts with other decimals, e.g. the prices defined with 8 or
+// 10 decimals, are scaled by ScaleDecimals with an explicit rounding.
+
+// Rounding is how an amount losing precision is rounded
+type Rounding int
+
+const (
+	// RoundDown truncates towards zero, the balances round down so they never exceed what can be spent
+	RoundDown Rounding = iota
+	// RoundUp rounds away from zero, the fees and costs round up so they're never less than what is charged
+	RoundUp
+)
+
+// DecToWei returns the wei of the native token amount, 0 for a nil amount
+func DecToWei(amount sdk.Dec) *big.Int {
+	if amount.IsNil() {
+		return new(big.Int)
+	}
+	return amount.BigInt()
+}
+
+// WeiToDec returns the native token amount of the wei, 0 for nil
+func WeiToDec(wei *big.Int) sdk.Dec {
+	if wei == nil {
+		return sdk.ZeroDec()
+	}
+	return sdk.NewDecFromBigIntWithPrec(wei, sdk.Precision)
+}
+
+// ScaleDecimals returns the amount with from decimals as an amount with to decimals. The amount is rounded
+// if it loses precision.
+func ScaleDecimals(amount *big.Int, from, to uint, rounding Rounding) *big.Int {
+	if amount == nil {
+		return new(big.Int)
+	}
+	if from <= to {
+		return new(big.Int).Mul(amount, pow10(to-from))
+	}
+
+	quo, rem := new(big.Int).QuoRem(amount, pow10(from-to), new(big.Int))
+	if rounding == RoundUp && rem.Sign() != 0 {
+		// QuoRem truncates towards zero, away from zero is one more unit of the sign of the amount
+		quo.Add(quo, big.NewInt(int64(rem.Sign())))
+	}
+	return quo
+}
+
+func pow10(n uint) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
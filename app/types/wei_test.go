@@ -0,0 +1,87 @@
+package types
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+)
+
+func TestDecToWei(t *testing.T) {
+	require.Equal(t, big.NewInt(0), DecToWei(sdk.Dec{}))
+	require.Equal(t, big.NewInt(1), DecToWei(sdk.SmallestDec()))
+	require.Equal(t, big.NewInt(1e18), DecToWei(sdk.OneDec()))
+	require.Equal(t, big.NewInt(-15e17), DecToWei(sdk.NewDecWithPrec(-15, 1)))
+
+	require.True(t, WeiToDec(nil).IsZero())
+	require.Equal(t, sdk.NewDecWithPrec(15, 1), WeiToDec(big.NewInt(15e17)))
+
+	// the conversion doesn't share the wei with the dec
+	wei := big.NewInt(1)
+	dec := WeiToDec(wei)
+	wei.SetInt64(2)
+	require.Equal(t, sdk.SmallestDec(), dec)
+}
+
+func TestWeiDecRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		wei := new(big.Int).Rand(r, new(big.Int).Lsh(big.NewInt(1), uint(r.Intn(200))))
+		if r.Intn(2) == 0 {
+			wei.Neg(wei)
+		}
+
+		dec := WeiToDec(wei)
+		require.Equal(t, 0, wei.Cmp(DecToWei(dec)), wei.String())
+		require.True(t, dec.Equal(WeiToDec(DecToWei(dec))), wei.String())
+
+		// the sums agree in both representations, as the fees and the values charged from the balances
+		other := new(big.Int).Rand(r, big.NewInt(1e18))
+		require.Equal(t, 0, new(big.Int).Add(wei, other).Cmp(DecToWei(dec.Add(WeiToDec(other)))), wei.String())
+	}
+}
+
+func TestScaleDecimals(t *testing.T) {
+	testCases := []struct {
+		amount   int64
+		from, to uint
+		rounding Rounding
+		expected int64
+	}{
+		{1, 10, 18, RoundDown, 1e8},
+		{1, 8, 18, RoundUp, 1e10},
+		{123456789012, 18, 8, RoundDown, 12},
+		{123456789012, 18, 8, RoundUp, 13},
+		{-123456789012, 18, 8, RoundDown, -12},
+		{-123456789012, 18, 8, RoundUp, -13},
+		{12e10, 18, 8, RoundUp, 12},
+		{5, 18, 18, RoundUp, 5},
+	}
+	for _, tc := range testCases {
+		require.Equal(t, big.NewInt(tc.expected), ScaleDecimals(big.NewInt(tc.amount), tc.from, tc.to, tc.rounding), tc)
+	}
+	require.Equal(t, big.NewInt(0), ScaleDecimals(nil, 8, 18, RoundDown))
+}
+
+func TestScaleDecimalsRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		wei := new(big.Int).Rand(r, new(big.Int).Lsh(big.NewInt(1), uint(r.Intn(128))))
+		decimals := uint(r.Intn(sdk.Precision + 1))
+
+		// the rounded down amount is never more than the wei, the rounded up one never less, by less than a unit
+		down := ScaleDecimals(ScaleDecimals(wei, sdk.Precision, decimals, RoundDown), decimals, sdk.Precision, RoundDown)
+		up := ScaleDecimals(ScaleDecimals(wei, sdk.Precision, decimals, RoundUp), decimals, sdk.Precision, RoundDown)
+		unit := pow10(sdk.Precision - decimals)
+		require.True(t, down.Cmp(wei) <= 0 && new(big.Int).Sub(wei, down).Cmp(unit) < 0, wei.String())
+		require.True(t, up.Cmp(wei) >= 0 && new(big.Int).Sub(up, wei).Cmp(unit) < 0, wei.String())
+
+		// scaling up is exact
+		amount := new(big.Int).Rand(r, big.NewInt(1e12))
+		require.Equal(t, 0, amount.Cmp(ScaleDecimals(ScaleDecimals(amount, decimals, sdk.Precision, RoundDown),
+			sdk.Precision, decimals, RoundUp)), amount.String())
+	}
+}
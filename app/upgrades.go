@@ -0,0 +1,24 @@
+package app
+
+import (
+	"github.com/okex/exchain/x/evm"
+)
+
+// registerUpgrades registers the state migrations run by the upgrades of the chain, keyed by the name of their
+// plan, and sets the handlers running them to the upgrade keeper. The evm migrations are registered with
+// RegisterMigrations, e.g.
+//
+//	app.EvmKeeper.RegisterMigrations("v1.2.0",
+//		evm.RenameParamMigration("EnableCreate", "EnableContractCreation"),
+//		evm.RekeyStoreMigration([]byte{0x13}, []byte{0x14}),
+//	)
+//
+// The params are renamed by their param store keys (ParamStoreKey* of the evm types) and the new key must be in
+// the key table. The entries under the new prefix of a rekey are overwritten, it must not be one of the prefixes
+// holding the live state (KeyPrefix* of the evm types), e.g. 0x13 and 0x14 are past them.
+//
+// The migrations of an upgrade can be tried on the current state with the dry-run-migrations query command of
+// the evm module before the upgrade height.
+func (app *OKExChainApp) registerUpgrades() {
+	evm.RegisterUpgradeHandlers(app.UpgradeKeeper, app.EvmKeeper)
+}
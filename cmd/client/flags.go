@@ -1,14 +1,21 @@
 package client
 
 import (
+	"time"
+
 	"github.com/okex/exchain/app"
+	"github.com/okex/exchain/app/analytics"
 	"github.com/okex/exchain/app/config"
 	"github.com/okex/exchain/app/rpc"
 	"github.com/okex/exchain/app/rpc/namespaces/eth"
 	"github.com/okex/exchain/app/rpc/namespaces/eth/filters"
+	"github.com/okex/exchain/app/rpc/readonly"
 	"github.com/okex/exchain/app/types"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
 	"github.com/okex/exchain/libs/tendermint/consensus"
 	"github.com/okex/exchain/libs/tendermint/libs/automation"
+	"github.com/okex/exchain/libs/tendermint/libs/tracing"
+	"github.com/okex/exchain/libs/tendermint/privval"
 	evmtypes "github.com/okex/exchain/x/evm/types"
 	"github.com/okex/exchain/x/evm/watcher"
 	"github.com/okex/exchain/x/stream"
@@ -20,7 +27,13 @@ import (
 func RegisterAppFlag(cmd *cobra.Command) {
 	cmd.Flags().Bool(watcher.FlagFastQuery, false, "Enable the fast query mode for rpc queries")
 	cmd.Flags().Int(watcher.FlagFastQueryLru, 1000, "Set the size of LRU cache under fast-query mode")
+	cmd.Flags().Bool(watcher.FlagBalanceHistory, false, "Index the native balance of the updated accounts at the end of each block for exchain_getBalanceHistory, requires the fast-query mode")
+	cmd.Flags().Int(watcher.FlagCommitQueueSize, 64, "The number of writes of the committed blocks queued to the watcher db before the commit of the next blocks waits for them")
+	cmd.Flags().Int(watcher.FlagBreakerThreshold, 5, "Route the fast queries of a data type straight to the chain after the watcher fails on it in a row as many times, 0 to disable")
+	cmd.Flags().Duration(watcher.FlagBreakerCooldown, 30*time.Second, "The period after which the watcher is probed again by the fast queries routed to the chain")
 	cmd.Flags().Bool(rpc.FlagPersonalAPI, true, "Enable the personal_ prefixed set of APIs in the Web3 JSON-RPC spec")
+	cmd.Flags().Bool(rpc.FlagAdminAPI, false, "Enable the admin_ prefixed set of APIs reporting the health of the node, e.g. of its remote signer, and managing the sender scores of its mempool")
+	cmd.Flags().Bool(rpc.FlagWebhookAPI, false, "Enable the webhook_ prefixed set of APIs registering http callbacks notified of the activity of addresses or log topics, requires the fast-query mode")
 	cmd.Flags().Bool(evmtypes.FlagEnableBloomFilter, false, "Enable bloom filter for event logs")
 	cmd.Flags().Int64(filters.FlagGetLogsHeightSpan, 2000, "config the block height span for get logs")
 	cmd.Flags().String(stream.NacosTmrpcUrls, "", "Stream plugin`s nacos server urls for discovery service of tendermint rpc")
@@ -35,6 +48,30 @@ func RegisterAppFlag(cmd *cobra.Command) {
 	cmd.Flags().Int(rpc.FlagRateLimitCount, 0, "Set the count of requests allowed per second of rpc rate limiter")
 	cmd.Flags().Int(rpc.FlagRateLimitBurst, 1, "Set the concurrent count of requests allowed of rpc rate limiter")
 	cmd.Flags().Uint64(config.FlagGasLimitBuffer, 50, "Percentage to increase gas limit")
+	cmd.Flags().Int(rpc.FlagAdmissionTxLimit, 0, "Max number of concurrent transaction submissions of the rpc server, 0 for no limit")
+	cmd.Flags().Int(rpc.FlagAdmissionCallLimit, 0, "Max number of concurrent eth_call and eth_estimateGas requests of the rpc server, 0 for no limit")
+	cmd.Flags().Int(rpc.FlagAdmissionDefaultLimit, 0, "Max number of concurrent requests of the rpc server which are neither submissions, calls nor low priority, 0 for no limit")
+	cmd.Flags().Int(rpc.FlagAdmissionLowLimit, 0, "Max number of concurrent low priority requests of the rpc server, such as traces and wide eth_getLogs, 0 for no limit. "+
+		"They are rejected as soon as the submissions or calls are queued")
+	cmd.Flags().Duration(rpc.FlagAdmissionQueueTimeout, time.Second, "Time the rpc requests wait for a slot of their priority class before being rejected with 429")
+	cmd.Flags().Int64(rpc.FlagAdmissionWideLogsSpan, 100, "Block range above which eth_getLogs is a low priority request")
+	cmd.Flags().String(rpc.FlagResponseCache, "", "Methods whose responses are cached by the rpc server with their max age, such as \"eth_chainId=0,eth_getBlockByNumber=10m,eth_getCode=1m\", "+
+		"0 never expires the responses. The responses at the latest block are dropped on the next block")
+	cmd.Flags().Int(rpc.FlagResponseCacheSize, 10000, "Max number of responses cached by the rpc server")
+	cmd.Flags().Bool(rpc.FlagDedup, true, "Execute the concurrent identical requests of the rpc server once, sharing the response, except the submissions and the filters")
+	cmd.Flags().Bool(rpc.FlagCompression, true, "Compress the responses of the rpc server with gzip or deflate for the clients sending Accept-Encoding")
+	cmd.Flags().Int(rpc.FlagCompressionMinSize, 1024, "Size in bytes below which the responses of the rpc server aren't compressed")
+	cmd.Flags().Bool(rpc.FlagMsgpack, false, "Encode the responses of the rpc server in msgpack for the clients accepting application/msgpack")
+	cmd.Flags().Bool(rpc.FlagStream, false, "Stream the results of eth_getLogs to the clients sending the X-Rpc-Stream header, as newline delimited json (ndjson) or as a chunked json array (array)")
+	cmd.Flags().String(rpc.FlagRecordTraffic, "", "Path of a jsonl file the single requests of the rpc server and their responses are appended to, to replay them with exchaind rpc-replay")
+	cmd.Flags().Int(rpc.FlagWarmUpBlocks, 0, "Number of the latest blocks whose headers, txs and receipts are read before the rpc server starts serving, once the watcher has caught up with the chain, 0 disables the warm-up")
+	cmd.Flags().Duration(rpc.FlagWarmUpTimeout, 30*time.Second, "Max time the warm-up waits for the watcher to catch up with the chain before the rpc server starts serving")
+	cmd.Flags().String(rpc.FlagVerifierSolcDir, "", "Dir of the solc binaries named solc-<version> compiling the sources of exchain_verifyContract, empty disables the contract verification. The compiler runs unisolated as the user of the node, without memory limits")
+	cmd.Flags().Int64(rpc.FlagMaxBodySize, 5<<20, "Max size in bytes of the body of the rpc requests, batches included, answered with a json-rpc error. The server never reads more than 5MB, 0 leaves the limit to the server")
+	cmd.Flags().Int(rpc.FlagMaxParamsDepth, 32, "Max nesting of the arrays and the objects of the params of the rpc requests, 0 for no limit")
+	cmd.Flags().Int(rpc.FlagMaxParamsLength, 10000, "Max number of elements of each array and object of the params of the rpc requests, 0 for no limit")
+	cmd.Flags().Bool(rpc.FlagStrictInput, false, "Reject the hex address params with an invalid EIP-55 checksum and the block numbers which aren't canonical hex quantities, instead of normalizing them")
+	cmd.Flags().Bool(readonly.FlagReadOnly, false, "Reject the signing and the broadcast methods of the rpc server, such as eth_sendTransaction, eth_sendRawTransaction and personal_*, whatever the keys on disk")
 	cmd.Flags().String(rpc.FlagDisableAPI, "", "Set the RPC API to be disabled, such as \"eth_getLogs,eth_newFilter,eth_newBlockFilter,eth_newPendingTransactionFilter,eth_getFilterChanges\"")
 	cmd.Flags().Int(config.FlagDynamicGpWeight, 80, "The recommended weight of dynamic gas price [1,100])")
 	cmd.Flags().Bool(config.FlagEnableDynamicGp, true, "Enable node to dynamic support gas price suggest")
@@ -50,12 +87,28 @@ func RegisterAppFlag(cmd *cobra.Command) {
 	cmd.Flags().Bool(eth.FlagEnableTxPool, false, "Enable the function of txPool to support concurrency call eth_sendRawTransaction")
 	cmd.Flags().Uint64(eth.TxPoolCap, 10000, "Set the txPool slice max length")
 	cmd.Flags().Int(eth.BroadcastPeriodSecond, 10, "every BroadcastPeriodSecond second check the txPool, and broadcast when it's eligible")
+	cmd.Flags().String(eth.FlagTxRelayEndpoints, "", "Comma separated tendermint rpc addresses of the sentries or validators the accepted txs are also relayed to, e.g. \"tcp://10.0.0.1:26657\"")
+	cmd.Flags().Int(eth.FlagTxRelayQueueSize, 10000, "Number of txs queued per relay endpoint, the txs are dropped once it is full")
+	cmd.Flags().String(eth.FlagPrivateTxRelayEndpoints, "", "Comma separated tendermint rpc addresses of the validators or the trusted relays the txs of exchain_sendPrivateTransaction are relayed to without being gossiped, e.g. \"tcp://10.0.0.1:26657\"")
 
 	cmd.Flags().Bool(rpc.FlagEnableMonitor, false, "Enable the rpc monitor and register rpc metrics to prometheus")
 
 	cmd.Flags().String(rpc.FlagKafkaAddr, "", "The address of kafka cluster to consume pending txs")
 	cmd.Flags().String(rpc.FlagKafkaTopic, "", "The topic that the kafka writer will produce messages to")
 
+	cmd.Flags().String(analytics.FlagAnalyticsExportDir, "", "Continuously export the blocks, txs, receipts and logs of the fast-query mode into this directory, empty disables the export")
+	cmd.Flags().String(analytics.FlagAnalyticsFormat, analytics.FormatCSV, "Format of the exported files: csv")
+	cmd.Flags().String(analytics.FlagAnalyticsPartition, analytics.PartitionDay, "Partitioning of the exported files: day | height")
+	cmd.Flags().Uint64(analytics.FlagAnalyticsPartitionSize, 100000, "Number of blocks of the height partitions")
+	cmd.Flags().Duration(analytics.FlagAnalyticsExportInterval, time.Minute, "Interval of the continuous export")
+
+	cmd.Flags().Bool(app.FlagDev, false, "Run a single node dev chain, sealing blocks on tx arrival, with pre-funded dev accounts in the genesis of a new chain")
+	cmd.Flags().Duration(app.FlagDevBlockInterval, 0, "Seal the dev blocks on this interval rather than on tx arrival")
+	cmd.Flags().Int(app.FlagDevAccounts, 10, "Number of pre-funded dev accounts")
+	cmd.Flags().String(app.FlagDevBalance, "10000"+sdk.DefaultBondDenom, "Balance of the pre-funded dev accounts")
+
+	cmd.Flags().String(rpc.FlagGRPCAddress, "", "The address the grpc query services listen on, such as \"0.0.0.0:9090\", empty disables the grpc server")
+
 	cmd.Flags().Bool(config.FlagEnableDynamic, false, "Enable dynamic configuration for nodes")
 	cmd.Flags().String(config.FlagApollo, "", "Apollo connection config(IP|AppID|NamespaceName) for dynamic configuration")
 
@@ -69,6 +122,29 @@ func RegisterAppFlag(cmd *cobra.Command) {
 	cmd.Flags().Bool(evmtypes.FlagTraceDisableStorage, false, "Disable storage output for evm trace")
 	cmd.Flags().Bool(evmtypes.FlagTraceDisableReturnData, false, "Disable return data output for evm trace")
 	cmd.Flags().Bool(evmtypes.FlagTraceDebug, false, "Output full trace logs for evm")
+	cmd.Flags().String(evmtypes.FlagTraceTracer, "", "Save the evm traces with the given tracer, e.g. callTracer, instead of the struct logs")
+
+	cmd.Flags().Bool(evmtypes.FlagEnableSignatures, false, "Enable the signatures db rendering the calls and the events of the evm traces and of the pending txs, enabled along with the evm traces")
+	cmd.Flags().String(evmtypes.FlagSignatures4ByteURL, "https://www.4byte.directory", "The 4byte directory resolving the unknown signatures of the signatures db")
+
+	cmd.Flags().String(evmtypes.FlagTxBlacklistFile, "", "Json file of the rules refusing the admission to the mempool of the evm txs by target address and calldata selector, managed with admin_addTxBlacklistRule, empty keeps the rules in memory")
+	cmd.Flags().Bool(evmtypes.FlagEnableStateDiffFeed, false, "Capture the changes made by the evm txs of each block for the accountChanges websocket subscriptions")
+	cmd.Flags().Bool(evmtypes.FlagEnableContractRedeployAudit, false, "Audit contracts redeployed over self-destructed ones, requires the fast-query mode")
+	cmd.Flags().Bool(evmtypes.FlagEnableEvmProfiler, false, "Enable the evm profiler to collect the gas used, calls and opcodes of contracts per block. "+
+		"The delivered txs are executed with a tracer in debug mode, which slows down the block execution")
+	cmd.Flags().Int(evmtypes.FlagJumpDestCacheSize, evmtypes.DefaultJumpDestCacheSize, "Number of contracts whose JUMPDEST analysis is cached across txs and blocks, 0 disables the cache")
+	cmd.Flags().Int(evmtypes.FlagPrecompileCacheSize, evmtypes.DefaultPrecompileCacheSize, "Number of outputs of the pure precompiled contracts (e.g. ecrecover) cached within a block, 0 disables the cache")
+	cmd.Flags().Bool(evmtypes.FlagEnablePrefetch, false, "Enable the prefetch of the evm accounts and storage slots read by the txs of a block, concurrently with their execution")
+	cmd.Flags().Int(evmtypes.FlagPrefetchHistorySize, evmtypes.DefaultPrefetchHistorySize, "Number of tx recipients whose recently touched storage slots are prefetched")
+
+	// flags for the pruning of the evm store, apart from the global pruning
+	cmd.Flags().Uint64(evmtypes.FlagEvmPruningKeepRecent, 0, "Number of recent heights of the evm and acc stores to keep on disk, used when evm-pruning-interval is set, at most the global one")
+	cmd.Flags().Uint64(evmtypes.FlagEvmPruningKeepEvery, 0, "Offset heights of the evm and acc stores to keep on disk after 'keep-recent', 0 keeps none of them, else a multiple of the global one")
+	cmd.Flags().Uint64(evmtypes.FlagEvmPruningInterval, 0, "Height interval at which the evm and acc stores are pruned with their own options, 0 follows the global pruning (can't be set with the iavl async commit)")
+
+	// flags for the tracing of the rpc queries
+	cmd.Flags().String(tracing.FlagOTLPEndpoint, "", "OTLP/HTTP collector url the spans of the rpc queries are exported to, such as \"http://127.0.0.1:4318/v1/traces\", empty disables the tracing")
+	cmd.Flags().Float64(tracing.FlagSampleRatio, 0.01, "Ratio of the rpc requests to trace, in (0, 1]")
 
 	cmd.Flags().Bool(config.FlagPprofAutoDump, false, "Enable auto dump pprof")
 	cmd.Flags().String(config.FlagPprofCollectInterval, "5s", "Interval for pprof dump loop")
@@ -93,4 +169,12 @@ func RegisterAppFlag(cmd *cobra.Command) {
 	cmd.Flags().String(automation.ConsensusTestcase, "", "consensus test case file")
 
 	cmd.Flags().Bool(app.FlagEnableRepairState, false, "Enable auto repair state on start")
+
+	cmd.Flags().Bool(privval.FlagStandby, false, "Run as the standby of another node: neither sign nor accept txs until promoted by the unsafe_promote_standby rpc")
+	cmd.Flags().String(privval.FlagStandbyLockRedisURL, "", "Address of the redis holding the signer lock of the standby pair, which protects it against double signing")
+	cmd.Flags().String(privval.FlagStandbyLockAuth, "", "Password of the redis holding the signer lock")
+	cmd.Flags().String(privval.FlagStandbyLockKey, "exchain-signer-lock", "Redis key of the signer lock, shared by the nodes of the standby pair")
+	cmd.Flags().Duration(privval.FlagStandbyLockTTL, 10*time.Second, "Lease of the signer lock, at least 1s, the promoted node stops signing once it can't extend it")
+	cmd.Flags().Bool(app.FlagEnableConsistencyCheck, false, "Check the watcher db and the bloom index against the application state on start, and rebuild the missing blocks from the block store")
+	cmd.Flags().Int64(app.FlagConsistencyCheckMaxBlocks, 10000, "Maximum number of blocks rebuilt by the consistency check, a watcher db or a bloom index further behind fails the start")
 }
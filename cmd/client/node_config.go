@@ -0,0 +1,237 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/okex/exchain/app/config"
+	"github.com/okex/exchain/app/rpc"
+	"github.com/okex/exchain/app/rpc/namespaces/eth/filters"
+	"github.com/okex/exchain/app/rpc/readonly"
+	"github.com/okex/exchain/app/rpc/respcache"
+	"github.com/okex/exchain/libs/cosmos-sdk/server"
+	abci "github.com/okex/exchain/libs/tendermint/abci/types"
+	"github.com/okex/exchain/libs/tendermint/libs/log"
+	evmtypes "github.com/okex/exchain/x/evm/types"
+	"github.com/okex/exchain/x/evm/watcher"
+	"github.com/spf13/viper"
+)
+
+// NodeConfig is the typed view of the rpc, watcher, bloom and mempool settings of the node, wherever they are set:
+// the flags, the OKEXCHAIN_ prefixed environment variables or the config files
+type NodeConfig struct {
+	RPC     RPCConfig     `json:"rpc"`
+	Watcher WatcherConfig `json:"watcher"`
+	Bloom   BloomConfig   `json:"bloom"`
+	Mempool MempoolConfig `json:"mempool"`
+}
+
+type RPCConfig struct {
+	PersonalAPI     bool   `json:"personal_api"`
+	AdminAPI        bool   `json:"admin_api"`
+	WebhookAPI      bool   `json:"webhook_api"`
+	DisableAPI      string `json:"disable_api"`
+	ReadOnly        bool   `json:"read_only"`
+	StrictInput     bool   `json:"strict_input"`
+	MaxBodySize     int64  `json:"max_body_size"`
+	MaxParamsDepth  int    `json:"max_params_depth"`
+	MaxParamsLength int    `json:"max_params_length"`
+	RateLimitAPI    string `json:"rate_limit_api"`
+	RateLimitCount  int    `json:"rate_limit_count"`
+	RateLimitBurst  int    `json:"rate_limit_burst"`
+	LogsHeightSpan  int64  `json:"logs_height_span"`
+
+	WarmUpBlocks  int           `json:"warmup_blocks"`
+	WarmUpTimeout time.Duration `json:"warmup_timeout"`
+
+	AdmissionTxLimit      int           `json:"admission_tx_limit"`
+	AdmissionCallLimit    int           `json:"admission_call_limit"`
+	AdmissionDefaultLimit int           `json:"admission_default_limit"`
+	AdmissionLowLimit     int           `json:"admission_low_limit"`
+	AdmissionQueueTimeout time.Duration `json:"admission_queue_timeout"`
+	AdmissionWideLogsSpan int64         `json:"admission_wide_logs_span"`
+
+	ResponseCache      string `json:"response_cache"`
+	ResponseCacheSize  int    `json:"response_cache_size"`
+	Dedup              bool   `json:"dedup"`
+	Compression        bool   `json:"compression"`
+	CompressionMinSize int    `json:"compression_min_size"`
+	Msgpack            bool   `json:"msgpack"`
+	Stream             bool   `json:"stream"`
+
+	GasLimitBuffer  uint64 `json:"gas_limit_buffer"`
+	EnableDynamicGp bool   `json:"enable_dynamic_gp"`
+	DynamicGpWeight int    `json:"dynamic_gp_weight"`
+}
+
+type WatcherConfig struct {
+	FastQuery        bool          `json:"fast_query"`
+	LruSize          int           `json:"lru_size"`
+	BreakerThreshold int           `json:"breaker_threshold"`
+	BreakerCooldown  time.Duration `json:"breaker_cooldown"`
+	BalanceHistory   bool          `json:"balance_history"`
+	CommitQueueSize  int           `json:"commit_queue_size"`
+}
+
+type BloomConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+type MempoolConfig struct {
+	Size               int   `json:"size"`
+	Recheck            bool  `json:"recheck"`
+	ForceRecheckGap    int64 `json:"force_recheck_gap"`
+	Flush              bool  `json:"flush"`
+	MaxTxNumPerBlock   int64 `json:"max_tx_num_per_block"`
+	MaxGasUsedPerBlock int64 `json:"max_gas_used_per_block"`
+}
+
+// deprecatedKeys are the settings ignored by the node, with the ones replacing them
+var deprecatedKeys = []struct {
+	key         string
+	replacement string
+}{
+	{abci.FlagCloseMutex, abci.FlagDisableABCIQueryMutex},
+}
+
+// LoadNodeConfig reads the node config from viper
+func LoadNodeConfig() NodeConfig {
+	return NodeConfig{
+		RPC: RPCConfig{
+			PersonalAPI:     viper.GetBool(rpc.FlagPersonalAPI),
+			AdminAPI:        viper.GetBool(rpc.FlagAdminAPI),
+			WebhookAPI:      viper.GetBool(rpc.FlagWebhookAPI),
+			DisableAPI:      viper.GetString(rpc.FlagDisableAPI),
+			ReadOnly:        viper.GetBool(readonly.FlagReadOnly),
+			StrictInput:     viper.GetBool(rpc.FlagStrictInput),
+			MaxBodySize:     viper.GetInt64(rpc.FlagMaxBodySize),
+			MaxParamsDepth:  viper.GetInt(rpc.FlagMaxParamsDepth),
+			MaxParamsLength: viper.GetInt(rpc.FlagMaxParamsLength),
+			RateLimitAPI:    viper.GetString(rpc.FlagRateLimitAPI),
+			RateLimitCount:  viper.GetInt(rpc.FlagRateLimitCount),
+			RateLimitBurst:  viper.GetInt(rpc.FlagRateLimitBurst),
+			LogsHeightSpan:  viper.GetInt64(filters.FlagGetLogsHeightSpan),
+
+			WarmUpBlocks:  viper.GetInt(rpc.FlagWarmUpBlocks),
+			WarmUpTimeout: viper.GetDuration(rpc.FlagWarmUpTimeout),
+
+			AdmissionTxLimit:      viper.GetInt(rpc.FlagAdmissionTxLimit),
+			AdmissionCallLimit:    viper.GetInt(rpc.FlagAdmissionCallLimit),
+			AdmissionDefaultLimit: viper.GetInt(rpc.FlagAdmissionDefaultLimit),
+			AdmissionLowLimit:     viper.GetInt(rpc.FlagAdmissionLowLimit),
+			AdmissionQueueTimeout: viper.GetDuration(rpc.FlagAdmissionQueueTimeout),
+			AdmissionWideLogsSpan: viper.GetInt64(rpc.FlagAdmissionWideLogsSpan),
+
+			ResponseCache:      viper.GetString(rpc.FlagResponseCache),
+			ResponseCacheSize:  viper.GetInt(rpc.FlagResponseCacheSize),
+			Dedup:              viper.GetBool(rpc.FlagDedup),
+			Compression:        viper.GetBool(rpc.FlagCompression),
+			CompressionMinSize: viper.GetInt(rpc.FlagCompressionMinSize),
+			Msgpack:            viper.GetBool(rpc.FlagMsgpack),
+			Stream:             viper.GetBool(rpc.FlagStream),
+
+			GasLimitBuffer:  viper.GetUint64(config.FlagGasLimitBuffer),
+			EnableDynamicGp: viper.GetBool(config.FlagEnableDynamicGp),
+			DynamicGpWeight: viper.GetInt(config.FlagDynamicGpWeight),
+		},
+		Watcher: WatcherConfig{
+			FastQuery:        viper.GetBool(watcher.FlagFastQuery),
+			LruSize:          viper.GetInt(watcher.FlagFastQueryLru),
+			BreakerThreshold: viper.GetInt(watcher.FlagBreakerThreshold),
+			BreakerCooldown:  viper.GetDuration(watcher.FlagBreakerCooldown),
+			BalanceHistory:   viper.GetBool(watcher.FlagBalanceHistory),
+			CommitQueueSize:  viper.GetInt(watcher.FlagCommitQueueSize),
+		},
+		Bloom: BloomConfig{
+			Enabled: viper.GetBool(evmtypes.FlagEnableBloomFilter),
+		},
+		Mempool: MempoolConfig{
+			Size:               viper.GetInt(config.FlagMempoolSize),
+			Recheck:            viper.GetBool(config.FlagMempoolRecheck),
+			ForceRecheckGap:    viper.GetInt64(config.FlagMempoolForceRecheckGap),
+			Flush:              viper.GetBool(config.FlagMempoolFlush),
+			MaxTxNumPerBlock:   viper.GetInt64(config.FlagMaxTxNumPerBlock),
+			MaxGasUsedPerBlock: viper.GetInt64(config.FlagMaxGasUsedPerBlock),
+		},
+	}
+}
+
+// Validate returns the problems of the config, empty if it is valid
+func (c NodeConfig) Validate() []string {
+	var problems []string
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	rpcCfg := c.RPC
+	check(rpcCfg.RateLimitCount >= 0, "%s can't be negative", rpc.FlagRateLimitCount)
+	check(rpcCfg.RateLimitCount == 0 || rpcCfg.RateLimitBurst > 0, "%s must be positive with %s set", rpc.FlagRateLimitBurst, rpc.FlagRateLimitCount)
+	check(rpcCfg.AdmissionTxLimit >= 0, "%s can't be negative", rpc.FlagAdmissionTxLimit)
+	check(rpcCfg.AdmissionCallLimit >= 0, "%s can't be negative", rpc.FlagAdmissionCallLimit)
+	check(rpcCfg.AdmissionDefaultLimit >= 0, "%s can't be negative", rpc.FlagAdmissionDefaultLimit)
+	check(rpcCfg.AdmissionLowLimit >= 0, "%s can't be negative", rpc.FlagAdmissionLowLimit)
+	check(rpcCfg.AdmissionQueueTimeout > 0, "%s must be positive", rpc.FlagAdmissionQueueTimeout)
+	check(rpcCfg.AdmissionWideLogsSpan > 0, "%s must be positive", rpc.FlagAdmissionWideLogsSpan)
+	if rpcCfg.ResponseCache != "" {
+		_, err := respcache.ParseTTLs(rpcCfg.ResponseCache)
+		check(err == nil, "invalid %s: %v", rpc.FlagResponseCache, err)
+		check(rpcCfg.ResponseCacheSize > 0, "%s must be positive with %s set", rpc.FlagResponseCacheSize, rpc.FlagResponseCache)
+	}
+	check(rpcCfg.CompressionMinSize >= 0, "%s can't be negative", rpc.FlagCompressionMinSize)
+	check(rpcCfg.MaxBodySize >= 0, "%s can't be negative", rpc.FlagMaxBodySize)
+	check(rpcCfg.MaxParamsDepth >= 0, "%s can't be negative", rpc.FlagMaxParamsDepth)
+	check(rpcCfg.MaxParamsLength >= 0, "%s can't be negative", rpc.FlagMaxParamsLength)
+	check(rpcCfg.WarmUpBlocks >= 0, "%s can't be negative", rpc.FlagWarmUpBlocks)
+	check(rpcCfg.WarmUpBlocks == 0 || rpcCfg.WarmUpTimeout >= 0, "%s can't be negative with %s set", rpc.FlagWarmUpTimeout, rpc.FlagWarmUpBlocks)
+	check(!rpcCfg.WebhookAPI || c.Watcher.FastQuery, "%s requires %s", rpc.FlagWebhookAPI, watcher.FlagFastQuery)
+
+	check(!c.Watcher.BalanceHistory || c.Watcher.FastQuery, "%s requires %s", watcher.FlagBalanceHistory, watcher.FlagFastQuery)
+	check(!c.Watcher.FastQuery || c.Watcher.LruSize > 0, "%s must be positive with %s enabled", watcher.FlagFastQueryLru, watcher.FlagFastQuery)
+	check(!c.Watcher.FastQuery || c.Watcher.CommitQueueSize > 0, "%s must be positive with %s enabled", watcher.FlagCommitQueueSize, watcher.FlagFastQuery)
+	check(c.Watcher.BreakerThreshold >= 0, "%s can't be negative", watcher.FlagBreakerThreshold)
+	check(c.Watcher.BreakerThreshold == 0 || c.Watcher.BreakerCooldown > 0, "%s must be positive with %s set", watcher.FlagBreakerCooldown, watcher.FlagBreakerThreshold)
+
+	check(c.Mempool.Size >= 0, "%s can't be negative", config.FlagMempoolSize)
+	check(c.Mempool.ForceRecheckGap > 0, "%s must be positive", config.FlagMempoolForceRecheckGap)
+	check(c.Mempool.MaxTxNumPerBlock >= 0, "%s can't be negative", config.FlagMaxTxNumPerBlock)
+	check(c.Mempool.MaxGasUsedPerBlock >= -1, "%s must be -1 for no limit, or not negative", config.FlagMaxGasUsedPerBlock)
+	return problems
+}
+
+// Warnings returns the settings which are set but ignored or adjusted by the node
+func (c NodeConfig) Warnings() []string {
+	var warnings []string
+	for _, deprecated := range deprecatedKeys {
+		if viper.IsSet(deprecated.key) {
+			warnings = append(warnings, fmt.Sprintf("%s is deprecated and ignored, use %s instead", deprecated.key, deprecated.replacement))
+		}
+	}
+	if c.RPC.RateLimitCount > 0 && c.RPC.RateLimitAPI == "" {
+		warnings = append(warnings, fmt.Sprintf("%s is ignored without %s", rpc.FlagRateLimitCount, rpc.FlagRateLimitAPI))
+	}
+	if c.RPC.ReadOnly && viper.GetString(server.FlagUlockKey) != "" {
+		warnings = append(warnings, fmt.Sprintf("%s is ignored with %s, the keys aren't unlocked", server.FlagUlockKey, readonly.FlagReadOnly))
+	}
+	if c.RPC.MaxBodySize > 5<<20 {
+		warnings = append(warnings, fmt.Sprintf("%s %d is above the 5MB read by the rpc server", rpc.FlagMaxBodySize, c.RPC.MaxBodySize))
+	}
+	if c.RPC.DynamicGpWeight < 1 || c.RPC.DynamicGpWeight > 100 {
+		warnings = append(warnings, fmt.Sprintf("%s %d is out of [1, 100] and clamped", config.FlagDynamicGpWeight, c.RPC.DynamicGpWeight))
+	}
+	return warnings
+}
+
+// CheckNodeConfig logs the warnings of the node config, and returns an error listing its problems
+func CheckNodeConfig(logger log.Logger) error {
+	cfg := LoadNodeConfig()
+	for _, warning := range cfg.Warnings() {
+		logger.Error(warning)
+	}
+	if problems := cfg.Validate(); len(problems) > 0 {
+		return fmt.Errorf("invalid config:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+
+	"github.com/okex/exchain/app/config"
+	"github.com/okex/exchain/app/rpc"
+	abci "github.com/okex/exchain/libs/tendermint/abci/types"
+	"github.com/okex/exchain/x/evm/watcher"
+)
+
+// setupNodeConfig sets the settings of the node to their defaults, along with the given ones
+func setupNodeConfig(t *testing.T, settings map[string]interface{}) {
+	viper.Reset()
+	cmd := &cobra.Command{}
+	RegisterAppFlag(cmd)
+	require.NoError(t, viper.BindPFlags(cmd.Flags()))
+	// registered along with the tendermint flags
+	viper.Set(config.FlagMempoolForceRecheckGap, 2000)
+	for key, value := range settings {
+		viper.Set(key, value)
+	}
+}
+
+func TestNodeConfigValidate(t *testing.T) {
+	defer viper.Reset()
+
+	testCases := []struct {
+		name     string
+		settings map[string]interface{}
+		problems []string
+	}{
+		{"defaults", nil, nil},
+		{
+			"rate limit without burst",
+			map[string]interface{}{rpc.FlagRateLimitCount: 10, rpc.FlagRateLimitBurst: 0},
+			[]string{rpc.FlagRateLimitBurst + " must be positive with " + rpc.FlagRateLimitCount + " set"},
+		},
+		{
+			"webhook without fast query",
+			map[string]interface{}{rpc.FlagWebhookAPI: true},
+			[]string{rpc.FlagWebhookAPI + " requires " + watcher.FlagFastQuery},
+		},
+		{
+			"empty commit queue",
+			map[string]interface{}{watcher.FlagFastQuery: true, watcher.FlagCommitQueueSize: 0},
+			[]string{watcher.FlagCommitQueueSize + " must be positive with " + watcher.FlagFastQuery + " enabled"},
+		},
+		{
+			"every problem is reported",
+			map[string]interface{}{config.FlagMempoolSize: -1, config.FlagMaxGasUsedPerBlock: -2},
+			[]string{
+				config.FlagMempoolSize + " can't be negative",
+				config.FlagMaxGasUsedPerBlock + " must be -1 for no limit, or not negative",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			setupNodeConfig(t, tc.settings)
+			require.Equal(t, tc.problems, LoadNodeConfig().Validate())
+		})
+	}
+
+	setupNodeConfig(t, map[string]interface{}{rpc.FlagResponseCache: "eth_chainId=forever"})
+	problems := LoadNodeConfig().Validate()
+	require.Len(t, problems, 1)
+	require.Contains(t, problems[0], "invalid "+rpc.FlagResponseCache)
+}
+
+func TestNodeConfigWarnings(t *testing.T) {
+	defer viper.Reset()
+
+	setupNodeConfig(t, nil)
+	require.Empty(t, LoadNodeConfig().Warnings())
+
+	setupNodeConfig(t, map[string]interface{}{
+		abci.FlagCloseMutex:        true,
+		rpc.FlagRateLimitCount:     10,
+		config.FlagDynamicGpWeight: 0,
+	})
+	require.Equal(t, []string{
+		abci.FlagCloseMutex + " is deprecated and ignored, use " + abci.FlagDisableABCIQueryMutex + " instead",
+		rpc.FlagRateLimitCount + " is ignored without " + rpc.FlagRateLimitAPI,
+		config.FlagDynamicGpWeight + " 0 is out of [1, 100] and clamped",
+	}, LoadNodeConfig().Warnings())
+}
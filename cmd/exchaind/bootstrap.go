@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/okex/exchain/app"
+	"github.com/okex/exchain/app/bootstrap"
+	"github.com/okex/exchain/libs/cosmos-sdk/server"
+	tmtypes "github.com/okex/exchain/libs/tendermint/types"
+	"github.com/okex/exchain/x/evm/watcher"
+)
+
+const (
+	flagSnapshotURL    = "snapshot-url"
+	flagSnapshotPubKey = "snapshot-pubkey"
+
+	// maxManifestSize bounds the size of the downloaded manifest
+	maxManifestSize = 1 << 20
+	// bootstrapManifestFile records the manifest of the snapshot the node was bootstrapped from
+	bootstrapManifestFile = "bootstrap_manifest.json"
+)
+
+func bootstrapCmd(ctx *server.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bootstrap",
+		Short: "Initialize the data of the node from a published snapshot instead of syncing from genesis",
+		Long: `The manifest.json at the snapshot url lists the files of the snapshot with their checksums, and is signed
+by the ed25519 key of the publisher given by --snapshot-pubkey. Every file is downloaded and checked before
+anything is written to the data directory: the tar.gz archives are extracted into it and the watcher snapshot
+is restored into the watcher db. The node then starts from the trusted height and hash of the manifest.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			baseURL := strings.TrimSuffix(viper.GetString(flagSnapshotURL), "/")
+			if baseURL == "" {
+				return fmt.Errorf("--%s is required", flagSnapshotURL)
+			}
+			dataDir := ctx.Config.DBDir()
+			if _, err := os.Stat(filepath.Join(dataDir, "application.db")); err == nil {
+				return fmt.Errorf("the data directory %s is not empty", dataDir)
+			}
+			genDoc, err := tmtypes.GenesisDocFromFile(ctx.Config.GenesisFile())
+			if err != nil {
+				return err
+			}
+
+			bz, err := bootstrap.Fetch(context.Background(), baseURL+"/"+bootstrap.ManifestFileName, maxManifestSize)
+			if err != nil {
+				return fmt.Errorf("failed to download the manifest: %w", err)
+			}
+			manifest, err := bootstrap.VerifyManifest(bz, viper.GetString(flagSnapshotPubKey))
+			if err != nil {
+				return err
+			}
+			if manifest.ChainID != genDoc.ChainID {
+				return fmt.Errorf("the snapshot is of chain %s, the genesis is of chain %s", manifest.ChainID, genDoc.ChainID)
+			}
+			log.Printf("Bootstrapping from the snapshot of %s at height %d\n", manifest.ChainID, manifest.Height)
+
+			if err := os.MkdirAll(dataDir, 0700); err != nil {
+				return err
+			}
+			downloadDir, err := ioutil.TempDir(dataDir, "bootstrap")
+			if err != nil {
+				return err
+			}
+			defer os.RemoveAll(downloadDir)
+
+			// all the files are verified before the data directory is touched
+			for _, file := range manifest.Files {
+				err := bootstrap.Download(context.Background(), baseURL+"/"+file.Name, filepath.Join(downloadDir, file.Name), file)
+				if err != nil {
+					return err
+				}
+			}
+			for _, file := range manifest.Files {
+				log.Printf("Unpacking %s\n", file.Name)
+				if err := unpackSnapshotFile(filepath.Join(downloadDir, file.Name), dataDir, manifest.Height, file); err != nil {
+					return fmt.Errorf("failed to unpack %s: %w", file.Name, err)
+				}
+			}
+
+			if err := app.VerifyBootstrap(ctx, manifest); err != nil {
+				return err
+			}
+			if err := ioutil.WriteFile(filepath.Join(dataDir, bootstrapManifestFile), bz, 0600); err != nil {
+				return err
+			}
+			log.Printf("Bootstrap success, trusted height %d hash %s\n", manifest.Height, manifest.BlockHash)
+			return nil
+		},
+	}
+
+	cmd.Flags().String(flagSnapshotURL, "", "Base url of the snapshot, holding its manifest.json and files")
+	cmd.Flags().String(flagSnapshotPubKey, "", "Hex ed25519 public key of the publisher of the snapshot")
+	cmd.Flags().String(flagDBBackend, "goleveldb", "Database backend of the watcher db: goleveldb | rocksdb")
+	return cmd
+}
+
+func unpackSnapshotFile(path, dataDir string, height int64, file bootstrap.ManifestFile) error {
+	switch file.Type {
+	case bootstrap.FileTypeArchive:
+		return bootstrap.ExtractArchive(path, dataDir)
+	case bootstrap.FileTypeWatcherSnapshot:
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		db, err := openWatchDB()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		info, err := watcher.RestoreSnapshot(db, f)
+		if err != nil {
+			return err
+		}
+		if int64(info.Height) != height {
+			return fmt.Errorf("the watcher snapshot is at height %d, the manifest is at %d", info.Height, height)
+		}
+		log.Printf("Restored the watcher snapshot, %s\n", info)
+		return nil
+	default:
+		return fmt.Errorf("unknown file type %s", file.Type)
+	}
+}
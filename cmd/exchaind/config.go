@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/okex/exchain/app"
+	"github.com/okex/exchain/cmd/client"
+	"github.com/okex/exchain/libs/cosmos-sdk/server"
+)
+
+func configCmd(ctx *server.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the config of the node",
+	}
+
+	cmd.AddCommand(validateConfigCmd(ctx))
+	return cmd
+}
+
+func validateConfigCmd(ctx *server.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the rpc, watcher, bloom and mempool config the node would start with, from the flags, the environment and the config files",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app.SetNodeConfig(ctx)
+
+			cfg := client.LoadNodeConfig()
+			bz, err := json.MarshalIndent(cfg, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(bz))
+
+			for _, warning := range cfg.Warnings() {
+				fmt.Printf("WARNING: %s\n", warning)
+			}
+			problems := cfg.Validate()
+			for _, problem := range problems {
+				fmt.Printf("ERROR: %s\n", problem)
+			}
+			if len(problems) > 0 {
+				return fmt.Errorf("the config has %d problem(s)", len(problems))
+			}
+			fmt.Println("the config is valid")
+			return nil
+		},
+	}
+
+	// the settings of the node, along with their defaults
+	client.RegisterAppFlag(cmd)
+	return cmd
+}
@@ -68,6 +68,7 @@ func dataCmd(ctx *server.Context) *cobra.Command {
 		pruningCmd(ctx),
 		queryCmd(ctx),
 		dbConvertCmd(ctx),
+		fastIndexCmd(ctx),
 	)
 
 	return cmd
@@ -170,6 +171,48 @@ func pruneAppCmd(ctx *server.Context) *cobra.Command {
 }
 
 
+func fastIndexCmd(ctx *server.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fast-index",
+		Short: "Build the fast index of the latest application states for the given stores",
+		Long: "Build the fast index of the latest application states for the given stores. Until the index is built,\n" +
+			"the node started with the stores in --iavl-fast-index-stores reads them from the tree.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config := ctx.Config
+			config.SetRoot(viper.GetString(flags.FlagHome))
+
+			if err := checkBackend(dbm.BackendType(ctx.Config.DBBackend)); err != nil {
+				return err
+			}
+
+			iavl.SetFastIndexStores(viper.GetStringSlice(iavl.FlagIavlFastIndexStores))
+			appDB := initDB(config, appDBName)
+			rs := initAppStore(appDB)
+
+			for key, store := range rs.GetStores() {
+				if !iavl.IsFastIndexStore(key.Name()) {
+					continue
+				}
+				iavlStore, ok := store.(*iavl.Store)
+				if !ok {
+					return fmt.Errorf("store %s is not an iavl store", key.Name())
+				}
+
+				start := time.Now()
+				count, err := iavlStore.BuildFastIndex()
+				if err != nil {
+					return err
+				}
+				log.Printf("Fast index of %s built with %d keys in %v\n", key.Name(), count, time.Since(start))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSlice(iavl.FlagIavlFastIndexStores, []string{"evm"}, "The stores to build the fast index for")
+	return cmd
+}
+
 func clearPruneHeightsCmd(ctx *server.Context) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "clear-prune-heights",
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/okex/exchain/app/eventsink"
+	"github.com/okex/exchain/libs/cosmos-sdk/client/flags"
+	"github.com/okex/exchain/libs/cosmos-sdk/server"
+	rpchttp "github.com/okex/exchain/libs/tendermint/rpc/client/http"
+)
+
+func eventSinkCmd(ctx *server.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "event-sink",
+		Short: "Manage the publication of the committed blocks, tx results and evm logs configured in exchaind.toml",
+	}
+
+	cmd.AddCommand(replayEventSinkCmd(ctx))
+	return cmd
+}
+
+func replayEventSinkCmd(ctx *server.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "replay <from-height> [to-height]",
+		Short: "Publish the blocks of a node from a height again, up to its latest block by default",
+		Long: `The blocks are read from the tendermint rpc of --node and published to the event sink of the config,
+the consumers receive their messages again. The last height published by the node itself is unchanged.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil || from <= 0 {
+				return fmt.Errorf("invalid height %s", args[0])
+			}
+			config, err := eventsink.ConfigFromViper()
+			if err != nil {
+				return err
+			}
+			if config.Type == "" {
+				return fmt.Errorf("the event sink isn't configured")
+			}
+			client, err := rpchttp.New(viper.GetString(flags.FlagNode), "/websocket")
+			if err != nil {
+				return err
+			}
+
+			to := int64(0)
+			if len(args) > 1 {
+				if to, err = strconv.ParseInt(args[1], 10, 64); err != nil || to < from {
+					return fmt.Errorf("invalid height %s", args[1])
+				}
+			} else {
+				status, err := client.Status()
+				if err != nil {
+					return err
+				}
+				to = status.SyncInfo.LatestBlockHeight
+			}
+
+			sink, err := eventsink.NewSink(config, client, filepath.Join(viper.GetString(flags.FlagHome), "data"), ctx.Logger)
+			if err != nil {
+				return err
+			}
+			defer sink.Close()
+			return sink.Replay(from, to)
+		},
+	}
+
+	cmd.Flags().String(flags.FlagNode, "tcp://localhost:26657", "<host>:<port> to the tendermint rpc of the node")
+	viper.BindPFlag(flags.FlagNode, cmd.Flags().Lookup(flags.FlagNode))
+	return cmd
+}
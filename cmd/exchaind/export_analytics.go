@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/okex/exchain/app/analytics"
+	"github.com/okex/exchain/libs/cosmos-sdk/server"
+	"github.com/okex/exchain/x/evm/watcher"
+)
+
+const (
+	flagExportOutput        = "output"
+	flagExportFormat        = "format"
+	flagExportPartition     = "partition"
+	flagExportPartitionSize = "partition-size"
+	flagExportEndHeight     = "end-height"
+)
+
+func exportAnalyticsCmd(ctx *server.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export-analytics",
+		Short: "Export the blocks, txs, receipts and logs of the watcher db into files for analytics, the node must be stopped",
+		Long: `The blocks are exported from the one after the last exported into the output directory, one directory
+per table and partition. A running node exports them continuously with --` + analytics.FlagAnalyticsExportDir + `.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			db, err := openWatchDB()
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			exporter, err := analytics.NewExporter(db, analytics.Config{
+				Dir:           viper.GetString(flagExportOutput),
+				Format:        viper.GetString(flagExportFormat),
+				Partition:     viper.GetString(flagExportPartition),
+				PartitionSize: viper.GetUint64(flagExportPartitionSize),
+			})
+			if err != nil {
+				return err
+			}
+			end := viper.GetUint64(flagExportEndHeight)
+			if end == 0 {
+				if end, err = watcher.GetLatestHeight(db); err != nil {
+					return err
+				}
+			}
+			last, err := exporter.LastHeight()
+			if err != nil {
+				return err
+			}
+			if last >= end {
+				return fmt.Errorf("the blocks are already exported up to height %d", last)
+			}
+
+			log.Printf("Exporting the blocks %d to %d\n", last+1, end)
+			n, err := exporter.Export(end)
+			if err != nil {
+				return err
+			}
+			log.Printf("Exported %d blocks\n", n)
+			return nil
+		},
+	}
+
+	cmd.Flags().String(flagExportOutput, "", "Output directory of the exported files")
+	cmd.Flags().String(flagExportFormat, analytics.FormatCSV, "Format of the exported files: csv")
+	cmd.Flags().String(flagExportPartition, analytics.PartitionDay, "Partitioning of the exported files: day | height")
+	cmd.Flags().Uint64(flagExportPartitionSize, 100000, "Number of blocks of the height partitions")
+	cmd.Flags().Uint64(flagExportEndHeight, 0, "Last height to export, the latest height of the watcher db by default")
+	cmd.Flags().String(flagDBBackend, "goleveldb", "Database backend of the watcher db: goleveldb | rocksdb")
+	return cmd
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/okex/exchain/cmd/client"
+	"github.com/okex/exchain/libs/cosmos-sdk/codec"
+	"github.com/okex/exchain/libs/cosmos-sdk/server"
+	rpchttp "github.com/okex/exchain/libs/tendermint/rpc/client/http"
+	evmfork "github.com/okex/exchain/x/evm/client/fork"
+	evmtypes "github.com/okex/exchain/x/evm/types"
+)
+
+const (
+	flagForkRPC    = "rpc"
+	flagForkHeight = "height"
+)
+
+// forkCmd runs the node like start, the evm accounts, code and storage missing locally are fetched from a remote
+// archive node at the fork height on their first access
+func forkCmd(ctx *server.Context, cdc *codec.Codec) *cobra.Command {
+	cmd := server.StartCmd(ctx, cdc, newApp, closeApp, registerRoutes, client.RegisterAppFlag, preRun)
+	cmd.Use = "fork"
+	cmd.Short = "Run a local development node forked from the state of a remote node"
+	cmd.Long = `Run the full node like start, the evm state missing from the local store is read from the remote node
+at the fork height on its first access, and kept in memory. The remote node must keep the state of that height,
+usually it is an archive node.
+
+The local chain is initialized as a development chain, e.g. with init, and its accounts sending transactions must
+be funded in its genesis. The accounts, code and storage read from the remote node are only written locally once
+they are modified by a transaction.`
+
+	startPreRun := cmd.PreRunE
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		remote := viper.GetString(flagForkRPC)
+		if remote == "" {
+			return fmt.Errorf("the remote node to fork from must be set by --%s", flagForkRPC)
+		}
+		height := viper.GetInt64(flagForkHeight)
+		if height <= 0 {
+			return fmt.Errorf("invalid fork height %d", height)
+		}
+
+		rpcClient, err := rpchttp.New(remote, "/websocket")
+		if err != nil {
+			return err
+		}
+		evmtypes.SetForkSource(evmfork.NewRPCSource(cdc, rpcClient, height))
+		ctx.Logger.Info("forking the evm state", "remote", remote, "height", height)
+
+		return startPreRun(cmd, args)
+	}
+
+	cmd.Flags().String(flagForkRPC, "", "Tendermint rpc address of the archive node to fork from")
+	cmd.Flags().Int64(flagForkHeight, 0, "Height of the state to fork from")
+	return cmd
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/okex/exchain/app/rpc/gateway"
+	"github.com/okex/exchain/cmd/client"
+	"github.com/okex/exchain/libs/cosmos-sdk/client/flags"
+	"github.com/okex/exchain/libs/cosmos-sdk/client/lcd"
+	"github.com/okex/exchain/libs/cosmos-sdk/codec"
+	"github.com/okex/exchain/libs/cosmos-sdk/server"
+)
+
+const (
+	flagGatewayConfig = "gateway-config"
+	flagGatewayLaddr  = "gateway-laddr"
+)
+
+// gatewayCmd serves the rest and the json-rpc apis of a remote node, whose blocks and txs are verified by a light
+// client unless it is trusted
+func gatewayCmd(cdc *codec.Codec) *cobra.Command {
+	cmd := lcd.ServeCommand(cdc, registerRoutes)
+	cmd.Short = "Serve the rest and the json-rpc apis of a remote node, verifying its blocks and txs unless --trust-node is set"
+	cmd.Flags().String(flags.FlagChainID, "", "Chain ID of the remote node, required to verify it")
+	client.RegisterAppFlag(cmd)
+	return cmd
+}
+
+// multiChainGatewayCmd serves the json-rpc apis of several chains, such as the mainnet and the testnet, from a
+// single process
+func multiChainGatewayCmd(ctx *server.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rpc-gateway",
+		Short: "Serve the json-rpc apis of several chains, selected by the path prefix or the X-Chain header, with a rate limiter and a response cache per chain",
+		Long: `Serve the json-rpc apis of several chains from a single process. The chains are set in a json config file:
+
+{"chains": [
+  {"name": "mainnet", "chain_id": "exchain-66", "upstream": "http://10.0.0.1:8545",
+   "rate_limit": 500, "rate_burst": 1000, "response_cache": "eth_chainId=0", "response_cache_size": 10000},
+  {"name": "testnet", "chain_id": "exchain-65", "upstream": "http://10.0.0.2:8545", "rate_limit": 50, "rate_burst": 100}
+]}
+
+The requests to /mainnet are proxied to the mainnet upstream, as well as the requests to / with the
+X-Chain header set to mainnet or exchain-66.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := gateway.LoadConfig(viper.GetString(flagGatewayConfig))
+			if err != nil {
+				return err
+			}
+			g, err := gateway.New(cfg)
+			if err != nil {
+				return err
+			}
+
+			srv := &http.Server{Addr: viper.GetString(flagGatewayLaddr), Handler: g}
+			server.TrapSignal(func() {
+				_ = srv.Close()
+			})
+			for _, chain := range cfg.Chains {
+				ctx.Logger.Info("serving chain", "name", chain.Name, "chain-id", chain.ChainID, "upstream", chain.Upstream)
+			}
+			ctx.Logger.Info("starting the rpc gateway", "laddr", srv.Addr)
+			if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String(flagGatewayConfig, "", "Path of the json config file of the chains")
+	cmd.Flags().String(flagGatewayLaddr, "0.0.0.0:8545", "Listen address of the gateway")
+	cmd.MarkFlagRequired(flagGatewayConfig)
+	return cmd
+}
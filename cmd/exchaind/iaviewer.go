@@ -468,6 +468,9 @@ func evmPrintKey(cdc *codec.Codec, key []byte, value []byte) {
 	case evmtypes.KeyPrefixHeightHash[0]:
 		log.Println(fmt.Sprintf("height:%s;blockHash:%s\n", hex.EncodeToString(key[1:]), hex.EncodeToString(value)))
 		return
+	case evmtypes.KeyPrefixRecentBlockHash[0]:
+		log.Println(fmt.Sprintf("slot:%s;height:%s;blockHash:%s\n", hex.EncodeToString(key[1:]), hex.EncodeToString(value[:8]), hex.EncodeToString(value[8:])))
+		return
 	case evmtypes.KeyPrefixContractDeploymentWhitelist[0]:
 		log.Println(fmt.Sprintf("whiteAddress:%s\n", hex.EncodeToString(key[1:])))
 		return
@@ -82,17 +82,27 @@ func main() {
 		client.TestnetCmd(ctx, cdc, app.ModuleBasics, auth.GenesisAccountIterator{}),
 		replayCmd(ctx),
 		repairStateCmd(ctx),
+		rollbackCmd(ctx),
+		bootstrapCmd(ctx),
+		exportAnalyticsCmd(ctx),
+		eventSinkCmd(ctx),
 		// AddGenesisAccountCmd allows users to add accounts to the genesis file
 		AddGenesisAccountCmd(ctx, cdc, app.DefaultNodeHome, app.DefaultCLIHome),
 		flags.NewCompletionCmd(rootCmd, true),
 		dataCmd(ctx),
+		watcherCmd(ctx),
 		exportAppCmd(ctx),
 		iaviewerCmd(cdc),
+		configCmd(ctx),
+		gatewayCmd(cdc),
+		multiChainGatewayCmd(ctx),
+		rpcReplayCmd(),
 	)
 
 	// Tendermint node base commands
 	server.AddCommands(ctx, cdc, rootCmd, newApp, closeApp, exportAppStateAndTMValidators,
-		registerRoutes, client.RegisterAppFlag, app.PreRun)
+		registerRoutes, client.RegisterAppFlag, preRun)
+	rootCmd.AddCommand(forkCmd(ctx, cdc))
 
 	// prepare and add flags
 	executor := cli.PrepareBaseCmd(rootCmd, "OKEXCHAIN", app.DefaultNodeHome)
@@ -104,12 +114,23 @@ func main() {
 	}
 }
 
+// preRun validates the config of the node once the node and dev modes have set their defaults
+func preRun(ctx *server.Context) error {
+	if err := app.PreRun(ctx); err != nil {
+		return err
+	}
+	return client.CheckNodeConfig(ctx.Logger)
+}
+
 func closeApp(iApp abci.Application) {
 	fmt.Println("Close App")
 	app := iApp.(*app.OKExChainApp)
+	// the watch data of the last blocks is written in the background
+	app.EvmKeeper.Watcher.Flush()
 	app.StopStore()
 	evmtypes.CloseIndexer()
 	evmtypes.CloseTracer()
+	evmtypes.CloseSignatures()
 	rpc.CloseEthBackend()
 }
 
@@ -118,6 +139,23 @@ func newApp(logger log.Logger, db dbm.DB, traceStore io.Writer) abci.Application
 	if err != nil {
 		panic(err)
 	}
+	evmPruningOpts, evmPruning, err := evmtypes.GetPruningOptions()
+	if err != nil {
+		panic(err)
+	}
+
+	options := []func(*baseapp.BaseApp){
+		baseapp.SetPruning(pruningOpts),
+		baseapp.SetMinGasPrices(viper.GetString(server.FlagMinGasPrices)),
+		baseapp.SetHaltHeight(uint64(viper.GetInt(server.FlagHaltHeight))),
+	}
+	if evmPruning {
+		// the accounts are kept along with the evm store, as the state at a height needs both of them
+		options = append(options,
+			baseapp.SetStorePruning(evmtypes.StoreKey, evmPruningOpts),
+			baseapp.SetStorePruning(auth.StoreKey, evmPruningOpts),
+		)
+	}
 
 	return app.NewOKExChainApp(
 		logger,
@@ -126,9 +164,7 @@ func newApp(logger log.Logger, db dbm.DB, traceStore io.Writer) abci.Application
 		true,
 		map[int64]bool{},
 		0,
-		baseapp.SetPruning(pruningOpts),
-		baseapp.SetMinGasPrices(viper.GetString(server.FlagMinGasPrices)),
-		baseapp.SetHaltHeight(uint64(viper.GetInt(server.FlagHaltHeight))),
+		options...,
 	)
 }
 
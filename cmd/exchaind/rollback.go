@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/okex/exchain/app"
+	"github.com/okex/exchain/libs/cosmos-sdk/client/flags"
+	"github.com/okex/exchain/libs/cosmos-sdk/server"
+	"github.com/okex/exchain/x/evm/watcher"
+)
+
+const flagRollbackHeight = "height"
+
+func rollbackCmd(ctx *server.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rollback [blocks]",
+		Short: "Roll the node back by the given number of blocks or to --height, the node must be stopped",
+		Long: `The application state, the watcher db, the bloom index, the tendermint state and the block store
+are rolled back together, and the application hash is checked against the chain. The removed blocks are
+synced again once the node restarts. An interrupted rollback can be run again with the same arguments.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var blocks int64
+			if len(args) > 0 {
+				var err error
+				if blocks, err = strconv.ParseInt(args[0], 10, 64); err != nil {
+					return fmt.Errorf("invalid number of blocks %s: %w", args[0], err)
+				}
+			}
+			height := viper.GetInt64(flagRollbackHeight)
+			if (blocks > 0) == (height > 0) {
+				return fmt.Errorf("either the number of blocks or --%s must be given", flagRollbackHeight)
+			}
+			height, err := app.RollbackHeight(ctx, height, blocks)
+			if err != nil {
+				return err
+			}
+
+			log.Println("--------- rollback start ---------")
+			// the watcher db only exists on the nodes of the fast-query mode
+			var watchDB dbm.DB
+			dbDir := filepath.Join(viper.GetString(flags.FlagHome), watcher.WatchDbDir)
+			if _, err := os.Stat(filepath.Join(dbDir, watcher.WatchDBName+".db")); err == nil {
+				if watchDB, err = openWatchDB(); err != nil {
+					return err
+				}
+				defer watchDB.Close()
+			}
+			if err := app.Rollback(ctx, height, watchDB); err != nil {
+				return err
+			}
+			log.Println("--------- rollback success ---------")
+			return nil
+		},
+	}
+
+	cmd.Flags().Int64(flagRollbackHeight, 0, "Roll back to the given height instead of a number of blocks")
+	cmd.Flags().String(flagDBBackend, "goleveldb", "Database backend of the watcher db: goleveldb | rocksdb")
+	return cmd
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/okex/exchain/app/rpc/traffic"
+)
+
+const (
+	flagReplayTarget    = "target"
+	flagReplayReference = "reference"
+	flagReplayIgnore    = "ignore-methods"
+	flagReplayTimeout   = "timeout"
+)
+
+// rpcReplayCmd replays the json-rpc traffic recorded with --rpc.record-traffic against a candidate node and
+// diffs its responses, to check the rpc compatibility of a release before it is rolled out
+func rpcReplayCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rpc-replay [traffic-file]",
+		Short: "Replay the json-rpc traffic recorded by a node against a candidate node and diff the responses",
+		Long: `Replay the json-rpc traffic recorded by a node with --rpc.record-traffic against a candidate node, such
+as a new release syncing a shadow fork, and diff its responses with the recorded ones, or with the ones of a
+reference node given by --reference. The latest block param of the requests is pinned to the height the
+request was served at, the requests at the pending block and the ones changing the state of the node are
+skipped. It fails if any response differs.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			cfg := traffic.ReplayConfig{
+				Target:    viper.GetString(flagReplayTarget),
+				Reference: viper.GetString(flagReplayReference),
+				Timeout:   viper.GetDuration(flagReplayTimeout),
+			}
+			for _, method := range strings.Split(viper.GetString(flagReplayIgnore), ",") {
+				if method = strings.TrimSpace(method); method != "" {
+					cfg.IgnoredMethods = append(cfg.IgnoredMethods, method)
+				}
+			}
+			report, err := traffic.Replay(f, cfg, func(mismatch traffic.Mismatch) {
+				fmt.Printf("MISMATCH line %d %s at height %d\n", mismatch.Line, mismatch.Method, mismatch.Height)
+				for _, diff := range mismatch.Diffs {
+					fmt.Printf("  %s\n", diff)
+				}
+			})
+			if err != nil {
+				return err
+			}
+
+			// the mismatches are printed as they are found
+			mismatches := len(report.Mismatches)
+			report.Mismatches = nil
+			bz, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(bz))
+			if mismatches > 0 {
+				return fmt.Errorf("%d of the %d replayed responses differ", mismatches, report.Replayed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String(flagReplayTarget, "http://127.0.0.1:8545", "Json-rpc endpoint of the candidate node")
+	cmd.Flags().String(flagReplayReference, "", "Json-rpc endpoint of a reference node whose responses are diffed instead of the recorded ones")
+	cmd.Flags().String(flagReplayIgnore, strings.Join(traffic.DefaultIgnoredMethods, ","), "Methods replayed without diffing their responses, which change over time")
+	cmd.Flags().Duration(flagReplayTimeout, 30*time.Second, "Timeout of each replayed request")
+	return cmd
+}
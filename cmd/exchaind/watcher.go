@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/okex/exchain/libs/cosmos-sdk/client/flags"
+	"github.com/okex/exchain/libs/cosmos-sdk/server"
+	"github.com/okex/exchain/x/evm/watcher"
+)
+
+func watcherCmd(ctx *server.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "watcher",
+		Short: "manage the watcher db of the fast-query mode",
+	}
+
+	cmd.AddCommand(
+		watcherSnapshotCmd(ctx),
+		migrateLogIndicesCmd(ctx),
+		migrateCodesCmd(ctx),
+	)
+
+	return cmd
+}
+
+func watcherSnapshotCmd(ctx *server.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Create or restore snapshots of the watcher db, to clone a rpc node without replaying blocks",
+	}
+
+	cmd.AddCommand(
+		createWatcherSnapshotCmd(ctx),
+		restoreWatcherSnapshotCmd(ctx),
+	)
+
+	cmd.PersistentFlags().String(flagDBBackend, "goleveldb", "Database backend: goleveldb | rocksdb")
+	return cmd
+}
+
+func createWatcherSnapshotCmd(ctx *server.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create [file]",
+		Short: "Create a snapshot of the watcher db at its latest height, the node must be stopped",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := os.Stat(args[0]); err == nil {
+				return fmt.Errorf("snapshot file %s already exists", args[0])
+			}
+
+			db, err := openWatchDB()
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			f, err := os.Create(args[0])
+			if err != nil {
+				return err
+			}
+			info, err := watcher.CreateSnapshot(db, f)
+			if err == nil {
+				err = f.Sync()
+			}
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(args[0])
+				return fmt.Errorf("failed to create the watcher snapshot: %w", err)
+			}
+
+			log.Printf("Created watcher snapshot %s, %s\n", args[0], info)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func restoreWatcherSnapshotCmd(ctx *server.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore [file]",
+		Short: "Restore the watcher db from a snapshot, the watcher db must not exist yet",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// check the whole snapshot before writing anything into the watcher db
+			info, err := readWatcherSnapshot(args[0], watcher.VerifySnapshot)
+			if err != nil {
+				return fmt.Errorf("invalid watcher snapshot: %w", err)
+			}
+			log.Printf("Verified watcher snapshot %s, %s\n", args[0], info)
+
+			db, err := openWatchDB()
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			info, err = readWatcherSnapshot(args[0], func(r io.Reader) (watcher.SnapshotInfo, error) {
+				return watcher.RestoreSnapshot(db, r)
+			})
+			if err != nil {
+				return fmt.Errorf("failed to restore the watcher snapshot: %w", err)
+			}
+
+			log.Printf("Restored the watcher db to height %d\n", info.Height)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func migrateLogIndicesCmd(ctx *server.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate-log-index",
+		Short: "Renumber the logs of the receipts committed before the block-wide log indices, the node must be stopped",
+		Long: `The receipts are otherwise fixed block by block when they are read through the rpc.
+The blocks already migrated are skipped, so the migration can be interrupted and run again.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			db, err := openWatchDB()
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			fixed, err := watcher.MigrateLogIndices(db)
+			if err != nil {
+				return fmt.Errorf("failed to migrate the log indices after %d blocks: %w", fixed, err)
+			}
+			log.Printf("Migrated the log indices of %d blocks\n", fixed)
+			return nil
+		},
+	}
+
+	cmd.Flags().String(flagDBBackend, "goleveldb", "Database backend: goleveldb | rocksdb")
+	return cmd
+}
+
+func migrateCodesCmd(ctx *server.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate-code",
+		Short: "Deduplicate the contract codes stored per address by the legacy watcher, the node must be stopped",
+		Long: `The codes are stored once by code hash and referenced by the contracts.
+The legacy codes are otherwise still read per address, so the migration can be interrupted and run again.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			db, err := openWatchDB()
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			migrated, err := watcher.MigrateCodes(db)
+			if err != nil {
+				return fmt.Errorf("failed to migrate the contract codes after %d contracts: %w", migrated, err)
+			}
+			log.Printf("Migrated the codes of %d contracts\n", migrated)
+			return nil
+		},
+	}
+
+	cmd.Flags().String(flagDBBackend, "goleveldb", "Database backend: goleveldb | rocksdb")
+	return cmd
+}
+
+func readWatcherSnapshot(file string, fn func(r io.Reader) (watcher.SnapshotInfo, error)) (watcher.SnapshotInfo, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return watcher.SnapshotInfo{}, err
+	}
+	defer f.Close()
+	return fn(f)
+}
+
+func openWatchDB() (dbm.DB, error) {
+	backend := dbm.BackendType(viper.GetString(flagDBBackend))
+	if err := checkBackend(backend); err != nil {
+		return nil, err
+	}
+
+	dbDir := filepath.Join(viper.GetString(flags.FlagHome), watcher.WatchDbDir)
+	return dbm.NewDB(watcher.WatchDBName, backend, dbDir), nil
+}
@@ -11,6 +11,8 @@ import (
 
 	"github.com/okex/exchain/libs/iavl"
 	abci "github.com/okex/exchain/libs/tendermint/abci/types"
+	"github.com/okex/exchain/libs/tendermint/libs/tracing"
+	"github.com/okex/exchain/libs/tendermint/mempool"
 	"github.com/okex/exchain/libs/tendermint/trace"
 
 	"github.com/okex/exchain/libs/cosmos-sdk/codec"
@@ -144,7 +146,7 @@ func (app *BaseApp) BeginBlock(req abci.RequestBeginBlock) (res abci.ResponseBeg
 	app.newBlockCache()
 	// add block gas meter
 	var gasMeter sdk.GasMeter
-	if maxGas := app.getMaximumBlockGas(); maxGas > 0 {
+	if maxGas := app.getBlockGasLimit(app.deliverState.ctx); maxGas > 0 {
 		gasMeter = sdk.NewGasMeter(maxGas)
 	} else {
 		gasMeter = sdk.NewInfiniteGasMeter()
@@ -181,7 +183,7 @@ func (app *BaseApp) EndBlock(req abci.RequestEndBlock) (res abci.ResponseEndBloc
 // will contain releveant error information. Regardless of tx execution outcome,
 // the ResponseCheckTx will contain relevant gas execution context.
 func (app *BaseApp) CheckTx(req abci.RequestCheckTx) abci.ResponseCheckTx {
-	tx, err := app.txDecoder(req.Tx)
+	tx, err := app.txDecoder(req.Tx, app.LastBlockHeight()+1)
 	if err != nil {
 		return sdkerrors.ResponseCheckTx(err, 0, 0, app.trace)
 	}
@@ -212,7 +214,14 @@ func (app *BaseApp) CheckTx(req abci.RequestCheckTx) abci.ResponseCheckTx {
 
 	gInfo, result, _, err := app.runTx(mode, req.Tx, tx, LatestSimulateTxHeight)
 	if err != nil {
-		return sdkerrors.ResponseCheckTx(err, gInfo.GasWanted, gInfo.GasUsed, app.trace)
+		res := sdkerrors.ResponseCheckTx(err, gInfo.GasWanted, gInfo.GasUsed, app.trace)
+		// the mempool scores the senders of the failed txs, they are only reported once verified by the signature
+		if recoverer, ok := tx.(sdk.SenderRecoverer); ok {
+			if sender := recoverer.RecoverSender(app.checkState.ctx); sender != "" {
+				res.Data, _ = json.Marshal(mempool.ExTxInfo{Sender: sender})
+			}
+		}
+		return res
 	}
 
 	return abci.ResponseCheckTx{
@@ -224,6 +233,27 @@ func (app *BaseApp) CheckTx(req abci.RequestCheckTx) abci.ResponseCheckTx {
 	}
 }
 
+// PreCheckTx implements the ABCI interface and runs the stateless checks of a tx. It doesn't touch the
+// check state, so it may run concurrently with other txs, the stateful checks are left to CheckTx.
+func (app *BaseApp) PreCheckTx(req abci.RequestCheckTx) abci.ResponseCheckTx {
+	tx, err := app.txDecoder(req.Tx, app.LastBlockHeight()+1)
+	if err != nil {
+		return sdkerrors.ResponseCheckTx(err, 0, 0, app.trace)
+	}
+
+	if err := validateBasicTxMsgs(tx.GetMsgs()); err != nil {
+		return sdkerrors.ResponseCheckTx(err, 0, 0, app.trace)
+	}
+
+	if app.preCheckTx != nil {
+		if err := app.preCheckTx(app.LastBlockHeight()+1, tx); err != nil {
+			return sdkerrors.ResponseCheckTx(err, 0, 0, app.trace)
+		}
+	}
+
+	return abci.ResponseCheckTx{Code: abci.CodeTypeOK}
+}
+
 
 
 // Commit implements the ABCI interface. It will commit all state that exists in
@@ -238,6 +268,7 @@ func (app *BaseApp) Commit(req abci.RequestCommit) abci.ResponseCommit {
 		req.Deltas = &abci.Deltas{}
 	}
 	header := app.deliverState.ctx.BlockHeader()
+	app.stopPrefetch()
 
 	// Write the DeliverTx state which is cache-wrapped and commit the MultiStore.
 	// The write to the DeliverTx state writes all state transitions to the root
@@ -310,6 +341,11 @@ func (app *BaseApp) halt() {
 // Query implements the ABCI interface. It delegates to CommitMultiStore if it
 // implements Queryable.
 func (app *BaseApp) Query(req abci.RequestQuery) abci.ResponseQuery {
+	// the grpc query services are routed by the full method name
+	if handler := app.grpcQueryRouter.Route(req.Path); handler != nil {
+		return handleQueryGRPC(app, handler, req)
+	}
+
 	path := splitPath(req.Path)
 	if len(path) == 0 {
 		sdkerrors.QueryResult(sdkerrors.Wrap(sdkerrors.ErrUnknownRequest, "no query path provided"))
@@ -339,7 +375,12 @@ func handleQueryApp(app *BaseApp, path []string, req abci.RequestQuery) abci.Res
 		case "simulate":
 			txBytes := req.Data
 
-			tx, err := app.txDecoder(txBytes)
+			// the tx is simulated in the block following the height
+			height := req.Height
+			if height == 0 {
+				height = app.LastBlockHeight()
+			}
+			tx, err := app.txDecoder(txBytes, height+1)
 			if err != nil {
 				return sdkerrors.QueryResult(sdkerrors.Wrap(err, "failed to decode tx"))
 			}
@@ -453,34 +494,17 @@ func handleQueryCustom(app *BaseApp, path []string, req abci.RequestQuery) abci.
 		return sdkerrors.QueryResult(sdkerrors.Wrapf(sdkerrors.ErrUnknownRequest, "no custom querier found for route %s", path[1]))
 	}
 
-	// when a client did not provide a query height, manually inject the latest
-	if req.Height == 0 {
-		req.Height = app.LastBlockHeight()
-	}
-
-	if req.Height <= 1 && req.Prove {
-		return sdkerrors.QueryResult(
-			sdkerrors.Wrap(
-				sdkerrors.ErrInvalidRequest,
-				"cannot query with proof when height <= 1; please provide a valid height",
-			),
-		)
-	}
-
-	cacheMS, err := app.cms.CacheMultiStoreWithVersion(req.Height)
+	ctx, err := app.createQueryContext(&req)
 	if err != nil {
-		return sdkerrors.QueryResult(
-			sdkerrors.Wrapf(
-				sdkerrors.ErrInvalidRequest,
-				"failed to load state at height %d; %s (latest height: %d)", req.Height, err, app.LastBlockHeight(),
-			),
-		)
+		return sdkerrors.QueryResult(err)
 	}
 
-	// cache wrap the commit-multistore for safety
-	ctx := sdk.NewContext(
-		cacheMS, app.checkState.ctx.BlockHeader(), true, app.logger,
-	).WithMinGasPrices(app.minGasPrices)
+	span := tracing.StartSpan("query " + path[1])
+	defer span.End()
+	if span != nil {
+		span.SetAttribute("height", req.Height)
+		ctx = ctx.WithContext(tracing.ContextWithSpan(ctx.Context(), span))
+	}
 
 	// Passes the rest of the path as an argument to the querier.
 	//
@@ -488,6 +512,7 @@ func handleQueryCustom(app *BaseApp, path []string, req abci.RequestQuery) abci.
 	// []string{"proposal", "test"} as the path.
 	resBytes, err := querier(ctx, path[2:], req)
 	if err != nil {
+		span.SetError(err)
 		space, code, log := sdkerrors.ABCIInfo(err, false)
 		return abci.ResponseQuery{
 			Code:      code,
@@ -503,6 +528,67 @@ func handleQueryCustom(app *BaseApp, path []string, req abci.RequestQuery) abci.
 	}
 }
 
+func handleQueryGRPC(app *BaseApp, handler GRPCQueryHandler, req abci.RequestQuery) abci.ResponseQuery {
+	ctx, err := app.createQueryContext(&req)
+	if err != nil {
+		return sdkerrors.QueryResult(err)
+	}
+
+	span := tracing.StartSpan("query " + req.Path)
+	defer span.End()
+	if span != nil {
+		span.SetAttribute("height", req.Height)
+		ctx = ctx.WithContext(tracing.ContextWithSpan(ctx.Context(), span))
+	}
+
+	resBytes, err := handler(ctx, req)
+	if err != nil {
+		span.SetError(err)
+		space, code, log := sdkerrors.ABCIInfo(err, false)
+		return abci.ResponseQuery{
+			Code:      code,
+			Codespace: space,
+			Log:       log,
+			Height:    req.Height,
+		}
+	}
+
+	return abci.ResponseQuery{
+		Height: req.Height,
+		Value:  resBytes,
+	}
+}
+
+// createQueryContext creates a context on the state at the height of the query, the latest height when the
+// query doesn't provide one
+func (app *BaseApp) createQueryContext(req *abci.RequestQuery) (sdk.Context, error) {
+	// when a client did not provide a query height, manually inject the latest
+	if req.Height == 0 {
+		req.Height = app.LastBlockHeight()
+	}
+
+	if req.Height <= 1 && req.Prove {
+		return sdk.Context{}, sdkerrors.Wrap(
+			sdkerrors.ErrInvalidRequest,
+			"cannot query with proof when height <= 1; please provide a valid height",
+		)
+	}
+
+	cacheMS, err := app.cms.CacheMultiStoreWithVersion(req.Height)
+	if err != nil {
+		return sdk.Context{}, sdkerrors.Wrapf(
+			sdkerrors.ErrInvalidRequest,
+			"failed to load state at height %d; %s (latest height: %d)", req.Height, err, app.LastBlockHeight(),
+		)
+	}
+
+	// cache wrap the commit-multistore for safety
+	ctx := sdk.NewContext(
+		cacheMS, app.checkState.ctx.BlockHeader(), true, app.logger,
+	).WithMinGasPrices(app.minGasPrices)
+	return ctx, nil
+}
+
 // splitPath splits a string path using the delimiter '/'.
 //
 // e.g. "this/is/funny" becomes []string{"this", "is", "funny"}
@@ -92,14 +92,15 @@ type (
 // BaseApp reflects the ABCI application implementation.
 type BaseApp struct { // nolint: maligned
 	// initialized on creation
-	logger      log.Logger
-	name        string               // application name from abci.Info
-	db          dbm.DB               // common DB backend
-	cms         sdk.CommitMultiStore // Main (uncached) state
-	storeLoader StoreLoader          // function to handle store loading, may be overridden with SetStoreLoader()
-	router      sdk.Router           // handle any kind of message
-	queryRouter sdk.QueryRouter      // router for redirecting query calls
-	txDecoder   sdk.TxDecoder        // unmarshal []byte into sdk.Tx
+	logger          log.Logger
+	name            string               // application name from abci.Info
+	db              dbm.DB               // common DB backend
+	cms             sdk.CommitMultiStore // Main (uncached) state
+	storeLoader     StoreLoader          // function to handle store loading, may be overridden with SetStoreLoader()
+	router          sdk.Router           // handle any kind of message
+	queryRouter     sdk.QueryRouter      // router for redirecting query calls
+	grpcQueryRouter *GRPCQueryRouter     // router for the grpc query services
+	txDecoder       sdk.TxDecoder        // unmarshal []byte into sdk.Tx
 
 	// set upon LoadVersion or LoadLatestVersion.
 	baseKey *sdk.KVStoreKey // Main KVStore in cms
@@ -116,6 +117,10 @@ type BaseApp struct { // nolint: maligned
 	fauxMerkleMode bool             // if true, IAVL MountStores uses MountStoresDB for simulation speed.
 
 	getTxFee                     sdk.GetTxFeeHandler
+	blockGasLimit                sdk.BlockGasLimitHandler
+	preDeliverTx                 sdk.PreDeliverTxHandler
+	preCheckTx                   sdk.PreCheckTxHandler
+	prefetchTx                   sdk.PrefetchTxHandler
 	updateFeeCollectorAccHandler sdk.UpdateFeeCollectorAccHandler
 	logFix                       sdk.LogFix
 
@@ -126,6 +131,10 @@ type BaseApp struct { // nolint: maligned
 	checkState   *state // for CheckTx
 	deliverState *state // for DeliverTx
 
+	// prefetchStop stops the prefetch of the current block, which closes prefetchDone once its workers return
+	prefetchStop chan struct{}
+	prefetchDone chan struct{}
+
 	// an inter-block write-through cache provided to the context during deliverState
 	interBlockCache sdk.MultiStorePersistentCache
 
@@ -179,16 +188,17 @@ func NewBaseApp(
 ) *BaseApp {
 
 	app := &BaseApp{
-		logger:         logger,
-		name:           name,
-		db:             db,
-		cms:            store.NewCommitMultiStore(db),
-		storeLoader:    DefaultStoreLoader,
-		router:         NewRouter(),
-		queryRouter:    NewQueryRouter(),
-		txDecoder:      txDecoder,
-		fauxMerkleMode: false,
-		trace:          false,
+		logger:          logger,
+		name:            name,
+		db:              db,
+		cms:             store.NewCommitMultiStore(db),
+		storeLoader:     DefaultStoreLoader,
+		router:          NewRouter(),
+		queryRouter:     NewQueryRouter(),
+		grpcQueryRouter: NewGRPCQueryRouter(),
+		txDecoder:       txDecoder,
+		fauxMerkleMode:  false,
+		trace:           false,
 
 		parallelTxManage: newParallelTxManager(),
 		chainCache:       sdk.NewChainCache(),
@@ -370,6 +380,15 @@ func (app *BaseApp) LoadVersion(version int64, baseKey *sdk.KVStoreKey) error {
 	return app.initFromMainStore(baseKey)
 }
 
+// RollbackToVersion deletes the versions of the multistore above the given one and loads it as the latest
+// version. The blocks after it must be delivered and committed again.
+func (app *BaseApp) RollbackToVersion(version int64, baseKey *sdk.KVStoreKey) error {
+	if err := app.cms.RollbackToVersion(version); err != nil {
+		return err
+	}
+	return app.initFromMainStore(baseKey)
+}
+
 // GetCommitVersion loads the latest committed version.
 func (app *BaseApp) GetCommitVersion() (int64, error) {
 	return app.cms.GetCommitVersion()
@@ -454,6 +473,9 @@ func (app *BaseApp) Router() sdk.Router {
 // QueryRouter returns the QueryRouter of a BaseApp.
 func (app *BaseApp) QueryRouter() sdk.QueryRouter { return app.queryRouter }
 
+// GRPCQueryRouter returns the GRPCQueryRouter of a BaseApp.
+func (app *BaseApp) GRPCQueryRouter() *GRPCQueryRouter { return app.grpcQueryRouter }
+
 // Seal seals a BaseApp. It prohibits any further modifications to a BaseApp.
 func (app *BaseApp) Seal() { app.sealed = true }
 
@@ -520,6 +542,19 @@ func (app *BaseApp) getMaximumBlockGas() uint64 {
 	}
 }
 
+// getBlockGasLimit gets the gas limit of the block of ctx, the lowest of the maximum gas of the consensus params and
+// of the block gas limit of the application, 0 for none
+func (app *BaseApp) getBlockGasLimit(ctx sdk.Context) uint64 {
+	maxGas := app.getMaximumBlockGas()
+	if app.blockGasLimit == nil {
+		return maxGas
+	}
+	if limit := app.blockGasLimit(ctx); limit != 0 && (maxGas == 0 || limit < maxGas) {
+		return limit
+	}
+	return maxGas
+}
+
 func (app *BaseApp) validateHeight(req abci.RequestBeginBlock) error {
 	if req.Header.Height < 1 {
 		return fmt.Errorf("invalid height: %d", req.Header.Height)
@@ -1009,7 +1044,7 @@ func (app *BaseApp) GetTxInfo(ctx sdk.Context, tx sdk.Tx) mempool.ExTxInfo {
 }
 
 func (app *BaseApp) GetRawTxInfo(rawTx tmtypes.Tx) mempool.ExTxInfo {
-	tx, err := app.txDecoder(rawTx)
+	tx, err := app.txDecoder(rawTx, app.LastBlockHeight()+1)
 	if err != nil {
 		return mempool.ExTxInfo{}
 	}
@@ -1018,7 +1053,7 @@ func (app *BaseApp) GetRawTxInfo(rawTx tmtypes.Tx) mempool.ExTxInfo {
 }
 
 func (app *BaseApp) GetTxHistoryGasUsed(rawTx tmtypes.Tx) int64 {
-	tx, err := app.txDecoder(rawTx)
+	tx, err := app.txDecoder(rawTx, app.LastBlockHeight()+1)
 	if err != nil {
 		return -1
 	}
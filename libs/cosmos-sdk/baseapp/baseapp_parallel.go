@@ -3,6 +3,7 @@ package baseapp
 import (
 	"encoding/hex"
 	"fmt"
+	"runtime"
 	"sync"
 
 	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
@@ -24,7 +25,7 @@ func (app *BaseApp) getExtraDataByTxs(txs [][]byte) []*extraDataForTx {
 		index := index
 		txBytes := txBytes
 		go func() {
-			tx, err := app.txDecoder(txBytes)
+			tx, err := app.txDecoder(txBytes, app.deliverState.ctx.BlockHeight())
 			if err != nil {
 				panic(err)
 			}
@@ -41,6 +42,116 @@ func (app *BaseApp) getExtraDataByTxs(txs [][]byte) []*extraDataForTx {
 	return res
 }
 
+// PreDeliverTxs runs the pre-deliver handler on the txs of the next block with one worker per cpu, the txs
+// failing to decode are left to DeliverTx to report. It then starts prefetching the state read by the txs.
+func (app *BaseApp) PreDeliverTxs(txs [][]byte) {
+	if (app.preDeliverTx == nil && app.prefetchTx == nil) || len(txs) == 0 {
+		return
+	}
+
+	height := app.LastBlockHeight() + 1
+	txIndexes := make(chan int, len(txs))
+	for i := range txs {
+		txIndexes <- i
+	}
+	close(txIndexes)
+
+	decodedTxs := make([]sdk.Tx, len(txs))
+	workers := runtime.NumCPU()
+	if workers > len(txs) {
+		workers = len(txs)
+	}
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for index := range txIndexes {
+				tx, err := app.txDecoder(txs[index], height)
+				if err != nil {
+					continue
+				}
+				decodedTxs[index] = tx
+				if app.preDeliverTx != nil {
+					app.preDeliverTx(height, tx)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	app.startPrefetch(decodedTxs)
+}
+
+// startPrefetch runs the prefetch handler on the decoded txs of the next block in the background, with one
+// worker per cpu reading the last committed state through its own cache, so that the workers don't contend on
+// it. The prefetch of the previous block is stopped first, e.g. if the block was never committed.
+func (app *BaseApp) startPrefetch(decodedTxs []sdk.Tx) {
+	app.stopPrefetch()
+	height := app.LastBlockHeight()
+	if app.prefetchTx == nil || height == 0 {
+		return
+	}
+
+	txs := make(chan sdk.Tx, len(decodedTxs))
+	for _, tx := range decodedTxs {
+		if tx != nil {
+			txs <- tx
+		}
+	}
+	close(txs)
+
+	workers := runtime.NumCPU()
+	if workers > len(txs) {
+		workers = len(txs)
+	}
+	ctxs := make([]sdk.Context, 0, workers)
+	for i := 0; i < workers; i++ {
+		cacheMS, err := app.cms.CacheMultiStoreWithVersion(height)
+		if err != nil {
+			app.logger.Error("failed to prefetch the txs", "height", height+1, "err", err)
+			return
+		}
+		ctxs = append(ctxs, sdk.NewContext(cacheMS, abci.Header{Height: height}, true, app.logger))
+	}
+	if len(ctxs) == 0 {
+		return
+	}
+
+	stop, done := make(chan struct{}), make(chan struct{})
+	app.prefetchStop, app.prefetchDone = stop, done
+	var wg sync.WaitGroup
+	wg.Add(len(ctxs))
+	for _, ctx := range ctxs {
+		go func(ctx sdk.Context) {
+			defer wg.Done()
+			for tx := range txs {
+				select {
+				case <-stop:
+					return
+				default:
+					app.prefetchTx(ctx, tx)
+				}
+			}
+		}(ctx)
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+}
+
+// stopPrefetch stops the running prefetch and waits for its workers to return, it is called before committing a block
+// so that the versions read by the prefetch aren't pruned meanwhile
+func (app *BaseApp) stopPrefetch() {
+	if app.prefetchStop == nil {
+		return
+	}
+	close(app.prefetchStop)
+	<-app.prefetchDone
+	app.prefetchStop, app.prefetchDone = nil, nil
+}
+
 func (app *BaseApp) ParallelTxs(txs [][]byte) []*abci.ResponseDeliverTx {
 	extraData := app.getExtraDataByTxs(txs)
 	app.parallelTxManage.isAsyncDeliverTx = true
@@ -86,7 +197,8 @@ func (app *BaseApp) fixFeeCollector(txString string) {
 }
 
 func (app *BaseApp) runTxs(txs [][]byte) []*abci.ResponseDeliverTx {
-	maxGas := app.getMaximumBlockGas()
+	// the txs exceeding the gas limit of the block are run again in order, against the block gas meter
+	maxGas := app.deliverState.ctx.BlockGasMeter().Limit()
 	currentGas := uint64(0)
 	overFlow := func(sumGas uint64, currGas int64, maxGas uint64) bool {
 		if maxGas <= 0 {
@@ -173,7 +285,7 @@ func (app *BaseApp) endParallelTxs() [][]byte {
 //we reuse the nonce that changed by the last async call
 //if last ante handler has been failed, we need rerun it ? or not?
 func (app *BaseApp) deliverTxWithCache(req abci.RequestDeliverTx) *executeResult {
-	tx, err := app.txDecoder(req.Tx)
+	tx, err := app.txDecoder(req.Tx, app.deliverState.ctx.BlockHeight())
 	if err != nil {
 		return nil
 	}
@@ -147,7 +147,7 @@ func (app *BaseApp) runAnte(info *runTxInfo, mode runTxMode) (error) {
 
 func (app *BaseApp) DeliverTx(req abci.RequestDeliverTx) abci.ResponseDeliverTx {
 
-	tx, err := app.txDecoder(req.Tx)
+	tx, err := app.txDecoder(req.Tx, app.deliverState.ctx.BlockHeight())
 	if err != nil {
 		return sdkerrors.ResponseDeliverTx(err, 0, 0, app.trace)
 	}
@@ -9,7 +9,9 @@ import (
 	"math/big"
 	"os"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/okex/exchain/libs/tendermint/mempool"
 
@@ -662,7 +664,7 @@ func (msg msgCounter2) ValidateBasic() error {
 
 // amino decode
 func testTxDecoder(cdc *codec.Codec) sdk.TxDecoder {
-	return func(txBytes []byte) (sdk.Tx, error) {
+	return func(txBytes []byte, _ ...int64) (sdk.Tx, error) {
 		var tx txTest
 		if len(txBytes) == 0 {
 			return nil, sdkerrors.Wrap(sdkerrors.ErrTxDecode, "tx bytes are empty")
@@ -812,6 +814,124 @@ func TestCheckTx(t *testing.T) {
 	require.Nil(t, storedBytes)
 }
 
+func TestPreDeliverTxs(t *testing.T) {
+	var handled int64
+	preDeliverOpt := func(bapp *BaseApp) {
+		bapp.SetPreDeliverTxHandler(func(height int64, tx sdk.Tx) {
+			require.Equal(t, int64(1), height)
+			atomic.AddInt64(&handled, 1)
+		})
+	}
+	app := setupBaseApp(t, preDeliverOpt)
+	app.InitChain(abci.RequestInitChain{})
+
+	codec := codec.New()
+	registerTestCodec(codec)
+
+	nTxs := 100
+	txs := make([][]byte, 0, nTxs+1)
+	for i := 0; i < nTxs; i++ {
+		txBytes, err := codec.MarshalBinaryLengthPrefixed(newTxCounter(int64(i), 0))
+		require.NoError(t, err)
+		txs = append(txs, txBytes)
+	}
+	// the txs failing to decode are skipped
+	txs = append(txs, []byte("invalid tx"))
+
+	app.PreDeliverTxs(txs)
+	require.Equal(t, int64(nTxs), atomic.LoadInt64(&handled))
+}
+
+func TestPrefetchTxs(t *testing.T) {
+	var prefetched int64
+	prefetchOpt := func(bapp *BaseApp) {
+		bapp.SetPrefetchTxHandler(func(ctx sdk.Context, tx sdk.Tx) {
+			// the last committed state is read
+			require.Positive(t, ctx.BlockHeight())
+			atomic.AddInt64(&prefetched, 1)
+		})
+	}
+	app := setupBaseApp(t, prefetchOpt)
+	app.InitChain(abci.RequestInitChain{})
+
+	codec := codec.New()
+	registerTestCodec(codec)
+	txs := [][]byte{[]byte("invalid tx")}
+	for i := 0; i < 10; i++ {
+		txBytes, err := codec.MarshalBinaryLengthPrefixed(newTxCounter(int64(i), 0))
+		require.NoError(t, err)
+		txs = append(txs, txBytes)
+	}
+
+	// nothing is committed to prefetch from yet
+	app.PreDeliverTxs(txs)
+	require.Nil(t, app.prefetchStop)
+
+	header := abci.Header{Height: 1}
+	app.BeginBlock(abci.RequestBeginBlock{Header: header})
+	app.EndBlock(abci.RequestEndBlock{})
+	app.Commit(abci.RequestCommit{})
+
+	// the prefetch runs in the background and is stopped once the block is committed
+	app.PreDeliverTxs(txs)
+	header = abci.Header{Height: 2}
+	app.BeginBlock(abci.RequestBeginBlock{Header: header})
+	require.Eventually(t, func() bool { return atomic.LoadInt64(&prefetched) == 10 }, time.Second, time.Millisecond)
+	app.EndBlock(abci.RequestEndBlock{})
+	app.Commit(abci.RequestCommit{})
+	require.Nil(t, app.prefetchStop)
+
+	// the prefetch of a block never committed is stopped by the next one
+	app.PreDeliverTxs(txs)
+	app.PreDeliverTxs(txs)
+	app.stopPrefetch()
+}
+
+func TestPreCheckTx(t *testing.T) {
+	counterKey := []byte("counter-key")
+
+	anteOpt := func(bapp *BaseApp) { bapp.SetAnteHandler(anteHandlerTxTest(t, capKey1, counterKey)) }
+	preCheckOpt := func(bapp *BaseApp) {
+		bapp.SetPreCheckTxHandler(func(height int64, tx sdk.Tx) error {
+			if tx.(txTest).Counter%2 == 1 {
+				return sdkerrors.ErrUnauthorized
+			}
+			return nil
+		})
+	}
+
+	app := setupBaseApp(t, anteOpt, preCheckOpt)
+	app.InitChain(abci.RequestInitChain{})
+
+	codec := codec.New()
+	registerTestCodec(codec)
+
+	nTxs := int64(10)
+	responses := make([]abci.ResponseCheckTx, nTxs)
+	var wg sync.WaitGroup
+	for i := int64(0); i < nTxs; i++ {
+		txBytes, err := codec.MarshalBinaryLengthPrefixed(newTxCounter(i, 0))
+		require.NoError(t, err)
+
+		wg.Add(1)
+		go func(i int64) {
+			defer wg.Done()
+			responses[i] = app.PreCheckTx(abci.RequestCheckTx{Tx: txBytes})
+		}(i)
+	}
+	wg.Wait()
+
+	for i, r := range responses {
+		require.Equal(t, i%2 == 0, r.IsOK(), fmt.Sprintf("%v", r))
+	}
+
+	// the stateless checks leave the check state untouched
+	require.Nil(t, app.checkState.ctx.KVStore(capKey1).Get(counterKey))
+
+	r := app.PreCheckTx(abci.RequestCheckTx{Tx: []byte("invalid")})
+	require.False(t, r.IsOK())
+}
+
 // Test that successive DeliverTx can see each others' effects
 // on the store, both within and across blocks.
 func TestDeliverTx(t *testing.T) {
@@ -1192,6 +1312,57 @@ func TestTxGasLimits(t *testing.T) {
 	}
 }
 
+// Test that the txs delivered in parallel past the block gas limit of the application fail in the order of the block
+func TestParallelTxsBlockGasLimit(t *testing.T) {
+	anteOpt := func(bapp *BaseApp) {
+		bapp.SetAnteHandler(func(ctx sdk.Context, tx sdk.Tx, simulate bool) (sdk.Context, error) {
+			newCtx := ctx.WithGasMeter(sdk.NewGasMeter(10))
+			newCtx.GasMeter().ConsumeGas(uint64(tx.(txTest).Counter), "counter-ante")
+			return newCtx, nil
+		})
+		bapp.SetParallelTxHandlers(
+			func(sdk.Context, sdk.Coins) error { return nil },
+			func(sdk.Context, sdk.Tx) (sdk.Coins, bool, sdk.SigCache) { return nil, true, nil },
+			func(txs [][]string) [][]byte { return make([][]byte, len(txs)) },
+		)
+		bapp.SetBlockGasLimitHandler(func(sdk.Context) uint64 { return 25 })
+	}
+	routerOpt := func(bapp *BaseApp) {
+		bapp.Router().AddRoute(routeMsgCounter, func(ctx sdk.Context, msg sdk.Msg) (*sdk.Result, error) {
+			return &sdk.Result{}, nil
+		})
+	}
+	app := setupBaseApp(t, anteOpt, routerOpt)
+	app.InitChain(abci.RequestInitChain{})
+
+	codec := codec.New()
+	registerTestCodec(codec)
+	txs := make([][]byte, 4)
+	for i := range txs {
+		txBytes, err := codec.MarshalBinaryLengthPrefixed(newTxCounter(10, int64(i)))
+		require.NoError(t, err)
+		txs[i] = txBytes
+	}
+
+	for i := 0; i < 5; i++ {
+		app.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: app.LastBlockHeight() + 1}})
+		res := app.ParallelTxs(txs)
+		require.Len(t, res, len(txs))
+		for j, r := range res {
+			if j < 2 {
+				require.True(t, r.IsOK(), r.Log)
+				continue
+			}
+			space, code, _ := sdkerrors.ABCIInfo(sdkerrors.ErrOutOfGas, false)
+			require.Equal(t, space, r.Codespace, r.Log)
+			require.Equal(t, code, r.Code, r.Log)
+		}
+		require.True(t, app.deliverState.ctx.BlockGasMeter().IsOutOfGas())
+		app.EndBlock(abci.RequestEndBlock{})
+		app.Commit(abci.RequestCommit{})
+	}
+}
+
 // Test that transactions exceeding gas limits fail
 func TestMaxBlockGasLimits(t *testing.T) {
 	gasGranted := uint64(10)
@@ -1542,6 +1713,30 @@ func TestGetMaximumBlockGas(t *testing.T) {
 	require.Panics(t, func() { app.getMaximumBlockGas() })
 }
 
+func TestGetBlockGasLimit(t *testing.T) {
+	var limit uint64
+	app := setupBaseApp(t, func(bapp *BaseApp) {
+		bapp.SetBlockGasLimitHandler(func(sdk.Context) uint64 { return limit })
+	})
+	ctx := app.checkState.ctx
+
+	app.setConsensusParams(&abci.ConsensusParams{Block: &abci.BlockParams{MaxGas: -1}})
+	require.Equal(t, uint64(0), app.getBlockGasLimit(ctx))
+	limit = 100
+	require.Equal(t, uint64(100), app.getBlockGasLimit(ctx))
+
+	app.setConsensusParams(&abci.ConsensusParams{Block: &abci.BlockParams{MaxGas: 50}})
+	require.Equal(t, uint64(50), app.getBlockGasLimit(ctx))
+	limit = 0
+	require.Equal(t, uint64(50), app.getBlockGasLimit(ctx))
+	limit = 40
+	require.Equal(t, uint64(40), app.getBlockGasLimit(ctx))
+
+	// the block gas meter is limited by the application
+	app.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: app.LastBlockHeight() + 1}})
+	require.Equal(t, uint64(40), app.deliverState.ctx.BlockGasMeter().Limit())
+}
+
 // NOTE: represents a new custom router for testing purposes of WithRouter()
 type testCustomRouter struct {
 	routes sync.Map
@@ -0,0 +1,76 @@
+package baseapp
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	sdkerrors "github.com/okex/exchain/libs/cosmos-sdk/types/errors"
+	abci "github.com/okex/exchain/libs/tendermint/abci/types"
+)
+
+// GRPCQueryHandler handles the abci query of a grpc method, the request data and the response value are the
+// protobuf encoding of the method messages
+type GRPCQueryHandler = func(ctx sdk.Context, req abci.RequestQuery) ([]byte, error)
+
+// GRPCQueryRouter routes the abci queries with a path "/<service>/<method>" to the grpc query services of the
+// modules. The services are registered the same way as on a grpc server, so the nodes serve them over grpc by
+// forwarding the calls as abci queries.
+type GRPCQueryRouter struct {
+	routes map[string]GRPCQueryHandler
+}
+
+// NewGRPCQueryRouter returns a reference to a new GRPCQueryRouter.
+func NewGRPCQueryRouter() *GRPCQueryRouter {
+	return &GRPCQueryRouter{
+		routes: map[string]GRPCQueryHandler{},
+	}
+}
+
+// RegisterService registers the unary methods of a grpc service implemented by handler. It will panic if a
+// method is registered twice.
+func (qrt *GRPCQueryRouter) RegisterService(sd *grpc.ServiceDesc, handler interface{}) {
+	for _, method := range sd.Methods {
+		fqName := fmt.Sprintf("/%s/%s", sd.ServiceName, method.MethodName)
+		if qrt.routes[fqName] != nil {
+			panic(fmt.Sprintf("grpc query route %s has already been registered", fqName))
+		}
+
+		methodHandler := method.Handler
+		qrt.routes[fqName] = func(ctx sdk.Context, req abci.RequestQuery) ([]byte, error) {
+			dec := func(msg interface{}) error {
+				return proto.Unmarshal(req.Data, msg.(proto.Message))
+			}
+			res, err := methodHandler(handler, sdk.WrapSDKContext(ctx), dec, nil)
+			if err != nil {
+				return nil, grpcToSDKError(err)
+			}
+			return proto.Marshal(res.(proto.Message))
+		}
+	}
+}
+
+// Route returns the handler of a grpc method, nil if the method isn't registered
+func (qrt *GRPCQueryRouter) Route(path string) GRPCQueryHandler {
+	return qrt.routes[path]
+}
+
+// grpcToSDKError converts the status errors of the services into the errors of the abci responses
+func grpcToSDKError(err error) error {
+	s, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	switch s.Code() {
+	case codes.InvalidArgument:
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, s.Message())
+	case codes.NotFound, codes.Unimplemented:
+		return sdkerrors.Wrap(sdkerrors.ErrUnknownRequest, s.Message())
+	default:
+		return sdkerrors.Wrap(sdkerrors.ErrInternal, s.Message())
+	}
+}
@@ -10,4 +10,8 @@ func (app *BaseApp) PushAnteHandler(ah sdk.AnteHandler) {
 
 func (app *BaseApp) GetDeliverStateCtx() sdk.Context {
 	return app.deliverState.ctx
-}
\ No newline at end of file
+}
+
+func (app *BaseApp) GetCommitMultiStore() sdk.CommitMultiStore {
+	return app.cms
+}
@@ -18,6 +18,11 @@ func SetPruning(opts sdk.PruningOptions) func(*BaseApp) {
 	return func(bap *BaseApp) { bap.cms.SetPruning(opts) }
 }
 
+// SetStorePruning sets a pruning option on the named store, apart from the one of the multistore
+func SetStorePruning(name string, opts sdk.PruningOptions) func(*BaseApp) {
+	return func(bap *BaseApp) { bap.cms.SetStorePruning(name, opts) }
+}
+
 // SetMinGasPrices returns an option that sets the minimum gas prices on the app.
 func SetMinGasPrices(gasPricesStr string) func(*BaseApp) {
 	gasPrices, err := sdk.ParseDecCoins(gasPricesStr)
@@ -172,3 +177,35 @@ func (app *BaseApp) SetParallelTxHandlers(feeCollectt sdk.UpdateFeeCollectorAccH
 	app.getTxFee = txFee
 	app.logFix = fixLog
 }
+
+// SetBlockGasLimitHandler sets the handler returning the gas limit of the blocks set by the application
+func (app *BaseApp) SetBlockGasLimitHandler(handler sdk.BlockGasLimitHandler) {
+	if app.sealed {
+		panic("SetBlockGasLimitHandler() on sealed BaseApp")
+	}
+	app.blockGasLimit = handler
+}
+
+// SetPreDeliverTxHandler sets the handler to prepare the txs of a block before delivering them
+func (app *BaseApp) SetPreDeliverTxHandler(handler sdk.PreDeliverTxHandler) {
+	if app.sealed {
+		panic("SetPreDeliverTxHandler() on sealed BaseApp")
+	}
+	app.preDeliverTx = handler
+}
+
+// SetPrefetchTxHandler sets the handler to warm the state read by the txs of a block while they are delivered
+func (app *BaseApp) SetPrefetchTxHandler(handler sdk.PrefetchTxHandler) {
+	if app.sealed {
+		panic("SetPrefetchTxHandler() on sealed BaseApp")
+	}
+	app.prefetchTx = handler
+}
+
+// SetPreCheckTxHandler sets the handler to run the stateless checks of a tx concurrently before CheckTx
+func (app *BaseApp) SetPreCheckTxHandler(handler sdk.PreCheckTxHandler) {
+	if app.sealed {
+		panic("SetPreCheckTxHandler() on sealed BaseApp")
+	}
+	app.preCheckTx = handler
+}
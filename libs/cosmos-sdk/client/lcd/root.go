@@ -1,13 +1,15 @@
 package lcd
 
 import (
+	gocontext "context"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
+	"sync"
 	"time"
 
-	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/rakyll/statik/fs"
 	"github.com/spf13/cobra"
@@ -33,8 +35,17 @@ type RestServer struct {
 	KeyBase keybase.Keybase
 	Cdc     *codec.Codec
 
-	log      log.Logger
-	listener net.Listener
+	log       log.Logger
+	listener  net.Listener
+	security   SecurityConfig
+	tlsConfig  *tls.Config
+	httpConfig HTTPConfig
+
+	shutdownLock  sync.Mutex
+	server        *http.Server
+	shuttingDown  bool
+	onDrainFns    []func()
+	onShutdownFns []func()
 }
 
 // NewRestServer creates a new rest server instance
@@ -43,12 +54,12 @@ func NewRestServer(cdc *codec.Codec, tmNode *node.Node) *RestServer {
 	cliCtx := context.NewCLIContext().WithCodec(cdc)
 	logger := log.NewTMLogger(log.NewSyncWriter(os.Stdout)).With("module", "rest-server")
 	if tmNode != nil {
+		// the local node is trusted, the responses of a remote one are verified unless --trust-node is set
 		cliCtx.Client = local.New(tmNode)
+		cliCtx.TrustNode = true
 		logger = tmNode.Logger.With("module", "rest-server")
 	}
 
-	cliCtx.TrustNode = true
-
 	return &RestServer{
 		Mux:    rootRouter,
 		CliCtx: cliCtx,
@@ -62,6 +73,109 @@ func (rs *RestServer) Logger() log.Logger {
 	return rs.log
 }
 
+// SetSecurity sets the access control and the TLS termination of the rest server, it must be called
+// before the routes are registered so that the servers started along with it can share them
+func (rs *RestServer) SetSecurity(security SecurityConfig) error {
+	tlsConfig, err := security.NewTLSConfig()
+	if err != nil {
+		return err
+	}
+	rs.security = security
+	rs.tlsConfig = tlsConfig
+	return nil
+}
+
+// HTTPConfig defines the tuning of the http connections of the rest server.
+type HTTPConfig struct {
+	// ReadTimeout and WriteTimeout override the timeouts the rest server is started with if they aren't 0
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	// IdleTimeout is the time the idle keep-alive connections are kept open, the read timeout is used if it's 0
+	IdleTimeout time.Duration
+	// MaxHeaderBytes is the max size of the request headers, the default of net/http is used if it's 0
+	MaxHeaderBytes int
+	// HTTP2 serves HTTP/2 besides HTTP/1.1, over TLS and over cleartext for the clients with prior knowledge
+	HTTP2 bool
+	// MaxConcurrentStreams is the max number of concurrent requests of a HTTP/2 connection, 0 for the default
+	MaxConcurrentStreams uint32
+	// ShutdownGracePeriod is the time the in-flight requests are given to finish on shutdown before their
+	// connections are closed, 0 closes them right away
+	ShutdownGracePeriod time.Duration
+}
+
+// SetHTTPConfig sets the tuning of the http connections of the rest server, it must be called before it starts
+func (rs *RestServer) SetHTTPConfig(httpConfig HTTPConfig) {
+	rs.httpConfig = httpConfig
+}
+
+// RegisterOnDrain registers a function to call on shutdown while the in-flight requests are drained, such as
+// the stop of the servers proxying their requests to the rest server.
+func (rs *RestServer) RegisterOnDrain(f func()) {
+	rs.shutdownLock.Lock()
+	defer rs.shutdownLock.Unlock()
+	rs.onDrainFns = append(rs.onDrainFns, f)
+}
+
+// RegisterOnShutdown registers a function to call on the shutdown of the rest server once the in-flight requests
+// are drained, such as the stop of the servers started along with it.
+func (rs *RestServer) RegisterOnShutdown(f func()) {
+	rs.shutdownLock.Lock()
+	defer rs.shutdownLock.Unlock()
+	rs.onShutdownFns = append(rs.onShutdownFns, f)
+}
+
+// Shutdown stops accepting requests and gives the in-flight ones the grace period to finish, along with the
+// functions registered by RegisterOnDrain. The functions registered by RegisterOnShutdown are called afterwards.
+// It's called when the node exits.
+func (rs *RestServer) Shutdown() {
+	rs.shutdownLock.Lock()
+	rs.shuttingDown = true
+	srv := rs.server
+	drainFns, fns := rs.onDrainFns, rs.onShutdownFns
+	rs.onDrainFns, rs.onShutdownFns = nil, nil
+	rs.shutdownLock.Unlock()
+
+	var wg sync.WaitGroup
+	for _, f := range drainFns {
+		wg.Add(1)
+		go func(f func()) {
+			defer wg.Done()
+			f()
+		}(f)
+	}
+	if srv != nil {
+		rs.drain(srv)
+	}
+	wg.Wait()
+
+	for _, f := range fns {
+		f()
+	}
+}
+
+// drain closes the listener and waits for the in-flight requests within the grace period, the connections
+// still open afterwards are closed
+func (rs *RestServer) drain(srv *http.Server) {
+	ctx, cancel := gocontext.WithTimeout(gocontext.Background(), rs.httpConfig.ShutdownGracePeriod)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		rs.log.Info("rest server connections closed on shutdown timeout", "err", err)
+		_ = srv.Close()
+		return
+	}
+	rs.log.Info("rest server requests drained")
+}
+
+// Security returns the access control and the TLS termination of the rest server.
+func (rs *RestServer) Security() SecurityConfig {
+	return rs.security
+}
+
+// TLSConfig returns the TLS config of the rest server, or nil if it serves plain http.
+func (rs *RestServer) TLSConfig() *tls.Config {
+	return rs.tlsConfig
+}
+
 // Start starts the rest server
 func (rs *RestServer) Start(listenAddr string, maxOpen int, readTimeout, writeTimeout uint, cors bool) (err error) {
 	//trapSignal(func() {
@@ -73,6 +187,7 @@ func (rs *RestServer) Start(listenAddr string, maxOpen int, readTimeout, writeTi
 	cfg.MaxOpenConnections = maxOpen
 	cfg.ReadTimeout = time.Duration(readTimeout) * time.Second
 	cfg.WriteTimeout = time.Duration(writeTimeout) * time.Second
+	rs.httpConfig.apply(cfg)
 
 	rs.listener, err = tmrpcserver.Listen(listenAddr, cfg)
 	if err != nil {
@@ -85,13 +200,64 @@ func (rs *RestServer) Start(listenAddr string, maxOpen int, readTimeout, writeTi
 		),
 	)
 
-	var h http.Handler = rs.Mux
+	security := rs.security
 	if cors {
-		allowAllCORS := handlers.CORS(handlers.AllowedHeaders([]string{"Content-Type"}))
-		h = allowAllCORS(h)
+		security.CORSOrigins = []string{"*"}
+	}
+	if rs.tlsConfig != nil {
+		tlsConfig := rs.tlsConfig
+		if cfg.EnableHTTP2 {
+			tlsConfig = withHTTP2(tlsConfig)
+		}
+		rs.listener = tls.NewListener(rs.listener, tlsConfig)
+	}
+
+	srv, err := tmrpcserver.NewHTTPServer(security.Handler(rs.Mux), rs.log, cfg)
+	if err != nil {
+		return err
+	}
+	rs.shutdownLock.Lock()
+	if rs.shuttingDown {
+		rs.shutdownLock.Unlock()
+		return rs.listener.Close()
 	}
+	rs.server = srv
+	rs.shutdownLock.Unlock()
 
-	return tmrpcserver.Serve(rs.listener, h, rs.log, cfg)
+	err = srv.Serve(rs.listener)
+	rs.log.Info("REST server stopped", "err", err)
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+func (c HTTPConfig) apply(cfg *tmrpcserver.Config) {
+	if c.ReadTimeout > 0 {
+		cfg.ReadTimeout = c.ReadTimeout
+	}
+	if c.WriteTimeout > 0 {
+		cfg.WriteTimeout = c.WriteTimeout
+	}
+	if c.MaxHeaderBytes > 0 {
+		cfg.MaxHeaderBytes = c.MaxHeaderBytes
+	}
+	cfg.IdleTimeout = c.IdleTimeout
+	cfg.EnableHTTP2 = c.HTTP2
+	cfg.MaxConcurrentStreams = c.MaxConcurrentStreams
+}
+
+// withHTTP2 returns a copy of the TLS config negotiating HTTP/2 with ALPN, as the listener terminates TLS
+// before the http server
+func withHTTP2(tlsConfig *tls.Config) *tls.Config {
+	for _, proto := range tlsConfig.NextProtos {
+		if proto == "h2" {
+			return tlsConfig
+		}
+	}
+	tlsConfig = tlsConfig.Clone()
+	tlsConfig.NextProtos = append([]string{"h2", "http/1.1"}, tlsConfig.NextProtos...)
+	return tlsConfig
 }
 
 // ServeCommand will start the application REST service as a blocking process. It
@@ -123,8 +289,12 @@ func ServeCommand(cdc *codec.Codec, registerRoutesFn func(*RestServer)) *cobra.C
 	return flags.RegisterRestServerFlags(cmd)
 }
 
-func StartRestServer(cdc *codec.Codec, registerRoutesFn func(*RestServer), tmNode *node.Node, addr string) error {
-	rs := NewRestServer(cdc, tmNode)
+// StartRestServer registers the routes on the rest server created by NewRestServer and starts it
+func StartRestServer(rs *RestServer, registerRoutesFn func(*RestServer), addr string, security SecurityConfig) error {
+	if err := rs.SetSecurity(security); err != nil {
+		rs.log.Error("invalid rest server security config", "err", err)
+		return err
+	}
 
 	registerRoutesFn(rs)
 	rs.registerSwaggerUI()
@@ -0,0 +1,105 @@
+package lcd
+
+import (
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// startTestServer starts the rest server with the handler and returns its url
+func startTestServer(t *testing.T, grace time.Duration, h http.Handler) (*RestServer, string) {
+	rs := NewRestServer(nil, nil)
+	rs.Mux.Handle("/", h)
+	rs.SetHTTPConfig(HTTPConfig{ShutdownGracePeriod: grace})
+	go func() { _ = rs.Start("tcp://127.0.0.1:0", 0, 10, 10, false) }()
+
+	var url string
+	require.Eventually(t, func() bool {
+		rs.shutdownLock.Lock()
+		defer rs.shutdownLock.Unlock()
+		if rs.server == nil {
+			return false
+		}
+		url = "http://" + rs.listener.Addr().String()
+		return true
+	}, time.Second, 10*time.Millisecond)
+	return rs, url
+}
+
+func TestRestServerShutdownDrain(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	rs, url := startTestServer(t, 5*time.Second, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		_, _ = w.Write([]byte("done"))
+	}))
+
+	var mtx sync.Mutex
+	var calls []string
+	rs.RegisterOnShutdown(func() {
+		mtx.Lock()
+		defer mtx.Unlock()
+		calls = append(calls, "shutdown")
+	})
+	rs.RegisterOnDrain(func() {
+		mtx.Lock()
+		defer mtx.Unlock()
+		calls = append(calls, "drain")
+	})
+
+	inFlight := make(chan string, 1)
+	go func() {
+		resp, err := http.Get(url)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		inFlight <- string(body)
+	}()
+	<-started
+
+	shutdown := make(chan struct{})
+	go func() {
+		rs.Shutdown()
+		close(shutdown)
+	}()
+
+	// no request is accepted once the shutdown starts, the in-flight one still finishes
+	require.Eventually(t, func() bool {
+		_, err := http.Get(url)
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+	mtx.Lock()
+	require.Equal(t, []string{"drain"}, calls)
+	mtx.Unlock()
+
+	close(release)
+	require.Equal(t, "done", <-inFlight)
+	<-shutdown
+	require.Equal(t, []string{"drain", "shutdown"}, calls)
+}
+
+func TestRestServerShutdownGracePeriod(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	rs, url := startTestServer(t, 100*time.Millisecond, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+
+	inFlight := make(chan error, 1)
+	go func() {
+		_, err := http.Get(url)
+		inFlight <- err
+	}()
+	<-started
+
+	// the request outliving the grace period has its connection closed
+	start := time.Now()
+	rs.Shutdown()
+	require.Less(t, int64(time.Since(start)), int64(time.Second))
+	require.Error(t, <-inFlight)
+}
@@ -0,0 +1,130 @@
+package lcd
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/handlers"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// SecurityConfig defines the access control and the TLS termination of the rest server, which are shared
+// with the servers started along with it, such as the web3 websocket server.
+type SecurityConfig struct {
+	// CORSOrigins are the origins allowed to make CORS requests, "*" allows all of them and empty disables CORS
+	CORSOrigins []string
+	// VHosts are the host names accepted in the Host header of the requests, "*" or empty accepts all of them
+	VHosts []string
+
+	// TLSCertFile and TLSKeyFile are the certificate and key files the TLS connections are served with
+	TLSCertFile string
+	TLSKeyFile  string
+	// ACMEHosts are the host names to obtain certificates for from Let's Encrypt, exclusive with the cert files
+	ACMEHosts []string
+	// ACMECacheDir is the directory the obtained certificates are cached in
+	ACMECacheDir string
+}
+
+// Handler wraps the handler with the virtual host check and the CORS policy of the config.
+func (c SecurityConfig) Handler(h http.Handler) http.Handler {
+	if len(c.CORSOrigins) > 0 {
+		h = handlers.CORS(
+			handlers.AllowedHeaders([]string{"Content-Type"}),
+			handlers.AllowedOrigins(c.CORSOrigins),
+		)(h)
+	}
+	return NewVHostHandler(c.VHosts, h)
+}
+
+// NewTLSConfig returns the TLS config of the cert files or of the ACME hosts, or nil if TLS is disabled.
+func (c SecurityConfig) NewTLSConfig() (*tls.Config, error) {
+	useFiles := c.TLSCertFile != "" || c.TLSKeyFile != ""
+	switch {
+	case useFiles && len(c.ACMEHosts) > 0:
+		return nil, errors.New("the TLS cert files and the ACME hosts can't be set together")
+	case useFiles:
+		if c.TLSCertFile == "" || c.TLSKeyFile == "" {
+			return nil, errors.New("both the TLS cert file and key file must be set")
+		}
+		cert, err := tls.LoadX509KeyPair(c.TLSCertFile, c.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+	case len(c.ACMEHosts) > 0:
+		if c.ACMECacheDir == "" {
+			return nil, errors.New("the ACME cache dir must be set")
+		}
+		// the certificates are obtained with the tls-alpn-01 challenge, which requires the listener
+		// to be reachable on port 443 of the hosts
+		m := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(c.ACMEHosts...),
+			Cache:      autocert.DirCache(c.ACMECacheDir),
+		}
+		return m.TLSConfig(), nil
+	default:
+		return nil, nil
+	}
+}
+
+// vhostHandler rejects the requests whose Host header isn't one of the allowed virtual hosts, which
+// protects the servers on the local network from DNS rebinding attacks
+type vhostHandler struct {
+	vhosts map[string]struct{}
+	next   http.Handler
+}
+
+// NewVHostHandler returns a handler which only serves the requests sent to one of the vhosts.
+// IP addresses and localhost are always accepted, as they can't be used for DNS rebinding, and the
+// websocket server forwards its calls to the rest server on localhost.
+func NewVHostHandler(vhosts []string, next http.Handler) http.Handler {
+	vhostMap := make(map[string]struct{}, len(vhosts))
+	for _, vhost := range vhosts {
+		if vhost == "*" {
+			return next
+		}
+		vhostMap[strings.ToLower(vhost)] = struct{}{}
+	}
+	if len(vhostMap) == 0 {
+		return next
+	}
+	return &vhostHandler{vhosts: vhostMap, next: next}
+}
+
+func (h *vhostHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// requests without a host are sent over http/1.0 or by clients on the same machine
+	if r.Host == "" {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		// the host has no port
+		host = r.Host
+	}
+	host = strings.ToLower(host)
+	if net.ParseIP(host) != nil || host == "localhost" {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	if _, ok := h.vhosts[host]; ok {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	http.Error(w, "invalid host specified", http.StatusForbidden)
+}
+
+// SplitList splits a comma separated flag value, dropping the empty items.
+func SplitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
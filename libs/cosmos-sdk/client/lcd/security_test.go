@@ -0,0 +1,101 @@
+package lcd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testCertFile = "../../../tendermint/rpc/jsonrpc/server/test.crt"
+	testKeyFile  = "../../../tendermint/rpc/jsonrpc/server/test.key"
+)
+
+func serve(h http.Handler, method, host, origin string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "http://example.com/", nil)
+	req.Host = host
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestVHostHandler(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	testCases := []struct {
+		vhosts []string
+		host   string
+		code   int
+	}{
+		{nil, "evil.com", http.StatusOK},
+		{[]string{"*"}, "evil.com", http.StatusOK},
+		{[]string{"rpc.example.com"}, "rpc.example.com", http.StatusOK},
+		{[]string{"rpc.example.com"}, "RPC.example.com:8545", http.StatusOK},
+		{[]string{"rpc.example.com"}, "evil.com", http.StatusForbidden},
+		{[]string{"rpc.example.com"}, "evil.com:8545", http.StatusForbidden},
+		{[]string{"rpc.example.com"}, "127.0.0.1:8545", http.StatusOK},
+		{[]string{"rpc.example.com"}, "[::1]:8545", http.StatusOK},
+		{[]string{"rpc.example.com"}, "localhost:8545", http.StatusOK},
+		{[]string{"rpc.example.com"}, "", http.StatusOK},
+	}
+	for _, tc := range testCases {
+		rec := serve(NewVHostHandler(tc.vhosts, ok), http.MethodPost, tc.host, "")
+		require.Equal(t, tc.code, rec.Code, "vhosts %v, host %s", tc.vhosts, tc.host)
+	}
+}
+
+func TestSecurityConfigHandler(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	// CORS is disabled by default
+	rec := serve(SecurityConfig{}.Handler(ok), http.MethodOptions, "localhost", "https://app.example.com")
+	require.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+
+	h := SecurityConfig{CORSOrigins: []string{"https://app.example.com"}, VHosts: []string{"rpc.example.com"}}.Handler(ok)
+	rec = serve(h, http.MethodOptions, "rpc.example.com", "https://app.example.com")
+	require.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	rec = serve(h, http.MethodOptions, "rpc.example.com", "https://evil.com")
+	require.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	// the vhost is checked before the CORS policy
+	rec = serve(h, http.MethodOptions, "evil.com", "https://app.example.com")
+	require.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = serve(SecurityConfig{CORSOrigins: []string{"*"}}.Handler(ok), http.MethodOptions, "localhost", "https://evil.com")
+	require.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestSecurityConfigNewTLSConfig(t *testing.T) {
+	tlsConfig, err := SecurityConfig{}.NewTLSConfig()
+	require.NoError(t, err)
+	require.Nil(t, tlsConfig)
+
+	tlsConfig, err = SecurityConfig{TLSCertFile: testCertFile, TLSKeyFile: testKeyFile}.NewTLSConfig()
+	require.NoError(t, err)
+	require.Len(t, tlsConfig.Certificates, 1)
+
+	tlsConfig, err = SecurityConfig{ACMEHosts: []string{"rpc.example.com"}, ACMECacheDir: t.TempDir()}.NewTLSConfig()
+	require.NoError(t, err)
+	require.NotNil(t, tlsConfig.GetCertificate)
+
+	for _, cfg := range []SecurityConfig{
+		{TLSCertFile: testCertFile},
+		{TLSKeyFile: testKeyFile},
+		{TLSCertFile: testKeyFile, TLSKeyFile: testKeyFile},
+		{TLSCertFile: testCertFile, TLSKeyFile: testKeyFile, ACMEHosts: []string{"rpc.example.com"}, ACMECacheDir: t.TempDir()},
+		{ACMEHosts: []string{"rpc.example.com"}},
+	} {
+		_, err = cfg.NewTLSConfig()
+		require.Error(t, err)
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	require.Nil(t, SplitList(""))
+	require.Equal(t, []string{"a.com", "b.com"}, SplitList(" a.com,,b.com ,"))
+}
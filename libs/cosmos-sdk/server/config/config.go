@@ -37,9 +37,10 @@ type BaseConfig struct {
 
 // Config defines the server's top level configuration
 type Config struct {
-	BaseConfig    `mapstructure:",squash"`
-	BackendConfig *BackendConfig `mapstructure:"backend"`
-	StreamConfig  *StreamConfig  `mapstructure:"stream"`
+	BaseConfig      `mapstructure:",squash"`
+	BackendConfig   *BackendConfig   `mapstructure:"backend"`
+	StreamConfig    *StreamConfig    `mapstructure:"stream"`
+	EventSinkConfig *EventSinkConfig `mapstructure:"event_sink"`
 }
 
 // SetMinGasPrices sets the validator's minimum gas prices.
@@ -76,7 +77,8 @@ func DefaultConfig() *Config {
 			MinGasPrices:    defaultMinGasPrices,
 			InterBlockCache: true,
 		},
-		BackendConfig: DefaultBackendConfig(),
-		StreamConfig:  DefaultStreamConfig(),
+		BackendConfig:   DefaultBackendConfig(),
+		StreamConfig:    DefaultStreamConfig(),
+		EventSinkConfig: DefaultEventSinkConfig(),
 	}
 }
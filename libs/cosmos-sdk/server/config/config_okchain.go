@@ -111,3 +111,25 @@ func DefaultStreamConfig() *StreamConfig {
 		Engine: "",
 	}
 }
+
+// EventSinkConfig - config of the publication of the committed blocks, tx results and evm logs
+type EventSinkConfig struct {
+	// Type is the broker the events are published to: kafka | nats, empty disables the sink
+	Type string `json:"type" mapstructure:"type"`
+	// Addrs are the comma separated addresses of the brokers
+	Addrs      string `json:"addrs" mapstructure:"addrs"`
+	BlockTopic string `json:"block_topic" mapstructure:"block_topic"`
+	TxTopic    string `json:"tx_topic" mapstructure:"tx_topic"`
+	LogTopic   string `json:"log_topic" mapstructure:"log_topic"`
+	// StartHeight is the first height published by a new sink, 0 starts from the latest block
+	StartHeight int64 `json:"start_height" mapstructure:"start_height"`
+}
+
+// DefaultEventSinkConfig returns the default config of the event sink, which is disabled
+func DefaultEventSinkConfig() *EventSinkConfig {
+	return &EventSinkConfig{
+		BlockTopic: "exchain.blocks",
+		TxTopic:    "exchain.txs",
+		LogTopic:   "exchain.logs",
+	}
+}
@@ -75,6 +75,19 @@ pushservice_pulsar_public_topic = "{{ .StreamConfig.PushservicePulsarPublicTopic
 pushservice_pulsar_private_topic = "{{ .StreamConfig.PushservicePulsarPrivateTopic }}"
 pushservice_pulsar_depth_topic = "{{ .StreamConfig.PushservicePulsarDepthTopic }}"
 redis_require_pass = "{{ .StreamConfig.RedisRequirePass }}"
+
+##### event sink configuration options #####
+# Publish the committed blocks, tx results and evm logs as json messages, at least once and in order
+[event_sink]
+# The broker the events are published to: kafka | nats, empty disables the sink
+type = "{{ .EventSinkConfig.Type }}"
+# Comma separated addresses of the brokers, e.g. "10.0.0.1:9092,10.0.0.2:9092" or "10.0.0.1:4222"
+addrs = "{{ .EventSinkConfig.Addrs }}"
+block_topic = "{{ .EventSinkConfig.BlockTopic }}"
+tx_topic = "{{ .EventSinkConfig.TxTopic }}"
+log_topic = "{{ .EventSinkConfig.LogTopic }}"
+# The first height published by a new sink, 0 starts from the latest block
+start_height = {{ .EventSinkConfig.StartHeight }}
 `
 
 var configTemplate *template.Template
@@ -23,6 +23,9 @@ const (
 	flagJailWhitelist = "jail-whitelist"
 	FlagEvmExportMode = "evm-export-mode"
 	FlagEvmExportPath = "evm-export-path"
+
+	FlagEvmExportAddresses   = "evm-export-addresses"
+	FlagEvmExportSinceHeight = "evm-export-since-height"
 )
 
 // ExportCmd dumps app state to JSON.
@@ -93,6 +96,8 @@ func ExportCmd(ctx *Context, cdc *codec.Codec, appExporter AppExporter) *cobra.C
 	cmd.Flags().String(FlagEvmExportMode, "default", "Select export mode for evm state (default|files|db)")
 	cmd.Flags().String(FlagEvmExportPath, "", "Evm contract & storage db or files used for export")
 	cmd.Flags().Uint64(FlagGoroutineNum, 0, "Limit on the number of goroutines used to export evm data(ignored if evm-export-mode is 'default')")
+	cmd.Flags().StringSlice(FlagEvmExportAddresses, []string{}, "Export the evm code and storage of these hex addresses only")
+	cmd.Flags().Int64(FlagEvmExportSinceHeight, 0, "Export the evm code and storage of the accounts which emitted logs since this height only (0 means all accounts)")
 	return cmd
 }
 
@@ -56,6 +56,10 @@ func (ms multiStore) SetPruning(opts sdk.PruningOptions) {
 	panic("not implemented")
 }
 
+func (ms multiStore) SetStorePruning(name string, opts sdk.PruningOptions) {
+	panic("not implemented")
+}
+
 func (ms multiStore) GetCommitKVStore(key sdk.StoreKey) sdk.CommitKVStore {
 	panic("not implemented")
 }
@@ -196,3 +200,7 @@ func (ms multiStore) SetLogger(log log.Logger) {
 func (ms multiStore) GetCommitVersion() (int64, error) {
 	panic("not implemented")
 }
+
+func (ms multiStore) RollbackToVersion(ver int64) error {
+	panic("not implemented")
+}
@@ -154,6 +154,7 @@ which accepts a path for the resulting pprof file.
 	cmd.Flags().String(tmtypes.FlagRedisAuth, "", "redis auth")
 
 	cmd.Flags().Int(iavl.FlagIavlCacheSize, 1000000, "Max size of iavl cache")
+	cmd.Flags().StringSlice(iavl.FlagIavlFastIndexStores, []string{}, "The stores keeping a flat index of their latest values for faster reads, e.g. evm")
 	cmd.Flags().StringToInt(tmiavl.FlagOutputModules, map[string]int{"evm": 1, "acc": 1}, "decide which module in iavl to be printed")
 	cmd.Flags().Int64(tmiavl.FlagIavlCommitIntervalHeight, 100, "Max interval to commit node cache into leveldb")
 	cmd.Flags().Int64(tmiavl.FlagIavlMinCommitItemCount, 500000, "Min nodes num to triggle node cache commit")
@@ -165,6 +166,7 @@ which accepts a path for the resulting pprof file.
 	cmd.Flags().Bool(abci.FlagDisableABCIQueryMutex, false, "Disable local client query mutex for better concurrency")
 	cmd.Flags().Bool(abci.FlagDisableCheckTx, false, "Disable checkTx for test")
 	cmd.Flags().MarkHidden(abci.FlagDisableCheckTx)
+	cmd.Flags().Bool(abci.FlagEnableConcurrentCheckTx, false, "Enable running the stateless checks of CheckTx concurrently")
 	cmd.Flags().Bool(abci.FlagCloseMutex, false, fmt.Sprintf("Deprecated in v0.19.13 version, use --%s instead.", abci.FlagDisableABCIQueryMutex))
 	cmd.Flags().MarkHidden(abci.FlagCloseMutex)
 	cmd.Flags().Bool(tmiavl.FlagIavlEnableGid, false, "Display goroutine id in iavl log")
@@ -306,7 +308,16 @@ func startInProcess(ctx *Context, cdc *codec.Codec, appCreator AppCreator, appSt
 		}
 	}
 
+	var restServer *lcd.RestServer
+	if registerRoutesFn != nil {
+		restServer = lcd.NewRestServer(cdc, tmNode)
+	}
+
 	TrapSignal(func() {
+		// drain the rpc connections before the node stops serving them
+		if restServer != nil {
+			restServer.Shutdown()
+		}
 		if tmNode.IsRunning() {
 			_ = tmNode.Stop()
 		}
@@ -319,8 +330,9 @@ func startInProcess(ctx *Context, cdc *codec.Codec, appCreator AppCreator, appSt
 		ctx.Logger.Info("exiting...")
 	})
 
-	if registerRoutesFn != nil {
-		go lcd.StartRestServer(cdc, registerRoutesFn, tmNode, viper.GetString(FlagListenAddr))
+	if restServer != nil {
+		restServer.SetHTTPConfig(restHTTPConfig())
+		go lcd.StartRestServer(restServer, registerRoutesFn, viper.GetString(FlagListenAddr), restSecurityConfig(cfg.RootDir))
 	}
 
 	baseapp.SetGlobalMempool(tmNode.Mempool(), cfg.Mempool.SortTxByGp, cfg.Mempool.EnablePendingPool)
@@ -344,6 +356,7 @@ func startInProcess(ctx *Context, cdc *codec.Codec, appCreator AppCreator, appSt
 // Use setExternalPackageValue to set external package config value.
 func setExternalPackageValue(cmd *cobra.Command) {
 	iavl.IavlCacheSize = viper.GetInt(iavl.FlagIavlCacheSize)
+	iavl.SetFastIndexStores(viper.GetStringSlice(iavl.FlagIavlFastIndexStores))
 	tmiavl.OutputModules, _ = cmd.Flags().GetStringToInt(tmiavl.FlagOutputModules)
 	tmiavl.CommitIntervalHeight = viper.GetInt64(tmiavl.FlagIavlCommitIntervalHeight)
 	tmiavl.MinCommitItemCount = viper.GetInt64(tmiavl.FlagIavlMinCommitItemCount)
@@ -359,4 +372,5 @@ func setExternalPackageValue(cmd *cobra.Command) {
 
 	abci.SetDisableABCIQueryMutex(viper.GetBool(abci.FlagDisableABCIQueryMutex))
 	abci.SetDisableCheckTx(viper.GetBool(abci.FlagDisableCheckTx))
+	abci.SetEnableConcurrentCheckTx(viper.GetBool(abci.FlagEnableConcurrentCheckTx))
 }
@@ -8,12 +8,15 @@ import (
 	"path/filepath"
 	"reflect"
 	"strconv"
+	"time"
 
 	"github.com/okex/exchain/libs/cosmos-sdk/client/flags"
+	"github.com/okex/exchain/libs/cosmos-sdk/client/lcd"
 
 	"github.com/okex/exchain/libs/cosmos-sdk/server/config"
 	cmn "github.com/okex/exchain/libs/tendermint/libs/os"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 // exchain full-node start flags
@@ -24,11 +27,29 @@ const (
 	FlagUlockKeyHome       = "rest.unlock_key_home"
 	FlagRestPathPrefix     = "rest.path_prefix"
 	FlagCORS               = "cors"
+	FlagVHosts             = "rest.vhosts"
+	FlagTLSCertFile        = "rest.tls_cert_file"
+	FlagTLSKeyFile         = "rest.tls_key_file"
+	FlagTLSACMEHosts       = "rest.tls_acme_hosts"
+	FlagTLSACMEDir         = "rest.tls_acme_dir"
 	FlagMaxOpenConnections = "max-open"
+	FlagReadTimeout        = "rest.read_timeout"
+	FlagWriteTimeout       = "rest.write_timeout"
+	FlagIdleTimeout        = "rest.idle_timeout"
+	FlagMaxHeaderBytes     = "rest.max_header_bytes"
+	FlagHTTP2              = "rest.http2"
+	FlagHTTP2MaxStreams    = "rest.http2_max_concurrent_streams"
+	FlagShutdownGrace      = "rest.shutdown_grace_period"
 	FlagHookstartInProcess = "startInProcess"
 	FlagWebsocket          = "wsport"
 	FlagWsMaxConnections   = "ws.max_connections"
 	FlagWsSubChannelLength = "ws.sub_channel_length"
+	FlagWsOrigins          = "ws.origins"
+
+	// websocket connection limits and keepalive flags
+	FlagWsMaxConnectionsPerIP = "ws.max_connections_per_ip"
+	FlagWsPingInterval        = "ws.ping_interval"
+	FlagWsIdleTimeout         = "ws.idle_timeout"
 
 	// plugin flags
 	FlagBackendEnableBackend       = "backend.enable_backend"
@@ -189,17 +210,62 @@ func registerRestServerFlags(cmd *cobra.Command) *cobra.Command {
 	cmd.Flags().String(FlagUlockKeyHome, os.ExpandEnv("$HOME/.exchaincli"), "The keybase home path")
 	cmd.Flags().String(FlagRestPathPrefix, "exchain", "Path prefix for registering rest api route.")
 	cmd.Flags().String(flags.FlagKeyringBackend, flags.DefaultKeyringBackend, "Select keyring's backend (os|file|test)")
-	cmd.Flags().String(FlagCORS, "", "Set the rest-server domains that can make CORS requests (* for all)")
+	cmd.Flags().String(FlagCORS, "", "Set the rest-server domains that can make CORS requests, comma separated (* for all)")
+	cmd.Flags().String(FlagVHosts, "*", "Comma separated host names the rest-server and websocket server accept requests for (* for all), IP addresses and localhost are always accepted")
+	cmd.Flags().String(FlagTLSCertFile, "", "TLS certificate file the rest-server and websocket server are served with")
+	cmd.Flags().String(FlagTLSKeyFile, "", "TLS key file the rest-server and websocket server are served with")
+	cmd.Flags().String(FlagTLSACMEHosts, "", "Comma separated host names to obtain the TLS certificates for from Let's Encrypt, the rest-server must be reachable on port 443 of the hosts")
+	cmd.Flags().String(FlagTLSACMEDir, "", "Directory the ACME certificates are cached in (default \"<home>/data/acme\")")
 	cmd.Flags().Int(FlagMaxOpenConnections, 1000, "The number of maximum open connections of rest-server")
+	cmd.Flags().Duration(FlagReadTimeout, 0, "Max duration of reading a request of the rest-server, 0 for no timeout")
+	cmd.Flags().Duration(FlagWriteTimeout, 0, "Max duration of writing a response of the rest-server, 0 for no timeout")
+	cmd.Flags().Duration(FlagIdleTimeout, 0, "Time the idle keep-alive connections of the rest-server are kept open, 0 for the read timeout")
+	cmd.Flags().Int(FlagMaxHeaderBytes, 1<<20, "Max size in bytes of the request headers of the rest-server")
+	cmd.Flags().Bool(FlagHTTP2, true, "Serve HTTP/2 on the rest-server, over TLS and over cleartext for the clients with prior knowledge")
+	cmd.Flags().Uint32(FlagHTTP2MaxStreams, 250, "Max number of concurrent requests of a HTTP/2 connection of the rest-server")
+	cmd.Flags().Duration(FlagShutdownGrace, 10*time.Second, "Time the in-flight requests of the rest-server and the websocket connections are given to finish when the node exits")
 	cmd.Flags().String(FlagExternalListenAddr, "127.0.0.1:26659", "Set the rest-server external ip and port, when it is launched by Docker")
 	cmd.Flags().String(FlagWebsocket, "8546", "websocket port to listen to")
 	cmd.Flags().Int(FlagWsMaxConnections, 20000, "the max capacity number of websocket client connections")
 	cmd.Flags().Int(FlagWsSubChannelLength, 100, "the length of subscription channel")
+	cmd.Flags().String(FlagWsOrigins, "*", "Comma separated origins the websocket server accepts connections from (* for all)")
+	cmd.Flags().Int(FlagWsMaxConnectionsPerIP, 0, "the max number of websocket client connections from one ip, 0 for no limit")
+	cmd.Flags().Duration(FlagWsPingInterval, 30*time.Second, "the interval the websocket clients are pinged on, 0 disables the pings")
+	cmd.Flags().Duration(FlagWsIdleTimeout, 60*time.Second, "the websocket connections which send neither a message nor a pong within it are closed, 0 disables the timeout")
 	cmd.Flags().String(flags.FlagChainID, "", "Chain ID of tendermint node for web3")
 	cmd.Flags().StringP(flags.FlagBroadcastMode, "b", flags.BroadcastSync, "Transaction broadcasting mode (sync|async|block) for web3")
 	return cmd
 }
 
+// restSecurityConfig returns the access control and the TLS termination of the rest server set by the flags
+func restSecurityConfig(rootDir string) lcd.SecurityConfig {
+	acmeDir := viper.GetString(FlagTLSACMEDir)
+	if acmeDir == "" {
+		acmeDir = filepath.Join(rootDir, "data", "acme")
+	}
+	return lcd.SecurityConfig{
+		CORSOrigins:  lcd.SplitList(viper.GetString(FlagCORS)),
+		VHosts:       lcd.SplitList(viper.GetString(FlagVHosts)),
+		TLSCertFile:  viper.GetString(FlagTLSCertFile),
+		TLSKeyFile:   viper.GetString(FlagTLSKeyFile),
+		ACMEHosts:    lcd.SplitList(viper.GetString(FlagTLSACMEHosts)),
+		ACMECacheDir: acmeDir,
+	}
+}
+
+// restHTTPConfig returns the tuning of the http connections of the rest server set by the flags
+func restHTTPConfig() lcd.HTTPConfig {
+	return lcd.HTTPConfig{
+		ReadTimeout:          viper.GetDuration(FlagReadTimeout),
+		WriteTimeout:         viper.GetDuration(FlagWriteTimeout),
+		IdleTimeout:          viper.GetDuration(FlagIdleTimeout),
+		MaxHeaderBytes:       viper.GetInt(FlagMaxHeaderBytes),
+		HTTP2:                viper.GetBool(FlagHTTP2),
+		MaxConcurrentStreams: viper.GetUint32(FlagHTTP2MaxStreams),
+		ShutdownGracePeriod:  viper.GetDuration(FlagShutdownGrace),
+	}
+}
+
 // registerExChainPluginFlags registers the flags required for rest server
 func registerExChainPluginFlags(cmd *cobra.Command) *cobra.Command {
 	cmd.Flags().Bool(FlagBackendEnableBackend, backendConf.EnableBackend, "Enable the node's backend plugin")
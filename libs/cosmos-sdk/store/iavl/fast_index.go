@@ -0,0 +1,270 @@
+package iavl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	dbm "github.com/tendermint/tm-db"
+)
+
+const (
+	FlagIavlFastIndexStores = "iavl-fast-index-stores"
+
+	fastIndexBatchSize = 10000
+)
+
+var (
+	// FastIndexStores are the names of the stores keeping a flat index of their latest values
+	FastIndexStores = map[string]bool{}
+
+	fastNodePrefix      = []byte("f/")
+	fastIndexVersionKey = []byte("m/fast_index_version")
+)
+
+// SetFastIndexStores sets the stores keeping a fast index
+func SetFastIndexStores(names []string) {
+	FastIndexStores = make(map[string]bool, len(names))
+	for _, name := range names {
+		FastIndexStores[name] = true
+	}
+}
+
+// IsFastIndexStore returns whether the store with the given name keeps a fast index
+func IsFastIndexStore(name string) bool {
+	return FastIndexStores[name]
+}
+
+// fastIndex is a flat key/value copy of the latest version of a tree, living in the tree's db next to its
+// nodes. It serves the reads of the latest version without traversing the tree, and is trusted only while
+// its version is the one of the tree, e.g. it is left behind when it is enabled on an existing store until
+// it gets built, or when the tree rolls back. The reads fall back to the tree on any miss.
+type fastIndex struct {
+	mtx     sync.RWMutex
+	db      dbm.DB
+	nodes   dbm.DB
+	version int64
+
+	// the changes since the last commit, a nil value is a removal
+	pending map[string][]byte
+}
+
+func newFastIndex(db dbm.DB, treeVersion int64) (*fastIndex, error) {
+	fi := &fastIndex{
+		db:      db,
+		nodes:   dbm.NewPrefixDB(db, fastNodePrefix),
+		version: -1,
+		pending: make(map[string][]byte),
+	}
+
+	bz, err := db.Get(fastIndexVersionKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(bz) == 8 {
+		fi.version = int64(binary.BigEndian.Uint64(bz))
+	} else if treeVersion == 0 {
+		// the index of an empty tree is complete from the start
+		fi.version = 0
+	}
+	return fi, nil
+}
+
+func (fi *fastIndex) get(key []byte, treeVersion int64) ([]byte, bool) {
+	fi.mtx.RLock()
+	defer fi.mtx.RUnlock()
+
+	if value, ok := fi.pending[string(key)]; ok {
+		return value, true
+	}
+	if fi.version != treeVersion {
+		return nil, false
+	}
+
+	value, err := fi.nodes.Get(key)
+	if err != nil || value == nil {
+		return nil, false
+	}
+	return value, true
+}
+
+func (fi *fastIndex) set(key, value []byte) {
+	fi.mtx.Lock()
+	fi.pending[string(key)] = value
+	fi.mtx.Unlock()
+}
+
+func (fi *fastIndex) remove(key []byte) {
+	fi.mtx.Lock()
+	fi.pending[string(key)] = nil
+	fi.mtx.Unlock()
+}
+
+// commit writes the pending changes if the index was up to date with the previous version of the tree
+func (fi *fastIndex) commit(prevVersion, version int64) error {
+	fi.mtx.Lock()
+	defer fi.mtx.Unlock()
+
+	pending := fi.pending
+	fi.pending = make(map[string][]byte)
+	if fi.version != prevVersion {
+		return nil
+	}
+
+	batch := fi.db.NewBatch()
+	defer batch.Close()
+	for key, value := range pending {
+		if value == nil {
+			batch.Delete(fastNodeKey([]byte(key)))
+		} else {
+			batch.Set(fastNodeKey([]byte(key)), value)
+		}
+	}
+	batch.Set(fastIndexVersionKey, versionBytes(version))
+	if err := batch.Write(); err != nil {
+		return err
+	}
+
+	fi.version = version
+	return nil
+}
+
+// invalidate marks the index as outdated, e.g. when the tree is updated by a delta which doesn't go through
+// the setters of the store. The index is left to be rebuilt.
+func (fi *fastIndex) invalidate() error {
+	fi.mtx.Lock()
+	defer fi.mtx.Unlock()
+
+	fi.pending = make(map[string][]byte)
+	if fi.version < 0 {
+		return nil
+	}
+	if err := fi.db.Delete(fastIndexVersionKey); err != nil {
+		return err
+	}
+	fi.version = -1
+	return nil
+}
+
+// rebuild replaces the index with the values of the tree at the given version
+func (fi *fastIndex) rebuild(version int64, iterate func(fn func(key, value []byte) bool) bool) (int, error) {
+	fi.mtx.Lock()
+	defer fi.mtx.Unlock()
+
+	if err := fi.clear(); err != nil {
+		return 0, err
+	}
+
+	var count int
+	var err error
+	batch := fi.db.NewBatch()
+	iterate(func(key, value []byte) bool {
+		batch.Set(fastNodeKey(key), value)
+		count++
+		if count%fastIndexBatchSize == 0 {
+			if err = batch.Write(); err != nil {
+				return true
+			}
+			batch.Close()
+			batch = fi.db.NewBatch()
+		}
+		return false
+	})
+	defer batch.Close()
+	if err != nil {
+		return count, err
+	}
+
+	batch.Set(fastIndexVersionKey, versionBytes(version))
+	if err := batch.WriteSync(); err != nil {
+		return count, err
+	}
+
+	fi.version = version
+	fi.pending = make(map[string][]byte)
+	return count, nil
+}
+
+// clear removes all the nodes of the index and marks it as outdated. The nodes are deleted by batches of
+// fastIndexBatchSize keys, so that clearing a large index doesn't hold all its keys in memory.
+func (fi *fastIndex) clear() error {
+	if err := fi.db.Delete(fastIndexVersionKey); err != nil {
+		return err
+	}
+	fi.version = -1
+
+	for {
+		it, err := fi.nodes.Iterator(nil, nil)
+		if err != nil {
+			return err
+		}
+		keys := make([][]byte, 0, fastIndexBatchSize)
+		for ; it.Valid() && len(keys) < fastIndexBatchSize; it.Next() {
+			keys = append(keys, append([]byte{}, it.Key()...))
+		}
+		it.Close()
+		if len(keys) == 0 {
+			return nil
+		}
+
+		batch := fi.db.NewBatch()
+		for _, key := range keys {
+			batch.Delete(fastNodeKey(key))
+		}
+		err = batch.Write()
+		batch.Close()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func fastNodeKey(key []byte) []byte {
+	return append(append(make([]byte, 0, len(fastNodePrefix)+len(key)), fastNodePrefix...), key...)
+}
+
+func versionBytes(version int64) []byte {
+	bz := make([]byte, 8)
+	binary.BigEndian.PutUint64(bz, uint64(version))
+	return bz
+}
+
+// EnableFastIndex makes the store keep a fast index of its latest values in the given db, which must be
+// the one of the tree
+func (st *Store) EnableFastIndex(db dbm.DB) error {
+	fi, err := newFastIndex(db, st.tree.Version())
+	if err != nil {
+		return err
+	}
+	st.fastIndex = fi
+	return nil
+}
+
+// IsFastIndexUpToDate returns whether the fast index serves the reads of the latest version
+func (st *Store) IsFastIndexUpToDate() bool {
+	if st.fastIndex == nil {
+		return false
+	}
+	st.fastIndex.mtx.RLock()
+	defer st.fastIndex.mtx.RUnlock()
+	return st.fastIndex.version == st.tree.Version()
+}
+
+// BuildFastIndex rebuilds the fast index from the latest version of the tree and returns the number of
+// indexed keys
+func (st *Store) BuildFastIndex() (int, error) {
+	if st.fastIndex == nil {
+		return 0, fmt.Errorf("fast index is not enabled on the store")
+	}
+
+	version := st.tree.Version()
+	if version == 0 {
+		return st.fastIndex.rebuild(version, func(func(key, value []byte) bool) bool { return false })
+	}
+
+	tree, err := st.tree.GetImmutable(version)
+	if err != nil {
+		return 0, err
+	}
+	return st.fastIndex.rebuild(version, tree.Iterate)
+}
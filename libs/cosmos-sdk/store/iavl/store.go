@@ -34,7 +34,8 @@ var (
 
 // Store Implements types.KVStore and CommitKVStore.
 type Store struct {
-	tree Tree
+	tree      Tree
+	fastIndex *fastIndex
 }
 
 func (st *Store) StopStore() {
@@ -133,10 +134,22 @@ func (st *Store) Commit(inDelta *iavl.TreeDelta, deltas []byte) (types.CommitID,
 		flag = true
 		st.tree.SetDelta(inDelta)
 	}
+	prevVersion := st.tree.Version()
 	hash, version, delta, err := st.tree.SaveVersion(flag)
 	if err != nil {
 		panic(err)
 	}
+	if st.fastIndex != nil {
+		if flag {
+			// the changes of the delta are unknown to the index
+			err = st.fastIndex.invalidate()
+		} else {
+			err = st.fastIndex.commit(prevVersion, version)
+		}
+		if err != nil {
+			panic(err)
+		}
+	}
 
 	return types.CommitID{
 		Version: version,
@@ -182,10 +195,18 @@ func (st *Store) CacheWrapWithTrace(w io.Writer, tc types.TraceContext) types.Ca
 func (st *Store) Set(key, value []byte) {
 	types.AssertValidValue(value)
 	st.tree.Set(key, value)
+	if st.fastIndex != nil {
+		st.fastIndex.set(key, value)
+	}
 }
 
 // Implements types.KVStore.
 func (st *Store) Get(key []byte) []byte {
+	if st.fastIndex != nil {
+		if value, ok := st.fastIndex.get(key, st.tree.Version()); ok {
+			return value
+		}
+	}
 	_, value := st.tree.Get(key)
 	return value
 }
@@ -198,6 +219,17 @@ func (st *Store) Has(key []byte) (exists bool) {
 // Implements types.KVStore.
 func (st *Store) Delete(key []byte) {
 	st.tree.Remove(key)
+	if st.fastIndex != nil {
+		st.fastIndex.remove(key)
+	}
+}
+
+// AvailableVersions returns the versions of the tree kept on disk in ascending order.
+func (st *Store) AvailableVersions() []int {
+	if tree, ok := st.tree.(*iavl.MutableTree); ok {
+		return tree.AvailableVersions()
+	}
+	return nil
 }
 
 // DeleteVersions deletes a series of versions from the MutableTree. An error
@@ -207,6 +239,22 @@ func (st *Store) DeleteVersions(versions ...int64) error {
 	return st.tree.DeleteVersions(versions...)
 }
 
+// LoadVersionForOverwriting loads the given version of the tree and deletes the versions above it, so that
+// they can be committed again. The fast index, which serves the later versions, is invalidated.
+func (st *Store) LoadVersionForOverwriting(version int64) error {
+	tree, ok := st.tree.(*iavl.MutableTree)
+	if !ok {
+		return fmt.Errorf("the tree of the store is immutable")
+	}
+	if _, err := tree.LoadVersionForOverwriting(version); err != nil {
+		return err
+	}
+	if st.fastIndex != nil {
+		return st.fastIndex.invalidate()
+	}
+	return nil
+}
+
 // Implements types.KVStore.
 func (st *Store) Iterator(start, end []byte) types.Iterator {
 	var iTree *iavl.ImmutableTree
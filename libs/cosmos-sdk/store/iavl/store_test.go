@@ -429,6 +429,81 @@ func nextVersion(iStore *Store) {
 	iStore.Commit(&iavl.TreeDelta{}, nil)
 }
 
+func TestIAVLFastIndex(t *testing.T) {
+	db := dbm.NewMemDB()
+	tree, _ := newAlohaTree(t, db)
+	iavlStore := UnsafeNewStore(tree)
+	require.NoError(t, iavlStore.EnableFastIndex(db))
+
+	// an existing store is read from the tree until the index is built
+	require.False(t, iavlStore.IsFastIndexUpToDate())
+	require.Equal(t, []byte(treeData["hello"]), iavlStore.Get([]byte("hello")))
+	nextVersion(iavlStore)
+	require.False(t, iavlStore.IsFastIndexUpToDate())
+
+	count, err := iavlStore.BuildFastIndex()
+	require.NoError(t, err)
+	require.Equal(t, len(treeData)+1, count)
+	require.True(t, iavlStore.IsFastIndexUpToDate())
+
+	// the uncommitted changes are served before the index
+	iavlStore.Set([]byte("hello"), []byte("adios"))
+	iavlStore.Delete([]byte("aloha"))
+	require.Equal(t, []byte("adios"), iavlStore.Get([]byte("hello")))
+	require.Nil(t, iavlStore.Get([]byte("aloha")))
+
+	iavlStore.Commit(&iavl.TreeDelta{}, nil)
+	require.True(t, iavlStore.IsFastIndexUpToDate())
+	require.Equal(t, []byte("adios"), iavlStore.Get([]byte("hello")))
+	require.Nil(t, iavlStore.Get([]byte("aloha")))
+
+	// the committed index holds the latest values only
+	fi, err := newFastIndex(db, tree.Version())
+	require.NoError(t, err)
+	value, ok := fi.get([]byte("hello"), tree.Version())
+	require.True(t, ok)
+	require.Equal(t, []byte("adios"), value)
+	_, ok = fi.get([]byte("aloha"), tree.Version())
+	require.False(t, ok)
+
+	// an index left behind by the tree is not trusted
+	_, ok = fi.get([]byte("hello"), tree.Version()+1)
+	require.False(t, ok)
+
+	// the changes applied from a delta are unknown to the index, it is no longer updated until rebuilt
+	require.NoError(t, iavlStore.fastIndex.invalidate())
+	require.False(t, iavlStore.IsFastIndexUpToDate())
+	iavlStore.Set([]byte("hello"), []byte("hola"))
+	nextVersion(iavlStore)
+	require.False(t, iavlStore.IsFastIndexUpToDate())
+	require.Equal(t, []byte("hola"), iavlStore.Get([]byte("hello")))
+	_, err = iavlStore.BuildFastIndex()
+	require.NoError(t, err)
+	require.True(t, iavlStore.IsFastIndexUpToDate())
+}
+
+func TestIAVLFastIndexClear(t *testing.T) {
+	db := dbm.NewMemDB()
+	tree, err := iavl.NewMutableTree(db, cacheSize)
+	require.NoError(t, err)
+	iavlStore := UnsafeNewStore(tree)
+	require.NoError(t, iavlStore.EnableFastIndex(db))
+
+	// more keys than a deletion batch
+	for i := 0; i < fastIndexBatchSize+10; i++ {
+		iavlStore.Set([]byte(fmt.Sprintf("key%d", i)), []byte("value"))
+	}
+	nextVersion(iavlStore)
+	require.True(t, iavlStore.IsFastIndexUpToDate())
+
+	require.NoError(t, iavlStore.fastIndex.clear())
+	it, err := iavlStore.fastIndex.nodes.Iterator(nil, nil)
+	require.NoError(t, err)
+	defer it.Close()
+	require.False(t, it.Valid())
+	require.False(t, iavlStore.IsFastIndexUpToDate())
+}
+
 func TestIAVLNoPrune(t *testing.T) {
 	db := dbm.NewMemDB()
 	tree, err := iavl.NewMutableTree(db, cacheSize)
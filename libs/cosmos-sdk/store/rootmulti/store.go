@@ -50,6 +50,9 @@ type Store struct {
 	pruneHeights   []int64
 	versions       []int64
 
+	// the sub-stores pruned with their own strategy, by name
+	storesPruningOpts map[string]types.PruningOptions
+
 	traceWriter  io.Writer
 	traceContext types.TraceContext
 
@@ -76,6 +79,8 @@ func NewStore(db dbm.DB) *Store {
 		keysByName:   make(map[string]types.StoreKey),
 		pruneHeights: make([]int64, 0),
 		versions:     make([]int64, 0),
+
+		storesPruningOpts: make(map[string]types.PruningOptions),
 	}
 }
 
@@ -86,6 +91,13 @@ func (rs *Store) SetPruning(pruningOpts types.PruningOptions) {
 	rs.pruningOpts = pruningOpts
 }
 
+// SetStorePruning sets the pruning strategy of the named sub-store. The sub-store is then left out of the
+// pruning of the root store, and its heights are pruned at its own interval. The strategy must keep none of the
+// heights pruned by the root store, which is checked when the version is loaded.
+func (rs *Store) SetStorePruning(name string, opts types.PruningOptions) {
+	rs.storesPruningOpts[name] = opts
+}
+
 // SetLazyLoading sets if the iavl store should be loaded lazily or not
 func (rs *Store) SetLazyLoading(lazyLoading bool) {
 	rs.lazyLoading = lazyLoading
@@ -191,7 +203,64 @@ func (rs *Store) GetCommitVersion() (int64, error) {
 	return minVersion, nil
 }
 
+// RollbackToVersion deletes the versions of the IAVL stores above the given one and makes it the latest
+// version, so that the blocks after it can be committed again. The stores are loaded at that version.
+func (rs *Store) RollbackToVersion(ver int64) error {
+	latest := getLatestVersion(rs.db)
+	if ver <= 0 || ver > latest {
+		return fmt.Errorf("cannot roll back to version %d, the latest version is %d", ver, latest)
+	}
+	if err := rs.loadVersion(ver, nil); err != nil {
+		return err
+	}
+
+	for key, store := range rs.stores {
+		if store.GetStoreType() != types.StoreTypeIAVL {
+			continue
+		}
+		iStore, ok := store.(*iavl.Store)
+		if !ok {
+			return fmt.Errorf("cannot roll back the wrapped store %s", key.Name())
+		}
+		if err := iStore.LoadVersionForOverwriting(ver); err != nil {
+			return fmt.Errorf("failed to roll back store %s: %w", key.Name(), err)
+		}
+	}
+
+	batch := rs.db.NewBatch()
+	defer batch.Close()
+	for v := ver + 1; v <= latest; v++ {
+		batch.Delete([]byte(fmt.Sprintf(commitInfoKeyFmt, v)))
+	}
+	setLatestVersion(batch, ver)
+	setPruningHeights(batch, versionsUpTo(rs.pruneHeights, ver))
+	rs.versions = versionsUpTo(rs.versions, ver)
+	setVersions(batch, rs.versions)
+	if err := batch.WriteSync(); err != nil {
+		return err
+	}
+
+	return rs.loadVersion(ver, nil)
+}
+
+// versionsUpTo returns the versions not greater than ver
+func versionsUpTo(versions []int64, ver int64) []int64 {
+	var kept []int64
+	for _, v := range versions {
+		if v <= ver {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
 func (rs *Store) loadVersion(ver int64, upgrades *types.StoreUpgrades) error {
+	for name, opts := range rs.storesPruningOpts {
+		if err := opts.ValidateWithin(rs.pruningOpts); err != nil {
+			return fmt.Errorf("invalid pruning options of store %s: %w", name, err)
+		}
+	}
+
 	infos := make(map[string]storeInfo)
 	var cInfo commitInfo
 	cInfo.Version = tmtypes.GetStartBlockHeight()
@@ -442,6 +511,7 @@ func (rs *Store) Commit(_ *iavltree.TreeDelta, deltas []byte) (types.CommitID, i
 		}
 
 		rs.versions = append(rs.versions, version)
+		rs.pruneStoresWithOwnOptions(version)
 	}
 	flushMetadata(rs.db, version, rs.lastCommitInfo, rs.pruneHeights, rs.versions)
 
@@ -469,6 +539,9 @@ func (rs *Store) pruneStores() {
 		}
 	}()
 	for key, store := range rs.stores {
+		if _, ok := rs.storesPruningOpts[key.Name()]; ok {
+			continue
+		}
 		if store.GetStoreType() == types.StoreTypeIAVL {
 			// If the store is wrapped with an inter-block cache, we must first unwrap
 			// it to get the underlying IAVL store.
@@ -485,6 +558,47 @@ func (rs *Store) pruneStores() {
 	rs.pruneHeights = make([]int64, 0)
 }
 
+// pruneStoresWithOwnOptions prunes the sub-stores having their own pruning strategy. The heights to prune
+// are taken from the versions available in the sub-store, so the ones left over by a restart get pruned too.
+func (rs *Store) pruneStoresWithOwnOptions(version int64) {
+	for name, opts := range rs.storesPruningOpts {
+		if opts.Interval == 0 || version%int64(opts.Interval) != 0 {
+			continue
+		}
+		key, ok := rs.keysByName[name]
+		if !ok {
+			continue
+		}
+		store, ok := rs.GetCommitKVStore(key).(*iavl.Store)
+		if !ok {
+			continue
+		}
+
+		var pruneHeights []int64
+		for _, v := range store.AvailableVersions() {
+			height := int64(v)
+			if height >= version-int64(opts.KeepRecent) {
+				break
+			}
+			if opts.KeepEvery == 0 || height%int64(opts.KeepEvery) != 0 {
+				pruneHeights = append(pruneHeights, height)
+			}
+		}
+		if len(pruneHeights) == 0 {
+			continue
+		}
+
+		if rs.logger != nil {
+			rs.logger.Info("pruning store", "store", name, "pruning-count", len(pruneHeights), "curr-height", version)
+		}
+		if err := store.DeleteVersions(pruneHeights...); err != nil {
+			if errCause := errors.Cause(err); errCause != nil && errCause != iavltree.ErrVersionDoesNotExist {
+				panic(err)
+			}
+		}
+	}
+}
+
 func (rs *Store) FlushPruneHeights(pruneHeights []int64, versions []int64) {
 	flushMetadata(rs.db, rs.lastCommitInfo.Version, rs.lastCommitInfo, pruneHeights, versions)
 }
@@ -693,6 +807,12 @@ func (rs *Store) loadCommitStoreFromParams(key types.StoreKey, id types.CommitID
 			return nil, err
 		}
 
+		if iavl.IsFastIndexStore(key.Name()) {
+			if err = store.(*iavl.Store).EnableFastIndex(db); err != nil {
+				return nil, err
+			}
+		}
+
 		if rs.interBlockCache != nil {
 			// Wrap and get a CommitKVStore with inter-block caching. Note, this should
 			// only wrap the primary CommitKVStore, not any store that is already
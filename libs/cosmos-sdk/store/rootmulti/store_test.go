@@ -175,6 +175,35 @@ func TestMultistoreCommitLoad(t *testing.T) {
 	checkStore(t, store, commitID, commitID)
 }
 
+func TestMultistoreRollbackToVersion(t *testing.T) {
+	var db dbm.DB = dbm.NewMemDB()
+	store := newMultiStoreWithMounts(db, types.PruneNothing)
+	require.Nil(t, store.LoadLatestVersion())
+
+	k := []byte("wind")
+	var commitIDs []types.CommitID
+	for i := 0; i < 3; i++ {
+		store.getStoreByName("store1").(types.KVStore).Set(k, []byte{byte(i)})
+		cID, _, _ := store.Commit(&iavltree.TreeDelta{}, nil)
+		commitIDs = append(commitIDs, cID)
+	}
+
+	require.Error(t, store.RollbackToVersion(4))
+	require.Nil(t, store.RollbackToVersion(1))
+	require.Equal(t, commitIDs[0], store.LastCommitID())
+	require.Equal(t, []byte{0}, store.getStoreByName("store1").(types.KVStore).Get(k))
+
+	// the versions above are overwritten by the next commits
+	store.getStoreByName("store1").(types.KVStore).Set(k, []byte{1})
+	cID, _, _ := store.Commit(&iavltree.TreeDelta{}, nil)
+	require.Equal(t, commitIDs[1], cID)
+
+	// the rollback is persisted
+	store = newMultiStoreWithMounts(db, types.PruneNothing)
+	require.Nil(t, store.LoadLatestVersion())
+	require.Equal(t, commitIDs[1], store.LastCommitID())
+}
+
 func TestMultistoreLoadWithUpgrade(t *testing.T) {
 	var db dbm.DB = dbm.NewMemDB()
 	store := newMultiStoreWithMounts(db, types.PruneNothing)
@@ -542,6 +571,61 @@ func TestMultiStore_PruningRestart(t *testing.T) {
 	}
 }
 
+func TestMultiStore_StorePruning(t *testing.T) {
+	db := dbm.NewMemDB()
+	ms := newMultiStoreWithMounts(db, types.PruneNothing)
+	ms.SetStorePruning("store1", types.NewPruningOptions(2, 3, 5, math.MaxInt64))
+	require.NoError(t, ms.LoadLatestVersion())
+
+	for i := int64(0); i < 10; i++ {
+		ms.Commit(&iavltree.TreeDelta{}, nil)
+	}
+
+	store1 := ms.getStoreByName("store1").(*iavl.Store)
+	require.Equal(t, []int{3, 6, 8, 9, 10}, store1.AvailableVersions())
+
+	// the other stores follow the pruning of the root store
+	store2 := ms.getStoreByName("store2").(*iavl.Store)
+	require.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, store2.AvailableVersions())
+}
+
+func TestMultiStore_StorePruningQueries(t *testing.T) {
+	db := dbm.NewMemDB()
+	ms := newMultiStoreWithMounts(db, types.NewPruningOptions(3, 2, 1, math.MaxUint64))
+	ms.SetStorePruning("store1", types.NewPruningOptions(1, 4, 1, math.MaxUint64))
+	require.NoError(t, ms.LoadLatestVersion())
+
+	key := []byte("key")
+	for i := int64(1); i <= 10; i++ {
+		value := []byte(fmt.Sprint(i))
+		ms.GetKVStore(ms.keysByName["store1"]).Set(key, value)
+		ms.GetKVStore(ms.keysByName["store2"]).Set(key, value)
+		ms.Commit(&iavltree.TreeDelta{}, nil)
+	}
+	require.Equal(t, []int{4, 8, 9, 10}, ms.getStoreByName("store1").(*iavl.Store).AvailableVersions())
+	require.Equal(t, []int{2, 4, 6, 7, 8, 9, 10}, ms.getStoreByName("store2").(*iavl.Store).AvailableVersions())
+
+	// the heights kept by the store are kept by the root store, so their whole state is queryable
+	for _, version := range []int64{4, 8, 9, 10} {
+		cms, err := ms.CacheMultiStoreWithVersion(version)
+		require.NoError(t, err)
+		require.Equal(t, []byte(fmt.Sprint(version)), cms.GetKVStore(ms.keysByName["store1"]).Get(key))
+		require.Equal(t, []byte(fmt.Sprint(version)), cms.GetKVStore(ms.keysByName["store2"]).Get(key))
+	}
+	// the heights pruned by the store only are not
+	for _, version := range []int64{2, 6, 7} {
+		cms, err := ms.CacheMultiStoreWithVersion(version)
+		require.NoError(t, err)
+		require.Nil(t, cms.GetKVStore(ms.keysByName["store1"]).Get(key))
+		require.Equal(t, []byte(fmt.Sprint(version)), cms.GetKVStore(ms.keysByName["store2"]).Get(key))
+	}
+
+	// the store can't keep the heights pruned by the root store
+	ms = newMultiStoreWithMounts(dbm.NewMemDB(), types.NewPruningOptions(3, 2, 1, math.MaxUint64))
+	ms.SetStorePruning("store1", types.NewPruningOptions(5, 0, 1, math.MaxUint64))
+	require.Error(t, ms.LoadLatestVersion())
+}
+
 //-----------------------------------------------------------------------
 // utils
 
@@ -0,0 +1,56 @@
+package spankv
+
+import (
+	"time"
+
+	"github.com/okex/exchain/libs/cosmos-sdk/store/types"
+	"github.com/okex/exchain/libs/tendermint/libs/tracing"
+)
+
+var _ types.KVStore = &Store{}
+
+// Store counts the reads of an underlying KVStore into a span of a traced query. The reads of a store are
+// aggregated into a single child span of the query, as a query may read a store thousands of times.
+type Store struct {
+	types.KVStore
+	span *tracing.Span
+}
+
+// NewStore returns a reference to a new span KVStore recording into the child of span named after the store.
+func NewStore(parent types.KVStore, span *tracing.Span, name string) *Store {
+	return &Store{
+		KVStore: parent,
+		span:    span.Aggregate("store " + name),
+	}
+}
+
+// Implements KVStore.
+func (ss *Store) Get(key []byte) []byte {
+	start := time.Now()
+	value := ss.KVStore.Get(key)
+	ss.span.Add("reads", 1)
+	ss.span.Add("read_bytes", int64(len(value)))
+	ss.span.Add("read_time_us", time.Since(start).Microseconds())
+	return value
+}
+
+// Implements KVStore.
+func (ss *Store) Has(key []byte) bool {
+	start := time.Now()
+	ok := ss.KVStore.Has(key)
+	ss.span.Add("reads", 1)
+	ss.span.Add("read_time_us", time.Since(start).Microseconds())
+	return ok
+}
+
+// Implements KVStore.
+func (ss *Store) Iterator(start, end []byte) types.Iterator {
+	ss.span.Add("iterators", 1)
+	return ss.KVStore.Iterator(start, end)
+}
+
+// Implements KVStore.
+func (ss *Store) ReverseIterator(start, end []byte) types.Iterator {
+	ss.span.Add("iterators", 1)
+	return ss.KVStore.ReverseIterator(start, end)
+}
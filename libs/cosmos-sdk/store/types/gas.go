@@ -28,12 +28,19 @@ type ErrorGasOverflow struct {
 	Descriptor string
 }
 
+// ErrorNegativeGasConsumed defines an error thrown when the amount of gas refunded results in a
+// negative gas consumed amount.
+type ErrorNegativeGasConsumed struct {
+	Descriptor string
+}
+
 // GasMeter interface to track gas consumption
 type GasMeter interface {
 	GasConsumed() Gas
 	GasConsumedToLimit() Gas
 	Limit() Gas
 	ConsumeGas(amount Gas, descriptor string)
+	RefundGas(amount Gas, descriptor string)
 	IsPastLimit() bool
 	IsOutOfGas() bool
 }
@@ -90,6 +97,16 @@ func (g *basicGasMeter) ConsumeGas(amount Gas, descriptor string) {
 
 }
 
+// RefundGas will deduct the given amount from the gas consumed. If the amount is greater than the
+// gas consumed, the function will panic.
+func (g *basicGasMeter) RefundGas(amount Gas, descriptor string) {
+	if g.consumed < amount {
+		panic(ErrorNegativeGasConsumed{Descriptor: descriptor})
+	}
+
+	g.consumed -= amount
+}
+
 func (g *basicGasMeter) IsPastLimit() bool {
 	return g.consumed > g.limit
 }
@@ -130,6 +147,16 @@ func (g *infiniteGasMeter) ConsumeGas(amount Gas, descriptor string) {
 	}
 }
 
+// RefundGas will deduct the given amount from the gas consumed. If the amount is greater than the
+// gas consumed, the function will panic.
+func (g *infiniteGasMeter) RefundGas(amount Gas, descriptor string) {
+	if g.consumed < amount {
+		panic(ErrorNegativeGasConsumed{Descriptor: descriptor})
+	}
+
+	g.consumed -= amount
+}
+
 func (g *infiniteGasMeter) IsPastLimit() bool {
 	return false
 }
@@ -45,6 +45,16 @@ func TestGasMeter(t *testing.T) {
 	}
 }
 
+func TestGasMeterRefund(t *testing.T) {
+	for _, meter := range []GasMeter{NewGasMeter(100), NewInfiniteGasMeter()} {
+		meter.ConsumeGas(60, "")
+		require.NotPanics(t, func() { meter.RefundGas(20, "") })
+		require.Equal(t, uint64(40), meter.GasConsumed())
+		require.Panics(t, func() { meter.RefundGas(41, "") })
+		require.Equal(t, uint64(40), meter.GasConsumed())
+	}
+}
+
 func TestAddUint64Overflow(t *testing.T) {
 	testCases := []struct {
 		a, b     uint64
@@ -1,6 +1,9 @@
 package types
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+)
 
 // Pruning option string constants
 const (
@@ -70,6 +73,32 @@ func (po PruningOptions) Validate() error {
 	return nil
 }
 
+// ValidateWithin checks the pruning strategy of a sub-store keeps none of the heights pruned by the strategy of
+// its root store: the state at a height is only served with all the sub-stores, so a height kept by the sub-store
+// alone can't be queried. The snapshot heights of KeepEvery are only kept if the root store keeps them all.
+func (po PruningOptions) ValidateWithin(root PruningOptions) error {
+	if root.KeepEvery == 1 {
+		// the root store keeps every height
+		return nil
+	}
+	if po.KeepEvery == 1 {
+		return fmt.Errorf("invalid 'KeepEvery' when the root store prunes: %d", po.KeepEvery)
+	}
+	if po.KeepRecent > root.KeepRecent {
+		return fmt.Errorf("invalid 'KeepRecent' beyond the one of the root store %d: %d", root.KeepRecent, po.KeepRecent)
+	}
+	if po.KeepEvery == 0 {
+		return nil
+	}
+	if root.KeepEvery == 0 || po.KeepEvery%root.KeepEvery != 0 {
+		return fmt.Errorf("invalid 'KeepEvery' not a multiple of the one of the root store %d: %d", root.KeepEvery, po.KeepEvery)
+	}
+	if root.MaxRetainNum != math.MaxUint64 {
+		return fmt.Errorf("invalid 'KeepEvery' when the root store keeps at most %d heights: %d", root.MaxRetainNum, po.KeepEvery)
+	}
+	return nil
+}
+
 func NewPruningOptionsFromString(strategy string) PruningOptions {
 	switch strategy {
 	case PruningOptionEverything:
@@ -28,3 +28,29 @@ func TestPruningOptions_Validate(t *testing.T) {
 		require.Equal(t, tc.expectErr, err != nil, "options: %v, err: %s", po, err)
 	}
 }
+
+func TestPruningOptions_ValidateWithin(t *testing.T) {
+	root := NewPruningOptions(100, 1000, 10, math.MaxUint64)
+	testCases := []struct {
+		root      PruningOptions
+		store     PruningOptions
+		expectErr bool
+	}{
+		{PruneNothing, NewPruningOptions(1000, 7, 10, math.MaxUint64), false},
+		{root, NewPruningOptions(100, 0, 10, math.MaxUint64), false},
+		{root, NewPruningOptions(10, 3000, 100, math.MaxUint64), false},
+		{root, PruneNothing, true},
+		{root, NewPruningOptions(101, 0, 10, math.MaxUint64), true},
+		{root, NewPruningOptions(10, 1500, 10, math.MaxUint64), true},
+		{PruneEverything, NewPruningOptions(5, 0, 10, math.MaxUint64), false},
+		{PruneEverything, NewPruningOptions(5, 10, 10, math.MaxUint64), true},
+		// the snapshots of the root store are dropped past its max retain num
+		{PruneDefault, NewPruningOptions(10, 0, 10, math.MaxUint64), false},
+		{PruneDefault, NewPruningOptions(10, 10000, 10, math.MaxUint64), true},
+	}
+
+	for _, tc := range testCases {
+		err := tc.store.ValidateWithin(tc.root)
+		require.Equal(t, tc.expectErr, err != nil, "root: %v, store: %v, err: %s", tc.root, tc.store, err)
+	}
+}
@@ -177,10 +177,16 @@ type CommitMultiStore interface {
 	LoadVersion(ver int64) error
 	GetCommitVersion() (int64, error)
 
+	// RollbackToVersion deletes the versions above the given one and loads it as the latest version.
+	RollbackToVersion(ver int64) error
+
 	// Set an inter-block (persistent) cache that maintains a mapping from
 	// StoreKeys to CommitKVStores.
 	SetInterBlockCache(MultiStorePersistentCache)
 
+	// SetStorePruning sets the pruning strategy of the named sub-store, overriding the one of the root store.
+	SetStorePruning(name string, opts PruningOptions)
+
 	StopStore()
 
 	SetLogger(log log.Logger)
@@ -7,8 +7,10 @@ import (
 	"github.com/gogo/protobuf/proto"
 	abci "github.com/okex/exchain/libs/tendermint/abci/types"
 	"github.com/okex/exchain/libs/tendermint/libs/log"
+	"github.com/okex/exchain/libs/tendermint/libs/tracing"
 
 	"github.com/okex/exchain/libs/cosmos-sdk/store/gaskv"
+	"github.com/okex/exchain/libs/cosmos-sdk/store/spankv"
 	stypes "github.com/okex/exchain/libs/cosmos-sdk/store/types"
 )
 
@@ -234,7 +236,12 @@ func (c Context) Value(key interface{}) interface{} {
 
 // KVStore fetches a KVStore from the MultiStore.
 func (c Context) KVStore(key StoreKey) KVStore {
-	return gaskv.NewStore(c.MultiStore().GetKVStore(key), c.GasMeter(), stypes.KVGasConfig())
+	store := gaskv.NewStore(c.MultiStore().GetKVStore(key), c.GasMeter(), stypes.KVGasConfig())
+	// the reads of a traced query are recorded into its span
+	if span := tracing.SpanFromContext(c.ctx); span != nil {
+		return spankv.NewStore(store, span, key.Name())
+	}
+	return store
 }
 
 // TransientStore fetches a TransientStore from the MultiStore.
@@ -262,3 +269,22 @@ func (c Context) WithSigCache(cache SigCache) Context {
 func EmptyContext() Context {
 	return Context{}
 }
+
+type sdkContextKeyType string
+
+// SdkContextKey is the key of the Context carried by the context.Context passed to the grpc query services
+const SdkContextKey sdkContextKeyType = "sdk-context"
+
+// WrapSDKContext returns a context.Context carrying ctx, to be passed to a grpc query service
+func WrapSDKContext(ctx Context) context.Context {
+	parent := ctx.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	return context.WithValue(parent, SdkContextKey, ctx)
+}
+
+// UnwrapSDKContext returns the Context carried by a context.Context out of WrapSDKContext
+func UnwrapSDKContext(ctx context.Context) Context {
+	return ctx.Value(SdkContextKey).(Context)
+}
@@ -15,8 +15,20 @@ type UpdateFeeCollectorAccHandler func(ctx Context, balance Coins) error
 
 type LogFix func(isAnteFailed [][]string) (logs [][]byte)
 
+// BlockGasLimitHandler returns the gas limit of the block set by the application, 0 for none. The block gas
+// meter is limited by the lowest of it and of the max gas of the consensus params.
+type BlockGasLimitHandler func(ctx Context) uint64
+
 type GetTxFeeHandler func(ctx Context, tx Tx) (Coins, bool, SigCache)
 
+type PreDeliverTxHandler func(height int64, tx Tx)
+
+type PreCheckTxHandler func(height int64, tx Tx) error
+
+// PrefetchTxHandler warms the state read by a tx of a block concurrently with the delivery of the txs, ctx
+// reads the last committed state
+type PrefetchTxHandler func(ctx Context, tx Tx)
+
 // AnteDecorator wraps the next AnteHandler to perform custom pre- and post-processing.
 type AnteDecorator interface {
 	AnteHandle(ctx Context, tx Tx, simulate bool, next AnteHandler) (newCtx Context, err error)
@@ -38,6 +38,16 @@ func init() {
 	initVersionBlockHeight()
 }
 
+// GetMilestoneMercuryHeight returns the height of the mercury milestone, 0 if it isn't enabled
+func GetMilestoneMercuryHeight() int64 {
+	return milestoneMercuryHeight
+}
+
+// UnittestOnlySetMilestoneMercuryHeight sets the height of the mercury milestone, for the tests only
+func UnittestOnlySetMilestoneMercuryHeight(height int64) {
+	milestoneMercuryHeight = height
+}
+
 //depracate homstead signer support
 func HigherThanMercury(height int64) bool {
 	if milestoneMercuryHeight == 0 {
@@ -157,8 +157,9 @@ func NewGasMeter(limit Gas) GasMeter {
 
 // nolint - reexport
 type (
-	ErrorOutOfGas    = types.ErrorOutOfGas
-	ErrorGasOverflow = types.ErrorGasOverflow
+	ErrorOutOfGas            = types.ErrorOutOfGas
+	ErrorNegativeGasConsumed = types.ErrorNegativeGasConsumed
+	ErrorGasOverflow         = types.ErrorGasOverflow
 )
 
 // nolint - reexport
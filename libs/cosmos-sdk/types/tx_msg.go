@@ -51,10 +51,18 @@ type Tx interface {
 	GetTxFnSignatureInfo() ([]byte, int)
 }
 
+// SenderRecoverer is implemented by the txs whose sender is recovered from their signature alone, like the evm txs.
+// Their sender is verified even when they fail their checks.
+type SenderRecoverer interface {
+	// RecoverSender returns the sender recovered from the signature of the tx, empty if the signature is invalid
+	RecoverSender(ctx Context) string
+}
+
 //__________________________________________________________
 
-// TxDecoder unmarshals transaction bytes
-type TxDecoder func(txBytes []byte) (Tx, error)
+// TxDecoder unmarshals transaction bytes. The height is the one of the block the tx is in, the encodings enabled by
+// a milestone are only decoded past it. Without the height the tx is decoded with the rules of the latest height.
+type TxDecoder func(txBytes []byte, height ...int64) (Tx, error)
 
 // TxEncoder marshals transaction to bytes
 type TxEncoder func(tx Tx) ([]byte, error)
@@ -11,7 +11,7 @@ type IsSystemFreeHandler func(ctx sdk.Context, msgs []sdk.Msg) bool
 
 
 type ObserverI interface {
-	OnAccountUpdated(acc exported.Account)
+	OnAccountUpdated(ctx sdk.Context, acc exported.Account)
 }
 
 func (k *AccountKeeper) SetObserverKeeper(observer ObserverI) {
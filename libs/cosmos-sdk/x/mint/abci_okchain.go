@@ -19,23 +19,56 @@ func beginBlocker(ctx sdk.Context, k Keeper) {
 	// fetch stored minter & params
 	params := k.GetParams(ctx)
 	minter := k.GetMinterCustom(ctx)
-	if ctx.BlockHeight() == 0 || uint64(ctx.BlockHeight()) >= minter.NextBlockToUpdate {
+	if len(params.InflationSchedule) > 0 {
+		k.UpdateMinterWithSchedule(ctx, &minter, params)
+	} else if ctx.BlockHeight() == 0 || uint64(ctx.BlockHeight()) >= minter.NextBlockToUpdate {
 		k.UpdateMinterCustom(ctx, &minter, params)
 	}
+	k.RecordMintedPerBlock(ctx, minter.MintedPerBlock)
 
-	if minter.MintedPerBlock.AmountOf(params.MintDenom).LTE(sdk.ZeroDec()) {
+	// the fees burned in place of minting are already in the mint module account
+	minted := sdk.MaxDec(minter.MintedPerBlock.AmountOf(params.MintDenom), sdk.ZeroDec())
+	burned, offset, err := k.BurnCollectedFees(ctx, params, minted)
+	if err != nil {
+		panic(err)
+	}
+	if burned.IsPositive() {
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				types.EventTypeFeeBurn,
+				sdk.NewAttribute(sdk.AttributeKeyAmount, sdk.NewDecCoinFromDec(params.MintDenom, burned).String()),
+				sdk.NewAttribute(types.AttributeKeyNetProvisions, minted.Sub(burned).String()),
+			),
+		)
+	}
+
+	if !minted.IsPositive() {
 		logger.Debug(fmt.Sprintf("No more <%v> to mint", params.MintDenom))
 		return
 	}
 
-	err := k.MintCoins(ctx, minter.MintedPerBlock)
+	toMint := minter.MintedPerBlock
+	if offset.IsPositive() {
+		toMint = toMint.Sub(sdk.NewDecCoinsFromDec(params.MintDenom, offset))
+	}
+	err = k.MintCoins(ctx, toMint)
+	if err != nil {
+		panic(err)
+	}
+
+	// send the proportions of the treasuries first, the rest is split between fee collection and farming
+	treasurySplits, err := k.SendToTreasuries(ctx, minter.MintedPerBlock, params.Treasuries)
 	if err != nil {
 		panic(err)
 	}
+	remaining := minter.MintedPerBlock
+	for _, split := range treasurySplits {
+		remaining = remaining.Sub(split.Amount)
+	}
 
-	farmingAmount := minter.MintedPerBlock.MulDecTruncate(params.FarmProportion)
+	farmingAmount := remaining.MulDecTruncate(params.FarmProportion)
 	// send the minted coins to the fee collector account
-	err = k.AddCollectedFees(ctx, minter.MintedPerBlock.Sub(farmingAmount))
+	err = k.AddCollectedFees(ctx, remaining.Sub(farmingAmount))
 	if err != nil {
 		panic(err)
 	}
@@ -45,6 +78,15 @@ func beginBlocker(ctx sdk.Context, k Keeper) {
 		panic(err)
 	}
 
+	if len(params.Treasuries) > 0 {
+		k.RecordMintSplit(ctx, types.MintSplit{
+			Height:       uint64(ctx.BlockHeight()),
+			Treasuries:   treasurySplits,
+			FeeCollector: remaining.Sub(farmingAmount),
+			YieldFarming: farmingAmount,
+		})
+	}
+
 	logger.Debug(fmt.Sprintf(
 		"total supply <%v>, "+
 			"\nparams <%v>, "+
@@ -55,7 +97,7 @@ func beginBlocker(ctx sdk.Context, k Keeper) {
 		sdk.NewDecCoinFromDec(params.MintDenom, k.StakingTokenSupply(ctx)),
 		params,
 		minter.MintedPerBlock,
-		minter.MintedPerBlock.Sub(farmingAmount),
+		remaining.Sub(farmingAmount),
 		farmingAmount,
 		minter.NextBlockToUpdate))
 
@@ -66,6 +108,15 @@ func beginBlocker(ctx sdk.Context, k Keeper) {
 			sdk.NewAttribute(sdk.AttributeKeyAmount, minter.MintedPerBlock.String()),
 		),
 	)
+	for _, split := range treasurySplits {
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				types.EventTypeTreasury,
+				sdk.NewAttribute(types.AttributeKeyTreasury, split.Name),
+				sdk.NewAttribute(sdk.AttributeKeyAmount, split.Amount.String()),
+			),
+		)
+	}
 }
 
 // BeginBlocker mints new tokens for the previous block.
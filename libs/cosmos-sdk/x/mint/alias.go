@@ -15,6 +15,18 @@ const (
 	QueryParameters       = types.QueryParameters
 	QueryInflation        = types.QueryInflation
 	QueryAnnualProvisions = types.QueryAnnualProvisions
+
+	QueryCurrentPhase        = types.QueryCurrentPhase
+	QueryProjectedProvisions = types.QueryProjectedProvisions
+	QuerySplitHistory        = types.QuerySplitHistory
+	QueryBurnedFees          = types.QueryBurnedFees
+	QueryProvisions          = types.QueryProvisions
+	QueryEpochProvisions     = types.QueryEpochProvisions
+	QuerySupplyProjection    = types.QuerySupplyProjection
+
+	CommunityPoolTreasury = types.CommunityPoolTreasury
+	EcosystemFundName     = types.EcosystemFundName
+	FeeBurnerName         = types.FeeBurnerName
 )
 
 var (
@@ -41,6 +53,9 @@ var (
 	//KeyInflationMin        = types.KeyInflationMin
 	//KeyGoalBonded          = types.KeyGoalBonded
 	KeyBlocksPerYear       = types.KeyBlocksPerYear
+	KeyInflationSchedule   = types.KeyInflationSchedule
+	KeyTreasuries          = types.KeyTreasuries
+	KeyFeeBurnProportion   = types.KeyFeeBurnProportion
 )
 
 type (
@@ -48,4 +63,12 @@ type (
 	GenesisState = types.GenesisState
 	Minter       = types.Minter
 	Params       = types.Params
+
+	InflationPhase    = types.InflationPhase
+	InflationSchedule = types.InflationSchedule
+	Treasury          = types.Treasury
+	Treasuries        = types.Treasuries
+	MintSplit         = types.MintSplit
+	Provisions        = types.Provisions
+	SupplyProjection  = types.SupplyProjection
 )
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/spf13/cobra"
 
@@ -28,6 +29,13 @@ func GetQueryCmd(cdc *codec.Codec) *cobra.Command {
 			GetCmdQueryParams(cdc),
 			GetCmdQueryInflation(cdc),
 			GetCmdQueryAnnualProvisions(cdc),
+			GetCmdQueryCurrentPhase(cdc),
+			GetCmdQueryProjectedProvisions(cdc),
+			GetCmdQuerySplitHistory(cdc),
+			GetCmdQueryBurnedFees(cdc),
+			GetCmdQueryProvisions(cdc),
+			GetCmdQueryEpochProvisions(cdc),
+			GetCmdQuerySupplyProjection(cdc),
 		)...,
 	)
 
@@ -111,3 +119,222 @@ func GetCmdQueryAnnualProvisions(cdc *codec.Codec) *cobra.Command {
 		},
 	}
 }
+
+// GetCmdQueryCurrentPhase implements a command to return the current phase of
+// the inflation schedule and the amount minted per block.
+func GetCmdQueryCurrentPhase(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "current-phase",
+		Short: "Query the current phase of the inflation schedule and the amount minted per block",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			route := fmt.Sprintf("custom/%s/%s", types.QuerierRoute, types.QueryCurrentPhase)
+			res, _, err := cliCtx.QueryWithData(route, nil)
+			if err != nil {
+				return err
+			}
+
+			var phase types.CurrentPhase
+			if err := cdc.UnmarshalJSON(res, &phase); err != nil {
+				return err
+			}
+
+			return cliCtx.PrintOutput(phase)
+		},
+	}
+}
+
+// GetCmdQueryProjectedProvisions implements a command to return the provisions
+// projected for the next blocks under the current minting parameters.
+func GetCmdQueryProjectedProvisions(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "projected-provisions [blocks]",
+		Short: "Query the provisions projected for the next blocks, a year of blocks by default",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			var blocks uint64
+			if len(args) == 1 {
+				var err error
+				if blocks, err = strconv.ParseUint(args[0], 10, 64); err != nil {
+					return fmt.Errorf("invalid number of blocks %s: %w", args[0], err)
+				}
+			}
+			bz, err := cdc.MarshalJSON(types.NewQueryProjectedProvisionsParams(blocks))
+			if err != nil {
+				return err
+			}
+
+			route := fmt.Sprintf("custom/%s/%s", types.QuerierRoute, types.QueryProjectedProvisions)
+			res, _, err := cliCtx.QueryWithData(route, bz)
+			if err != nil {
+				return err
+			}
+
+			var provisions types.ProjectedProvisions
+			if err := cdc.UnmarshalJSON(res, &provisions); err != nil {
+				return err
+			}
+
+			return cliCtx.PrintOutput(provisions)
+		},
+	}
+}
+
+// GetCmdQuerySplitHistory implements a command to return the history of the
+// splits of the provisions between the treasuries, fee collection and farming.
+func GetCmdQuerySplitHistory(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "split-history",
+		Short: "Query the history of the splits of the provisions between the treasuries, fee collection and farming",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			route := fmt.Sprintf("custom/%s/%s", types.QuerierRoute, types.QuerySplitHistory)
+			res, _, err := cliCtx.QueryWithData(route, nil)
+			if err != nil {
+				return err
+			}
+
+			var splits []types.MintSplit
+			if err := cdc.UnmarshalJSON(res, &splits); err != nil {
+				return err
+			}
+
+			return cliCtx.PrintOutput(splits)
+		},
+	}
+}
+
+// GetCmdQueryBurnedFees implements a command to return the cumulative fees
+// burned.
+func GetCmdQueryBurnedFees(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "burned-fees",
+		Short: "Query the cumulative gas fees burned",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			route := fmt.Sprintf("custom/%s/%s", types.QuerierRoute, types.QueryBurnedFees)
+			res, _, err := cliCtx.QueryWithData(route, nil)
+			if err != nil {
+				return err
+			}
+
+			var burned sdk.DecCoins
+			if err := cdc.UnmarshalJSON(res, &burned); err != nil {
+				return err
+			}
+
+			return cliCtx.PrintOutput(burned)
+		},
+	}
+}
+
+// GetCmdQueryProvisions implements a command to return the amount minted by
+// a range of blocks.
+func GetCmdQueryProvisions(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "provisions [from-height] [to-height]",
+		Short: "Query the amount minted by the blocks from from-height up to to-height excluded",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			from, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid from height %s: %w", args[0], err)
+			}
+			to, err := strconv.ParseUint(args[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid to height %s: %w", args[1], err)
+			}
+			bz, err := cdc.MarshalJSON(types.NewQueryProvisionsParams(from, to))
+			if err != nil {
+				return err
+			}
+
+			route := fmt.Sprintf("custom/%s/%s", types.QuerierRoute, types.QueryProvisions)
+			return queryProvisions(cliCtx, cdc, route, bz)
+		},
+	}
+}
+
+// GetCmdQueryEpochProvisions implements a command to return the amount minted
+// during a deflation epoch.
+func GetCmdQueryEpochProvisions(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "epoch-provisions [epoch]",
+		Short: "Query the amount minted during a deflation epoch, epoch 0 starting at genesis",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			epoch, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid epoch %s: %w", args[0], err)
+			}
+			bz, err := cdc.MarshalJSON(types.NewQueryEpochProvisionsParams(epoch))
+			if err != nil {
+				return err
+			}
+
+			route := fmt.Sprintf("custom/%s/%s", types.QuerierRoute, types.QueryEpochProvisions)
+			return queryProvisions(cliCtx, cdc, route, bz)
+		},
+	}
+}
+
+func queryProvisions(cliCtx context.CLIContext, cdc *codec.Codec, route string, bz []byte) error {
+	res, _, err := cliCtx.QueryWithData(route, bz)
+	if err != nil {
+		return err
+	}
+
+	var provisions types.Provisions
+	if err := cdc.UnmarshalJSON(res, &provisions); err != nil {
+		return err
+	}
+
+	return cliCtx.PrintOutput(provisions)
+}
+
+// GetCmdQuerySupplyProjection implements a command to return the supply
+// expected at a future height.
+func GetCmdQuerySupplyProjection(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "supply-projection [height]",
+		Short: "Query the supply expected at a future height under the current params",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			height, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid height %s: %w", args[0], err)
+			}
+			bz, err := cdc.MarshalJSON(types.NewQuerySupplyProjectionParams(height))
+			if err != nil {
+				return err
+			}
+
+			route := fmt.Sprintf("custom/%s/%s", types.QuerierRoute, types.QuerySupplyProjection)
+			res, _, err := cliCtx.QueryWithData(route, bz)
+			if err != nil {
+				return err
+			}
+
+			var projection types.SupplyProjection
+			if err := cdc.UnmarshalJSON(res, &projection); err != nil {
+				return err
+			}
+
+			return cliCtx.PrintOutput(projection)
+		},
+	}
+}
@@ -3,6 +3,7 @@ package rest
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/gorilla/mux"
 
@@ -26,6 +27,41 @@ func registerQueryRoutes(cliCtx context.CLIContext, r *mux.Router) {
 		"/minting/annual-provisions",
 		queryAnnualProvisionsHandlerFn(cliCtx),
 	).Methods("GET")
+
+	r.HandleFunc(
+		"/minting/current-phase",
+		queryCurrentPhaseHandlerFn(cliCtx),
+	).Methods("GET")
+
+	r.HandleFunc(
+		"/minting/projected-provisions",
+		queryProjectedProvisionsHandlerFn(cliCtx),
+	).Methods("GET")
+
+	r.HandleFunc(
+		"/minting/split-history",
+		querySplitHistoryHandlerFn(cliCtx),
+	).Methods("GET")
+
+	r.HandleFunc(
+		"/minting/burned-fees",
+		queryBurnedFeesHandlerFn(cliCtx),
+	).Methods("GET")
+
+	r.HandleFunc(
+		"/minting/provisions",
+		queryProvisionsHandlerFn(cliCtx),
+	).Methods("GET")
+
+	r.HandleFunc(
+		"/minting/epoch-provisions/{epoch}",
+		queryEpochProvisionsHandlerFn(cliCtx),
+	).Methods("GET")
+
+	r.HandleFunc(
+		"/minting/supply-projection/{height}",
+		querySupplyProjectionHandlerFn(cliCtx),
+	).Methods("GET")
 }
 
 func queryParamsHandlerFn(cliCtx context.CLIContext) http.HandlerFunc {
@@ -87,3 +123,167 @@ func queryAnnualProvisionsHandlerFn(cliCtx context.CLIContext) http.HandlerFunc
 		rest.PostProcessResponse(w, cliCtx, res)
 	}
 }
+
+func queryCurrentPhaseHandlerFn(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		route := fmt.Sprintf("custom/%s/%s", types.QuerierRoute, types.QueryCurrentPhase)
+
+		cliCtx, ok := rest.ParseQueryHeightOrReturnBadRequest(w, cliCtx, r)
+		if !ok {
+			return
+		}
+
+		res, height, err := cliCtx.QueryWithData(route, nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		cliCtx = cliCtx.WithHeight(height)
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func queryProjectedProvisionsHandlerFn(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		route := fmt.Sprintf("custom/%s/%s", types.QuerierRoute, types.QueryProjectedProvisions)
+
+		var blocks uint64
+		if blocksStr := r.URL.Query().Get("blocks"); blocksStr != "" {
+			var err error
+			if blocks, err = strconv.ParseUint(blocksStr, 10, 64); err != nil {
+				rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+
+		cliCtx, ok := rest.ParseQueryHeightOrReturnBadRequest(w, cliCtx, r)
+		if !ok {
+			return
+		}
+
+		bz, err := cliCtx.Codec.MarshalJSON(types.NewQueryProjectedProvisionsParams(blocks))
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		res, height, err := cliCtx.QueryWithData(route, bz)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		cliCtx = cliCtx.WithHeight(height)
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func querySplitHistoryHandlerFn(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		route := fmt.Sprintf("custom/%s/%s", types.QuerierRoute, types.QuerySplitHistory)
+
+		cliCtx, ok := rest.ParseQueryHeightOrReturnBadRequest(w, cliCtx, r)
+		if !ok {
+			return
+		}
+
+		res, height, err := cliCtx.QueryWithData(route, nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		cliCtx = cliCtx.WithHeight(height)
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func queryBurnedFeesHandlerFn(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		route := fmt.Sprintf("custom/%s/%s", types.QuerierRoute, types.QueryBurnedFees)
+
+		cliCtx, ok := rest.ParseQueryHeightOrReturnBadRequest(w, cliCtx, r)
+		if !ok {
+			return
+		}
+
+		res, height, err := cliCtx.QueryWithData(route, nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		cliCtx = cliCtx.WithHeight(height)
+		rest.PostProcessResponse(w, cliCtx, res)
+	}
+}
+
+func queryProvisionsHandlerFn(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		route := fmt.Sprintf("custom/%s/%s", types.QuerierRoute, types.QueryProvisions)
+
+		from, err := strconv.ParseUint(r.URL.Query().Get("from_height"), 10, 64)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("invalid from_height: %s", err))
+			return
+		}
+		to, err := strconv.ParseUint(r.URL.Query().Get("to_height"), 10, 64)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("invalid to_height: %s", err))
+			return
+		}
+
+		queryWithParams(w, r, cliCtx, route, types.NewQueryProvisionsParams(from, to))
+	}
+}
+
+func queryEpochProvisionsHandlerFn(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		route := fmt.Sprintf("custom/%s/%s", types.QuerierRoute, types.QueryEpochProvisions)
+
+		epoch, err := strconv.ParseUint(mux.Vars(r)["epoch"], 10, 64)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		queryWithParams(w, r, cliCtx, route, types.NewQueryEpochProvisionsParams(epoch))
+	}
+}
+
+func querySupplyProjectionHandlerFn(cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		route := fmt.Sprintf("custom/%s/%s", types.QuerierRoute, types.QuerySupplyProjection)
+
+		height, err := strconv.ParseUint(mux.Vars(r)["height"], 10, 64)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		queryWithParams(w, r, cliCtx, route, types.NewQuerySupplyProjectionParams(height))
+	}
+}
+
+func queryWithParams(w http.ResponseWriter, r *http.Request, cliCtx context.CLIContext, route string, params interface{}) {
+	cliCtx, ok := rest.ParseQueryHeightOrReturnBadRequest(w, cliCtx, r)
+	if !ok {
+		return
+	}
+
+	bz, err := cliCtx.Codec.MarshalJSON(params)
+	if err != nil {
+		rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, height, err := cliCtx.QueryWithData(route, bz)
+	if err != nil {
+		rest.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	cliCtx = cliCtx.WithHeight(height)
+	rest.PostProcessResponse(w, cliCtx, res)
+}
@@ -1,6 +1,7 @@
 package keeper
 
 import (
+	"bytes"
 	"fmt"
 
 	"github.com/okex/exchain/libs/tendermint/libs/log"
@@ -22,6 +23,8 @@ type Keeper struct {
 
 	farmModuleName     string
 	originalMintedPerBlock sdk.Dec
+
+	distrKeeper types.DistributionKeeper
 }
 
 // NewKeeper creates a new mint Keeper instance
@@ -38,7 +41,7 @@ func NewKeeper(
 	return Keeper{
 		cdc:              cdc,
 		storeKey:         key,
-		paramSpace:       paramSpace.WithKeyTable(types.ParamKeyTable()),
+		paramSpace:       paramSpace.WithKeyTable(types.ParamKeyTableWithModuleAccounts(isModuleAccount(supplyKeeper))),
 		sk:               sk,
 		supplyKeeper:     supplyKeeper,
 		feeCollectorName: feeCollectorName,
@@ -77,15 +80,41 @@ func (k Keeper) SetMinter(ctx sdk.Context, minter types.MinterCustom) {
 
 // GetParams returns the total set of minting parameters.
 func (k Keeper) GetParams(ctx sdk.Context) (params types.Params) {
-	k.paramSpace.GetParamSet(ctx, &params)
+	for _, pair := range params.ParamSetPairs() {
+		if isParamAddedAfterLaunch(pair.Key) {
+			k.paramSpace.GetIfExists(ctx, pair.Key, pair.Value)
+			continue
+		}
+		k.paramSpace.Get(ctx, pair.Key, pair.Value)
+	}
+	if params.FeeBurnProportion.IsNil() {
+		params.FeeBurnProportion = sdk.ZeroDec()
+	}
 	return params
 }
 
+// isParamAddedAfterLaunch returns whether the param may be missing on the running chains, until a proposal
+// sets it
+func isParamAddedAfterLaunch(key []byte) bool {
+	return bytes.Equal(key, types.KeyInflationSchedule) || bytes.Equal(key, types.KeyTreasuries) ||
+		bytes.Equal(key, types.KeyFeeBurnProportion)
+}
+
 // SetParams sets the total set of minting parameters.
 func (k Keeper) SetParams(ctx sdk.Context, params types.Params) {
+	if err := params.Treasuries.ValidateModuleAccounts(isModuleAccount(k.supplyKeeper)); err != nil {
+		panic(err)
+	}
 	k.paramSpace.SetParamSet(ctx, &params)
 }
 
+// isModuleAccount returns the check of the names of the module accounts of the supply keeper
+func isModuleAccount(supplyKeeper types.SupplyKeeper) func(name string) bool {
+	return func(name string) bool {
+		return supplyKeeper.GetModuleAddress(name) != nil
+	}
+}
+
 //______________________________________________________________________
 
 // StakingTokenSupply implements an alias call to the underlying staking keeper's
@@ -1,10 +1,195 @@
 package keeper
 
 import (
+	"bytes"
+	"fmt"
+
 	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
 	"github.com/okex/exchain/libs/cosmos-sdk/x/mint/internal/types"
 )
 
+// SetDistributionKeeper sets the keeper funding the community pool treasury
+func (k *Keeper) SetDistributionKeeper(distrKeeper types.DistributionKeeper) {
+	k.distrKeeper = distrKeeper
+}
+
+// SendToTreasuries sends the proportions of the minted coins of the treasuries, and returns the amounts
+// sent. The treasuries without a module account are skipped, their proportions stay in the fee collection.
+func (k Keeper) SendToTreasuries(ctx sdk.Context, minted sdk.Coins, treasuries types.Treasuries) ([]types.TreasurySplit, error) {
+	var splits []types.TreasurySplit
+	for _, treasury := range treasuries {
+		amount := minted.MulDecTruncate(treasury.Proportion)
+		if amount.IsZero() {
+			continue
+		}
+
+		switch {
+		case treasury.Name == types.CommunityPoolTreasury:
+			if k.distrKeeper == nil {
+				k.Logger(ctx).Error("no distribution keeper to fund the community pool treasury")
+				continue
+			}
+			if err := k.distrKeeper.FundCommunityPoolFromModule(ctx, amount, types.ModuleName); err != nil {
+				return nil, err
+			}
+		case k.supplyKeeper.GetModuleAddress(treasury.Name) == nil:
+			k.Logger(ctx).Error(fmt.Sprintf("treasury %s is not a module account", treasury.Name))
+			continue
+		default:
+			if err := k.supplyKeeper.SendCoinsFromModuleToModule(ctx, types.ModuleName, treasury.Name, amount); err != nil {
+				return nil, err
+			}
+		}
+		splits = append(splits, types.TreasurySplit{Name: treasury.Name, Amount: amount})
+	}
+	return splits, nil
+}
+
+// RecordMintSplit stores the split of the provisions of the current block when it differs from the last
+// recorded one
+func (k Keeper) RecordMintSplit(ctx sdk.Context, split types.MintSplit) {
+	store := ctx.KVStore(k.storeKey)
+	it := sdk.KVStoreReversePrefixIterator(store, types.MintSplitKeyPrefix)
+	if it.Valid() {
+		var last types.MintSplit
+		k.cdc.MustUnmarshalBinaryLengthPrefixed(it.Value(), &last)
+		last.Height = split.Height
+		if bytes.Equal(k.cdc.MustMarshalBinaryLengthPrefixed(last), k.cdc.MustMarshalBinaryLengthPrefixed(split)) {
+			it.Close()
+			return
+		}
+	}
+	it.Close()
+
+	store.Set(types.GetMintSplitKey(split.Height), k.cdc.MustMarshalBinaryLengthPrefixed(split))
+}
+
+// GetMintSplits returns the recorded splits of the provisions, by ascending height
+func (k Keeper) GetMintSplits(ctx sdk.Context) (splits []types.MintSplit) {
+	it := sdk.KVStorePrefixIterator(ctx.KVStore(k.storeKey), types.MintSplitKeyPrefix)
+	defer it.Close()
+	for ; it.Valid(); it.Next() {
+		var split types.MintSplit
+		k.cdc.MustUnmarshalBinaryLengthPrefixed(it.Value(), &split)
+		splits = append(splits, split)
+	}
+	return
+}
+
+// RecordMintedPerBlock stores the amount minted per block from the current block on, when it differs from
+// the last recorded one
+func (k Keeper) RecordMintedPerBlock(ctx sdk.Context, amount sdk.DecCoins) {
+	store := ctx.KVStore(k.storeKey)
+	record := types.MintedPerBlock{Height: uint64(ctx.BlockHeight()), Amount: amount}
+	it := sdk.KVStoreReversePrefixIterator(store, types.MintedPerBlockKeyPrefix)
+	if it.Valid() {
+		var last types.MintedPerBlock
+		k.cdc.MustUnmarshalBinaryLengthPrefixed(it.Value(), &last)
+		last.Height = record.Height
+		if bytes.Equal(k.cdc.MustMarshalBinaryLengthPrefixed(last), k.cdc.MustMarshalBinaryLengthPrefixed(record)) {
+			it.Close()
+			return
+		}
+	}
+	it.Close()
+
+	store.Set(types.GetMintedPerBlockKey(record.Height), k.cdc.MustMarshalBinaryLengthPrefixed(record))
+}
+
+// GetMintedPerBlockRecords returns the recorded amounts minted per block, by ascending height
+func (k Keeper) GetMintedPerBlockRecords(ctx sdk.Context) (records []types.MintedPerBlock) {
+	it := sdk.KVStorePrefixIterator(ctx.KVStore(k.storeKey), types.MintedPerBlockKeyPrefix)
+	defer it.Close()
+	for ; it.Valid(); it.Next() {
+		var record types.MintedPerBlock
+		k.cdc.MustUnmarshalBinaryLengthPrefixed(it.Value(), &record)
+		records = append(records, record)
+	}
+	return
+}
+
+// HistoricalProvisions returns the amount minted by the blocks in [from, to) up to the current block, and the
+// height the minted amounts are recorded from. The blocks before that height are not counted.
+func (k Keeper) HistoricalProvisions(ctx sdk.Context, from, to uint64) (provisions sdk.DecCoins, recordedFrom uint64) {
+	if current := uint64(ctx.BlockHeight()) + 1; to > current {
+		to = current
+	}
+
+	records := k.GetMintedPerBlockRecords(ctx)
+	if len(records) == 0 {
+		return sdk.DecCoins{}, 0
+	}
+	for i, record := range records {
+		start, end := record.Height, to
+		if i+1 < len(records) && records[i+1].Height < end {
+			end = records[i+1].Height
+		}
+		if start < from {
+			start = from
+		}
+		if start >= end {
+			continue
+		}
+		provisions = provisions.Add(record.Amount.MulDec(sdk.NewDec(int64(end - start)))...)
+	}
+	if provisions == nil {
+		provisions = sdk.DecCoins{}
+	}
+	return provisions, records[0].Height
+}
+
+// BurnCollectedFees burns the proportion of the fees collected in the mint denom set by FeeBurnProportion.
+// The burned fees offset the provisions up to the minted amount: they are moved to the mint module account
+// to be distributed in place of minted coins, only the excess is actually burned. It returns the burned
+// fees and the part offsetting the provisions.
+func (k Keeper) BurnCollectedFees(ctx sdk.Context, params types.Params, minted sdk.Dec) (burned, offset sdk.Dec, err error) {
+	burned, offset = sdk.ZeroDec(), sdk.ZeroDec()
+	if !params.FeeBurnProportion.IsPositive() {
+		return
+	}
+
+	feeCollector := k.supplyKeeper.GetModuleAccount(ctx, k.feeCollectorName)
+	burned = feeCollector.GetCoins().AmountOf(params.MintDenom).MulTruncate(params.FeeBurnProportion)
+	if !burned.IsPositive() {
+		return
+	}
+
+	offset = sdk.MinDec(burned, minted)
+	if offset.IsPositive() {
+		err = k.supplyKeeper.SendCoinsFromModuleToModule(ctx, k.feeCollectorName, types.ModuleName,
+			sdk.NewDecCoinsFromDec(params.MintDenom, offset))
+		if err != nil {
+			return
+		}
+	}
+	if excess := burned.Sub(offset); excess.IsPositive() {
+		excessCoins := sdk.NewDecCoinsFromDec(params.MintDenom, excess)
+		if err = k.supplyKeeper.SendCoinsFromModuleToModule(ctx, k.feeCollectorName, types.FeeBurnerName, excessCoins); err != nil {
+			return
+		}
+		if err = k.supplyKeeper.BurnCoins(ctx, types.FeeBurnerName, excessCoins); err != nil {
+			return
+		}
+	}
+
+	k.SetBurnedFees(ctx, k.GetBurnedFees(ctx).Add(sdk.NewDecCoinsFromDec(params.MintDenom, burned)...))
+	return
+}
+
+// GetBurnedFees returns the cumulative fees burned
+func (k Keeper) GetBurnedFees(ctx sdk.Context) (burned sdk.DecCoins) {
+	b := ctx.KVStore(k.storeKey).Get(types.BurnedFeesKey)
+	if b != nil {
+		k.cdc.MustUnmarshalBinaryLengthPrefixed(b, &burned)
+	}
+	return
+}
+
+// SetBurnedFees sets the cumulative fees burned
+func (k Keeper) SetBurnedFees(ctx sdk.Context, burned sdk.DecCoins) {
+	ctx.KVStore(k.storeKey).Set(types.BurnedFeesKey, k.cdc.MustMarshalBinaryLengthPrefixed(burned))
+}
+
 func (k Keeper) AddYieldFarming(ctx sdk.Context, yieldAmt sdk.Coins) error {
 	// todo: verify farmModuleName
 	if len(k.farmModuleName) == 0 {
@@ -45,6 +230,56 @@ func (k Keeper) UpdateMinterCustom(ctx sdk.Context, minter *types.MinterCustom,
 	k.SetMinterCustom(ctx, *minter)
 }
 
+// UpdateMinterWithSchedule sets the amount minted per block to the one of the inflation schedule at the
+// current height. The minter is stored only when the phase changes. Before the first phase, the minted
+// amount keeps deflating every DeflationEpoch years. After the last phase, the next block to update is the
+// one where the deflation resumes if the schedule gets removed.
+func (k Keeper) UpdateMinterWithSchedule(ctx sdk.Context, minter *types.MinterCustom, params types.Params) {
+	height := uint64(ctx.BlockHeight())
+	if params.InflationSchedule.PhaseAt(height) < 0 {
+		if height == 0 || height >= minter.NextBlockToUpdate {
+			k.UpdateMinterCustom(ctx, minter, params)
+		}
+		return
+	}
+
+	mintedPerBlock := params.InflationSchedule.MintedPerBlockAt(height)
+	nextBlockToUpdate := params.InflationSchedule.NextPhaseHeight(height)
+	if nextBlockToUpdate == 0 {
+		last := params.InflationSchedule[len(params.InflationSchedule)-1]
+		nextBlockToUpdate = last.StartHeight + params.DeflationEpoch*params.BlocksPerYear
+	}
+
+	if minter.MintedPerBlock.AmountOf(params.MintDenom).Equal(mintedPerBlock) && minter.NextBlockToUpdate == nextBlockToUpdate {
+		return
+	}
+	minter.MintedPerBlock = sdk.NewDecCoinsFromDec(params.MintDenom, mintedPerBlock)
+	minter.NextBlockToUpdate = nextBlockToUpdate
+	k.SetMinterCustom(ctx, *minter)
+}
+
+// ProjectedProvisions returns the amount minted by the blocks in [from, to) under the current params
+func (k Keeper) ProjectedProvisions(ctx sdk.Context, from, to uint64) sdk.DecCoins {
+	params := k.GetParams(ctx)
+	minter := k.GetMinterCustom(ctx)
+	mintedPerBlock := minter.MintedPerBlock.AmountOf(params.MintDenom)
+
+	total := sdk.ZeroDec()
+	// the deflation goes on until the first phase of the schedule, if any
+	deflationEnd := to
+	if len(params.InflationSchedule) > 0 && params.InflationSchedule[0].StartHeight < to {
+		deflationEnd = params.InflationSchedule[0].StartHeight
+	}
+	if from < deflationEnd {
+		total = types.ProjectedDeflationProvisions(params, mintedPerBlock, minter.NextBlockToUpdate, from, deflationEnd)
+		from = deflationEnd
+	}
+	if from < to {
+		total = total.Add(params.InflationSchedule.ProjectedProvisions(from, to))
+	}
+	return sdk.NewDecCoinsFromDec(params.MintDenom, total)
+}
+
 
 //______________________________________________________________________
 
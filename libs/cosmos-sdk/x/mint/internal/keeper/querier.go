@@ -1,6 +1,8 @@
 package keeper
 
 import (
+	"math"
+
 	abci "github.com/okex/exchain/libs/tendermint/abci/types"
 
 	"github.com/okex/exchain/libs/cosmos-sdk/codec"
@@ -11,11 +13,32 @@ import (
 
 // NewQuerier returns a minting Querier handler.
 func NewQuerier(k Keeper) sdk.Querier {
-	return func(ctx sdk.Context, path []string, _ abci.RequestQuery) ([]byte, error) {
+	return func(ctx sdk.Context, path []string, req abci.RequestQuery) ([]byte, error) {
 		switch path[0] {
 		case types.QueryParameters:
 			return queryParams(ctx, k)
 
+		case types.QueryCurrentPhase:
+			return queryCurrentPhase(ctx, k)
+
+		case types.QueryProjectedProvisions:
+			return queryProjectedProvisions(ctx, req, k)
+
+		case types.QuerySplitHistory:
+			return querySplitHistory(ctx, k)
+
+		case types.QueryBurnedFees:
+			return queryBurnedFees(ctx, k)
+
+		case types.QueryProvisions:
+			return queryProvisions(ctx, req, k)
+
+		case types.QueryEpochProvisions:
+			return queryEpochProvisions(ctx, req, k)
+
+		case types.QuerySupplyProjection:
+			return querySupplyProjection(ctx, req, k)
+
 		default:
 			return nil, sdkerrors.Wrapf(sdkerrors.ErrUnknownRequest, "unknown query path: %s", path[0])
 		}
@@ -33,6 +56,178 @@ func queryParams(ctx sdk.Context, k Keeper) ([]byte, error) {
 	return res, nil
 }
 
+func queryCurrentPhase(ctx sdk.Context, k Keeper) ([]byte, error) {
+	params := k.GetParams(ctx)
+	minter := k.GetMinterCustom(ctx)
+	height := uint64(ctx.BlockHeight())
+
+	phase := types.CurrentPhase{
+		Height:            height,
+		Phase:             params.InflationSchedule.PhaseAt(height),
+		MintedPerBlock:    minter.MintedPerBlock,
+		NextBlockToUpdate: minter.NextBlockToUpdate,
+	}
+
+	res, err := codec.MarshalJSONIndent(k.cdc, phase)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+
+	return res, nil
+}
+
+func queryProjectedProvisions(ctx sdk.Context, req abci.RequestQuery, k Keeper) ([]byte, error) {
+	var reqParams types.QueryProjectedProvisionsParams
+	if len(req.Data) > 0 {
+		if err := k.cdc.UnmarshalJSON(req.Data, &reqParams); err != nil {
+			return nil, sdkerrors.Wrap(sdkerrors.ErrJSONUnmarshal, err.Error())
+		}
+	}
+	params := k.GetParams(ctx)
+	blocks := reqParams.Blocks
+	if blocks == 0 {
+		blocks = params.BlocksPerYear
+	}
+	// the provisions of the current block are already minted
+	from := uint64(ctx.BlockHeight()) + 1
+	if err := validateProjectedBlocks(params, from, blocks); err != nil {
+		return nil, err
+	}
+	provisions := types.ProjectedProvisions{
+		FromHeight: from,
+		ToHeight:   from + blocks,
+		Amount:     k.ProjectedProvisions(ctx, from, from+blocks),
+	}
+
+	res, err := codec.MarshalJSONIndent(k.cdc, provisions)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+
+	return res, nil
+}
+
+func querySplitHistory(ctx sdk.Context, k Keeper) ([]byte, error) {
+	splits := k.GetMintSplits(ctx)
+	if splits == nil {
+		splits = []types.MintSplit{}
+	}
+
+	res, err := codec.MarshalJSONIndent(k.cdc, splits)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+
+	return res, nil
+}
+
+func queryBurnedFees(ctx sdk.Context, k Keeper) ([]byte, error) {
+	burned := k.GetBurnedFees(ctx)
+	if burned == nil {
+		burned = sdk.DecCoins{}
+	}
+
+	res, err := codec.MarshalJSONIndent(k.cdc, burned)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+
+	return res, nil
+}
+
+func queryProvisions(ctx sdk.Context, req abci.RequestQuery, k Keeper) ([]byte, error) {
+	var reqParams types.QueryProvisionsParams
+	if err := k.cdc.UnmarshalJSON(req.Data, &reqParams); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONUnmarshal, err.Error())
+	}
+	if reqParams.FromHeight >= reqParams.ToHeight {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "from height %d must be lower than to height %d",
+			reqParams.FromHeight, reqParams.ToHeight)
+	}
+
+	return marshalProvisions(ctx, k, reqParams.FromHeight, reqParams.ToHeight)
+}
+
+func queryEpochProvisions(ctx sdk.Context, req abci.RequestQuery, k Keeper) ([]byte, error) {
+	var reqParams types.QueryEpochProvisionsParams
+	if err := k.cdc.UnmarshalJSON(req.Data, &reqParams); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONUnmarshal, err.Error())
+	}
+	params := k.GetParams(ctx)
+	epoch := params.DeflationEpoch * params.BlocksPerYear
+	if epoch == 0 {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "the deflation epoch is empty")
+	}
+	if reqParams.Epoch >= math.MaxInt64/epoch {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "epoch overflows the height: %d", reqParams.Epoch)
+	}
+
+	return marshalProvisions(ctx, k, reqParams.Epoch*epoch, (reqParams.Epoch+1)*epoch)
+}
+
+func marshalProvisions(ctx sdk.Context, k Keeper, from, to uint64) ([]byte, error) {
+	amount, recordedFrom := k.HistoricalProvisions(ctx, from, to)
+	provisions := types.Provisions{
+		FromHeight:         from,
+		ToHeight:           to,
+		Amount:             amount,
+		RecordedFromHeight: recordedFrom,
+	}
+
+	res, err := codec.MarshalJSONIndent(k.cdc, provisions)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+
+	return res, nil
+}
+
+// validateProjectedBlocks bounds the projection of the blocks following from to MaxProjectedYears years
+func validateProjectedBlocks(params types.Params, from, blocks uint64) error {
+	if blocks/types.MaxProjectedYears > params.BlocksPerYear {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest,
+			"blocks must not exceed %d years of blocks: %d", types.MaxProjectedYears, blocks)
+	}
+	if blocks > math.MaxInt64-from {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "blocks overflow the height: %d", blocks)
+	}
+	return nil
+}
+
+func querySupplyProjection(ctx sdk.Context, req abci.RequestQuery, k Keeper) ([]byte, error) {
+	var reqParams types.QuerySupplyProjectionParams
+	if err := k.cdc.UnmarshalJSON(req.Data, &reqParams); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONUnmarshal, err.Error())
+	}
+	current := uint64(ctx.BlockHeight())
+	if reqParams.Height <= current {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "height %d must be greater than the current height %d",
+			reqParams.Height, current)
+	}
+
+	// the supply at a height includes the provisions of its block
+	params := k.GetParams(ctx)
+	if err := validateProjectedBlocks(params, current+1, reqParams.Height-current); err != nil {
+		return nil, err
+	}
+	supply := sdk.NewDecCoinsFromDec(params.MintDenom, k.StakingTokenSupply(ctx))
+	provisions := k.ProjectedProvisions(ctx, current+1, reqParams.Height+1)
+	projection := types.SupplyProjection{
+		Height:              reqParams.Height,
+		CurrentHeight:       current,
+		CurrentSupply:       supply,
+		ProjectedProvisions: provisions,
+		ProjectedSupply:     supply.Add(provisions...),
+	}
+
+	res, err := codec.MarshalJSONIndent(k.cdc, projection)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+
+	return res, nil
+}
+
 func queryInflation(ctx sdk.Context, k Keeper) ([]byte, error) {
 	minter := k.GetMinter(ctx)
 
@@ -1,10 +1,12 @@
 package keeper_test
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
 	keep "github.com/okex/exchain/libs/cosmos-sdk/x/mint/internal/keeper"
 	"github.com/okex/exchain/libs/cosmos-sdk/x/mint/internal/types"
 
@@ -46,3 +48,248 @@ func TestQueryParams(t *testing.T) {
 	require.Equal(t, expected.DeflationEpoch, params.DeflationEpoch)
 	require.Equal(t, expected.FarmProportion, params.FarmProportion)
 }
+
+func TestQueryInflationSchedule(t *testing.T) {
+	app, ctx := createTestApp(false)
+	querier := keep.NewQuerier(app.MintKeeper)
+
+	params := app.MintKeeper.GetParams(ctx)
+	params.InflationSchedule = types.InflationSchedule{
+		{StartHeight: 10, MintedPerBlock: sdk.NewDec(4)},
+		{StartHeight: 20, MintedPerBlock: sdk.NewDec(2)},
+	}
+	app.MintKeeper.SetParams(ctx, params)
+
+	ctx = ctx.WithBlockHeight(15)
+	minter := app.MintKeeper.GetMinterCustom(ctx)
+	app.MintKeeper.UpdateMinterWithSchedule(ctx, &minter, params)
+
+	var phase types.CurrentPhase
+	res, err := querier(ctx, []string{types.QueryCurrentPhase}, abci.RequestQuery{})
+	require.NoError(t, err)
+	require.NoError(t, app.Codec().UnmarshalJSON(res, &phase))
+	require.Equal(t, 0, phase.Phase)
+	require.Equal(t, sdk.NewDec(4), phase.MintedPerBlock.AmountOf(params.MintDenom))
+	require.Equal(t, uint64(20), phase.NextBlockToUpdate)
+
+	var provisions types.ProjectedProvisions
+	bz := app.Codec().MustMarshalJSON(types.NewQueryProjectedProvisionsParams(10))
+	res, err = querier(ctx, []string{types.QueryProjectedProvisions}, abci.RequestQuery{Data: bz})
+	require.NoError(t, err)
+	require.NoError(t, app.Codec().UnmarshalJSON(res, &provisions))
+	require.Equal(t, uint64(16), provisions.FromHeight)
+	require.Equal(t, uint64(26), provisions.ToHeight)
+	// 4 blocks of the first phase and 6 of the second
+	require.Equal(t, sdk.NewDec(4*4+6*2), provisions.Amount.AmountOf(params.MintDenom))
+}
+
+func TestInflationScheduleBeforeFirstPhase(t *testing.T) {
+	app, ctx := createTestApp(false)
+	querier := keep.NewQuerier(app.MintKeeper)
+
+	params := app.MintKeeper.GetParams(ctx)
+	params.DeflationEpoch = 1
+	params.BlocksPerYear = 10
+	params.DeflationRate = sdk.NewDecWithPrec(5, 1)
+	params.InflationSchedule = types.InflationSchedule{{StartHeight: 30, MintedPerBlock: sdk.NewDec(1)}}
+	app.MintKeeper.SetParams(ctx, params)
+	minter := types.MinterCustom{
+		MintedPerBlock:    sdk.NewDecCoinsFromDec(params.MintDenom, sdk.NewDec(8)),
+		NextBlockToUpdate: 10,
+	}
+	app.MintKeeper.SetMinterCustom(ctx, minter)
+
+	// the minted amount deflates until the first phase
+	app.MintKeeper.UpdateMinterWithSchedule(ctx.WithBlockHeight(5), &minter, params)
+	require.Equal(t, sdk.NewDec(8), minter.MintedPerBlock.AmountOf(params.MintDenom))
+	require.Equal(t, uint64(10), minter.NextBlockToUpdate)
+
+	ctx = ctx.WithBlockHeight(10)
+	app.MintKeeper.UpdateMinterWithSchedule(ctx, &minter, params)
+	require.Equal(t, sdk.NewDec(4), minter.MintedPerBlock.AmountOf(params.MintDenom))
+	require.Equal(t, uint64(20), minter.NextBlockToUpdate)
+
+	var provisions types.ProjectedProvisions
+	bz := app.Codec().MustMarshalJSON(types.NewQueryProjectedProvisionsParams(30))
+	res, err := querier(ctx, []string{types.QueryProjectedProvisions}, abci.RequestQuery{Data: bz})
+	require.NoError(t, err)
+	require.NoError(t, app.Codec().UnmarshalJSON(res, &provisions))
+	// 9 blocks minting 4, 10 deflated ones minting 2, then 11 blocks of the first phase
+	require.Equal(t, sdk.NewDec(9*4+10*2+11*1), provisions.Amount.AmountOf(params.MintDenom))
+
+	app.MintKeeper.UpdateMinterWithSchedule(ctx.WithBlockHeight(30), &minter, params)
+	require.Equal(t, sdk.NewDec(1), minter.MintedPerBlock.AmountOf(params.MintDenom))
+	require.Equal(t, uint64(40), minter.NextBlockToUpdate)
+}
+
+func TestQueryProjectedProvisionsBlocksLimit(t *testing.T) {
+	app, ctx := createTestApp(false)
+	querier := keep.NewQuerier(app.MintKeeper)
+	params := app.MintKeeper.GetParams(ctx)
+
+	bz := app.Codec().MustMarshalJSON(types.NewQueryProjectedProvisionsParams((types.MaxProjectedYears + 1) * params.BlocksPerYear))
+	_, err := querier(ctx, []string{types.QueryProjectedProvisions}, abci.RequestQuery{Data: bz})
+	require.Error(t, err)
+
+	params.BlocksPerYear = math.MaxUint64 / types.MaxProjectedYears
+	app.MintKeeper.SetParams(ctx, params)
+	bz = app.Codec().MustMarshalJSON(types.NewQueryProjectedProvisionsParams(math.MaxUint64 - 1))
+	_, err = querier(ctx, []string{types.QueryProjectedProvisions}, abci.RequestQuery{Data: bz})
+	require.Error(t, err)
+}
+
+func TestQuerySplitHistory(t *testing.T) {
+	app, ctx := createTestApp(false)
+	querier := keep.NewQuerier(app.MintKeeper)
+
+	treasuries := types.Treasuries{
+		{Name: "distribution", Proportion: sdk.NewDecWithPrec(2, 1)},
+		{Name: "unknown", Proportion: sdk.NewDecWithPrec(1, 1)},
+	}
+	minted := sdk.NewDecCoinsFromDec(sdk.DefaultBondDenom, sdk.NewDec(10))
+	require.NoError(t, app.MintKeeper.MintCoins(ctx, minted))
+
+	// the treasury without a module account is skipped
+	splits, err := app.MintKeeper.SendToTreasuries(ctx, minted, treasuries)
+	require.NoError(t, err)
+	require.Len(t, splits, 1)
+	require.Equal(t, "distribution", splits[0].Name)
+	require.Equal(t, sdk.NewDec(2), splits[0].Amount.AmountOf(sdk.DefaultBondDenom))
+	distrAcc := app.SupplyKeeper.GetModuleAccount(ctx, "distribution")
+	require.Equal(t, sdk.NewDec(2), distrAcc.GetCoins().AmountOf(sdk.DefaultBondDenom))
+
+	// a split equal to the last recorded one isn't recorded again
+	for height := uint64(1); height <= 3; height++ {
+		app.MintKeeper.RecordMintSplit(ctx, types.MintSplit{Height: height, Treasuries: splits})
+	}
+	changed := []types.TreasurySplit{{Name: "distribution", Amount: sdk.NewDecCoinsFromDec(sdk.DefaultBondDenom, sdk.NewDec(1))}}
+	app.MintKeeper.RecordMintSplit(ctx, types.MintSplit{Height: 4, Treasuries: changed})
+
+	var history []types.MintSplit
+	res, err := querier(ctx, []string{types.QuerySplitHistory}, abci.RequestQuery{})
+	require.NoError(t, err)
+	require.NoError(t, app.Codec().UnmarshalJSON(res, &history))
+	require.Len(t, history, 2)
+	require.Equal(t, uint64(1), history[0].Height)
+	require.Equal(t, uint64(4), history[1].Height)
+}
+
+func TestQueryBurnedFees(t *testing.T) {
+	app, ctx := createTestApp(false)
+	querier := keep.NewQuerier(app.MintKeeper)
+
+	params := app.MintKeeper.GetParams(ctx)
+	params.FeeBurnProportion = sdk.NewDecWithPrec(5, 1)
+	app.MintKeeper.SetParams(ctx, params)
+
+	fees := sdk.NewDecCoinsFromDec(params.MintDenom, sdk.NewDec(10))
+	require.NoError(t, app.MintKeeper.MintCoins(ctx, fees))
+	require.NoError(t, app.MintKeeper.AddCollectedFees(ctx, fees))
+	supplyBefore := app.SupplyKeeper.GetSupply(ctx).GetTotal().AmountOf(params.MintDenom)
+
+	// 3 of the 5 burned offset the provisions, the other 2 are burned
+	burned, offset, err := app.MintKeeper.BurnCollectedFees(ctx, params, sdk.NewDec(3))
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewDec(5), burned)
+	require.Equal(t, sdk.NewDec(3), offset)
+
+	feeCollector := app.SupplyKeeper.GetModuleAccount(ctx, "fee_collector")
+	require.Equal(t, sdk.NewDec(5), feeCollector.GetCoins().AmountOf(params.MintDenom))
+	mintAcc := app.SupplyKeeper.GetModuleAccount(ctx, types.ModuleName)
+	require.Equal(t, sdk.NewDec(3), mintAcc.GetCoins().AmountOf(params.MintDenom))
+	supplyAfter := app.SupplyKeeper.GetSupply(ctx).GetTotal().AmountOf(params.MintDenom)
+	require.Equal(t, sdk.NewDec(2), supplyBefore.Sub(supplyAfter))
+
+	var total sdk.DecCoins
+	res, err := querier(ctx, []string{types.QueryBurnedFees}, abci.RequestQuery{})
+	require.NoError(t, err)
+	require.NoError(t, app.Codec().UnmarshalJSON(res, &total))
+	require.Equal(t, sdk.NewDec(5), total.AmountOf(params.MintDenom))
+}
+
+func TestQueryProvisions(t *testing.T) {
+	app, ctx := createTestApp(false)
+	querier := keep.NewQuerier(app.MintKeeper)
+	params := app.MintKeeper.GetParams(ctx)
+	params.BlocksPerYear = 10
+	params.DeflationEpoch = 1
+	app.MintKeeper.SetParams(ctx, params)
+
+	// 4 minted per block from height 5, 2 from height 15, recorded once per change
+	for height := int64(5); height < 25; height++ {
+		minted := sdk.NewDec(4)
+		if height >= 15 {
+			minted = sdk.NewDec(2)
+		}
+		app.MintKeeper.RecordMintedPerBlock(ctx.WithBlockHeight(height), sdk.NewDecCoinsFromDec(params.MintDenom, minted))
+	}
+	require.Len(t, app.MintKeeper.GetMintedPerBlockRecords(ctx), 2)
+	ctx = ctx.WithBlockHeight(24)
+
+	var provisions types.Provisions
+	bz := app.Codec().MustMarshalJSON(types.NewQueryProvisionsParams(0, 100))
+	res, err := querier(ctx, []string{types.QueryProvisions}, abci.RequestQuery{Data: bz})
+	require.NoError(t, err)
+	require.NoError(t, app.Codec().UnmarshalJSON(res, &provisions))
+	// the blocks after the current one are not minted yet
+	require.Equal(t, sdk.NewDec(10*4+10*2), provisions.Amount.AmountOf(params.MintDenom))
+	require.Equal(t, uint64(5), provisions.RecordedFromHeight)
+
+	bz = app.Codec().MustMarshalJSON(types.NewQueryEpochProvisionsParams(1))
+	res, err = querier(ctx, []string{types.QueryEpochProvisions}, abci.RequestQuery{Data: bz})
+	require.NoError(t, err)
+	require.NoError(t, app.Codec().UnmarshalJSON(res, &provisions))
+	require.Equal(t, uint64(10), provisions.FromHeight)
+	require.Equal(t, uint64(20), provisions.ToHeight)
+	require.Equal(t, sdk.NewDec(5*4+5*2), provisions.Amount.AmountOf(params.MintDenom))
+
+	bz = app.Codec().MustMarshalJSON(types.NewQueryProvisionsParams(10, 10))
+	_, err = querier(ctx, []string{types.QueryProvisions}, abci.RequestQuery{Data: bz})
+	require.Error(t, err)
+}
+
+func TestQuerySupplyProjection(t *testing.T) {
+	app, ctx := createTestApp(false)
+	querier := keep.NewQuerier(app.MintKeeper)
+
+	params := app.MintKeeper.GetParams(ctx)
+	params.InflationSchedule = types.InflationSchedule{{StartHeight: 1, MintedPerBlock: sdk.NewDec(3)}}
+	app.MintKeeper.SetParams(ctx, params)
+	ctx = ctx.WithBlockHeight(10)
+
+	var projection types.SupplyProjection
+	bz := app.Codec().MustMarshalJSON(types.NewQuerySupplyProjectionParams(20))
+	res, err := querier(ctx, []string{types.QuerySupplyProjection}, abci.RequestQuery{Data: bz})
+	require.NoError(t, err)
+	require.NoError(t, app.Codec().UnmarshalJSON(res, &projection))
+	require.Equal(t, sdk.NewDec(10*3), projection.ProjectedProvisions.AmountOf(params.MintDenom))
+	supply := app.MintKeeper.StakingTokenSupply(ctx)
+	require.Equal(t, supply.Add(sdk.NewDec(10*3)), projection.ProjectedSupply.AmountOf(params.MintDenom))
+
+	bz = app.Codec().MustMarshalJSON(types.NewQuerySupplyProjectionParams(10))
+	_, err = querier(ctx, []string{types.QuerySupplyProjection}, abci.RequestQuery{Data: bz})
+	require.Error(t, err)
+
+	bz = app.Codec().MustMarshalJSON(types.NewQuerySupplyProjectionParams(math.MaxUint64))
+	_, err = querier(ctx, []string{types.QuerySupplyProjection}, abci.RequestQuery{Data: bz})
+	require.Error(t, err)
+}
+
+func TestTreasuryParams(t *testing.T) {
+	app, ctx := createTestApp(false)
+	subspace, ok := app.ParamsKeeper.GetSubspace(types.ModuleName)
+	require.True(t, ok)
+
+	// the param changes of the proposals only accept the module accounts and the community pool as treasuries
+	require.NoError(t, subspace.Update(ctx, types.KeyTreasuries,
+		[]byte(`[{"name":"distribution","proportion":"0.2"},{"name":"community_pool","proportion":"0.1"}]`)))
+	require.Len(t, app.MintKeeper.GetParams(ctx).Treasuries, 2)
+	err := subspace.Update(ctx, types.KeyTreasuries, []byte(`[{"name":"unknown","proportion":"0.1"}]`))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "treasury unknown is not a module account")
+	require.Len(t, app.MintKeeper.GetParams(ctx).Treasuries, 2)
+
+	params := types.DefaultParams()
+	params.Treasuries = types.Treasuries{{Name: "unknown", Proportion: sdk.NewDecWithPrec(1, 1)}}
+	require.Panics(t, func() { app.MintKeeper.SetParams(ctx, params) })
+}
@@ -2,9 +2,13 @@ package types
 
 // Minting module event types
 const (
-	EventTypeMint = ModuleName
+	EventTypeMint     = ModuleName
+	EventTypeTreasury = "mint_treasury"
+	EventTypeFeeBurn  = "fee_burn"
 
 	AttributeKeyBondedRatio      = "bonded_ratio"
 	AttributeKeyInflation        = "inflation"
 	AttributeKeyAnnualProvisions = "annual_provisions"
+	AttributeKeyTreasury         = "treasury"
+	AttributeKeyNetProvisions    = "net_provisions"
 )
@@ -0,0 +1,116 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+)
+
+// InflationPhase mints a fixed amount per block from its start height until the start height of the next phase
+type InflationPhase struct {
+	StartHeight    uint64  `json:"start_height" yaml:"start_height"`
+	MintedPerBlock sdk.Dec `json:"minted_per_block" yaml:"minted_per_block"`
+}
+
+func (p InflationPhase) String() string {
+	return fmt.Sprintf("from height %d: %s per block", p.StartHeight, p.MintedPerBlock)
+}
+
+// InflationSchedule is the list of the phases of the emission, sorted by start height. An empty schedule
+// keeps the deflation of the minted amount every DeflationEpoch years.
+type InflationSchedule []InflationPhase
+
+func (s InflationSchedule) String() string {
+	if len(s) == 0 {
+		return "none"
+	}
+	phases := make([]string, len(s))
+	for i, p := range s {
+		phases[i] = p.String()
+	}
+	return strings.Join(phases, ", ")
+}
+
+// Validate checks the phases are sorted by strictly increasing start heights and mint non-negative amounts
+func (s InflationSchedule) Validate() error {
+	for i, p := range s {
+		if p.MintedPerBlock.IsNil() || p.MintedPerBlock.IsNegative() {
+			return fmt.Errorf("minted per block of inflation phase %d must be non-negative: %s", i, p.MintedPerBlock)
+		}
+		if i > 0 && p.StartHeight <= s[i-1].StartHeight {
+			return fmt.Errorf("start height of inflation phase %d must be greater than %d: %d", i, s[i-1].StartHeight, p.StartHeight)
+		}
+	}
+	return nil
+}
+
+// PhaseAt returns the index of the phase minting at the given height, -1 before the first phase
+func (s InflationSchedule) PhaseAt(height uint64) int {
+	index := -1
+	for i, p := range s {
+		if p.StartHeight > height {
+			break
+		}
+		index = i
+	}
+	return index
+}
+
+// MintedPerBlockAt returns the amount minted at the given height, zero before the first phase
+func (s InflationSchedule) MintedPerBlockAt(height uint64) sdk.Dec {
+	index := s.PhaseAt(height)
+	if index < 0 {
+		return sdk.ZeroDec()
+	}
+	return s[index].MintedPerBlock
+}
+
+// NextPhaseHeight returns the start height of the phase following the one at the given height, 0 if there's none
+func (s InflationSchedule) NextPhaseHeight(height uint64) uint64 {
+	index := s.PhaseAt(height)
+	if index+1 < len(s) {
+		return s[index+1].StartHeight
+	}
+	return 0
+}
+
+// ProjectedProvisions returns the amount minted by the blocks in [from, to) following the schedule
+func (s InflationSchedule) ProjectedProvisions(from, to uint64) sdk.Dec {
+	total := sdk.ZeroDec()
+	for height := from; height < to; {
+		end := s.NextPhaseHeight(height)
+		if end == 0 || end > to {
+			end = to
+		}
+		total = total.Add(s.MintedPerBlockAt(height).MulInt64(int64(end - height)))
+		height = end
+	}
+	return total
+}
+
+// ProjectedDeflationProvisions returns the amount minted by the blocks in [from, to) without a schedule, when
+// the minted amount is multiplied by the deflation rate every DeflationEpoch years from nextBlockToUpdate
+func ProjectedDeflationProvisions(params Params, mintedPerBlock sdk.Dec, nextBlockToUpdate, from, to uint64) sdk.Dec {
+	epoch := params.DeflationEpoch * params.BlocksPerYear
+	total := sdk.ZeroDec()
+	for height := from; height < to; {
+		if nextBlockToUpdate != 0 && height >= nextBlockToUpdate {
+			if epoch == 0 {
+				// the amount never deflates again
+				nextBlockToUpdate = 0
+				continue
+			}
+			mintedPerBlock = mintedPerBlock.Mul(params.DeflationRate)
+			nextBlockToUpdate += epoch
+			continue
+		}
+		end := to
+		if nextBlockToUpdate > height && nextBlockToUpdate < to {
+			end = nextBlockToUpdate
+		}
+		total = total.Add(mintedPerBlock.MulInt64(int64(end - height)))
+		height = end
+	}
+	return total
+}
@@ -0,0 +1,51 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+)
+
+func TestInflationSchedule(t *testing.T) {
+	schedule := InflationSchedule{
+		{StartHeight: 10, MintedPerBlock: sdk.NewDec(4)},
+		{StartHeight: 20, MintedPerBlock: sdk.NewDec(2)},
+		{StartHeight: 30, MintedPerBlock: sdk.ZeroDec()},
+	}
+	require.NoError(t, schedule.Validate())
+
+	require.Equal(t, -1, schedule.PhaseAt(9))
+	require.Equal(t, 0, schedule.PhaseAt(10))
+	require.Equal(t, 1, schedule.PhaseAt(29))
+	require.Equal(t, 2, schedule.PhaseAt(1000))
+
+	require.True(t, schedule.MintedPerBlockAt(5).IsZero())
+	require.Equal(t, sdk.NewDec(2), schedule.MintedPerBlockAt(25))
+	require.Equal(t, uint64(10), schedule.NextPhaseHeight(0))
+	require.Equal(t, uint64(30), schedule.NextPhaseHeight(20))
+	require.Equal(t, uint64(0), schedule.NextPhaseHeight(30))
+
+	// 5 blocks of phase 0, 10 of phase 1 and 5 of phase 2
+	require.Equal(t, sdk.NewDec(5*4+10*2), schedule.ProjectedProvisions(15, 35))
+	require.True(t, schedule.ProjectedProvisions(0, 10).IsZero())
+
+	require.Error(t, InflationSchedule{
+		{StartHeight: 10, MintedPerBlock: sdk.NewDec(4)},
+		{StartHeight: 10, MintedPerBlock: sdk.NewDec(2)},
+	}.Validate())
+	require.Error(t, InflationSchedule{{StartHeight: 10, MintedPerBlock: sdk.NewDec(-1)}}.Validate())
+	require.Error(t, InflationSchedule{{StartHeight: 10}}.Validate())
+}
+
+func TestProjectedDeflationProvisions(t *testing.T) {
+	params := DefaultParams()
+	params.BlocksPerYear = 10
+	params.DeflationEpoch = 1
+	params.DeflationRate = sdk.NewDecWithPrec(5, 1)
+
+	// 5 blocks minting 8, 10 blocks minting 4 and 5 blocks minting 2
+	provisions := ProjectedDeflationProvisions(params, sdk.NewDec(8), 20, 15, 35)
+	require.Equal(t, sdk.NewDec(5*8+10*4+5*2), provisions)
+}
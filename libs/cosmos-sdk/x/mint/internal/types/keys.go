@@ -1,13 +1,36 @@
 package types
 
-// MinterKey is used for the keeper store
-var MinterKey = []byte{0x00}
+import sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+
+var (
+	// MinterKey is used for the keeper store
+	MinterKey = []byte{0x00}
+	// MintSplitKeyPrefix is the prefix of the recorded splits of the provisions, by height
+	MintSplitKeyPrefix = []byte{0x01}
+	// BurnedFeesKey is the key of the cumulative fees burned
+	BurnedFeesKey = []byte{0x02}
+	// MintedPerBlockKeyPrefix is the prefix of the recorded amounts minted per block, by height
+	MintedPerBlockKeyPrefix = []byte{0x03}
+)
+
+// GetMintSplitKey returns the key of the split of the provisions recorded at the given height
+func GetMintSplitKey(height uint64) []byte {
+	return append(MintSplitKeyPrefix, sdk.Uint64ToBigEndian(height)...)
+}
+
+// GetMintedPerBlockKey returns the key of the amount minted per block recorded at the given height
+func GetMintedPerBlockKey(height uint64) []byte {
+	return append(MintedPerBlockKeyPrefix, sdk.Uint64ToBigEndian(height)...)
+}
 
 // nolint
 const (
 	// ModuleName
 	ModuleName = "mint"
 
+	// FeeBurnerName is the module account burning the fees not offset by the provisions
+	FeeBurnerName = "fee_burner"
+
 	// DefaultParamspace params keeper
 	DefaultParamspace = ModuleName
 
@@ -21,4 +44,12 @@ const (
 	QueryParameters       = "parameters"
 	QueryInflation        = "inflation"
 	QueryAnnualProvisions = "annual_provisions"
+
+	QueryCurrentPhase        = "current_phase"
+	QueryProjectedProvisions = "projected_provisions"
+	QuerySplitHistory        = "split_history"
+	QueryBurnedFees          = "burned_fees"
+	QueryProvisions          = "provisions"
+	QueryEpochProvisions     = "epoch_provisions"
+	QuerySupplyProjection    = "supply_projection"
 )
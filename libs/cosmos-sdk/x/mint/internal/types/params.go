@@ -1,6 +1,7 @@
 package types
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"strings"
@@ -21,6 +22,10 @@ var (
 	KeyDeflationRate  = []byte("DeflationRate")
 	KeyDeflationEpoch = []byte("DeflationEpoch")
 	KeyFarmProportion = []byte("YieldFarmingProportion")
+
+	KeyInflationSchedule = []byte("InflationSchedule")
+	KeyTreasuries        = []byte("Treasuries")
+	KeyFeeBurnProportion = []byte("FeeBurnProportion")
 )
 
 // mint parameters
@@ -35,6 +40,10 @@ type Params struct {
 	DeflationRate  sdk.Dec `json:"deflation_rate" yaml:"deflation_rate"` // deflation rate every DeflationEpoch
 	DeflationEpoch uint64  `json:"deflation_epoch" yaml:"deflation_epoch"` // block number to deflate
 	FarmProportion sdk.Dec `json:"farm_proportion" yaml:"farm_proportion"` // proportion of minted for farm
+
+	InflationSchedule InflationSchedule `json:"inflation_schedule" yaml:"inflation_schedule"`   // phases of the emission, replacing the deflation once set
+	Treasuries        Treasuries        `json:"treasuries" yaml:"treasuries"`                   // treasuries receiving a proportion of minted
+	FeeBurnProportion sdk.Dec           `json:"fee_burn_proportion" yaml:"fee_burn_proportion"` // proportion of the collected gas fees burned
 }
 
 // ParamTable for minting module.
@@ -42,6 +51,24 @@ func ParamKeyTable() params.KeyTable {
 	return params.NewKeyTable().RegisterParamSet(&Params{})
 }
 
+// ParamKeyTableWithModuleAccounts returns the key table of the minting module which also validates the
+// treasuries against the module accounts of the isModuleAccount check, so that the param change proposals can't
+// set an unknown treasury
+func ParamKeyTableWithModuleAccounts(isModuleAccount func(name string) bool) params.KeyTable {
+	pairs := (&Params{}).ParamSetPairs()
+	for i, pair := range pairs {
+		if bytes.Equal(pair.Key, KeyTreasuries) {
+			pairs[i].ValidatorFn = func(i interface{}) error {
+				if err := validateTreasuries(i); err != nil {
+					return err
+				}
+				return i.(Treasuries).ValidateModuleAccounts(isModuleAccount)
+			}
+		}
+	}
+	return params.NewKeyTable(pairs...)
+}
+
 func NewParams(
 	mintDenom string, inflationRateChange, inflationMax, inflationMin, goalBonded sdk.Dec, blocksPerYear uint64,
 	deflationEpoch uint64, deflationRateChange, farmPropotion sdk.Dec,
@@ -68,6 +95,8 @@ func DefaultParams() Params {
 		DeflationRate:  sdk.NewDecWithPrec(5, 1),
 		DeflationEpoch: 3,                        // 3 years
 		FarmProportion: sdk.NewDecWithPrec(5, 1), // 0.5
+
+		FeeBurnProportion: sdk.ZeroDec(),
 	}
 }
 
@@ -88,6 +117,15 @@ func (p Params) Validate() error {
 	if err := validateBlocksPerYear(p.BlocksPerYear); err != nil {
 		return err
 	}
+	if err := validateInflationSchedule(p.InflationSchedule); err != nil {
+		return err
+	}
+	if err := validateTreasuries(p.Treasuries); err != nil {
+		return err
+	}
+	if err := validateFeeBurnProportion(p.FeeBurnProportion); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -98,8 +136,11 @@ func (p Params) String() string {
   Deflation Rate Every %d Years:  %s
   Blocks Per Year:                %d
   Farm Proportion:                %s
+  Inflation Schedule:             %s
+  Treasuries:                     %s
+  Fee Burn Proportion:            %s
 `,
-		p.MintDenom, p.DeflationEpoch, p.DeflationRate, p.BlocksPerYear, p.FarmProportion,
+		p.MintDenom, p.DeflationEpoch, p.DeflationRate, p.BlocksPerYear, p.FarmProportion, p.InflationSchedule, p.Treasuries, p.FeeBurnProportion,
 	)
 }
 
@@ -111,6 +152,9 @@ func (p *Params) ParamSetPairs() params.ParamSetPairs {
 		params.NewParamSetPair(KeyDeflationRate, &p.DeflationRate, validateDeflationRate),
 		params.NewParamSetPair(KeyDeflationEpoch, &p.DeflationEpoch, validateDeflationEpoch),
 		params.NewParamSetPair(KeyFarmProportion, &p.FarmProportion, validateFarmProportion),
+		params.NewParamSetPair(KeyInflationSchedule, &p.InflationSchedule, validateInflationSchedule),
+		params.NewParamSetPair(KeyTreasuries, &p.Treasuries, validateTreasuries),
+		params.NewParamSetPair(KeyFeeBurnProportion, &p.FeeBurnProportion, validateFeeBurnProportion),
 	}
 }
 
@@ -251,3 +295,37 @@ func validateDeflationEpoch(i interface{}) error {
 
 	return nil
 }
+
+func validateInflationSchedule(i interface{}) error {
+	v, ok := i.(InflationSchedule)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+
+	return v.Validate()
+}
+
+func validateTreasuries(i interface{}) error {
+	v, ok := i.(Treasuries)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+
+	return v.Validate()
+}
+
+func validateFeeBurnProportion(i interface{}) error {
+	v, ok := i.(sdk.Dec)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+
+	if v.IsNil() || v.IsNegative() {
+		return fmt.Errorf("fee burn proportion must be non-negative: %s", v)
+	}
+	if v.GT(sdk.OneDec()) {
+		return fmt.Errorf("fee burn proportion too large: %s", v)
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package types
+
+import (
+	"fmt"
+
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+)
+
+// CurrentPhase is the result of the current phase query. Phase is -1 while no phase of the inflation
+// schedule mints, i.e. without a schedule or before its first phase, the minted amount then deflates.
+type CurrentPhase struct {
+	Height            uint64       `json:"height" yaml:"height"`
+	Phase             int          `json:"phase" yaml:"phase"`
+	MintedPerBlock    sdk.DecCoins `json:"minted_per_block" yaml:"minted_per_block"`
+	NextBlockToUpdate uint64       `json:"next_block_to_update" yaml:"next_block_to_update"`
+}
+
+func (cp CurrentPhase) String() string {
+	return fmt.Sprintf(`Current Phase:
+  Height:                %d
+  Phase:                 %d
+  Minted Per Block:      %s
+  Next Block To Update:  %d`,
+		cp.Height, cp.Phase, cp.MintedPerBlock, cp.NextBlockToUpdate)
+}
+
+// MaxProjectedYears bounds the blocks of a projected provisions query to that many years of blocks
+const MaxProjectedYears = 100
+
+// QueryProjectedProvisionsParams defines the params of the projected provisions query, the provisions of
+// BlocksPerYear blocks are projected when Blocks is 0
+type QueryProjectedProvisionsParams struct {
+	Blocks uint64 `json:"blocks" yaml:"blocks"`
+}
+
+// NewQueryProjectedProvisionsParams creates a new instance of QueryProjectedProvisionsParams
+func NewQueryProjectedProvisionsParams(blocks uint64) QueryProjectedProvisionsParams {
+	return QueryProjectedProvisionsParams{Blocks: blocks}
+}
+
+// ProjectedProvisions is the result of the projected provisions query, the amount minted by the blocks
+// in [FromHeight, ToHeight) under the current params
+type ProjectedProvisions struct {
+	FromHeight uint64       `json:"from_height" yaml:"from_height"`
+	ToHeight   uint64       `json:"to_height" yaml:"to_height"`
+	Amount     sdk.DecCoins `json:"amount" yaml:"amount"`
+}
+
+func (pp ProjectedProvisions) String() string {
+	return fmt.Sprintf(`Projected Provisions:
+  From Height:  %d
+  To Height:    %d
+  Amount:       %s`,
+		pp.FromHeight, pp.ToHeight, pp.Amount)
+}
+
+// MintedPerBlock is the amount minted per block from Height on, until the next record
+type MintedPerBlock struct {
+	Height uint64       `json:"height" yaml:"height"`
+	Amount sdk.DecCoins `json:"amount" yaml:"amount"`
+}
+
+// QueryProvisionsParams defines the params of the provisions query, the blocks in [FromHeight, ToHeight)
+type QueryProvisionsParams struct {
+	FromHeight uint64 `json:"from_height" yaml:"from_height"`
+	ToHeight   uint64 `json:"to_height" yaml:"to_height"`
+}
+
+// NewQueryProvisionsParams creates a new instance of QueryProvisionsParams
+func NewQueryProvisionsParams(fromHeight, toHeight uint64) QueryProvisionsParams {
+	return QueryProvisionsParams{FromHeight: fromHeight, ToHeight: toHeight}
+}
+
+// QueryEpochProvisionsParams defines the params of the epoch provisions query. An epoch lasts
+// DeflationEpoch * BlocksPerYear blocks, epoch 0 starting at genesis.
+type QueryEpochProvisionsParams struct {
+	Epoch uint64 `json:"epoch" yaml:"epoch"`
+}
+
+// NewQueryEpochProvisionsParams creates a new instance of QueryEpochProvisionsParams
+func NewQueryEpochProvisionsParams(epoch uint64) QueryEpochProvisionsParams {
+	return QueryEpochProvisionsParams{Epoch: epoch}
+}
+
+// Provisions is the result of the provisions queries, the amount minted by the blocks in [FromHeight, ToHeight).
+// The minted amounts are recorded from RecordedFromHeight on, the blocks before it are not counted.
+type Provisions struct {
+	FromHeight         uint64       `json:"from_height" yaml:"from_height"`
+	ToHeight           uint64       `json:"to_height" yaml:"to_height"`
+	Amount             sdk.DecCoins `json:"amount" yaml:"amount"`
+	RecordedFromHeight uint64       `json:"recorded_from_height" yaml:"recorded_from_height"`
+}
+
+func (p Provisions) String() string {
+	return fmt.Sprintf(`Provisions:
+  From Height:           %d
+  To Height:             %d
+  Amount:                %s
+  Recorded From Height:  %d`,
+		p.FromHeight, p.ToHeight, p.Amount, p.RecordedFromHeight)
+}
+
+// QuerySupplyProjectionParams defines the params of the supply projection query
+type QuerySupplyProjectionParams struct {
+	Height uint64 `json:"height" yaml:"height"`
+}
+
+// NewQuerySupplyProjectionParams creates a new instance of QuerySupplyProjectionParams
+func NewQuerySupplyProjectionParams(height uint64) QuerySupplyProjectionParams {
+	return QuerySupplyProjectionParams{Height: height}
+}
+
+// SupplyProjection is the result of the supply projection query, the supply expected at Height under the
+// current params. The fees burned until then are not known in advance and are not deducted.
+type SupplyProjection struct {
+	Height              uint64       `json:"height" yaml:"height"`
+	CurrentHeight       uint64       `json:"current_height" yaml:"current_height"`
+	CurrentSupply       sdk.DecCoins `json:"current_supply" yaml:"current_supply"`
+	ProjectedProvisions sdk.DecCoins `json:"projected_provisions" yaml:"projected_provisions"`
+	ProjectedSupply     sdk.DecCoins `json:"projected_supply" yaml:"projected_supply"`
+}
+
+func (sp SupplyProjection) String() string {
+	return fmt.Sprintf(`Supply Projection:
+  Height:                %d
+  Current Height:        %d
+  Current Supply:        %s
+  Projected Provisions:  %s
+  Projected Supply:      %s`,
+		sp.Height, sp.CurrentHeight, sp.CurrentSupply, sp.ProjectedProvisions, sp.ProjectedSupply)
+}
@@ -0,0 +1,102 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+)
+
+const (
+	// CommunityPoolTreasury is the treasury funding the community pool of the distribution module
+	CommunityPoolTreasury = "community_pool"
+	// EcosystemFundName is the module account of the ecosystem fund treasury
+	EcosystemFundName = "ecosystem_fund"
+)
+
+// Treasury receives a proportion of the provisions of every block. Its name is the one of a module account,
+// or CommunityPoolTreasury.
+type Treasury struct {
+	Name       string  `json:"name" yaml:"name"`
+	Proportion sdk.Dec `json:"proportion" yaml:"proportion"`
+}
+
+func (t Treasury) String() string {
+	return fmt.Sprintf("%s: %s", t.Name, t.Proportion)
+}
+
+// Treasuries are the treasuries receiving the provisions before the fee collection and the yield farming
+type Treasuries []Treasury
+
+func (ts Treasuries) String() string {
+	if len(ts) == 0 {
+		return "none"
+	}
+	treasuries := make([]string, len(ts))
+	for i, t := range ts {
+		treasuries[i] = t.String()
+	}
+	return strings.Join(treasuries, ", ")
+}
+
+// Validate checks the treasuries are unique and their proportions add up to at most one
+func (ts Treasuries) Validate() error {
+	names := make(map[string]bool, len(ts))
+	total := sdk.ZeroDec()
+	for _, t := range ts {
+		if strings.TrimSpace(t.Name) == "" {
+			return fmt.Errorf("treasury name cannot be blank")
+		}
+		if names[t.Name] {
+			return fmt.Errorf("duplicate treasury %s", t.Name)
+		}
+		names[t.Name] = true
+
+		if t.Proportion.IsNil() || t.Proportion.IsNegative() {
+			return fmt.Errorf("proportion of treasury %s must be non-negative: %s", t.Name, t.Proportion)
+		}
+		total = total.Add(t.Proportion)
+	}
+	if total.GT(sdk.OneDec()) {
+		return fmt.Errorf("total proportion of the treasuries too large: %s", total)
+	}
+	return nil
+}
+
+// ValidateModuleAccounts checks the treasuries are module accounts by the isModuleAccount check, or
+// CommunityPoolTreasury
+func (ts Treasuries) ValidateModuleAccounts(isModuleAccount func(name string) bool) error {
+	for _, t := range ts {
+		if t.Name != CommunityPoolTreasury && !isModuleAccount(t.Name) {
+			return fmt.Errorf("treasury %s is not a module account", t.Name)
+		}
+	}
+	return nil
+}
+
+// TreasurySplit is the amount of the provisions of a block sent to a treasury
+type TreasurySplit struct {
+	Name   string       `json:"name" yaml:"name"`
+	Amount sdk.DecCoins `json:"amount" yaml:"amount"`
+}
+
+// MintSplit is the split of the provisions of every block from Height on, until the next recorded split
+type MintSplit struct {
+	Height       uint64          `json:"height" yaml:"height"`
+	Treasuries   []TreasurySplit `json:"treasuries" yaml:"treasuries"`
+	FeeCollector sdk.DecCoins    `json:"fee_collector" yaml:"fee_collector"`
+	YieldFarming sdk.DecCoins    `json:"yield_farming" yaml:"yield_farming"`
+}
+
+func (ms MintSplit) String() string {
+	treasuries := make([]string, len(ms.Treasuries))
+	for i, t := range ms.Treasuries {
+		treasuries[i] = fmt.Sprintf("%s: %s", t.Name, t.Amount)
+	}
+	return fmt.Sprintf(`Mint Split:
+  Height:          %d
+  Treasuries:      %s
+  Fee Collector:   %s
+  Yield Farming:   %s`,
+		ms.Height, strings.Join(treasuries, ", "), ms.FeeCollector, ms.YieldFarming)
+}
@@ -0,0 +1,28 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+)
+
+func TestTreasuriesValidate(t *testing.T) {
+	require.NoError(t, Treasuries{}.Validate())
+	require.NoError(t, Treasuries{
+		{Name: CommunityPoolTreasury, Proportion: sdk.NewDecWithPrec(6, 1)},
+		{Name: EcosystemFundName, Proportion: sdk.NewDecWithPrec(4, 1)},
+	}.Validate())
+
+	require.Error(t, Treasuries{
+		{Name: CommunityPoolTreasury, Proportion: sdk.NewDecWithPrec(6, 1)},
+		{Name: EcosystemFundName, Proportion: sdk.NewDecWithPrec(5, 1)},
+	}.Validate(), "total proportion above one")
+	require.Error(t, Treasuries{
+		{Name: EcosystemFundName, Proportion: sdk.NewDecWithPrec(1, 1)},
+		{Name: EcosystemFundName, Proportion: sdk.NewDecWithPrec(1, 1)},
+	}.Validate(), "duplicate treasury")
+	require.Error(t, Treasuries{{Name: " ", Proportion: sdk.NewDecWithPrec(1, 1)}}.Validate())
+	require.Error(t, Treasuries{{Name: EcosystemFundName, Proportion: sdk.NewDec(-1)}}.Validate())
+}
@@ -7,6 +7,7 @@ import (
 	tmkv "github.com/okex/exchain/libs/tendermint/libs/kv"
 
 	"github.com/okex/exchain/libs/cosmos-sdk/codec"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
 	"github.com/okex/exchain/libs/cosmos-sdk/x/mint/internal/types"
 )
 
@@ -18,6 +19,21 @@ func DecodeStore(cdc *codec.Codec, kvA, kvB tmkv.Pair) string {
 		cdc.MustUnmarshalBinaryLengthPrefixed(kvA.Value, &minterA)
 		cdc.MustUnmarshalBinaryLengthPrefixed(kvB.Value, &minterB)
 		return fmt.Sprintf("%v\n%v", minterA, minterB)
+	case bytes.HasPrefix(kvA.Key, types.MintSplitKeyPrefix):
+		var splitA, splitB types.MintSplit
+		cdc.MustUnmarshalBinaryLengthPrefixed(kvA.Value, &splitA)
+		cdc.MustUnmarshalBinaryLengthPrefixed(kvB.Value, &splitB)
+		return fmt.Sprintf("%v\n%v", splitA, splitB)
+	case bytes.Equal(kvA.Key, types.BurnedFeesKey):
+		var burnedA, burnedB sdk.DecCoins
+		cdc.MustUnmarshalBinaryLengthPrefixed(kvA.Value, &burnedA)
+		cdc.MustUnmarshalBinaryLengthPrefixed(kvB.Value, &burnedB)
+		return fmt.Sprintf("%v\n%v", burnedA, burnedB)
+	case bytes.HasPrefix(kvA.Key, types.MintedPerBlockKeyPrefix):
+		var mintedA, mintedB types.MintedPerBlock
+		cdc.MustUnmarshalBinaryLengthPrefixed(kvA.Value, &mintedA)
+		cdc.MustUnmarshalBinaryLengthPrefixed(kvB.Value, &mintedB)
+		return fmt.Sprintf("%v\n%v", mintedA, mintedB)
 	default:
 		panic(fmt.Sprintf("invalid mint key %X", kvA.Key))
 	}
@@ -0,0 +1,117 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// JumpDestCache keeps the JUMPDEST analysis of the deployed contracts by code
+// hash, so that it is shared by the EVMs of all the transactions instead of
+// being redone by each of them. It is safe for concurrent use.
+type JumpDestCache struct {
+	cache *lru.Cache
+}
+
+// NewJumpDestCache creates a JUMPDEST analysis cache of the given number of
+// contracts.
+func NewJumpDestCache(size int) (*JumpDestCache, error) {
+	cache, err := lru.New(size)
+	if err != nil {
+		return nil, err
+	}
+	return &JumpDestCache{cache: cache}, nil
+}
+
+func (c *JumpDestCache) get(codeHash common.Hash) (bitvec, bool) {
+	if c == nil {
+		return nil, false
+	}
+	if analysis, ok := c.cache.Get(codeHash); ok {
+		return analysis.(bitvec), true
+	}
+	return nil, false
+}
+
+func (c *JumpDestCache) add(codeHash common.Hash, analysis bitvec) {
+	if c != nil {
+		c.cache.Add(codeHash, analysis)
+	}
+}
+
+// cacheablePrecompiles are the precompiled contracts whose results are worth
+// caching: they are pure and cost much more than hashing their input.
+var cacheablePrecompiles = map[common.Address]bool{
+	common.BytesToAddress([]byte{1}): true, // ecrecover
+	common.BytesToAddress([]byte{5}): true, // bigModExp
+	common.BytesToAddress([]byte{6}): true, // bn256Add
+	common.BytesToAddress([]byte{7}): true, // bn256ScalarMul
+	common.BytesToAddress([]byte{8}): true, // bn256Pairing
+	common.BytesToAddress([]byte{9}): true, // blake2F
+}
+
+type precompileKey struct {
+	addr  common.Address
+	input common.Hash
+}
+
+type precompileResult struct {
+	output []byte
+	err    error
+}
+
+// PrecompileCache memoizes the outputs of the pure precompiled contracts by
+// input, e.g. the ecrecover of the same signatures by several transactions.
+// The gas is still charged as if the contract was run. It is safe for
+// concurrent use.
+type PrecompileCache struct {
+	cache *lru.Cache
+}
+
+// NewPrecompileCache creates a precompiled contract cache of the given number
+// of results.
+func NewPrecompileCache(size int) (*PrecompileCache, error) {
+	cache, err := lru.New(size)
+	if err != nil {
+		return nil, err
+	}
+	return &PrecompileCache{cache: cache}, nil
+}
+
+// Purge drops the cached results.
+func (c *PrecompileCache) Purge() {
+	c.cache.Purge()
+}
+
+// run returns the cached output of the precompiled contract for the input, or
+// runs the contract and caches its output.
+func (c *PrecompileCache) run(addr common.Address, p PrecompiledContract, input []byte) ([]byte, error) {
+	if c == nil || !cacheablePrecompiles[addr] {
+		return p.Run(input)
+	}
+
+	key := precompileKey{addr: addr, input: crypto.Keccak256Hash(input)}
+	if result, ok := c.cache.Get(key); ok {
+		res := result.(precompileResult)
+		return common.CopyBytes(res.output), res.err
+	}
+	output, err := p.Run(input)
+	c.cache.Add(key, precompileResult{output: common.CopyBytes(output), err: err})
+	return output, err
+}
@@ -48,6 +48,7 @@ type Client interface {
 	BeginBlockSync(types.RequestBeginBlock) (*types.ResponseBeginBlock, error)
 	EndBlockSync(types.RequestEndBlock) (*types.ResponseEndBlock, error)
 	ParallelTxs([][]byte) []*types.ResponseDeliverTx
+	PreDeliverTxs([][]byte)
 }
 
 //----------------------------------------
@@ -227,6 +227,9 @@ func (cli *grpcClient) ParallelTxs(txs [][]byte) []*types.ResponseDeliverTx {
 	return nil
 }
 
+func (cli *grpcClient) PreDeliverTxs(_ [][]byte) {
+}
+
 func (cli *grpcClient) finishAsyncCall(req *types.Request, res *types.Response) *ReqRes {
 	reqres := NewReqRes(req)
 	reqres.Response = res // Set response
@@ -94,12 +94,16 @@ func (app *localClient) DeliverTxAsync(params types.RequestDeliverTx) *ReqRes {
 }
 
 func (app *localClient) CheckTxAsync(req types.RequestCheckTx) *ReqRes {
+	res, rejected := app.preCheckTx(req)
+
 	if !types.GetDisableABCIQueryMutex() {
 		app.mtx.Lock()
 		defer app.mtx.Unlock()
 	}
 
-	res := app.Application.CheckTx(req)
+	if !rejected {
+		res = app.Application.CheckTx(req)
+	}
 	return app.callback(
 		types.ToRequestCheckTx(req),
 		types.ToResponseCheckTx(res),
@@ -168,6 +172,12 @@ func (app *localClient) ParallelTxs(txs [][]byte) []*types.ResponseDeliverTx {
 	return app.Application.ParallelTxs(txs)
 }
 
+func (app *localClient) PreDeliverTxs(txs [][]byte) {
+	app.mtx.Lock()
+	defer app.mtx.Unlock()
+	app.Application.PreDeliverTxs(txs)
+}
+
 //-------------------------------------------------------
 
 func (app *localClient) FlushSync() error {
@@ -204,15 +214,31 @@ func (app *localClient) DeliverTxSync(req types.RequestDeliverTx) (*types.Respon
 }
 
 func (app *localClient) CheckTxSync(req types.RequestCheckTx) (*types.ResponseCheckTx, error) {
+	res, rejected := app.preCheckTx(req)
+	if rejected {
+		return &res, nil
+	}
+
 	if !types.GetDisableABCIQueryMutex() {
 		app.mtx.Lock()
 		defer app.mtx.Unlock()
 	}
 
-	res := app.Application.CheckTx(req)
+	res = app.Application.CheckTx(req)
 	return &res, nil
 }
 
+// preCheckTx runs the stateless checks of a new tx out of the app mutex, so that they run concurrently
+// across the txs and only the stateful checks are serialized. It returns true if the tx is rejected.
+func (app *localClient) preCheckTx(req types.RequestCheckTx) (types.ResponseCheckTx, bool) {
+	if !types.GetEnableConcurrentCheckTx() || req.Type != types.CheckTxType_New {
+		return types.ResponseCheckTx{}, false
+	}
+
+	res := app.Application.PreCheckTx(req)
+	return res, !res.IsOK()
+}
+
 func (app *localClient) QuerySync(req types.RequestQuery) (*types.ResponseQuery, error) {
 	if !types.GetDisableABCIQueryMutex() {
 		app.mtx.Lock()
@@ -270,6 +270,9 @@ func (cli *socketClient) ParallelTxs(_ [][]byte) []*types.ResponseDeliverTx {
 	return nil
 }
 
+func (cli *socketClient) PreDeliverTxs(_ [][]byte) {
+}
+
 //----------------------------------------
 
 func (cli *socketClient) FlushSync() error {
@@ -148,6 +148,13 @@ func (app *PersistentKVStoreApplication) ParallelTxs(_ [][]byte) []*types.Respon
 	return nil
 }
 
+func (app *PersistentKVStoreApplication) PreDeliverTxs(_ [][]byte) {
+}
+
+func (app *PersistentKVStoreApplication) PreCheckTx(req types.RequestCheckTx) types.ResponseCheckTx {
+	return app.app.PreCheckTx(req)
+}
+
 //---------------------------------------------
 // update validators
 
@@ -15,7 +15,8 @@ type Application interface {
 	Query(RequestQuery) ResponseQuery             // Query for state
 
 	// Mempool Connection
-	CheckTx(RequestCheckTx) ResponseCheckTx // Validate a tx for the mempool
+	CheckTx(RequestCheckTx) ResponseCheckTx    // Validate a tx for the mempool
+	PreCheckTx(RequestCheckTx) ResponseCheckTx // Run the stateless checks of a tx, may be called concurrently
 
 	// Consensus Connection
 	InitChain(RequestInitChain) ResponseInitChain    // Initialize blockchain w validators/other info from TendermintCore
@@ -25,6 +26,7 @@ type Application interface {
 
 	Commit(RequestCommit) ResponseCommit             // Commit the state and return the application Merkle root hash
 	ParallelTxs(txs [][]byte) []*ResponseDeliverTx
+	PreDeliverTxs(txs [][]byte)
 }
 
 //-------------------------------------------------------
@@ -79,6 +81,13 @@ func (a BaseApplication) ParallelTxs(_ [][]byte) []*ResponseDeliverTx {
 	return nil
 }
 
+func (a BaseApplication) PreDeliverTxs(_ [][]byte) {
+}
+
+func (BaseApplication) PreCheckTx(req RequestCheckTx) ResponseCheckTx {
+	return ResponseCheckTx{Code: CodeTypeOK}
+}
+
 //-------------------------------------------------------
 
 // GRPCApplication is a GRPC wrapper for Application
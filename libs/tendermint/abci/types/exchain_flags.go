@@ -2,11 +2,13 @@ package types
 
 var disableABCIQueryMutex bool
 var disableCheckTx bool
+var enableConcurrentCheckTx bool
 
 const (
-	FlagCloseMutex            = "close-mutex"
-	FlagDisableABCIQueryMutex = "disable-abci-query-mutex"
-	FlagDisableCheckTx        = "disable-checktx"
+	FlagCloseMutex              = "close-mutex"
+	FlagDisableABCIQueryMutex   = "disable-abci-query-mutex"
+	FlagDisableCheckTx          = "disable-checktx"
+	FlagEnableConcurrentCheckTx = "enable-concurrent-checktx"
 )
 
 func GetDisableABCIQueryMutex() bool {
@@ -24,3 +26,11 @@ func GetDisableCheckTx() bool {
 func SetDisableCheckTx(isRemove bool) {
 	disableCheckTx = isRemove
 }
+
+func GetEnableConcurrentCheckTx() bool {
+	return enableConcurrentCheckTx
+}
+
+func SetEnableConcurrentCheckTx(enable bool) {
+	enableConcurrentCheckTx = enable
+}
@@ -30,6 +30,10 @@ func AddNodeFlags(cmd *cobra.Command) {
 		"priv_validator_laddr",
 		config.PrivValidatorListenAddr,
 		"Socket address to listen on for connections from external priv_validator process")
+	cmd.Flags().String(
+		"priv_validator_secondary_laddr",
+		config.PrivValidatorSecondaryListenAddr,
+		"Socket address to listen on for connections from a secondary external priv_validator process, used once the first one can't be reached")
 
 	// node flags
 	cmd.Flags().Bool("fast_sync", config.FastSyncMode, "Fast blockchain syncing")
@@ -148,6 +152,26 @@ func AddNodeFlags(cmd *cobra.Command) {
 		config.Mempool.PendingPoolMaxTxPerAddress,
 		"Maximum number of transactions per address in the pending pool",
 	)
+	cmd.Flags().Bool(
+		"mempool.enable_sender_reputation",
+		config.Mempool.EnableSenderReputation,
+		"Score the senders by the failures and the evictions of their txs, graylisting the abusive ones",
+	)
+	cmd.Flags().Int(
+		"mempool.sender_score_half_life",
+		config.Mempool.SenderScoreHalfLife,
+		"The time period in second for the score of a sender to decay by half",
+	)
+	cmd.Flags().Int(
+		"mempool.sender_graylist_score",
+		config.Mempool.SenderGraylistScore,
+		"The score graylisting a sender, a failed tx scores 1 and an evicted tx 2. Past half of it the sender is deprioritized",
+	)
+	cmd.Flags().Int(
+		"mempool.sender_graylist_period",
+		config.Mempool.SenderGraylistPeriod,
+		"The time period in second the txs of a graylisted sender are rejected",
+	)
 
 	// db flags
 	cmd.Flags().String(
@@ -209,6 +209,10 @@ type BaseConfig struct { //nolint: maligned
 	// connections from an external PrivValidator process
 	PrivValidatorListenAddr string `mapstructure:"priv_validator_laddr"`
 
+	// TCP or UNIX socket address of a secondary external PrivValidator holding the same key,
+	// which signs once the first one can't be reached
+	PrivValidatorSecondaryListenAddr string `mapstructure:"priv_validator_secondary_laddr"`
+
 	// A JSON file containing the private key to use for p2p authenticated encryption
 	NodeKey string `mapstructure:"node_key_file"`
 
@@ -298,6 +302,9 @@ func (cfg BaseConfig) ValidateBasic() error {
 	default:
 		return errors.New("unknown log_format (must be 'plain' or 'json')")
 	}
+	if cfg.PrivValidatorSecondaryListenAddr != "" && cfg.PrivValidatorListenAddr == "" {
+		return errors.New("priv_validator_secondary_laddr requires priv_validator_laddr")
+	}
 	return nil
 }
 
@@ -677,6 +684,10 @@ type MempoolConfig struct {
 	PendingPoolPeriod          int    `mapstructure:"pending_pool_period"`
 	PendingPoolReserveBlocks   int    `mapstructure:"pending_pool_reserve_blocks"`
 	PendingPoolMaxTxPerAddress int    `mapstructure:"pending_pool_max_tx_per_address"`
+	EnableSenderReputation     bool   `mapstructure:"enable_sender_reputation"`
+	SenderScoreHalfLife        int    `mapstructure:"sender_score_half_life"`
+	SenderGraylistScore        int    `mapstructure:"sender_graylist_score"`
+	SenderGraylistPeriod       int    `mapstructure:"sender_graylist_period"`
 }
 
 // DefaultMempoolConfig returns a default configuration for the Tendermint mempool
@@ -700,6 +711,9 @@ func DefaultMempoolConfig() *MempoolConfig {
 		PendingPoolPeriod:          3,
 		PendingPoolReserveBlocks:   100,
 		PendingPoolMaxTxPerAddress: 100,
+		SenderScoreHalfLife:        300,
+		SenderGraylistScore:        20,
+		SenderGraylistPeriod:       600,
 	}
 }
 
@@ -741,6 +755,17 @@ func (cfg *MempoolConfig) ValidateBasic() error {
 	if cfg.ForceRecheckGap <= 0 {
 		return errors.New("force_recheck_gap can't be negative or zero")
 	}
+	if cfg.EnableSenderReputation {
+		if cfg.SenderScoreHalfLife <= 0 {
+			return errors.New("sender_score_half_life can't be negative or zero")
+		}
+		if cfg.SenderGraylistScore <= 0 {
+			return errors.New("sender_graylist_score can't be negative or zero")
+		}
+		if cfg.SenderGraylistPeriod < 0 {
+			return errors.New("sender_graylist_period can't be negative")
+		}
+	}
 	return nil
 }
 
@@ -803,6 +828,9 @@ type ConsensusConfig struct {
 	// EmptyBlocks mode and possible interval between empty blocks
 	CreateEmptyBlocks         bool          `mapstructure:"create_empty_blocks"`
 	CreateEmptyBlocksInterval time.Duration `mapstructure:"create_empty_blocks_interval"`
+	// Do not make a block only to sign the app hash changed by the last block, the blocks are only
+	// made for txs. The app hash of a block is then signed by the next block with txs.
+	SkipProofBlocks bool `mapstructure:"skip_proof_blocks"`
 
 	// Reactor sleep duration parameters
 	PeerGossipSleepDuration     time.Duration `mapstructure:"peer_gossip_sleep_duration"`
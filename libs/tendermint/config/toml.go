@@ -128,6 +128,10 @@ priv_validator_state_file = "{{ js .BaseConfig.PrivValidatorState }}"
 # connections from an external PrivValidator process
 priv_validator_laddr = "{{ .BaseConfig.PrivValidatorListenAddr }}"
 
+# TCP or UNIX socket address of a secondary external PrivValidator process holding the same key,
+# which signs once the first one can't be reached
+priv_validator_secondary_laddr = "{{ .BaseConfig.PrivValidatorSecondaryListenAddr }}"
+
 # Path to the JSON file containing the private key to use for node authentication in the p2p protocol
 node_key_file = "{{ js .BaseConfig.NodeKey }}"
 
@@ -325,6 +329,14 @@ sort_tx_by_gp = {{ .Mempool.SortTxByGp }}
 # Minimum price bump percentage to replace an already existing transaction (nonce)
 tx_price_bump = {{ .Mempool.TxPriceBump }}
 
+# Score the senders by the failures (1 point) and the evictions (2 points) of their txs, the score decays by
+# half every half-life. The senders reaching the graylist score are rejected for the graylist period, the ones
+# past half of it are rejected while the mempool is more than half full. The periods are in seconds.
+enable_sender_reputation = {{ .Mempool.EnableSenderReputation }}
+sender_score_half_life = {{ .Mempool.SenderScoreHalfLife }}
+sender_graylist_score = {{ .Mempool.SenderGraylistScore }}
+sender_graylist_period = {{ .Mempool.SenderGraylistPeriod }}
+
 ##### fast sync configuration options #####
 [fastsync]
 
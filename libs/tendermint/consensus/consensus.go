@@ -907,11 +907,14 @@ func (cs *State) enterNewRound(height int64, round int) {
 }
 
 // needProofBlock returns true on the first height (so the genesis app hash is signed right away)
-// and where the last block (height-1) caused the app hash to change
+// and where the last block (height-1) caused the app hash to change, unless the proof blocks are skipped
 func (cs *State) needProofBlock(height int64) bool {
 	if height == types.GetStartBlockHeight()+1 {
 		return true
 	}
+	if cs.config.SkipProofBlocks {
+		return false
+	}
 
 	lastBlockMeta := cs.blockStore.LoadBlockMeta(height - 1)
 	if lastBlockMeta == nil {
@@ -44,6 +44,28 @@ func TestMempoolNoProgressUntilTxsAvailable(t *testing.T) {
 	ensureNoNewEventOnChannel(newBlockCh)
 }
 
+func TestMempoolNoProofBlockWhenSkipped(t *testing.T) {
+	config := ResetConfig("consensus_mempool_skip_proof_blocks_test")
+	defer os.RemoveAll(config.RootDir)
+	config.Consensus.CreateEmptyBlocks = false
+	config.Consensus.SkipProofBlocks = true
+	state, privVals := randGenesisState(1, false, 10)
+	cs := newStateWithConfig(config, state, privVals[0], NewCounterApplication())
+	assertMempool(cs.txNotifier).EnableTxsAvailable()
+	height, round := cs.Height, cs.Round
+	newBlockCh := subscribe(cs.eventBus, types.EventQueryNewBlock)
+	startTestRound(cs, height, round)
+
+	ensureNewEventOnChannel(newBlockCh) // first block gets committed
+	ensureNoNewEventOnChannel(newBlockCh)
+	deliverTxsRange(cs, 0, 1)
+	ensureNewEventOnChannel(newBlockCh)   // commit txs
+	ensureNoNewEventOnChannel(newBlockCh) // the updated app hash waits for the next txs
+	deliverTxsRange(cs, 1, 2)
+	ensureNewEventOnChannel(newBlockCh) // commit txs
+	ensureNoNewEventOnChannel(newBlockCh)
+}
+
 func TestMempoolProgressAfterCreateEmptyBlocksInterval(t *testing.T) {
 	config := ResetConfig("consensus_mempool_txs_available_test")
 	defer os.RemoveAll(config.RootDir)
@@ -145,7 +167,7 @@ func TestMempoolRmBadTx(t *testing.T) {
 	resDeliver := app.DeliverTx(abci.RequestDeliverTx{Tx: txBytes})
 	assert.False(t, resDeliver.IsErr(), fmt.Sprintf("expected no error. got %v", resDeliver))
 
-	resCommit := app.Commit()
+	resCommit := app.Commit(abci.RequestCommit{})
 	assert.True(t, len(resCommit.Data) > 0)
 
 	emptyMempoolCh := make(chan struct{})
@@ -243,7 +265,7 @@ func txAsUint64(tx []byte) uint64 {
 	return binary.BigEndian.Uint64(tx8)
 }
 
-func (app *CounterApplication) Commit() abci.ResponseCommit {
+func (app *CounterApplication) Commit(req abci.RequestCommit) abci.ResponseCommit {
 	app.mempoolTxCount = app.txCount
 	if app.txCount == 0 {
 		return abci.ResponseCommit{}
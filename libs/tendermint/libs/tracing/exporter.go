@@ -0,0 +1,202 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	exportQueueSize = 4096
+	exportBatchSize = 512
+	exportInterval  = 5 * time.Second
+
+	spanKindInternal = 1
+	statusCodeError  = 2
+)
+
+// otlpExporter posts the ended spans in batches to an OTLP/HTTP collector, in the json encoding of the
+// protocol. The spans are dropped when the collector can't keep up, the tracing never blocks a request.
+type otlpExporter struct {
+	url         string
+	serviceName string
+	client      *http.Client
+	spans       chan *Span
+}
+
+func newOTLPExporter(endpoint, serviceName string) *otlpExporter {
+	return &otlpExporter{
+		url:         endpoint,
+		serviceName: serviceName,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		spans:       make(chan *Span, exportQueueSize),
+	}
+}
+
+func (e *otlpExporter) export(s *Span) {
+	if e == nil {
+		return
+	}
+	select {
+	case e.spans <- s:
+	default:
+	}
+}
+
+func (e *otlpExporter) run() {
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, exportBatchSize)
+	for {
+		select {
+		case s := <-e.spans:
+			batch = append(batch, s)
+			if len(batch) < exportBatchSize {
+				continue
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		}
+		if err := e.post(batch); err != nil {
+			log.Println("failed to export spans:", err)
+		}
+		batch = batch[:0]
+	}
+}
+
+func (e *otlpExporter) post(batch []*Span) error {
+	bz, err := json.Marshal(e.request(batch))
+	if err != nil {
+		return err
+	}
+	resp, err := e.client.Post(e.url, "application/json", bytes.NewReader(bz))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("collector responded %s", resp.Status)
+	}
+	return nil
+}
+
+// the json encoding of the OTLP trace service request, see opentelemetry-proto/collector/trace/v1
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            *otlpStatus    `json:"status,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	BoolValue   *bool   `json:"boolValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"`
+}
+
+func (e *otlpExporter) request(batch []*Span) otlpRequest {
+	spans := make([]otlpSpan, 0, len(batch))
+	for _, s := range batch {
+		spans = append(spans, s.otlp())
+	}
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: []otlpKeyValue{newKeyValue("service.name", e.serviceName)}},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: "exchain"}, Spans: spans}},
+	}}}
+}
+
+func (s *Span) otlp() otlpSpan {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	span := otlpSpan{
+		TraceID:           hex.EncodeToString(s.traceID[:]),
+		SpanID:            hex.EncodeToString(s.spanID[:]),
+		Name:              s.name,
+		Kind:              spanKindInternal,
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+	}
+	if s.parentID != [8]byte{} {
+		span.ParentSpanID = hex.EncodeToString(s.parentID[:])
+	}
+	for _, attr := range s.attrs {
+		span.Attributes = append(span.Attributes, newKeyValue(attr.key, attr.value))
+	}
+	if s.err != "" {
+		span.Status = &otlpStatus{Code: statusCodeError, Message: s.err}
+	}
+	return span
+}
+
+func newKeyValue(key string, value interface{}) otlpKeyValue {
+	kv := otlpKeyValue{Key: key}
+	switch v := value.(type) {
+	case bool:
+		kv.Value.BoolValue = &v
+	case int:
+		i := strconv.Itoa(v)
+		kv.Value.IntValue = &i
+	case int64:
+		i := strconv.FormatInt(v, 10)
+		kv.Value.IntValue = &i
+	case uint64:
+		i := strconv.FormatUint(v, 10)
+		kv.Value.IntValue = &i
+	case string:
+		kv.Value.StringValue = &v
+	default:
+		str := ""
+		if s, ok := v.(interface{ String() string }); ok {
+			str = s.String()
+		}
+		kv.Value.StringValue = &str
+	}
+	return kv
+}
@@ -0,0 +1,225 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	mrand "math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	gorid "github.com/okex/exchain/libs/goroutine"
+)
+
+const (
+	FlagOTLPEndpoint = "tracing-otlp-endpoint"
+	FlagSampleRatio  = "tracing-sample-ratio"
+)
+
+var (
+	enabled     int32
+	sampleRatio float64
+	exporter    *otlpExporter
+
+	// the active span of the goroutines serving a traced request, by goroutine id
+	activeSpans   sync.Map
+	activeSpanNum int64
+)
+
+// Init enables the tracing, the spans of the sampled requests are exported to the OTLP/HTTP collector at endpoint.
+// The tracing stays disabled with an empty endpoint.
+func Init(endpoint, serviceName string, ratio float64) {
+	if endpoint == "" || ratio <= 0 {
+		return
+	}
+	sampleRatio = ratio
+	exporter = newOTLPExporter(endpoint, serviceName)
+	go exporter.run()
+	atomic.StoreInt32(&enabled, 1)
+}
+
+// Enabled returns true when the spans are recorded
+func Enabled() bool {
+	return atomic.LoadInt32(&enabled) == 1
+}
+
+type attribute struct {
+	key   string
+	value interface{}
+}
+
+// Span is a timed operation of a traced request. All the methods of a nil span are no-ops, so the callers
+// don't need to check whether the request is traced.
+type Span struct {
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	name     string
+	start    time.Time
+	end      time.Time
+	// the time of the last Add, which ends the aggregating spans
+	lastAdd time.Time
+	err     string
+
+	mtx   sync.Mutex
+	attrs []attribute
+	// the children aggregating many operations, by name, they are ended along with the span
+	aggregates map[string]*Span
+
+	gid  string
+	prev *Span
+}
+
+// StartRootSpan starts the span of a request, the request is traced as per the sample ratio. The span is
+// the active one of the goroutine until it ends.
+func StartRootSpan(name string) *Span {
+	if !Enabled() || (sampleRatio < 1 && mrand.Float64() >= sampleRatio) {
+		return nil
+	}
+	s := newSpan(name, nil)
+	s.activate(gorid.GoRId.String())
+	return s
+}
+
+// StartSpan starts a child of the active span of the goroutine. It returns nil when the goroutine doesn't
+// serve a traced request.
+func StartSpan(name string) *Span {
+	if !Enabled() || atomic.LoadInt64(&activeSpanNum) == 0 {
+		return nil
+	}
+	gid := gorid.GoRId.String()
+	parent, ok := activeSpans.Load(gid)
+	if !ok {
+		return nil
+	}
+	s := newSpan(name, parent.(*Span))
+	s.activate(gid)
+	return s
+}
+
+func newSpan(name string, parent *Span) *Span {
+	s := &Span{name: name, start: time.Now()}
+	if parent != nil {
+		s.traceID = parent.traceID
+		s.parentID = parent.spanID
+	} else {
+		rand.Read(s.traceID[:])
+	}
+	rand.Read(s.spanID[:])
+	return s
+}
+
+func (s *Span) activate(gid string) {
+	s.gid = gid
+	if prev, ok := activeSpans.Load(gid); ok {
+		s.prev = prev.(*Span)
+	} else {
+		atomic.AddInt64(&activeSpanNum, 1)
+	}
+	activeSpans.Store(gid, s)
+}
+
+// SetAttribute sets an attribute of the span, the value is a string, a bool or an integer
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mtx.Lock()
+	s.attrs = append(s.attrs, attribute{key, value})
+	s.mtx.Unlock()
+}
+
+// SetError marks the span as failed
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mtx.Lock()
+	s.err = err.Error()
+	s.mtx.Unlock()
+}
+
+// Aggregate returns the child of the span aggregating the operations named name, e.g. the reads of a
+// store. The child is created on the first call and ended along with the span, at its last Add if any.
+func (s *Span) Aggregate(name string) *Span {
+	if s == nil {
+		return nil
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if child, ok := s.aggregates[name]; ok {
+		return child
+	}
+	if s.aggregates == nil {
+		s.aggregates = make(map[string]*Span)
+	}
+	child := newSpan(name, s)
+	s.aggregates[name] = child
+	return child
+}
+
+// Add increases the integer attribute key of the span by n
+func (s *Span) Add(key string, n int64) {
+	if s == nil {
+		return
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.lastAdd = time.Now()
+	for i := range s.attrs {
+		if s.attrs[i].key == key {
+			s.attrs[i].value = s.attrs[i].value.(int64) + n
+			return
+		}
+	}
+	s.attrs = append(s.attrs, attribute{key, n})
+}
+
+// End ends the span and queues it to be exported, the active span of the goroutine goes back to its parent
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	now := time.Now()
+	if s.prev != nil {
+		activeSpans.Store(s.gid, s.prev)
+	} else {
+		activeSpans.Delete(s.gid)
+		atomic.AddInt64(&activeSpanNum, -1)
+	}
+
+	s.mtx.Lock()
+	s.end = now
+	aggregates := s.aggregates
+	s.mtx.Unlock()
+	for _, child := range aggregates {
+		child.mtx.Lock()
+		if child.lastAdd.IsZero() {
+			child.end = now
+		} else {
+			child.end = child.lastAdd
+		}
+		child.mtx.Unlock()
+		exporter.export(child)
+	}
+	exporter.export(s)
+}
+
+type spanKey struct{}
+
+// ContextWithSpan returns a copy of ctx carrying the span
+func ContextWithSpan(ctx context.Context, s *Span) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, spanKey{}, s)
+}
+
+// SpanFromContext returns the span carried by ctx, nil if none
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
@@ -0,0 +1,119 @@
+package tracing
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func enableForTest(t *testing.T, endpoint string) {
+	sampleRatio = 1
+	exporter = newOTLPExporter(endpoint, "test")
+	atomic.StoreInt32(&enabled, 1)
+	t.Cleanup(func() {
+		atomic.StoreInt32(&enabled, 0)
+		exporter = nil
+	})
+}
+
+func TestSpanDisabled(t *testing.T) {
+	require.Nil(t, StartRootSpan("root"))
+	span := StartSpan("child")
+	require.Nil(t, span)
+
+	// the methods of a nil span are no-ops
+	span.SetAttribute("key", "value")
+	span.SetError(errors.New("error"))
+	span.Aggregate("store").Add("reads", 1)
+	span.End()
+}
+
+func TestSpanParent(t *testing.T) {
+	enableForTest(t, "")
+
+	// no child span out of a traced request
+	require.Nil(t, StartSpan("child"))
+
+	root := StartRootSpan("root")
+	require.NotNil(t, root)
+	child := StartSpan("child")
+	require.Equal(t, root.traceID, child.traceID)
+	require.Equal(t, root.spanID, child.parentID)
+
+	grandChild := StartSpan("grandchild")
+	require.Equal(t, child.spanID, grandChild.parentID)
+	grandChild.End()
+
+	// the child is the active span again
+	sibling := StartSpan("sibling")
+	require.Equal(t, child.spanID, sibling.parentID)
+	sibling.End()
+	child.End()
+
+	// the other goroutines don't serve the request
+	done := make(chan *Span)
+	go func() { done <- StartSpan("other") }()
+	require.Nil(t, <-done)
+
+	root.End()
+	require.Nil(t, StartSpan("child"))
+	require.Equal(t, int64(0), atomic.LoadInt64(&activeSpanNum))
+}
+
+func TestSpanExport(t *testing.T) {
+	var received otlpRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bz, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(bz, &received))
+	}))
+	defer server.Close()
+	enableForTest(t, server.URL)
+
+	root := StartRootSpan("eth_getBalance")
+	root.SetAttribute("height", int64(10))
+	store := root.Aggregate("store evm")
+	store.Add("reads", 1)
+	store.Add("reads", 2)
+	root.Add("txs", 1)
+	root.SetError(errors.New("not found"))
+	time.Sleep(time.Millisecond)
+	root.End()
+
+	// the aggregating span ends at its last operation, the span at its end
+	require.Equal(t, store.lastAdd, store.end)
+	require.True(t, root.end.After(root.lastAdd))
+	require.True(t, store.end.Before(root.end))
+
+	var batch []*Span
+	for len(exporter.spans) > 0 {
+		batch = append(batch, <-exporter.spans)
+	}
+	require.Len(t, batch, 2)
+	require.NoError(t, exporter.post(batch))
+
+	require.Len(t, received.ResourceSpans, 1)
+	require.Equal(t, "service.name", received.ResourceSpans[0].Resource.Attributes[0].Key)
+	spans := received.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 2)
+
+	storeSpan, rootSpan := spans[0], spans[1]
+	require.Equal(t, "store evm", storeSpan.Name)
+	require.Equal(t, hex.EncodeToString(root.spanID[:]), storeSpan.ParentSpanID)
+	require.Equal(t, "reads", storeSpan.Attributes[0].Key)
+	require.Equal(t, "3", *storeSpan.Attributes[0].Value.IntValue)
+
+	require.Equal(t, "eth_getBalance", rootSpan.Name)
+	require.Empty(t, rootSpan.ParentSpanID)
+	require.Equal(t, hex.EncodeToString(root.traceID[:]), rootSpan.TraceID)
+	require.Equal(t, "10", *rootSpan.Attributes[0].Value.IntValue)
+	require.Equal(t, statusCodeError, rootSpan.Status.Code)
+}
@@ -22,7 +22,6 @@ import (
 	"github.com/okex/exchain/libs/tendermint/libs/log"
 	tmmath "github.com/okex/exchain/libs/tendermint/libs/math"
 	tmos "github.com/okex/exchain/libs/tendermint/libs/os"
-	"github.com/okex/exchain/libs/tendermint/p2p"
 	"github.com/okex/exchain/libs/tendermint/proxy"
 	"github.com/okex/exchain/libs/tendermint/trace"
 	"github.com/okex/exchain/libs/tendermint/types"
@@ -92,6 +91,8 @@ type CListMempool struct {
 
 	txInfoparser TxInfoParser
 	checkCnt     int64
+
+	reputation *senderReputation
 }
 
 var _ Mempool = &CListMempool{}
@@ -129,6 +130,14 @@ func NewCListMempool(
 	}
 	mempool.addressRecord = newAddressRecord()
 
+	if config.EnableSenderReputation {
+		mempool.reputation = newSenderReputation(
+			time.Duration(config.SenderScoreHalfLife)*time.Second,
+			float64(config.SenderGraylistScore),
+			time.Duration(config.SenderGraylistPeriod)*time.Second,
+		)
+	}
+
 	if config.EnablePendingPool {
 		mempool.pendingPool = newPendingPool(config.PendingPoolSize, config.PendingPoolPeriod,
 			config.PendingPoolReserveBlocks, config.PendingPoolMaxTxPerAddress)
@@ -306,6 +315,15 @@ func (mem *CListMempool) CheckTx(tx types.Tx, cb func(*abci.Response), txInfo Tx
 		}
 	}
 
+	// the senders with a bad reputation are rejected before the app checks their txs
+	if mem.reputation != nil && mem.txInfoparser != nil {
+		busy := mem.Size()*2 >= cfg.DynamicConfig.GetMempoolSize()
+		if err = mem.reputation.check(mem.txInfoparser.GetRawTxInfo(tx).Sender, busy); err != nil {
+			mem.cache.Remove(tx)
+			return err
+		}
+	}
+
 	// CACHE
 	// Record a new sender for a tx we've already seen.
 	// Note it's possible a tx is still in the cache but no longer in the mempool
@@ -347,7 +365,7 @@ func (mem *CListMempool) CheckTx(tx types.Tx, cb func(*abci.Response), txInfo Tx
 			r.CheckTx.GasWanted = gasUsed
 		}
 	}
-	reqRes.SetCallback(mem.reqResCb(tx, txInfo.SenderID, txInfo.SenderP2PID, cb))
+	reqRes.SetCallback(mem.reqResCb(tx, txInfo, cb))
 	atomic.AddInt64(&mem.checkCnt, 1)
 	return nil
 }
@@ -384,8 +402,7 @@ func (mem *CListMempool) globalCb(req *abci.Request, res *abci.Response) {
 // Used in CheckTx to record PeerID who sent us the tx.
 func (mem *CListMempool) reqResCb(
 	tx []byte,
-	peerID uint16,
-	peerP2PID p2p.ID,
+	txInfo TxInfo,
 	externalCb func(*abci.Response),
 ) func(res *abci.Response) {
 	return func(res *abci.Response) {
@@ -394,7 +411,7 @@ func (mem *CListMempool) reqResCb(
 			panic("recheck cursor is not nil in reqResCb")
 		}
 
-		mem.resCbFirstTime(tx, peerID, peerP2PID, res)
+		mem.resCbFirstTime(tx, txInfo, res)
 
 		// update metrics
 		mem.metrics.Size.Set(float64(mem.Size()))
@@ -429,10 +446,7 @@ func (mem *CListMempool) addAndSortTx(memTx *mempoolTx, info ExTxInfo) error {
 	mem.txsMap.Store(txKey(memTx.tx), e)
 	atomic.AddInt64(&mem.txsBytes, int64(len(memTx.tx)))
 	mem.metrics.TxSizeBytes.Observe(float64(len(memTx.tx)))
-	mem.eventBus.PublishEventPendingTx(types.EventDataTx{TxResult: types.TxResult{
-		Height: memTx.height,
-		Tx:     memTx.tx,
-	}})
+	mem.publishPendingTx(memTx)
 
 	return nil
 }
@@ -451,12 +465,20 @@ func (mem *CListMempool) addTx(memTx *mempoolTx, info ExTxInfo) error {
 	mem.txsMap.Store(txKey(memTx.tx), e)
 	atomic.AddInt64(&mem.txsBytes, int64(len(memTx.tx)))
 	mem.metrics.TxSizeBytes.Observe(float64(len(memTx.tx)))
+	mem.publishPendingTx(memTx)
+
+	return nil
+}
+
+// publishPendingTx publishes the tx to the subscribers of the pending txs, unless it's private
+func (mem *CListMempool) publishPendingTx(memTx *mempoolTx) {
+	if memTx.private {
+		return
+	}
 	mem.eventBus.PublishEventPendingTx(types.EventDataTx{TxResult: types.TxResult{
 		Height: memTx.height,
 		Tx:     memTx.tx,
 	}})
-
-	return nil
 }
 
 // Called from:
@@ -560,8 +582,7 @@ func (mem *CListMempool) consumePendingTx(address string, nonce uint64) {
 // handled by the resCbRecheck callback.
 func (mem *CListMempool) resCbFirstTime(
 	tx []byte,
-	peerID uint16,
-	peerP2PID p2p.ID,
+	txInfo TxInfo,
 	res *abci.Response,
 ) {
 	switch r := res.Value.(type) {
@@ -583,8 +604,9 @@ func (mem *CListMempool) resCbFirstTime(
 				height:    mem.height,
 				gasWanted: r.CheckTx.GasWanted,
 				tx:        tx,
+				private:   txInfo.Private,
 			}
-			memTx.senders.Store(peerID, true)
+			memTx.senders.Store(txInfo.SenderID, true)
 
 			var exTxInfo ExTxInfo
 			if err := json.Unmarshal(r.CheckTx.Data, &exTxInfo); err != nil {
@@ -605,6 +627,7 @@ func (mem *CListMempool) resCbFirstTime(
 				err = mem.addTx(memTx, exTxInfo)
 			}
 
+			mem.reputation.onCheck(exTxInfo.Sender, err != nil)
 			if err == nil {
 				mem.logger.Info("Added good transaction",
 					"tx", txID(tx),
@@ -616,7 +639,7 @@ func (mem *CListMempool) resCbFirstTime(
 			} else {
 				// ignore bad transaction
 				mem.logger.Info("Fail to add transaction into mempool, rejected it",
-					"tx", txID(tx), "peerID", peerP2PID, "res", r, "err", postCheckErr)
+					"tx", txID(tx), "peerID", txInfo.SenderP2PID, "res", r, "err", postCheckErr)
 				mem.metrics.FailedTxs.Add(1)
 				// remove from cache (it might be good later)
 				mem.cache.Remove(tx)
@@ -627,7 +650,14 @@ func (mem *CListMempool) resCbFirstTime(
 		} else {
 			// ignore bad transaction
 			mem.logger.Info("Rejected bad transaction",
-				"tx", txID(tx), "peerID", peerP2PID, "res", r, "err", postCheckErr)
+				"tx", txID(tx), "peerID", txInfo.SenderP2PID, "res", r, "err", postCheckErr)
+			// the app only reports the senders of the failed txs verified by their signature
+			if mem.reputation != nil && len(r.CheckTx.Data) > 0 {
+				var exTxInfo ExTxInfo
+				if err := json.Unmarshal(r.CheckTx.Data, &exTxInfo); err == nil {
+					mem.reputation.onCheck(exTxInfo.Sender, true)
+				}
+			}
 			mem.metrics.FailedTxs.Add(1)
 			// remove from cache (it might be good later)
 			mem.cache.Remove(tx)
@@ -661,6 +691,7 @@ func (mem *CListMempool) resCbRecheck(req *abci.Request, res *abci.Response) {
 		} else {
 			// Tx became invalidated due to newly committed block.
 			mem.logger.Info("Tx is no longer valid", "tx", txID(tx), "res", r, "err", postCheckErr)
+			mem.reputation.onEviction(mem.recheckCursor.Address)
 			// NOTE: we remove tx from the cache because it might be good later
 			mem.removeTx(tx, mem.recheckCursor, true)
 		}
@@ -700,16 +731,23 @@ func (mem *CListMempool) notifyTxsAvailable() {
 	}
 }
 
+// RequestBlock makes the consensus waiting for txs propose a block, even if the mempool is empty.
+// It does nothing when the consensus does not wait for txs.
+func (mem *CListMempool) RequestBlock() {
+	if mem.txsAvailable == nil {
+		return
+	}
+	select {
+	case mem.txsAvailable <- struct{}{}:
+	default:
+	}
+}
+
 // Safe for concurrent use by multiple goroutines.
 func (mem *CListMempool) ReapMaxBytesMaxGas(maxBytes, maxGas int64) types.Txs {
 	mem.updateMtx.RLock()
 	defer mem.updateMtx.RUnlock()
 
-	var (
-		totalBytes int64
-		totalGas   int64
-		totalTxNum int64
-	)
 	// TODO: we will get a performance boost if we have a good estimate of avg
 	// size per tx, and set the initial capacity based off of that.
 	// txs := make([]types.Tx, 0, tmmath.MinInt(mem.txs.Len(), max/mem.avgTxSize))
@@ -718,12 +756,26 @@ func (mem *CListMempool) ReapMaxBytesMaxGas(maxBytes, maxGas int64) types.Txs {
 		mem.logger.Info("ReapMaxBytesMaxGas", "ProposingHeight", mem.height+1,
 			"MempoolTxs", mem.txs.Len(), "ReapTxs", len(txs))
 	}()
+	mem.reap(maxBytes, maxGas, func(e *clist.CElement, _ int64) {
+		txs = append(txs, e.Value.(*mempoolTx).tx)
+	})
+	return txs
+}
+
+// reap visits the txs of the next block in their order in the mempool, with their size in the block. It returns
+// the limit the reaping stopped at, empty if all the txs of the mempool fit in the block. The lock must be held.
+func (mem *CListMempool) reap(maxBytes, maxGas int64, visit func(e *clist.CElement, bytes int64)) string {
+	var (
+		totalBytes int64
+		totalGas   int64
+		totalTxNum int64
+	)
 	for e := mem.txs.Front(); e != nil; e = e.Next() {
 		memTx := e.Value.(*mempoolTx)
 		// Check total size requirement
 		aminoOverhead := types.ComputeAminoOverhead(memTx.tx, 1)
 		if maxBytes > -1 && totalBytes+int64(len(memTx.tx))+aminoOverhead > maxBytes {
-			return txs
+			return ReapLimitBytes
 		}
 		totalBytes += int64(len(memTx.tx)) + aminoOverhead
 		// Check total gas requirement.
@@ -732,18 +784,17 @@ func (mem *CListMempool) ReapMaxBytesMaxGas(maxBytes, maxGas int64) types.Txs {
 		// must be non-negative, it follows that this won't overflow.
 		newTotalGas := totalGas + memTx.gasWanted
 		if maxGas > -1 && newTotalGas > maxGas {
-			return txs
+			return ReapLimitGas
 		}
 		if totalTxNum >= cfg.DynamicConfig.GetMaxTxNumPerBlock() {
-			return txs
+			return ReapLimitTxNum
 		}
 
 		totalTxNum++
 		totalGas = newTotalGas
-		txs = append(txs, memTx.tx)
+		visit(e, int64(len(memTx.tx))+aminoOverhead)
 	}
-
-	return txs
+	return ""
 }
 
 // Safe for concurrent use by multiple goroutines.
@@ -758,6 +809,10 @@ func (mem *CListMempool) ReapMaxTxs(max int) types.Txs {
 	txs := make([]types.Tx, 0, tmmath.MinInt(mem.txs.Len(), max))
 	for e := mem.txs.Front(); e != nil && len(txs) <= max; e = e.Next() {
 		memTx := e.Value.(*mempoolTx)
+		// the private txs aren't listed, they'd be exposed before their block
+		if memTx.private {
+			continue
+		}
 		txs = append(txs, memTx.tx)
 	}
 	return txs
@@ -1012,6 +1067,9 @@ type mempoolTx struct {
 	// ids of peers who've sent us this tx (as a map for quick lookups).
 	// senders: PeerID -> bool
 	senders sync.Map
+
+	// private is true for the txs submitted privately, see TxInfo.Private
+	private bool
 }
 
 // Height returns the height for this transaction
@@ -1130,6 +1188,18 @@ func (mem *CListMempool) SetTxInfoParser(parser TxInfoParser) {
 	mem.txInfoparser = parser
 }
 
+// SenderScores returns the reputation of the senders of txs, the most abusive first. It's empty unless the
+// sender reputation is enabled.
+func (mem *CListMempool) SenderScores() []SenderScore {
+	return mem.reputation.list()
+}
+
+// ClearSenderScore forgets the reputation of the sender, lifting its graylisting, or of all the senders if
+// it's empty. It returns the number of senders cleared.
+func (mem *CListMempool) ClearSenderScore(sender string) int {
+	return mem.reputation.clear(sender)
+}
+
 func (mem *CListMempool) pendingPoolJob() {
 	for addressNonce := range mem.pendingPoolNotify {
 		timeStart := time.Now()
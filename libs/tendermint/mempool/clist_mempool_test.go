@@ -1,6 +1,7 @@
 package mempool
 
 import (
+	"bytes"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/binary"
@@ -331,7 +332,7 @@ func TestSerialReap(t *testing.T) {
 					res.Code, res.Data, res.Log)
 			}
 		}
-		res, err := appConnCon.CommitSync()
+		res, err := appConnCon.CommitSync(abci.RequestCommit{})
 		if err != nil {
 			t.Errorf("client error committing: %v", err)
 		}
@@ -536,7 +537,7 @@ func TestMempoolTxsBytes(t *testing.T) {
 	res, err := appConnCon.DeliverTxSync(abci.RequestDeliverTx{Tx: txBytes})
 	require.NoError(t, err)
 	require.EqualValues(t, 0, res.Code)
-	res2, err := appConnCon.CommitSync()
+	res2, err := appConnCon.CommitSync(abci.RequestCommit{})
 	require.NoError(t, err)
 	require.NotEmpty(t, res2.Data)
 
@@ -953,3 +954,22 @@ func TestAddAndSortTxConcurrency(t *testing.T) {
 	wait.Wait()
 }
 
+func TestPrivateTx(t *testing.T) {
+	app := kvstore.NewApplication()
+	cc := proxy.NewLocalClientCreator(app)
+	mempool, cleanup := newMempoolWithApp(cc)
+	defer cleanup()
+
+	public := checkTxs(t, mempool, 1, UnknownPeerID)
+	private := types.Tx("private tx")
+	require.NoError(t, mempool.CheckTx(private, nil, TxInfo{SenderID: UnknownPeerID, Private: true}))
+	require.Equal(t, 2, mempool.Size())
+
+	// the private tx is proposed but isn't listed
+	require.Len(t, mempool.ReapMaxBytesMaxGas(-1, -1), 2)
+	require.Equal(t, public, mempool.ReapMaxTxs(-1))
+	for e := mempool.TxsFront(); e != nil; e = e.Next() {
+		memTx := e.Value.(*mempoolTx)
+		require.Equal(t, bytes.Equal(memTx.tx, private), memTx.private)
+	}
+}
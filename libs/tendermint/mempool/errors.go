@@ -2,6 +2,7 @@ package mempool
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -39,6 +40,26 @@ func (e ErrMempoolIsFull) Error() string {
 		e.txsBytes, e.maxTxsBytes)
 }
 
+// ErrSenderGraylisted means the sender of the tx is graylisted for the failures and the evictions of its txs
+type ErrSenderGraylisted struct {
+	Sender string
+	Until  time.Time
+}
+
+func (e ErrSenderGraylisted) Error() string {
+	return fmt.Sprintf("sender %s is graylisted until %s", e.Sender, e.Until.UTC().Format(time.RFC3339))
+}
+
+// ErrSenderDeprioritized means the score of the sender of the tx keeps it out of the mempool while it's busy
+type ErrSenderDeprioritized struct {
+	Sender string
+	Score  float64
+}
+
+func (e ErrSenderDeprioritized) Error() string {
+	return fmt.Sprintf("sender %s is deprioritized while the mempool is busy, score %.2f", e.Sender, e.Score)
+}
+
 // ErrPreCheck is returned when tx is too big
 type ErrPreCheck struct {
 	Reason error
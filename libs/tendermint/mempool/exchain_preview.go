@@ -0,0 +1,70 @@
+package mempool
+
+import (
+	"math/big"
+
+	"github.com/okex/exchain/libs/tendermint/libs/clist"
+	"github.com/okex/exchain/libs/tendermint/types"
+)
+
+// the limits the reaping of the txs of a block stops at
+const (
+	ReapLimitBytes = "max block bytes"
+	ReapLimitGas   = "max block gas"
+	ReapLimitTxNum = "max txs per block"
+)
+
+// BlockPreview is the candidate next block the mempool would reap for a proposal
+type BlockPreview struct {
+	// SortedByGasPrice is true if the txs are ordered by gas price, else by arrival
+	SortedByGasPrice bool        `json:"sorted_by_gas_price"`
+	Txs              []PreviewTx `json:"txs"`
+	TotalBytes       int64       `json:"total_bytes"`
+	TotalGas         int64       `json:"total_gas"`
+	// Excluded is the number of the txs of the mempool left out of the block
+	Excluded int `json:"excluded"`
+	// Limit is the limit the block is full at, empty if all the txs of the mempool are included
+	Limit string `json:"limit"`
+}
+
+// PreviewTx is a tx of the candidate next block
+type PreviewTx struct {
+	Tx     types.Tx `json:"tx"`
+	Sender string   `json:"sender"`
+	Nonce  uint64   `json:"nonce"`
+	// GasPrice is nil unless the txs are ordered by gas price or parsed by the app
+	GasPrice *big.Int `json:"gas_price"`
+	// Gas is the gas the tx is accounted for in the block: its simulated gas used when the gas used per block is
+	// limited, else its gas limit
+	Gas   int64 `json:"gas"`
+	Bytes int64 `json:"bytes"`
+}
+
+// PreviewBlock returns the txs the mempool would reap for the next block with the limits, in the order they'd be
+// proposed. Nothing is removed from the mempool.
+func (mem *CListMempool) PreviewBlock(maxBytes, maxGas int64) BlockPreview {
+	mem.updateMtx.RLock()
+	defer mem.updateMtx.RUnlock()
+
+	preview := BlockPreview{SortedByGasPrice: mem.config.SortTxByGp, Txs: []PreviewTx{}}
+	preview.Limit = mem.reap(maxBytes, maxGas, func(e *clist.CElement, bytes int64) {
+		memTx := e.Value.(*mempoolTx)
+		tx := PreviewTx{
+			Tx:       memTx.tx,
+			Sender:   e.Address,
+			Nonce:    e.Nonce,
+			GasPrice: e.GasPrice,
+			Gas:      memTx.gasWanted,
+			Bytes:    bytes,
+		}
+		if !mem.config.SortTxByGp && mem.txInfoparser != nil {
+			info := mem.txInfoparser.GetRawTxInfo(memTx.tx)
+			tx.Sender, tx.Nonce, tx.GasPrice = info.Sender, info.Nonce, info.GasPrice
+		}
+		preview.Txs = append(preview.Txs, tx)
+		preview.TotalBytes += bytes
+		preview.TotalGas += memTx.gasWanted
+	})
+	preview.Excluded = mem.txs.Len() - len(preview.Txs)
+	return preview
+}
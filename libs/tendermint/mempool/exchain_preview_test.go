@@ -0,0 +1,49 @@
+package mempool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/okex/exchain/libs/tendermint/abci/example/kvstore"
+	"github.com/okex/exchain/libs/tendermint/proxy"
+)
+
+func TestPreviewBlock(t *testing.T) {
+	app := kvstore.NewApplication()
+	cc := proxy.NewLocalClientCreator(app)
+	mempool, cleanup := newMempoolWithApp(cc)
+	defer cleanup()
+
+	// each tx has 20 bytes + amino overhead = 22 bytes, 1 gas
+	checkTxs(t, mempool, 20, UnknownPeerID)
+
+	testCases := []struct {
+		maxBytes, maxGas int64
+		txs              int
+		limit            string
+	}{
+		{-1, -1, 20, ""},
+		{-1, 10, 10, ReapLimitGas},
+		{220, -1, 10, ReapLimitBytes},
+		{220, 5, 5, ReapLimitGas},
+	}
+	for _, tc := range testCases {
+		preview := mempool.PreviewBlock(tc.maxBytes, tc.maxGas)
+		require.Len(t, preview.Txs, tc.txs)
+		require.Equal(t, tc.limit, preview.Limit)
+		require.Equal(t, 20-tc.txs, preview.Excluded)
+		require.Equal(t, int64(tc.txs), preview.TotalGas)
+		require.Equal(t, int64(22*tc.txs), preview.TotalBytes)
+
+		// the preview is the block the mempool reaps, in the same order
+		reaped := mempool.ReapMaxBytesMaxGas(tc.maxBytes, tc.maxGas)
+		require.Len(t, reaped, tc.txs)
+		for i, tx := range preview.Txs {
+			require.Equal(t, reaped[i], tx.Tx)
+			require.Equal(t, int64(1), tx.Gas)
+			require.Equal(t, int64(22), tx.Bytes)
+		}
+	}
+	require.Equal(t, 20, mempool.Size())
+}
@@ -117,6 +117,9 @@ type TxInfo struct {
 	SenderID uint16
 	// SenderP2PID is the actual p2p.ID of the sender, used e.g. for logging.
 	SenderP2PID p2p.ID
+	// Private is true for the txs submitted privately to the node, which are proposed by the node but are
+	// neither gossiped to its peers nor published as pending txs.
+	Private bool
 }
 
 //--------------------------------------------------------------------------------
@@ -239,8 +239,8 @@ func (memR *Reactor) broadcastTxRoutine(peer p2p.Peer) {
 			continue
 		}
 
-		// ensure peer hasn't already sent us this tx
-		if _, ok := memTx.senders.Load(peerID); !ok {
+		// ensure peer hasn't already sent us this tx, the private txs are never sent
+		if _, ok := memTx.senders.Load(peerID); !ok && !memTx.private {
 			// send memTx
 			msg := &TxMessage{Tx: memTx.tx}
 			success := peer.Send(MempoolChannel, cdc.MustMarshalBinaryBare(msg))
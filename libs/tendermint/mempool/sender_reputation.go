@@ -0,0 +1,212 @@
+package mempool
+
+import (
+	"encoding/hex"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/okex/exchain/libs/tendermint/libs/bech32"
+)
+
+const (
+	// the points added to the score of a sender for a tx failing the check, and for a tx evicted on recheck
+	failurePenalty  = 1
+	evictionPenalty = 2
+
+	// the scores below are forgotten once the number of tracked senders reaches maxTrackedSenders
+	negligibleScore   = 0.01
+	maxTrackedSenders = 100000
+)
+
+// senderKey returns the address of the sender in lowercase hex, the form the senders are scored by. The evm txs
+// report their senders in hex and the cosmos txs in bech32, a sender is scored the same for both.
+func senderKey(sender string) string {
+	if strings.HasPrefix(sender, "0x") || strings.HasPrefix(sender, "0X") {
+		return "0x" + strings.ToLower(sender[2:])
+	}
+	if _, bz, err := bech32.DecodeAndConvert(sender); err == nil {
+		return "0x" + hex.EncodeToString(bz)
+	}
+	return sender
+}
+
+// SenderScore is the reputation of a sender of txs to the mempool, built from the failures of its txs and their
+// evictions. The score decays by half every half-life, the more abusive the sender the higher its score.
+type SenderScore struct {
+	Sender          string     `json:"sender"`
+	Score           float64    `json:"score"`
+	Checks          uint64     `json:"checks"`
+	Failures        uint64     `json:"failures"`
+	Evictions       uint64     `json:"evictions"`
+	GraylistedUntil *time.Time `json:"graylisted_until,omitempty"`
+
+	updated time.Time
+}
+
+// senderReputation scores the senders of txs to the mempool. The senders whose score reaches the graylist score
+// are rejected for the graylist period, the ones past half of it are deprioritized.
+type senderReputation struct {
+	mtx    sync.Mutex
+	scores map[string]*SenderScore
+
+	halfLife       time.Duration
+	graylistScore  float64
+	graylistPeriod time.Duration
+	now            func() time.Time
+}
+
+func newSenderReputation(halfLife time.Duration, graylistScore float64, graylistPeriod time.Duration) *senderReputation {
+	return &senderReputation{
+		scores:         make(map[string]*SenderScore),
+		halfLife:       halfLife,
+		graylistScore:  graylistScore,
+		graylistPeriod: graylistPeriod,
+		now:            time.Now,
+	}
+}
+
+// get returns the score of the sender decayed up to now, nil if the sender isn't tracked
+func (r *senderReputation) get(sender string, now time.Time) *SenderScore {
+	s, ok := r.scores[sender]
+	if !ok {
+		return nil
+	}
+	if elapsed := now.Sub(s.updated); elapsed > 0 && r.halfLife > 0 {
+		s.Score *= math.Pow(0.5, float64(elapsed)/float64(r.halfLife))
+	}
+	s.updated = now
+	if s.GraylistedUntil != nil && !now.Before(*s.GraylistedUntil) {
+		s.GraylistedUntil = nil
+	}
+	return s
+}
+
+func (r *senderReputation) getOrCreate(sender string, now time.Time) *SenderScore {
+	if s := r.get(sender, now); s != nil {
+		return s
+	}
+	if len(r.scores) >= maxTrackedSenders {
+		r.prune(now)
+	}
+	s := &SenderScore{Sender: sender, updated: now}
+	r.scores[sender] = s
+	return s
+}
+
+// prune forgets the senders with a negligible score which aren't graylisted
+func (r *senderReputation) prune(now time.Time) {
+	for sender := range r.scores {
+		if s := r.get(sender, now); s.Score < negligibleScore && s.GraylistedUntil == nil {
+			delete(r.scores, sender)
+		}
+	}
+}
+
+func (r *senderReputation) penalize(s *SenderScore, points float64, now time.Time) {
+	s.Score += points
+	if s.Score >= r.graylistScore && s.GraylistedUntil == nil {
+		until := now.Add(r.graylistPeriod)
+		s.GraylistedUntil = &until
+	}
+}
+
+// onCheck records the result of the check of a tx of the sender. The sender of a failed tx must be verified by
+// its signature, the senders claimed by the txs are never penalized.
+func (r *senderReputation) onCheck(sender string, failed bool) {
+	if r == nil || sender == "" {
+		return
+	}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	now := r.now()
+	s := r.getOrCreate(senderKey(sender), now)
+	s.Checks++
+	if failed {
+		s.Failures++
+		r.penalize(s, failurePenalty, now)
+	}
+}
+
+// onEviction records the eviction of a tx of the sender, which became invalid once rechecked
+func (r *senderReputation) onEviction(sender string) {
+	if r == nil || sender == "" {
+		return
+	}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	now := r.now()
+	s := r.getOrCreate(senderKey(sender), now)
+	s.Evictions++
+	r.penalize(s, evictionPenalty, now)
+}
+
+// check returns the error rejecting the tx of the sender if it's graylisted, or if it's deprioritized while the
+// mempool is busy
+func (r *senderReputation) check(sender string, busy bool) error {
+	if r == nil || sender == "" {
+		return nil
+	}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	s := r.get(senderKey(sender), r.now())
+	if s == nil {
+		return nil
+	}
+	if s.GraylistedUntil != nil {
+		return ErrSenderGraylisted{Sender: sender, Until: *s.GraylistedUntil}
+	}
+	if busy && s.Score >= r.graylistScore/2 {
+		return ErrSenderDeprioritized{Sender: sender, Score: s.Score}
+	}
+	return nil
+}
+
+// list returns the scores of the tracked senders, the highest first
+func (r *senderReputation) list() []SenderScore {
+	scores := make([]SenderScore, 0)
+	if r == nil {
+		return scores
+	}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	now := r.now()
+	for sender := range r.scores {
+		scores = append(scores, *r.get(sender, now))
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score > scores[j].Score
+		}
+		return scores[i].Sender < scores[j].Sender
+	})
+	return scores
+}
+
+// clear forgets the score of the sender, given by its hex or bech32 address, or of all the senders if it's empty.
+// It returns the number of senders cleared.
+func (r *senderReputation) clear(sender string) int {
+	if r == nil {
+		return 0
+	}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if sender == "" {
+		n := len(r.scores)
+		r.scores = make(map[string]*SenderScore)
+		return n
+	}
+	key := senderKey(sender)
+	if _, ok := r.scores[key]; !ok {
+		return 0
+	}
+	delete(r.scores, key)
+	return 1
+}
@@ -0,0 +1,110 @@
+package mempool
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/okex/exchain/libs/tendermint/abci/types"
+	cfg "github.com/okex/exchain/libs/tendermint/config"
+	"github.com/okex/exchain/libs/tendermint/proxy"
+	"github.com/okex/exchain/libs/tendermint/types"
+)
+
+func TestSenderReputation(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	r := newSenderReputation(time.Minute, 10, 10*time.Minute)
+	r.now = func() time.Time { return now }
+
+	// the checked txs don't score
+	r.onCheck("good", false)
+	require.NoError(t, r.check("good", true))
+
+	// past half of the graylist score the sender is rejected while the mempool is busy
+	for i := 0; i < 5; i++ {
+		r.onCheck("spammer", true)
+	}
+	require.NoError(t, r.check("spammer", false))
+	require.IsType(t, ErrSenderDeprioritized{}, r.check("spammer", true))
+
+	// the evictions score twice as much, the graylisted sender is rejected for the graylist period
+	r.onEviction("spammer")
+	r.onEviction("spammer")
+	r.onEviction("spammer")
+	require.IsType(t, ErrSenderGraylisted{}, r.check("spammer", false))
+
+	scores := r.list()
+	require.Len(t, scores, 2)
+	require.Equal(t, "spammer", scores[0].Sender)
+	require.Equal(t, uint64(5), scores[0].Failures)
+	require.Equal(t, uint64(3), scores[0].Evictions)
+	require.Equal(t, float64(11), scores[0].Score)
+	require.NotNil(t, scores[0].GraylistedUntil)
+
+	// the score decays by half every half-life and the graylisting expires
+	now = now.Add(10 * time.Minute)
+	require.NoError(t, r.check("spammer", true))
+	require.InDelta(t, 11.0/1024, r.list()[0].Score, 1e-9)
+	require.Nil(t, r.list()[0].GraylistedUntil)
+
+	require.Equal(t, 1, r.clear("spammer"))
+	require.Equal(t, 0, r.clear("spammer"))
+	require.Equal(t, 1, r.clear(""))
+	require.Empty(t, r.list())
+
+	// the disabled reputation accepts all the senders
+	var disabled *senderReputation
+	disabled.onCheck("spammer", true)
+	disabled.onEviction("spammer")
+	require.NoError(t, disabled.check("spammer", true))
+	require.Empty(t, disabled.list())
+}
+
+func TestSenderKey(t *testing.T) {
+	key := "0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266"
+	require.Equal(t, key, senderKey("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"))
+	require.Equal(t, key, senderKey(key))
+	require.Equal(t, key, senderKey("ex17w0adeg64ky0daxwd2ugyuneellmjgnxt5dhzh"))
+	// the senders which aren't addresses are kept as they are
+	require.Equal(t, "spammer", senderKey("spammer"))
+}
+
+// failingCheckApp fails the checks of all the txs, reporting the sender of the tx as verified by its signature
+type failingCheckApp struct {
+	abci.BaseApplication
+	sender string
+}
+
+func (app *failingCheckApp) CheckTx(req abci.RequestCheckTx) abci.ResponseCheckTx {
+	res := abci.ResponseCheckTx{Code: 1, Log: "invalid tx"}
+	if app.sender != "" {
+		res.Data, _ = json.Marshal(ExTxInfo{Sender: app.sender})
+	}
+	return res
+}
+
+func TestSenderReputationFailedChecks(t *testing.T) {
+	app := &failingCheckApp{}
+	config := cfg.ResetTestRoot("mempool_test")
+	config.Mempool.EnableSenderReputation = true
+	mempool, cleanup := newMempoolWithAppAndConfig(proxy.NewLocalClientCreator(app), config)
+	defer cleanup()
+
+	// the failed txs without a verified sender aren't scored
+	require.NoError(t, mempool.CheckTx(types.Tx("tx1"), nil, TxInfo{}))
+	require.Empty(t, mempool.SenderScores())
+
+	app.sender = "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"
+	require.NoError(t, mempool.CheckTx(types.Tx("tx2"), nil, TxInfo{}))
+	require.NoError(t, mempool.CheckTx(types.Tx("tx3"), nil, TxInfo{}))
+	scores := mempool.SenderScores()
+	require.Len(t, scores, 1)
+	require.Equal(t, "0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266", scores[0].Sender)
+	require.Equal(t, uint64(2), scores[0].Failures)
+
+	// the sender is cleared by its bech32 address as well
+	require.Equal(t, 1, mempool.ClearSenderScore("ex17w0adeg64ky0daxwd2ugyuneellmjgnxt5dhzh"))
+	require.Empty(t, mempool.SenderScores())
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/cors"
+	"github.com/spf13/viper"
 
 	amino "github.com/tendermint/go-amino"
 	dbm "github.com/tendermint/tm-db"
@@ -158,6 +159,7 @@ type Node struct {
 	config        *cfg.Config
 	genesisDoc    *types.GenesisDoc   // initial validator set
 	privValidator types.PrivValidator // local node's validator key
+	standby       *privval.StandbyPrivValidator
 
 	// network
 	transport   *p2p.MultiplexTransport
@@ -609,12 +611,27 @@ func NewNode(config *cfg.Config,
 	// external signing process.
 	if config.PrivValidatorListenAddr != "" {
 		// FIXME: we should start services inside OnStart
-		privValidator, err = createAndStartPrivValidatorSocketClient(config.PrivValidatorListenAddr, logger)
+		privvalMetrics := privval.NopMetrics()
+		if config.Instrumentation.Prometheus {
+			privvalMetrics = privval.PrometheusMetrics(config.Instrumentation.Namespace, "chain_id", genDoc.ChainID)
+		}
+		privValidator, err = createAndStartPrivValidatorSocketClient(config.PrivValidatorListenAddr,
+			config.PrivValidatorSecondaryListenAddr, privvalMetrics, logger)
 		if err != nil {
 			return nil, errors.Wrap(err, "error with private validator socket client")
 		}
 	}
 
+	// a standby node doesn't sign until it is promoted
+	var standby *privval.StandbyPrivValidator
+	if viper.GetBool(privval.FlagStandby) {
+		standby, err = privval.NewStandbyPrivValidatorFromFlags(privValidator, logger.With("module", "privval"))
+		if err != nil {
+			return nil, errors.Wrap(err, "error with standby private validator")
+		}
+		privValidator = standby
+	}
+
 	pubKey, err := privValidator.GetPubKey()
 	if err != nil {
 		return nil, errors.Wrap(err, "can't get pubkey")
@@ -716,6 +733,7 @@ func NewNode(config *cfg.Config,
 		config:        config,
 		genesisDoc:    genDoc,
 		privValidator: privValidator,
+		standby:       standby,
 
 		transport: transport,
 		sw:        sw,
@@ -838,9 +856,19 @@ func (n *Node) OnStop() {
 		}
 	}
 
-	if pvsc, ok := n.privValidator.(service.Service); ok {
+	pv := n.privValidator
+	if n.standby != nil {
+		if err := n.standby.Demote(); err != nil {
+			n.Logger.Error("Error releasing the signer lock", "err", err)
+		}
+		pv = n.standby.PrivValidator
+	}
+	if pvsc, ok := pv.(service.Service); ok {
 		pvsc.Stop()
 	}
+	if client, ok := pv.(*privval.FailoverSignerClient); ok {
+		client.Close()
+	}
 
 	if n.prometheusSrv != nil {
 		if err := n.prometheusSrv.Shutdown(context.Background()); err != nil {
@@ -856,7 +884,7 @@ func (n *Node) ConfigureRPC() error {
 	if err != nil {
 		return fmt.Errorf("can't get pubkey: %w", err)
 	}
-	rpccore.SetEnvironment(&rpccore.Environment{
+	env := &rpccore.Environment{
 		ProxyAppQuery: n.proxyApp.Query(),
 
 		StateDB:        n.stateDB,
@@ -876,7 +904,11 @@ func (n *Node) ConfigureRPC() error {
 		Logger: n.Logger.With("module", "rpc"),
 
 		Config: *n.config.RPC,
-	})
+	}
+	if n.standby != nil {
+		env.Standby = n.standby
+	}
+	rpccore.SetEnvironment(env)
 	return nil
 }
 
@@ -1212,8 +1244,34 @@ func saveGenesisDoc(db dbm.DB, genDoc *types.GenesisDoc) {
 
 func createAndStartPrivValidatorSocketClient(
 	listenAddr string,
+	secondaryListenAddr string,
+	metrics *privval.Metrics,
 	logger log.Logger,
 ) (types.PrivValidator, error) {
+	primary, err := createAndStartSignerClient(listenAddr, logger)
+	if err != nil {
+		return nil, err
+	}
+	// the signer is only wrapped into the failover client when a secondary signer is configured
+	if secondaryListenAddr == "" {
+		privval.SetRemoteSigner(privval.NewSingleSignerStatus(listenAddr, primary))
+		return primary, nil
+	}
+	secondary, err := createAndStartSignerClient(secondaryListenAddr, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := privval.NewFailoverSignerClient(listenAddr, primary, secondaryListenAddr, secondary, metrics,
+		logger.With("module", "privval"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to start private validator: %w", err)
+	}
+	privval.SetRemoteSigner(client)
+	return client, nil
+}
+
+func createAndStartSignerClient(listenAddr string, logger log.Logger) (*privval.RetrySignerClient, error) {
 	pve, err := privval.NewSignerListener(listenAddr, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to start private validator: %w", err)
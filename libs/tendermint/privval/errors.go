@@ -20,8 +20,17 @@ var (
 	ErrReadTimeout        = errors.New("endpoint read timed out")
 	ErrUnexpectedResponse = errors.New("empty response")
 	ErrWriteTimeout       = errors.New("endpoint write timed out")
+
+	// ErrSignRequestNotSent is wrapped into the errors of the signing requests that never reached the remote
+	// signer, they can be sent to another signer without risking a double sign
+	ErrSignRequestNotSent = errors.New("sign request not sent")
 )
 
+// isConnectionError returns true if the request failed to get a connection, before anything was written to it
+func isConnectionError(err error) bool {
+	return errors.Is(err, ErrNoConnection) || errors.Is(err, ErrConnectionTimeout)
+}
+
 // RemoteSignerError allows (remote) validators to include meaningful error
 // descriptions in their reply.
 type RemoteSignerError struct {
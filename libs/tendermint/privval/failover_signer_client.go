@@ -0,0 +1,299 @@
+package privval
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/okex/exchain/libs/tendermint/crypto"
+	"github.com/okex/exchain/libs/tendermint/libs/log"
+	"github.com/okex/exchain/libs/tendermint/types"
+)
+
+const (
+	PrimarySignerEndpoint   = "primary"
+	SecondarySignerEndpoint = "secondary"
+
+	// signerPingInterval is the interval of the health checks of the endpoints
+	signerPingInterval = 5 * time.Second
+)
+
+// RemoteSigner is a PrivValidator behind a connection to a remote signer, e.g. a RetrySignerClient.
+type RemoteSigner interface {
+	types.PrivValidator
+	IsConnected() bool
+	Ping() error
+}
+
+// SignerEndpointStatus is the health of a remote signer endpoint.
+type SignerEndpointStatus struct {
+	Name        string  `json:"name"`
+	Address     string  `json:"address"`
+	Active      bool    `json:"active"`
+	Connected   bool    `json:"connected"`
+	Signs       uint64  `json:"signs"`
+	Failures    uint64  `json:"failures"`
+	LastLatency string  `json:"lastLatency"`
+	LastError   *string `json:"lastError"`
+}
+
+// SignerStatus is the health of the remote signer of the node.
+type SignerStatus struct {
+	Endpoints []SignerEndpointStatus `json:"endpoints"`
+	Failovers uint64                 `json:"failovers"`
+}
+
+type failoverEndpoint struct {
+	name    string
+	address string
+	signer  RemoteSigner
+
+	connected   bool
+	signs       uint64
+	failures    uint64
+	lastLatency time.Duration
+	lastErr     error
+}
+
+// FailoverSignerClient signs with a primary remote signer and fails over to a secondary one holding the same key.
+// It fails over only when the request couldn't be sent to the active signer, as reported by RetrySignerClient with
+// ErrSignRequestNotSent, and only to a connected signer: neither a signer refusing to sign, e.g. because of its
+// double-sign protection, nor a signer failing to answer is bypassed. The failover is sticky, the client
+// only switches back once the other signer fails in turn, so that a flapping connection doesn't alternate the
+// signers. The health of the endpoints is reported by Status and by the metrics.
+type FailoverSignerClient struct {
+	endpoints []*failoverEndpoint
+	metrics   *Metrics
+	logger    log.Logger
+
+	mtx       sync.Mutex
+	active    int
+	failovers uint64
+	quit      chan struct{}
+	closeOnce sync.Once
+}
+
+var _ types.PrivValidator = (*FailoverSignerClient)(nil)
+
+// NewFailoverSignerClient creates the client of the primary signer and of the optional secondary one, which must
+// hold the same key.
+func NewFailoverSignerClient(
+	primaryAddr string,
+	primary RemoteSigner,
+	secondaryAddr string,
+	secondary RemoteSigner,
+	metrics *Metrics,
+	logger log.Logger,
+) (*FailoverSignerClient, error) {
+	c := &FailoverSignerClient{
+		endpoints: []*failoverEndpoint{{name: PrimarySignerEndpoint, address: primaryAddr, signer: primary}},
+		metrics:   metrics,
+		logger:    logger,
+		quit:      make(chan struct{}),
+	}
+	if secondary != nil {
+		pubKey, err := primary.GetPubKey()
+		if err != nil {
+			return nil, fmt.Errorf("can't get the pubkey of the primary signer: %w", err)
+		}
+		secondaryPubKey, err := secondary.GetPubKey()
+		if err != nil {
+			return nil, fmt.Errorf("can't get the pubkey of the secondary signer: %w", err)
+		}
+		if !bytes.Equal(pubKey.Bytes(), secondaryPubKey.Bytes()) {
+			return nil, errors.New("the primary and the secondary signers hold different keys")
+		}
+		c.endpoints = append(c.endpoints, &failoverEndpoint{name: SecondarySignerEndpoint, address: secondaryAddr, signer: secondary})
+	}
+
+	c.checkEndpoints()
+	go c.pingRoutine()
+	return c, nil
+}
+
+// Close stops the health checks of the endpoints
+func (c *FailoverSignerClient) Close() {
+	c.closeOnce.Do(func() { close(c.quit) })
+}
+
+func (c *FailoverSignerClient) pingRoutine() {
+	ticker := time.NewTicker(signerPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.quit:
+			return
+		case <-ticker.C:
+			c.checkEndpoints()
+		}
+	}
+}
+
+// checkEndpoints updates the connection state of the endpoints
+func (c *FailoverSignerClient) checkEndpoints() {
+	for _, ep := range c.endpoints {
+		connected := ep.signer.IsConnected() && ep.signer.Ping() == nil
+		c.mtx.Lock()
+		ep.connected = connected
+		c.mtx.Unlock()
+		c.metrics.Connected.With("endpoint", ep.name).Set(boolToFloat(connected))
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.updateActiveMetrics()
+}
+
+// Status returns the health of the endpoints
+func (c *FailoverSignerClient) Status() SignerStatus {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	status := SignerStatus{Failovers: c.failovers}
+	for i, ep := range c.endpoints {
+		epStatus := SignerEndpointStatus{
+			Name:        ep.name,
+			Address:     ep.address,
+			Active:      i == c.active,
+			Connected:   ep.connected,
+			Signs:       ep.signs,
+			Failures:    ep.failures,
+			LastLatency: ep.lastLatency.String(),
+		}
+		if ep.lastErr != nil {
+			lastErr := ep.lastErr.Error()
+			epStatus.LastError = &lastErr
+		}
+		status.Endpoints = append(status.Endpoints, epStatus)
+	}
+	return status
+}
+
+// GetPubKey returns the pubkey of the active signer
+func (c *FailoverSignerClient) GetPubKey() (crypto.PubKey, error) {
+	c.mtx.Lock()
+	signer := c.endpoints[c.active].signer
+	c.mtx.Unlock()
+	return signer.GetPubKey()
+}
+
+// SignVote signs the vote with the active signer, failing over if it can't be reached
+func (c *FailoverSignerClient) SignVote(chainID string, vote *types.Vote) error {
+	return c.sign(func(signer RemoteSigner) error {
+		return signer.SignVote(chainID, vote)
+	})
+}
+
+// SignProposal signs the proposal with the active signer, failing over if it can't be reached
+func (c *FailoverSignerClient) SignProposal(chainID string, proposal *types.Proposal) error {
+	return c.sign(func(signer RemoteSigner) error {
+		return signer.SignProposal(chainID, proposal)
+	})
+}
+
+func (c *FailoverSignerClient) sign(fn func(signer RemoteSigner) error) error {
+	// the signing requests are serialized, a failover never lets two signers sign concurrently
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	err := c.signWith(c.endpoints[c.active], fn)
+	if err == nil || !c.shouldFailover(err) {
+		return err
+	}
+
+	next := (c.active + 1) % len(c.endpoints)
+	c.logger.Error("Remote signer failed, fail over", "from", c.endpoints[c.active].name,
+		"to", c.endpoints[next].name, "err", err)
+	c.active = next
+	c.failovers++
+	c.metrics.Failovers.Add(1)
+	c.updateActiveMetrics()
+	return c.signWith(c.endpoints[c.active], fn)
+}
+
+// shouldFailover returns true if the request never reached the active signer, and the other signer is connected.
+// A request that may have been written, e.g. one timing out while waiting for the response, is never sent again
+// to the other signer: the active one may have signed it.
+func (c *FailoverSignerClient) shouldFailover(err error) bool {
+	if len(c.endpoints) < 2 || !errors.Is(err, ErrSignRequestNotSent) {
+		return false
+	}
+	next := c.endpoints[(c.active+1)%len(c.endpoints)]
+	return next.signer.IsConnected()
+}
+
+func (c *FailoverSignerClient) signWith(ep *failoverEndpoint, fn func(signer RemoteSigner) error) error {
+	start := time.Now()
+	err := fn(ep.signer)
+	ep.lastLatency = time.Since(start)
+	c.metrics.SignLatency.With("endpoint", ep.name).Observe(ep.lastLatency.Seconds())
+	ep.lastErr = err
+	if err != nil {
+		ep.failures++
+		c.metrics.SignFailures.With("endpoint", ep.name).Add(1)
+		return err
+	}
+	ep.signs++
+	return nil
+}
+
+func (c *FailoverSignerClient) updateActiveMetrics() {
+	for i, ep := range c.endpoints {
+		c.metrics.Active.With("endpoint", ep.name).Set(boolToFloat(i == c.active))
+	}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// SignerStatusReporter reports the health of the remote signer of the node
+type SignerStatusReporter interface {
+	Status() SignerStatus
+}
+
+// singleSignerStatus reports the health of a remote signer without secondary, which isn't wrapped into a
+// FailoverSignerClient
+type singleSignerStatus struct {
+	address string
+	signer  RemoteSigner
+}
+
+// NewSingleSignerStatus returns the reporter of the health of a remote signer without secondary
+func NewSingleSignerStatus(address string, signer RemoteSigner) SignerStatusReporter {
+	return singleSignerStatus{address: address, signer: signer}
+}
+
+func (s singleSignerStatus) Status() SignerStatus {
+	return SignerStatus{Endpoints: []SignerEndpointStatus{{
+		Name:      PrimarySignerEndpoint,
+		Address:   s.address,
+		Active:    true,
+		Connected: s.signer.IsConnected(),
+	}}}
+}
+
+type remoteSignerHolder struct {
+	reporter SignerStatusReporter
+}
+
+var remoteSigner atomic.Value
+
+// SetRemoteSigner registers the remote signer of the node, whose status is reported by GetRemoteSignerStatus
+func SetRemoteSigner(reporter SignerStatusReporter) {
+	remoteSigner.Store(remoteSignerHolder{reporter: reporter})
+}
+
+// GetRemoteSignerStatus returns the health of the remote signer of the node, nil if the node signs with a local key
+func GetRemoteSignerStatus() *SignerStatus {
+	holder, ok := remoteSigner.Load().(remoteSignerHolder)
+	if !ok || holder.reporter == nil {
+		return nil
+	}
+	status := holder.reporter.Status()
+	return &status
+}
@@ -0,0 +1,117 @@
+package privval
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/okex/exchain/libs/tendermint/crypto/ed25519"
+	"github.com/okex/exchain/libs/tendermint/libs/log"
+	"github.com/okex/exchain/libs/tendermint/types"
+)
+
+// mockRemoteSigner is a remote signer whose connection can be cut
+type mockRemoteSigner struct {
+	types.PrivValidator
+	connected bool
+	refuse    bool
+	// unanswered makes the signer get the requests without answering them
+	unanswered bool
+	votes      int
+}
+
+func (s *mockRemoteSigner) IsConnected() bool { return s.connected }
+
+func (s *mockRemoteSigner) Ping() error {
+	if !s.connected {
+		return ErrNoConnection
+	}
+	return nil
+}
+
+func (s *mockRemoteSigner) SignVote(chainID string, vote *types.Vote) error {
+	switch {
+	case !s.connected:
+		return fmt.Errorf("%w: %v", ErrSignRequestNotSent, ErrNoConnection)
+	case s.unanswered:
+		return fmt.Errorf("exhausted all attempts to sign vote: %w", ErrReadTimeout)
+	case s.refuse:
+		return &RemoteSignerError{Code: 1, Description: "double sign"}
+	}
+	s.votes++
+	return s.PrivValidator.SignVote(chainID, vote)
+}
+
+func TestFailoverSignerClient(t *testing.T) {
+	pv := types.NewMockPV()
+	primary := &mockRemoteSigner{PrivValidator: pv, connected: true}
+	secondary := &mockRemoteSigner{PrivValidator: pv, connected: true}
+	client, err := NewFailoverSignerClient("primary:26659", primary, "secondary:26659", secondary, NopMetrics(), log.NewNopLogger())
+	require.NoError(t, err)
+	defer client.Close()
+	vote := func() *types.Vote { return &types.Vote{Type: types.PrevoteType, Height: 1} }
+
+	require.NoError(t, client.SignVote("chain", vote()))
+	require.Equal(t, 1, primary.votes)
+
+	// a signer refusing to sign is not bypassed
+	primary.refuse = true
+	var remoteErr *RemoteSignerError
+	require.True(t, errors.As(client.SignVote("chain", vote()), &remoteErr))
+	require.Zero(t, secondary.votes)
+	primary.refuse = false
+
+	// nor is a signer that may have signed without answering
+	primary.unanswered = true
+	require.True(t, errors.Is(client.SignVote("chain", vote()), ErrReadTimeout))
+	require.Zero(t, secondary.votes)
+	require.Zero(t, client.Status().Failovers)
+	primary.unanswered = false
+
+	// the secondary signs once the primary can't be reached, and keeps signing afterwards
+	primary.connected = false
+	require.NoError(t, client.SignVote("chain", vote()))
+	primary.connected = true
+	require.NoError(t, client.SignVote("chain", vote()))
+	require.Equal(t, 2, secondary.votes)
+
+	status := client.Status()
+	require.Equal(t, uint64(1), status.Failovers)
+	require.False(t, status.Endpoints[0].Active)
+	require.Equal(t, uint64(1), status.Endpoints[0].Signs)
+	require.Equal(t, uint64(3), status.Endpoints[0].Failures)
+	require.True(t, status.Endpoints[1].Active)
+	require.Equal(t, uint64(2), status.Endpoints[1].Signs)
+
+	// no failover to a disconnected signer
+	primary.connected = false
+	secondary.connected = false
+	require.True(t, errors.Is(client.SignVote("chain", vote()), ErrSignRequestNotSent))
+	require.Equal(t, uint64(1), client.Status().Failovers)
+
+	// the signers must hold the same key
+	_, err = NewFailoverSignerClient("primary:26659", primary, "other:26659",
+		&mockRemoteSigner{PrivValidator: types.NewMockPV()}, NopMetrics(), log.NewNopLogger())
+	require.Error(t, err)
+}
+
+func TestRetrySignerClientRequestNotSent(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	sle := NewSignerListenerEndpoint(log.TestingLogger(), NewTCPListener(ln, ed25519.GenPrivKey()))
+	sle.timeoutAccept = 10 * time.Millisecond
+	sc, err := NewSignerClient(sle)
+	require.NoError(t, err)
+	defer sc.Close()
+
+	// no signer dials in, the requests never leave the node
+	client := NewRetrySignerClient(sc, 2, time.Millisecond)
+	err = client.SignVote("chain", &types.Vote{Type: types.PrevoteType, Height: 1})
+	require.True(t, errors.Is(err, ErrSignRequestNotSent))
+	err = client.SignProposal("chain", &types.Proposal{Type: types.ProposalType, Height: 1})
+	require.True(t, errors.Is(err, ErrSignRequestNotSent))
+}
@@ -0,0 +1,83 @@
+package privval
+
+import (
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// MetricsSubsystem is a subsystem shared by all metrics exposed by this
+	// package.
+	MetricsSubsystem = "privval"
+)
+
+// Metrics contains metrics exposed by this package.
+type Metrics struct {
+	// Whether the remote signer endpoint is connected, by endpoint.
+	Connected metrics.Gauge
+	// Whether the remote signer endpoint is the one signing, by endpoint.
+	Active metrics.Gauge
+	// Histogram of the signing latencies in seconds, by endpoint.
+	SignLatency metrics.Histogram
+	// Number of failed signing requests, by endpoint.
+	SignFailures metrics.Counter
+	// Number of failovers between the remote signer endpoints.
+	Failovers metrics.Counter
+}
+
+// PrometheusMetrics returns Metrics build using Prometheus client library.
+// Optionally, labels can be provided along with their values ("foo",
+// "fooValue").
+func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
+	labels := []string{}
+	for i := 0; i < len(labelsAndValues); i += 2 {
+		labels = append(labels, labelsAndValues[i])
+	}
+	endpointLabels := append(labels, "endpoint")
+	return &Metrics{
+		Connected: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "connected",
+			Help:      "Whether the remote signer endpoint is connected.",
+		}, endpointLabels).With(labelsAndValues...),
+		Active: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "active",
+			Help:      "Whether the remote signer endpoint is the one signing.",
+		}, endpointLabels).With(labelsAndValues...),
+		SignLatency: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "sign_latency_seconds",
+			Help:      "Latency of the signing requests to the remote signer in seconds.",
+			Buckets:   stdprometheus.ExponentialBuckets(0.001, 2, 14),
+		}, endpointLabels).With(labelsAndValues...),
+		SignFailures: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "sign_failures",
+			Help:      "Number of failed signing requests to the remote signer.",
+		}, endpointLabels).With(labelsAndValues...),
+		Failovers: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "failovers",
+			Help:      "Number of failovers between the remote signer endpoints.",
+		}, labels).With(labelsAndValues...),
+	}
+}
+
+// NopMetrics returns no-op Metrics.
+func NopMetrics() *Metrics {
+	return &Metrics{
+		Connected:    discard.NewGauge(),
+		Active:       discard.NewGauge(),
+		SignLatency:  discard.NewHistogram(),
+		SignFailures: discard.NewCounter(),
+		Failovers:    discard.NewCounter(),
+	}
+}
@@ -0,0 +1,150 @@
+package privval
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// The lock is a redis key which never expires, holding "token|expiry|height|round|step": the token of its owner,
+// the end of its lease in milliseconds by the clock of redis and the last height/round/step signed under it, which
+// outlives the leases and the owners. The lock is free once its lease has expired.
+
+// acquireScript takes the lock if it is free or already held by the token, it keeps the last signed state
+var acquireScript = redis.NewScript(`
+redis.replicate_commands()
+local now = redis.call("time")
+local nowms = tonumber(now[1]) * 1000 + math.floor(tonumber(now[2]) / 1000)
+local hrs = "0|0|0"
+local v = redis.call("get", KEYS[1])
+if v then
+	local token, expiry, signed = string.match(v, "^(%x+)|(%d+)|(%d+|%d+|%d+)$")
+	if token then
+		if token ~= ARGV[1] and tonumber(expiry) > nowms then
+			return 0
+		end
+		hrs = signed
+	end
+end
+redis.call("set", KEYS[1], ARGV[1] .. "|" .. (nowms + tonumber(ARGV[2])) .. "|" .. hrs)
+return 1`)
+
+// refreshScript extends the lease only if the lock is still held by the token
+var refreshScript = redis.NewScript(`
+redis.replicate_commands()
+local now = redis.call("time")
+local nowms = tonumber(now[1]) * 1000 + math.floor(tonumber(now[2]) / 1000)
+local v = redis.call("get", KEYS[1])
+if not v then
+	return 0
+end
+local token, expiry, signed = string.match(v, "^(%x+)|(%d+)|(%d+|%d+|%d+)$")
+if token ~= ARGV[1] or tonumber(expiry) <= nowms then
+	return 0
+end
+redis.call("set", KEYS[1], ARGV[1] .. "|" .. (nowms + tonumber(ARGV[2])) .. "|" .. signed)
+return 1`)
+
+// recordScript stores the signed state if the lock is still held by the token and the state is beyond the last one
+var recordScript = redis.NewScript(`
+redis.replicate_commands()
+local now = redis.call("time")
+local nowms = tonumber(now[1]) * 1000 + math.floor(tonumber(now[2]) / 1000)
+local v = redis.call("get", KEYS[1])
+if not v then
+	return 0
+end
+local token, expiry, h, r, s = string.match(v, "^(%x+)|(%d+)|(%d+)|(%d+)|(%d+)$")
+if token ~= ARGV[1] or tonumber(expiry) <= nowms then
+	return 0
+end
+local nh, nr, ns = tonumber(ARGV[2]), tonumber(ARGV[3]), tonumber(ARGV[4])
+h, r, s = tonumber(h), tonumber(r), tonumber(s)
+if nh < h or (nh == h and (nr < r or (nr == r and ns <= s))) then
+	return -1
+end
+redis.call("set", KEYS[1], token .. "|" .. expiry .. "|" .. ARGV[2] .. "|" .. ARGV[3] .. "|" .. ARGV[4])
+return 1`)
+
+// releaseScript ends the lease only if the lock is still held by the token, it keeps the last signed state
+var releaseScript = redis.NewScript(`
+local v = redis.call("get", KEYS[1])
+if not v then
+	return 0
+end
+local token, expiry, signed = string.match(v, "^(%x+)|(%d+)|(%d+|%d+|%d+)$")
+if token ~= ARGV[1] then
+	return 0
+end
+redis.call("set", KEYS[1], token .. "|0|" .. signed)
+return 1`)
+
+// RedisLock is a SignerLock stored in a redis key holding the random token of its owner and the last signed state
+type RedisLock struct {
+	rdb   *redis.Client
+	key   string
+	token string
+	ttl   time.Duration
+}
+
+var _ SignerLock = (*RedisLock)(nil)
+
+func NewRedisLock(url, auth, key string, ttl time.Duration) *RedisLock {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		panic(err)
+	}
+	rdb := redis.NewClient(&redis.Options{
+		Addr:     url,
+		Password: auth,
+		DB:       0,
+	})
+	return &RedisLock{rdb: rdb, key: key, token: hex.EncodeToString(token), ttl: ttl}
+}
+
+func (l *RedisLock) Acquire(ctx context.Context) error {
+	res, err := acquireScript.Run(ctx, l.rdb, []string{l.key}, l.token, l.ttl.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if res == 0 {
+		return ErrLockHeld
+	}
+	return nil
+}
+
+func (l *RedisLock) Refresh(ctx context.Context) error {
+	res, err := refreshScript.Run(ctx, l.rdb, []string{l.key}, l.token, l.ttl.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if res == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+func (l *RedisLock) Record(ctx context.Context, height int64, round int, step int8) error {
+	res, err := recordScript.Run(ctx, l.rdb, []string{l.key}, l.token, height, round, step).Int64()
+	if err != nil {
+		return err
+	}
+	switch res {
+	case 0:
+		return ErrLockLost
+	case -1:
+		return ErrAlreadySigned
+	}
+	return nil
+}
+
+func (l *RedisLock) Release(ctx context.Context) error {
+	return releaseScript.Run(ctx, l.rdb, []string{l.key}, l.token).Err()
+}
+
+func (l *RedisLock) TTL() time.Duration {
+	return l.ttl
+}
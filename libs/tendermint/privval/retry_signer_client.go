@@ -63,7 +63,10 @@ func (sc *RetrySignerClient) GetPubKey() (crypto.PubKey, error) {
 }
 
 func (sc *RetrySignerClient) SignVote(chainID string, vote *types.Vote) error {
-	var err error
+	var (
+		err  error
+		sent bool
+	)
 	for i := 0; i < sc.retries || sc.retries == 0; i++ {
 		err = sc.next.SignVote(chainID, vote)
 		if err == nil {
@@ -73,13 +76,20 @@ func (sc *RetrySignerClient) SignVote(chainID string, vote *types.Vote) error {
 		if _, ok := err.(*RemoteSignerError); ok {
 			return err
 		}
+		sent = sent || !isConnectionError(err)
 		time.Sleep(sc.timeout)
 	}
+	if !sent {
+		return fmt.Errorf("exhausted all attempts to sign vote, %w: %v", ErrSignRequestNotSent, err)
+	}
 	return fmt.Errorf("exhausted all attempts to sign vote: %w", err)
 }
 
 func (sc *RetrySignerClient) SignProposal(chainID string, proposal *types.Proposal) error {
-	var err error
+	var (
+		err  error
+		sent bool
+	)
 	for i := 0; i < sc.retries || sc.retries == 0; i++ {
 		err = sc.next.SignProposal(chainID, proposal)
 		if err == nil {
@@ -89,7 +99,11 @@ func (sc *RetrySignerClient) SignProposal(chainID string, proposal *types.Propos
 		if _, ok := err.(*RemoteSignerError); ok {
 			return err
 		}
+		sent = sent || !isConnectionError(err)
 		time.Sleep(sc.timeout)
 	}
+	if !sent {
+		return fmt.Errorf("exhausted all attempts to sign proposal, %w: %v", ErrSignRequestNotSent, err)
+	}
 	return fmt.Errorf("exhausted all attempts to sign proposal: %w", err)
 }
@@ -0,0 +1,219 @@
+package privval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/okex/exchain/libs/tendermint/libs/log"
+	"github.com/okex/exchain/libs/tendermint/types"
+)
+
+const (
+	FlagStandby             = "standby"
+	FlagStandbyLockRedisURL = "standby-lock-redis-url"
+	FlagStandbyLockAuth     = "standby-lock-redis-auth"
+	FlagStandbyLockKey      = "standby-lock-key"
+	FlagStandbyLockTTL      = "standby-lock-ttl"
+
+	// the lease is considered lost a bit before it expires on the lock server, to absorb the clock drift and the
+	// latency of the refresh
+	leaseSafetyMargin = 500 * time.Millisecond
+	// minLockTTL leaves the lease valid long enough to be refreshed, every third of the TTL
+	minLockTTL = 2 * leaseSafetyMargin
+)
+
+var (
+	ErrStandby    = errors.New("the node is in standby")
+	ErrLockHeld   = errors.New("the signer lock is held by another node")
+	ErrLockLost   = errors.New("the signer lock is lost")
+	ErrNotStandby = errors.New("the node is not in standby mode")
+	// ErrAlreadySigned is returned if the signer lock recorded a signature at or beyond the height/round/step
+	ErrAlreadySigned = errors.New("the signer lock recorded a signature at or beyond this height, round and step")
+)
+
+// SignerLock is an external lock held by the only node of a redundant pair allowed to sign, e.g. in redis, etcd or
+// consul. It is a lease which expires unless it is refreshed. It also holds the last height/round/step signed by
+// any node of the pair, so that a node promoted after a failover never signs again what the other one signed.
+type SignerLock interface {
+	// Acquire takes the lock, it fails with ErrLockHeld if another node holds it
+	Acquire(ctx context.Context) error
+	// Refresh extends the lease of the lock, it fails with ErrLockLost if the lock is not held anymore
+	Refresh(ctx context.Context) error
+	// Record stores the height/round/step about to be signed, it fails with ErrLockLost if the lock is not held
+	// anymore and with ErrAlreadySigned if it is not beyond the last one recorded
+	Record(ctx context.Context, height int64, round int, step int8) error
+	// Release gives the lock up if it is held
+	Release(ctx context.Context) error
+	// TTL is the duration of the lease
+	TTL() time.Duration
+}
+
+// StandbyPrivValidator wraps the PrivValidator of a node replicating the chain as the standby of another one: it
+// refuses to sign until the node is promoted. The promotion takes the signer lock, which is refreshed while the
+// node is active, so that the two nodes of the pair never sign together. Losing the lock demotes the node.
+// Without a lock the promotion is not protected against double signing.
+type StandbyPrivValidator struct {
+	types.PrivValidator
+
+	lock   SignerLock
+	logger log.Logger
+
+	mtx    sync.Mutex
+	active bool
+	// the lease is valid until expiry, the signatures are refused afterwards
+	expiry time.Time
+	quit   chan struct{}
+}
+
+var _ types.PrivValidator = (*StandbyPrivValidator)(nil)
+
+// NewStandbyPrivValidator creates the standby of pv, the lock may be nil
+func NewStandbyPrivValidator(pv types.PrivValidator, lock SignerLock, logger log.Logger) *StandbyPrivValidator {
+	return &StandbyPrivValidator{PrivValidator: pv, lock: lock, logger: logger}
+}
+
+// NewStandbyPrivValidatorFromFlags creates the standby of pv with the signer lock given by the flags
+func NewStandbyPrivValidatorFromFlags(pv types.PrivValidator, logger log.Logger) (*StandbyPrivValidator, error) {
+	var lock SignerLock
+	if url := viper.GetString(FlagStandbyLockRedisURL); url != "" {
+		ttl := viper.GetDuration(FlagStandbyLockTTL)
+		if ttl < minLockTTL {
+			return nil, fmt.Errorf("--%s must be at least %v, got %v", FlagStandbyLockTTL, minLockTTL, ttl)
+		}
+		lock = NewRedisLock(url, viper.GetString(FlagStandbyLockAuth), viper.GetString(FlagStandbyLockKey), ttl)
+	} else {
+		logger.Error("No signer lock is configured, the standby is not protected against double signing")
+	}
+	return NewStandbyPrivValidator(pv, lock, logger), nil
+}
+
+// IsActive returns true if the node is promoted and may sign
+func (pv *StandbyPrivValidator) IsActive() bool {
+	pv.mtx.Lock()
+	defer pv.mtx.Unlock()
+	return pv.canSign()
+}
+
+// Promote takes the signer lock and lets the node sign
+func (pv *StandbyPrivValidator) Promote() error {
+	pv.mtx.Lock()
+	defer pv.mtx.Unlock()
+	if pv.active {
+		return nil
+	}
+
+	if pv.lock != nil {
+		start := time.Now()
+		ctx, cancel := context.WithTimeout(context.Background(), pv.lock.TTL())
+		defer cancel()
+		if err := pv.lock.Acquire(ctx); err != nil {
+			return err
+		}
+		pv.expiry = start.Add(pv.lock.TTL() - leaseSafetyMargin)
+		pv.quit = make(chan struct{})
+		go pv.refreshRoutine(pv.quit)
+	}
+	pv.active = true
+	pv.logger.Info("Promoted the standby node")
+	return nil
+}
+
+// Demote stops the signatures and releases the signer lock
+func (pv *StandbyPrivValidator) Demote() error {
+	pv.mtx.Lock()
+	defer pv.mtx.Unlock()
+	if !pv.active {
+		return nil
+	}
+
+	pv.active = false
+	pv.logger.Info("Demoted the node to standby")
+	if pv.lock == nil {
+		return nil
+	}
+	close(pv.quit)
+	ctx, cancel := context.WithTimeout(context.Background(), pv.lock.TTL())
+	defer cancel()
+	return pv.lock.Release(ctx)
+}
+
+// refreshRoutine extends the lease until the node is demoted, the node is demoted once the lease can't be extended
+func (pv *StandbyPrivValidator) refreshRoutine(quit chan struct{}) {
+	ticker := time.NewTicker(pv.lock.TTL() / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-quit:
+			return
+		case <-ticker.C:
+		}
+
+		start := time.Now()
+		ctx, cancel := context.WithTimeout(context.Background(), pv.lock.TTL()/3)
+		err := pv.lock.Refresh(ctx)
+		cancel()
+
+		pv.mtx.Lock()
+		select {
+		case <-quit:
+			// demoted meanwhile
+		default:
+			if err == nil {
+				pv.expiry = start.Add(pv.lock.TTL() - leaseSafetyMargin)
+			} else if errors.Is(err, ErrLockLost) || !pv.canSign() {
+				pv.logger.Error("Failed to refresh the signer lock, demote the node to standby", "err", err)
+				pv.active = false
+				pv.mtx.Unlock()
+				return
+			} else {
+				pv.logger.Error("Failed to refresh the signer lock", "err", err)
+			}
+		}
+		pv.mtx.Unlock()
+	}
+}
+
+func (pv *StandbyPrivValidator) canSign() bool {
+	return pv.active && (pv.lock == nil || time.Now().Before(pv.expiry))
+}
+
+// record stores the height/round/step in the signer lock before it is signed, a failed signature leaves it burnt
+func (pv *StandbyPrivValidator) record(height int64, round int, step int8) error {
+	if pv.lock == nil {
+		return nil
+	}
+	ctx, cancel := context.WithDeadline(context.Background(), pv.expiry)
+	defer cancel()
+	return pv.lock.Record(ctx, height, round, step)
+}
+
+// SignVote signs the vote if the node is promoted and the vote is beyond the last signature of the pair
+func (pv *StandbyPrivValidator) SignVote(chainID string, vote *types.Vote) error {
+	pv.mtx.Lock()
+	defer pv.mtx.Unlock()
+	if !pv.canSign() {
+		return ErrStandby
+	}
+	if err := pv.record(vote.Height, vote.Round, voteToStep(vote)); err != nil {
+		return err
+	}
+	return pv.PrivValidator.SignVote(chainID, vote)
+}
+
+// SignProposal signs the proposal if the node is promoted and the proposal is beyond the last signature of the pair
+func (pv *StandbyPrivValidator) SignProposal(chainID string, proposal *types.Proposal) error {
+	pv.mtx.Lock()
+	defer pv.mtx.Unlock()
+	if !pv.canSign() {
+		return ErrStandby
+	}
+	if err := pv.record(proposal.Height, proposal.Round, stepPropose); err != nil {
+		return err
+	}
+	return pv.PrivValidator.SignProposal(chainID, proposal)
+}
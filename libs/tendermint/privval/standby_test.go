@@ -0,0 +1,140 @@
+package privval
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+
+	"github.com/okex/exchain/libs/tendermint/libs/log"
+	"github.com/okex/exchain/libs/tendermint/types"
+)
+
+// memLock is a SignerLock shared in memory by the nodes of a pair
+type memLock struct {
+	mtx    *sync.Mutex
+	holder *string
+	signed *[3]int64
+	owner  string
+	ttl    time.Duration
+}
+
+func (l memLock) Acquire(context.Context) error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if *l.holder != "" && *l.holder != l.owner {
+		return ErrLockHeld
+	}
+	*l.holder = l.owner
+	return nil
+}
+
+func (l memLock) Refresh(context.Context) error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if *l.holder != l.owner {
+		return ErrLockLost
+	}
+	return nil
+}
+
+func (l memLock) Record(_ context.Context, height int64, round int, step int8) error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if *l.holder != l.owner {
+		return ErrLockLost
+	}
+	hrs := [3]int64{height, int64(round), int64(step)}
+	for i := range hrs {
+		if hrs[i] != l.signed[i] {
+			if hrs[i] < l.signed[i] {
+				return ErrAlreadySigned
+			}
+			*l.signed = hrs
+			return nil
+		}
+	}
+	return ErrAlreadySigned
+}
+
+func (l memLock) Release(context.Context) error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if *l.holder == l.owner {
+		*l.holder = ""
+	}
+	return nil
+}
+
+func (l memLock) TTL() time.Duration { return l.ttl }
+
+func TestStandbyPrivValidator(t *testing.T) {
+	var mtx sync.Mutex
+	holder := ""
+	var signed [3]int64
+	newStandby := func(owner string) *StandbyPrivValidator {
+		lock := memLock{mtx: &mtx, holder: &holder, signed: &signed, owner: owner, ttl: 3 * time.Second}
+		return NewStandbyPrivValidator(types.NewMockPV(), lock, log.NewNopLogger())
+	}
+	primary, secondary := newStandby("primary"), newStandby("secondary")
+	height := int64(0)
+	vote := func() *types.Vote {
+		height++
+		return &types.Vote{Type: types.PrevoteType, Height: height}
+	}
+
+	// neither signs in standby
+	require.Equal(t, ErrStandby, primary.SignVote("chain", vote()))
+	require.Equal(t, ErrStandby, primary.SignProposal("chain", &types.Proposal{Height: 1}))
+
+	require.NoError(t, primary.Promote())
+	require.True(t, primary.IsActive())
+	require.NoError(t, primary.SignVote("chain", vote()))
+	require.NoError(t, primary.SignProposal("chain", &types.Proposal{Height: height + 1}))
+
+	// the other node of the pair can't be promoted meanwhile
+	require.Equal(t, ErrLockHeld, secondary.Promote())
+	require.False(t, secondary.IsActive())
+
+	// a failover once the primary is demoted
+	require.NoError(t, primary.Demote())
+	require.Equal(t, ErrStandby, primary.SignVote("chain", vote()))
+	require.NoError(t, secondary.Promote())
+	require.NoError(t, secondary.SignVote("chain", vote()))
+
+	// nothing at or below the last signature of the pair is signed again
+	require.Equal(t, ErrAlreadySigned, secondary.SignVote("chain", &types.Vote{Type: types.PrevoteType, Height: height}))
+	require.Equal(t, ErrAlreadySigned, secondary.SignProposal("chain", &types.Proposal{Height: height - 1}))
+	require.Equal(t, ErrAlreadySigned, secondary.SignProposal("chain", &types.Proposal{Height: height}))
+	require.NoError(t, secondary.SignVote("chain", &types.Vote{Type: types.PrecommitType, Height: height}))
+
+	// the node losing the lock is demoted
+	mtx.Lock()
+	holder = "primary"
+	mtx.Unlock()
+	require.Eventually(t, func() bool { return !secondary.IsActive() }, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, ErrStandby, secondary.SignVote("chain", vote()))
+
+	// the node without a lock is promoted at once
+	unlocked := NewStandbyPrivValidator(types.NewMockPV(), nil, log.NewNopLogger())
+	require.NoError(t, unlocked.Promote())
+	require.NoError(t, unlocked.SignVote("chain", vote()))
+}
+
+func TestStandbyLockTTL(t *testing.T) {
+	viper.Set(FlagStandbyLockRedisURL, "127.0.0.1:6379")
+	defer viper.Set(FlagStandbyLockRedisURL, "")
+
+	for _, ttl := range []time.Duration{0, leaseSafetyMargin, minLockTTL - time.Millisecond} {
+		viper.Set(FlagStandbyLockTTL, ttl)
+		_, err := NewStandbyPrivValidatorFromFlags(types.NewMockPV(), log.NewNopLogger())
+		require.Error(t, err, ttl)
+	}
+	viper.Set(FlagStandbyLockTTL, minLockTTL)
+	pv, err := NewStandbyPrivValidatorFromFlags(types.NewMockPV(), log.NewNopLogger())
+	require.NoError(t, err)
+	require.Equal(t, minLockTTL, pv.lock.TTL())
+}
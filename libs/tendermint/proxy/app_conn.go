@@ -20,6 +20,7 @@ type AppConnConsensus interface {
 	CommitSync(types.RequestCommit) (*types.ResponseCommit, error)
 	SetOptionAsync(req types.RequestSetOption) *abcicli.ReqRes
 	ParallelTxs([][]byte) []*types.ResponseDeliverTx
+	PreDeliverTxs([][]byte)
 	SetOptionSync(req types.RequestSetOption) (*types.ResponseSetOption, error)
 }
 
@@ -141,6 +142,10 @@ func (app *appConnConsensus) ParallelTxs(txs [][]byte) []*types.ResponseDeliverT
 	return app.appConn.ParallelTxs(txs)
 }
 
+func (app *appConnConsensus) PreDeliverTxs(txs [][]byte) {
+	app.appConn.PreDeliverTxs(txs)
+}
+
 //------------------------------------------------
 // Implements AppConnQuery (subset of abcicli.Client)
 
@@ -264,6 +264,12 @@ func (c *baseRPCClient) BroadcastTxSync(tx types.Tx) (*ctypes.ResultBroadcastTx,
 	return c.broadcastTX("broadcast_tx_sync", tx)
 }
 
+// BroadcastTxPrivate submits the tx to the mempool of the node without it being gossiped, see
+// core.BroadcastTxPrivate. It isn't part of the Client interface.
+func (c *baseRPCClient) BroadcastTxPrivate(tx types.Tx) (*ctypes.ResultBroadcastTx, error) {
+	return c.broadcastTX("broadcast_tx_private", tx)
+}
+
 func (c *baseRPCClient) broadcastTX(route string, tx types.Tx) (*ctypes.ResultBroadcastTx, error) {
 	result := new(ctypes.ResultBroadcastTx)
 	_, err := c.caller.Call(route, map[string]interface{}{"tx": tx}, result)
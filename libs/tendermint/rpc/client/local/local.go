@@ -102,6 +102,11 @@ func (c *Local) BroadcastTxSync(tx types.Tx) (*ctypes.ResultBroadcastTx, error)
 	return core.BroadcastTxSync(c.ctx, tx)
 }
 
+// BroadcastTxPrivate submits the tx to the mempool without it being gossiped, see core.BroadcastTxPrivate
+func (c *Local) BroadcastTxPrivate(tx types.Tx) (*ctypes.ResultBroadcastTx, error) {
+	return core.BroadcastTxPrivate(c.ctx, tx)
+}
+
 func (c *Local) UnconfirmedTxs(limit int) (*ctypes.ResultUnconfirmedTxs, error) {
 	return core.UnconfirmedTxs(c.ctx, limit)
 }
@@ -3,6 +3,7 @@ package core
 import (
 	abci "github.com/okex/exchain/libs/tendermint/abci/types"
 	"github.com/okex/exchain/libs/tendermint/libs/bytes"
+	"github.com/okex/exchain/libs/tendermint/libs/tracing"
 	"github.com/okex/exchain/libs/tendermint/proxy"
 	ctypes "github.com/okex/exchain/libs/tendermint/rpc/core/types"
 	rpctypes "github.com/okex/exchain/libs/tendermint/rpc/jsonrpc/types"
@@ -17,6 +18,11 @@ func ABCIQuery(
 	height int64,
 	prove bool,
 ) (*ctypes.ResultABCIQuery, error) {
+	span := tracing.StartSpan("tendermint abci_query")
+	span.SetAttribute("path", path)
+	span.SetAttribute("height", height)
+	defer span.End()
+
 	resQuery, err := env.ProxyAppQuery.QuerySync(abci.RequestQuery{
 		Path:   path,
 		Data:   data,
@@ -24,6 +30,7 @@ func ABCIQuery(
 		Prove:  prove,
 	})
 	if err != nil {
+		span.SetError(err)
 		return nil, err
 	}
 	env.Logger.Info("ABCIQuery", "path", path, "data", data, "result", resQuery)
@@ -62,6 +62,12 @@ type peers interface {
 	Peers() p2p.IPeerSet
 }
 
+type standby interface {
+	IsActive() bool
+	Promote() error
+	Demote() error
+}
+
 //----------------------------------------------
 // Environment contains objects and interfaces used by the RPC. It is expected
 // to be setup once during startup.
@@ -84,6 +90,8 @@ type Environment struct {
 	ConsensusReactor *consensus.Reactor
 	EventBus         *types.EventBus // thread safe
 	Mempool          mempl.Mempool
+	// Standby is nil unless the node runs in standby mode
+	Standby standby
 
 	Logger log.Logger
 
@@ -22,6 +22,9 @@ import (
 // CheckTx nor DeliverTx results.
 // More: https://docs.tendermint.com/master/rpc/#/Tx/broadcast_tx_async
 func BroadcastTxAsync(ctx *rpctypes.Context, tx types.Tx) (*ctypes.ResultBroadcastTx, error) {
+	if err := checkStandby(); err != nil {
+		return nil, err
+	}
 	err := env.Mempool.CheckTx(tx, nil, mempl.TxInfo{})
 
 	if err != nil {
@@ -34,10 +37,23 @@ func BroadcastTxAsync(ctx *rpctypes.Context, tx types.Tx) (*ctypes.ResultBroadca
 // DeliverTx result.
 // More: https://docs.tendermint.com/master/rpc/#/Tx/broadcast_tx_sync
 func BroadcastTxSync(ctx *rpctypes.Context, tx types.Tx) (*ctypes.ResultBroadcastTx, error) {
+	return broadcastTxSync(tx, mempl.TxInfo{})
+}
+
+// BroadcastTxPrivate returns with the response from CheckTx like BroadcastTxSync, but the tx is kept private: it's
+// proposed by the node once it's the proposer and is never gossiped to its peers nor published as pending.
+func BroadcastTxPrivate(ctx *rpctypes.Context, tx types.Tx) (*ctypes.ResultBroadcastTx, error) {
+	return broadcastTxSync(tx, mempl.TxInfo{Private: true})
+}
+
+func broadcastTxSync(tx types.Tx, txInfo mempl.TxInfo) (*ctypes.ResultBroadcastTx, error) {
+	if err := checkStandby(); err != nil {
+		return nil, err
+	}
 	resCh := make(chan *abci.Response, 1)
 	err := env.Mempool.CheckTx(tx, func(res *abci.Response) {
 		resCh <- res
-	}, mempl.TxInfo{})
+	}, txInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -55,6 +71,9 @@ func BroadcastTxSync(ctx *rpctypes.Context, tx types.Tx) (*ctypes.ResultBroadcas
 // BroadcastTxCommit returns with the responses from CheckTx and DeliverTx.
 // More: https://docs.tendermint.com/master/rpc/#/Tx/broadcast_tx_commit
 func BroadcastTxCommit(ctx *rpctypes.Context, tx types.Tx) (*ctypes.ResultBroadcastTxCommit, error) {
+	if err := checkStandby(); err != nil {
+		return nil, err
+	}
 	subscriber := ctx.RemoteAddr()
 
 	if env.EventBus.NumClients() >= env.Config.MaxSubscriptionClients {
@@ -34,11 +34,13 @@ var Routes = map[string]*rpc.RPCFunc{
 	"user_unconfirmed_txs":     rpc.NewRPCFunc(UserUnconfirmedTxs, "address,limit"),
 	"user_num_unconfirmed_txs": rpc.NewRPCFunc(UserNumUnconfirmedTxs, "address"),
 	"get_address_list":         rpc.NewRPCFunc(GetAddressList, ""),
+	"standby":                  rpc.NewRPCFunc(Standby, ""),
 
 	// tx broadcast API
-	"broadcast_tx_commit": rpc.NewRPCFunc(BroadcastTxCommit, "tx"),
-	"broadcast_tx_sync":   rpc.NewRPCFunc(BroadcastTxSync, "tx"),
-	"broadcast_tx_async":  rpc.NewRPCFunc(BroadcastTxAsync, "tx"),
+	"broadcast_tx_commit":  rpc.NewRPCFunc(BroadcastTxCommit, "tx"),
+	"broadcast_tx_sync":    rpc.NewRPCFunc(BroadcastTxSync, "tx"),
+	"broadcast_tx_async":   rpc.NewRPCFunc(BroadcastTxAsync, "tx"),
+	"broadcast_tx_private": rpc.NewRPCFunc(BroadcastTxPrivate, "tx"),
 
 	// abci API
 	"abci_query": rpc.NewRPCFunc(ABCIQuery, "path,data,height,prove"),
@@ -53,6 +55,8 @@ func AddUnsafeRoutes() {
 	Routes["dial_seeds"] = rpc.NewRPCFunc(UnsafeDialSeeds, "seeds")
 	Routes["dial_peers"] = rpc.NewRPCFunc(UnsafeDialPeers, "peers,persistent")
 	Routes["unsafe_flush_mempool"] = rpc.NewRPCFunc(UnsafeFlushMempool, "")
+	Routes["unsafe_promote_standby"] = rpc.NewRPCFunc(UnsafePromoteStandby, "")
+	Routes["unsafe_demote_standby"] = rpc.NewRPCFunc(UnsafeDemoteStandby, "")
 
 	// profiler API
 	Routes["unsafe_start_cpu_profiler"] = rpc.NewRPCFunc(UnsafeStartCPUProfiler, "filename")
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"errors"
+
+	ctypes "github.com/okex/exchain/libs/tendermint/rpc/core/types"
+	rpctypes "github.com/okex/exchain/libs/tendermint/rpc/jsonrpc/types"
+)
+
+var errStandby = errors.New("the node is in standby, the txs are not accepted until it is promoted")
+
+// Standby returns whether the node runs in standby mode and whether it is promoted.
+func Standby(ctx *rpctypes.Context) (*ctypes.ResultStandby, error) {
+	return standbyResult(), nil
+}
+
+// UnsafePromoteStandby promotes the standby node: it takes the signer lock, then signs and accepts txs.
+func UnsafePromoteStandby(ctx *rpctypes.Context) (*ctypes.ResultStandby, error) {
+	if env.Standby == nil {
+		return nil, errors.New("the node is not in standby mode")
+	}
+	if err := env.Standby.Promote(); err != nil {
+		return nil, err
+	}
+	env.Logger.Info("Promoted the standby node", "remote", ctx.RemoteAddr())
+	return standbyResult(), nil
+}
+
+// UnsafeDemoteStandby demotes the node back to standby and releases the signer lock.
+func UnsafeDemoteStandby(ctx *rpctypes.Context) (*ctypes.ResultStandby, error) {
+	if env.Standby == nil {
+		return nil, errors.New("the node is not in standby mode")
+	}
+	if err := env.Standby.Demote(); err != nil {
+		return nil, err
+	}
+	env.Logger.Info("Demoted the node to standby", "remote", ctx.RemoteAddr())
+	return standbyResult(), nil
+}
+
+func standbyResult() *ctypes.ResultStandby {
+	if env.Standby == nil {
+		return &ctypes.ResultStandby{}
+	}
+	return &ctypes.ResultStandby{Standby: true, Active: env.Standby.IsActive()}
+}
+
+// checkStandby refuses the writes of a standby node not promoted yet
+func checkStandby() error {
+	if env.Standby != nil && !env.Standby.IsActive() {
+		return errStandby
+	}
+	return nil
+}
@@ -224,6 +224,14 @@ type ResultBroadcastEvidence struct {
 	Hash []byte `json:"hash"`
 }
 
+// Standby state of the node
+type ResultStandby struct {
+	// Standby is true if the node runs in standby mode
+	Standby bool `json:"standby"`
+	// Active is true once the node is promoted
+	Active bool `json:"active"`
+}
+
 // empty results
 type (
 	ResultUnsafeFlushMempool struct{}
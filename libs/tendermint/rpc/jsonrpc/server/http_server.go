@@ -13,6 +13,8 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 	"golang.org/x/net/netutil"
 
 	"github.com/okex/exchain/libs/tendermint/libs/log"
@@ -32,6 +34,13 @@ type Config struct {
 	MaxBodyBytes int64
 	// mirrors http.Server#MaxHeaderBytes
 	MaxHeaderBytes int
+	// mirrors http.Server#IdleTimeout, the read timeout is used if it's 0
+	IdleTimeout time.Duration
+	// EnableHTTP2 serves HTTP/2 over TLS and over cleartext (h2c) besides HTTP/1.1
+	EnableHTTP2 bool
+	// MaxConcurrentStreams is the max number of concurrent streams of a HTTP/2
+	// connection, 0 for the default of http2
+	MaxConcurrentStreams uint32
 }
 
 // DefaultConfig returns a default configuration.
@@ -52,13 +61,11 @@ func DefaultConfig() *Config {
 // NOTE: This function blocks - you may want to call it in a go-routine.
 func Serve(listener net.Listener, handler http.Handler, logger log.Logger, config *Config) error {
 	logger.Info(fmt.Sprintf("Starting RPC HTTP server on %s", listener.Addr()))
-	s := &http.Server{
-		Handler:        RecoverAndLogHandler(maxBytesHandler{h: handler, n: config.MaxBodyBytes}, logger),
-		ReadTimeout:    config.ReadTimeout,
-		WriteTimeout:   config.WriteTimeout,
-		MaxHeaderBytes: config.MaxHeaderBytes,
+	s, err := NewHTTPServer(handler, logger, config)
+	if err != nil {
+		return err
 	}
-	err := s.Serve(listener)
+	err = s.Serve(listener)
 	logger.Info("RPC HTTP server stopped", "err", err)
 	return err
 }
@@ -77,16 +84,50 @@ func ServeTLS(
 ) error {
 	logger.Info(fmt.Sprintf("Starting RPC HTTPS server on %s (cert: %q, key: %q)",
 		listener.Addr(), certFile, keyFile))
+	s, err := NewHTTPServer(handler, logger, config)
+	if err != nil {
+		return err
+	}
+	err = s.ServeTLS(listener, certFile, keyFile)
+
+	logger.Error("RPC HTTPS server stopped", "err", err)
+	return err
+}
+
+// NewHTTPServer creates the http.Server Serve and ServeTLS serve the handler
+// with, for the callers which shut it down gracefully. It wraps handler with
+// RecoverAndLogHandler and a handler, which limits the max body size to
+// config.MaxBodyBytes.
+func NewHTTPServer(handler http.Handler, logger log.Logger, config *Config) (*http.Server, error) {
 	s := &http.Server{
 		Handler:        RecoverAndLogHandler(maxBytesHandler{h: handler, n: config.MaxBodyBytes}, logger),
 		ReadTimeout:    config.ReadTimeout,
 		WriteTimeout:   config.WriteTimeout,
 		MaxHeaderBytes: config.MaxHeaderBytes,
+		IdleTimeout:    config.IdleTimeout,
 	}
-	err := s.ServeTLS(listener, certFile, keyFile)
+	if err := configureHTTP2(s, config); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
 
-	logger.Error("RPC HTTPS server stopped", "err", err)
-	return err
+// configureHTTP2 enables HTTP/2 on the server if the config enables it. The
+// listeners terminating TLS themselves must negotiate "h2" with ALPN.
+func configureHTTP2(s *http.Server, config *Config) error {
+	if !config.EnableHTTP2 {
+		return nil
+	}
+	h2s := &http2.Server{
+		MaxConcurrentStreams: config.MaxConcurrentStreams,
+		IdleTimeout:          config.IdleTimeout,
+	}
+	if err := http2.ConfigureServer(s, h2s); err != nil {
+		return err
+	}
+	// the clients with prior knowledge of HTTP/2 use it over cleartext
+	s.Handler = h2c.NewHandler(s.Handler, h2s)
+	return nil
 }
 
 func WriteRPCResponseHTTPError(
@@ -14,6 +14,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
 
 	"github.com/okex/exchain/libs/tendermint/libs/log"
 )
@@ -92,3 +93,39 @@ func TestServeTLS(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, []byte("some body"), body)
 }
+
+func TestServeHTTP2(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Proto)
+	})
+	config := DefaultConfig()
+	config.EnableHTTP2 = true
+	config.MaxConcurrentStreams = 10
+	l, err := Listen("tcp://127.0.0.1:0", config)
+	require.NoError(t, err)
+	defer l.Close()
+	go Serve(l, mux, log.TestingLogger(), config)
+
+	// HTTP/2 over cleartext with prior knowledge
+	h2c := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}}
+	res, err := h2c.Get("http://" + l.Addr().String())
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP/2.0", string(body))
+
+	// HTTP/1.1 is still served
+	res, err = http.Get("http://" + l.Addr().String())
+	require.NoError(t, err)
+	body, err = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP/1.1", string(body))
+}
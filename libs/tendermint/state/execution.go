@@ -280,6 +280,7 @@ func (blockExec *BlockExecutor) runAbci(block *types.Block, delta *types.Deltas)
 		blockExec.logger.Info("Apply delta", "height", block.Height,
 			"deltas", delta, "gid", gorid.GoRId)
 
+		// the txs are not delivered when applying a delta, so they don't need PreDeliverTxs
 		execBlockOnProxyAppWithDeltas(blockExec.proxyApp, block, blockExec.db)
 		err = types.Json.Unmarshal(delta.ABCIRsp, &abciResponses)
 		if err != nil {
@@ -441,6 +442,9 @@ func execBlockOnProxyApp(context *executionContext) (*ABCIResponses, error) {
 	}
 	proxyAppConn.SetResponseCallback(proxyCb)
 
+	// Let the app prepare the txs of the block in batch, e.g. verify the signatures concurrently.
+	proxyAppConn.PreDeliverTxs(transTxsToBytes(block.Txs))
+
 	commitInfo, byzVals := getBeginBlockValidatorInfo(block, stateDB)
 
 	// Begin block
@@ -24,6 +24,9 @@ func execBlockOnProxyAppAsync(
 
 	abciResponses := NewABCIResponses(block)
 
+	// Let the app prepare the txs of the block in batch, e.g. verify the signatures concurrently.
+	proxyAppConn.PreDeliverTxs(transTxsToBytes(block.Txs))
+
 	commitInfo, byzVals := getBeginBlockValidatorInfo(block, stateDB)
 
 	// Begin block
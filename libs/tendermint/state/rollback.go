@@ -0,0 +1,72 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+
+	dbm "github.com/tendermint/tm-db"
+)
+
+// Rollback overwrites the latest state with the state after the block at the given height. It is rebuilt
+// from the validators and the consensus params saved per height, and from the headers of the block at that
+// height and of the next one, which must still be in the block store. The blocks above the height are left
+// to the caller to delete. Rolling back to the height of the latest state is a no-op.
+func Rollback(db dbm.DB, blockStore BlockStore, height int64) (State, error) {
+	latest := LoadState(db)
+	if latest.IsEmpty() {
+		return State{}, errors.New("no state found")
+	}
+	if height == latest.LastBlockHeight {
+		return latest, nil
+	}
+	if height <= 0 || height > latest.LastBlockHeight {
+		return State{}, fmt.Errorf("cannot roll back to height %d, the latest state is at height %d",
+			height, latest.LastBlockHeight)
+	}
+
+	rollbackBlock, nextBlock := blockStore.LoadBlockMeta(height), blockStore.LoadBlockMeta(height+1)
+	if rollbackBlock == nil || nextBlock == nil {
+		return State{}, fmt.Errorf("the blocks at height %d and %d must be in the block store", height, height+1)
+	}
+
+	lastValidators, err := LoadValidators(db, height)
+	if err != nil {
+		return State{}, err
+	}
+	validators, err := LoadValidators(db, height+1)
+	if err != nil {
+		return State{}, err
+	}
+	nextValidators, err := LoadValidators(db, height+2)
+	if err != nil {
+		return State{}, err
+	}
+	consensusParams, err := LoadConsensusParams(db, height+1)
+	if err != nil {
+		return State{}, err
+	}
+
+	// the validators of height+2 and the consensus params of height+1 were saved with the state at the
+	// height, with its last heights changed
+	state := State{
+		Version: latest.Version,
+		ChainID: latest.ChainID,
+
+		LastBlockHeight: height,
+		LastBlockID:     rollbackBlock.BlockID,
+		LastBlockTime:   rollbackBlock.Header.Time,
+
+		NextValidators:              nextValidators,
+		Validators:                  validators,
+		LastValidators:              lastValidators,
+		LastHeightValidatorsChanged: loadValidatorsInfo(db, height+2).LastHeightChanged,
+
+		ConsensusParams:                  consensusParams,
+		LastHeightConsensusParamsChanged: loadConsensusParamsInfo(db, height+1).LastHeightChanged,
+
+		LastResultsHash: nextBlock.Header.LastResultsHash,
+		AppHash:         nextBlock.Header.AppHash,
+	}
+	SaveState(db, state)
+	return state, nil
+}
@@ -261,6 +261,51 @@ func (bs *BlockStore) PruneBlocks(height int64) (uint64, error) {
 	return pruned, nil
 }
 
+// DeleteBlocksFromTop removes the blocks above a height, which becomes the latest height of the store. It
+// returns the number of blocks deleted.
+func (bs *BlockStore) DeleteBlocksFromTop(height int64) (uint64, error) {
+	bs.mtx.RLock()
+	base, top := bs.base, bs.height
+	bs.mtx.RUnlock()
+	if height < base {
+		return 0, fmt.Errorf("cannot delete the blocks down to height %v, it is lower than base height %v",
+			height, base)
+	}
+	if height >= top {
+		return 0, nil
+	}
+
+	deleted := uint64(0)
+	batch := bs.db.NewBatch()
+	defer batch.Close()
+	for h := top; h > height; h-- {
+		meta := bs.LoadBlockMeta(h)
+		if meta == nil { // assume already deleted
+			continue
+		}
+		batch.Delete(calcBlockMetaKey(h))
+		batch.Delete(calcBlockHashKey(meta.BlockID.Hash))
+		// the commit of the previous block is saved with the block
+		batch.Delete(calcBlockCommitKey(h - 1))
+		batch.Delete(calcSeenCommitKey(h))
+		for p := 0; p < meta.BlockID.PartsHeader.Total; p++ {
+			batch.Delete(calcBlockPartKey(h, p))
+		}
+		deleted++
+	}
+
+	// update the height first, so that noone tries to access the blocks being deleted
+	bs.mtx.Lock()
+	bs.height = height
+	bs.mtx.Unlock()
+	bs.saveState()
+
+	if err := batch.WriteSync(); err != nil {
+		return 0, fmt.Errorf("failed to delete the blocks down to height %v: %w", height, err)
+	}
+	return deleted, nil
+}
+
 // SaveBlock persists the given block, blockParts, and seenCommit to the underlying db.
 // blockParts: Must be parts of the block
 // seenCommit: The +2/3 precommits that were seen which committed at height.
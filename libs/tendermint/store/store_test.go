@@ -472,6 +472,55 @@ func TestPruneBlocks(t *testing.T) {
 	assert.Nil(t, bs.LoadBlock(1501))
 }
 
+func TestDeleteBlocksFromTop(t *testing.T) {
+	config := cfg.ResetTestRoot("blockchain_reactor_test")
+	defer os.RemoveAll(config.RootDir)
+	state, err := sm.LoadStateFromDBOrGenesisFile(dbm.NewMemDB(), config.GenesisFile())
+	require.NoError(t, err)
+	db := dbm.NewMemDB()
+	bs := NewBlockStore(db)
+
+	for h := int64(1); h <= 20; h++ {
+		block := makeBlock(h, state, new(types.Commit))
+		partSet := block.MakePartSet(2)
+		seenCommit := makeTestCommit(h, tmtime.Now())
+		bs.SaveBlock(block, partSet, seenCommit)
+	}
+	_, err = bs.PruneBlocks(5)
+	require.NoError(t, err)
+	deletedBlock := bs.LoadBlock(16)
+
+	deleted, err := bs.DeleteBlocksFromTop(15)
+	require.NoError(t, err)
+	assert.EqualValues(t, 5, deleted)
+	assert.EqualValues(t, 5, bs.Base())
+	assert.EqualValues(t, 15, bs.Height())
+	assert.EqualValues(t, BlockStoreStateJSON{
+		Base:   5,
+		Height: 15,
+	}, LoadBlockStoreStateJSON(db))
+
+	require.NotNil(t, bs.LoadBlock(15))
+	require.NotNil(t, bs.LoadSeenCommit(15))
+	require.Nil(t, bs.LoadBlock(16))
+	require.Nil(t, bs.LoadBlockByHash(deletedBlock.Hash()))
+	require.Nil(t, bs.LoadBlockCommit(15))
+	require.Nil(t, bs.LoadSeenCommit(16))
+	require.Nil(t, bs.LoadBlockPart(16, 0))
+
+	// the blocks can be saved again from the new height
+	block := makeBlock(16, state, new(types.Commit))
+	bs.SaveBlock(block, block.MakePartSet(2), makeTestCommit(16, tmtime.Now()))
+	assert.EqualValues(t, 16, bs.Height())
+
+	// deleting down to the height or above is a no-op, below the base errors
+	deleted, err = bs.DeleteBlocksFromTop(16)
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, deleted)
+	_, err = bs.DeleteBlocksFromTop(4)
+	require.Error(t, err)
+}
+
 func TestLoadBlockMeta(t *testing.T) {
 	bs, db := freshBlockStore()
 	height := int64(10)
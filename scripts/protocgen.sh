@@ -0,0 +1,12 @@
+#!/usr/bin/env bash
+
+set -eo pipefail
+
+proto_dirs=$(find ./x -name '*.proto' -print0 | xargs -0 -n1 dirname | sort | uniq)
+for dir in $proto_dirs; do
+  protoc \
+  -I. \
+  -Ilibs/tendermint \
+  --gogo_out=Mgoogle/protobuf/any.proto=github.com/gogo/protobuf/types,plugins=grpc,paths=source_relative:. \
+  $(find "${dir}" -maxdepth 1 -name '*.proto')
+done
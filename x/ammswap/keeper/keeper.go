@@ -196,6 +196,14 @@ func GetInputPrice(inputAmount, inputReserve, outputReserve, feeRate sdk.Dec) sd
 	return common.MulAndQuo(inputAmountWithFee, outputReserve, denominator)
 }
 
+// GetOutputPrice returns the input amount to sell to buy the output amount, the inverse of GetInputPrice. It's
+// rounded up so that the pool never sells the output amount for less than its price.
+func GetOutputPrice(outputAmount, inputReserve, outputReserve, feeRate sdk.Dec) sdk.Dec {
+	numerator := inputReserve.Mul(outputAmount)
+	denominator := outputReserve.Sub(outputAmount).MulTruncate(sdk.OneDec().Sub(feeRate))
+	return numerator.QuoRoundUp(denominator)
+}
+
 func (k *Keeper) SetObserverKeeper(bk types.BackendKeeper) {
 	k.ObserverKeeper = append(k.ObserverKeeper, bk)
 }
@@ -89,3 +89,18 @@ func TestGetInputPrice(t *testing.T) {
 	expectedAmount := sdk.NewDec(0)
 	require.Equal(t, expectedAmount.String(), outputAmount.String())
 }
+
+func TestGetOutputPrice(t *testing.T) {
+	inputReserve := sdk.NewDec(100)
+	outputReserve := sdk.NewDec(200)
+	feeRate := sdk.NewDecWithPrec(3, 3)
+
+	// 100 * 10 / ((200 - 10) * 0.997)
+	inputAmount := GetOutputPrice(sdk.NewDec(10), inputReserve, outputReserve, feeRate)
+	require.Equal(t, "5.278994879374967007", inputAmount.String())
+
+	// selling the input amount buys at least the output amount
+	outputAmount := GetInputPrice(inputAmount, inputReserve, outputReserve, feeRate)
+	require.True(t, outputAmount.GTE(sdk.NewDec(10)), outputAmount.String())
+	require.True(t, outputAmount.Sub(sdk.NewDec(10)).LT(sdk.NewDecWithPrec(1, 15)), outputAmount.String())
+}
@@ -0,0 +1,100 @@
+package monitor
+
+import (
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+// EvmMetrics is the struct of metric of the evm module. The contracts are not used as labels, since their number
+// is unbounded: the per contract profile is served by the evm profile query.
+type EvmMetrics struct {
+	ContractGasUsed metrics.Counter
+	ContractCalls   metrics.Counter
+	Opcodes         metrics.Counter
+	SigCacheHits    metrics.Counter
+	SigCacheMisses  metrics.Counter
+
+	StateCacheHits   metrics.Counter
+	StateCacheMisses metrics.Counter
+	PrefetchedSlots  metrics.Counter
+}
+
+// DefaultEvmMetrics returns Metrics build using Prometheus client library if Prometheus is enabled
+// Otherwise, it returns no-op Metrics
+func DefaultEvmMetrics(config *prometheusConfig) *EvmMetrics {
+	if config.Prometheus {
+		return NewEvmMetrics()
+	}
+	return NopEvmMetrics()
+}
+
+// NewEvmMetrics returns a pointer of a new EvmMetrics object
+func NewEvmMetrics() *EvmMetrics {
+	return &EvmMetrics{
+		ContractGasUsed: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: xNameSpace,
+			Subsystem: evmSubSystem,
+			Name:      "contract_gas_used",
+			Help:      "the gas consumed by the executions of the contracts",
+		}, []string{}),
+		ContractCalls: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: xNameSpace,
+			Subsystem: evmSubSystem,
+			Name:      "contract_calls",
+			Help:      "the number of calls to the contracts",
+		}, []string{}),
+		Opcodes: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: xNameSpace,
+			Subsystem: evmSubSystem,
+			Name:      "opcodes",
+			Help:      "the number of executed opcodes",
+		}, []string{"opcode"}),
+		SigCacheHits: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: xNameSpace,
+			Subsystem: evmSubSystem,
+			Name:      "sig_cache_hits",
+			Help:      "the number of evm tx senders found in the signature cache",
+		}, nil),
+		SigCacheMisses: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: xNameSpace,
+			Subsystem: evmSubSystem,
+			Name:      "sig_cache_misses",
+			Help:      "the number of evm tx senders recovered from the signature",
+		}, nil),
+		StateCacheHits: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: xNameSpace,
+			Subsystem: evmSubSystem,
+			Name:      "state_cache_hits",
+			Help:      "the number of evm accounts and storage slots found in the state cache of the block",
+		}, nil),
+		StateCacheMisses: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: xNameSpace,
+			Subsystem: evmSubSystem,
+			Name:      "state_cache_misses",
+			Help:      "the number of evm accounts and storage slots read from the store",
+		}, nil),
+		PrefetchedSlots: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: xNameSpace,
+			Subsystem: evmSubSystem,
+			Name:      "prefetched_slots",
+			Help:      "the number of evm storage slots prefetched before the execution of the txs",
+		}, nil),
+	}
+}
+
+// NopEvmMetrics returns a pointer of a no-op Metrics
+func NopEvmMetrics() *EvmMetrics {
+	return &EvmMetrics{
+		ContractGasUsed: discard.NewCounter(),
+		ContractCalls:   discard.NewCounter(),
+		Opcodes:         discard.NewCounter(),
+		SigCacheHits:    discard.NewCounter(),
+		SigCacheMisses:  discard.NewCounter(),
+
+		StateCacheHits:   discard.NewCounter(),
+		StateCacheMisses: discard.NewCounter(),
+		PrefetchedSlots:  discard.NewCounter(),
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/okex/exchain/libs/cosmos-sdk/client"
+	"github.com/okex/exchain/libs/cosmos-sdk/client/flags"
 	"github.com/okex/exchain/libs/cosmos-sdk/client/context"
 	"github.com/okex/exchain/libs/cosmos-sdk/codec"
 	"github.com/okex/exchain/x/debug/types"
@@ -21,7 +21,7 @@ func GetDebugCmd(cdc *codec.Codec) *cobra.Command {
 		Short: "Debugging subcommands",
 	}
 
-	queryCmd.AddCommand(client.GetCommands(
+	queryCmd.AddCommand(flags.GetCommands(
 		CmdSetLogLevel(queryRoute, cdc),
 		CmdDumpStore(queryRoute, cdc),
 		CmdSanityCheck(queryRoute, cdc),
@@ -47,3 +47,15 @@ func (k Keeper) distributeFromFeePool(ctx sdk.Context, amount sdk.Coins, receive
 	k.SetFeePool(ctx, feePool)
 	return nil
 }
+
+// FundCommunityPoolFromModule sends coins from a module account to the community pool
+func (k Keeper) FundCommunityPoolFromModule(ctx sdk.Context, amount sdk.SysCoins, senderModule string) error {
+	if err := k.supplyKeeper.SendCoinsFromModuleToModule(ctx, senderModule, types.ModuleName, amount); err != nil {
+		return err
+	}
+
+	feePool := k.GetFeePool(ctx)
+	feePool.CommunityPool = feePool.CommunityPool.Add(amount...)
+	k.SetFeePool(ctx, feePool)
+	return nil
+}
@@ -18,10 +18,17 @@ var (
 	NewKeeper         = keeper.NewKeeper
 	TxDecoder         = types.TxDecoder
 	NewSimulateKeeper = keeper.NewSimulateKeeper
+	NewQueryServer    = keeper.NewQueryServer
+
+	RestoreSnapshotMigration = keeper.RestoreSnapshotMigration
+	RenameParamMigration     = keeper.RenameParamMigration
+	RekeyStoreMigration      = keeper.RekeyStoreMigration
+	TransformStoreMigration  = keeper.TransformStoreMigration
 )
 
 //nolint
 type (
 	Keeper       = keeper.Keeper
+	Migration    = keeper.Migration
 	GenesisState = types.GenesisState
 )
@@ -35,6 +35,11 @@ func GetQueryCmd(moduleName string, cdc *codec.Codec) *cobra.Command {
 		GetCmdQueryContractDeploymentWhitelist(moduleName, cdc),
 		GetCmdQueryContractBlockedList(moduleName, cdc),
 		GetCmdQueryContractMethodeBlockedList(moduleName, cdc),
+		GetCmdQueryUpgradeSnapshot(moduleName, cdc),
+		GetCmdDryRunMigrations(moduleName, cdc),
+		GetCmdQueryTokenRegistry(moduleName, cdc),
+		GetCmdQueryContractGasQuota(moduleName, cdc),
+		GetCmdQueryMinGasPrice(moduleName, cdc),
 	)...)
 	return evmQueryCmd
 }
@@ -263,3 +268,167 @@ $ exchaincli query evm params
 		},
 	}
 }
+
+// GetCmdQueryUpgradeSnapshot gets the upgrade snapshot query command.
+func GetCmdQueryUpgradeSnapshot(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "upgrade-snapshot [upgrade-name]",
+		Short: "Query the evm params and chain config saved before the migrations of an upgrade",
+		Long: strings.TrimSpace(
+			fmt.Sprintf(`Query the evm params and chain config saved before the evm migrations of an upgrade were run.
+
+Example:
+$ %s query evm upgrade-snapshot v1.2.0
+`,
+				version.ClientName,
+			),
+		),
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			route := fmt.Sprintf("custom/%s/%s/%s", queryRoute, types.QueryUpgradeSnapshot, args[0])
+			bz, _, err := cliCtx.QueryWithData(route, nil)
+			if err != nil {
+				return err
+			}
+
+			var snapshot types.UpgradeSnapshot
+			cdc.MustUnmarshalJSON(bz, &snapshot)
+			return cliCtx.PrintOutput(snapshot)
+		},
+	}
+}
+
+// GetCmdDryRunMigrations gets the command running the evm migrations of an upgrade without writing them.
+func GetCmdDryRunMigrations(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "dry-run-migrations [upgrade-name]",
+		Short: "Run the evm migrations of an upgrade on the current state without writing them",
+		Long: strings.TrimSpace(
+			fmt.Sprintf(`Run the evm migrations registered for an upgrade by the binary of the queried node on top of
+the current state, or of the state at the given height, without writing any change. It reports the evm params
+and chain config before and after the migrations, and the error of the first failed migration.
+
+Example:
+$ %s query evm dry-run-migrations v1.2.0 --height 1000
+`,
+				version.ClientName,
+			),
+		),
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			route := fmt.Sprintf("custom/%s/%s/%s", queryRoute, types.QueryDryRunMigrations, args[0])
+			bz, _, err := cliCtx.QueryWithData(route, nil)
+			if err != nil {
+				return err
+			}
+
+			var report types.MigrationReport
+			cdc.MustUnmarshalJSON(bz, &report)
+			return cliCtx.PrintOutput(report)
+		},
+	}
+}
+
+// GetCmdQueryTokenRegistry gets the token registry query command.
+func GetCmdQueryTokenRegistry(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "token-registry [denom]",
+		Short: "Query the erc20 metadata of the ibc denoms and bridged assets of the token registry",
+		Long: strings.TrimSpace(
+			fmt.Sprintf(`Query the erc20 metadata of a denom of the token registry, or of all its tokens without a denom.
+
+Example:
+$ %s query evm token-registry ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2
+`,
+				version.ClientName,
+			),
+		),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			route := fmt.Sprintf("custom/%s/%s", queryRoute, types.QueryTokenRegistry)
+			if len(args) == 1 {
+				route = fmt.Sprintf("%s/%s", route, args[0])
+			}
+			bz, _, err := cliCtx.QueryWithData(route, nil)
+			if err != nil {
+				return err
+			}
+
+			if len(args) == 1 {
+				var metadata types.TokenMetadata
+				cdc.MustUnmarshalJSON(bz, &metadata)
+				return cliCtx.PrintOutput(metadata)
+			}
+			var registry []types.TokenMetadata
+			cdc.MustUnmarshalJSON(bz, &registry)
+			return cliCtx.PrintOutput(registry)
+		},
+	}
+}
+
+// GetCmdQueryMinGasPrice gets the min gas price floor query command.
+func GetCmdQueryMinGasPrice(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "min-gas-price",
+		Short: "Query the min gas price floor of the evm txs and the gas price votes of the validators",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			route := fmt.Sprintf("custom/%s/%s", queryRoute, types.QueryMinGasPrice)
+			bz, _, err := cliCtx.QueryWithData(route, nil)
+			if err != nil {
+				return err
+			}
+
+			var res types.QueryResMinGasPrice
+			cdc.MustUnmarshalJSON(bz, &res)
+			return cliCtx.PrintOutput(res)
+		},
+	}
+}
+
+// GetCmdQueryContractGasQuota gets the contract gas quota query command.
+func GetCmdQueryContractGasQuota(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "contract-gas-quota [contract-address]",
+		Short: "Query the gas per block quotas of the contracts along with their gas used in the current block",
+		Long: strings.TrimSpace(
+			fmt.Sprintf(`Query the gas per block quota of a contract, or of all the contracts with a quota without an address.
+
+Example:
+$ %s query evm contract-gas-quota ex1k0wwsg7xf9tjt3rvxdewz42e74sp286agrf9qc
+`,
+				version.ClientName,
+			),
+		),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			route := fmt.Sprintf("custom/%s/%s", queryRoute, types.QueryContractGasQuota)
+			if len(args) == 1 {
+				route = fmt.Sprintf("%s/%s", route, args[0])
+			}
+			bz, _, err := cliCtx.QueryWithData(route, nil)
+			if err != nil {
+				return err
+			}
+
+			if len(args) == 1 {
+				var quota types.ContractGasQuotaUsage
+				cdc.MustUnmarshalJSON(bz, &quota)
+				return cliCtx.PrintOutput(quota)
+			}
+			var quotas []types.ContractGasQuotaUsage
+			cdc.MustUnmarshalJSON(bz, &quotas)
+			return cliCtx.PrintOutput(quotas)
+		},
+	}
+}
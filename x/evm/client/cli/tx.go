@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/okex/exchain/libs/cosmos-sdk/client/context"
+	"github.com/okex/exchain/libs/cosmos-sdk/client/flags"
 	"github.com/okex/exchain/libs/cosmos-sdk/codec"
 	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
 	"github.com/okex/exchain/libs/cosmos-sdk/version"
@@ -17,6 +18,11 @@ import (
 	"github.com/spf13/cobra"
 )
 
+const (
+	flagSpendLimit = "spend-limit"
+	flagExpiration = "expiration"
+)
+
 // GetCmdManageContractDeploymentWhitelistProposal implements a command handler for submitting a manage contract deployment
 // whitelist proposal transaction
 func GetCmdManageContractDeploymentWhitelistProposal(cdc *codec.Codec) *cobra.Command {
@@ -223,3 +229,313 @@ Where proposal.json contains:
 		},
 	}
 }
+
+// GetCmdManageChainConfigForksProposal implements a command handler for submitting a manage chain config forks proposal
+// transaction
+func GetCmdManageChainConfigForksProposal(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "update-chain-config-forks [proposal-file]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Submit an update chain config forks proposal",
+		Long: strings.TrimSpace(
+			fmt.Sprintf(`Submit a proposal scheduling the berlin, london and shanghai switch blocks along with an initial deposit.
+A negative block disables the fork. A fork can only be scheduled above the height at which the proposal passes.
+The proposal details must be supplied via a JSON file.
+
+Example:
+$ %s tx gov submit-proposal update-chain-config-forks <path/to/proposal.json> --from=<key_or_address>
+
+Where proposal.json contains:
+
+{
+  "title": "activate berlin, london and shanghai",
+  "description": "schedule the berlin, london and shanghai switch blocks",
+  "berlin_block": "5000000",
+  "london_block": "5000000",
+  "shanghai_block": "5000000",
+  "deposit": [
+    {
+      "denom": "%s",
+      "amount": "100.000000000000000000"
+    }
+  ]
+}
+`, version.ClientName, sdk.DefaultBondDenom,
+			)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			proposal, err := evmutils.ParseManageChainConfigForksProposalJSON(cdc, args[0])
+			if err != nil {
+				return err
+			}
+
+			content := types.NewManageChainConfigForksProposal(
+				proposal.Title,
+				proposal.Description,
+				proposal.BerlinBlock,
+				proposal.LondonBlock,
+				proposal.ShanghaiBlock,
+			)
+
+			err = content.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			msg := gov.NewMsgSubmitProposal(content, proposal.Deposit, cliCtx.GetFromAddress())
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+// GetTxCmd returns the transaction commands for the evm module
+func GetTxCmd(cdc *codec.Codec) *cobra.Command {
+	txCmd := &cobra.Command{
+		Use:   types.ModuleName,
+		Short: "EVM transactions subcommands",
+	}
+
+	txCmd.AddCommand(flags.PostCommands(
+		getCmdGrantFeeAllowance(cdc),
+		getCmdRevokeFeeAllowance(cdc),
+		getCmdVoteGasPrice(cdc),
+	)...)
+
+	return txCmd
+}
+
+func getCmdGrantFeeAllowance(cdc *codec.Codec) *cobra.Command {
+	var spendLimit string
+	var expiration int64
+	cmd := &cobra.Command{
+		Use:   "grant-fee-allowance [grantee]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Grant an allowance paying the gas fee of the evm txs of the grantee",
+		Long: strings.TrimSpace(
+			fmt.Sprintf(`Grant an allowance paying the gas fee of the evm txs of the grantee, replacing the previous one.
+The spend limit is the amount of %s the grantee may spend, zero means no limit. The expiration is the
+last height of the allowance, zero means it never expires.
+
+Example:
+$ %s tx evm grant-fee-allowance ex1k0wwsg7xf9tjt3rvxdewz42e74sp286agrf9qc --spend-limit 10 --expiration 1000000 --from=<key_or_address>
+`, sdk.DefaultBondDenom, version.ClientName,
+			)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			grantee, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+			limit, err := sdk.NewDecFromStr(spendLimit)
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgGrantFeeAllowance(cliCtx.GetFromAddress(), grantee, limit, expiration)
+			if err = msg.ValidateBasic(); err != nil {
+				return err
+			}
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+
+	cmd.Flags().StringVar(&spendLimit, flagSpendLimit, "0", fmt.Sprintf("The amount of %s the grantee may spend, zero means no limit", sdk.DefaultBondDenom))
+	cmd.Flags().Int64Var(&expiration, flagExpiration, 0, "The last height of the allowance, zero means it never expires")
+	return cmd
+}
+
+func getCmdRevokeFeeAllowance(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "revoke-fee-allowance [grantee]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Revoke the allowance paying the gas fee of the evm txs of the grantee",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			grantee, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgRevokeFeeAllowance(cliCtx.GetFromAddress(), grantee)
+			if err = msg.ValidateBasic(); err != nil {
+				return err
+			}
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+func getCmdVoteGasPrice(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "vote-gas-price [gas-price]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Vote the min gas price of the evm txs as the operator of a bonded validator",
+		Long: strings.TrimSpace(
+			fmt.Sprintf(`Vote the min gas price of the evm txs, in %s per gas, replacing the previous vote of the validator.
+The votes of the bonded validators are aggregated into the min gas price floor at the end of each block.
+
+Example:
+$ %s tx evm vote-gas-price 0.000000001 --from=<validator_operator_key>
+`, sdk.DefaultBondDenom, version.ClientName,
+			)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			gasPrice, err := sdk.NewDecFromStr(args[0])
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgVoteGasPrice(sdk.ValAddress(cliCtx.GetFromAddress()), gasPrice)
+			if err = msg.ValidateBasic(); err != nil {
+				return err
+			}
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+// GetCmdManageTokenRegistryProposal implements a command handler for submitting a manage token registry proposal
+// transaction
+func GetCmdManageTokenRegistryProposal(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "update-token-registry [proposal-file]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Submit an update token registry proposal",
+		Long: strings.TrimSpace(
+			fmt.Sprintf(`Submit a proposal setting or deleting the erc20 metadata of ibc denoms and bridged assets of the token
+registry, along with an initial deposit. Only the denom of the tokens is used when they are deleted.
+The proposal details must be supplied via a JSON file.
+
+Example:
+$ %s tx gov submit-proposal update-token-registry <path/to/proposal.json> --from=<key_or_address>
+
+Where proposal.json contains:
+
+{
+  "title": "register atom",
+  "description": "register the atom transferred from the cosmos hub",
+  "tokens": [
+    {
+      "denom": "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2",
+      "name": "Cosmos Hub Atom",
+      "symbol": "ATOM",
+      "decimals": 6,
+      "origin_chain": "cosmoshub-4",
+      "origin_denom": "uatom"
+    }
+  ],
+  "is_added": true,
+  "deposit": [
+    {
+      "denom": "%s",
+      "amount": "100.000000000000000000"
+    }
+  ]
+}
+`, version.ClientName, sdk.DefaultBondDenom,
+			)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			proposal, err := evmutils.ParseManageTokenRegistryProposalJSON(cdc, args[0])
+			if err != nil {
+				return err
+			}
+
+			content := types.NewManageTokenRegistryProposal(
+				proposal.Title,
+				proposal.Description,
+				proposal.Tokens,
+				proposal.IsAdded,
+			)
+
+			err = content.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			msg := gov.NewMsgSubmitProposal(content, proposal.Deposit, cliCtx.GetFromAddress())
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
+// GetCmdManageContractGasQuotaProposal implements a command handler for submitting a manage contract gas quota proposal
+// transaction
+func GetCmdManageContractGasQuotaProposal(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "update-contract-gas-quota [proposal-file]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Submit an update contract gas quota proposal",
+		Long: strings.TrimSpace(
+			fmt.Sprintf(`Submit a proposal setting or deleting the gas per block quotas of contracts, along with an initial
+deposit. The txs calling a contract are rejected once they could exceed its quota in the block. Only the txs
+calling the contract directly count, not the calls made to it by other contracts. Only the address of the quotas
+is used when they are deleted.
+The proposal details must be supplied via a JSON file.
+
+Example:
+$ %s tx gov submit-proposal update-contract-gas-quota <path/to/proposal.json> --from=<key_or_address>
+
+Where proposal.json contains:
+
+{
+  "title": "limit the gas of the contract",
+  "description": "limit the gas per block consumed by the contract to 10 percent of the block space",
+  "quotas": [
+    {
+      "address": "ex1k0wwsg7xf9tjt3rvxdewz42e74sp286agrf9qc",
+      "gas_per_block": "4000000"
+    }
+  ],
+  "is_added": true,
+  "deposit": [
+    {
+      "denom": "%s",
+      "amount": "100.000000000000000000"
+    }
+  ]
+}
+`, version.ClientName, sdk.DefaultBondDenom,
+			)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			proposal, err := evmutils.ParseManageContractGasQuotaProposalJSON(cdc, args[0])
+			if err != nil {
+				return err
+			}
+
+			content := types.NewManageContractGasQuotaProposal(
+				proposal.Title,
+				proposal.Description,
+				proposal.Quotas,
+				proposal.IsAdded,
+			)
+
+			err = content.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			msg := gov.NewMsgSubmitProposal(content, proposal.Deposit, cliCtx.GetFromAddress())
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
@@ -0,0 +1,133 @@
+package fork
+
+import (
+	"fmt"
+	"sync"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+
+	"github.com/okex/exchain/libs/cosmos-sdk/codec"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	"github.com/okex/exchain/libs/cosmos-sdk/x/auth"
+	authexported "github.com/okex/exchain/libs/cosmos-sdk/x/auth/exported"
+	rpcclient "github.com/okex/exchain/libs/tendermint/rpc/client"
+	"github.com/okex/exchain/x/evm/types"
+)
+
+// RPCSource reads the state of a remote node at a fixed height, the remote node must keep the state of that height.
+// The results are cached, the state of a height never changes.
+type RPCSource struct {
+	cdc    *codec.Codec
+	client rpcclient.ABCIClient
+	height int64
+
+	mtx      sync.RWMutex
+	accounts map[ethcmn.Address]authexported.Account
+	codes    map[string][]byte
+	states   map[ethcmn.Address]map[ethcmn.Hash][]byte
+}
+
+var _ types.ForkSource = (*RPCSource)(nil)
+
+// NewRPCSource returns the source of the state of the remote node at height
+func NewRPCSource(cdc *codec.Codec, client rpcclient.ABCIClient, height int64) *RPCSource {
+	return &RPCSource{
+		cdc:      cdc,
+		client:   client,
+		height:   height,
+		accounts: make(map[ethcmn.Address]authexported.Account),
+		codes:    make(map[string][]byte),
+		states:   make(map[ethcmn.Address]map[ethcmn.Hash][]byte),
+	}
+}
+
+// GetAccount implements the ForkSource interface
+func (s *RPCSource) GetAccount(addr ethcmn.Address) (authexported.Account, error) {
+	s.mtx.RLock()
+	acc, ok := s.accounts[addr]
+	s.mtx.RUnlock()
+	if ok {
+		return acc, nil
+	}
+
+	bz, err := s.query(auth.StoreKey, auth.AddressStoreKey(sdk.AccAddress(addr.Bytes())))
+	if err != nil {
+		return nil, err
+	}
+	if len(bz) != 0 {
+		if acc, err = s.decodeAccount(bz); err != nil {
+			return nil, fmt.Errorf("failed to decode the forked account %s: %s", addr.String(), err)
+		}
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.accounts[addr] = acc
+	return acc, nil
+}
+
+// GetCode implements the ForkSource interface
+func (s *RPCSource) GetCode(codeHash []byte) ([]byte, error) {
+	s.mtx.RLock()
+	code, ok := s.codes[string(codeHash)]
+	s.mtx.RUnlock()
+	if ok {
+		return code, nil
+	}
+
+	code, err := s.query(types.StoreKey, append(types.KeyPrefixCode, codeHash...))
+	if err != nil {
+		return nil, err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.codes[string(codeHash)] = code
+	return code, nil
+}
+
+// GetState implements the ForkSource interface
+func (s *RPCSource) GetState(addr ethcmn.Address, key ethcmn.Hash) ([]byte, error) {
+	s.mtx.RLock()
+	value, ok := s.states[addr][key]
+	s.mtx.RUnlock()
+	if ok {
+		return value, nil
+	}
+
+	value, err := s.query(types.StoreKey, append(types.AddressStoragePrefix(addr), key.Bytes()...))
+	if err != nil {
+		return nil, err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	slots, ok := s.states[addr]
+	if !ok {
+		slots = make(map[ethcmn.Hash][]byte)
+		s.states[addr] = slots
+	}
+	slots[key] = value
+	return value, nil
+}
+
+func (s *RPCSource) query(storeName string, key []byte) ([]byte, error) {
+	res, err := s.client.ABCIQueryWithOptions(fmt.Sprintf("/store/%s/key", storeName), key, rpcclient.ABCIQueryOptions{Height: s.height})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the forked node: %s", err)
+	}
+	if !res.Response.IsOK() {
+		return nil, fmt.Errorf("failed to query the forked node at height %d: %s", s.height, res.Response.Log)
+	}
+	return res.Response.Value, nil
+}
+
+// decodeAccount decodes an account the way the account keeper does
+func (s *RPCSource) decodeAccount(bz []byte) (acc authexported.Account, err error) {
+	val, err := s.cdc.UnmarshalBinaryBareWithRegisteredUnmarshaller(bz, &acc)
+	if err == nil {
+		return val.(authexported.Account), nil
+	}
+	err = s.cdc.UnmarshalBinaryBare(bz, &acc)
+	return acc, err
+}
@@ -0,0 +1,85 @@
+package fork
+
+import (
+	"testing"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/okex/exchain/app"
+	"github.com/okex/exchain/app/codec"
+	ethermint "github.com/okex/exchain/app/types"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	"github.com/okex/exchain/libs/cosmos-sdk/x/auth"
+	abci "github.com/okex/exchain/libs/tendermint/abci/types"
+	"github.com/okex/exchain/libs/tendermint/libs/bytes"
+	rpcclient "github.com/okex/exchain/libs/tendermint/rpc/client"
+	ctypes "github.com/okex/exchain/libs/tendermint/rpc/core/types"
+	"github.com/okex/exchain/x/evm/types"
+)
+
+type storeClient struct {
+	rpcclient.ABCIClient
+
+	height  int64
+	store   map[string][]byte
+	queries int
+}
+
+func (c *storeClient) ABCIQueryWithOptions(path string, data bytes.HexBytes, opts rpcclient.ABCIQueryOptions) (*ctypes.ResultABCIQuery, error) {
+	c.queries++
+	if opts.Height != c.height {
+		return &ctypes.ResultABCIQuery{Response: abci.ResponseQuery{Code: 1, Log: "height is not available"}}, nil
+	}
+	return &ctypes.ResultABCIQuery{Response: abci.ResponseQuery{Value: c.store[path+string(data)]}}, nil
+}
+
+func TestRPCSource(t *testing.T) {
+	cdc := codec.MakeCodec(app.ModuleBasics)
+	addr := ethcmn.BytesToAddress([]byte("forked"))
+	code := []byte("code")
+	acc := &ethermint.EthAccount{
+		BaseAccount: auth.NewBaseAccount(sdk.AccAddress(addr.Bytes()), nil, nil, 0, 3),
+		CodeHash:    ethcrypto.Keccak256(code),
+	}
+	key, value := ethcmn.BytesToHash([]byte("key")), ethcmn.BytesToHash([]byte("value"))
+
+	client := &storeClient{
+		height: 10,
+		store: map[string][]byte{
+			"/store/acc/key" + string(auth.AddressStoreKey(acc.Address)):                        cdc.MustMarshalBinaryBare(acc),
+			"/store/evm/key" + string(append(types.KeyPrefixCode, acc.CodeHash...)):             code,
+			"/store/evm/key" + string(append(types.AddressStoragePrefix(addr), key.Bytes()...)): value.Bytes(),
+		},
+	}
+	source := NewRPCSource(cdc, client, 10)
+
+	forked, err := source.GetAccount(addr)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), forked.GetSequence())
+	forked, err = source.GetAccount(ethcmn.BytesToAddress([]byte("missing")))
+	require.NoError(t, err)
+	require.Nil(t, forked)
+
+	forkedCode, err := source.GetCode(acc.CodeHash)
+	require.NoError(t, err)
+	require.Equal(t, code, forkedCode)
+	forkedValue, err := source.GetState(addr, key)
+	require.NoError(t, err)
+	require.Equal(t, value.Bytes(), forkedValue)
+
+	// the results are cached
+	queries := client.queries
+	_, err = source.GetAccount(addr)
+	require.NoError(t, err)
+	_, err = source.GetAccount(ethcmn.BytesToAddress([]byte("missing")))
+	require.NoError(t, err)
+	_, err = source.GetState(addr, key)
+	require.NoError(t, err)
+	require.Equal(t, queries, client.queries)
+
+	// the remote node must keep the state of the height
+	_, err = NewRPCSource(cdc, client, 11).GetCode(acc.CodeHash)
+	require.Error(t, err)
+}
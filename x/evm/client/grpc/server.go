@@ -0,0 +1,193 @@
+package grpc
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"strconv"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/golang/protobuf/proto"
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	clientcontext "github.com/okex/exchain/libs/cosmos-sdk/client/context"
+	sdkerrors "github.com/okex/exchain/libs/cosmos-sdk/types/errors"
+	"github.com/okex/exchain/libs/tendermint/libs/log"
+	rpcclient "github.com/okex/exchain/libs/tendermint/rpc/client"
+	"github.com/okex/exchain/x/evm/types"
+)
+
+// BlockHeightHeader is the grpc metadata selecting the height of the state a query is served on
+const BlockHeightHeader = "x-cosmos-block-height"
+
+type queryServer struct {
+	cliCtx clientcontext.CLIContext
+}
+
+// NewQueryServer returns the grpc query service of the module served by a node, the calls are forwarded to the
+// app as abci queries, except TxLogs which is served out of the tx index of the node
+func NewQueryServer(cliCtx clientcontext.CLIContext) types.QueryServer {
+	return queryServer{cliCtx: cliCtx}
+}
+
+// StartGRPCServer serves the grpc query service of the module at addr, it returns once the listener is open
+func StartGRPCServer(cliCtx clientcontext.CLIContext, logger log.Logger, addr string) (*gogrpc.Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	server := gogrpc.NewServer()
+	types.RegisterQueryServer(server, NewQueryServer(cliCtx))
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			logger.Error("grpc server stopped", "err", err)
+		}
+	}()
+	return server, nil
+}
+
+func (s queryServer) Code(ctx context.Context, req *types.QueryCodeRequest) (*types.QueryCodeResponse, error) {
+	res := new(types.QueryCodeResponse)
+	if err := s.forward(ctx, "Code", req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s queryServer) Storage(ctx context.Context, req *types.QueryStorageRequest) (*types.QueryStorageResponse, error) {
+	res := new(types.QueryStorageResponse)
+	if err := s.forward(ctx, "Storage", req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s queryServer) StorageSlots(ctx context.Context, req *types.QueryStorageSlotsRequest) (*types.QueryStorageSlotsResponse, error) {
+	res := new(types.QueryStorageSlotsResponse)
+	if err := s.forward(ctx, "StorageSlots", req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s queryServer) Params(ctx context.Context, req *types.QueryParamsRequest) (*types.QueryParamsResponse, error) {
+	res := new(types.QueryParamsResponse)
+	if err := s.forward(ctx, "Params", req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s queryServer) Bloom(ctx context.Context, req *types.QueryBloomRequest) (*types.QueryBloomResponse, error) {
+	res := new(types.QueryBloomResponse)
+	if err := s.forward(ctx, "Bloom", req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// TxLogs returns the logs of a transaction out of its result in the tx index, the pages are keyed by the
+// big endian index of their first log
+func (s queryServer) TxLogs(_ context.Context, req *types.QueryTxLogsRequest) (*types.QueryTxLogsResponse, error) {
+	hash, err := parseHexHash(req.Hash)
+	if err != nil {
+		return nil, err
+	}
+	node, err := s.cliCtx.GetNode()
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	txRes, err := node.Tx(hash.Bytes(), !s.cliCtx.TrustNode)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	resultData, err := types.DecodeResultData(txRes.TxResult.Data)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to decode the result of tx %s: %s", req.Hash, err)
+	}
+
+	start := uint64(0)
+	if key := req.Pagination.GetKey(); len(key) != 0 {
+		if len(key) != 8 {
+			return nil, status.Error(codes.InvalidArgument, "invalid pagination key")
+		}
+		start = binary.BigEndian.Uint64(key)
+	}
+	logs := resultData.Logs
+	if start > uint64(len(logs)) {
+		start = uint64(len(logs))
+	}
+	end := start + uint64(req.Pagination.GetLimit())
+
+	res := &types.QueryTxLogsResponse{Pagination: &types.PageResponse{}}
+	if end < uint64(len(logs)) {
+		res.Pagination.NextKey = make([]byte, 8)
+		binary.BigEndian.PutUint64(res.Pagination.NextKey, end)
+	} else {
+		end = uint64(len(logs))
+	}
+	for _, ethLog := range logs[start:end] {
+		res.Logs = append(res.Logs, types.NewProtoLog(ethLog))
+	}
+	return res, nil
+}
+
+// forward queries the grpc method of the app at the height of the request metadata, the height of the
+// context by default
+func (s queryServer) forward(ctx context.Context, method string, req, res proto.Message) error {
+	height := s.cliCtx.Height
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(BlockHeightHeader); len(values) == 1 {
+			h, err := strconv.ParseInt(values[0], 10, 64)
+			if err != nil || h < 0 {
+				return status.Errorf(codes.InvalidArgument, "invalid %s header %q", BlockHeightHeader, values[0])
+			}
+			height = h
+		}
+	}
+
+	data, err := proto.Marshal(req)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	node, err := s.cliCtx.GetNode()
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	path := "/" + types.QueryServiceName + "/" + method
+	result, err := node.ABCIQueryWithOptions(path, data, rpcclient.ABCIQueryOptions{Height: height})
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	if !result.Response.IsOK() {
+		return abciToStatus(result.Response.Codespace, result.Response.Code, result.Response.Log)
+	}
+	if err := proto.Unmarshal(result.Response.Value, res); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+func abciToStatus(codespace string, code uint32, msg string) error {
+	if codespace == sdkerrors.RootCodespace {
+		switch code {
+		case sdkerrors.ErrInvalidRequest.ABCICode():
+			return status.Error(codes.InvalidArgument, msg)
+		case sdkerrors.ErrUnknownRequest.ABCICode():
+			return status.Error(codes.NotFound, msg)
+		}
+	}
+	return status.Error(codes.Internal, msg)
+}
+
+func parseHexHash(hash string) (ethcmn.Hash, error) {
+	bz, err := hexutil.Decode(hash)
+	if err != nil || len(bz) != ethcmn.HashLength {
+		return ethcmn.Hash{}, status.Errorf(codes.InvalidArgument, "invalid hex hash %q", hash)
+	}
+	return ethcmn.BytesToHash(bz), nil
+}
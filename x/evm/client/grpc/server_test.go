@@ -0,0 +1,121 @@
+package grpc
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	clientcontext "github.com/okex/exchain/libs/cosmos-sdk/client/context"
+	abci "github.com/okex/exchain/libs/tendermint/abci/types"
+	"github.com/okex/exchain/libs/tendermint/libs/bytes"
+	rpcclient "github.com/okex/exchain/libs/tendermint/rpc/client"
+	ctypes "github.com/okex/exchain/libs/tendermint/rpc/core/types"
+	"github.com/okex/exchain/x/evm/evmproto"
+	"github.com/okex/exchain/x/evm/types"
+)
+
+// node serves the result of a single tx and records the abci queries
+type node struct {
+	rpcclient.Client
+	txData  []byte
+	path    string
+	height  int64
+	queried proto.Message
+}
+
+func (n *node) Tx(hash []byte, prove bool) (*ctypes.ResultTx, error) {
+	return &ctypes.ResultTx{Hash: hash, TxResult: abci.ResponseDeliverTx{Data: n.txData}}, nil
+}
+
+func (n *node) ABCIQueryWithOptions(path string, data bytes.HexBytes, opts rpcclient.ABCIQueryOptions) (*ctypes.ResultABCIQuery, error) {
+	n.path, n.height = path, opts.Height
+	value, _ := proto.Marshal(n.queried)
+	return &ctypes.ResultABCIQuery{Response: abci.ResponseQuery{Value: value}}, nil
+}
+
+func newTxLogsServer(t *testing.T, logs int) types.QueryServer {
+	data := types.ResultData{}
+	for i := 0; i < logs; i++ {
+		data.Logs = append(data.Logs, &ethtypes.Log{Address: ethcmn.BytesToAddress([]byte{0x1}), Index: uint(i)})
+	}
+	bz, err := types.EncodeResultData(data)
+	require.NoError(t, err)
+	return NewQueryServer(clientcontext.CLIContext{Client: &node{txData: bz}, TrustNode: true})
+}
+
+func TestTxLogsPages(t *testing.T) {
+	s := newTxLogsServer(t, types.MaxPageLimit+50)
+	hash := ethcmn.BytesToHash([]byte("tx")).Hex()
+
+	// the pages without a limit have the default limit
+	var logs []*evmproto.Log
+	var pages int
+	page := &types.PageRequest{}
+	for {
+		res, err := s.TxLogs(context.Background(), &types.QueryTxLogsRequest{Hash: hash, Pagination: page})
+		require.NoError(t, err)
+		require.True(t, len(res.Logs) <= types.DefaultPageLimit)
+		logs = append(logs, res.Logs...)
+		pages++
+		if len(res.Pagination.NextKey) == 0 {
+			break
+		}
+		require.Equal(t, uint64(len(logs)), binary.BigEndian.Uint64(res.Pagination.NextKey))
+		page = &types.PageRequest{Key: res.Pagination.NextKey}
+	}
+	require.Len(t, logs, types.MaxPageLimit+50)
+	require.Equal(t, (types.MaxPageLimit+50+types.DefaultPageLimit-1)/types.DefaultPageLimit, pages)
+	for i, log := range logs {
+		require.Equal(t, uint64(i), log.Index)
+	}
+
+	// the limit is capped
+	res, err := s.TxLogs(context.Background(), &types.QueryTxLogsRequest{Hash: hash, Pagination: &types.PageRequest{Limit: 1 << 62}})
+	require.NoError(t, err)
+	require.Len(t, res.Logs, types.MaxPageLimit)
+	require.NotEmpty(t, res.Pagination.NextKey)
+
+	// a page past the logs is empty
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, 1<<40)
+	res, err = s.TxLogs(context.Background(), &types.QueryTxLogsRequest{Hash: hash, Pagination: &types.PageRequest{Key: key}})
+	require.NoError(t, err)
+	require.Empty(t, res.Logs)
+	require.Empty(t, res.Pagination.NextKey)
+
+	// invalid requests
+	_, err = s.TxLogs(context.Background(), &types.QueryTxLogsRequest{Hash: hash, Pagination: &types.PageRequest{Key: []byte{0x1}}})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = s.TxLogs(context.Background(), &types.QueryTxLogsRequest{Hash: "0x01"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestForwardedQueries(t *testing.T) {
+	n := &node{queried: &types.QueryStorageSlotsResponse{Slots: []*types.StorageSlot{{Key: "0x01", Value: "0x02"}}}}
+	s := NewQueryServer(clientcontext.CLIContext{Client: n, Height: 3})
+	req := &types.QueryStorageSlotsRequest{Address: ethcmn.BytesToAddress([]byte{0x1}).Hex()}
+
+	// the queries are served at the height of the context by default
+	res, err := s.StorageSlots(context.Background(), req)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(n.queried, res))
+	require.Equal(t, "/"+types.QueryServiceName+"/StorageSlots", n.path)
+	require.Equal(t, int64(3), n.height)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(BlockHeightHeader, "5"))
+	_, err = s.StorageSlots(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), n.height)
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(BlockHeightHeader, "-1"))
+	_, err = s.StorageSlots(ctx, req)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -22,4 +22,22 @@ var (
 		cli.GetCmdManageContractMethodBlockedListProposal,
 		rest.ManageContractMethodBlockedListProposalRESTHandler,
 	)
+
+	// ManageChainConfigForksProposalHandler alias gov NewProposalHandler
+	ManageChainConfigForksProposalHandler = govcli.NewProposalHandler(
+		cli.GetCmdManageChainConfigForksProposal,
+		rest.ManageChainConfigForksProposalRESTHandler,
+	)
+
+	// ManageTokenRegistryProposalHandler alias gov NewProposalHandler
+	ManageTokenRegistryProposalHandler = govcli.NewProposalHandler(
+		cli.GetCmdManageTokenRegistryProposal,
+		rest.ManageTokenRegistryProposalRESTHandler,
+	)
+
+	// ManageContractGasQuotaProposalHandler alias gov NewProposalHandler
+	ManageContractGasQuotaProposalHandler = govcli.NewProposalHandler(
+		cli.GetCmdManageContractGasQuotaProposal,
+		rest.ManageContractGasQuotaProposalRESTHandler,
+	)
 )
@@ -0,0 +1,119 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	clientcontext "github.com/okex/exchain/libs/cosmos-sdk/client/context"
+	"github.com/okex/exchain/libs/cosmos-sdk/types/rest"
+	evmgrpc "github.com/okex/exchain/x/evm/client/grpc"
+	evmtypes "github.com/okex/exchain/x/evm/types"
+)
+
+// registerGRPCGatewayRoutes registers the REST bindings of the grpc query service, the responses are the json
+// encoding of the protobuf messages
+func registerGRPCGatewayRoutes(cliCtx clientcontext.CLIContext, r *mux.Router) {
+	r.HandleFunc("/evm/v1/code/{address}", grpcGatewayHandlerFn(cliCtx, func(s evmtypes.QueryServer, r *http.Request) (proto.Message, error) {
+		return s.Code(r.Context(), &evmtypes.QueryCodeRequest{Address: mux.Vars(r)["address"]})
+	})).Methods("GET")
+	r.HandleFunc("/evm/v1/storage/{address}/{key}", grpcGatewayHandlerFn(cliCtx, func(s evmtypes.QueryServer, r *http.Request) (proto.Message, error) {
+		vars := mux.Vars(r)
+		return s.Storage(r.Context(), &evmtypes.QueryStorageRequest{Address: vars["address"], Key: vars["key"]})
+	})).Methods("GET")
+	r.HandleFunc("/evm/v1/storage/{address}", grpcGatewayHandlerFn(cliCtx, func(s evmtypes.QueryServer, r *http.Request) (proto.Message, error) {
+		page, err := parsePageRequest(r)
+		if err != nil {
+			return nil, err
+		}
+		return s.StorageSlots(r.Context(), &evmtypes.QueryStorageSlotsRequest{Address: mux.Vars(r)["address"], Pagination: page})
+	})).Methods("GET")
+	r.HandleFunc("/evm/v1/params", grpcGatewayHandlerFn(cliCtx, func(s evmtypes.QueryServer, r *http.Request) (proto.Message, error) {
+		return s.Params(r.Context(), &evmtypes.QueryParamsRequest{})
+	})).Methods("GET")
+	r.HandleFunc("/evm/v1/bloom/{height}", grpcGatewayHandlerFn(cliCtx, func(s evmtypes.QueryServer, r *http.Request) (proto.Message, error) {
+		height, err := strconv.ParseInt(mux.Vars(r)["height"], 10, 64)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid block height: %s", err)
+		}
+		return s.Bloom(r.Context(), &evmtypes.QueryBloomRequest{Height: height})
+	})).Methods("GET")
+	r.HandleFunc("/evm/v1/tx_logs/{hash}", grpcGatewayHandlerFn(cliCtx, func(s evmtypes.QueryServer, r *http.Request) (proto.Message, error) {
+		page, err := parsePageRequest(r)
+		if err != nil {
+			return nil, err
+		}
+		return s.TxLogs(r.Context(), &evmtypes.QueryTxLogsRequest{Hash: mux.Vars(r)["hash"], Pagination: page})
+	})).Methods("GET")
+}
+
+type grpcGatewayQuery func(s evmtypes.QueryServer, r *http.Request) (proto.Message, error)
+
+func grpcGatewayHandlerFn(cliCtx clientcontext.CLIContext, query grpcGatewayQuery) http.HandlerFunc {
+	marshaler := jsonpb.Marshaler{OrigName: true, EmitDefaults: true}
+	return func(w http.ResponseWriter, r *http.Request) {
+		cliCtx, ok := rest.ParseQueryHeightOrReturnBadRequest(w, cliCtx, r)
+		if !ok {
+			return
+		}
+
+		res, err := query(evmgrpc.NewQueryServer(cliCtx), r)
+		if err != nil {
+			s, _ := status.FromError(err)
+			rest.WriteErrorResponse(w, httpStatusFromCode(s.Code()), s.Message())
+			return
+		}
+
+		var buf bytes.Buffer
+		if err := marshaler.Marshal(&buf, res); err != nil {
+			rest.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		rest.PostProcessResponseBare(w, cliCtx, buf.Bytes())
+	}
+}
+
+// parsePageRequest parses the pagination.key and pagination.limit query parameters, the key is the base64
+// encoded next_key of the previous page
+func parsePageRequest(r *http.Request) (*evmtypes.PageRequest, error) {
+	page := &evmtypes.PageRequest{}
+	if key := r.FormValue("pagination.key"); key != "" {
+		bz, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			if bz, err = base64.URLEncoding.DecodeString(key); err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid pagination key: %s", err)
+			}
+		}
+		page.Key = bz
+	}
+	if limit := r.FormValue("pagination.limit"); limit != "" {
+		n, err := strconv.ParseUint(limit, 10, 64)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid pagination limit: %s", err)
+		}
+		page.Limit = n
+	}
+	return page, nil
+}
+
+func httpStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -36,6 +36,7 @@ func RegisterRoutes(cliCtx context.CLIContext, r *mux.Router) {
 	r.HandleFunc("/section", QuerySectionFn(cliCtx)).Methods("GET")
 	r.HandleFunc("/contract/blocked_list", QueryContractBlockedListHandlerFn(cliCtx)).Methods("GET")
 	r.HandleFunc("/contract/method_blocked_list", QueryContractMethodBlockedListHandlerFn(cliCtx)).Methods("GET")
+	registerGRPCGatewayRoutes(cliCtx, r)
 
 }
 
@@ -89,7 +90,7 @@ func QueryTx(cliCtx context.CLIContext, hashHexStr string) (interface{}, error)
 		}
 	}
 
-	tx, err := evmtypes.TxDecoder(cliCtx.Codec)(resTx.Tx)
+	tx, err := evmtypes.TxDecoder(cliCtx.Codec)(resTx.Tx, resTx.Height)
 	if err != nil {
 		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONUnmarshal, err.Error())
 	}
@@ -200,6 +201,21 @@ func ManageContractMethodBlockedListProposalRESTHandler(context.CLIContext) govR
 	return govRest.ProposalRESTHandler{}
 }
 
+// ManageChainConfigForksProposalRESTHandler defines evm proposal handler
+func ManageChainConfigForksProposalRESTHandler(context.CLIContext) govRest.ProposalRESTHandler {
+	return govRest.ProposalRESTHandler{}
+}
+
+// ManageTokenRegistryProposalRESTHandler defines evm proposal handler
+func ManageTokenRegistryProposalRESTHandler(context.CLIContext) govRest.ProposalRESTHandler {
+	return govRest.ProposalRESTHandler{}
+}
+
+// ManageContractGasQuotaProposalRESTHandler defines evm proposal handler
+func ManageContractGasQuotaProposalRESTHandler(context.CLIContext) govRest.ProposalRESTHandler {
+	return govRest.ProposalRESTHandler{}
+}
+
 func QuerySectionFn(cliCtx context.CLIContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		res, _, err := cliCtx.Query(fmt.Sprintf("custom/%s/%s", evmtypes.RouterKey, evmtypes.QuerySection))
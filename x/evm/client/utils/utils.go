@@ -37,6 +37,37 @@ type (
 		Deposit      sdk.SysCoins              `json:"deposit" yaml:"deposit"`
 	}
 
+	// ManageChainConfigForksProposalJSON defines a ManageChainConfigForksProposal with a deposit used to parse manage
+	// chain config forks proposals from a JSON file.
+	ManageChainConfigForksProposalJSON struct {
+		Title         string       `json:"title" yaml:"title"`
+		Description   string       `json:"description" yaml:"description"`
+		BerlinBlock   sdk.Int      `json:"berlin_block" yaml:"berlin_block"`
+		LondonBlock   sdk.Int      `json:"london_block" yaml:"london_block"`
+		ShanghaiBlock sdk.Int      `json:"shanghai_block" yaml:"shanghai_block"`
+		Deposit       sdk.SysCoins `json:"deposit" yaml:"deposit"`
+	}
+
+	// ManageTokenRegistryProposalJSON defines a ManageTokenRegistryProposal with a deposit used to parse manage token
+	// registry proposals from a JSON file.
+	ManageTokenRegistryProposalJSON struct {
+		Title       string                `json:"title" yaml:"title"`
+		Description string                `json:"description" yaml:"description"`
+		Tokens      []types.TokenMetadata `json:"tokens" yaml:"tokens"`
+		IsAdded     bool                  `json:"is_added" yaml:"is_added"`
+		Deposit     sdk.SysCoins          `json:"deposit" yaml:"deposit"`
+	}
+
+	// ManageContractGasQuotaProposalJSON defines a ManageContractGasQuotaProposal with a deposit used to parse manage
+	// contract gas quota proposals from a JSON file.
+	ManageContractGasQuotaProposalJSON struct {
+		Title       string                   `json:"title" yaml:"title"`
+		Description string                   `json:"description" yaml:"description"`
+		Quotas      []types.ContractGasQuota `json:"quotas" yaml:"quotas"`
+		IsAdded     bool                     `json:"is_added" yaml:"is_added"`
+		Deposit     sdk.SysCoins             `json:"deposit" yaml:"deposit"`
+	}
+
 	ResponseBlockContract struct {
 		Address      string                `json:"address" yaml:"address"`
 		BlockMethods types.ContractMethods `json:"block_methods" yaml:"block_methods"`
@@ -79,3 +110,39 @@ func ParseManageContractMethodBlockedListProposalJSON(cdc *codec.Codec, proposal
 	cdc.MustUnmarshalJSON(contents, &proposal)
 	return
 }
+
+// ParseManageChainConfigForksProposalJSON parses json from proposal file to ManageChainConfigForksProposalJSON struct
+func ParseManageChainConfigForksProposalJSON(cdc *codec.Codec, proposalFilePath string) (
+	proposal ManageChainConfigForksProposalJSON, err error) {
+	contents, err := ioutil.ReadFile(proposalFilePath)
+	if err != nil {
+		return
+	}
+
+	cdc.MustUnmarshalJSON(contents, &proposal)
+	return
+}
+
+// ParseManageTokenRegistryProposalJSON parses json from proposal file to ManageTokenRegistryProposalJSON struct
+func ParseManageTokenRegistryProposalJSON(cdc *codec.Codec, proposalFilePath string) (
+	proposal ManageTokenRegistryProposalJSON, err error) {
+	contents, err := ioutil.ReadFile(proposalFilePath)
+	if err != nil {
+		return
+	}
+
+	cdc.MustUnmarshalJSON(contents, &proposal)
+	return
+}
+
+// ParseManageContractGasQuotaProposalJSON parses json from proposal file to ManageContractGasQuotaProposalJSON struct
+func ParseManageContractGasQuotaProposalJSON(cdc *codec.Codec, proposalFilePath string) (
+	proposal ManageContractGasQuotaProposalJSON, err error) {
+	contents, err := ioutil.ReadFile(proposalFilePath)
+	if err != nil {
+		return
+	}
+
+	cdc.MustUnmarshalJSON(contents, &proposal)
+	return
+}
@@ -0,0 +1,1176 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: x/evm/evmproto/evm.proto
+
+package evmproto
+
+import (
+	fmt "fmt"
+	proto "github.com/gogo/protobuf/proto"
+	types "github.com/gogo/protobuf/types"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+// MsgEthereumTx is an ethereum transaction, the payload is kept in its rlp encoding so the signature and the
+// hash of the transaction are the ethereum ones
+type MsgEthereumTx struct {
+	// rlp encoding of the transaction data
+	Data                 []byte   `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MsgEthereumTx) Reset()         { *m = MsgEthereumTx{} }
+func (m *MsgEthereumTx) String() string { return proto.CompactTextString(m) }
+func (*MsgEthereumTx) ProtoMessage()    {}
+func (*MsgEthereumTx) Descriptor() ([]byte, []int) {
+	return fileDescriptor_69ff075df9832927, []int{0}
+}
+func (m *MsgEthereumTx) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MsgEthereumTx.Unmarshal(m, b)
+}
+func (m *MsgEthereumTx) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MsgEthereumTx.Marshal(b, m, deterministic)
+}
+func (m *MsgEthereumTx) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MsgEthereumTx.Merge(m, src)
+}
+func (m *MsgEthereumTx) XXX_Size() int {
+	return xxx_messageInfo_MsgEthereumTx.Size(m)
+}
+func (m *MsgEthereumTx) XXX_DiscardUnknown() {
+	xxx_messageInfo_MsgEthereumTx.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MsgEthereumTx proto.InternalMessageInfo
+
+func (m *MsgEthereumTx) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+// Tx is the protobuf encoding of a transaction of evm messages, the messages are packed into Any with the type
+// urls "/okexchain.evm.v1.<message>"
+type Tx struct {
+	Msgs                 []*types.Any `protobuf:"bytes,1,rep,name=msgs,proto3" json:"msgs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *Tx) Reset()         { *m = Tx{} }
+func (m *Tx) String() string { return proto.CompactTextString(m) }
+func (*Tx) ProtoMessage()    {}
+func (*Tx) Descriptor() ([]byte, []int) {
+	return fileDescriptor_69ff075df9832927, []int{1}
+}
+func (m *Tx) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Tx.Unmarshal(m, b)
+}
+func (m *Tx) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Tx.Marshal(b, m, deterministic)
+}
+func (m *Tx) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Tx.Merge(m, src)
+}
+func (m *Tx) XXX_Size() int {
+	return xxx_messageInfo_Tx.Size(m)
+}
+func (m *Tx) XXX_DiscardUnknown() {
+	xxx_messageInfo_Tx.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Tx proto.InternalMessageInfo
+
+func (m *Tx) GetMsgs() []*types.Any {
+	if m != nil {
+		return m.Msgs
+	}
+	return nil
+}
+
+type State struct {
+	// hex key of the slot
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// hex value of the slot
+	Value                string   `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *State) Reset()         { *m = State{} }
+func (m *State) String() string { return proto.CompactTextString(m) }
+func (*State) ProtoMessage()    {}
+func (*State) Descriptor() ([]byte, []int) {
+	return fileDescriptor_69ff075df9832927, []int{2}
+}
+func (m *State) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_State.Unmarshal(m, b)
+}
+func (m *State) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_State.Marshal(b, m, deterministic)
+}
+func (m *State) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_State.Merge(m, src)
+}
+func (m *State) XXX_Size() int {
+	return xxx_messageInfo_State.Size(m)
+}
+func (m *State) XXX_DiscardUnknown() {
+	xxx_messageInfo_State.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_State proto.InternalMessageInfo
+
+func (m *State) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *State) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+type GenesisAccount struct {
+	// hex address of the account
+	Address              string   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Code                 []byte   `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	Storage              []*State `protobuf:"bytes,3,rep,name=storage,proto3" json:"storage,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GenesisAccount) Reset()         { *m = GenesisAccount{} }
+func (m *GenesisAccount) String() string { return proto.CompactTextString(m) }
+func (*GenesisAccount) ProtoMessage()    {}
+func (*GenesisAccount) Descriptor() ([]byte, []int) {
+	return fileDescriptor_69ff075df9832927, []int{3}
+}
+func (m *GenesisAccount) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GenesisAccount.Unmarshal(m, b)
+}
+func (m *GenesisAccount) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GenesisAccount.Marshal(b, m, deterministic)
+}
+func (m *GenesisAccount) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GenesisAccount.Merge(m, src)
+}
+func (m *GenesisAccount) XXX_Size() int {
+	return xxx_messageInfo_GenesisAccount.Size(m)
+}
+func (m *GenesisAccount) XXX_DiscardUnknown() {
+	xxx_messageInfo_GenesisAccount.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GenesisAccount proto.InternalMessageInfo
+
+func (m *GenesisAccount) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *GenesisAccount) GetCode() []byte {
+	if m != nil {
+		return m.Code
+	}
+	return nil
+}
+
+func (m *GenesisAccount) GetStorage() []*State {
+	if m != nil {
+		return m.Storage
+	}
+	return nil
+}
+
+type TransactionLogs struct {
+	// hex hash of the transaction
+	Hash                 string   `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Logs                 []*Log   `protobuf:"bytes,2,rep,name=logs,proto3" json:"logs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TransactionLogs) Reset()         { *m = TransactionLogs{} }
+func (m *TransactionLogs) String() string { return proto.CompactTextString(m) }
+func (*TransactionLogs) ProtoMessage()    {}
+func (*TransactionLogs) Descriptor() ([]byte, []int) {
+	return fileDescriptor_69ff075df9832927, []int{4}
+}
+func (m *TransactionLogs) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TransactionLogs.Unmarshal(m, b)
+}
+func (m *TransactionLogs) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TransactionLogs.Marshal(b, m, deterministic)
+}
+func (m *TransactionLogs) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransactionLogs.Merge(m, src)
+}
+func (m *TransactionLogs) XXX_Size() int {
+	return xxx_messageInfo_TransactionLogs.Size(m)
+}
+func (m *TransactionLogs) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransactionLogs.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransactionLogs proto.InternalMessageInfo
+
+func (m *TransactionLogs) GetHash() string {
+	if m != nil {
+		return m.Hash
+	}
+	return ""
+}
+
+func (m *TransactionLogs) GetLogs() []*Log {
+	if m != nil {
+		return m.Logs
+	}
+	return nil
+}
+
+type ContractMethod struct {
+	Sign                 string   `protobuf:"bytes,1,opt,name=sign,proto3" json:"sign,omitempty"`
+	Extra                string   `protobuf:"bytes,2,opt,name=extra,proto3" json:"extra,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ContractMethod) Reset()         { *m = ContractMethod{} }
+func (m *ContractMethod) String() string { return proto.CompactTextString(m) }
+func (*ContractMethod) ProtoMessage()    {}
+func (*ContractMethod) Descriptor() ([]byte, []int) {
+	return fileDescriptor_69ff075df9832927, []int{5}
+}
+func (m *ContractMethod) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ContractMethod.Unmarshal(m, b)
+}
+func (m *ContractMethod) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ContractMethod.Marshal(b, m, deterministic)
+}
+func (m *ContractMethod) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ContractMethod.Merge(m, src)
+}
+func (m *ContractMethod) XXX_Size() int {
+	return xxx_messageInfo_ContractMethod.Size(m)
+}
+func (m *ContractMethod) XXX_DiscardUnknown() {
+	xxx_messageInfo_ContractMethod.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ContractMethod proto.InternalMessageInfo
+
+func (m *ContractMethod) GetSign() string {
+	if m != nil {
+		return m.Sign
+	}
+	return ""
+}
+
+func (m *ContractMethod) GetExtra() string {
+	if m != nil {
+		return m.Extra
+	}
+	return ""
+}
+
+type BlockedContract struct {
+	// bech32 address of the contract
+	Address              string            `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	BlockMethods         []*ContractMethod `protobuf:"bytes,2,rep,name=block_methods,json=blockMethods,proto3" json:"block_methods,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *BlockedContract) Reset()         { *m = BlockedContract{} }
+func (m *BlockedContract) String() string { return proto.CompactTextString(m) }
+func (*BlockedContract) ProtoMessage()    {}
+func (*BlockedContract) Descriptor() ([]byte, []int) {
+	return fileDescriptor_69ff075df9832927, []int{6}
+}
+func (m *BlockedContract) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BlockedContract.Unmarshal(m, b)
+}
+func (m *BlockedContract) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BlockedContract.Marshal(b, m, deterministic)
+}
+func (m *BlockedContract) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BlockedContract.Merge(m, src)
+}
+func (m *BlockedContract) XXX_Size() int {
+	return xxx_messageInfo_BlockedContract.Size(m)
+}
+func (m *BlockedContract) XXX_DiscardUnknown() {
+	xxx_messageInfo_BlockedContract.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BlockedContract proto.InternalMessageInfo
+
+func (m *BlockedContract) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *BlockedContract) GetBlockMethods() []*ContractMethod {
+	if m != nil {
+		return m.BlockMethods
+	}
+	return nil
+}
+
+// ChainConfig mirrors the chain config, the fork blocks are decimal integers, negative when the fork is disabled
+type ChainConfig struct {
+	HomesteadBlock       string   `protobuf:"bytes,1,opt,name=homestead_block,json=homesteadBlock,proto3" json:"homestead_block,omitempty"`
+	DaoForkBlock         string   `protobuf:"bytes,2,opt,name=dao_fork_block,json=daoForkBlock,proto3" json:"dao_fork_block,omitempty"`
+	DaoForkSupport       bool     `protobuf:"varint,3,opt,name=dao_fork_support,json=daoForkSupport,proto3" json:"dao_fork_support,omitempty"`
+	Eip150Block          string   `protobuf:"bytes,4,opt,name=eip150_block,json=eip150Block,proto3" json:"eip150_block,omitempty"`
+	Eip150Hash           string   `protobuf:"bytes,5,opt,name=eip150_hash,json=eip150Hash,proto3" json:"eip150_hash,omitempty"`
+	Eip155Block          string   `protobuf:"bytes,6,opt,name=eip155_block,json=eip155Block,proto3" json:"eip155_block,omitempty"`
+	Eip158Block          string   `protobuf:"bytes,7,opt,name=eip158_block,json=eip158Block,proto3" json:"eip158_block,omitempty"`
+	ByzantiumBlock       string   `protobuf:"bytes,8,opt,name=byzantium_block,json=byzantiumBlock,proto3" json:"byzantium_block,omitempty"`
+	ConstantinopleBlock  string   `protobuf:"bytes,9,opt,name=constantinople_block,json=constantinopleBlock,proto3" json:"constantinople_block,omitempty"`
+	PetersburgBlock      string   `protobuf:"bytes,10,opt,name=petersburg_block,json=petersburgBlock,proto3" json:"petersburg_block,omitempty"`
+	IstanbulBlock        string   `protobuf:"bytes,11,opt,name=istanbul_block,json=istanbulBlock,proto3" json:"istanbul_block,omitempty"`
+	MuirGlacierBlock     string   `protobuf:"bytes,12,opt,name=muir_glacier_block,json=muirGlacierBlock,proto3" json:"muir_glacier_block,omitempty"`
+	YoloV2Block          string   `protobuf:"bytes,13,opt,name=yolo_v2_block,json=yoloV2Block,proto3" json:"yolo_v2_block,omitempty"`
+	EwasmBlock           string   `protobuf:"bytes,14,opt,name=ewasm_block,json=ewasmBlock,proto3" json:"ewasm_block,omitempty"`
+	BerlinBlock          string   `protobuf:"bytes,15,opt,name=berlin_block,json=berlinBlock,proto3" json:"berlin_block,omitempty"`
+	LondonBlock          string   `protobuf:"bytes,16,opt,name=london_block,json=londonBlock,proto3" json:"london_block,omitempty"`
+	ShanghaiBlock        string   `protobuf:"bytes,17,opt,name=shanghai_block,json=shanghaiBlock,proto3" json:"shanghai_block,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ChainConfig) Reset()         { *m = ChainConfig{} }
+func (m *ChainConfig) String() string { return proto.CompactTextString(m) }
+func (*ChainConfig) ProtoMessage()    {}
+func (*ChainConfig) Descriptor() ([]byte, []int) {
+	return fileDescriptor_69ff075df9832927, []int{7}
+}
+func (m *ChainConfig) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ChainConfig.Unmarshal(m, b)
+}
+func (m *ChainConfig) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ChainConfig.Marshal(b, m, deterministic)
+}
+func (m *ChainConfig) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ChainConfig.Merge(m, src)
+}
+func (m *ChainConfig) XXX_Size() int {
+	return xxx_messageInfo_ChainConfig.Size(m)
+}
+func (m *ChainConfig) XXX_DiscardUnknown() {
+	xxx_messageInfo_ChainConfig.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ChainConfig proto.InternalMessageInfo
+
+func (m *ChainConfig) GetHomesteadBlock() string {
+	if m != nil {
+		return m.HomesteadBlock
+	}
+	return ""
+}
+
+func (m *ChainConfig) GetDaoForkBlock() string {
+	if m != nil {
+		return m.DaoForkBlock
+	}
+	return ""
+}
+
+func (m *ChainConfig) GetDaoForkSupport() bool {
+	if m != nil {
+		return m.DaoForkSupport
+	}
+	return false
+}
+
+func (m *ChainConfig) GetEip150Block() string {
+	if m != nil {
+		return m.Eip150Block
+	}
+	return ""
+}
+
+func (m *ChainConfig) GetEip150Hash() string {
+	if m != nil {
+		return m.Eip150Hash
+	}
+	return ""
+}
+
+func (m *ChainConfig) GetEip155Block() string {
+	if m != nil {
+		return m.Eip155Block
+	}
+	return ""
+}
+
+func (m *ChainConfig) GetEip158Block() string {
+	if m != nil {
+		return m.Eip158Block
+	}
+	return ""
+}
+
+func (m *ChainConfig) GetByzantiumBlock() string {
+	if m != nil {
+		return m.ByzantiumBlock
+	}
+	return ""
+}
+
+func (m *ChainConfig) GetConstantinopleBlock() string {
+	if m != nil {
+		return m.ConstantinopleBlock
+	}
+	return ""
+}
+
+func (m *ChainConfig) GetPetersburgBlock() string {
+	if m != nil {
+		return m.PetersburgBlock
+	}
+	return ""
+}
+
+func (m *ChainConfig) GetIstanbulBlock() string {
+	if m != nil {
+		return m.IstanbulBlock
+	}
+	return ""
+}
+
+func (m *ChainConfig) GetMuirGlacierBlock() string {
+	if m != nil {
+		return m.MuirGlacierBlock
+	}
+	return ""
+}
+
+func (m *ChainConfig) GetYoloV2Block() string {
+	if m != nil {
+		return m.YoloV2Block
+	}
+	return ""
+}
+
+func (m *ChainConfig) GetEwasmBlock() string {
+	if m != nil {
+		return m.EwasmBlock
+	}
+	return ""
+}
+
+func (m *ChainConfig) GetBerlinBlock() string {
+	if m != nil {
+		return m.BerlinBlock
+	}
+	return ""
+}
+
+func (m *ChainConfig) GetLondonBlock() string {
+	if m != nil {
+		return m.LondonBlock
+	}
+	return ""
+}
+
+func (m *ChainConfig) GetShanghaiBlock() string {
+	if m != nil {
+		return m.ShanghaiBlock
+	}
+	return ""
+}
+
+type RecentBlockHash struct {
+	Height uint64 `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	// hex hash of the block
+	Hash                 string   `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RecentBlockHash) Reset()         { *m = RecentBlockHash{} }
+func (m *RecentBlockHash) String() string { return proto.CompactTextString(m) }
+func (*RecentBlockHash) ProtoMessage()    {}
+func (*RecentBlockHash) Descriptor() ([]byte, []int) {
+	return fileDescriptor_69ff075df9832927, []int{8}
+}
+func (m *RecentBlockHash) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RecentBlockHash.Unmarshal(m, b)
+}
+func (m *RecentBlockHash) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RecentBlockHash.Marshal(b, m, deterministic)
+}
+func (m *RecentBlockHash) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RecentBlockHash.Merge(m, src)
+}
+func (m *RecentBlockHash) XXX_Size() int {
+	return xxx_messageInfo_RecentBlockHash.Size(m)
+}
+func (m *RecentBlockHash) XXX_DiscardUnknown() {
+	xxx_messageInfo_RecentBlockHash.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RecentBlockHash proto.InternalMessageInfo
+
+func (m *RecentBlockHash) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *RecentBlockHash) GetHash() string {
+	if m != nil {
+		return m.Hash
+	}
+	return ""
+}
+
+type Params struct {
+	EnableCreate                      bool     `protobuf:"varint,1,opt,name=enable_create,json=enableCreate,proto3" json:"enable_create,omitempty"`
+	EnableCall                        bool     `protobuf:"varint,2,opt,name=enable_call,json=enableCall,proto3" json:"enable_call,omitempty"`
+	ExtraEips                         []int64  `protobuf:"varint,3,rep,packed,name=extra_eips,json=extraEips,proto3" json:"extra_eips,omitempty"`
+	EnableContractDeploymentWhitelist bool     `protobuf:"varint,4,opt,name=enable_contract_deployment_whitelist,json=enableContractDeploymentWhitelist,proto3" json:"enable_contract_deployment_whitelist,omitempty"`
+	EnableContractBlockedList         bool     `protobuf:"varint,5,opt,name=enable_contract_blocked_list,json=enableContractBlockedList,proto3" json:"enable_contract_blocked_list,omitempty"`
+	MaxGasLimitPerTx                  uint64   `protobuf:"varint,6,opt,name=max_gas_limit_per_tx,json=maxGasLimitPerTx,proto3" json:"max_gas_limit_per_tx,omitempty"`
+	BaseFee                           uint64   `protobuf:"varint,7,opt,name=base_fee,json=baseFee,proto3" json:"base_fee,omitempty"`
+	MaxGasLimitPerBlock               uint64   `protobuf:"varint,8,opt,name=max_gas_limit_per_block,json=maxGasLimitPerBlock,proto3" json:"max_gas_limit_per_block,omitempty"`
+	EnableBlockHashWindow             bool     `protobuf:"varint,9,opt,name=enable_block_hash_window,json=enableBlockHashWindow,proto3" json:"enable_block_hash_window,omitempty"`
+	FeeDenoms                         []string `protobuf:"bytes,10,rep,name=fee_denoms,json=feeDenoms,proto3" json:"fee_denoms,omitempty"`
+	// decimal
+	FeeConversionSpread string `protobuf:"bytes,11,opt,name=fee_conversion_spread,json=feeConversionSpread,proto3" json:"fee_conversion_spread,omitempty"`
+	GasPriceVoteWindow  uint64 `protobuf:"varint,12,opt,name=gas_price_vote_window,json=gasPriceVoteWindow,proto3" json:"gas_price_vote_window,omitempty"`
+	// decimal
+	MaxGasPriceChange    string   `protobuf:"bytes,13,opt,name=max_gas_price_change,json=maxGasPriceChange,proto3" json:"max_gas_price_change,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Params) Reset()         { *m = Params{} }
+func (m *Params) String() string { return proto.CompactTextString(m) }
+func (*Params) ProtoMessage()    {}
+func (*Params) Descriptor() ([]byte, []int) {
+	return fileDescriptor_69ff075df9832927, []int{9}
+}
+func (m *Params) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Params.Unmarshal(m, b)
+}
+func (m *Params) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Params.Marshal(b, m, deterministic)
+}
+func (m *Params) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Params.Merge(m, src)
+}
+func (m *Params) XXX_Size() int {
+	return xxx_messageInfo_Params.Size(m)
+}
+func (m *Params) XXX_DiscardUnknown() {
+	xxx_messageInfo_Params.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Params proto.InternalMessageInfo
+
+func (m *Params) GetEnableCreate() bool {
+	if m != nil {
+		return m.EnableCreate
+	}
+	return false
+}
+
+func (m *Params) GetEnableCall() bool {
+	if m != nil {
+		return m.EnableCall
+	}
+	return false
+}
+
+func (m *Params) GetExtraEips() []int64 {
+	if m != nil {
+		return m.ExtraEips
+	}
+	return nil
+}
+
+func (m *Params) GetEnableContractDeploymentWhitelist() bool {
+	if m != nil {
+		return m.EnableContractDeploymentWhitelist
+	}
+	return false
+}
+
+func (m *Params) GetEnableContractBlockedList() bool {
+	if m != nil {
+		return m.EnableContractBlockedList
+	}
+	return false
+}
+
+func (m *Params) GetMaxGasLimitPerTx() uint64 {
+	if m != nil {
+		return m.MaxGasLimitPerTx
+	}
+	return 0
+}
+
+func (m *Params) GetBaseFee() uint64 {
+	if m != nil {
+		return m.BaseFee
+	}
+	return 0
+}
+
+func (m *Params) GetMaxGasLimitPerBlock() uint64 {
+	if m != nil {
+		return m.MaxGasLimitPerBlock
+	}
+	return 0
+}
+
+func (m *Params) GetEnableBlockHashWindow() bool {
+	if m != nil {
+		return m.EnableBlockHashWindow
+	}
+	return false
+}
+
+func (m *Params) GetFeeDenoms() []string {
+	if m != nil {
+		return m.FeeDenoms
+	}
+	return nil
+}
+
+func (m *Params) GetFeeConversionSpread() string {
+	if m != nil {
+		return m.FeeConversionSpread
+	}
+	return ""
+}
+
+func (m *Params) GetGasPriceVoteWindow() uint64 {
+	if m != nil {
+		return m.GasPriceVoteWindow
+	}
+	return 0
+}
+
+func (m *Params) GetMaxGasPriceChange() string {
+	if m != nil {
+		return m.MaxGasPriceChange
+	}
+	return ""
+}
+
+// Log is an ethereum log
+type Log struct {
+	Address              string   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Topics               []string `protobuf:"bytes,2,rep,name=topics,proto3" json:"topics,omitempty"`
+	Data                 []byte   `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	BlockNumber          uint64   `protobuf:"varint,4,opt,name=block_number,json=blockNumber,proto3" json:"block_number,omitempty"`
+	TxHash               string   `protobuf:"bytes,5,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
+	TxIndex              uint64   `protobuf:"varint,6,opt,name=tx_index,json=txIndex,proto3" json:"tx_index,omitempty"`
+	BlockHash            string   `protobuf:"bytes,7,opt,name=block_hash,json=blockHash,proto3" json:"block_hash,omitempty"`
+	Index                uint64   `protobuf:"varint,8,opt,name=index,proto3" json:"index,omitempty"`
+	Removed              bool     `protobuf:"varint,9,opt,name=removed,proto3" json:"removed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Log) Reset()         { *m = Log{} }
+func (m *Log) String() string { return proto.CompactTextString(m) }
+func (*Log) ProtoMessage()    {}
+func (*Log) Descriptor() ([]byte, []int) {
+	return fileDescriptor_69ff075df9832927, []int{10}
+}
+func (m *Log) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Log.Unmarshal(m, b)
+}
+func (m *Log) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Log.Marshal(b, m, deterministic)
+}
+func (m *Log) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Log.Merge(m, src)
+}
+func (m *Log) XXX_Size() int {
+	return xxx_messageInfo_Log.Size(m)
+}
+func (m *Log) XXX_DiscardUnknown() {
+	xxx_messageInfo_Log.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Log proto.InternalMessageInfo
+
+func (m *Log) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *Log) GetTopics() []string {
+	if m != nil {
+		return m.Topics
+	}
+	return nil
+}
+
+func (m *Log) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *Log) GetBlockNumber() uint64 {
+	if m != nil {
+		return m.BlockNumber
+	}
+	return 0
+}
+
+func (m *Log) GetTxHash() string {
+	if m != nil {
+		return m.TxHash
+	}
+	return ""
+}
+
+func (m *Log) GetTxIndex() uint64 {
+	if m != nil {
+		return m.TxIndex
+	}
+	return 0
+}
+
+func (m *Log) GetBlockHash() string {
+	if m != nil {
+		return m.BlockHash
+	}
+	return ""
+}
+
+func (m *Log) GetIndex() uint64 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *Log) GetRemoved() bool {
+	if m != nil {
+		return m.Removed
+	}
+	return false
+}
+
+type FeeAllowance struct {
+	// bech32 addresses
+	Granter string `protobuf:"bytes,1,opt,name=granter,proto3" json:"granter,omitempty"`
+	Grantee string `protobuf:"bytes,2,opt,name=grantee,proto3" json:"grantee,omitempty"`
+	// decimal
+	SpendLimit           string   `protobuf:"bytes,3,opt,name=spend_limit,json=spendLimit,proto3" json:"spend_limit,omitempty"`
+	Expiration           int64    `protobuf:"varint,4,opt,name=expiration,proto3" json:"expiration,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FeeAllowance) Reset()         { *m = FeeAllowance{} }
+func (m *FeeAllowance) String() string { return proto.CompactTextString(m) }
+func (*FeeAllowance) ProtoMessage()    {}
+func (*FeeAllowance) Descriptor() ([]byte, []int) {
+	return fileDescriptor_69ff075df9832927, []int{11}
+}
+func (m *FeeAllowance) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FeeAllowance.Unmarshal(m, b)
+}
+func (m *FeeAllowance) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FeeAllowance.Marshal(b, m, deterministic)
+}
+func (m *FeeAllowance) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FeeAllowance.Merge(m, src)
+}
+func (m *FeeAllowance) XXX_Size() int {
+	return xxx_messageInfo_FeeAllowance.Size(m)
+}
+func (m *FeeAllowance) XXX_DiscardUnknown() {
+	xxx_messageInfo_FeeAllowance.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FeeAllowance proto.InternalMessageInfo
+
+func (m *FeeAllowance) GetGranter() string {
+	if m != nil {
+		return m.Granter
+	}
+	return ""
+}
+
+func (m *FeeAllowance) GetGrantee() string {
+	if m != nil {
+		return m.Grantee
+	}
+	return ""
+}
+
+func (m *FeeAllowance) GetSpendLimit() string {
+	if m != nil {
+		return m.SpendLimit
+	}
+	return ""
+}
+
+func (m *FeeAllowance) GetExpiration() int64 {
+	if m != nil {
+		return m.Expiration
+	}
+	return 0
+}
+
+type TokenMetadata struct {
+	Denom    string `protobuf:"bytes,1,opt,name=denom,proto3" json:"denom,omitempty"`
+	Name     string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Symbol   string `protobuf:"bytes,3,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Decimals uint32 `protobuf:"varint,4,opt,name=decimals,proto3" json:"decimals,omitempty"`
+	// hex address of the erc20 contract
+	Contract             string   `protobuf:"bytes,5,opt,name=contract,proto3" json:"contract,omitempty"`
+	OriginChain          string   `protobuf:"bytes,6,opt,name=origin_chain,json=originChain,proto3" json:"origin_chain,omitempty"`
+	OriginDenom          string   `protobuf:"bytes,7,opt,name=origin_denom,json=originDenom,proto3" json:"origin_denom,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TokenMetadata) Reset()         { *m = TokenMetadata{} }
+func (m *TokenMetadata) String() string { return proto.CompactTextString(m) }
+func (*TokenMetadata) ProtoMessage()    {}
+func (*TokenMetadata) Descriptor() ([]byte, []int) {
+	return fileDescriptor_69ff075df9832927, []int{12}
+}
+func (m *TokenMetadata) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TokenMetadata.Unmarshal(m, b)
+}
+func (m *TokenMetadata) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TokenMetadata.Marshal(b, m, deterministic)
+}
+func (m *TokenMetadata) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TokenMetadata.Merge(m, src)
+}
+func (m *TokenMetadata) XXX_Size() int {
+	return xxx_messageInfo_TokenMetadata.Size(m)
+}
+func (m *TokenMetadata) XXX_DiscardUnknown() {
+	xxx_messageInfo_TokenMetadata.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TokenMetadata proto.InternalMessageInfo
+
+func (m *TokenMetadata) GetDenom() string {
+	if m != nil {
+		return m.Denom
+	}
+	return ""
+}
+
+func (m *TokenMetadata) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *TokenMetadata) GetSymbol() string {
+	if m != nil {
+		return m.Symbol
+	}
+	return ""
+}
+
+func (m *TokenMetadata) GetDecimals() uint32 {
+	if m != nil {
+		return m.Decimals
+	}
+	return 0
+}
+
+func (m *TokenMetadata) GetContract() string {
+	if m != nil {
+		return m.Contract
+	}
+	return ""
+}
+
+func (m *TokenMetadata) GetOriginChain() string {
+	if m != nil {
+		return m.OriginChain
+	}
+	return ""
+}
+
+func (m *TokenMetadata) GetOriginDenom() string {
+	if m != nil {
+		return m.OriginDenom
+	}
+	return ""
+}
+
+type GenesisState struct {
+	Accounts []*GenesisAccount  `protobuf:"bytes,1,rep,name=accounts,proto3" json:"accounts,omitempty"`
+	TxsLogs  []*TransactionLogs `protobuf:"bytes,2,rep,name=txs_logs,json=txsLogs,proto3" json:"txs_logs,omitempty"`
+	// bech32 addresses
+	ContractDeploymentWhitelist []string           `protobuf:"bytes,3,rep,name=contract_deployment_whitelist,json=contractDeploymentWhitelist,proto3" json:"contract_deployment_whitelist,omitempty"`
+	ContractBlockedList         []string           `protobuf:"bytes,4,rep,name=contract_blocked_list,json=contractBlockedList,proto3" json:"contract_blocked_list,omitempty"`
+	ContractMethodBlockedList   []*BlockedContract `protobuf:"bytes,5,rep,name=contract_method_blocked_list,json=contractMethodBlockedList,proto3" json:"contract_method_blocked_list,omitempty"`
+	ChainConfig                 *ChainConfig       `protobuf:"bytes,6,opt,name=chain_config,json=chainConfig,proto3" json:"chain_config,omitempty"`
+	Params                      *Params            `protobuf:"bytes,7,opt,name=params,proto3" json:"params,omitempty"`
+	RecentBlockHashes           []*RecentBlockHash `protobuf:"bytes,8,rep,name=recent_block_hashes,json=recentBlockHashes,proto3" json:"recent_block_hashes,omitempty"`
+	FeeAllowances               []*FeeAllowance    `protobuf:"bytes,9,rep,name=fee_allowances,json=feeAllowances,proto3" json:"fee_allowances,omitempty"`
+	TokenRegistry               []*TokenMetadata   `protobuf:"bytes,10,rep,name=token_registry,json=tokenRegistry,proto3" json:"token_registry,omitempty"`
+	XXX_NoUnkeyedLiteral        struct{}           `json:"-"`
+	XXX_unrecognized            []byte             `json:"-"`
+	XXX_sizecache               int32              `json:"-"`
+}
+
+func (m *GenesisState) Reset()         { *m = GenesisState{} }
+func (m *GenesisState) String() string { return proto.CompactTextString(m) }
+func (*GenesisState) ProtoMessage()    {}
+func (*GenesisState) Descriptor() ([]byte, []int) {
+	return fileDescriptor_69ff075df9832927, []int{13}
+}
+func (m *GenesisState) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GenesisState.Unmarshal(m, b)
+}
+func (m *GenesisState) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GenesisState.Marshal(b, m, deterministic)
+}
+func (m *GenesisState) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GenesisState.Merge(m, src)
+}
+func (m *GenesisState) XXX_Size() int {
+	return xxx_messageInfo_GenesisState.Size(m)
+}
+func (m *GenesisState) XXX_DiscardUnknown() {
+	xxx_messageInfo_GenesisState.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GenesisState proto.InternalMessageInfo
+
+func (m *GenesisState) GetAccounts() []*GenesisAccount {
+	if m != nil {
+		return m.Accounts
+	}
+	return nil
+}
+
+func (m *GenesisState) GetTxsLogs() []*TransactionLogs {
+	if m != nil {
+		return m.TxsLogs
+	}
+	return nil
+}
+
+func (m *GenesisState) GetContractDeploymentWhitelist() []string {
+	if m != nil {
+		return m.ContractDeploymentWhitelist
+	}
+	return nil
+}
+
+func (m *GenesisState) GetContractBlockedList() []string {
+	if m != nil {
+		return m.ContractBlockedList
+	}
+	return nil
+}
+
+func (m *GenesisState) GetContractMethodBlockedList() []*BlockedContract {
+	if m != nil {
+		return m.ContractMethodBlockedList
+	}
+	return nil
+}
+
+func (m *GenesisState) GetChainConfig() *ChainConfig {
+	if m != nil {
+		return m.ChainConfig
+	}
+	return nil
+}
+
+func (m *GenesisState) GetParams() *Params {
+	if m != nil {
+		return m.Params
+	}
+	return nil
+}
+
+func (m *GenesisState) GetRecentBlockHashes() []*RecentBlockHash {
+	if m != nil {
+		return m.RecentBlockHashes
+	}
+	return nil
+}
+
+func (m *GenesisState) GetFeeAllowances() []*FeeAllowance {
+	if m != nil {
+		return m.FeeAllowances
+	}
+	return nil
+}
+
+func (m *GenesisState) GetTokenRegistry() []*TokenMetadata {
+	if m != nil {
+		return m.TokenRegistry
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*MsgEthereumTx)(nil), "okexchain.evm.v1.MsgEthereumTx")
+	proto.RegisterType((*Tx)(nil), "okexchain.evm.v1.Tx")
+	proto.RegisterType((*State)(nil), "okexchain.evm.v1.State")
+	proto.RegisterType((*GenesisAccount)(nil), "okexchain.evm.v1.GenesisAccount")
+	proto.RegisterType((*TransactionLogs)(nil), "okexchain.evm.v1.TransactionLogs")
+	proto.RegisterType((*ContractMethod)(nil), "okexchain.evm.v1.ContractMethod")
+	proto.RegisterType((*BlockedContract)(nil), "okexchain.evm.v1.BlockedContract")
+	proto.RegisterType((*ChainConfig)(nil), "okexchain.evm.v1.ChainConfig")
+	proto.RegisterType((*RecentBlockHash)(nil), "okexchain.evm.v1.RecentBlockHash")
+	proto.RegisterType((*Params)(nil), "okexchain.evm.v1.Params")
+	proto.RegisterType((*Log)(nil), "okexchain.evm.v1.Log")
+	proto.RegisterType((*FeeAllowance)(nil), "okexchain.evm.v1.FeeAllowance")
+	proto.RegisterType((*TokenMetadata)(nil), "okexchain.evm.v1.TokenMetadata")
+	proto.RegisterType((*GenesisState)(nil), "okexchain.evm.v1.GenesisState")
+}
+
+func init() { proto.RegisterFile("x/evm/evmproto/evm.proto", fileDescriptor_69ff075df9832927) }
+
+var fileDescriptor_69ff075df9832927 = []byte{
+	// 1460 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x57, 0xdd, 0x6e, 0x1b, 0xbb,
+	0x11, 0x86, 0x2d, 0xc9, 0x96, 0x46, 0x3f, 0x76, 0xd6, 0x76, 0x22, 0xa7, 0xf9, 0x71, 0x94, 0xb4,
+	0x71, 0x80, 0x42, 0x8a, 0xd5, 0x06, 0x0d, 0x8a, 0x16, 0x6d, 0xe2, 0xc4, 0x69, 0x01, 0xa7, 0x75,
+	0x19, 0x23, 0x01, 0x7a, 0xb3, 0xa0, 0x76, 0x47, 0xab, 0x85, 0x77, 0x97, 0x5b, 0x92, 0xb2, 0xa5,
+	0xde, 0xf6, 0x3d, 0xfa, 0x24, 0xe7, 0x1d, 0xce, 0xab, 0x9c, 0xf3, 0x06, 0x07, 0x1c, 0x72, 0x57,
+	0x92, 0xed, 0xf8, 0xc2, 0x30, 0x67, 0xe6, 0x9b, 0x6f, 0xc9, 0x19, 0xce, 0x70, 0x04, 0xdd, 0xd9,
+	0x00, 0x2f, 0x53, 0xf3, 0x97, 0x4b, 0xa1, 0x85, 0x59, 0xf4, 0x69, 0xe5, 0x6d, 0x8b, 0x0b, 0x9c,
+	0x05, 0x13, 0x1e, 0x67, 0x7d, 0xa3, 0xbc, 0x3c, 0x7a, 0xb8, 0x1f, 0x09, 0x11, 0x25, 0x38, 0x20,
+	0xfb, 0x68, 0x3a, 0x1e, 0xf0, 0x6c, 0x6e, 0xc1, 0xbd, 0xe7, 0xd0, 0xfe, 0xac, 0xa2, 0x8f, 0x7a,
+	0x82, 0x12, 0xa7, 0xe9, 0xf9, 0xcc, 0xf3, 0xa0, 0x1a, 0x72, 0xcd, 0xbb, 0x6b, 0x07, 0x6b, 0x87,
+	0x2d, 0x46, 0xeb, 0x5e, 0x1f, 0xd6, 0xcf, 0x67, 0xde, 0x21, 0x54, 0x53, 0x15, 0xa9, 0xee, 0xda,
+	0x41, 0xe5, 0xb0, 0x39, 0xdc, 0xed, 0x5b, 0xd2, 0x7e, 0x41, 0xda, 0x7f, 0x97, 0xcd, 0x19, 0x21,
+	0x7a, 0x03, 0xa8, 0x7d, 0xd1, 0x5c, 0xa3, 0xb7, 0x0d, 0x95, 0x0b, 0x9c, 0x13, 0x57, 0x83, 0x99,
+	0xa5, 0xb7, 0x0b, 0xb5, 0x4b, 0x9e, 0x4c, 0xb1, 0xbb, 0x4e, 0x3a, 0x2b, 0xf4, 0xfe, 0x03, 0x9d,
+	0x4f, 0x98, 0xa1, 0x8a, 0xd5, 0xbb, 0x20, 0x10, 0xd3, 0x4c, 0x7b, 0x5d, 0xd8, 0xe4, 0x61, 0x28,
+	0x51, 0x29, 0xe7, 0x5d, 0x88, 0x66, 0x83, 0x81, 0x08, 0x2d, 0x41, 0x8b, 0xd1, 0xda, 0x3b, 0x82,
+	0x4d, 0xa5, 0x85, 0xe4, 0x11, 0x76, 0x2b, 0xb4, 0xbb, 0x07, 0xfd, 0xeb, 0x41, 0xe8, 0xd3, 0x8e,
+	0x58, 0x81, 0xeb, 0x9d, 0xc1, 0xd6, 0xb9, 0xe4, 0x99, 0xe2, 0x81, 0x8e, 0x45, 0x76, 0x2a, 0x22,
+	0x62, 0x9e, 0x70, 0x35, 0x71, 0x1f, 0xa4, 0xb5, 0xf7, 0x0a, 0xaa, 0x89, 0x88, 0x54, 0x77, 0x9d,
+	0x68, 0xf7, 0x6e, 0xd2, 0x9e, 0x8a, 0x88, 0x11, 0xa4, 0xf7, 0x47, 0xe8, 0x1c, 0x8b, 0x4c, 0x4b,
+	0x1e, 0xe8, 0xcf, 0xa8, 0x27, 0x22, 0x34, 0x84, 0x2a, 0x8e, 0xb2, 0x82, 0xd0, 0xac, 0x4d, 0x00,
+	0x70, 0xa6, 0x25, 0x2f, 0x02, 0x40, 0x42, 0x4f, 0xc2, 0xd6, 0xfb, 0x44, 0x04, 0x17, 0x18, 0x16,
+	0x14, 0x77, 0x44, 0xe0, 0x23, 0xb4, 0x47, 0x06, 0xec, 0xa7, 0xf4, 0x99, 0x62, 0x73, 0x07, 0x37,
+	0x37, 0xb7, 0xba, 0x1f, 0xd6, 0x22, 0x37, 0x2b, 0xa8, 0xde, 0xff, 0x6b, 0xd0, 0x3c, 0x36, 0xe8,
+	0x63, 0x91, 0x8d, 0xe3, 0xc8, 0x7b, 0x09, 0x5b, 0x13, 0x91, 0xa2, 0xd2, 0xc8, 0x43, 0x9f, 0x90,
+	0xee, 0xc3, 0x9d, 0x52, 0x4d, 0x7b, 0xf4, 0x5e, 0x40, 0x27, 0xe4, 0xc2, 0x1f, 0x0b, 0x79, 0xe1,
+	0x70, 0xf6, 0x2c, 0xad, 0x90, 0x8b, 0x13, 0x21, 0x2f, 0x2c, 0xea, 0x10, 0xb6, 0x4b, 0x94, 0x9a,
+	0xe6, 0xb9, 0x90, 0xba, 0x5b, 0x39, 0x58, 0x3b, 0xac, 0xb3, 0x8e, 0xc3, 0x7d, 0xb1, 0x5a, 0xef,
+	0x19, 0xb4, 0x30, 0xce, 0x8f, 0xde, 0xbc, 0x76, 0x6c, 0x55, 0x62, 0x6b, 0x5a, 0x9d, 0x25, 0x7b,
+	0x0a, 0x4e, 0xf4, 0x29, 0x43, 0x35, 0x42, 0x80, 0x55, 0xfd, 0xcd, 0xe4, 0xa9, 0xe0, 0x78, 0xe3,
+	0x38, 0x36, 0x96, 0x38, 0xde, 0x58, 0x8e, 0x02, 0xf2, 0xd6, 0x41, 0x36, 0x97, 0x20, 0x6f, 0x2d,
+	0xe4, 0x25, 0x6c, 0x8d, 0xe6, 0xff, 0xe5, 0x99, 0x8e, 0xa7, 0xa9, 0x43, 0xd5, 0x6d, 0x08, 0x4a,
+	0xb5, 0x05, 0x1e, 0xc1, 0x6e, 0x20, 0x32, 0xa5, 0x8d, 0x2e, 0x13, 0x79, 0x82, 0x0e, 0xdd, 0x20,
+	0xf4, 0xce, 0xaa, 0xcd, 0xba, 0xbc, 0x82, 0xed, 0x1c, 0x35, 0x4a, 0x35, 0x9a, 0xca, 0xc8, 0xc1,
+	0x81, 0xe0, 0x5b, 0x0b, 0xbd, 0x85, 0xfe, 0x1a, 0x3a, 0xb1, 0xf1, 0x1f, 0x4d, 0x13, 0x07, 0x6c,
+	0x12, 0xb0, 0x5d, 0x68, 0x2d, 0xec, 0xb7, 0xe0, 0xa5, 0xd3, 0x58, 0xfa, 0x51, 0xc2, 0x83, 0x18,
+	0xa5, 0x83, 0xb6, 0x08, 0xba, 0x6d, 0x2c, 0x9f, 0xac, 0xc1, 0xa2, 0x7b, 0xd0, 0x9e, 0x8b, 0x44,
+	0xf8, 0x97, 0x43, 0x07, 0x6c, 0xdb, 0xf3, 0x1b, 0xe5, 0xd7, 0xe1, 0x22, 0xcc, 0x57, 0x5c, 0x15,
+	0x67, 0xef, 0xb8, 0x30, 0x1b, 0x55, 0x19, 0xc3, 0x11, 0xca, 0x24, 0xce, 0x1c, 0x62, 0xcb, 0x72,
+	0x58, 0x5d, 0x09, 0x49, 0x44, 0x16, 0x8a, 0x02, 0xb2, 0x6d, 0x21, 0x56, 0x57, 0x9e, 0x4f, 0x4d,
+	0x78, 0x16, 0x4d, 0x78, 0xec, 0x40, 0xf7, 0xec, 0xf9, 0x0a, 0x2d, 0xc1, 0x7a, 0x7f, 0x86, 0x2d,
+	0x86, 0x01, 0x66, 0x9a, 0x44, 0x4a, 0xf3, 0x7d, 0xd8, 0x98, 0x60, 0x1c, 0x4d, 0x34, 0x5d, 0xcd,
+	0x2a, 0x73, 0x52, 0x59, 0xba, 0xeb, 0x8b, 0xd2, 0xed, 0xfd, 0x54, 0x85, 0x8d, 0x33, 0x2e, 0x79,
+	0xaa, 0xbc, 0xe7, 0xd0, 0xc6, 0x8c, 0x8f, 0x12, 0xf4, 0x03, 0x89, 0x5c, 0x23, 0x79, 0xd7, 0x59,
+	0xcb, 0x2a, 0x8f, 0x49, 0x47, 0x87, 0x77, 0x20, 0x9e, 0x24, 0x44, 0x55, 0x67, 0xe0, 0x20, 0x3c,
+	0x49, 0xbc, 0xc7, 0x00, 0x54, 0xad, 0x3e, 0xc6, 0xb9, 0xa2, 0x46, 0x53, 0x61, 0x0d, 0xd2, 0x7c,
+	0x8c, 0x73, 0xe5, 0xfd, 0x13, 0x5e, 0x14, 0xfe, 0xae, 0xec, 0xfc, 0x10, 0xf3, 0x44, 0xcc, 0x53,
+	0xcc, 0xb4, 0x7f, 0x35, 0x89, 0x35, 0x26, 0xb1, 0xd2, 0x74, 0xbd, 0xeb, 0xec, 0x99, 0x23, 0x76,
+	0xd0, 0x0f, 0x25, 0xf2, 0x5b, 0x01, 0xf4, 0xfe, 0x02, 0x8f, 0xae, 0x13, 0x8e, 0x6c, 0x93, 0xf0,
+	0x89, 0xa8, 0x46, 0x44, 0xfb, 0xab, 0x44, 0xae, 0x8d, 0x9c, 0x1a, 0x82, 0x3e, 0xec, 0xa6, 0x7c,
+	0xe6, 0x47, 0x5c, 0xf9, 0x49, 0x9c, 0xc6, 0xda, 0xcf, 0x51, 0xfa, 0x7a, 0x46, 0xc5, 0x51, 0x65,
+	0xdb, 0x29, 0x9f, 0x7d, 0xe2, 0xea, 0xd4, 0x58, 0xce, 0x50, 0x9e, 0xcf, 0xbc, 0x7d, 0xa8, 0x8f,
+	0xb8, 0x42, 0x7f, 0x8c, 0x48, 0xd5, 0x51, 0x65, 0x9b, 0x46, 0x3e, 0x41, 0xf4, 0x7e, 0x0f, 0x0f,
+	0x6e, 0x52, 0x2d, 0x2a, 0xa4, 0xca, 0x76, 0x56, 0xd9, 0x6c, 0xa2, 0xff, 0x00, 0x5d, 0x77, 0x02,
+	0xdb, 0xb0, 0x4c, 0x5e, 0xfc, 0xab, 0x38, 0x0b, 0xc5, 0x15, 0x95, 0x4a, 0x9d, 0xed, 0x59, 0x7b,
+	0x99, 0xe1, 0x6f, 0x64, 0x34, 0xa1, 0x1e, 0x23, 0xfa, 0x21, 0x66, 0x22, 0x55, 0x5d, 0x38, 0xa8,
+	0x1c, 0x36, 0x58, 0x63, 0x8c, 0xf8, 0x81, 0x14, 0xde, 0x10, 0xf6, 0x8c, 0x39, 0x10, 0xd9, 0x25,
+	0x4a, 0x15, 0x8b, 0xcc, 0x57, 0xb9, 0x44, 0x1e, 0xba, 0x3a, 0xd9, 0x19, 0x23, 0x1e, 0x97, 0xb6,
+	0x2f, 0x64, 0xf2, 0x8e, 0x60, 0xcf, 0xec, 0x3e, 0x97, 0x71, 0x80, 0xfe, 0xa5, 0xd0, 0x58, 0x6c,
+	0xa4, 0x45, 0xfb, 0xf7, 0x22, 0xae, 0xce, 0x8c, 0xed, 0xab, 0xd0, 0xe8, 0x76, 0x31, 0x58, 0xc4,
+	0xcf, 0xba, 0x05, 0xe6, 0x7e, 0xa2, 0xab, 0x9c, 0x7b, 0xf6, 0xc4, 0xe4, 0x74, 0x4c, 0x86, 0xde,
+	0xcf, 0x6b, 0x50, 0x39, 0x15, 0xd1, 0x1d, 0xbd, 0xfb, 0x3e, 0x6c, 0x68, 0x91, 0xc7, 0x81, 0x6d,
+	0xda, 0x0d, 0xe6, 0xa4, 0xf2, 0xd9, 0xad, 0x2c, 0x9e, 0x5d, 0x2a, 0x36, 0x0a, 0x5b, 0x36, 0x4d,
+	0x47, 0x28, 0xe9, 0xe2, 0x54, 0x59, 0x93, 0x74, 0xff, 0x20, 0x95, 0xf7, 0x00, 0x36, 0xf5, 0x6c,
+	0xb9, 0x27, 0x6e, 0xe8, 0x19, 0x15, 0xca, 0x3e, 0xd4, 0xf5, 0xcc, 0x8f, 0xb3, 0x10, 0x8b, 0x74,
+	0x6f, 0xea, 0xd9, 0xdf, 0x8d, 0x68, 0x62, 0xbb, 0xc8, 0x86, 0xeb, 0x82, 0x8d, 0x51, 0x59, 0x62,
+	0xbb, 0x50, 0xb3, 0x6e, 0x36, 0xaf, 0x56, 0x30, 0x27, 0x92, 0x98, 0x8a, 0x4b, 0x0c, 0x5d, 0xe2,
+	0x0a, 0xb1, 0xf7, 0xbf, 0x35, 0x68, 0x9d, 0x20, 0xbe, 0x4b, 0x12, 0x71, 0xc5, 0xb3, 0x00, 0x0d,
+	0x34, 0x92, 0x3c, 0xd3, 0x28, 0x8b, 0xc3, 0x3b, 0x71, 0x61, 0x29, 0x9e, 0xff, 0x42, 0x34, 0xb5,
+	0xa7, 0x72, 0xcc, 0x42, 0x7b, 0xb9, 0x28, 0x0a, 0x0d, 0x06, 0xa4, 0xa2, 0x1b, 0xe5, 0x3d, 0x31,
+	0xb5, 0x97, 0xc7, 0x92, 0x9b, 0xd7, 0x9a, 0x22, 0x51, 0x61, 0x4b, 0x9a, 0xde, 0x8f, 0x6b, 0xd0,
+	0x3e, 0x17, 0x17, 0x98, 0x7d, 0x46, 0xcd, 0x29, 0x7a, 0xbb, 0x50, 0xa3, 0xeb, 0xe3, 0x36, 0x61,
+	0x05, 0x13, 0xe7, 0x8c, 0xa7, 0xc5, 0xf7, 0x69, 0x6d, 0x72, 0xa2, 0xe6, 0xe9, 0x48, 0x24, 0xee,
+	0xbb, 0x4e, 0xf2, 0x1e, 0x42, 0x3d, 0xc4, 0x20, 0x4e, 0x79, 0xa2, 0xe8, 0x8b, 0x6d, 0x56, 0xca,
+	0xc6, 0x56, 0x14, 0xa5, 0x8b, 0x7c, 0x29, 0x9b, 0xbc, 0x09, 0x19, 0x47, 0x71, 0xe6, 0xd3, 0x63,
+	0x5c, 0xbc, 0x45, 0x56, 0x47, 0x2f, 0xee, 0x12, 0xc4, 0xee, 0x71, 0x73, 0x19, 0x42, 0x97, 0xbc,
+	0xf7, 0x43, 0x0d, 0x5a, 0x6e, 0x28, 0xb2, 0xc3, 0xd4, 0x9f, 0xa0, 0xce, 0xed, 0x74, 0x54, 0xcc,
+	0x60, 0xb7, 0xbc, 0xf8, 0xab, 0x63, 0x14, 0x2b, 0x3d, 0x8c, 0xb7, 0x9e, 0x29, 0x7f, 0x69, 0x98,
+	0x79, 0x76, 0xd3, 0xfb, 0xda, 0x44, 0x64, 0xee, 0x8c, 0x32, 0x0b, 0xef, 0x3d, 0x3c, 0xbe, 0xbb,
+	0xa9, 0x55, 0xe8, 0x36, 0xff, 0x2a, 0xb8, 0xa3, 0x9d, 0x0d, 0x61, 0xef, 0xf6, 0x3e, 0x56, 0x3d,
+	0xa8, 0xb8, 0x47, 0xf3, 0x46, 0x07, 0x1b, 0xc1, 0xa3, 0xd2, 0xc7, 0x4e, 0x3b, 0xd7, 0x5b, 0xe0,
+	0x77, 0x4e, 0x72, 0x6d, 0x9a, 0x62, 0xfb, 0xc1, 0xca, 0x28, 0xb4, 0xfc, 0x8d, 0xbf, 0x42, 0x8b,
+	0x5c, 0x4d, 0x3b, 0x19, 0xc7, 0x11, 0xa5, 0xab, 0x39, 0x7c, 0x7c, 0xcb, 0x34, 0xb5, 0x18, 0x96,
+	0x58, 0x33, 0x58, 0x08, 0xde, 0x6b, 0xd8, 0xc8, 0xe9, 0xa1, 0xa1, 0x3c, 0x36, 0x87, 0xdd, 0x9b,
+	0xbe, 0xf6, 0x21, 0x62, 0x0e, 0xe7, 0xfd, 0x0b, 0x76, 0x24, 0x3d, 0x6d, 0x4b, 0x8d, 0x11, 0x55,
+	0xb7, 0xfe, 0xbd, 0xe3, 0x5c, 0x7b, 0x07, 0xd9, 0x3d, 0xb9, 0xaa, 0x40, 0x33, 0x15, 0x76, 0x4c,
+	0x4f, 0xe4, 0x45, 0x1d, 0xaa, 0x6e, 0x83, 0xd8, 0x9e, 0xdc, 0x64, 0x5b, 0x2e, 0x57, 0xd6, 0x1e,
+	0x2f, 0x49, 0xca, 0x3b, 0x81, 0x8e, 0x36, 0x75, 0xe4, 0x4b, 0x8c, 0x62, 0xa5, 0xe5, 0x9c, 0xba,
+	0x6f, 0x73, 0xf8, 0xf4, 0x96, 0xdb, 0xb2, 0x5c, 0x6f, 0xac, 0x4d, 0x6e, 0xcc, 0x79, 0xbd, 0x3f,
+	0xfc, 0xf7, 0x6f, 0xa2, 0x58, 0x4f, 0xa6, 0xa3, 0x7e, 0x20, 0xd2, 0x81, 0xf1, 0x1d, 0x38, 0xf7,
+	0xc1, 0xea, 0x2f, 0x97, 0xd1, 0x06, 0xfd, 0xfb, 0xdd, 0x2f, 0x03, 0x00, 0xff, 0xbf, 0x16, 0xc9,
+	0xd2, 0x0c, 0x00, 0x00,
+}
@@ -0,0 +1,70 @@
+package evm
+
+import (
+	"fmt"
+	"strings"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+)
+
+// exportFilter selects the accounts whose code and storage are exported, by an address list or by the blooms of the
+// blocks since a height. A nil filter selects every account.
+type exportFilter struct {
+	addresses map[ethcmn.Address]struct{}
+	blooms    []ethtypes.Bloom
+}
+
+// newExportFilter returns the filter of the given hex addresses and of the accounts touched since sinceHeight, that
+// is the accounts which emitted logs in a block since then. It returns nil when neither is set.
+func newExportFilter(ctx sdk.Context, k Keeper, addresses []string, sinceHeight int64) (*exportFilter, error) {
+	if len(addresses) == 0 && sinceHeight <= 0 {
+		return nil, nil
+	}
+
+	filter := &exportFilter{addresses: make(map[ethcmn.Address]struct{})}
+	for _, addrs := range addresses {
+		// the addresses are comma separated when they are set through the environment
+		for _, addr := range strings.Split(addrs, ",") {
+			addr = strings.TrimSpace(addr)
+			if addr == "" {
+				continue
+			}
+			if !ethcmn.IsHexAddress(addr) {
+				return nil, fmt.Errorf("invalid address %s to export", addr)
+			}
+			filter.addresses[ethcmn.HexToAddress(addr)] = struct{}{}
+		}
+	}
+
+	if sinceHeight > 0 {
+		if sinceHeight > ctx.BlockHeight() {
+			return nil, fmt.Errorf("export height %d is above the current height %d", sinceHeight, ctx.BlockHeight())
+		}
+		for height := sinceHeight; height <= ctx.BlockHeight(); height++ {
+			bloom := k.GetBlockBloom(ctx, height)
+			if bloom != (ethtypes.Bloom{}) {
+				filter.blooms = append(filter.blooms, bloom)
+			}
+		}
+	}
+	return filter, nil
+}
+
+// contains reports whether the code and storage of addr are exported. The blooms may match accounts which were not
+// touched, those are exported too.
+func (f *exportFilter) contains(addr ethcmn.Address) bool {
+	if f == nil {
+		return true
+	}
+	if _, ok := f.addresses[addr]; ok {
+		return true
+	}
+	for _, bloom := range f.blooms {
+		if bloom.Test(addr.Bytes()) {
+			return true
+		}
+	}
+	return false
+}
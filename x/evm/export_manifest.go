@@ -0,0 +1,100 @@
+package evm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/okex/exchain/libs/cosmos-sdk/version"
+)
+
+const (
+	exportManifestFile = "evm_manifest.json"
+
+	// legacyExportVersion is the format of the code and storage files or dbs exported without a manifest, by the
+	// releases before versioning. They are imported as such, without checking their counts.
+	legacyExportVersion = 0
+	// exportVersion is the format exported by this release, the legacy layout described by a manifest
+	exportVersion = 1
+)
+
+// exportManifest describes the code and storage exported alongside the genesis, in files or dbs
+type exportManifest struct {
+	Version    uint32 `json:"version"`
+	AppVersion string `json:"app_version"`
+	Mode       string `json:"mode"`
+	Height     int64  `json:"height"`
+	Accounts   uint64 `json:"accounts"`
+	Code       uint64 `json:"code"`
+	Storage    uint64 `json:"storage"`
+}
+
+func newExportManifest(mode string, height int64, accounts int) exportManifest {
+	return exportManifest{
+		Version:    exportVersion,
+		AppVersion: version.Version,
+		Mode:       mode,
+		Height:     height,
+		Accounts:   uint64(accounts),
+		Code:       codeCount,
+		Storage:    storageCount,
+	}
+}
+
+func writeExportManifest(dataPath string, manifest exportManifest) error {
+	bz, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dataPath, exportManifestFile), bz, 0644)
+}
+
+// readExportManifest reads the manifest of the export at dataPath, a legacy export has none
+func readExportManifest(dataPath string) (exportManifest, error) {
+	bz, err := ioutil.ReadFile(filepath.Join(dataPath, exportManifestFile))
+	if os.IsNotExist(err) {
+		return exportManifest{Version: legacyExportVersion}, nil
+	}
+	if err != nil {
+		return exportManifest{}, err
+	}
+
+	var manifest exportManifest
+	if err := json.Unmarshal(bz, &manifest); err != nil {
+		return exportManifest{}, fmt.Errorf("invalid manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// checkImport checks the export can be imported in the given mode along with the genesis accounts
+func (m exportManifest) checkImport(mode string, accounts int) error {
+	switch m.Version {
+	case legacyExportVersion:
+		return nil
+	case exportVersion:
+	default:
+		return fmt.Errorf("export format version %d is not supported, the latest supported is %d", m.Version, exportVersion)
+	}
+
+	if m.Mode != mode {
+		return fmt.Errorf("exported in %s mode, cannot be imported in %s mode", m.Mode, mode)
+	}
+	if m.Accounts != uint64(accounts) {
+		return fmt.Errorf("%d accounts exported at height %d, the genesis has %d", m.Accounts, m.Height, accounts)
+	}
+	return nil
+}
+
+// checkImported checks all the exported code and storage were imported
+func (m exportManifest) checkImported(code, storage uint64) error {
+	if m.Version == legacyExportVersion {
+		return nil
+	}
+	if m.Code != code || m.Storage != storage {
+		return fmt.Errorf("incomplete export: %d code and %d storage exported, %d and %d imported",
+			m.Code, m.Storage, code, storage)
+	}
+	return nil
+}
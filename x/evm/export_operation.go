@@ -33,6 +33,7 @@ const (
 var (
 	codePath       string
 	storagePath    string
+	evmDataPath    string
 	defaultPath, _ = os.Getwd()
 
 	goroutinePool chan struct{}
@@ -44,11 +45,22 @@ var (
 	evmByteCodeDB, evmStateDB dbm.DB
 )
 
+// accountData is the code and storage of an account to import, read by a goroutine of the pool
+type accountData struct {
+	address  ethcmn.Address
+	codeHash []byte
+	code     []byte
+	storage  types.Storage
+	err      error
+}
+
 // initExportEnv only initializes the paths and goroutine pool
 func initExportEnv(dataPath, mode string, goroutineNum uint64) {
 	if dataPath == "" {
 		dataPath = defaultPath
 	}
+	evmDataPath = dataPath
+	codeCount, storageCount = 0, 0
 
 	switch mode {
 	case "default":
@@ -80,6 +92,8 @@ func initImportEnv(dataPath, mode string, goroutineNum uint64) {
 	if dataPath == "" {
 		dataPath = defaultPath
 	}
+	evmDataPath = dataPath
+	codeCount, storageCount = 0, 0
 	switch mode {
 	case "default":
 		return
@@ -90,6 +104,7 @@ func initImportEnv(dataPath, mode string, goroutineNum uint64) {
 		initGoroutinePool(goroutineNum)
 	case "db":
 		initEVMDB(dataPath)
+		initGoroutinePool(goroutineNum)
 	default:
 		panic("unsupported import mode")
 	}
@@ -105,17 +120,6 @@ func exportToFile(ctx sdk.Context, k Keeper, address ethcmn.Address) {
 	go syncWriteAccountStorage(ctx, k, address)
 }
 
-// importFromFile import EVM code and storage from files
-func importFromFile(ctx sdk.Context, logger log.Logger, k Keeper, address ethcmn.Address, codeHash []byte) {
-	// read Code from file
-	addGoroutine()
-	go syncReadCodeFromFile(ctx, logger, k, address, codeHash)
-
-	// read Storage From file
-	addGoroutine()
-	go syncReadStorageFromFile(ctx, logger, k, address)
-}
-
 // exportToDB export EVM code and storage to leveldb
 func exportToDB(ctx sdk.Context, k Keeper, address ethcmn.Address, codeHash []byte) {
 	if code := k.GetCode(ctx, address); len(code) > 0 {
@@ -130,31 +134,73 @@ func exportToDB(ctx sdk.Context, k Keeper, address ethcmn.Address, codeHash []by
 	go exportStorage(ctx, k, address, evmStateDB)
 }
 
-// importFromDB import EVM code and storage to leveldb
-func importFromDB(ctx sdk.Context, k Keeper, address ethcmn.Address, codeHash []byte) {
-	if isEmptyState(evmByteCodeDB) || isEmptyState(evmStateDB) {
-		panic("failed to open evm db")
+// importAccounts reads the code and storage of the accounts with the goroutine pool, and writes them one account
+// after another in the order of the accounts, so the import doesn't depend on the order the reads finish in
+func importAccounts(ctx sdk.Context, logger log.Logger, k Keeper, mode string, accounts []accountData) {
+	read := readAccountFromFiles
+	if mode == dbMode {
+		if isEmptyState(evmByteCodeDB) || isEmptyState(evmStateDB) {
+			panic("failed to open evm db")
+		}
+		read = readAccountFromDB
 	}
 
-	code, err := evmByteCodeDB.Get(append(types.KeyPrefixCode, codeHash...))
-	if err != nil {
-		panic(err)
+	results := make([]chan *accountData, len(accounts))
+	for i := range results {
+		results[i] = make(chan *accountData, 1)
 	}
-	if len(code) != 0 {
-		k.SetCodeDirectly(ctx, codeHash, code)
-		codeCount++
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for i := range accounts {
+			// the slot is released once the account is written, which bounds the accounts held in memory
+			select {
+			case goroutinePool <- struct{}{}:
+			case <-done:
+				return
+			}
+			go func(acc *accountData, result chan<- *accountData) {
+				acc.err = read(logger, acc)
+				result <- acc
+			}(&accounts[i], results[i])
+		}
+	}()
+
+	for _, result := range results {
+		acc := <-result
+		<-goroutinePool
+		if acc.err != nil {
+			panic(acc.err)
+		}
+		if len(acc.code) != 0 {
+			k.SetCodeDirectly(ctx, acc.codeHash, acc.code)
+			codeCount++
+		}
+		k.SetStorageDirectly(ctx, acc.address, acc.storage)
+		storageCount += uint64(len(acc.storage))
+		acc.code, acc.storage = nil, nil
 	}
+}
 
-	prefix := types.AddressStoragePrefix(address)
+// readAccountFromDB reads the code and storage of an account from leveldb
+func readAccountFromDB(_ log.Logger, acc *accountData) error {
+	code, err := evmByteCodeDB.Get(append(types.KeyPrefixCode, acc.codeHash...))
+	if err != nil {
+		return err
+	}
+	acc.code = code
+
+	prefix := types.AddressStoragePrefix(acc.address)
 	iterator, err := evmStateDB.Iterator(prefix, sdk.PrefixEndBytes(prefix))
 	if err != nil {
-		panic(err)
+		return err
 	}
+	defer iterator.Close()
 	for ; iterator.Valid(); iterator.Next() {
-		k.SetStateDirectly(ctx, address, ethcmn.BytesToHash(iterator.Key()[len(prefix):]), ethcmn.BytesToHash(iterator.Value()))
-		storageCount++
+		acc.storage = append(acc.storage, types.NewState(
+			ethcmn.BytesToHash(iterator.Key()[len(prefix):]), ethcmn.BytesToHash(iterator.Value())))
 	}
-	iterator.Close()
+	return nil
 }
 
 func exportStorage(ctx sdk.Context, k Keeper, addr ethcmn.Address, db dbm.DB) {
@@ -186,7 +232,10 @@ func initEVMDB(path string) {
 // initGoroutinePool creates an appropriate number of maximum goroutine
 func initGoroutinePool(goroutineNum uint64) {
 	if goroutineNum == 0 {
-		goroutineNum = uint64(runtime.NumCPU()-1) * 16
+		goroutineNum = 16
+		if runtime.NumCPU() > 1 {
+			goroutineNum = uint64(runtime.NumCPU()-1) * 16
+		}
 	}
 	goroutinePool = make(chan struct{}, goroutineNum)
 }
@@ -288,57 +337,47 @@ func syncWriteAccountStorage(ctx sdk.Context, k Keeper, address ethcmn.Address)
 // ************************************************************************************************************
 // the List of functions are used for loading different type of data, then persists data on db
 //    First, get data from local file
-//    Second, format data, then set them into db
+//    Second, format data, then importAccounts sets them into db in the order of the accounts
 // ************************************************************************************************************
-// syncReadCodeFromFile synchronize the process of setting types.Code into evm db when InitGenesis
-func syncReadCodeFromFile(ctx sdk.Context, logger log.Logger, k Keeper, address ethcmn.Address, codeHash []byte) {
-	defer finishGoroutine()
-
-	codeFilePath := filepath.Join(codePath, address.String()+codeFileSuffix)
+// readAccountFromFiles reads the code and storage of an account from its files, if any
+func readAccountFromFiles(logger log.Logger, acc *accountData) error {
+	codeFilePath := filepath.Join(codePath, acc.address.String()+codeFileSuffix)
 	if pathExist(codeFilePath) {
-		logger.Debug("start loading code", "filename", address.String()+codeFileSuffix)
+		logger.Debug("start loading code", "filename", acc.address.String()+codeFileSuffix)
 		bin, err := ioutil.ReadFile(codeFilePath)
 		if err != nil {
-			panic(err)
+			return err
 		}
 
 		// make "0x608002412.....80" string into a slice of byte
-		code := hexutil.MustDecode(string(bin))
-
-		// Set contract code into db, ignoring setting in cache
-		k.SetCodeDirectly(ctx, codeHash, code)
-		atomic.AddUint64(&codeCount, 1)
+		if acc.code, err = hexutil.Decode(string(bin)); err != nil {
+			return fmt.Errorf("invalid code in %s: %w", codeFilePath, err)
+		}
 	}
-}
-
-// syncReadStorageFromFile synchronize the process of setting types.Storage into evm db when InitGenesis
-func syncReadStorageFromFile(ctx sdk.Context, logger log.Logger, k Keeper, address ethcmn.Address) {
-	defer finishGoroutine()
 
-	storageFilePath := filepath.Join(storagePath, address.String()+storageFileSuffix)
-	if pathExist(storageFilePath) {
-		logger.Debug("start loading storage", "filename", address.String()+storageFileSuffix)
-		f, err := os.Open(storageFilePath)
-		if err != nil {
-			panic(err)
-		}
-		defer f.Close()
-		rd := bufio.NewReader(f)
-		for {
-			// eg. kvStr = "0xc543bf77d2a7bddbeb14b8d8bfa3405a8410be06d8c3e68d5bd5e7b9abd43d39:0x4e584d0000000000000000000000000000000000000000000000000000000006\n"
-			kvStr, err := rd.ReadString('\n')
-			if err != nil || io.EOF == err {
-				break
-			}
-			// remove '\n' in the end of string, then split kvStr based on ':'
-			kvPair := strings.Split(strings.ReplaceAll(kvStr, "\n", ""), ":")
-			//convert hexStr into common.Hash struct
-			key, value := ethcmn.HexToHash(kvPair[0]), ethcmn.HexToHash(kvPair[1])
-			// Set the state of key&value into db, ignoring setting in cache
-			k.SetStateDirectly(ctx, address, key, value)
-			atomic.AddUint64(&storageCount, 1)
+	storageFilePath := filepath.Join(storagePath, acc.address.String()+storageFileSuffix)
+	if !pathExist(storageFilePath) {
+		return nil
+	}
+	logger.Debug("start loading storage", "filename", acc.address.String()+storageFileSuffix)
+	f, err := os.Open(storageFilePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	rd := bufio.NewReader(f)
+	for {
+		// eg. kvStr = "0xc543bf77d2a7bddbeb14b8d8bfa3405a8410be06d8c3e68d5bd5e7b9abd43d39:0x4e584d0000000000000000000000000000000000000000000000000000000006\n"
+		kvStr, err := rd.ReadString('\n')
+		if err != nil || io.EOF == err {
+			break
 		}
+		// remove '\n' in the end of string, then split kvStr based on ':'
+		kvPair := strings.Split(strings.ReplaceAll(kvStr, "\n", ""), ":")
+		//convert hexStr into common.Hash struct
+		acc.storage = append(acc.storage, types.NewState(ethcmn.HexToHash(kvPair[0]), ethcmn.HexToHash(kvPair[1])))
 	}
+	return nil
 }
 
 // pathExist used for judging the file or path exist or not when InitGenesis
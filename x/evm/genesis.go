@@ -17,9 +17,6 @@ import (
 func InitGenesis(ctx sdk.Context, k Keeper, accountKeeper types.AccountKeeper, data GenesisState) []abci.ValidatorUpdate { // nolint: interfacer
 	logger := ctx.Logger().With("module", types.ModuleName)
 
-	k.SetParams(ctx, data.Params)
-
-	csdb := types.CreateEmptyCommitStateDB(k.GenerateCSDBParams(), ctx)
 	mode := viper.GetString(server.FlagEvmImportMode)
 	if mode == "" {
 		// for some UT
@@ -27,6 +24,17 @@ func InitGenesis(ctx sdk.Context, k Keeper, accountKeeper types.AccountKeeper, d
 	}
 	initImportEnv(viper.GetString(server.FlagEvmImportPath), mode, viper.GetUint64(server.FlagGoroutineNum))
 
+	// report all the problems of the genesis and import files before writing any state
+	manifest, err := validateImport(data, mode)
+	if err != nil {
+		panic(err)
+	}
+
+	k.SetParams(ctx, data.Params)
+
+	csdb := types.CreateEmptyCommitStateDB(k.GenerateCSDBParams(), ctx)
+
+	var imports []accountData
 	for _, account := range data.Accounts {
 		address := ethcmn.HexToAddress(account.Address)
 		accAddress := sdk.AccAddress(address.Bytes())
@@ -56,22 +64,21 @@ func InitGenesis(ctx sdk.Context, k Keeper, accountKeeper types.AccountKeeper, d
 				csdb.SetCode(address, account.Code)
 				codeCount++
 			}
-			for _, storage := range account.Storage {
-				k.SetStateDirectly(ctx, address, storage.Key, storage.Value)
-				storageCount++
-			}
-		case filesMode:
-			importFromFile(ctx, logger, k, address, ethAcc.CodeHash)
-		case dbMode:
-			importFromDB(ctx, k, address, ethAcc.CodeHash)
+			k.SetStorageDirectly(ctx, address, account.Storage)
+			storageCount += uint64(len(account.Storage))
+		case filesMode, dbMode:
+			imports = append(imports, accountData{address: address, codeHash: ethAcc.CodeHash})
 		default:
 			panic("unsupported import mode")
 		}
 	}
 
-	// wait for all data to be imported from files
-	if mode == filesMode {
-		wg.Wait()
+	// import the code and storage from files or db
+	if len(imports) > 0 {
+		importAccounts(ctx, logger, k, mode, imports)
+	}
+	if err := manifest.checkImported(codeCount, storageCount); err != nil {
+		panic(err)
 	}
 
 	// set contract deployment whitelist into store
@@ -86,7 +93,7 @@ func InitGenesis(ctx sdk.Context, k Keeper, accountKeeper types.AccountKeeper, d
 	logger.Debug("Import finished", "code", codeCount, "storage", storageCount)
 
 	// set state objects and code to store
-	_, err := csdb.Commit(false)
+	_, err = csdb.Commit(false)
 	if err != nil {
 		panic(err)
 	}
@@ -100,6 +107,20 @@ func InitGenesis(ctx sdk.Context, k Keeper, accountKeeper types.AccountKeeper, d
 
 	k.SetChainConfig(ctx, data.ChainConfig)
 
+	// keep the BLOCKHASH of the blocks sealed before the export
+	for _, bh := range data.RecentBlockHashes {
+		k.SetHeightHash(ctx, bh.Height, bh.Hash)
+		k.SetRecentBlockHash(ctx, bh.Height, bh.Hash)
+	}
+
+	for _, allowance := range data.FeeAllowances {
+		k.SetFeeAllowance(ctx, allowance)
+	}
+
+	for _, token := range data.TokenRegistry {
+		k.SetTokenMetadata(ctx, token)
+	}
+
 	return []abci.ValidatorUpdate{}
 }
 
@@ -114,6 +135,11 @@ func ExportGenesis(ctx sdk.Context, k Keeper, ak types.AccountKeeper) GenesisSta
 	}
 	initExportEnv(viper.GetString(server.FlagEvmExportPath), mode, viper.GetUint64(server.FlagGoroutineNum))
 
+	filter, err := newExportFilter(ctx, k, viper.GetStringSlice(server.FlagEvmExportAddresses), viper.GetInt64(server.FlagEvmExportSinceHeight))
+	if err != nil {
+		panic(err)
+	}
+
 	// nolint: prealloc
 	var ethGenAccounts []types.GenesisAccount
 	csdb := types.CreateEmptyCommitStateDB(k.GenerateCSDBParams(), ctx)
@@ -126,6 +152,9 @@ func ExportGenesis(ctx sdk.Context, k Keeper, ak types.AccountKeeper) GenesisSta
 		}
 
 		addr := ethAccount.EthAddress()
+		if !filter.contains(addr) {
+			return false
+		}
 		code, storage := []byte(nil), types.Storage(nil)
 		var err error
 
@@ -157,9 +186,12 @@ func ExportGenesis(ctx sdk.Context, k Keeper, ak types.AccountKeeper) GenesisSta
 		ethGenAccounts = append(ethGenAccounts, genAccount)
 		return false
 	})
-	// wait for all data to be written into files or db
+	// wait for all data to be written into files or db, then describe them in the manifest
 	if mode == filesMode || mode == dbMode {
 		wg.Wait()
+		if err := writeExportManifest(evmDataPath, newExportManifest(mode, ctx.BlockHeight(), len(ethGenAccounts))); err != nil {
+			panic(err)
+		}
 	}
 	logger.Debug("Export finished", "code", codeCount, "storage", storageCount)
 
@@ -177,5 +209,8 @@ func ExportGenesis(ctx sdk.Context, k Keeper, ak types.AccountKeeper) GenesisSta
 		ContractDeploymentWhitelist: csdb.GetContractDeploymentWhitelist(),
 		ContractBlockedList:         csdb.GetContractBlockedList(),
 		ContractMethodBlockedList:   bcml,
+		RecentBlockHashes:           k.GetRecentBlockHashes(ctx),
+		FeeAllowances:               k.GetFeeAllowances(ctx),
+		TokenRegistry:               k.GetTokenRegistry(ctx),
 	}
 }
@@ -1,6 +1,11 @@
 package evm_test
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
 	"os"
 	"path/filepath"
 	"strings"
@@ -35,6 +40,70 @@ func (suite *EvmTestSuite) TestExportImport() {
 	_ = evm.InitGenesis(suite.ctx, *suite.app.EvmKeeper, &suite.app.AccountKeeper, genState)
 }
 
+func (suite *EvmTestSuite) TestExportImport_recentBlockHashes() {
+	hash := func(height uint64) ethcmn.Hash {
+		return ethcmn.BytesToHash([]byte(fmt.Sprintf("hash %d", height)))
+	}
+	ctx := suite.ctx.WithBlockHeight(300)
+	// sealed before the window was enabled, and out of the window
+	suite.app.EvmKeeper.SetHeightHash(ctx, 44, hash(44))
+	suite.app.EvmKeeper.SetHeightHash(ctx, 45, hash(45))
+	suite.app.EvmKeeper.SetRecentBlockHash(ctx, 299, hash(299))
+
+	genState := evm.ExportGenesis(ctx, *suite.app.EvmKeeper, &suite.app.AccountKeeper)
+	suite.Require().Equal([]types.RecentBlockHash{{Height: 45, Hash: hash(45)}, {Height: 299, Hash: hash(299)}},
+		genState.RecentBlockHashes)
+	suite.Require().NoError(genState.Validate())
+
+	// the imported chain continues from the exported height
+	suite.SetupTest()
+	ctx = suite.ctx.WithBlockHeight(301)
+	_ = evm.InitGenesis(ctx, *suite.app.EvmKeeper, &suite.app.AccountKeeper, genState)
+	params := suite.app.EvmKeeper.GetParams(ctx)
+	params.EnableBlockHashWindow = true
+	suite.app.EvmKeeper.SetParams(ctx, params)
+
+	csdb := types.CreateEmptyCommitStateDB(suite.app.EvmKeeper.GenerateCSDBParams(), ctx)
+	getHash := types.GetHashFn(ctx, csdb)
+	suite.Require().Equal(hash(45), getHash(45))
+	suite.Require().Equal(hash(299), getHash(299))
+	suite.Require().Equal(hash(299), suite.app.EvmKeeper.GetHeightHash(ctx, 299))
+}
+
+func (suite *EvmTestSuite) TestExportImport_legacyChainConfig() {
+	// a genesis exported before the berlin and london switches existed
+	var genesis map[string]json.RawMessage
+	suite.Require().NoError(json.Unmarshal(types.ModuleCdc.MustMarshalJSON(types.DefaultGenesisState()), &genesis))
+	var config map[string]json.RawMessage
+	suite.Require().NoError(json.Unmarshal(genesis["chain_config"], &config))
+	delete(config, "berlin_block")
+	delete(config, "london_block")
+	delete(config, "shanghai_block")
+	genesis["chain_config"], _ = json.Marshal(config)
+	legacyGenesis, err := json.Marshal(genesis)
+	suite.Require().NoError(err)
+
+	var genState types.GenesisState
+	types.ModuleCdc.MustUnmarshalJSON(legacyGenesis, &genState)
+	suite.Require().NoError(genState.Validate())
+	_ = evm.InitGenesis(suite.ctx, *suite.app.EvmKeeper, &suite.app.AccountKeeper, genState)
+
+	// export and import it again, the forks must stay disabled
+	exported := types.ModuleCdc.MustMarshalJSON(evm.ExportGenesis(suite.ctx, *suite.app.EvmKeeper, &suite.app.AccountKeeper))
+	genState = types.GenesisState{}
+	types.ModuleCdc.MustUnmarshalJSON(exported, &genState)
+	suite.Require().Equal(sdk.NewInt(-1), genState.ChainConfig.BerlinBlock)
+	suite.Require().Equal(sdk.NewInt(-1), genState.ChainConfig.LondonBlock)
+	suite.Require().Equal(sdk.NewInt(-1), genState.ChainConfig.ShanghaiBlock)
+	_ = evm.InitGenesis(suite.ctx, *suite.app.EvmKeeper, &suite.app.AccountKeeper, genState)
+
+	chainConfig, found := suite.app.EvmKeeper.GetChainConfig(suite.ctx)
+	suite.Require().True(found)
+	ethConfig := chainConfig.EthereumConfig(big.NewInt(1))
+	suite.Require().Nil(ethConfig.BerlinBlock)
+	suite.Require().Nil(ethConfig.LondonBlock)
+}
+
 func (suite *EvmTestSuite) TestInitGenesis() {
 	privkey, err := ethsecp256k1.GenerateKey()
 	suite.Require().NoError(err)
@@ -608,6 +677,62 @@ func (suite *EvmTestSuite) TestExport_files() {
 	testImport_files(suite, exportState, tmpPath, ethAccount, code, storage, expectedAddrList)
 }
 
+func (suite *EvmTestSuite) TestInitGenesis_invalidFiles() {
+	viper.SetEnvPrefix("OKEXCHAIN")
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	viper.AutomaticEnv()
+
+	privkey, err := ethsecp256k1.GenerateKey()
+	suite.Require().NoError(err)
+	address := ethcmn.HexToAddress(privkey.PubKey().Address().String())
+	orphan := ethcmn.BytesToAddress([]byte("orphan"))
+
+	tmpPath := "./test_tmp_db"
+	suite.Require().NoError(os.MkdirAll(filepath.Join(tmpPath, "code"), 0777))
+	suite.Require().NoError(os.MkdirAll(filepath.Join(tmpPath, "storage"), 0777))
+	os.Setenv("OKEXCHAIN_EVM_IMPORT_MODE", "files")
+	os.Setenv("OKEXCHAIN_EVM_IMPORT_PATH", tmpPath)
+	defer func() {
+		os.Setenv("OKEXCHAIN_EVM_IMPORT_MODE", "default")
+		os.RemoveAll(tmpPath)
+	}()
+
+	key := ethcmn.BytesToHash([]byte("key")).Hex()
+	codeFile := filepath.Join(tmpPath, "code", address.String()+".code")
+	storageFile := filepath.Join(tmpPath, "storage", address.String()+".storage")
+	orphanFile := filepath.Join(tmpPath, "storage", orphan.String()+".storage")
+	suite.Require().NoError(ioutil.WriteFile(codeFile, []byte("0xzz"), 0644))
+	storage := fmt.Sprintf("%s:%s\n0x01:%s\n%s:%s\n%s:%s", key, key, key, key, key, key, key)
+	suite.Require().NoError(ioutil.WriteFile(storageFile, []byte(storage), 0644))
+	suite.Require().NoError(ioutil.WriteFile(orphanFile, []byte(key+":"+key+"\n"), 0644))
+
+	genState := types.GenesisState{
+		Params: types.DefaultParams(),
+		Accounts: []types.GenesisAccount{
+			{Address: address.String()},
+			{Address: address.String()},
+		},
+	}
+
+	defer func() {
+		r := recover()
+		suite.Require().NotNil(r)
+		var problems *evm.GenesisProblems
+		suite.Require().True(errors.As(r.(error), &problems))
+		suite.Require().Equal([]evm.GenesisProblem{
+			{File: "genesis.json", Reason: fmt.Sprintf("accounts[1]: duplicate account %s", address.String())},
+			{File: codeFile, Line: 1, Reason: "invalid code hex: invalid hex string"},
+			{File: orphanFile, Reason: fmt.Sprintf("no genesis account %s", orphan.String())},
+			{File: storageFile, Line: 2, Reason: `invalid key "0x01": 1 bytes instead of 32`},
+			{File: storageFile, Line: 3, Reason: fmt.Sprintf("duplicate key %s of line 1", key)},
+			{File: storageFile, Line: 4, Reason: "line not ended by a newline"},
+		}, problems.Problems)
+		// the files are validated in name order, nothing is written before the validation
+		suite.Require().Nil(suite.app.EvmKeeper.GetCode(suite.ctx, address))
+	}()
+	evm.InitGenesis(suite.ctx, *suite.app.EvmKeeper, &suite.app.AccountKeeper, genState)
+}
+
 func (suite *EvmTestSuite) TestExport_files1() {
 	viper.SetEnvPrefix("OKEXCHAIN")
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
@@ -808,3 +933,216 @@ func testImport_files(suite *EvmTestSuite,
 		suite.Require().Equal(expectedAddrList, suite.stateDB.GetContractBlockedList())
 	})
 }
+
+func (suite *EvmTestSuite) TestImport_filesBatched() {
+	viper.SetEnvPrefix("OKEXCHAIN")
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	viper.AutomaticEnv()
+
+	// more accounts than goroutines, the reads run ahead of the writes by at most 2 accounts
+	var ethAccounts []ethermint.EthAccount
+	var genAccounts []types.GenesisAccount
+	for i := 0; i < 5; i++ {
+		privkey, err := ethsecp256k1.GenerateKey()
+		suite.Require().NoError(err)
+		address := ethcmn.HexToAddress(privkey.PubKey().Address().String())
+
+		code := []byte{1, 2, byte(i)}
+		ethAccount := ethermint.EthAccount{
+			BaseAccount: &auth.BaseAccount{Address: address.Bytes()},
+			CodeHash:    ethcrypto.Keccak256(code),
+		}
+		suite.app.AccountKeeper.SetAccount(suite.ctx, ethAccount)
+		ethAccounts = append(ethAccounts, ethAccount)
+
+		var storage types.Storage
+		for j := 0; j <= i; j++ {
+			key := common.BytesToHash([]byte(fmt.Sprintf("key%d", j)))
+			storage = append(storage, types.NewState(key, common.BytesToHash([]byte(fmt.Sprintf("value%d%d", i, j)))))
+		}
+		genAccounts = append(genAccounts, types.GenesisAccount{Address: address.String(), Code: code, Storage: storage})
+	}
+	os.Setenv("OKEXCHAIN_EVM_IMPORT_MODE", "default")
+	evm.InitGenesis(suite.ctx, *suite.app.EvmKeeper, &suite.app.AccountKeeper,
+		types.GenesisState{Params: types.DefaultParams(), Accounts: genAccounts})
+
+	tmpPath := "./test_tmp_db"
+	os.Setenv("OKEXCHAIN_EVM_EXPORT_MODE", "files")
+	os.Setenv("OKEXCHAIN_EVM_EXPORT_PATH", tmpPath)
+	os.Setenv("OKEXCHAIN_GOROUTINE_NUM", "2")
+	defer func() {
+		os.Setenv("OKEXCHAIN_EVM_IMPORT_MODE", "default")
+		os.Setenv("OKEXCHAIN_EVM_EXPORT_MODE", "default")
+		os.Unsetenv("OKEXCHAIN_GOROUTINE_NUM")
+		os.RemoveAll(tmpPath)
+	}()
+	exportState := evm.ExportGenesis(suite.ctx, *suite.app.EvmKeeper, &suite.app.AccountKeeper)
+
+	suite.SetupTest() // reset
+	for _, ethAccount := range ethAccounts {
+		suite.app.AccountKeeper.SetAccount(suite.ctx, ethAccount)
+	}
+	os.Setenv("OKEXCHAIN_EVM_IMPORT_MODE", "files")
+	os.Setenv("OKEXCHAIN_EVM_IMPORT_PATH", tmpPath)
+	suite.Require().NotPanics(func() {
+		evm.InitGenesis(suite.ctx, *suite.app.EvmKeeper, &suite.app.AccountKeeper, exportState)
+	})
+
+	for i, genAccount := range genAccounts {
+		address := ethAccounts[i].EthAddress()
+		suite.Require().Equal([]byte(genAccount.Code), suite.app.EvmKeeper.GetCode(suite.ctx, address))
+		storage, err := suite.app.EvmKeeper.GetAccountStorage(suite.ctx, address)
+		suite.Require().NoError(err)
+		suite.Require().ElementsMatch(genAccount.Storage, storage)
+	}
+}
+
+func (suite *EvmTestSuite) TestExport_manifest() {
+	viper.SetEnvPrefix("OKEXCHAIN")
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	viper.AutomaticEnv()
+
+	privkey, err := ethsecp256k1.GenerateKey()
+	suite.Require().NoError(err)
+	address := ethcmn.HexToAddress(privkey.PubKey().Address().String())
+	code := []byte{1, 2, 3}
+	ethAccount := ethermint.EthAccount{
+		BaseAccount: &auth.BaseAccount{Address: address.Bytes()},
+		CodeHash:    ethcrypto.Keccak256(code),
+	}
+	suite.app.AccountKeeper.SetAccount(suite.ctx, ethAccount)
+	storage := types.Storage{
+		{Key: common.BytesToHash([]byte("key1")), Value: common.BytesToHash([]byte("value1"))},
+		{Key: common.BytesToHash([]byte("key2")), Value: common.BytesToHash([]byte("value2"))},
+	}
+	os.Setenv("OKEXCHAIN_EVM_IMPORT_MODE", "default")
+	evm.InitGenesis(suite.ctx, *suite.app.EvmKeeper, &suite.app.AccountKeeper, types.GenesisState{
+		Params:   types.DefaultParams(),
+		Accounts: []types.GenesisAccount{{Address: address.String(), Code: code, Storage: storage}},
+	})
+
+	tmpPath := "./test_tmp_db"
+	manifestFile := filepath.Join(tmpPath, "evm_manifest.json")
+	os.Setenv("OKEXCHAIN_EVM_EXPORT_MODE", "files")
+	os.Setenv("OKEXCHAIN_EVM_EXPORT_PATH", tmpPath)
+	defer func() {
+		os.Setenv("OKEXCHAIN_EVM_IMPORT_MODE", "default")
+		os.Setenv("OKEXCHAIN_EVM_EXPORT_MODE", "default")
+		os.RemoveAll(tmpPath)
+	}()
+	exportState := evm.ExportGenesis(suite.ctx, *suite.app.EvmKeeper, &suite.app.AccountKeeper)
+
+	bz, err := ioutil.ReadFile(manifestFile)
+	suite.Require().NoError(err)
+	manifest := make(map[string]interface{})
+	suite.Require().NoError(json.Unmarshal(bz, &manifest))
+	suite.Require().Equal(float64(1), manifest["version"])
+	suite.Require().Equal("files", manifest["mode"])
+	suite.Require().Equal(float64(1), manifest["accounts"])
+	suite.Require().Equal(float64(1), manifest["code"])
+	suite.Require().Equal(float64(2), manifest["storage"])
+
+	importWithManifest := func(manifest map[string]interface{}) {
+		os.Setenv("OKEXCHAIN_EVM_IMPORT_MODE", "default")
+		suite.SetupTest() // reset
+		suite.app.AccountKeeper.SetAccount(suite.ctx, ethAccount)
+		if manifest == nil {
+			suite.Require().NoError(os.Remove(manifestFile))
+		} else {
+			bz, err := json.Marshal(manifest)
+			suite.Require().NoError(err)
+			suite.Require().NoError(ioutil.WriteFile(manifestFile, bz, 0644))
+		}
+		os.Setenv("OKEXCHAIN_EVM_IMPORT_MODE", "files")
+		os.Setenv("OKEXCHAIN_EVM_IMPORT_PATH", tmpPath)
+		evm.InitGenesis(suite.ctx, *suite.app.EvmKeeper, &suite.app.AccountKeeper, exportState)
+	}
+
+	// the manifest must match the genesis and the imported code and storage
+	for _, tc := range []struct {
+		key   string
+		value interface{}
+	}{
+		{"version", 2},
+		{"mode", "db"},
+		{"accounts", 2},
+		{"storage", 3},
+	} {
+		tampered := make(map[string]interface{})
+		for k, v := range manifest {
+			tampered[k] = v
+		}
+		tampered[tc.key] = tc.value
+		suite.Require().Panics(func() { importWithManifest(tampered) }, tc.key)
+	}
+
+	suite.Require().NotPanics(func() { importWithManifest(manifest) })
+	// the exports of the releases before the manifest are imported as legacy exports
+	suite.Require().NotPanics(func() { importWithManifest(nil) })
+	suite.Require().Equal(code, suite.app.EvmKeeper.GetCode(suite.ctx, address))
+	imported, err := suite.app.EvmKeeper.GetAccountStorage(suite.ctx, address)
+	suite.Require().NoError(err)
+	suite.Require().ElementsMatch(storage, imported)
+}
+
+func (suite *EvmTestSuite) TestExport_filter() {
+	viper.SetEnvPrefix("OKEXCHAIN")
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	viper.AutomaticEnv()
+
+	var addresses []ethcmn.Address
+	var accounts []types.GenesisAccount
+	for i := 0; i < 3; i++ {
+		privkey, err := ethsecp256k1.GenerateKey()
+		suite.Require().NoError(err)
+		address := ethcmn.HexToAddress(privkey.PubKey().Address().String())
+		code := []byte{byte(i + 1)}
+		suite.app.AccountKeeper.SetAccount(suite.ctx, ethermint.EthAccount{
+			BaseAccount: &auth.BaseAccount{Address: address.Bytes()},
+			CodeHash:    ethcrypto.Keccak256(code),
+		})
+		addresses = append(addresses, address)
+		accounts = append(accounts, types.GenesisAccount{Address: address.String(), Code: code})
+	}
+	os.Setenv("OKEXCHAIN_EVM_IMPORT_MODE", "default")
+	evm.InitGenesis(suite.ctx, *suite.app.EvmKeeper, &suite.app.AccountKeeper, types.GenesisState{
+		Params:   types.DefaultParams(),
+		Accounts: accounts,
+	})
+
+	// the third account emitted a log in the block of the second height
+	suite.ctx = suite.ctx.WithBlockHeight(3)
+	suite.app.EvmKeeper.SetBlockBloom(suite.ctx, 2, ethtypes.BytesToBloom(ethtypes.LogsBloom([]*ethtypes.Log{{Address: addresses[2]}})))
+	defer func() {
+		os.Unsetenv("OKEXCHAIN_EVM_EXPORT_ADDRESSES")
+		os.Unsetenv("OKEXCHAIN_EVM_EXPORT_SINCE_HEIGHT")
+	}()
+
+	exported := func() []string {
+		var exported []string
+		for _, account := range evm.ExportGenesis(suite.ctx, *suite.app.EvmKeeper, &suite.app.AccountKeeper).Accounts {
+			exported = append(exported, account.Address)
+		}
+		return exported
+	}
+
+	suite.Require().Len(exported(), 3)
+
+	os.Setenv("OKEXCHAIN_EVM_EXPORT_ADDRESSES", addresses[0].String()+","+addresses[1].String())
+	suite.Require().ElementsMatch([]string{addresses[0].String(), addresses[1].String()}, exported())
+
+	os.Setenv("OKEXCHAIN_EVM_EXPORT_SINCE_HEIGHT", "2")
+	suite.Require().ElementsMatch([]string{addresses[0].String(), addresses[1].String(), addresses[2].String()}, exported())
+
+	os.Unsetenv("OKEXCHAIN_EVM_EXPORT_ADDRESSES")
+	suite.Require().Equal([]string{addresses[2].String()}, exported())
+	os.Setenv("OKEXCHAIN_EVM_EXPORT_SINCE_HEIGHT", "3")
+	suite.Require().Empty(exported())
+
+	// invalid filters
+	os.Setenv("OKEXCHAIN_EVM_EXPORT_SINCE_HEIGHT", "4")
+	suite.Require().Panics(func() { exported() })
+	os.Setenv("OKEXCHAIN_EVM_EXPORT_SINCE_HEIGHT", "0")
+	os.Setenv("OKEXCHAIN_EVM_EXPORT_ADDRESSES", "0x01")
+	suite.Require().Panics(func() { exported() })
+}
@@ -0,0 +1,256 @@
+package evm
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/okex/exchain/x/evm/types"
+)
+
+const (
+	genesisFileName = "genesis.json"
+
+	// maxGenesisProblems bounds the problems reported, a broken export may have one on every storage line
+	maxGenesisProblems = 1000
+)
+
+// GenesisProblem is a problem found by the validation of the evm genesis before the import. Line is 0 when the
+// problem is not located on a line of File.
+type GenesisProblem struct {
+	File   string
+	Line   int
+	Reason string
+}
+
+func (p GenesisProblem) String() string {
+	if p.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", p.File, p.Line, p.Reason)
+	}
+	return fmt.Sprintf("%s: %s", p.File, p.Reason)
+}
+
+// GenesisProblems is the error of the validation of the evm genesis, reporting every problem found
+type GenesisProblems struct {
+	Problems []GenesisProblem
+	// Omitted is the number of problems found beyond maxGenesisProblems
+	Omitted int
+}
+
+func (ps *GenesisProblems) add(file string, line int, format string, args ...interface{}) {
+	if len(ps.Problems) >= maxGenesisProblems {
+		ps.Omitted++
+		return
+	}
+	ps.Problems = append(ps.Problems, GenesisProblem{File: file, Line: line, Reason: fmt.Sprintf(format, args...)})
+}
+
+func (ps *GenesisProblems) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "invalid evm genesis, %d problems found:", len(ps.Problems)+ps.Omitted)
+	for _, p := range ps.Problems {
+		sb.WriteString("\n  ")
+		sb.WriteString(p.String())
+	}
+	if ps.Omitted > 0 {
+		fmt.Fprintf(&sb, "\n  ... and %d more", ps.Omitted)
+	}
+	return sb.String()
+}
+
+// validateImport checks the genesis state, and the manifest and code and storage files or dbs of the import mode,
+// before any state is written. It must be called after initImportEnv, and returns the manifest of the export.
+func validateImport(data GenesisState, mode string) (manifest exportManifest, err error) {
+	problems := &GenesisProblems{}
+
+	addresses := validateGenesisAccounts(problems, data.Accounts)
+	validateGenesisTxsLogs(problems, data.TxsLogs)
+	if mode == filesMode || mode == dbMode {
+		if manifest, err = readExportManifest(evmDataPath); err == nil {
+			err = manifest.checkImport(mode, len(data.Accounts))
+		}
+		if err != nil {
+			problems.add(filepath.Join(evmDataPath, exportManifestFile), 0, "%s", err)
+		}
+	}
+	switch mode {
+	case filesMode:
+		validateImportFiles(problems, addresses)
+	case dbMode:
+		validateImportDB(problems)
+	}
+
+	if len(problems.Problems) > 0 {
+		return manifest, problems
+	}
+	return manifest, nil
+}
+
+// validateGenesisAccounts checks the addresses and storage of the accounts, and returns the valid addresses
+func validateGenesisAccounts(problems *GenesisProblems, accounts []types.GenesisAccount) map[ethcmn.Address]bool {
+	addresses := make(map[ethcmn.Address]bool, len(accounts))
+	for i, account := range accounts {
+		if !ethcmn.IsHexAddress(account.Address) {
+			problems.add(genesisFileName, 0, "accounts[%d]: invalid address %q", i, account.Address)
+			continue
+		}
+		address := ethcmn.HexToAddress(account.Address)
+		if addresses[address] {
+			problems.add(genesisFileName, 0, "accounts[%d]: duplicate account %s", i, account.Address)
+			continue
+		}
+		addresses[address] = true
+
+		seen := make(map[ethcmn.Hash]bool, len(account.Storage))
+		for j, state := range account.Storage {
+			if seen[state.Key] {
+				problems.add(genesisFileName, 0, "accounts[%d].storage[%d]: duplicate key %s", i, j, state.Key.Hex())
+			} else if err := state.Validate(); err != nil {
+				problems.add(genesisFileName, 0, "accounts[%d].storage[%d]: %s", i, j, err)
+			}
+			seen[state.Key] = true
+		}
+	}
+	return addresses
+}
+
+func validateGenesisTxsLogs(problems *GenesisProblems, txsLogs []types.TransactionLogs) {
+	seen := make(map[ethcmn.Hash]bool, len(txsLogs))
+	for i, txLogs := range txsLogs {
+		if seen[txLogs.Hash] {
+			problems.add(genesisFileName, 0, "txs_logs[%d]: duplicate logs of transaction %s", i, txLogs.Hash.Hex())
+			continue
+		}
+		seen[txLogs.Hash] = true
+		if err := txLogs.Validate(); err != nil {
+			problems.add(genesisFileName, 0, "txs_logs[%d]: %s", i, err)
+		}
+	}
+}
+
+// validateImportFiles checks every code and storage file decodes, and belongs to a genesis account. The files
+// of other addresses would be silently skipped by the import.
+func validateImportFiles(problems *GenesisProblems, addresses map[ethcmn.Address]bool) {
+	forEachImportFile(problems, codePath, codeFileSuffix, addresses, validateCodeFile)
+	forEachImportFile(problems, storagePath, storageFileSuffix, addresses, validateStorageFile)
+}
+
+func forEachImportFile(problems *GenesisProblems, dir, suffix string, addresses map[ethcmn.Address]bool,
+	validate func(problems *GenesisProblems, file string)) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			problems.add(dir, 0, "failed to read the directory: %s", err)
+		}
+		return
+	}
+	for _, f := range files {
+		file := filepath.Join(dir, f.Name())
+		name := strings.TrimSuffix(f.Name(), suffix)
+		switch {
+		case f.IsDir() || name == f.Name():
+			problems.add(file, 0, "not a %s file", suffix)
+		case !ethcmn.IsHexAddress(name):
+			problems.add(file, 0, "file name is not an address")
+		case !addresses[ethcmn.HexToAddress(name)]:
+			problems.add(file, 0, "no genesis account %s", name)
+		default:
+			validate(problems, file)
+		}
+	}
+}
+
+func validateCodeFile(problems *GenesisProblems, file string) {
+	bin, err := ioutil.ReadFile(file)
+	if err != nil {
+		problems.add(file, 0, "failed to read the code: %s", err)
+		return
+	}
+	code, err := hexutil.Decode(string(bin))
+	if err != nil {
+		problems.add(file, 1, "invalid code hex: %s", err)
+		return
+	}
+	if len(code) == 0 {
+		problems.add(file, 1, "empty code")
+	}
+}
+
+// validateStorageFile checks every line of the file is a "key:value" pair of 32 bytes hex, ended by a newline
+func validateStorageFile(problems *GenesisProblems, file string) {
+	f, err := os.Open(file)
+	if err != nil {
+		problems.add(file, 0, "failed to read the storage: %s", err)
+		return
+	}
+	defer f.Close()
+
+	rd := bufio.NewReader(f)
+	seen := make(map[string]int)
+	for line := 1; ; line++ {
+		kvStr, err := rd.ReadString('\n')
+		if err != nil {
+			// the import stops at the first line without a newline
+			if kvStr != "" {
+				problems.add(file, line, "line not ended by a newline")
+			}
+			return
+		}
+
+		kvPair := strings.Split(strings.TrimSuffix(kvStr, "\n"), ":")
+		if len(kvPair) != 2 {
+			problems.add(file, line, "expected a key:value pair, got %q", strings.TrimSuffix(kvStr, "\n"))
+			continue
+		}
+		if err := validateStorageHash(kvPair[0]); err != nil {
+			problems.add(file, line, "invalid key %q: %s", kvPair[0], err)
+			continue
+		}
+		if err := validateStorageHash(kvPair[1]); err != nil {
+			problems.add(file, line, "invalid value %q: %s", kvPair[1], err)
+		}
+		key := strings.ToLower(kvPair[0])
+		if first, ok := seen[key]; ok {
+			problems.add(file, line, "duplicate key %s of line %d", kvPair[0], first)
+			continue
+		}
+		seen[key] = line
+	}
+}
+
+func validateStorageHash(s string) error {
+	b, err := hexutil.Decode(s)
+	if err != nil {
+		return err
+	}
+	if len(b) != ethcmn.HashLength {
+		return fmt.Errorf("%d bytes instead of %d", len(b), ethcmn.HashLength)
+	}
+	return nil
+}
+
+// validateImportDB checks the lengths of the storage keys and values of the evm state db
+func validateImportDB(problems *GenesisProblems) {
+	const file = "evm_state.db"
+	keyLen := len(types.AddressStoragePrefix(ethcmn.Address{})) + ethcmn.HashLength
+
+	iterator, err := evmStateDB.Iterator(nil, nil)
+	if err != nil {
+		problems.add(file, 0, "failed to iterate the storage: %s", err)
+		return
+	}
+	defer iterator.Close()
+	for ; iterator.Valid(); iterator.Next() {
+		if len(iterator.Key()) != keyLen {
+			problems.add(file, 0, "storage key %X: %d bytes instead of %d", iterator.Key(), len(iterator.Key()), keyLen)
+		} else if len(iterator.Value()) != ethcmn.HashLength {
+			problems.add(file, 0, "value of storage key %X: %d bytes instead of %d", iterator.Key(),
+				len(iterator.Value()), ethcmn.HashLength)
+		}
+	}
+}
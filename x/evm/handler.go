@@ -1,7 +1,12 @@
 package evm
 
 import (
+	"bytes"
+	"sort"
+	"strconv"
+
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/okex/exchain/app/refund"
 	ethermint "github.com/okex/exchain/app/types"
 	bam "github.com/okex/exchain/libs/cosmos-sdk/baseapp"
@@ -75,6 +80,21 @@ func NewHandler(k *Keeper) sdk.Handler {
 			handlerFun = func() (*sdk.Result, error) {
 				return handleMsgEthermint(ctx, k, msg)
 			}
+		case types.MsgGrantFeeAllowance:
+			name = "handleMsgGrantFeeAllowance"
+			handlerFun = func() (*sdk.Result, error) {
+				return handleMsgGrantFeeAllowance(ctx, k, msg)
+			}
+		case types.MsgRevokeFeeAllowance:
+			name = "handleMsgRevokeFeeAllowance"
+			handlerFun = func() (*sdk.Result, error) {
+				return handleMsgRevokeFeeAllowance(ctx, k, msg)
+			}
+		case types.MsgVoteGasPrice:
+			name = "handleMsgVoteGasPrice"
+			handlerFun = func() (*sdk.Result, error) {
+				return handleMsgVoteGasPrice(ctx, k, msg)
+			}
 		default:
 			return nil, sdkerrors.Wrapf(sdkerrors.ErrUnknownRequest, "unrecognized %s message type: %T", ModuleName, msg)
 		}
@@ -201,7 +221,33 @@ func handleMsgEthereumTx(ctx sdk.Context, k *Keeper, msg types.MsgEthereumTx) (*
 	}()
 
 	StartTxLog(bam.TransitionDb)
-	executionResult, resultData, err, innerTxs, erc20s := st.TransitionDb(ctx, config)
+	var executionResult *types.ExecutionResult
+	var resultData *types.ResultData
+	var innerTxs, erc20s interface{}
+	// the txs calling a contract are rejected once they could exceed the gas quota of the contract in the block,
+	// the quota isn't charged to the txs. The gas they consume is recorded by the gas refund, failed or not. Only
+	// the recipient of the tx is checked, not the contracts it calls.
+	if msg.Data.Recipient != nil {
+		err = k.CheckContractGasQuota(ctx.WithGasMeter(sdk.NewInfiniteGasMeter()), msg.Data.Recipient.Bytes(), msg.Data.GasLimit)
+	}
+	// the changes are captured along with the unhashed keys of the storage slots written by the tx
+	captureStateDiff := !st.Simulate && !ctx.IsAsync() && types.IsStateDiffFeedEnabled()
+	if captureStateDiff {
+		st.Csdb.TrackStateDiff()
+	}
+	if err == nil {
+		executionResult, resultData, err, innerTxs, erc20s = st.TransitionDb(ctx, config)
+	}
+	if captureStateDiff {
+		// the nonce of the sender is increased by the ante handler, and kept if the tx fails
+		diff := types.StateDiff{sender: {Nonce: &types.NonceDiff{
+			From: hexutil.Uint64(st.AccountNonce), To: hexutil.Uint64(st.AccountNonce + 1),
+		}}}
+		if err == nil {
+			diff.Merge(executionResult.StateDiff)
+		}
+		k.AddStateDiff(diff)
+	}
 	if ctx.IsAsync() {
 		k.LogsManages.Set(string(ctx.TxBytes()), keeper.TxResult{
 			ResultData: resultData,
@@ -234,11 +280,17 @@ func handleMsgEthereumTx(ctx sdk.Context, k *Keeper, msg types.MsgEthereumTx) (*
 		k.Watcher.SaveTransactionReceipt(watcher.TransactionSuccess, msg, common.BytesToHash(txHash), uint64(k.TxCount-1), resultData, ctx.GasMeter().GasConsumed())
 		if msg.Data.Recipient == nil {
 			st.Csdb.IteratorCode(func(addr common.Address, c types.CacheCode) bool {
-				k.Watcher.SaveContractCode(addr, c.Code)
+				k.Watcher.SaveContractCode(addr, c.CodeHash)
 				k.Watcher.SaveContractCodeByHash(c.CodeHash, c.Code)
 				return true
 			})
 		}
+		if types.IsContractRedeployAuditEnabled() {
+			auditContractRedeploy(ctx, k, st.Csdb, ethHash)
+		}
+		if types.IsPrefetchEnabled() && msg.Data.Recipient != nil {
+			types.RecordTouchedSlots(*msg.Data.Recipient, st.Csdb.TouchedSlots())
+		}
 	}
 
 	ctx.EventManager().EmitEvents(sdk.Events{
@@ -262,6 +314,9 @@ func handleMsgEthereumTx(ctx sdk.Context, k *Keeper, msg types.MsgEthereumTx) (*
 		)
 	}
 
+	// the state loaded by the tx is cached for the next txs of the block
+	st.Csdb.WriteStateCache()
+
 	// set the events to the result
 	executionResult.Result.Events = ctx.EventManager().Events()
 	StopTxLog(bam.TransitionDb)
@@ -355,7 +410,112 @@ func handleMsgEthermint(ctx sdk.Context, k *Keeper, msg types.MsgEthermint) (*sd
 		)
 	}
 
+	// the state loaded by the tx is cached for the next txs of the block
+	st.Csdb.WriteStateCache()
+
 	// set the events to the result
 	executionResult.Result.Events = ctx.EventManager().Events()
 	return executionResult.Result, nil
 }
+
+// handleMsgGrantFeeAllowance grants the grantee an allowance of the granter paying the gas fee of its evm txs.
+// The unexpired allowance of another granter can't be replaced, the grantee has a single sponsor at a time.
+func handleMsgGrantFeeAllowance(ctx sdk.Context, k *Keeper, msg types.MsgGrantFeeAllowance) (*sdk.Result, error) {
+	if allowance, found := k.GetFeeAllowance(ctx, msg.Grantee); found &&
+		!allowance.Granter.Equals(msg.Granter) && !allowance.IsExpired(ctx.BlockHeight()) {
+		return nil, sdkerrors.Wrapf(types.ErrFeeAllowanceExists, "grantee %s is sponsored by %s", msg.Grantee, allowance.Granter)
+	}
+
+	k.SetFeeAllowance(ctx, msg.FeeAllowance())
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			types.EventTypeGrantFeeAllowance,
+			sdk.NewAttribute(types.AttributeKeyGranter, msg.Granter.String()),
+			sdk.NewAttribute(types.AttributeKeyGrantee, msg.Grantee.String()),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Granter.String()),
+		),
+	})
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+// handleMsgRevokeFeeAllowance revokes the allowance of the granter paying the gas fee of the evm txs of the grantee
+func handleMsgRevokeFeeAllowance(ctx sdk.Context, k *Keeper, msg types.MsgRevokeFeeAllowance) (*sdk.Result, error) {
+	allowance, found := k.GetFeeAllowance(ctx, msg.Grantee)
+	if !found || !allowance.Granter.Equals(msg.Granter) {
+		return nil, sdkerrors.Wrapf(types.ErrFeeAllowanceNotFound, "grantee %s has no fee allowance of %s", msg.Grantee, msg.Granter)
+	}
+
+	k.DeleteFeeAllowance(ctx, msg.Grantee)
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			types.EventTypeRevokeFeeAllowance,
+			sdk.NewAttribute(types.AttributeKeyGranter, msg.Granter.String()),
+			sdk.NewAttribute(types.AttributeKeyGrantee, msg.Grantee.String()),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Granter.String()),
+		),
+	})
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+// handleMsgVoteGasPrice records the min gas price recommended by a bonded validator, it counts in the floor from
+// the end of the block
+func handleMsgVoteGasPrice(ctx sdk.Context, k *Keeper, msg types.MsgVoteGasPrice) (*sdk.Result, error) {
+	if err := k.VoteGasPrice(ctx, msg.Validator, msg.GasPrice); err != nil {
+		return nil, err
+	}
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			types.EventTypeVoteGasPrice,
+			sdk.NewAttribute(types.AttributeKeyValidator, msg.Validator.String()),
+			sdk.NewAttribute(types.AttributeKeyGasPrice, msg.GasPrice.String()),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, sdk.AccAddress(msg.Validator).String()),
+		),
+	})
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
+
+// auditContractRedeploy reports the contracts deployed by the tx at the address of a self-destructed contract,
+// then records the contracts self-destructed by the tx. It relies on the watcher to keep the history.
+func auditContractRedeploy(ctx sdk.Context, k *Keeper, csdb *types.CommitStateDB, txHash common.Hash) {
+	var deployed []common.Address
+	csdb.IteratorCode(func(addr common.Address, _ types.CacheCode) bool {
+		deployed = append(deployed, addr)
+		return true
+	})
+	sort.Slice(deployed, func(i, j int) bool {
+		return bytes.Compare(deployed[i].Bytes(), deployed[j].Bytes()) < 0
+	})
+
+	for _, addr := range deployed {
+		destructedHeight, redeployed := k.Watcher.AuditContractDeployment(addr, txHash)
+		if !redeployed {
+			continue
+		}
+		ctx.EventManager().EmitEvent(
+			sdk.NewEvent(
+				types.EventTypeContractRedeployed,
+				sdk.NewAttribute(types.AttributeKeyContractAddress, addr.String()),
+				sdk.NewAttribute(types.AttributeKeyDestructedHeight, strconv.FormatUint(destructedHeight, 10)),
+			),
+		)
+	}
+
+	for _, addr := range csdb.GetDestructedContracts() {
+		k.Watcher.SaveDestructedContract(addr)
+	}
+}
@@ -393,6 +393,96 @@ func (suite *EvmTestSuite) TestSendTransaction() {
 	suite.Require().EqualValues(expectedGas, suite.ctx.GasMeter().GasConsumed())
 }
 
+func (suite *EvmTestSuite) TestContractGasQuota() {
+	gasLimit := uint64(30000)
+	gasPrice := big.NewInt(10000)
+	recipient := ethcmn.Address{0x1}
+	// PUSH1 0 PUSH1 0 REVERT
+	reverting := ethcmn.Address{0x2}
+
+	priv, err := ethsecp256k1.GenerateKey()
+	suite.Require().NoError(err, "failed to create key")
+	pub := priv.ToECDSA().Public().(*ecdsa.PublicKey)
+
+	suite.app.EvmKeeper.SetBalance(suite.ctx, ethcrypto.PubkeyToAddress(*pub), big.NewInt(100))
+	suite.stateDB.CreateAccount(reverting)
+	suite.stateDB.SetCode(reverting, []byte{0x60, 0x00, 0x60, 0x00, 0xfd})
+	suite.Require().NoError(suite.stateDB.Finalise(true))
+	_, err = suite.stateDB.Commit(true)
+	suite.Require().NoError(err)
+	suite.app.EvmKeeper.SetContractGasQuota(suite.ctx, types.NewContractGasQuota(recipient.Bytes(), 50000))
+	suite.app.EvmKeeper.SetContractGasQuota(suite.ctx, types.NewContractGasQuota(reverting.Bytes(), 70000))
+
+	// fill the fee collector for the gas refunds
+	feeCollectorAcc := supply.NewEmptyModuleAccount(auth.FeeCollectorName)
+	feeCollectorAcc.Coins = sdk.NewCoins(sdk.NewCoin(sdk.DefaultBondDenom, sdk.OneDec()))
+	suite.app.SupplyKeeper.SetModuleAccount(suite.ctx, feeCollectorAcc)
+
+	// the tx is delivered like by the baseapp: the changes of a failed tx are discarded, while the gas refund
+	// runs on the state of the block whether the tx fails or not
+	nonce := uint64(0)
+	send := func(to ethcmn.Address) (uint64, error) {
+		tx := types.NewMsgEthereumTx(nonce, &to, big.NewInt(1), gasLimit, gasPrice, nil)
+		suite.Require().NoError(tx.Sign(big.NewInt(3), priv.ToECDSA()))
+		gasMeter := sdk.NewGasMeter(gasLimit)
+		txCtx, write := suite.ctx.WithGasMeter(gasMeter).CacheContext()
+		_, err := suite.handler(txCtx, tx)
+		if err == nil {
+			write()
+			nonce++
+		}
+		_, refundErr := suite.app.GasRefundHandler(suite.ctx.WithGasMeter(gasMeter), tx)
+		suite.Require().NoError(refundErr)
+		return gasMeter.GasConsumed(), err
+	}
+
+	// the gas consumed by the txs counts towards the quota of the block
+	gasUsed, err := send(recipient)
+	suite.Require().NoError(err)
+	suite.Require().True(gasUsed >= 21000)
+	suite.Require().Equal(gasUsed, suite.app.EvmKeeper.GetContractGasUsed(suite.ctx, recipient.Bytes()))
+
+	// a tx whose gas limit could exceed the quota is rejected
+	_, err = send(recipient)
+	suite.Require().Error(err)
+	suite.Require().Contains(err.Error(), types.ErrContractGasQuotaExceeded.Error())
+	suite.Require().True(suite.app.EvmKeeper.GetContractGasUsed(suite.ctx, recipient.Bytes()) >= gasUsed)
+
+	// the quota is renewed in the next block
+	suite.ctx = suite.ctx.WithBlockHeight(suite.ctx.BlockHeight() + 1)
+	suite.Require().Zero(suite.app.EvmKeeper.GetContractGasUsed(suite.ctx, recipient.Bytes()))
+	_, err = send(recipient)
+	suite.Require().NoError(err)
+
+	// the gas of the reverted calls counts towards the quota as well, until they exhaust it
+	var reverted uint64
+	for i := 0; i < 2; i++ {
+		gasUsed, err = send(reverting)
+		suite.Require().Error(err)
+		suite.Require().NotContains(err.Error(), types.ErrContractGasQuotaExceeded.Error())
+		reverted += gasUsed
+		suite.Require().Equal(reverted, suite.app.EvmKeeper.GetContractGasUsed(suite.ctx, reverting.Bytes()))
+	}
+	_, err = send(reverting)
+	suite.Require().Error(err)
+	suite.Require().Contains(err.Error(), types.ErrContractGasQuotaExceeded.Error())
+
+	// only the recipient of the tx is checked: a contract calling the exhausted one isn't rejected, and the gas of
+	// the internal call isn't charged to the quota
+	// CALL(GAS, reverting, 0, 0, 0, 0, 0) STOP
+	proxy := ethcmn.Address{0x3}
+	proxyCode := append([]byte{0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x73}, reverting.Bytes()...)
+	suite.stateDB.CreateAccount(proxy)
+	suite.stateDB.SetCode(proxy, append(proxyCode, 0x5a, 0xf1, 0x00))
+	suite.Require().NoError(suite.stateDB.Finalise(true))
+	_, err = suite.stateDB.Commit(true)
+	suite.Require().NoError(err)
+	reverted = suite.app.EvmKeeper.GetContractGasUsed(suite.ctx, reverting.Bytes())
+	_, err = send(proxy)
+	suite.Require().NoError(err)
+	suite.Require().Equal(reverted, suite.app.EvmKeeper.GetContractGasUsed(suite.ctx, reverting.Bytes()))
+}
+
 func (suite *EvmTestSuite) TestOutOfGasWhenDeployContract() {
 	// Test contract:
 	//http://remix.ethereum.org/#optimize=false&evmVersion=istanbul&version=soljson-v0.5.15+commit.6a57276f.js
@@ -627,6 +717,45 @@ func (suite *EvmTestSuite) TestDefaultMsgHandler() {
 	suite.Require().NotNil(sdkErr)
 }
 
+func (suite *EvmTestSuite) TestHandleMsgFeeAllowance() {
+	granter, grantee, other := ethcmn.BytesToAddress([]byte{0x1}), ethcmn.BytesToAddress([]byte{0x2}), ethcmn.BytesToAddress([]byte{0x3})
+	limit := sdk.NewDec(10)
+
+	// revoking a missing allowance fails
+	_, err := suite.handler(suite.ctx, types.NewMsgRevokeFeeAllowance(granter.Bytes(), grantee.Bytes()))
+	suite.Require().Error(err)
+
+	_, err = suite.handler(suite.ctx, types.NewMsgGrantFeeAllowance(granter.Bytes(), grantee.Bytes(), limit, 10))
+	suite.Require().NoError(err)
+	allowance, found := suite.app.EvmKeeper.GetFeeAllowance(suite.ctx, grantee.Bytes())
+	suite.Require().True(found)
+	suite.Require().Equal(types.NewFeeAllowance(granter.Bytes(), grantee.Bytes(), limit, 10), allowance)
+
+	// another granter can't replace the unexpired allowance, nor revoke it
+	_, err = suite.handler(suite.ctx, types.NewMsgGrantFeeAllowance(other.Bytes(), grantee.Bytes(), limit, 0))
+	suite.Require().Error(err)
+	_, err = suite.handler(suite.ctx, types.NewMsgRevokeFeeAllowance(other.Bytes(), grantee.Bytes()))
+	suite.Require().Error(err)
+
+	// the granter replaces its own allowance
+	_, err = suite.handler(suite.ctx, types.NewMsgGrantFeeAllowance(granter.Bytes(), grantee.Bytes(), sdk.ZeroDec(), 0))
+	suite.Require().NoError(err)
+	allowance, _ = suite.app.EvmKeeper.GetFeeAllowance(suite.ctx, grantee.Bytes())
+	suite.Require().True(allowance.SpendLimit.IsZero())
+
+	_, err = suite.handler(suite.ctx, types.NewMsgRevokeFeeAllowance(granter.Bytes(), grantee.Bytes()))
+	suite.Require().NoError(err)
+	_, found = suite.app.EvmKeeper.GetFeeAllowance(suite.ctx, grantee.Bytes())
+	suite.Require().False(found)
+
+	// the expired allowance of a granter is replaced by another one
+	_, err = suite.handler(suite.ctx, types.NewMsgGrantFeeAllowance(granter.Bytes(), grantee.Bytes(), limit, 1))
+	suite.Require().NoError(err)
+	_, err = suite.handler(suite.ctx.WithBlockHeight(2), types.NewMsgGrantFeeAllowance(other.Bytes(), grantee.Bytes(), limit, 0))
+	suite.Require().NoError(err)
+	suite.Require().Len(suite.app.EvmKeeper.GetFeeAllowances(suite.ctx), 1)
+}
+
 func (suite *EvmTestSuite) TestSimulateConflict() {
 
 	gasLimit := uint64(100000)
@@ -8,12 +8,14 @@ import (
 	tmtypes "github.com/okex/exchain/libs/tendermint/types"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 
 	abci "github.com/okex/exchain/libs/tendermint/abci/types"
 
 	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
 
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	ethermint "github.com/okex/exchain/app/types"
 	"github.com/okex/exchain/x/evm/types"
 )
 
@@ -33,6 +35,9 @@ func (k *Keeper) BeginBlock(ctx sdk.Context, req abci.RequestBeginBlock) {
 	height := req.Header.GetHeight() - 1
 
 	k.SetHeightHash(ctx, uint64(height), common.BytesToHash(lastHash))
+	if k.GetParams(ctx).EnableBlockHashWindow {
+		k.SetRecentBlockHash(ctx, uint64(height), common.BytesToHash(lastHash))
+	}
 	k.SetBlockHash(ctx, lastHash, height)
 	k.InitInnerBlock(common.BytesToHash(currentHash).Hex())
 
@@ -42,6 +47,13 @@ func (k *Keeper) BeginBlock(ctx sdk.Context, req abci.RequestBeginBlock) {
 	k.LogSize = 0
 	k.LogsManages = NewLogManager()
 	k.Bhash = common.BytesToHash(currentHash)
+	if k.stateCache != nil {
+		k.stateCache.Reset()
+	}
+	if types.IsStateDiffFeedEnabled() {
+		k.blockStateDiff = make(types.StateDiff)
+	}
+	types.PurgePrecompileCache()
 
 	//that can make sure latest block has been committed
 	k.Watcher.NewHeight(uint64(req.Header.GetHeight()), common.BytesToHash(currentHash), req.Header)
@@ -60,6 +72,8 @@ func (k Keeper) EndBlock(ctx sdk.Context, req abci.RequestEndBlock) []abci.Valid
 	bloom := ethtypes.BytesToBloom(k.Bloom.Bytes())
 	k.SetBlockBloom(ctx, req.Height, bloom)
 
+	k.UpdateMinGasPrice(ctx)
+
 	if types.GetEnableBloomFilter() {
 		// the hash of current block is stored when executing BeginBlock of next block.
 		// so update section in the next block.
@@ -104,11 +118,55 @@ func (k Keeper) EndBlock(ctx sdk.Context, req abci.RequestEndBlock) []abci.Valid
 		params := k.GetParams(ctx)
 		k.Watcher.SaveParams(params)
 
-		k.Watcher.SaveBlock(bloom)
+		// the evm end blocker is the last one, the balances are the ones of the end of the block
+		if watcher.IsBalanceHistoryEnabled() {
+			for _, addr := range k.Watcher.BalanceChangedAddrs() {
+				balance := new(big.Int)
+				if acc := k.accountKeeper.GetAccount(ctx, addr.Bytes()); acc != nil {
+					balance = ethermint.DecToWei(acc.GetCoins().AmountOf(sdk.DefaultBondDenom))
+				}
+				k.Watcher.SaveBalance(addr, balance)
+			}
+		}
+
+		k.Watcher.SaveBlock(bloom, params.BlockGasLimit(), k.GetBaseFee(ctx), k.GetCoinbase(ctx))
 		k.Watcher.Commit()
 	}
 
 	k.UpdateInnerBlockData()
 
+	if k.blockStateDiff != nil {
+		types.PublishStateDiff(&types.BlockStateDiff{
+			BlockNumber: hexutil.Uint64(req.Height),
+			BlockHash:   k.Bhash,
+			Accounts:    k.blockStateDiff,
+		})
+	}
+
+	if types.IsEvmProfilerEnabled() {
+		k.reportEvmProfile(types.CommitEvmProfile(req.Height))
+	}
+
+	hits, misses := types.PopSenderCacheStats()
+	k.evmMetrics.SigCacheHits.Add(float64(hits))
+	k.evmMetrics.SigCacheMisses.Add(float64(misses))
+	hits, misses = types.PopStateCacheStats()
+	k.evmMetrics.StateCacheHits.Add(float64(hits))
+	k.evmMetrics.StateCacheMisses.Add(float64(misses))
+	k.evmMetrics.PrefetchedSlots.Add(float64(types.PopPrefetchStats()))
+
 	return []abci.ValidatorUpdate{}
 }
+
+func (k Keeper) reportEvmProfile(profile types.BlockProfile) {
+	var gasUsed, calls uint64
+	for _, cp := range profile.Contracts {
+		gasUsed += cp.GasUsed
+		calls += cp.Calls
+	}
+	k.evmMetrics.ContractGasUsed.Add(float64(gasUsed))
+	k.evmMetrics.ContractCalls.Add(float64(calls))
+	for op, count := range profile.Opcodes {
+		k.evmMetrics.Opcodes.With("opcode", op).Add(float64(count))
+	}
+}
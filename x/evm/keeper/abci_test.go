@@ -5,6 +5,7 @@ import (
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 	"github.com/okex/exchain/app/crypto/ethsecp256k1"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
 	"github.com/okex/exchain/x/evm/types"
 	"github.com/okex/exchain/x/evm/watcher"
 	"github.com/spf13/viper"
@@ -48,6 +49,38 @@ func (suite *KeeperTestSuite) TestBeginBlock() {
 	suite.Require().Equal(int64(9), lastHeight)
 }
 
+func (suite *KeeperTestSuite) TestBeginBlock_recentBlockHash() {
+	beginBlock := func(height int64, hash []byte) {
+		suite.app.EvmKeeper.BeginBlock(suite.ctx, abci.RequestBeginBlock{
+			Header: abci.Header{LastBlockId: abci.BlockID{Hash: hash}, Height: height},
+		})
+	}
+
+	// the ring buffer is only written once the window is enabled
+	beginBlock(10, []byte("hash 9"))
+	_, found := suite.app.EvmKeeper.GetRecentBlockHash(suite.ctx, 9)
+	suite.Require().False(found)
+
+	params := suite.app.EvmKeeper.GetParams(suite.ctx)
+	params.EnableBlockHashWindow = true
+	suite.app.EvmKeeper.SetParams(suite.ctx, params)
+
+	beginBlock(11, []byte("hash 10"))
+	hash, found := suite.app.EvmKeeper.GetRecentBlockHash(suite.ctx, 10)
+	suite.Require().True(found)
+	suite.Require().Equal(ethcmn.BytesToHash([]byte("hash 10")), hash)
+
+	// the slot is reused BlockHashWindow heights later
+	beginBlock(11+types.BlockHashWindow, []byte("hash 266"))
+	_, found = suite.app.EvmKeeper.GetRecentBlockHash(suite.ctx, 10)
+	suite.Require().False(found)
+	hash, found = suite.app.EvmKeeper.GetRecentBlockHash(suite.ctx, 10+types.BlockHashWindow)
+	suite.Require().True(found)
+	suite.Require().Equal(ethcmn.BytesToHash([]byte("hash 266")), hash)
+	// the height -> hash index is kept for the json-rpc
+	suite.Require().Equal(ethcmn.BytesToHash([]byte("hash 10")), suite.app.EvmKeeper.GetHeightHash(suite.ctx, 10))
+}
+
 func (suite *KeeperTestSuite) TestEndBlock() {
 	// update the counters
 	suite.app.EvmKeeper.Bloom.SetInt64(10)
@@ -125,3 +158,58 @@ func (suite *KeeperTestSuite) TestResetCache() {
 	suite.Require().Zero(len(suite.stateDB.WithContext(suite.ctx).Preimages()))
 	suite.Require().Zero(suite.stateDB.GetRefund())
 }
+
+type validatorPowers map[string]int64
+
+func (p validatorPowers) GetFeeRecipientByConsAddr(sdk.Context, sdk.ConsAddress) (sdk.AccAddress, bool) {
+	return nil, false
+}
+
+func (p validatorPowers) GetLastValidatorPower(_ sdk.Context, operator sdk.ValAddress) int64 {
+	return p[operator.String()]
+}
+
+func (suite *KeeperTestSuite) TestEndBlock_minGasPrice() {
+	val1, val2, val3 := sdk.ValAddress("validator1"), sdk.ValAddress("validator2"), sdk.ValAddress("validator3")
+	suite.app.EvmKeeper.SetStakingKeeper(validatorPowers{val1.String(): 10, val2.String(): 5})
+	endBlock := func(height int64) {
+		suite.ctx = suite.ctx.WithBlockHeight(height)
+		suite.app.EvmKeeper.EndBlock(suite.ctx, abci.RequestEndBlock{Height: height})
+	}
+
+	// the votes are rejected while the oracle is disabled
+	suite.Require().Error(suite.app.EvmKeeper.VoteGasPrice(suite.ctx, val1, sdk.NewDec(8)))
+
+	params := types.DefaultParams()
+	params.GasPriceVoteWindow = 3
+	suite.app.EvmKeeper.SetParams(suite.ctx, params)
+
+	// only the bonded validators vote
+	suite.Require().Error(suite.app.EvmKeeper.VoteGasPrice(suite.ctx, val3, sdk.NewDec(8)))
+	suite.Require().NoError(suite.app.EvmKeeper.VoteGasPrice(suite.ctx, val1, sdk.NewDec(8)))
+	suite.Require().NoError(suite.app.EvmKeeper.VoteGasPrice(suite.ctx, val2, sdk.NewDec(1)))
+
+	// the first floor is the weighted median
+	endBlock(1)
+	suite.Require().Equal(sdk.NewDec(8), suite.app.EvmKeeper.GetMinGasPrice(suite.ctx))
+
+	// the floor moves by 12.5% per block at most
+	suite.ctx = suite.ctx.WithBlockHeight(2)
+	suite.Require().NoError(suite.app.EvmKeeper.VoteGasPrice(suite.ctx, val1, sdk.NewDec(100)))
+	endBlock(2)
+	suite.Require().Equal(sdk.NewDec(9), suite.app.EvmKeeper.GetMinGasPrice(suite.ctx))
+
+	// the expired votes are removed, the floor is kept without votes
+	endBlock(4)
+	suite.Require().Len(suite.app.EvmKeeper.GetGasPriceVotes(suite.ctx), 1)
+	suite.Require().Equal(sdk.NewDecWithPrec(10125, 3), suite.app.EvmKeeper.GetMinGasPrice(suite.ctx))
+	endBlock(5)
+	suite.Require().Empty(suite.app.EvmKeeper.GetGasPriceVotes(suite.ctx))
+	suite.Require().Equal(sdk.NewDecWithPrec(10125, 3), suite.app.EvmKeeper.GetMinGasPrice(suite.ctx))
+
+	// the floor is lifted once the oracle is disabled
+	params.GasPriceVoteWindow = 0
+	suite.app.EvmKeeper.SetParams(suite.ctx, params)
+	endBlock(7)
+	suite.Require().True(suite.app.EvmKeeper.GetMinGasPrice(suite.ctx).IsZero())
+}
@@ -0,0 +1,234 @@
+package keeper
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	ethermint "github.com/okex/exchain/app/types"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	sdkerrors "github.com/okex/exchain/libs/cosmos-sdk/types/errors"
+	tmtypes "github.com/okex/exchain/libs/tendermint/types"
+	"github.com/okex/exchain/x/evm/types"
+)
+
+// SimulateBundle executes the txs of the bundle in order on top of the state of the context, once the overrides
+// are applied. As with eth_call no gas fee is charged and the nonce of the calls isn't checked, but the nonce
+// of the raw txs must be the one of their sender. A failed tx is reported in its result and doesn't stop the
+// bundle. The state of the context is left unchanged.
+func (k Keeper) SimulateBundle(ctx sdk.Context, req types.SimulateBundleRequest) ([]types.BundleTxResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error())
+	}
+
+	chainID, err := ethermint.ParseChainID(ctx.ChainID())
+	if err != nil {
+		return nil, err
+	}
+	config, found := k.GetChainConfig(ctx)
+	if !found {
+		return nil, types.ErrChainConfigNotFound
+	}
+
+	ctx, _ = ctx.CacheContext()
+	ctx = applyBlockOverrides(ctx, req.BlockOverrides)
+	if err := k.applyStateOverrides(ctx, req.StateOverrides); err != nil {
+		return nil, err
+	}
+
+	bhash := types.CreateEmptyCommitStateDB(k.GeneratePureCSDBParams(), ctx).GetHeightHash(uint64(ctx.BlockHeight()))
+	results := make([]types.BundleTxResult, len(req.Txs))
+	var logSize uint
+	for i, tx := range req.Txs {
+		results[i], logSize = k.simulateBundleTx(ctx, config, chainID, bhash, i, logSize, tx)
+	}
+	return results, nil
+}
+
+func applyBlockOverrides(ctx sdk.Context, overrides *types.BlockOverrides) sdk.Context {
+	if overrides == nil {
+		return ctx
+	}
+	if overrides.Number != nil {
+		ctx = ctx.WithBlockHeight(overrides.Number.ToInt().Int64())
+	}
+	if overrides.Time != nil {
+		ctx = ctx.WithBlockTime(time.Unix(int64(*overrides.Time), 0).UTC())
+	}
+	if overrides.Coinbase != nil {
+		ctx = ctx.WithProposer(sdk.ConsAddress(overrides.Coinbase.Bytes()))
+	}
+	return ctx
+}
+
+func (k Keeper) applyStateOverrides(ctx sdk.Context, overrides map[ethcmn.Address]types.AccountOverride) error {
+	if len(overrides) == 0 {
+		return nil
+	}
+
+	csdb := types.CreateEmptyCommitStateDB(k.GenerateCSDBParams(), ctx)
+	for addr, override := range overrides {
+		if override.Balance != nil {
+			csdb.SetBalance(addr, override.Balance.ToInt())
+		}
+		if override.Nonce != nil {
+			csdb.SetNonce(addr, uint64(*override.Nonce))
+		}
+		if override.Code != nil {
+			csdb.SetCode(addr, *override.Code)
+		}
+		if override.State != nil {
+			csdb.SetStorage(addr, override.State)
+		}
+		for key, value := range override.StateDiff {
+			csdb.SetState(addr, key, value)
+		}
+	}
+
+	// the overridden accounts are kept even if they are empty
+	if err := csdb.Finalise(false); err != nil {
+		return err
+	}
+	_, err := csdb.Commit(false)
+	return err
+}
+
+// simulateBundleTx executes the i-th tx of the bundle and returns its result with the log index of the next tx
+func (k Keeper) simulateBundleTx(
+	ctx sdk.Context, config types.ChainConfig, chainID *big.Int, bhash ethcmn.Hash, i int, logSize uint, tx types.BundleTx,
+) (result types.BundleTxResult, nextLogSize uint) {
+	nextLogSize = logSize
+	st, err := k.bundleStateTransition(ctx, chainID, tx)
+	if st.TxHash != nil {
+		result.TxHash = *st.TxHash
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return
+	}
+
+	// the sender nonce is increased by the ante handler before the tx is executed, and kept if the tx fails
+	txCtx := ctx.WithGasMeter(sdk.NewGasMeter(st.GasLimit))
+	st.Csdb.Prepare(*st.TxHash, bhash, i)
+	st.Csdb.SetLogSize(logSize)
+	st.Csdb.TrackStateDiff()
+	st.Csdb.SetNonce(st.Sender, st.AccountNonce+1)
+
+	resData, err := transitionBundleTx(txCtx, config, st)
+	result.GasUsed = hexutil.Uint64(txCtx.GasMeter().GasConsumed())
+	if err != nil {
+		result.Error = err.Error()
+		csdb := types.CreateEmptyCommitStateDB(k.GenerateCSDBParams(), ctx)
+		csdb.SetNonce(st.Sender, st.AccountNonce+1)
+		result.StateDiff = csdb.StateDiff()
+		if err := csdb.Finalise(true); err != nil {
+			result.Error = err.Error()
+		}
+		return
+	}
+
+	result.ReturnData = resData.Ret
+	if st.Recipient == nil {
+		result.ContractAddress = &resData.ContractAddress
+	}
+	result.Logs, _ = st.Csdb.GetLogs(*st.TxHash)
+	result.StateDiff = st.Csdb.StateDiff()
+	nextLogSize = st.Csdb.GetLogSize()
+
+	if err := st.Csdb.Finalise(true); err != nil {
+		result.Error = err.Error()
+		return
+	}
+	if _, err := st.Csdb.Commit(true); err != nil {
+		result.Error = err.Error()
+	}
+	return
+}
+
+// bundleStateTransition returns the state transition of the raw tx or of the call
+func (k Keeper) bundleStateTransition(ctx sdk.Context, chainID *big.Int, tx types.BundleTx) (types.StateTransition, error) {
+	st := types.StateTransition{
+		ChainID:  chainID,
+		Csdb:     types.CreateEmptyCommitStateDB(k.GenerateCSDBParams(), ctx),
+		Simulate: true,
+	}
+
+	if len(tx.Raw) != 0 {
+		txHash := ethcmn.BytesToHash(tmtypes.Tx(tx.Raw).Hash())
+		st.TxHash = &txHash
+
+		var msg types.MsgEthereumTx
+		if err := rlp.DecodeBytes(tx.Raw, &msg); err != nil {
+			return st, err
+		}
+		sigCache, err := msg.VerifySig(chainID, ctx.BlockHeight(), nil)
+		if err != nil {
+			return st, err
+		}
+
+		st.Sender = sigCache.GetFrom()
+		if nonce := st.Csdb.GetNonce(st.Sender); nonce != msg.Data.AccountNonce {
+			return st, sdkerrors.Wrapf(sdkerrors.ErrInvalidSequence, "invalid nonce; got %d, expected %d",
+				msg.Data.AccountNonce, nonce)
+		}
+		st.AccountNonce = msg.Data.AccountNonce
+		st.Price = msg.Data.Price
+		st.GasLimit = msg.Data.GasLimit
+		st.Recipient = msg.Data.Recipient
+		st.Amount = msg.Data.Amount
+		st.Payload = msg.Data.Payload
+		return st, nil
+	}
+
+	// the calls are unsigned, their hash is the one of their arguments
+	bz, err := json.Marshal(tx)
+	if err != nil {
+		return st, err
+	}
+	txHash := crypto.Keccak256Hash(bz)
+	st.TxHash = &txHash
+
+	if tx.From != nil {
+		st.Sender = *tx.From
+	}
+	st.AccountNonce = st.Csdb.GetNonce(st.Sender)
+	st.Price = new(big.Int).SetUint64(ethermint.DefaultGasPrice)
+	if tx.GasPrice != nil {
+		st.Price = tx.GasPrice.ToInt()
+	}
+	// as with eth_call, the gas of the calls is capped
+	st.GasLimit = ethermint.DefaultRPCGasLimit
+	if tx.Gas != nil && uint64(*tx.Gas) < st.GasLimit {
+		st.GasLimit = uint64(*tx.Gas)
+	}
+	st.Recipient = tx.To
+	st.Amount = new(big.Int)
+	if tx.Value != nil {
+		st.Amount = tx.Value.ToInt()
+	}
+	if tx.Data != nil {
+		st.Payload = *tx.Data
+	}
+	return st, nil
+}
+
+// transitionBundleTx executes the state transition, running out of gas is reported as an error
+func transitionBundleTx(ctx sdk.Context, config types.ChainConfig, st types.StateTransition) (resData *types.ResultData, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			outOfGas, ok := r.(sdk.ErrorOutOfGas)
+			if !ok {
+				panic(r)
+			}
+			err = sdkerrors.Wrap(sdkerrors.ErrOutOfGas, fmt.Sprintf("out of gas in location: %v", outOfGas.Descriptor))
+		}
+	}()
+
+	_, resData, err, _, _ = st.TransitionDb(ctx, config)
+	return
+}
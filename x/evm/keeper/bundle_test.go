@@ -0,0 +1,94 @@
+package keeper_test
+
+import (
+	"encoding/json"
+	"math/big"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/okex/exchain/app/crypto/ethsecp256k1"
+	abci "github.com/okex/exchain/libs/tendermint/abci/types"
+	"github.com/okex/exchain/x/evm/types"
+)
+
+// bundleContract stores 42 in the slot 0 and emits an empty log
+var bundleContract = hexutil.Bytes(ethcmn.FromHex("602a60005560006000a000"))
+
+func (suite *KeeperTestSuite) TestSimulateBundle() {
+	params := types.DefaultParams()
+	params.EnableCall = true
+	suite.app.EvmKeeper.SetParams(suite.ctx, params)
+
+	priv, err := ethsecp256k1.GenerateKey()
+	suite.Require().NoError(err)
+	sender := ethcmn.BytesToAddress(priv.PubKey().Address().Bytes())
+	contract := ethcmn.BytesToAddress([]byte("contract"))
+	recipient := ethcmn.BytesToAddress([]byte("recipient"))
+
+	signed := types.NewMsgEthereumTx(1, &recipient, big.NewInt(10), 21000, big.NewInt(1), nil)
+	suite.Require().NoError(signed.Sign(big.NewInt(3), priv.ToECDSA()))
+	raw, err := rlp.EncodeToBytes(&signed)
+	suite.Require().NoError(err)
+
+	lowGas := hexutil.Uint64(1000)
+	balance := hexutil.Big(*big.NewInt(100))
+	number := hexutil.Big(*big.NewInt(10))
+	req := types.SimulateBundleRequest{
+		Txs: []types.BundleTx{
+			{From: &sender, To: &contract},
+			{Raw: raw},
+			{From: &sender, To: &contract, Gas: &lowGas},
+			{Raw: raw},
+		},
+		StateOverrides: map[ethcmn.Address]types.AccountOverride{
+			sender:   {Balance: &balance},
+			contract: {Code: &bundleContract},
+		},
+		BlockOverrides: &types.BlockOverrides{Number: &number},
+	}
+
+	ctx := suite.ctx.WithIsCheckTx(true)
+	results, err := suite.app.EvmKeeper.SimulateBundle(ctx, req)
+	suite.Require().NoError(err)
+	suite.Require().Len(results, 4)
+
+	// the call writes the storage and emits a log in the overridden block
+	suite.Require().Empty(results[0].Error)
+	suite.Require().Len(results[0].Logs, 1)
+	suite.Require().Equal(uint64(10), results[0].Logs[0].BlockNumber)
+	suite.Require().Equal(&types.StorageDiff{To: ethcmn.BigToHash(big.NewInt(42))},
+		results[0].StateDiff[contract].Storage[ethcmn.Hash{}])
+	suite.Require().Equal(&types.NonceDiff{From: 0, To: 1}, results[0].StateDiff[sender].Nonce)
+
+	// the raw tx sees the nonce increased by the call
+	suite.Require().Empty(results[1].Error)
+	suite.Require().Equal(hexutil.Uint64(21000), results[1].GasUsed)
+	suite.Require().Equal(&types.BalanceDiff{From: (*hexutil.Big)(big.NewInt(100)), To: (*hexutil.Big)(big.NewInt(90))},
+		results[1].StateDiff[sender].Balance)
+	suite.Require().Equal(&types.BalanceDiff{From: (*hexutil.Big)(big.NewInt(0)), To: (*hexutil.Big)(big.NewInt(10))},
+		results[1].StateDiff[recipient].Balance)
+
+	// a failed call only increases the nonce of its sender
+	suite.Require().NotEmpty(results[2].Error)
+	suite.Require().Equal(types.StateDiff{sender: {Nonce: &types.NonceDiff{From: 2, To: 3}}}, results[2].StateDiff)
+
+	// the raw tx can't be replayed
+	suite.Require().Contains(results[3].Error, "invalid nonce")
+
+	// the state of the context isn't changed by the simulation
+	suite.Require().Nil(suite.app.AccountKeeper.GetAccount(suite.ctx, sender.Bytes()))
+
+	bz, err := json.Marshal(req)
+	suite.Require().NoError(err)
+	res, err := suite.querier(ctx, []string{types.QuerySimulateBundle}, abci.RequestQuery{Data: bz})
+	suite.Require().NoError(err)
+	var queried []types.BundleTxResult
+	suite.Require().NoError(json.Unmarshal(res, &queried))
+	suite.Require().Len(queried, 4)
+	suite.Require().Equal(results[1].TxHash, queried[1].TxHash)
+	suite.Require().Equal(results[1].GasUsed, queried[1].GasUsed)
+
+	_, err = suite.app.EvmKeeper.SimulateBundle(ctx, types.SimulateBundleRequest{})
+	suite.Require().Error(err)
+}
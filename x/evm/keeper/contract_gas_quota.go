@@ -0,0 +1,99 @@
+package keeper
+
+import (
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	sdkerrors "github.com/okex/exchain/libs/cosmos-sdk/types/errors"
+	"github.com/okex/exchain/x/evm/types"
+)
+
+// ----------------------------------------------------------------------------
+// Contract gas quota functions
+// The gas per block quotas of the contracts set by the contract gas quota proposals, enforced by the evm handler
+// on the txs calling the contracts. Only the recipient of a tx is checked and charged, the calls made by other
+// contracts to a contract with a quota run and consume gas outside of its quota.
+// ----------------------------------------------------------------------------
+
+// GetContractGasQuota returns the gas per block quota of the contract
+func (k Keeper) GetContractGasQuota(ctx sdk.Context, contract sdk.AccAddress) (types.ContractGasQuota, bool) {
+	bz := ctx.KVStore(k.storeKey).Get(types.GetContractGasQuotaKey(contract))
+	if len(bz) == 0 {
+		return types.ContractGasQuota{}, false
+	}
+
+	var quota types.ContractGasQuota
+	k.cdc.MustUnmarshalBinaryLengthPrefixed(bz, &quota)
+	return quota, true
+}
+
+// SetContractGasQuota sets the gas per block quota of its contract
+func (k Keeper) SetContractGasQuota(ctx sdk.Context, quota types.ContractGasQuota) {
+	ctx.KVStore(k.storeKey).Set(types.GetContractGasQuotaKey(quota.Address), k.cdc.MustMarshalBinaryLengthPrefixed(quota))
+}
+
+// DeleteContractGasQuota removes the gas per block quota of the contract along with its gas usage
+func (k Keeper) DeleteContractGasQuota(ctx sdk.Context, contract sdk.AccAddress) {
+	store := ctx.KVStore(k.storeKey)
+	store.Delete(types.GetContractGasQuotaKey(contract))
+	store.Delete(types.GetContractGasUsageKey(contract))
+}
+
+// GetContractGasQuotas returns all the contract gas quotas, in the order of their address
+func (k Keeper) GetContractGasQuotas(ctx sdk.Context) []types.ContractGasQuota {
+	iterator := sdk.KVStorePrefixIterator(ctx.KVStore(k.storeKey), types.KeyPrefixContractGasQuota)
+	defer iterator.Close()
+
+	var quotas []types.ContractGasQuota
+	for ; iterator.Valid(); iterator.Next() {
+		var quota types.ContractGasQuota
+		k.cdc.MustUnmarshalBinaryLengthPrefixed(iterator.Value(), &quota)
+		quotas = append(quotas, quota)
+	}
+	return quotas
+}
+
+// GetContractGasUsed returns the gas consumed by the txs calling the contract in the current block. The usage
+// recorded in a previous block is stale and counts as zero, so that it doesn't need to be reset.
+func (k Keeper) GetContractGasUsed(ctx sdk.Context, contract sdk.AccAddress) uint64 {
+	bz := ctx.KVStore(k.storeKey).Get(types.GetContractGasUsageKey(contract))
+	if len(bz) == 0 {
+		return 0
+	}
+
+	var usage types.ContractGasUsage
+	k.cdc.MustUnmarshalBinaryLengthPrefixed(bz, &usage)
+	if usage.Height != ctx.BlockHeight() {
+		return 0
+	}
+	return usage.GasUsed
+}
+
+// AddContractGasUsed adds the gas consumed by a tx calling the contract to its usage of the current block, whether
+// the tx fails or not. Only the contracts with a quota are tracked.
+func (k Keeper) AddContractGasUsed(ctx sdk.Context, contract sdk.AccAddress, gasUsed uint64) {
+	if _, found := k.GetContractGasQuota(ctx, contract); !found {
+		return
+	}
+
+	usage := types.ContractGasUsage{
+		Height:  ctx.BlockHeight(),
+		GasUsed: k.GetContractGasUsed(ctx, contract) + gasUsed,
+	}
+	ctx.KVStore(k.storeKey).Set(types.GetContractGasUsageKey(contract), k.cdc.MustMarshalBinaryLengthPrefixed(usage))
+}
+
+// CheckContractGasQuota returns an error if a tx calling the contract with the gas limit could exceed the gas
+// quota of the contract in the current block. The tx is checked against its gas limit, the most it can consume,
+// so that the usage never exceeds the quota, while the usage only adds up the gas the txs actually consumed.
+func (k Keeper) CheckContractGasQuota(ctx sdk.Context, contract sdk.AccAddress, gasLimit uint64) error {
+	quota, found := k.GetContractGasQuota(ctx, contract)
+	if !found {
+		return nil
+	}
+
+	gasUsed := k.GetContractGasUsed(ctx, contract)
+	if gasUsed+gasLimit <= quota.GasPerBlock && gasUsed+gasLimit >= gasUsed {
+		return nil
+	}
+	return sdkerrors.Wrapf(types.ErrContractGasQuotaExceeded, "contract %s has used %d of its %d gas per block, tx gas limit %d",
+		contract, gasUsed, quota.GasPerBlock, gasLimit)
+}
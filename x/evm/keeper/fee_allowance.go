@@ -0,0 +1,89 @@
+package keeper
+
+import (
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	"github.com/okex/exchain/x/evm/types"
+)
+
+// ----------------------------------------------------------------------------
+// Fee allowance functions
+// Required by the ante handler to charge the gas fee of the evm txs to their sponsor.
+// ----------------------------------------------------------------------------
+
+// GetFeeAllowance returns the fee allowance granted to the grantee
+func (k Keeper) GetFeeAllowance(ctx sdk.Context, grantee sdk.AccAddress) (types.FeeAllowance, bool) {
+	bz := ctx.KVStore(k.storeKey).Get(types.GetFeeAllowanceKey(grantee))
+	if len(bz) == 0 {
+		return types.FeeAllowance{}, false
+	}
+
+	var allowance types.FeeAllowance
+	k.cdc.MustUnmarshalBinaryLengthPrefixed(bz, &allowance)
+	return allowance, true
+}
+
+// SetFeeAllowance sets the fee allowance of its grantee
+func (k Keeper) SetFeeAllowance(ctx sdk.Context, allowance types.FeeAllowance) {
+	ctx.KVStore(k.storeKey).Set(types.GetFeeAllowanceKey(allowance.Grantee), k.cdc.MustMarshalBinaryLengthPrefixed(allowance))
+}
+
+// DeleteFeeAllowance removes the fee allowance granted to the grantee
+func (k Keeper) DeleteFeeAllowance(ctx sdk.Context, grantee sdk.AccAddress) {
+	ctx.KVStore(k.storeKey).Delete(types.GetFeeAllowanceKey(grantee))
+}
+
+// GetFeeAllowances returns all the fee allowances
+func (k Keeper) GetFeeAllowances(ctx sdk.Context) []types.FeeAllowance {
+	iterator := sdk.KVStorePrefixIterator(ctx.KVStore(k.storeKey), types.KeyPrefixFeeAllowance)
+	defer iterator.Close()
+
+	var allowances []types.FeeAllowance
+	for ; iterator.Valid(); iterator.Next() {
+		var allowance types.FeeAllowance
+		k.cdc.MustUnmarshalBinaryLengthPrefixed(iterator.Value(), &allowance)
+		allowances = append(allowances, allowance)
+	}
+	return allowances
+}
+
+// GetTxFeeAllowance returns the fee allowance charged the gas fee of the evm tx being delivered for the sender, as
+// it was before the charge
+func (k Keeper) GetTxFeeAllowance(ctx sdk.Context, sender sdk.AccAddress) (types.FeeAllowance, bool) {
+	bz := ctx.KVStore(k.storeKey).Get(types.GetTxFeeAllowanceKey(sender))
+	if len(bz) == 0 {
+		return types.FeeAllowance{}, false
+	}
+
+	var allowance types.FeeAllowance
+	k.cdc.MustUnmarshalBinaryLengthPrefixed(bz, &allowance)
+	return allowance, true
+}
+
+// SetTxFeeAllowance records the fee allowance charged the gas fee of the evm tx being delivered for the sender,
+// so that the unused gas is refunded to its granter and to its spend limit
+func (k Keeper) SetTxFeeAllowance(ctx sdk.Context, sender sdk.AccAddress, allowance types.FeeAllowance) {
+	ctx.KVStore(k.storeKey).Set(types.GetTxFeeAllowanceKey(sender), k.cdc.MustMarshalBinaryLengthPrefixed(allowance))
+}
+
+// DeleteTxFeeAllowance removes the record of the fee allowance charged the gas fee of the evm tx of the sender
+func (k Keeper) DeleteTxFeeAllowance(ctx sdk.Context, sender sdk.AccAddress) {
+	ctx.KVStore(k.storeKey).Delete(types.GetTxFeeAllowanceKey(sender))
+}
+
+// RefundFeeAllowance credits the refunded gas fee back to the spend limit of the allowance, which was charged
+// the whole gas fee. The allowance used up by the charge is granted again.
+func (k Keeper) RefundFeeAllowance(ctx sdk.Context, charged types.FeeAllowance, refund sdk.Dec) {
+	if charged.SpendLimit.IsZero() || !refund.IsPositive() {
+		return
+	}
+	allowance, found := k.GetFeeAllowance(ctx, charged.Grantee)
+	if !found {
+		// the charge used up the spend limit
+		allowance = charged
+		allowance.SpendLimit = sdk.ZeroDec()
+	} else if !allowance.Granter.Equals(charged.Granter) {
+		return
+	}
+	allowance.SpendLimit = allowance.SpendLimit.Add(refund)
+	k.SetFeeAllowance(ctx, allowance)
+}
@@ -0,0 +1,114 @@
+package keeper
+
+import (
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	sdkerrors "github.com/okex/exchain/libs/cosmos-sdk/types/errors"
+	"github.com/okex/exchain/x/evm/types"
+)
+
+// ----------------------------------------------------------------------------
+// Gas price oracle functions
+// The min gas price floor of the evm txs, aggregated at the end of the blocks from the votes of the validators
+// and enforced by the ante handler.
+// ----------------------------------------------------------------------------
+
+// GetGasPriceVote returns the gas price vote of the validator
+func (k Keeper) GetGasPriceVote(ctx sdk.Context, validator sdk.ValAddress) (types.GasPriceVote, bool) {
+	bz := ctx.KVStore(k.storeKey).Get(types.GetGasPriceVoteKey(validator))
+	if len(bz) == 0 {
+		return types.GasPriceVote{}, false
+	}
+
+	var vote types.GasPriceVote
+	k.cdc.MustUnmarshalBinaryLengthPrefixed(bz, &vote)
+	return vote, true
+}
+
+// SetGasPriceVote sets the gas price vote of its validator
+func (k Keeper) SetGasPriceVote(ctx sdk.Context, vote types.GasPriceVote) {
+	ctx.KVStore(k.storeKey).Set(types.GetGasPriceVoteKey(vote.Validator), k.cdc.MustMarshalBinaryLengthPrefixed(vote))
+}
+
+// DeleteGasPriceVote removes the gas price vote of the validator
+func (k Keeper) DeleteGasPriceVote(ctx sdk.Context, validator sdk.ValAddress) {
+	ctx.KVStore(k.storeKey).Delete(types.GetGasPriceVoteKey(validator))
+}
+
+// GetGasPriceVotes returns all the gas price votes
+func (k Keeper) GetGasPriceVotes(ctx sdk.Context) []types.GasPriceVote {
+	iterator := sdk.KVStorePrefixIterator(ctx.KVStore(k.storeKey), types.KeyPrefixGasPriceVote)
+	defer iterator.Close()
+
+	var votes []types.GasPriceVote
+	for ; iterator.Valid(); iterator.Next() {
+		var vote types.GasPriceVote
+		k.cdc.MustUnmarshalBinaryLengthPrefixed(iterator.Value(), &vote)
+		votes = append(votes, vote)
+	}
+	return votes
+}
+
+// VoteGasPrice records the gas price vote of the validator at the current height, replacing its previous one.
+// Only the bonded validators vote while the oracle is enabled.
+func (k Keeper) VoteGasPrice(ctx sdk.Context, validator sdk.ValAddress, gasPrice sdk.Dec) error {
+	if k.GetParams(ctx).GasPriceVoteWindow == 0 {
+		return types.ErrGasPriceOracleDisabled
+	}
+	if k.stakingKeeper == nil || k.stakingKeeper.GetLastValidatorPower(ctx, validator) <= 0 {
+		return sdkerrors.Wrapf(types.ErrInvalidGasPriceVoter, "validator %s", validator)
+	}
+
+	k.SetGasPriceVote(ctx, types.GasPriceVote{Validator: validator, GasPrice: gasPrice, Height: ctx.BlockHeight()})
+	return nil
+}
+
+// GetMinGasPrice returns the min gas price floor of the evm txs, zero if the oracle is disabled or no vote
+// was aggregated yet
+func (k Keeper) GetMinGasPrice(ctx sdk.Context) sdk.Dec {
+	bz := ctx.KVStore(k.storeKey).Get(types.KeyPrefixMinGasPrice)
+	if len(bz) == 0 {
+		return sdk.ZeroDec()
+	}
+
+	var gasPrice sdk.Dec
+	k.cdc.MustUnmarshalBinaryLengthPrefixed(bz, &gasPrice)
+	return gasPrice
+}
+
+// SetMinGasPrice sets the min gas price floor of the evm txs
+func (k Keeper) SetMinGasPrice(ctx sdk.Context, gasPrice sdk.Dec) {
+	ctx.KVStore(k.storeKey).Set(types.KeyPrefixMinGasPrice, k.cdc.MustMarshalBinaryLengthPrefixed(gasPrice))
+}
+
+// UpdateMinGasPrice moves the min gas price floor towards the median of the gas price votes in the window,
+// weighted by the power of their validators and bounded by the max change of the params. The expired votes
+// are removed, the floor is kept while no bonded validator votes and removed once the oracle is disabled.
+func (k Keeper) UpdateMinGasPrice(ctx sdk.Context) {
+	params := k.GetParams(ctx)
+	if params.GasPriceVoteWindow == 0 {
+		if store := ctx.KVStore(k.storeKey); store.Has(types.KeyPrefixMinGasPrice) {
+			store.Delete(types.KeyPrefixMinGasPrice)
+		}
+		return
+	}
+	if k.stakingKeeper == nil {
+		return
+	}
+
+	var votes []types.GasPriceVote
+	var powers []int64
+	for _, vote := range k.GetGasPriceVotes(ctx) {
+		if vote.IsExpired(ctx.BlockHeight(), params.GasPriceVoteWindow) {
+			k.DeleteGasPriceVote(ctx, vote.Validator)
+			continue
+		}
+		votes = append(votes, vote)
+		powers = append(powers, k.stakingKeeper.GetLastValidatorPower(ctx, vote.Validator))
+	}
+
+	median := types.WeightedMedianGasPrice(votes, powers)
+	if median.IsNil() {
+		return
+	}
+	k.SetMinGasPrice(ctx, types.SmoothGasPrice(k.GetMinGasPrice(ctx), median, params.MaxGasPriceChange))
+}
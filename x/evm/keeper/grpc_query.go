@@ -0,0 +1,102 @@
+package keeper
+
+import (
+	"context"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/okex/exchain/libs/cosmos-sdk/store/prefix"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	"github.com/okex/exchain/x/evm/types"
+)
+
+type queryServer struct {
+	k *Keeper
+}
+
+// NewQueryServer returns the implementation of the grpc query service of the module, served by the abci queries
+func NewQueryServer(k *Keeper) types.QueryServer {
+	return queryServer{k: k}
+}
+
+// Code implements the Query/Code grpc method
+func (s queryServer) Code(c context.Context, req *types.QueryCodeRequest) (*types.QueryCodeResponse, error) {
+	addr, err := parseHexAddress(req.Address)
+	if err != nil {
+		return nil, err
+	}
+	ctx := sdk.UnwrapSDKContext(c)
+	if !s.k.isStateAccount(ctx, addr) {
+		return &types.QueryCodeResponse{}, nil
+	}
+	return &types.QueryCodeResponse{Code: s.k.GetCode(ctx, addr)}, nil
+}
+
+// Storage implements the Query/Storage grpc method
+func (s queryServer) Storage(c context.Context, req *types.QueryStorageRequest) (*types.QueryStorageResponse, error) {
+	addr, err := parseHexAddress(req.Address)
+	if err != nil {
+		return nil, err
+	}
+	ctx := sdk.UnwrapSDKContext(c)
+	value := s.k.GetState(ctx, addr, ethcmn.HexToHash(req.Key))
+	return &types.QueryStorageResponse{Value: value.Hex()}, nil
+}
+
+// StorageSlots implements the Query/StorageSlots grpc method, the slots are paged by their keys
+func (s queryServer) StorageSlots(c context.Context, req *types.QueryStorageSlotsRequest) (*types.QueryStorageSlotsResponse, error) {
+	addr, err := parseHexAddress(req.Address)
+	if err != nil {
+		return nil, err
+	}
+	ctx := sdk.UnwrapSDKContext(c)
+	store := prefix.NewStore(ctx.KVStore(s.k.storeKey), types.AddressStoragePrefix(addr))
+
+	limit := req.Pagination.GetLimit()
+	iterator := store.Iterator(req.Pagination.GetKey(), nil)
+	defer iterator.Close()
+
+	res := &types.QueryStorageSlotsResponse{Pagination: &types.PageResponse{}}
+	for ; iterator.Valid(); iterator.Next() {
+		if len(res.Slots) == limit {
+			res.Pagination.NextKey = iterator.Key()
+			break
+		}
+		res.Slots = append(res.Slots, &types.StorageSlot{
+			Key:   ethcmn.BytesToHash(iterator.Key()).Hex(),
+			Value: ethcmn.BytesToHash(iterator.Value()).Hex(),
+		})
+	}
+	return res, nil
+}
+
+// Params implements the Query/Params grpc method
+func (s queryServer) Params(c context.Context, _ *types.QueryParamsRequest) (*types.QueryParamsResponse, error) {
+	ctx := sdk.UnwrapSDKContext(c)
+	return &types.QueryParamsResponse{Params: types.NewProtoParams(s.k.GetParams(ctx))}, nil
+}
+
+// Bloom implements the Query/Bloom grpc method
+func (s queryServer) Bloom(c context.Context, req *types.QueryBloomRequest) (*types.QueryBloomResponse, error) {
+	if req.Height <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid block height %d", req.Height)
+	}
+	ctx := sdk.UnwrapSDKContext(c)
+	bloom := s.k.GetBlockBloom(ctx.WithBlockHeight(req.Height), req.Height)
+	return &types.QueryBloomResponse{Bloom: bloom.Bytes()}, nil
+}
+
+// TxLogs implements the Query/TxLogs grpc method. The logs aren't kept in the state, the nodes serve them
+// out of their tx index.
+func (s queryServer) TxLogs(context.Context, *types.QueryTxLogsRequest) (*types.QueryTxLogsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "the tx logs are served by the nodes out of the tx index")
+}
+
+func parseHexAddress(address string) (ethcmn.Address, error) {
+	if !ethcmn.IsHexAddress(address) {
+		return ethcmn.Address{}, status.Errorf(codes.InvalidArgument, "invalid hex address %q", address)
+	}
+	return ethcmn.HexToAddress(address), nil
+}
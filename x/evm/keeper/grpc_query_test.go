@@ -0,0 +1,87 @@
+package keeper_test
+
+import (
+	"fmt"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/protobuf/proto"
+
+	sdkerrors "github.com/okex/exchain/libs/cosmos-sdk/types/errors"
+	abci "github.com/okex/exchain/libs/tendermint/abci/types"
+	"github.com/okex/exchain/x/evm/types"
+)
+
+func (suite *KeeperTestSuite) grpcQuery(method string, req, res proto.Message) error {
+	handler := suite.app.GRPCQueryRouter().Route("/" + types.QueryServiceName + "/" + method)
+	suite.Require().NotNil(handler)
+	data, err := proto.Marshal(req)
+	suite.Require().NoError(err)
+	bz, err := handler(suite.ctx, abci.RequestQuery{Data: data})
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(bz, res)
+}
+
+func (suite *KeeperTestSuite) TestGRPCQuery() {
+	code := []byte("code")
+	suite.stateDB.WithContext(suite.ctx).SetCode(suite.address, code)
+	for i := 0; i < 5; i++ {
+		suite.stateDB.WithContext(suite.ctx).SetState(suite.address, ethcmn.BytesToHash([]byte(fmt.Sprintf("key%d", i))), ethcmn.BytesToHash([]byte(fmt.Sprintf("value%d", i))))
+	}
+	suite.stateDB.WithContext(suite.ctx).Finalise(false)
+	_, err := suite.stateDB.WithContext(suite.ctx).Commit(false)
+	suite.Require().NoError(err)
+	bloom := ethtypes.BytesToBloom([]byte{0x1, 0x3})
+	suite.app.EvmKeeper.SetBlockBloom(suite.ctx, 4, bloom)
+
+	var codeRes types.QueryCodeResponse
+	suite.Require().NoError(suite.grpcQuery("Code", &types.QueryCodeRequest{Address: addrHex}, &codeRes))
+	suite.Require().Equal(code, codeRes.Code)
+
+	var storageRes types.QueryStorageResponse
+	key := ethcmn.BytesToHash([]byte("key1"))
+	suite.Require().NoError(suite.grpcQuery("Storage", &types.QueryStorageRequest{Address: addrHex, Key: key.Hex()}, &storageRes))
+	suite.Require().Equal(ethcmn.BytesToHash([]byte("value1")).Hex(), storageRes.Value)
+
+	// the slots are paged by their keys
+	var slots []*types.StorageSlot
+	page := &types.PageRequest{Limit: 2}
+	for {
+		var slotsRes types.QueryStorageSlotsResponse
+		suite.Require().NoError(suite.grpcQuery("StorageSlots", &types.QueryStorageSlotsRequest{Address: addrHex, Pagination: page}, &slotsRes))
+		suite.Require().True(len(slotsRes.Slots) <= 2)
+		slots = append(slots, slotsRes.Slots...)
+		if slotsRes.Pagination == nil || len(slotsRes.Pagination.NextKey) == 0 {
+			break
+		}
+		page = &types.PageRequest{Key: slotsRes.Pagination.NextKey, Limit: 2}
+	}
+	storage, err := suite.app.EvmKeeper.GetAccountStorage(suite.ctx, suite.address)
+	suite.Require().NoError(err)
+	suite.Require().Len(slots, len(storage))
+	suite.Require().Len(slots, 5)
+
+	// a page without a limit has the default limit
+	var slotsRes types.QueryStorageSlotsResponse
+	suite.Require().NoError(suite.grpcQuery("StorageSlots", &types.QueryStorageSlotsRequest{Address: addrHex, Pagination: &types.PageRequest{}}, &slotsRes))
+	suite.Require().Len(slotsRes.Slots, 5)
+	suite.Require().Empty(slotsRes.Pagination.GetNextKey())
+
+	var paramsRes types.QueryParamsResponse
+	suite.Require().NoError(suite.grpcQuery("Params", &types.QueryParamsRequest{}, &paramsRes))
+	suite.Require().Equal(types.NewProtoParams(suite.app.EvmKeeper.GetParams(suite.ctx)), paramsRes.Params)
+
+	var bloomRes types.QueryBloomResponse
+	suite.Require().NoError(suite.grpcQuery("Bloom", &types.QueryBloomRequest{Height: 4}, &bloomRes))
+	suite.Require().Equal(bloom.Bytes(), bloomRes.Bloom)
+
+	// invalid requests
+	err = suite.grpcQuery("Code", &types.QueryCodeRequest{Address: "0x01232"}, &codeRes)
+	suite.Require().True(sdkerrors.ErrInvalidRequest.Is(err))
+	err = suite.grpcQuery("Bloom", &types.QueryBloomRequest{}, &bloomRes)
+	suite.Require().True(sdkerrors.ErrInvalidRequest.Is(err))
+	err = suite.grpcQuery("TxLogs", &types.QueryTxLogsRequest{Hash: hex}, &types.QueryTxLogsResponse{})
+	suite.Require().True(sdkerrors.ErrUnknownRequest.Is(err))
+}
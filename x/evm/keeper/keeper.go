@@ -13,6 +13,7 @@ import (
 	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
 	"github.com/okex/exchain/libs/cosmos-sdk/x/auth"
 	"github.com/okex/exchain/libs/tendermint/libs/log"
+	"github.com/okex/exchain/x/common/monitor"
 	"github.com/okex/exchain/x/evm/types"
 	"github.com/okex/exchain/x/evm/watcher"
 	"github.com/okex/exchain/x/params"
@@ -36,6 +37,7 @@ type Keeper struct {
 	supplyKeeper  types.SupplyKeeper
 	bankKeeper    types.BankKeeper
 	govKeeper     GovKeeper
+	stakingKeeper types.StakingKeeper
 
 	// Transaction counter in a block. Used on StateSB's Prepare function.
 	// It is reset to 0 every block on BeginBlock so there's no point in storing the counter
@@ -51,6 +53,17 @@ type Keeper struct {
 
 	// add inner block data
 	innerBlockData BlockInnerData
+
+	evmMetrics *monitor.EvmMetrics
+
+	// evm migrations run by each upgrade
+	migrations map[string][]Migration
+
+	// accounts and storage slots loaded or committed by the txs of the current block
+	stateCache *types.StateCache
+
+	// changes made by the evm txs of the current block, nil if the state diff feed is disabled
+	blockStateDiff types.StateDiff
 }
 
 // NewKeeper generates new evm module keeper
@@ -63,6 +76,13 @@ func NewKeeper(
 	}
 
 	types.InitTxTraces()
+	types.InitSignatures()
+	types.InitContractRedeployAudit()
+	types.InitEvmProfiler()
+	types.InitVMCaches()
+	types.InitPrefetch()
+	types.InitStateDiffFeed()
+	types.InitTxBlacklist()
 	err := initInnerDB()
 	if err != nil {
 		panic(err)
@@ -88,15 +108,21 @@ func NewKeeper(
 		Ada:           types.DefaultPrefixDb{},
 
 		innerBlockData: defaultBlockInnerData(),
+		evmMetrics:     monitor.NopEvmMetrics(),
+		migrations:     make(map[string][]Migration),
+		stateCache:     types.NewStateCache(nil),
 	}
 	k.Watcher.SetWatchDataFunc()
-	if k.Watcher.Enabled() {
-		ak.SetObserverKeeper(k)
-	}
+	ak.SetObserverKeeper(k)
 
 	return k
 }
 
+// SetEvmMetrics sets the metrics the evm profiler reports to
+func (k *Keeper) SetEvmMetrics(metrics *monitor.EvmMetrics) {
+	k.evmMetrics = metrics
+}
+
 // NewKeeper generates new evm module keeper
 func NewSimulateKeeper(
 	cdc *codec.Codec, storeKey sdk.StoreKey, paramSpace types.Subspace, ak types.AccountKeeper, sk types.SupplyKeeper, bk types.BankKeeper, ada types.DbAdapter,
@@ -117,10 +143,27 @@ func NewSimulateKeeper(
 	}
 }
 
-func (k Keeper) OnAccountUpdated(acc auth.Account) {
+func (k Keeper) OnAccountUpdated(ctx sdk.Context, acc auth.Account) {
 	account := acc.GetAddress()
-	k.Watcher.AddDirtyAccount(&account)
-	k.Watcher.DeleteAccount(account)
+	if types.IsStateCacheable(ctx) {
+		k.stateCache.EvictAccount(ethcmn.BytesToAddress(account))
+	}
+	if k.Watcher.Enabled() {
+		k.Watcher.AddDirtyAccount(&account)
+		k.Watcher.DeleteAccount(account)
+		// the accounts updated by the check txs and the simulations aren't committed
+		if !ctx.IsCheckTx() {
+			k.Watcher.AddBalanceChange(account)
+		}
+	}
+}
+
+// AddStateDiff merges the changes made by a delivered tx into the ones of the block, if the state diff feed is
+// enabled
+func (k *Keeper) AddStateDiff(diff types.StateDiff) {
+	if k.blockStateDiff != nil {
+		k.blockStateDiff.Merge(diff)
+	}
 }
 
 // Logger returns a module-specific logger.
@@ -131,9 +174,11 @@ func (k Keeper) GenerateCSDBParams() types.CommitStateDBParams {
 		AccountKeeper: k.accountKeeper,
 		SupplyKeeper:  k.supplyKeeper,
 		BankKeeper:    k.bankKeeper,
+		StakingKeeper: k.stakingKeeper,
 		Watcher:       k.Watcher,
 		Ada:           k.Ada,
 		Cdc:           k.cdc,
+		StateCache:    k.stateCache,
 	}
 }
 
@@ -192,6 +237,39 @@ func (k Keeper) SetHeightHash(ctx sdk.Context, height uint64, hash common.Hash)
 	types.CreateEmptyCommitStateDB(k.GenerateCSDBParams(), ctx).SetHeightHash(height, hash)
 }
 
+// GetRecentBlockHash returns the block header hash of one of the last heights from the BLOCKHASH ring buffer.
+func (k Keeper) GetRecentBlockHash(ctx sdk.Context, height uint64) (common.Hash, bool) {
+	return types.CreateEmptyCommitStateDB(k.GenerateCSDBParams(), ctx).GetRecentBlockHash(height)
+}
+
+// SetRecentBlockHash sets the block header hash of a height in the BLOCKHASH ring buffer.
+func (k Keeper) SetRecentBlockHash(ctx sdk.Context, height uint64, hash common.Hash) {
+	types.CreateEmptyCommitStateDB(k.GenerateCSDBParams(), ctx).SetRecentBlockHash(height, hash)
+}
+
+// GetRecentBlockHashes returns the known hashes of the BlockHashWindow heights up to the current one, from the
+// ring buffer or, for the heights sealed before the window was enabled, from the height -> hash index.
+func (k Keeper) GetRecentBlockHashes(ctx sdk.Context) []types.RecentBlockHash {
+	csdb := types.CreateEmptyCommitStateDB(k.GenerateCSDBParams(), ctx)
+	current := uint64(ctx.BlockHeight())
+	lowest := uint64(1)
+	if current > types.BlockHashWindow {
+		lowest = current - types.BlockHashWindow + 1
+	}
+
+	var hashes []types.RecentBlockHash
+	for height := lowest; height <= current; height++ {
+		hash, ok := csdb.GetRecentBlockHash(height)
+		if !ok {
+			hash = csdb.GetHeightHash(height)
+		}
+		if hash != (common.Hash{}) {
+			hashes = append(hashes, types.RecentBlockHash{Height: height, Hash: hash})
+		}
+	}
+	return hashes
+}
+
 // ----------------------------------------------------------------------------
 // Block bloom bits mapping functions
 // Required by Web3 API.
@@ -209,6 +287,11 @@ func (k Keeper) GetBlockBloom(ctx sdk.Context, height int64) ethtypes.Bloom {
 	return ethtypes.BytesToBloom(bz)
 }
 
+// GetCoinbase returns the address receiving the fees of the proposer of the block of the context
+func (k Keeper) GetCoinbase(ctx sdk.Context) common.Address {
+	return types.GetCoinbase(ctx, k.stakingKeeper)
+}
+
 func (k Keeper) GetStoreKey() store.StoreKey {
 	return k.storeKey
 }
@@ -255,7 +338,7 @@ func (k Keeper) GetChainConfig(ctx sdk.Context) (types.ChainConfig, bool) {
 // SetChainConfig sets the mapping from block consensus hash to block height
 func (k Keeper) SetChainConfig(ctx sdk.Context, config types.ChainConfig) {
 	store := k.Ada.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefixChainConfig)
-	bz := k.cdc.MustMarshalBinaryBare(config)
+	bz := types.MarshalChainConfig(k.cdc, config)
 	// get to an empty key that's already prefixed by KeyPrefixChainConfig
 	store.Set([]byte{}, bz)
 }
@@ -265,6 +348,12 @@ func (k *Keeper) SetGovKeeper(gk GovKeeper) {
 	k.govKeeper = gk
 }
 
+// SetStakingKeeper sets keeper of staking, resolving the fee recipient of the block proposers and the power of
+// the gas price voters
+func (k *Keeper) SetStakingKeeper(sk types.StakingKeeper) {
+	k.stakingKeeper = sk
+}
+
 // checks whether the address is blocked
 func (k *Keeper) IsAddressBlocked(ctx sdk.Context, addr sdk.AccAddress) bool {
 	csdb := types.CreateEmptyCommitStateDB(k.GenerateCSDBParams(), ctx)
@@ -18,3 +18,11 @@ func (k Keeper) SetStateDirectly(ctx sdk.Context, addr ethcmn.Address, key, valu
 	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.AddressStoragePrefix(addr))
 	store.Set(key.Bytes(), value.Bytes())
 }
+
+// SetStorageDirectly commits the states of an account into db with no cache
+func (k Keeper) SetStorageDirectly(ctx sdk.Context, addr ethcmn.Address, storage types.Storage) {
+	store := prefix.NewStore(ctx.KVStore(k.storeKey), types.AddressStoragePrefix(addr))
+	for _, state := range storage {
+		store.Set(state.Key.Bytes(), state.Value.Bytes())
+	}
+}
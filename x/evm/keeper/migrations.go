@@ -0,0 +1,234 @@
+package keeper
+
+import (
+	"fmt"
+	"sort"
+
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	sdkerrors "github.com/okex/exchain/libs/cosmos-sdk/types/errors"
+	"github.com/okex/exchain/x/evm/types"
+)
+
+// Migration is a state migration of the evm module run by an upgrade
+type Migration struct {
+	Description string
+	Migrate     func(ctx sdk.Context, k Keeper) error
+}
+
+// RegisterMigrations appends the migrations to the ones run by the upgrade. It must be called before the
+// upgrade handlers are registered.
+func (k *Keeper) RegisterMigrations(upgrade string, migrations ...Migration) {
+	k.migrations[upgrade] = append(k.migrations[upgrade], migrations...)
+}
+
+// GetMigrationUpgrades returns the sorted names of the upgrades running evm migrations
+func (k Keeper) GetMigrationUpgrades() []string {
+	upgrades := make([]string, 0, len(k.migrations))
+	for upgrade := range k.migrations {
+		upgrades = append(upgrades, upgrade)
+	}
+	sort.Strings(upgrades)
+	return upgrades
+}
+
+// RunMigrations runs the migrations of the upgrade in order, once the params and the chain config are saved to
+// the snapshot of the upgrade. No change is written if a migration fails, or if the migrated params or chain
+// config are invalid.
+func (k Keeper) RunMigrations(ctx sdk.Context, upgrade string) error {
+	snapshot, err := k.newUpgradeSnapshot(ctx, upgrade)
+	if err != nil {
+		return err
+	}
+
+	cacheCtx, write := ctx.CacheContext()
+	if _, err := k.runMigrations(cacheCtx, upgrade); err != nil {
+		return err
+	}
+	write()
+
+	k.SetUpgradeSnapshot(ctx, snapshot)
+	k.Logger(ctx).Info("evm migrations done", "upgrade", upgrade, "count", len(k.migrations[upgrade]))
+	return nil
+}
+
+// DryRunMigrations runs the migrations of the upgrade without writing any change, and reports the params and
+// the chain config they would leave.
+func (k Keeper) DryRunMigrations(ctx sdk.Context, upgrade string) (types.MigrationReport, error) {
+	snapshot, err := k.newUpgradeSnapshot(ctx, upgrade)
+	if err != nil {
+		return types.MigrationReport{}, err
+	}
+
+	cacheCtx, _ := ctx.CacheContext()
+	descriptions, err := k.runMigrations(cacheCtx, upgrade)
+	report := types.MigrationReport{
+		Upgrade:           upgrade,
+		Migrations:        descriptions,
+		ParamsBefore:      snapshot.Params,
+		ParamsAfter:       k.GetParams(cacheCtx),
+		ChainConfigBefore: snapshot.ChainConfig,
+	}
+	report.ChainConfigAfter, _ = k.GetChainConfig(cacheCtx)
+	if err != nil {
+		report.Error = err.Error()
+	}
+	return report, nil
+}
+
+// runMigrations runs the migrations of the upgrade and returns their descriptions
+func (k Keeper) runMigrations(ctx sdk.Context, upgrade string) ([]string, error) {
+	var descriptions []string
+	for i, migration := range k.migrations[upgrade] {
+		descriptions = append(descriptions, migration.Description)
+		if err := migration.Migrate(ctx, k); err != nil {
+			return descriptions, sdkerrors.Wrapf(types.ErrMigrationFailed, "upgrade %s, migration %d (%s): %s",
+				upgrade, i, migration.Description, err)
+		}
+	}
+
+	if err := k.GetParams(ctx).Validate(); err != nil {
+		return descriptions, sdkerrors.Wrapf(types.ErrMigrationFailed, "upgrade %s: invalid params: %s", upgrade, err)
+	}
+	config, found := k.GetChainConfig(ctx)
+	if !found {
+		return descriptions, sdkerrors.Wrapf(types.ErrMigrationFailed, "upgrade %s: %s", upgrade, types.ErrChainConfigNotFound)
+	}
+	if err := config.Validate(); err != nil {
+		return descriptions, sdkerrors.Wrapf(types.ErrMigrationFailed, "upgrade %s: invalid chain config: %s", upgrade, err)
+	}
+	return descriptions, nil
+}
+
+func (k Keeper) newUpgradeSnapshot(ctx sdk.Context, upgrade string) (types.UpgradeSnapshot, error) {
+	if _, ok := k.migrations[upgrade]; !ok {
+		return types.UpgradeSnapshot{}, sdkerrors.Wrapf(types.ErrMigrationsNotFound, "upgrade %s", upgrade)
+	}
+
+	config, found := k.GetChainConfig(ctx)
+	if !found {
+		return types.UpgradeSnapshot{}, types.ErrChainConfigNotFound
+	}
+	return types.UpgradeSnapshot{
+		Upgrade:     upgrade,
+		Height:      ctx.BlockHeight(),
+		Params:      k.GetParams(ctx),
+		ChainConfig: config,
+	}, nil
+}
+
+// GetUpgradeSnapshot returns the params and the chain config saved before the migrations of the upgrade
+func (k Keeper) GetUpgradeSnapshot(ctx sdk.Context, upgrade string) (types.UpgradeSnapshot, bool) {
+	bz := ctx.KVStore(k.storeKey).Get(types.GetUpgradeSnapshotKey(upgrade))
+	if len(bz) == 0 {
+		return types.UpgradeSnapshot{}, false
+	}
+
+	var snapshot types.UpgradeSnapshot
+	k.cdc.MustUnmarshalBinaryLengthPrefixed(bz, &snapshot)
+	return snapshot, true
+}
+
+// SetUpgradeSnapshot saves the params and the chain config of the snapshot of its upgrade
+func (k Keeper) SetUpgradeSnapshot(ctx sdk.Context, snapshot types.UpgradeSnapshot) {
+	ctx.KVStore(k.storeKey).Set(types.GetUpgradeSnapshotKey(snapshot.Upgrade), k.cdc.MustMarshalBinaryLengthPrefixed(snapshot))
+}
+
+// ----------------------------------------------------------------------------
+// Migrations
+// Building blocks of the migrations registered by the upgrades.
+// ----------------------------------------------------------------------------
+
+// RestoreSnapshotMigration restores the params and the chain config saved before the migrations of a previous
+// upgrade, e.g. to revert them.
+func RestoreSnapshotMigration(upgrade string) Migration {
+	return Migration{
+		Description: fmt.Sprintf("restore the params and the chain config of the upgrade %s", upgrade),
+		Migrate: func(ctx sdk.Context, k Keeper) error {
+			snapshot, found := k.GetUpgradeSnapshot(ctx, upgrade)
+			if !found {
+				return sdkerrors.Wrapf(types.ErrUpgradeSnapshotNotFound, "upgrade %s", upgrade)
+			}
+			k.SetParams(ctx, snapshot.Params)
+			k.SetChainConfig(ctx, snapshot.ChainConfig)
+			return nil
+		},
+	}
+}
+
+// RenameParamMigration moves the value of a param to its new key, which must be registered to the param key
+// table. The param store can't delete keys, the value is left under the old key but it isn't read anymore
+// once the old key is out of the key table.
+func RenameParamMigration(oldKey, newKey string) Migration {
+	return Migration{
+		Description: fmt.Sprintf("rename the param %s to %s", oldKey, newKey),
+		Migrate: func(ctx sdk.Context, k Keeper) error {
+			bz := k.paramSpace.GetRaw(ctx, []byte(oldKey))
+			if len(bz) == 0 {
+				return fmt.Errorf("param %s not found", oldKey)
+			}
+			return k.paramSpace.Update(ctx, []byte(newKey), bz)
+		},
+	}
+}
+
+// RekeyStoreMigration moves the entries of the evm store under the old prefix to the new one, keeping the rest
+// of their keys
+func RekeyStoreMigration(oldPrefix, newPrefix []byte) Migration {
+	return Migration{
+		Description: fmt.Sprintf("move the store entries from the prefix %X to %X", oldPrefix, newPrefix),
+		Migrate: func(ctx sdk.Context, k Keeper) error {
+			store := ctx.KVStore(k.storeKey)
+			entries := prefixEntries(store, oldPrefix)
+			for _, entry := range entries {
+				store.Delete(entry.key)
+			}
+			for _, entry := range entries {
+				store.Set(append(append([]byte{}, newPrefix...), entry.key[len(oldPrefix):]...), entry.value)
+			}
+			return nil
+		},
+	}
+}
+
+// TransformStoreMigration replaces the value of each entry of the evm store under the prefix with the one
+// returned by the transform, e.g. to re-encode the values to a new format. The entry is deleted if the
+// transform returns an empty value.
+func TransformStoreMigration(
+	description string, prefix []byte, transform func(key, value []byte) ([]byte, error),
+) Migration {
+	return Migration{
+		Description: description,
+		Migrate: func(ctx sdk.Context, k Keeper) error {
+			store := ctx.KVStore(k.storeKey)
+			for _, entry := range prefixEntries(store, prefix) {
+				value, err := transform(entry.key, entry.value)
+				if err != nil {
+					return fmt.Errorf("key %X: %w", entry.key, err)
+				}
+				if len(value) == 0 {
+					store.Delete(entry.key)
+				} else {
+					store.Set(entry.key, value)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+type storeEntry struct {
+	key, value []byte
+}
+
+// prefixEntries returns the entries of the store under the prefix, so that the store can be written while they
+// are migrated
+func prefixEntries(store sdk.KVStore, prefix []byte) []storeEntry {
+	iterator := sdk.KVStorePrefixIterator(store, prefix)
+	defer iterator.Close()
+
+	var entries []storeEntry
+	for ; iterator.Valid(); iterator.Next() {
+		entries = append(entries, storeEntry{key: iterator.Key(), value: iterator.Value()})
+	}
+	return entries
+}
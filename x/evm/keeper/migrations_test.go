@@ -0,0 +1,78 @@
+package keeper_test
+
+import (
+	"errors"
+
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	abci "github.com/okex/exchain/libs/tendermint/abci/types"
+	"github.com/okex/exchain/x/evm/keeper"
+	"github.com/okex/exchain/x/evm/types"
+)
+
+func (suite *KeeperTestSuite) TestRunMigrations() {
+	params := types.DefaultParams()
+	params.EnableCreate, params.EnableCall = false, true
+	suite.app.EvmKeeper.SetParams(suite.ctx, params)
+	store := suite.ctx.KVStore(suite.app.GetKey(types.StoreKey))
+	store.Set([]byte{0xF0, 0x1}, []byte("value"))
+
+	k := suite.app.EvmKeeper
+	k.RegisterMigrations("v1",
+		keeper.RenameParamMigration(string(types.ParamStoreKeyEnableCall), string(types.ParamStoreKeyEnableCreate)),
+		keeper.RekeyStoreMigration([]byte{0xF0}, []byte{0xF1}),
+		keeper.TransformStoreMigration("upper case the values", []byte{0xF1}, func(_, value []byte) ([]byte, error) {
+			return []byte("VALUE"), nil
+		}),
+	)
+	k.RegisterMigrations("v2", keeper.RestoreSnapshotMigration("v1"))
+	k.RegisterMigrations("failed", keeper.Migration{
+		Description: "set the max gas limit per tx and fail",
+		Migrate: func(ctx sdk.Context, k keeper.Keeper) error {
+			params := k.GetParams(ctx)
+			params.MaxGasLimitPerTx = 1
+			k.SetParams(ctx, params)
+			return errors.New("failed")
+		},
+	})
+	suite.Require().Equal([]string{"failed", "v1", "v2"}, k.GetMigrationUpgrades())
+
+	// the dry run reports the params after the migrations without writing them
+	report, err := k.DryRunMigrations(suite.ctx, "v1")
+	suite.Require().NoError(err)
+	suite.Require().Empty(report.Error)
+	suite.Require().Len(report.Migrations, 3)
+	suite.Require().False(report.ParamsBefore.EnableCreate)
+	suite.Require().True(report.ParamsAfter.EnableCreate)
+	suite.Require().False(k.GetParams(suite.ctx).EnableCreate)
+	suite.Require().True(store.Has([]byte{0xF0, 0x1}))
+
+	suite.Require().NoError(k.RunMigrations(suite.ctx, "v1"))
+	suite.Require().True(k.GetParams(suite.ctx).EnableCreate)
+	suite.Require().False(store.Has([]byte{0xF0, 0x1}))
+	suite.Require().Equal([]byte("VALUE"), store.Get([]byte{0xF1, 0x1}))
+	snapshot, found := k.GetUpgradeSnapshot(suite.ctx, "v1")
+	suite.Require().True(found)
+	suite.Require().Equal(params, snapshot.Params)
+
+	// the snapshot of a previous upgrade is restored
+	suite.Require().NoError(k.RunMigrations(suite.ctx, "v2"))
+	suite.Require().Equal(params, k.GetParams(suite.ctx))
+
+	// no change is written by a failed upgrade
+	report, err = k.DryRunMigrations(suite.ctx, "failed")
+	suite.Require().NoError(err)
+	suite.Require().Contains(report.Error, "failed")
+	suite.Require().Equal(uint64(1), report.ParamsAfter.MaxGasLimitPerTx)
+	suite.Require().Error(k.RunMigrations(suite.ctx, "failed"))
+	suite.Require().Equal(params, k.GetParams(suite.ctx))
+	_, found = k.GetUpgradeSnapshot(suite.ctx, "failed")
+	suite.Require().False(found)
+
+	suite.Require().Error(k.RunMigrations(suite.ctx, "unknown"))
+	_, err = suite.querier(suite.ctx, []string{types.QueryDryRunMigrations, "v1"}, abci.RequestQuery{})
+	suite.Require().NoError(err)
+	_, err = suite.querier(suite.ctx, []string{types.QueryUpgradeSnapshot, "v1"}, abci.RequestQuery{})
+	suite.Require().NoError(err)
+	_, err = suite.querier(suite.ctx, []string{types.QueryUpgradeSnapshot, "unknown"}, abci.RequestQuery{})
+	suite.Require().Error(err)
+}
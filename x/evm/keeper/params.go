@@ -1,13 +1,16 @@
 package keeper
 
 import (
+	"math/big"
+
 	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
 	"github.com/okex/exchain/x/evm/types"
 )
 
-// GetParams returns the total set of evm parameters.
+// GetParams returns the total set of evm parameters. The params added after the launch of the chain (e.g. BaseFee)
+// keep their zero value until they're set by a proposal.
 func (k Keeper) GetParams(ctx sdk.Context) (params types.Params) {
-	k.paramSpace.GetParamSet(ctx, &params)
+	k.paramSpace.GetParamSetIfExists(ctx, &params)
 	return
 }
 
@@ -15,3 +18,14 @@ func (k Keeper) GetParams(ctx sdk.Context) (params types.Params) {
 func (k Keeper) SetParams(ctx sdk.Context, params types.Params) {
 	k.paramSpace.SetParamSet(ctx, &params)
 }
+
+// GetBaseFee returns the base fee per gas of the current block. It returns nil before London is activated,
+// as blocks don't carry a base fee until then.
+func (k Keeper) GetBaseFee(ctx sdk.Context) *big.Int {
+	config, found := k.GetChainConfig(ctx)
+	if !found || !config.IsLondon(ctx.BlockHeight()) {
+		return nil
+	}
+
+	return new(big.Int).SetUint64(k.GetParams(ctx).BaseFee)
+}
@@ -1,7 +1,12 @@
 package keeper_test
 
 import (
+	"math/big"
+
+	"github.com/okex/exchain/libs/cosmos-sdk/store/prefix"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
 	"github.com/okex/exchain/x/evm/types"
+	"github.com/okex/exchain/x/params"
 )
 
 func (suite *KeeperTestSuite) TestParams() {
@@ -11,3 +16,46 @@ func (suite *KeeperTestSuite) TestParams() {
 	newParams := suite.app.EvmKeeper.GetParams(suite.ctx)
 	suite.Require().Equal(newParams, params)
 }
+
+func (suite *KeeperTestSuite) TestBaseFee() {
+	params := suite.app.EvmKeeper.GetParams(suite.ctx)
+	params.BaseFee = 1000000000
+	suite.app.EvmKeeper.SetParams(suite.ctx, params)
+
+	// London is disabled by default
+	suite.Require().Nil(suite.app.EvmKeeper.GetBaseFee(suite.ctx))
+
+	config, found := suite.app.EvmKeeper.GetChainConfig(suite.ctx)
+	suite.Require().True(found)
+	config.BerlinBlock = sdk.ZeroInt()
+	config.LondonBlock = sdk.NewInt(suite.ctx.BlockHeight() + 1)
+	suite.app.EvmKeeper.SetChainConfig(suite.ctx, config)
+	suite.Require().Nil(suite.app.EvmKeeper.GetBaseFee(suite.ctx))
+
+	ctx := suite.ctx.WithBlockHeight(suite.ctx.BlockHeight() + 1)
+	suite.Require().Equal(big.NewInt(1000000000), suite.app.EvmKeeper.GetBaseFee(ctx))
+}
+
+func (suite *KeeperTestSuite) TestParams_addedAfterLaunch() {
+	// the param store of a chain launched before BaseFee, MaxGasLimitPerBlock and EnableBlockHashWindow were added
+	// doesn't contain them
+	store := prefix.NewStore(suite.ctx.KVStore(suite.app.GetKey(params.StoreKey)), []byte(types.DefaultParamspace+"/"))
+	store.Delete(types.ParamStoreKeyBaseFee)
+	store.Delete(types.ParamStoreKeyMaxGasLimitPerBlock)
+	store.Delete(types.ParamStoreKeyBlockHashWindow)
+
+	var evmParams types.Params
+	suite.Require().NotPanics(func() {
+		evmParams = suite.app.EvmKeeper.GetParams(suite.ctx)
+	})
+	suite.Require().Zero(evmParams.BaseFee)
+	suite.Require().Zero(evmParams.MaxGasLimitPerBlock)
+	suite.Require().Equal(types.DefaultParams().MaxGasLimitPerTx, evmParams.MaxGasLimitPerTx)
+
+	csdb := types.CreateEmptyCommitStateDB(suite.app.EvmKeeper.GenerateCSDBParams(), suite.ctx)
+	suite.Require().NotPanics(func() {
+		evmParams = csdb.GetParams()
+	})
+	suite.Require().Zero(evmParams.BaseFee)
+	suite.Require().Zero(evmParams.MaxGasLimitPerBlock)
+}
@@ -0,0 +1,42 @@
+package keeper
+
+import (
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethermint "github.com/okex/exchain/app/types"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	"github.com/okex/exchain/x/evm/types"
+)
+
+// PrefetchTx warms the accounts, the code and the storage slots read by an evm tx before it is executed, see
+// sdk.PrefetchTxHandler. The slots are the ones touched by the recent txs sent to the same recipient, reading
+// them from the last committed state loads the nodes of the store in its caches.
+func (k *Keeper) PrefetchTx(ctx sdk.Context, tx sdk.Tx) {
+	evmTx, ok := tx.(types.MsgEthereumTx)
+	if !ok {
+		return
+	}
+
+	// the sender was recovered by the pre-deliver handler
+	if sigCache, err := evmTx.VerifySig(evmTx.ChainID(), ctx.BlockHeight()+1, nil); err == nil {
+		k.accountKeeper.GetAccount(ctx, sigCache.GetFrom().Bytes())
+	}
+	recipient := evmTx.To()
+	if recipient == nil {
+		return
+	}
+	if acc, ok := k.accountKeeper.GetAccount(ctx, recipient.Bytes()).(*ethermint.EthAccount); ok && len(acc.CodeHash) != 0 {
+		k.Ada.NewStore(ctx.KVStore(k.storeKey), types.KeyPrefixCode).Get(acc.CodeHash)
+	}
+
+	slots := types.RecentSlots(*recipient)
+	stores := make(map[ethcmn.Address]types.StoreProxy)
+	for _, slot := range slots {
+		store, ok := stores[slot.Address]
+		if !ok {
+			store = k.Ada.NewStore(ctx.KVStore(k.storeKey), types.AddressStoragePrefix(slot.Address))
+			stores[slot.Address] = store
+		}
+		store.Get(slot.Key.Bytes())
+	}
+	types.AddPrefetchedSlots(len(slots))
+}
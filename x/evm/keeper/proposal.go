@@ -5,6 +5,7 @@ import (
 	"time"
 
 	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	sdkerrors "github.com/okex/exchain/libs/cosmos-sdk/types/errors"
 	"github.com/okex/exchain/x/evm/types"
 	sdkGov "github.com/okex/exchain/x/gov"
 	govKeeper "github.com/okex/exchain/x/gov/keeper"
@@ -16,7 +17,8 @@ var _ govKeeper.ProposalHandler = (*Keeper)(nil)
 // GetMinDeposit returns min deposit
 func (k Keeper) GetMinDeposit(ctx sdk.Context, content sdkGov.Content) (minDeposit sdk.SysCoins) {
 	switch content.(type) {
-	case types.ManageContractDeploymentWhitelistProposal, types.ManageContractBlockedListProposal, types.ManageContractMethodBlockedListProposal:
+	case types.ManageContractDeploymentWhitelistProposal, types.ManageContractBlockedListProposal, types.ManageContractMethodBlockedListProposal,
+		types.ManageChainConfigForksProposal, types.ManageTokenRegistryProposal, types.ManageContractGasQuotaProposal:
 		minDeposit = k.govKeeper.GetDepositParams(ctx).MinDeposit
 	}
 
@@ -26,7 +28,8 @@ func (k Keeper) GetMinDeposit(ctx sdk.Context, content sdkGov.Content) (minDepos
 // GetMaxDepositPeriod returns max deposit period
 func (k Keeper) GetMaxDepositPeriod(ctx sdk.Context, content sdkGov.Content) (maxDepositPeriod time.Duration) {
 	switch content.(type) {
-	case types.ManageContractDeploymentWhitelistProposal, types.ManageContractBlockedListProposal, types.ManageContractMethodBlockedListProposal:
+	case types.ManageContractDeploymentWhitelistProposal, types.ManageContractBlockedListProposal, types.ManageContractMethodBlockedListProposal,
+		types.ManageChainConfigForksProposal, types.ManageTokenRegistryProposal, types.ManageContractGasQuotaProposal:
 		maxDepositPeriod = k.govKeeper.GetDepositParams(ctx).MaxDepositPeriod
 	}
 
@@ -36,7 +39,8 @@ func (k Keeper) GetMaxDepositPeriod(ctx sdk.Context, content sdkGov.Content) (ma
 // GetVotingPeriod returns voting period
 func (k Keeper) GetVotingPeriod(ctx sdk.Context, content sdkGov.Content) (votingPeriod time.Duration) {
 	switch content.(type) {
-	case types.ManageContractDeploymentWhitelistProposal, types.ManageContractBlockedListProposal, types.ManageContractMethodBlockedListProposal:
+	case types.ManageContractDeploymentWhitelistProposal, types.ManageContractBlockedListProposal, types.ManageContractMethodBlockedListProposal,
+		types.ManageChainConfigForksProposal, types.ManageTokenRegistryProposal, types.ManageContractGasQuotaProposal:
 		votingPeriod = k.govKeeper.GetVotingParams(ctx).VotingPeriod
 	}
 
@@ -65,6 +69,34 @@ func (k Keeper) CheckMsgSubmitProposal(ctx sdk.Context, msg govTypes.MsgSubmitPr
 			}
 		}
 		return nil
+	case types.ManageChainConfigForksProposal:
+		// the forks are checked again against the height at which the proposal passes
+		config, found := k.GetChainConfig(ctx)
+		if !found {
+			return types.ErrChainConfigNotFound
+		}
+		_, err := content.UpdateForks(config, ctx.BlockHeight())
+		return err
+	case types.ManageTokenRegistryProposal:
+		// can not delete a token which isn't in the registry
+		if !content.IsAdded {
+			for _, token := range content.Tokens {
+				if _, found := k.GetTokenMetadata(ctx, token.Denom); !found {
+					return sdkerrors.Wrapf(types.ErrTokenMetadataNotFound, "denom %s", token.Denom)
+				}
+			}
+		}
+		return nil
+	case types.ManageContractGasQuotaProposal:
+		// can not delete a quota which doesn't exist
+		if !content.IsAdded {
+			for _, quota := range content.Quotas {
+				if _, found := k.GetContractGasQuota(ctx, quota.Address); !found {
+					return sdkerrors.Wrapf(types.ErrContractGasQuotaNotFound, "contract %s", quota.Address)
+				}
+			}
+		}
+		return nil
 	default:
 		return sdk.ErrUnknownRequest(fmt.Sprintf("unrecognized %s proposal content type: %T", types.DefaultCodespace, content))
 	}
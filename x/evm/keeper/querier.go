@@ -4,24 +4,34 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
 
 	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethermint "github.com/okex/exchain/app/types"
 	"github.com/okex/exchain/app/utils"
 	"github.com/okex/exchain/libs/cosmos-sdk/codec"
 	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
 	sdkerrors "github.com/okex/exchain/libs/cosmos-sdk/types/errors"
 	abci "github.com/okex/exchain/libs/tendermint/abci/types"
+	"github.com/okex/exchain/libs/tendermint/libs/tracing"
 	"github.com/okex/exchain/x/evm/types"
 )
 
 // NewQuerier is the module level router for state queries
 func NewQuerier(keeper Keeper) sdk.Querier {
-	return func(ctx sdk.Context, path []string, _ abci.RequestQuery) ([]byte, error) {
+	return func(ctx sdk.Context, path []string, req abci.RequestQuery) ([]byte, error) {
 		if len(path) < 1 {
 			return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest,
 				"Insufficient parameters, at least 1 parameter is required")
 		}
 
+		span := tracing.StartSpan("evm " + path[0])
+		defer span.End()
+		if span != nil {
+			ctx = ctx.WithContext(tracing.ContextWithSpan(ctx.Context(), span))
+		}
+
 		switch path[0] {
 		case types.QueryBalance:
 			return queryBalance(ctx, path, keeper)
@@ -55,6 +65,22 @@ func NewQuerier(keeper Keeper) sdk.Querier {
 			return queryContractBlockedList(ctx, keeper)
 		case types.QueryContractMethodBlockedList:
 			return queryContractMethodBlockedList(ctx, keeper)
+		case types.QueryBaseFee:
+			return queryBaseFee(ctx, keeper)
+		case types.QueryEvmProfile:
+			return queryEvmProfile(keeper)
+		case types.QuerySimulateBundle:
+			return querySimulateBundle(ctx, req, keeper)
+		case types.QueryUpgradeSnapshot:
+			return queryUpgradeSnapshot(ctx, path, keeper)
+		case types.QueryDryRunMigrations:
+			return queryDryRunMigrations(ctx, path, keeper)
+		case types.QueryTokenRegistry:
+			return queryTokenRegistry(ctx, path, keeper)
+		case types.QueryMinGasPrice:
+			return queryMinGasPrice(ctx, keeper)
+		case types.QueryContractGasQuota:
+			return queryContractGasQuota(ctx, path, keeper)
 		default:
 			return nil, sdkerrors.Wrap(sdkerrors.ErrUnknownRequest, "unknown query endpoint")
 		}
@@ -165,9 +191,11 @@ func queryCode(ctx sdk.Context, path []string, keeper Keeper) ([]byte, error) {
 	}
 
 	addr := ethcmn.HexToAddress(path[1])
-	so := keeper.GetOrNewStateObject(ctx, addr)
-	code := keeper.GetCodeByHash(ctx, ethcmn.BytesToHash(so.CodeHash()))
-	res := types.QueryResCode{Code: code}
+	res := types.QueryResCode{}
+	if keeper.isStateAccount(ctx, addr) {
+		so := keeper.GetOrNewStateObject(ctx, addr)
+		res.Code = keeper.GetCodeByHash(ctx, ethcmn.BytesToHash(so.CodeHash()))
+	}
 	bz, err := codec.MarshalJSONIndent(keeper.cdc, res)
 	if err != nil {
 		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
@@ -176,6 +204,17 @@ func queryCode(ctx sdk.Context, path []string, keeper Keeper) ([]byte, error) {
 	return bz, nil
 }
 
+// isStateAccount returns false if the address is a module or a vesting account, they have no code and
+// can't be loaded as state objects
+func (k Keeper) isStateAccount(ctx sdk.Context, addr ethcmn.Address) bool {
+	acc := k.accountKeeper.GetAccount(ctx, addr.Bytes())
+	if acc == nil {
+		return true
+	}
+	_, ok := acc.(*ethermint.EthAccount)
+	return ok
+}
+
 func queryCodeByHash(ctx sdk.Context, path []string, keeper Keeper) ([]byte, error) {
 	if len(path) < 2 {
 		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest,
@@ -303,6 +342,89 @@ func queryParams(ctx sdk.Context, keeper Keeper) (res []byte, err sdk.Error) {
 	return res, nil
 }
 
+func queryBaseFee(ctx sdk.Context, keeper Keeper) ([]byte, error) {
+	res := types.QueryResBaseFee{}
+	if baseFee := keeper.GetBaseFee(ctx); baseFee != nil {
+		res.BaseFee = (*hexutil.Big)(baseFee)
+	}
+
+	bz, err := codec.MarshalJSONIndent(keeper.cdc, res)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+	return bz, nil
+}
+
+func queryEvmProfile(keeper Keeper) ([]byte, error) {
+	if !types.IsEvmProfilerEnabled() {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "evm profiler is disabled on this node")
+	}
+
+	bz, err := codec.MarshalJSONIndent(keeper.cdc, types.GetLastEvmProfile())
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+	return bz, nil
+}
+
+// querySimulateBundle simulates the bundle in the block at the queried height, unless its number is overridden
+func querySimulateBundle(ctx sdk.Context, req abci.RequestQuery, keeper Keeper) ([]byte, error) {
+	var bundleReq types.SimulateBundleRequest
+	if err := json.Unmarshal(req.Data, &bundleReq); err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONUnmarshal, err.Error())
+	}
+
+	if req.Height > 0 {
+		ctx = ctx.WithBlockHeight(req.Height)
+	}
+	results, err := keeper.SimulateBundle(ctx, bundleReq)
+	if err != nil {
+		return nil, err
+	}
+
+	bz, err := json.Marshal(results)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+	return bz, nil
+}
+
+func queryUpgradeSnapshot(ctx sdk.Context, path []string, keeper Keeper) ([]byte, error) {
+	if len(path) < 2 {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest,
+			"Insufficient parameters, at least 2 parameters is required")
+	}
+
+	snapshot, found := keeper.GetUpgradeSnapshot(ctx, path[1])
+	if !found {
+		return nil, sdkerrors.Wrapf(types.ErrUpgradeSnapshotNotFound, "upgrade %s", path[1])
+	}
+	bz, err := codec.MarshalJSONIndent(keeper.cdc, snapshot)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+	return bz, nil
+}
+
+// queryDryRunMigrations runs the evm migrations of the upgrade registered to the queried node on top of the
+// queried state
+func queryDryRunMigrations(ctx sdk.Context, path []string, keeper Keeper) ([]byte, error) {
+	if len(path) < 2 {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest,
+			"Insufficient parameters, at least 2 parameters is required")
+	}
+
+	report, err := keeper.DryRunMigrations(ctx, path[1])
+	if err != nil {
+		return nil, err
+	}
+	bz, err := codec.MarshalJSONIndent(keeper.cdc, report)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+	return bz, nil
+}
+
 func queryHeightToHash(ctx sdk.Context, path []string, keeper Keeper) ([]byte, error) {
 	if len(path) < 2 {
 		return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidRequest,
@@ -337,3 +459,70 @@ func querySection(ctx sdk.Context, path []string, keeper Keeper) ([]byte, error)
 
 	return res, nil
 }
+
+// queryTokenRegistry returns the metadata of the denom of the path, or of all the tokens of the registry
+func queryTokenRegistry(ctx sdk.Context, path []string, keeper Keeper) ([]byte, error) {
+	var res interface{}
+	if len(path) > 1 {
+		metadata, found := keeper.GetTokenMetadata(ctx, strings.Join(path[1:], "/"))
+		if !found {
+			return nil, sdkerrors.Wrapf(types.ErrTokenMetadataNotFound, "denom %s", strings.Join(path[1:], "/"))
+		}
+		res = metadata
+	} else {
+		registry := keeper.GetTokenRegistry(ctx)
+		if registry == nil {
+			registry = []types.TokenMetadata{}
+		}
+		res = registry
+	}
+	bz, err := codec.MarshalJSONIndent(keeper.cdc, res)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+	return bz, nil
+}
+
+// queryMinGasPrice returns the min gas price floor along with the gas price votes
+func queryMinGasPrice(ctx sdk.Context, keeper Keeper) ([]byte, error) {
+	res := types.QueryResMinGasPrice{
+		MinGasPrice: keeper.GetMinGasPrice(ctx),
+		Votes:       keeper.GetGasPriceVotes(ctx),
+	}
+	if res.Votes == nil {
+		res.Votes = []types.GasPriceVote{}
+	}
+	bz, err := codec.MarshalJSONIndent(keeper.cdc, res)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+	return bz, nil
+}
+
+// queryContractGasQuota returns the gas quota of the contract of the path, or all the contract gas quotas, along
+// with the gas consumed by the contracts in the current block
+func queryContractGasQuota(ctx sdk.Context, path []string, keeper Keeper) ([]byte, error) {
+	var res interface{}
+	if len(path) > 1 {
+		contract, err := sdk.AccAddressFromBech32(path[1])
+		if err != nil {
+			return nil, sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, err.Error())
+		}
+		quota, found := keeper.GetContractGasQuota(ctx, contract)
+		if !found {
+			return nil, sdkerrors.Wrapf(types.ErrContractGasQuotaNotFound, "contract %s", contract)
+		}
+		res = types.NewContractGasQuotaUsage(quota, keeper.GetContractGasUsed(ctx, contract))
+	} else {
+		quotas := make([]types.ContractGasQuotaUsage, 0)
+		for _, quota := range keeper.GetContractGasQuotas(ctx) {
+			quotas = append(quotas, types.NewContractGasQuotaUsage(quota, keeper.GetContractGasUsed(ctx, quota.Address)))
+		}
+		res = quotas
+	}
+	bz, err := codec.MarshalJSONIndent(keeper.cdc, res)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrJSONMarshal, err.Error())
+	}
+	return bz, nil
+}
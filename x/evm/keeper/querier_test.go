@@ -9,6 +9,9 @@ import (
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/okex/exchain/x/evm/types"
 
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	"github.com/okex/exchain/libs/cosmos-sdk/x/auth"
+	"github.com/okex/exchain/libs/cosmos-sdk/x/supply"
 	abci "github.com/okex/exchain/libs/tendermint/abci/types"
 )
 
@@ -27,6 +30,9 @@ func (suite *KeeperTestSuite) TestQuerier() {
 		{"block number", []string{types.QueryBlockNumber}, func() {}, true},
 		{"storage", []string{types.QueryStorage, "0x0", "0x0"}, func() {}, true},
 		{"code", []string{types.QueryCode, "0x0"}, func() {}, true},
+		{"module account code", []string{types.QueryCode, ethcmn.BytesToAddress(supply.NewModuleAddress(auth.FeeCollectorName)).Hex()}, func() {
+			suite.app.SupplyKeeper.GetModuleAccount(suite.ctx, auth.FeeCollectorName)
+		}, true},
 		{"hash to height", []string{types.QueryHashToHeight, hex}, func() {
 			suite.app.EvmKeeper.SetBlockHash(suite.ctx, hash, 8)
 		}, true},
@@ -52,8 +58,23 @@ func (suite *KeeperTestSuite) TestQuerier() {
 			}
 			suite.stateDB.WithContext(suite.ctx).Finalise(false)
 		}, true},
+		{"token registry", []string{types.QueryTokenRegistry}, func() {}, true},
+		{"token metadata", []string{types.QueryTokenRegistry, "ibc", "2739"}, func() {
+			suite.app.EvmKeeper.SetTokenMetadata(suite.ctx, types.TokenMetadata{Denom: "ibc/2739", Symbol: "ATOM", Decimals: 6, OriginChain: "cosmoshub-4"})
+		}, true},
+		{"fail token metadata", []string{types.QueryTokenRegistry, "ibc/0000"}, func() {}, false},
+		{"contract gas quotas", []string{types.QueryContractGasQuota}, func() {}, true},
+		{"contract gas quota", []string{types.QueryContractGasQuota, "ex1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqpxuz0nc"}, func() {
+			suite.app.EvmKeeper.SetContractGasQuota(suite.ctx, types.NewContractGasQuota(ethcmn.BytesToAddress([]byte{0x1}).Bytes(), 1000000))
+		}, true},
+		{"fail contract gas quota", []string{types.QueryContractGasQuota, "ex1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqpxuz0nc"}, func() {}, false},
+		{"min gas price", []string{types.QueryMinGasPrice}, func() {
+			suite.app.EvmKeeper.SetMinGasPrice(suite.ctx, sdk.NewDecWithPrec(1, 9))
+		}, true},
 		{"unknown request", []string{"other"}, func() {}, false},
 		{"parameters", []string{types.QueryParameters}, func() {}, true},
+		{"base fee", []string{types.QueryBaseFee}, func() {}, true},
+		{"evm profile disabled", []string{types.QueryEvmProfile}, func() {}, false},
 	}
 
 	for i, tc := range testCases {
@@ -0,0 +1,47 @@
+package keeper
+
+import (
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	"github.com/okex/exchain/x/evm/types"
+)
+
+// ----------------------------------------------------------------------------
+// Token registry functions
+// The erc20 metadata of the ibc denoms and bridged assets, managed by the token registry proposals.
+// ----------------------------------------------------------------------------
+
+// GetTokenMetadata returns the metadata of the denom in the token registry
+func (k Keeper) GetTokenMetadata(ctx sdk.Context, denom string) (types.TokenMetadata, bool) {
+	bz := ctx.KVStore(k.storeKey).Get(types.GetTokenRegistryKey(denom))
+	if len(bz) == 0 {
+		return types.TokenMetadata{}, false
+	}
+
+	var metadata types.TokenMetadata
+	k.cdc.MustUnmarshalBinaryLengthPrefixed(bz, &metadata)
+	return metadata, true
+}
+
+// SetTokenMetadata sets the metadata of its denom into the token registry
+func (k Keeper) SetTokenMetadata(ctx sdk.Context, metadata types.TokenMetadata) {
+	ctx.KVStore(k.storeKey).Set(types.GetTokenRegistryKey(metadata.Denom), k.cdc.MustMarshalBinaryLengthPrefixed(metadata))
+}
+
+// DeleteTokenMetadata removes the metadata of the denom from the token registry
+func (k Keeper) DeleteTokenMetadata(ctx sdk.Context, denom string) {
+	ctx.KVStore(k.storeKey).Delete(types.GetTokenRegistryKey(denom))
+}
+
+// GetTokenRegistry returns the metadata of all the tokens of the registry, in the order of their denom
+func (k Keeper) GetTokenRegistry(ctx sdk.Context) []types.TokenMetadata {
+	iterator := sdk.KVStorePrefixIterator(ctx.KVStore(k.storeKey), types.KeyPrefixTokenRegistry)
+	defer iterator.Close()
+
+	var registry []types.TokenMetadata
+	for ; iterator.Valid(); iterator.Next() {
+		var metadata types.TokenMetadata
+		k.cdc.MustUnmarshalBinaryLengthPrefixed(iterator.Value(), &metadata)
+		registry = append(registry, metadata)
+	}
+	return registry
+}
@@ -41,8 +41,7 @@ func (AppModuleBasic) DefaultGenesis() json.RawMessage {
 
 // ValidateGenesis is the validation check of the Genesis
 func (AppModuleBasic) ValidateGenesis(bz json.RawMessage) error {
-	var genesisState types.GenesisState
-	err := types.ModuleCdc.UnmarshalJSON(bz, &genesisState)
+	genesisState, err := types.UnmarshalGenesisState(types.ModuleCdc, bz)
 	if err != nil {
 		return err
 	}
@@ -61,7 +60,7 @@ func (AppModuleBasic) GetQueryCmd(cdc *codec.Codec) *cobra.Command {
 
 // GetTxCmd Gets the root tx command of this module
 func (AppModuleBasic) GetTxCmd(cdc *codec.Codec) *cobra.Command {
-	return nil
+	return cli.GetTxCmd(cdc)
 }
 
 //____________________________________________________________________________
@@ -124,8 +123,10 @@ func (am AppModule) EndBlock(ctx sdk.Context, req abci.RequestEndBlock) []abci.V
 
 // InitGenesis instantiates the genesis state
 func (am AppModule) InitGenesis(ctx sdk.Context, data json.RawMessage) []abci.ValidatorUpdate {
-	var genesisState types.GenesisState
-	types.ModuleCdc.MustUnmarshalJSON(data, &genesisState)
+	genesisState, err := types.UnmarshalGenesisState(types.ModuleCdc, data)
+	if err != nil {
+		panic(err)
+	}
 	return InitGenesis(ctx, *am.keeper, am.ak, genesisState)
 }
 
@@ -1,6 +1,8 @@
 package evm
 
 import (
+	"strconv"
+
 	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
 	"github.com/okex/exchain/x/common"
 	"github.com/okex/exchain/x/evm/types"
@@ -17,6 +19,12 @@ func NewManageContractDeploymentWhitelistProposalHandler(k *Keeper) govTypes.Han
 			return handleManageContractBlockedlListProposal(ctx, k, proposal)
 		case types.ManageContractMethodBlockedListProposal:
 			return handleManageContractMethodBlockedlListProposal(ctx, k, proposal)
+		case types.ManageChainConfigForksProposal:
+			return handleManageChainConfigForksProposal(ctx, k, proposal)
+		case types.ManageTokenRegistryProposal:
+			return handleManageTokenRegistryProposal(ctx, k, proposal)
+		case types.ManageContractGasQuotaProposal:
+			return handleManageContractGasQuotaProposal(ctx, k, proposal)
 		default:
 			return common.ErrUnknownProposalType(types.DefaultCodespace, content.ProposalType())
 		}
@@ -77,3 +85,87 @@ func handleManageContractMethodBlockedlListProposal(ctx sdk.Context, k *Keeper,
 	// remove contract method from blocked list
 	return csdb.DeleteContractMethodBlockedList(manageContractMethodBlockedListProposal.ContractList)
 }
+
+func handleManageChainConfigForksProposal(ctx sdk.Context, k *Keeper, proposal *govTypes.Proposal) sdk.Error {
+	// check
+	manageChainConfigForksProposal, ok := proposal.Content.(types.ManageChainConfigForksProposal)
+	if !ok {
+		return types.ErrUnexpectedProposalType
+	}
+
+	config, found := k.GetChainConfig(ctx)
+	if !found {
+		return types.ErrChainConfigNotFound
+	}
+
+	config, err := manageChainConfigForksProposal.UpdateForks(config, ctx.BlockHeight())
+	if err != nil {
+		return err
+	}
+
+	k.SetChainConfig(ctx, config)
+	return nil
+}
+
+func handleManageTokenRegistryProposal(ctx sdk.Context, k *Keeper, proposal *govTypes.Proposal) sdk.Error {
+	// check
+	manageTokenRegistryProposal, ok := proposal.Content.(types.ManageTokenRegistryProposal)
+	if !ok {
+		return types.ErrUnexpectedProposalType
+	}
+
+	for _, token := range manageTokenRegistryProposal.Tokens {
+		if manageTokenRegistryProposal.IsAdded {
+			k.SetTokenMetadata(ctx, token)
+			ctx.EventManager().EmitEvent(sdk.NewEvent(
+				types.EventTypeSetTokenMetadata,
+				sdk.NewAttribute(types.AttributeKeyDenom, token.Denom),
+				sdk.NewAttribute(types.AttributeKeySymbol, token.Symbol),
+				sdk.NewAttribute(types.AttributeKeyOriginChain, token.OriginChain),
+			))
+			continue
+		}
+
+		// the tokens deleted in the meantime are skipped
+		if _, found := k.GetTokenMetadata(ctx, token.Denom); !found {
+			continue
+		}
+		k.DeleteTokenMetadata(ctx, token.Denom)
+		ctx.EventManager().EmitEvent(sdk.NewEvent(
+			types.EventTypeDeleteTokenMetadata,
+			sdk.NewAttribute(types.AttributeKeyDenom, token.Denom),
+		))
+	}
+	return nil
+}
+
+func handleManageContractGasQuotaProposal(ctx sdk.Context, k *Keeper, proposal *govTypes.Proposal) sdk.Error {
+	// check
+	manageContractGasQuotaProposal, ok := proposal.Content.(types.ManageContractGasQuotaProposal)
+	if !ok {
+		return types.ErrUnexpectedProposalType
+	}
+
+	for _, quota := range manageContractGasQuotaProposal.Quotas {
+		if manageContractGasQuotaProposal.IsAdded {
+			k.SetContractGasQuota(ctx, quota)
+			ctx.EventManager().EmitEvent(sdk.NewEvent(
+				types.EventTypeSetContractGasQuota,
+				sdk.NewAttribute(types.AttributeKeyContractAddress, quota.Address.String()),
+				sdk.NewAttribute(types.AttributeKeyGasPerBlock, strconv.FormatUint(quota.GasPerBlock, 10)),
+			))
+			continue
+		}
+
+		// the quotas deleted in the meantime are skipped
+		if _, found := k.GetContractGasQuota(ctx, quota.Address); !found {
+			continue
+		}
+		k.DeleteContractGasQuota(ctx, quota.Address)
+		ctx.EventManager().EmitEvent(sdk.NewEvent(
+			types.EventTypeDeleteContractGasQuota,
+			sdk.NewAttribute(types.AttributeKeyContractAddress, quota.Address.String()),
+		))
+	}
+	return nil
+}
@@ -1,7 +1,10 @@
 package evm_test
 
 import (
+	"math/big"
+
 	ethcmn "github.com/ethereum/go-ethereum/common"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
 	"github.com/okex/exchain/x/evm"
 	"github.com/okex/exchain/x/evm/types"
 	govtypes "github.com/okex/exchain/x/gov/types"
@@ -343,3 +346,137 @@ func (suite *EvmTestSuite) TestProposalHandler_ManageContractMethodBlockedListPr
 		})
 	}
 }
+
+func (suite *EvmTestSuite) TestProposalHandler_ManageChainConfigForksProposal() {
+	suite.govHandler = evm.NewManageContractDeploymentWhitelistProposalHandler(suite.app.EvmKeeper)
+	suite.ctx = suite.ctx.WithBlockHeight(10)
+
+	// scheduling a fork at the current height is rejected
+	proposal := types.NewManageChainConfigForksProposal("default title", "default description", sdk.NewInt(10), sdk.NewInt(-1),
+		sdk.NewInt(-1))
+	suite.Require().Error(suite.app.EvmKeeper.CheckMsgSubmitProposal(suite.ctx, govtypes.NewMsgSubmitProposal(proposal, nil, nil)))
+	suite.Require().Error(suite.govHandler(suite.ctx, &govtypes.Proposal{Content: proposal}))
+
+	proposal = types.NewManageChainConfigForksProposal("default title", "default description", sdk.NewInt(20), sdk.NewInt(30),
+		sdk.NewInt(40))
+	suite.Require().NoError(suite.app.EvmKeeper.CheckMsgSubmitProposal(suite.ctx, govtypes.NewMsgSubmitProposal(proposal, nil, nil)))
+	suite.Require().NoError(suite.govHandler(suite.ctx, &govtypes.Proposal{Content: proposal}))
+
+	config, found := suite.app.EvmKeeper.GetChainConfig(suite.ctx)
+	suite.Require().True(found)
+	suite.Require().Equal(sdk.NewInt(20), config.BerlinBlock)
+	suite.Require().Equal(sdk.NewInt(30), config.LondonBlock)
+	suite.Require().Equal(sdk.NewInt(40), config.ShanghaiBlock)
+
+	// BASEFEE ISZERO PUSH1 0 SSTORE STOP, only valid once london is activated
+	contract := ethcmn.BytesToAddress([]byte("contract"))
+	sender := ethcmn.BytesToAddress([]byte("sender"))
+	csdb := types.CreateEmptyCommitStateDB(suite.app.EvmKeeper.GenerateCSDBParams(), suite.ctx)
+	csdb.SetCode(contract, []byte{0x48, 0x15, 0x60, 0x00, 0x55, 0x00})
+	suite.Require().NoError(csdb.Finalise(true))
+	_, err := csdb.Commit(true)
+	suite.Require().NoError(err)
+
+	// PUSH1 1 PUSH0 SSTORE STOP, only valid once shanghai is activated
+	push0Contract := ethcmn.BytesToAddress([]byte("push0Contract"))
+	csdb.SetCode(push0Contract, []byte{0x60, 0x01, 0x5f, 0x55, 0x00})
+	suite.Require().NoError(csdb.Finalise(true))
+	_, err = csdb.Commit(true)
+	suite.Require().NoError(err)
+
+	for _, tc := range []struct {
+		contract ethcmn.Address
+		height   int64
+		expErr   bool
+	}{
+		{contract, 29, true},
+		{contract, 30, false},
+		{push0Contract, 39, true},
+		{push0Contract, 40, false},
+	} {
+		contract := tc.contract
+		ctx := suite.ctx.WithBlockHeight(tc.height).WithGasMeter(sdk.NewInfiniteGasMeter())
+		st := types.StateTransition{
+			GasLimit:  1000000,
+			Recipient: &contract,
+			Amount:    big.NewInt(0),
+			Price:     big.NewInt(1),
+			ChainID:   big.NewInt(1),
+			Csdb:      types.CreateEmptyCommitStateDB(suite.app.EvmKeeper.GenerateCSDBParams(), ctx),
+			TxHash:    &ethcmn.Hash{},
+			Sender:    sender,
+		}
+		_, _, err, _, _ := st.TransitionDb(ctx, config)
+		suite.Require().Equal(tc.expErr, err != nil, "height %d", tc.height)
+	}
+
+	// berlin is activated at 20, it can't be moved anymore
+	proposal.BerlinBlock = sdk.NewInt(40)
+	proposal.LondonBlock = sdk.NewInt(40)
+	proposal.ShanghaiBlock = sdk.NewInt(50)
+	ctx := suite.ctx.WithBlockHeight(25)
+	suite.Require().Error(suite.app.EvmKeeper.CheckMsgSubmitProposal(ctx, govtypes.NewMsgSubmitProposal(proposal, nil, nil)))
+	suite.Require().Error(suite.govHandler(ctx, &govtypes.Proposal{Content: proposal}))
+}
+
+func (suite *EvmTestSuite) TestProposalHandler_ManageTokenRegistryProposal() {
+	suite.govHandler = evm.NewManageContractDeploymentWhitelistProposalHandler(suite.app.EvmKeeper)
+	atom := types.TokenMetadata{
+		Denom:       "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2",
+		Name:        "Cosmos Hub Atom",
+		Symbol:      "ATOM",
+		Decimals:    6,
+		OriginChain: "cosmoshub-4",
+		OriginDenom: "uatom",
+	}
+	usdc := types.TokenMetadata{Denom: "bridge/usdc", Symbol: "USDC", Decimals: 6, OriginChain: "1"}
+
+	// a token which isn't in the registry can't be deleted
+	proposal := types.NewManageTokenRegistryProposal("default title", "default description", []types.TokenMetadata{atom}, false)
+	suite.Require().Error(suite.app.EvmKeeper.CheckMsgSubmitProposal(suite.ctx, govtypes.NewMsgSubmitProposal(proposal, nil, nil)))
+
+	proposal = types.NewManageTokenRegistryProposal("default title", "default description", []types.TokenMetadata{usdc, atom}, true)
+	suite.Require().NoError(suite.app.EvmKeeper.CheckMsgSubmitProposal(suite.ctx, govtypes.NewMsgSubmitProposal(proposal, nil, nil)))
+	suite.Require().NoError(suite.govHandler(suite.ctx, &govtypes.Proposal{Content: proposal}))
+	suite.Require().Equal([]types.TokenMetadata{usdc, atom}, suite.app.EvmKeeper.GetTokenRegistry(suite.ctx))
+	metadata, found := suite.app.EvmKeeper.GetTokenMetadata(suite.ctx, atom.Denom)
+	suite.Require().True(found)
+	suite.Require().Equal(atom, metadata)
+
+	events := suite.ctx.EventManager().Events()
+	suite.Require().Equal(types.EventTypeSetTokenMetadata, events[len(events)-1].Type)
+
+	proposal = types.NewManageTokenRegistryProposal("default title", "default description", []types.TokenMetadata{{Denom: atom.Denom}}, false)
+	suite.Require().NoError(suite.app.EvmKeeper.CheckMsgSubmitProposal(suite.ctx, govtypes.NewMsgSubmitProposal(proposal, nil, nil)))
+	suite.Require().NoError(suite.govHandler(suite.ctx, &govtypes.Proposal{Content: proposal}))
+	suite.Require().Equal([]types.TokenMetadata{usdc}, suite.app.EvmKeeper.GetTokenRegistry(suite.ctx))
+
+	events = suite.ctx.EventManager().Events()
+	suite.Require().Equal(types.EventTypeDeleteTokenMetadata, events[len(events)-1].Type)
+}
+
+func (suite *EvmTestSuite) TestProposalHandler_ManageContractGasQuotaProposal() {
+	suite.govHandler = evm.NewManageContractDeploymentWhitelistProposalHandler(suite.app.EvmKeeper)
+	contract1, contract2 := sdk.AccAddress(ethcmn.BytesToAddress([]byte{0x1}).Bytes()), sdk.AccAddress(ethcmn.BytesToAddress([]byte{0x2}).Bytes())
+	quota1, quota2 := types.NewContractGasQuota(contract1, 1000000), types.NewContractGasQuota(contract2, 50000)
+
+	// a quota which doesn't exist can't be deleted
+	proposal := types.NewManageContractGasQuotaProposal("default title", "default description", []types.ContractGasQuota{quota1}, false)
+	suite.Require().Error(suite.app.EvmKeeper.CheckMsgSubmitProposal(suite.ctx, govtypes.NewMsgSubmitProposal(proposal, nil, nil)))
+
+	proposal = types.NewManageContractGasQuotaProposal("default title", "default description", []types.ContractGasQuota{quota2, quota1}, true)
+	suite.Require().NoError(suite.app.EvmKeeper.CheckMsgSubmitProposal(suite.ctx, govtypes.NewMsgSubmitProposal(proposal, nil, nil)))
+	suite.Require().NoError(suite.govHandler(suite.ctx, &govtypes.Proposal{Content: proposal}))
+	suite.Require().Equal([]types.ContractGasQuota{quota1, quota2}, suite.app.EvmKeeper.GetContractGasQuotas(suite.ctx))
+
+	events := suite.ctx.EventManager().Events()
+	suite.Require().Equal(types.EventTypeSetContractGasQuota, events[len(events)-1].Type)
+
+	proposal = types.NewManageContractGasQuotaProposal("default title", "default description", []types.ContractGasQuota{{Address: contract1}}, false)
+	suite.Require().NoError(suite.app.EvmKeeper.CheckMsgSubmitProposal(suite.ctx, govtypes.NewMsgSubmitProposal(proposal, nil, nil)))
+	suite.Require().NoError(suite.govHandler(suite.ctx, &govtypes.Proposal{Content: proposal}))
+	suite.Require().Equal([]types.ContractGasQuota{quota2}, suite.app.EvmKeeper.GetContractGasQuotas(suite.ctx))
+
+	events = suite.ctx.EventManager().Events()
+	suite.Require().Equal(types.EventTypeDeleteContractGasQuota, events[len(events)-1].Type)
+}
@@ -55,7 +55,7 @@ const (
 	// sections. It's useful during chain upgrades to prevent disk overload.
 	bloomThrottling = 100 * time.Millisecond
 
-	bloomDir              = "bloom"
+	BloomDir              = "bloom"
 	FlagEnableBloomFilter = "enable-bloom-filter"
 )
 
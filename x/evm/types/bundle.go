@@ -0,0 +1,131 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// MaxBundleTxs is the maximum number of txs simulated by a bundle query
+const MaxBundleTxs = 256
+
+// SimulateBundleRequest is the request of the bundle simulation query. Its txs are executed in order on top of
+// the queried state once the overrides are applied, each one seeing the changes of the previous ones.
+type SimulateBundleRequest struct {
+	Txs            []BundleTx                         `json:"txs"`
+	StateOverrides map[common.Address]AccountOverride `json:"stateOverrides,omitempty"`
+	BlockOverrides *BlockOverrides                    `json:"blockOverrides,omitempty"`
+}
+
+// BundleTx is either a signed raw evm tx or the arguments of a call, the signature and the nonce of a call
+// aren't checked.
+type BundleTx struct {
+	Raw hexutil.Bytes `json:"raw,omitempty"`
+
+	From     *common.Address `json:"from,omitempty"`
+	To       *common.Address `json:"to,omitempty"`
+	Gas      *hexutil.Uint64 `json:"gas,omitempty"`
+	GasPrice *hexutil.Big    `json:"gasPrice,omitempty"`
+	Value    *hexutil.Big    `json:"value,omitempty"`
+	Data     *hexutil.Bytes  `json:"data,omitempty"`
+}
+
+// AccountOverride replaces the fields of an account before the bundle is simulated. State replaces the whole
+// storage of the account while StateDiff only replaces the given slots.
+type AccountOverride struct {
+	Nonce     *hexutil.Uint64             `json:"nonce,omitempty"`
+	Code      *hexutil.Bytes              `json:"code,omitempty"`
+	Balance   *hexutil.Big                `json:"balance,omitempty"`
+	State     map[common.Hash]common.Hash `json:"state,omitempty"`
+	StateDiff map[common.Hash]common.Hash `json:"stateDiff,omitempty"`
+}
+
+// BlockOverrides replaces the fields of the block the bundle is simulated in. The coinbase is overridden
+// through the proposer of the block.
+type BlockOverrides struct {
+	Number   *hexutil.Big    `json:"number,omitempty"`
+	Time     *hexutil.Uint64 `json:"time,omitempty"`
+	Coinbase *common.Address `json:"coinbase,omitempty"`
+}
+
+// Validate performs a stateless validation of the request
+func (req SimulateBundleRequest) Validate() error {
+	if len(req.Txs) == 0 {
+		return errors.New("empty bundle")
+	}
+	if len(req.Txs) > MaxBundleTxs {
+		return fmt.Errorf("too many txs in the bundle: %d, limit %d", len(req.Txs), MaxBundleTxs)
+	}
+	for i, tx := range req.Txs {
+		if len(tx.Raw) != 0 && (tx.From != nil || tx.To != nil || tx.Gas != nil || tx.GasPrice != nil ||
+			tx.Value != nil || tx.Data != nil) {
+			return fmt.Errorf("tx %d: a raw tx can't have call arguments", i)
+		}
+	}
+	for addr, override := range req.StateOverrides {
+		if override.State != nil && override.StateDiff != nil {
+			return fmt.Errorf("account %s: both state and stateDiff are overridden", addr.Hex())
+		}
+	}
+	if req.BlockOverrides != nil && req.BlockOverrides.Number != nil {
+		if number := req.BlockOverrides.Number.ToInt(); number.Sign() <= 0 || !number.IsInt64() {
+			return fmt.Errorf("invalid block number override: %s", number)
+		}
+	}
+	return nil
+}
+
+// BundleTxResult is the result of a tx of the bundle. Error is set when the tx failed, its state changes are
+// then dropped except for the nonce of the sender.
+type BundleTxResult struct {
+	TxHash          common.Hash     `json:"txHash"`
+	GasUsed         hexutil.Uint64  `json:"gasUsed"`
+	ReturnData      hexutil.Bytes   `json:"returnData"`
+	ContractAddress *common.Address `json:"contractAddress,omitempty"`
+	Logs            []*ethtypes.Log `json:"logs"`
+	Error           string          `json:"error,omitempty"`
+	StateDiff       StateDiff       `json:"stateDiff"`
+}
+
+// StateDiff is the changes made to the accounts by a tx
+type StateDiff map[common.Address]*AccountDiff
+
+// AccountDiff is the changes made to an account, the fields left unchanged are nil
+type AccountDiff struct {
+	Balance *BalanceDiff                 `json:"balance,omitempty"`
+	Nonce   *NonceDiff                   `json:"nonce,omitempty"`
+	Code    *CodeDiff                    `json:"code,omitempty"`
+	Storage map[common.Hash]*StorageDiff `json:"storage,omitempty"`
+}
+
+// empty returns true if the account is unchanged
+func (diff AccountDiff) empty() bool {
+	return diff.Balance == nil && diff.Nonce == nil && diff.Code == nil && len(diff.Storage) == 0
+}
+
+// BalanceDiff is the change of the balance of an account
+type BalanceDiff struct {
+	From *hexutil.Big `json:"from"`
+	To   *hexutil.Big `json:"to"`
+}
+
+// NonceDiff is the change of the nonce of an account
+type NonceDiff struct {
+	From hexutil.Uint64 `json:"from"`
+	To   hexutil.Uint64 `json:"to"`
+}
+
+// CodeDiff is the change of the code of an account
+type CodeDiff struct {
+	From hexutil.Bytes `json:"from"`
+	To   hexutil.Bytes `json:"to"`
+}
+
+// StorageDiff is the change of a storage slot of an account
+type StorageDiff struct {
+	From common.Hash `json:"from"`
+	To   common.Hash `json:"to"`
+}
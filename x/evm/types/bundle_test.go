@@ -0,0 +1,58 @@
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulateBundleRequestValidate(t *testing.T) {
+	addr := common.BytesToAddress([]byte("addr"))
+	call := BundleTx{From: &addr, To: &addr}
+	raw := BundleTx{Raw: hexutil.Bytes{0x1}}
+
+	testCases := []struct {
+		name    string
+		req     SimulateBundleRequest
+		expPass bool
+	}{
+		{"calls and raw txs", SimulateBundleRequest{Txs: []BundleTx{call, raw}}, true},
+		{"empty bundle", SimulateBundleRequest{}, false},
+		{"too many txs", SimulateBundleRequest{Txs: make([]BundleTx, MaxBundleTxs+1)}, false},
+		{"raw tx with call arguments", SimulateBundleRequest{Txs: []BundleTx{{Raw: raw.Raw, To: &addr}}}, false},
+		{
+			"state and state diff overrides",
+			SimulateBundleRequest{
+				Txs: []BundleTx{call},
+				StateOverrides: map[common.Address]AccountOverride{
+					addr: {State: map[common.Hash]common.Hash{}, StateDiff: map[common.Hash]common.Hash{}},
+				},
+			},
+			false,
+		},
+		{
+			"block number override",
+			SimulateBundleRequest{Txs: []BundleTx{call}, BlockOverrides: &BlockOverrides{Number: (*hexutil.Big)(big.NewInt(1))}},
+			true,
+		},
+		{
+			"zero block number override",
+			SimulateBundleRequest{Txs: []BundleTx{call}, BlockOverrides: &BlockOverrides{Number: (*hexutil.Big)(big.NewInt(0))}},
+			false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.req.Validate()
+			if tc.expPass {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"math/big"
 	"strings"
 
+	"github.com/okex/exchain/libs/cosmos-sdk/codec"
 	"github.com/tendermint/go-amino"
 
 	"gopkg.in/yaml.v2"
@@ -45,6 +46,63 @@ type ChainConfig struct {
 
 	YoloV2Block sdk.Int `json:"yoloV2_block" yaml:"yoloV2_block"` // YOLO v1: https://github.com/ethereum/EIPs/pull/2657 (Ephemeral testnet)
 	EWASMBlock  sdk.Int `json:"ewasm_block" yaml:"ewasm_block"`   // EWASM switch block (< 0 no fork, 0 = already activated)
+
+	BerlinBlock sdk.Int `json:"berlin_block" yaml:"berlin_block"` // Berlin switch block, EIP-2929 access costs (< 0 no fork, 0 = already on berlin)
+	// London switch block (< 0 no fork, 0 = already on london). It enables the evm rules of London: the reduced
+	// refunds of EIP-3529, the BASEFEE opcode of EIP-3198 (which returns the base_fee param, as there is no fee
+	// market) and the rejection of new code starting with 0xEF of EIP-3541.
+	LondonBlock sdk.Int `json:"london_block" yaml:"london_block"`
+	// Shanghai switch block (< 0 no fork, 0 = already on shanghai). It enables the PUSH0 opcode of EIP-3855, the
+	// initcode size limit and metering of EIP-3860 and the warm coinbase of EIP-3651.
+	ShanghaiBlock sdk.Int `json:"shanghai_block" yaml:"shanghai_block"`
+}
+
+// legacyChainConfig is the layout of ChainConfig before the berlin, london and shanghai switches were added.
+type legacyChainConfig struct {
+	HomesteadBlock      sdk.Int
+	DAOForkBlock        sdk.Int
+	DAOForkSupport      bool
+	EIP150Block         sdk.Int
+	EIP150Hash          string
+	EIP155Block         sdk.Int
+	EIP158Block         sdk.Int
+	ByzantiumBlock      sdk.Int
+	ConstantinopleBlock sdk.Int
+	PetersburgBlock     sdk.Int
+	IstanbulBlock       sdk.Int
+	MuirGlacierBlock    sdk.Int
+	YoloV2Block         sdk.Int
+	EWASMBlock          sdk.Int
+}
+
+// MarshalChainConfig encodes the config to be stored. As long as none of berlin, london and shanghai is activated, the
+// legacy layout is used, so that the stored bytes (and the gas consumed to read them) stay the same as before
+// the forks were introduced.
+func MarshalChainConfig(cdc *codec.Codec, config ChainConfig) []byte {
+	bz := cdc.MustMarshalBinaryBare(config)
+	if getBlockValue(config.BerlinBlock) != nil || getBlockValue(config.LondonBlock) != nil ||
+		getBlockValue(config.ShanghaiBlock) != nil {
+		return bz
+	}
+
+	legacy := cdc.MustMarshalBinaryBare(legacyChainConfig{
+		HomesteadBlock:      config.HomesteadBlock,
+		DAOForkBlock:        config.DAOForkBlock,
+		DAOForkSupport:      config.DAOForkSupport,
+		EIP150Block:         config.EIP150Block,
+		EIP150Hash:          config.EIP150Hash,
+		EIP155Block:         config.EIP155Block,
+		EIP158Block:         config.EIP158Block,
+		ByzantiumBlock:      config.ByzantiumBlock,
+		ConstantinopleBlock: config.ConstantinopleBlock,
+		PetersburgBlock:     config.PetersburgBlock,
+		IstanbulBlock:       config.IstanbulBlock,
+		MuirGlacierBlock:    config.MuirGlacierBlock,
+		YoloV2Block:         config.YoloV2Block,
+		EWASMBlock:          config.EWASMBlock,
+	})
+	// keep the type prefix of ChainConfig
+	return append(bz[:4:4], legacy...)
 }
 
 // EthereumConfig returns an Ethereum ChainConfig for EVM state transitions.
@@ -64,6 +122,8 @@ func (cc ChainConfig) EthereumConfig(chainID *big.Int) *params.ChainConfig {
 		PetersburgBlock:     getBlockValue(cc.PetersburgBlock),
 		IstanbulBlock:       getBlockValue(cc.IstanbulBlock),
 		MuirGlacierBlock:    getBlockValue(cc.MuirGlacierBlock),
+		BerlinBlock:         getBlockValue(cc.BerlinBlock),
+		LondonBlock:         getBlockValue(cc.LondonBlock),
 	}
 }
 
@@ -77,6 +137,18 @@ func (cc ChainConfig) IsHomestead() bool {
 	return getBlockValue(cc.HomesteadBlock) != nil
 }
 
+// IsLondon returns whether the London version is activated at the given height.
+func (cc ChainConfig) IsLondon(height int64) bool {
+	london := getBlockValue(cc.LondonBlock)
+	return london != nil && london.Cmp(big.NewInt(height)) <= 0
+}
+
+// IsShanghai returns whether the Shanghai version is activated at the given height.
+func (cc ChainConfig) IsShanghai(height int64) bool {
+	shanghai := getBlockValue(cc.ShanghaiBlock)
+	return shanghai != nil && shanghai.Cmp(big.NewInt(height)) <= 0
+}
+
 // String implements the fmt.Stringer interface
 func (cc ChainConfig) String() string {
 	out, _ := yaml.Marshal(cc)
@@ -100,11 +172,31 @@ func DefaultChainConfig() ChainConfig {
 		MuirGlacierBlock:    sdk.ZeroInt(),
 		YoloV2Block:         sdk.NewInt(-1),
 		EWASMBlock:          sdk.NewInt(-1),
+		BerlinBlock:         sdk.NewInt(-1),
+		LondonBlock:         sdk.NewInt(-1),
+		ShanghaiBlock:       sdk.NewInt(-1),
 	}
 }
 
+// disableMissingForks sets the forks which are missing from a config decoded with the legacy layout to a
+// negative value. Uninitialized Ints are encoded as zero, which would activate the forks from genesis once the
+// config is exported and imported again.
+func (cc *ChainConfig) disableMissingForks() {
+	if cc.BerlinBlock.IsNil() {
+		cc.BerlinBlock = sdk.NewInt(-1)
+	}
+	if cc.LondonBlock.IsNil() {
+		cc.LondonBlock = sdk.NewInt(-1)
+	}
+	if cc.ShanghaiBlock.IsNil() {
+		cc.ShanghaiBlock = sdk.NewInt(-1)
+	}
+}
+
+// getBlockValue returns nil for negative values. Uninitialized values are also treated as nil, since
+// chain configs stored before a fork field existed don't carry that field.
 func getBlockValue(block sdk.Int) *big.Int {
-	if block.IsNegative() {
+	if block.IsNil() || block.IsNegative() {
 		return nil
 	}
 
@@ -153,6 +245,18 @@ func (cc ChainConfig) Validate() error {
 	if err := validateBlock(cc.EWASMBlock); err != nil {
 		return sdkerrors.Wrap(err, "eWASMBlock")
 	}
+	if err := validateForkBlock(cc.BerlinBlock); err != nil {
+		return sdkerrors.Wrap(err, "berlinBlock")
+	}
+	if err := validateForkBlock(cc.LondonBlock); err != nil {
+		return sdkerrors.Wrap(err, "londonBlock")
+	}
+	if err := validateForkBlock(cc.ShanghaiBlock); err != nil {
+		return sdkerrors.Wrap(err, "shanghaiBlock")
+	}
+	if err := cc.checkForkOrder(); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -168,11 +272,11 @@ func (config *ChainConfig) UnmarshalFromAmino(data []byte) error {
 			break
 		}
 
-		pos, aminoType, err := amino.ParseProtoPosAndTypeMustOneByte(data[0])
+		pos, aminoType, n, err := parseProtoPosAndType(data)
 		if err != nil {
 			return err
 		}
-		data = data[1:]
+		data = data[n:]
 
 		if aminoType == amino.Typ3_ByteLength {
 			var n int
@@ -265,13 +369,42 @@ func (config *ChainConfig) UnmarshalFromAmino(data []byte) error {
 				return err
 			}
 			config.EWASMBlock = integer
+		case 15:
+			integer, err := sdk.NewIntFromAmino(subData)
+			if err != nil {
+				return err
+			}
+			config.BerlinBlock = integer
+		case 16:
+			integer, err := sdk.NewIntFromAmino(subData)
+			if err != nil {
+				return err
+			}
+			config.LondonBlock = integer
+		case 17:
+			integer, err := sdk.NewIntFromAmino(subData)
+			if err != nil {
+				return err
+			}
+			config.ShanghaiBlock = integer
 		default:
 			return fmt.Errorf("unexpect feild num %d", pos)
 		}
 	}
+	config.disableMissingForks()
 	return nil
 }
 
+// parseProtoPosAndType decodes the key of a field. Unlike amino.ParseProtoPosAndTypeMustOneByte, it
+// supports the field numbers above 15, whose keys are encoded on more than one byte.
+func parseProtoPosAndType(data []byte) (pos int, aminoType amino.Typ3, n int, err error) {
+	key, n, err := amino.DecodeUvarint(data)
+	if err != nil {
+		return 0, 0, n, err
+	}
+	return int(key >> 3), amino.Typ3(key & 0x07), n, nil
+}
+
 func validateHash(hex string) error {
 	if hex != "" && strings.TrimSpace(hex) == "" {
 		return sdkerrors.Wrapf(ErrInvalidChainConfig, "hash cannot be blank")
@@ -296,3 +429,26 @@ func validateBlock(block sdk.Int) error {
 
 	return nil
 }
+
+// validateForkBlock is like validateBlock but accepts uninitialized values, which are treated as a
+// disabled fork so that chain configs exported before the fork field was introduced stay valid.
+func validateForkBlock(block sdk.Int) error {
+	if block.IsNil() {
+		return nil
+	}
+	return validateBlock(block)
+}
+
+// checkForkOrder makes sure that London is never activated before Berlin, as the London gas rules
+// are defined on top of the EIP-2929 access lists, and that Shanghai is never activated before London.
+func (cc ChainConfig) checkForkOrder() error {
+	berlin, london := getBlockValue(cc.BerlinBlock), getBlockValue(cc.LondonBlock)
+	if london != nil && (berlin == nil || berlin.Cmp(london) > 0) {
+		return sdkerrors.Wrapf(ErrInvalidChainConfig, "london block %s must not be activated before berlin block", london)
+	}
+	shanghai := getBlockValue(cc.ShanghaiBlock)
+	if shanghai != nil && (london == nil || london.Cmp(shanghai) > 0) {
+		return sdkerrors.Wrapf(ErrInvalidChainConfig, "shanghai block %s must not be activated before london block", shanghai)
+	}
+	return nil
+}
@@ -212,6 +212,91 @@ func TestChainConfigValidate(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"valid without berlin and london",
+			ChainConfig{
+				HomesteadBlock:      sdk.OneInt(),
+				DAOForkBlock:        sdk.OneInt(),
+				EIP150Block:         sdk.OneInt(),
+				EIP150Hash:          defaultEIP150Hash,
+				EIP155Block:         sdk.OneInt(),
+				EIP158Block:         sdk.OneInt(),
+				ByzantiumBlock:      sdk.OneInt(),
+				ConstantinopleBlock: sdk.OneInt(),
+				PetersburgBlock:     sdk.OneInt(),
+				IstanbulBlock:       sdk.OneInt(),
+				MuirGlacierBlock:    sdk.OneInt(),
+				YoloV2Block:         sdk.OneInt(),
+				EWASMBlock:          sdk.OneInt(),
+				BerlinBlock:         sdk.Int{},
+				LondonBlock:         sdk.NewInt(-1),
+			},
+			false,
+		},
+		{
+			"london before berlin",
+			ChainConfig{
+				HomesteadBlock:      sdk.OneInt(),
+				DAOForkBlock:        sdk.OneInt(),
+				EIP150Block:         sdk.OneInt(),
+				EIP150Hash:          defaultEIP150Hash,
+				EIP155Block:         sdk.OneInt(),
+				EIP158Block:         sdk.OneInt(),
+				ByzantiumBlock:      sdk.OneInt(),
+				ConstantinopleBlock: sdk.OneInt(),
+				PetersburgBlock:     sdk.OneInt(),
+				IstanbulBlock:       sdk.OneInt(),
+				MuirGlacierBlock:    sdk.OneInt(),
+				YoloV2Block:         sdk.OneInt(),
+				EWASMBlock:          sdk.OneInt(),
+				BerlinBlock:         sdk.NewInt(10),
+				LondonBlock:         sdk.NewInt(5),
+			},
+			true,
+		},
+		{
+			"london without berlin",
+			ChainConfig{
+				HomesteadBlock:      sdk.OneInt(),
+				DAOForkBlock:        sdk.OneInt(),
+				EIP150Block:         sdk.OneInt(),
+				EIP150Hash:          defaultEIP150Hash,
+				EIP155Block:         sdk.OneInt(),
+				EIP158Block:         sdk.OneInt(),
+				ByzantiumBlock:      sdk.OneInt(),
+				ConstantinopleBlock: sdk.OneInt(),
+				PetersburgBlock:     sdk.OneInt(),
+				IstanbulBlock:       sdk.OneInt(),
+				MuirGlacierBlock:    sdk.OneInt(),
+				YoloV2Block:         sdk.OneInt(),
+				EWASMBlock:          sdk.OneInt(),
+				BerlinBlock:         sdk.NewInt(-1),
+				LondonBlock:         sdk.NewInt(5),
+			},
+			true,
+		},
+		{
+			"shanghai before london",
+			ChainConfig{
+				HomesteadBlock:      sdk.OneInt(),
+				DAOForkBlock:        sdk.OneInt(),
+				EIP150Block:         sdk.OneInt(),
+				EIP150Hash:          defaultEIP150Hash,
+				EIP155Block:         sdk.OneInt(),
+				EIP158Block:         sdk.OneInt(),
+				ByzantiumBlock:      sdk.OneInt(),
+				ConstantinopleBlock: sdk.OneInt(),
+				PetersburgBlock:     sdk.OneInt(),
+				IstanbulBlock:       sdk.OneInt(),
+				MuirGlacierBlock:    sdk.OneInt(),
+				YoloV2Block:         sdk.OneInt(),
+				EWASMBlock:          sdk.OneInt(),
+				BerlinBlock:         sdk.NewInt(5),
+				LondonBlock:         sdk.NewInt(10),
+				ShanghaiBlock:       sdk.NewInt(5),
+			},
+			true,
+		},
 		{
 			"invalid hash",
 			ChainConfig{
@@ -259,6 +344,9 @@ istanbul_block: "0"
 muir_glacier_block: "0"
 yoloV2_block: "-1"
 ewasm_block: "-1"
+berlin_block: "-1"
+london_block: "-1"
+shanghai_block: "-1"
 `
 	require.Equal(t, configStr, DefaultChainConfig().String())
 }
@@ -5,20 +5,31 @@ import (
 
 	"github.com/okex/exchain/libs/cosmos-sdk/codec"
 	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	"github.com/okex/exchain/x/evm/evmproto"
 	"github.com/tendermint/go-amino"
 )
 
 // ModuleCdc defines the evm module's codec
 var ModuleCdc = codec.New()
 
+// ModuleRegistry resolves the evm messages packed into Any in the protobuf encoding of the txs
+var ModuleRegistry = NewMsgRegistry()
+
 const (
 	MsgEthereumTxName = "ethermint/MsgEthereumTx"
 	ChainConfigName   = "ethermint/ChainConfig"
 	MsgEthermintName  = "ethermint/MsgEthermint"
 	TxDataName        = "ethermint/TxData"
 
+	MsgGrantFeeAllowanceName  = "okexchain/evm/MsgGrantFeeAllowance"
+	MsgRevokeFeeAllowanceName = "okexchain/evm/MsgRevokeFeeAllowance"
+	MsgVoteGasPriceName       = "okexchain/evm/MsgVoteGasPrice"
+
 	ManageContractDeploymentWhitelistProposalName = "okexchain/evm/ManageContractDeploymentWhitelistProposal"
 	ManageContractBlockedListProposalName         = "okexchain/evm/ManageContractBlockedListProposal"
+	ManageChainConfigForksProposalName            = "okexchain/evm/ManageChainConfigForksProposal"
+	ManageTokenRegistryProposalName               = "okexchain/evm/ManageTokenRegistryProposal"
+	ManageContractGasQuotaProposalName            = "okexchain/evm/ManageContractGasQuotaProposal"
 )
 
 // RegisterCodec registers all the necessary types and interfaces for the
@@ -27,10 +38,16 @@ func RegisterCodec(cdc *codec.Codec) {
 	cdc.RegisterConcrete(MsgEthereumTx{}, MsgEthereumTxName, nil)
 	cdc.RegisterConcrete(MsgEthermint{}, MsgEthermintName, nil)
 	cdc.RegisterConcrete(TxData{}, TxDataName, nil)
+	cdc.RegisterConcrete(MsgGrantFeeAllowance{}, MsgGrantFeeAllowanceName, nil)
+	cdc.RegisterConcrete(MsgRevokeFeeAllowance{}, MsgRevokeFeeAllowanceName, nil)
+	cdc.RegisterConcrete(MsgVoteGasPrice{}, MsgVoteGasPriceName, nil)
 	cdc.RegisterConcrete(ChainConfig{}, ChainConfigName, nil)
 	cdc.RegisterConcrete(ManageContractDeploymentWhitelistProposal{}, ManageContractDeploymentWhitelistProposalName, nil)
 	cdc.RegisterConcrete(ManageContractBlockedListProposal{}, ManageContractBlockedListProposalName, nil)
 	cdc.RegisterConcrete(ManageContractMethodBlockedListProposal{}, "okexchain/evm/ManageContractMethodBlockedListProposal", nil)
+	cdc.RegisterConcrete(ManageChainConfigForksProposal{}, ManageChainConfigForksProposalName, nil)
+	cdc.RegisterConcrete(ManageTokenRegistryProposal{}, ManageTokenRegistryProposalName, nil)
+	cdc.RegisterConcrete(ManageContractGasQuotaProposal{}, ManageContractGasQuotaProposalName, nil)
 
 	cdc.RegisterConcreteUnmarshaller(ChainConfigName, func(c *amino.Codec, bytes []byte) (interface{}, int, error) {
 		config, n, err := UnmarshalChainConfigFromAmino(c, bytes)
@@ -62,7 +79,14 @@ func RegisterCodec(cdc *codec.Codec) {
 	})
 }
 
+// RegisterInterfaces registers the messages of the module packed into Any, in the form they're registered in
+// the amino codec
+func RegisterInterfaces(registry *MsgRegistry) {
+	registry.RegisterMsg(MsgEthereumTx{}, &evmproto.MsgEthereumTx{}, msgEthereumTxToProto, msgEthereumTxFromProto)
+}
+
 func init() {
+	RegisterInterfaces(ModuleRegistry)
 	RegisterCodec(ModuleCdc)
 	codec.RegisterCrypto(ModuleCdc)
 	ModuleCdc.Seal()
@@ -83,12 +107,12 @@ func UnmarshalChainConfigFromAmino(_ *amino.Codec, data []byte) (*ChainConfig, i
 			break
 		}
 
-		pos, aminoType, err := amino.ParseProtoPosAndTypeMustOneByte(data[0])
+		pos, aminoType, n, err := parseProtoPosAndType(data)
 		if err != nil {
 			return nil, read, err
 		}
-		data = data[1:]
-		read += 1
+		data = data[n:]
+		read += n
 
 		if aminoType == amino.Typ3_ByteLength {
 			var n int
@@ -182,9 +206,28 @@ func UnmarshalChainConfigFromAmino(_ *amino.Codec, data []byte) (*ChainConfig, i
 				return nil, read, err
 			}
 			config.EWASMBlock = integer
+		case 15:
+			integer, err := sdk.NewIntFromAmino(subData)
+			if err != nil {
+				return nil, read, err
+			}
+			config.BerlinBlock = integer
+		case 16:
+			integer, err := sdk.NewIntFromAmino(subData)
+			if err != nil {
+				return nil, read, err
+			}
+			config.LondonBlock = integer
+		case 17:
+			integer, err := sdk.NewIntFromAmino(subData)
+			if err != nil {
+				return nil, read, err
+			}
+			config.ShanghaiBlock = integer
 		default:
 			return nil, read, fmt.Errorf("unexpect feild num %d", pos)
 		}
 	}
+	config.disableMissingForks()
 	return config, read, nil
 }
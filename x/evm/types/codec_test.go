@@ -1,6 +1,7 @@
 package types
 
 import (
+	"encoding/hex"
 	"testing"
 
 	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
@@ -24,6 +25,9 @@ func TestUnmarshalChainConfigFromAmino(t *testing.T) {
 		MuirGlacierBlock:    sdk.ZeroInt(),
 		YoloV2Block:         sdk.OneInt(),
 		EWASMBlock:          sdk.OneInt(),
+		BerlinBlock:         sdk.OneInt(),
+		LondonBlock:         sdk.NewInt(2),
+		ShanghaiBlock:       sdk.NewInt(3),
 	}
 	cdc := amino.NewCodec()
 	RegisterCodec(cdc)
@@ -43,6 +47,46 @@ func TestUnmarshalChainConfigFromAmino(t *testing.T) {
 	require.EqualValues(t, configFromAmino, configFromUnmarshaller)
 }
 
+// defaultChainConfigBytes is the stored encoding of the default chain config before the berlin and london
+// switches were introduced
+const defaultChainConfigBytes = "be5b26f40a013012013018012201302a4230783030303030303030303030303030303030303030303030303030" +
+	"303030303030303030303030303030303030303030303030303030303030303030303030303032013" +
+	"03a01304201304a01305201305a01306201306a022d3172022d31"
+
+func TestMarshalChainConfig(t *testing.T) {
+	cdc := amino.NewCodec()
+	RegisterCodec(cdc)
+
+	// without berlin, london and shanghai the stored bytes are the same as before the forks were introduced
+	config := DefaultChainConfig()
+	data := MarshalChainConfig(cdc, config)
+	require.Equal(t, defaultChainConfigBytes, hex.EncodeToString(data))
+
+	var decoded ChainConfig
+	require.NoError(t, decoded.UnmarshalFromAmino(data[4:]))
+	require.Equal(t, config, decoded)
+	decodedi, err := cdc.UnmarshalBinaryBareWithRegisteredUnmarshaller(data, &ChainConfig{})
+	require.NoError(t, err)
+	require.Equal(t, config, decodedi.(ChainConfig))
+
+	// the forks are only encoded once one of them is activated
+	config.BerlinBlock = sdk.NewInt(10)
+	data = MarshalChainConfig(cdc, config)
+	require.Equal(t, cdc.MustMarshalBinaryBare(config), data)
+	decoded = ChainConfig{}
+	require.NoError(t, decoded.UnmarshalFromAmino(data[4:]))
+	require.Equal(t, config, decoded)
+
+	// as well as when only the shanghai switch is set
+	config.BerlinBlock = sdk.NewInt(-1)
+	config.ShanghaiBlock = sdk.NewInt(10)
+	data = MarshalChainConfig(cdc, config)
+	require.Equal(t, cdc.MustMarshalBinaryBare(config), data)
+	decoded = ChainConfig{}
+	require.NoError(t, decoded.UnmarshalFromAmino(data[4:]))
+	require.Equal(t, config, decoded)
+}
+
 func BenchmarkUnmarshalChainConfigFromAmino(b *testing.B) {
 	config := &ChainConfig{
 		HomesteadBlock:      sdk.OneInt(),
@@ -0,0 +1,71 @@
+package types
+
+import (
+	"fmt"
+
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	sdkerrors "github.com/okex/exchain/libs/cosmos-sdk/types/errors"
+)
+
+// ContractGasQuota is the gas a contract can consume per block, set by governance to contain a contract eating
+// most of the block space. Only the txs calling the contract directly count towards its quota.
+type ContractGasQuota struct {
+	Address     sdk.AccAddress `json:"address" yaml:"address"`
+	GasPerBlock uint64         `json:"gas_per_block" yaml:"gas_per_block"`
+}
+
+// NewContractGasQuota creates a new instance of ContractGasQuota
+func NewContractGasQuota(address sdk.AccAddress, gasPerBlock uint64) ContractGasQuota {
+	return ContractGasQuota{
+		Address:     address,
+		GasPerBlock: gasPerBlock,
+	}
+}
+
+// String returns a human readable string representation of the quota
+func (q ContractGasQuota) String() string {
+	return fmt.Sprintf(`Contract Gas Quota:
+  Address:       %s
+  Gas Per Block: %d`, q.Address, q.GasPerBlock)
+}
+
+// Validate performs a basic validation of the quota
+func (q ContractGasQuota) Validate() error {
+	if q.Address.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, "contract address can't be empty")
+	}
+	if q.GasPerBlock == 0 {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "zero gas per block quota of contract %s", q.Address)
+	}
+	return nil
+}
+
+// ContractGasUsage is the gas consumed by the txs calling a contract in the block of the height
+type ContractGasUsage struct {
+	Height  int64  `json:"height" yaml:"height"`
+	GasUsed uint64 `json:"gas_used" yaml:"gas_used"`
+}
+
+// ContractGasQuotaUsage is a contract gas quota along with the gas consumed by the contract in the current block
+type ContractGasQuotaUsage struct {
+	Address     sdk.AccAddress `json:"address" yaml:"address"`
+	GasPerBlock uint64         `json:"gas_per_block" yaml:"gas_per_block"`
+	GasUsed     uint64         `json:"gas_used" yaml:"gas_used"`
+}
+
+// NewContractGasQuotaUsage creates a new instance of ContractGasQuotaUsage
+func NewContractGasQuotaUsage(quota ContractGasQuota, gasUsed uint64) ContractGasQuotaUsage {
+	return ContractGasQuotaUsage{
+		Address:     quota.Address,
+		GasPerBlock: quota.GasPerBlock,
+		GasUsed:     gasUsed,
+	}
+}
+
+// String returns a human readable string representation of the quota usage
+func (u ContractGasQuotaUsage) String() string {
+	return fmt.Sprintf(`Contract Gas Quota:
+  Address:       %s
+  Gas Per Block: %d
+  Gas Used:      %d`, u.Address, u.GasPerBlock, u.GasUsed)
+}
@@ -0,0 +1,85 @@
+package types_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+)
+
+// runEIPCode calls the code with the extra eips of the patched go-ethereum (libs/go-ethereum/PATCHES.md)
+// enabled, it returns the output and the gas used
+func runEIPCode(t *testing.T, code string, gasLimit uint64, eips ...int) ([]byte, uint64, error) {
+	address := common.BytesToAddress([]byte("contract"))
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+	statedb.CreateAccount(address)
+	statedb.SetCode(address, hexutil.MustDecode(code))
+	statedb.Finalise(true)
+
+	vmctx := vm.BlockContext{
+		CanTransfer: func(vm.StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:    func(vm.StateDB, common.Address, common.Address, *big.Int) {},
+		BlockNumber: big.NewInt(0),
+	}
+	evm := vm.NewEVM(vmctx, vm.TxContext{}, statedb, params.AllEthashProtocolChanges, vm.Config{ExtraEips: eips})
+
+	ret, gas, err := evm.Call(vm.AccountRef(common.Address{}), address, nil, gasLimit, new(big.Int))
+	return ret, gasLimit - gas, err
+}
+
+func TestEIP3855(t *testing.T) {
+	// mstore(PUSH0, 0x2a) return(PUSH0, 0x20)
+	code := "0x602a5f5260205ff3"
+
+	_, _, err := runEIPCode(t, code, 100000)
+	require.Error(t, err, "PUSH0 is valid without EIP-3855")
+
+	ret, used, err := runEIPCode(t, code, 100000, 3855)
+	require.NoError(t, err)
+	require.Equal(t, common.LeftPadBytes([]byte{0x2a}, 32), ret)
+	// PUSH1 PUSH0 MSTORE PUSH1 PUSH0 RETURN, and the expansion of the memory to a word
+	require.Equal(t, 3+vm.GasQuickStep+vm.GasFastestStep+3+vm.GasQuickStep+3, used)
+}
+
+func TestEIP3860(t *testing.T) {
+	const gasLimit = 100000
+	testCases := []struct {
+		name  string
+		code  string
+		size  uint64
+		fails bool
+	}{
+		{"create(0, 0, 0xc000)", "0x61c00060006000f000", 0xc000, false},
+		{"create(0, 0, 0xc001)", "0x61c00160006000f000", 0xc001, true},
+		{"create2(0, 0, 0xc000, 0)", "0x600061c00060006000f500", 0xc000, false},
+		{"create2(0, 0, 0xc001, 0)", "0x600061c00160006000f500", 0xc001, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, used, err := runEIPCode(t, tc.code, gasLimit, 3860)
+			if tc.fails {
+				// the too large initcode uses all the gas
+				require.Error(t, err)
+				require.Equal(t, uint64(gasLimit), used)
+			} else {
+				require.NoError(t, err)
+			}
+
+			// the EIP-3860 activation doesn't modify the instruction sets shared by the EVMs without it
+			_, legacyUsed, err := runEIPCode(t, tc.code, gasLimit)
+			require.NoError(t, err)
+			if !tc.fails {
+				require.Equal(t, legacyUsed+params.InitCodeWordGas*((tc.size+31)/32), used)
+			}
+		})
+	}
+}
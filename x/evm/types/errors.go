@@ -57,6 +57,45 @@ var (
 	// ErrEmptyAddressBlockedContract returns an error if the contract method is empty
 	ErrEmptyAddressBlockedContract = sdkerrors.Register(ModuleName, 19, "Empty address in contract method blocked list is not allowed")
 
+	// ErrMaxInitCodeSizeExceeded returns an error if the initcode of a contract creation exceeds the EIP-3860 limit
+	ErrMaxInitCodeSizeExceeded = sdkerrors.Register(ModuleName, 20, "max initcode size exceeded")
+
+	// ErrFeeAllowanceNotFound returns an error if the grantee has no fee allowance of the granter
+	ErrFeeAllowanceNotFound = sdkerrors.Register(ModuleName, 21, "fee allowance not found")
+
+	// ErrFeeAllowanceExists returns an error if the grantee already has a fee allowance of another granter
+	ErrFeeAllowanceExists = sdkerrors.Register(ModuleName, 22, "fee allowance of another granter exists")
+
+	// ErrMigrationsNotFound returns an error if no evm migration is registered for the upgrade
+	ErrMigrationsNotFound = sdkerrors.Register(ModuleName, 23, "evm migrations not found")
+
+	// ErrMigrationFailed returns an error if an evm migration of an upgrade fails
+	ErrMigrationFailed = sdkerrors.Register(ModuleName, 24, "evm migration failed")
+
+	// ErrUpgradeSnapshotNotFound returns an error if no params and chain config were saved before the upgrade
+	ErrUpgradeSnapshotNotFound = sdkerrors.Register(ModuleName, 25, "upgrade snapshot not found")
+
+	// ErrInvalidTokenMetadata returns an error if the metadata of a token of the registry is invalid
+	ErrInvalidTokenMetadata = sdkerrors.Register(ModuleName, 26, "invalid token metadata")
+
+	// ErrTokenMetadataNotFound returns an error if the denom isn't in the token registry
+	ErrTokenMetadataNotFound = sdkerrors.Register(ModuleName, 27, "token metadata not found")
+
+	// ErrGasPriceOracleDisabled returns an error if a gas price is voted while the vote window is 0
+	ErrGasPriceOracleDisabled = sdkerrors.Register(ModuleName, 28, "gas price oracle disabled")
+
+	// ErrInvalidGasPriceVoter returns an error if the gas price voter isn't a bonded validator
+	ErrInvalidGasPriceVoter = sdkerrors.Register(ModuleName, 29, "gas price voter is not a bonded validator")
+
+	// ErrContractGasQuotaExceeded returns an error if a tx would exceed the gas per block quota of its contract
+	ErrContractGasQuotaExceeded = sdkerrors.Register(ModuleName, 30, "contract gas quota exceeded")
+
+	// ErrContractGasQuotaNotFound returns an error if the contract has no gas quota
+	ErrContractGasQuotaNotFound = sdkerrors.Register(ModuleName, 31, "contract gas quota not found")
+
+	// ErrTxBlacklisted returns an error if a tx is refused by the tx blacklist of the node
+	ErrTxBlacklisted = sdkerrors.Register(ModuleName, 32, "tx blacklisted")
+
 
 	CodeSpaceEvmCallFailed = uint32(7)
 
@@ -4,8 +4,29 @@ package types
 const (
 	EventTypeEthermint  = TypeMsgEthermint
 	EventTypeEthereumTx = TypeMsgEthereumTx
+	// EventTypeContractRedeployed is only emitted in contract redeploy audit mode
+	EventTypeContractRedeployed = "contract_redeployed"
+	EventTypeGrantFeeAllowance  = TypeMsgGrantFeeAllowance
+	EventTypeRevokeFeeAllowance = TypeMsgRevokeFeeAllowance
+	// EventTypeSetTokenMetadata and EventTypeDeleteTokenMetadata are emitted by the token registry proposals
+	EventTypeSetTokenMetadata    = "set_token_metadata"
+	EventTypeDeleteTokenMetadata = "delete_token_metadata"
+	EventTypeVoteGasPrice        = TypeMsgVoteGasPrice
+	// EventTypeSetContractGasQuota and EventTypeDeleteContractGasQuota are emitted by the contract gas quota
+	// proposals
+	EventTypeSetContractGasQuota    = "set_contract_gas_quota"
+	EventTypeDeleteContractGasQuota = "delete_contract_gas_quota"
 
-	AttributeKeyContractAddress = "contract"
-	AttributeKeyRecipient       = "recipient"
-	AttributeValueCategory      = ModuleName
+	AttributeKeyContractAddress  = "contract"
+	AttributeKeyRecipient        = "recipient"
+	AttributeKeyDestructedHeight = "destructed_height"
+	AttributeKeyGranter          = "granter"
+	AttributeKeyGrantee          = "grantee"
+	AttributeKeyDenom            = "denom"
+	AttributeKeySymbol           = "symbol"
+	AttributeKeyOriginChain      = "origin_chain"
+	AttributeKeyValidator        = "validator"
+	AttributeKeyGasPrice         = "gas_price"
+	AttributeKeyGasPerBlock      = "gas_per_block"
+	AttributeValueCategory       = ModuleName
 )
@@ -24,9 +24,18 @@ type SupplyKeeper interface {
 
 type Subspace interface {
 	GetParamSet(ctx sdk.Context, ps params.ParamSet)
+	GetParamSetIfExists(ctx sdk.Context, ps params.ParamSet)
 	SetParamSet(ctx sdk.Context, ps params.ParamSet)
+	GetRaw(ctx sdk.Context, key []byte) []byte
+	Update(ctx sdk.Context, key, value []byte) error
 }
 
 type BankKeeper interface {
 	BlacklistedAddr(addr sdk.AccAddress) bool
 }
+
+// StakingKeeper defines the expected staking keeper interface
+type StakingKeeper interface {
+	GetFeeRecipientByConsAddr(ctx sdk.Context, consAddr sdk.ConsAddress) (sdk.AccAddress, bool)
+	GetLastValidatorPower(ctx sdk.Context, operator sdk.ValAddress) int64
+}
@@ -0,0 +1,169 @@
+package types
+
+import (
+	"fmt"
+
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	sdkerrors "github.com/okex/exchain/libs/cosmos-sdk/types/errors"
+)
+
+const (
+	// TypeMsgGrantFeeAllowance defines the type string of MsgGrantFeeAllowance
+	TypeMsgGrantFeeAllowance = "grant_fee_allowance"
+	// TypeMsgRevokeFeeAllowance defines the type string of MsgRevokeFeeAllowance
+	TypeMsgRevokeFeeAllowance = "revoke_fee_allowance"
+)
+
+var (
+	_ sdk.Msg = MsgGrantFeeAllowance{}
+	_ sdk.Msg = MsgRevokeFeeAllowance{}
+)
+
+// FeeAllowance is the allowance of a granter paying the gas fee of the evm txs of a grantee, so that the
+// grantee may send evm txs without any balance of the evm denom
+type FeeAllowance struct {
+	Granter sdk.AccAddress `json:"granter" yaml:"granter"`
+	Grantee sdk.AccAddress `json:"grantee" yaml:"grantee"`
+	// SpendLimit is the amount of the evm denom left to the grantee, zero means no limit
+	SpendLimit sdk.Dec `json:"spend_limit" yaml:"spend_limit"`
+	// Expiration is the last height the allowance may be used at, zero means it never expires
+	Expiration int64 `json:"expiration" yaml:"expiration"`
+}
+
+// NewFeeAllowance creates a new FeeAllowance instance
+func NewFeeAllowance(granter, grantee sdk.AccAddress, spendLimit sdk.Dec, expiration int64) FeeAllowance {
+	return FeeAllowance{
+		Granter:    granter,
+		Grantee:    grantee,
+		SpendLimit: spendLimit,
+		Expiration: expiration,
+	}
+}
+
+// String returns a human readable string representation of the allowance
+func (fa FeeAllowance) String() string {
+	return fmt.Sprintf(`Fee Allowance:
+  Granter:     %s
+  Grantee:     %s
+  Spend Limit: %s
+  Expiration:  %d`, fa.Granter, fa.Grantee, fa.SpendLimit, fa.Expiration)
+}
+
+// Validate performs a stateless validation of the allowance
+func (fa FeeAllowance) Validate() error {
+	if fa.Granter.Empty() || fa.Grantee.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, "granter and grantee can't be empty")
+	}
+	if fa.Granter.Equals(fa.Grantee) {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, "granter can't grant a fee allowance to itself")
+	}
+	if fa.SpendLimit.IsNil() || fa.SpendLimit.IsNegative() {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidCoins, "invalid spend limit: %s", fa.SpendLimit)
+	}
+	if fa.Expiration < 0 {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "invalid expiration: %d", fa.Expiration)
+	}
+	return nil
+}
+
+// IsExpired returns true if the allowance can't be used at the height anymore
+func (fa FeeAllowance) IsExpired(height int64) bool {
+	return fa.Expiration != 0 && height > fa.Expiration
+}
+
+// Covers returns true if the spend limit of the allowance pays the fee
+func (fa FeeAllowance) Covers(fee sdk.Dec) bool {
+	return fa.SpendLimit.IsZero() || fa.SpendLimit.GTE(fee)
+}
+
+// Spend returns the allowance left after paying the fee, and false if the spend limit is used up
+func (fa FeeAllowance) Spend(fee sdk.Dec) (FeeAllowance, bool) {
+	if fa.SpendLimit.IsZero() {
+		return fa, true
+	}
+	fa.SpendLimit = fa.SpendLimit.Sub(fee)
+	return fa, fa.SpendLimit.IsPositive()
+}
+
+// MsgGrantFeeAllowance grants the grantee an allowance of the granter paying the gas fee of its evm txs. It
+// replaces the previous allowance of the same granter.
+type MsgGrantFeeAllowance struct {
+	Granter    sdk.AccAddress `json:"granter" yaml:"granter"`
+	Grantee    sdk.AccAddress `json:"grantee" yaml:"grantee"`
+	SpendLimit sdk.Dec        `json:"spend_limit" yaml:"spend_limit"`
+	Expiration int64          `json:"expiration" yaml:"expiration"`
+}
+
+// NewMsgGrantFeeAllowance creates a new MsgGrantFeeAllowance instance
+func NewMsgGrantFeeAllowance(granter, grantee sdk.AccAddress, spendLimit sdk.Dec, expiration int64) MsgGrantFeeAllowance {
+	return MsgGrantFeeAllowance{
+		Granter:    granter,
+		Grantee:    grantee,
+		SpendLimit: spendLimit,
+		Expiration: expiration,
+	}
+}
+
+// Route should return the name of the module
+func (msg MsgGrantFeeAllowance) Route() string { return RouterKey }
+
+// Type returns the action of the message
+func (msg MsgGrantFeeAllowance) Type() string { return TypeMsgGrantFeeAllowance }
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgGrantFeeAllowance) ValidateBasic() error {
+	return msg.FeeAllowance().Validate()
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgGrantFeeAllowance) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+// GetSigners defines whose signature is required
+func (msg MsgGrantFeeAllowance) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Granter}
+}
+
+// FeeAllowance returns the allowance granted by the message
+func (msg MsgGrantFeeAllowance) FeeAllowance() FeeAllowance {
+	return NewFeeAllowance(msg.Granter, msg.Grantee, msg.SpendLimit, msg.Expiration)
+}
+
+// MsgRevokeFeeAllowance revokes the allowance of the granter paying the gas fee of the evm txs of the grantee
+type MsgRevokeFeeAllowance struct {
+	Granter sdk.AccAddress `json:"granter" yaml:"granter"`
+	Grantee sdk.AccAddress `json:"grantee" yaml:"grantee"`
+}
+
+// NewMsgRevokeFeeAllowance creates a new MsgRevokeFeeAllowance instance
+func NewMsgRevokeFeeAllowance(granter, grantee sdk.AccAddress) MsgRevokeFeeAllowance {
+	return MsgRevokeFeeAllowance{
+		Granter: granter,
+		Grantee: grantee,
+	}
+}
+
+// Route should return the name of the module
+func (msg MsgRevokeFeeAllowance) Route() string { return RouterKey }
+
+// Type returns the action of the message
+func (msg MsgRevokeFeeAllowance) Type() string { return TypeMsgRevokeFeeAllowance }
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgRevokeFeeAllowance) ValidateBasic() error {
+	if msg.Granter.Empty() || msg.Grantee.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, "granter and grantee can't be empty")
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgRevokeFeeAllowance) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+// GetSigners defines whose signature is required
+func (msg MsgRevokeFeeAllowance) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Granter}
+}
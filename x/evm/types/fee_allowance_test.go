@@ -0,0 +1,61 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+)
+
+func TestFeeAllowance(t *testing.T) {
+	granter, grantee := newSdkAddress(), newSdkAddress()
+
+	allowance := NewFeeAllowance(granter, grantee, sdk.NewDec(10), 100)
+	require.NoError(t, allowance.Validate())
+	require.False(t, allowance.IsExpired(100))
+	require.True(t, allowance.IsExpired(101))
+	require.True(t, allowance.Covers(sdk.NewDec(10)))
+	require.False(t, allowance.Covers(sdk.NewDec(11)))
+
+	left, ok := allowance.Spend(sdk.NewDec(4))
+	require.True(t, ok)
+	require.Equal(t, sdk.NewDec(6), left.SpendLimit)
+	_, ok = left.Spend(sdk.NewDec(6))
+	require.False(t, ok)
+
+	// zero spend limit and expiration mean no limit
+	unlimited := NewFeeAllowance(granter, grantee, sdk.ZeroDec(), 0)
+	require.False(t, unlimited.IsExpired(1<<40))
+	require.True(t, unlimited.Covers(sdk.NewDec(1e9)))
+	left, ok = unlimited.Spend(sdk.NewDec(1e9))
+	require.True(t, ok)
+	require.True(t, left.SpendLimit.IsZero())
+
+	require.Error(t, NewFeeAllowance(granter, granter, sdk.ZeroDec(), 0).Validate())
+	require.Error(t, NewFeeAllowance(nil, grantee, sdk.ZeroDec(), 0).Validate())
+	require.Error(t, NewFeeAllowance(granter, grantee, sdk.NewDec(-1), 0).Validate())
+	require.Error(t, NewFeeAllowance(granter, grantee, sdk.Dec{}, 0).Validate())
+	require.Error(t, NewFeeAllowance(granter, grantee, sdk.ZeroDec(), -1).Validate())
+}
+
+func TestMsgFeeAllowance(t *testing.T) {
+	granter, grantee := newSdkAddress(), newSdkAddress()
+
+	grant := NewMsgGrantFeeAllowance(granter, grantee, sdk.NewDec(10), 100)
+	require.Equal(t, RouterKey, grant.Route())
+	require.Equal(t, TypeMsgGrantFeeAllowance, grant.Type())
+	require.NoError(t, grant.ValidateBasic())
+	require.Equal(t, []sdk.AccAddress{granter}, grant.GetSigners())
+	require.Equal(t, NewFeeAllowance(granter, grantee, sdk.NewDec(10), 100), grant.FeeAllowance())
+	require.NotPanics(t, func() { grant.GetSignBytes() })
+	require.Error(t, NewMsgGrantFeeAllowance(granter, granter, sdk.NewDec(10), 100).ValidateBasic())
+
+	revoke := NewMsgRevokeFeeAllowance(granter, grantee)
+	require.Equal(t, RouterKey, revoke.Route())
+	require.Equal(t, TypeMsgRevokeFeeAllowance, revoke.Type())
+	require.NoError(t, revoke.ValidateBasic())
+	require.Equal(t, []sdk.AccAddress{granter}, revoke.GetSigners())
+	require.NotPanics(t, func() { revoke.GetSignBytes() })
+	require.Error(t, NewMsgRevokeFeeAllowance(granter, nil).ValidateBasic())
+}
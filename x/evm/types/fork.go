@@ -0,0 +1,82 @@
+package types
+
+import (
+	"sync"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	authexported "github.com/okex/exchain/libs/cosmos-sdk/x/auth/exported"
+)
+
+// ForkSource provides the state of a remote chain at the height the local chain was forked from. It is read when
+// the local store misses an account, a code or a storage slot.
+type ForkSource interface {
+	// GetAccount returns the account of addr, nil if it does not exist
+	GetAccount(addr ethcmn.Address) (authexported.Account, error)
+	// GetCode returns the code of codeHash
+	GetCode(codeHash []byte) ([]byte, error)
+	// GetState returns the raw value of the storage slot of addr, key is prefixed by GetStorageByAddressKey
+	GetState(addr ethcmn.Address, key ethcmn.Hash) ([]byte, error)
+}
+
+// forkState tracks the accounts and slots written locally, they are never fetched from the fork source again as
+// the local deletions would otherwise be undone by the remote state
+type forkState struct {
+	source ForkSource
+
+	mtx             sync.RWMutex
+	deletedAccounts map[ethcmn.Address]struct{}
+	writtenSlots    map[ethcmn.Address]map[ethcmn.Hash]struct{}
+}
+
+var fork *forkState
+
+// SetForkSource sets the source of the state missing from the local store, a nil source disables the fork
+func SetForkSource(source ForkSource) {
+	if source == nil {
+		fork = nil
+		return
+	}
+	fork = &forkState{
+		source:          source,
+		deletedAccounts: make(map[ethcmn.Address]struct{}),
+		writtenSlots:    make(map[ethcmn.Address]map[ethcmn.Hash]struct{}),
+	}
+}
+
+func (f *forkState) account(addr ethcmn.Address) (authexported.Account, error) {
+	f.mtx.RLock()
+	_, deleted := f.deletedAccounts[addr]
+	f.mtx.RUnlock()
+	if deleted {
+		return nil, nil
+	}
+	return f.source.GetAccount(addr)
+}
+
+func (f *forkState) state(addr ethcmn.Address, key ethcmn.Hash) ([]byte, error) {
+	f.mtx.RLock()
+	_, deleted := f.deletedAccounts[addr]
+	_, written := f.writtenSlots[addr][key]
+	f.mtx.RUnlock()
+	if deleted || written {
+		return nil, nil
+	}
+	return f.source.GetState(addr, key)
+}
+
+func (f *forkState) deleteAccount(addr ethcmn.Address) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.deletedAccounts[addr] = struct{}{}
+}
+
+func (f *forkState) writeSlot(addr ethcmn.Address, key ethcmn.Hash) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	slots, ok := f.writtenSlots[addr]
+	if !ok {
+		slots = make(map[ethcmn.Hash]struct{})
+		f.writtenSlots[addr] = slots
+	}
+	slots[key] = struct{}{}
+}
@@ -0,0 +1,126 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	sdkerrors "github.com/okex/exchain/libs/cosmos-sdk/types/errors"
+)
+
+const (
+	// TypeMsgVoteGasPrice defines the type string of MsgVoteGasPrice
+	TypeMsgVoteGasPrice = "vote_gas_price"
+)
+
+// GasPriceVote is the min gas price recommended by a validator, in the evm denom per gas as the min gas prices
+// of the nodes. It counts in the floor of the blocks of the vote window from its height.
+type GasPriceVote struct {
+	Validator sdk.ValAddress `json:"validator" yaml:"validator"`
+	GasPrice  sdk.Dec        `json:"gas_price" yaml:"gas_price"`
+	Height    int64          `json:"height" yaml:"height"`
+}
+
+// String returns a human readable string representation of the vote
+func (v GasPriceVote) String() string {
+	return fmt.Sprintf(`Gas Price Vote:
+  Validator: %s
+  Gas Price: %s
+  Height:    %d`, v.Validator, v.GasPrice, v.Height)
+}
+
+// IsExpired returns whether the vote is out of the vote window at the height
+func (v GasPriceVote) IsExpired(height int64, window uint64) bool {
+	return height-v.Height >= int64(window)
+}
+
+// MsgVoteGasPrice submits the min gas price recommended by a validator, signed by its operator. It replaces the
+// previous vote of the validator.
+type MsgVoteGasPrice struct {
+	Validator sdk.ValAddress `json:"validator" yaml:"validator"`
+	GasPrice  sdk.Dec        `json:"gas_price" yaml:"gas_price"`
+}
+
+// NewMsgVoteGasPrice creates a new MsgVoteGasPrice instance
+func NewMsgVoteGasPrice(validator sdk.ValAddress, gasPrice sdk.Dec) MsgVoteGasPrice {
+	return MsgVoteGasPrice{
+		Validator: validator,
+		GasPrice:  gasPrice,
+	}
+}
+
+// Route should return the name of the module
+func (msg MsgVoteGasPrice) Route() string { return RouterKey }
+
+// Type returns the action of the message
+func (msg MsgVoteGasPrice) Type() string { return TypeMsgVoteGasPrice }
+
+// ValidateBasic runs stateless checks on the message
+func (msg MsgVoteGasPrice) ValidateBasic() error {
+	if msg.Validator.Empty() {
+		return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, "validator can't be empty")
+	}
+	if msg.GasPrice.IsNil() || msg.GasPrice.IsNegative() {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidRequest, "invalid gas price %s", msg.GasPrice)
+	}
+	return nil
+}
+
+// GetSignBytes encodes the message for signing
+func (msg MsgVoteGasPrice) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+// GetSigners defines whose signature is required
+func (msg MsgVoteGasPrice) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{sdk.AccAddress(msg.Validator)}
+}
+
+// WeightedMedianGasPrice returns the gas price of the votes at which half of the total power of their
+// validators is reached, the votes being sorted by gas price. The powers are in the order of the votes, the
+// votes without power are ignored. It's nil without any vote with power.
+func WeightedMedianGasPrice(votes []GasPriceVote, powers []int64) sdk.Dec {
+	type weighted struct {
+		gasPrice sdk.Dec
+		power    int64
+	}
+	var prices []weighted
+	var total int64
+	for i, vote := range votes {
+		if powers[i] <= 0 {
+			continue
+		}
+		prices = append(prices, weighted{vote.GasPrice, powers[i]})
+		total += powers[i]
+	}
+	if len(prices) == 0 {
+		return sdk.Dec{}
+	}
+
+	sort.SliceStable(prices, func(i, j int) bool { return prices[i].gasPrice.LT(prices[j].gasPrice) })
+	var sum int64
+	for _, p := range prices {
+		sum += p.power
+		if sum*2 >= total {
+			return p.gasPrice
+		}
+	}
+	return prices[len(prices)-1].gasPrice
+}
+
+// SmoothGasPrice returns the target gas price bounded by the max change rate from the previous one, so that
+// the floor follows the votes without spiking. The target is returned as is without a previous gas price or a
+// max change rate.
+func SmoothGasPrice(previous, target, maxChange sdk.Dec) sdk.Dec {
+	if previous.IsNil() || !previous.IsPositive() || maxChange.IsNil() || !maxChange.IsPositive() {
+		return target
+	}
+	delta := previous.Mul(maxChange)
+	if upper := previous.Add(delta); target.GT(upper) {
+		return upper
+	}
+	if lower := previous.Sub(delta); target.LT(lower) {
+		return lower
+	}
+	return target
+}
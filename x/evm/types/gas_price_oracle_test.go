@@ -0,0 +1,72 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+)
+
+func TestGasPriceVote(t *testing.T) {
+	vote := GasPriceVote{Validator: sdk.ValAddress(newSdkAddress()), GasPrice: sdk.NewDec(1), Height: 10}
+	require.False(t, vote.IsExpired(10, 5))
+	require.False(t, vote.IsExpired(14, 5))
+	require.True(t, vote.IsExpired(15, 5))
+
+	msg := NewMsgVoteGasPrice(vote.Validator, vote.GasPrice)
+	require.NoError(t, msg.ValidateBasic())
+	require.Equal(t, []sdk.AccAddress{sdk.AccAddress(vote.Validator)}, msg.GetSigners())
+	require.NoError(t, NewMsgVoteGasPrice(vote.Validator, sdk.ZeroDec()).ValidateBasic())
+	require.Error(t, NewMsgVoteGasPrice(nil, vote.GasPrice).ValidateBasic())
+	require.Error(t, NewMsgVoteGasPrice(vote.Validator, sdk.Dec{}).ValidateBasic())
+	require.Error(t, NewMsgVoteGasPrice(vote.Validator, sdk.NewDec(-1)).ValidateBasic())
+}
+
+func TestWeightedMedianGasPrice(t *testing.T) {
+	votes := func(prices ...int64) []GasPriceVote {
+		var votes []GasPriceVote
+		for _, price := range prices {
+			votes = append(votes, GasPriceVote{GasPrice: sdk.NewDec(price)})
+		}
+		return votes
+	}
+
+	testCases := []struct {
+		name     string
+		votes    []GasPriceVote
+		powers   []int64
+		expected sdk.Dec
+	}{
+		{"no vote", nil, nil, sdk.Dec{}},
+		{"no power", votes(1, 2), []int64{0, 0}, sdk.Dec{}},
+		{"single vote", votes(7), []int64{1}, sdk.NewDec(7)},
+		{"equal powers", votes(3, 1, 2), []int64{1, 1, 1}, sdk.NewDec(2)},
+		{"heavy validator", votes(3, 1, 2), []int64{10, 1, 1}, sdk.NewDec(3)},
+		{"half of the power", votes(1, 5), []int64{1, 1}, sdk.NewDec(1)},
+		{"unbonded ignored", votes(100, 1, 2), []int64{0, 1, 2}, sdk.NewDec(2)},
+	}
+
+	for _, tc := range testCases {
+		median := WeightedMedianGasPrice(tc.votes, tc.powers)
+		if tc.expected.IsNil() {
+			require.True(t, median.IsNil(), tc.name)
+			continue
+		}
+		require.Equal(t, tc.expected, median, tc.name)
+	}
+}
+
+func TestSmoothGasPrice(t *testing.T) {
+	maxChange := sdk.NewDecWithPrec(125, 3)
+	previous := sdk.NewDec(8)
+
+	require.Equal(t, sdk.NewDec(9), SmoothGasPrice(previous, sdk.NewDec(100), maxChange))
+	require.Equal(t, sdk.NewDec(7), SmoothGasPrice(previous, sdk.ZeroDec(), maxChange))
+	require.Equal(t, sdk.NewDecWithPrec(85, 1), SmoothGasPrice(previous, sdk.NewDecWithPrec(85, 1), maxChange))
+
+	// without a previous floor or a max change the target is followed at once
+	require.Equal(t, sdk.NewDec(100), SmoothGasPrice(sdk.ZeroDec(), sdk.NewDec(100), maxChange))
+	require.Equal(t, sdk.NewDec(100), SmoothGasPrice(previous, sdk.NewDec(100), sdk.ZeroDec()))
+	require.Equal(t, sdk.NewDec(100), SmoothGasPrice(previous, sdk.NewDec(100), sdk.Dec{}))
+}
@@ -19,6 +19,16 @@ type (
 		ContractMethodBlockedList   BlockedContractList `json:"contract_method_blocked_list,omitempty"`
 		ChainConfig                 ChainConfig         `json:"chain_config"`
 		Params                      Params              `json:"params"`
+		RecentBlockHashes           []RecentBlockHash   `json:"recent_block_hashes,omitempty"`
+		FeeAllowances               []FeeAllowance      `json:"fee_allowances,omitempty"`
+		TokenRegistry               []TokenMetadata     `json:"token_registry,omitempty"`
+	}
+
+	// RecentBlockHash defines the hash of one of the last BlockHashWindow blocks, kept across an export
+	// so that BLOCKHASH keeps returning it after the import.
+	RecentBlockHash struct {
+		Height uint64      `json:"height"`
+		Hash   ethcmn.Hash `json:"hash"`
 	}
 
 	// GenesisAccount defines an account to be initialized in the genesis state.
@@ -75,12 +85,45 @@ func (ga *GenesisAccount) UnmarshalJSON(input []byte) error {
 	if formatState.Code == "" {
 		ga.Code = nil
 	} else {
-		ga.Code = hexutil.MustDecode(formatState.Code)
+		code, err := hexutil.Decode(formatState.Code)
+		if err != nil {
+			return fmt.Errorf("invalid code of account %s: %w", formatState.Address, err)
+		}
+		ga.Code = code
 	}
 	ga.Storage = formatState.Storage
 	return nil
 }
 
+func (bh RecentBlockHash) MarshalJSON() ([]byte, error) {
+	formatState := &struct {
+		Height uint64 `json:"height"`
+		Hash   string `json:"hash"`
+	}{
+		Height: bh.Height,
+		Hash:   bh.Hash.Hex(),
+	}
+	return json.Marshal(formatState)
+}
+
+func (bh *RecentBlockHash) UnmarshalJSON(input []byte) error {
+	formatState := &struct {
+		Height uint64 `json:"height"`
+		Hash   string `json:"hash"`
+	}{}
+	if err := json.Unmarshal(input, &formatState); err != nil {
+		return err
+	}
+
+	hash, err := hexutil.Decode(formatState.Hash)
+	if err != nil || len(hash) != ethcmn.HashLength {
+		return fmt.Errorf("invalid recent block hash of height %d: %s", formatState.Height, formatState.Hash)
+	}
+	bh.Height = formatState.Height
+	bh.Hash = ethcmn.BytesToHash(hash)
+	return nil
+}
+
 // DefaultGenesisState sets default evm genesis state with empty accounts and default params and
 // chain config values.
 func DefaultGenesisState() GenesisState {
@@ -121,6 +164,58 @@ func (gs GenesisState) Validate() error {
 		seenTxs[tx.Hash.String()] = true
 	}
 
+	if len(gs.RecentBlockHashes) > BlockHashWindow {
+		return fmt.Errorf("%d recent block hashes, the window holds %d", len(gs.RecentBlockHashes), BlockHashWindow)
+	}
+	seenHeights := make(map[uint64]bool)
+	for _, bh := range gs.RecentBlockHashes {
+		if seenHeights[bh.Height] {
+			return fmt.Errorf("duplicated recent block hash of height %d", bh.Height)
+		}
+		if bh.Hash == (ethcmn.Hash{}) {
+			return fmt.Errorf("empty recent block hash of height %d", bh.Height)
+		}
+		seenHeights[bh.Height] = true
+	}
+	// the heights must fit in the window together, or they would share a ring buffer slot
+	if n := len(gs.RecentBlockHashes); n > 1 {
+		lowest, highest := gs.RecentBlockHashes[0].Height, gs.RecentBlockHashes[0].Height
+		for _, bh := range gs.RecentBlockHashes[1:] {
+			if bh.Height < lowest {
+				lowest = bh.Height
+			}
+			if bh.Height > highest {
+				highest = bh.Height
+			}
+		}
+		if highest-lowest >= BlockHashWindow {
+			return fmt.Errorf("recent block hashes from height %d to %d don't fit in the window of %d blocks",
+				lowest, highest, BlockHashWindow)
+		}
+	}
+
+	seenGrantees := make(map[string]bool)
+	for _, allowance := range gs.FeeAllowances {
+		if seenGrantees[allowance.Grantee.String()] {
+			return fmt.Errorf("duplicated fee allowance of grantee %s", allowance.Grantee)
+		}
+		if err := allowance.Validate(); err != nil {
+			return fmt.Errorf("invalid fee allowance of grantee %s: %w", allowance.Grantee, err)
+		}
+		seenGrantees[allowance.Grantee.String()] = true
+	}
+
+	seenDenoms := make(map[string]bool)
+	for _, token := range gs.TokenRegistry {
+		if seenDenoms[token.Denom] {
+			return fmt.Errorf("duplicated token metadata of denom %s", token.Denom)
+		}
+		if err := token.Validate(); err != nil {
+			return fmt.Errorf("invalid token metadata of denom %s: %w", token.Denom, err)
+		}
+		seenDenoms[token.Denom] = true
+	}
+
 	if err := gs.ChainConfig.Validate(); err != nil {
 		return err
 	}
@@ -218,6 +218,51 @@ func TestValidateGenesis(t *testing.T) {
 			},
 			expPass: false,
 		},
+		{
+			name: "recent block hashes",
+			genState: GenesisState{
+				ChainConfig: DefaultChainConfig(),
+				Params:      DefaultParams(),
+				RecentBlockHashes: []RecentBlockHash{
+					{Height: 10, Hash: ethcmn.BytesToHash([]byte("hash 10"))},
+					{Height: 10 + BlockHashWindow - 1, Hash: ethcmn.BytesToHash([]byte("hash 265"))},
+				},
+			},
+			expPass: true,
+		},
+		{
+			name: "duplicated recent block hash",
+			genState: GenesisState{
+				ChainConfig: DefaultChainConfig(),
+				Params:      DefaultParams(),
+				RecentBlockHashes: []RecentBlockHash{
+					{Height: 10, Hash: ethcmn.BytesToHash([]byte("hash 10"))},
+					{Height: 10, Hash: ethcmn.BytesToHash([]byte("hash 10"))},
+				},
+			},
+			expPass: false,
+		},
+		{
+			name: "empty recent block hash",
+			genState: GenesisState{
+				ChainConfig:       DefaultChainConfig(),
+				Params:            DefaultParams(),
+				RecentBlockHashes: []RecentBlockHash{{Height: 10}},
+			},
+			expPass: false,
+		},
+		{
+			name: "recent block hashes out of the window",
+			genState: GenesisState{
+				ChainConfig: DefaultChainConfig(),
+				Params:      DefaultParams(),
+				RecentBlockHashes: []RecentBlockHash{
+					{Height: 10, Hash: ethcmn.BytesToHash([]byte("hash 10"))},
+					{Height: 10 + BlockHashWindow, Hash: ethcmn.BytesToHash([]byte("hash 266"))},
+				},
+			},
+			expPass: false,
+		},
 		{
 			name: "invalid chain config",
 			genState: GenesisState{
@@ -0,0 +1,120 @@
+package types
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc"
+
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	"github.com/okex/exchain/x/evm/evmproto"
+)
+
+// The messages and the service of query.proto are generated into query.pb.go.
+
+const (
+	QueryServiceName = "okexchain.evm.v1.Query"
+
+	DefaultPageLimit = 100
+	MaxPageLimit     = 1000
+)
+
+// GetLimit returns the limit of the page, bounded by MaxPageLimit
+func (m *PageRequest) GetLimit() int {
+	if m == nil || m.Limit == 0 {
+		return DefaultPageLimit
+	}
+	if m.Limit > MaxPageLimit {
+		return MaxPageLimit
+	}
+	return int(m.Limit)
+}
+
+// GetKey returns the key the page starts from
+func (m *PageRequest) GetKey() []byte {
+	if m == nil {
+		return nil
+	}
+	return m.Key
+}
+
+// NewProtoParams converts the params into their protobuf message
+func NewProtoParams(p Params) *evmproto.Params {
+	var eips []int64
+	for _, eip := range p.ExtraEIPs {
+		eips = append(eips, int64(eip))
+	}
+	return &evmproto.Params{
+		EnableCreate:                      p.EnableCreate,
+		EnableCall:                        p.EnableCall,
+		ExtraEips:                         eips,
+		EnableContractDeploymentWhitelist: p.EnableContractDeploymentWhitelist,
+		EnableContractBlockedList:         p.EnableContractBlockedList,
+		MaxGasLimitPerTx:                  p.MaxGasLimitPerTx,
+		BaseFee:                           p.BaseFee,
+		MaxGasLimitPerBlock:               p.MaxGasLimitPerBlock,
+		EnableBlockHashWindow:             p.EnableBlockHashWindow,
+		FeeDenoms:                         p.FeeDenoms,
+		FeeConversionSpread:               decString(p.FeeConversionSpread),
+		GasPriceVoteWindow:                p.GasPriceVoteWindow,
+		MaxGasPriceChange:                 decString(p.MaxGasPriceChange),
+	}
+}
+
+// ParamsFromProto converts the message back into params, the missing decimals are zero
+func ParamsFromProto(m *evmproto.Params) (Params, error) {
+	if m == nil {
+		return Params{}, fmt.Errorf("missing params")
+	}
+	feeConversionSpread, err := decFromString(m.FeeConversionSpread)
+	if err != nil {
+		return Params{}, err
+	}
+	maxGasPriceChange, err := decFromString(m.MaxGasPriceChange)
+	if err != nil {
+		return Params{}, err
+	}
+
+	p := Params{
+		EnableCreate:                      m.EnableCreate,
+		EnableCall:                        m.EnableCall,
+		EnableContractDeploymentWhitelist: m.EnableContractDeploymentWhitelist,
+		EnableContractBlockedList:         m.EnableContractBlockedList,
+		MaxGasLimitPerTx:                  m.MaxGasLimitPerTx,
+		BaseFee:                           m.BaseFee,
+		MaxGasLimitPerBlock:               m.MaxGasLimitPerBlock,
+		EnableBlockHashWindow:             m.EnableBlockHashWindow,
+		FeeConversionSpread:               feeConversionSpread,
+		GasPriceVoteWindow:                m.GasPriceVoteWindow,
+		MaxGasPriceChange:                 maxGasPriceChange,
+	}
+	for _, eip := range m.ExtraEips {
+		p.ExtraEIPs = append(p.ExtraEIPs, int(eip))
+	}
+	// the empty lists are decoded as nil, as by amino
+	p.FeeDenoms = append(p.FeeDenoms, m.FeeDenoms...)
+	return p, nil
+}
+
+func decString(d sdk.Dec) string {
+	if d.IsNil() {
+		return ""
+	}
+	return d.String()
+}
+
+func decFromString(s string) (sdk.Dec, error) {
+	if s == "" {
+		return sdk.ZeroDec(), nil
+	}
+	return sdk.NewDecFromStr(s)
+}
+
+// ServiceRegistrar is implemented by the grpc server and the query router of the app
+type ServiceRegistrar interface {
+	RegisterService(sd *grpc.ServiceDesc, ss interface{})
+}
+
+// RegisterQueryService registers the query service on the grpc server or on the query router of the app
+func RegisterQueryService(s ServiceRegistrar, srv QueryServer) {
+	s.RegisterService(&_Query_serviceDesc, srv)
+}
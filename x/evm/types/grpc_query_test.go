@@ -0,0 +1,36 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGRPCQueryMessages(t *testing.T) {
+	res := &QueryStorageSlotsResponse{
+		Slots:      []*StorageSlot{{Key: "0x01", Value: "0x02"}, {Key: "0x03", Value: "0x04"}},
+		Pagination: &PageResponse{NextKey: []byte{0x5}},
+	}
+	bz, err := proto.Marshal(res)
+	require.NoError(t, err)
+	var decoded QueryStorageSlotsResponse
+	require.NoError(t, proto.Unmarshal(bz, &decoded))
+	require.True(t, proto.Equal(res, &decoded))
+
+	params := NewProtoParams(DefaultParams())
+	params.ExtraEips = []int64{2200}
+	json, err := (&jsonpb.Marshaler{OrigName: true}).MarshalToString(&QueryParamsResponse{Params: params})
+	require.NoError(t, err)
+	require.Contains(t, json, `"extra_eips":["2200"]`)
+	require.Contains(t, json, `"max_gas_limit_per_tx":"30000000"`)
+}
+
+func TestPageRequestLimit(t *testing.T) {
+	var page *PageRequest
+	require.Equal(t, DefaultPageLimit, page.GetLimit())
+	require.Nil(t, page.GetKey())
+	require.Equal(t, 10, (&PageRequest{Limit: 10}).GetLimit())
+	require.Equal(t, MaxPageLimit, (&PageRequest{Limit: MaxPageLimit + 1}).GetLimit())
+}
@@ -62,18 +62,24 @@ func InitIndexer(db dbm.DB) {
 		return
 	}
 
-	indexer = &Indexer{
+	indexer = NewIndexer(db)
+}
+
+// NewIndexer returns an indexer of the bloom bits stored in the db
+func NewIndexer(db dbm.DB) *Indexer {
+	i := &Indexer{
 		backend: initBloomIndexer(db),
 		update:  make(chan sdk.Context),
 		quit:    make(chan struct{}),
 	}
-	indexer.setValidSections(indexer.GetValidSections())
+	i.setValidSections(i.GetValidSections())
+	return i
 }
 
 func BloomDb() dbm.DB {
 	dataDir := filepath.Join(viper.GetString("home"), "data")
 	var err error
-	db, err := sdk.NewLevelDB(bloomDir, dataDir)
+	db, err := sdk.NewLevelDB(BloomDir, dataDir)
 	if err != nil {
 		panic(err)
 	}
@@ -253,4 +259,63 @@ func (i *Indexer) updateCtx(oldCtx sdk.Context) sdk.Context {
 	}
 
 	return newCtx
-}
\ No newline at end of file
+}
+
+// Rollback invalidates the sections of the index which are not complete at the given height, see RollbackBloomBits
+func (i *Indexer) Rollback(height int64) (uint64, error) {
+	sections, err := RollbackBloomBits(i.backend.db, height)
+	if err != nil {
+		return 0, err
+	}
+	i.storedSections = sections
+	return sections, nil
+}
+
+// RollbackBloomBits invalidates the sections of the bloom index which are not complete at the given height,
+// they are processed again once the chain reaches them. It returns the number of valid sections left.
+func RollbackBloomBits(db dbm.DB, height int64) (uint64, error) {
+	i := &Indexer{backend: bloomIndexer{db: db}}
+	stored := i.GetValidSections()
+	sections := uint64(0)
+	if height > tmtypes.GetStartBlockHeight() {
+		sections = uint64(height-tmtypes.GetStartBlockHeight()) / BloomBitsBlocks
+	}
+	if sections >= stored {
+		return stored, nil
+	}
+
+	// remove the bloom bits of the invalidated sections, keyed by bit and section
+	for bit := 0; bit < ethtypes.BloomBitLength; bit++ {
+		start := make([]byte, 11)
+		copy(start, bloomBitsPrefix)
+		binary.BigEndian.PutUint16(start[1:], uint16(bit))
+		binary.BigEndian.PutUint64(start[3:], sections)
+		end := make([]byte, 3)
+		copy(end, bloomBitsPrefix)
+		binary.BigEndian.PutUint16(end[1:], uint16(bit+1))
+		if err := deleteRange(db, start, end); err != nil {
+			return 0, err
+		}
+	}
+	i.storedSections = stored
+	i.setValidSections(sections)
+	return sections, nil
+}
+
+func deleteRange(db dbm.DB, start, end []byte) error {
+	it, err := db.Iterator(start, end)
+	if err != nil {
+		return err
+	}
+	var keys [][]byte
+	for ; it.Valid(); it.Next() {
+		keys = append(keys, append([]byte{}, it.Key()...))
+	}
+	it.Close()
+	for _, key := range keys {
+		if err := db.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
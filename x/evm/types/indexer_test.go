@@ -55,3 +55,34 @@ func (m mockKeeper) SetBlockBloom(ctx sdk.Context, height int64, bloom ethtypes.
 func (m mockKeeper) GetHeightHash(ctx sdk.Context, height uint64) common.Hash {
 	return common.Hash{0x01}
 }
+
+func TestRollbackBloomBits(t *testing.T) {
+	db := dbm.NewMemDB()
+	i := &Indexer{backend: bloomIndexer{db: db}}
+	for section := uint64(0); section < 3; section++ {
+		i.setSectionHead(section, common.Hash{0x01})
+		require.NoError(t, db.Set(bloomBitsKey(0, section, common.Hash{0x01}), []byte{0x01}))
+		require.NoError(t, db.Set(bloomBitsKey(ethtypes.BloomBitLength-1, section, common.Hash{0x01}), []byte{0x01}))
+	}
+	i.setValidSections(3)
+
+	sections, err := RollbackBloomBits(db, int64(2*BloomBitsBlocks+5))
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), sections)
+	require.Equal(t, uint64(2), i.GetValidSections())
+	require.Equal(t, common.Hash{}, i.sectionHead(2))
+	require.Equal(t, common.Hash{0x01}, i.sectionHead(1))
+	for _, bit := range []uint{0, ethtypes.BloomBitLength - 1} {
+		has, err := db.Has(bloomBitsKey(bit, 1, common.Hash{0x01}))
+		require.NoError(t, err)
+		require.True(t, has)
+		has, err = db.Has(bloomBitsKey(bit, 2, common.Hash{0x01}))
+		require.NoError(t, err)
+		require.False(t, has)
+	}
+
+	// the sections complete at the height are kept
+	sections, err = RollbackBloomBits(db, int64(2*BloomBitsBlocks))
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), sections)
+}
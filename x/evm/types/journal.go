@@ -120,14 +120,27 @@ func (j *journal) substractDirty(addr ethcmn.Address) {
 
 // deleteDirty deletes a dirty entry from the jounal's dirties slice. If the
 // entry is not found it performs a no-op.
+//
+// The entries are reverted in the reverse order of their addition, so the deleted
+// entry is the last one of the slice unless an address was explicitly set to dirty
+// (see dirty) after it. Only in that case are the next entries shifted and
+// reindexed.
 func (j *journal) deleteDirty(addr ethcmn.Address) {
 	idx, found := j.addressToJournalIndex[addr]
 	if !found {
 		return
 	}
 
-	j.dirties = append(j.dirties[:idx], j.dirties[idx+1:]...)
 	delete(j.addressToJournalIndex, addr)
+	if idx == len(j.dirties)-1 {
+		j.dirties = j.dirties[:idx]
+		return
+	}
+
+	j.dirties = append(j.dirties[:idx], j.dirties[idx+1:]...)
+	for i := idx; i < len(j.dirties); i++ {
+		j.addressToJournalIndex[j.dirties[i].address] = i
+	}
 }
 
 type (
@@ -316,9 +329,10 @@ func (ch addPreimageChange) revert(s *CommitStateDB) {
 	// remove from the slice
 	delete(s.hashToPreimageIndex, ch.hash)
 
-	// if the slice contains one element, delete it
-	if len(s.preimages) == 1 {
-		s.preimages = []preimageEntry{}
+	// the preimages are reverted in the reverse order of their addition, the
+	// reverted one is the last of the slice
+	if idx == len(s.preimages)-1 {
+		s.preimages = s.preimages[:idx]
 		return
 	}
 
@@ -2,6 +2,7 @@ package types
 
 import (
 	"fmt"
+	"math/big"
 	"os"
 	"testing"
 
@@ -341,3 +342,59 @@ func (suite *JournalTestSuite) TestJournal_dirty() {
 	suite.journal.dirty(suite.address)
 	suite.Require().Equal(1, suite.journal.getDirty(suite.address))
 }
+
+func (suite *JournalTestSuite) TestJournal_deleteDirty() {
+	addrs := []ethcmn.Address{
+		ethcmn.BytesToAddress([]byte("addr0")),
+		ripemd,
+		ethcmn.BytesToAddress([]byte("addr2")),
+	}
+
+	suite.journal.append(touchChange{account: &addrs[0]})
+	suite.journal.dirty(addrs[1])
+	suite.journal.append(touchChange{account: &addrs[2]})
+
+	// the dirty entry of the first address is deleted before the one of ripemd
+	suite.journal.revert(suite.stateDB, 0)
+	suite.Require().Equal([]dirty{{address: ripemd, changes: 1}}, suite.journal.dirties)
+	suite.Require().Equal(map[ethcmn.Address]int{ripemd: 0}, suite.journal.addressToJournalIndex)
+}
+
+// BenchmarkRevertToSnapshot reverts the changes of a stack of nested calls, each call taking a snapshot and
+// changing the storage and the balance of its own account
+func BenchmarkRevertToSnapshot(b *testing.B) {
+	for _, depth := range []int{16, 256, 4096} {
+		b.Run(fmt.Sprintf("depth %d", depth), func(b *testing.B) {
+			csdb := &CommitStateDB{
+				ctx:                 sdk.Context{}.WithIsCheckTx(true),
+				stateObjects:        make(map[ethcmn.Address]*stateEntry),
+				hashToPreimageIndex: make(map[ethcmn.Hash]int),
+				journal:             newJournal(),
+				accessList:          newAccessList(),
+			}
+			objects := make([]*stateObject, depth)
+			for i := range objects {
+				addr := ethcmn.BigToAddress(big.NewInt(int64(i + 1)))
+				objects[i] = newStateObject(csdb, &ethermint.EthAccount{
+					BaseAccount: auth.NewBaseAccount(addr.Bytes(), nil, nil, 0, 0),
+				})
+				csdb.setStateObject(objects[i])
+			}
+			key, value := ethcmn.BytesToHash([]byte("key")), ethcmn.BytesToHash([]byte("value"))
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				root := csdb.Snapshot()
+				for _, so := range objects {
+					csdb.Snapshot()
+					csdb.journal.append(storageChange{account: &so.address, key: key})
+					so.setState(key, value)
+					so.SetBalance(big.NewInt(1))
+					csdb.AddLog(&ethtypes.Log{Address: so.address})
+				}
+				csdb.RevertToSnapshot(root)
+			}
+		})
+	}
+}
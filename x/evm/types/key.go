@@ -28,8 +28,20 @@ var (
 	KeyPrefixHeightHash                  = []byte{0x07}
 	KeyPrefixContractDeploymentWhitelist = []byte{0x08}
 	KeyPrefixContractBlockedList         = []byte{0x09}
+	KeyPrefixRecentBlockHash             = []byte{0x0A}
+	KeyPrefixFeeAllowance                = []byte{0x0B}
+	KeyPrefixTxFeeAllowance              = []byte{0x0C}
+	KeyPrefixUpgradeSnapshot             = []byte{0x0D}
+	KeyPrefixTokenRegistry               = []byte{0x0E}
+	KeyPrefixGasPriceVote                = []byte{0x0F}
+	KeyPrefixMinGasPrice                 = []byte{0x10}
+	KeyPrefixContractGasQuota            = []byte{0x11}
+	KeyPrefixContractGasUsage            = []byte{0x12}
 )
 
+// BlockHashWindow is the number of previous blocks whose hash is available to the BLOCKHASH opcode
+const BlockHashWindow = 256
+
 // HeightHashKey returns the key for the given chain epoch and height.
 // The key will be composed in the following order:
 //   key = prefix + bytes(height)
@@ -39,6 +51,12 @@ func HeightHashKey(height uint64) []byte {
 	return sdk.Uint64ToBigEndian(height)
 }
 
+// RecentBlockHashKey returns the ring buffer slot of the given height. The slots are
+// overwritten every BlockHashWindow heights, so the index never grows.
+func RecentBlockHashKey(height uint64) []byte {
+	return []byte{byte(height % BlockHashWindow)}
+}
+
 // BloomKey defines the store key for a block Bloom
 func BloomKey(height int64) []byte {
 	return sdk.Uint64ToBigEndian(uint64(height))
@@ -67,4 +85,39 @@ func GetContractBlockedListMemberKey(contractAddr sdk.AccAddress) []byte {
 // splitBlockedContractAddress splits the blocked contract address from a ContractBlockedListMemberKey
 func splitBlockedContractAddress(key []byte) sdk.AccAddress {
 	return key[1:]
-}
\ No newline at end of file
+}
+
+// GetFeeAllowanceKey builds the key for the fee allowance granted to a grantee
+func GetFeeAllowanceKey(grantee sdk.AccAddress) []byte {
+	return append(KeyPrefixFeeAllowance, grantee...)
+}
+
+// GetTxFeeAllowanceKey builds the key for the fee allowance charged the gas fee of the current evm tx of a sender
+func GetTxFeeAllowanceKey(sender sdk.AccAddress) []byte {
+	return append(KeyPrefixTxFeeAllowance, sender...)
+}
+
+// GetUpgradeSnapshotKey builds the key for the params and chain config saved before the migrations of an upgrade
+func GetUpgradeSnapshotKey(upgrade string) []byte {
+	return append(KeyPrefixUpgradeSnapshot, upgrade...)
+}
+
+// GetTokenRegistryKey builds the key for the metadata of a token of the registry
+func GetTokenRegistryKey(denom string) []byte {
+	return append(KeyPrefixTokenRegistry, denom...)
+}
+
+// GetGasPriceVoteKey builds the key for the gas price vote of a validator
+func GetGasPriceVoteKey(validator sdk.ValAddress) []byte {
+	return append(KeyPrefixGasPriceVote, validator...)
+}
+
+// GetContractGasQuotaKey builds the key for the gas per block quota of a contract
+func GetContractGasQuotaKey(contract sdk.AccAddress) []byte {
+	return append(KeyPrefixContractGasQuota, contract...)
+}
+
+// GetContractGasUsageKey builds the key for the gas consumed by a contract in the current block
+func GetContractGasUsageKey(contract sdk.AccAddress) []byte {
+	return append(KeyPrefixContractGasUsage, contract...)
+}
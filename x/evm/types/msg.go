@@ -201,7 +201,7 @@ type MsgEthereumTx struct {
 
 func (msg MsgEthereumTx) GetFee() sdk.Coins {
 	fee := make(sdk.Coins, 1)
-	fee[0] = sdk.NewCoin(sdk.DefaultBondDenom, sdk.NewDecFromBigIntWithPrec(msg.Fee(), sdk.Precision))
+	fee[0] = sdk.NewCoin(sdk.DefaultBondDenom, types.WeiToDec(msg.Fee()))
 	return fee
 }
 
@@ -475,10 +475,22 @@ func (msg *MsgEthereumTx) VerifySig(chainID *big.Int, height int64, sigCtx sdk.S
 		sigHash = msg.HomesteadSignHash()
 	}
 
+	cacheKey, cacheable := newSenderCacheKey(sigHash, msg.Data.V, msg.Data.R, msg.Data.S)
+	if cacheable {
+		if sender, ok := getCachedSender(cacheKey); ok {
+			sigCache := &ethSigCache{signer: signer, from: sender}
+			msg.from.Store(sigCache)
+			return sigCache, nil
+		}
+	}
+
 	sender, err := recoverEthSig(msg.Data.R, msg.Data.S, V, sigHash)
 	if err != nil {
 		return nil, err
 	}
+	if cacheable {
+		cacheSender(cacheKey, sender)
+	}
 	sigCache := &ethSigCache{signer: signer, from: sender}
 	msg.from.Store(sigCache)
 	return sigCache, nil
@@ -578,6 +590,11 @@ func (msg MsgEthereumTx) GetTxInfo(ctx sdk.Context) mempool.ExTxInfo {
 	return exTxInfo
 }
 
+// RecoverSender returns the sender recovered from the signature of the tx, empty if the signature is invalid
+func (msg MsgEthereumTx) RecoverSender(ctx sdk.Context) string {
+	return msg.GetTxInfo(ctx).Sender
+}
+
 // GetGasPrice return gas price
 func (msg MsgEthereumTx) GetGasPrice() *big.Int {
 	return msg.Data.Price
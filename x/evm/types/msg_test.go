@@ -224,6 +224,43 @@ func TestMsgEthereumTxSig(t *testing.T) {
 	require.Nil(t, signerCache)
 }
 
+func TestMsgEthereumTxSenderCache(t *testing.T) {
+	chainID := big.NewInt(3)
+	priv, _ := ethsecp256k1.GenerateKey()
+	addr := ethcmn.BytesToAddress(priv.PubKey().Address().Bytes())
+
+	msg := NewMsgEthereumTx(1, &addr, nil, 100000, nil, []byte("sender cache"))
+	require.NoError(t, msg.Sign(chainID, priv.ToECDSA()))
+
+	PopSenderCacheStats()
+	signerCache, err := msg.VerifySig(chainID, 0, nil)
+	require.NoError(t, err)
+	require.Equal(t, addr, signerCache.GetFrom())
+	hits, misses := PopSenderCacheStats()
+	require.Equal(t, uint64(0), hits)
+	require.Equal(t, uint64(1), misses)
+
+	// a new decoded copy of the tx is served from the cache
+	decoded := MsgEthereumTx{Data: msg.Data}
+	signerCache, err = decoded.VerifySig(chainID, 0, nil)
+	require.NoError(t, err)
+	require.Equal(t, addr, signerCache.GetFrom())
+	hits, misses = PopSenderCacheStats()
+	require.Equal(t, uint64(1), hits)
+	require.Equal(t, uint64(0), misses)
+
+	// the cached sender is bound to the chain id
+	decoded = MsgEthereumTx{Data: msg.Data}
+	_, err = decoded.VerifySig(big.NewInt(4), 0, nil)
+	require.Error(t, err)
+
+	// out-of-range signature values never hit the cache
+	decoded = MsgEthereumTx{Data: msg.Data}
+	decoded.Data.V = new(big.Int).Add(msg.Data.V, new(big.Int).Lsh(big.NewInt(1), 256))
+	_, err = decoded.VerifySig(chainID, 0, nil)
+	require.Error(t, err)
+}
+
 func TestMsgEthereumTx_ChainID(t *testing.T) {
 	chainID := big.NewInt(3)
 	priv, _ := ethsecp256k1.GenerateKey()
@@ -6,6 +6,7 @@ import (
 	"gopkg.in/yaml.v2"
 
 	"github.com/ethereum/go-ethereum/core/vm"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
 	"github.com/okex/exchain/x/params"
 )
 
@@ -15,6 +16,10 @@ const (
 	DefaultMaxGasLimitPerTx = 30000000
 )
 
+// DefaultMaxGasPriceChange is the default max rate the min gas price floor moves by per block, as the base fee
+// of EIP-1559
+var DefaultMaxGasPriceChange = sdk.NewDecWithPrec(125, 3)
+
 // Parameter keys
 var (
 	ParamStoreKeyEnableCreate                = []byte("EnableCreate")
@@ -23,6 +28,13 @@ var (
 	ParamStoreKeyContractDeploymentWhitelist = []byte("EnableContractDeploymentWhitelist")
 	ParamStoreKeyContractBlockedList         = []byte("EnableContractBlockedList")
 	ParamStoreKeyMaxGasLimitPerTx            = []byte("MaxGasLimitPerTx")
+	ParamStoreKeyBaseFee                     = []byte("BaseFee")
+	ParamStoreKeyMaxGasLimitPerBlock         = []byte("MaxGasLimitPerBlock")
+	ParamStoreKeyBlockHashWindow             = []byte("EnableBlockHashWindow")
+	ParamStoreKeyFeeDenoms                   = []byte("FeeDenoms")
+	ParamStoreKeyFeeConversionSpread         = []byte("FeeConversionSpread")
+	ParamStoreKeyGasPriceVoteWindow          = []byte("GasPriceVoteWindow")
+	ParamStoreKeyMaxGasPriceChange           = []byte("MaxGasPriceChange")
 )
 
 // ParamKeyTable returns the parameter key table.
@@ -44,6 +56,24 @@ type Params struct {
 	EnableContractBlockedList bool `json:"enable_contract_blocked_list" yaml:"enable_contract_blocked_list"`
 	// MaxGasLimit defines the max gas limit in transaction
 	MaxGasLimitPerTx uint64 `json:"max_gas_limit_per_tx" yaml:"max_gas_limit_per_tx"`
+	// BaseFee defines the base fee per gas, in wei, returned by the BASEFEE opcode once London is activated
+	BaseFee uint64 `json:"base_fee" yaml:"base_fee"`
+	// MaxGasLimitPerBlock defines the max gas of a block, 0 means no limit
+	MaxGasLimitPerBlock uint64 `json:"max_gas_limit_per_block" yaml:"max_gas_limit_per_block"`
+	// EnableBlockHashWindow limits BLOCKHASH to the last 256 blocks, served from a ring buffer,
+	// as on ethereum. When disabled any previous height is served from the height -> hash index
+	EnableBlockHashWindow bool `json:"enable_block_hash_window" yaml:"enable_block_hash_window"`
+	// FeeDenoms defines the alternate denoms, in order of preference, that pay the gas fee of the senders
+	// lacking the evm denom. They're converted into the evm denom through their ammswap pool with it
+	FeeDenoms []string `json:"fee_denoms" yaml:"fee_denoms"`
+	// FeeConversionSpread defines the fee rate of the conversions of the fee denoms, charged over the pool price
+	FeeConversionSpread sdk.Dec `json:"fee_conversion_spread" yaml:"fee_conversion_spread"`
+	// GasPriceVoteWindow defines the number of blocks the gas price votes of the validators count in the min
+	// gas price floor of the evm txs, 0 disables the floor
+	GasPriceVoteWindow uint64 `json:"gas_price_vote_window" yaml:"gas_price_vote_window"`
+	// MaxGasPriceChange defines the max rate the floor moves by per block towards the median of the votes,
+	// 0 lets it follow the median at once
+	MaxGasPriceChange sdk.Dec `json:"max_gas_price_change" yaml:"max_gas_price_change"`
 }
 
 // NewParams creates a new Params instance
@@ -56,6 +86,8 @@ func NewParams(enableCreate, enableCall, enableContractDeploymentWhitelist, enab
 		EnableContractDeploymentWhitelist: enableContractDeploymentWhitelist,
 		EnableContractBlockedList:         enableContractBlockedList,
 		MaxGasLimitPerTx:                  maxGasLimitPerTx,
+		FeeConversionSpread:               sdk.ZeroDec(),
+		MaxGasPriceChange:                 sdk.ZeroDec(),
 	}
 }
 
@@ -68,6 +100,13 @@ func DefaultParams() Params {
 		EnableContractDeploymentWhitelist: false,
 		EnableContractBlockedList:         false,
 		MaxGasLimitPerTx:                  DefaultMaxGasLimitPerTx,
+		BaseFee:                           0,
+		MaxGasLimitPerBlock:               0,
+		EnableBlockHashWindow:             false,
+		FeeDenoms:                         []string(nil),
+		FeeConversionSpread:               sdk.ZeroDec(),
+		GasPriceVoteWindow:                0,
+		MaxGasPriceChange:                 DefaultMaxGasPriceChange,
 	}
 }
 
@@ -86,14 +125,43 @@ func (p *Params) ParamSetPairs() params.ParamSetPairs {
 		params.NewParamSetPair(ParamStoreKeyContractDeploymentWhitelist, &p.EnableContractDeploymentWhitelist, validateBool),
 		params.NewParamSetPair(ParamStoreKeyContractBlockedList, &p.EnableContractBlockedList, validateBool),
 		params.NewParamSetPair(ParamStoreKeyMaxGasLimitPerTx, &p.MaxGasLimitPerTx, validateUint64),
+		params.NewParamSetPair(ParamStoreKeyBaseFee, &p.BaseFee, validateUint64),
+		params.NewParamSetPair(ParamStoreKeyMaxGasLimitPerBlock, &p.MaxGasLimitPerBlock, validateUint64),
+		params.NewParamSetPair(ParamStoreKeyBlockHashWindow, &p.EnableBlockHashWindow, validateBool),
+		params.NewParamSetPair(ParamStoreKeyFeeDenoms, &p.FeeDenoms, validateFeeDenoms),
+		params.NewParamSetPair(ParamStoreKeyFeeConversionSpread, &p.FeeConversionSpread, validateFeeConversionSpread),
+		params.NewParamSetPair(ParamStoreKeyGasPriceVoteWindow, &p.GasPriceVoteWindow, validateUint64),
+		params.NewParamSetPair(ParamStoreKeyMaxGasPriceChange, &p.MaxGasPriceChange, validateMaxGasPriceChange),
 	}
 }
 
 // Validate performs basic validation on evm parameters.
 func (p Params) Validate() error {
+	if p.MaxGasLimitPerBlock != 0 && p.MaxGasLimitPerTx > p.MaxGasLimitPerBlock {
+		return fmt.Errorf("max gas limit per tx %d exceeds max gas limit per block %d", p.MaxGasLimitPerTx, p.MaxGasLimitPerBlock)
+	}
+	if err := validateFeeDenoms(p.FeeDenoms); err != nil {
+		return err
+	}
+	if err := validateFeeConversionSpread(p.FeeConversionSpread); err != nil {
+		return err
+	}
+	if err := validateMaxGasPriceChange(p.MaxGasPriceChange); err != nil {
+		return err
+	}
 	return validateEIPs(p.ExtraEIPs)
 }
 
+// BlockGasLimit returns the gas limit of the blocks exposed to the ethereum clients. Without a
+// block gas limit it's the max uint32, so that javascript dev tooling supporting only up to
+// 53 bits doesn't error.
+func (p Params) BlockGasLimit() uint64 {
+	if p.MaxGasLimitPerBlock == 0 {
+		return uint64(^uint32(0))
+	}
+	return p.MaxGasLimitPerBlock
+}
+
 func validateBool(i interface{}) error {
 	_, ok := i.(bool)
 	if !ok {
@@ -124,3 +192,57 @@ func validateUint64(i interface{}) error {
 	}
 	return nil
 }
+
+func validateFeeDenoms(i interface{}) error {
+	denoms, ok := i.([]string)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+
+	seen := make(map[string]bool, len(denoms))
+	for _, denom := range denoms {
+		if err := sdk.ValidateDenom(denom); err != nil {
+			return fmt.Errorf("invalid fee denom %s: %w", denom, err)
+		}
+		if denom == sdk.DefaultBondDenom {
+			return fmt.Errorf("fee denom %s is the evm denom", denom)
+		}
+		if seen[denom] {
+			return fmt.Errorf("duplicate fee denom %s", denom)
+		}
+		seen[denom] = true
+	}
+	return nil
+}
+
+func validateFeeConversionSpread(i interface{}) error {
+	spread, ok := i.(sdk.Dec)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+
+	// the spread is unset in the params stored before it was introduced
+	if spread.IsNil() {
+		return nil
+	}
+	if spread.IsNegative() || spread.GTE(sdk.OneDec()) {
+		return fmt.Errorf("fee conversion spread must be in [0, 1): %s", spread)
+	}
+	return nil
+}
+
+func validateMaxGasPriceChange(i interface{}) error {
+	change, ok := i.(sdk.Dec)
+	if !ok {
+		return fmt.Errorf("invalid parameter type: %T", i)
+	}
+
+	// the change is unset in the params stored before it was introduced
+	if change.IsNil() {
+		return nil
+	}
+	if change.IsNegative() || change.GT(sdk.OneDec()) {
+		return fmt.Errorf("max gas price change must be in [0, 1]: %s", change)
+	}
+	return nil
+}
@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
 )
 
 func TestParamsValidate(t *testing.T) {
@@ -19,6 +21,44 @@ func TestParamsValidate(t *testing.T) {
 			NewParams(true, true, false, false, DefaultMaxGasLimitPerTx, 2929, 1884, 1344),
 			false,
 		},
+		{
+			"tx gas limit exceeding block gas limit",
+			Params{
+				MaxGasLimitPerTx:    DefaultMaxGasLimitPerTx,
+				MaxGasLimitPerBlock: DefaultMaxGasLimitPerTx - 1,
+			},
+			true,
+		},
+		{
+			"valid fee denoms",
+			Params{
+				FeeDenoms:           []string{"usdk", "btck"},
+				FeeConversionSpread: sdk.NewDecWithPrec(1, 2),
+			},
+			false,
+		},
+		{
+			"fee denom is the evm denom",
+			Params{
+				FeeDenoms: []string{sdk.DefaultBondDenom},
+			},
+			true,
+		},
+		{
+			"duplicate fee denom",
+			Params{
+				FeeDenoms: []string{"usdk", "usdk"},
+			},
+			true,
+		},
+		{
+			"fee conversion spread out of range",
+			Params{
+				FeeDenoms:           []string{"usdk"},
+				FeeConversionSpread: sdk.OneDec(),
+			},
+			true,
+		},
 		{
 			"invalid eip",
 			Params{
@@ -46,6 +86,15 @@ func TestParamsValidatePriv(t *testing.T) {
 	require.NoError(t, validateEIPs([]int{1884}))
 	require.NoError(t, validateUint64(uint64(30000000)))
 	require.Error(t, validateUint64("test"))
+	require.NoError(t, validateFeeDenoms([]string(nil)))
+	require.Error(t, validateFeeDenoms("usdk"))
+	require.Error(t, validateFeeDenoms([]string{"Invalid Denom"}))
+	require.NoError(t, validateFeeConversionSpread(sdk.Dec{}))
+	require.Error(t, validateFeeConversionSpread(sdk.NewDecWithPrec(-1, 2)))
+	require.NoError(t, validateMaxGasPriceChange(sdk.Dec{}))
+	require.NoError(t, validateMaxGasPriceChange(sdk.OneDec()))
+	require.Error(t, validateMaxGasPriceChange(sdk.NewDecWithPrec(-1, 2)))
+	require.Error(t, validateMaxGasPriceChange(sdk.NewDecWithPrec(101, 2)))
 }
 
 func TestParams_String(t *testing.T) {
@@ -55,6 +104,21 @@ extra_eips: []
 enable_contract_deployment_whitelist: false
 enable_contract_blocked_list: false
 max_gas_limit_per_tx: 30000000
+base_fee: 0
+max_gas_limit_per_block: 0
+enable_block_hash_window: false
+fee_denoms: []
+fee_conversion_spread: "0.000000000000000000"
+gas_price_vote_window: 0
+max_gas_price_change: "0.125000000000000000"
 `
 	require.True(t, strings.EqualFold(expectedParamsStr, DefaultParams().String()))
 }
+
+func TestParams_BlockGasLimit(t *testing.T) {
+	params := DefaultParams()
+	require.Equal(t, uint64(^uint32(0)), params.BlockGasLimit())
+
+	params.MaxGasLimitPerBlock = 40000000
+	require.Equal(t, uint64(40000000), params.BlockGasLimit())
+}
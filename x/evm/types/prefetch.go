@@ -0,0 +1,94 @@
+package types
+
+import (
+	"sync/atomic"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/spf13/viper"
+)
+
+const (
+	FlagEnablePrefetch      = "evm-prefetch"
+	FlagPrefetchHistorySize = "evm-prefetch-history-size"
+
+	DefaultPrefetchHistorySize = 4096
+	// maxPrefetchSlots bounds the storage slots remembered per tx recipient
+	maxPrefetchSlots = 256
+)
+
+var (
+	// prefetchHistory keeps the storage slots touched by the recent txs of each recipient, see RecordTouchedSlots
+	prefetchHistory *lru.Cache
+
+	prefetchedSlots uint64
+)
+
+// PrefetchSlot is the store key of a storage slot of a contract, as returned by GetStorageByAddressKey
+type PrefetchSlot struct {
+	Address ethcmn.Address
+	Key     ethcmn.Hash
+}
+
+// InitPrefetch creates the history of the prefetch if it is enabled in the node config
+func InitPrefetch() {
+	prefetchHistory = nil
+	if !viper.GetBool(FlagEnablePrefetch) {
+		return
+	}
+	size := viper.GetInt(FlagPrefetchHistorySize)
+	if size <= 0 {
+		size = DefaultPrefetchHistorySize
+	}
+	prefetchHistory, _ = lru.New(size)
+}
+
+// IsPrefetchEnabled returns true if the state read by the evm txs of a block is prefetched before their execution
+func IsPrefetchEnabled() bool {
+	return prefetchHistory != nil
+}
+
+// RecordTouchedSlots remembers the storage slots touched by a tx sent to the recipient, so that they are
+// prefetched for the next txs sent to it. The slots of the previous txs are kept after the new ones, up to
+// maxPrefetchSlots.
+func RecordTouchedSlots(recipient ethcmn.Address, slots []PrefetchSlot) {
+	if prefetchHistory == nil || len(slots) == 0 {
+		return
+	}
+
+	seen := make(map[PrefetchSlot]struct{}, len(slots))
+	merged := make([]PrefetchSlot, 0, len(slots))
+	add := func(slots []PrefetchSlot) {
+		for _, slot := range slots {
+			if _, ok := seen[slot]; ok || len(merged) == maxPrefetchSlots {
+				continue
+			}
+			seen[slot] = struct{}{}
+			merged = append(merged, slot)
+		}
+	}
+	add(slots)
+	add(RecentSlots(recipient))
+	prefetchHistory.Add(recipient, merged)
+}
+
+// RecentSlots returns the storage slots touched by the recent txs sent to the recipient
+func RecentSlots(recipient ethcmn.Address) []PrefetchSlot {
+	if prefetchHistory == nil {
+		return nil
+	}
+	if slots, ok := prefetchHistory.Peek(recipient); ok {
+		return slots.([]PrefetchSlot)
+	}
+	return nil
+}
+
+// AddPrefetchedSlots counts the storage slots prefetched
+func AddPrefetchedSlots(n int) {
+	atomic.AddUint64(&prefetchedSlots, uint64(n))
+}
+
+// PopPrefetchStats returns the number of storage slots prefetched since the last call
+func PopPrefetchStats() uint64 {
+	return atomic.SwapUint64(&prefetchedSlots, 0)
+}
@@ -0,0 +1,48 @@
+package types_test
+
+import (
+	"math/big"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/viper"
+
+	"github.com/okex/exchain/x/evm/types"
+)
+
+func (suite *StateDBTestSuite) TestPrefetchHistory() {
+	recipient := ethcmn.BytesToAddress([]byte("recipient"))
+	defer func() {
+		viper.Reset()
+		types.InitPrefetch()
+	}()
+
+	// nothing is recorded while the prefetch is disabled
+	types.InitPrefetch()
+	suite.Require().False(types.IsPrefetchEnabled())
+	types.RecordTouchedSlots(recipient, []types.PrefetchSlot{{Address: recipient}})
+	suite.Require().Empty(types.RecentSlots(recipient))
+
+	viper.Set(types.FlagEnablePrefetch, true)
+	types.InitPrefetch()
+	suite.Require().True(types.IsPrefetchEnabled())
+
+	// the slots loaded by the tx are recorded, including the written ones
+	csdb := types.CreateEmptyCommitStateDB(suite.app.EvmKeeper.GenerateCSDBParams(), suite.ctx)
+	csdb.GetState(suite.address, ethcmn.BytesToHash([]byte("read")))
+	csdb.SetState(suite.address, ethcmn.BytesToHash([]byte("written")), ethcmn.BytesToHash([]byte("value")))
+	slots := csdb.TouchedSlots()
+	suite.Require().Len(slots, 2)
+	types.RecordTouchedSlots(recipient, slots)
+	suite.Require().Equal(slots, types.RecentSlots(recipient))
+
+	// the slots of the next txs come first, without duplicates, up to a bound
+	var next []types.PrefetchSlot
+	for i := 0; i < 300; i++ {
+		next = append(next, types.PrefetchSlot{Address: recipient, Key: ethcmn.BigToHash(big.NewInt(int64(i)))})
+	}
+	types.RecordTouchedSlots(recipient, []types.PrefetchSlot{next[0], slots[0]})
+	suite.Require().Equal([]types.PrefetchSlot{next[0], slots[0], slots[1]}, types.RecentSlots(recipient))
+	types.RecordTouchedSlots(recipient, next)
+	suite.Require().Len(types.RecentSlots(recipient), 256)
+	suite.Require().Equal(next[0], types.RecentSlots(recipient)[0])
+}
@@ -0,0 +1,161 @@
+package types
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/spf13/viper"
+)
+
+const (
+	FlagEnableEvmProfiler = "evm-profiler-enable"
+)
+
+var (
+	enableEvmProfiler bool
+	evmProfiler       = newBlockProfiler()
+)
+
+// InitEvmProfiler loads the evm profiler switch from the node config
+func InitEvmProfiler() {
+	enableEvmProfiler = viper.GetBool(FlagEnableEvmProfiler)
+}
+
+// IsEvmProfilerEnabled returns whether the evm executions of the delivered txs are profiled
+func IsEvmProfilerEnabled() bool {
+	return enableEvmProfiler
+}
+
+// ContractProfile is the execution profile of a contract. GasUsed includes the gas forwarded to nested calls.
+type ContractProfile struct {
+	Address common.Address `json:"address"`
+	GasUsed uint64         `json:"gas_used"`
+	Calls   uint64         `json:"calls"`
+}
+
+// BlockProfile is the evm execution profile aggregated over a block, with the contracts sorted by gas used
+type BlockProfile struct {
+	Height    int64             `json:"height"`
+	Contracts []ContractProfile `json:"contracts"`
+	Opcodes   map[string]uint64 `json:"opcodes"`
+}
+
+// txProfiler implements vm.Tracer to collect the profile of a single evm execution
+type txProfiler struct {
+	contracts map[common.Address]*ContractProfile
+	opcodes   map[vm.OpCode]uint64
+	depth     int
+}
+
+func newTxProfiler() *txProfiler {
+	return &txProfiler{
+		contracts: make(map[common.Address]*ContractProfile),
+		opcodes:   make(map[vm.OpCode]uint64),
+	}
+}
+
+func (p *txProfiler) CaptureStart(*vm.EVM, common.Address, common.Address, bool, []byte, uint64, *big.Int) {
+}
+
+func (p *txProfiler) CaptureState(_ *vm.EVM, _ uint64, op vm.OpCode, _, cost uint64, scope *vm.ScopeContext, _ []byte, depth int, _ error) {
+	addr := scope.Contract.Address()
+	cp, ok := p.contracts[addr]
+	if !ok {
+		cp = &ContractProfile{Address: addr}
+		p.contracts[addr] = cp
+	}
+	// a deeper step is the first one of a new call frame
+	if depth > p.depth {
+		cp.Calls++
+	}
+	p.depth = depth
+
+	cp.GasUsed += cost
+	p.opcodes[op]++
+}
+
+func (p *txProfiler) CaptureFault(*vm.EVM, uint64, vm.OpCode, uint64, uint64, *vm.ScopeContext, int, error) {
+}
+
+func (p *txProfiler) CaptureEnd([]byte, uint64, time.Duration, error) {
+}
+
+type blockProfiler struct {
+	mtx       sync.Mutex
+	contracts map[common.Address]*ContractProfile
+	opcodes   map[vm.OpCode]uint64
+	last      BlockProfile
+}
+
+func newBlockProfiler() *blockProfiler {
+	return &blockProfiler{
+		contracts: make(map[common.Address]*ContractProfile),
+		opcodes:   make(map[vm.OpCode]uint64),
+	}
+}
+
+func (bp *blockProfiler) merge(p *txProfiler) {
+	bp.mtx.Lock()
+	defer bp.mtx.Unlock()
+
+	for addr, cp := range p.contracts {
+		if acc, ok := bp.contracts[addr]; ok {
+			acc.GasUsed += cp.GasUsed
+			acc.Calls += cp.Calls
+		} else {
+			c := *cp
+			bp.contracts[addr] = &c
+		}
+	}
+	for op, count := range p.opcodes {
+		bp.opcodes[op] += count
+	}
+}
+
+func (bp *blockProfiler) commit(height int64) BlockProfile {
+	bp.mtx.Lock()
+	defer bp.mtx.Unlock()
+
+	profile := BlockProfile{
+		Height:    height,
+		Contracts: make([]ContractProfile, 0, len(bp.contracts)),
+		Opcodes:   make(map[string]uint64, len(bp.opcodes)),
+	}
+	for _, cp := range bp.contracts {
+		profile.Contracts = append(profile.Contracts, *cp)
+	}
+	sort.Slice(profile.Contracts, func(i, j int) bool {
+		if profile.Contracts[i].GasUsed != profile.Contracts[j].GasUsed {
+			return profile.Contracts[i].GasUsed > profile.Contracts[j].GasUsed
+		}
+		return profile.Contracts[i].Address.Hex() < profile.Contracts[j].Address.Hex()
+	})
+	for op, count := range bp.opcodes {
+		profile.Opcodes[op.String()] = count
+	}
+
+	bp.contracts = make(map[common.Address]*ContractProfile)
+	bp.opcodes = make(map[vm.OpCode]uint64)
+	bp.last = profile
+	return profile
+}
+
+func (bp *blockProfiler) lastProfile() BlockProfile {
+	bp.mtx.Lock()
+	defer bp.mtx.Unlock()
+	return bp.last
+}
+
+// CommitEvmProfile closes the profile of the block at the given height and starts a new one
+func CommitEvmProfile(height int64) BlockProfile {
+	return evmProfiler.commit(height)
+}
+
+// GetLastEvmProfile returns the profile of the last committed block
+func GetLastEvmProfile() BlockProfile {
+	return evmProfiler.lastProfile()
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	sdkerrors "github.com/okex/exchain/libs/cosmos-sdk/types/errors"
 	govtypes "github.com/okex/exchain/x/gov/types"
 )
 
@@ -16,21 +17,36 @@ const (
 	proposalTypeManageContractBlockedList = "ManageContractBlockedList"
 	// proposalTypeManageContractMethodBlockedList defines the type for a ManageContractMethodBlockedList
 	proposalTypeManageContractMethodBlockedList = "ManageContractMethodBlockedList"
+	// proposalTypeManageChainConfigForks defines the type for a ManageChainConfigForksProposal
+	proposalTypeManageChainConfigForks = "ManageChainConfigForks"
+	// proposalTypeManageTokenRegistry defines the type for a ManageTokenRegistryProposal
+	proposalTypeManageTokenRegistry = "ManageTokenRegistry"
+	// proposalTypeManageContractGasQuota defines the type for a ManageContractGasQuotaProposal
+	proposalTypeManageContractGasQuota = "ManageContractGasQuota"
 )
 
 func init() {
 	govtypes.RegisterProposalType(proposalTypeManageContractDeploymentWhitelist)
 	govtypes.RegisterProposalType(proposalTypeManageContractBlockedList)
 	govtypes.RegisterProposalType(proposalTypeManageContractMethodBlockedList)
+	govtypes.RegisterProposalType(proposalTypeManageChainConfigForks)
+	govtypes.RegisterProposalType(proposalTypeManageTokenRegistry)
+	govtypes.RegisterProposalType(proposalTypeManageContractGasQuota)
 	govtypes.RegisterProposalTypeCodec(ManageContractDeploymentWhitelistProposal{}, "okexchain/evm/ManageContractDeploymentWhitelistProposal")
 	govtypes.RegisterProposalTypeCodec(ManageContractBlockedListProposal{}, "okexchain/evm/ManageContractBlockedListProposal")
 	govtypes.RegisterProposalTypeCodec(ManageContractMethodBlockedListProposal{}, "okexchain/evm/ManageContractMethodBlockedListProposal")
+	govtypes.RegisterProposalTypeCodec(ManageChainConfigForksProposal{}, ManageChainConfigForksProposalName)
+	govtypes.RegisterProposalTypeCodec(ManageTokenRegistryProposal{}, ManageTokenRegistryProposalName)
+	govtypes.RegisterProposalTypeCodec(ManageContractGasQuotaProposal{}, ManageContractGasQuotaProposalName)
 }
 
 var (
 	_ govtypes.Content = (*ManageContractDeploymentWhitelistProposal)(nil)
 	_ govtypes.Content = (*ManageContractBlockedListProposal)(nil)
 	_ govtypes.Content = (*ManageContractMethodBlockedListProposal)(nil)
+	_ govtypes.Content = (*ManageChainConfigForksProposal)(nil)
+	_ govtypes.Content = (*ManageTokenRegistryProposal)(nil)
+	_ govtypes.Content = (*ManageContractGasQuotaProposal)(nil)
 )
 
 // ManageContractDeploymentWhitelistProposal - structure for the proposal to add or delete deployer addresses from whitelist
@@ -343,3 +359,355 @@ func (mp ManageContractMethodBlockedListProposal) String() string {
 
 	return strings.TrimSpace(builder.String())
 }
+
+// ManageChainConfigForksProposal - structure for the proposal to schedule the berlin, london and shanghai switch blocks
+// of the chain config
+type ManageChainConfigForksProposal struct {
+	Title         string  `json:"title" yaml:"title"`
+	Description   string  `json:"description" yaml:"description"`
+	BerlinBlock   sdk.Int `json:"berlin_block" yaml:"berlin_block"`
+	LondonBlock   sdk.Int `json:"london_block" yaml:"london_block"`
+	ShanghaiBlock sdk.Int `json:"shanghai_block" yaml:"shanghai_block"`
+}
+
+// NewManageChainConfigForksProposal creates a new instance of ManageChainConfigForksProposal
+func NewManageChainConfigForksProposal(title, description string, berlinBlock, londonBlock, shanghaiBlock sdk.Int,
+) ManageChainConfigForksProposal {
+	return ManageChainConfigForksProposal{
+		Title:         title,
+		Description:   description,
+		BerlinBlock:   berlinBlock,
+		LondonBlock:   londonBlock,
+		ShanghaiBlock: shanghaiBlock,
+	}
+}
+
+// GetTitle returns title of a manage chain config forks proposal object
+func (mp ManageChainConfigForksProposal) GetTitle() string {
+	return mp.Title
+}
+
+// GetDescription returns description of a manage chain config forks proposal object
+func (mp ManageChainConfigForksProposal) GetDescription() string {
+	return mp.Description
+}
+
+// ProposalRoute returns route key of a manage chain config forks proposal object
+func (mp ManageChainConfigForksProposal) ProposalRoute() string {
+	return RouterKey
+}
+
+// ProposalType returns type of a manage chain config forks proposal object
+func (mp ManageChainConfigForksProposal) ProposalType() string {
+	return proposalTypeManageChainConfigForks
+}
+
+// ValidateBasic validates a manage chain config forks proposal
+func (mp ManageChainConfigForksProposal) ValidateBasic() sdk.Error {
+	if len(strings.TrimSpace(mp.Title)) == 0 {
+		return govtypes.ErrInvalidProposalContent("title is required")
+	}
+	if len(mp.Title) > govtypes.MaxTitleLength {
+		return govtypes.ErrInvalidProposalContent("title length is longer than the maximum title length")
+	}
+
+	if len(mp.Description) == 0 {
+		return govtypes.ErrInvalidProposalContent("description is required")
+	}
+
+	if len(mp.Description) > govtypes.MaxDescriptionLength {
+		return govtypes.ErrInvalidProposalContent("description length is longer than the maximum description length")
+	}
+
+	if mp.ProposalType() != proposalTypeManageChainConfigForks {
+		return govtypes.ErrInvalidProposalType(mp.ProposalType())
+	}
+
+	if err := validateBlock(mp.BerlinBlock); err != nil {
+		return sdkerrors.Wrap(err, "berlinBlock")
+	}
+	if err := validateBlock(mp.LondonBlock); err != nil {
+		return sdkerrors.Wrap(err, "londonBlock")
+	}
+	if err := validateBlock(mp.ShanghaiBlock); err != nil {
+		return sdkerrors.Wrap(err, "shanghaiBlock")
+	}
+
+	return ChainConfig{
+		BerlinBlock:   mp.BerlinBlock,
+		LondonBlock:   mp.LondonBlock,
+		ShanghaiBlock: mp.ShanghaiBlock,
+	}.checkForkOrder()
+}
+
+// String returns a human readable string representation of a ManageChainConfigForksProposal
+func (mp ManageChainConfigForksProposal) String() string {
+	return fmt.Sprintf(`ManageChainConfigForksProposal:
+ Title:					%s
+ Description:        	%s
+ Type:                	%s
+ BerlinBlock:			%s
+ LondonBlock:			%s
+ ShanghaiBlock:			%s`,
+		mp.Title, mp.Description, mp.ProposalType(), mp.BerlinBlock, mp.LondonBlock, mp.ShanghaiBlock)
+}
+
+// UpdateForks returns the chain config with the switch blocks of the proposal. A fork which is already activated at
+// the given height can't be moved, and a fork can only be scheduled above that height.
+func (mp ManageChainConfigForksProposal) UpdateForks(config ChainConfig, height int64) (ChainConfig, error) {
+	var err error
+	if config.BerlinBlock, err = updateForkBlock(config.BerlinBlock, mp.BerlinBlock, height); err != nil {
+		return config, sdkerrors.Wrap(err, "berlinBlock")
+	}
+	if config.LondonBlock, err = updateForkBlock(config.LondonBlock, mp.LondonBlock, height); err != nil {
+		return config, sdkerrors.Wrap(err, "londonBlock")
+	}
+	if config.ShanghaiBlock, err = updateForkBlock(config.ShanghaiBlock, mp.ShanghaiBlock, height); err != nil {
+		return config, sdkerrors.Wrap(err, "shanghaiBlock")
+	}
+
+	return config, config.Validate()
+}
+
+func updateForkBlock(current, proposed sdk.Int, height int64) (sdk.Int, error) {
+	if current.Equal(proposed) {
+		return current, nil
+	}
+
+	if block := getBlockValue(current); block != nil && block.Int64() <= height {
+		return current, sdkerrors.Wrapf(ErrInvalidChainConfig, "fork already activated at block %s", current)
+	}
+	if block := getBlockValue(proposed); block != nil && block.Int64() <= height {
+		return current, sdkerrors.Wrapf(ErrInvalidChainConfig, "fork block %s must be above the current height %d", proposed, height)
+	}
+
+	return proposed, nil
+}
+
+// ManageTokenRegistryProposal - structure for the proposal to set or delete the erc20 metadata of the ibc denoms and
+// bridged assets of the token registry
+type ManageTokenRegistryProposal struct {
+	Title       string `json:"title" yaml:"title"`
+	Description string `json:"description" yaml:"description"`
+	// Tokens are the metadata set into the registry, only their denom is used when they are deleted
+	Tokens  []TokenMetadata `json:"tokens" yaml:"tokens"`
+	IsAdded bool            `json:"is_added" yaml:"is_added"`
+}
+
+// NewManageTokenRegistryProposal creates a new instance of ManageTokenRegistryProposal
+func NewManageTokenRegistryProposal(title, description string, tokens []TokenMetadata, isAdded bool,
+) ManageTokenRegistryProposal {
+	return ManageTokenRegistryProposal{
+		Title:       title,
+		Description: description,
+		Tokens:      tokens,
+		IsAdded:     isAdded,
+	}
+}
+
+// GetTitle returns title of a manage token registry proposal object
+func (mp ManageTokenRegistryProposal) GetTitle() string {
+	return mp.Title
+}
+
+// GetDescription returns description of a manage token registry proposal object
+func (mp ManageTokenRegistryProposal) GetDescription() string {
+	return mp.Description
+}
+
+// ProposalRoute returns route key of a manage token registry proposal object
+func (mp ManageTokenRegistryProposal) ProposalRoute() string {
+	return RouterKey
+}
+
+// ProposalType returns type of a manage token registry proposal object
+func (mp ManageTokenRegistryProposal) ProposalType() string {
+	return proposalTypeManageTokenRegistry
+}
+
+// ValidateBasic validates a manage token registry proposal
+func (mp ManageTokenRegistryProposal) ValidateBasic() sdk.Error {
+	if len(strings.TrimSpace(mp.Title)) == 0 {
+		return govtypes.ErrInvalidProposalContent("title is required")
+	}
+	if len(mp.Title) > govtypes.MaxTitleLength {
+		return govtypes.ErrInvalidProposalContent("title length is longer than the maximum title length")
+	}
+
+	if len(mp.Description) == 0 {
+		return govtypes.ErrInvalidProposalContent("description is required")
+	}
+
+	if len(mp.Description) > govtypes.MaxDescriptionLength {
+		return govtypes.ErrInvalidProposalContent("description length is longer than the maximum description length")
+	}
+
+	if mp.ProposalType() != proposalTypeManageTokenRegistry {
+		return govtypes.ErrInvalidProposalType(mp.ProposalType())
+	}
+
+	if len(mp.Tokens) == 0 {
+		return sdkerrors.Wrap(ErrInvalidTokenMetadata, "empty token list")
+	}
+	if len(mp.Tokens) > maxAddressListLength {
+		return sdkerrors.Wrapf(ErrInvalidTokenMetadata, "the length of the token list %d is larger than the max limitation %d",
+			len(mp.Tokens), maxAddressListLength)
+	}
+
+	denoms := make(map[string]bool, len(mp.Tokens))
+	for _, token := range mp.Tokens {
+		if denoms[token.Denom] {
+			return sdkerrors.Wrapf(ErrInvalidTokenMetadata, "duplicated denom %s", token.Denom)
+		}
+		denoms[token.Denom] = true
+
+		var err error
+		if mp.IsAdded {
+			err = token.Validate()
+		} else {
+			err = validateTokenDenom(token.Denom)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// String returns a human readable string representation of a ManageTokenRegistryProposal
+func (mp ManageTokenRegistryProposal) String() string {
+	var builder strings.Builder
+	builder.WriteString(
+		fmt.Sprintf(`ManageTokenRegistryProposal:
+ Title:					%s
+ Description:        	%s
+ Type:                	%s
+ IsAdded:				%t
+ Tokens:
+`,
+			mp.Title, mp.Description, mp.ProposalType(), mp.IsAdded),
+	)
+
+	for _, token := range mp.Tokens {
+		builder.WriteString("\t\t\t\t\t\t")
+		builder.WriteString(token.Denom)
+		builder.Write([]byte{'\n'})
+	}
+
+	return strings.TrimSpace(builder.String())
+}
+
+// ManageContractGasQuotaProposal - structure for the proposal to set or delete the gas per block quotas of contracts
+type ManageContractGasQuotaProposal struct {
+	Title       string `json:"title" yaml:"title"`
+	Description string `json:"description" yaml:"description"`
+	// Quotas are the quotas set to the contracts, only their address is used when they are deleted
+	Quotas  []ContractGasQuota `json:"quotas" yaml:"quotas"`
+	IsAdded bool               `json:"is_added" yaml:"is_added"`
+}
+
+// NewManageContractGasQuotaProposal creates a new instance of ManageContractGasQuotaProposal
+func NewManageContractGasQuotaProposal(title, description string, quotas []ContractGasQuota, isAdded bool,
+) ManageContractGasQuotaProposal {
+	return ManageContractGasQuotaProposal{
+		Title:       title,
+		Description: description,
+		Quotas:      quotas,
+		IsAdded:     isAdded,
+	}
+}
+
+// GetTitle returns title of a manage contract gas quota proposal object
+func (mp ManageContractGasQuotaProposal) GetTitle() string {
+	return mp.Title
+}
+
+// GetDescription returns description of a manage contract gas quota proposal object
+func (mp ManageContractGasQuotaProposal) GetDescription() string {
+	return mp.Description
+}
+
+// ProposalRoute returns route key of a manage contract gas quota proposal object
+func (mp ManageContractGasQuotaProposal) ProposalRoute() string {
+	return RouterKey
+}
+
+// ProposalType returns type of a manage contract gas quota proposal object
+func (mp ManageContractGasQuotaProposal) ProposalType() string {
+	return proposalTypeManageContractGasQuota
+}
+
+// ValidateBasic validates a manage contract gas quota proposal
+func (mp ManageContractGasQuotaProposal) ValidateBasic() sdk.Error {
+	if len(strings.TrimSpace(mp.Title)) == 0 {
+		return govtypes.ErrInvalidProposalContent("title is required")
+	}
+	if len(mp.Title) > govtypes.MaxTitleLength {
+		return govtypes.ErrInvalidProposalContent("title length is longer than the maximum title length")
+	}
+
+	if len(mp.Description) == 0 {
+		return govtypes.ErrInvalidProposalContent("description is required")
+	}
+
+	if len(mp.Description) > govtypes.MaxDescriptionLength {
+		return govtypes.ErrInvalidProposalContent("description length is longer than the maximum description length")
+	}
+
+	if mp.ProposalType() != proposalTypeManageContractGasQuota {
+		return govtypes.ErrInvalidProposalType(mp.ProposalType())
+	}
+
+	quotaLen := len(mp.Quotas)
+	if quotaLen == 0 {
+		return ErrEmptyAddressList
+	}
+
+	if quotaLen > maxAddressListLength {
+		return ErrOversizeAddrList(quotaLen)
+	}
+
+	addrs := make(AddressList, 0, quotaLen)
+	for _, quota := range mp.Quotas {
+		addrs = append(addrs, quota.Address)
+		if !mp.IsAdded {
+			if quota.Address.Empty() {
+				return sdkerrors.Wrap(sdkerrors.ErrInvalidAddress, "contract address can't be empty")
+			}
+			continue
+		}
+		if err := quota.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if isAddrDuplicated(addrs) {
+		return ErrDuplicatedAddr
+	}
+
+	return nil
+}
+
+// String returns a human readable string representation of a ManageContractGasQuotaProposal
+func (mp ManageContractGasQuotaProposal) String() string {
+	var builder strings.Builder
+	builder.WriteString(
+		fmt.Sprintf(`ManageContractGasQuotaProposal:
+ Title:					%s
+ Description:        	%s
+ Type:                	%s
+ IsAdded:				%t
+ Quotas:
+`,
+			mp.Title, mp.Description, mp.ProposalType(), mp.IsAdded),
+	)
+
+	for _, quota := range mp.Quotas {
+		builder.WriteString("\t\t\t\t\t\t")
+		builder.WriteString(fmt.Sprintf("%s: %d", quota.Address, quota.GasPerBlock))
+		builder.Write([]byte{'\n'})
+	}
+
+	return strings.TrimSpace(builder.String())
+}
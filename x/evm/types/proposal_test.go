@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	ethcmn "github.com/ethereum/go-ethereum/common"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
 	govtypes "github.com/okex/exchain/x/gov/types"
 	"github.com/stretchr/testify/suite"
 )
@@ -404,3 +405,127 @@ func (suite *ProposalTestSuite) TestProposal_ManageContractMethodBlockedListProp
 		})
 	}
 }
+
+func (suite *ProposalTestSuite) TestProposal_ManageChainConfigForksProposal() {
+	testCases := []struct {
+		msg           string
+		berlinBlock   sdk.Int
+		londonBlock   sdk.Int
+		shanghaiBlock sdk.Int
+		expectedErr   bool
+	}{
+		{"schedule all forks", sdk.NewInt(10), sdk.NewInt(20), sdk.NewInt(30), false},
+		{"schedule berlin only", sdk.NewInt(10), sdk.NewInt(-1), sdk.NewInt(-1), false},
+		{"london before berlin", sdk.NewInt(20), sdk.NewInt(10), sdk.NewInt(-1), true},
+		{"london without berlin", sdk.NewInt(-1), sdk.NewInt(10), sdk.NewInt(-1), true},
+		{"shanghai before london", sdk.NewInt(10), sdk.NewInt(20), sdk.NewInt(15), true},
+		{"shanghai without london", sdk.NewInt(10), sdk.NewInt(-1), sdk.NewInt(20), true},
+		{"uninitialized block", sdk.Int{}, sdk.NewInt(-1), sdk.NewInt(-1), true},
+		{"uninitialized shanghai block", sdk.NewInt(10), sdk.NewInt(-1), sdk.Int{}, true},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.msg, func() {
+			proposal := NewManageChainConfigForksProposal(expectedTitle, expectedDescription, tc.berlinBlock, tc.londonBlock,
+				tc.shanghaiBlock)
+			suite.Require().Equal(RouterKey, proposal.ProposalRoute())
+			suite.Require().Equal(proposalTypeManageChainConfigForks, proposal.ProposalType())
+			if tc.expectedErr {
+				suite.Require().Error(proposal.ValidateBasic())
+			} else {
+				suite.Require().NoError(proposal.ValidateBasic())
+			}
+		})
+	}
+
+	// forks can't be moved once activated, nor scheduled at or below the current height
+	config := DefaultChainConfig()
+	proposal := NewManageChainConfigForksProposal(expectedTitle, expectedDescription, sdk.NewInt(10), sdk.NewInt(-1),
+		sdk.NewInt(-1))
+	_, err := proposal.UpdateForks(config, 10)
+	suite.Require().Error(err)
+	config, err = proposal.UpdateForks(config, 9)
+	suite.Require().NoError(err)
+	suite.Require().Equal(sdk.NewInt(10), config.BerlinBlock)
+
+	proposal.BerlinBlock = sdk.NewInt(100)
+	_, err = proposal.UpdateForks(config, 10)
+	suite.Require().Error(err)
+	updated, err := proposal.UpdateForks(config, 9)
+	suite.Require().NoError(err)
+	suite.Require().Equal(sdk.NewInt(100), updated.BerlinBlock)
+}
+
+func (suite *ProposalTestSuite) TestProposal_ManageTokenRegistryProposal() {
+	atom := TokenMetadata{
+		Denom:       "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2",
+		Name:        "Cosmos Hub Atom",
+		Symbol:      "ATOM",
+		Decimals:    6,
+		OriginChain: "cosmoshub-4",
+		OriginDenom: "uatom",
+	}
+	withContract := atom
+	withContract.Contract = "0xc0ffee0000000000000000000000000000000000"
+	invalidContract := atom
+	invalidContract.Contract = "0xc0ffee"
+	noSymbol := atom
+	noSymbol.Symbol = ""
+	noOrigin := atom
+	noOrigin.OriginChain = " "
+	tooManyDecimals := atom
+	tooManyDecimals.Decimals = 78
+	spacedDenom := atom
+	spacedDenom.Denom = "ibc/ 2739"
+
+	testCases := []struct {
+		msg         string
+		tokens      []TokenMetadata
+		isAdded     bool
+		expectedErr bool
+	}{
+		{"set a token", []TokenMetadata{atom}, true, false},
+		{"set a token with its contract", []TokenMetadata{withContract}, true, false},
+		{"delete a token by its denom", []TokenMetadata{{Denom: atom.Denom}}, false, false},
+		{"no token", nil, true, true},
+		{"duplicated denom", []TokenMetadata{atom, withContract}, true, true},
+		{"invalid contract", []TokenMetadata{invalidContract}, true, true},
+		{"no symbol", []TokenMetadata{noSymbol}, true, true},
+		{"no origin chain", []TokenMetadata{noOrigin}, true, true},
+		{"too many decimals", []TokenMetadata{tooManyDecimals}, true, true},
+		{"invalid denom", []TokenMetadata{spacedDenom}, false, true},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.msg, func() {
+			proposal := NewManageTokenRegistryProposal("default title", "default description", tc.tokens, tc.isAdded)
+			suite.Require().Equal(tc.expectedErr, proposal.ValidateBasic() != nil)
+		})
+	}
+}
+
+func (suite *ProposalTestSuite) TestProposal_ManageContractGasQuotaProposal() {
+	contract1, contract2 := sdk.AccAddress(ethcmn.BytesToAddress([]byte{0x1}).Bytes()), sdk.AccAddress(ethcmn.BytesToAddress([]byte{0x2}).Bytes())
+
+	testCases := []struct {
+		msg         string
+		quotas      []ContractGasQuota
+		isAdded     bool
+		expectedErr bool
+	}{
+		{"set quotas", []ContractGasQuota{NewContractGasQuota(contract1, 1000000), NewContractGasQuota(contract2, 1)}, true, false},
+		{"delete a quota by its address", []ContractGasQuota{{Address: contract1}}, false, false},
+		{"no quota", nil, true, true},
+		{"duplicated address", []ContractGasQuota{NewContractGasQuota(contract1, 1000000), NewContractGasQuota(contract1, 1)}, true, true},
+		{"zero gas per block", []ContractGasQuota{{Address: contract1}}, true, true},
+		{"empty address", []ContractGasQuota{NewContractGasQuota(nil, 1000000)}, true, true},
+		{"delete an empty address", []ContractGasQuota{{}}, false, true},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.msg, func() {
+			proposal := NewManageContractGasQuotaProposal("default title", "default description", tc.quotas, tc.isAdded)
+			suite.Require().Equal(tc.expectedErr, proposal.ValidateBasic() != nil)
+		})
+	}
+}
@@ -0,0 +1,457 @@
+package types
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/proto"
+	gogotypes "github.com/gogo/protobuf/types"
+
+	"github.com/okex/exchain/libs/cosmos-sdk/codec"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	sdkerrors "github.com/okex/exchain/libs/cosmos-sdk/types/errors"
+	"github.com/okex/exchain/x/evm/evmproto"
+)
+
+// MsgRegistry resolves the messages packed into Any by their type urls, the type url of a message is the name
+// of the protobuf message it's encoded into
+type MsgRegistry struct {
+	byTypeURL map[string]*msgImpl
+	byType    map[reflect.Type]*msgImpl
+}
+
+type msgImpl struct {
+	typeURL   string
+	protoType reflect.Type
+	toProto   func(sdk.Msg) (proto.Message, error)
+	fromProto func(proto.Message) (sdk.Msg, error)
+}
+
+// NewMsgRegistry returns an empty registry
+func NewMsgRegistry() *MsgRegistry {
+	return &MsgRegistry{
+		byTypeURL: make(map[string]*msgImpl),
+		byType:    make(map[reflect.Type]*msgImpl),
+	}
+}
+
+// RegisterMsg registers the message msg encoded into the protobuf message protoMsg by toProto and decoded
+// back by fromProto. The message is registered in the form it's registered in the amino codec, the other
+// forms aren't packed. It panics if the message or the protobuf message is registered twice.
+func (r *MsgRegistry) RegisterMsg(msg sdk.Msg, protoMsg proto.Message,
+	toProto func(sdk.Msg) (proto.Message, error), fromProto func(proto.Message) (sdk.Msg, error)) {
+	impl := &msgImpl{
+		typeURL:   "/" + proto.MessageName(protoMsg),
+		protoType: reflect.TypeOf(protoMsg).Elem(),
+		toProto:   toProto,
+		fromProto: fromProto,
+	}
+	msgType := reflect.TypeOf(msg)
+	if _, ok := r.byTypeURL[impl.typeURL]; ok {
+		panic(fmt.Sprintf("type url %s has already been registered", impl.typeURL))
+	}
+	if _, ok := r.byType[msgType]; ok {
+		panic(fmt.Sprintf("%s has already been registered", msgType))
+	}
+	r.byTypeURL[impl.typeURL] = impl
+	r.byType[msgType] = impl
+}
+
+// Pack packs a registered message into Any
+func (r *MsgRegistry) Pack(msg sdk.Msg) (*gogotypes.Any, error) {
+	impl, ok := r.byType[reflect.TypeOf(msg)]
+	if !ok {
+		return nil, fmt.Errorf("cannot pack %T into Any, it isn't registered", msg)
+	}
+	protoMsg, err := impl.toProto(msg)
+	if err != nil {
+		return nil, err
+	}
+	value, err := proto.Marshal(protoMsg)
+	if err != nil {
+		return nil, err
+	}
+	return &gogotypes.Any{TypeUrl: impl.typeURL, Value: value}, nil
+}
+
+// Unpack unpacks a registered message out of Any
+func (r *MsgRegistry) Unpack(any *gogotypes.Any) (sdk.Msg, error) {
+	impl, ok := r.byTypeURL[any.GetTypeUrl()]
+	if !ok {
+		return nil, fmt.Errorf("cannot unpack Any with type url %q, it isn't registered", any.GetTypeUrl())
+	}
+	protoMsg := reflect.New(impl.protoType).Interface().(proto.Message)
+	if err := proto.Unmarshal(any.Value, protoMsg); err != nil {
+		return nil, err
+	}
+	return impl.fromProto(protoMsg)
+}
+
+// PackMsg packs an evm message into Any with the registry of the module
+func PackMsg(msg sdk.Msg) (*gogotypes.Any, error) {
+	return ModuleRegistry.Pack(msg)
+}
+
+// UnpackMsg unpacks an evm message out of Any with the registry of the module
+func UnpackMsg(any *gogotypes.Any) (sdk.Msg, error) {
+	return ModuleRegistry.Unpack(any)
+}
+
+// msgEthereumTxToProto keeps the payload of MsgEthereumTx in its rlp encoding, so the signature and the hash of
+// the tx are the ethereum ones
+func msgEthereumTxToProto(msg sdk.Msg) (proto.Message, error) {
+	ethMsg := msg.(MsgEthereumTx)
+	data, err := rlp.EncodeToBytes(&ethMsg.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &evmproto.MsgEthereumTx{Data: data}, nil
+}
+
+func msgEthereumTxFromProto(protoMsg proto.Message) (sdk.Msg, error) {
+	var msg MsgEthereumTx
+	if err := rlp.DecodeBytes(protoMsg.(*evmproto.MsgEthereumTx).Data, &msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// EncodeProtoTx encodes an ethereum transaction into the protobuf encoding of Tx
+func EncodeProtoTx(tx sdk.Tx) ([]byte, error) {
+	msgs := tx.GetMsgs()
+	protoTx := &evmproto.Tx{Msgs: make([]*gogotypes.Any, len(msgs))}
+	for i, msg := range msgs {
+		any, err := PackMsg(msg)
+		if err != nil {
+			return nil, err
+		}
+		protoTx.Msgs[i] = any
+	}
+	return proto.Marshal(protoTx)
+}
+
+// DecodeProtoTx decodes the protobuf encoding of Tx, the tx holds a single MsgEthereumTx
+func DecodeProtoTx(txBytes []byte) (sdk.Tx, error) {
+	var protoTx evmproto.Tx
+	if err := proto.Unmarshal(txBytes, &protoTx); err != nil {
+		return nil, err
+	}
+	if len(protoTx.Msgs) != 1 {
+		return nil, fmt.Errorf("expected a single message in the tx, got %d", len(protoTx.Msgs))
+	}
+
+	msg, err := UnpackMsg(protoTx.Msgs[0])
+	if err != nil {
+		return nil, err
+	}
+	tx, ok := msg.(MsgEthereumTx)
+	if !ok {
+		return nil, fmt.Errorf("expected a MsgEthereumTx in the tx, got %T", msg)
+	}
+	return tx, nil
+}
+
+// ProtoTxEncoder returns the encoder of the ethereum transactions into the protobuf encoding of Tx
+func ProtoTxEncoder() sdk.TxEncoder {
+	return func(tx sdk.Tx) ([]byte, error) {
+		bz, err := EncodeProtoTx(tx)
+		if err != nil {
+			return nil, sdkerrors.Wrap(sdkerrors.ErrTxDecode, err.Error())
+		}
+		return bz, nil
+	}
+}
+
+// NewProtoLog converts an ethereum log into its protobuf message
+func NewProtoLog(log *ethtypes.Log) *evmproto.Log {
+	topics := make([]string, len(log.Topics))
+	for i, topic := range log.Topics {
+		topics[i] = topic.Hex()
+	}
+	return &evmproto.Log{
+		Address:     log.Address.Hex(),
+		Topics:      topics,
+		Data:        log.Data,
+		BlockNumber: log.BlockNumber,
+		TxHash:      log.TxHash.Hex(),
+		TxIndex:     uint64(log.TxIndex),
+		BlockHash:   log.BlockHash.Hex(),
+		Index:       uint64(log.Index),
+		Removed:     log.Removed,
+	}
+}
+
+// EthLogFromProto converts the message back into an ethereum log
+func EthLogFromProto(m *evmproto.Log) *ethtypes.Log {
+	topics := make([]ethcmn.Hash, len(m.Topics))
+	for i, topic := range m.Topics {
+		topics[i] = ethcmn.HexToHash(topic)
+	}
+	return &ethtypes.Log{
+		Address:     ethcmn.HexToAddress(m.Address),
+		Topics:      topics,
+		Data:        m.Data,
+		BlockNumber: m.BlockNumber,
+		TxHash:      ethcmn.HexToHash(m.TxHash),
+		TxIndex:     uint(m.TxIndex),
+		BlockHash:   ethcmn.HexToHash(m.BlockHash),
+		Index:       uint(m.Index),
+		Removed:     m.Removed,
+	}
+}
+
+// NewProtoChainConfig converts the chain config into its protobuf message
+func NewProtoChainConfig(cc ChainConfig) *evmproto.ChainConfig {
+	return &evmproto.ChainConfig{
+		HomesteadBlock:      intString(cc.HomesteadBlock),
+		DaoForkBlock:        intString(cc.DAOForkBlock),
+		DaoForkSupport:      cc.DAOForkSupport,
+		Eip150Block:         intString(cc.EIP150Block),
+		Eip150Hash:          cc.EIP150Hash,
+		Eip155Block:         intString(cc.EIP155Block),
+		Eip158Block:         intString(cc.EIP158Block),
+		ByzantiumBlock:      intString(cc.ByzantiumBlock),
+		ConstantinopleBlock: intString(cc.ConstantinopleBlock),
+		PetersburgBlock:     intString(cc.PetersburgBlock),
+		IstanbulBlock:       intString(cc.IstanbulBlock),
+		MuirGlacierBlock:    intString(cc.MuirGlacierBlock),
+		YoloV2Block:         intString(cc.YoloV2Block),
+		EwasmBlock:          intString(cc.EWASMBlock),
+		BerlinBlock:         intString(cc.BerlinBlock),
+		LondonBlock:         intString(cc.LondonBlock),
+		ShanghaiBlock:       intString(cc.ShanghaiBlock),
+	}
+}
+
+// ChainConfigFromProto converts the message back into a chain config. The missing forks introduced after the
+// genesis layout was defined are disabled, the other missing values fail the validation of the config.
+func ChainConfigFromProto(m *evmproto.ChainConfig) (ChainConfig, error) {
+	if m == nil {
+		return ChainConfig{}, fmt.Errorf("missing chain config")
+	}
+
+	cc := ChainConfig{DAOForkSupport: m.DaoForkSupport, EIP150Hash: m.Eip150Hash}
+	blocks := []struct {
+		value string
+		block *sdk.Int
+	}{
+		{m.HomesteadBlock, &cc.HomesteadBlock},
+		{m.DaoForkBlock, &cc.DAOForkBlock},
+		{m.Eip150Block, &cc.EIP150Block},
+		{m.Eip155Block, &cc.EIP155Block},
+		{m.Eip158Block, &cc.EIP158Block},
+		{m.ByzantiumBlock, &cc.ByzantiumBlock},
+		{m.ConstantinopleBlock, &cc.ConstantinopleBlock},
+		{m.PetersburgBlock, &cc.PetersburgBlock},
+		{m.IstanbulBlock, &cc.IstanbulBlock},
+		{m.MuirGlacierBlock, &cc.MuirGlacierBlock},
+		{m.YoloV2Block, &cc.YoloV2Block},
+		{m.EwasmBlock, &cc.EWASMBlock},
+		{m.BerlinBlock, &cc.BerlinBlock},
+		{m.LondonBlock, &cc.LondonBlock},
+		{m.ShanghaiBlock, &cc.ShanghaiBlock},
+	}
+	for _, b := range blocks {
+		if b.value == "" {
+			continue
+		}
+		block, ok := sdk.NewIntFromString(b.value)
+		if !ok {
+			return ChainConfig{}, fmt.Errorf("invalid fork block %q", b.value)
+		}
+		*b.block = block
+	}
+	cc.disableMissingForks()
+	return cc, nil
+}
+
+func intString(i sdk.Int) string {
+	if i.IsNil() {
+		return ""
+	}
+	return i.String()
+}
+
+// ToProto converts the genesis state into its protobuf message
+func (gs GenesisState) ToProto() *evmproto.GenesisState {
+	pgs := &evmproto.GenesisState{
+		ChainConfig: NewProtoChainConfig(gs.ChainConfig),
+		Params:      NewProtoParams(gs.Params),
+	}
+	for _, acc := range gs.Accounts {
+		pacc := &evmproto.GenesisAccount{Address: acc.Address, Code: acc.Code}
+		for _, state := range acc.Storage {
+			pacc.Storage = append(pacc.Storage, &evmproto.State{Key: state.Key.Hex(), Value: state.Value.Hex()})
+		}
+		pgs.Accounts = append(pgs.Accounts, pacc)
+	}
+	for _, txLogs := range gs.TxsLogs {
+		ptxLogs := &evmproto.TransactionLogs{Hash: txLogs.Hash.Hex()}
+		for _, log := range txLogs.Logs {
+			ptxLogs.Logs = append(ptxLogs.Logs, NewProtoLog(log))
+		}
+		pgs.TxsLogs = append(pgs.TxsLogs, ptxLogs)
+	}
+	for _, addr := range gs.ContractDeploymentWhitelist {
+		pgs.ContractDeploymentWhitelist = append(pgs.ContractDeploymentWhitelist, addr.String())
+	}
+	for _, addr := range gs.ContractBlockedList {
+		pgs.ContractBlockedList = append(pgs.ContractBlockedList, addr.String())
+	}
+	for _, bc := range gs.ContractMethodBlockedList {
+		pbc := &evmproto.BlockedContract{Address: bc.Address.String()}
+		for _, method := range bc.BlockMethods {
+			pbc.BlockMethods = append(pbc.BlockMethods, &evmproto.ContractMethod{Sign: method.Sign, Extra: method.Extra})
+		}
+		pgs.ContractMethodBlockedList = append(pgs.ContractMethodBlockedList, pbc)
+	}
+	for _, bh := range gs.RecentBlockHashes {
+		pgs.RecentBlockHashes = append(pgs.RecentBlockHashes, &evmproto.RecentBlockHash{Height: bh.Height, Hash: bh.Hash.Hex()})
+	}
+	for _, fa := range gs.FeeAllowances {
+		pgs.FeeAllowances = append(pgs.FeeAllowances, &evmproto.FeeAllowance{
+			Granter:    fa.Granter.String(),
+			Grantee:    fa.Grantee.String(),
+			SpendLimit: decString(fa.SpendLimit),
+			Expiration: fa.Expiration,
+		})
+	}
+	for _, token := range gs.TokenRegistry {
+		pgs.TokenRegistry = append(pgs.TokenRegistry, &evmproto.TokenMetadata{
+			Denom:       token.Denom,
+			Name:        token.Name,
+			Symbol:      token.Symbol,
+			Decimals:    uint32(token.Decimals),
+			Contract:    token.Contract,
+			OriginChain: token.OriginChain,
+			OriginDenom: token.OriginDenom,
+		})
+	}
+	return pgs
+}
+
+// GenesisStateFromProto converts the protobuf message back into a genesis state
+func GenesisStateFromProto(pgs *evmproto.GenesisState) (GenesisState, error) {
+	chainConfig, err := ChainConfigFromProto(pgs.ChainConfig)
+	if err != nil {
+		return GenesisState{}, err
+	}
+	params, err := ParamsFromProto(pgs.Params)
+	if err != nil {
+		return GenesisState{}, err
+	}
+
+	gs := GenesisState{
+		Accounts:                    []GenesisAccount{},
+		TxsLogs:                     []TransactionLogs{},
+		ContractDeploymentWhitelist: AddressList{},
+		ContractBlockedList:         AddressList{},
+		ChainConfig:                 chainConfig,
+		Params:                      params,
+	}
+	for _, pacc := range pgs.Accounts {
+		acc := GenesisAccount{Address: pacc.Address, Code: hexutil.Bytes(pacc.Code)}
+		for _, state := range pacc.Storage {
+			acc.Storage = append(acc.Storage, NewState(ethcmn.HexToHash(state.Key), ethcmn.HexToHash(state.Value)))
+		}
+		gs.Accounts = append(gs.Accounts, acc)
+	}
+	for _, ptxLogs := range pgs.TxsLogs {
+		txLogs := TransactionLogs{Hash: ethcmn.HexToHash(ptxLogs.Hash), Logs: []*ethtypes.Log{}}
+		for _, log := range ptxLogs.Logs {
+			txLogs.Logs = append(txLogs.Logs, EthLogFromProto(log))
+		}
+		gs.TxsLogs = append(gs.TxsLogs, txLogs)
+	}
+	for _, bech32 := range pgs.ContractDeploymentWhitelist {
+		addr, err := sdk.AccAddressFromBech32(bech32)
+		if err != nil {
+			return GenesisState{}, err
+		}
+		gs.ContractDeploymentWhitelist = append(gs.ContractDeploymentWhitelist, addr)
+	}
+	for _, bech32 := range pgs.ContractBlockedList {
+		addr, err := sdk.AccAddressFromBech32(bech32)
+		if err != nil {
+			return GenesisState{}, err
+		}
+		gs.ContractBlockedList = append(gs.ContractBlockedList, addr)
+	}
+	for _, pbc := range pgs.ContractMethodBlockedList {
+		addr, err := sdk.AccAddressFromBech32(pbc.Address)
+		if err != nil {
+			return GenesisState{}, err
+		}
+		methods := ContractMethods{}
+		for _, method := range pbc.BlockMethods {
+			methods = append(methods, ContractMethod{Sign: method.Sign, Extra: method.Extra})
+		}
+		gs.ContractMethodBlockedList = append(gs.ContractMethodBlockedList, *NewBlockContract(addr, methods))
+	}
+	for _, pbh := range pgs.RecentBlockHashes {
+		gs.RecentBlockHashes = append(gs.RecentBlockHashes, RecentBlockHash{Height: pbh.Height, Hash: ethcmn.HexToHash(pbh.Hash)})
+	}
+	for _, pfa := range pgs.FeeAllowances {
+		granter, err := sdk.AccAddressFromBech32(pfa.Granter)
+		if err != nil {
+			return GenesisState{}, err
+		}
+		grantee, err := sdk.AccAddressFromBech32(pfa.Grantee)
+		if err != nil {
+			return GenesisState{}, err
+		}
+		spendLimit, err := decFromString(pfa.SpendLimit)
+		if err != nil {
+			return GenesisState{}, err
+		}
+		gs.FeeAllowances = append(gs.FeeAllowances, NewFeeAllowance(granter, grantee, spendLimit, pfa.Expiration))
+	}
+	for _, ptoken := range pgs.TokenRegistry {
+		if ptoken.Decimals > math.MaxUint8 {
+			return GenesisState{}, fmt.Errorf("invalid decimals %d of %s", ptoken.Decimals, ptoken.Denom)
+		}
+		gs.TokenRegistry = append(gs.TokenRegistry, TokenMetadata{
+			Denom:       ptoken.Denom,
+			Name:        ptoken.Name,
+			Symbol:      ptoken.Symbol,
+			Decimals:    uint8(ptoken.Decimals),
+			Contract:    ptoken.Contract,
+			OriginChain: ptoken.OriginChain,
+			OriginDenom: ptoken.OriginDenom,
+		})
+	}
+	return gs, nil
+}
+
+// MarshalGenesisProtoJSON encodes the genesis state into the json encoding of its protobuf message
+func MarshalGenesisProtoJSON(gs GenesisState) ([]byte, error) {
+	var buf bytes.Buffer
+	marshaler := jsonpb.Marshaler{OrigName: true, EmitDefaults: true}
+	if err := marshaler.Marshal(&buf, gs.ToProto()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalGenesisState decodes the genesis state out of the json encoding of its protobuf message, or out of
+// the amino json encoding. The protobuf decoding rejects the unknown fields and the hex encoded bytes of the
+// amino encoding, so the amino genesis files keep decoding as they did.
+func UnmarshalGenesisState(cdc *codec.Codec, bz []byte) (GenesisState, error) {
+	var pgs evmproto.GenesisState
+	if err := jsonpb.Unmarshal(bytes.NewReader(bz), &pgs); err == nil {
+		return GenesisStateFromProto(&pgs)
+	}
+
+	var gs GenesisState
+	if err := cdc.UnmarshalJSON(bz, &gs); err != nil {
+		return GenesisState{}, err
+	}
+	return gs, nil
+}
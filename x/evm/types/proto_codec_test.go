@@ -0,0 +1,173 @@
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	gogotypes "github.com/gogo/protobuf/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/okex/exchain/app/crypto/ethsecp256k1"
+	"github.com/okex/exchain/libs/cosmos-sdk/codec"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	"github.com/okex/exchain/x/evm/evmproto"
+)
+
+func TestProtoTxEncoding(t *testing.T) {
+	priv, err := ethsecp256k1.GenerateKey()
+	require.NoError(t, err)
+	addr := ethcmn.BytesToAddress(priv.PubKey().Address().Bytes())
+	msg := NewMsgEthereumTx(0, &addr, big.NewInt(10), 100000, big.NewInt(1), []byte("test"))
+	require.NoError(t, msg.Sign(big.NewInt(3), priv.ToECDSA()))
+
+	cdc := codec.New()
+	cdc.RegisterInterface((*sdk.Tx)(nil), nil)
+	RegisterCodec(cdc)
+
+	bz, err := ProtoTxEncoder()(msg)
+	require.NoError(t, err)
+	aminoBz, err := cdc.MarshalBinaryLengthPrefixed(msg)
+	require.NoError(t, err)
+
+	// the protobuf encoding is only decoded past the mercury milestone
+	_, err = TxDecoder(cdc)(bz, 10)
+	require.Error(t, err)
+	_, err = TxDecoder(cdc)(bz)
+	require.Error(t, err)
+
+	sdk.UnittestOnlySetMilestoneMercuryHeight(10)
+	defer sdk.UnittestOnlySetMilestoneMercuryHeight(0)
+	_, err = TxDecoder(cdc)(bz, 10)
+	require.Error(t, err)
+	for _, height := range [][]int64{{11}, nil} {
+		tx, err := TxDecoder(cdc)(bz, height...)
+		require.NoError(t, err)
+		decoded, ok := tx.(MsgEthereumTx)
+		require.True(t, ok)
+		require.Equal(t, msg.Data, decoded.Data)
+	}
+
+	// the amino encoding is decoded at any height
+	for _, height := range [][]int64{{10}, {11}, nil} {
+		tx, err := TxDecoder(cdc)(aminoBz, height...)
+		require.NoError(t, err)
+		require.Equal(t, msg.Data, tx.(MsgEthereumTx).Data)
+	}
+}
+
+func TestUnpackMsgInvalid(t *testing.T) {
+	any, err := PackMsg(NewMsgEthereumTx(0, nil, nil, 100000, nil, nil))
+	require.NoError(t, err)
+	require.Equal(t, "/okexchain.evm.v1.MsgEthereumTx", any.TypeUrl)
+
+	_, err = UnpackMsg(&gogotypes.Any{TypeUrl: "/okexchain.evm.v1.Unknown", Value: any.Value})
+	require.Error(t, err)
+	_, err = UnpackMsg(nil)
+	require.Error(t, err)
+	_, err = DecodeProtoTx([]byte{0x1})
+	require.Error(t, err)
+}
+
+func TestMsgRegistry(t *testing.T) {
+	msg := NewMsgEthereumTx(1, nil, big.NewInt(10), 100000, big.NewInt(1), []byte("test"))
+	any, err := PackMsg(msg)
+	require.NoError(t, err)
+	unpacked, err := UnpackMsg(any)
+	require.NoError(t, err)
+	require.Equal(t, msg.Data, unpacked.(MsgEthereumTx).Data)
+
+	// only the form registered in the amino codec is packed
+	_, err = PackMsg(&msg)
+	require.Error(t, err)
+	_, err = PackMsg(MsgEthermint{})
+	require.Error(t, err)
+
+	registry := NewMsgRegistry()
+	RegisterInterfaces(registry)
+	require.Panics(t, func() { RegisterInterfaces(registry) })
+	// the messages of another registry aren't unpacked
+	_, err = NewMsgRegistry().Unpack(any)
+	require.Error(t, err)
+}
+
+func TestGenesisStateProtoJSON(t *testing.T) {
+	gs := DefaultGenesisState()
+	gs.RecentBlockHashes = []RecentBlockHash{{Height: 10, Hash: ethcmn.BytesToHash([]byte("hash"))}}
+	gs.Params.FeeDenoms = []string{"usdt"}
+	gs.Params.FeeConversionSpread = sdk.NewDecWithPrec(3, 3)
+	gs.FeeAllowances = []FeeAllowance{
+		NewFeeAllowance(sdk.AccAddress(ethcmn.HexToAddress("0x1").Bytes()), sdk.AccAddress(ethcmn.HexToAddress("0x2").Bytes()),
+			sdk.NewDec(5), 100),
+		NewFeeAllowance(sdk.AccAddress(ethcmn.HexToAddress("0x1").Bytes()), sdk.AccAddress(ethcmn.HexToAddress("0x3").Bytes()),
+			sdk.ZeroDec(), 0),
+	}
+	gs.TokenRegistry = []TokenMetadata{{
+		Denom:       "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2",
+		Name:        "Cosmos Hub Atom",
+		Symbol:      "ATOM",
+		Decimals:    6,
+		Contract:    ethcmn.HexToAddress("0x4").Hex(),
+		OriginChain: "cosmoshub-4",
+		OriginDenom: "uatom",
+	}}
+
+	// the amino json is still decoded
+	aminoBz := ModuleCdc.MustMarshalJSON(gs)
+	var expected GenesisState
+	ModuleCdc.MustUnmarshalJSON(aminoBz, &expected)
+	decoded, err := UnmarshalGenesisState(ModuleCdc, aminoBz)
+	require.NoError(t, err)
+	require.Equal(t, expected, decoded)
+
+	bz, err := MarshalGenesisProtoJSON(gs)
+	require.NoError(t, err)
+	decoded, err = UnmarshalGenesisState(ModuleCdc, bz)
+	require.NoError(t, err)
+	require.NoError(t, decoded.Validate())
+	require.Equal(t, gs.RecentBlockHashes, decoded.RecentBlockHashes)
+	require.Equal(t, gs.Params, decoded.Params)
+	require.Equal(t, gs.FeeAllowances, decoded.FeeAllowances)
+	require.Equal(t, gs.TokenRegistry, decoded.TokenRegistry)
+	// the empty lists are decoded as nil, compare the encodings
+	decodedBz, err := MarshalGenesisProtoJSON(decoded)
+	require.NoError(t, err)
+	require.Equal(t, string(bz), string(decodedBz))
+}
+
+func TestParamsProto(t *testing.T) {
+	params := DefaultParams()
+	params.ExtraEIPs = []int{2200}
+	params.FeeDenoms = []string{"usdt", "okb"}
+	params.FeeConversionSpread = sdk.NewDecWithPrec(3, 3)
+	params.GasPriceVoteWindow = 20
+	params.MaxGasPriceChange = sdk.NewDecWithPrec(2, 1)
+
+	decoded, err := ParamsFromProto(NewProtoParams(params))
+	require.NoError(t, err)
+	require.Equal(t, params.ExtraEIPs, decoded.ExtraEIPs)
+	require.Equal(t, params.FeeDenoms, decoded.FeeDenoms)
+	require.True(t, params.FeeConversionSpread.Equal(decoded.FeeConversionSpread))
+	require.Equal(t, params.GasPriceVoteWindow, decoded.GasPriceVoteWindow)
+	require.True(t, params.MaxGasPriceChange.Equal(decoded.MaxGasPriceChange))
+
+	// the missing decimals are zero
+	decoded, err = ParamsFromProto(&evmproto.Params{})
+	require.NoError(t, err)
+	require.True(t, decoded.FeeConversionSpread.IsZero())
+	require.True(t, decoded.MaxGasPriceChange.IsZero())
+
+	_, err = ParamsFromProto(&evmproto.Params{FeeConversionSpread: "not a decimal"})
+	require.Error(t, err)
+	_, err = ParamsFromProto(&evmproto.Params{MaxGasPriceChange: "not a decimal"})
+	require.Error(t, err)
+	_, err = ParamsFromProto(nil)
+	require.Error(t, err)
+}
+
+func TestGenesisStateFromProtoInvalidDecimals(t *testing.T) {
+	pgs := DefaultGenesisState().ToProto()
+	pgs.TokenRegistry = []*evmproto.TokenMetadata{{Denom: "usdt", Symbol: "USDT", Decimals: 256, OriginChain: "exchain-66"}}
+	_, err := GenesisStateFromProto(pgs)
+	require.Error(t, err)
+}
@@ -0,0 +1,42 @@
+package types
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/spf13/viper"
+
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	tmiavl "github.com/okex/exchain/libs/iavl"
+)
+
+const (
+	FlagEvmPruningKeepRecent = "evm-pruning-keep-recent"
+	FlagEvmPruningKeepEvery  = "evm-pruning-keep-every"
+	FlagEvmPruningInterval   = "evm-pruning-interval"
+)
+
+// GetPruningOptions returns the pruning strategy of the evm state set in the node config, apart from the
+// global one. It applies to the evm store and to the acc store, which holds the balances and nonces of the
+// evm accounts. The second value is false when the evm state follows the global pruning strategy.
+func GetPruningOptions() (sdk.PruningOptions, bool, error) {
+	interval := viper.GetUint64(FlagEvmPruningInterval)
+	if interval == 0 {
+		return sdk.PruningOptions{}, false, nil
+	}
+
+	opts := sdk.PruningOptions{
+		KeepRecent:   viper.GetUint64(FlagEvmPruningKeepRecent),
+		KeepEvery:    viper.GetUint64(FlagEvmPruningKeepEvery),
+		Interval:     interval,
+		MaxRetainNum: math.MaxUint64,
+	}
+	if viper.GetBool(tmiavl.FlagIavlEnableAsyncCommit) {
+		// the iavl trees prune their versions by themselves with the async commit
+		return opts, false, fmt.Errorf("invalid evm pruning options: %s can't be set with %s", FlagEvmPruningInterval, tmiavl.FlagIavlEnableAsyncCommit)
+	}
+	if opts.KeepEvery == 1 {
+		return opts, false, fmt.Errorf("invalid evm pruning options: %s must not be 1 when pruning", FlagEvmPruningKeepEvery)
+	}
+	return opts, true, nil
+}
@@ -0,0 +1,37 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+
+	tmiavl "github.com/okex/exchain/libs/iavl"
+)
+
+func TestGetPruningOptions(t *testing.T) {
+	defer viper.Reset()
+
+	_, ok, err := GetPruningOptions()
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	viper.Set(FlagEvmPruningKeepRecent, 100)
+	viper.Set(FlagEvmPruningKeepEvery, 1000)
+	viper.Set(FlagEvmPruningInterval, 10)
+	opts, ok, err := GetPruningOptions()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, uint64(100), opts.KeepRecent)
+	require.Equal(t, uint64(1000), opts.KeepEvery)
+	require.Equal(t, uint64(10), opts.Interval)
+
+	viper.Set(FlagEvmPruningKeepEvery, 1)
+	_, _, err = GetPruningOptions()
+	require.Error(t, err)
+
+	viper.Set(FlagEvmPruningKeepEvery, 1000)
+	viper.Set(tmiavl.FlagIavlEnableAsyncCommit, true)
+	_, _, err = GetPruningOptions()
+	require.Error(t, err)
+}
@@ -3,7 +3,10 @@ package types
 import (
 	"fmt"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	"gopkg.in/yaml.v2"
 )
 
 // Supported endpoints
@@ -26,6 +29,14 @@ const (
 	QueryContractDeploymentWhitelist = "contract-deployment-whitelist"
 	QueryContractBlockedList         = "contract-blocked-list"
 	QueryContractMethodBlockedList   = "contract-method-blocked-list"
+	QueryBaseFee                     = "baseFee"
+	QueryEvmProfile                  = "evmProfile"
+	QuerySimulateBundle              = "simulateBundle"
+	QueryUpgradeSnapshot             = "upgradeSnapshot"
+	QueryDryRunMigrations            = "dryRunMigrations"
+	QueryTokenRegistry               = "token-registry"
+	QueryMinGasPrice                 = "min-gas-price"
+	QueryContractGasQuota            = "contract-gas-quota"
 )
 
 // QueryResBalance is response type for balance query
@@ -97,6 +108,29 @@ func (q QueryBloomFilter) String() string {
 	return string(q.Bloom.Bytes())
 }
 
+// QueryResBaseFee is response type for base fee query. BaseFee is nil before London is activated.
+type QueryResBaseFee struct {
+	BaseFee *hexutil.Big `json:"base_fee"`
+}
+
+func (q QueryResBaseFee) String() string {
+	if q.BaseFee == nil {
+		return "<nil>"
+	}
+	return q.BaseFee.String()
+}
+
+// QueryResMinGasPrice is response type for the min gas price query, the floor and the votes aggregated into it
+type QueryResMinGasPrice struct {
+	MinGasPrice sdk.Dec        `json:"min_gas_price" yaml:"min_gas_price"`
+	Votes       []GasPriceVote `json:"votes" yaml:"votes"`
+}
+
+func (q QueryResMinGasPrice) String() string {
+	out, _ := yaml.Marshal(q)
+	return string(out)
+}
+
 // QueryAccount is response type for querying Ethereum state objects
 type QueryResAccount struct {
 	Balance  string `json:"balance"`
@@ -0,0 +1,973 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: x/evm/types/query.proto
+
+package types
+
+import (
+	context "context"
+	fmt "fmt"
+	_ "github.com/gogo/protobuf/gogoproto"
+	proto "github.com/gogo/protobuf/proto"
+	evmproto "github.com/okex/exchain/x/evm/evmproto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+// PageRequest selects a page of the results, from key if set, limit is 100 when unset
+type PageRequest struct {
+	Key                  []byte   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Limit                uint64   `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PageRequest) Reset()         { *m = PageRequest{} }
+func (m *PageRequest) String() string { return proto.CompactTextString(m) }
+func (*PageRequest) ProtoMessage()    {}
+func (*PageRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e23e3de47cd4b5a3, []int{0}
+}
+func (m *PageRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PageRequest.Unmarshal(m, b)
+}
+func (m *PageRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PageRequest.Marshal(b, m, deterministic)
+}
+func (m *PageRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PageRequest.Merge(m, src)
+}
+func (m *PageRequest) XXX_Size() int {
+	return xxx_messageInfo_PageRequest.Size(m)
+}
+func (m *PageRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PageRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PageRequest proto.InternalMessageInfo
+
+// PageResponse holds the key of the next page, empty on the last page
+type PageResponse struct {
+	NextKey              []byte   `protobuf:"bytes,1,opt,name=next_key,json=nextKey,proto3" json:"next_key,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PageResponse) Reset()         { *m = PageResponse{} }
+func (m *PageResponse) String() string { return proto.CompactTextString(m) }
+func (*PageResponse) ProtoMessage()    {}
+func (*PageResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e23e3de47cd4b5a3, []int{1}
+}
+func (m *PageResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PageResponse.Unmarshal(m, b)
+}
+func (m *PageResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PageResponse.Marshal(b, m, deterministic)
+}
+func (m *PageResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PageResponse.Merge(m, src)
+}
+func (m *PageResponse) XXX_Size() int {
+	return xxx_messageInfo_PageResponse.Size(m)
+}
+func (m *PageResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_PageResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PageResponse proto.InternalMessageInfo
+
+func (m *PageResponse) GetNextKey() []byte {
+	if m != nil {
+		return m.NextKey
+	}
+	return nil
+}
+
+type QueryCodeRequest struct {
+	// hex address of the contract
+	Address              string   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *QueryCodeRequest) Reset()         { *m = QueryCodeRequest{} }
+func (m *QueryCodeRequest) String() string { return proto.CompactTextString(m) }
+func (*QueryCodeRequest) ProtoMessage()    {}
+func (*QueryCodeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e23e3de47cd4b5a3, []int{2}
+}
+func (m *QueryCodeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QueryCodeRequest.Unmarshal(m, b)
+}
+func (m *QueryCodeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QueryCodeRequest.Marshal(b, m, deterministic)
+}
+func (m *QueryCodeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueryCodeRequest.Merge(m, src)
+}
+func (m *QueryCodeRequest) XXX_Size() int {
+	return xxx_messageInfo_QueryCodeRequest.Size(m)
+}
+func (m *QueryCodeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueryCodeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueryCodeRequest proto.InternalMessageInfo
+
+func (m *QueryCodeRequest) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+type QueryCodeResponse struct {
+	Code                 []byte   `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *QueryCodeResponse) Reset()         { *m = QueryCodeResponse{} }
+func (m *QueryCodeResponse) String() string { return proto.CompactTextString(m) }
+func (*QueryCodeResponse) ProtoMessage()    {}
+func (*QueryCodeResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e23e3de47cd4b5a3, []int{3}
+}
+func (m *QueryCodeResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QueryCodeResponse.Unmarshal(m, b)
+}
+func (m *QueryCodeResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QueryCodeResponse.Marshal(b, m, deterministic)
+}
+func (m *QueryCodeResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueryCodeResponse.Merge(m, src)
+}
+func (m *QueryCodeResponse) XXX_Size() int {
+	return xxx_messageInfo_QueryCodeResponse.Size(m)
+}
+func (m *QueryCodeResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueryCodeResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueryCodeResponse proto.InternalMessageInfo
+
+func (m *QueryCodeResponse) GetCode() []byte {
+	if m != nil {
+		return m.Code
+	}
+	return nil
+}
+
+type QueryStorageRequest struct {
+	// hex address of the contract
+	Address string `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	// hex key of the slot
+	Key                  string   `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *QueryStorageRequest) Reset()         { *m = QueryStorageRequest{} }
+func (m *QueryStorageRequest) String() string { return proto.CompactTextString(m) }
+func (*QueryStorageRequest) ProtoMessage()    {}
+func (*QueryStorageRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e23e3de47cd4b5a3, []int{4}
+}
+func (m *QueryStorageRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QueryStorageRequest.Unmarshal(m, b)
+}
+func (m *QueryStorageRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QueryStorageRequest.Marshal(b, m, deterministic)
+}
+func (m *QueryStorageRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueryStorageRequest.Merge(m, src)
+}
+func (m *QueryStorageRequest) XXX_Size() int {
+	return xxx_messageInfo_QueryStorageRequest.Size(m)
+}
+func (m *QueryStorageRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueryStorageRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueryStorageRequest proto.InternalMessageInfo
+
+func (m *QueryStorageRequest) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *QueryStorageRequest) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+type QueryStorageResponse struct {
+	// hex value of the slot
+	Value                string   `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *QueryStorageResponse) Reset()         { *m = QueryStorageResponse{} }
+func (m *QueryStorageResponse) String() string { return proto.CompactTextString(m) }
+func (*QueryStorageResponse) ProtoMessage()    {}
+func (*QueryStorageResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e23e3de47cd4b5a3, []int{5}
+}
+func (m *QueryStorageResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QueryStorageResponse.Unmarshal(m, b)
+}
+func (m *QueryStorageResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QueryStorageResponse.Marshal(b, m, deterministic)
+}
+func (m *QueryStorageResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueryStorageResponse.Merge(m, src)
+}
+func (m *QueryStorageResponse) XXX_Size() int {
+	return xxx_messageInfo_QueryStorageResponse.Size(m)
+}
+func (m *QueryStorageResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueryStorageResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueryStorageResponse proto.InternalMessageInfo
+
+func (m *QueryStorageResponse) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+type QueryStorageSlotsRequest struct {
+	// hex address of the contract
+	Address              string       `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Pagination           *PageRequest `protobuf:"bytes,2,opt,name=pagination,proto3" json:"pagination,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *QueryStorageSlotsRequest) Reset()         { *m = QueryStorageSlotsRequest{} }
+func (m *QueryStorageSlotsRequest) String() string { return proto.CompactTextString(m) }
+func (*QueryStorageSlotsRequest) ProtoMessage()    {}
+func (*QueryStorageSlotsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e23e3de47cd4b5a3, []int{6}
+}
+func (m *QueryStorageSlotsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QueryStorageSlotsRequest.Unmarshal(m, b)
+}
+func (m *QueryStorageSlotsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QueryStorageSlotsRequest.Marshal(b, m, deterministic)
+}
+func (m *QueryStorageSlotsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueryStorageSlotsRequest.Merge(m, src)
+}
+func (m *QueryStorageSlotsRequest) XXX_Size() int {
+	return xxx_messageInfo_QueryStorageSlotsRequest.Size(m)
+}
+func (m *QueryStorageSlotsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueryStorageSlotsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueryStorageSlotsRequest proto.InternalMessageInfo
+
+func (m *QueryStorageSlotsRequest) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *QueryStorageSlotsRequest) GetPagination() *PageRequest {
+	if m != nil {
+		return m.Pagination
+	}
+	return nil
+}
+
+type StorageSlot struct {
+	Key                  string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value                string   `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StorageSlot) Reset()         { *m = StorageSlot{} }
+func (m *StorageSlot) String() string { return proto.CompactTextString(m) }
+func (*StorageSlot) ProtoMessage()    {}
+func (*StorageSlot) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e23e3de47cd4b5a3, []int{7}
+}
+func (m *StorageSlot) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StorageSlot.Unmarshal(m, b)
+}
+func (m *StorageSlot) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StorageSlot.Marshal(b, m, deterministic)
+}
+func (m *StorageSlot) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StorageSlot.Merge(m, src)
+}
+func (m *StorageSlot) XXX_Size() int {
+	return xxx_messageInfo_StorageSlot.Size(m)
+}
+func (m *StorageSlot) XXX_DiscardUnknown() {
+	xxx_messageInfo_StorageSlot.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StorageSlot proto.InternalMessageInfo
+
+func (m *StorageSlot) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *StorageSlot) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+type QueryStorageSlotsResponse struct {
+	Slots                []*StorageSlot `protobuf:"bytes,1,rep,name=slots,proto3" json:"slots,omitempty"`
+	Pagination           *PageResponse  `protobuf:"bytes,2,opt,name=pagination,proto3" json:"pagination,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *QueryStorageSlotsResponse) Reset()         { *m = QueryStorageSlotsResponse{} }
+func (m *QueryStorageSlotsResponse) String() string { return proto.CompactTextString(m) }
+func (*QueryStorageSlotsResponse) ProtoMessage()    {}
+func (*QueryStorageSlotsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e23e3de47cd4b5a3, []int{8}
+}
+func (m *QueryStorageSlotsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QueryStorageSlotsResponse.Unmarshal(m, b)
+}
+func (m *QueryStorageSlotsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QueryStorageSlotsResponse.Marshal(b, m, deterministic)
+}
+func (m *QueryStorageSlotsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueryStorageSlotsResponse.Merge(m, src)
+}
+func (m *QueryStorageSlotsResponse) XXX_Size() int {
+	return xxx_messageInfo_QueryStorageSlotsResponse.Size(m)
+}
+func (m *QueryStorageSlotsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueryStorageSlotsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueryStorageSlotsResponse proto.InternalMessageInfo
+
+func (m *QueryStorageSlotsResponse) GetSlots() []*StorageSlot {
+	if m != nil {
+		return m.Slots
+	}
+	return nil
+}
+
+func (m *QueryStorageSlotsResponse) GetPagination() *PageResponse {
+	if m != nil {
+		return m.Pagination
+	}
+	return nil
+}
+
+type QueryParamsRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *QueryParamsRequest) Reset()         { *m = QueryParamsRequest{} }
+func (m *QueryParamsRequest) String() string { return proto.CompactTextString(m) }
+func (*QueryParamsRequest) ProtoMessage()    {}
+func (*QueryParamsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e23e3de47cd4b5a3, []int{9}
+}
+func (m *QueryParamsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QueryParamsRequest.Unmarshal(m, b)
+}
+func (m *QueryParamsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QueryParamsRequest.Marshal(b, m, deterministic)
+}
+func (m *QueryParamsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueryParamsRequest.Merge(m, src)
+}
+func (m *QueryParamsRequest) XXX_Size() int {
+	return xxx_messageInfo_QueryParamsRequest.Size(m)
+}
+func (m *QueryParamsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueryParamsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueryParamsRequest proto.InternalMessageInfo
+
+type QueryParamsResponse struct {
+	Params               *evmproto.Params `protobuf:"bytes,1,opt,name=params,proto3" json:"params,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *QueryParamsResponse) Reset()         { *m = QueryParamsResponse{} }
+func (m *QueryParamsResponse) String() string { return proto.CompactTextString(m) }
+func (*QueryParamsResponse) ProtoMessage()    {}
+func (*QueryParamsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e23e3de47cd4b5a3, []int{10}
+}
+func (m *QueryParamsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QueryParamsResponse.Unmarshal(m, b)
+}
+func (m *QueryParamsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QueryParamsResponse.Marshal(b, m, deterministic)
+}
+func (m *QueryParamsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueryParamsResponse.Merge(m, src)
+}
+func (m *QueryParamsResponse) XXX_Size() int {
+	return xxx_messageInfo_QueryParamsResponse.Size(m)
+}
+func (m *QueryParamsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueryParamsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueryParamsResponse proto.InternalMessageInfo
+
+func (m *QueryParamsResponse) GetParams() *evmproto.Params {
+	if m != nil {
+		return m.Params
+	}
+	return nil
+}
+
+type QueryBloomRequest struct {
+	Height               int64    `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *QueryBloomRequest) Reset()         { *m = QueryBloomRequest{} }
+func (m *QueryBloomRequest) String() string { return proto.CompactTextString(m) }
+func (*QueryBloomRequest) ProtoMessage()    {}
+func (*QueryBloomRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e23e3de47cd4b5a3, []int{11}
+}
+func (m *QueryBloomRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QueryBloomRequest.Unmarshal(m, b)
+}
+func (m *QueryBloomRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QueryBloomRequest.Marshal(b, m, deterministic)
+}
+func (m *QueryBloomRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueryBloomRequest.Merge(m, src)
+}
+func (m *QueryBloomRequest) XXX_Size() int {
+	return xxx_messageInfo_QueryBloomRequest.Size(m)
+}
+func (m *QueryBloomRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueryBloomRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueryBloomRequest proto.InternalMessageInfo
+
+func (m *QueryBloomRequest) GetHeight() int64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+type QueryBloomResponse struct {
+	Bloom                []byte   `protobuf:"bytes,1,opt,name=bloom,proto3" json:"bloom,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *QueryBloomResponse) Reset()         { *m = QueryBloomResponse{} }
+func (m *QueryBloomResponse) String() string { return proto.CompactTextString(m) }
+func (*QueryBloomResponse) ProtoMessage()    {}
+func (*QueryBloomResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e23e3de47cd4b5a3, []int{12}
+}
+func (m *QueryBloomResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QueryBloomResponse.Unmarshal(m, b)
+}
+func (m *QueryBloomResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QueryBloomResponse.Marshal(b, m, deterministic)
+}
+func (m *QueryBloomResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueryBloomResponse.Merge(m, src)
+}
+func (m *QueryBloomResponse) XXX_Size() int {
+	return xxx_messageInfo_QueryBloomResponse.Size(m)
+}
+func (m *QueryBloomResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueryBloomResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueryBloomResponse proto.InternalMessageInfo
+
+func (m *QueryBloomResponse) GetBloom() []byte {
+	if m != nil {
+		return m.Bloom
+	}
+	return nil
+}
+
+type QueryTxLogsRequest struct {
+	// hex hash of the transaction
+	Hash                 string       `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Pagination           *PageRequest `protobuf:"bytes,2,opt,name=pagination,proto3" json:"pagination,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *QueryTxLogsRequest) Reset()         { *m = QueryTxLogsRequest{} }
+func (m *QueryTxLogsRequest) String() string { return proto.CompactTextString(m) }
+func (*QueryTxLogsRequest) ProtoMessage()    {}
+func (*QueryTxLogsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e23e3de47cd4b5a3, []int{13}
+}
+func (m *QueryTxLogsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QueryTxLogsRequest.Unmarshal(m, b)
+}
+func (m *QueryTxLogsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QueryTxLogsRequest.Marshal(b, m, deterministic)
+}
+func (m *QueryTxLogsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueryTxLogsRequest.Merge(m, src)
+}
+func (m *QueryTxLogsRequest) XXX_Size() int {
+	return xxx_messageInfo_QueryTxLogsRequest.Size(m)
+}
+func (m *QueryTxLogsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueryTxLogsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueryTxLogsRequest proto.InternalMessageInfo
+
+func (m *QueryTxLogsRequest) GetHash() string {
+	if m != nil {
+		return m.Hash
+	}
+	return ""
+}
+
+func (m *QueryTxLogsRequest) GetPagination() *PageRequest {
+	if m != nil {
+		return m.Pagination
+	}
+	return nil
+}
+
+type QueryTxLogsResponse struct {
+	Logs                 []*evmproto.Log `protobuf:"bytes,1,rep,name=logs,proto3" json:"logs,omitempty"`
+	Pagination           *PageResponse   `protobuf:"bytes,2,opt,name=pagination,proto3" json:"pagination,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *QueryTxLogsResponse) Reset()         { *m = QueryTxLogsResponse{} }
+func (m *QueryTxLogsResponse) String() string { return proto.CompactTextString(m) }
+func (*QueryTxLogsResponse) ProtoMessage()    {}
+func (*QueryTxLogsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e23e3de47cd4b5a3, []int{14}
+}
+func (m *QueryTxLogsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QueryTxLogsResponse.Unmarshal(m, b)
+}
+func (m *QueryTxLogsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QueryTxLogsResponse.Marshal(b, m, deterministic)
+}
+func (m *QueryTxLogsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueryTxLogsResponse.Merge(m, src)
+}
+func (m *QueryTxLogsResponse) XXX_Size() int {
+	return xxx_messageInfo_QueryTxLogsResponse.Size(m)
+}
+func (m *QueryTxLogsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueryTxLogsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueryTxLogsResponse proto.InternalMessageInfo
+
+func (m *QueryTxLogsResponse) GetLogs() []*evmproto.Log {
+	if m != nil {
+		return m.Logs
+	}
+	return nil
+}
+
+func (m *QueryTxLogsResponse) GetPagination() *PageResponse {
+	if m != nil {
+		return m.Pagination
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*PageRequest)(nil), "okexchain.evm.v1.PageRequest")
+	proto.RegisterType((*PageResponse)(nil), "okexchain.evm.v1.PageResponse")
+	proto.RegisterType((*QueryCodeRequest)(nil), "okexchain.evm.v1.QueryCodeRequest")
+	proto.RegisterType((*QueryCodeResponse)(nil), "okexchain.evm.v1.QueryCodeResponse")
+	proto.RegisterType((*QueryStorageRequest)(nil), "okexchain.evm.v1.QueryStorageRequest")
+	proto.RegisterType((*QueryStorageResponse)(nil), "okexchain.evm.v1.QueryStorageResponse")
+	proto.RegisterType((*QueryStorageSlotsRequest)(nil), "okexchain.evm.v1.QueryStorageSlotsRequest")
+	proto.RegisterType((*StorageSlot)(nil), "okexchain.evm.v1.StorageSlot")
+	proto.RegisterType((*QueryStorageSlotsResponse)(nil), "okexchain.evm.v1.QueryStorageSlotsResponse")
+	proto.RegisterType((*QueryParamsRequest)(nil), "okexchain.evm.v1.QueryParamsRequest")
+	proto.RegisterType((*QueryParamsResponse)(nil), "okexchain.evm.v1.QueryParamsResponse")
+	proto.RegisterType((*QueryBloomRequest)(nil), "okexchain.evm.v1.QueryBloomRequest")
+	proto.RegisterType((*QueryBloomResponse)(nil), "okexchain.evm.v1.QueryBloomResponse")
+	proto.RegisterType((*QueryTxLogsRequest)(nil), "okexchain.evm.v1.QueryTxLogsRequest")
+	proto.RegisterType((*QueryTxLogsResponse)(nil), "okexchain.evm.v1.QueryTxLogsResponse")
+}
+
+func init() { proto.RegisterFile("x/evm/types/query.proto", fileDescriptor_e23e3de47cd4b5a3) }
+
+var fileDescriptor_e23e3de47cd4b5a3 = []byte{
+	// 608 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x55, 0x4d, 0x6f, 0xd3, 0x40,
+	0x10, 0xc5, 0x89, 0x93, 0xd0, 0x49, 0x0e, 0x61, 0x13, 0xc0, 0xb5, 0x04, 0x44, 0xdb, 0xa6, 0xf4,
+	0x4b, 0x36, 0xa4, 0xe2, 0x02, 0x02, 0x89, 0x72, 0xe0, 0x40, 0x25, 0x8a, 0x0b, 0x12, 0xe2, 0x52,
+	0x39, 0xc9, 0x6a, 0x6d, 0x35, 0xce, 0xba, 0xf6, 0x26, 0x4a, 0x6e, 0x1c, 0x39, 0xf2, 0x07, 0x90,
+	0xf8, 0xa9, 0xc8, 0xbb, 0x9b, 0x64, 0xdd, 0xe6, 0x03, 0xa9, 0xb7, 0x99, 0xc9, 0x7b, 0x6f, 0x66,
+	0xf2, 0x66, 0x65, 0x78, 0x3c, 0x71, 0xc9, 0x38, 0x72, 0xf9, 0x34, 0x26, 0xa9, 0x7b, 0x3d, 0x22,
+	0xc9, 0xd4, 0x89, 0x13, 0xc6, 0x19, 0xaa, 0xb3, 0x2b, 0x32, 0xe9, 0x05, 0x7e, 0x38, 0x74, 0xc8,
+	0x38, 0x72, 0xc6, 0x2f, 0xed, 0x3d, 0x1e, 0x84, 0x49, 0xff, 0x32, 0xf6, 0x13, 0x3e, 0x75, 0x05,
+	0xc8, 0xa5, 0x8c, 0xb2, 0x45, 0x24, 0x99, 0xb6, 0x25, 0x25, 0xc9, 0x38, 0x92, 0x3f, 0x65, 0x74,
+	0x11, 0xe1, 0x37, 0x50, 0x3d, 0xf7, 0x29, 0xf1, 0xc8, 0xf5, 0x88, 0xa4, 0x1c, 0xd5, 0xa1, 0x78,
+	0x45, 0xa6, 0x96, 0xd1, 0x32, 0xf6, 0x6b, 0x5e, 0x16, 0xa2, 0x26, 0x94, 0x06, 0x61, 0x14, 0x72,
+	0xab, 0xd0, 0x32, 0xf6, 0x4d, 0x4f, 0x26, 0xaf, 0xcd, 0x5f, 0x7f, 0x9f, 0xdd, 0xc3, 0x07, 0x50,
+	0x93, 0xe4, 0x34, 0x66, 0xc3, 0x94, 0xa0, 0x6d, 0xb8, 0x3f, 0x24, 0x13, 0x7e, 0xb9, 0x90, 0xa8,
+	0x64, 0xf9, 0x27, 0x32, 0xc5, 0xc7, 0x50, 0xff, 0x92, 0xad, 0xf2, 0x81, 0xf5, 0xe7, 0xcd, 0x2c,
+	0xa8, 0xf8, 0xfd, 0x7e, 0x42, 0xd2, 0x54, 0xa0, 0xb7, 0xbc, 0x59, 0x8a, 0x9f, 0xc3, 0x03, 0x0d,
+	0xad, 0xd4, 0x11, 0x98, 0x3d, 0xd6, 0x27, 0x4a, 0x59, 0xc4, 0xf8, 0x3d, 0x34, 0x04, 0xf0, 0x82,
+	0xb3, 0xc4, 0xa7, 0x9b, 0x95, 0x67, 0x0b, 0x16, 0x44, 0x35, 0x0b, 0xf1, 0x31, 0x34, 0xf3, 0x12,
+	0xaa, 0x5d, 0x13, 0x4a, 0x63, 0x7f, 0x30, 0x22, 0x4a, 0x41, 0x26, 0x38, 0x05, 0x4b, 0x47, 0x5f,
+	0x0c, 0x18, 0x4f, 0x37, 0x77, 0x7d, 0x0b, 0x10, 0xfb, 0x34, 0x1c, 0xfa, 0x3c, 0x64, 0x43, 0xd1,
+	0xbc, 0xda, 0x79, 0xe2, 0xdc, 0xb4, 0xd3, 0xd1, 0x9c, 0xf0, 0x34, 0x02, 0x7e, 0x05, 0x55, 0xad,
+	0x9f, 0x6e, 0xd2, 0xd6, 0xdc, 0x24, 0x39, 0x6b, 0x41, 0x9f, 0xf5, 0xb7, 0x01, 0xdb, 0x4b, 0x86,
+	0x55, 0xfb, 0x9d, 0x40, 0x29, 0xcd, 0x0a, 0x96, 0xd1, 0x2a, 0x2e, 0x1f, 0x47, 0xa3, 0x79, 0x12,
+	0x8b, 0xde, 0x2d, 0x59, 0xe4, 0xe9, 0xaa, 0x45, 0x64, 0xa3, 0xdc, 0x26, 0x4d, 0x40, 0x62, 0xa2,
+	0x73, 0x3f, 0xf1, 0xa3, 0xd9, 0x1f, 0x87, 0x3f, 0x42, 0x23, 0x57, 0x55, 0x13, 0xbe, 0x80, 0x72,
+	0x2c, 0x2a, 0x62, 0xd5, 0x6a, 0xc7, 0x5a, 0xd6, 0x48, 0x30, 0x14, 0x0e, 0x1f, 0xa9, 0xbb, 0x39,
+	0x1d, 0x30, 0x16, 0xcd, 0x6c, 0x79, 0x04, 0xe5, 0x80, 0x84, 0x34, 0xe0, 0x42, 0xa6, 0xe8, 0xa9,
+	0x0c, 0x1f, 0x02, 0xd2, 0xc1, 0x0b, 0xdb, 0xbb, 0x59, 0x41, 0x9d, 0x99, 0x4c, 0x30, 0x55, 0xd8,
+	0xaf, 0x93, 0x33, 0x46, 0xe7, 0x86, 0x23, 0x30, 0x03, 0x3f, 0x0d, 0x94, 0x13, 0x22, 0xbe, 0xab,
+	0xd5, 0x3f, 0x0d, 0x68, 0xe4, 0x3a, 0xa9, 0xb1, 0x0e, 0xc0, 0x1c, 0x30, 0x3a, 0x33, 0xeb, 0xe1,
+	0x6d, 0xc1, 0x33, 0x46, 0x3d, 0x01, 0xb9, 0xab, 0x47, 0x9d, 0x3f, 0x26, 0x94, 0xc4, 0x08, 0xe8,
+	0x33, 0x98, 0xd9, 0x0b, 0x44, 0xf8, 0x36, 0xfb, 0xe6, 0x63, 0xb6, 0x77, 0xd6, 0x62, 0xd4, 0x16,
+	0xdf, 0xa1, 0xa2, 0x8e, 0x0a, 0xb5, 0x57, 0xe0, 0xf3, 0x2f, 0xd9, 0xde, 0xdb, 0x04, 0x53, 0xca,
+	0x14, 0x6a, 0xfa, 0x95, 0xa3, 0xc3, 0xf5, 0x3c, 0xfd, 0xdd, 0xda, 0x47, 0xff, 0x85, 0x55, 0x8d,
+	0xbe, 0x41, 0x59, 0x1e, 0x1d, 0xda, 0x5d, 0x41, 0xcb, 0xdd, 0xb6, 0xdd, 0xde, 0x80, 0x52, 0xb2,
+	0x1e, 0x94, 0xc4, 0x1d, 0xa2, 0x55, 0xff, 0xa3, 0x7e, 0xd2, 0xf6, 0xee, 0x7a, 0xd0, 0x62, 0x54,
+	0x79, 0x45, 0x2b, 0x47, 0xcd, 0x9d, 0xb3, 0xdd, 0xde, 0x80, 0x92, 0xb2, 0xa7, 0xed, 0x1f, 0x3b,
+	0x34, 0xe4, 0xc1, 0xa8, 0xeb, 0xf4, 0x58, 0xe4, 0x66, 0x14, 0x57, 0xb1, 0x5c, 0xed, 0xcb, 0xd5,
+	0x2d, 0x8b, 0x0f, 0xcc, 0xc9, 0xbf, 0x01, 0x00, 0x80, 0x43, 0x3b, 0xef, 0xcf, 0x06, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// QueryClient is the client API for Query service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type QueryClient interface {
+	// Code queries the code of a contract
+	Code(ctx context.Context, in *QueryCodeRequest, opts ...grpc.CallOption) (*QueryCodeResponse, error)
+	// Storage queries a storage slot of a contract
+	Storage(ctx context.Context, in *QueryStorageRequest, opts ...grpc.CallOption) (*QueryStorageResponse, error)
+	// StorageSlots queries the storage slots of a contract by pages
+	StorageSlots(ctx context.Context, in *QueryStorageSlotsRequest, opts ...grpc.CallOption) (*QueryStorageSlotsResponse, error)
+	// Params queries the params of the evm module
+	Params(ctx context.Context, in *QueryParamsRequest, opts ...grpc.CallOption) (*QueryParamsResponse, error)
+	// Bloom queries the bloom filter of a block
+	Bloom(ctx context.Context, in *QueryBloomRequest, opts ...grpc.CallOption) (*QueryBloomResponse, error)
+	// TxLogs queries the logs of a transaction by pages, it's served by the node from its tx index
+	TxLogs(ctx context.Context, in *QueryTxLogsRequest, opts ...grpc.CallOption) (*QueryTxLogsResponse, error)
+}
+
+type queryClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewQueryClient(cc *grpc.ClientConn) QueryClient {
+	return &queryClient{cc}
+}
+
+func (c *queryClient) Code(ctx context.Context, in *QueryCodeRequest, opts ...grpc.CallOption) (*QueryCodeResponse, error) {
+	out := new(QueryCodeResponse)
+	err := c.cc.Invoke(ctx, "/okexchain.evm.v1.Query/Code", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queryClient) Storage(ctx context.Context, in *QueryStorageRequest, opts ...grpc.CallOption) (*QueryStorageResponse, error) {
+	out := new(QueryStorageResponse)
+	err := c.cc.Invoke(ctx, "/okexchain.evm.v1.Query/Storage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queryClient) StorageSlots(ctx context.Context, in *QueryStorageSlotsRequest, opts ...grpc.CallOption) (*QueryStorageSlotsResponse, error) {
+	out := new(QueryStorageSlotsResponse)
+	err := c.cc.Invoke(ctx, "/okexchain.evm.v1.Query/StorageSlots", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queryClient) Params(ctx context.Context, in *QueryParamsRequest, opts ...grpc.CallOption) (*QueryParamsResponse, error) {
+	out := new(QueryParamsResponse)
+	err := c.cc.Invoke(ctx, "/okexchain.evm.v1.Query/Params", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queryClient) Bloom(ctx context.Context, in *QueryBloomRequest, opts ...grpc.CallOption) (*QueryBloomResponse, error) {
+	out := new(QueryBloomResponse)
+	err := c.cc.Invoke(ctx, "/okexchain.evm.v1.Query/Bloom", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queryClient) TxLogs(ctx context.Context, in *QueryTxLogsRequest, opts ...grpc.CallOption) (*QueryTxLogsResponse, error) {
+	out := new(QueryTxLogsResponse)
+	err := c.cc.Invoke(ctx, "/okexchain.evm.v1.Query/TxLogs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// QueryServer is the server API for Query service.
+type QueryServer interface {
+	// Code queries the code of a contract
+	Code(context.Context, *QueryCodeRequest) (*QueryCodeResponse, error)
+	// Storage queries a storage slot of a contract
+	Storage(context.Context, *QueryStorageRequest) (*QueryStorageResponse, error)
+	// StorageSlots queries the storage slots of a contract by pages
+	StorageSlots(context.Context, *QueryStorageSlotsRequest) (*QueryStorageSlotsResponse, error)
+	// Params queries the params of the evm module
+	Params(context.Context, *QueryParamsRequest) (*QueryParamsResponse, error)
+	// Bloom queries the bloom filter of a block
+	Bloom(context.Context, *QueryBloomRequest) (*QueryBloomResponse, error)
+	// TxLogs queries the logs of a transaction by pages, it's served by the node from its tx index
+	TxLogs(context.Context, *QueryTxLogsRequest) (*QueryTxLogsResponse, error)
+}
+
+// UnimplementedQueryServer can be embedded to have forward compatible implementations.
+type UnimplementedQueryServer struct {
+}
+
+func (*UnimplementedQueryServer) Code(ctx context.Context, req *QueryCodeRequest) (*QueryCodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Code not implemented")
+}
+func (*UnimplementedQueryServer) Storage(ctx context.Context, req *QueryStorageRequest) (*QueryStorageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Storage not implemented")
+}
+func (*UnimplementedQueryServer) StorageSlots(ctx context.Context, req *QueryStorageSlotsRequest) (*QueryStorageSlotsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StorageSlots not implemented")
+}
+func (*UnimplementedQueryServer) Params(ctx context.Context, req *QueryParamsRequest) (*QueryParamsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Params not implemented")
+}
+func (*UnimplementedQueryServer) Bloom(ctx context.Context, req *QueryBloomRequest) (*QueryBloomResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Bloom not implemented")
+}
+func (*UnimplementedQueryServer) TxLogs(ctx context.Context, req *QueryTxLogsRequest) (*QueryTxLogsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TxLogs not implemented")
+}
+
+func RegisterQueryServer(s *grpc.Server, srv QueryServer) {
+	s.RegisterService(&_Query_serviceDesc, srv)
+}
+
+func _Query_Code_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryCodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServer).Code(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/okexchain.evm.v1.Query/Code",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServer).Code(ctx, req.(*QueryCodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Query_Storage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryStorageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServer).Storage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/okexchain.evm.v1.Query/Storage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServer).Storage(ctx, req.(*QueryStorageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Query_StorageSlots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryStorageSlotsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServer).StorageSlots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/okexchain.evm.v1.Query/StorageSlots",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServer).StorageSlots(ctx, req.(*QueryStorageSlotsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Query_Params_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryParamsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServer).Params(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/okexchain.evm.v1.Query/Params",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServer).Params(ctx, req.(*QueryParamsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Query_Bloom_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryBloomRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServer).Bloom(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/okexchain.evm.v1.Query/Bloom",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServer).Bloom(ctx, req.(*QueryBloomRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Query_TxLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryTxLogsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServer).TxLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/okexchain.evm.v1.Query/TxLogs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServer).TxLogs(ctx, req.(*QueryTxLogsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Query_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "okexchain.evm.v1.Query",
+	HandlerType: (*QueryServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Code",
+			Handler:    _Query_Code_Handler,
+		},
+		{
+			MethodName: "Storage",
+			Handler:    _Query_Storage_Handler,
+		},
+		{
+			MethodName: "StorageSlots",
+			Handler:    _Query_StorageSlots_Handler,
+		},
+		{
+			MethodName: "Params",
+			Handler:    _Query_Params_Handler,
+		},
+		{
+			MethodName: "Bloom",
+			Handler:    _Query_Bloom_Handler,
+		},
+		{
+			MethodName: "TxLogs",
+			Handler:    _Query_TxLogs_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "x/evm/types/query.proto",
+}
@@ -0,0 +1,22 @@
+package types
+
+import (
+	"github.com/spf13/viper"
+)
+
+const (
+	FlagEnableContractRedeployAudit = "evm-redeploy-audit"
+)
+
+var enableContractRedeployAudit bool
+
+// InitContractRedeployAudit loads the contract redeployment audit switch from the node config.
+// The audit is node local: it only emits events and watcher entries, and never touches the state.
+func InitContractRedeployAudit() {
+	enableContractRedeployAudit = viper.GetBool(FlagEnableContractRedeployAudit)
+}
+
+// IsContractRedeployAuditEnabled returns whether contracts deployed over self-destructed ones are audited
+func IsContractRedeployAuditEnabled() bool {
+	return enableContractRedeployAudit
+}
@@ -0,0 +1,56 @@
+package types
+
+import (
+	"math/big"
+	"sync/atomic"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+const senderCacheSize = 100000
+
+var (
+	// senderCache keeps the recovered senders of the evm txs, so that a tx verified in CheckTx
+	// or by the batch verification of a block doesn't go through ecrecover again in DeliverTx
+	senderCache, _ = lru.NewARC(senderCacheSize)
+
+	senderCacheHits   uint64
+	senderCacheMisses uint64
+)
+
+type senderCacheKey [4 * ethcmn.HashLength]byte
+
+// newSenderCacheKey builds the cache key from the signing hash, which commits to the tx content and
+// the chain id, and from the raw signature values. The values are stored with a fixed width, so
+// out-of-range signatures are not cached and always go through the full recovery.
+func newSenderCacheKey(sigHash ethcmn.Hash, v, r, s *big.Int) (key senderCacheKey, ok bool) {
+	if v.BitLen() > 256 || r.BitLen() > 256 || s.BitLen() > 256 {
+		return key, false
+	}
+
+	copy(key[:], sigHash[:])
+	copy(key[ethcmn.HashLength:], ethcmn.BigToHash(v).Bytes())
+	copy(key[2*ethcmn.HashLength:], ethcmn.BigToHash(r).Bytes())
+	copy(key[3*ethcmn.HashLength:], ethcmn.BigToHash(s).Bytes())
+	return key, true
+}
+
+func getCachedSender(key senderCacheKey) (ethcmn.Address, bool) {
+	if v, ok := senderCache.Get(key); ok {
+		atomic.AddUint64(&senderCacheHits, 1)
+		return v.(ethcmn.Address), true
+	}
+
+	atomic.AddUint64(&senderCacheMisses, 1)
+	return ethcmn.Address{}, false
+}
+
+func cacheSender(key senderCacheKey, sender ethcmn.Address) {
+	senderCache.Add(key, sender)
+}
+
+// PopSenderCacheStats returns the hits and misses of the sender cache since the last call
+func PopSenderCacheStats() (hits, misses uint64) {
+	return atomic.SwapUint64(&senderCacheHits, 0), atomic.SwapUint64(&senderCacheMisses, 0)
+}
@@ -0,0 +1,403 @@
+package types
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	"github.com/spf13/viper"
+	dbm "github.com/tendermint/tm-db"
+)
+
+const (
+	signaturesDir = "signatures"
+
+	FlagEnableSignatures   = "evm-signatures-enable"
+	FlagSignatures4ByteURL = "evm-signatures-4byte-url"
+
+	// SignatureTypeFunction and SignatureTypeEvent are the types of the signatures, keyed by the 4 bytes selector
+	// of the functions and by the topic of the events
+	SignatureTypeFunction = "function"
+	SignatureTypeEvent    = "event"
+
+	// SignatureSourceABI and SignatureSource4Byte are the sources of the signatures, the ones of the uploaded abis
+	// take precedence over the text signatures of the 4byte directory and dataset
+	SignatureSourceABI   = "abi"
+	SignatureSource4Byte = "4byte"
+)
+
+var (
+	signaturesDB dbm.DB
+
+	errSignaturesDisabled = errors.New("the signatures db is disabled, see --" + FlagEnableSignatures)
+)
+
+// InitSignatures opens the signatures db rendering the calls and the events of the traces and of the pending txs,
+// which is enabled along with the evm traces
+func InitSignatures() {
+	if !viper.GetBool(FlagEnableSignatures) && !viper.GetBool(FlagEnableTraces) {
+		return
+	}
+
+	dataDir := filepath.Join(viper.GetString("home"), "data")
+	var err error
+	signaturesDB, err = sdk.NewLevelDB(signaturesDir, dataDir)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func CloseSignatures() {
+	if signaturesDB != nil {
+		signaturesDB.Close()
+	}
+}
+
+// Signature is the signature of a function or of an event, keyed by its selector: the 4 bytes selector of the
+// functions and the topic of the events. The fragment is the json abi of the function or of the event.
+type Signature struct {
+	Selector  hexutil.Bytes   `json:"selector"`
+	Type      string          `json:"type"`
+	Signature string          `json:"signature"`
+	Source    string          `json:"source"`
+	Fragment  json.RawMessage `json:"fragment"`
+}
+
+// parse returns the abi of the single function or event of the fragment
+func (s Signature) parse() (abi.ABI, error) {
+	parsed, err := abi.JSON(bytes.NewReader(append(append([]byte{'['}, s.Fragment...), ']')))
+	if err != nil {
+		return parsed, err
+	}
+	if len(parsed.Methods)+len(parsed.Events) != 1 {
+		return parsed, errors.New("the fragment of a signature must be a single function or event")
+	}
+	return parsed, nil
+}
+
+func newSignature(fragment json.RawMessage, source string) (Signature, error) {
+	sig := Signature{Source: source, Fragment: fragment}
+	parsed, err := sig.parse()
+	if err != nil {
+		return sig, err
+	}
+	for _, method := range parsed.Methods {
+		sig.Selector, sig.Type, sig.Signature = method.ID, SignatureTypeFunction, method.Sig
+	}
+	for _, event := range parsed.Events {
+		sig.Selector, sig.Type, sig.Signature = event.ID.Bytes(), SignatureTypeEvent, event.Sig
+	}
+	return sig, nil
+}
+
+// AddSignaturesABI adds the signatures of the functions and of the events of the json abi, replacing the ones
+// with the same selector. It returns the signatures added.
+func AddSignaturesABI(abiJSON []byte) ([]Signature, error) {
+	if signaturesDB == nil {
+		return nil, errSignaturesDisabled
+	}
+	var fragments []map[string]interface{}
+	if err := json.Unmarshal(abiJSON, &fragments); err != nil {
+		return nil, fmt.Errorf("invalid abi: %w", err)
+	}
+
+	var sigs []Signature
+	for _, fragment := range fragments {
+		// the type of the functions defaults to function
+		switch fragment["type"] {
+		case nil, "":
+			fragment["type"] = SignatureTypeFunction
+		case SignatureTypeFunction, SignatureTypeEvent:
+		default:
+			continue
+		}
+		bz, err := json.Marshal(fragment)
+		if err != nil {
+			return nil, err
+		}
+		sig, err := newSignature(bz, SignatureSourceABI)
+		if err != nil {
+			return nil, fmt.Errorf("invalid abi %s %v: %w", fragment["type"], fragment["name"], err)
+		}
+		sigs = append(sigs, sig)
+	}
+
+	batch := signaturesDB.NewBatch()
+	defer batch.Close()
+	for _, sig := range sigs {
+		bz, err := json.Marshal(sig)
+		if err != nil {
+			return nil, err
+		}
+		batch.Set(sig.Selector, bz)
+	}
+	return sigs, batch.WriteSync()
+}
+
+// AddTextSignature adds the text signature of a function or of an event of the 4byte directory or dataset, e.g.
+// transfer(address,uint256). The signature of an uploaded abi with the same selector is kept and returned.
+func AddTextSignature(text, sigType string) (Signature, error) {
+	if signaturesDB == nil {
+		return Signature{}, errSignaturesDisabled
+	}
+	sig, err := newTextSignature(text, sigType)
+	if err != nil {
+		return sig, err
+	}
+	if existing, found := GetSignature(sig.Selector); found && existing.Source == SignatureSourceABI {
+		return existing, nil
+	}
+	bz, err := json.Marshal(sig)
+	if err != nil {
+		return sig, err
+	}
+	return sig, signaturesDB.SetSync(sig.Selector, bz)
+}
+
+func newTextSignature(text, sigType string) (Signature, error) {
+	if sigType != SignatureTypeFunction && sigType != SignatureTypeEvent {
+		return Signature{}, fmt.Errorf("invalid signature type %s", sigType)
+	}
+	name, inputs, err := parseTextSignature(text)
+	if err != nil {
+		return Signature{}, err
+	}
+	fragment, err := json.Marshal(struct {
+		Type   string                   `json:"type"`
+		Name   string                   `json:"name"`
+		Inputs []abi.ArgumentMarshaling `json:"inputs"`
+	}{sigType, name, inputs})
+	if err != nil {
+		return Signature{}, err
+	}
+	sig, err := newSignature(fragment, SignatureSource4Byte)
+	if err != nil {
+		return sig, fmt.Errorf("invalid text signature %s: %w", text, err)
+	}
+	return sig, nil
+}
+
+// ImportSignatures imports the text signatures of the 4byte dataset of the path and returns the number of
+// signatures imported. The path is either a directory of files named after the hex selector, holding the
+// text signatures of the selector separated by semicolons, or a file of a text signature per line, optionally
+// preceded by its hex selector and a separator. The type of the signatures is told by the length of their
+// selector, and is sigType without a selector. The signatures which don't match their selector are skipped.
+func ImportSignatures(path, sigType string) (int, error) {
+	if signaturesDB == nil {
+		return 0, errSignaturesDisabled
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+
+	batch := signaturesDB.NewBatch()
+	defer batch.Close()
+	var imported int
+	add := func(selector, text string) (bool, error) {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return false, nil
+		}
+		typ := sigType
+		if selector != "" {
+			switch len(strings.TrimPrefix(selector, "0x")) {
+			case 8:
+				typ = SignatureTypeFunction
+			case 64:
+				typ = SignatureTypeEvent
+			default:
+				return false, nil
+			}
+		}
+		sig, err := newTextSignature(text, typ)
+		if err != nil {
+			return false, nil
+		}
+		if selector != "" && !strings.EqualFold(strings.TrimPrefix(selector, "0x"), hex.EncodeToString(sig.Selector)) {
+			return false, nil
+		}
+		if existing, found := GetSignature(sig.Selector); found && existing.Source == SignatureSourceABI {
+			return false, nil
+		}
+		bz, err := json.Marshal(sig)
+		if err != nil {
+			return false, err
+		}
+		batch.Set(sig.Selector, bz)
+		imported++
+		return true, nil
+	}
+
+	if info.IsDir() {
+		files, err := ioutil.ReadDir(path)
+		if err != nil {
+			return 0, err
+		}
+		for _, file := range files {
+			if file.IsDir() {
+				continue
+			}
+			bz, err := ioutil.ReadFile(filepath.Join(path, file.Name()))
+			if err != nil {
+				return 0, err
+			}
+			// the oldest valid signature of a selector is taken, it comes first
+			for _, text := range strings.Split(string(bz), ";") {
+				added, err := add(file.Name(), text)
+				if err != nil {
+					return 0, err
+				}
+				if added {
+					break
+				}
+			}
+		}
+		return imported, batch.WriteSync()
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		var selector string
+		if strings.HasPrefix(line, "0x") {
+			if end := strings.IndexAny(line, ",\t ;:"); end > 0 {
+				selector, line = line[:end], line[end+1:]
+			}
+		}
+		if _, err := add(selector, line); err != nil {
+			return 0, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return imported, batch.WriteSync()
+}
+
+// GetSignature returns the signature of the 4 bytes selector of a function or of the topic of an event
+func GetSignature(selector []byte) (Signature, bool) {
+	if signaturesDB == nil {
+		return Signature{}, false
+	}
+	bz, err := signaturesDB.Get(selector)
+	if err != nil || len(bz) == 0 {
+		return Signature{}, false
+	}
+	var sig Signature
+	if err := json.Unmarshal(bz, &sig); err != nil {
+		return Signature{}, false
+	}
+	return sig, true
+}
+
+// GetSignatures returns all the signatures, in the order of their selector
+func GetSignatures() ([]Signature, error) {
+	if signaturesDB == nil {
+		return nil, errSignaturesDisabled
+	}
+	iterator, err := signaturesDB.Iterator(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer iterator.Close()
+
+	sigs := make([]Signature, 0)
+	for ; iterator.Valid(); iterator.Next() {
+		var sig Signature
+		if err := json.Unmarshal(iterator.Value(), &sig); err != nil {
+			return nil, err
+		}
+		sigs = append(sigs, sig)
+	}
+	return sigs, nil
+}
+
+// DeleteSignature removes the signature of the selector
+func DeleteSignature(selector []byte) error {
+	if signaturesDB == nil {
+		return errSignaturesDisabled
+	}
+	return signaturesDB.DeleteSync(selector)
+}
+
+// parseTextSignature parses a text signature as transfer(address,uint256) into the name and the inputs of the
+// function or event. The components of the tuples are enclosed in parentheses, and named after their index as
+// the text signatures have no names.
+func parseTextSignature(text string) (string, []abi.ArgumentMarshaling, error) {
+	text = strings.ReplaceAll(text, " ", "")
+	open := strings.Index(text, "(")
+	if open <= 0 {
+		return "", nil, fmt.Errorf("invalid text signature %s", text)
+	}
+	inputs, rest, err := parseTextArgs(text[open:])
+	if err != nil || rest != "" {
+		return "", nil, fmt.Errorf("invalid text signature %s", text)
+	}
+	return text[:open], inputs, nil
+}
+
+// parseTextArgs parses the parenthesized list of types at the start of s, it returns the rest of s
+func parseTextArgs(s string) ([]abi.ArgumentMarshaling, string, error) {
+	if !strings.HasPrefix(s, "(") {
+		return nil, s, errors.New("missing (")
+	}
+	s = s[1:]
+	args := make([]abi.ArgumentMarshaling, 0)
+	if strings.HasPrefix(s, ")") {
+		return args, s[1:], nil
+	}
+
+	for {
+		var arg abi.ArgumentMarshaling
+		if strings.HasPrefix(s, "(") {
+			components, rest, err := parseTextArgs(s)
+			if err != nil {
+				return nil, s, err
+			}
+			for i := range components {
+				components[i].Name = fmt.Sprintf("field%d", i)
+			}
+			arg.Type, arg.Components, s = "tuple", components, rest
+			// the array suffixes of the tuple
+			for strings.HasPrefix(s, "[") {
+				end := strings.Index(s, "]")
+				if end < 0 {
+					return nil, s, errors.New("missing ]")
+				}
+				arg.Type, s = arg.Type+s[:end+1], s[end+1:]
+			}
+		} else {
+			end := strings.IndexAny(s, ",)")
+			if end <= 0 {
+				return nil, s, errors.New("missing type")
+			}
+			arg.Type, s = s[:end], s[end:]
+		}
+		args = append(args, arg)
+
+		switch {
+		case strings.HasPrefix(s, ")"):
+			return args, s[1:], nil
+		case strings.HasPrefix(s, ","):
+			s = s[1:]
+		default:
+			return nil, s, errors.New("missing )")
+		}
+	}
+}
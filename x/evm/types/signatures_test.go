@@ -0,0 +1,123 @@
+package types
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+)
+
+func TestParseTextSignature(t *testing.T) {
+	testCases := []struct {
+		text     string
+		name     string
+		types    []string
+		expError bool
+	}{
+		{"transfer(address,uint256)", "transfer", []string{"address", "uint256"}, false},
+		{"noArgs()", "noArgs", []string{}, false},
+		{"aggregate((address,bytes)[])", "aggregate", []string{"tuple[]"}, false},
+		{"nested((uint8,(bool,string))[2],bytes32)", "nested", []string{"tuple[2]", "bytes32"}, false},
+		{"missing(address", "", nil, true},
+		{"(address)", "", nil, true},
+		{"empty(address,)", "", nil, true},
+		{"trailing(address)x", "", nil, true},
+	}
+
+	for _, tc := range testCases {
+		name, inputs, err := parseTextSignature(tc.text)
+		if tc.expError {
+			require.Error(t, err, tc.text)
+			continue
+		}
+		require.NoError(t, err, tc.text)
+		require.Equal(t, tc.name, name)
+		types := make([]string, len(inputs))
+		for i, input := range inputs {
+			types[i] = input.Type
+		}
+		require.Equal(t, tc.types, types)
+	}
+}
+
+func TestImportSignatures(t *testing.T) {
+	_, err := ImportSignatures(os.TempDir(), SignatureTypeFunction)
+	require.Error(t, err, "the signatures db is disabled")
+
+	signaturesDB = dbm.NewMemDB()
+	defer func() { signaturesDB = nil }()
+
+	selector := func(text string) string {
+		return hex.EncodeToString(crypto.Keccak256([]byte(text))[:4])
+	}
+	topic := func(text string) string {
+		return hex.EncodeToString(crypto.Keccak256([]byte(text)))
+	}
+
+	dir, err := ioutil.TempDir("", "signatures")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// a directory of the 4byte dataset, the oldest signature of a selector comes first
+	dataset := filepath.Join(dir, "dataset")
+	require.NoError(t, os.Mkdir(dataset, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dataset, selector("transfer(address,uint256)")),
+		[]byte("transfer(address,uint256);many_msg_babbage(bytes1)"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dataset, "deadbeef"), []byte("mismatch(uint256)"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dataset, topic("Approval(address,address,uint256)")),
+		[]byte("Approval(address,address,uint256)"), 0644))
+	imported, err := ImportSignatures(dataset, SignatureTypeFunction)
+	require.NoError(t, err)
+	require.Equal(t, 2, imported)
+
+	transfer, err := hex.DecodeString(selector("transfer(address,uint256)"))
+	require.NoError(t, err)
+	sig, found := GetSignature(transfer)
+	require.True(t, found)
+	require.Equal(t, "transfer(address,uint256)", sig.Signature)
+	require.Equal(t, SignatureTypeFunction, sig.Type)
+	require.Equal(t, SignatureSource4Byte, sig.Source)
+
+	approval, err := hex.DecodeString(topic("Approval(address,address,uint256)"))
+	require.NoError(t, err)
+	sig, found = GetSignature(approval)
+	require.True(t, found)
+	require.Equal(t, SignatureTypeEvent, sig.Type)
+
+	// a file of a signature per line, with or without its selector
+	_, err = AddSignaturesABI([]byte(`[{"type":"function","name":"balanceOf","inputs":[{"name":"owner","type":"address"}]}]`))
+	require.NoError(t, err)
+	file := filepath.Join(dir, "events.txt")
+	require.NoError(t, ioutil.WriteFile(file, []byte("Deposit(address,uint256)\n\n"+
+		"0x"+selector("balanceOf(address)")+",balanceOf(address)\n"+
+		"0x"+selector("approve(address,uint256)")+"\tapprove(address,uint256)\n"+
+		"invalid(\n"), 0644))
+	imported, err = ImportSignatures(file, SignatureTypeEvent)
+	require.NoError(t, err)
+	require.Equal(t, 2, imported)
+
+	// the signatures of the abis aren't replaced
+	balanceOf, err := hex.DecodeString(selector("balanceOf(address)"))
+	require.NoError(t, err)
+	sig, found = GetSignature(balanceOf)
+	require.True(t, found)
+	require.Equal(t, SignatureSourceABI, sig.Source)
+
+	deposit, err := hex.DecodeString(topic("Deposit(address,uint256)"))
+	require.NoError(t, err)
+	sig, found = GetSignature(deposit)
+	require.True(t, found)
+	require.Equal(t, SignatureTypeEvent, sig.Type)
+
+	all, err := GetSignatures()
+	require.NoError(t, err)
+	require.Len(t, all, 5)
+
+	_, err = ImportSignatures(filepath.Join(dir, "missing"), SignatureTypeFunction)
+	require.Error(t, err)
+}
@@ -0,0 +1,201 @@
+package types
+
+import (
+	"sync/atomic"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethermint "github.com/okex/exchain/app/types"
+	stypes "github.com/okex/exchain/libs/cosmos-sdk/store/types"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+)
+
+var (
+	stateCacheHits   uint64
+	stateCacheMisses uint64
+)
+
+// StateCache keeps the accounts and the storage slots loaded by the evm txs of a block, so that the next txs of
+// the block don't read them from the store again.
+//
+// The cache of the block is the parent of a cache per CommitStateDB, written to the block cache once the tx
+// succeeds, see WriteStateCache. Only the state read from the store is cached: the state written by a tx is
+// evicted from the block cache and never cached, since the tx may still fail and its writes be dropped once its
+// handler returns. The reads of an evm tx only see the state committed by the previous txs and by its own ante
+// handler, which is kept when the tx fails, so they stay valid whatever the outcome of the tx. Hits consume the
+// gas of the store read they replace, the gas used by a tx doesn't depend on the content of the cache.
+type StateCache struct {
+	parent   *StateCache
+	accounts map[ethcmn.Address]cachedAccount
+	storage  map[ethcmn.Address]map[ethcmn.Hash][]byte
+	// written are the accounts and the slots written by the CommitStateDB, they are no longer cacheable
+	writtenAccounts map[ethcmn.Address]struct{}
+	writtenSlots    map[ethcmn.Address]map[ethcmn.Hash]struct{}
+	// evictions counts the evictions of each account from the block cache, an account loaded before an eviction
+	// is stale and not written to the block cache
+	evictions map[ethcmn.Address]uint64
+}
+
+type cachedAccount struct {
+	account *ethermint.EthAccount
+	// gas consumed by reading the account from the store
+	gas uint64
+	// evictions of the account from the block cache when it was loaded
+	evictions uint64
+}
+
+// NewStateCache creates an empty state cache on top of the parent one, which is nil for the cache of a block
+func NewStateCache(parent *StateCache) *StateCache {
+	c := &StateCache{parent: parent}
+	c.Reset()
+	return c
+}
+
+// Reset drops the cached entries, it is called at the beginning of every block
+func (c *StateCache) Reset() {
+	c.accounts = make(map[ethcmn.Address]cachedAccount)
+	c.storage = make(map[ethcmn.Address]map[ethcmn.Hash][]byte)
+	c.writtenAccounts = make(map[ethcmn.Address]struct{})
+	c.writtenSlots = make(map[ethcmn.Address]map[ethcmn.Hash]struct{})
+	c.evictions = make(map[ethcmn.Address]uint64)
+}
+
+// EvictAccount removes the account from the cache and its parents, e.g. once it is updated out of the evm
+func (c *StateCache) EvictAccount(addr ethcmn.Address) {
+	for ; c != nil; c = c.parent {
+		delete(c.accounts, addr)
+		if c.parent == nil {
+			c.evictions[addr]++
+		}
+	}
+}
+
+// root returns the cache of the block
+func (c *StateCache) root() *StateCache {
+	for c.parent != nil {
+		c = c.parent
+	}
+	return c
+}
+
+// Write moves the entries of the cache to its parent
+func (c *StateCache) Write() {
+	if c == nil || c.parent == nil {
+		return
+	}
+
+	evictions := c.root().evictions
+	for addr, acc := range c.accounts {
+		if evictions[addr] == acc.evictions {
+			c.parent.accounts[addr] = acc
+		}
+	}
+	for addr, slots := range c.storage {
+		for key, value := range slots {
+			c.parent.setSlot(addr, key, value)
+		}
+	}
+	c.Reset()
+}
+
+func (c *StateCache) getAccount(addr ethcmn.Address) (*ethermint.EthAccount, uint64, bool) {
+	if c == nil {
+		return nil, 0, false
+	}
+
+	for ; c != nil; c = c.parent {
+		if acc, ok := c.accounts[addr]; ok {
+			atomic.AddUint64(&stateCacheHits, 1)
+			return acc.account.Copy().(*ethermint.EthAccount), acc.gas, true
+		}
+	}
+
+	atomic.AddUint64(&stateCacheMisses, 1)
+	return nil, 0, false
+}
+
+// setAccount caches a copy of the account loaded from the store, unless the CommitStateDB has written it
+func (c *StateCache) setAccount(addr ethcmn.Address, acc *ethermint.EthAccount, gas uint64) {
+	if c == nil {
+		return
+	}
+	if _, ok := c.writtenAccounts[addr]; ok {
+		return
+	}
+	c.accounts[addr] = cachedAccount{
+		account:   acc.Copy().(*ethermint.EthAccount),
+		gas:       gas,
+		evictions: c.root().evictions[addr],
+	}
+}
+
+func (c *StateCache) getSlot(addr ethcmn.Address, key ethcmn.Hash) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	for ; c != nil; c = c.parent {
+		if value, ok := c.storage[addr][key]; ok {
+			atomic.AddUint64(&stateCacheHits, 1)
+			return value, true
+		}
+	}
+
+	atomic.AddUint64(&stateCacheMisses, 1)
+	return nil, false
+}
+
+// setSlot caches the value of the slot loaded from the store, unless the CommitStateDB has written it
+func (c *StateCache) setSlot(addr ethcmn.Address, key ethcmn.Hash, value []byte) {
+	if c == nil {
+		return
+	}
+	if _, ok := c.writtenSlots[addr][key]; ok {
+		return
+	}
+	if _, ok := c.storage[addr]; !ok {
+		c.storage[addr] = make(map[ethcmn.Hash][]byte)
+	}
+	c.storage[addr][key] = value
+}
+
+// writeAccount evicts the account written by the CommitStateDB, the tx doesn't cache it again
+func (c *StateCache) writeAccount(addr ethcmn.Address) {
+	if c == nil {
+		return
+	}
+	c.EvictAccount(addr)
+	c.writtenAccounts[addr] = struct{}{}
+}
+
+// writeSlot evicts the slot written by the CommitStateDB, the tx doesn't cache it again
+func (c *StateCache) writeSlot(addr ethcmn.Address, key ethcmn.Hash) {
+	if c == nil {
+		return
+	}
+	for cache := c; cache != nil; cache = cache.parent {
+		delete(cache.storage[addr], key)
+	}
+	if _, ok := c.writtenSlots[addr]; !ok {
+		c.writtenSlots[addr] = make(map[ethcmn.Hash]struct{})
+	}
+	c.writtenSlots[addr][key] = struct{}{}
+}
+
+// readGas returns the gas consumed by reading a value of the given size from a KVStore
+func readGas(size int) stypes.Gas {
+	config := stypes.KVGasConfig()
+	return config.ReadCostFlat + config.ReadCostPerByte*stypes.Gas(size)
+}
+
+// IsStateCacheable returns true if the evm state can be cached in the context: only the txs delivered in order
+// in a block share a state cache, neither the checked, the simulated, nor the parallel ones. The parallel txs
+// read the store instead, for the same state and the same gas, so that the serial and the parallel executions of
+// a block agree.
+func IsStateCacheable(ctx sdk.Context) bool {
+	return !ctx.IsCheckTx() && !ctx.IsReCheckTx() && !ctx.IsAsync() && fork == nil
+}
+
+// PopStateCacheStats returns the hits and misses of the state caches since the last call
+func PopStateCacheStats() (hits, misses uint64) {
+	return atomic.SwapUint64(&stateCacheHits, 0), atomic.SwapUint64(&stateCacheMisses, 0)
+}
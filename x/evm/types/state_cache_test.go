@@ -0,0 +1,86 @@
+package types_test
+
+import (
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethermint "github.com/okex/exchain/app/types"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	"github.com/okex/exchain/x/evm/types"
+)
+
+func (suite *StateDBTestSuite) TestStateCache() {
+	key, value := ethcmn.BytesToHash([]byte("key")), ethcmn.BytesToHash([]byte("value"))
+	newCSDB := func(ctx sdk.Context) *types.CommitStateDB {
+		return types.CreateEmptyCommitStateDB(suite.app.EvmKeeper.GenerateCSDBParams(), ctx)
+	}
+
+	csdb := newCSDB(suite.ctx)
+	csdb.SetState(suite.address, key, value)
+	suite.Require().NoError(csdb.Finalise(false))
+	_, err := csdb.Commit(false)
+	suite.Require().NoError(err)
+	csdb.WriteStateCache()
+	types.PopStateCacheStats()
+
+	// the state committed by a tx isn't cached, the next tx reads it from the store and caches it
+	csdb = newCSDB(suite.ctx)
+	suite.Require().Equal(value, csdb.GetState(suite.address, key))
+	csdb.WriteStateCache()
+	hits, misses := types.PopStateCacheStats()
+	suite.Require().Zero(hits)
+	suite.Require().Equal(uint64(2), misses)
+
+	// the cached account and slot are read for the gas of a store read, the same as the parallel txs which don't
+	// use the cache
+	ctx := suite.ctx.WithGasMeter(sdk.NewGasMeter(1000000))
+	suite.Require().Equal(value, newCSDB(ctx).GetState(suite.address, key))
+	hits, misses = types.PopStateCacheStats()
+	suite.Require().Equal(uint64(2), hits)
+	suite.Require().Zero(misses)
+
+	params := suite.app.EvmKeeper.GenerateCSDBParams()
+	params.StateCache = nil
+	uncachedCtx := suite.ctx.WithGasMeter(sdk.NewGasMeter(1000000))
+	suite.Require().Equal(value, types.CreateEmptyCommitStateDB(params, uncachedCtx).GetState(suite.address, key))
+	suite.Require().Equal(uncachedCtx.GasMeter().GasConsumed(), ctx.GasMeter().GasConsumed())
+
+	asyncCtx := suite.ctx.WithGasMeter(sdk.NewGasMeter(1000000)).WithAsync()
+	suite.Require().Equal(value, newCSDB(asyncCtx).GetState(suite.address, key))
+	suite.Require().Equal(asyncCtx.GasMeter().GasConsumed(), ctx.GasMeter().GasConsumed())
+	hits, misses = types.PopStateCacheStats()
+	suite.Require().Zero(hits + misses)
+
+	// a tx failing after its handler drops its writes, the next txs don't see them
+	failedCtx, _ := suite.ctx.CacheContext()
+	csdb = newCSDB(failedCtx)
+	suite.Require().Equal(value, csdb.GetState(suite.address, key))
+	csdb.SetState(suite.address, key, ethcmn.BytesToHash([]byte("failed")))
+	suite.Require().NoError(csdb.Finalise(false))
+	_, err = csdb.Commit(false)
+	suite.Require().NoError(err)
+	csdb.WriteStateCache()
+	types.PopStateCacheStats()
+	suite.Require().Equal(value, newCSDB(suite.ctx).GetState(suite.address, key))
+	_, misses = types.PopStateCacheStats()
+	suite.Require().Equal(uint64(2), misses)
+
+	// an account loaded before it is updated out of the evm isn't cached
+	csdb = newCSDB(suite.ctx)
+	suite.Require().Zero(csdb.GetBalance(suite.address).Sign())
+	acc := suite.app.AccountKeeper.GetAccount(suite.ctx, suite.address.Bytes())
+	suite.Require().NoError(acc.SetCoins(sdk.NewCoins(ethermint.NewPhotonCoin(sdk.NewInt(2)))))
+	suite.app.AccountKeeper.SetAccount(suite.ctx, acc)
+	csdb.WriteStateCache()
+	suite.Require().Equal(ethermint.DecToWei(sdk.NewDec(2)), newCSDB(suite.ctx).GetBalance(suite.address))
+
+	// the accounts updated out of the evm are evicted
+	acc = suite.app.AccountKeeper.GetAccount(suite.ctx, suite.address.Bytes())
+	suite.Require().NoError(acc.SetCoins(sdk.NewCoins(ethermint.NewPhotonCoin(sdk.NewInt(1)))))
+	suite.app.AccountKeeper.SetAccount(suite.ctx, acc)
+	suite.Require().Equal(ethermint.DecToWei(sdk.NewDec(1)), newCSDB(suite.ctx).GetBalance(suite.address))
+
+	// the state isn't cached for the checked txs
+	types.PopStateCacheStats()
+	suite.Require().Equal(value, newCSDB(suite.ctx.WithIsCheckTx(true)).GetState(suite.address, key))
+	hits, misses = types.PopStateCacheStats()
+	suite.Require().Zero(hits + misses)
+}
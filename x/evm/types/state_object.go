@@ -164,6 +164,9 @@ func (so *stateObject) SetState(db ethstate.Database, key, value ethcmn.Hash) {
 	}
 
 	prefixKey := so.GetStorageByAddressKey(key.Bytes())
+	if so.stateDB.slotKeys != nil {
+		so.stateDB.slotKeys[prefixKey] = key
+	}
 
 	// since the new value is different, update and journal the change
 	so.stateDB.journal.append(storageChange{
@@ -211,11 +214,10 @@ func (so *stateObject) setCode(codeHash ethcmn.Hash, code []byte) {
 // AddBalance adds an amount to a state object's balance. It is used to add
 // funds to the destination account of a transfer.
 func (so *stateObject) AddBalance(amount *big.Int) {
-	amt := sdk.NewDecFromBigIntWithPrec(amount, sdk.Precision) // int2dec
+	amt := types.WeiToDec(amount) // int2dec
 	// EIP158: We must check emptiness for the objects such that the account
 	// clearing (0,0,0 objects) can take effect.
 
-	// NOTE: this will panic if amount is nil
 	if amt.IsZero() {
 		if so.empty() {
 			so.touch()
@@ -230,7 +232,7 @@ func (so *stateObject) AddBalance(amount *big.Int) {
 // SubBalance removes an amount from the stateObject's balance. It is used to
 // remove funds from the origin account of a transfer.
 func (so *stateObject) SubBalance(amount *big.Int) {
-	amt := sdk.NewDecFromBigIntWithPrec(amount, sdk.Precision) // int2dec
+	amt := types.WeiToDec(amount) // int2dec
 	if amt.IsZero() {
 		return
 	}
@@ -240,7 +242,7 @@ func (so *stateObject) SubBalance(amount *big.Int) {
 
 // SetBalance sets the state object's balance.
 func (so *stateObject) SetBalance(amount *big.Int) {
-	amt := sdk.NewDecFromBigIntWithPrec(amount, sdk.Precision) // int2dec
+	amt := types.WeiToDec(amount) // int2dec
 
 	so.stateDB.journal.append(balanceChange{
 		account: &so.address,
@@ -295,6 +297,10 @@ func (so *stateObject) commitState() {
 		if (state.Value == ethcmn.Hash{}) {
 			store.Delete(state.Key.Bytes())
 			so.stateDB.ctx.Cache().UpdateStorage(so.address, state.Key, state.Value.Bytes(), true)
+			so.stateDB.stateCache.writeSlot(so.address, state.Key)
+			if fork != nil {
+				fork.writeSlot(so.address, state.Key)
+			}
 			if !so.stateDB.ctx.IsCheckTx() {
 				if so.stateDB.Watcher.Enabled() {
 					so.stateDB.Watcher.SaveState(so.Address(), state.Key.Bytes(), ethcmn.Hash{}.Bytes())
@@ -322,6 +328,7 @@ func (so *stateObject) commitState() {
 		so.originStorage[idx].Value = state.Value
 		store.Set(state.Key.Bytes(), state.Value.Bytes())
 		so.stateDB.ctx.Cache().UpdateStorage(so.address, state.Key, state.Value.Bytes(), true)
+		so.stateDB.stateCache.writeSlot(so.address, state.Key)
 		if !so.stateDB.ctx.IsCheckTx() {
 			if so.stateDB.Watcher.Enabled() {
 				so.stateDB.Watcher.SaveState(so.Address(), state.Key.Bytes(), state.Value.Bytes())
@@ -393,6 +400,12 @@ func (so *stateObject) Code(_ ethstate.Database) []byte {
 	} else {
 		store := so.stateDB.dbAdapter.NewStore(ctx.KVStore(so.stateDB.storeKey), KeyPrefixCode)
 		code = store.Get(so.CodeHash())
+		if len(code) == 0 && fork != nil {
+			var err error
+			if code, err = fork.source.GetCode(so.CodeHash()); err != nil {
+				so.setError(err)
+			}
+		}
 		ctx.Cache().UpdateCode(so.CodeHash(), code, false)
 	}
 
@@ -440,8 +453,20 @@ func (so *stateObject) GetCommittedState(_ ethstate.Database, key ethcmn.Hash) e
 
 	rawValue, ok = ctx.Cache().GetStorage(so.address, prefixKey)
 	if !ok {
-		store := so.stateDB.dbAdapter.NewStore(ctx.KVStore(so.stateDB.storeKey), AddressStoragePrefix(so.Address()))
-		rawValue = store.Get(prefixKey.Bytes())
+		if rawValue, ok = so.stateDB.stateCache.getSlot(so.address, prefixKey); ok {
+			// consume the gas of the store read replaced by the state cache
+			ctx.GasMeter().ConsumeGas(readGas(len(rawValue)), "x/evm/types/state_object.go/GetCommittedState")
+		} else {
+			store := so.stateDB.dbAdapter.NewStore(ctx.KVStore(so.stateDB.storeKey), AddressStoragePrefix(so.Address()))
+			rawValue = store.Get(prefixKey.Bytes())
+			if len(rawValue) == 0 && fork != nil {
+				var err error
+				if rawValue, err = fork.state(so.address, prefixKey); err != nil {
+					so.setError(err)
+				}
+			}
+			so.stateDB.stateCache.setSlot(so.address, prefixKey, rawValue)
+		}
 		ctx.Cache().UpdateStorage(so.address, prefixKey, rawValue, false)
 	}
 
@@ -12,6 +12,7 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
 	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
 	sdkerrors "github.com/okex/exchain/libs/cosmos-sdk/types/errors"
 	"github.com/okex/exchain/x/common/analyzer"
@@ -48,14 +49,23 @@ type ExecutionResult struct {
 	Bloom   *big.Int
 	Result  *sdk.Result
 	GasInfo GasInfo
+	// StateDiff is the changes committed by the tx, only captured once the csdb tracks them, see TrackStateDiff
+	StateDiff StateDiff
 }
 
 // GetHashFn implements vm.GetHashFunc for Ethermint. It handles 3 cases:
 //  1. The requested height matches the current height (and thus same epoch number)
 //  2. The requested height is from an previous height from the same chain epoch
 //  3. The requested height is from a height greater than the latest one
+// With the EnableBlockHashWindow param, case 2 is limited to the last BlockHashWindow heights as on
+// ethereum, and case 1 returns an empty hash as the hash of the current block isn't known to ethereum
+// contracts yet.
 func GetHashFn(ctx sdk.Context, csdb *CommitStateDB) vm.GetHashFunc {
 	return func(height uint64) common.Hash {
+		if csdb.GetParams().EnableBlockHashWindow {
+			return getWindowHash(ctx, csdb, height)
+		}
+
 		switch {
 		case ctx.BlockHeight() == int64(height):
 			// Case 1: The requested height matches the one from the context so we can retrieve the header
@@ -74,6 +84,35 @@ func GetHashFn(ctx sdk.Context, csdb *CommitStateDB) vm.GetHashFunc {
 	}
 }
 
+// getWindowHash returns the hash of one of the BlockHashWindow heights preceding the current one, and an
+// empty hash for any other height.
+func getWindowHash(ctx sdk.Context, csdb *CommitStateDB, height uint64) common.Hash {
+	current := uint64(ctx.BlockHeight())
+	if height >= current || current-height > BlockHashWindow {
+		return common.Hash{}
+	}
+
+	csdb = csdb.WithContext(ctx)
+	if hash, ok := csdb.GetRecentBlockHash(height); ok {
+		return hash
+	}
+	// the heights sealed before the window was enabled are only in the height -> hash index
+	return csdb.GetHeightHash(height)
+}
+
+// GetCoinbase returns the fee recipient set by the proposer of the block, or its consensus address if it
+// didn't set any.
+func GetCoinbase(ctx sdk.Context, sk StakingKeeper) common.Address {
+	proposer := ctx.BlockHeader().ProposerAddress
+	if sk != nil && len(proposer) != 0 {
+		// the lookup isn't charged to the tx
+		if feeRecipient, ok := sk.GetFeeRecipientByConsAddr(ctx.WithGasMeter(sdk.NewInfiniteGasMeter()), proposer); ok {
+			return common.BytesToAddress(feeRecipient)
+		}
+	}
+	return common.BytesToAddress(proposer)
+}
+
 func (st StateTransition) newEVM(
 	ctx sdk.Context,
 	csdb *CommitStateDB,
@@ -82,16 +121,19 @@ func (st StateTransition) newEVM(
 	config ChainConfig,
 	vmConfig vm.Config,
 ) *vm.EVM {
-	// Create context for evm
+	// Create context for evm. The block seen by the contracts is the tendermint block being executed:
+	// NUMBER is its height, TIMESTAMP its header time in seconds and COINBASE the fee recipient of its
+	// proposer. There is no proof of work so DIFFICULTY is always 0, and CHAINID is the one of the transaction.
 	blockCtx := vm.BlockContext{
 		CanTransfer: core.CanTransfer,
 		Transfer:    core.Transfer,
 		GetHash:     GetHashFn(ctx, csdb),
-		Coinbase:    common.BytesToAddress(ctx.BlockHeader().ProposerAddress),
+		Coinbase:    GetCoinbase(ctx, csdb.stakingKeeper),
 		BlockNumber: big.NewInt(ctx.BlockHeight()),
 		Time:        big.NewInt(ctx.BlockHeader().Time.Unix()),
 		Difficulty:  big.NewInt(0), // unused. Only required in PoW context
 		GasLimit:    gasLimit,
+		BaseFee:     new(big.Int).SetUint64(csdb.GetParams().BaseFee), // returned by BASEFEE once London is activated
 	}
 
 	txCtx := vm.TxContext{
@@ -126,6 +168,16 @@ func (st StateTransition) TransitionDb(ctx sdk.Context, config ChainConfig) (exe
 		return exeRes, resData, sdkerrors.Wrap(err, "invalid intrinsic gas for transaction"), innerTxs, erc20Contracts
 	}
 
+	isShanghai := config.IsShanghai(ctx.BlockHeight())
+	if isShanghai && contractCreation {
+		// EIP-3860: the initcode of a creation tx is limited and charged per word
+		if len(st.Payload) > params.MaxInitCodeSize {
+			return exeRes, resData, sdkerrors.Wrapf(ErrMaxInitCodeSizeExceeded, "code size %d, limit %d",
+				len(st.Payload), params.MaxInitCodeSize), innerTxs, erc20Contracts
+		}
+		cost += params.InitCodeWordGas * toWordSize(uint64(len(st.Payload)))
+	}
+
 	consumedGas := ctx.GasMeter().GasConsumed()
 	if consumedGas < cost {
 		// If Cosmos standard tx ante handler cost is less than EVM intrinsic cost
@@ -163,15 +215,43 @@ func (st StateTransition) TransitionDb(ctx sdk.Context, config ChainConfig) (exe
 		to = st.Recipient.String()
 	}
 	enableDebug := checkTracesSegment(ctx.BlockHeight(), st.Sender.String(), to)
+	if enableDebug && !st.Simulate {
+		tracer = newTraceTracer(st.TxHash)
+	}
+
+	extraEips := params.ExtraEIPs
+	if isShanghai {
+		// copy the eips of the params, so that the ones of shanghai are never appended to the stored slice
+		extraEips = append(append([]int{}, extraEips...), shanghaiEips...)
+	}
+
+	// the tx traces take precedence over the profiler, which also requires the debug mode
+	var profiler *txProfiler
+	if !enableDebug && !st.Simulate && IsEvmProfilerEnabled() {
+		profiler = newTxProfiler()
+		tracer = profiler
+		defer evmProfiler.merge(profiler)
+	}
 
 	vmConfig := vm.Config{
-		ExtraEips:  params.ExtraEIPs,
-		Debug:      enableDebug,
+		ExtraEips:  extraEips,
+		Debug:      enableDebug || profiler != nil,
 		Tracer:     tracer,
 		ContractVerifier: NewContractVerifier(params),
+		JumpDestCache:    jumpDestCache,
+		PrecompileCache:  precompileCache,
 	}
 
 	evm := st.newEVM(ctx, csdb, gasLimit, st.Price, config, vmConfig)
+	rules := evm.ChainConfig().Rules(evm.Context.BlockNumber)
+	if rules.IsBerlin {
+		// EIP-2929: the sender, the recipient and the precompiles start warm
+		csdb.PrepareAccessList(st.Sender, st.Recipient, vm.ActivePrecompiles(rules), nil)
+		if isShanghai {
+			// EIP-3651: the coinbase starts warm
+			csdb.AddAddressToAccessList(evm.Context.Coinbase)
+		}
+	}
 
 	var (
 		ret             []byte
@@ -225,6 +305,13 @@ func (st StateTransition) TransitionDb(ctx sdk.Context, config ChainConfig) (exe
 	}
 
 	gasConsumed := gasLimit - leftOverGas
+	var refund uint64
+	if rules.IsBerlin {
+		// refunds are only credited once Berlin is activated, which keeps the gas accounting of the
+		// blocks executed before the fork unchanged. As in geth, the refund is bounded by the gas used
+		// by the tx, that is the intrinsic gas plus the evm consumption.
+		refund = refundGas(cost+gasConsumed, csdb.GetRefund(), rules.IsLondon)
+	}
 
 	innerTxs, erc20Contracts = parseInnerTxAndContract(evm, err != nil)
 
@@ -232,6 +319,9 @@ func (st StateTransition) TransitionDb(ctx sdk.Context, config ChainConfig) (exe
 		// Consume gas from evm execution
 		// Out of gas check does not need to be done here since it is done within the EVM execution
 		ctx.WithGasMeter(currentGasMeter).GasMeter().ConsumeGas(gasConsumed, "EVM execution consumption")
+		if refund > 0 {
+			currentGasMeter.RefundGas(refund, "EVM refund")
+		}
 	}()
 
 	defer func() {
@@ -271,7 +361,13 @@ func (st StateTransition) TransitionDb(ctx sdk.Context, config ChainConfig) (exe
 		bloomFilter = ethtypes.BytesToBloom(bloomInt.Bytes())
 	}
 
+	var stateDiff StateDiff
 	if !st.Simulate {
+		// the diff is computed against the committed state, before the changes are finalised
+		if csdb.slotKeys != nil {
+			stateDiff = csdb.StateDiff()
+		}
+
 		// Finalise state if not a simulated transaction
 		// TODO: change to depend on config
 		if err = csdb.Finalise(true); err != nil {
@@ -316,11 +412,36 @@ func (st StateTransition) TransitionDb(ctx sdk.Context, config ChainConfig) (exe
 			GasLimit:    gasLimit,
 			GasRefunded: leftOverGas,
 		},
+		StateDiff: stateDiff,
 	}
 
 	return
 }
 
+// refundGas returns the amount of gas credited back from the refund counter of the state db. The refund
+// is capped to gasUsed/2 before London and to gasUsed/5 after EIP-3529.
+func refundGas(gasUsed, refundCounter uint64, isLondon bool) uint64 {
+	refundQuotient := params.RefundQuotient
+	if isLondon {
+		refundQuotient = params.RefundQuotientEIP3529
+	}
+
+	refund := gasUsed / refundQuotient
+	if refund > refundCounter {
+		refund = refundCounter
+	}
+	return refund
+}
+
+// shanghaiEips are the evm eips enabled by the shanghai switch: the PUSH0 opcode (EIP-3855) and the initcode
+// limit and metering of CREATE and CREATE2 (EIP-3860).
+var shanghaiEips = []int{3855, 3860}
+
+// toWordSize returns the number of 32 bytes words needed to hold size bytes
+func toWordSize(size uint64) uint64 {
+	return (size + 31) / 32
+}
+
 func newRevertError(data []byte, e error) error {
 	var resultError []string
 	if data == nil || e.Error() != vm.ErrExecutionReverted.Error() {
@@ -1,17 +1,25 @@
 package types_test
 
 import (
+	"fmt"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/okex/exchain/app/crypto/ethsecp256k1"
 	ethermint "github.com/okex/exchain/app/types"
 	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
 	abci "github.com/okex/exchain/libs/tendermint/abci/types"
 	"github.com/okex/exchain/x/evm/types"
+	"github.com/spf13/viper"
 )
 
 var (
@@ -212,6 +220,127 @@ func (suite *StateDBTestSuite) TestGetHashFn() {
 	}
 }
 
+func (suite *StateDBTestSuite) TestGetHashFn_blockHashWindow() {
+	suite.ctx = suite.ctx.WithBlockHeight(1000)
+	params := suite.stateDB.GetParams()
+	params.EnableBlockHashWindow = true
+	suite.stateDB.WithContext(suite.ctx).SetParams(params)
+
+	hash := func(height uint64) ethcmn.Hash {
+		return ethcmn.BytesToHash([]byte(fmt.Sprintf("hash %d", height)))
+	}
+	csdb := suite.stateDB.WithContext(suite.ctx)
+	csdb.SetBlockHash(hash(1000))
+	for _, height := range []uint64{999, 744} {
+		csdb.SetHeightHash(height, hash(height))
+		csdb.SetRecentBlockHash(height, hash(height))
+	}
+	// sealed before the window was enabled
+	csdb.SetHeightHash(800, hash(800))
+	csdb.SetHeightHash(743, hash(743))
+
+	getHash := types.GetHashFn(suite.ctx, suite.stateDB)
+	suite.Require().Equal(hash(999), getHash(999))
+	suite.Require().Equal(hash(744), getHash(744))
+	suite.Require().Equal(hash(800), getHash(800))
+	// out of the window
+	suite.Require().Equal(ethcmn.Hash{}, getHash(743))
+	suite.Require().Equal(ethcmn.Hash{}, getHash(1000))
+	suite.Require().Equal(ethcmn.Hash{}, getHash(1001))
+}
+
+type feeRecipientKeeper map[string]sdk.AccAddress
+
+func (k feeRecipientKeeper) GetFeeRecipientByConsAddr(_ sdk.Context, consAddr sdk.ConsAddress) (sdk.AccAddress, bool) {
+	feeRecipient, ok := k[consAddr.String()]
+	return feeRecipient, ok
+}
+
+func (k feeRecipientKeeper) GetLastValidatorPower(sdk.Context, sdk.ValAddress) int64 {
+	return 0
+}
+
+func (suite *StateDBTestSuite) TestGetCoinbase() {
+	proposer := ethcmn.HexToAddress("0x000000000000000000000000000000000000beef")
+	feeRecipient := ethcmn.HexToAddress("0x000000000000000000000000000000000000fee0")
+	ctx := suite.ctx.WithBlockHeader(abci.Header{ProposerAddress: proposer.Bytes()})
+
+	suite.Require().Equal(proposer, types.GetCoinbase(ctx, nil))
+	suite.Require().Equal(proposer, types.GetCoinbase(ctx, feeRecipientKeeper{}))
+	sk := feeRecipientKeeper{sdk.ConsAddress(proposer.Bytes()).String(): feeRecipient.Bytes()}
+	suite.Require().Equal(feeRecipient, types.GetCoinbase(ctx, sk))
+}
+
+func (suite *StateDBTestSuite) TestTransitionDbBlockContext() {
+	contract := ethcmn.HexToAddress("0x000000000000000000000000000000000000c0de")
+	proposer := ethcmn.HexToAddress("0x000000000000000000000000000000000000beef")
+	blockTime := time.Unix(1600000000, 999999999)
+	hash := func(height uint64) ethcmn.Hash {
+		return ethcmn.BytesToHash([]byte(fmt.Sprintf("hash %d", height)))
+	}
+	// stores to slots 0 to 8: TIMESTAMP, NUMBER, BLOCKHASH(NUMBER-1), BLOCKHASH(NUMBER), BLOCKHASH(NUMBER-256),
+	// BLOCKHASH(NUMBER-257), CHAINID, COINBASE, DIFFICULTY
+	code := hexutil.MustDecode("0x" +
+		"42600055" + "43600155" + "6001430340600255" + "4340600355" + "610100430340600455" +
+		"610101430340600555" + "46600655" + "41600755" + "44600855" + "00")
+
+	for _, window := range []bool{false, true} {
+		suite.SetupTest()
+		ctx := suite.ctx.WithBlockHeader(abci.Header{
+			ChainID:         "ethermint-1",
+			Height:          300,
+			Time:            blockTime,
+			ProposerAddress: proposer.Bytes(),
+		}).WithGasMeter(sdk.NewInfiniteGasMeter())
+
+		csdb := suite.stateDB.WithContext(ctx)
+		params := csdb.GetParams()
+		params.EnableBlockHashWindow = window
+		csdb.SetParams(params)
+		for _, height := range []uint64{43, 44, 299} {
+			if window {
+				csdb.SetRecentBlockHash(height, hash(height))
+			} else {
+				csdb.SetHeightHash(height, hash(height))
+			}
+		}
+		csdb.CreateAccount(contract)
+		csdb.SetCode(contract, code)
+		suite.Require().NoError(csdb.Finalise(true))
+		_, err := csdb.Commit(true)
+		suite.Require().NoError(err)
+
+		st := types.StateTransition{
+			GasLimit:  1000000,
+			Recipient: &contract,
+			Amount:    big.NewInt(0),
+			Price:     big.NewInt(1),
+			ChainID:   big.NewInt(67),
+			Csdb:      types.CreateEmptyCommitStateDB(suite.app.EvmKeeper.GenerateCSDBParams(), ctx),
+			TxHash:    &ethcmn.Hash{},
+			Sender:    suite.address,
+		}
+		st.Csdb.SetBlockHash(hash(300))
+		_, _, err, _, _ = st.TransitionDb(ctx, types.DefaultChainConfig())
+		suite.Require().NoError(err)
+
+		slot := func(i int64) ethcmn.Hash {
+			return st.Csdb.GetState(contract, ethcmn.BigToHash(big.NewInt(i)))
+		}
+		// the timestamp is truncated to seconds
+		suite.Require().Equal(ethcmn.BigToHash(big.NewInt(1600000000)), slot(0))
+		suite.Require().Equal(ethcmn.BigToHash(big.NewInt(300)), slot(1))
+		suite.Require().Equal(hash(299), slot(2))
+		// the hashes of the current block and of the blocks older than 256 are unknown, as on ethereum
+		suite.Require().Equal(ethcmn.Hash{}, slot(3))
+		suite.Require().Equal(hash(44), slot(4))
+		suite.Require().Equal(ethcmn.Hash{}, slot(5))
+		suite.Require().Equal(ethcmn.BigToHash(big.NewInt(67)), slot(6))
+		suite.Require().Equal(proposer.Hash(), slot(7))
+		suite.Require().Equal(ethcmn.Hash{}, slot(8))
+	}
+}
+
 func (suite *StateDBTestSuite) TestTransitionDb() {
 	suite.stateDB.SetNonce(suite.address, 123)
 
@@ -518,3 +647,344 @@ func (suite *StateDBTestSuite) TestTransitionDb() {
 	suite.Require().Equal(fromBalance, sdk.NewDec(4940).BigInt())
 	suite.Require().Equal(toBalance, sdk.NewDec(50).BigInt())
 }
+
+func (suite *StateDBTestSuite) TestTransitionDbGasParity() {
+	contract := ethcmn.HexToAddress("0x000000000000000000000000000000000000c0de")
+	slot := ethcmn.Hash{}
+
+	berlinConfig := types.DefaultChainConfig()
+	berlinConfig.BerlinBlock = sdk.ZeroInt()
+	londonConfig := berlinConfig
+	londonConfig.LondonBlock = sdk.ZeroInt()
+
+	testCases := []struct {
+		name    string
+		code    string
+		initial ethcmn.Hash
+	}{
+		{"sstore zero to non-zero", "0x600160005500", ethcmn.Hash{}},
+		{"sstore non-zero to zero", "0x600060005500", ethcmn.BigToHash(big.NewInt(1))},
+		{"sstore dirty slot restored", "0x6002600055600160005500", ethcmn.BigToHash(big.NewInt(1))},
+		{"sstore set and clear", "0x6001600055600060005500", ethcmn.Hash{}},
+		{"sload cold then warm", "0x6000545060005450600154505b00", ethcmn.BigToHash(big.NewInt(1))},
+		{"balance and extcodesize", "0x6001315060013b5030315000", ethcmn.Hash{}},
+	}
+
+	forks := []struct {
+		name   string
+		config types.ChainConfig
+	}{
+		{"berlin", berlinConfig},
+		{"london", londonConfig},
+	}
+
+	for _, fork := range forks {
+		config := fork.config
+		for _, tc := range testCases {
+			suite.Run(fork.name+" "+tc.name, func() {
+				suite.SetupTest()
+				code := hexutil.MustDecode(tc.code)
+				gasLimit := uint64(1000000)
+
+				// execution on the go-ethereum state, the gas used is computed as geth's state transition does
+				gethDB, err := state.New(ethcmn.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+				suite.Require().NoError(err)
+				gethDB.SetCode(contract, code)
+				gethDB.SetState(contract, slot, tc.initial)
+				gethDB.Finalise(true)
+
+				ethConfig := config.EthereumConfig(big.NewInt(1))
+				intrinsic, err := core.IntrinsicGas(nil, nil, false, true, true)
+				suite.Require().NoError(err)
+				_, leftOverGas, err := runtime.Call(contract, nil, &runtime.Config{
+					ChainConfig: ethConfig,
+					Origin:      suite.address,
+					BlockNumber: big.NewInt(1),
+					GasLimit:    gasLimit - intrinsic,
+					State:       gethDB,
+				})
+				suite.Require().NoError(err)
+				quotient := params.RefundQuotient
+				if ethConfig.IsLondon(big.NewInt(1)) {
+					quotient = params.RefundQuotientEIP3529
+				}
+				gethGasUsed := gasLimit - leftOverGas
+				refund := gethGasUsed / quotient
+				if refund > gethDB.GetRefund() {
+					refund = gethDB.GetRefund()
+				}
+				gethGasUsed -= refund
+
+				// execution on the exchain state
+				suite.stateDB.CreateAccount(contract)
+				suite.stateDB.SetCode(contract, code)
+				suite.stateDB.SetState(contract, slot, tc.initial)
+				suite.Require().NoError(suite.stateDB.Finalise(true))
+				_, err = suite.stateDB.Commit(true)
+				suite.Require().NoError(err)
+
+				ctx := suite.ctx.WithGasMeter(sdk.NewGasMeter(gasLimit))
+				st := types.StateTransition{
+					GasLimit:  gasLimit,
+					Recipient: &contract,
+					Amount:    big.NewInt(0),
+					Price:     big.NewInt(1),
+					ChainID:   big.NewInt(1),
+					Csdb:      types.CreateEmptyCommitStateDB(suite.app.EvmKeeper.GenerateCSDBParams(), ctx),
+					TxHash:    &ethcmn.Hash{},
+					Sender:    suite.address,
+				}
+				_, _, err, _, _ = st.TransitionDb(ctx, config)
+				suite.Require().NoError(err)
+				suite.Require().Equal(gethGasUsed, ctx.GasMeter().GasConsumed())
+			})
+		}
+	}
+
+	// a cleared slot under berlin: 5006 evm gas and 15000 on the refund counter, so min(26006/2, 15000) is refunded
+	suite.Run("berlin refund exceeds evm gas", func() {
+		suite.SetupTest()
+		suite.stateDB.CreateAccount(contract)
+		suite.stateDB.SetCode(contract, hexutil.MustDecode("0x600060005500"))
+		suite.stateDB.SetState(contract, slot, ethcmn.BigToHash(big.NewInt(1)))
+		suite.Require().NoError(suite.stateDB.Finalise(true))
+		_, err := suite.stateDB.Commit(true)
+		suite.Require().NoError(err)
+
+		ctx := suite.ctx.WithGasMeter(sdk.NewGasMeter(1000000))
+		st := types.StateTransition{
+			GasLimit:  1000000,
+			Recipient: &contract,
+			Amount:    big.NewInt(0),
+			Price:     big.NewInt(1),
+			ChainID:   big.NewInt(1),
+			Csdb:      types.CreateEmptyCommitStateDB(suite.app.EvmKeeper.GenerateCSDBParams(), ctx),
+			TxHash:    &ethcmn.Hash{},
+			Sender:    suite.address,
+		}
+		_, _, err, _, _ = st.TransitionDb(ctx, berlinConfig)
+		suite.Require().NoError(err)
+		suite.Require().Equal(uint64(13003), ctx.GasMeter().GasConsumed())
+	})
+}
+
+func (suite *StateDBTestSuite) TestTransitionDbLondonRules() {
+	contract := ethcmn.HexToAddress("0x000000000000000000000000000000000000c0de")
+
+	berlinConfig := types.DefaultChainConfig()
+	berlinConfig.BerlinBlock = sdk.ZeroInt()
+	londonConfig := berlinConfig
+	londonConfig.LondonBlock = sdk.ZeroInt()
+
+	newTransition := func(ctx sdk.Context, recipient *ethcmn.Address, payload []byte) types.StateTransition {
+		return types.StateTransition{
+			AccountNonce: 0,
+			GasLimit:     1000000,
+			Recipient:    recipient,
+			Amount:       big.NewInt(0),
+			Payload:      payload,
+			Price:        big.NewInt(1),
+			ChainID:      big.NewInt(1),
+			Csdb:         types.CreateEmptyCommitStateDB(suite.app.EvmKeeper.GenerateCSDBParams(), ctx),
+			TxHash:       &ethcmn.Hash{},
+			Sender:       suite.address,
+		}
+	}
+
+	testCases := []struct {
+		name   string
+		config types.ChainConfig
+		expErr bool
+	}{
+		{"berlin", berlinConfig, true},
+		{"london", londonConfig, false},
+	}
+
+	for _, tc := range testCases {
+		suite.Run("basefee "+tc.name, func() {
+			suite.SetupTest()
+			// BASEFEE ISZERO PUSH1 0 SSTORE STOP
+			suite.stateDB.CreateAccount(contract)
+			suite.stateDB.SetCode(contract, hexutil.MustDecode("0x481560005500"))
+			suite.Require().NoError(suite.stateDB.Finalise(true))
+			_, err := suite.stateDB.Commit(true)
+			suite.Require().NoError(err)
+
+			ctx := suite.ctx.WithGasMeter(sdk.NewInfiniteGasMeter())
+			st := newTransition(ctx, &contract, nil)
+			_, _, err, _, _ = st.TransitionDb(ctx, tc.config)
+			if tc.expErr {
+				// BASEFEE is an invalid opcode before london
+				suite.Require().Error(err)
+				return
+			}
+			suite.Require().NoError(err)
+			suite.Require().Equal(ethcmn.BigToHash(big.NewInt(1)), st.Csdb.GetState(contract, ethcmn.Hash{}))
+		})
+
+		suite.Run("0xEF code "+tc.name, func() {
+			suite.SetupTest()
+			// init code returning the single byte 0xEF
+			ctx := suite.ctx.WithGasMeter(sdk.NewInfiniteGasMeter())
+			st := newTransition(ctx, nil, hexutil.MustDecode("0x60ef60005360016000f3"))
+			_, resData, err, _, _ := st.TransitionDb(ctx, tc.config)
+			if !tc.expErr {
+				// EIP-3541 rejects the deployment after london
+				suite.Require().Error(err)
+				return
+			}
+			suite.Require().NoError(err)
+			suite.Require().Equal([]byte{0xef}, st.Csdb.GetCode(resData.ContractAddress))
+		})
+	}
+}
+
+func (suite *StateDBTestSuite) TestTransitionDbShanghaiRules() {
+	contract := ethcmn.HexToAddress("0x000000000000000000000000000000000000c0de")
+
+	londonConfig := types.DefaultChainConfig()
+	londonConfig.BerlinBlock = sdk.ZeroInt()
+	londonConfig.LondonBlock = sdk.ZeroInt()
+	shanghaiConfig := londonConfig
+	shanghaiConfig.ShanghaiBlock = sdk.ZeroInt()
+
+	newTransition := func(ctx sdk.Context, recipient *ethcmn.Address, payload []byte) types.StateTransition {
+		return types.StateTransition{
+			AccountNonce: 0,
+			GasLimit:     1000000,
+			Recipient:    recipient,
+			Amount:       big.NewInt(0),
+			Payload:      payload,
+			Price:        big.NewInt(1),
+			ChainID:      big.NewInt(1),
+			Csdb:         types.CreateEmptyCommitStateDB(suite.app.EvmKeeper.GenerateCSDBParams(), ctx),
+			TxHash:       &ethcmn.Hash{},
+			Sender:       suite.address,
+		}
+	}
+	setCode := func(code []byte) {
+		suite.stateDB.CreateAccount(contract)
+		suite.stateDB.SetCode(contract, code)
+		suite.Require().NoError(suite.stateDB.Finalise(true))
+		_, err := suite.stateDB.Commit(true)
+		suite.Require().NoError(err)
+	}
+	gasUsed := func(config types.ChainConfig, recipient *ethcmn.Address, payload []byte) uint64 {
+		ctx := suite.ctx.WithGasMeter(sdk.NewInfiniteGasMeter())
+		st := newTransition(ctx, recipient, payload)
+		_, _, err, _, _ := st.TransitionDb(ctx, config)
+		suite.Require().NoError(err)
+		return ctx.GasMeter().GasConsumed()
+	}
+
+	suite.Run("push0", func() {
+		for _, tc := range []struct {
+			config types.ChainConfig
+			expErr bool
+		}{
+			{londonConfig, true},
+			{shanghaiConfig, false},
+		} {
+			suite.SetupTest()
+			// PUSH1 1 PUSH0 SSTORE STOP
+			setCode(hexutil.MustDecode("0x60015f5500"))
+
+			ctx := suite.ctx.WithGasMeter(sdk.NewInfiniteGasMeter())
+			st := newTransition(ctx, &contract, nil)
+			_, _, err, _, _ := st.TransitionDb(ctx, tc.config)
+			if tc.expErr {
+				// PUSH0 is an invalid opcode before shanghai
+				suite.Require().Error(err)
+				continue
+			}
+			suite.Require().NoError(err)
+			suite.Require().Equal(ethcmn.BigToHash(big.NewInt(1)), st.Csdb.GetState(contract, ethcmn.Hash{}))
+		}
+	})
+
+	suite.Run("initcode limit", func() {
+		suite.SetupTest()
+		payload := make([]byte, params.MaxInitCodeSize+1)
+		ctx := suite.ctx.WithGasMeter(sdk.NewInfiniteGasMeter())
+		st := newTransition(ctx, nil, payload)
+		_, _, err, _, _ := st.TransitionDb(ctx, londonConfig)
+		suite.Require().NoError(err)
+
+		suite.SetupTest()
+		ctx = suite.ctx.WithGasMeter(sdk.NewInfiniteGasMeter())
+		st = newTransition(ctx, nil, payload)
+		_, _, err, _, _ = st.TransitionDb(ctx, shanghaiConfig)
+		suite.Require().Error(err)
+		suite.Require().True(types.ErrMaxInitCodeSizeExceeded.Is(err))
+	})
+
+	suite.Run("initcode gas", func() {
+		// two words of initcode cost 2 * InitCodeWordGas more after shanghai
+		payload := make([]byte, 64)
+		suite.SetupTest()
+		london := gasUsed(londonConfig, nil, payload)
+		suite.SetupTest()
+		shanghai := gasUsed(shanghaiConfig, nil, payload)
+		suite.Require().Equal(london+2*params.InitCodeWordGas, shanghai)
+	})
+
+	suite.Run("warm coinbase", func() {
+		// COINBASE BALANCE POP STOP
+		suite.SetupTest()
+		setCode(hexutil.MustDecode("0x41315000"))
+		london := gasUsed(londonConfig, &contract, nil)
+		suite.SetupTest()
+		setCode(hexutil.MustDecode("0x41315000"))
+		shanghai := gasUsed(shanghaiConfig, &contract, nil)
+		suite.Require().Equal(london-(params.ColdAccountAccessCostEIP2929-params.WarmStorageReadCostEIP2929), shanghai)
+	})
+}
+
+func (suite *StateDBTestSuite) TestTransitionDbProfiler() {
+	viper.Set(types.FlagEnableEvmProfiler, true)
+	types.InitEvmProfiler()
+	defer func() {
+		viper.Set(types.FlagEnableEvmProfiler, false)
+		types.InitEvmProfiler()
+	}()
+
+	// PUSH1 1 PUSH1 0 SSTORE STOP
+	contract := ethcmn.HexToAddress("0x000000000000000000000000000000000000c0de")
+	suite.stateDB.CreateAccount(contract)
+	suite.stateDB.SetCode(contract, hexutil.MustDecode("0x600160005500"))
+	suite.Require().NoError(suite.stateDB.Finalise(true))
+	_, err := suite.stateDB.Commit(true)
+	suite.Require().NoError(err)
+
+	types.CommitEvmProfile(suite.ctx.BlockHeight() - 1)
+	for i := 0; i < 2; i++ {
+		ctx := suite.ctx.WithGasMeter(sdk.NewInfiniteGasMeter())
+		st := types.StateTransition{
+			AccountNonce: uint64(i),
+			GasLimit:     100000,
+			Recipient:    &contract,
+			Amount:       big.NewInt(0),
+			Price:        big.NewInt(1),
+			ChainID:      big.NewInt(1),
+			Csdb:         types.CreateEmptyCommitStateDB(suite.app.EvmKeeper.GenerateCSDBParams(), ctx),
+			TxHash:       &ethcmn.Hash{},
+			Sender:       suite.address,
+		}
+		_, _, err, _, _ = st.TransitionDb(ctx, types.DefaultChainConfig())
+		suite.Require().NoError(err)
+	}
+
+	profile := types.CommitEvmProfile(suite.ctx.BlockHeight())
+	suite.Require().Equal(suite.ctx.BlockHeight(), profile.Height)
+	suite.Require().Len(profile.Contracts, 1)
+	suite.Require().Equal(contract, profile.Contracts[0].Address)
+	suite.Require().Equal(uint64(2), profile.Contracts[0].Calls)
+	suite.Require().NotZero(profile.Contracts[0].GasUsed)
+	suite.Require().Equal(uint64(4), profile.Opcodes["PUSH1"])
+	suite.Require().Equal(uint64(2), profile.Opcodes["SSTORE"])
+	suite.Require().Equal(uint64(2), profile.Opcodes["STOP"])
+	suite.Require().Equal(profile, types.GetLastEvmProfile())
+
+	// the profile of the next block starts empty
+	suite.Require().Empty(types.CommitEvmProfile(suite.ctx.BlockHeight() + 1).Contracts)
+}
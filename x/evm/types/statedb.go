@@ -1,6 +1,8 @@
 package types
 
 import (
+	"bytes"
+	"encoding/binary"
 	"fmt"
 	"github.com/okex/exchain/libs/cosmos-sdk/codec"
 	"math/big"
@@ -8,12 +10,14 @@ import (
 	"sync"
 
 	"github.com/okex/exchain/libs/cosmos-sdk/x/auth"
+	authexported "github.com/okex/exchain/libs/cosmos-sdk/x/auth/exported"
 
 	"github.com/okex/exchain/libs/cosmos-sdk/store/prefix"
 
 	"github.com/okex/exchain/libs/cosmos-sdk/store/types"
 
 	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	ethstate "github.com/ethereum/go-ethereum/core/state"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	ethvm "github.com/ethereum/go-ethereum/core/vm"
@@ -41,9 +45,12 @@ type CommitStateDBParams struct {
 	SupplyKeeper  SupplyKeeper
 	Watcher       Watcher
 	BankKeeper    BankKeeper
+	StakingKeeper StakingKeeper
 	Ada           DbAdapter
 	// Amino codec
 	Cdc *codec.Codec
+	// state cache of the block, the evm state isn't cached if nil
+	StateCache *StateCache
 }
 
 type Watcher interface {
@@ -80,6 +87,7 @@ type CommitStateDB struct {
 	supplyKeeper  SupplyKeeper
 	Watcher       Watcher
 	bankKeeper    BankKeeper
+	stakingKeeper StakingKeeper
 
 	// array that hold 'live' objects, which will get modified while processing a
 	// state transition
@@ -123,6 +131,15 @@ type CommitStateDB struct {
 
 	codeCache map[ethcmn.Address]CacheCode
 
+	// contracts self-destructed in the current transaction, only tracked in redeploy audit mode
+	destructedContracts []ethcmn.Address
+
+	// unhashed keys of the storage slots by their prefixed keys, only tracked once TrackStateDiff is called
+	slotKeys map[ethcmn.Hash]ethcmn.Hash
+
+	// accounts and storage slots loaded or committed by the csdb, on top of the state cache of the block
+	stateCache *StateCache
+
 	dbAdapter DbAdapter
 
 	// Amino codec
@@ -186,6 +203,7 @@ func CreateEmptyCommitStateDB(csdbParams CommitStateDBParams, ctx sdk.Context) *
 		accountKeeper: csdbParams.AccountKeeper,
 		supplyKeeper:  csdbParams.SupplyKeeper,
 		bankKeeper:    csdbParams.BankKeeper,
+		stakingKeeper: csdbParams.StakingKeeper,
 		Watcher:       csdbParams.Watcher,
 		cdc:           csdbParams.Cdc,
 
@@ -200,9 +218,24 @@ func CreateEmptyCommitStateDB(csdbParams CommitStateDBParams, ctx sdk.Context) *
 		logs:                []*ethtypes.Log{},
 		codeCache:           make(map[ethcmn.Address]CacheCode, 0),
 		dbAdapter:           csdbParams.Ada,
+		stateCache:          newTxStateCache(csdbParams.StateCache, ctx),
 	}
 }
 
+// newTxStateCache returns the state cache of a csdb, if the state can be cached in the context
+func newTxStateCache(blockCache *StateCache, ctx sdk.Context) *StateCache {
+	if blockCache == nil || !IsStateCacheable(ctx) {
+		return nil
+	}
+	return NewStateCache(blockCache)
+}
+
+// WriteStateCache writes the state loaded by the csdb to the state cache of the block, once its tx succeeds. The
+// state committed by the csdb isn't part of it, so it never outlives the tx when the tx fails after its handler.
+func (csdb *CommitStateDB) WriteStateCache() {
+	csdb.stateCache.Write()
+}
+
 func (csdb *CommitStateDB) SetInternalDb(dba DbAdapter) {
 	csdb.dbAdapter = dba
 }
@@ -234,6 +267,24 @@ func (csdb *CommitStateDB) IteratorCode(cb func(addr ethcmn.Address, c CacheCode
 	}
 }
 
+// TouchedSlots returns the storage slots loaded by the state objects of the CommitStateDB, which include the
+// slots written since the evm reads the committed value of a slot before writing it
+func (csdb *CommitStateDB) TouchedSlots() []PrefetchSlot {
+	var slots []PrefetchSlot
+	for _, stateEntry := range csdb.stateObjects {
+		for _, state := range stateEntry.stateObject.originStorage {
+			slots = append(slots, PrefetchSlot{Address: stateEntry.address, Key: state.Key})
+		}
+	}
+	return slots
+}
+
+// GetDestructedContracts returns the contracts removed by SELFDESTRUCT once the state is finalised.
+// It is only populated when the contract redeploy audit is enabled.
+func (csdb *CommitStateDB) GetDestructedContracts() []ethcmn.Address {
+	return csdb.destructedContracts
+}
+
 // ----------------------------------------------------------------------------
 // Setters
 // ----------------------------------------------------------------------------
@@ -251,6 +302,13 @@ func (csdb *CommitStateDB) SetHeightHash(height uint64, hash ethcmn.Hash) {
 	store.Set(key, hash.Bytes())
 }
 
+// SetRecentBlockHash stores the block header hash of the given height in the ring buffer of the last
+// BlockHashWindow heights, overwriting the hash stored BlockHashWindow heights before.
+func (csdb *CommitStateDB) SetRecentBlockHash(height uint64, hash ethcmn.Hash) {
+	store := csdb.dbAdapter.NewStore(csdb.ctx.KVStore(csdb.storeKey), KeyPrefixRecentBlockHash)
+	store.Set(RecentBlockHashKey(height), append(sdk.Uint64ToBigEndian(height), hash.Bytes()...))
+}
+
 // SetParams sets the evm parameters to the param space.
 func (csdb *CommitStateDB) SetParams(params Params) {
 	csdb.params = &params
@@ -469,7 +527,7 @@ func (csdb *CommitStateDB) PrepareAccessList(sender ethcmn.Address, dest *ethcmn
 	}
 
 	csdb.AddAddressToAccessList(sender)
-	if csdb != nil {
+	if dest != nil {
 		csdb.AddAddressToAccessList(*dest)
 		// If it's a create-tx, the destination will be added inside evm.create
 	}
@@ -522,11 +580,24 @@ func (csdb *CommitStateDB) GetHeightHash(height uint64) ethcmn.Hash {
 	return ethcmn.BytesToHash(bz)
 }
 
+// GetRecentBlockHash returns the block header hash of the given height from the ring buffer of the last
+// BlockHashWindow heights. The second return value is false when the slot holds another height, or nothing.
+func (csdb *CommitStateDB) GetRecentBlockHash(height uint64) (ethcmn.Hash, bool) {
+	store := csdb.dbAdapter.NewStore(csdb.ctx.KVStore(csdb.storeKey), KeyPrefixRecentBlockHash)
+	bz := store.Get(RecentBlockHashKey(height))
+	if len(bz) != 8+ethcmn.HashLength || binary.BigEndian.Uint64(bz[:8]) != height {
+		return ethcmn.Hash{}, false
+	}
+
+	return ethcmn.BytesToHash(bz[8:]), true
+}
+
 // GetParams returns the total set of evm parameters.
 func (csdb *CommitStateDB) GetParams() Params {
 	if csdb.params == nil {
 		var params Params
-		csdb.paramSpace.GetParamSet(csdb.ctx, &params)
+		// the params added after the launch (e.g. BaseFee) are missing until they're set by a proposal
+		csdb.paramSpace.GetParamSetIfExists(csdb.ctx, &params)
 		csdb.params = &params
 	}
 	return *csdb.params
@@ -842,7 +913,7 @@ func (csdb *CommitStateDB) IntermediateRoot(deleteEmptyObjects bool) (ethcmn.Has
 // updateStateObject writes the given state object to the store.
 func (csdb *CommitStateDB) updateStateObject(so *stateObject) error {
 	// NOTE: we don't use sdk.NewCoin here to avoid panic on test importer's genesis
-	newBalance := sdk.Coin{Denom: sdk.DefaultBondDenom, Amount: sdk.NewDecFromBigIntWithPrec(so.Balance(), sdk.Precision)} // int2dec
+	newBalance := sdk.Coin{Denom: sdk.DefaultBondDenom, Amount: ethermint.WeiToDec(so.Balance())} // int2dec
 	if !newBalance.IsValid() {
 		return fmt.Errorf("invalid balance %s", newBalance)
 	}
@@ -863,6 +934,7 @@ func (csdb *CommitStateDB) updateStateObject(so *stateObject) error {
 	}
 
 	csdb.accountKeeper.SetAccount(csdb.ctx, so.account)
+	csdb.stateCache.writeAccount(so.address)
 	if !csdb.ctx.IsCheckTx() {
 		if csdb.Watcher.Enabled() {
 			csdb.Watcher.SaveAccount(so.account, false)
@@ -876,6 +948,14 @@ func (csdb *CommitStateDB) updateStateObject(so *stateObject) error {
 func (csdb *CommitStateDB) deleteStateObject(so *stateObject) {
 	so.deleted = true
 	csdb.accountKeeper.RemoveAccount(csdb.ctx, so.account)
+	csdb.stateCache.writeAccount(so.address)
+	if fork != nil {
+		fork.deleteAccount(so.address)
+	}
+
+	if so.suicided && IsContractRedeployAuditEnabled() {
+		csdb.destructedContracts = append(csdb.destructedContracts, so.address)
+	}
 }
 
 // ----------------------------------------------------------------------------
@@ -987,7 +1067,7 @@ func (csdb *CommitStateDB) Suicide(addr ethcmn.Address) bool {
 	csdb.journal.append(suicideChange{
 		account:     &addr,
 		prev:        so.suicided,
-		prevBalance: sdk.NewDecFromBigIntWithPrec(so.Balance(), sdk.Precision), // int2dec
+		prevBalance: ethermint.WeiToDec(so.Balance()), // int2dec
 	})
 
 	so.markSuicided()
@@ -1010,6 +1090,7 @@ func (csdb *CommitStateDB) Reset(_ ethcmn.Hash) error {
 	csdb.hashToPreimageIndex = make(map[ethcmn.Hash]int)
 	csdb.accessList = newAccessList()
 	csdb.params = nil
+	csdb.destructedContracts = nil
 
 	csdb.clearJournalAndRefund()
 	return nil
@@ -1075,7 +1156,7 @@ func (csdb *CommitStateDB) CreateAccount(addr ethcmn.Address) {
 
 	newobj, prevobj := csdb.createObject(addr)
 	if prevobj != nil {
-		newobj.setBalance(sdk.DefaultBondDenom, sdk.NewDecFromBigIntWithPrec(prevobj.Balance(), sdk.Precision)) // int2dec
+		newobj.setBalance(sdk.DefaultBondDenom, ethermint.WeiToDec(prevobj.Balance())) // int2dec
 	}
 }
 
@@ -1172,8 +1253,16 @@ func (csdb *CommitStateDB) getStateObject(addr ethcmn.Address) (stateObject *sta
 		}
 	}
 
-	// otherwise, attempt to fetch the account from the account mapper
-	acc := csdb.accountKeeper.GetAccount(csdb.ctx, sdk.AccAddress(addr.Bytes()))
+	// otherwise, attempt to fetch the account from the state cache or the account mapper
+	acc := csdb.loadAccount(addr)
+	if acc == nil && fork != nil {
+		// the account is read from the chain forked from, it is stored locally once modified
+		var err error
+		if acc, err = fork.account(addr); err != nil {
+			csdb.setError(err)
+			return nil
+		}
+	}
 	if acc == nil {
 		csdb.setError(fmt.Errorf("no account found for address: %s", addr.String()))
 		return nil
@@ -1186,6 +1275,22 @@ func (csdb *CommitStateDB) getStateObject(addr ethcmn.Address) (stateObject *sta
 	return so
 }
 
+// loadAccount returns the account from the state cache, or from the account mapper once cached. A cache hit
+// consumes the gas of the read of the account mapper it replaces.
+func (csdb *CommitStateDB) loadAccount(addr ethcmn.Address) authexported.Account {
+	if acc, gas, ok := csdb.stateCache.getAccount(addr); ok {
+		csdb.ctx.GasMeter().ConsumeGas(gas, "x/evm/types/statedb.go/loadAccount")
+		return acc
+	}
+
+	gasConsumed := csdb.ctx.GasMeter().GasConsumed()
+	acc := csdb.accountKeeper.GetAccount(csdb.ctx, sdk.AccAddress(addr.Bytes()))
+	if ethAcc, ok := acc.(*ethermint.EthAccount); ok {
+		csdb.stateCache.setAccount(addr, ethAcc, csdb.ctx.GasMeter().GasConsumed()-gasConsumed)
+	}
+	return acc
+}
+
 func (csdb *CommitStateDB) setStateObject(so *stateObject) {
 	if _, found := csdb.stateObjects[so.Address()]; found {
 		// update the existing object
@@ -1448,3 +1553,117 @@ func (csdb *CommitStateDB) SetContractMethodBlocked(contract BlockedContract) {
 		csdb.Watcher.SaveContractMethodBlockedListItem(contract.Address, value)
 	}
 }
+
+// ----------------------------------------------------------------------------
+// Simulation
+// ----------------------------------------------------------------------------
+
+// TrackStateDiff makes the state db track the unhashed keys of the storage slots written from now on, so that
+// they are reported by StateDiff.
+func (csdb *CommitStateDB) TrackStateDiff() {
+	csdb.slotKeys = make(map[ethcmn.Hash]ethcmn.Hash)
+}
+
+// SetStorage replaces the whole storage of the account with the given one. It is used by the state overrides
+// of the simulations.
+func (csdb *CommitStateDB) SetStorage(addr ethcmn.Address, storage map[ethcmn.Hash]ethcmn.Hash) {
+	so := csdb.getStateObject(addr)
+	if so == nil {
+		so, _ = csdb.createObject(addr)
+	}
+
+	// the stored slots are iterated by their prefixed keys, so they are cleared without SetState
+	var committed []State
+	_ = csdb.ForEachStorage(addr, func(key, value ethcmn.Hash) bool {
+		committed = append(committed, NewState(key, value))
+		return false
+	})
+	for _, state := range committed {
+		csdb.journal.append(storageChange{
+			account:   &so.address,
+			key:       state.Key,
+			prevValue: state.Value,
+		})
+		so.setState(state.Key, ethcmn.Hash{})
+	}
+
+	for key, value := range storage {
+		csdb.SetState(addr, key, value)
+	}
+}
+
+// StateDiff returns the changes made to the accounts since the state was last finalised, compared to the
+// committed state. It must be called before the state is finalised.
+func (csdb *CommitStateDB) StateDiff() StateDiff {
+	diff := make(StateDiff)
+	for _, dirty := range csdb.journal.dirties {
+		stateEntry, exist := csdb.stateObjects[dirty.address]
+		if !exist {
+			continue
+		}
+		so := stateEntry.stateObject
+
+		prevBalance, prevNonce, prevCodeHash := zeroBalance, uint64(0), emptyCodeHash
+		if acc, ok := csdb.committedAccount(so.address).(*ethermint.EthAccount); ok {
+			prevBalance, prevNonce = acc.Balance(sdk.DefaultBondDenom).BigInt(), acc.Sequence
+			if len(acc.CodeHash) != 0 {
+				prevCodeHash = acc.CodeHash
+			}
+		}
+
+		balance, nonce, codeHash := so.Balance(), so.Nonce(), so.CodeHash()
+		if so.suicided || so.deleted {
+			balance, nonce, codeHash = zeroBalance, 0, emptyCodeHash
+		}
+
+		accDiff := &AccountDiff{}
+		if prevBalance.Cmp(balance) != 0 {
+			accDiff.Balance = &BalanceDiff{From: (*hexutil.Big)(prevBalance), To: (*hexutil.Big)(balance)}
+		}
+		if prevNonce != nonce {
+			accDiff.Nonce = &NonceDiff{From: hexutil.Uint64(prevNonce), To: hexutil.Uint64(nonce)}
+		}
+		if !bytes.Equal(prevCodeHash, codeHash) {
+			accDiff.Code = &CodeDiff{To: hexutil.Bytes(so.code)}
+			if !bytes.Equal(prevCodeHash, emptyCodeHash) {
+				accDiff.Code.From = csdb.GetCodeByHash(ethcmn.BytesToHash(prevCodeHash))
+			}
+			if bytes.Equal(codeHash, emptyCodeHash) {
+				accDiff.Code.To = nil
+			}
+		}
+
+		for _, state := range so.dirtyStorage {
+			var prev ethcmn.Hash
+			if idx, ok := so.keyToOriginStorageIndex[state.Key]; ok {
+				prev = so.originStorage[idx].Value
+			}
+			if prev == state.Value {
+				continue
+			}
+
+			key := state.Key
+			if slotKey, ok := csdb.slotKeys[state.Key]; ok {
+				key = slotKey
+			}
+			if accDiff.Storage == nil {
+				accDiff.Storage = make(map[ethcmn.Hash]*StorageDiff)
+			}
+			accDiff.Storage[key] = &StorageDiff{From: prev, To: state.Value}
+		}
+
+		if !accDiff.empty() {
+			diff[so.address] = accDiff
+		}
+	}
+	return diff
+}
+
+// committedAccount returns the account as stored before the current changes
+func (csdb *CommitStateDB) committedAccount(addr ethcmn.Address) authexported.Account {
+	acc := csdb.accountKeeper.GetAccount(csdb.ctx, sdk.AccAddress(addr.Bytes()))
+	if acc == nil && fork != nil {
+		acc, _ = fork.account(addr)
+	}
+	return acc
+}
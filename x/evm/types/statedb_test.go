@@ -13,8 +13,10 @@ import (
 	ethermint "github.com/okex/exchain/app/types"
 	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
 	"github.com/okex/exchain/libs/cosmos-sdk/x/auth"
+	authexported "github.com/okex/exchain/libs/cosmos-sdk/x/auth/exported"
 	abci "github.com/okex/exchain/libs/tendermint/abci/types"
 	"github.com/okex/exchain/x/evm/types"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -490,6 +492,42 @@ func (suite *StateDBTestSuite) TestSuiteDB_Suicide() {
 	}
 }
 
+func (suite *StateDBTestSuite) TestSuiteDB_DestructedContracts() {
+	testCase := []struct {
+		name        string
+		enableAudit bool
+	}{
+		{"audit disabled", false},
+		{"audit enabled", true},
+	}
+
+	for _, tc := range testCase {
+		suite.Run(tc.name, func() {
+			suite.SetupTest()
+			viper.Set(types.FlagEnableContractRedeployAudit, tc.enableAudit)
+			types.InitContractRedeployAudit()
+			defer func() {
+				viper.Set(types.FlagEnableContractRedeployAudit, false)
+				types.InitContractRedeployAudit()
+			}()
+
+			suite.stateDB.SetBalance(suite.address, big.NewInt(100))
+			suite.Require().True(suite.stateDB.Suicide(suite.address))
+			suite.Require().Empty(suite.stateDB.GetDestructedContracts())
+
+			suite.Require().NoError(suite.stateDB.Finalise(true))
+			if tc.enableAudit {
+				suite.Require().Equal([]ethcmn.Address{suite.address}, suite.stateDB.GetDestructedContracts())
+			} else {
+				suite.Require().Empty(suite.stateDB.GetDestructedContracts())
+			}
+
+			suite.Require().NoError(suite.stateDB.Reset(ethcmn.Hash{}))
+			suite.Require().Empty(suite.stateDB.GetDestructedContracts())
+		})
+	}
+}
+
 func (suite *StateDBTestSuite) TestCommitStateDB_Commit() {
 	testCase := []struct {
 		name       string
@@ -1244,3 +1282,60 @@ func (suite *StateDBTestSuite) TestCommitStateDB_IsContractInBlockedList() {
 	ok = suite.stateDB.IsContractInBlockedList(addr1)
 	suite.Require().True(ok)
 }
+
+type forkSource struct {
+	account *ethermint.EthAccount
+	code    []byte
+	value   ethcmn.Hash
+}
+
+func (s forkSource) GetAccount(addr ethcmn.Address) (authexported.Account, error) {
+	if addr != s.account.EthAddress() {
+		return nil, nil
+	}
+	return s.account, nil
+}
+
+func (s forkSource) GetCode(codeHash []byte) ([]byte, error) {
+	return s.code, nil
+}
+
+func (s forkSource) GetState(addr ethcmn.Address, key ethcmn.Hash) ([]byte, error) {
+	if addr != s.account.EthAddress() {
+		return nil, nil
+	}
+	return s.value.Bytes(), nil
+}
+
+func (suite *StateDBTestSuite) TestForkSource() {
+	code := []byte("code")
+	forked := ethcmn.BytesToAddress([]byte("forked"))
+	source := forkSource{
+		account: &ethermint.EthAccount{
+			BaseAccount: auth.NewBaseAccount(sdk.AccAddress(forked.Bytes()), sdk.NewCoins(ethermint.NewPhotonCoinInt64(5)), nil, 0, 3),
+			CodeHash:    ethcrypto.Keccak256(code),
+		},
+		code:  code,
+		value: ethcmn.BytesToHash([]byte("value")),
+	}
+	types.SetForkSource(source)
+	defer types.SetForkSource(nil)
+
+	key1, key2 := ethcmn.BytesToHash([]byte("key1")), ethcmn.BytesToHash([]byte("key2"))
+	suite.Require().True(suite.stateDB.Exist(forked))
+	suite.Require().Equal(uint64(3), suite.stateDB.GetNonce(forked))
+	suite.Require().Equal(code, suite.stateDB.GetCode(forked))
+	suite.Require().Equal(source.value, suite.stateDB.GetState(forked, key1))
+	suite.Require().False(suite.stateDB.Exist(ethcmn.BytesToAddress([]byte("missing"))))
+
+	// the slots deleted locally are not read from the fork source again
+	suite.stateDB.SetState(forked, key1, ethcmn.Hash{})
+	suite.Require().NoError(suite.stateDB.Finalise(false))
+	_, err := suite.stateDB.Commit(false)
+	suite.Require().NoError(err)
+
+	stateDB := types.CreateEmptyCommitStateDB(suite.app.EvmKeeper.GenerateCSDBParams(), suite.ctx)
+	suite.Require().Equal(ethcmn.Hash{}, stateDB.GetState(forked, key1))
+	suite.Require().Equal(source.value, stateDB.GetState(forked, key2))
+	suite.Require().Equal(code, stateDB.GetCode(forked))
+}
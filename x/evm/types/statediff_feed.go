@@ -0,0 +1,149 @@
+package types
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/viper"
+)
+
+const (
+	FlagEnableStateDiffFeed = "evm-state-diff-feed"
+
+	// stateDiffFeedBuffer is the number of blocks a subscriber can fall behind before the next ones are dropped
+	stateDiffFeedBuffer = 16
+)
+
+var enableStateDiffFeed bool
+
+// InitStateDiffFeed loads the state diff feed switch from the node config. The feed is node local: the diffs are
+// captured from the state committed by the delivered txs, and never touch the state.
+func InitStateDiffFeed() {
+	enableStateDiffFeed = viper.GetBool(FlagEnableStateDiffFeed)
+}
+
+// IsStateDiffFeedEnabled returns whether the changes made by the evm txs of the blocks are published
+func IsStateDiffFeedEnabled() bool {
+	return enableStateDiffFeed
+}
+
+// BlockStateDiff is the changes made to the accounts by the evm txs of a block, it's shared by all the subscribers
+// and must not be modified
+type BlockStateDiff struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	Accounts    StateDiff      `json:"accounts"`
+}
+
+// Merge applies the changes made after the ones of the diff, so that each change goes from its first value to its
+// last one. The changes back to their first value are dropped.
+func (diff StateDiff) Merge(next StateDiff) {
+	for addr, nextAcc := range next {
+		acc, ok := diff[addr]
+		if !ok {
+			acc = &AccountDiff{}
+			diff[addr] = acc
+		}
+
+		if nextAcc.Balance != nil {
+			acc.Balance = mergeBalanceDiff(acc.Balance, nextAcc.Balance)
+		}
+		if nextAcc.Nonce != nil {
+			acc.Nonce = mergeNonceDiff(acc.Nonce, nextAcc.Nonce)
+		}
+		if nextAcc.Code != nil {
+			acc.Code = mergeCodeDiff(acc.Code, nextAcc.Code)
+		}
+		for key, nextSlot := range nextAcc.Storage {
+			if acc.Storage == nil {
+				acc.Storage = make(map[common.Hash]*StorageDiff)
+			}
+			slot := &StorageDiff{From: nextSlot.From, To: nextSlot.To}
+			if prev, ok := acc.Storage[key]; ok {
+				slot.From = prev.From
+			}
+			if slot.From == slot.To {
+				delete(acc.Storage, key)
+			} else {
+				acc.Storage[key] = slot
+			}
+		}
+
+		if acc.empty() {
+			delete(diff, addr)
+		}
+	}
+}
+
+func mergeBalanceDiff(prev, next *BalanceDiff) *BalanceDiff {
+	merged := &BalanceDiff{From: next.From, To: next.To}
+	if prev != nil {
+		merged.From = prev.From
+	}
+	if merged.From.ToInt().Cmp(merged.To.ToInt()) == 0 {
+		return nil
+	}
+	return merged
+}
+
+func mergeNonceDiff(prev, next *NonceDiff) *NonceDiff {
+	merged := &NonceDiff{From: next.From, To: next.To}
+	if prev != nil {
+		merged.From = prev.From
+	}
+	if merged.From == merged.To {
+		return nil
+	}
+	return merged
+}
+
+func mergeCodeDiff(prev, next *CodeDiff) *CodeDiff {
+	merged := &CodeDiff{From: next.From, To: next.To}
+	if prev != nil {
+		merged.From = prev.From
+	}
+	if bytes.Equal(merged.From, merged.To) {
+		return nil
+	}
+	return merged
+}
+
+// stateDiffFeed broadcasts the changes of the blocks once they are executed
+type stateDiffFeed struct {
+	mtx  sync.Mutex
+	subs map[chan *BlockStateDiff]struct{}
+}
+
+var stateDiffs = &stateDiffFeed{subs: make(map[chan *BlockStateDiff]struct{})}
+
+// SubscribeStateDiffs returns the changes made by the evm txs of the executed blocks, and the function to
+// unsubscribe. The changes are dropped while the subscriber doesn't keep up.
+func SubscribeStateDiffs() (<-chan *BlockStateDiff, func()) {
+	ch := make(chan *BlockStateDiff, stateDiffFeedBuffer)
+	stateDiffs.mtx.Lock()
+	stateDiffs.subs[ch] = struct{}{}
+	stateDiffs.mtx.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			stateDiffs.mtx.Lock()
+			delete(stateDiffs.subs, ch)
+			stateDiffs.mtx.Unlock()
+		})
+	}
+}
+
+// PublishStateDiff sends the changes of the executed block to the subscribers
+func PublishStateDiff(diff *BlockStateDiff) {
+	stateDiffs.mtx.Lock()
+	defer stateDiffs.mtx.Unlock()
+	for ch := range stateDiffs.subs {
+		select {
+		case ch <- diff:
+		default:
+		}
+	}
+}
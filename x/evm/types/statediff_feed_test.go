@@ -0,0 +1,79 @@
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateDiffMerge(t *testing.T) {
+	addr, other := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+	slot, otherSlot := common.HexToHash("0x01"), common.HexToHash("0x02")
+	balance := func(from, to int64) *BalanceDiff {
+		return &BalanceDiff{From: (*hexutil.Big)(big.NewInt(from)), To: (*hexutil.Big)(big.NewInt(to))}
+	}
+
+	diff := make(StateDiff)
+	diff.Merge(StateDiff{
+		addr: {
+			Balance: balance(100, 70),
+			Nonce:   &NonceDiff{From: 1, To: 2},
+			Storage: map[common.Hash]*StorageDiff{slot: {From: common.Hash{}, To: common.HexToHash("0x0a")}},
+		},
+	})
+	diff.Merge(StateDiff{
+		addr: {
+			Balance: balance(70, 90),
+			Nonce:   &NonceDiff{From: 2, To: 3},
+			Code:    &CodeDiff{To: hexutil.Bytes{0x60, 0x00}},
+			Storage: map[common.Hash]*StorageDiff{
+				slot:      {From: common.HexToHash("0x0a"), To: common.HexToHash("0x0b")},
+				otherSlot: {From: common.Hash{}, To: common.HexToHash("0x01")},
+			},
+		},
+		other: {Balance: balance(0, 30)},
+	})
+
+	// the changes go from their first value to their last one
+	require.Len(t, diff, 2)
+	require.Equal(t, int64(100), diff[addr].Balance.From.ToInt().Int64())
+	require.Equal(t, int64(90), diff[addr].Balance.To.ToInt().Int64())
+	require.Equal(t, &NonceDiff{From: 1, To: 3}, diff[addr].Nonce)
+	require.Equal(t, hexutil.Bytes{0x60, 0x00}, diff[addr].Code.To)
+	require.Equal(t, &StorageDiff{From: common.Hash{}, To: common.HexToHash("0x0b")}, diff[addr].Storage[slot])
+	require.Len(t, diff[addr].Storage, 2)
+
+	// the changes back to their first value are dropped, along with the accounts left unchanged
+	diff.Merge(StateDiff{
+		addr: {
+			Balance: balance(90, 100),
+			Storage: map[common.Hash]*StorageDiff{slot: {From: common.HexToHash("0x0b"), To: common.Hash{}}},
+		},
+		other: {Balance: balance(30, 0)},
+	})
+	require.Len(t, diff, 1)
+	require.Nil(t, diff[addr].Balance)
+	require.NotNil(t, diff[addr].Nonce)
+	require.Len(t, diff[addr].Storage, 1)
+	require.Contains(t, diff[addr].Storage, otherSlot)
+}
+
+func TestSubscribeStateDiffs(t *testing.T) {
+	diffs, cancel := SubscribeStateDiffs()
+	diff := &BlockStateDiff{BlockNumber: 1, Accounts: StateDiff{}}
+	PublishStateDiff(diff)
+	require.Equal(t, diff, <-diffs)
+
+	// the diffs are dropped while the subscriber doesn't keep up
+	for i := 0; i < stateDiffFeedBuffer+1; i++ {
+		PublishStateDiff(diff)
+	}
+	require.Len(t, diffs, stateDiffFeedBuffer)
+
+	cancel()
+	cancel()
+	require.Empty(t, stateDiffs.subs)
+}
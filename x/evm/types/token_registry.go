@@ -0,0 +1,79 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	sdkerrors "github.com/okex/exchain/libs/cosmos-sdk/types/errors"
+)
+
+const (
+	maxTokenDenomLength  = 128
+	maxTokenNameLength   = 64
+	maxTokenSymbolLength = 32
+	// maxTokenDecimals is the number of decimal digits of the max uint256
+	maxTokenDecimals = 77
+)
+
+// TokenMetadata is the erc20 metadata of an ibc denom or of a bridged asset, so that the wallets display the
+// asset as its origin chain does
+type TokenMetadata struct {
+	// Denom is the denom of the asset on the chain, e.g. ibc/<hash of the trace>
+	Denom    string `json:"denom" yaml:"denom"`
+	Name     string `json:"name" yaml:"name"`
+	Symbol   string `json:"symbol" yaml:"symbol"`
+	Decimals uint8  `json:"decimals" yaml:"decimals"`
+	// Contract is the hex address of the erc20 contract representing the asset on the evm, if any
+	Contract string `json:"contract,omitempty" yaml:"contract,omitempty"`
+	// OriginChain is the chain id of the chain the asset is issued on, and OriginDenom its denom or its
+	// contract address there
+	OriginChain string `json:"origin_chain" yaml:"origin_chain"`
+	OriginDenom string `json:"origin_denom,omitempty" yaml:"origin_denom,omitempty"`
+}
+
+// String returns a human readable string representation of the token metadata
+func (tm TokenMetadata) String() string {
+	return fmt.Sprintf(`Token Metadata:
+  Denom:        %s
+  Name:         %s
+  Symbol:       %s
+  Decimals:     %d
+  Contract:     %s
+  Origin Chain: %s
+  Origin Denom: %s`, tm.Denom, tm.Name, tm.Symbol, tm.Decimals, tm.Contract, tm.OriginChain, tm.OriginDenom)
+}
+
+// Validate performs a stateless validation of the token metadata
+func (tm TokenMetadata) Validate() error {
+	if err := validateTokenDenom(tm.Denom); err != nil {
+		return err
+	}
+	if len(tm.Name) > maxTokenNameLength {
+		return sdkerrors.Wrapf(ErrInvalidTokenMetadata, "name is longer than %d", maxTokenNameLength)
+	}
+	if len(strings.TrimSpace(tm.Symbol)) == 0 || len(tm.Symbol) > maxTokenSymbolLength {
+		return sdkerrors.Wrapf(ErrInvalidTokenMetadata, "symbol must be 1 to %d characters", maxTokenSymbolLength)
+	}
+	if tm.Decimals > maxTokenDecimals {
+		return sdkerrors.Wrapf(ErrInvalidTokenMetadata, "decimals %d is above %d", tm.Decimals, maxTokenDecimals)
+	}
+	if tm.Contract != "" && !ethcmn.IsHexAddress(tm.Contract) {
+		return sdkerrors.Wrapf(ErrInvalidTokenMetadata, "invalid contract address %s", tm.Contract)
+	}
+	if len(strings.TrimSpace(tm.OriginChain)) == 0 {
+		return sdkerrors.Wrap(ErrInvalidTokenMetadata, "origin chain is required")
+	}
+	return nil
+}
+
+func validateTokenDenom(denom string) error {
+	if len(denom) == 0 || len(denom) > maxTokenDenomLength {
+		return sdkerrors.Wrapf(ErrInvalidTokenMetadata, "denom must be 1 to %d characters", maxTokenDenomLength)
+	}
+	if strings.IndexFunc(denom, unicode.IsSpace) >= 0 {
+		return sdkerrors.Wrapf(ErrInvalidTokenMetadata, "invalid denom %q", denom)
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// maxTraceDecodingDepth bounds the decoding of the calls nested in the bytes args, e.g. the calls of a multicall
+const maxTraceDecodingDepth = 4
+
+// DecodedCall is the function and the args of a call data, or the event and the args of a log, decoded with
+// the signatures db
+type DecodedCall struct {
+	Signature string       `json:"signature"`
+	Args      []DecodedArg `json:"args"`
+}
+
+// DecodedArg is an arg of a decoded call. The components of the tuples are decoded args as well, and the bytes
+// holding a call with a known signature, e.g. the calls of a multicall, are decoded calls.
+type DecodedArg struct {
+	Name  string      `json:"name,omitempty"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// DecodeCallData decodes the call data with the signatures db, nil if its selector is unknown or if it doesn't
+// match its signature
+func DecodeCallData(data []byte) *DecodedCall {
+	return decodeCallData(data, 0)
+}
+
+func decodeCallData(data []byte, depth int) *DecodedCall {
+	if len(data) < 4 || depth > maxTraceDecodingDepth {
+		return nil
+	}
+	sig, found := GetSignature(data[:4])
+	if !found || sig.Type != SignatureTypeFunction {
+		return nil
+	}
+	parsed, err := sig.parse()
+	if err != nil {
+		return nil
+	}
+	return decodeMethodCall(parsed, data, depth)
+}
+
+// DecodeCallDataWithABI decodes the call data with the abi of the called contract, e.g. the abi of a verified
+// contract, nil if the function isn't in the abi or if the call data doesn't match it. The calls nested in the
+// bytes args are decoded with the signatures db.
+func DecodeCallDataWithABI(contractABI abi.ABI, data []byte) *DecodedCall {
+	if len(data) < 4 {
+		return nil
+	}
+	return decodeMethodCall(contractABI, data, 0)
+}
+
+func decodeMethodCall(contractABI abi.ABI, data []byte, depth int) *DecodedCall {
+	method, err := contractABI.MethodById(data[:4])
+	if err != nil {
+		return nil
+	}
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil
+	}
+
+	call := &DecodedCall{Signature: method.Sig, Args: make([]DecodedArg, len(values))}
+	for i, input := range method.Inputs {
+		call.Args[i] = DecodedArg{
+			Name:  input.Name,
+			Type:  input.Type.String(),
+			Value: formatDecodedValue(input.Type, reflect.ValueOf(values[i]), depth),
+		}
+	}
+	return call
+}
+
+// DecodeLog decodes the event of the log with the signatures db, nil if the topic of the event is unknown. The
+// args are left out if the log doesn't match the event, e.g. an event of an abi with a different indexed args.
+// The indexed args of dynamic types are the hash of their value.
+func DecodeLog(topics []common.Hash, data []byte) *DecodedCall {
+	if len(topics) == 0 {
+		return nil
+	}
+	sig, found := GetSignature(topics[0].Bytes())
+	if !found || sig.Type != SignatureTypeEvent {
+		return nil
+	}
+	parsed, err := sig.parse()
+	if err != nil {
+		return nil
+	}
+	return decodeEventLog(parsed, topics, data)
+}
+
+// DecodeLogWithABI decodes the event of the log with the abi of the contract emitting it, e.g. the abi of a
+// verified contract, nil if the event isn't in the abi. The args are left out as by DecodeLog.
+func DecodeLogWithABI(contractABI abi.ABI, topics []common.Hash, data []byte) *DecodedCall {
+	if len(topics) == 0 {
+		return nil
+	}
+	return decodeEventLog(contractABI, topics, data)
+}
+
+func decodeEventLog(contractABI abi.ABI, topics []common.Hash, data []byte) *DecodedCall {
+	event, err := contractABI.EventByID(topics[0])
+	if err != nil {
+		return nil
+	}
+
+	log := &DecodedCall{Signature: event.Sig}
+	var indexed abi.Arguments
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+	if len(indexed) != len(topics)-1 {
+		return log
+	}
+	values, err := event.Inputs.NonIndexed().Unpack(data)
+	if err != nil {
+		return log
+	}
+
+	args := make([]DecodedArg, 0, len(event.Inputs))
+	var topic, value int
+	for _, input := range event.Inputs {
+		arg := DecodedArg{Name: input.Name, Type: input.Type.String()}
+		if input.Indexed {
+			topic++
+			switch input.Type.T {
+			case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+				arg.Value = topics[topic]
+			default:
+				decoded, err := abi.Arguments{{Type: input.Type}}.Unpack(topics[topic].Bytes())
+				if err != nil {
+					return log
+				}
+				arg.Value = formatDecodedValue(input.Type, reflect.ValueOf(decoded[0]), 0)
+			}
+		} else {
+			arg.Value = formatDecodedValue(input.Type, reflect.ValueOf(values[value]), 0)
+			value++
+		}
+		args = append(args, arg)
+	}
+	log.Args = args
+	return log
+}
+
+// formatDecodedValue formats the value of the type for the json output: the integers are decimal strings and the
+// bytes are hex strings, unless they hold a call with a known signature
+func formatDecodedValue(t abi.Type, v reflect.Value, depth int) interface{} {
+	switch t.T {
+	case abi.BytesTy:
+		data := v.Bytes()
+		if call := decodeCallData(data, depth+1); call != nil {
+			return call
+		}
+		return hexutil.Bytes(data)
+	case abi.FixedBytesTy:
+		data := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(data), v)
+		return hexutil.Bytes(data)
+	case abi.IntTy, abi.UintTy:
+		return fmt.Sprint(v.Interface())
+	case abi.SliceTy, abi.ArrayTy:
+		values := make([]interface{}, v.Len())
+		for i := range values {
+			values[i] = formatDecodedValue(*t.Elem, v.Index(i), depth)
+		}
+		return values
+	case abi.TupleTy:
+		components := make([]DecodedArg, len(t.TupleElems))
+		for i, elem := range t.TupleElems {
+			components[i] = DecodedArg{
+				Name:  t.TupleRawNames[i],
+				Type:  elem.String(),
+				Value: formatDecodedValue(*elem, v.Field(i), depth),
+			}
+		}
+		return components
+	default:
+		return v.Interface()
+	}
+}
+
+// DecorateCallTrace decorates the frames of a trace of the callTracer with their call decoded with the signatures
+// db, under the decoded field. The traces of the other tracers are returned as they are.
+func DecorateCallTrace(trace json.RawMessage) json.RawMessage {
+	var frame map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(trace))
+	decoder.UseNumber()
+	if err := decoder.Decode(&frame); err != nil {
+		return trace
+	}
+	if _, ok := frame["input"]; !ok {
+		return trace
+	}
+
+	decorateCallFrame(frame)
+	bz, err := json.Marshal(frame)
+	if err != nil {
+		return trace
+	}
+	return bz
+}
+
+func decorateCallFrame(frame map[string]interface{}) {
+	if input, ok := frame["input"].(string); ok {
+		if data, err := hexutil.Decode(input); err == nil {
+			if call := DecodeCallData(data); call != nil {
+				frame["decoded"] = call
+			}
+		}
+	}
+	calls, _ := frame["calls"].([]interface{})
+	for _, call := range calls {
+		if subframe, ok := call.(map[string]interface{}); ok {
+			decorateCallFrame(subframe)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+)
+
+const traceTestABI = `[
+	{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"name":"multicall","inputs":[{"name":"data","type":"bytes[]"}],"outputs":[{"name":"results","type":"bytes[]"}]},
+	{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256"}]}
+]`
+
+func TestTraceDecoder(t *testing.T) {
+	_, err := AddSignaturesABI([]byte(traceTestABI))
+	require.Error(t, err, "the signatures db is disabled")
+
+	signaturesDB = dbm.NewMemDB()
+	defer func() { signaturesDB = nil }()
+
+	sigs, err := AddSignaturesABI([]byte(traceTestABI))
+	require.NoError(t, err)
+	require.Len(t, sigs, 3)
+
+	// the 4byte signatures don't replace the ones of the abis
+	sig, err := AddTextSignature("transfer(address,uint256)", SignatureTypeFunction)
+	require.NoError(t, err)
+	require.Equal(t, SignatureSourceABI, sig.Source)
+	sig, err = AddTextSignature("aggregate((address,bytes)[])", SignatureTypeFunction)
+	require.NoError(t, err)
+	require.Equal(t, SignatureSource4Byte, sig.Source)
+	require.Equal(t, "aggregate((address,bytes)[])", sig.Signature)
+
+	all, err := GetSignatures()
+	require.NoError(t, err)
+	require.Len(t, all, 4)
+
+	// the type of the functions defaults to function in the signatures, not in the abi package
+	parsed, err := abi.JSON(strings.NewReader(strings.Replace(traceTestABI, `{"name":"multicall"`, `{"type":"function","name":"multicall"`, 1)))
+	require.NoError(t, err)
+	to := common.HexToAddress("0xc0ffee0000000000000000000000000000000000")
+	transfer, err := parsed.Pack("transfer", to, big.NewInt(1000))
+	require.NoError(t, err)
+	multicall, err := parsed.Pack("multicall", [][]byte{transfer, {0x1, 0x2}})
+	require.NoError(t, err)
+
+	// the calls of the multicall are decoded as well
+	call := DecodeCallData(multicall)
+	require.NotNil(t, call)
+	require.Equal(t, "multicall(bytes[])", call.Signature)
+	calls := call.Args[0].Value.([]interface{})
+	require.Len(t, calls, 2)
+	require.Equal(t, "transfer(address,uint256)", calls[0].(*DecodedCall).Signature)
+	require.Equal(t, "1000", calls[0].(*DecodedCall).Args[1].Value)
+	require.Equal(t, hexutil.Bytes{0x1, 0x2}, calls[1])
+
+	require.Nil(t, DecodeCallData([]byte{0xde, 0xad, 0xbe, 0xef}))
+	require.Nil(t, DecodeCallData(transfer[:20]))
+
+	trace, err := json.Marshal(map[string]interface{}{
+		"type":  "CALL",
+		"input": hexutil.Bytes(multicall),
+		"gas":   "0x5208",
+		"calls": []map[string]interface{}{{"type": "CALL", "input": hexutil.Bytes(transfer)}},
+	})
+	require.NoError(t, err)
+	var decorated struct {
+		Gas     string       `json:"gas"`
+		Decoded *DecodedCall `json:"decoded"`
+		Calls   []struct {
+			Decoded *DecodedCall `json:"decoded"`
+		} `json:"calls"`
+	}
+	require.NoError(t, json.Unmarshal(DecorateCallTrace(trace), &decorated))
+	require.Equal(t, "0x5208", decorated.Gas)
+	require.Equal(t, "multicall(bytes[])", decorated.Decoded.Signature)
+	require.Equal(t, "transfer(address,uint256)", decorated.Calls[0].Decoded.Signature)
+
+	// the struct logs aren't decorated
+	structLogs := json.RawMessage(`{"gas":21000,"failed":false,"returnValue":"","structLogs":[]}`)
+	require.Equal(t, structLogs, DecorateCallTrace(structLogs))
+
+	require.NoError(t, DeleteSignature(transfer[:4]))
+	_, found := GetSignature(transfer[:4])
+	require.False(t, found)
+
+	transferEvent := parsed.Events["Transfer"]
+	from := common.HexToAddress("0xbeef000000000000000000000000000000000000")
+	data, err := transferEvent.Inputs.NonIndexed().Pack(big.NewInt(1000))
+	require.NoError(t, err)
+	topics := []common.Hash{transferEvent.ID, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())}
+	log := DecodeLog(topics, data)
+	require.NotNil(t, log)
+	require.Equal(t, "Transfer(address,address,uint256)", log.Signature)
+	require.Equal(t, []DecodedArg{
+		{Name: "from", Type: "address", Value: from},
+		{Name: "to", Type: "address", Value: to},
+		{Name: "value", Type: "uint256", Value: "1000"},
+	}, log.Args)
+
+	// the args of an event with a different indexing are left out
+	log = DecodeLog(topics[:2], append(common.BytesToHash(to.Bytes()).Bytes(), data...))
+	require.Equal(t, "Transfer(address,address,uint256)", log.Signature)
+	require.Nil(t, log.Args)
+
+	// the abi of a contract decodes its calls and its events without the signatures db
+	require.NoError(t, DeleteSignature(transferEvent.ID.Bytes()))
+	require.Nil(t, DecodeLog(topics, data))
+	require.Equal(t, "Transfer(address,address,uint256)", DecodeLogWithABI(parsed, topics, data).Signature)
+	require.Equal(t, "transfer(address,uint256)", DecodeCallDataWithABI(parsed, transfer).Signature)
+	require.Nil(t, DecodeCallDataWithABI(parsed, []byte{0xde, 0xad, 0xbe, 0xef}))
+	require.Nil(t, DecodeLogWithABI(parsed, nil, data))
+
+	// the selectors of the functions aren't events
+	require.Nil(t, DecodeLog([]common.Hash{common.BytesToHash(transfer[:4])}, data))
+	require.Nil(t, DecodeLog(nil, data))
+}
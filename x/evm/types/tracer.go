@@ -29,11 +29,14 @@ const (
 	FlagTraceDisableStorage    = "evm-trace-nostorage"
 	FlagTraceDisableReturnData = "evm-trace-noreturndata"
 	FlagTraceDebug             = "evm-trace-debug"
+	FlagTraceTracer            = "evm-trace-tracer"
 )
 
 var (
 	tracesDB     dbm.DB
 	enableTraces bool
+	// traceTracer is the name of the tracer of the traces, e.g. callTracer, the struct logs are traced without it
+	traceTracer string
 
 	// trace from/to addr
 	traceFromAddrs, traceToAddrs map[string]struct{}
@@ -99,6 +102,13 @@ func InitTxTraces() {
 		Debug:             viper.GetBool(FlagTraceDebug),
 	}
 
+	traceTracer = viper.GetString(FlagTraceTracer)
+	if traceTracer != "" {
+		if _, err := tracers.New(traceTracer, &tracers.Context{}); err != nil {
+			panic(fmt.Errorf("invalid evm trace tracer %s: %w", traceTracer, err))
+		}
+	}
+
 	dataDir := filepath.Join(viper.GetString("home"), "data")
 	tracesDB, err = sdk.NewLevelDB(tracesDir, dataDir)
 	if err != nil {
@@ -106,6 +116,22 @@ func InitTxTraces() {
 	}
 }
 
+// newTraceTracer returns the tracer of the traces of the tx, the struct logger unless a tracer is configured
+func newTraceTracer(txHash *common.Hash) vm.Tracer {
+	if traceTracer == "" {
+		return vm.NewStructLogger(evmLogConfig)
+	}
+	ctx := &tracers.Context{}
+	if txHash != nil {
+		ctx.TxHash = *txHash
+	}
+	tracer, err := tracers.New(traceTracer, ctx)
+	if err != nil {
+		return vm.NewStructLogger(evmLogConfig)
+	}
+	return tracer
+}
+
 func checkTracesSegment(height int64, from, to string) bool {
 	_, fromOk := traceFromAddrs[from]
 	_, toOk := traceToAddrs[to]
@@ -176,3 +202,42 @@ func DeleteTracesFromDB(txHash []byte) error {
 	}
 	return tracesDB.Delete(txHash)
 }
+
+// GetTraceCallRecipients returns the recipients of the internal calls of the traced tx, read from the
+// stack of its struct logs. It returns nothing if the tx isn't traced, or the stack isn't traced.
+func GetTraceCallRecipients(txHash []byte) []common.Address {
+	bz := GetTracesFromDB(txHash)
+	if len(bz) == 0 {
+		return nil
+	}
+	var trace struct {
+		StructLogs []struct {
+			Op    string   `json:"op"`
+			Stack []string `json:"stack"`
+		} `json:"structLogs"`
+	}
+	if err := json.Unmarshal(bz, &trace); err != nil {
+		return nil
+	}
+
+	var recipients []common.Address
+	seen := make(map[common.Address]bool)
+	for _, log := range trace.StructLogs {
+		switch log.Op {
+		case vm.CALL.String(), vm.CALLCODE.String(), vm.DELEGATECALL.String(), vm.STATICCALL.String():
+		default:
+			continue
+		}
+		// the address is the second item of the stack of the call
+		if len(log.Stack) < 2 {
+			continue
+		}
+		addr := common.HexToAddress(log.Stack[len(log.Stack)-2])
+		if _, ok := vm.PrecompiledContractsBerlin[addr]; ok || seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		recipients = append(recipients, addr)
+	}
+	return recipients
+}
@@ -0,0 +1,241 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/viper"
+)
+
+const (
+	FlagTxBlacklistFile = "evm-tx-blacklist-file"
+
+	// maxTxBlacklistRules bounds the rules of the blacklist, which are matched against every checked tx
+	maxTxBlacklistRules = 1000
+)
+
+// TxBlacklistRule refuses the admission to the mempool of the evm txs calling the target address, the ones whose
+// calldata starts with the selector, or both if both are set. The rule is dropped once it expires, it never
+// expires without an expiry.
+type TxBlacklistRule struct {
+	To       *common.Address `json:"to,omitempty"`
+	Selector hexutil.Bytes   `json:"selector,omitempty"`
+	Expiry   *time.Time      `json:"expiry,omitempty"`
+	Reason   string          `json:"reason,omitempty"`
+	Added    time.Time       `json:"added"`
+}
+
+// Validate checks that the rule has a target or a selector of 4 bytes
+func (r TxBlacklistRule) Validate() error {
+	if r.To == nil && len(r.Selector) == 0 {
+		return errors.New("the rule has neither a target address nor a selector")
+	}
+	if len(r.Selector) != 0 && len(r.Selector) != 4 {
+		return fmt.Errorf("invalid selector %s, a selector has 4 bytes", r.Selector)
+	}
+	return nil
+}
+
+func (r TxBlacklistRule) expired(now time.Time) bool {
+	return r.Expiry != nil && !now.Before(*r.Expiry)
+}
+
+// key identifies the rule by its target and its selector
+func (r TxBlacklistRule) key() string {
+	var to common.Address
+	if r.To != nil {
+		to = *r.To
+	}
+	return fmt.Sprintf("%t%s%s", r.To != nil, to.Hex(), r.Selector)
+}
+
+// matches tells if the call of the address with the calldata is refused, the contract creations are never
+func (r TxBlacklistRule) matches(to *common.Address, data []byte) bool {
+	if to == nil {
+		return false
+	}
+	if r.To != nil && *r.To != *to {
+		return false
+	}
+	return len(r.Selector) == 0 || bytes.HasPrefix(data, r.Selector)
+}
+
+// txBlacklist is the node local blacklist of the evm txs, saved to the blacklist file if there is one
+type txBlacklist struct {
+	mtx   sync.RWMutex
+	rules map[string]TxBlacklistRule
+	path  string
+	now   func() time.Time
+}
+
+var txBlacklistRules = &txBlacklist{rules: make(map[string]TxBlacklistRule), now: time.Now}
+
+// InitTxBlacklist loads the blacklist of the evm txs from the blacklist file of the node config, which is created
+// by the first rule added without it. The blacklist is node local: it only applies to the admission of the txs to
+// the mempool, the txs of the blocks are executed whatever it holds.
+func InitTxBlacklist() {
+	path := viper.GetString(FlagTxBlacklistFile)
+	if err := txBlacklistRules.load(path); err != nil {
+		panic(fmt.Errorf("failed to load the tx blacklist %s: %w", path, err))
+	}
+}
+
+func (b *txBlacklist) load(path string) error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.path = path
+	b.rules = make(map[string]TxBlacklistRule)
+	if path == "" {
+		return nil
+	}
+
+	bz, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var rules []TxBlacklistRule
+	if err := json.Unmarshal(bz, &rules); err != nil {
+		return err
+	}
+	now := b.now()
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+		if !rule.expired(now) {
+			b.rules[rule.key()] = rule
+		}
+	}
+	return nil
+}
+
+// save writes the rules to the blacklist file, the lock must be held
+func (b *txBlacklist) save() error {
+	if b.path == "" {
+		return nil
+	}
+	bz, err := json.MarshalIndent(b.list(), "", "  ")
+	if err != nil {
+		return err
+	}
+	// the file is replaced at once, so that a crash never leaves it half written
+	tmp := b.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, bz, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, b.path)
+}
+
+// list returns the rules which haven't expired in the order of their key, the lock must be held
+func (b *txBlacklist) list() []TxBlacklistRule {
+	now := b.now()
+	rules := make([]TxBlacklistRule, 0, len(b.rules))
+	for _, rule := range b.rules {
+		if !rule.expired(now) {
+			rules = append(rules, rule)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].key() < rules[j].key() })
+	return rules
+}
+
+func (b *txBlacklist) add(rule TxBlacklistRule) (TxBlacklistRule, error) {
+	if err := rule.Validate(); err != nil {
+		return rule, err
+	}
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	now := b.now()
+	if rule.expired(now) {
+		return rule, errors.New("the rule has already expired")
+	}
+	for key, r := range b.rules {
+		if r.expired(now) {
+			delete(b.rules, key)
+		}
+	}
+	if _, ok := b.rules[rule.key()]; !ok && len(b.rules) >= maxTxBlacklistRules {
+		return rule, fmt.Errorf("the blacklist has the max of %d rules", maxTxBlacklistRules)
+	}
+
+	rule.Added = now
+	prev, replaced := b.rules[rule.key()]
+	b.rules[rule.key()] = rule
+	if err := b.save(); err != nil {
+		if replaced {
+			b.rules[rule.key()] = prev
+		} else {
+			delete(b.rules, rule.key())
+		}
+		return rule, err
+	}
+	return rule, nil
+}
+
+func (b *txBlacklist) remove(to *common.Address, selector hexutil.Bytes) (bool, error) {
+	key := TxBlacklistRule{To: to, Selector: selector}.key()
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	prev, ok := b.rules[key]
+	if !ok {
+		return false, nil
+	}
+	delete(b.rules, key)
+	if err := b.save(); err != nil {
+		b.rules[key] = prev
+		return false, err
+	}
+	return true, nil
+}
+
+func (b *txBlacklist) match(to *common.Address, data []byte) (TxBlacklistRule, bool) {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
+	if len(b.rules) == 0 {
+		return TxBlacklistRule{}, false
+	}
+	now := b.now()
+	for _, rule := range b.rules {
+		if !rule.expired(now) && rule.matches(to, data) {
+			return rule, true
+		}
+	}
+	return TxBlacklistRule{}, false
+}
+
+// AddTxBlacklistRule adds the rule to the blacklist of the evm txs, replacing the one with the same target and
+// selector, and saves the blacklist. It returns the rule added.
+func AddTxBlacklistRule(rule TxBlacklistRule) (TxBlacklistRule, error) {
+	return txBlacklistRules.add(rule)
+}
+
+// RemoveTxBlacklistRule removes the rule of the target and the selector from the blacklist of the evm txs and
+// saves the blacklist. It returns false if there is no such rule.
+func RemoveTxBlacklistRule(to *common.Address, selector hexutil.Bytes) (bool, error) {
+	return txBlacklistRules.remove(to, selector)
+}
+
+// GetTxBlacklist returns the rules of the blacklist of the evm txs which haven't expired
+func GetTxBlacklist() []TxBlacklistRule {
+	txBlacklistRules.mtx.RLock()
+	defer txBlacklistRules.mtx.RUnlock()
+	return txBlacklistRules.list()
+}
+
+// MatchTxBlacklist returns the rule refusing the call of the address with the calldata, if any. The address is
+// nil for the contract creations.
+func MatchTxBlacklist(to *common.Address, data []byte) (TxBlacklistRule, bool) {
+	return txBlacklistRules.match(to, data)
+}
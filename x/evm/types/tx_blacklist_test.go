@@ -0,0 +1,110 @@
+package types
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxBlacklistRuleValidate(t *testing.T) {
+	to := common.HexToAddress("0x1")
+	testCases := []struct {
+		rule     TxBlacklistRule
+		expError bool
+	}{
+		{TxBlacklistRule{To: &to}, false},
+		{TxBlacklistRule{Selector: hexutil.Bytes{1, 2, 3, 4}}, false},
+		{TxBlacklistRule{To: &to, Selector: hexutil.Bytes{1, 2, 3, 4}}, false},
+		{TxBlacklistRule{}, true},
+		{TxBlacklistRule{To: &to, Selector: hexutil.Bytes{1, 2, 3}}, true},
+	}
+
+	for i, tc := range testCases {
+		err := tc.rule.Validate()
+		if tc.expError {
+			require.Error(t, err, i)
+		} else {
+			require.NoError(t, err, i)
+		}
+	}
+}
+
+func TestTxBlacklistMatch(t *testing.T) {
+	now := time.Now()
+	blacklist := &txBlacklist{rules: make(map[string]TxBlacklistRule), now: func() time.Time { return now }}
+	target, other := common.HexToAddress("0x1"), common.HexToAddress("0x2")
+	selector := hexutil.Bytes{0xa9, 0x05, 0x9c, 0xbb}
+
+	_, err := blacklist.add(TxBlacklistRule{To: &target, Reason: "exploited"})
+	require.NoError(t, err)
+	expiry := now.Add(time.Hour)
+	_, err = blacklist.add(TxBlacklistRule{Selector: selector, Expiry: &expiry})
+	require.NoError(t, err)
+
+	rule, ok := blacklist.match(&target, nil)
+	require.True(t, ok)
+	require.Equal(t, "exploited", rule.Reason)
+	_, ok = blacklist.match(&other, append(selector, 0xff))
+	require.True(t, ok)
+	_, ok = blacklist.match(&other, []byte{0xa9, 0x05})
+	require.False(t, ok)
+	// the contract creations are never refused
+	_, ok = blacklist.match(nil, selector)
+	require.False(t, ok)
+
+	// the expired rules neither match nor are listed
+	now = now.Add(2 * time.Hour)
+	_, ok = blacklist.match(&other, selector)
+	require.False(t, ok)
+	require.Len(t, blacklist.list(), 1)
+	_, err = blacklist.add(TxBlacklistRule{Selector: selector, Expiry: &expiry})
+	require.Error(t, err)
+
+	removed, err := blacklist.remove(&target, nil)
+	require.NoError(t, err)
+	require.True(t, removed)
+	_, ok = blacklist.match(&target, nil)
+	require.False(t, ok)
+	removed, err = blacklist.remove(&target, nil)
+	require.NoError(t, err)
+	require.False(t, removed)
+}
+
+func TestTxBlacklistPersistence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tx_blacklist")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "blacklist.json")
+
+	blacklist := &txBlacklist{now: time.Now}
+	require.NoError(t, blacklist.load(path))
+	to := common.HexToAddress("0x1")
+	_, err = blacklist.add(TxBlacklistRule{To: &to, Reason: "first"})
+	require.NoError(t, err)
+	// the rule with the same target and selector is replaced
+	_, err = blacklist.add(TxBlacklistRule{To: &to, Reason: "second"})
+	require.NoError(t, err)
+	_, err = blacklist.add(TxBlacklistRule{To: &to, Selector: hexutil.Bytes{1, 2, 3, 4}})
+	require.NoError(t, err)
+
+	reloaded := &txBlacklist{now: time.Now}
+	require.NoError(t, reloaded.load(path))
+	rules := reloaded.list()
+	require.Len(t, rules, 2)
+	for i, rule := range blacklist.list() {
+		require.Equal(t, rule.key(), rules[i].key())
+		require.True(t, rule.Added.Equal(rules[i].Added))
+	}
+	rule, ok := reloaded.match(&to, nil)
+	require.True(t, ok)
+	require.Equal(t, "second", rule.Reason)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte(`[{"selector":"0x01"}]`), 0600))
+	require.Error(t, reloaded.load(path))
+}
@@ -0,0 +1,55 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UpgradeSnapshot is the evm params and chain config saved before the migrations of an upgrade are run, so that
+// they can be restored by a later upgrade if the migrations have to be reverted
+type UpgradeSnapshot struct {
+	Upgrade     string      `json:"upgrade" yaml:"upgrade"`
+	Height      int64       `json:"height" yaml:"height"`
+	Params      Params      `json:"params" yaml:"params"`
+	ChainConfig ChainConfig `json:"chain_config" yaml:"chain_config"`
+}
+
+// String implements the fmt.Stringer interface
+func (s UpgradeSnapshot) String() string {
+	return fmt.Sprintf(`Upgrade Snapshot:
+  Upgrade: %s
+  Height:  %d
+%s
+%s`, s.Upgrade, s.Height, s.Params, s.ChainConfig)
+}
+
+// MigrationReport is the outcome of a dry run of the migrations of an upgrade. Error is set if a migration
+// failed, the params and chain config after the upgrade are then the ones left by the previous migrations.
+type MigrationReport struct {
+	Upgrade           string      `json:"upgrade" yaml:"upgrade"`
+	Migrations        []string    `json:"migrations" yaml:"migrations"`
+	Error             string      `json:"error,omitempty" yaml:"error,omitempty"`
+	ParamsBefore      Params      `json:"params_before" yaml:"params_before"`
+	ParamsAfter       Params      `json:"params_after" yaml:"params_after"`
+	ChainConfigBefore ChainConfig `json:"chain_config_before" yaml:"chain_config_before"`
+	ChainConfigAfter  ChainConfig `json:"chain_config_after" yaml:"chain_config_after"`
+}
+
+// String implements the fmt.Stringer interface
+func (r MigrationReport) String() string {
+	result := "succeeded"
+	if r.Error != "" {
+		result = "failed: " + r.Error
+	}
+	return fmt.Sprintf(`Migration Report:
+  Upgrade:    %s
+  Migrations: %s
+  Result:     %s
+Before:
+%s
+%s
+After:
+%s
+%s`, r.Upgrade, strings.Join(r.Migrations, ", "), result,
+		r.ParamsBefore, r.ChainConfigBefore, r.ParamsAfter, r.ChainConfigAfter)
+}
@@ -532,7 +532,7 @@ func DecodeResultData(in []byte) (ResultData, error) {
 // TxDecoder returns an sdk.TxDecoder that can decode both auth.StdTx and
 // MsgEthereumTx transactions.
 func TxDecoder(cdc *codec.Codec) sdk.TxDecoder {
-	return func(txBytes []byte) (sdk.Tx, error) {
+	return func(txBytes []byte, height ...int64) (sdk.Tx, error) {
 		var tx sdk.Tx
 
 		if len(txBytes) == 0 {
@@ -546,6 +546,12 @@ func TxDecoder(cdc *codec.Codec) sdk.TxDecoder {
 		if err != nil {
 			err := cdc.UnmarshalBinaryLengthPrefixed(txBytes, &tx)
 			if err != nil {
+				// the txs in the protobuf encoding
+				if protoTxEnabled(height...) {
+					if protoTx, protoErr := DecodeProtoTx(txBytes); protoErr == nil {
+						return protoTx, nil
+					}
+				}
 				return nil, sdkerrors.Wrap(sdkerrors.ErrTxDecode, err.Error())
 			}
 		} else {
@@ -556,6 +562,16 @@ func TxDecoder(cdc *codec.Codec) sdk.TxDecoder {
 	}
 }
 
+// protoTxEnabled returns true if the txs in the protobuf encoding are decoded at the height. They are only decoded
+// past the mercury milestone, the blocks below it replay with the amino txs only. Without the height, they are
+// decoded once the milestone is enabled.
+func protoTxEnabled(height ...int64) bool {
+	if len(height) == 0 {
+		return sdk.GetMilestoneMercuryHeight() != 0
+	}
+	return sdk.HigherThanMercury(height[0])
+}
+
 // recoverEthSig recovers a signature according to the Ethereum specification and
 // returns the sender or an error.
 //
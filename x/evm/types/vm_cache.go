@@ -0,0 +1,41 @@
+package types
+
+import (
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/spf13/viper"
+)
+
+const (
+	FlagJumpDestCacheSize   = "evm-jumpdest-cache-size"
+	FlagPrecompileCacheSize = "evm-precompile-cache-size"
+
+	DefaultJumpDestCacheSize   = 4096
+	DefaultPrecompileCacheSize = 4096
+)
+
+var (
+	// jumpDestCache keeps the JUMPDEST analysis of the contracts across the txs and the blocks, the analysis
+	// only depends on the code
+	jumpDestCache *vm.JumpDestCache
+	// precompileCache keeps the outputs of the pure precompiled contracts within a block
+	precompileCache *vm.PrecompileCache
+)
+
+// InitVMCaches creates the caches of the evm interpreter with their sizes from the node config, a cache of
+// size 0 is disabled
+func InitVMCaches() {
+	jumpDestCache, precompileCache = nil, nil
+	if size := viper.GetInt(FlagJumpDestCacheSize); size > 0 {
+		jumpDestCache, _ = vm.NewJumpDestCache(size)
+	}
+	if size := viper.GetInt(FlagPrecompileCacheSize); size > 0 {
+		precompileCache, _ = vm.NewPrecompileCache(size)
+	}
+}
+
+// PurgePrecompileCache drops the outputs of the precompiled contracts cached by the previous block
+func PurgePrecompileCache() {
+	if precompileCache != nil {
+		precompileCache.Purge()
+	}
+}
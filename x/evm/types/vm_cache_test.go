@@ -0,0 +1,48 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+// ecrecoverContract jumps over a STOP, then returns the output of ecrecover for the calldata
+var ecrecoverContract = common.FromHex("60806000600037600b56005b602060006080600060015afa5060206000f3")
+
+func TestVMCaches(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	hash := crypto.Keccak256([]byte("message"))
+	sig, err := crypto.Sign(hash, key)
+	require.NoError(t, err)
+
+	input := append(append(append(hash, common.LeftPadBytes([]byte{sig[64] + 27}, 32)...), sig[:32]...), sig[32:64]...)
+	expected := common.LeftPadBytes(crypto.PubkeyToAddress(key.PublicKey).Bytes(), 32)
+
+	defer func() {
+		viper.Reset()
+		InitVMCaches()
+	}()
+	for _, size := range []int{0, DefaultPrecompileCacheSize} {
+		viper.Set(FlagJumpDestCacheSize, size)
+		viper.Set(FlagPrecompileCacheSize, size)
+		InitVMCaches()
+		require.Equal(t, size == 0, jumpDestCache == nil)
+		require.Equal(t, size == 0, precompileCache == nil)
+
+		// the second execution reads the analysis and the ecrecover output from the caches
+		for i := 0; i < 2; i++ {
+			ret, _, err := runtime.Execute(ecrecoverContract, input, &runtime.Config{
+				EVMConfig: vm.Config{JumpDestCache: jumpDestCache, PrecompileCache: precompileCache},
+			})
+			require.NoError(t, err)
+			require.Equal(t, expected, ret)
+		}
+		PurgePrecompileCache()
+	}
+}
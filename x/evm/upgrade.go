@@ -0,0 +1,20 @@
+package evm
+
+import (
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	"github.com/okex/exchain/libs/cosmos-sdk/x/upgrade"
+)
+
+// RegisterUpgradeHandlers sets an upgrade handler running the evm migrations of each upgrade registered to the
+// keeper, so that they are run at the height of the upgrade plan. As with a missing upgrade handler, the chain
+// halts if a migration fails.
+func RegisterUpgradeHandlers(uk upgrade.Keeper, k *Keeper) {
+	for _, name := range k.GetMigrationUpgrades() {
+		name := name
+		uk.SetUpgradeHandler(name, func(ctx sdk.Context, _ upgrade.Plan) {
+			if err := k.RunMigrations(ctx, name); err != nil {
+				panic(err)
+			}
+		})
+	}
+}
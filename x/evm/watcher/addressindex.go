@@ -0,0 +1,144 @@
+package watcher
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	dbm "github.com/tendermint/tm-db"
+
+	rpctypes "github.com/okex/exchain/app/rpc/types"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	"github.com/okex/exchain/x/evm/types"
+)
+
+// The txs of an address are indexed by (address, height, tx index), so that they are listed from the latest
+// one. The address of a tx is its sender, its recipient, the contract it creates, and the recipients of its
+// internal calls if the tx is traced with the stack, see types.GetTraceCallRecipients.
+
+const (
+	// addressTxCursorLen is the length of the (height, tx index) suffix of the index keys
+	addressTxCursorLen = 8 + 4
+
+	DefaultAddressTxsLimit = 100
+	MaxAddressTxsLimit     = 1000
+)
+
+// MsgAddressTx indexes a tx under one of its addresses
+type MsgAddressTx struct {
+	addr    common.Address
+	height  uint64
+	txIndex uint64
+	txHash  common.Hash
+}
+
+func NewMsgAddressTx(addr common.Address, height, txIndex uint64, txHash common.Hash) *MsgAddressTx {
+	return &MsgAddressTx{addr: addr, height: height, txIndex: txIndex, txHash: txHash}
+}
+
+func (m MsgAddressTx) GetType() uint32 {
+	return TypeOthers
+}
+
+func (m MsgAddressTx) GetKey() []byte {
+	return append(addressTxPrefix(m.addr), addressTxCursor(m.height, m.txIndex)...)
+}
+
+func (m MsgAddressTx) GetValue() string {
+	return m.txHash.Hex()
+}
+
+func addressTxPrefix(addr common.Address) []byte {
+	return append(append([]byte{}, prefixAddressTx...), addr.Bytes()...)
+}
+
+func addressTxCursor(height, txIndex uint64) []byte {
+	cursor := make([]byte, addressTxCursorLen)
+	binary.BigEndian.PutUint64(cursor, height)
+	binary.BigEndian.PutUint32(cursor[8:], uint32(txIndex))
+	return cursor
+}
+
+// newMsgAddressTxs indexes the tx under each of its addresses
+func newMsgAddressTxs(msg *types.MsgEthereumTx, txHash common.Hash, height, txIndex uint64, data *types.ResultData) []WatchMessage {
+	addrs := []common.Address{common.BytesToAddress(msg.From().Bytes())}
+	if msg.Data.Recipient != nil {
+		addrs = append(addrs, *msg.Data.Recipient)
+	}
+	if data != nil && data.ContractAddress != (common.Address{}) {
+		addrs = append(addrs, data.ContractAddress)
+	}
+	addrs = append(addrs, types.GetTraceCallRecipients(txHash.Bytes())...)
+
+	msgs := make([]WatchMessage, 0, len(addrs))
+	seen := make(map[common.Address]bool, len(addrs))
+	for _, addr := range addrs {
+		if seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		msgs = append(msgs, NewMsgAddressTx(addr, height, txIndex, txHash))
+	}
+	return msgs
+}
+
+// AddressTxs is a page of the txs of an address, from the latest one
+type AddressTxs struct {
+	Transactions []*rpctypes.Transaction `json:"transactions"`
+	// NextCursor is the cursor of the next page, nil on the last page
+	NextCursor *hexutil.Bytes `json:"nextCursor"`
+}
+
+// GetTransactionsByAddress returns the txs of the address before the cursor, which is the NextCursor of the
+// previous page, or nil for the first page.
+func (q Querier) GetTransactionsByAddress(addr common.Address, cursor []byte, limit int) (*AddressTxs, error) {
+	if !q.enabled() {
+		return nil, errors.New(MsgFunctionDisable)
+	}
+	if cursor != nil && len(cursor) != addressTxCursorLen {
+		return nil, errors.New("invalid cursor")
+	}
+	if limit <= 0 || limit > MaxAddressTxsLimit {
+		return nil, errors.New("invalid limit")
+	}
+
+	prefix := addressTxPrefix(addr)
+	end := sdk.PrefixEndBytes(prefix)
+	if cursor != nil {
+		end = append(prefix, cursor...)
+	}
+	it, err := q.store.db.ReverseIterator(prefix, end)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	result := &AddressTxs{Transactions: []*rpctypes.Transaction{}}
+	var lastCursor []byte
+	for ; it.Valid(); it.Next() {
+		if len(result.Transactions) == limit {
+			next := hexutil.Bytes(lastCursor)
+			result.NextCursor = &next
+			break
+		}
+		tx, err := q.GetTransactionByHash(common.HexToHash(string(it.Value())))
+		if err != nil {
+			return nil, err
+		}
+		result.Transactions = append(result.Transactions, tx)
+		lastCursor = append([]byte{}, it.Key()[len(prefix):]...)
+	}
+	return result, nil
+}
+
+// rollbackAddressTxs removes the txs indexed above the height
+func rollbackAddressTxs(db dbm.DB, batch dbm.Batch, height uint64) error {
+	return iteratePrefix(db, prefixAddressTx, func(key, _ []byte) error {
+		cursor := key[len(key)-addressTxCursorLen:]
+		if binary.BigEndian.Uint64(cursor) > height {
+			batch.Delete(key)
+		}
+		return nil
+	})
+}
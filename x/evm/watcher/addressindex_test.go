@@ -0,0 +1,83 @@
+package watcher
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	rpctypes "github.com/okex/exchain/app/rpc/types"
+	"github.com/okex/exchain/x/evm/types"
+)
+
+func TestGetTransactionsByAddress(t *testing.T) {
+	db := dbm.NewMemDB()
+	q := Querier{store: &WatchStore{db: db}, sw: true}
+	to := common.HexToAddress("0x02")
+	contract := common.HexToAddress("0x03")
+	call := types.NewMsgEthereumTx(0, &to, big.NewInt(0), 21000, big.NewInt(1), nil)
+	create := types.NewMsgEthereumTx(0, nil, big.NewInt(0), 21000, big.NewInt(1), nil)
+
+	// two txs calling the recipient per block, the second block also creates the contract
+	var txHashes []common.Hash
+	for height := uint64(1); height <= 2; height++ {
+		for txIndex := uint64(0); txIndex < 2; txIndex++ {
+			txHash := common.BigToHash(new(big.Int).SetUint64(height*10 + txIndex))
+			bz, err := json.Marshal(rpctypes.Transaction{Hash: txHash})
+			require.NoError(t, err)
+			require.NoError(t, db.Set(append(prefixTx, txHash.Bytes()...), bz))
+			for _, msg := range newMsgAddressTxs(&call, txHash, height, txIndex, &types.ResultData{}) {
+				require.NoError(t, db.Set(msg.GetKey(), []byte(msg.GetValue())))
+			}
+			txHashes = append(txHashes, txHash)
+		}
+	}
+	createHash := common.HexToHash("0xc0")
+	bz, err := json.Marshal(rpctypes.Transaction{Hash: createHash})
+	require.NoError(t, err)
+	require.NoError(t, db.Set(append(prefixTx, createHash.Bytes()...), bz))
+	msgs := newMsgAddressTxs(&create, createHash, 2, 2, &types.ResultData{ContractAddress: contract})
+	// the sender and the created contract
+	require.Len(t, msgs, 2)
+	for _, msg := range msgs {
+		require.NoError(t, db.Set(msg.GetKey(), []byte(msg.GetValue())))
+	}
+
+	// the txs are listed from the latest one, page by page
+	page, err := q.GetTransactionsByAddress(to, nil, 3)
+	require.NoError(t, err)
+	require.Len(t, page.Transactions, 3)
+	require.Equal(t, txHashes[3], page.Transactions[0].Hash)
+	require.Equal(t, txHashes[1], page.Transactions[2].Hash)
+	require.NotNil(t, page.NextCursor)
+	page, err = q.GetTransactionsByAddress(to, *page.NextCursor, 3)
+	require.NoError(t, err)
+	require.Len(t, page.Transactions, 1)
+	require.Equal(t, txHashes[0], page.Transactions[0].Hash)
+	require.Nil(t, page.NextCursor)
+
+	page, err = q.GetTransactionsByAddress(contract, nil, DefaultAddressTxsLimit)
+	require.NoError(t, err)
+	require.Len(t, page.Transactions, 1)
+	require.Equal(t, createHash, page.Transactions[0].Hash)
+
+	_, err = q.GetTransactionsByAddress(to, []byte{0x01}, 3)
+	require.Error(t, err)
+	_, err = q.GetTransactionsByAddress(to, nil, MaxAddressTxsLimit+1)
+	require.Error(t, err)
+
+	// the txs indexed above the height are rolled back
+	require.NoError(t, db.Set(NewMsgLatestHeight(2).GetKey(), []byte(NewMsgLatestHeight(2).GetValue())))
+	_, err = Rollback(db, 1)
+	require.NoError(t, err)
+	page, err = q.GetTransactionsByAddress(to, nil, DefaultAddressTxsLimit)
+	require.NoError(t, err)
+	require.Len(t, page.Transactions, 2)
+	require.Equal(t, txHashes[1], page.Transactions[0].Hash)
+	page, err = q.GetTransactionsByAddress(contract, nil, DefaultAddressTxsLimit)
+	require.NoError(t, err)
+	require.Empty(t, page.Transactions)
+}
@@ -0,0 +1,154 @@
+package watcher
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/viper"
+	dbm "github.com/tendermint/tm-db"
+
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+)
+
+// The native balances of the addresses are indexed by (address, height) at the end of each block where their
+// account is updated, so that the history of a balance is listed from the latest change without replaying the
+// blocks. The delta of a change is the difference with the previous indexed balance. The index is opt-in, see
+// FlagBalanceHistory.
+
+const (
+	FlagBalanceHistory = "fast-query-balance-history"
+
+	DefaultBalanceHistoryLimit = 100
+	MaxBalanceHistoryLimit     = 1000
+)
+
+var (
+	balanceHistoryEnable     = false
+	onceBalanceHistoryEnable sync.Once
+)
+
+// IsBalanceHistoryEnabled tells if the balance history is indexed, along with the fast query mode
+func IsBalanceHistoryEnabled() bool {
+	onceBalanceHistoryEnable.Do(func() {
+		balanceHistoryEnable = IsWatcherEnabled() && viper.GetBool(FlagBalanceHistory)
+	})
+	return balanceHistoryEnable
+}
+
+// MsgBalance is the native balance of an address at the end of the block of the height, in wei
+type MsgBalance struct {
+	addr    common.Address
+	height  uint64
+	balance *big.Int
+}
+
+func NewMsgBalance(addr common.Address, height uint64, balance *big.Int) *MsgBalance {
+	return &MsgBalance{addr: addr, height: height, balance: balance}
+}
+
+func (m MsgBalance) GetType() uint32 {
+	return TypeOthers
+}
+
+func (m MsgBalance) GetKey() []byte {
+	return append(balanceHistoryPrefix(m.addr), sdk.Uint64ToBigEndian(m.height)...)
+}
+
+func (m MsgBalance) GetValue() string {
+	return m.balance.String()
+}
+
+func balanceHistoryPrefix(addr common.Address) []byte {
+	return append(append([]byte{}, prefixBalanceHistory...), addr.Bytes()...)
+}
+
+// BalanceChange is the native balance of an address after a block changing it. The delta is nil for the oldest
+// indexed balance, as the balance before it is unknown.
+type BalanceChange struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	Balance     *hexutil.Big   `json:"balance"`
+	Delta       *hexutil.Big   `json:"delta"`
+}
+
+// BalanceHistory is a page of the balance changes of an address, from the latest one
+type BalanceHistory struct {
+	Changes []BalanceChange `json:"changes"`
+	// NextCursor is the cursor of the next page, nil on the last page
+	NextCursor *hexutil.Bytes `json:"nextCursor"`
+}
+
+// GetBalanceHistory returns the balance changes of the address before the cursor, which is the NextCursor of
+// the previous page, or nil for the first page. The blocks updating the account without changing its balance,
+// e.g. a transfer of another token, are skipped.
+func (q Querier) GetBalanceHistory(addr common.Address, cursor []byte, limit int) (*BalanceHistory, error) {
+	if !q.enabled() || !IsBalanceHistoryEnabled() {
+		return nil, errors.New(MsgFunctionDisable)
+	}
+	if cursor != nil && len(cursor) != 8 {
+		return nil, errors.New("invalid cursor")
+	}
+	if limit <= 0 || limit > MaxBalanceHistoryLimit {
+		return nil, errors.New("invalid limit")
+	}
+
+	prefix := balanceHistoryPrefix(addr)
+	end := sdk.PrefixEndBytes(prefix)
+	if cursor != nil {
+		end = append(prefix, cursor...)
+	}
+	it, err := q.store.db.ReverseIterator(prefix, end)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	result := &BalanceHistory{Changes: []BalanceChange{}}
+	// the delta of a balance is known once the previous one is read
+	var pending *BalanceChange
+	var lastCursor []byte
+	for ; it.Valid(); it.Next() {
+		balance, ok := new(big.Int).SetString(string(it.Value()), 10)
+		if !ok {
+			return nil, errors.New("invalid indexed balance")
+		}
+		if pending != nil {
+			delta := new(big.Int).Sub(pending.Balance.ToInt(), balance)
+			if delta.Sign() != 0 {
+				if len(result.Changes) == limit {
+					next := hexutil.Bytes(lastCursor)
+					result.NextCursor = &next
+					return result, nil
+				}
+				pending.Delta = (*hexutil.Big)(delta)
+				result.Changes = append(result.Changes, *pending)
+				lastCursor = sdk.Uint64ToBigEndian(uint64(pending.BlockNumber))
+			}
+		}
+		height := binary.BigEndian.Uint64(it.Key()[len(prefix):])
+		pending = &BalanceChange{BlockNumber: hexutil.Uint64(height), Balance: (*hexutil.Big)(balance)}
+	}
+
+	if pending != nil {
+		if len(result.Changes) == limit {
+			next := hexutil.Bytes(lastCursor)
+			result.NextCursor = &next
+		} else {
+			result.Changes = append(result.Changes, *pending)
+		}
+	}
+	return result, nil
+}
+
+// rollbackBalanceHistory removes the balances indexed above the height
+func rollbackBalanceHistory(db dbm.DB, batch dbm.Batch, height uint64) error {
+	return iteratePrefix(db, prefixBalanceHistory, func(key, _ []byte) error {
+		if binary.BigEndian.Uint64(key[len(key)-8:]) > height {
+			batch.Delete(key)
+		}
+		return nil
+	})
+}
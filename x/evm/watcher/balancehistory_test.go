@@ -0,0 +1,110 @@
+package watcher
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+)
+
+func enableBalanceHistory(t *testing.T) {
+	onceBalanceHistoryEnable.Do(func() {})
+	balanceHistoryEnable = true
+	t.Cleanup(func() { balanceHistoryEnable = false })
+}
+
+func TestGetBalanceHistory(t *testing.T) {
+	db := dbm.NewMemDB()
+	q := Querier{store: &WatchStore{db: db}, sw: true}
+	addr := common.HexToAddress("0x01")
+
+	_, err := q.GetBalanceHistory(addr, nil, DefaultBalanceHistoryLimit)
+	require.Error(t, err, "the balance history is disabled")
+	enableBalanceHistory(t)
+
+	// the account is updated without a balance change at the height 3
+	for height, balance := range map[uint64]int64{1: 100, 2: 70, 3: 70, 4: 90, 6: 0} {
+		msg := NewMsgBalance(addr, height, big.NewInt(balance))
+		require.NoError(t, db.Set(msg.GetKey(), []byte(msg.GetValue())))
+	}
+	other := NewMsgBalance(common.HexToAddress("0x02"), 5, big.NewInt(1))
+	require.NoError(t, db.Set(other.GetKey(), []byte(other.GetValue())))
+
+	type change struct {
+		height         uint64
+		balance, delta int64
+	}
+	requireChanges := func(expected []change, history *BalanceHistory) {
+		require.Len(t, history.Changes, len(expected))
+		for i, c := range expected {
+			require.Equal(t, c.height, uint64(history.Changes[i].BlockNumber))
+			require.Equal(t, c.balance, history.Changes[i].Balance.ToInt().Int64())
+			require.Equal(t, c.delta, history.Changes[i].Delta.ToInt().Int64())
+		}
+	}
+
+	// the changes are listed from the latest one, page by page
+	page, err := q.GetBalanceHistory(addr, nil, 2)
+	require.NoError(t, err)
+	requireChanges([]change{{6, 0, -90}, {4, 90, 20}}, page)
+	require.NotNil(t, page.NextCursor)
+	page, err = q.GetBalanceHistory(addr, *page.NextCursor, 2)
+	require.NoError(t, err)
+	// the oldest indexed balance has no delta
+	require.Len(t, page.Changes, 2)
+	requireChanges([]change{{2, 70, -30}}, &BalanceHistory{Changes: page.Changes[:1]})
+	require.Equal(t, uint64(1), uint64(page.Changes[1].BlockNumber))
+	require.Nil(t, page.Changes[1].Delta)
+	require.Nil(t, page.NextCursor)
+
+	page, err = q.GetBalanceHistory(addr, nil, 3)
+	require.NoError(t, err)
+	require.Len(t, page.Changes, 3)
+	require.NotNil(t, page.NextCursor)
+	page, err = q.GetBalanceHistory(addr, *page.NextCursor, 3)
+	require.NoError(t, err)
+	require.Len(t, page.Changes, 1)
+	require.Nil(t, page.NextCursor)
+
+	page, err = q.GetBalanceHistory(common.HexToAddress("0x03"), nil, DefaultBalanceHistoryLimit)
+	require.NoError(t, err)
+	require.Empty(t, page.Changes)
+
+	_, err = q.GetBalanceHistory(addr, []byte{0x01}, 2)
+	require.Error(t, err)
+	_, err = q.GetBalanceHistory(addr, nil, MaxBalanceHistoryLimit+1)
+	require.Error(t, err)
+
+	// the balances indexed above the height are rolled back
+	require.NoError(t, db.Set(NewMsgLatestHeight(6).GetKey(), []byte(NewMsgLatestHeight(6).GetValue())))
+	_, err = Rollback(db, 3)
+	require.NoError(t, err)
+	page, err = q.GetBalanceHistory(addr, nil, DefaultBalanceHistoryLimit)
+	require.NoError(t, err)
+	require.Len(t, page.Changes, 2)
+	require.Equal(t, uint64(2), uint64(page.Changes[0].BlockNumber))
+}
+
+func TestSaveBalance(t *testing.T) {
+	w := &Watcher{sw: true}
+	w.NewHeight(7, common.Hash{}, w.header)
+	w.AddBalanceChange(common.HexToAddress("0x02").Bytes())
+	require.Empty(t, w.BalanceChangedAddrs(), "the balance history is disabled")
+	enableBalanceHistory(t)
+
+	w.AddBalanceChange(common.HexToAddress("0x02").Bytes())
+	w.AddBalanceChange(common.HexToAddress("0x01").Bytes())
+	w.AddBalanceChange(common.HexToAddress("0x02").Bytes())
+	require.Equal(t, []common.Address{common.HexToAddress("0x01"), common.HexToAddress("0x02")}, w.BalanceChangedAddrs())
+
+	w.SaveBalance(common.HexToAddress("0x01"), big.NewInt(42))
+	require.Len(t, w.batch, 1)
+	require.Equal(t, append(balanceHistoryPrefix(common.HexToAddress("0x01")), 0, 0, 0, 0, 0, 0, 0, 7), w.batch[0].GetKey())
+	require.Equal(t, "42", w.batch[0].GetValue())
+
+	// the changes are reset on a new height
+	w.NewHeight(8, common.Hash{}, w.header)
+	require.Empty(t, w.BalanceChangedAddrs())
+}
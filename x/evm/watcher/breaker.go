@@ -0,0 +1,162 @@
+package watcher
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/viper"
+
+	"github.com/okex/exchain/x/common/monitor"
+)
+
+const (
+	FlagBreakerThreshold = "fast-query-breaker-threshold"
+	FlagBreakerCooldown  = "fast-query-breaker-cooldown"
+)
+
+// DataType is a type of the data queried from the watcher, each type has its own circuit breaker
+type DataType string
+
+const (
+	DataBlock   DataType = "block"
+	DataTx      DataType = "tx"
+	DataReceipt DataType = "receipt"
+	DataAccount DataType = "account"
+	DataState   DataType = "state"
+	DataCode    DataType = "code"
+)
+
+// ErrCircuitOpen is returned by the queries of the data types whose breaker is open, the callers fall back to
+// the chain straight away
+var ErrCircuitOpen = errors.New("the fast query of the data is temporarily disabled")
+
+// When the watcher fails on a data type, or lags behind the chain so that the data found by the fallback is
+// missing from it, every query pays the cost of the watcher before falling back. After threshold failures in a
+// row the breaker of the type opens and the queries go straight to the fallback. Once the cooldown is over, a
+// query probes the watcher per cooldown: the breaker closes if it succeeds, and opens again if it fails.
+type breakers struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mtx   sync.Mutex
+	types map[DataType]*breaker
+}
+
+type breaker struct {
+	failures  int
+	openUntil time.Time // zero if the breaker is closed
+	probing   bool
+}
+
+var (
+	defaultBreakers     *breakers
+	defaultBreakersOnce sync.Once
+
+	breakerMetricsOnce sync.Once
+	breakerOpen        metrics.Gauge
+	breakerSkipped     metrics.Counter
+)
+
+// getBreakers returns the breakers shared by the queriers, nil if they are disabled
+func getBreakers() *breakers {
+	defaultBreakersOnce.Do(func() {
+		threshold := viper.GetInt(FlagBreakerThreshold)
+		if threshold > 0 {
+			defaultBreakers = newBreakers(threshold, viper.GetDuration(FlagBreakerCooldown))
+		}
+	})
+	return defaultBreakers
+}
+
+func newBreakers(threshold int, cooldown time.Duration) *breakers {
+	breakerMetricsOnce.Do(func() {
+		breakerOpen = prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: monitor.XNameSpace,
+			Subsystem: "rpc",
+			Name:      "watcher_breaker_open",
+			Help:      "1 if the fast queries of the data type go straight to the chain",
+		}, []string{"type"})
+		breakerSkipped = prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: monitor.XNameSpace,
+			Subsystem: "rpc",
+			Name:      "watcher_breaker_skipped",
+			Help:      "the number of fast queries sent straight to the chain by an open breaker",
+		}, []string{"type"})
+	})
+	return &breakers{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		types:     make(map[DataType]*breaker),
+	}
+}
+
+func (bs *breakers) get(t DataType) *breaker {
+	b, ok := bs.types[t]
+	if !ok {
+		b = &breaker{}
+		bs.types[t] = b
+	}
+	return b
+}
+
+// allow returns ErrCircuitOpen if the queries of the data type must skip the watcher
+func (bs *breakers) allow(t DataType) error {
+	if bs == nil {
+		return nil
+	}
+	bs.mtx.Lock()
+	defer bs.mtx.Unlock()
+	b := bs.get(t)
+	if b.openUntil.IsZero() {
+		return nil
+	}
+	now := bs.now()
+	if now.Before(b.openUntil) {
+		breakerSkipped.With("type", string(t)).Add(1)
+		return ErrCircuitOpen
+	}
+	// a single probe per cooldown, in case its result is never recorded
+	b.probing = true
+	b.openUntil = now.Add(bs.cooldown)
+	return nil
+}
+
+// record updates the breaker of the data type with the result of a query, the data not found is neither a
+// success nor a failure, as it may be missing from the chain as well, see ReportMiss
+func (bs *breakers) record(t DataType, err error) {
+	if bs == nil || err == ErrCircuitOpen || err == errNotFound {
+		return
+	}
+	bs.mtx.Lock()
+	defer bs.mtx.Unlock()
+	b := bs.get(t)
+	if err == nil {
+		if !b.openUntil.IsZero() {
+			breakerOpen.With("type", string(t)).Set(0)
+		}
+		*b = breaker{}
+		return
+	}
+
+	b.failures++
+	if b.probing || b.failures >= bs.threshold {
+		b.openUntil = bs.now().Add(bs.cooldown)
+		b.failures = 0
+		b.probing = false
+		breakerOpen.With("type", string(t)).Set(1)
+	}
+}
+
+// ReportMiss records that the data of the type queried with the error was missing from the watcher but found on
+// the chain, which happens while the watcher lags behind it
+func (q Querier) ReportMiss(t DataType, err error) {
+	if err == errNotFound {
+		q.breakers.record(t, errors.New("missing from the watcher"))
+	}
+}
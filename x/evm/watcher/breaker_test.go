@@ -0,0 +1,73 @@
+package watcher
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+)
+
+func TestBreakers(t *testing.T) {
+	now := time.Unix(0, 0)
+	bs := newBreakers(2, time.Minute)
+	bs.now = func() time.Time { return now }
+	errFailed := errors.New("failed")
+
+	// the data not found doesn't count, nor the failures separated by a success
+	bs.record(DataTx, errFailed)
+	bs.record(DataTx, errNotFound)
+	bs.record(DataTx, nil)
+	bs.record(DataTx, errFailed)
+	require.NoError(t, bs.allow(DataTx))
+
+	bs.record(DataTx, errFailed)
+	require.Equal(t, ErrCircuitOpen, bs.allow(DataTx))
+	// the breakers of the other types are independent
+	require.NoError(t, bs.allow(DataBlock))
+
+	// a single probe once the cooldown is over, its failure opens the breaker again
+	now = now.Add(time.Minute)
+	require.NoError(t, bs.allow(DataTx))
+	require.Equal(t, ErrCircuitOpen, bs.allow(DataTx))
+	bs.record(DataTx, errFailed)
+	now = now.Add(time.Minute - time.Second)
+	require.Equal(t, ErrCircuitOpen, bs.allow(DataTx))
+
+	// its success closes it
+	now = now.Add(time.Second)
+	require.NoError(t, bs.allow(DataTx))
+	bs.record(DataTx, nil)
+	require.NoError(t, bs.allow(DataTx))
+	require.NoError(t, bs.allow(DataTx))
+
+	var disabled *breakers
+	disabled.record(DataTx, errFailed)
+	require.NoError(t, disabled.allow(DataTx))
+}
+
+func TestQuerierBreaker(t *testing.T) {
+	bs := newBreakers(2, time.Minute)
+	q := Querier{store: &WatchStore{db: dbm.NewMemDB()}, sw: true, breakers: bs}
+	hash := common.HexToHash("0x01")
+
+	// the txs found by the fallback but missing from the lagging watcher trip the breaker
+	for i := 0; i < 2; i++ {
+		_, err := q.GetTransactionByHash(hash)
+		require.Equal(t, errNotFound, err)
+		q.ReportMiss(DataTx, err)
+	}
+	_, err := q.GetTransactionByHash(hash)
+	require.Equal(t, ErrCircuitOpen, err)
+	// which isn't a miss
+	q.ReportMiss(DataTx, err)
+	_, err = q.GetTransactionReceipt(hash)
+	require.Equal(t, errNotFound, err)
+
+	// the disabled watcher leaves the breakers alone
+	q.Enable(false)
+	_, err = q.GetTransactionByHash(hash)
+	require.EqualError(t, err, MsgFunctionDisable)
+}
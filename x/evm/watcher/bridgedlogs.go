@@ -0,0 +1,92 @@
+package watcher
+
+import (
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// The bridged logs are evm logs of the cosmos events of the txs, they aren't emitted by an evm tx. They are
+// saved per block, numbered after the logs of its evm txs, and served with them by eth_getLogs and the log
+// subscriptions. The bloom of the block served by the rpc has them, but
+// not the bloom of the evm store the bloombits index is built from, which is part of the consensus state.
+
+// MsgBridgedLogs is the logs bridged from the cosmos events of the txs of a block
+type MsgBridgedLogs struct {
+	blockHash []byte
+	logs      []*ethtypes.Log
+}
+
+func NewMsgBridgedLogs(blockHash common.Hash, logs []*ethtypes.Log) *MsgBridgedLogs {
+	return &MsgBridgedLogs{blockHash: blockHash.Bytes(), logs: logs}
+}
+
+func (m MsgBridgedLogs) GetType() uint32 {
+	return TypeOthers
+}
+
+func (m MsgBridgedLogs) GetKey() []byte {
+	return append(prefixBridgedLogs, m.blockHash...)
+}
+
+func (m MsgBridgedLogs) GetValue() string {
+	bz, err := json.Marshal(m.logs)
+	if err != nil {
+		return ""
+	}
+	return string(bz)
+}
+
+// SaveBridgedLogs saves the logs bridged from the events of the cosmos tx of the block, the txIndex is the index
+// of the tx in the block
+func (w *Watcher) SaveBridgedLogs(txHash common.Hash, txIndex uint64, logs []*ethtypes.Log) {
+	if !w.Enabled() {
+		return
+	}
+	for _, log := range logs {
+		log.BlockNumber = w.height
+		log.BlockHash = w.blockHash
+		log.TxHash = txHash
+		log.TxIndex = uint(txIndex)
+		w.bridgedLogs = append(w.bridgedLogs, log)
+	}
+}
+
+// addBridgedLogs adds the bridged logs of the block to the batch and to the bloom of the block
+func (w *Watcher) addBridgedLogs(bloom ethtypes.Bloom) ethtypes.Bloom {
+	if len(w.bridgedLogs) == 0 {
+		return bloom
+	}
+	for _, log := range w.bridgedLogs {
+		bloom.Add(log.Address.Bytes())
+		for _, topic := range log.Topics {
+			bloom.Add(topic.Bytes())
+		}
+	}
+	w.batch = append(w.batch, NewMsgBridgedLogs(w.blockHash, w.bridgedLogs))
+	return bloom
+}
+
+// bridgedLogs returns the bridged logs of the batch
+func bridgedLogs(batch []WatchMessage) []*ethtypes.Log {
+	for _, b := range batch {
+		if m, ok := b.(*MsgBridgedLogs); ok {
+			return m.logs
+		}
+	}
+	return nil
+}
+
+// GetBridgedLogs returns the bridged logs of the block, nil if it has none
+func (q Querier) GetBridgedLogs(blockHash common.Hash) ([]*ethtypes.Log, error) {
+	bz, err := q.store.Get(append(prefixBridgedLogs, blockHash.Bytes()...))
+	if err != nil || len(bz) == 0 {
+		return nil, err
+	}
+	var logs []*ethtypes.Log
+	if err := json.Unmarshal(bz, &logs); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
@@ -0,0 +1,54 @@
+package watcher
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/okex/exchain/x/evm/types"
+)
+
+func TestBridgedLogs(t *testing.T) {
+	to := common.HexToAddress("0x01")
+	tx := types.NewMsgEthereumTx(0, &to, big.NewInt(0), 21000, big.NewInt(1), nil)
+	blockHash := common.HexToHash("0xb0")
+	bridge := common.HexToAddress("0x01bc")
+	topic := common.HexToHash("0x7e")
+
+	// the bridged logs are numbered after the logs of the evm txs
+	receipt := NewMsgTransactionReceipt(TransactionSuccess, &tx, common.HexToHash("0x00"), blockHash, 0, 1, newLogs(2), 0, 0)
+	bridged := NewMsgBridgedLogs(blockHash, []*ethtypes.Log{
+		{Address: bridge, Topics: []common.Hash{topic}, TxHash: common.HexToHash("0xc0"), TxIndex: 1},
+		{Address: bridge, Topics: []common.Hash{topic}, TxHash: common.HexToHash("0xc1"), TxIndex: 2},
+	})
+	batch := []WatchMessage{bridged, receipt}
+	assignLogIndices(batch)
+	require.Equal(t, []uint{0, 1}, logIndices(t, receipt.GetValue()))
+
+	db := dbm.NewMemDB()
+	q := Querier{store: &WatchStore{db: db}, sw: true}
+	require.NoError(t, db.Set(bridged.GetKey(), []byte(bridged.GetValue())))
+	logs, err := q.GetBridgedLogs(blockHash)
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+	require.Equal(t, uint(2), logs[0].Index)
+	require.Equal(t, uint(3), logs[1].Index)
+	require.Equal(t, common.HexToHash("0xc1"), logs[1].TxHash)
+
+	logs, err = q.GetBridgedLogs(common.HexToHash("0xb1"))
+	require.NoError(t, err)
+	require.Nil(t, logs)
+
+	// the bridged logs are counted in the log stats
+	var count uint64
+	for _, m := range newMsgLogStats(1, batch) {
+		if s := m.(*MsgLogStats); s.addr == bridge && s.topic0 == topic {
+			count = s.count
+		}
+	}
+	require.Equal(t, uint64(2), count)
+}
@@ -0,0 +1,88 @@
+package watcher
+
+import (
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	dbm "github.com/tendermint/tm-db"
+)
+
+const migrateCodesBatchSize = 1000
+
+var emptyCodeHash = ethcrypto.Keccak256Hash(nil)
+
+// migrateCode moves the code of a legacy CodeInfo to the code hash entry shared by the contracts with the same
+// code, and references it from the CodeInfo. It returns false if the CodeInfo was already migrated.
+func migrateCode(batch dbm.Batch, key []byte, value []byte) (bool, error) {
+	var codeInfo CodeInfo
+	if err := json.Unmarshal(value, &codeInfo); err != nil {
+		return false, err
+	}
+	if codeInfo.CodeHash != (common.Hash{}) {
+		return false, nil
+	}
+
+	code, err := hex.DecodeString(codeInfo.Code)
+	if err != nil {
+		return false, err
+	}
+	codeInfo.CodeHash = ethcrypto.Keccak256Hash(code)
+	codeInfo.Code = ""
+	migrated, err := json.Marshal(codeInfo)
+	if err != nil {
+		return false, err
+	}
+	batch.Set(NewMsgCodeByHash(codeInfo.CodeHash.Bytes(), code).GetKey(), code)
+	batch.Set(key, migrated)
+	return true, nil
+}
+
+// MigrateCodes rewrites the contract codes stored per address by the legacy watcher into references to the codes
+// stored by code hash, it returns the number of contracts migrated. The contracts already migrated are skipped, so
+// the migration can be interrupted and run again. The db must not be written by a node meanwhile.
+func MigrateCodes(db dbm.DB) (int, error) {
+	var migrated int
+	start, end := prefixCode, sdk.PrefixEndBytes(prefixCode)
+	for {
+		// the db isn't written while it's iterated, some backends lock it
+		it, err := db.Iterator(start, end)
+		if err != nil {
+			return migrated, err
+		}
+		var keys, values [][]byte
+		for ; it.Valid() && len(keys) < migrateCodesBatchSize; it.Next() {
+			keys = append(keys, append([]byte{}, it.Key()...))
+			values = append(values, append([]byte{}, it.Value()...))
+		}
+		more := it.Valid()
+		if more {
+			start = append([]byte{}, it.Key()...)
+		}
+		it.Close()
+
+		batch := db.NewBatch()
+		count := 0
+		for i := range keys {
+			ok, err := migrateCode(batch, keys[i], values[i])
+			if err != nil {
+				batch.Close()
+				return migrated, err
+			}
+			if ok {
+				count++
+			}
+		}
+		err = batch.Write()
+		batch.Close()
+		if err != nil {
+			return migrated, err
+		}
+		migrated += count
+		if !more {
+			return migrated, nil
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package watcher
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/status-im/keycard-go/hexutils"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+)
+
+func TestMigrateCodes(t *testing.T) {
+	db := dbm.NewMemDB()
+	cache, err := lru.New(16)
+	require.NoError(t, err)
+	q := Querier{store: &WatchStore{db: db}, sw: true, lru: cache}
+	code := []byte{0x60, 0x80, 0x60, 0x40}
+	codeHash := ethcrypto.Keccak256(code)
+	clone, legacy := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+
+	// the clones reference the code stored once by hash
+	for _, msg := range []WatchMessage{NewMsgCode(clone, codeHash, 2), NewMsgCodeByHash(codeHash, code)} {
+		require.NoError(t, db.Set(msg.GetKey(), []byte(msg.GetValue())))
+	}
+	// the legacy entries hold the whole code
+	legacyInfo, err := json.Marshal(CodeInfo{Height: 1, Code: hexutils.BytesToHex(code)})
+	require.NoError(t, err)
+	require.NoError(t, db.Set(append(prefixCode, legacy.Bytes()...), legacyInfo))
+
+	for _, addr := range []common.Address{clone, legacy} {
+		got, err := q.GetCode(addr, 0)
+		require.NoError(t, err)
+		require.Equal(t, code, got)
+	}
+	_, err = q.GetCode(clone, 1)
+	require.Error(t, err)
+
+	count, err := MigrateCodes(db)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	count, err = MigrateCodes(db)
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	value, err := db.Get(append(prefixCode, legacy.Bytes()...))
+	require.NoError(t, err)
+	var codeInfo CodeInfo
+	require.NoError(t, json.Unmarshal(value, &codeInfo))
+	require.Empty(t, codeInfo.Code)
+	require.Equal(t, uint64(1), codeInfo.Height)
+	got, err := q.GetCode(legacy, 0)
+	require.NoError(t, err)
+	require.Equal(t, code, got)
+}
@@ -72,3 +72,8 @@ func (w WatchStore) Has(key []byte) bool {
 	}
 	return res
 }
+
+// GetDB returns the underlying db of the watcher
+func (w WatchStore) GetDB() dbm.DB {
+	return w.db
+}
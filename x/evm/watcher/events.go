@@ -0,0 +1,118 @@
+package watcher
+
+import (
+	"encoding/json"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+
+	rpctypes "github.com/okex/exchain/app/rpc/types"
+)
+
+// chainEventsBuffer is the number of blocks a subscriber can fall behind before the next ones are dropped
+const chainEventsBuffer = 16
+
+// ChainEvent is the header and the logs of a block committed into the watcher db, it's shared by all the
+// subscribers and must not be modified
+type ChainEvent struct {
+	Header *rpctypes.EthHeaderWithBlockHash
+	// Logs are the logs of all the txs of the block, in the order of their index
+	Logs []*ethtypes.Log
+	// Receipts are the receipts of the txs of the block, in the order of the txs
+	Receipts []*TransactionReceipt
+}
+
+// chainFeed broadcasts the blocks once they are committed into the watcher db, so the rpc serves a block
+// as soon as its event is received
+type chainFeed struct {
+	mtx  sync.Mutex
+	subs map[chan *ChainEvent]struct{}
+}
+
+var chainEvents = &chainFeed{subs: make(map[chan *ChainEvent]struct{})}
+
+// SubscribeChainEvents returns the events of the blocks committed into the watcher db, and the function
+// to unsubscribe. The events are dropped while the subscriber doesn't keep up.
+func SubscribeChainEvents() (<-chan *ChainEvent, func()) {
+	ch := make(chan *ChainEvent, chainEventsBuffer)
+	chainEvents.mtx.Lock()
+	chainEvents.subs[ch] = struct{}{}
+	chainEvents.mtx.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			chainEvents.mtx.Lock()
+			delete(chainEvents.subs, ch)
+			chainEvents.mtx.Unlock()
+		})
+	}
+}
+
+// publish sends the event of the committed block, from the value of its MsgBlock, of its receipts and of its
+// bridged logs
+func (f *chainFeed) publish(block []byte, receipts map[string][]byte, bridged []byte) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if len(f.subs) == 0 {
+		return
+	}
+	var b EthBlock
+	if err := json.Unmarshal(block, &b); err != nil {
+		return
+	}
+	ev := &ChainEvent{Header: newHeader(&b), Logs: []*ethtypes.Log{}}
+	for _, value := range receipts {
+		var receipt TransactionReceipt
+		if err := json.Unmarshal(value, &receipt); err != nil {
+			continue
+		}
+		ev.Logs = append(ev.Logs, receipt.Logs...)
+		ev.Receipts = append(ev.Receipts, &receipt)
+	}
+	if len(bridged) > 0 {
+		var logs []*ethtypes.Log
+		if err := json.Unmarshal(bridged, &logs); err == nil {
+			ev.Logs = append(ev.Logs, logs...)
+		}
+	}
+	sort.Slice(ev.Logs, func(i, j int) bool { return ev.Logs[i].Index < ev.Logs[j].Index })
+	sort.Slice(ev.Receipts, func(i, j int) bool { return ev.Receipts[i].TransactionIndex < ev.Receipts[j].TransactionIndex })
+
+	for ch := range f.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// newHeader returns the geth-shaped header of the block
+func newHeader(b *EthBlock) *rpctypes.EthHeaderWithBlockHash {
+	gasUsed := hexutil.Uint64(0)
+	if b.GasUsed != nil {
+		gasUsed = hexutil.Uint64(b.GasUsed.ToInt().Uint64())
+	}
+	return &rpctypes.EthHeaderWithBlockHash{
+		ParentHash:  b.ParentHash,
+		UncleHash:   ethtypes.EmptyUncleHash,
+		Coinbase:    b.Miner,
+		Root:        b.StateRoot,
+		TxHash:      b.TransactionsRoot,
+		ReceiptHash: ethtypes.EmptyRootHash,
+		Bloom:       b.LogsBloom,
+		Difficulty:  (*hexutil.Big)(new(big.Int).SetUint64(uint64(b.Difficulty))),
+		Number:      (*hexutil.Big)(new(big.Int).SetUint64(uint64(b.Number))),
+		GasLimit:    b.GasLimit,
+		GasUsed:     gasUsed,
+		Time:        b.Timestamp,
+		Extra:       b.ExtraData,
+		MixDigest:   b.MixHash,
+		Nonce:       ethtypes.BlockNonce(b.Nonce),
+		Hash:        b.Hash,
+		BaseFee:     b.BaseFeePerGas,
+	}
+}
@@ -0,0 +1,74 @@
+package watcher
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	abci "github.com/okex/exchain/libs/tendermint/abci/types"
+	"github.com/okex/exchain/x/evm/types"
+)
+
+func TestChainEvents(t *testing.T) {
+	db := dbm.NewMemDB()
+	w := &Watcher{store: &WatchStore{db: db}}
+
+	eventsCh, unsubscribe := SubscribeChainEvents()
+	blockHash := common.HexToHash("0xb1")
+	bloom := ethtypes.BytesToBloom([]byte{0x01})
+	miner := common.HexToAddress("0x01")
+	header := abci.Header{Height: 10, Time: time.Unix(1000, 0), LastBlockId: abci.BlockID{Hash: common.HexToHash("0xb0").Bytes()}}
+	block := NewMsgBlock(10, bloom, blockHash, header, 30000000, big.NewInt(21000), []common.Hash{}, big.NewInt(7), miner)
+	to := common.HexToAddress("0x02")
+	tx := types.NewMsgEthereumTx(0, &to, big.NewInt(0), 21000, big.NewInt(1), nil)
+	tx1 := NewMsgTransactionReceipt(TransactionSuccess, &tx, common.HexToHash("0x01"), blockHash, 1, 10, newLogs(1), 0, 0)
+	tx0 := NewMsgTransactionReceipt(TransactionSuccess, &tx, common.HexToHash("0x00"), blockHash, 0, 10, newLogs(2), 0, 0)
+	batch := []WatchMessage{tx1, tx0, block, NewMsgLatestHeight(10)}
+	assignLogIndices(batch)
+	w.commitBatch(batch)
+
+	// the event is pushed once the block is written
+	ev := <-eventsCh
+	head := ev.Header
+	has, err := db.Has(block.GetKey())
+	require.NoError(t, err)
+	require.True(t, has)
+	require.Equal(t, blockHash, head.Hash)
+	require.Equal(t, common.HexToHash("0xb0"), head.ParentHash)
+	require.Equal(t, big.NewInt(10), head.Number.ToInt())
+	require.Equal(t, bloom, head.Bloom)
+	require.Equal(t, miner, head.Coinbase)
+	require.Equal(t, hexutil.Uint64(30000000), head.GasLimit)
+	require.Equal(t, hexutil.Uint64(21000), head.GasUsed)
+	require.Equal(t, big.NewInt(7), head.BaseFee.ToInt())
+	require.Equal(t, hexutil.Uint64(1000), head.Time)
+	require.Equal(t, ethtypes.EmptyUncleHash, head.UncleHash)
+	require.Len(t, ev.Logs, 3)
+	for i, log := range ev.Logs {
+		require.Equal(t, uint(i), log.Index)
+		require.Equal(t, blockHash, log.BlockHash)
+	}
+	require.Equal(t, common.HexToHash("0x01"), ev.Logs[2].TxHash)
+	require.Len(t, ev.Receipts, 2)
+	require.Equal(t, common.HexToHash("0x00").String(), ev.Receipts[0].TransactionHash)
+
+	// the replicas push the events of the watch data
+	w.commitCenterBatch([]*Batch{
+		{tx0.GetKey(), []byte(tx0.GetValue()), tx0.GetType()},
+		{block.GetKey(), []byte(block.GetValue()), block.GetType()},
+	})
+	ev = <-eventsCh
+	require.Equal(t, blockHash, ev.Header.Hash)
+	require.Len(t, ev.Logs, 2)
+
+	unsubscribe()
+	unsubscribe()
+	w.commitBatch([]WatchMessage{block})
+	require.Empty(t, eventsCh)
+}
@@ -0,0 +1,69 @@
+package watcher
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	rpctypes "github.com/okex/exchain/app/rpc/types"
+	dbm "github.com/tendermint/tm-db"
+)
+
+// BlockData is a block of the watcher db with its txs and their receipts, in the order of the block
+type BlockData struct {
+	Block    EthBlock
+	Txs      []rpctypes.Transaction
+	Receipts []TransactionReceipt
+}
+
+// ReadBlockData reads the block at the height with its txs and receipts from the watcher db, it returns nil if
+// the block isn't found. The log indices of the receipts are fixed first, like on their first read by the rpc.
+func ReadBlockData(db dbm.DB, height uint64) (*BlockData, error) {
+	hash, err := db.Get(NewMsgBlockInfo(height, common.Hash{}).GetKey())
+	if err != nil || hash == nil {
+		return nil, err
+	}
+	blockHash := common.HexToHash(string(hash))
+	bz, err := db.Get(append(prefixBlock, blockHash.Bytes()...))
+	if err != nil || bz == nil {
+		return nil, err
+	}
+	if _, err := FixBlockLogIndices(db, blockHash); err != nil {
+		return nil, err
+	}
+
+	var data BlockData
+	if err := json.Unmarshal(bz, &data.Block); err != nil {
+		return nil, fmt.Errorf("invalid block %s at height %d: %w", blockHash, height, err)
+	}
+	var txHashes struct {
+		Transactions []common.Hash `json:"transactions"`
+	}
+	if err := json.Unmarshal(bz, &txHashes); err != nil {
+		return nil, fmt.Errorf("invalid txs of block %s at height %d: %w", blockHash, height, err)
+	}
+	for _, txHash := range txHashes.Transactions {
+		var tx rpctypes.Transaction
+		if err := readJSON(db, append(prefixTx, txHash.Bytes()...), &tx); err != nil {
+			return nil, fmt.Errorf("failed to read tx %s: %w", txHash, err)
+		}
+		var receipt TransactionReceipt
+		if err := readJSON(db, append(prefixReceipt, txHash.Bytes()...), &receipt); err != nil {
+			return nil, fmt.Errorf("failed to read the receipt of tx %s: %w", txHash, err)
+		}
+		data.Txs = append(data.Txs, tx)
+		data.Receipts = append(data.Receipts, receipt)
+	}
+	return &data, nil
+}
+
+func readJSON(db dbm.DB, key []byte, v interface{}) error {
+	bz, err := db.Get(key)
+	if err != nil {
+		return err
+	}
+	if bz == nil {
+		return errNotFound
+	}
+	return json.Unmarshal(bz, v)
+}
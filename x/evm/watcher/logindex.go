@@ -0,0 +1,173 @@
+package watcher
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	dbm "github.com/tendermint/tm-db"
+
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+)
+
+// The logs of a block are indexed from 0 across all its txs, as the clients dedupe the logs by
+// (blockHash, logIndex). The indices are assigned when the block is committed into the watcher, the
+// blocks committed before are fixed when their receipts are read, or all at once by MigrateLogIndices.
+
+const migrateLogIndicesBatchSize = 1000
+
+// MsgLogIndexed marks a block whose receipts have the block-wide log indices
+type MsgLogIndexed struct {
+	blockHash []byte
+}
+
+func NewMsgLogIndexed(blockHash common.Hash) *MsgLogIndexed {
+	return &MsgLogIndexed{blockHash: blockHash.Bytes()}
+}
+
+func (m MsgLogIndexed) GetType() uint32 {
+	return TypeOthers
+}
+
+func (m MsgLogIndexed) GetKey() []byte {
+	return append(prefixLogIndexed, m.blockHash...)
+}
+
+func (m MsgLogIndexed) GetValue() string {
+	return "1"
+}
+
+// assignLogIndices numbers the logs of the receipts in the batch in the order of their txs, whatever the
+// indices the txs were executed with
+func assignLogIndices(batch []WatchMessage) {
+	var logIndex uint
+	for _, m := range lastReceipts(batch) {
+		receipt := m.newReceipt()
+		for _, log := range receipt.Logs {
+			log.Index = logIndex
+			logIndex++
+		}
+		m.receipt = &receipt
+	}
+	for _, log := range bridgedLogs(batch) {
+		log.Index = logIndex
+		logIndex++
+	}
+}
+
+// lastReceipts returns the receipts of the batch in the order of their txs, a tx re-executed in the block
+// keeps its last receipt, like the store does
+func lastReceipts(batch []WatchMessage) []*MsgTransactionReceipt {
+	last := make(map[string]*MsgTransactionReceipt)
+	for _, b := range batch {
+		if m, ok := b.(*MsgTransactionReceipt); ok {
+			last[string(m.txHash)] = m
+		}
+	}
+	receipts := make([]*MsgTransactionReceipt, 0, len(last))
+	for _, m := range last {
+		receipts = append(receipts, m)
+	}
+	sort.Slice(receipts, func(i, j int) bool { return receipts[i].txIndex < receipts[j].txIndex })
+	return receipts
+}
+
+// FixBlockLogIndices renumbers the logs of the receipts of a block committed before the block-wide log
+// indices. It returns false if the block is already fixed, or isn't committed yet.
+func FixBlockLogIndices(db dbm.DB, blockHash common.Hash) (bool, error) {
+	indexedKey := NewMsgLogIndexed(blockHash).GetKey()
+	if indexed, err := db.Has(indexedKey); err != nil || indexed {
+		return false, err
+	}
+	bz, err := db.Get(append(prefixBlock, blockHash.Bytes()...))
+	if err != nil || bz == nil {
+		return false, err
+	}
+	var block EthBlock
+	if err := json.Unmarshal(bz, &block); err != nil {
+		return false, err
+	}
+
+	// the block stores the hashes of its txs
+	txHashes, _ := block.Transactions.([]interface{})
+	receipts := make([]*TransactionReceipt, 0, len(txHashes))
+	for _, txHash := range txHashes {
+		hexHash, ok := txHash.(string)
+		if !ok {
+			continue
+		}
+		bz, err := db.Get(append(prefixReceipt, common.HexToHash(hexHash).Bytes()...))
+		if err != nil {
+			return false, err
+		}
+		if bz == nil {
+			continue
+		}
+		var receipt TransactionReceipt
+		if err := json.Unmarshal(bz, &receipt); err != nil {
+			return false, err
+		}
+		receipts = append(receipts, &receipt)
+	}
+	sort.Slice(receipts, func(i, j int) bool { return receipts[i].TransactionIndex < receipts[j].TransactionIndex })
+
+	batch := db.NewBatch()
+	defer batch.Close()
+	var logIndex uint
+	for _, receipt := range receipts {
+		changed := false
+		for _, log := range receipt.Logs {
+			if log.Index != logIndex {
+				log.Index = logIndex
+				changed = true
+			}
+			logIndex++
+		}
+		if !changed {
+			continue
+		}
+		value, err := json.Marshal(receipt)
+		if err != nil {
+			return false, err
+		}
+		batch.Set(append(prefixReceipt, common.HexToHash(receipt.TransactionHash).Bytes()...), value)
+	}
+	batch.Set(indexedKey, []byte(NewMsgLogIndexed(blockHash).GetValue()))
+	return true, batch.Write()
+}
+
+// MigrateLogIndices fixes the log indices of all the blocks of the watcher db, it returns the number of
+// blocks fixed. The db must not be written by a node meanwhile.
+func MigrateLogIndices(db dbm.DB) (int, error) {
+	var fixed int
+	start, end := prefixBlock, sdk.PrefixEndBytes(prefixBlock)
+	for {
+		// the db isn't written while it's iterated, some backends lock it
+		it, err := db.Iterator(start, end)
+		if err != nil {
+			return fixed, err
+		}
+		var blockHashes []common.Hash
+		for ; it.Valid() && len(blockHashes) < migrateLogIndicesBatchSize; it.Next() {
+			blockHashes = append(blockHashes, common.BytesToHash(it.Key()[len(prefixBlock):]))
+		}
+		more := it.Valid()
+		if more {
+			start = append([]byte{}, it.Key()...)
+		}
+		it.Close()
+
+		for _, blockHash := range blockHashes {
+			ok, err := FixBlockLogIndices(db, blockHash)
+			if err != nil {
+				return fixed, err
+			}
+			if ok {
+				fixed++
+			}
+		}
+		if !more {
+			return fixed, nil
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package watcher
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/okex/exchain/x/evm/types"
+)
+
+func newLogs(n int) *types.ResultData {
+	data := &types.ResultData{}
+	for i := 0; i < n; i++ {
+		// the logs restart from 0 in every tx
+		data.Logs = append(data.Logs, &ethtypes.Log{Topics: []common.Hash{}, Index: uint(i)})
+	}
+	return data
+}
+
+func logIndices(t *testing.T, value string) []uint {
+	var receipt TransactionReceipt
+	require.NoError(t, json.Unmarshal([]byte(value), &receipt))
+	indices := []uint{}
+	for _, log := range receipt.Logs {
+		indices = append(indices, log.Index)
+	}
+	return indices
+}
+
+func TestAssignLogIndices(t *testing.T) {
+	to := common.HexToAddress("0x01")
+	tx := types.NewMsgEthereumTx(0, &to, big.NewInt(0), 21000, big.NewInt(1), nil)
+	blockHash := common.HexToHash("0xb0")
+	receipt := func(txHash string, txIndex uint64, logs int) *MsgTransactionReceipt {
+		return NewMsgTransactionReceipt(TransactionSuccess, &tx, common.HexToHash(txHash), blockHash, txIndex, 1, newLogs(logs), 0, 0)
+	}
+
+	// the receipts are numbered in the order of their txs, the re-executed tx keeps its last receipt
+	tx2 := receipt("0x02", 2, 1)
+	tx0 := receipt("0x00", 0, 2)
+	tx1 := receipt("0x01", 1, 0)
+	tx2Rerun := receipt("0x02", 3, 3)
+	batch := []WatchMessage{tx2, tx0, NewMsgLatestHeight(1), tx1, tx2Rerun}
+	assignLogIndices(batch)
+
+	require.Equal(t, []uint{0, 1}, logIndices(t, tx0.GetValue()))
+	require.Equal(t, []uint{}, logIndices(t, tx1.GetValue()))
+	require.Equal(t, []uint{2, 3, 4}, logIndices(t, tx2Rerun.GetValue()))
+}
+
+func TestFixBlockLogIndices(t *testing.T) {
+	db := dbm.NewMemDB()
+	q := Querier{store: &WatchStore{db: db}, sw: true}
+	to := common.HexToAddress("0x01")
+	tx := types.NewMsgEthereumTx(0, &to, big.NewInt(0), 21000, big.NewInt(1), nil)
+
+	// the blocks committed before the block-wide log indices
+	blockHashes := []common.Hash{common.HexToHash("0xb0"), common.HexToHash("0xb1")}
+	var txHashes []common.Hash
+	for i, blockHash := range blockHashes {
+		var blockTxs []common.Hash
+		for j := 0; j < 3; j++ {
+			txHash := common.BigToHash(big.NewInt(int64(10*i + j + 1)))
+			msg := NewMsgTransactionReceipt(TransactionSuccess, &tx, txHash, blockHash, uint64(j), uint64(i+1), newLogs(2), 0, 0)
+			require.NoError(t, db.Set(msg.GetKey(), []byte(msg.GetValue())))
+			blockTxs = append(blockTxs, txHash)
+			txHashes = append(txHashes, txHash)
+		}
+		block, err := json.Marshal(EthBlock{Hash: blockHash, Transactions: blockTxs})
+		require.NoError(t, err)
+		require.NoError(t, db.Set(append(prefixBlock, blockHash.Bytes()...), block))
+	}
+
+	// the receipts are fixed on read
+	receipt, err := q.GetTransactionReceipt(txHashes[2])
+	require.NoError(t, err)
+	require.Equal(t, uint(4), receipt.Logs[0].Index)
+	require.Equal(t, uint(5), receipt.Logs[1].Index)
+	fixed, err := FixBlockLogIndices(db, blockHashes[0])
+	require.NoError(t, err)
+	require.False(t, fixed, "the block is already fixed")
+
+	// the migration fixes the other blocks
+	fixed, err = FixBlockLogIndices(db, common.HexToHash("0xb2"))
+	require.NoError(t, err)
+	require.False(t, fixed, "the block isn't committed")
+	count, err := MigrateLogIndices(db)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	for i, txHash := range txHashes[3:] {
+		receipt, err := q.GetTransactionReceipt(txHash)
+		require.NoError(t, err)
+		require.Equal(t, uint(2*i), receipt.Logs[0].Index)
+	}
+	count, err = MigrateLogIndices(db)
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+}
@@ -0,0 +1,212 @@
+package watcher
+
+import (
+	"encoding/binary"
+	"errors"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	dbm "github.com/tendermint/tm-db"
+
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+)
+
+// The logs of a block are counted by (contract address, topic0), so that the dapps can estimate the size of
+// the results of eth_getLogs over a block range before querying it. The logs without topics are counted under
+// the zero topic.
+
+const (
+	DefaultLogStatsBucketSize = 1000
+	// MaxLogStatsRanges bounds the number of ranges returned by a query
+	MaxLogStatsRanges = 10000
+)
+
+// MsgLogStats is the number of logs of a contract with the topic0 in a block
+type MsgLogStats struct {
+	addr   common.Address
+	topic0 common.Hash
+	height uint64
+	count  uint64
+}
+
+func NewMsgLogStats(addr common.Address, topic0 common.Hash, height, count uint64) *MsgLogStats {
+	return &MsgLogStats{addr: addr, topic0: topic0, height: height, count: count}
+}
+
+func (m MsgLogStats) GetType() uint32 {
+	return TypeOthers
+}
+
+func (m MsgLogStats) GetKey() []byte {
+	key := append(logStatsPrefix(m.addr), m.topic0.Bytes()...)
+	return append(key, sdk.Uint64ToBigEndian(m.height)...)
+}
+
+func (m MsgLogStats) GetValue() string {
+	return strconv.FormatUint(m.count, 10)
+}
+
+func logStatsPrefix(addr common.Address) []byte {
+	return append(append([]byte{}, prefixLogStats...), addr.Bytes()...)
+}
+
+type logStatsKey struct {
+	addr   common.Address
+	topic0 common.Hash
+}
+
+// newMsgLogStats counts the logs of the receipts of the block in the batch
+func newMsgLogStats(height uint64, batch []WatchMessage) []WatchMessage {
+	var logs []*ethtypes.Log
+	for _, m := range lastReceipts(batch) {
+		receipt := m.receipt
+		if receipt == nil {
+			tr := m.newReceipt()
+			receipt = &tr
+		}
+		logs = append(logs, receipt.Logs...)
+	}
+	logs = append(logs, bridgedLogs(batch)...)
+
+	counts := make(map[logStatsKey]uint64)
+	var keys []logStatsKey
+	for _, log := range logs {
+		key := logStatsKey{addr: log.Address}
+		if len(log.Topics) > 0 {
+			key.topic0 = log.Topics[0]
+		}
+		if _, ok := counts[key]; !ok {
+			keys = append(keys, key)
+		}
+		counts[key]++
+	}
+
+	msgs := make([]WatchMessage, 0, len(keys))
+	for _, key := range keys {
+		msgs = append(msgs, NewMsgLogStats(key.addr, key.topic0, height, counts[key]))
+	}
+	return msgs
+}
+
+// LogRange is the number of logs in a range of blocks
+type LogRange struct {
+	FromBlock hexutil.Uint64 `json:"fromBlock"`
+	ToBlock   hexutil.Uint64 `json:"toBlock"`
+	Count     hexutil.Uint64 `json:"count"`
+}
+
+// LogStats is the number of logs of a contract in a range of blocks, split in the ranges of the bucket size
+// which have logs
+type LogStats struct {
+	Total  hexutil.Uint64 `json:"total"`
+	Ranges []LogRange     `json:"ranges"`
+}
+
+// GetLogStats counts the logs of the contract with the topic0, or with any topic if topic0 is nil, from the
+// block fromBlock to the block toBlock included, in buckets of bucketSize blocks starting at fromBlock.
+func (q Querier) GetLogStats(addr common.Address, topic0 *common.Hash, fromBlock, toBlock, bucketSize uint64) (*LogStats, error) {
+	if !q.enabled() {
+		return nil, errors.New(MsgFunctionDisable)
+	}
+	if fromBlock > toBlock {
+		return nil, errors.New("invalid block range")
+	}
+	if bucketSize == 0 || (toBlock-fromBlock)/bucketSize >= MaxLogStatsRanges {
+		return nil, errors.New("invalid bucket size")
+	}
+
+	// the counts of the topics are summed, as the heights are not ordered across the topics
+	counts := make(map[uint64]uint64)
+	count := func(prefix []byte) error {
+		start := append(append([]byte{}, prefix...), sdk.Uint64ToBigEndian(fromBlock)...)
+		end := append(append([]byte{}, prefix...), sdk.Uint64ToBigEndian(toBlock+1)...)
+		it, err := q.store.db.Iterator(start, end)
+		if err != nil {
+			return err
+		}
+		defer it.Close()
+		for ; it.Valid(); it.Next() {
+			height := binary.BigEndian.Uint64(it.Key()[len(prefix):])
+			n, err := strconv.ParseUint(string(it.Value()), 10, 64)
+			if err != nil {
+				return err
+			}
+			counts[fromBlock+(height-fromBlock)/bucketSize*bucketSize] += n
+		}
+		return nil
+	}
+
+	if topic0 != nil {
+		if err := count(append(logStatsPrefix(addr), topic0.Bytes()...)); err != nil {
+			return nil, err
+		}
+	} else {
+		var topics []common.Hash
+		err := iteratePrefixKeys(q.store.db, logStatsPrefix(addr), common.HashLength, func(topic []byte) {
+			topics = append(topics, common.BytesToHash(topic))
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, topic := range topics {
+			if err := count(append(logStatsPrefix(addr), topic.Bytes()...)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	stats := &LogStats{Ranges: []LogRange{}}
+	for start := fromBlock; start <= toBlock; start += bucketSize {
+		if n, ok := counts[start]; ok {
+			end := start + bucketSize - 1
+			if end > toBlock {
+				end = toBlock
+			}
+			stats.Ranges = append(stats.Ranges, LogRange{FromBlock: hexutil.Uint64(start), ToBlock: hexutil.Uint64(end), Count: hexutil.Uint64(n)})
+			stats.Total += hexutil.Uint64(n)
+		}
+		if start+bucketSize < start {
+			break
+		}
+	}
+	return stats, nil
+}
+
+// iteratePrefixKeys calls fn with the distinct parts of the given length following the prefix in the keys,
+// skipping the keys sharing a part
+func iteratePrefixKeys(db dbm.DB, prefix []byte, length int, fn func(part []byte)) error {
+	start, end := prefix, sdk.PrefixEndBytes(prefix)
+	for {
+		it, err := db.Iterator(start, end)
+		if err != nil {
+			return err
+		}
+		if !it.Valid() {
+			it.Close()
+			return nil
+		}
+		key := append([]byte{}, it.Key()...)
+		it.Close()
+		if len(key) < len(prefix)+length {
+			return errors.New("invalid key length")
+		}
+		part := key[len(prefix) : len(prefix)+length]
+		fn(part)
+		start = sdk.PrefixEndBytes(append(append([]byte{}, prefix...), part...))
+		if start == nil {
+			return nil
+		}
+	}
+}
+
+// rollbackLogStats removes the logs counted above the height
+func rollbackLogStats(db dbm.DB, batch dbm.Batch, height uint64) error {
+	return iteratePrefix(db, prefixLogStats, func(key, _ []byte) error {
+		if binary.BigEndian.Uint64(key[len(key)-8:]) > height {
+			batch.Delete(key)
+		}
+		return nil
+	})
+}
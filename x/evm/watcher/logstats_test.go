@@ -0,0 +1,73 @@
+package watcher
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/okex/exchain/x/evm/types"
+)
+
+func TestGetLogStats(t *testing.T) {
+	db := dbm.NewMemDB()
+	q := Querier{store: &WatchStore{db: db}, sw: true}
+	to := common.HexToAddress("0x01")
+	tx := types.NewMsgEthereumTx(0, &to, big.NewInt(0), 21000, big.NewInt(1), nil)
+	contract := common.HexToAddress("0xc0")
+	transfer, approval := common.HexToHash("0xaa"), common.HexToHash("0xbb")
+	newLog := func(topics ...common.Hash) *ethtypes.Log {
+		return &ethtypes.Log{Address: contract, Topics: topics}
+	}
+
+	// a transfer per block, an approval and an anonymous log every 5 blocks
+	for height := uint64(1); height <= 20; height++ {
+		logs := []*ethtypes.Log{newLog(transfer)}
+		if height%5 == 0 {
+			logs = append(logs, newLog(approval, transfer), newLog())
+		}
+		blockHash := common.BigToHash(new(big.Int).SetUint64(height))
+		batch := []WatchMessage{
+			NewMsgTransactionReceipt(TransactionSuccess, &tx, common.HexToHash("0x02"), blockHash, 0, height, &types.ResultData{Logs: logs}, 0, 0),
+		}
+		assignLogIndices(batch)
+		for _, msg := range newMsgLogStats(height, batch) {
+			require.NoError(t, db.Set(msg.GetKey(), []byte(msg.GetValue())))
+		}
+	}
+
+	stats, err := q.GetLogStats(contract, &transfer, 1, 20, 10)
+	require.NoError(t, err)
+	require.Equal(t, uint64(20), uint64(stats.Total))
+	require.Equal(t, []LogRange{{FromBlock: 1, ToBlock: 10, Count: 10}, {FromBlock: 11, ToBlock: 20, Count: 10}}, stats.Ranges)
+
+	stats, err = q.GetLogStats(contract, &approval, 3, 12, 4)
+	require.NoError(t, err)
+	require.Equal(t, []LogRange{{FromBlock: 3, ToBlock: 6, Count: 1}, {FromBlock: 7, ToBlock: 10, Count: 1}}, stats.Ranges)
+
+	// all the topics, the anonymous logs included
+	stats, err = q.GetLogStats(contract, nil, 6, 15, 100)
+	require.NoError(t, err)
+	require.Equal(t, []LogRange{{FromBlock: 6, ToBlock: 15, Count: 14}}, stats.Ranges)
+
+	stats, err = q.GetLogStats(to, nil, 1, 20, 10)
+	require.NoError(t, err)
+	require.Zero(t, stats.Total)
+	require.Empty(t, stats.Ranges)
+
+	_, err = q.GetLogStats(contract, nil, 2, 1, 10)
+	require.Error(t, err)
+	_, err = q.GetLogStats(contract, nil, 1, MaxLogStatsRanges*10, 1)
+	require.Error(t, err)
+
+	// the logs counted above the height are rolled back
+	require.NoError(t, db.Set(NewMsgLatestHeight(20).GetKey(), []byte(NewMsgLatestHeight(20).GetValue())))
+	_, err = Rollback(db, 10)
+	require.NoError(t, err)
+	stats, err = q.GetLogStats(contract, &transfer, 1, 20, 10)
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), uint64(stats.Total))
+}
@@ -0,0 +1,57 @@
+package watcher
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+const FlagCommitQueueSize = "fast-query-commit-queue"
+
+// commitPipeline writes the watch data of the blocks in the background, one block after another in the
+// order they are committed. As the jobs never overlap, the data of a block can't be overwritten by the
+// one of an earlier block, and the latest height, written last by each block, never gets ahead of the
+// written data.
+//
+// The writes of up to size jobs are queued without blocking the commit of the next blocks. Past it the
+// writer is too far behind the chain: submit blocks until a job is done rather than queuing the watch
+// data of the blocks without bound, and logs the time it waited.
+type commitPipeline struct {
+	once sync.Once
+	jobs chan func()
+	wg   sync.WaitGroup
+}
+
+func newCommitPipeline(size int) *commitPipeline {
+	if size < 1 {
+		size = 1
+	}
+	return &commitPipeline{jobs: make(chan func(), size)}
+}
+
+func (p *commitPipeline) run() {
+	for job := range p.jobs {
+		job()
+		p.wg.Done()
+	}
+}
+
+// submit queues the writes of a block, it only blocks while the queue is full
+func (p *commitPipeline) submit(job func()) {
+	p.once.Do(func() { go p.run() })
+	p.wg.Add(1)
+	select {
+	case p.jobs <- job:
+		return
+	default:
+	}
+
+	start := time.Now()
+	p.jobs <- job
+	log.Printf("watchdb: the writes are %d jobs behind the chain, waited %s for them\n", cap(p.jobs), time.Since(start))
+}
+
+// wait blocks until the writes of all the submitted blocks are done
+func (p *commitPipeline) wait() {
+	p.wg.Wait()
+}
@@ -22,9 +22,10 @@ const MsgFunctionDisable = "fast query function has been disabled"
 var errNotFound = errors.New("leveldb: not found")
 
 type Querier struct {
-	store *WatchStore
-	sw    bool
-	lru   *lru.Cache
+	store    *WatchStore
+	sw       bool
+	lru      *lru.Cache
+	breakers *breakers
 }
 
 func (q Querier) enabled() bool {
@@ -35,15 +36,37 @@ func (q *Querier) Enable(sw bool) {
 	q.sw = sw
 }
 
+// allow returns ErrCircuitOpen if the queries of the data type must go straight to the chain
+func (q Querier) allow(t DataType) error {
+	if !q.enabled() {
+		return nil
+	}
+	return q.breakers.allow(t)
+}
+
+func (q Querier) record(t DataType, err error) {
+	if q.enabled() {
+		q.breakers.record(t, err)
+	}
+}
+
 func NewQuerier() *Querier {
 	lru, e := lru.New(GetWatchLruSize())
 	if e != nil {
 		panic(errors.New("Failed to init LRU Cause " + e.Error()))
 	}
-	return &Querier{store: InstanceOfWatchStore(), sw: IsWatcherEnabled(), lru: lru}
+	return &Querier{store: InstanceOfWatchStore(), sw: IsWatcherEnabled(), lru: lru, breakers: getBreakers()}
+}
+
+func (q Querier) GetTransactionReceipt(hash common.Hash) (receipt *TransactionReceipt, err error) {
+	if err = q.allow(DataReceipt); err != nil {
+		return nil, err
+	}
+	defer func() { q.record(DataReceipt, err) }()
+	return q.getTransactionReceipt(hash)
 }
 
-func (q Querier) GetTransactionReceipt(hash common.Hash) (*TransactionReceipt, error) {
+func (q Querier) getTransactionReceipt(hash common.Hash) (*TransactionReceipt, error) {
 	if !q.enabled() {
 		return nil, errors.New(MsgFunctionDisable)
 	}
@@ -59,13 +82,43 @@ func (q Querier) GetTransactionReceipt(hash common.Hash) (*TransactionReceipt, e
 	if e != nil {
 		return nil, e
 	}
+	if len(receipt.Logs) > 0 {
+		// the receipts committed before the block-wide log indices are fixed on their first read
+		fixed, e := FixBlockLogIndices(q.store.db, common.HexToHash(receipt.BlockHash))
+		if e != nil {
+			return nil, e
+		}
+		if fixed {
+			return q.getTransactionReceipt(hash)
+		}
+	}
 	if receipt.Logs == nil {
 		receipt.Logs = []*ethtypes.Log{}
 	}
 	return &receipt, nil
 }
 
-func (q Querier) GetBlockByHash(hash common.Hash, fullTx bool) (*EthBlock, error) {
+// SaveTransactionReceipt backfills the watcher with a receipt built outside of the block commit
+func (q Querier) SaveTransactionReceipt(receipt *TransactionReceipt) {
+	if !q.enabled() {
+		return
+	}
+	b, e := json.Marshal(receipt)
+	if e != nil {
+		return
+	}
+	q.store.Set(append(prefixReceipt, common.HexToHash(receipt.TransactionHash).Bytes()...), b)
+}
+
+func (q Querier) GetBlockByHash(hash common.Hash, fullTx bool) (block *EthBlock, err error) {
+	if err = q.allow(DataBlock); err != nil {
+		return nil, err
+	}
+	defer func() { q.record(DataBlock, err) }()
+	return q.getBlockByHash(hash, fullTx)
+}
+
+func (q Querier) getBlockByHash(hash common.Hash, fullTx bool) (*EthBlock, error) {
 	if !q.enabled() {
 		return nil, errors.New(MsgFunctionDisable)
 	}
@@ -102,7 +155,40 @@ func (q Querier) GetBlockByHash(hash common.Hash, fullTx bool) (*EthBlock, error
 	return &block, nil
 }
 
-func (q Querier) GetBlockHashByNumber(number uint64) (common.Hash, error) {
+// GetBlockLogs returns the logs of the txs of the block, in the order of the txs
+func (q Querier) GetBlockLogs(hash common.Hash) ([][]*ethtypes.Log, error) {
+	block, e := q.GetBlockByHash(hash, false)
+	if e != nil {
+		return nil, e
+	}
+	txsHash, _ := block.Transactions.([]interface{})
+	blockLogs := make([][]*ethtypes.Log, 0, len(txsHash))
+	for _, tx := range txsHash {
+		txHash, ok := tx.(string)
+		if !ok {
+			continue
+		}
+		receipt, e := q.GetTransactionReceipt(common.HexToHash(txHash))
+		if e != nil {
+			return nil, e
+		}
+		blockLogs = append(blockLogs, receipt.Logs)
+	}
+	if bridged, err := q.GetBridgedLogs(hash); err == nil && len(bridged) > 0 {
+		blockLogs = append(blockLogs, bridged)
+	}
+	return blockLogs, nil
+}
+
+func (q Querier) GetBlockHashByNumber(number uint64) (hash common.Hash, err error) {
+	if err = q.allow(DataBlock); err != nil {
+		return common.Hash{}, err
+	}
+	defer func() { q.record(DataBlock, err) }()
+	return q.getBlockHashByNumber(number)
+}
+
+func (q Querier) getBlockHashByNumber(number uint64) (common.Hash, error) {
 	if !q.enabled() {
 		return common.Hash{}, errors.New(MsgFunctionDisable)
 	}
@@ -124,30 +210,27 @@ func (q Querier) GetBlockHashByNumber(number uint64) (common.Hash, error) {
 	return common.HexToHash(string(hash)), e
 }
 
-func (q Querier) GetBlockByNumber(number uint64, fullTx bool) (*EthBlock, error) {
-	if !q.enabled() {
-		return nil, errors.New(MsgFunctionDisable)
-	}
-	var height = number
-	var err error
-	if height == 0 {
-		height, err = q.GetLatestBlockNumber()
-		if err != nil {
-			return nil, err
-		}
-	}
-	hash, e := q.store.Get(append(prefixBlockInfo, []byte(strconv.Itoa(int(height)))...))
-	if e != nil {
-		return nil, e
+func (q Querier) GetBlockByNumber(number uint64, fullTx bool) (block *EthBlock, err error) {
+	if err = q.allow(DataBlock); err != nil {
+		return nil, err
 	}
-	if hash == nil {
-		return nil, errNotFound
+	defer func() { q.record(DataBlock, err) }()
+	hash, err := q.getBlockHashByNumber(number)
+	if err != nil {
+		return nil, err
 	}
+	return q.getBlockByHash(hash, fullTx)
+}
 
-	return q.GetBlockByHash(common.HexToHash(string(hash)), fullTx)
+func (q Querier) GetCode(contractAddr common.Address, height uint64) (code []byte, err error) {
+	if err = q.allow(DataCode); err != nil {
+		return nil, err
+	}
+	defer func() { q.record(DataCode, err) }()
+	return q.getCode(contractAddr, height)
 }
 
-func (q Querier) GetCode(contractAddr common.Address, height uint64) ([]byte, error) {
+func (q Querier) getCode(contractAddr common.Address, height uint64) ([]byte, error) {
 	if !q.enabled() {
 		return nil, errors.New(MsgFunctionDisable)
 	}
@@ -167,7 +250,14 @@ func (q Querier) GetCode(contractAddr common.Address, height uint64) ([]byte, er
 	if height < codeInfo.Height && height > 0 {
 		return nil, errors.New("the target height has not deploy this contract yet")
 	}
-	return hex.DecodeString(codeInfo.Code)
+	if codeInfo.CodeHash == (common.Hash{}) {
+		// fallback for the entries not migrated yet, see MigrateCodes
+		return hex.DecodeString(codeInfo.Code)
+	}
+	if codeInfo.CodeHash == emptyCodeHash {
+		return nil, nil
+	}
+	return q.GetCodeByHash(codeInfo.CodeHash.Bytes())
 }
 
 func (q Querier) GetCodeByHash(codeHash []byte) ([]byte, error) {
@@ -207,7 +297,15 @@ func (q Querier) GetLatestBlockNumber() (uint64, error) {
 	return uint64(h), e
 }
 
-func (q Querier) GetTransactionByHash(hash common.Hash) (*rpctypes.Transaction, error) {
+func (q Querier) GetTransactionByHash(hash common.Hash) (tx *rpctypes.Transaction, err error) {
+	if err = q.allow(DataTx); err != nil {
+		return nil, err
+	}
+	defer func() { q.record(DataTx, err) }()
+	return q.getTransactionByHash(hash)
+}
+
+func (q Querier) getTransactionByHash(hash common.Hash) (*rpctypes.Transaction, error) {
 	if !q.enabled() {
 		return nil, errors.New(MsgFunctionDisable)
 	}
@@ -285,8 +383,12 @@ func (q Querier) GetTransactionsByBlockNumber(number, offset, limit uint64) ([]*
 	return txs, nil
 }
 
-func (q Querier) MustGetAccount(addr sdk.AccAddress) (*types.EthAccount, error) {
-	acc, e := q.GetAccount(addr)
+func (q Querier) MustGetAccount(addr sdk.AccAddress) (acc *types.EthAccount, e error) {
+	if e = q.allow(DataAccount); e != nil {
+		return nil, e
+	}
+	defer func() { q.record(DataAccount, e) }()
+	acc, e = q.GetAccount(addr)
 	//todo delete account from rdb if we get Account from H db successfully
 	if e != nil {
 		acc, e = q.GetAccountFromRdb(addr)
@@ -343,14 +445,18 @@ func (q Querier) DeleteAccountFromRdb(addr sdk.AccAddress) {
 	q.store.Delete(append(prefixRpcDb, GetMsgAccountKey(addr.Bytes())...))
 }
 
-func (q Querier) MustGetState(addr common.Address, key []byte) ([]byte, error) {
+func (q Querier) MustGetState(addr common.Address, key []byte) (b []byte, e error) {
 	orgKey := GetMsgStateKey(addr, key)
 	realKey := common.BytesToHash(orgKey)
 	data := state.GetStateFromLru(realKey)
 	if data != nil {
 		return data, nil
 	}
-	b, e := q.GetState(orgKey)
+	if e = q.allow(DataState); e != nil {
+		return nil, e
+	}
+	defer func() { q.record(DataState, e) }()
+	b, e = q.GetState(orgKey)
 	if e != nil {
 		b, e = q.GetStateFromRdb(orgKey)
 	} else {
@@ -436,3 +542,22 @@ func (q Querier) HasContractDeploymentWhitelist(key []byte) bool {
 	}
 	return q.store.Has(append(prefixWhiteList, key...))
 }
+
+func (q Querier) GetContractLifecycle(addr common.Address) (*ContractLifecycle, error) {
+	if !q.enabled() {
+		return nil, errors.New(MsgFunctionDisable)
+	}
+	b, e := q.store.Get(append(prefixContractLifecycle, addr.Bytes()...))
+	if e != nil {
+		return nil, e
+	}
+	if b == nil {
+		return nil, errNotFound
+	}
+	var lifecycle ContractLifecycle
+	e = json.Unmarshal(b, &lifecycle)
+	if e != nil {
+		return nil, e
+	}
+	return &lifecycle, nil
+}
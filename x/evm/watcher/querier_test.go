@@ -0,0 +1,48 @@
+package watcher
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/okex/exchain/x/evm/types"
+)
+
+func TestGetBlockLogs(t *testing.T) {
+	db := dbm.NewMemDB()
+	q := Querier{store: &WatchStore{db: db}, sw: true}
+	to := common.HexToAddress("0x01")
+	tx := types.NewMsgEthereumTx(0, &to, big.NewInt(0), 21000, big.NewInt(1), nil)
+	blockHash := common.HexToHash("0xb0")
+
+	// the txs are stored in the order of the block
+	txHashes := []common.Hash{common.HexToHash("0x02"), common.HexToHash("0x01")}
+	batch := []WatchMessage{
+		NewMsgTransactionReceipt(TransactionSuccess, &tx, txHashes[0], blockHash, 0, 1, newLogs(1), 0, 0),
+		NewMsgTransactionReceipt(TransactionSuccess, &tx, txHashes[1], blockHash, 1, 1, newLogs(2), 0, 0),
+		NewMsgLogIndexed(blockHash),
+	}
+	assignLogIndices(batch)
+	for _, msg := range batch {
+		require.NoError(t, db.Set(msg.GetKey(), []byte(msg.GetValue())))
+	}
+	block, err := json.Marshal(EthBlock{Hash: blockHash, Transactions: txHashes})
+	require.NoError(t, err)
+	require.NoError(t, db.Set(append(prefixBlock, blockHash.Bytes()...), block))
+
+	blockLogs, err := q.GetBlockLogs(blockHash)
+	require.NoError(t, err)
+	require.Len(t, blockLogs, 2)
+	require.Len(t, blockLogs[0], 1)
+	require.Equal(t, txHashes[0], blockLogs[0][0].TxHash)
+	require.Equal(t, uint(0), blockLogs[0][0].Index)
+	require.Len(t, blockLogs[1], 2)
+	require.Equal(t, uint(2), blockLogs[1][1].Index)
+
+	_, err = q.GetBlockLogs(common.HexToHash("0xb1"))
+	require.Error(t, err)
+}
@@ -0,0 +1,86 @@
+package watcher
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/okex/exchain/libs/tendermint/abci/types"
+	evmtypes "github.com/okex/exchain/x/evm/types"
+	dbm "github.com/tendermint/tm-db"
+)
+
+// RebuiltTx is an evm tx of a block written again into the watcher db, with the result of its delivery
+type RebuiltTx struct {
+	Msg     evmtypes.MsgEthereumTx
+	Hash    common.Hash
+	Failed  bool
+	Data    evmtypes.ResultData
+	GasUsed uint64
+}
+
+// RebuiltBlock is a block committed by the chain, written again into the watcher db
+type RebuiltBlock struct {
+	Header   types.Header
+	Hash     common.Hash
+	Txs      []RebuiltTx
+	GasLimit uint64
+	BaseFee  *big.Int
+	Miner    common.Address
+}
+
+// Rebuild writes the blocks from the height following the latest one of the watcher db up to the given height,
+// loaded one by one. Each block is written with its txs, receipts, address txs and log stats the way the evm module
+// writes a delivered block, then its height is set as the latest one, so that an interrupted rebuild can be run
+// again. The contract codes, lifecycles and balance history of the blocks aren't indexed again. The accounts and the
+// storage slots are only kept at their latest values, they are all removed so that the rpc reads them from the
+// chain again. The db must not be written by a node meanwhile.
+func Rebuild(db dbm.DB, height uint64, load func(height uint64) (*RebuiltBlock, error)) error {
+	latest, err := GetLatestHeight(db)
+	if err != nil {
+		return err
+	}
+	if latest >= height {
+		return nil
+	}
+
+	batch := db.NewBatch()
+	defer batch.Close()
+	for _, prefix := range [][]byte{prefixAccount, PrefixState, prefixRpcDb} {
+		if err := deletePrefix(db, batch, prefix); err != nil {
+			return err
+		}
+	}
+	if err := batch.WriteSync(); err != nil {
+		return err
+	}
+
+	for h := latest + 1; h <= height; h++ {
+		block, err := load(h)
+		if err != nil {
+			return fmt.Errorf("failed to load block %d: %w", h, err)
+		}
+		rebuildBlock(db, block)
+	}
+	return nil
+}
+
+func rebuildBlock(db dbm.DB, block *RebuiltBlock) {
+	w := &Watcher{store: &WatchStore{db: db}, sw: true, watchData: &WatchData{}}
+	w.NewHeight(uint64(block.Header.Height), block.Hash, block.Header)
+
+	bloom := new(big.Int)
+	for i, tx := range block.Txs {
+		w.SaveEthereumTx(tx.Msg, tx.Hash, uint64(i))
+		if tx.Failed {
+			w.SaveTransactionReceipt(TransactionFailed, tx.Msg, tx.Hash, uint64(i), &evmtypes.ResultData{}, tx.GasUsed)
+			continue
+		}
+		data := tx.Data
+		w.SaveTransactionReceipt(TransactionSuccess, tx.Msg, tx.Hash, uint64(i), &data, tx.GasUsed)
+		bloom.Or(bloom, data.Bloom.Big())
+	}
+	w.SaveBlock(ethtypes.BytesToBloom(bloom.Bytes()), block.GasLimit, block.BaseFee, block.Miner)
+	w.commitBatch(w.blockBatch())
+}
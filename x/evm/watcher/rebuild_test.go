@@ -0,0 +1,103 @@
+package watcher
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	abci "github.com/okex/exchain/libs/tendermint/abci/types"
+	"github.com/okex/exchain/x/evm/types"
+)
+
+func TestRebuild(t *testing.T) {
+	db := dbm.NewMemDB()
+	cache, err := lru.New(10)
+	require.NoError(t, err)
+	q := Querier{store: &WatchStore{db: db}, sw: true, lru: cache}
+	to := common.HexToAddress("0x02")
+	contract := common.HexToAddress("0x03")
+	tx := types.NewMsgEthereumTx(0, &to, big.NewInt(0), 21000, big.NewInt(1), nil)
+
+	// every block has a tx with two logs and a failed tx
+	var loaded []uint64
+	load := func(height uint64) (*RebuiltBlock, error) {
+		loaded = append(loaded, height)
+		data := newLogs(2)
+		data.Logs[1].Address = contract
+		data.Bloom = ethtypes.BytesToBloom(ethtypes.LogsBloom(data.Logs))
+		return &RebuiltBlock{
+			Header: abci.Header{Height: int64(height), Time: time.Unix(1000, 0)},
+			Hash:   common.BigToHash(new(big.Int).SetUint64(height)),
+			Txs: []RebuiltTx{
+				{Msg: tx, Hash: common.BigToHash(new(big.Int).SetUint64(height + 100)), Data: *data, GasUsed: 30000},
+				{Msg: tx, Hash: common.BigToHash(new(big.Int).SetUint64(height + 200)), Failed: true, GasUsed: 21000},
+			},
+			GasLimit: 30000000,
+			BaseFee:  big.NewInt(7),
+			Miner:    to,
+		}, nil
+	}
+
+	// the db has no latest height to rebuild from
+	require.Error(t, Rebuild(db, 3, load))
+
+	state := NewMsgState(contract, []byte{0x01}, []byte{0x02})
+	for _, msg := range []WatchMessage{NewMsgLatestHeight(1), state} {
+		require.NoError(t, db.Set(msg.GetKey(), []byte(msg.GetValue())))
+	}
+	require.NoError(t, Rebuild(db, 3, load))
+	require.Equal(t, []uint64{2, 3}, loaded)
+
+	latest, err := GetLatestHeight(db)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), latest)
+	// the state of the db was the one of height 1, it is read from the chain again
+	has, err := db.Has(state.GetKey())
+	require.NoError(t, err)
+	require.False(t, has)
+
+	for height := uint64(2); height <= 3; height++ {
+		block, err := q.GetBlockByNumber(height, false)
+		require.NoError(t, err)
+		require.Equal(t, common.BigToHash(new(big.Int).SetUint64(height)), block.Hash)
+		require.Len(t, block.Transactions, 2)
+		require.Equal(t, uint64(51000), block.GasUsed.ToInt().Uint64())
+		require.Equal(t, uint64(30000000), uint64(block.GasLimit))
+		require.True(t, ethtypes.BloomLookup(block.LogsBloom, contract))
+
+		receipt, err := q.GetTransactionReceipt(common.BigToHash(new(big.Int).SetUint64(height + 100)))
+		require.NoError(t, err)
+		require.Equal(t, uint64(TransactionSuccess), uint64(receipt.Status))
+		require.Len(t, receipt.Logs, 2)
+		require.Equal(t, uint(1), receipt.Logs[1].Index)
+		failed, err := q.GetTransactionReceipt(common.BigToHash(new(big.Int).SetUint64(height + 200)))
+		require.NoError(t, err)
+		require.Equal(t, uint64(TransactionFailed), uint64(failed.Status))
+		require.Equal(t, uint64(51000), uint64(failed.CumulativeGasUsed))
+		require.Empty(t, failed.Logs)
+	}
+
+	// a db up to date isn't rebuilt
+	loaded = nil
+	require.NoError(t, Rebuild(db, 3, load))
+	require.Empty(t, loaded)
+
+	// a block missing from the chain stops the rebuild at the previous one
+	err = Rebuild(db, 5, func(height uint64) (*RebuiltBlock, error) {
+		if height == 5 {
+			return nil, errors.New("not found")
+		}
+		return load(height)
+	})
+	require.Error(t, err)
+	latest, err = GetLatestHeight(db)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), latest)
+}
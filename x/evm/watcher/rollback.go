@@ -0,0 +1,185 @@
+package watcher
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	dbm "github.com/tendermint/tm-db"
+)
+
+// GetLatestHeight returns the latest height written into the watcher db
+func GetLatestHeight(db dbm.DB) (uint64, error) {
+	bz, err := db.Get(NewMsgLatestHeight(0).GetKey())
+	if err != nil {
+		return 0, err
+	}
+	if bz == nil {
+		return 0, errors.New("the watcher db has no latest height")
+	}
+	height, err := strconv.ParseUint(string(bz), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid latest height of the watcher db: %w", err)
+	}
+	return height, nil
+}
+
+// Rollback removes the blocks above the given height from the watcher db, with their txs, receipts and log
+// indices, and the contract codes, lifecycles, address txs, log stats and balances written after it, then sets the
+// height as the latest one. The accounts and the storage slots are only kept at their latest values, they are
+// all removed so that the rpc reads them from the chain again. It returns the number of blocks removed. The db
+// must not be written by a node meanwhile.
+func Rollback(db dbm.DB, height uint64) (int, error) {
+	latest, err := GetLatestHeight(db)
+	if err != nil {
+		return 0, err
+	}
+
+	batch := db.NewBatch()
+	defer batch.Close()
+
+	removed := 0
+	for h := latest; h > height; h-- {
+		ok, err := rollbackBlock(db, batch, h)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			removed++
+		}
+	}
+	if err := rollbackCodes(db, batch, height); err != nil {
+		return 0, err
+	}
+	if err := rollbackContractLifecycles(db, batch, height); err != nil {
+		return 0, err
+	}
+	if err := rollbackAddressTxs(db, batch, height); err != nil {
+		return 0, err
+	}
+	if err := rollbackLogStats(db, batch, height); err != nil {
+		return 0, err
+	}
+	if err := rollbackBalanceHistory(db, batch, height); err != nil {
+		return 0, err
+	}
+	for _, prefix := range [][]byte{prefixAccount, PrefixState, prefixRpcDb} {
+		if err := deletePrefix(db, batch, prefix); err != nil {
+			return 0, err
+		}
+	}
+	if latest > height {
+		latestHeight := NewMsgLatestHeight(height)
+		batch.Set(latestHeight.GetKey(), []byte(latestHeight.GetValue()))
+	}
+	return removed, batch.WriteSync()
+}
+
+// rollbackBlock removes the block at the height with its txs, it returns false if the block isn't found
+func rollbackBlock(db dbm.DB, batch dbm.Batch, height uint64) (bool, error) {
+	blockInfoKey := NewMsgBlockInfo(height, common.Hash{}).GetKey()
+	hash, err := db.Get(blockInfoKey)
+	if err != nil || hash == nil {
+		return false, err
+	}
+	blockHash := common.HexToHash(string(hash))
+	blockKey := append(prefixBlock, blockHash.Bytes()...)
+	bz, err := db.Get(blockKey)
+	if err != nil {
+		return false, err
+	}
+
+	if bz != nil {
+		var block struct {
+			Transactions []common.Hash `json:"transactions"`
+		}
+		if err := json.Unmarshal(bz, &block); err != nil {
+			return false, fmt.Errorf("invalid block %s at height %d: %w", blockHash, height, err)
+		}
+		for _, txHash := range block.Transactions {
+			batch.Delete(append(prefixTx, txHash.Bytes()...))
+			batch.Delete(append(prefixReceipt, txHash.Bytes()...))
+		}
+	}
+	batch.Delete(blockKey)
+	batch.Delete(NewMsgLogIndexed(blockHash).GetKey())
+	batch.Delete(blockInfoKey)
+	return true, nil
+}
+
+// rollbackCodes removes the references to the codes of the contracts deployed above the height, the codes
+// stored by hash may be shared with other contracts and are kept
+func rollbackCodes(db dbm.DB, batch dbm.Batch, height uint64) error {
+	return iteratePrefix(db, prefixCode, func(key, value []byte) error {
+		var codeInfo CodeInfo
+		if err := json.Unmarshal(value, &codeInfo); err != nil {
+			return err
+		}
+		if codeInfo.Height > height {
+			batch.Delete(key)
+		}
+		return nil
+	})
+}
+
+// rollbackContractLifecycles removes the self-destructs and the redeployments audited above the height
+func rollbackContractLifecycles(db dbm.DB, batch dbm.Batch, height uint64) error {
+	return iteratePrefix(db, prefixContractLifecycle, func(key, value []byte) error {
+		var lifecycle ContractLifecycle
+		if err := json.Unmarshal(value, &lifecycle); err != nil {
+			return err
+		}
+		changed := false
+		if uint64(lifecycle.DestructedBlockNumber) > height {
+			lifecycle.DestructedBlockNumber = 0
+			changed = true
+		}
+		redeployments := lifecycle.Redeployments[:0]
+		for _, redeploy := range lifecycle.Redeployments {
+			if uint64(redeploy.BlockNumber) > height {
+				changed = true
+				// the redeployed contract was self-destructed before the height
+				if uint64(redeploy.DestructedBlockNumber) <= height {
+					lifecycle.DestructedBlockNumber = hexutil.Uint64(redeploy.DestructedBlockNumber)
+				}
+				continue
+			}
+			redeployments = append(redeployments, redeploy)
+		}
+		lifecycle.Redeployments = redeployments
+
+		switch {
+		case !changed:
+		case lifecycle.DestructedBlockNumber == 0 && len(lifecycle.Redeployments) == 0:
+			batch.Delete(key)
+		default:
+			addr := common.BytesToAddress(key[len(prefixContractLifecycle):])
+			batch.Set(key, []byte(NewMsgContractLifecycle(addr, &lifecycle).GetValue()))
+		}
+		return nil
+	})
+}
+
+func deletePrefix(db dbm.DB, batch dbm.Batch, prefix []byte) error {
+	return iteratePrefix(db, prefix, func(key, _ []byte) error {
+		batch.Delete(key)
+		return nil
+	})
+}
+
+func iteratePrefix(db dbm.DB, prefix []byte, fn func(key, value []byte) error) error {
+	it, err := dbm.IteratePrefix(db, prefix)
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	for ; it.Valid(); it.Next() {
+		if err := fn(append([]byte{}, it.Key()...), it.Value()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package watcher
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	abci "github.com/okex/exchain/libs/tendermint/abci/types"
+	"github.com/okex/exchain/x/evm/types"
+)
+
+func TestRollback(t *testing.T) {
+	db := dbm.NewMemDB()
+	set := func(msgs ...WatchMessage) {
+		for _, msg := range msgs {
+			require.NoError(t, db.Set(msg.GetKey(), []byte(msg.GetValue())))
+		}
+	}
+	has := func(key []byte) bool {
+		ok, err := db.Has(key)
+		require.NoError(t, err)
+		return ok
+	}
+
+	to := common.HexToAddress("0x02")
+	tx := types.NewMsgEthereumTx(0, &to, big.NewInt(0), 21000, big.NewInt(1), nil)
+	contract := common.HexToAddress("0x03")
+	var receipts, blocks []WatchMessage
+	for height := uint64(1); height <= 3; height++ {
+		blockHash := common.BigToHash(new(big.Int).SetUint64(height))
+		txHash := common.BigToHash(new(big.Int).SetUint64(height + 100))
+		header := abci.Header{Height: int64(height), Time: time.Unix(1000, 0)}
+		block := NewMsgBlock(height, ethtypes.Bloom{}, blockHash, header, 30000000, big.NewInt(21000), []common.Hash{txHash}, big.NewInt(7), to)
+		receipt := NewMsgTransactionReceipt(TransactionSuccess, &tx, txHash, blockHash, 0, height, newLogs(1), 0, 0)
+		require.NoError(t, db.Set(append(prefixTx, txHash.Bytes()...), []byte("{}")))
+		set(block, NewMsgBlockInfo(height, blockHash), receipt,
+			NewMsgLogIndexed(blockHash), NewMsgCode(common.BigToAddress(new(big.Int).SetUint64(height)), []byte{0x01}, height))
+		receipts, blocks = append(receipts, receipt), append(blocks, block)
+	}
+	set(NewMsgLatestHeight(3), NewMsgState(contract, []byte{0x01}, []byte{0x02}), NewMsgContractLifecycle(contract, &ContractLifecycle{
+		Redeployments: []ContractRedeploy{
+			{BlockNumber: 1, DestructedBlockNumber: 1},
+			{BlockNumber: 3, DestructedBlockNumber: 2},
+		},
+	}))
+
+	removed, err := Rollback(db, 1)
+	require.NoError(t, err)
+	require.Equal(t, 2, removed)
+	latest, err := GetLatestHeight(db)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), latest)
+
+	// the blocks above the height are removed with their txs and codes
+	require.True(t, has(blocks[0].GetKey()))
+	require.True(t, has(receipts[0].GetKey()))
+	require.True(t, has(append(prefixTx, common.BigToHash(big.NewInt(101)).Bytes()...)))
+	require.True(t, has(NewMsgCode(common.BigToAddress(big.NewInt(1)), nil, 0).GetKey()))
+	for i := 1; i < 3; i++ {
+		require.False(t, has(blocks[i].GetKey()))
+		require.False(t, has(receipts[i].GetKey()))
+		require.False(t, has(append(prefixTx, common.BigToHash(big.NewInt(int64(i+101))).Bytes()...)))
+		require.False(t, has(NewMsgCode(common.BigToAddress(big.NewInt(int64(i+1))), nil, 0).GetKey()))
+	}
+	// the state is read from the chain again
+	require.False(t, has(NewMsgState(contract, []byte{0x01}, nil).GetKey()))
+
+	// the contract was self-destructed at height 2 and redeployed at height 3
+	value, err := db.Get(NewMsgContractLifecycle(contract, nil).GetKey())
+	require.NoError(t, err)
+	var lifecycle ContractLifecycle
+	require.NoError(t, json.Unmarshal(value, &lifecycle))
+	require.Len(t, lifecycle.Redeployments, 1)
+	require.Equal(t, hexutil.Uint64(0), lifecycle.DestructedBlockNumber)
+
+	// the rollback can be rerun
+	removed, err = Rollback(db, 1)
+	require.NoError(t, err)
+	require.Zero(t, removed)
+}
@@ -0,0 +1,309 @@
+package watcher
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+
+	dbm "github.com/tendermint/tm-db"
+)
+
+const (
+	snapshotFormat    = uint32(1)
+	snapshotBatchSize = 10000
+
+	// bounds the allocation of a key or value read from a corrupted snapshot
+	maxSnapshotEntrySize = 1 << 30
+)
+
+var snapshotMagic = []byte("OKWATCH")
+
+// SnapshotInfo describes a snapshot of the watcher db
+type SnapshotInfo struct {
+	Format   uint32 `json:"format"`
+	Height   uint64 `json:"height"`
+	Entries  uint64 `json:"entries"`
+	Checksum string `json:"checksum"`
+}
+
+func (si SnapshotInfo) String() string {
+	return fmt.Sprintf("format: %d, height: %d, entries: %d, checksum: %s", si.Format, si.Height, si.Entries, si.Checksum)
+}
+
+// A snapshot is a gzip stream of:
+//   magic | format (uint32) | height (uint64) | entries | end of entries | count (uint64) | sha256
+// where each entry is uvarint(len(key)) | key | uvarint(len(value)) | value, the entries end with an empty
+// key, and the sha256 is the one of all the bytes before it.
+
+// CreateSnapshot writes a point-in-time archive of the watcher db into w. The db must not be written while
+// the snapshot is taken, i.e. the node is stopped.
+func CreateSnapshot(db dbm.DB, w io.Writer) (SnapshotInfo, error) {
+	info := SnapshotInfo{Format: snapshotFormat}
+
+	bz, err := db.Get(append(prefixLatestHeight, KeyLatestHeight...))
+	if err != nil {
+		return info, err
+	}
+	if bz == nil {
+		return info, errors.New("the watcher db has no latest height")
+	}
+	height, err := strconv.ParseUint(string(bz), 10, 64)
+	if err != nil {
+		return info, fmt.Errorf("invalid latest height of the watcher db: %w", err)
+	}
+	info.Height = height
+
+	zw := gzip.NewWriter(w)
+	sw := newSnapshotWriter(zw)
+	sw.write(snapshotMagic)
+	sw.writeUint32(info.Format)
+	sw.writeUint64(info.Height)
+
+	it, err := db.Iterator(nil, nil)
+	if err != nil {
+		return info, err
+	}
+	for ; it.Valid(); it.Next() {
+		sw.writeBytes(it.Key())
+		sw.writeBytes(it.Value())
+		info.Entries++
+	}
+	it.Close()
+	// the empty key ending the entries
+	sw.writeUvarint(0)
+	sw.writeUint64(info.Entries)
+
+	sum := sw.sum()
+	info.Checksum = fmt.Sprintf("%x", sum)
+	if _, err := sw.w.Write(sum); err != nil {
+		return info, err
+	}
+	if err := sw.flush(); err != nil {
+		return info, err
+	}
+	return info, zw.Close()
+}
+
+// VerifySnapshot reads the snapshot from r and checks its checksum, without restoring it
+func VerifySnapshot(r io.Reader) (SnapshotInfo, error) {
+	return readSnapshot(r, func(key, value []byte) error { return nil })
+}
+
+// RestoreSnapshot writes the entries of the snapshot from r into db, which must be empty. The snapshot is
+// expected to be verified beforehand, a checksum mismatch found here leaves db partially written.
+func RestoreSnapshot(db dbm.DB, r io.Reader) (SnapshotInfo, error) {
+	it, err := db.Iterator(nil, nil)
+	if err != nil {
+		return SnapshotInfo{}, err
+	}
+	empty := !it.Valid()
+	it.Close()
+	if !empty {
+		return SnapshotInfo{}, errors.New("the watcher db to restore into is not empty")
+	}
+
+	batch := db.NewBatch()
+	var count int
+	info, err := readSnapshot(r, func(key, value []byte) error {
+		batch.Set(key, value)
+		count++
+		if count%snapshotBatchSize == 0 {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Close()
+			batch = db.NewBatch()
+		}
+		return nil
+	})
+	defer batch.Close()
+	if err != nil {
+		return info, err
+	}
+	return info, batch.WriteSync()
+}
+
+func readSnapshot(r io.Reader, fn func(key, value []byte) error) (SnapshotInfo, error) {
+	var info SnapshotInfo
+
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return info, err
+	}
+	defer zr.Close()
+	sr := newSnapshotReader(zr)
+
+	magic := make([]byte, len(snapshotMagic))
+	if err := sr.read(magic); err != nil {
+		return info, err
+	}
+	if !bytes.Equal(magic, snapshotMagic) {
+		return info, errors.New("not a watcher db snapshot")
+	}
+	if info.Format, err = sr.readUint32(); err != nil {
+		return info, err
+	}
+	if info.Format != snapshotFormat {
+		return info, fmt.Errorf("unsupported snapshot format %d", info.Format)
+	}
+	if info.Height, err = sr.readUint64(); err != nil {
+		return info, err
+	}
+
+	for {
+		key, err := sr.readBytes()
+		if err != nil {
+			return info, err
+		}
+		if len(key) == 0 {
+			break
+		}
+		value, err := sr.readBytes()
+		if err != nil {
+			return info, err
+		}
+		if err := fn(key, value); err != nil {
+			return info, err
+		}
+		info.Entries++
+	}
+
+	count, err := sr.readUint64()
+	if err != nil {
+		return info, err
+	}
+	if count != info.Entries {
+		return info, fmt.Errorf("snapshot has %d entries, expected %d", info.Entries, count)
+	}
+
+	expected := sr.sum()
+	sum := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(sr.r, sum); err != nil {
+		return info, err
+	}
+	info.Checksum = fmt.Sprintf("%x", sum)
+	if !bytes.Equal(sum, expected) {
+		return info, fmt.Errorf("snapshot checksum mismatch, computed %x, recorded %x", expected, sum)
+	}
+	return info, nil
+}
+
+// snapshotWriter writes the snapshot while hashing it, the first error is kept and returned by flush
+type snapshotWriter struct {
+	w   *bufio.Writer
+	h   hash.Hash
+	buf [binary.MaxVarintLen64]byte
+	err error
+}
+
+func newSnapshotWriter(w io.Writer) *snapshotWriter {
+	return &snapshotWriter{w: bufio.NewWriter(w), h: sha256.New()}
+}
+
+func (sw *snapshotWriter) write(bz []byte) {
+	if sw.err != nil {
+		return
+	}
+	sw.h.Write(bz)
+	_, sw.err = sw.w.Write(bz)
+}
+
+func (sw *snapshotWriter) writeUvarint(x uint64) {
+	n := binary.PutUvarint(sw.buf[:], x)
+	sw.write(sw.buf[:n])
+}
+
+func (sw *snapshotWriter) writeUint32(x uint32) {
+	binary.BigEndian.PutUint32(sw.buf[:4], x)
+	sw.write(sw.buf[:4])
+}
+
+func (sw *snapshotWriter) writeUint64(x uint64) {
+	binary.BigEndian.PutUint64(sw.buf[:8], x)
+	sw.write(sw.buf[:8])
+}
+
+func (sw *snapshotWriter) writeBytes(bz []byte) {
+	sw.writeUvarint(uint64(len(bz)))
+	sw.write(bz)
+}
+
+func (sw *snapshotWriter) sum() []byte {
+	return sw.h.Sum(nil)
+}
+
+func (sw *snapshotWriter) flush() error {
+	if sw.err != nil {
+		return sw.err
+	}
+	return sw.w.Flush()
+}
+
+// snapshotReader reads the snapshot while hashing it
+type snapshotReader struct {
+	r *bufio.Reader
+	h hash.Hash
+}
+
+func newSnapshotReader(r io.Reader) *snapshotReader {
+	return &snapshotReader{r: bufio.NewReader(r), h: sha256.New()}
+}
+
+func (sr *snapshotReader) read(bz []byte) error {
+	if _, err := io.ReadFull(sr.r, bz); err != nil {
+		return fmt.Errorf("truncated snapshot: %w", err)
+	}
+	sr.h.Write(bz)
+	return nil
+}
+
+func (sr *snapshotReader) ReadByte() (byte, error) {
+	b, err := sr.r.ReadByte()
+	if err != nil {
+		return 0, fmt.Errorf("truncated snapshot: %w", err)
+	}
+	sr.h.Write([]byte{b})
+	return b, nil
+}
+
+func (sr *snapshotReader) readUint32() (uint32, error) {
+	bz := make([]byte, 4)
+	if err := sr.read(bz); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(bz), nil
+}
+
+func (sr *snapshotReader) readUint64() (uint64, error) {
+	bz := make([]byte, 8)
+	if err := sr.read(bz); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(bz), nil
+}
+
+func (sr *snapshotReader) readBytes() ([]byte, error) {
+	n, err := binary.ReadUvarint(sr)
+	if err != nil {
+		return nil, err
+	}
+	if n > maxSnapshotEntrySize {
+		return nil, fmt.Errorf("corrupted snapshot: entry of %d bytes", n)
+	}
+	bz := make([]byte, n)
+	if err := sr.read(bz); err != nil {
+		return nil, err
+	}
+	return bz, nil
+}
+
+func (sr *snapshotReader) sum() []byte {
+	return sr.h.Sum(nil)
+}
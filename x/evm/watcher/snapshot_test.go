@@ -0,0 +1,65 @@
+package watcher
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+)
+
+func TestSnapshot(t *testing.T) {
+	db := dbm.NewMemDB()
+	_, err := CreateSnapshot(db, &bytes.Buffer{})
+	require.Error(t, err, "no latest height")
+
+	for i := 0; i < 100; i++ {
+		require.NoError(t, db.Set(append(prefixTx, []byte(fmt.Sprintf("tx%d", i))...), []byte(fmt.Sprintf("value%d", i))))
+	}
+	latestHeight := NewMsgLatestHeight(42)
+	require.NoError(t, db.Set(latestHeight.GetKey(), []byte(latestHeight.GetValue())))
+
+	var buf bytes.Buffer
+	info, err := CreateSnapshot(db, &buf)
+	require.NoError(t, err)
+	require.Equal(t, uint64(42), info.Height)
+	require.Equal(t, uint64(101), info.Entries)
+	snapshot := buf.Bytes()
+
+	verified, err := VerifySnapshot(bytes.NewReader(snapshot))
+	require.NoError(t, err)
+	require.Equal(t, info, verified)
+
+	restored := dbm.NewMemDB()
+	_, err = RestoreSnapshot(restored, bytes.NewReader(snapshot))
+	require.NoError(t, err)
+	it, err := db.Iterator(nil, nil)
+	require.NoError(t, err)
+	for ; it.Valid(); it.Next() {
+		value, err := restored.Get(it.Key())
+		require.NoError(t, err)
+		require.Equal(t, it.Value(), value)
+	}
+	it.Close()
+
+	// the db to restore into must be empty
+	_, err = RestoreSnapshot(restored, bytes.NewReader(snapshot))
+	require.Error(t, err)
+
+	// a snapshot with a tampered checksum is rejected
+	zr, err := gzip.NewReader(bytes.NewReader(snapshot))
+	require.NoError(t, err)
+	raw, err := ioutil.ReadAll(zr)
+	require.NoError(t, err)
+	raw[len(raw)-1] ^= 0xff
+	var tampered bytes.Buffer
+	zw := gzip.NewWriter(&tampered)
+	_, err = zw.Write(raw)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	_, err = VerifySnapshot(&tampered)
+	require.Error(t, err)
+}
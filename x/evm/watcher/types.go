@@ -18,7 +18,6 @@ import (
 	"github.com/okex/exchain/libs/cosmos-sdk/x/auth"
 	abci "github.com/okex/exchain/libs/tendermint/abci/types"
 	"github.com/okex/exchain/x/evm/types"
-	"github.com/status-im/keycard-go/hexutils"
 )
 
 var (
@@ -36,6 +35,13 @@ var (
 	prefixBlackList    = []byte{0x12}
 	prefixRpcDb        = []byte{0x13}
 
+	prefixContractLifecycle = []byte{0x14}
+	prefixLogIndexed        = []byte{0x15}
+	prefixAddressTx         = []byte{0x16}
+	prefixLogStats          = []byte{0x17}
+	prefixBridgedLogs       = []byte{0x18}
+	prefixBalanceHistory    = []byte{0x19}
+
 	KeyLatestHeight = "LatestHeight"
 
 	TransactionSuccess = uint32(1)
@@ -113,15 +119,18 @@ func (m MsgCode) GetType() uint32 {
 	return TypeOthers
 }
 
+// CodeInfo is stored per contract address. The code itself is stored once by code hash, see MsgCodeByHash, and
+// referenced by CodeHash. Code is only set by the entries written before the codes were deduplicated.
 type CodeInfo struct {
-	Height uint64 `json:"height"`
-	Code   string `json:"code"`
+	Height   uint64      `json:"height"`
+	Code     string      `json:"code,omitempty"`
+	CodeHash common.Hash `json:"code_hash"`
 }
 
-func NewMsgCode(contractAddr common.Address, code []byte, height uint64) *MsgCode {
+func NewMsgCode(contractAddr common.Address, codeHash []byte, height uint64) *MsgCode {
 	codeInfo := CodeInfo{
-		Height: height,
-		Code:   hexutils.BytesToHex(code),
+		Height:   height,
+		CodeHash: common.BytesToHash(codeHash),
 	}
 	jsCode, e := json.Marshal(codeInfo)
 	if e != nil {
@@ -167,7 +176,11 @@ func (m MsgCodeByHash) GetValue() string {
 
 type MsgTransactionReceipt struct {
 	txHash  []byte
-	receipt string
+	txIndex uint64
+	// the receipt is built on commit, so that it gets the log indices fixed after the parallel txs
+	newReceipt func() TransactionReceipt
+	// receipt is the receipt with the block-wide log indices, see assignLogIndices
+	receipt *TransactionReceipt
 }
 
 func (m MsgTransactionReceipt) GetType() uint32 {
@@ -189,33 +202,55 @@ type TransactionReceipt struct {
 	To                *common.Address `json:"to"`
 }
 
-func NewMsgTransactionReceipt(status uint32, tx *types.MsgEthereumTx, txHash, blockHash common.Hash, txIndex, height uint64, data *types.ResultData, cumulativeGas, GasUsed uint64) *MsgTransactionReceipt {
-
+// NewTransactionReceipt builds the receipt of an evm tx, it's used by the watcher and by the rpc when
+// the watcher misses the receipt, so that both return the same receipt.
+// The logs keep their block-relative index from the result data, and the contract address of a
+// contract creation is derived from the sender and its nonce when the creation failed.
+func NewTransactionReceipt(status uint32, tx *types.MsgEthereumTx, from common.Address, txHash, blockHash common.Hash,
+	txIndex, height uint64, data *types.ResultData, cumulativeGas, gasUsed uint64) TransactionReceipt {
 	tr := TransactionReceipt{
 		Status:            hexutil.Uint64(status),
 		CumulativeGasUsed: hexutil.Uint64(cumulativeGas),
 		LogsBloom:         data.Bloom,
-		Logs:              data.Logs,
+		Logs:              make([]*ethtypes.Log, len(data.Logs)),
 		TransactionHash:   txHash.String(),
-		ContractAddress:   &data.ContractAddress,
-		GasUsed:           hexutil.Uint64(GasUsed),
+		GasUsed:           hexutil.Uint64(gasUsed),
 		BlockHash:         blockHash.String(),
 		BlockNumber:       hexutil.Uint64(height),
 		TransactionIndex:  hexutil.Uint64(txIndex),
-		From:              common.BytesToAddress(tx.From().Bytes()).Hex(),
+		From:              from.Hex(),
 		To:                tx.To(),
 	}
 
-	//contract address will be set to 0x0000000000000000000000000000000000000000 if contract deploy failed
-	if tr.ContractAddress != nil && tr.ContractAddress.String() == "0x0000000000000000000000000000000000000000" {
-		//set to nil to keep sync with ethereum rpc
-		tr.ContractAddress = nil
+	// the logs are copied, as the ones of the result data are encoded in the tx result
+	for i, log := range data.Logs {
+		receiptLog := *log
+		receiptLog.TxHash = txHash
+		receiptLog.BlockHash = blockHash
+		receiptLog.BlockNumber = height
+		receiptLog.TxIndex = uint(txIndex)
+		tr.Logs[i] = &receiptLog
 	}
-	jsTr, e := json.Marshal(tr)
-	if e != nil {
-		return nil
+
+	if tx.To() == nil {
+		contractAddr := data.ContractAddress
+		if contractAddr == (common.Address{}) {
+			contractAddr = ethcrypto.CreateAddress(from, tx.Data.AccountNonce)
+		}
+		tr.ContractAddress = &contractAddr
+	}
+	return tr
+}
+
+func NewMsgTransactionReceipt(status uint32, tx *types.MsgEthereumTx, txHash, blockHash common.Hash, txIndex, height uint64, data *types.ResultData, cumulativeGas, GasUsed uint64) *MsgTransactionReceipt {
+	from := common.BytesToAddress(tx.From().Bytes())
+	return &MsgTransactionReceipt{
+		txHash:  txHash.Bytes(),
+		txIndex: txIndex,
+		newReceipt: func() TransactionReceipt {
+			return NewTransactionReceipt(status, tx, from, txHash, blockHash, txIndex, height, data, cumulativeGas, GasUsed)
+		},
 	}
-	return &MsgTransactionReceipt{txHash: txHash.Bytes(), receipt: string(jsTr)}
 }
 
 func (m MsgTransactionReceipt) GetKey() []byte {
@@ -223,7 +258,16 @@ func (m MsgTransactionReceipt) GetKey() []byte {
 }
 
 func (m MsgTransactionReceipt) GetValue() string {
-	return m.receipt
+	receipt := m.receipt
+	if receipt == nil {
+		tr := m.newReceipt()
+		receipt = &tr
+	}
+	jsTr, e := json.Marshal(receipt)
+	if e != nil {
+		return ""
+	}
+	return string(jsTr)
 }
 
 type MsgBlock struct {
@@ -283,9 +327,10 @@ type EthBlock struct {
 	Uncles           []common.Hash  `json:"uncles"`
 	ReceiptsRoot     common.Hash    `json:"receiptsRoot"`
 	Transactions     interface{}    `json:"transactions"`
+	BaseFeePerGas    *hexutil.Big   `json:"baseFeePerGas,omitempty"`
 }
 
-func NewMsgBlock(height uint64, blockBloom ethtypes.Bloom, blockHash common.Hash, header abci.Header, gasLimit uint64, gasUsed *big.Int, txs interface{}) *MsgBlock {
+func NewMsgBlock(height uint64, blockBloom ethtypes.Bloom, blockHash common.Hash, header abci.Header, gasLimit uint64, gasUsed *big.Int, txs interface{}, baseFee *big.Int, miner common.Address) *MsgBlock {
 	b := EthBlock{
 		Number:           hexutil.Uint64(height),
 		Hash:             blockHash,
@@ -295,7 +340,7 @@ func NewMsgBlock(height uint64, blockBloom ethtypes.Bloom, blockHash common.Hash
 		LogsBloom:        blockBloom,
 		TransactionsRoot: common.BytesToHash(header.DataHash),
 		StateRoot:        common.BytesToHash(header.AppHash),
-		Miner:            common.BytesToAddress(header.ProposerAddress),
+		Miner:            miner,
 		MixHash:          common.Hash{},
 		Difficulty:       0,
 		TotalDifficulty:  0,
@@ -307,6 +352,7 @@ func NewMsgBlock(height uint64, blockBloom ethtypes.Bloom, blockHash common.Hash
 		Uncles:           []common.Hash{},
 		ReceiptsRoot:     common.Hash{},
 		Transactions:     txs,
+		BaseFeePerGas:    (*hexutil.Big)(baseFee),
 	}
 	jsBlock, e := json.Marshal(b)
 	if e != nil {
@@ -530,3 +576,45 @@ func (msgItem *MsgContractMethodBlockedListItem) GetKey() []byte {
 func (msgItem *MsgContractMethodBlockedListItem) GetValue() string {
 	return string(msgItem.methods)
 }
+
+// ContractRedeploy records a contract deployed at the address of a self-destructed contract
+type ContractRedeploy struct {
+	TxHash                common.Hash    `json:"transactionHash"`
+	BlockNumber           hexutil.Uint64 `json:"blockNumber"`
+	DestructedBlockNumber hexutil.Uint64 `json:"destructedBlockNumber"`
+}
+
+// ContractLifecycle is the audited self-destruct and redeploy history of a contract address
+type ContractLifecycle struct {
+	// DestructedBlockNumber is the height of the last self-destruct not followed by a redeployment yet
+	DestructedBlockNumber hexutil.Uint64     `json:"destructedBlockNumber"`
+	Redeployments         []ContractRedeploy `json:"redeployments"`
+}
+
+type MsgContractLifecycle struct {
+	addr      common.Address
+	lifecycle *ContractLifecycle
+}
+
+func (msgItem *MsgContractLifecycle) GetType() uint32 {
+	return TypeOthers
+}
+
+func NewMsgContractLifecycle(addr common.Address, lifecycle *ContractLifecycle) *MsgContractLifecycle {
+	return &MsgContractLifecycle{
+		addr:      addr,
+		lifecycle: lifecycle,
+	}
+}
+
+func (msgItem *MsgContractLifecycle) GetKey() []byte {
+	return append(prefixContractLifecycle, msgItem.addr.Bytes()...)
+}
+
+func (msgItem *MsgContractLifecycle) GetValue() string {
+	jsonValue, err := json.Marshal(msgItem.lifecycle)
+	if err != nil {
+		panic(err)
+	}
+	return string(jsonValue)
+}
@@ -0,0 +1,64 @@
+package watcher
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/okex/exchain/x/evm/types"
+)
+
+func TestNewTransactionReceipt(t *testing.T) {
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	txHash := common.HexToHash("0x01")
+	blockHash := common.HexToHash("0x02")
+	data := &types.ResultData{
+		Logs: []*ethtypes.Log{{Address: from, Index: 7}, {Address: from, Index: 8}},
+	}
+
+	// the logs are filled with the tx and the block, and keep their block-relative index
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	tx := types.NewMsgEthereumTx(3, &to, big.NewInt(0), 21000, big.NewInt(1), nil)
+	receipt := NewTransactionReceipt(TransactionSuccess, &tx, from, txHash, blockHash, 2, 10, data, 42000, 21000)
+	require.Nil(t, receipt.ContractAddress)
+	require.Equal(t, &to, receipt.To)
+	require.Len(t, receipt.Logs, 2)
+	for i, log := range receipt.Logs {
+		require.Equal(t, uint(7+i), log.Index)
+		require.Equal(t, txHash, log.TxHash)
+		require.Equal(t, blockHash, log.BlockHash)
+		require.Equal(t, uint64(10), log.BlockNumber)
+		require.Equal(t, uint(2), log.TxIndex)
+	}
+	require.Equal(t, common.Hash{}, data.Logs[0].TxHash, "the result data is left untouched")
+
+	// a failed contract creation has no result data, its address is derived from the sender
+	create := types.NewMsgEthereumTxContract(3, big.NewInt(0), 100000, big.NewInt(1), []byte{0x60})
+	receipt = NewTransactionReceipt(TransactionFailed, &create, from, txHash, blockHash, 2, 10, &types.ResultData{}, 42000, 21000)
+	require.Equal(t, ethcrypto.CreateAddress(from, 3), *receipt.ContractAddress)
+	require.NotNil(t, receipt.Logs)
+	require.Empty(t, receipt.Logs)
+
+	contractAddr := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	receipt = NewTransactionReceipt(TransactionSuccess, &create, from, txHash, blockHash, 2, 10,
+		&types.ResultData{ContractAddress: contractAddr}, 42000, 21000)
+	require.Equal(t, contractAddr, *receipt.ContractAddress)
+}
+
+func TestMsgTransactionReceiptFixedLogs(t *testing.T) {
+	to := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	tx := types.NewMsgEthereumTx(0, &to, big.NewInt(0), 21000, big.NewInt(1), nil)
+	data := &types.ResultData{Logs: []*ethtypes.Log{{Address: to, Topics: []common.Hash{}, Index: 0}}}
+	msg := NewMsgTransactionReceipt(TransactionSuccess, &tx, common.HexToHash("0x01"), common.HexToHash("0x02"), 1, 10, data, 0, 0)
+
+	// the logs renumbered after the parallel execution are stored with their final index
+	data.Logs[0].Index = 5
+	var receipt TransactionReceipt
+	require.NoError(t, json.Unmarshal([]byte(msg.GetValue()), &receipt))
+	require.Equal(t, uint(5), receipt.Logs[0].Index)
+}
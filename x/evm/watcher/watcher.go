@@ -1,8 +1,11 @@
 package watcher
 
 import (
+	"bytes"
+
 	jsoniter "github.com/json-iterator/go"
 	"math/big"
+	"sort"
 	"sync"
 
 	"github.com/okex/exchain/app/rpc/namespaces/eth/state"
@@ -10,6 +13,7 @@ import (
 	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/okex/exchain/libs/cosmos-sdk/x/auth"
 	"github.com/okex/exchain/libs/tendermint/abci/types"
@@ -19,7 +23,11 @@ import (
 	"github.com/spf13/viper"
 )
 
-var itjs = jsoniter.ConfigCompatibleWithStandardLibrary
+var (
+	itjs = jsoniter.ConfigCompatibleWithStandardLibrary
+
+	latestHeightKey = append(prefixLatestHeight, KeyLatestHeight...)
+)
 
 type Watcher struct {
 	store         *WatchStore
@@ -34,8 +42,16 @@ type Watcher struct {
 	sw            bool
 	firstUse      bool
 	delayEraseKey [][]byte
+	// contract lifecycles touched in the current block, see AuditContractDeployment
+	lifecycles map[common.Address]*ContractLifecycle
+	// logs bridged from the cosmos events of the current block, see SaveBridgedLogs
+	bridgedLogs []*ethtypes.Log
+	// accounts updated in the current block, whose balance is indexed, see AddBalanceChange
+	balanceAddrs map[common.Address]struct{}
 	// for state delta transfering in network
 	watchData *WatchData
+	// writes the watch data of the committed blocks in the background
+	pipeline *commitPipeline
 }
 
 var (
@@ -68,7 +84,7 @@ func GetWatchLruSize() int {
 }
 
 func NewWatcher() *Watcher {
-	watcher := &Watcher{store: InstanceOfWatchStore(), sw: IsWatcherEnabled(), firstUse: true, delayEraseKey: make([][]byte, 0), watchData: &WatchData{}}
+	watcher := &Watcher{store: InstanceOfWatchStore(), sw: IsWatcherEnabled(), firstUse: true, delayEraseKey: make([][]byte, 0), watchData: &WatchData{}, pipeline: newCommitPipeline(viper.GetInt(FlagCommitQueueSize))}
 	return watcher
 }
 
@@ -99,6 +115,9 @@ func (w *Watcher) NewHeight(height uint64, blockHash common.Hash, header types.H
 	w.cumulativeGas = make(map[uint64]uint64)
 	w.gasUsed = 0
 	w.blockTxs = []common.Hash{}
+	w.lifecycles = make(map[common.Address]*ContractLifecycle)
+	w.bridgedLogs = nil
+	w.balanceAddrs = make(map[common.Address]struct{})
 
 	// ResetTransferWatchData
 	w.watchData = &WatchData{}
@@ -115,11 +134,12 @@ func (w *Watcher) SaveEthereumTx(msg evmtypes.MsgEthereumTx, txHash common.Hash,
 	w.UpdateBlockTxs(txHash)
 }
 
-func (w *Watcher) SaveContractCode(addr common.Address, code []byte) {
+// SaveContractCode references the code of the contract by its hash, the code is saved by SaveContractCodeByHash
+func (w *Watcher) SaveContractCode(addr common.Address, codeHash []byte) {
 	if !w.Enabled() {
 		return
 	}
-	wMsg := NewMsgCode(addr, code, w.height)
+	wMsg := NewMsgCode(addr, codeHash, w.height)
 	if wMsg != nil {
 		w.staleBatch = append(w.staleBatch, wMsg)
 	}
@@ -144,6 +164,7 @@ func (w *Watcher) SaveTransactionReceipt(status uint32, msg evmtypes.MsgEthereum
 	if wMsg != nil {
 		w.batch = append(w.batch, wMsg)
 	}
+	w.batch = append(w.batch, newMsgAddressTxs(&msg, txHash, w.height, txIndex, data)...)
 }
 
 func (w *Watcher) UpdateCumulativeGas(txIndex, gasUsed uint64) {
@@ -193,6 +214,36 @@ func (w *Watcher) AddDirtyAccount(addr *sdk.AccAddress) {
 	w.watchData.DirtyAccount = append(w.watchData.DirtyAccount, addr)
 }
 
+// AddBalanceChange marks the account as updated in the current block, so that its balance at the end of the
+// block is indexed with SaveBalance
+func (w *Watcher) AddBalanceChange(addr sdk.AccAddress) {
+	if !w.Enabled() || !IsBalanceHistoryEnabled() {
+		return
+	}
+	if w.balanceAddrs == nil {
+		w.balanceAddrs = make(map[common.Address]struct{})
+	}
+	w.balanceAddrs[common.BytesToAddress(addr)] = struct{}{}
+}
+
+// BalanceChangedAddrs returns the accounts updated in the current block, in the order of their address
+func (w *Watcher) BalanceChangedAddrs() []common.Address {
+	addrs := make([]common.Address, 0, len(w.balanceAddrs))
+	for addr := range w.balanceAddrs {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return bytes.Compare(addrs[i].Bytes(), addrs[j].Bytes()) < 0 })
+	return addrs
+}
+
+// SaveBalance indexes the native balance of the address at the end of the current block
+func (w *Watcher) SaveBalance(addr common.Address, balance *big.Int) {
+	if !w.Enabled() || !IsBalanceHistoryEnabled() {
+		return
+	}
+	w.batch = append(w.batch, NewMsgBalance(addr, w.height, balance))
+}
+
 func (w *Watcher) ExecuteDelayEraseKey() {
 	if !w.Enabled() {
 		return
@@ -216,11 +267,14 @@ func (w *Watcher) SaveState(addr common.Address, key, value []byte) {
 	}
 }
 
-func (w *Watcher) SaveBlock(bloom ethtypes.Bloom) {
+func (w *Watcher) SaveBlock(bloom ethtypes.Bloom, gasLimit uint64, baseFee *big.Int, miner common.Address) {
 	if !w.Enabled() {
 		return
 	}
-	wMsg := NewMsgBlock(w.height, bloom, w.blockHash, w.header, uint64(0xffffffff), big.NewInt(int64(w.gasUsed)), w.blockTxs)
+	// the mark is written before the block, so the rpc never fixes the log indices of a block being committed
+	w.batch = append(w.batch, NewMsgLogIndexed(w.blockHash))
+	bloom = w.addBridgedLogs(bloom)
+	wMsg := NewMsgBlock(w.height, bloom, w.blockHash, w.header, gasLimit, big.NewInt(int64(w.gasUsed)), w.blockTxs, baseFee, miner)
 	if wMsg != nil {
 		w.batch = append(w.batch, wMsg)
 	}
@@ -306,6 +360,58 @@ func (w *Watcher) DeleteContractDeploymentWhitelist(addr sdk.AccAddress) {
 	}
 }
 
+// SaveDestructedContract marks the contract as self-destructed at the current height,
+// so that a later deployment at the same address is reported as a redeployment.
+func (w *Watcher) SaveDestructedContract(addr common.Address) {
+	if !w.Enabled() {
+		return
+	}
+	lifecycle := w.getContractLifecycle(addr)
+	lifecycle.DestructedBlockNumber = hexutil.Uint64(w.height)
+	w.batch = append(w.batch, NewMsgContractLifecycle(addr, lifecycle))
+}
+
+// AuditContractDeployment checks whether the contract deployed by txHash replaces a self-destructed contract.
+// If so, the redeployment is appended to the contract lifecycle and the height of the self-destruct is returned.
+func (w *Watcher) AuditContractDeployment(addr common.Address, txHash common.Hash) (destructedHeight uint64, redeployed bool) {
+	if !w.Enabled() {
+		return 0, false
+	}
+	lifecycle := w.getContractLifecycle(addr)
+	if lifecycle.DestructedBlockNumber == 0 {
+		return 0, false
+	}
+
+	destructedHeight = uint64(lifecycle.DestructedBlockNumber)
+	lifecycle.Redeployments = append(lifecycle.Redeployments, ContractRedeploy{
+		TxHash:                txHash,
+		BlockNumber:           hexutil.Uint64(w.height),
+		DestructedBlockNumber: lifecycle.DestructedBlockNumber,
+	})
+	lifecycle.DestructedBlockNumber = 0
+	w.batch = append(w.batch, NewMsgContractLifecycle(addr, lifecycle))
+	return destructedHeight, true
+}
+
+// getContractLifecycle prefers the lifecycles updated in the current block, as the batch is not committed yet
+func (w *Watcher) getContractLifecycle(addr common.Address) *ContractLifecycle {
+	if lifecycle, ok := w.lifecycles[addr]; ok {
+		return lifecycle
+	}
+
+	lifecycle := &ContractLifecycle{}
+	if b, err := w.store.Get(append(prefixContractLifecycle, addr.Bytes()...)); err == nil && b != nil {
+		if err := itjs.Unmarshal(b, lifecycle); err != nil {
+			lifecycle = &ContractLifecycle{}
+		}
+	}
+	if w.lifecycles == nil {
+		w.lifecycles = make(map[common.Address]*ContractLifecycle)
+	}
+	w.lifecycles[addr] = lifecycle
+	return lifecycle
+}
+
 func (w *Watcher) Finalize() {
 	if !w.Enabled() {
 		return
@@ -356,9 +462,8 @@ func (w *Watcher) Commit() {
 	if !w.Enabled() {
 		return
 	}
-	//hold it in temp
-	batch := w.batch
-	go w.commitBatch(w.batch)
+	batch := w.blockBatch()
+	w.pipeline.submit(func() { w.commitBatch(batch) })
 
 	// get centerBatch for sending to DataCenter
 	centerBatch := make([]*Batch, len(batch))
@@ -368,42 +473,107 @@ func (w *Watcher) Commit() {
 	w.watchData.Batches = centerBatch
 }
 
+// blockBatch returns the watch messages of the block, with the indices of its logs and their stats
+func (w *Watcher) blockBatch() []WatchMessage {
+	//hold it in temp
+	batch := w.batch
+	assignLogIndices(batch)
+	return append(batch, newMsgLogStats(w.height, batch)...)
+}
+
 func (w *Watcher) CommitWatchData() {
 	if w.watchData == nil || w.watchData.Size() == 0 {
 		return
 	}
-	if w.watchData.Batches != nil {
-		go w.commitCenterBatch(w.watchData.Batches)
-	}
-	if w.watchData.DirtyAccount != nil {
-		go w.delDirtyAccount(w.watchData.DirtyAccount)
-	}
-	if w.watchData.DirtyList != nil {
-		go w.delDirtyList(w.watchData.DirtyList)
-	}
-	if w.watchData.BloomData != nil {
-		go w.commitBloomData(w.watchData.BloomData)
+	watchData := w.watchData
+	w.pipeline.submit(func() {
+		if watchData.DirtyAccount != nil {
+			w.delDirtyAccount(watchData.DirtyAccount)
+		}
+		if watchData.DirtyList != nil {
+			w.delDirtyList(watchData.DirtyList)
+		}
+		if watchData.BloomData != nil {
+			w.commitBloomData(watchData.BloomData)
+		}
+		if watchData.Batches != nil {
+			w.commitCenterBatch(watchData.Batches)
+		}
+	})
+}
+
+// Flush blocks until the watch data of the committed blocks is written, so that none of it is lost when the
+// node exits
+func (w *Watcher) Flush() {
+	if w.pipeline == nil {
+		return
 	}
+	w.pipeline.wait()
 }
 
 func (w *Watcher) commitBatch(batch []WatchMessage) {
+	var latestHeight WatchMessage
+	var block, bridged []byte
+	// a tx re-executed in the block keeps its last receipt
+	receipts := make(map[string][]byte)
 	for _, b := range batch {
-		key := b.GetKey()
+		if _, ok := b.(*MsgLatestHeight); ok {
+			latestHeight = b
+			continue
+		}
 		value := []byte(b.GetValue())
-		typeValue := b.GetType()
-		w.store.Set(key, value)
-		if typeValue == TypeState {
-			state.SetStateToLru(common.BytesToHash(key), value)
+		switch b.(type) {
+		case *MsgBlock:
+			block = value
+		case *MsgTransactionReceipt:
+			receipts[string(b.GetKey())] = value
+		case *MsgBridgedLogs:
+			bridged = value
 		}
+		w.setBatch(b.GetKey(), value, b.GetType())
+	}
+	// the latest height is written after all the data of its block, so the rpc never sees a height whose data is not fully written
+	if latestHeight != nil {
+		w.setBatch(latestHeight.GetKey(), []byte(latestHeight.GetValue()), latestHeight.GetType())
+	}
+	if block != nil {
+		chainEvents.publish(block, receipts, bridged)
 	}
 }
 
 func (w *Watcher) commitCenterBatch(batch []*Batch) {
+	var latestHeight *Batch
+	var block, bridged []byte
+	receipts := make(map[string][]byte)
 	for _, b := range batch {
-		w.store.Set(b.Key, b.Value)
-		if b.TypeValue == TypeState {
-			state.SetStateToLru(common.BytesToHash(b.Key), b.Value)
+		if bytes.Equal(b.Key, latestHeightKey) {
+			latestHeight = b
+			continue
 		}
+		if len(b.Key) == 1+common.HashLength {
+			switch {
+			case bytes.HasPrefix(b.Key, prefixBlock):
+				block = b.Value
+			case bytes.HasPrefix(b.Key, prefixReceipt):
+				receipts[string(b.Key)] = b.Value
+			case bytes.HasPrefix(b.Key, prefixBridgedLogs):
+				bridged = b.Value
+			}
+		}
+		w.setBatch(b.Key, b.Value, b.TypeValue)
+	}
+	if latestHeight != nil {
+		w.setBatch(latestHeight.Key, latestHeight.Value, latestHeight.TypeValue)
+	}
+	if block != nil {
+		chainEvents.publish(block, receipts, bridged)
+	}
+}
+
+func (w *Watcher) setBatch(key, value []byte, typeValue uint32) {
+	w.store.Set(key, value)
+	if typeValue == TypeState {
+		state.SetStateToLru(common.BytesToHash(key), value)
 	}
 }
 
@@ -1 +1,108 @@
 package watcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+)
+
+func TestCommitPipeline(t *testing.T) {
+	p := newCommitPipeline(2)
+
+	var written []int
+	for i := 0; i < 5; i++ {
+		height := i
+		p.submit(func() {
+			// the earlier blocks are slower to write
+			time.Sleep(time.Duration(5-height) * time.Millisecond)
+			written = append(written, height)
+		})
+	}
+	p.wait()
+
+	require.Equal(t, []int{0, 1, 2, 3, 4}, written)
+}
+
+func TestCommitPipelineBackpressure(t *testing.T) {
+	p := newCommitPipeline(2)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	p.submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	// the writes of the next blocks are queued while the writer is stalled
+	queued := make(chan struct{})
+	go func() {
+		p.submit(func() {})
+		p.submit(func() {})
+		close(queued)
+	}()
+	select {
+	case <-queued:
+	case <-time.After(time.Second):
+		t.Fatal("submit blocked with room in the queue")
+	}
+
+	// past the size of the queue the commit waits for the writer
+	submitted := make(chan struct{})
+	go func() {
+		p.submit(func() {})
+		close(submitted)
+	}()
+	select {
+	case <-submitted:
+		t.Fatal("submit didn't wait with a full queue")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-submitted:
+	case <-time.After(time.Second):
+		t.Fatal("submit still blocked once the writer caught up")
+	}
+	p.wait()
+}
+
+type keyRecordDB struct {
+	*dbm.MemDB
+	keys []string
+}
+
+func (db *keyRecordDB) Set(key []byte, value []byte) error {
+	db.keys = append(db.keys, string(key))
+	return db.MemDB.Set(key, value)
+}
+
+func TestCommitBatchLatestHeightLast(t *testing.T) {
+	db := &keyRecordDB{MemDB: dbm.NewMemDB()}
+	w := &Watcher{store: &WatchStore{db: db}}
+
+	latestHeight := NewMsgLatestHeight(10)
+	blockInfo := NewMsgBlockInfo(10, [32]byte{1})
+	w.commitBatch([]WatchMessage{latestHeight, blockInfo})
+	require.Equal(t, []string{string(blockInfo.GetKey()), string(latestHeightKey)}, db.keys)
+
+	db.keys = nil
+	w.commitCenterBatch([]*Batch{
+		{latestHeight.GetKey(), []byte(latestHeight.GetValue()), latestHeight.GetType()},
+		{blockInfo.GetKey(), []byte(blockInfo.GetValue()), blockInfo.GetType()},
+	})
+	require.Equal(t, []string{string(blockInfo.GetKey()), string(latestHeightKey)}, db.keys)
+}
+
+func TestWatcherFlush(t *testing.T) {
+	db := &keyRecordDB{MemDB: dbm.NewMemDB()}
+	w := &Watcher{store: &WatchStore{db: db}, sw: true, watchData: &WatchData{}, pipeline: newCommitPipeline(2)}
+	(&Watcher{}).Flush()
+
+	w.batch = []WatchMessage{NewMsgLatestHeight(10)}
+	w.Commit()
+	w.Flush()
+	require.Equal(t, []string{string(latestHeightKey)}, db.keys)
+}
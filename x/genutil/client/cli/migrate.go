@@ -12,11 +12,14 @@ import (
 	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
 	"github.com/okex/exchain/libs/cosmos-sdk/version"
 	extypes "github.com/okex/exchain/libs/cosmos-sdk/x/genutil"
+	evmproto "github.com/okex/exchain/x/genutil/client/legacy/evm_proto"
 	v018 "github.com/okex/exchain/x/genutil/client/legacy/v0_18"
 )
 
 var migrationMap = extypes.MigrationMap{
 	"v0.18": v018.Migrate,
+	// the evm genesis state in the json encoding of its protobuf message
+	"evm-proto": evmproto.Migrate,
 }
 
 const (
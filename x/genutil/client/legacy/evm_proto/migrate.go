@@ -0,0 +1,25 @@
+package evmproto
+
+import (
+	"github.com/okex/exchain/libs/cosmos-sdk/x/genutil"
+	evmtypes "github.com/okex/exchain/x/evm/types"
+)
+
+// Migrate migrates the evm genesis state from the amino json encoding to the json encoding of its protobuf
+// message, the other modules are left untouched.
+func Migrate(appState genutil.AppMap) genutil.AppMap {
+	if appState[evmtypes.ModuleName] == nil {
+		return appState
+	}
+
+	evmState, err := evmtypes.UnmarshalGenesisState(evmtypes.ModuleCdc, appState[evmtypes.ModuleName])
+	if err != nil {
+		panic(err)
+	}
+	bz, err := evmtypes.MarshalGenesisProtoJSON(evmState)
+	if err != nil {
+		panic(err)
+	}
+	appState[evmtypes.ModuleName] = bz
+	return appState
+}
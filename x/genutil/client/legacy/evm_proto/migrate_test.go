@@ -0,0 +1,36 @@
+package evmproto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	"github.com/okex/exchain/libs/cosmos-sdk/x/genutil"
+	evmtypes "github.com/okex/exchain/x/evm/types"
+)
+
+func TestMigrate(t *testing.T) {
+	gs := evmtypes.DefaultGenesisState()
+	gs.Accounts = append(gs.Accounts, evmtypes.GenesisAccount{
+		Address: "0x756F45E3FA69347A9A973A725E3C98bC4db0b5a0",
+		Code:    []byte{0x60, 0x80},
+		Storage: evmtypes.Storage{evmtypes.NewState([32]byte{1}, [32]byte{2})},
+	})
+	gs.Params.FeeDenoms = []string{"usdt"}
+	gs.Params.FeeConversionSpread = sdk.NewDecWithPrec(3, 3)
+	appState := genutil.AppMap{
+		evmtypes.ModuleName: evmtypes.ModuleCdc.MustMarshalJSON(gs),
+		"staking":           []byte(`{"params":{}}`),
+	}
+
+	appState = Migrate(appState)
+	require.Equal(t, `{"params":{}}`, string(appState["staking"]))
+	require.Contains(t, string(appState[evmtypes.ModuleName]), `"yolo_v2_block":"-1"`)
+	require.Contains(t, string(appState[evmtypes.ModuleName]), `"fee_conversion_spread":"0.003000000000000000"`)
+
+	migrated, err := evmtypes.UnmarshalGenesisState(evmtypes.ModuleCdc, appState[evmtypes.ModuleName])
+	require.NoError(t, err)
+	require.Equal(t, gs, migrated)
+	require.NoError(t, migrated.Validate())
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/okex/exchain/libs/cosmos-sdk/client/flags"
 
+	ethcmn "github.com/ethereum/go-ethereum/common"
 	"github.com/spf13/cobra"
 	flag "github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -40,6 +41,7 @@ func GetTxCmd(storeKey string, cdc *codec.Codec) *cobra.Command {
 			GetCmdCreateValidator(cdc),
 			GetCmdDestroyValidator(cdc),
 			GetCmdEditValidator(cdc),
+			GetCmdSetFeeRecipient(cdc),
 			GetCmdDeposit(cdc),
 			GetCmdWithdraw(cdc),
 			GetCmdAddShares(cdc),
@@ -144,6 +146,37 @@ var (
 //defaultCommissionMaxChangeRate = "0.01"
 )
 
+// GetCmdSetFeeRecipient gets the set-fee-recipient command
+func GetCmdSetFeeRecipient(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-fee-recipient [address]",
+		Short: "set the evm fee recipient of a validator",
+		Long: `Set the address returned by the evm COINBASE and reported as the miner of the blocks proposed by
+the validator, in hex or bech32. Without address it's reset to the consensus address of the validator.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			txBldr := auth.NewTxBuilderFromCLI(inBuf).WithTxEncoder(auth.DefaultTxEncoder(cdc))
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			var feeRecipient sdk.AccAddress
+			if len(args) == 1 {
+				if ethcmn.IsHexAddress(args[0]) {
+					feeRecipient = ethcmn.HexToAddress(args[0]).Bytes()
+				} else {
+					var err error
+					if feeRecipient, err = sdk.AccAddressFromBech32(args[0]); err != nil {
+						return fmt.Errorf("invalid fee recipient %s: %w", args[0], err)
+					}
+				}
+			}
+
+			msg := types.NewMsgSetFeeRecipient(sdk.ValAddress(cliCtx.GetFromAddress()), feeRecipient)
+			return utils.GenerateOrBroadcastMsgs(cliCtx, txBldr, []sdk.Msg{msg})
+		},
+	}
+}
+
 // CreateValidatorMsgHelpers returns the flagset, particular flags, and a description of defaults
 // this is anticipated to be used with the gen-tx
 func CreateValidatorMsgHelpers(ipDefault string) (fs *flag.FlagSet, nodeIDFlag, pubkeyFlag, amountFlag,
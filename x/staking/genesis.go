@@ -45,6 +45,9 @@ func InitGenesis(ctx sdk.Context, keeper Keeper, accountKeeper types.AccountKeep
 	for _, proxyDelegatorKeyExported := range data.ProxyDelegatorKeys {
 		keeper.SetProxyBinding(ctx, proxyDelegatorKeyExported.ProxyAddr, proxyDelegatorKeyExported.DelAddr, false)
 	}
+	for _, feeRecipientExported := range data.FeeRecipients {
+		keeper.SetFeeRecipient(ctx, feeRecipientExported.ValidatorAddress, feeRecipientExported.FeeRecipient)
+	}
 
 	checkPools(ctx, keeper, sdk.NewDecCoinFromDec(sdk.DefaultBondDenom, bondedTokens),
 		sdk.NewDecCoinFromDec(sdk.DefaultBondDenom, notBondedTokens), data.Exported)
@@ -164,6 +167,12 @@ func ExportGenesis(ctx sdk.Context, keeper Keeper) types.GenesisState {
 		return false
 	})
 
+	var feeRecipients []types.FeeRecipientExported
+	keeper.IterateFeeRecipients(ctx, func(valAddr sdk.ValAddress, feeRecipient sdk.AccAddress) (stop bool) {
+		feeRecipients = append(feeRecipients, types.NewFeeRecipientExported(valAddr, feeRecipient))
+		return false
+	})
+
 	return types.GenesisState{
 		Params:               params,
 		LastTotalPower:       lastTotalPower,
@@ -173,6 +182,7 @@ func ExportGenesis(ctx sdk.Context, keeper Keeper) types.GenesisState {
 		UnbondingDelegations: undelegationInfos,
 		AllShares:            sharesExportedSlice,
 		ProxyDelegatorKeys:   proxyDelegatorKeys,
+		FeeRecipients:        feeRecipients,
 		Exported:             true,
 	}
 }
@@ -199,6 +209,9 @@ func ValidateGenesis(data types.GenesisState) error {
 	if err != nil {
 		return err
 	}
+	if err = validateGenesisStateFeeRecipients(data.Validators, data.FeeRecipients); err != nil {
+		return err
+	}
 	return data.Params.Validate()
 }
 
@@ -223,3 +236,20 @@ func validateGenesisStateValidators(valsExported []types.ValidatorExported) (err
 	}
 	return
 }
+
+func validateGenesisStateFeeRecipients(valsExported []types.ValidatorExported,
+	feeRecipients []types.FeeRecipientExported) error {
+	vals := make(map[string]bool, len(valsExported))
+	for _, valExported := range valsExported {
+		vals[valExported.OperatorAddress.String()] = true
+	}
+	for _, feeRecipient := range feeRecipients {
+		if !vals[feeRecipient.ValidatorAddress.String()] {
+			return fmt.Errorf("fee recipient of unknown validator %s in genesis state", feeRecipient.ValidatorAddress)
+		}
+		if len(feeRecipient.FeeRecipient) != sdk.AddrLen {
+			return fmt.Errorf("invalid fee recipient of validator %s in genesis state", feeRecipient.ValidatorAddress)
+		}
+	}
+	return nil
+}
@@ -20,6 +20,8 @@ func NewHandler(k keeper.Keeper) sdk.Handler {
 			return handleMsgCreateValidator(ctx, msg, k)
 		case types.MsgEditValidator:
 			return handleMsgEditValidator(ctx, msg, k)
+		case types.MsgSetFeeRecipient:
+			return handleMsgSetFeeRecipient(ctx, msg, k)
 		case types.MsgDeposit:
 			return handleMsgDeposit(ctx, msg, k)
 		case types.MsgWithdraw:
@@ -178,3 +180,25 @@ func handleMsgEditValidator(ctx sdk.Context, msg types.MsgEditValidator, k keepe
 
 	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
 }
+
+func handleMsgSetFeeRecipient(ctx sdk.Context, msg types.MsgSetFeeRecipient, k keeper.Keeper) (*sdk.Result, error) {
+	// validator must already be registered
+	if _, found := k.GetValidator(ctx, msg.ValidatorAddress); !found {
+		return nil, ErrNoValidatorFound(msg.ValidatorAddress.String())
+	}
+
+	k.SetFeeRecipient(ctx, msg.ValidatorAddress, msg.FeeRecipient)
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(types.EventTypeSetFeeRecipient,
+			sdk.NewAttribute(types.AttributeKeyValidator, msg.ValidatorAddress.String()),
+			sdk.NewAttribute(types.AttributeKeyFeeRecipient, msg.FeeRecipient.String()),
+		),
+		sdk.NewEvent(sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.ValidatorAddress.String()),
+		),
+	})
+
+	return &sdk.Result{Events: ctx.EventManager().Events()}, nil
+}
@@ -155,6 +155,42 @@ func TestInvalidPubKeyTypeMsgCreateValidator(t *testing.T) {
 	require.Nil(t, err, "%v", got)
 }
 
+func TestSetFeeRecipient(t *testing.T) {
+	validatorAddr := sdk.ValAddress(keep.Addrs[0])
+	feeRecipient := keep.Addrs[1]
+	ctx, _, mKeeper := CreateTestInput(t, false, SufficientInitPower)
+	keeper := mKeeper.Keeper
+	handler := NewHandler(keeper)
+
+	// the validator must exist
+	_, err := handler(ctx, NewMsgSetFeeRecipient(validatorAddr, feeRecipient))
+	require.NotNil(t, err)
+
+	got, err := handler(ctx, NewTestMsgCreateValidator(validatorAddr, keep.PKs[0], DefaultMSD))
+	require.Nil(t, err, "expected create-validator to be ok, got %v", got)
+	consAddr := sdk.ConsAddress(keep.PKs[0].Address())
+	_, found := keeper.GetFeeRecipientByConsAddr(ctx, consAddr)
+	require.False(t, found)
+
+	_, err = handler(ctx, NewMsgSetFeeRecipient(validatorAddr, feeRecipient))
+	require.Nil(t, err)
+	got2, found := keeper.GetFeeRecipientByConsAddr(ctx, consAddr)
+	require.True(t, found)
+	require.Equal(t, feeRecipient, got2)
+
+	// exported and imported with the genesis
+	genesis := ExportGenesis(ctx, keeper)
+	require.Equal(t, []types.FeeRecipientExported{types.NewFeeRecipientExported(validatorAddr, feeRecipient)},
+		genesis.FeeRecipients)
+	require.Nil(t, ValidateGenesis(genesis))
+
+	// an empty fee recipient resets it
+	_, err = handler(ctx, NewMsgSetFeeRecipient(validatorAddr, nil))
+	require.Nil(t, err)
+	_, found = keeper.GetFeeRecipientByConsAddr(ctx, consAddr)
+	require.False(t, found)
+}
+
 // TODO: msd is fixed now. nothing could change it!!!
 func TestEditValidatorDecreaseMinSelfDelegation(t *testing.T) {
 	validatorAddr := sdk.ValAddress(keep.Addrs[0])
@@ -0,0 +1,47 @@
+package keeper
+
+import (
+	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	"github.com/okex/exchain/x/staking/types"
+)
+
+// SetFeeRecipient sets the evm fee recipient of a validator, an empty address deletes it
+func (k Keeper) SetFeeRecipient(ctx sdk.Context, valAddr sdk.ValAddress, feeRecipient sdk.AccAddress) {
+	store := ctx.KVStore(k.storeKey)
+	if feeRecipient.Empty() {
+		store.Delete(types.GetFeeRecipientKey(valAddr))
+		return
+	}
+	store.Set(types.GetFeeRecipientKey(valAddr), feeRecipient)
+}
+
+// GetFeeRecipient gets the evm fee recipient of a validator
+func (k Keeper) GetFeeRecipient(ctx sdk.Context, valAddr sdk.ValAddress) (sdk.AccAddress, bool) {
+	bz := ctx.KVStore(k.storeKey).Get(types.GetFeeRecipientKey(valAddr))
+	if bz == nil {
+		return nil, false
+	}
+	return bz, true
+}
+
+// GetFeeRecipientByConsAddr gets the evm fee recipient of the validator with the consensus address, as found in
+// the proposer address of a block header
+func (k Keeper) GetFeeRecipientByConsAddr(ctx sdk.Context, consAddr sdk.ConsAddress) (sdk.AccAddress, bool) {
+	opAddr := ctx.KVStore(k.storeKey).Get(types.GetValidatorByConsAddrKey(consAddr))
+	if opAddr == nil {
+		return nil, false
+	}
+	return k.GetFeeRecipient(ctx, opAddr)
+}
+
+// IterateFeeRecipients iterates over the evm fee recipients of the validators
+func (k Keeper) IterateFeeRecipients(ctx sdk.Context, fn func(valAddr sdk.ValAddress, feeRecipient sdk.AccAddress) (stop bool)) {
+	iterator := sdk.KVStorePrefixIterator(ctx.KVStore(k.storeKey), types.FeeRecipientKey)
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		if fn(iterator.Key()[1:], iterator.Value()) {
+			break
+		}
+	}
+}
@@ -39,6 +39,8 @@ func NewQuerier(k Keeper) sdk.Querier {
 			return queryProxy(ctx, req, k)
 		case types.QueryDelegator:
 			return queryDelegator(ctx, req, k)
+		case types.QueryFeeRecipient:
+			return queryFeeRecipient(ctx, req, k)
 		default:
 			return nil, types.ErrUnknownStakingQueryType()
 		}
@@ -233,6 +235,25 @@ func queryForAddress(ctx sdk.Context, req abci.RequestQuery, k Keeper) (res []by
 	return res, nil
 }
 
+// queryFeeRecipient returns the evm fee recipient of the validator with the hex consensus address, empty if
+// the validator didn't set any
+func queryFeeRecipient(ctx sdk.Context, req abci.RequestQuery, k Keeper) (res []byte, err error) {
+	consAddr, errHex := sdk.ConsAddressFromHex(string(req.Data))
+	if errHex != nil || len(consAddr) != crypto.AddressSize {
+		return nil, types.ErrBadValidatorAddr()
+	}
+	if _, found := k.GetValidatorByConsAddr(ctx, consAddr); !found {
+		return nil, types.ErrNoValidatorFound(consAddr.String())
+	}
+
+	feeRecipient, _ := k.GetFeeRecipientByConsAddr(ctx, consAddr)
+	res, errRes := codec.MarshalJSONIndent(types.ModuleCdc, feeRecipient)
+	if errRes != nil {
+		return nil, common.ErrMarshalJSONFailed(errRes.Error())
+	}
+	return res, nil
+}
+
 func queryForAccAddress(ctx sdk.Context, req abci.RequestQuery) (res []byte, err error) {
 
 	valAddr, errBech32 := sdk.ValAddressFromBech32(string(req.Data))
@@ -5,6 +5,7 @@ const (
 	EventTypeCompleteUnbonding = "complete_unbonding"
 	EventTypeCreateValidator   = "create_validator"
 	EventTypeEditValidator     = "edit_validator"
+	EventTypeSetFeeRecipient   = "set_fee_recipient"
 	EventTypeDelegate          = "delegate"
 	EventTypeUnbond            = "unbond"
 
@@ -13,6 +14,7 @@ const (
 	AttributeKeyMinSelfDelegation = "min_self_delegation"
 	AttributeKeyDelegator         = "delegator"
 	AttributeKeyCompletionTime    = "completion_time"
+	AttributeKeyFeeRecipient      = "fee_recipient"
 	AttributeValueCategory        = ModuleName
 
 	EventTypeAddShares = "add_shares"
@@ -17,6 +17,7 @@ type GenesisState struct {
 	UnbondingDelegations []UndelegationInfo          `json:"unbonding_delegations" yaml:"unbonding_delegations"`
 	AllShares            []SharesExported            `json:"all_shares" yaml:"all_shares"`
 	ProxyDelegatorKeys   []ProxyDelegatorKeyExported `json:"proxy_delegator_keys" yaml:"proxy_delegator_keys"`
+	FeeRecipients        []FeeRecipientExported      `json:"fee_recipients,omitempty" yaml:"fee_recipients,omitempty"`
 	Exported             bool                        `json:"exported" yaml:"exported"`
 }
 
@@ -114,6 +115,20 @@ func NewProxyDelegatorKeyExported(delAddr, proxyAddr sdk.AccAddress) ProxyDelega
 	}
 }
 
+// FeeRecipientExported is designed for the evm fee recipients export
+type FeeRecipientExported struct {
+	ValidatorAddress sdk.ValAddress `json:"validator_address" yaml:"validator_address"`
+	FeeRecipient     sdk.AccAddress `json:"fee_recipient" yaml:"fee_recipient"`
+}
+
+// NewFeeRecipientExported creates a new object of FeeRecipientExported
+func NewFeeRecipientExported(valAddr sdk.ValAddress, feeRecipient sdk.AccAddress) FeeRecipientExported {
+	return FeeRecipientExported{
+		valAddr,
+		feeRecipient,
+	}
+}
+
 // SharesExported is designed for types.Shares export
 type SharesExported struct {
 	DelAddress       sdk.AccAddress `json:"delegator_address" yaml:"delegator_address"`
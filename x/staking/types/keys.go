@@ -48,6 +48,7 @@ var (
 	UnDelegationInfoKey = []byte{0x53}
 	UnDelegateQueueKey  = []byte{0x54}
 	ProxyKey            = []byte{0x55}
+	FeeRecipientKey     = []byte{0x56} // prefix for the evm fee recipient of a validator
 
 	// prefix key for vals info to enforce the update of validator-set
 	ValidatorAbandonedKey = []byte{0x60}
@@ -126,6 +127,12 @@ func GetProxyDelegatorKey(proxyAddr, delAddr sdk.AccAddress) []byte {
 	return append(append(ProxyKey, proxyAddr...), delAddr...)
 }
 
+// GetFeeRecipientKey gets the key for the evm fee recipient of a validator
+// VALUE: fee recipient address ([]byte)
+func GetFeeRecipientKey(valAddr sdk.ValAddress) []byte {
+	return append(FeeRecipientKey, valAddr.Bytes()...)
+}
+
 // GetSharesKey gets the whole key for an item of shares info
 func GetSharesKey(valAddr sdk.ValAddress, delAddr sdk.AccAddress) []byte {
 	return append(GetSharesToValidatorsKey(valAddr), delAddr.Bytes()...)
@@ -6,12 +6,14 @@ import (
 	"github.com/okex/exchain/libs/tendermint/crypto"
 
 	sdk "github.com/okex/exchain/libs/cosmos-sdk/types"
+	sdkerrors "github.com/okex/exchain/libs/cosmos-sdk/types/errors"
 )
 
 // ensure Msg interface compliance at compile time
 var (
 	_ sdk.Msg = &MsgCreateValidator{}
 	_ sdk.Msg = &MsgEditValidator{}
+	_ sdk.Msg = &MsgSetFeeRecipient{}
 )
 
 //______________________________________________________________________
@@ -167,3 +169,43 @@ func (msg MsgEditValidator) ValidateBasic() error {
 
 	return nil
 }
+
+// MsgSetFeeRecipient - struct for setting the address returned by the evm COINBASE in the blocks proposed by a
+// validator. An empty fee recipient resets it to the consensus address of the validator
+type MsgSetFeeRecipient struct {
+	ValidatorAddress sdk.ValAddress `json:"validator_address" yaml:"validator_address"`
+	FeeRecipient     sdk.AccAddress `json:"fee_recipient" yaml:"fee_recipient"`
+}
+
+// NewMsgSetFeeRecipient creates a msg of set-fee-recipient
+func NewMsgSetFeeRecipient(valAddr sdk.ValAddress, feeRecipient sdk.AccAddress) MsgSetFeeRecipient {
+	return MsgSetFeeRecipient{
+		ValidatorAddress: valAddr,
+		FeeRecipient:     feeRecipient,
+	}
+}
+
+// nolint
+func (msg MsgSetFeeRecipient) Route() string { return RouterKey }
+func (msg MsgSetFeeRecipient) Type() string  { return "set_fee_recipient" }
+func (msg MsgSetFeeRecipient) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{sdk.AccAddress(msg.ValidatorAddress)}
+}
+
+// GetSignBytes gets the bytes for the message signer to sign on
+func (msg MsgSetFeeRecipient) GetSignBytes() []byte {
+	bz := ModuleCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(bz)
+}
+
+// ValidateBasic gives a quick validity check
+func (msg MsgSetFeeRecipient) ValidateBasic() error {
+	if msg.ValidatorAddress.Empty() {
+		return ErrNilValidatorAddr()
+	}
+	if !msg.FeeRecipient.Empty() && len(msg.FeeRecipient) != sdk.AddrLen {
+		return sdkerrors.Wrapf(sdkerrors.ErrInvalidAddress, "invalid fee recipient length %d", len(msg.FeeRecipient))
+	}
+
+	return nil
+}
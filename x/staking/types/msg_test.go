@@ -129,6 +129,31 @@ func TestMsgEditValidator(t *testing.T) {
 	}
 }
 
+// test ValidateBasic for MsgSetFeeRecipient
+func TestMsgSetFeeRecipient(t *testing.T) {
+	tests := []struct {
+		name          string
+		validatorAddr sdk.ValAddress
+		feeRecipient  sdk.AccAddress
+		expectPass    bool
+	}{
+		{"basic good", valAddr1, sdk.AccAddress(valAddr2), true},
+		{"reset", valAddr1, nil, true},
+		{"empty address", emptyAddr, sdk.AccAddress(valAddr2), false},
+		{"bad fee recipient", valAddr1, sdk.AccAddress("short"), false},
+	}
+
+	for _, tc := range tests {
+		msg := NewMsgSetFeeRecipient(tc.validatorAddr, tc.feeRecipient)
+		if tc.expectPass {
+			require.Nil(t, msg.ValidateBasic(), "test: %v", tc.name)
+			checkMsg(t, msg, "set_fee_recipient")
+		} else {
+			require.NotNil(t, msg.ValidateBasic(), "test: %v", tc.name)
+		}
+	}
+}
+
 func checkMsg(t *testing.T, msg sdk.Msg, expType string) {
 	require.Contains(t, msg.Route(), RouterKey)
 	require.Contains(t, msg.Type(), expType)
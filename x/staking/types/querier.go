@@ -17,6 +17,7 @@ const (
 	QueryProxy               = "proxy"
 	QueryValidatorAllShares  = "validatorAllShares"
 	QueryDelegator           = "delegator"
+	QueryFeeRecipient        = "feeRecipient"
 )
 
 // QueryDelegatorParams defines the params for the following queries:
@@ -74,7 +74,7 @@ func (k Keeper) OnTokenPairUpdated(ctx sdk.Context) {
 }
 
 // OnAccountUpdated called by auth when account updated
-func (k Keeper) OnAccountUpdated(acc auth.Account) {
+func (k Keeper) OnAccountUpdated(_ sdk.Context, acc auth.Account) {
 	k.stream.logger.Debug(fmt.Sprintf("OnAccountUpdated:%s", acc.GetAddress()))
 	k.stream.Cache.AddUpdatedAccount(acc)
 }